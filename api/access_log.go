@@ -0,0 +1,212 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+const (
+	// requestIDHeader is the header that carries a request's ID. A client may
+	// set it to trace its requests through the logs, otherwise an ID is
+	// assigned. Either way, the response carries it too.
+	requestIDHeader = "X-Request-ID"
+
+	// Longest request ID a client may set
+	maxRequestIDLen = 64
+
+	// Number of random bytes in an assigned request ID
+	requestIDLen = 8
+)
+
+var errCantHijack = errors.New("response writer can't be hijacked")
+
+// requestIDKey is the key of a request's ID in its context
+type requestIDKey struct{}
+
+// RequestID returns the ID assigned to [r], so that services can include it in
+// their logs, or the empty string if it wasn't assigned one
+func RequestID(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID returns [r] with an ID assigned to it. The ID the client set
+// is kept if it's valid.
+func withRequestID(r *http.Request) *http.Request {
+	id := r.Header.Get(requestIDHeader)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	return r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+}
+
+// requestIDHandler assigns each request an ID, and sets it on the response
+func requestIDHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = withRequestID(r)
+		w.Header().Set(requestIDHeader, RequestID(r))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// newRequestID returns a random request ID
+func newRequestID() string {
+	idBytes := make([]byte, requestIDLen)
+	// If reading fails the ID is all zeros, which only makes tracing harder
+	_, _ = rand.Read(idBytes)
+	return hex.EncodeToString(idBytes)
+}
+
+// validRequestID returns true if [id] is short and only contains letters,
+// digits, '-', '_' and '.', so it can't corrupt the logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// accessLogEntry is the record written to the access log for each request
+type accessLogEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestID"`
+	Caller    string    `json:"caller"`
+
+	// The service is the route the request was made to, and the method is the
+	// JSON-RPC method called, if any
+	HTTPMethod string `json:"httpMethod"`
+	Service    string `json:"service"`
+	Method     string `json:"method,omitempty"`
+
+	Status int `json:"status"`
+	// Duration is in milliseconds
+	Duration float64 `json:"duration"`
+}
+
+// accessLogHandler writes an entry to [log] for each request to [handler], a
+// JSON object on its own line
+type accessLogHandler struct {
+	log     io.Writer
+	clock   timer.Clock
+	service string
+	handler http.Handler
+}
+
+func (h *accessLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := h.clock.Time()
+	entry := accessLogEntry{
+		Time:       start,
+		RequestID:  RequestID(r),
+		Caller:     r.RemoteAddr,
+		HTTPMethod: r.Method,
+		Service:    h.service,
+	}
+
+	entry.Method = jsonRPCMethod(r)
+
+	writer := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	h.handler.ServeHTTP(writer, r)
+
+	entry.Status = writer.status
+	entry.Duration = float64(h.clock.Time().Sub(start)) / float64(time.Millisecond)
+	entryBytes, err := json.Marshal(&entry)
+	if err != nil {
+		return
+	}
+	_, _ = h.log.Write(append(entryBytes, '\n'))
+}
+
+// statusWriter records the status code of a response. It can be hijacked and
+// flushed if the response writer it wraps can be, so websocket connections can
+// be upgraded through it and event streams sent through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errCantHijack
+	}
+	w.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// jsonRPCMethod returns the JSON-RPC method that [r] calls, or "" if it doesn't
+// call one. The methods of a batch of calls are separated by commas.
+func jsonRPCMethod(r *http.Request) string {
+	return strings.Join(jsonRPCMethods(r), ",")
+}
+
+// jsonRPCMethods returns the JSON-RPC methods that [r] calls, one for each call
+// of a batch, or nil if it doesn't call any. The body is read to find the
+// methods, then replaced so the handler can read it again.
+func jsonRPCMethods(r *http.Request) []string {
+	if r.Body == nil || r.Method != http.MethodPost {
+		return nil
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	type call struct {
+		Method string `json:"method"`
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		batch := []call(nil)
+		if json.Unmarshal(body, &batch) != nil {
+			return nil
+		}
+		methods := make([]string, len(batch))
+		for i, c := range batch {
+			methods[i] = c.Method
+		}
+		return methods
+	}
+	single := call{}
+	if json.Unmarshal(body, &single) != nil {
+		return nil
+	}
+	return []string{single.Method}
+}
@@ -0,0 +1,82 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	seen := ""
+	handler := requestIDHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) { seen = RequestID(r) }))
+
+	for header, kept := range map[string]bool{
+		"trace-1.a_b":            true,
+		"":                       false,
+		"has spaces":             false,
+		"new\nline":              false,
+		strings.Repeat("a", 100): false,
+	} {
+		request := httptest.NewRequest(http.MethodPost, "/ext/keystore", nil)
+		request.Header.Set(requestIDHeader, header)
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+
+		switch {
+		case seen == "":
+			t.Fatalf("request with header %q should have been assigned an ID", header)
+		case writer.Header().Get(requestIDHeader) != seen:
+			t.Fatalf("response should carry the request's ID %q", seen)
+		case (seen == header) != kept:
+			t.Fatalf("request with header %q was assigned %q", header, seen)
+		}
+	}
+
+	if RequestID(nil) != "" || RequestID(httptest.NewRequest(http.MethodGet, "/", nil)) != "" {
+		t.Fatalf("requests without IDs should have the empty ID")
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	log := &bytes.Buffer{}
+	body := ""
+	handler := requestIDHandler(&accessLogHandler{
+		log:     log,
+		service: "/ext/keystore",
+		handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf := &bytes.Buffer{}
+			buf.ReadFrom(r.Body)
+			body = buf.String()
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	})
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"keystore.createUser","params":{}}`
+	request := httptest.NewRequest(http.MethodPost, "/ext/keystore", strings.NewReader(call))
+	request.Header.Set(requestIDHeader, "trace")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	if body != call {
+		t.Fatalf("handler should have read the whole body but read %q", body)
+	}
+	entry := accessLogEntry{}
+	if err := json.Unmarshal(log.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case entry.RequestID != "trace":
+		t.Fatalf("entry should have the request's ID but has %q", entry.RequestID)
+	case entry.Service != "/ext/keystore" || entry.Method != "keystore.createUser" || entry.HTTPMethod != http.MethodPost:
+		t.Fatalf("entry should describe the call: %+v", entry)
+	case entry.Status != http.StatusTeapot:
+		t.Fatalf("entry should have the response's status but has %d", entry.Status)
+	case entry.Caller != request.RemoteAddr:
+		t.Fatalf("entry should have the caller's address but has %q", entry.Caller)
+	}
+}
@@ -0,0 +1,107 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/vms/components/address"
+)
+
+// defaultAddressChain is the chain that ConvertAddress formats addresses for if
+// no chain is given
+const defaultAddressChain = "X"
+
+var (
+	errNoAddressOrKey = errors.New("either an address or a public key must be given")
+	errAddressAndKey  = errors.New("only one of an address and a public key may be given")
+)
+
+// ConvertAddressArgs are the arguments for calling ConvertAddress
+type ConvertAddressArgs struct {
+	// Address to convert, in CB58 or bech32. It may be prefixed with the alias
+	// of the chain it's on, such as X-6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV, or
+	// not, such as a P-Chain address.
+	Address string `json:"address"`
+
+	// SECP256K1 public key whose address to convert
+	PublicKey formatting.CB58 `json:"publicKey"`
+
+	// Alias or ID of the chain to format the address for, in addition to the
+	// P-Chain. Defaults to the X-Chain.
+	Chain string `json:"chain"`
+}
+
+// ConvertAddressReply are the results from calling ConvertAddress
+type ConvertAddressReply struct {
+	// The address as the P-Chain represents it
+	PChainAddress ids.ShortID `json:"pChainAddress"`
+
+	// The address as [args.Chain] represents it
+	ChainAddress string `json:"chainAddress"`
+
+	// The address on [args.Chain] in bech32, with the HRP of this node's
+	// network
+	ChainAddressBech32 string `json:"chainAddressBech32"`
+
+	// ID of [args.Chain]
+	ChainID ids.ID `json:"chainID"`
+}
+
+// ConvertAddress returns the representations of an address on the P-Chain and
+// on another chain, given the address on either chain or the public key that
+// controls it
+func (service *Admin) ConvertAddress(_ *http.Request, args *ConvertAddressArgs, reply *ConvertAddressReply) error {
+	service.log.Debug("Admin: ConvertAddress called with address %s and chain %s", args.Address, args.Chain)
+
+	addr := ids.ShortID{}
+	switch {
+	case args.Address == "" && len(args.PublicKey.Bytes) == 0:
+		return errNoAddressOrKey
+	case args.Address != "" && len(args.PublicKey.Bytes) != 0:
+		return errAddressAndKey
+	case args.Address != "":
+		chainAlias, parsedAddr, err := address.ParseAny(args.Address, service.networkID)
+		if err != nil {
+			return fmt.Errorf("couldn't parse address %s: %w", args.Address, err)
+		}
+		// Catch addresses that are prefixed with a mistyped chain
+		if chainAlias != "" {
+			if _, err := service.chainManager.Lookup(chainAlias); err != nil {
+				return fmt.Errorf("address %s is on an unknown chain: %w", args.Address, err)
+			}
+		}
+		addr = parsedAddr
+	default:
+		keyAddr, err := address.FromPublicKey(args.PublicKey.Bytes)
+		if err != nil {
+			return fmt.Errorf("couldn't parse public key: %w", err)
+		}
+		addr = keyAddr
+	}
+
+	chain := args.Chain
+	if chain == "" {
+		chain = defaultAddressChain
+	}
+	chainID, err := service.chainManager.Lookup(chain)
+	if err != nil {
+		return fmt.Errorf("unknown chain %s: %w", chain, err)
+	}
+
+	bech32Addr, err := address.FormatBech32(chain, service.networkID, addr.Bytes())
+	if err != nil {
+		return fmt.Errorf("couldn't format address in bech32: %w", err)
+	}
+
+	reply.PChainAddress = addr
+	reply.ChainAddress = address.Format(chain, addr.Bytes())
+	reply.ChainAddressBech32 = bech32Addr
+	reply.ChainID = chainID
+	return nil
+}
@@ -0,0 +1,168 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/gecko/api"
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// aliaser gives IDs aliases
+type aliaser interface {
+	Alias(ids.ID, string) error
+	RemoveAlias(string) error
+}
+
+// Aliases are the chain and VM aliases added at runtime through the admin API.
+// They are persisted so that they can be restored when the node restarts.
+type Aliases struct {
+	lock       sync.Mutex
+	log        logging.Logger
+	httpServer *api.Server
+
+	chains aliasStore
+	vms    aliasStore
+}
+
+// Initialize the aliases, which are persisted in [db]
+func (a *Aliases) Initialize(log logging.Logger, db database.Database, chainAliaser, vmAliaser aliaser, httpServer *api.Server) {
+	a.log = log
+	a.httpServer = httpServer
+	a.chains = aliasStore{
+		db:       prefixdb.New([]byte("chains"), db),
+		aliaser:  chainAliaser,
+		endpoint: "bc/",
+	}
+	a.vms = aliasStore{
+		db:       prefixdb.New([]byte("vms"), db),
+		aliaser:  vmAliaser,
+		endpoint: "vm/",
+	}
+}
+
+// Restore gives the chains and VMs the aliases that were persisted, and
+// aliases their HTTP endpoints
+func (a *Aliases) Restore() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for _, store := range []*aliasStore{&a.chains, &a.vms} {
+		if err := store.restore(a.log, a.httpServer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AliasChain gives chain [chainID] the alias [alias] and persists it
+// Assumes the HTTP server's read lock is held
+func (a *Aliases) AliasChain(chainID ids.ID, alias string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.chains.add(a.httpServer, chainID, alias)
+}
+
+// RemoveChainAlias removes the alias [alias], which must have been added with
+// AliasChain, from the chain it refers to
+// Assumes the HTTP server's read lock is held
+func (a *Aliases) RemoveChainAlias(alias string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.chains.remove(a.httpServer, alias)
+}
+
+// AliasVM gives VM [vmID] the alias [alias] and persists it
+// Assumes the HTTP server's read lock is held
+func (a *Aliases) AliasVM(vmID ids.ID, alias string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.vms.add(a.httpServer, vmID, alias)
+}
+
+// RemoveVMAlias removes the alias [alias], which must have been added with
+// AliasVM, from the VM it refers to
+// Assumes the HTTP server's read lock is held
+func (a *Aliases) RemoveVMAlias(alias string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.vms.remove(a.httpServer, alias)
+}
+
+// aliasStore persists the aliases given to one kind of ID
+type aliasStore struct {
+	// Key: alias
+	// Value: ID the alias refers to
+	db database.Database
+
+	aliaser aliaser
+
+	// Prefix of the HTTP endpoints of the IDs
+	endpoint string
+}
+
+func (s *aliasStore) restore(log logging.Logger, httpServer *api.Server) error {
+	it := s.db.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		alias := string(it.Key())
+		id, err := ids.ToID(it.Value())
+		if err != nil {
+			return err
+		}
+		if err := s.aliaser.Alias(id, alias); err != nil {
+			return err
+		}
+		if err := httpServer.AddAliases(s.endpoint+id.String(), s.endpoint+alias); err != nil {
+			return err
+		}
+		log.Debug("restored alias %s of %s", alias, id)
+	}
+	return it.Error()
+}
+
+func (s *aliasStore) add(httpServer *api.Server, id ids.ID, alias string) error {
+	if err := s.aliaser.Alias(id, alias); err != nil {
+		return err
+	}
+	if err := httpServer.AddAliasesWithReadLock(s.endpoint+id.String(), s.endpoint+alias); err != nil {
+		// Don't leave an alias that can't be reached over HTTP
+		if removeErr := s.aliaser.RemoveAlias(alias); removeErr != nil {
+			return fmt.Errorf("%s: %w", removeErr, err)
+		}
+		return err
+	}
+	return s.db.Put([]byte(alias), id.Bytes())
+}
+
+func (s *aliasStore) remove(httpServer *api.Server, alias string) error {
+	idBytes, err := s.db.Get([]byte(alias))
+	if err == database.ErrNotFound {
+		return fmt.Errorf("%s wasn't added through the admin API, so it can't be removed", alias)
+	} else if err != nil {
+		return err
+	}
+	id, err := ids.ToID(idBytes)
+	if err != nil {
+		return err
+	}
+
+	if err := s.aliaser.RemoveAlias(alias); err != nil {
+		return err
+	}
+	if err := httpServer.RemoveAliasesWithReadLock(s.endpoint+id.String(), s.endpoint+alias); err != nil {
+		return err
+	}
+	return s.db.Delete([]byte(alias))
+}
@@ -6,7 +6,10 @@ package admin
 import (
 	"net/http"
 
+	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
 )
 
 // GetChainAliasesArgs are the arguments for Admin.GetChainAliases API call
@@ -25,3 +28,101 @@ func (service *Admin) GetChainAliases(r *http.Request, args *GetChainAliasesArgs
 	reply.Aliases = service.chainManager.Aliases(ID)
 	return nil
 }
+
+// StartChainArgs are the arguments for calling StartChain
+type StartChainArgs struct {
+	// ID or alias of the VM the chain runs
+	VMID string `json:"vmID"`
+
+	// IDs or aliases of the feature extensions the chain runs
+	FxIDs []string `json:"fxIDs"`
+
+	// ID of the subnet that validates the chain. The default subnet if empty.
+	SubnetID ids.ID `json:"subnetID"`
+
+	GenesisData formatting.CB58 `json:"genesisData"`
+}
+
+// StartChainReply are the results from calling StartChain
+type StartChainReply struct {
+	ChainID ids.ID `json:"chainID"`
+}
+
+// StartChain creates a chain that runs the VM [args.VMID] from the genesis
+// [args.GenesisData]. The chain's ID is derived from its subnet, VM and
+// genesis. The chain isn't recorded on the platform chain, so it isn't started
+// again when the node restarts.
+func (service *Admin) StartChain(_ *http.Request, args *StartChainArgs, reply *StartChainReply) error {
+	service.log.Debug("Admin: StartChain called with %s", args.VMID)
+
+	vmID, err := service.chainManager.LookupVM(args.VMID)
+	if err != nil {
+		return err
+	}
+	subnetID := args.SubnetID
+	if subnetID.IsZero() {
+		// The ID of the default subnet
+		subnetID = ids.Empty
+	}
+
+	chainBytes := make([]byte, 0, 2*hashing.HashLen+len(args.GenesisData.Bytes))
+	chainBytes = append(chainBytes, subnetID.Bytes()...)
+	chainBytes = append(chainBytes, vmID.Bytes()...)
+	chainBytes = append(chainBytes, args.GenesisData.Bytes...)
+	chainID := ids.NewID(hashing.ComputeHash256Array(chainBytes))
+
+	if err := service.chainManager.StartChain(chains.ChainParameters{
+		ID:          chainID,
+		SubnetID:    subnetID,
+		GenesisData: args.GenesisData.Bytes,
+		VMAlias:     vmID.String(),
+		FxAliases:   args.FxIDs,
+	}); err != nil {
+		return err
+	}
+	reply.ChainID = chainID
+	return nil
+}
+
+// StopChainArgs are the arguments for calling StopChain and RestartChain
+type StopChainArgs struct {
+	// Alias or ID of the chain
+	Chain string `json:"chain"`
+}
+
+// StopChainReply are the results from calling StopChain and RestartChain
+type StopChainReply struct {
+	Success bool `json:"success"`
+}
+
+// StopChain stops the chain [args.Chain]: its VM is shut down, which flushes
+// its database, and its API endpoints are removed
+func (service *Admin) StopChain(_ *http.Request, args *StopChainArgs, reply *StopChainReply) error {
+	service.log.Debug("Admin: StopChain called with %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	if err := service.chainManager.StopChain(chainID); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
+// RestartChain stops the chain [args.Chain] and creates it again, such as to
+// recover a chain that's stuck without restarting the node
+func (service *Admin) RestartChain(_ *http.Request, args *StopChainArgs, reply *StopChainReply) error {
+	service.log.Debug("Admin: RestartChain called with %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	if err := service.chainManager.RestartChain(chainID); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
@@ -0,0 +1,87 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+)
+
+var (
+	errNotCheckpointable = errors.New("chain doesn't support checkpoints")
+)
+
+type checkpointableChain struct {
+	ctx *snow.Context
+	vm  common.CheckpointableVM
+}
+
+// Checkpoints keeps track of the chains whose state can be rolled back to a
+// checkpoint
+type Checkpoints struct {
+	lock   sync.Mutex
+	chains map[[32]byte]checkpointableChain
+}
+
+// RegisterChain implements the chains.Registrant interface
+func (c *Checkpoints) RegisterChain(ctx *snow.Context, vmIntf interface{}) {
+	vm, ok := vmIntf.(common.CheckpointableVM)
+	if !ok {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.chains == nil {
+		c.chains = make(map[[32]byte]checkpointableChain)
+	}
+	c.chains[ctx.ChainID.Key()] = checkpointableChain{
+		ctx: ctx,
+		vm:  vm,
+	}
+}
+
+// Checkpoints returns the IDs of the checkpoints that the chain [chainID] can
+// be rolled back to, oldest first
+func (c *Checkpoints) Checkpoints(chainID ids.ID) ([]ids.ID, error) {
+	chain, err := c.chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	chain.ctx.Lock.Lock()
+	defer chain.ctx.Lock.Unlock()
+
+	return chain.vm.Checkpoints(), nil
+}
+
+// Rollback sets the state of the chain [chainID] back to its state at the
+// checkpoint [checkpointID]
+func (c *Checkpoints) Rollback(chainID, checkpointID ids.ID) error {
+	chain, err := c.chain(chainID)
+	if err != nil {
+		return err
+	}
+
+	chain.ctx.Lock.Lock()
+	defer chain.ctx.Lock.Unlock()
+
+	return chain.vm.RollbackTo(checkpointID)
+}
+
+func (c *Checkpoints) chain(chainID ids.ID) (checkpointableChain, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	chain, ok := c.chains[chainID.Key()]
+	if !ok {
+		return checkpointableChain{}, errNotCheckpointable
+	}
+	return chain, nil
+}
@@ -0,0 +1,219 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/gecko/api"
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// adminEndpoint is the endpoint of the Admin API, which can't be disabled, as
+// it couldn't be enabled again
+const adminEndpoint = "admin"
+
+var (
+	errEmptyEndpoint  = errors.New("endpoint can't be empty")
+	errDisableAdmin   = errors.New("the Admin API can't be disabled")
+	errEmptyAliasName = errors.New("alias can't be empty")
+)
+
+// Endpoints are the HTTP endpoint aliases added, and the endpoints disabled,
+// at runtime through the admin API. They are persisted so that they can be
+// restored when the node restarts.
+type Endpoints struct {
+	lock       sync.Mutex
+	log        logging.Logger
+	httpServer *api.Server
+
+	// Key: alias
+	// Value: endpoint the alias refers to
+	aliases database.Database
+
+	// Key: disabled endpoint
+	// Value: nothing
+	disabled database.Database
+
+	// Endpoints the node's configuration disables. They aren't persisted,
+	// since the configuration is read again when the node restarts.
+	configured map[string]bool
+}
+
+// Initialize the endpoints, which are persisted in [db]
+func (e *Endpoints) Initialize(log logging.Logger, db database.Database, httpServer *api.Server) {
+	e.log = log
+	e.httpServer = httpServer
+	e.aliases = prefixdb.New([]byte("aliases"), db)
+	e.disabled = prefixdb.New([]byte("disabled"), db)
+}
+
+// Restore aliases and disables the endpoints that were persisted
+func (e *Endpoints) Restore() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	it := e.aliases.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		alias, endpoint := string(it.Key()), string(it.Value())
+		if err := e.httpServer.AddAliases(endpoint, alias); err != nil {
+			return err
+		}
+		e.log.Debug("restored alias %s of endpoint %s", alias, endpoint)
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	disabledIt := e.disabled.NewIterator()
+	defer disabledIt.Release()
+	for disabledIt.Next() {
+		endpoint := string(disabledIt.Key())
+		e.httpServer.DisableEndpoint(endpoint)
+		e.log.Info("endpoint %s is disabled", endpoint)
+	}
+	return disabledIt.Error()
+}
+
+// Alias gives [endpoint] the alias [alias] and persists it
+// Assumes the HTTP server's read lock is held
+func (e *Endpoints) Alias(endpoint, alias string) error {
+	endpoint, alias = normalizeEndpoint(endpoint), normalizeEndpoint(alias)
+	switch {
+	case endpoint == "":
+		return errEmptyEndpoint
+	case alias == "":
+		return errEmptyAliasName
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if err := e.httpServer.AddAliasesWithReadLock(endpoint, alias); err != nil {
+		return err
+	}
+	return e.aliases.Put([]byte(alias), []byte(endpoint))
+}
+
+// RemoveAlias removes the alias [alias], which must have been added with
+// Alias, from the endpoint it refers to
+// Assumes the HTTP server's read lock is held
+func (e *Endpoints) RemoveAlias(alias string) error {
+	alias = normalizeEndpoint(alias)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	endpoint, err := e.aliases.Get([]byte(alias))
+	if err == database.ErrNotFound {
+		return fmt.Errorf("%s wasn't added through the admin API, so it can't be removed", alias)
+	} else if err != nil {
+		return err
+	}
+	if err := e.httpServer.RemoveAliasesWithReadLock(string(endpoint), alias); err != nil {
+		return err
+	}
+	return e.aliases.Delete([]byte(alias))
+}
+
+// Disable stops serving [endpoint], and persists that it's disabled
+func (e *Endpoints) Disable(endpoint string) error {
+	endpoint = normalizeEndpoint(endpoint)
+	switch {
+	case endpoint == "":
+		return errEmptyEndpoint
+	case endpoint == adminEndpoint:
+		return errDisableAdmin
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	// If [endpoint] is an alias, the endpoint it refers to is disabled
+	disabled := e.httpServer.DisableEndpoint(endpoint)
+	if disabled == adminEndpoint {
+		e.httpServer.EnableEndpoint(disabled)
+		return errDisableAdmin
+	}
+	return e.disabled.Put([]byte(disabled), nil)
+}
+
+// Enable serves [endpoint] again, and persists that it's enabled
+func (e *Endpoints) Enable(endpoint string) error {
+	endpoint = normalizeEndpoint(endpoint)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	enabled := e.httpServer.EnableEndpoint(endpoint)
+	return e.disabled.Delete([]byte(enabled))
+}
+
+// SetConfigured disables [endpoints], which the node's configuration lists as
+// disabled. The endpoints it listed before but no longer does are enabled,
+// unless they were disabled through the admin API.
+func (e *Endpoints) SetConfigured(endpoints []string) error {
+	for _, endpoint := range endpoints {
+		if normalizeEndpoint(endpoint) == adminEndpoint {
+			return errDisableAdmin
+		}
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	// If an endpoint is an alias, the endpoint it refers to is disabled
+	configured := make(map[string]bool, len(endpoints))
+	for _, endpoint := range endpoints {
+		if endpoint = normalizeEndpoint(endpoint); endpoint == "" {
+			continue
+		}
+		disabled := e.httpServer.DisableEndpoint(endpoint)
+		if disabled == adminEndpoint {
+			e.httpServer.EnableEndpoint(disabled)
+			// Keep track of the endpoints already disabled, so the next
+			// configuration that doesn't list them enables them again
+			for endpoint := range e.configured {
+				configured[endpoint] = true
+			}
+			e.configured = configured
+			return errDisableAdmin
+		}
+		configured[disabled] = true
+	}
+	for endpoint := range e.configured {
+		if configured[endpoint] {
+			continue
+		}
+		if persisted, err := e.disabled.Has([]byte(endpoint)); err != nil {
+			return err
+		} else if !persisted {
+			e.httpServer.EnableEndpoint(endpoint)
+			e.log.Info("endpoint %s is enabled", endpoint)
+		}
+	}
+	for endpoint := range configured {
+		if !e.configured[endpoint] {
+			e.log.Info("endpoint %s is disabled", endpoint)
+		}
+	}
+	e.configured = configured
+	return nil
+}
+
+// Disabled returns the endpoints that are disabled
+func (e *Endpoints) Disabled() []string { return e.httpServer.DisabledEndpoints() }
+
+// normalizeEndpoint returns [endpoint] relative to /ext/, so "/ext/keystore",
+// "/keystore" and "keystore" are the same endpoint
+func normalizeEndpoint(endpoint string) string {
+	endpoint = strings.Trim(endpoint, "/")
+	return strings.TrimPrefix(endpoint, "ext/")
+}
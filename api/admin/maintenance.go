@@ -0,0 +1,476 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+var (
+	errNoCaches = errors.New("chain doesn't support clearing its caches")
+	errNoLevel  = errors.New("no log level was given")
+)
+
+type cachingChain struct {
+	ctx *snow.Context
+	vm  common.CachingVM
+}
+
+// Caches keeps track of the chains whose caches can be cleared
+type Caches struct {
+	lock   sync.Mutex
+	chains map[[32]byte]cachingChain
+}
+
+// RegisterChain implements the chains.Registrant interface
+func (c *Caches) RegisterChain(ctx *snow.Context, vmIntf interface{}) {
+	vm, ok := vmIntf.(common.CachingVM)
+	if !ok {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.chains == nil {
+		c.chains = make(map[[32]byte]cachingChain)
+	}
+	c.chains[ctx.ChainID.Key()] = cachingChain{
+		ctx: ctx,
+		vm:  vm,
+	}
+}
+
+// Clear the caches of the chain [chainID]
+func (c *Caches) Clear(chainID ids.ID) error {
+	c.lock.Lock()
+	chain, ok := c.chains[chainID.Key()]
+	c.lock.Unlock()
+	if !ok {
+		return errNoCaches
+	}
+
+	chain.ctx.Lock.Lock()
+	defer chain.ctx.Lock.Unlock()
+
+	chain.vm.ClearCaches()
+	return nil
+}
+
+// ClearAll clears the caches of every chain that supports it, and returns the
+// IDs of those chains
+func (c *Caches) ClearAll() []ids.ID {
+	c.lock.Lock()
+	chains := make([]cachingChain, 0, len(c.chains))
+	for _, chain := range c.chains {
+		chains = append(chains, chain)
+	}
+	c.lock.Unlock()
+
+	cleared := make([]ids.ID, len(chains))
+	for i, chain := range chains {
+		chain.ctx.Lock.Lock()
+		chain.vm.ClearCaches()
+		chain.ctx.Lock.Unlock()
+
+		cleared[i] = chain.ctx.ChainID
+	}
+	return cleared
+}
+
+// CompactDatabaseArgs are the arguments for calling CompactDatabase
+type CompactDatabaseArgs struct{}
+
+// CompactDatabaseReply are the results from calling CompactDatabase
+type CompactDatabaseReply struct {
+	Success bool `json:"success"`
+}
+
+// CompactDatabase compacts the node's whole database, which reclaims the space
+// taken by deleted and overwritten values. The node keeps running, but the
+// database may be slower until it returns.
+func (service *Admin) CompactDatabase(_ *http.Request, _ *CompactDatabaseArgs, reply *CompactDatabaseReply) error {
+	service.log.Info("Admin: CompactDatabase called")
+
+	if err := service.db.Compact(nil, nil); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
+// VerifyDatabaseArgs are the arguments for calling VerifyDatabase
+type VerifyDatabaseArgs struct{}
+
+// CorruptRange is a range of keys of the node's database whose data is corrupt
+type CorruptRange struct {
+	// Start is the last key read before the corrupt data, and Limit is the
+	// first key read after it. Either is empty at the end of the database.
+	Start formatting.CB58 `json:"start"`
+	Limit formatting.CB58 `json:"limit"`
+
+	// Prefixes are the prefixes of Start and Limit that the node's components
+	// and chains store their data under
+	Prefixes []formatting.CB58 `json:"prefixes"`
+
+	// ChainIDs are the chains whose data may be in the range
+	ChainIDs []ids.ID `json:"chainIDs"`
+}
+
+// VerifyDatabaseReply are the results from calling VerifyDatabase
+type VerifyDatabaseReply struct {
+	Corrupt []CorruptRange `json:"corrupt"`
+}
+
+// VerifyDatabase reads the node's whole database, checking its data against
+// its checksums, and returns the ranges of keys whose data is corrupt. It may
+// take as long as compacting the database.
+func (service *Admin) VerifyDatabase(_ *http.Request, _ *VerifyDatabaseArgs, reply *VerifyDatabaseReply) error {
+	service.log.Info("Admin: VerifyDatabase called")
+
+	corrupt, err := database.Verify(service.db)
+	if err != nil {
+		return err
+	}
+	reply.Corrupt = service.corruptRanges(corrupt)
+	return nil
+}
+
+// RepairDatabaseArgs are the arguments for calling RepairDatabase
+type RepairDatabaseArgs struct{}
+
+// RepairDatabaseReply are the results from calling RepairDatabase
+type RepairDatabaseReply struct {
+	// Repaired are the ranges whose corrupt data was dropped. The chains whose
+	// data may have been in them should be rebootstrapped.
+	Repaired []CorruptRange `json:"repaired"`
+
+	// Corrupt are the ranges whose data is still corrupt
+	Corrupt []CorruptRange `json:"corrupt"`
+}
+
+// RepairDatabase verifies the node's whole database, as VerifyDatabase does,
+// and drops the data that fails its checksums. The database is closed and
+// recovered, so its other operations wait for the repair to finish. The chains
+// whose data was dropped should then be rebootstrapped with RebootstrapChain.
+func (service *Admin) RepairDatabase(_ *http.Request, _ *RepairDatabaseArgs, reply *RepairDatabaseReply) error {
+	service.log.Info("Admin: RepairDatabase called")
+
+	corrupt, err := database.Verify(service.db)
+	if err != nil {
+		return err
+	}
+	if len(corrupt) > 0 {
+		if err := database.Repair(service.db); err != nil {
+			return err
+		}
+	}
+
+	remaining, err := database.Verify(service.db)
+	if err != nil {
+		return err
+	}
+	reply.Repaired = service.corruptRanges(corrupt)
+	reply.Corrupt = service.corruptRanges(remaining)
+	return nil
+}
+
+// corruptRanges returns [corrupt] along with the prefixes and chains whose data
+// may be in each range
+func (service *Admin) corruptRanges(corrupt []database.CorruptRange) []CorruptRange {
+	progress := service.chainManager.BootstrapProgress()
+	chainPrefixes := make([][][]byte, len(progress))
+	for i, chain := range progress {
+		chainPrefixes[i] = chains.DBPrefixes(chain.ChainID)
+	}
+
+	ranges := make([]CorruptRange, len(corrupt))
+	for i, keys := range corrupt {
+		startPrefix, limitPrefix := dbPrefix(keys.Start), dbPrefix(keys.Limit)
+		corruptRange := CorruptRange{
+			Start:    formatting.CB58{Bytes: keys.Start},
+			Limit:    formatting.CB58{Bytes: keys.Limit},
+			Prefixes: []formatting.CB58{},
+			ChainIDs: []ids.ID{},
+		}
+		if len(startPrefix) > 0 {
+			corruptRange.Prefixes = append(corruptRange.Prefixes, formatting.CB58{Bytes: startPrefix})
+		}
+		if len(limitPrefix) > 0 && !bytes.Equal(startPrefix, limitPrefix) {
+			corruptRange.Prefixes = append(corruptRange.Prefixes, formatting.CB58{Bytes: limitPrefix})
+		}
+		for j, prefixes := range chainPrefixes {
+			for _, prefix := range prefixes {
+				if bytes.Compare(prefix, startPrefix) >= 0 &&
+					(keys.Limit == nil || bytes.Compare(prefix, limitPrefix) <= 0) {
+					corruptRange.ChainIDs = append(corruptRange.ChainIDs, progress[j].ChainID)
+					break
+				}
+			}
+		}
+		ranges[i] = corruptRange
+		service.log.Error("database is corrupt between %s and %s", corruptRange.Start, corruptRange.Limit)
+	}
+	return ranges
+}
+
+// dbPrefix returns the prefix of [key] that a component of the node stores its
+// data under
+func dbPrefix(key []byte) []byte {
+	if len(key) > hashing.HashLen {
+		return key[:hashing.HashLen]
+	}
+	return key
+}
+
+// FlushLogsArgs are the arguments for calling FlushLogs and RotateLogs
+type FlushLogsArgs struct{}
+
+// FlushLogsReply are the results from calling FlushLogs and RotateLogs
+type FlushLogsReply struct {
+	Success bool `json:"success"`
+}
+
+// FlushLogs writes the messages logged so far by all of the node's logs to
+// their log files
+func (service *Admin) FlushLogs(_ *http.Request, _ *FlushLogsArgs, reply *FlushLogsReply) error {
+	service.log.Debug("Admin: FlushLogs called")
+
+	service.logFactory.Flush()
+	reply.Success = true
+	return nil
+}
+
+// RotateLogs starts a new log file for each of the node's logs, so that the
+// current ones can be moved or archived
+func (service *Admin) RotateLogs(_ *http.Request, _ *FlushLogsArgs, reply *FlushLogsReply) error {
+	service.log.Info("Admin: RotateLogs called")
+
+	service.logFactory.Rotate()
+	reply.Success = true
+	return nil
+}
+
+// SetLoggerLevelArgs are the arguments for calling SetLoggerLevel
+type SetLoggerLevelArgs struct {
+	// Name of the logger, as returned by GetLoggerLevels. A chain's loggers
+	// can also be named by the chain's alias.
+	LoggerName string `json:"loggerName"`
+
+	// The levels of the messages written to the log files and displayed. An
+	// empty level isn't changed.
+	LogLevel     string `json:"logLevel"`
+	DisplayLevel string `json:"displayLevel"`
+}
+
+// SetLoggerLevelReply are the results from calling SetLoggerLevel
+type SetLoggerLevelReply struct {
+	Success bool `json:"success"`
+}
+
+// SetLoggerLevel changes the levels of one of the node's loggers, and of the
+// loggers nested in it, such as the logger of a chain's API. They keep these
+// levels until the node's log levels are reloaded.
+func (service *Admin) SetLoggerLevel(_ *http.Request, args *SetLoggerLevelArgs, reply *SetLoggerLevelReply) error {
+	service.log.Info("Admin: SetLoggerLevel called with LoggerName: %s, LogLevel: %s, DisplayLevel: %s",
+		args.LoggerName, args.LogLevel, args.DisplayLevel)
+
+	if args.LogLevel == "" && args.DisplayLevel == "" {
+		return errNoLevel
+	}
+	name := service.loggerName(args.LoggerName)
+	levels, ok := service.logFactory.GetLoggerLevels()[name]
+	if !ok {
+		return fmt.Errorf("unknown logger: %s", args.LoggerName)
+	}
+	if args.LogLevel != "" {
+		level, err := logging.ToLevel(args.LogLevel)
+		if err != nil {
+			return err
+		}
+		levels.LogLevel = level
+	}
+	if args.DisplayLevel != "" {
+		level, err := logging.ToLevel(args.DisplayLevel)
+		if err != nil {
+			return err
+		}
+		levels.DisplayLevel = level
+	}
+	if err := service.logFactory.SetLoggerLevels(name, levels); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
+// GetLoggerLevelsArgs are the arguments for calling GetLoggerLevels
+type GetLoggerLevelsArgs struct {
+	// Name of the logger whose levels are returned. If empty, the levels of
+	// every logger are returned.
+	LoggerName string `json:"loggerName"`
+}
+
+// LoggerLevels are the levels of the messages that a logger writes to its log
+// files and displays
+type LoggerLevels struct {
+	LogLevel     string `json:"logLevel"`
+	DisplayLevel string `json:"displayLevel"`
+}
+
+// GetLoggerLevelsReply are the results from calling GetLoggerLevels
+type GetLoggerLevelsReply struct {
+	LoggerLevels map[string]LoggerLevels `json:"loggerLevels"`
+}
+
+// GetLoggerLevels returns the levels of the node's loggers, by name. The node
+// logs to "main", its API server logs to "http", and each chain logs to the
+// logger named its ID, and its API to the logger named its ID followed by
+// "/http".
+func (service *Admin) GetLoggerLevels(_ *http.Request, args *GetLoggerLevelsArgs, reply *GetLoggerLevelsReply) error {
+	service.log.Debug("Admin: GetLoggerLevels called with LoggerName: %s", args.LoggerName)
+
+	name := service.loggerName(args.LoggerName)
+	reply.LoggerLevels = map[string]LoggerLevels{}
+	for loggerName, levels := range service.logFactory.GetLoggerLevels() {
+		if name != "" && loggerName != name {
+			continue
+		}
+		reply.LoggerLevels[loggerName] = LoggerLevels{
+			LogLevel:     levelString(levels.LogLevel),
+			DisplayLevel: levelString(levels.DisplayLevel),
+		}
+	}
+	if name != "" && len(reply.LoggerLevels) == 0 {
+		return fmt.Errorf("unknown logger: %s", args.LoggerName)
+	}
+	return nil
+}
+
+// loggerName returns the name of the logger [name], whose first part may be
+// the alias of a chain rather than its ID
+func (service *Admin) loggerName(name string) string {
+	if _, ok := service.logFactory.GetLoggerLevels()[name]; ok || name == "" {
+		return name
+	}
+	parts := strings.SplitN(name, "/", 2)
+	chainID, err := service.chainManager.Lookup(parts[0])
+	if err != nil {
+		return name
+	}
+	parts[0] = chainID.String()
+	return strings.Join(parts, "/")
+}
+
+// levelString returns [level] as it's given to SetLoggerLevel
+func levelString(level logging.Level) string {
+	return strings.ToLower(strings.TrimSpace(level.String()))
+}
+
+// Reloader can reload the part of the node's configuration that may be
+// changed while it's running
+type Reloader interface{ ReloadConfig() error }
+
+// ReloadConfigArgs are the arguments for calling ReloadConfig
+type ReloadConfigArgs struct{}
+
+// ReloadConfigReply are the results from calling ReloadConfig
+type ReloadConfigReply struct {
+	Success bool `json:"success"`
+}
+
+// ReloadConfig rereads the log levels, API limits, connection limits, gossip
+// parameters, bootstrap peers and disabled endpoints from the command line,
+// environment variables and config file, and reloads the API server's TLS
+// certificate, as SIGHUP does. If the configuration is invalid, the node keeps
+// its current one. Consensus parameters are never reloaded.
+func (service *Admin) ReloadConfig(_ *http.Request, _ *ReloadConfigArgs, reply *ReloadConfigReply) error {
+	service.log.Info("Admin: ReloadConfig called")
+
+	if err := service.reloader.ReloadConfig(); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
+// ClearCachesArgs are the arguments for calling ClearCaches
+type ClearCachesArgs struct {
+	// Alias or ID of the chain. Every chain if empty.
+	Chain string `json:"chain"`
+}
+
+// ClearCachesReply are the results from calling ClearCaches
+type ClearCachesReply struct {
+	// Chains whose caches were cleared
+	Chains []ids.ID `json:"chains"`
+}
+
+// ClearCaches removes the entries from the in-memory caches of a chain, or of
+// every chain that supports it
+func (service *Admin) ClearCaches(_ *http.Request, args *ClearCachesArgs, reply *ClearCachesReply) error {
+	service.log.Info("Admin: ClearCaches called with Chain: %s", args.Chain)
+
+	if args.Chain == "" {
+		reply.Chains = service.caches.ClearAll()
+		return nil
+	}
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	if err := service.caches.Clear(chainID); err != nil {
+		return err
+	}
+	reply.Chains = []ids.ID{chainID}
+	return nil
+}
+
+// RebootstrapChainArgs are the arguments for calling RebootstrapChain
+type RebootstrapChainArgs struct {
+	// Alias or ID of the chain
+	Chain string `json:"chain"`
+}
+
+// RebootstrapChainReply are the results from calling RebootstrapChain
+type RebootstrapChainReply struct {
+	Success bool `json:"success"`
+}
+
+// RebootstrapChain restarts a chain that finished bootstrapping, so that it
+// bootstraps again from its beacons, such as to catch up a chain that fell
+// behind. A chain that's still bootstrapping isn't interrupted.
+func (service *Admin) RebootstrapChain(_ *http.Request, args *RebootstrapChainArgs, reply *RebootstrapChainReply) error {
+	service.log.Info("Admin: RebootstrapChain called with Chain: %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	for _, chain := range service.chainManager.BootstrapProgress() {
+		if chain.ChainID.Equals(chainID) && chain.Phase != common.Bootstrapped {
+			return fmt.Errorf("chain %s is still bootstrapping: %s", chainID, chain.Phase)
+		}
+	}
+	if err := service.chainManager.RestartChain(chainID); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
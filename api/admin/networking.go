@@ -6,14 +6,52 @@ package admin
 import (
 	"sort"
 
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/networking/peers"
+	"github.com/ava-labs/gecko/networking/throttle"
+	"github.com/ava-labs/gecko/snow/networking"
+	"github.com/ava-labs/gecko/snow/networking/latency"
 	"github.com/ava-labs/gecko/utils"
 )
 
 // Peerable can return a group of peers
 type Peerable interface{ Peers() []utils.IPDesc }
 
+// Bandwidther can return the bandwidth attributed to each chain
+type Bandwidther interface {
+	Bandwidth() []networking.ChainBandwidth
+}
+
+// Latencier can return the round trip time of requests to each validator
+type Latencier interface {
+	Latencies() []latency.PeerLatency
+}
+
+// PeerInfoer can return the metadata advertised by connected peers
+type PeerInfoer interface {
+	PeerInfo() []peers.Info
+}
+
+// TrafficTracker can return the messages and bytes exchanged with each peer
+type TrafficTracker interface {
+	PeerTraffic() []throttle.PeerTraffic
+}
+
+// Capturer can record the messages of a chain for debugging
+type Capturer interface {
+	StartCapture(chainID ids.ID) error
+	StopCapture() error
+}
+
 // Networking provides helper methods for tracking the current network state
-type Networking struct{ peers Peerable }
+type Networking struct {
+	peers     Peerable
+	bandwidth Bandwidther
+	latencies Latencier
+	peerInfo  PeerInfoer
+	capturer  Capturer
+	traffic   TrafficTracker
+}
 
 // Peers returns the current peers
 func (n *Networking) Peers() ([]string, error) {
@@ -25,3 +63,26 @@ func (n *Networking) Peers() ([]string, error) {
 	sort.Strings(ips)
 	return ips, nil
 }
+
+// Bandwidth returns the number of bytes sent and received on behalf of each
+// chain
+func (n *Networking) Bandwidth() []networking.ChainBandwidth {
+	return n.bandwidth.Bandwidth()
+}
+
+// Latencies returns the smoothed round trip time of requests to each validator
+func (n *Networking) Latencies() []latency.PeerLatency {
+	return n.latencies.Latencies()
+}
+
+// PeerInfo returns the metadata advertised by each connected peer
+func (n *Networking) PeerInfo() []peers.Info { return n.peerInfo.PeerInfo() }
+
+// PeerTraffic returns the messages and bytes exchanged with each peer
+func (n *Networking) PeerTraffic() []throttle.PeerTraffic { return n.traffic.PeerTraffic() }
+
+// StartCapture starts capturing the messages of [chainID]
+func (n *Networking) StartCapture(chainID ids.ID) error { return n.capturer.StartCapture(chainID) }
+
+// StopCapture stops capturing messages
+func (n *Networking) StopCapture() error { return n.capturer.StopCapture() }
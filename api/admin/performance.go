@@ -6,8 +6,18 @@ package admin
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"sync"
+)
+
+const (
+	defaultCPUProfile       = "cpu.profile"
+	defaultMemoryProfile    = "mem.profile"
+	defaultLockProfile      = "lock.profile"
+	defaultBlockProfile     = "block.profile"
+	defaultGoroutineProfile = "goroutine.profile"
 )
 
 var (
@@ -16,66 +26,134 @@ var (
 )
 
 // Performance provides helper methods for measuring the current performance of
-// the system
-type Performance struct{ cpuProfileFile *os.File }
+// the system. Profiles are written to files in its directory. API calls are
+// served concurrently, so the running CPU profile is guarded by a lock.
+type Performance struct {
+	dir string
+
+	lock           sync.Mutex
+	cpuProfileFile *os.File
+}
+
+// create the profile file [filename] in the profile directory, or the file
+// [defaultName] if [filename] is empty. Only the last element of [filename] is
+// used, so that profiles can't be written outside of the directory.
+func (p *Performance) create(filename, defaultName string) (*os.File, error) {
+	name := filepath.Base(filename)
+	if filename == "" || name == "." || name == ".." || name == string(filepath.Separator) {
+		name = defaultName
+	}
+	if p.dir != "" {
+		if err := os.MkdirAll(p.dir, os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+	return os.Create(filepath.Join(p.dir, name))
+}
+
+// StartCPUProfiler starts measuring the cpu utilization of this node. Returns
+// the path of the file the profile is written to.
+func (p *Performance) StartCPUProfiler(filename string) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
 
-// StartCPUProfiler starts measuring the cpu utilization of this node
-func (p *Performance) StartCPUProfiler(filename string) error {
 	if p.cpuProfileFile != nil {
-		return errCPUProfilerRunning
+		return "", errCPUProfilerRunning
 	}
 
-	file, err := os.Create(filename)
+	file, err := p.create(filename, defaultCPUProfile)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if err := pprof.StartCPUProfile(file); err != nil {
 		file.Close()
-		return err
+		return "", err
 	}
 	runtime.SetMutexProfileFraction(1)
 
 	p.cpuProfileFile = file
-	return nil
+	return file.Name(), nil
 }
 
-// StopCPUProfiler stops measuring the cpu utilization of this node
-func (p *Performance) StopCPUProfiler() error {
+// StopCPUProfiler stops measuring the cpu utilization of this node. Returns the
+// path of the file the profile was written to.
+func (p *Performance) StopCPUProfiler() (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
 	if p.cpuProfileFile == nil {
-		return errCPUProfilerNotRunning
+		return "", errCPUProfilerNotRunning
 	}
 
 	pprof.StopCPUProfile()
+	path := p.cpuProfileFile.Name()
 	err := p.cpuProfileFile.Close()
 	p.cpuProfileFile = nil
-	return err
+	return path, err
 }
 
-// MemoryProfile dumps the current memory utilization of this node
-func (p *Performance) MemoryProfile(filename string) error {
-	file, err := os.Create(filename)
+// MemoryProfile dumps the current memory utilization of this node. Returns the
+// path of the file the profile was written to.
+func (p *Performance) MemoryProfile(filename string) (string, error) {
+	file, err := p.create(filename, defaultMemoryProfile)
 	if err != nil {
-		return err
+		return "", err
 	}
 	runtime.GC() // get up-to-date statistics
 	if err := pprof.WriteHeapProfile(file); err != nil {
 		file.Close()
-		return err
+		return "", err
 	}
-	return file.Close()
+	return file.Name(), file.Close()
 }
 
 // LockProfile dumps the current lock statistics of this node
-func (p *Performance) LockProfile(filename string) error {
-	file, err := os.Create(filename)
+func (p *Performance) LockProfile(filename string) (string, error) {
+	return p.writeProfile("mutex", filename, defaultLockProfile)
+}
+
+// BlockProfile dumps where the goroutines of this node have blocked, if block
+// profiling is enabled
+func (p *Performance) BlockProfile(filename string) (string, error) {
+	return p.writeProfile("block", filename, defaultBlockProfile)
+}
+
+// GoroutineProfile dumps the stack traces of every goroutine of this node, so
+// that stalls can be diagnosed
+func (p *Performance) GoroutineProfile(filename string) (string, error) {
+	return p.writeProfile("goroutine", filename, defaultGoroutineProfile)
+}
+
+// SetMutexProfiling enables or disables recording lock contention
+func (p *Performance) SetMutexProfiling(enabled bool) {
+	if enabled {
+		runtime.SetMutexProfileFraction(1)
+	} else {
+		runtime.SetMutexProfileFraction(0)
+	}
+}
+
+// SetBlockProfiling enables or disables recording where goroutines block
+func (p *Performance) SetBlockProfiling(enabled bool) {
+	if enabled {
+		runtime.SetBlockProfileRate(1)
+	} else {
+		runtime.SetBlockProfileRate(0)
+	}
+}
+
+// writeProfile writes the profile [name] to the file [filename], or
+// [defaultName] if [filename] is empty. Returns the path of the file.
+func (p *Performance) writeProfile(name, filename, defaultName string) (string, error) {
+	file, err := p.create(filename, defaultName)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	profile := pprof.Lookup("mutex")
+	profile := pprof.Lookup(name)
 	if err := profile.WriteTo(file, 1); err != nil {
 		file.Close()
-		return err
+		return "", err
 	}
-	return file.Close()
+	return file.Name(), file.Close()
 }
@@ -5,44 +5,84 @@ package admin
 
 import (
 	"net/http"
-
-	"github.com/gorilla/rpc/v2"
+	"sort"
+	"time"
 
 	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/upgrades"
+	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms"
 
 	cjson "github.com/ava-labs/gecko/utils/json"
 )
 
 // Admin is the API service for node admin management
 type Admin struct {
-	nodeID       ids.ShortID
-	networkID    uint32
-	log          logging.Logger
-	networking   Networking
-	performance  Performance
-	chainManager chains.Manager
-	httpServer   *api.Server
-}
-
-// NewService returns a new admin API service
-func NewService(nodeID ids.ShortID, networkID uint32, log logging.Logger, chainManager chains.Manager, peers Peerable, httpServer *api.Server) *common.HTTPHandler {
-	newServer := rpc.NewServer()
+	nodeID        ids.ShortID
+	nodeVersion   string
+	networkID     uint32
+	advertisedIPs []utils.IPDesc
+	log           logging.Logger
+	logFactory    logging.Factory
+	db            database.Database
+	networking    Networking
+	performance   Performance
+	checkpoints   *Checkpoints
+	caches        *Caches
+	chainManager  chains.Manager
+	vmManager     vms.Manager
+	aliases       *Aliases
+	endpoints     *Endpoints
+	upgrades      *upgrades.Manager
+	httpServer    *api.Server
+	reloader      Reloader
+}
+
+// NewService returns a new admin API service. Profiles are written to
+// [profileDir]. [logFactory] made the node's logs, and [db] is the node's
+// database.
+func NewService(nodeID ids.ShortID, nodeVersion string, networkID uint32, advertisedIPs []utils.IPDesc, log logging.Logger, logFactory logging.Factory, db database.Database, profileDir string, chainManager chains.Manager, vmManager vms.Manager, aliases *Aliases, endpoints *Endpoints, upgradeManager *upgrades.Manager, peers Peerable, bandwidth Bandwidther, latencies Latencier, peerInfo PeerInfoer, capturer Capturer, traffic TrafficTracker, httpServer *api.Server, reloader Reloader) *common.HTTPHandler {
+	newServer := cjson.NewServer()
 	codec := cjson.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
 	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+
+	checkpoints := &Checkpoints{}
+	chainManager.AddRegistrant(checkpoints)
+	caches := &Caches{}
+	chainManager.AddRegistrant(caches)
+
 	newServer.RegisterService(&Admin{
-		nodeID:       nodeID,
-		networkID:    networkID,
-		log:          log,
-		chainManager: chainManager,
+		nodeID:        nodeID,
+		nodeVersion:   nodeVersion,
+		networkID:     networkID,
+		advertisedIPs: advertisedIPs,
+		log:           log,
+		logFactory:    logFactory,
+		db:            db,
+		performance:   Performance{dir: profileDir},
+		checkpoints:   checkpoints,
+		caches:        caches,
+		chainManager:  chainManager,
+		vmManager:     vmManager,
+		aliases:       aliases,
+		endpoints:     endpoints,
+		upgrades:      upgradeManager,
 		networking: Networking{
-			peers: peers,
+			peers:     peers,
+			bandwidth: bandwidth,
+			latencies: latencies,
+			peerInfo:  peerInfo,
+			capturer:  capturer,
+			traffic:   traffic,
 		},
 		httpServer: httpServer,
+		reloader:   reloader,
 	}, "admin")
 	return &common.HTTPHandler{Handler: newServer}
 }
@@ -63,6 +103,42 @@ func (service *Admin) GetNodeID(r *http.Request, args *GetNodeIDArgs, reply *Get
 	return nil
 }
 
+// GetNodeVersionArgs are the arguments for calling GetNodeVersion
+type GetNodeVersionArgs struct{}
+
+// GetNodeVersionReply are the results from calling GetNodeVersion
+type GetNodeVersionReply struct {
+	Version string `json:"version"`
+}
+
+// GetNodeVersion returns the version of the software this node is running
+func (service *Admin) GetNodeVersion(_ *http.Request, _ *GetNodeVersionArgs, reply *GetNodeVersionReply) error {
+	service.log.Debug("Admin: GetNodeVersion called")
+
+	reply.Version = service.nodeVersion
+	return nil
+}
+
+// GetNodeIPArgs are the arguments for calling GetNodeIP
+type GetNodeIPArgs struct{}
+
+// GetNodeIPReply are the results from calling GetNodeIP
+type GetNodeIPReply struct {
+	IPs []string `json:"ips"`
+}
+
+// GetNodeIP returns the addresses this node advertises to its peers as
+// accepting staking connections
+func (service *Admin) GetNodeIP(_ *http.Request, _ *GetNodeIPArgs, reply *GetNodeIPReply) error {
+	service.log.Debug("Admin: GetNodeIP called")
+
+	reply.IPs = make([]string, len(service.advertisedIPs))
+	for i, ip := range service.advertisedIPs {
+		reply.IPs[i] = ip.String()
+	}
+	return nil
+}
+
 // GetNetworkIDArgs are the arguments for calling GetNetworkID
 type GetNetworkIDArgs struct{}
 
@@ -98,6 +174,45 @@ func (service *Admin) GetBlockchainID(r *http.Request, args *GetBlockchainIDArgs
 	return err
 }
 
+// GetChainsArgs are the arguments for calling GetChains
+type GetChainsArgs struct{}
+
+// ChainInfo describes a chain this node is running
+type ChainInfo struct {
+	ChainID      ids.ID   `json:"chainID"`
+	Aliases      []string `json:"aliases"`
+	SubnetID     ids.ID   `json:"subnetID"`
+	Phase        string   `json:"phase"`
+	Bootstrapped bool     `json:"bootstrapped"`
+}
+
+// GetChainsReply are the results from calling GetChains
+type GetChainsReply struct {
+	Chains []ChainInfo `json:"chains"`
+}
+
+// GetChains returns the chains this node is running, sorted by ID, and whether
+// each has finished bootstrapping
+func (service *Admin) GetChains(_ *http.Request, _ *GetChainsArgs, reply *GetChainsReply) error {
+	service.log.Debug("Admin: GetChains called")
+
+	reply.Chains = []ChainInfo{}
+	for _, chain := range service.chainManager.BootstrapProgress() {
+		subnetID, _ := service.chainManager.SubnetID(chain.ChainID)
+		reply.Chains = append(reply.Chains, ChainInfo{
+			ChainID:      chain.ChainID,
+			Aliases:      service.chainManager.Aliases(chain.ChainID),
+			SubnetID:     subnetID,
+			Phase:        chain.Phase.String(),
+			Bootstrapped: chain.Phase == common.Bootstrapped,
+		})
+	}
+	sort.Slice(reply.Chains, func(i, j int) bool {
+		return reply.Chains[i].ChainID.String() < reply.Chains[j].ChainID.String()
+	})
+	return nil
+}
+
 // PeersArgs are the arguments for calling Peers
 type PeersArgs struct{}
 
@@ -115,6 +230,235 @@ func (service *Admin) Peers(r *http.Request, args *PeersArgs, reply *PeersReply)
 	return err
 }
 
+// PeersInfoArgs are the arguments for calling PeersInfo
+type PeersInfoArgs struct{}
+
+// PeerInfo is the metadata a connected peer advertised about itself
+type PeerInfo struct {
+	NodeID         ids.ShortID  `json:"nodeID"`
+	IP             string       `json:"ip"`
+	NodeVersion    string       `json:"nodeVersion"`
+	Uptime         cjson.Uint64 `json:"uptime"`
+	TrackedSubnets []ids.ID     `json:"trackedSubnets"`
+	Capabilities   []string     `json:"capabilities"`
+	AdvertisedIPs  []string     `json:"advertisedIPs"`
+}
+
+// PeersInfoReply are the results from calling PeersInfo
+type PeersInfoReply struct {
+	Peers []PeerInfo `json:"peers"`
+}
+
+// PeersInfo returns the version, uptime in seconds, tracked subnets,
+// capabilities and addresses advertised by each connected peer
+func (service *Admin) PeersInfo(_ *http.Request, _ *PeersInfoArgs, reply *PeersInfoReply) error {
+	service.log.Debug("Admin: PeersInfo called")
+
+	peers := service.networking.PeerInfo()
+	reply.Peers = make([]PeerInfo, len(peers))
+	for i, peer := range peers {
+		reply.Peers[i] = PeerInfo{
+			NodeID:         peer.NodeID,
+			IP:             peer.IP.String(),
+			NodeVersion:    peer.NodeVersion,
+			Uptime:         cjson.Uint64(peer.Uptime / time.Second),
+			TrackedSubnets: peer.TrackedSubnets,
+			Capabilities:   peer.Capabilities,
+		}
+		for _, ip := range peer.IPs {
+			reply.Peers[i].AdvertisedIPs = append(reply.Peers[i].AdvertisedIPs, ip.String())
+		}
+	}
+	return nil
+}
+
+// ChainBandwidthArgs are the arguments for calling ChainBandwidth
+type ChainBandwidthArgs struct{}
+
+// ChainBandwidth is the number of bytes sent and received on behalf of a chain
+type ChainBandwidth struct {
+	ChainID       ids.ID       `json:"chainID"`
+	Aliases       []string     `json:"aliases"`
+	BytesSent     cjson.Uint64 `json:"bytesSent"`
+	BytesReceived cjson.Uint64 `json:"bytesReceived"`
+}
+
+// ChainBandwidthReply are the results from calling ChainBandwidth
+type ChainBandwidthReply struct {
+	Chains []ChainBandwidth `json:"chains"`
+}
+
+// ChainBandwidth returns the number of consensus message bytes that this node
+// has sent and received on behalf of each chain
+func (service *Admin) ChainBandwidth(_ *http.Request, _ *ChainBandwidthArgs, reply *ChainBandwidthReply) error {
+	service.log.Debug("Admin: ChainBandwidth called")
+
+	bandwidth := service.networking.Bandwidth()
+	reply.Chains = make([]ChainBandwidth, len(bandwidth))
+	for i, chain := range bandwidth {
+		reply.Chains[i] = ChainBandwidth{
+			ChainID:       chain.ChainID,
+			Aliases:       service.chainManager.Aliases(chain.ChainID),
+			BytesSent:     cjson.Uint64(chain.BytesSent),
+			BytesReceived: cjson.Uint64(chain.BytesReceived),
+		}
+	}
+	return nil
+}
+
+// PeerTrafficArgs are the arguments for calling PeerTraffic
+type PeerTrafficArgs struct{}
+
+// PeerTraffic is the number of messages and bytes exchanged with a peer
+type PeerTraffic struct {
+	NodeID         ids.ShortID  `json:"nodeID"`
+	MsgsSent       cjson.Uint64 `json:"msgsSent"`
+	MsgsReceived   cjson.Uint64 `json:"msgsReceived"`
+	MsgsThrottled  cjson.Uint64 `json:"msgsThrottled"`
+	BytesSent      cjson.Uint64 `json:"bytesSent"`
+	BytesReceived  cjson.Uint64 `json:"bytesReceived"`
+	BytesThrottled cjson.Uint64 `json:"bytesThrottled"`
+}
+
+// PeerTrafficReply are the results from calling PeerTraffic
+type PeerTrafficReply struct {
+	Peers []PeerTraffic `json:"peers"`
+}
+
+// PeerTraffic returns the number of consensus messages and bytes that this
+// node has sent to and received from each peer recently. Throttled messages
+// were dropped because the peer exceeded its inbound limits.
+func (service *Admin) PeerTraffic(_ *http.Request, _ *PeerTrafficArgs, reply *PeerTrafficReply) error {
+	service.log.Debug("Admin: PeerTraffic called")
+
+	traffic := service.networking.PeerTraffic()
+	reply.Peers = make([]PeerTraffic, len(traffic))
+	for i, peer := range traffic {
+		reply.Peers[i] = PeerTraffic{
+			NodeID:         peer.NodeID,
+			MsgsSent:       cjson.Uint64(peer.MsgsSent),
+			MsgsReceived:   cjson.Uint64(peer.MsgsReceived),
+			MsgsThrottled:  cjson.Uint64(peer.MsgsThrottled),
+			BytesSent:      cjson.Uint64(peer.BytesSent),
+			BytesReceived:  cjson.Uint64(peer.BytesReceived),
+			BytesThrottled: cjson.Uint64(peer.BytesThrottled),
+		}
+	}
+	return nil
+}
+
+// PeerLatenciesArgs are the arguments for calling PeerLatencies
+type PeerLatenciesArgs struct{}
+
+// PeerLatency is the smoothed round trip time of requests to a validator
+type PeerLatency struct {
+	NodeID ids.ShortID `json:"nodeID"`
+	// Latency and Deviation are in nanoseconds
+	Latency   cjson.Uint64 `json:"latency"`
+	Deviation cjson.Uint64 `json:"deviation"`
+	Samples   cjson.Uint64 `json:"samples"`
+}
+
+// PeerLatenciesReply are the results from calling PeerLatencies
+type PeerLatenciesReply struct {
+	Peers []PeerLatency `json:"peers"`
+}
+
+// PeerLatencies returns the smoothed round trip time of the requests this node
+// has sent to each validator, and how much it varies
+func (service *Admin) PeerLatencies(_ *http.Request, _ *PeerLatenciesArgs, reply *PeerLatenciesReply) error {
+	service.log.Debug("Admin: PeerLatencies called")
+
+	latencies := service.networking.Latencies()
+	reply.Peers = make([]PeerLatency, len(latencies))
+	for i, peer := range latencies {
+		reply.Peers[i] = PeerLatency{
+			NodeID:    peer.ValidatorID,
+			Latency:   cjson.Uint64(peer.Latency),
+			Deviation: cjson.Uint64(peer.Deviation),
+			Samples:   cjson.Uint64(peer.Samples),
+		}
+	}
+	return nil
+}
+
+// GetBootstrapProgressArgs are the arguments for calling GetBootstrapProgress
+type GetBootstrapProgressArgs struct {
+	// Alias or ID of the chain to return the progress of. If empty, the
+	// progress of every chain is returned.
+	Chain string `json:"chain"`
+}
+
+// ChainBootstrapProgress is the progress of bootstrapping a chain
+type ChainBootstrapProgress struct {
+	ChainID      ids.ID   `json:"chainID"`
+	Aliases      []string `json:"aliases"`
+	Phase        string   `json:"phase"`
+	Bootstrapped bool     `json:"bootstrapped"`
+
+	// Containers fetched, and the containers known to be needed so far
+	Fetched  cjson.Uint64 `json:"fetched"`
+	Expected cjson.Uint64 `json:"expected"`
+
+	// Operations executed, and the operations to execute
+	Executed  cjson.Uint64 `json:"executed"`
+	ToExecute cjson.Uint64 `json:"toExecute"`
+
+	// Unix times. 0 if unknown.
+	StartTime           cjson.Uint64 `json:"startTime"`
+	LastProgressTime    cjson.Uint64 `json:"lastProgressTime"`
+	EstimatedCompletion cjson.Uint64 `json:"estimatedCompletion"`
+}
+
+// GetBootstrapProgressReply are the results from calling GetBootstrapProgress
+type GetBootstrapProgressReply struct {
+	Chains []ChainBootstrapProgress `json:"chains"`
+}
+
+// GetBootstrapProgress returns how far each chain is through bootstrapping. A
+// chain whose last progress was long ago may be stuck.
+func (service *Admin) GetBootstrapProgress(_ *http.Request, args *GetBootstrapProgressArgs, reply *GetBootstrapProgressReply) error {
+	service.log.Debug("Admin: GetBootstrapProgress called with %s", args.Chain)
+
+	chainID := ids.ID{}
+	if args.Chain != "" {
+		var err error
+		chainID, err = service.chainManager.Lookup(args.Chain)
+		if err != nil {
+			return err
+		}
+	}
+
+	reply.Chains = []ChainBootstrapProgress{}
+	for _, chain := range service.chainManager.BootstrapProgress() {
+		if !chainID.IsZero() && !chainID.Equals(chain.ChainID) {
+			continue
+		}
+		reply.Chains = append(reply.Chains, ChainBootstrapProgress{
+			ChainID:             chain.ChainID,
+			Aliases:             service.chainManager.Aliases(chain.ChainID),
+			Phase:               chain.Phase.String(),
+			Bootstrapped:        chain.Phase == common.Bootstrapped,
+			Fetched:             cjson.Uint64(chain.Fetched),
+			Expected:            cjson.Uint64(chain.Expected),
+			Executed:            cjson.Uint64(chain.Executed),
+			ToExecute:           cjson.Uint64(chain.ToExecute),
+			StartTime:           unixTime(chain.Started),
+			LastProgressTime:    unixTime(chain.LastProgress),
+			EstimatedCompletion: unixTime(chain.EstimatedCompletion),
+		})
+	}
+	return nil
+}
+
+// unixTime returns [t] as a Unix time, or 0 if [t] is the zero time
+func unixTime(t time.Time) cjson.Uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return cjson.Uint64(t.Unix())
+}
+
 // StartCPUProfilerArgs are the arguments for calling StartCPUProfiler
 type StartCPUProfilerArgs struct {
 	Filename string `json:"filename"`
@@ -123,13 +467,21 @@ type StartCPUProfilerArgs struct {
 // StartCPUProfilerReply are the results from calling StartCPUProfiler
 type StartCPUProfilerReply struct {
 	Success bool `json:"success"`
+	// Path of the file the profile is written to
+	Path string `json:"path"`
 }
 
-// StartCPUProfiler starts a cpu profile writing to the specified file
+// StartCPUProfiler starts a cpu profile writing to the specified file in the
+// profile directory
 func (service *Admin) StartCPUProfiler(r *http.Request, args *StartCPUProfilerArgs, reply *StartCPUProfilerReply) error {
 	service.log.Debug("Admin: StartCPUProfiler called with %s", args.Filename)
+	path, err := service.performance.StartCPUProfiler(args.Filename)
+	if err != nil {
+		return err
+	}
 	reply.Success = true
-	return service.performance.StartCPUProfiler(args.Filename)
+	reply.Path = path
+	return nil
 }
 
 // StopCPUProfilerArgs are the arguments for calling StopCPUProfiler
@@ -138,13 +490,20 @@ type StopCPUProfilerArgs struct{}
 // StopCPUProfilerReply are the results from calling StopCPUProfiler
 type StopCPUProfilerReply struct {
 	Success bool `json:"success"`
+	// Path of the file the profile is written to
+	Path string `json:"path"`
 }
 
 // StopCPUProfiler stops the cpu profile
 func (service *Admin) StopCPUProfiler(r *http.Request, args *StopCPUProfilerArgs, reply *StopCPUProfilerReply) error {
 	service.log.Debug("Admin: StopCPUProfiler called")
+	path, err := service.performance.StopCPUProfiler()
+	if err != nil {
+		return err
+	}
 	reply.Success = true
-	return service.performance.StopCPUProfiler()
+	reply.Path = path
+	return nil
 }
 
 // MemoryProfileArgs are the arguments for calling MemoryProfile
@@ -155,13 +514,21 @@ type MemoryProfileArgs struct {
 // MemoryProfileReply are the results from calling MemoryProfile
 type MemoryProfileReply struct {
 	Success bool `json:"success"`
+	// Path of the file the profile is written to
+	Path string `json:"path"`
 }
 
-// MemoryProfile runs a memory profile writing to the specified file
+// MemoryProfile runs a memory profile writing to the specified file in the
+// profile directory
 func (service *Admin) MemoryProfile(r *http.Request, args *MemoryProfileArgs, reply *MemoryProfileReply) error {
 	service.log.Debug("Admin: MemoryProfile called with %s", args.Filename)
+	path, err := service.performance.MemoryProfile(args.Filename)
+	if err != nil {
+		return err
+	}
 	reply.Success = true
-	return service.performance.MemoryProfile(args.Filename)
+	reply.Path = path
+	return nil
 }
 
 // LockProfileArgs are the arguments for calling LockProfile
@@ -172,13 +539,152 @@ type LockProfileArgs struct {
 // LockProfileReply are the results from calling LockProfile
 type LockProfileReply struct {
 	Success bool `json:"success"`
+	// Path of the file the profile is written to
+	Path string `json:"path"`
 }
 
-// LockProfile runs a mutex profile writing to the specified file
+// LockProfile runs a mutex profile writing to the specified file in the
+// profile directory
 func (service *Admin) LockProfile(r *http.Request, args *LockProfileArgs, reply *LockProfileReply) error {
 	service.log.Debug("Admin: LockProfile called with %s", args.Filename)
+	path, err := service.performance.LockProfile(args.Filename)
+	if err != nil {
+		return err
+	}
+	reply.Success = true
+	reply.Path = path
+	return nil
+}
+
+// BlockProfileArgs are the arguments for calling BlockProfile
+type BlockProfileArgs struct {
+	Filename string `json:"filename"`
+}
+
+// BlockProfileReply are the results from calling BlockProfile
+type BlockProfileReply struct {
+	Success bool `json:"success"`
+	// Path of the file the profile is written to
+	Path string `json:"path"`
+}
+
+// BlockProfile runs a block profile writing to the specified file in the
+// profile directory. Block profiling must have been enabled by
+// SetBlockProfiling.
+func (service *Admin) BlockProfile(_ *http.Request, args *BlockProfileArgs, reply *BlockProfileReply) error {
+	service.log.Debug("Admin: BlockProfile called with %s", args.Filename)
+	path, err := service.performance.BlockProfile(args.Filename)
+	if err != nil {
+		return err
+	}
 	reply.Success = true
-	return service.performance.LockProfile(args.Filename)
+	reply.Path = path
+	return nil
+}
+
+// GoroutineProfileArgs are the arguments for calling GoroutineProfile
+type GoroutineProfileArgs struct {
+	Filename string `json:"filename"`
+}
+
+// GoroutineProfileReply are the results from calling GoroutineProfile
+type GoroutineProfileReply struct {
+	Success bool `json:"success"`
+	// Path of the file the profile is written to
+	Path string `json:"path"`
+}
+
+// GoroutineProfile writes the stack traces of every goroutine to the specified
+// file in the profile directory
+func (service *Admin) GoroutineProfile(_ *http.Request, args *GoroutineProfileArgs, reply *GoroutineProfileReply) error {
+	service.log.Debug("Admin: GoroutineProfile called with %s", args.Filename)
+	path, err := service.performance.GoroutineProfile(args.Filename)
+	if err != nil {
+		return err
+	}
+	reply.Success = true
+	reply.Path = path
+	return nil
+}
+
+// SetProfilingArgs are the arguments for calling SetMutexProfiling and
+// SetBlockProfiling
+type SetProfilingArgs struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetProfilingReply are the results from calling SetMutexProfiling and
+// SetBlockProfiling
+type SetProfilingReply struct {
+	Success bool `json:"success"`
+}
+
+// SetMutexProfiling enables or disables recording lock contention, which
+// LockProfile writes
+func (service *Admin) SetMutexProfiling(_ *http.Request, args *SetProfilingArgs, reply *SetProfilingReply) error {
+	service.log.Debug("Admin: SetMutexProfiling called with %t", args.Enabled)
+	service.performance.SetMutexProfiling(args.Enabled)
+	reply.Success = true
+	return nil
+}
+
+// SetBlockProfiling enables or disables recording where goroutines block,
+// which BlockProfile writes. Recording slows the node down.
+func (service *Admin) SetBlockProfiling(_ *http.Request, args *SetProfilingArgs, reply *SetProfilingReply) error {
+	service.log.Debug("Admin: SetBlockProfiling called with %t", args.Enabled)
+	service.performance.SetBlockProfiling(args.Enabled)
+	reply.Success = true
+	return nil
+}
+
+// GetUpgradesArgs are the arguments for calling GetUpgrades
+type GetUpgradesArgs struct{}
+
+// FormattedUpgrade describes an upgrade
+type FormattedUpgrade struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// RFC3339 time the upgrade activates at, if it activates at a time
+	Time string `json:"time,omitempty"`
+	// Height the upgrade activates at, if it activates at a height
+	Height cjson.Uint64 `json:"height,omitempty"`
+	// True if the upgrade is scheduled to activate
+	Scheduled bool `json:"scheduled"`
+	// True if a chain running on this node implements the upgrade
+	Recognized bool `json:"recognized"`
+	// True if the upgrade has been observed to be active
+	Active bool `json:"active"`
+}
+
+// GetUpgradesReply are the results from calling GetUpgrades
+type GetUpgradesReply struct {
+	Upgrades []FormattedUpgrade `json:"upgrades"`
+}
+
+// GetUpgrades returns the upgrades that the chains running on this node
+// recognize or that are scheduled to activate
+func (service *Admin) GetUpgrades(_ *http.Request, _ *GetUpgradesArgs, reply *GetUpgradesReply) error {
+	service.log.Debug("Admin: GetUpgrades called")
+
+	reply.Upgrades = []FormattedUpgrade{}
+	for _, status := range service.upgrades.Statuses() {
+		upgrade := FormattedUpgrade{
+			Name:        status.Name,
+			Description: status.Description,
+			Scheduled:   status.Scheduled,
+			Recognized:  status.Recognized,
+			Active:      status.Active,
+		}
+		if status.Scheduled {
+			if activation := status.Activation; !activation.Time.IsZero() {
+				upgrade.Time = activation.Time.UTC().Format(time.RFC3339)
+			} else {
+				upgrade.Height = cjson.Uint64(activation.Height)
+			}
+		}
+		reply.Upgrades = append(reply.Upgrades, upgrade)
+	}
+	return nil
 }
 
 // AliasArgs are the arguments for calling Alias
@@ -192,11 +698,95 @@ type AliasReply struct {
 	Success bool `json:"success"`
 }
 
-// Alias attempts to alias an HTTP endpoint to a new name
+// Alias attempts to alias an HTTP endpoint to a new name. The alias is
+// persisted, so it outlives restarts of the node.
 func (service *Admin) Alias(r *http.Request, args *AliasArgs, reply *AliasReply) error {
 	service.log.Debug("Admin: Alias called with URL: %s, Alias: %s", args.Endpoint, args.Alias)
+
+	if err := service.endpoints.Alias(args.Endpoint, args.Alias); err != nil {
+		return err
+	}
+
 	reply.Success = true
-	return service.httpServer.AddAliasesWithReadLock(args.Endpoint, args.Alias)
+	return nil
+}
+
+// RemoveAliasArgs are the arguments for calling RemoveAlias
+type RemoveAliasArgs struct {
+	Alias string `json:"alias"`
+}
+
+// RemoveAliasReply are the results from calling RemoveAlias
+type RemoveAliasReply struct {
+	Success bool `json:"success"`
+}
+
+// RemoveAlias removes an alias that was given to an HTTP endpoint with Alias
+func (service *Admin) RemoveAlias(_ *http.Request, args *RemoveAliasArgs, reply *RemoveAliasReply) error {
+	service.log.Debug("Admin: RemoveAlias called with Alias: %s", args.Alias)
+
+	if err := service.endpoints.RemoveAlias(args.Alias); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// EndpointArgs are the arguments for calling DisableEndpoint and
+// EnableEndpoint
+type EndpointArgs struct {
+	// Endpoint such as "keystore" or "bc/X"
+	Endpoint string `json:"endpoint"`
+}
+
+// EndpointReply are the results from calling DisableEndpoint and
+// EnableEndpoint
+type EndpointReply struct {
+	Success bool `json:"success"`
+}
+
+// DisableEndpoint stops serving an HTTP endpoint and its aliases, such as the
+// Keystore API of a public node. The endpoint stays disabled across restarts
+// of the node until it's enabled. The Admin API can't be disabled.
+func (service *Admin) DisableEndpoint(_ *http.Request, args *EndpointArgs, reply *EndpointReply) error {
+	service.log.Debug("Admin: DisableEndpoint called with Endpoint: %s", args.Endpoint)
+
+	if err := service.endpoints.Disable(args.Endpoint); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// EnableEndpoint serves an HTTP endpoint that was disabled with
+// DisableEndpoint again
+func (service *Admin) EnableEndpoint(_ *http.Request, args *EndpointArgs, reply *EndpointReply) error {
+	service.log.Debug("Admin: EnableEndpoint called with Endpoint: %s", args.Endpoint)
+
+	if err := service.endpoints.Enable(args.Endpoint); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// GetDisabledEndpointsArgs are the arguments for calling GetDisabledEndpoints
+type GetDisabledEndpointsArgs struct{}
+
+// GetDisabledEndpointsReply are the results from calling GetDisabledEndpoints
+type GetDisabledEndpointsReply struct {
+	Endpoints []string `json:"endpoints"`
+}
+
+// GetDisabledEndpoints returns the HTTP endpoints that are disabled
+func (service *Admin) GetDisabledEndpoints(_ *http.Request, _ *GetDisabledEndpointsArgs, reply *GetDisabledEndpointsReply) error {
+	service.log.Debug("Admin: GetDisabledEndpoints called")
+
+	reply.Endpoints = service.endpoints.Disabled()
+	return nil
 }
 
 // AliasChainArgs are the arguments for calling AliasChain
@@ -210,7 +800,8 @@ type AliasChainReply struct {
 	Success bool `json:"success"`
 }
 
-// AliasChain attempts to alias a chain to a new name
+// AliasChain attempts to alias a chain to a new name. The alias is persisted,
+// so it outlives restarts of the node.
 func (service *Admin) AliasChain(_ *http.Request, args *AliasChainArgs, reply *AliasChainReply) error {
 	service.log.Debug("Admin: AliasChain called with Chain: %s, Alias: %s", args.Chain, args.Alias)
 
@@ -219,10 +810,176 @@ func (service *Admin) AliasChain(_ *http.Request, args *AliasChainArgs, reply *A
 		return err
 	}
 
-	if err := service.chainManager.Alias(chainID, args.Alias); err != nil {
+	if err := service.aliases.AliasChain(chainID, args.Alias); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// RemoveChainAliasArgs are the arguments for calling RemoveChainAlias
+type RemoveChainAliasArgs struct {
+	Alias string `json:"alias"`
+}
+
+// RemoveChainAliasReply are the results from calling RemoveChainAlias
+type RemoveChainAliasReply struct {
+	Success bool `json:"success"`
+}
+
+// RemoveChainAlias removes an alias that was given to a chain with AliasChain
+func (service *Admin) RemoveChainAlias(_ *http.Request, args *RemoveChainAliasArgs, reply *RemoveChainAliasReply) error {
+	service.log.Debug("Admin: RemoveChainAlias called with Alias: %s", args.Alias)
+
+	if err := service.aliases.RemoveChainAlias(args.Alias); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// AliasVMArgs are the arguments for calling AliasVM
+type AliasVMArgs struct {
+	VM    string `json:"vm"`
+	Alias string `json:"alias"`
+}
+
+// AliasVMReply are the results from calling AliasVM
+type AliasVMReply struct {
+	Success bool `json:"success"`
+}
+
+// AliasVM attempts to alias a VM to a new name
+func (service *Admin) AliasVM(_ *http.Request, args *AliasVMArgs, reply *AliasVMReply) error {
+	service.log.Debug("Admin: AliasVM called with VM: %s, Alias: %s", args.VM, args.Alias)
+
+	vmID, err := service.vmManager.Lookup(args.VM)
+	if err != nil {
+		return err
+	}
+
+	if err := service.aliases.AliasVM(vmID, args.Alias); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// RemoveVMAliasArgs are the arguments for calling RemoveVMAlias
+type RemoveVMAliasArgs struct {
+	Alias string `json:"alias"`
+}
+
+// RemoveVMAliasReply are the results from calling RemoveVMAlias
+type RemoveVMAliasReply struct {
+	Success bool `json:"success"`
+}
+
+// RemoveVMAlias removes an alias that was given to a VM with AliasVM
+func (service *Admin) RemoveVMAlias(_ *http.Request, args *RemoveVMAliasArgs, reply *RemoveVMAliasReply) error {
+	service.log.Debug("Admin: RemoveVMAlias called with Alias: %s", args.Alias)
+
+	if err := service.aliases.RemoveVMAlias(args.Alias); err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return nil
+}
+
+// StartCaptureArgs are the arguments for calling StartCapture
+type StartCaptureArgs struct {
+	Chain string `json:"chain"`
+}
+
+// StartCaptureReply are the results from calling StartCapture
+type StartCaptureReply struct {
+	Success bool `json:"success"`
+}
+
+// StartCapture starts writing the messages sent and received on behalf of a
+// chain to the capture files
+func (service *Admin) StartCapture(_ *http.Request, args *StartCaptureArgs, reply *StartCaptureReply) error {
+	service.log.Debug("Admin: StartCapture called with Chain: %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+
+	reply.Success = true
+	return service.networking.StartCapture(chainID)
+}
+
+// StopCaptureArgs are the arguments for calling StopCapture
+type StopCaptureArgs struct{}
+
+// StopCaptureReply are the results from calling StopCapture
+type StopCaptureReply struct {
+	Success bool `json:"success"`
+}
+
+// StopCapture stops writing messages to the capture files
+func (service *Admin) StopCapture(_ *http.Request, _ *StopCaptureArgs, reply *StopCaptureReply) error {
+	service.log.Debug("Admin: StopCapture called")
+
+	reply.Success = true
+	return service.networking.StopCapture()
+}
+
+// GetCheckpointsArgs are the arguments for calling GetCheckpoints
+type GetCheckpointsArgs struct {
+	Chain string `json:"chain"`
+}
+
+// GetCheckpointsReply are the results from calling GetCheckpoints
+type GetCheckpointsReply struct {
+	Checkpoints []ids.ID `json:"checkpoints"`
+}
+
+// GetCheckpoints returns the IDs of the checkpoints that a chain can be rolled
+// back to, oldest first
+func (service *Admin) GetCheckpoints(_ *http.Request, args *GetCheckpointsArgs, reply *GetCheckpointsReply) error {
+	service.log.Debug("Admin: GetCheckpoints called with Chain: %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+
+	reply.Checkpoints, err = service.checkpoints.Checkpoints(chainID)
+	return err
+}
+
+// RollbackChainArgs are the arguments for calling RollbackChain
+type RollbackChainArgs struct {
+	Chain        string `json:"chain"`
+	CheckpointID ids.ID `json:"checkpointID"`
+}
+
+// RollbackChainReply are the results from calling RollbackChain
+type RollbackChainReply struct {
+	Success bool `json:"success"`
+}
+
+// RollbackChain sets the state of a chain back to its state at one of its
+// checkpoints. The node should be restarted afterwards, so that the chain
+// bootstraps the containers accepted after the checkpoint again.
+func (service *Admin) RollbackChain(_ *http.Request, args *RollbackChainArgs, reply *RollbackChainReply) error {
+	service.log.Info("Admin: RollbackChain called with Chain: %s, CheckpointID: %s", args.Chain, args.CheckpointID)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+
+	if err := service.checkpoints.Rollback(chainID, args.CheckpointID); err != nil {
 		return err
 	}
 
 	reply.Success = true
-	return service.httpServer.AddAliasesWithReadLock("bc/"+chainID.String(), "bc/"+args.Alias)
+	return nil
 }
@@ -0,0 +1,269 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	// Endpoints are the paths of API requests after this prefix
+	baseURL = "/ext/"
+
+	// Endpoint of the Auth API, which is authorized by password instead of
+	// by token
+	authEndpoint = "auth"
+
+	// AllEndpoints is the endpoint scope that authorizes every endpoint
+	AllEndpoints = "*"
+
+	// Number of random bytes in a token
+	tokenLen = 32
+
+	headerPrefix = "Bearer "
+)
+
+var (
+	errWrongPassword = errors.New("incorrect password")
+	errNoEndpoints   = errors.New("a token must be scoped to at least one endpoint")
+	errNoToken       = errors.New("this endpoint requires an authorization token")
+	errUnknownToken  = errors.New("unknown or revoked token")
+	errWrongScope    = errors.New("token isn't authorized for this endpoint")
+)
+
+// Auth issues revocable bearer tokens that are each scoped to a set of API
+// endpoints, and authorizes requests to the API by them. Requests to public
+// endpoints, and to the Auth API itself, don't need a token. If endpoints are
+// protected, only requests to them need a token.
+type Auth struct {
+	lock sync.RWMutex
+	log  logging.Logger
+
+	// Hash of the password that must be given to issue and revoke tokens
+	password [32]byte
+
+	// Endpoints that don't need a token, such as "health"
+	public []string
+
+	// Endpoints that need a token, such as "keystore". If empty, every
+	// endpoint that isn't public does.
+	protected []string
+
+	// Key: Hash of the token
+	// Value: Endpoints the token authorizes
+	tokens map[[32]byte][]string
+
+	// Returns the endpoint that an endpoint is an alias of, so that requests
+	// to an alias are authorized the same as requests to the endpoint. If
+	// nil, endpoints have no aliases.
+	canonical func(endpoint string) string
+
+	// Persists the tokens, keyed by their hash, so they survive restarts
+	db database.Database
+}
+
+// Initialize the auth service. Tokens are issued with [password], and requests
+// to the endpoints in [public] don't need a token. If [protected] isn't empty,
+// only requests to the endpoints in it, and under them, need a token.
+func (a *Auth) Initialize(log logging.Logger, db database.Database, password string, public, protected []string) error {
+	a.log = log
+	a.db = db
+	a.password = hashing.ComputeHash256Array([]byte(password))
+	a.tokens = make(map[[32]byte][]string)
+	for _, endpoint := range public {
+		a.public = append(a.public, normalize(endpoint))
+	}
+	for _, endpoint := range protected {
+		a.protected = append(a.protected, normalize(endpoint))
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		tokenHash := [32]byte{}
+		copy(tokenHash[:], it.Key())
+		endpoints := []string(nil)
+		if err := json.Unmarshal(it.Value(), &endpoints); err != nil {
+			return err
+		}
+		a.tokens[tokenHash] = endpoints
+	}
+	return it.Error()
+}
+
+// SetCanonical authorizes requests to an alias of an endpoint, such as
+// "bc/X", as if they were made to the endpoint, by resolving endpoints with
+// [canonical]. Endpoints are resolved whenever requests are authorized, so
+// aliases may change while they're served.
+func (a *Auth) SetCanonical(canonical func(endpoint string) string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.canonical = canonical
+}
+
+// NewToken returns a token that authorizes requests to [endpoints], if
+// [password] is correct. An endpoint authorizes requests to it and to the
+// endpoints under it, so "bc/X" authorizes "bc/X/wallet". AllEndpoints
+// authorizes every endpoint.
+func (a *Auth) NewToken(password string, endpoints []string) (string, error) {
+	if len(endpoints) == 0 {
+		return "", errNoEndpoints
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.checkPassword(password) {
+		return "", errWrongPassword
+	}
+
+	tokenBytes := make([]byte, tokenLen)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	token := formatting.CB58{Bytes: tokenBytes}.String()
+
+	scope := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		scope[i] = normalize(endpoint)
+	}
+	scopeBytes, err := json.Marshal(scope)
+	if err != nil {
+		return "", err
+	}
+	tokenHash := hashing.ComputeHash256Array([]byte(token))
+	if err := a.db.Put(tokenHash[:], scopeBytes); err != nil {
+		return "", err
+	}
+	a.tokens[tokenHash] = scope
+	return token, nil
+}
+
+// RevokeToken revokes [token], if [password] is correct
+func (a *Auth) RevokeToken(password, token string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.checkPassword(password) {
+		return errWrongPassword
+	}
+
+	tokenHash := hashing.ComputeHash256Array([]byte(token))
+	if _, exists := a.tokens[tokenHash]; !exists {
+		return errUnknownToken
+	}
+	if err := a.db.Delete(tokenHash[:]); err != nil {
+		return err
+	}
+	delete(a.tokens, tokenHash)
+	return nil
+}
+
+// ChangePassword changes the password from [oldPassword] to [newPassword].
+// Tokens that were already issued stay valid. The change lasts until the node
+// restarts, after which the configured password is used again.
+func (a *Auth) ChangePassword(oldPassword, newPassword string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.checkPassword(oldPassword) {
+		return errWrongPassword
+	}
+	a.password = hashing.ComputeHash256Array([]byte(newPassword))
+	return nil
+}
+
+// Authorize returns nil if the request [r] may be served. Requests to
+// endpoints that aren't public, and that are protected if any are, must carry
+// a token that's authorized for the endpoint in their
+// "Authorization: Bearer <token>" header.
+func (a *Auth) Authorize(r *http.Request) error {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	endpoint := a.resolve(normalize(r.URL.Path))
+	if a.covers(authEndpoint, endpoint) {
+		return nil
+	}
+	for _, public := range a.public {
+		if a.covers(public, endpoint) {
+			return nil
+		}
+	}
+	if len(a.protected) > 0 && !a.isProtected(endpoint) {
+		return nil
+	}
+
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, headerPrefix) {
+		return errNoToken
+	}
+	scope, exists := a.tokens[hashing.ComputeHash256Array([]byte(strings.TrimPrefix(header, headerPrefix)))]
+	if !exists {
+		return errUnknownToken
+	}
+	for _, allowed := range scope {
+		if a.covers(allowed, endpoint) {
+			return nil
+		}
+	}
+	return errWrongScope
+}
+
+// isProtected returns true if [endpoint] is one of the protected endpoints, or
+// under one. Assumes the lock is held.
+func (a *Auth) isProtected(endpoint string) bool {
+	for _, protected := range a.protected {
+		if a.covers(protected, endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the endpoint that [endpoint] is an alias of, or [endpoint]
+// if it isn't an alias. Assumes the lock is held.
+func (a *Auth) resolve(endpoint string) string {
+	if a.canonical == nil || endpoint == AllEndpoints {
+		return endpoint
+	}
+	return a.canonical(endpoint)
+}
+
+// covers returns true if [scope], or the endpoint it's an alias of, authorizes
+// requests to [endpoint], which is already resolved. Assumes the lock is held.
+func (a *Auth) covers(scope, endpoint string) bool {
+	return covers(a.resolve(scope), endpoint)
+}
+
+// checkPassword returns true if [password] is the password. Assumes the lock
+// is held.
+func (a *Auth) checkPassword(password string) bool {
+	passwordHash := hashing.ComputeHash256Array([]byte(password))
+	return subtle.ConstantTimeCompare(passwordHash[:], a.password[:]) == 1
+}
+
+// normalize returns [endpoint] without the API's base URL or surrounding
+// slashes, so "/ext/bc/X/" and "bc/X" are the same endpoint
+func normalize(endpoint string) string {
+	endpoint = strings.TrimPrefix(endpoint, baseURL)
+	return strings.Trim(endpoint, "/")
+}
+
+// covers returns true if [scope] authorizes requests to [endpoint]
+func covers(scope, endpoint string) bool {
+	return scope == AllEndpoints || scope == endpoint || strings.HasPrefix(endpoint, scope+"/")
+}
@@ -0,0 +1,169 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// request returns a request to [path] that carries [token], if it isn't empty
+func request(path, token string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, path, nil)
+	if token != "" {
+		r.Header.Set("Authorization", headerPrefix+token)
+	}
+	return r
+}
+
+func TestAuthorize(t *testing.T) {
+	a := &Auth{}
+	if err := a.Initialize(logging.NoLog{}, memdb.New(), "password", []string{"health"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.NewToken("wrong", []string{"admin"}); err != errWrongPassword {
+		t.Fatalf("shouldn't have issued a token with the wrong password")
+	}
+	if _, err := a.NewToken("password", nil); err != errNoEndpoints {
+		t.Fatalf("shouldn't have issued a token without endpoints")
+	}
+	token, err := a.NewToken("password", []string{"/ext/bc/X/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path, token string
+		expected    error
+	}{
+		{"/ext/health", "", nil},
+		{"/ext/health/readiness", "", nil},
+		{"/ext/auth", "", nil},
+		{"/ext/admin", "", errNoToken},
+		{"/ext/admin", "unknown", errUnknownToken},
+		{"/ext/admin", token, errWrongScope},
+		{"/ext/bc/X", token, nil},
+		{"/ext/bc/X/wallet", token, nil},
+		{"/ext/bc/XY", token, errWrongScope},
+	} {
+		if err := a.Authorize(request(test.path, test.token)); err != test.expected {
+			t.Fatalf("request to %s should have returned %v but returned %v", test.path, test.expected, err)
+		}
+	}
+
+	if err := a.RevokeToken("wrong", token); err != errWrongPassword {
+		t.Fatalf("shouldn't have revoked a token with the wrong password")
+	}
+	if err := a.RevokeToken("password", token); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Authorize(request("/ext/bc/X", token)); err != errUnknownToken {
+		t.Fatalf("shouldn't have authorized a revoked token")
+	}
+	if err := a.RevokeToken("password", token); err != errUnknownToken {
+		t.Fatalf("shouldn't have revoked a token twice")
+	}
+}
+
+func TestTokensPersist(t *testing.T) {
+	db := memdb.New()
+	a := &Auth{}
+	if err := a.Initialize(logging.NoLog{}, db, "password", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	token, err := a.NewToken("password", []string{AllEndpoints})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ChangePassword("password", "new password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.NewToken("password", []string{AllEndpoints}); err != errWrongPassword {
+		t.Fatalf("shouldn't have issued a token with the old password")
+	}
+
+	restarted := &Auth{}
+	if err := restarted.Initialize(logging.NoLog{}, db, "password", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := restarted.Authorize(request("/ext/keystore", token)); err != nil {
+		t.Fatalf("token should have survived a restart: %s", err)
+	}
+}
+
+func TestAuthorizeProtected(t *testing.T) {
+	a := &Auth{}
+	if err := a.Initialize(logging.NoLog{}, memdb.New(), "password", []string{"admin/health"}, []string{"keystore", "/ext/admin/"}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := a.NewToken("password", []string{"keystore"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path, token string
+		expected    error
+	}{
+		{"/ext/bc/X", "", nil},
+		{"/ext/info", "", nil},
+		{"/ext/auth", "", nil},
+		{"/ext/admin/health", "", nil},
+		{"/ext/keystore", "", errNoToken},
+		{"/ext/keystore", token, nil},
+		{"/ext/admin", token, errWrongScope},
+		{"/ext/admin/profiles", "", errNoToken},
+		{"/ext/keystores", "", nil},
+	} {
+		if err := a.Authorize(request(test.path, test.token)); err != test.expected {
+			t.Fatalf("request to %s should have returned %v but returned %v", test.path, test.expected, err)
+		}
+	}
+}
+
+func TestAuthorizeAliases(t *testing.T) {
+	a := &Auth{}
+	if err := a.Initialize(logging.NoLog{}, memdb.New(), "password", []string{"bc/P"}, []string{"bc/X", "keystore"}); err != nil {
+		t.Fatal(err)
+	}
+	aliases := map[string]string{
+		"bc/X":     "bc/abc",
+		"bc/P":     "bc/def",
+		"wallet":   "bc/abc",
+		"keys":     "keystore",
+		"keys/rpc": "keystore/rpc",
+	}
+	a.SetCanonical(func(endpoint string) string {
+		if canonical, ok := aliases[endpoint]; ok {
+			return canonical
+		}
+		return endpoint
+	})
+	token, err := a.NewToken("password", []string{"bc/X"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path, token string
+		expected    error
+	}{
+		{"/ext/bc/abc", "", errNoToken},
+		{"/ext/wallet", "", errNoToken},
+		{"/ext/keys", "", errNoToken},
+		{"/ext/keys/rpc", "", errNoToken},
+		{"/ext/bc/abc", token, nil},
+		{"/ext/wallet", token, nil},
+		{"/ext/bc/def", "", nil},
+	} {
+		if err := a.Authorize(request(test.path, test.token)); err != test.expected {
+			t.Fatalf("request to %s should have returned %v but returned %v", test.path, test.expected, err)
+		}
+	}
+}
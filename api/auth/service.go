@@ -0,0 +1,94 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package auth
+
+import (
+	"net/http"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// CreateHandler returns a new service object that can send requests to the
+// Auth API
+func (a *Auth) CreateHandler() *common.HTTPHandler {
+	newServer := cjson.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Service{auth: a}, "auth")
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: newServer}
+}
+
+// Service is the API service for issuing and revoking tokens
+type Service struct{ auth *Auth }
+
+// NewTokenArgs are the arguments for calling NewToken
+type NewTokenArgs struct {
+	Password string `json:"password"`
+
+	// Endpoints the token authorizes, such as "admin" or "bc/X". "*"
+	// authorizes every endpoint.
+	Endpoints []string `json:"endpoints"`
+}
+
+// NewTokenReply is the reply from calling NewToken
+type NewTokenReply struct {
+	Token string `json:"token"`
+}
+
+// NewToken returns a new token that authorizes requests to the given endpoints
+func (service *Service) NewToken(_ *http.Request, args *NewTokenArgs, reply *NewTokenReply) error {
+	service.auth.log.Debug("Auth: NewToken called for %v", args.Endpoints)
+
+	token, err := service.auth.NewToken(args.Password, args.Endpoints)
+	reply.Token = token
+	return err
+}
+
+// RevokeTokenArgs are the arguments for calling RevokeToken
+type RevokeTokenArgs struct {
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// RevokeTokenReply is the reply from calling RevokeToken
+type RevokeTokenReply struct {
+	Success bool `json:"success"`
+}
+
+// RevokeToken revokes a token, so requests carrying it are no longer
+// authorized
+func (service *Service) RevokeToken(_ *http.Request, args *RevokeTokenArgs, reply *RevokeTokenReply) error {
+	service.auth.log.Debug("Auth: RevokeToken called")
+
+	if err := service.auth.RevokeToken(args.Password, args.Token); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
+// ChangePasswordArgs are the arguments for calling ChangePassword
+type ChangePasswordArgs struct {
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+}
+
+// ChangePasswordReply is the reply from calling ChangePassword
+type ChangePasswordReply struct {
+	Success bool `json:"success"`
+}
+
+// ChangePassword changes the password that tokens are issued and revoked with
+func (service *Service) ChangePassword(_ *http.Request, args *ChangePasswordArgs, reply *ChangePasswordReply) error {
+	service.auth.log.Debug("Auth: ChangePassword called")
+
+	if err := service.auth.ChangePassword(args.OldPassword, args.NewPassword); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
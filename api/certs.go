@@ -0,0 +1,121 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// certPollFrequency is how often the certificate files are checked for changes
+const certPollFrequency = 10 * time.Second
+
+// certReloader serves the API server's TLS certificate. The certificate is
+// reloaded from its files when either of them changes, or when it's asked to
+// be, so certificates can be renewed without restarting the node.
+type certReloader struct {
+	log               logging.Logger
+	certFile, keyFile string
+
+	lock sync.RWMutex
+	cert *tls.Certificate
+	// Modification times of the files the certificate was loaded from
+	certModTime, keyModTime time.Time
+
+	repeater *timer.Repeater
+}
+
+// newCertReloader loads the certificate in [certFile] and [keyFile]
+func newCertReloader(log logging.Logger, certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{
+		log:      log,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	return c, c.reload()
+}
+
+// GetCertificate returns the most recently loaded certificate. It has the
+// signature of tls.Config.GetCertificate.
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.cert, nil
+}
+
+// watch for changes to the certificate files until stop is called
+func (c *certReloader) watch() {
+	c.repeater = timer.NewRepeater(c.reloadIfModified, certPollFrequency)
+	go c.log.RecoverAndPanic(c.repeater.Dispatch)
+}
+
+// stop watching for changes to the certificate files
+func (c *certReloader) stop() {
+	if c.repeater != nil {
+		c.repeater.Stop()
+	}
+}
+
+// reloadIfModified reloads the certificate if either of its files was modified
+// since it was loaded. If reloading fails, for example because only one of the
+// files has been replaced so far, the previous certificate is kept and
+// reloading is retried on the next check.
+func (c *certReloader) reloadIfModified() {
+	certModTime, keyModTime, err := c.modTimes()
+	if err != nil {
+		c.log.Warn("failed to check the API server's TLS certificate files due to %s", err)
+		return
+	}
+
+	c.lock.RLock()
+	modified := !certModTime.Equal(c.certModTime) || !keyModTime.Equal(c.keyModTime)
+	c.lock.RUnlock()
+	if !modified {
+		return
+	}
+
+	c.log.Info("reloading the API server's TLS certificate because its files changed")
+	if err := c.reload(); err != nil {
+		c.log.Warn("failed to reload the API server's TLS certificate due to %s", err)
+	}
+}
+
+// reload the certificate from its files
+func (c *certReloader) reload() error {
+	certModTime, keyModTime, err := c.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.cert = &cert
+	c.certModTime = certModTime
+	c.keyModTime = keyModTime
+	return nil
+}
+
+// modTimes returns the modification times of the certificate and key files
+func (c *certReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(c.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	keyInfo, err := os.Stat(c.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
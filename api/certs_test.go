@@ -0,0 +1,130 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// writeCert writes a self-signed certificate with serial number [serial] to
+// [certFile] and its key to [keyFile], and sets their modification times to
+// [modTime]
+func writeCert(t *testing.T, certFile, keyFile string, serial int64, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// serial returns the serial number of the certificate [c] serves
+func serial(t *testing.T, c *certReloader) int64 {
+	cert, err := c.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.SerialNumber.Int64()
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "api.crt"), filepath.Join(dir, "api.key")
+	modTime := time.Unix(1000, 0)
+	writeCert(t, certFile, keyFile, 1, modTime)
+
+	c, err := newCertReloader(logging.NoLog{}, certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serial(t, c) != 1 {
+		t.Fatalf("should have loaded the certificate")
+	}
+
+	// Unmodified files aren't reloaded
+	writeCert(t, certFile, keyFile, 2, modTime)
+	c.reloadIfModified()
+	if serial(t, c) != 1 {
+		t.Fatalf("shouldn't have reloaded unmodified files")
+	}
+
+	modTime = modTime.Add(time.Second)
+	writeCert(t, certFile, keyFile, 3, modTime)
+	c.reloadIfModified()
+	if serial(t, c) != 3 {
+		t.Fatalf("should have reloaded the modified files")
+	}
+
+	// A certificate that fails to load is retried while the old one is served
+	if err := ioutil.WriteFile(keyFile, []byte("partially written"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c.reloadIfModified()
+	if serial(t, c) != 3 {
+		t.Fatalf("should have kept serving the previous certificate")
+	}
+	writeCert(t, certFile, keyFile, 4, modTime.Add(time.Second))
+	c.reloadIfModified()
+	if serial(t, c) != 4 {
+		t.Fatalf("should have reloaded the fixed files")
+	}
+
+	// Reloading when asked to doesn't depend on the files' modification times
+	writeCert(t, certFile, keyFile, 5, modTime)
+	s := Server{certs: []*certReloader{c}}
+	if err := s.ReloadCertificates(); err != nil {
+		t.Fatal(err)
+	}
+	if serial(t, c) != 5 {
+		t.Fatalf("should have reloaded the certificate when asked to")
+	}
+
+	if _, err := newCertReloader(logging.NoLog{}, certFile, filepath.Join(dir, "missing.key")); err == nil {
+		t.Fatalf("shouldn't have loaded a missing key")
+	}
+}
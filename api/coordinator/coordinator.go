@@ -0,0 +1,438 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package coordinator
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+const (
+	// MaxAttempts is the most times a step tries to issue its transaction
+	// before the workflow fails
+	MaxAttempts = 5
+
+	// How often running workflows are advanced
+	tickFrequency = time.Second
+
+	// Bounds of the delay before a failed step is retried. The delay doubles
+	// with every failed attempt.
+	minRetryDelay = 2 * time.Second
+	maxRetryDelay = time.Minute
+
+	// How long a transaction may be unknown to its chain before it's
+	// considered dropped, and the step issues it again
+	droppedTimeout = time.Minute
+
+	// Fields of the replies that steps read
+	txIDField   = "txID"
+	statusField = "status"
+)
+
+var (
+	errUnknownWorkflow = errors.New("unknown workflow")
+	errFinished        = errors.New("workflow already finished")
+	errNoTxID          = errors.New("the reply of the last call has no txID")
+	errMissingInput    = errors.New("the previous reply is missing an input")
+	errRejected        = errors.New("transaction was rejected")
+	errDropped         = errors.New("transaction was dropped")
+	errRestarted       = errors.New("node restarted while the workflow was running")
+)
+
+// Caller calls the APIs of chains
+type Caller interface {
+	CallChain(chainID ids.ID, method string, args, reply interface{}) error
+}
+
+// ChainLookup returns the ID of the chain that has ID or alias [alias]
+type ChainLookup interface {
+	Lookup(alias string) (ids.ID, error)
+}
+
+// run is the state of a workflow that hasn't finished
+type run struct {
+	workflow *Workflow
+	steps    []Step
+	chainIDs []ids.ID
+
+	// When the current step may next try to issue its transaction
+	nextAttempt time.Time
+
+	// When the current step's transaction was issued
+	issued time.Time
+}
+
+// Coordinator runs workflows: sequences of transactions on different chains,
+// such as exporting $AVA from the X-Chain, importing it on the P-Chain and then
+// staking it, where each transaction depends on the previous one being
+// accepted. Workflows are advanced in the background, and their progress is
+// persisted so it can be queried after they finish.
+//
+// The arguments of a workflow's calls may include credentials, so they're kept
+// in memory only. If the node restarts, the workflows that were running fail.
+type Coordinator struct {
+	lock  sync.Mutex
+	log   logging.Logger
+	clock timer.Clock
+
+	caller      Caller
+	chainLookup ChainLookup
+
+	// Key: Workflow ID
+	// Value: JSON of the workflow
+	db database.Database
+
+	// Key: Workflow ID
+	// Value: The workflow's state
+	running map[[32]byte]*run
+
+	// Number of workflows started, used to make their IDs unique
+	numStarted uint64
+
+	repeater *timer.Repeater
+}
+
+// Initialize the coordinator, which persists workflows in [db], resolves chains
+// with [chainLookup] and calls their APIs with [caller]. Workflows that were
+// running when the node stopped are marked as failed.
+func (c *Coordinator) Initialize(log logging.Logger, db database.Database, chainLookup ChainLookup, caller Caller) error {
+	c.log = log
+	c.db = db
+	c.chainLookup = chainLookup
+	c.caller = caller
+	c.running = make(map[[32]byte]*run)
+
+	if err := c.failInterrupted(); err != nil {
+		return err
+	}
+
+	c.repeater = timer.NewRepeater(c.tick, tickFrequency)
+	go log.RecoverAndPanic(c.repeater.Dispatch)
+	return nil
+}
+
+// Shutdown stops advancing workflows
+func (c *Coordinator) Shutdown() {
+	if c.repeater != nil {
+		c.repeater.Stop()
+	}
+}
+
+// Start a workflow that runs [steps], and return its ID
+func (c *Coordinator) Start(steps []Step) (ids.ID, error) {
+	if err := verifySteps(steps); err != nil {
+		return ids.ID{}, err
+	}
+	chainIDs := make([]ids.ID, len(steps))
+	for i, step := range steps {
+		chainID, err := c.chainLookup.Lookup(step.Chain)
+		if err != nil {
+			return ids.ID{}, fmt.Errorf("step %d is on unknown chain %s: %w", i, step.Chain, err)
+		}
+		chainIDs[i] = chainID
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.clock.Time()
+	c.numStarted++
+	idBytes := make([]byte, 16)
+	binary.BigEndian.PutUint64(idBytes, uint64(now.UnixNano()))
+	binary.BigEndian.PutUint64(idBytes[8:], c.numStarted)
+	workflowID := ids.NewID(hashing.ComputeHash256Array(idBytes))
+
+	workflow := &Workflow{
+		ID:      workflowID,
+		Status:  Running,
+		Steps:   make([]StepResult, len(steps)),
+		Created: uint64(now.Unix()),
+		Updated: uint64(now.Unix()),
+	}
+	if err := c.save(workflow); err != nil {
+		return ids.ID{}, err
+	}
+	c.running[workflowID.Key()] = &run{
+		workflow: workflow,
+		steps:    steps,
+		chainIDs: chainIDs,
+	}
+	c.log.Info("started workflow %s with %d steps", workflowID, len(steps))
+	return workflowID, nil
+}
+
+// Workflow returns the workflow with ID [workflowID]
+func (c *Coordinator) Workflow(workflowID ids.ID) (*Workflow, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.load(workflowID)
+}
+
+// Cancel the workflow with ID [workflowID]. Transactions it already issued
+// aren't undone.
+func (c *Coordinator) Cancel(workflowID ids.ID) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	r, ok := c.running[workflowID.Key()]
+	if !ok {
+		if _, err := c.load(workflowID); err != nil {
+			return err
+		}
+		return errFinished
+	}
+	delete(c.running, workflowID.Key())
+
+	r.workflow.Status = Cancelled
+	if result := &r.workflow.Steps[r.workflow.Current]; !result.Status.Finished() {
+		result.Status = Cancelled
+	}
+	r.workflow.Updated = c.clock.Unix()
+	c.log.Info("cancelled workflow %s", workflowID)
+	return c.save(r.workflow)
+}
+
+// tick advances every running workflow
+func (c *Coordinator) tick() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key, r := range c.running {
+		if !c.advance(r) {
+			continue
+		}
+		if err := c.save(r.workflow); err != nil {
+			c.log.Error("couldn't save workflow %s due to %s", r.workflow.ID, err)
+		}
+		if r.workflow.Status.Finished() {
+			c.log.Info("workflow %s finished with status %s", r.workflow.ID, r.workflow.Status)
+			delete(c.running, key)
+		}
+	}
+}
+
+// advance the current step of [r]. Returns true if the workflow changed.
+// Assumes the lock is held.
+func (c *Coordinator) advance(r *run) bool {
+	now := c.clock.Time()
+	workflow := r.workflow
+	result := &workflow.Steps[workflow.Current]
+	step := r.steps[workflow.Current]
+	chainID := r.chainIDs[workflow.Current]
+
+	switch result.Status {
+	case Pending:
+		if now.Before(r.nextAttempt) {
+			return false
+		}
+		result.Attempts++
+		txID, err := c.issue(chainID, step)
+		if err != nil {
+			c.failAttempt(r, err)
+			break
+		}
+		result.TxID = txID
+		result.Error = ""
+		r.issued = now
+		if step.StatusMethod == "" {
+			c.succeedStep(r)
+		} else {
+			result.Status = Running
+		}
+	case Running:
+		status, err := c.status(chainID, step.StatusMethod, result.TxID)
+		if err != nil {
+			// The transaction may still be accepted, so its status is
+			// checked again on the next tick
+			c.log.Debug("couldn't get the status of transaction %s of workflow %s due to %s", result.TxID, workflow.ID, err)
+			return false
+		}
+		switch status {
+		case choices.Accepted:
+			c.succeedStep(r)
+		case choices.Rejected:
+			c.failAttempt(r, errRejected)
+		case choices.Unknown:
+			if now.Sub(r.issued) < droppedTimeout {
+				return false
+			}
+			c.failAttempt(r, errDropped)
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	workflow.Updated = uint64(now.Unix())
+	return true
+}
+
+// issue the transaction of [step] on the chain [chainID], and return its ID
+func (c *Coordinator) issue(chainID ids.ID, step Step) (ids.ID, error) {
+	previous := map[string]interface{}(nil)
+	for _, call := range step.Calls {
+		params := make(map[string]interface{}, len(call.Params)+len(call.Inputs))
+		for name, value := range call.Params {
+			params[name] = value
+		}
+		for name, field := range call.Inputs {
+			value, ok := lookupField(previous, field)
+			if !ok {
+				return ids.ID{}, fmt.Errorf("%w: %s", errMissingInput, field)
+			}
+			params[name] = value
+		}
+
+		reply := map[string]interface{}{}
+		if err := c.caller.CallChain(chainID, call.Method, params, &reply); err != nil {
+			return ids.ID{}, fmt.Errorf("%s failed: %w", call.Method, err)
+		}
+		previous = reply
+	}
+
+	txIDIntf, ok := lookupField(previous, txIDField)
+	if !ok {
+		return ids.ID{}, errNoTxID
+	}
+	txIDStr, ok := txIDIntf.(string)
+	if !ok {
+		return ids.ID{}, errNoTxID
+	}
+	return ids.FromString(txIDStr)
+}
+
+// status returns the status of transaction [txID] on the chain [chainID]
+func (c *Coordinator) status(chainID ids.ID, method string, txID ids.ID) (choices.Status, error) {
+	reply := map[string]interface{}{}
+	if err := c.caller.CallChain(chainID, method, map[string]interface{}{txIDField: txID.String()}, &reply); err != nil {
+		return choices.Unknown, err
+	}
+	statusIntf, _ := lookupField(reply, statusField)
+	statusStr, ok := statusIntf.(string)
+	if !ok {
+		return choices.Unknown, fmt.Errorf("the reply of %s has no status", method)
+	}
+	status := choices.Unknown
+	err := status.UnmarshalJSON([]byte(fmt.Sprintf("%q", statusStr)))
+	return status, err
+}
+
+// failAttempt records that the current step of [r] failed due to [err], and
+// fails the workflow if the step can't be retried. Assumes the lock is held.
+func (c *Coordinator) failAttempt(r *run, err error) {
+	workflow := r.workflow
+	result := &workflow.Steps[workflow.Current]
+	result.Error = err.Error()
+	c.log.Debug("step %d of workflow %s failed attempt %d due to %s", workflow.Current, workflow.ID, result.Attempts, err)
+
+	if result.Attempts >= MaxAttempts {
+		result.Status = Failed
+		workflow.Status = Failed
+		return
+	}
+	result.Status = Pending
+
+	delay := minRetryDelay << (result.Attempts - 1)
+	if delay > maxRetryDelay || delay <= 0 {
+		delay = maxRetryDelay
+	}
+	r.nextAttempt = c.clock.Time().Add(delay)
+}
+
+// succeedStep records that the current step of [r] finished, and moves to the
+// next step. Assumes the lock is held.
+func (c *Coordinator) succeedStep(r *run) {
+	workflow := r.workflow
+	workflow.Steps[workflow.Current].Status = Succeeded
+	if workflow.Current == len(workflow.Steps)-1 {
+		workflow.Status = Succeeded
+		return
+	}
+	workflow.Current++
+	r.nextAttempt = time.Time{}
+}
+
+// failInterrupted marks the workflows that were running when the node stopped
+// as failed, since the arguments of their calls are lost
+func (c *Coordinator) failInterrupted() error {
+	iter := c.db.NewIterator()
+	defer iter.Release()
+
+	interrupted := []*Workflow(nil)
+	for iter.Next() {
+		workflow := &Workflow{}
+		if err := json.Unmarshal(iter.Value(), workflow); err != nil {
+			return err
+		}
+		if !workflow.Status.Finished() {
+			interrupted = append(interrupted, workflow)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	for _, workflow := range interrupted {
+		workflow.Status = Failed
+		result := &workflow.Steps[workflow.Current]
+		result.Status = Failed
+		result.Error = errRestarted.Error()
+		workflow.Updated = c.clock.Unix()
+		if err := c.save(workflow); err != nil {
+			return err
+		}
+		c.log.Info("workflow %s failed because the node restarted", workflow.ID)
+	}
+	return nil
+}
+
+// save [workflow] to the database
+func (c *Coordinator) save(workflow *Workflow) error {
+	workflowBytes, err := json.Marshal(workflow)
+	if err != nil {
+		return err
+	}
+	return c.db.Put(workflow.ID.Bytes(), workflowBytes)
+}
+
+// load the workflow with ID [workflowID] from the database
+func (c *Coordinator) load(workflowID ids.ID) (*Workflow, error) {
+	workflowBytes, err := c.db.Get(workflowID.Bytes())
+	if err == database.ErrNotFound {
+		return nil, errUnknownWorkflow
+	}
+	if err != nil {
+		return nil, err
+	}
+	workflow := &Workflow{}
+	err = json.Unmarshal(workflowBytes, workflow)
+	return workflow, err
+}
+
+// lookupField returns the field [name] of [reply]. Fields are matched case
+// insensitively, since some APIs don't tag the fields of their replies.
+func lookupField(reply map[string]interface{}, name string) (interface{}, bool) {
+	if value, ok := reply[name]; ok {
+		return value, true
+	}
+	for field, value := range reply {
+		if strings.EqualFold(field, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
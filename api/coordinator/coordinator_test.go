@@ -0,0 +1,330 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package coordinator
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+var (
+	xChainID = ids.NewID([32]byte{1})
+	pChainID = ids.NewID([32]byte{2})
+	txID0    = ids.NewID([32]byte{3})
+	txID1    = ids.NewID([32]byte{4})
+)
+
+// testCaller calls the handlers registered for each method. The arguments and
+// replies are passed through JSON, as they are by the API server.
+type testCaller struct {
+	t        *testing.T
+	handlers map[string]func(chainID ids.ID, params map[string]interface{}) (interface{}, error)
+}
+
+func (c *testCaller) CallChain(chainID ids.ID, method string, args, reply interface{}) error {
+	handler, ok := c.handlers[method]
+	if !ok {
+		c.t.Fatalf("unexpected call to %s", method)
+	}
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	params := map[string]interface{}{}
+	if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return err
+	}
+	result, err := handler(chainID, params)
+	if err != nil {
+		return err
+	}
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(resultBytes, reply)
+}
+
+func chainLookup(t *testing.T) *ids.Aliaser {
+	aliaser := &ids.Aliaser{}
+	aliaser.Initialize()
+	if err := aliaser.Alias(xChainID, "X"); err != nil {
+		t.Fatal(err)
+	}
+	if err := aliaser.Alias(pChainID, "P"); err != nil {
+		t.Fatal(err)
+	}
+	return aliaser
+}
+
+// newCoordinator returns a coordinator whose workflows are only advanced when
+// the test calls tick
+func newCoordinator(t *testing.T, caller *testCaller) *Coordinator {
+	c := &Coordinator{}
+	c.clock.Set(time.Unix(1000, 0))
+	if err := c.Initialize(logging.NoLog{}, memdb.New(), chainLookup(t), caller); err != nil {
+		t.Fatal(err)
+	}
+	c.Shutdown()
+	return c
+}
+
+// exportImportSteps exports from the X-Chain and imports on the P-Chain, which
+// signs and then issues the import
+func exportImportSteps() []Step {
+	return []Step{
+		{
+			Chain:        "X",
+			Calls:        []Call{{Method: "avm.exportAVA", Params: map[string]interface{}{"amount": 10}}},
+			StatusMethod: "avm.getTxStatus",
+		},
+		{
+			Chain: "P",
+			Calls: []Call{
+				{Method: "platform.importAVA"},
+				{Method: "platform.sign", Inputs: map[string]string{"tx": "unsignedTx"}},
+				{Method: "platform.issueTx", Inputs: map[string]string{"tx": "Tx"}},
+			},
+		},
+	}
+}
+
+func TestWorkflowSucceeds(t *testing.T) {
+	status := "Processing"
+	caller := &testCaller{t: t, handlers: map[string]func(ids.ID, map[string]interface{}) (interface{}, error){
+		"avm.exportAVA": func(chainID ids.ID, params map[string]interface{}) (interface{}, error) {
+			if !chainID.Equals(xChainID) || params["amount"] != float64(10) {
+				t.Fatalf("exported on the wrong chain or with the wrong arguments")
+			}
+			return map[string]interface{}{"txID": txID0.String()}, nil
+		},
+		"avm.getTxStatus": func(_ ids.ID, params map[string]interface{}) (interface{}, error) {
+			if params["txID"] != txID0.String() {
+				t.Fatalf("got the status of the wrong transaction")
+			}
+			return map[string]interface{}{"status": status}, nil
+		},
+		"platform.importAVA": func(chainID ids.ID, _ map[string]interface{}) (interface{}, error) {
+			if !chainID.Equals(pChainID) {
+				t.Fatalf("imported on the wrong chain")
+			}
+			return map[string]interface{}{"unsignedTx": "unsigned"}, nil
+		},
+		"platform.sign": func(_ ids.ID, params map[string]interface{}) (interface{}, error) {
+			if params["tx"] != "unsigned" {
+				t.Fatalf("signed the wrong transaction")
+			}
+			return map[string]interface{}{"Tx": "signed"}, nil
+		},
+		"platform.issueTx": func(_ ids.ID, params map[string]interface{}) (interface{}, error) {
+			if params["tx"] != "signed" {
+				t.Fatalf("issued the wrong transaction")
+			}
+			return map[string]interface{}{"TxID": txID1.String()}, nil
+		},
+	}}
+	c := newCoordinator(t, caller)
+	s := &Service{coordinator: c}
+
+	startReply := WorkflowIDReply{}
+	if err := s.StartWorkflow(nil, &StartWorkflowArgs{Steps: exportImportSteps()}, &startReply); err != nil {
+		t.Fatal(err)
+	}
+	workflowID := startReply.WorkflowID
+
+	c.tick() // Issues the export
+	c.tick() // The export is processing
+
+	workflow := Workflow{}
+	if err := s.GetWorkflow(nil, &WorkflowArgs{WorkflowID: workflowID}, &workflow); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case workflow.Status != Running || workflow.Current != 0:
+		t.Fatalf("workflow should be running its first step")
+	case workflow.Steps[0].Status != Running || !workflow.Steps[0].TxID.Equals(txID0):
+		t.Fatalf("the export should have been issued")
+	}
+
+	status = "Accepted"
+	c.tick() // The export is accepted
+	c.tick() // Issues the import
+
+	if err := s.GetWorkflow(nil, &WorkflowArgs{WorkflowID: workflowID}, &workflow); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case workflow.Status != Succeeded || workflow.Current != 1:
+		t.Fatalf("workflow should have succeeded but has status %s", workflow.Status)
+	case workflow.Steps[1].Status != Succeeded || !workflow.Steps[1].TxID.Equals(txID1):
+		t.Fatalf("the import should have been issued")
+	case workflow.Steps[0].Attempts != 1 || workflow.Steps[1].Attempts != 1:
+		t.Fatalf("each step should have been attempted once")
+	}
+
+	if err := s.CancelWorkflow(nil, &WorkflowArgs{WorkflowID: workflowID}, &CancelWorkflowReply{}); err == nil {
+		t.Fatalf("shouldn't have cancelled a finished workflow")
+	}
+}
+
+func TestWorkflowRetries(t *testing.T) {
+	numFailures := 2
+	caller := &testCaller{t: t, handlers: map[string]func(ids.ID, map[string]interface{}) (interface{}, error){
+		"avm.exportAVA": func(ids.ID, map[string]interface{}) (interface{}, error) {
+			if numFailures > 0 {
+				numFailures--
+				return nil, errors.New("insufficient funds")
+			}
+			return map[string]interface{}{"txID": txID0.String()}, nil
+		},
+		"avm.getTxStatus": func(ids.ID, map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"status": "Accepted"}, nil
+		},
+	}}
+	c := newCoordinator(t, caller)
+
+	workflowID, err := c.Start(exportImportSteps()[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.tick() // First attempt fails
+	c.tick() // Waiting to retry
+	workflow, err := c.Workflow(workflowID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := workflow.Steps[0]; result.Status != Pending || result.Attempts != 1 || result.Error == "" {
+		t.Fatalf("the first attempt should have failed: %+v", result)
+	}
+
+	now := c.clock.Time()
+	c.clock.Set(now.Add(minRetryDelay))
+	c.tick() // Second attempt fails
+	c.clock.Set(now.Add(3 * minRetryDelay))
+	c.tick() // Third attempt succeeds
+	c.tick() // The export is accepted
+
+	if workflow, err = c.Workflow(workflowID); err != nil {
+		t.Fatal(err)
+	}
+	if result := workflow.Steps[0]; workflow.Status != Succeeded || result.Attempts != 3 || result.Error != "" {
+		t.Fatalf("the third attempt should have succeeded: %+v", result)
+	}
+}
+
+func TestWorkflowFails(t *testing.T) {
+	caller := &testCaller{t: t, handlers: map[string]func(ids.ID, map[string]interface{}) (interface{}, error){
+		"avm.exportAVA": func(ids.ID, map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"txID": txID0.String()}, nil
+		},
+		"avm.getTxStatus": func(ids.ID, map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"status": "Rejected"}, nil
+		},
+	}}
+	c := newCoordinator(t, caller)
+
+	workflowID, err := c.Start(exportImportSteps())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < MaxAttempts; i++ {
+		c.clock.Set(c.clock.Time().Add(maxRetryDelay))
+		c.tick() // Issues the export
+		c.tick() // The export is rejected
+	}
+
+	workflow, err := c.Workflow(workflowID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case workflow.Status != Failed || workflow.Steps[0].Status != Failed:
+		t.Fatalf("workflow should have failed")
+	case workflow.Steps[0].Attempts != MaxAttempts || workflow.Steps[0].Error != errRejected.Error():
+		t.Fatalf("the export should have been rejected %d times: %+v", MaxAttempts, workflow.Steps[0])
+	case workflow.Steps[1].Status != Pending:
+		t.Fatalf("the import shouldn't have been issued")
+	}
+}
+
+func TestWorkflowCancelAndRestart(t *testing.T) {
+	caller := &testCaller{t: t, handlers: map[string]func(ids.ID, map[string]interface{}) (interface{}, error){
+		"avm.exportAVA": func(ids.ID, map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"txID": txID0.String()}, nil
+		},
+		"avm.getTxStatus": func(ids.ID, map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"status": "Processing"}, nil
+		},
+	}}
+	c := newCoordinator(t, caller)
+
+	cancelledID, err := c.Start(exportImportSteps())
+	if err != nil {
+		t.Fatal(err)
+	}
+	interruptedID, err := c.Start(exportImportSteps())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelledID.Equals(interruptedID) {
+		t.Fatalf("workflows should have different IDs")
+	}
+	c.tick()
+
+	if err := c.Cancel(cancelledID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Cancel(ids.NewID([32]byte{5})); err != errUnknownWorkflow {
+		t.Fatalf("should have failed to cancel an unknown workflow")
+	}
+
+	// Restart the coordinator on the same database
+	restarted := &Coordinator{}
+	if err := restarted.Initialize(logging.NoLog{}, c.db, chainLookup(t), caller); err != nil {
+		t.Fatal(err)
+	}
+	restarted.Shutdown()
+
+	cancelled, err := restarted.Workflow(cancelledID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled.Status != Cancelled || cancelled.Steps[0].Status != Cancelled {
+		t.Fatalf("workflow should have been cancelled")
+	}
+	interrupted, err := restarted.Workflow(interruptedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interrupted.Status != Failed || interrupted.Steps[0].Error != errRestarted.Error() {
+		t.Fatalf("workflow should have failed when the node restarted")
+	}
+}
+
+func TestStartInvalidWorkflow(t *testing.T) {
+	c := newCoordinator(t, &testCaller{t: t})
+
+	if _, err := c.Start(nil); err == nil {
+		t.Fatalf("should have failed to start a workflow without steps")
+	}
+
+	steps := exportImportSteps()
+	steps[1].Chain = "C"
+	if _, err := c.Start(steps); err == nil {
+		t.Fatalf("should have failed to start a workflow on an unknown chain")
+	}
+
+	steps = exportImportSteps()
+	steps[1].Calls[0].Inputs = map[string]string{"tx": "unsignedTx"}
+	if _, err := c.Start(steps); err == nil {
+		t.Fatalf("should have failed to start a workflow whose first call takes inputs")
+	}
+}
@@ -0,0 +1,84 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package coordinator
+
+import (
+	"net/http"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// CreateHandler returns a new service object that can send requests to this
+// API
+func (c *Coordinator) CreateHandler() *common.HTTPHandler {
+	newServer := cjson.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Service{coordinator: c}, "coordinator")
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: newServer}
+}
+
+// Service is the API service for the coordinator
+type Service struct{ coordinator *Coordinator }
+
+// StartWorkflowArgs are the arguments for calling StartWorkflow
+type StartWorkflowArgs struct {
+	Steps []Step `json:"steps"`
+}
+
+// WorkflowIDReply is the reply from calling StartWorkflow
+type WorkflowIDReply struct {
+	WorkflowID ids.ID `json:"workflowID"`
+}
+
+// StartWorkflow starts a workflow that issues the transactions of
+// [args.Steps] in order, and returns its ID
+func (s *Service) StartWorkflow(_ *http.Request, args *StartWorkflowArgs, reply *WorkflowIDReply) error {
+	s.coordinator.log.Debug("Coordinator: StartWorkflow called with %d steps", len(args.Steps))
+
+	workflowID, err := s.coordinator.Start(args.Steps)
+	if err != nil {
+		return err
+	}
+	reply.WorkflowID = workflowID
+	return nil
+}
+
+// WorkflowArgs identify a workflow
+type WorkflowArgs struct {
+	WorkflowID ids.ID `json:"workflowID"`
+}
+
+// GetWorkflow returns the status of a workflow and of each of its steps
+func (s *Service) GetWorkflow(_ *http.Request, args *WorkflowArgs, reply *Workflow) error {
+	s.coordinator.log.Debug("Coordinator: GetWorkflow called with %s", args.WorkflowID)
+
+	workflow, err := s.coordinator.Workflow(args.WorkflowID)
+	if err != nil {
+		return err
+	}
+	*reply = *workflow
+	return nil
+}
+
+// CancelWorkflowReply is the reply from calling CancelWorkflow
+type CancelWorkflowReply struct {
+	Success bool `json:"success"`
+}
+
+// CancelWorkflow stops a running workflow. Transactions it already issued
+// aren't undone.
+func (s *Service) CancelWorkflow(_ *http.Request, args *WorkflowArgs, reply *CancelWorkflowReply) error {
+	s.coordinator.log.Debug("Coordinator: CancelWorkflow called with %s", args.WorkflowID)
+
+	if err := s.coordinator.Cancel(args.WorkflowID); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
@@ -0,0 +1,187 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package coordinator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+const (
+	// MaxSteps is the most steps a workflow may have
+	MaxSteps = 16
+
+	// MaxCalls is the most calls a step may make
+	MaxCalls = 8
+)
+
+var (
+	errNoSteps       = errors.New("workflow has no steps")
+	errTooManySteps  = errors.New("workflow has too many steps")
+	errNoChain       = errors.New("step has no chain")
+	errNoCalls       = errors.New("step makes no calls")
+	errTooManyCalls  = errors.New("step makes too many calls")
+	errNoMethod      = errors.New("call has no method")
+	errFirstInputs   = errors.New("the first call of a step has no previous reply to take inputs from")
+	errUnknownStatus = errors.New("unknown status")
+)
+
+// Call is a call to a method of a chain's API
+type Call struct {
+	// JSON-RPC method, such as platform.importAVA
+	Method string `json:"method"`
+
+	// Arguments of the method
+	Params map[string]interface{} `json:"params"`
+
+	// Arguments taken from the reply of the previous call of the step.
+	// Key: Name of the argument
+	// Value: Name of the field of the previous reply
+	Inputs map[string]string `json:"inputs"`
+}
+
+// Step issues a transaction through a chain's API, and waits for it to be
+// accepted. For example, a step that imports $AVA on the P-Chain calls
+// platform.importAVA, passes its unsignedTx to platform.sign as the tx, and
+// passes the signed tx to platform.issueTx.
+type Step struct {
+	// Alias or ID of the chain
+	Chain string `json:"chain"`
+
+	// Calls that issue the transaction, in order. The reply of the last call
+	// must have the ID of the transaction in its txID field.
+	Calls []Call `json:"calls"`
+
+	// Method that returns the status of the transaction, such as
+	// avm.getTxStatus. It's called with the txID argument, and its reply must
+	// have the status in its status field. If empty, the step is finished
+	// once the transaction is issued.
+	StatusMethod string `json:"statusMethod"`
+}
+
+// Verify returns nil if the step is well formed
+func (s *Step) Verify() error {
+	switch {
+	case s.Chain == "":
+		return errNoChain
+	case len(s.Calls) == 0:
+		return errNoCalls
+	case len(s.Calls) > MaxCalls:
+		return errTooManyCalls
+	case len(s.Calls[0].Inputs) > 0:
+		return errFirstInputs
+	}
+	for _, call := range s.Calls {
+		if call.Method == "" {
+			return errNoMethod
+		}
+	}
+	return nil
+}
+
+// Status of a workflow or of one of its steps
+type Status uint32
+
+// List of possible status values
+const (
+	// Waiting to start
+	Pending Status = iota
+	// A workflow is issuing its steps. A step's transaction was issued, and
+	// it's waiting for the transaction to be accepted.
+	Running
+	// Every step of a workflow was accepted, or a step's transaction was
+	// accepted
+	Succeeded
+	// A step failed too many times, or the node restarted while the workflow
+	// was running
+	Failed
+	// The workflow was cancelled before it finished
+	Cancelled
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pending:
+		return "Pending"
+	case Running:
+		return "Running"
+	case Succeeded:
+		return "Succeeded"
+	case Failed:
+		return "Failed"
+	case Cancelled:
+		return "Cancelled"
+	default:
+		return "Invalid status"
+	}
+}
+
+// MarshalJSON ...
+func (s Status) MarshalJSON() ([]byte, error) {
+	if s > Cancelled {
+		return nil, errUnknownStatus
+	}
+	return []byte(fmt.Sprintf("%q", s)), nil
+}
+
+// UnmarshalJSON ...
+func (s *Status) UnmarshalJSON(b []byte) error {
+	for status := Pending; status <= Cancelled; status++ {
+		if string(b) == fmt.Sprintf("%q", status) {
+			*s = status
+			return nil
+		}
+	}
+	return errUnknownStatus
+}
+
+// Finished returns true if the status won't change
+func (s Status) Finished() bool { return s == Succeeded || s == Failed || s == Cancelled }
+
+// StepResult is the progress of a step
+type StepResult struct {
+	Status Status `json:"status"`
+
+	// Transaction the step issued most recently
+	TxID ids.ID `json:"txID"`
+
+	// Number of times the step tried to issue its transaction
+	Attempts uint32 `json:"attempts"`
+
+	// Why the most recent attempt failed
+	Error string `json:"error,omitempty"`
+}
+
+// Workflow is a sequence of steps, each of which is started once the previous
+// step's transaction was accepted
+type Workflow struct {
+	ID     ids.ID       `json:"id"`
+	Status Status       `json:"status"`
+	Steps  []StepResult `json:"steps"`
+
+	// Index of the step being run
+	Current int `json:"current"`
+
+	// Unix times
+	Created uint64 `json:"created"`
+	Updated uint64 `json:"updated"`
+}
+
+// verifySteps returns nil if [steps] can be run as a workflow
+func verifySteps(steps []Step) error {
+	switch {
+	case len(steps) == 0:
+		return errNoSteps
+	case len(steps) > MaxSteps:
+		return errTooManySteps
+	}
+	for i, step := range steps {
+		if err := step.Verify(); err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+	}
+	return nil
+}
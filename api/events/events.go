@@ -0,0 +1,338 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// ChainEndpoint is the endpoint, under the Events API, of the stream of
+	// each chain's events. The chain is given by its ID or alias, as in
+	// /ext/events/X.
+	ChainEndpoint = "/{" + chainVar + "}"
+
+	// chainVar is the name of the path variable the chain is given by
+	chainVar = "chain"
+
+	// Kinds of containers whose acceptance is streamed
+	containers = "containers"
+	decisions  = "decisions"
+
+	// Name the stream registers with the event dispatchers under
+	dispatcherID = "events"
+
+	// bufferSize is the number of events that are held for a client that
+	// hasn't received them yet. Clients that fall further behind are
+	// disconnected, so that they can't hold up the chains.
+	bufferSize = 1024
+
+	// keepAliveFrequency is how often a comment is sent to clients that
+	// haven't been sent an event, so that proxies don't close the connection
+	keepAliveFrequency = 30 * time.Second
+)
+
+// ChainLookup returns the ID of the chain that has ID or alias [alias]
+type ChainLookup interface {
+	Lookup(alias string) (ids.ID, error)
+}
+
+// Event is the data of an event sent when a chain accepts a container
+type Event struct {
+	ChainID string `json:"chainID"`
+	ID      string `json:"id"`
+
+	// Only set if the client asked for the containers' bytes
+	Bytes *formatting.CB58 `json:"bytes,omitempty"`
+}
+
+// subscriber is a client's subscription to the containers of one kind that a
+// chain accepts
+type subscriber struct {
+	kind    string
+	payload bool
+
+	// If either isn't empty, only the containers with one of [ids], or whose
+	// bytes hold one of [addresses], are sent
+	ids       ids.Set
+	addresses [][]byte
+
+	sink *sink
+}
+
+// matches returns true if the container [containerID], whose bytes are
+// [container], should be sent to the subscriber
+func (sub *subscriber) matches(containerID ids.ID, container []byte) bool {
+	if sub.ids.Len() == 0 && len(sub.addresses) == 0 {
+		return true
+	}
+	if sub.ids.Contains(containerID) {
+		return true
+	}
+	for _, addr := range sub.addresses {
+		if bytes.Contains(container, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// notification is the data of an event of a kind of container
+type notification struct {
+	kind string
+	data []byte
+}
+
+// sink holds the events for a client that haven't been sent to it yet. A
+// client's subscriptions share its sink.
+type sink struct {
+	events chan notification
+
+	// Closed when the client is disconnected for falling behind
+	dropped  chan struct{}
+	dropOnce sync.Once
+}
+
+// newSink returns a sink that holds up to [size] events
+func newSink(size int) *sink {
+	return &sink{
+		events:  make(chan notification, size),
+		dropped: make(chan struct{}),
+	}
+}
+
+// drop the client, because it fell behind
+func (s *sink) drop() { s.dropOnce.Do(func() { close(s.dropped) }) }
+
+// Stream sends the containers chains accept to the clients that are subscribed
+// to them as server-sent events, as described at
+// https://html.spec.whatwg.org/multipage/server-sent-events.html. It's a
+// lighter alternative to websocket subscriptions for clients like shell
+// scripts and dashboards, as in:
+//
+//	curl -N -H "Accept: text/event-stream" localhost:9650/ext/events/X?type=decisions
+type Stream struct {
+	lock        sync.Mutex
+	log         logging.Logger
+	chainLookup ChainLookup
+	networkID   uint32
+
+	// Key: Chain ID
+	// Value: Subscribers to each kind of container the chain accepts
+	subscribers map[[32]byte]map[string]map[*subscriber]struct{}
+}
+
+// Initialize the stream of the chains of network [networkID]
+func (s *Stream) Initialize(log logging.Logger, chainLookup ChainLookup, networkID uint32) {
+	s.log = log
+	s.chainLookup = chainLookup
+	s.networkID = networkID
+	s.subscribers = make(map[[32]byte]map[string]map[*subscriber]struct{})
+}
+
+// Register the stream with the dispatchers of the events that chains make
+// decisions and accept containers
+func (s *Stream) Register(decisionDispatcher, consensusDispatcher *triggers.EventDispatcher) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		decisionDispatcher.Register(dispatcherID, &acceptor{stream: s, kind: decisions}),
+		consensusDispatcher.Register(dispatcherID, &acceptor{stream: s, kind: containers}),
+	)
+	return errs.Err
+}
+
+// CreateHandler returns the handler that streams events to clients. It's
+// served at ChainEndpoint, which gives the chain in the path. The query
+// parameter "type" gives the kind of containers that are streamed, either
+// "containers", the default, or "decisions". If the query parameter "payload"
+// is true, each event holds the bytes of its container. Clients should accept
+// text/event-stream, as browsers do, so that the stream isn't timed out.
+func (s *Stream) CreateHandler() *common.HTTPHandler {
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: http.HandlerFunc(s.serve)}
+}
+
+// acceptor adds the containers of one kind that are accepted to the stream
+type acceptor struct {
+	stream *Stream
+	kind   string
+}
+
+// Accept implements the triggers.Acceptor interface
+func (a *acceptor) Accept(chainID, containerID ids.ID, container []byte) error {
+	a.stream.accept(a.kind, chainID, containerID, container)
+	return nil
+}
+
+// accept sends the container [containerID], of kind [kind] and accepted by
+// chain [chainID], to the clients subscribed to it
+func (s *Stream) accept(kind string, chainID, containerID ids.ID, container []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	subscribers := s.subscribers[chainID.Key()][kind]
+	if len(subscribers) == 0 {
+		return
+	}
+
+	event := Event{ChainID: chainID.String(), ID: containerID.String()}
+	withoutPayload, err := json.Marshal(&event)
+	if err != nil {
+		s.log.Error("couldn't marshal the event of container %s: %s", containerID, err)
+		return
+	}
+	withPayload := []byte(nil)
+	for sub := range subscribers {
+		if !sub.matches(containerID, container) {
+			continue
+		}
+		data := withoutPayload
+		if sub.payload {
+			if withPayload == nil {
+				event.Bytes = &formatting.CB58{Bytes: container}
+				if withPayload, err = json.Marshal(&event); err != nil {
+					s.log.Error("couldn't marshal the event of container %s: %s", containerID, err)
+					return
+				}
+			}
+			data = withPayload
+		}
+
+		select {
+		case sub.sink.events <- notification{kind: kind, data: data}:
+		default:
+			s.log.Debug("disconnecting an event stream of chain %s that fell behind", chainID)
+			delete(subscribers, sub)
+			sub.sink.drop()
+		}
+	}
+}
+
+// subscribe [sub] to the containers of its kind that chain [chainID] accepts.
+// If [replaced] isn't nil, it's unsubscribed at the same time, so that no
+// container is sent to neither or both of them.
+func (s *Stream) subscribe(chainID ids.ID, sub, replaced *subscriber) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if replaced != nil {
+		delete(s.subscribers[chainID.Key()][replaced.kind], replaced)
+	}
+
+	kinds, exists := s.subscribers[chainID.Key()]
+	if !exists {
+		kinds = make(map[string]map[*subscriber]struct{})
+		s.subscribers[chainID.Key()] = kinds
+	}
+	subscribers, exists := kinds[sub.kind]
+	if !exists {
+		subscribers = make(map[*subscriber]struct{})
+		kinds[sub.kind] = subscribers
+	}
+	subscribers[sub] = struct{}{}
+}
+
+// unsubscribe [sub] from the containers of its kind that chain [chainID]
+// accepts
+func (s *Stream) unsubscribe(chainID ids.ID, sub *subscriber) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	kinds := s.subscribers[chainID.Key()]
+	delete(kinds[sub.kind], sub)
+	if len(kinds[sub.kind]) == 0 {
+		delete(kinds, sub.kind)
+	}
+	if len(kinds) == 0 {
+		delete(s.subscribers, chainID.Key())
+	}
+}
+
+// serve streams the events of the chain in the path of [r] until the client
+// disconnects
+func (s *Stream) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, fmt.Sprintf("method %s isn't supported", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming isn't supported", http.StatusInternalServerError)
+		return
+	}
+
+	chain := mux.Vars(r)[chainVar]
+	chainID, err := s.chainLookup.Lookup(chain)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unknown chain %q", chain), http.StatusNotFound)
+		return
+	}
+	query := r.URL.Query()
+	kind := query.Get("type")
+	switch kind {
+	case "":
+		kind = containers
+	case containers, decisions:
+	default:
+		http.Error(w, fmt.Sprintf("unknown type %q. Should be %q or %q", kind, containers, decisions), http.StatusBadRequest)
+		return
+	}
+	payload := false
+	if value := query.Get("payload"); value != "" {
+		if payload, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, fmt.Sprintf("payload should be true or false but is %q", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	sub := &subscriber{
+		kind:    kind,
+		payload: payload,
+		sink:    newSink(bufferSize),
+	}
+	s.subscribe(chainID, sub, nil)
+	defer s.unsubscribe(chainID, sub)
+	s.log.Debug("streaming the %s accepted by chain %s", kind, chainID)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveFrequency)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event := <-sub.sink.events:
+			if _, err := fmt.Fprintf(w, "event: accept\ndata: %s\n\n", event.data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-sub.sink.dropped:
+			return
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,174 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+var chainID = ids.NewID([32]byte{1})
+
+// newStream returns a stream registered with new dispatchers, and a server
+// that serves it
+func newStream(t *testing.T) (*Stream, *triggers.EventDispatcher, *triggers.EventDispatcher, *httptest.Server) {
+	aliaser := &ids.Aliaser{}
+	aliaser.Initialize()
+	for _, alias := range []string{"X", chainID.String()} {
+		if err := aliaser.Alias(chainID, alias); err != nil {
+			t.Fatal(err)
+		}
+	}
+	decisions := &triggers.EventDispatcher{}
+	decisions.Initialize(logging.NoLog{})
+	consensus := &triggers.EventDispatcher{}
+	consensus.Initialize(logging.NoLog{})
+
+	s := &Stream{}
+	s.Initialize(logging.NoLog{}, aliaser, 12345)
+	if err := s.Register(decisions, consensus); err != nil {
+		t.Fatal(err)
+	}
+	router := mux.NewRouter()
+	router.Handle("/ext/events"+ChainEndpoint, s.CreateHandler().Handler)
+	return s, decisions, consensus, httptest.NewServer(router)
+}
+
+// subscribed waits until [n] clients are subscribed to the stream
+func subscribed(t *testing.T, s *Stream, n int) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		s.lock.Lock()
+		count := 0
+		for _, kinds := range s.subscribers {
+			for _, subscribers := range kinds {
+				count += len(subscribers)
+			}
+		}
+		s.lock.Unlock()
+		if count == n {
+			return
+		}
+	}
+	t.Fatalf("%d clients never subscribed", n)
+}
+
+// readEvent returns the data of the next event from [reader]
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+	data := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" && data != "" {
+			return data
+		}
+		if strings.HasPrefix(line, "data: ") {
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	s, decisions, consensus, server := newStream(t)
+	defer server.Close()
+
+	containers, err := http.Get(server.URL + "/ext/events/X")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer containers.Body.Close()
+	if contentType := containers.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("expected an event stream but got %s", contentType)
+	}
+	payloads, err := http.Get(server.URL + "/ext/events/" + chainID.String() + "?type=decisions&payload=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer payloads.Body.Close()
+	subscribed(t, s, 2)
+
+	vtxID := ids.NewID([32]byte{2})
+	txID := ids.NewID([32]byte{3})
+	consensus.Accept(chainID, vtxID, []byte{1})
+	decisions.Accept(chainID, txID, []byte{2})
+	// Containers of other chains aren't streamed
+	consensus.Accept(ids.NewID([32]byte{4}), txID, []byte{3})
+	consensus.Accept(chainID, txID, []byte{4})
+
+	reader := bufio.NewReader(containers.Body)
+	expected := `{"chainID":"` + chainID.String() + `","id":"` + vtxID.String() + `"}`
+	if data := readEvent(t, reader); data != expected {
+		t.Fatalf("expected %s but got %s", expected, data)
+	}
+	expected = `{"chainID":"` + chainID.String() + `","id":"` + txID.String() + `"}`
+	if data := readEvent(t, reader); data != expected {
+		t.Fatalf("expected %s but got %s", expected, data)
+	}
+
+	bytes, err := (&formatting.CB58{Bytes: []byte{2}}).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"chainID":"` + chainID.String() + `","id":"` + txID.String() + `","bytes":` + string(bytes) + `}`
+	if data := readEvent(t, bufio.NewReader(payloads.Body)); data != expected {
+		t.Fatalf("expected %s but got %s", expected, data)
+	}
+}
+
+func TestStreamInvalidRequests(t *testing.T) {
+	_, _, _, server := newStream(t)
+	defer server.Close()
+
+	for path, status := range map[string]int{
+		"/ext/events/Y":                 http.StatusNotFound,
+		"/ext/events/X?type=blocks":     http.StatusBadRequest,
+		"/ext/events/X?payload=perhaps": http.StatusBadRequest,
+	} {
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != status {
+			t.Fatalf("expected status %d from %s but got %d", status, path, response.StatusCode)
+		}
+	}
+}
+
+func TestStreamDropsSlowClients(t *testing.T) {
+	s := &Stream{}
+	s.Initialize(logging.NoLog{}, nil, 12345)
+	sub := &subscriber{
+		kind: containers,
+		sink: newSink(1),
+	}
+	s.subscribe(chainID, sub, nil)
+
+	s.accept(containers, chainID, ids.NewID([32]byte{2}), nil)
+	s.accept(containers, chainID, ids.NewID([32]byte{3}), nil)
+	select {
+	case <-sub.sink.dropped:
+	default:
+		t.Fatal("a client that fell behind should have been dropped")
+	}
+	s.unsubscribe(chainID, sub)
+	if len(s.subscribers) != 0 {
+		t.Fatal("unsubscribing every client should have forgotten the chain")
+	}
+}
@@ -0,0 +1,365 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/components/address"
+)
+
+const (
+	// WebSocketEndpoint is the endpoint, under the Events API, that clients
+	// connect to over a websocket to subscribe to the events of any chain
+	WebSocketEndpoint = ""
+
+	// Time allowed to write a message to the client
+	wsWriteWait = 10 * time.Second
+
+	// Time allowed to read the next pong message from the client
+	wsPongWait = 60 * time.Second
+
+	// Send pings to the client with this period. Must be less than wsPongWait.
+	wsPingPeriod = (wsPongWait * 9) / 10
+
+	// Largest call a client may send
+	wsMaxMessageSize = 64 * 1024 // bytes
+
+	// Most subscriptions a connection may have, and most IDs and addresses a
+	// subscription may be filtered by
+	maxSubscriptions = 64
+	maxFilters       = 1024
+
+	// Methods that clients call to subscribe to and unsubscribe from the
+	// events of a chain
+	subscribeMethod   = "subscribe"
+	unsubscribeMethod = "unsubscribe"
+
+	// Method of the notifications of events that are pushed to clients
+	acceptMethod = "accept"
+)
+
+var (
+	errTooManySubscriptions = fmt.Errorf("a connection may have at most %d subscriptions", maxSubscriptions)
+	errTooManyFilters       = fmt.Errorf("a subscription may be filtered by at most %d IDs and addresses", maxFilters)
+	errNotSubscribed        = errors.New("not subscribed to these events")
+	errUnknownMethod        = errors.New("unknown method")
+
+	wsUpgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     func(*http.Request) bool { return true },
+	}
+)
+
+// SubscribeArgs are the params of the subscribe and unsubscribe methods
+type SubscribeArgs struct {
+	// ID or alias of the chain
+	Chain string `json:"chain"`
+
+	// Kind of containers, either "containers", the default, or "decisions"
+	Type string `json:"type"`
+
+	// If true, each event holds the bytes of its container
+	Payload bool `json:"payload"`
+
+	// If either isn't empty, only the containers with one of these IDs, or
+	// whose bytes hold one of these addresses, are sent. Subscribing to the
+	// same chain and type again replaces the filters.
+	IDs       []string `json:"ids"`
+	Addresses []string `json:"addresses"`
+}
+
+// SubscribeReply is the result of the subscribe and unsubscribe methods
+type SubscribeReply struct {
+	Success bool `json:"success"`
+}
+
+// Notification is the params of a notification of an event
+type Notification struct {
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// wsRequest is a JSON-RPC 2.0 call of the subscribe or unsubscribe method
+type wsRequest struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params SubscribeArgs    `json:"params"`
+}
+
+// wsResponse is a JSON-RPC 2.0 response to a call
+type wsResponse struct {
+	Version string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  *SubscribeReply  `json:"result,omitempty"`
+	Error   *wsError         `json:"error,omitempty"`
+}
+
+type wsError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// wsNotification is a JSON-RPC 2.0 notification of an event
+type wsNotification struct {
+	Version string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  *Notification `json:"params"`
+}
+
+// CreateWebSocketHandler returns the handler that streams events to clients
+// over websocket connections. It's served at WebSocketEndpoint. Clients call
+// the JSON-RPC methods "subscribe" and "unsubscribe", with SubscribeArgs, and
+// are sent the events they're subscribed to as notifications of the method
+// "accept", with a Notification. A client that falls too far behind is
+// disconnected, so that it can't hold up the chains.
+func (s *Stream) CreateWebSocketHandler() *common.HTTPHandler {
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: &wsServer{stream: s}}
+}
+
+// wsServer upgrades requests to websocket connections that stream events
+type wsServer struct{ stream *Stream }
+
+// HandlesWebSockets implements the api.WebSocketHandler interface
+func (*wsServer) HandlesWebSockets() bool { return true }
+
+func (h *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		http.Error(w, "events are streamed over a websocket connection", http.StatusBadRequest)
+		return
+	}
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.stream.log.Debug("failed to upgrade to a websocket connection due to %s", err)
+		return
+	}
+	c := &wsConn{
+		stream:        h.stream,
+		conn:          conn,
+		sink:          newSink(bufferSize),
+		responses:     make(chan []byte),
+		closed:        make(chan struct{}),
+		writerDone:    make(chan struct{}),
+		subscriptions: make(map[subscription]*subscriber),
+	}
+	go h.stream.log.RecoverAndPanic(c.writePump)
+	c.readPump()
+}
+
+// subscription is a chain, by its key, and a kind of container
+type subscription struct {
+	chainID [32]byte
+	kind    string
+}
+
+// wsConn is a client's websocket connection
+type wsConn struct {
+	stream *Stream
+	conn   *websocket.Conn
+
+	// Events waiting to be written. Every subscription of the client shares
+	// it.
+	sink *sink
+
+	// Responses to the client's calls waiting to be written
+	responses chan []byte
+
+	// Closed once the connection stops being read from, and once it stops
+	// being written to
+	closed, writerDone chan struct{}
+
+	// The client's subscriptions. Only used by readPump.
+	subscriptions map[subscription]*subscriber
+}
+
+// readPump handles the calls the client sends until the connection closes
+func (c *wsConn) readPump() {
+	defer func() {
+		for sub, subscriber := range c.subscriptions {
+			c.stream.unsubscribe(ids.NewID(sub.chainID), subscriber)
+		}
+		close(c.closed)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error { return c.conn.SetReadDeadline(time.Now().Add(wsPongWait)) })
+
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				c.stream.log.Debug("unexpected close of websocket connection: %s", err)
+			}
+			return
+		}
+
+		response, err := json.Marshal(c.handle(msg))
+		if err != nil {
+			c.stream.log.Debug("couldn't marshal a response to a websocket call due to %s", err)
+			continue
+		}
+		// Calls aren't read while the client isn't reading the responses
+		select {
+		case c.responses <- response:
+		case <-c.writerDone:
+			return
+		}
+	}
+}
+
+// handle the call [msg], and return the response to send to the client
+func (c *wsConn) handle(msg []byte) *wsResponse {
+	request := wsRequest{}
+	if err := json.Unmarshal(msg, &request); err != nil {
+		return &wsResponse{Version: "2.0", Error: &wsError{Code: -32700, Message: err.Error()}}
+	}
+
+	response := &wsResponse{Version: "2.0", ID: request.ID}
+	var err error
+	switch request.Method {
+	case subscribeMethod:
+		err = c.subscribe(&request.Params)
+	case unsubscribeMethod:
+		err = c.unsubscribe(&request.Params)
+	default:
+		response.Error = &wsError{Code: -32601, Message: fmt.Sprintf("%s: %s", errUnknownMethod, request.Method)}
+		return response
+	}
+	if err != nil {
+		response.Error = &wsError{Code: -32602, Message: err.Error()}
+	} else {
+		response.Result = &SubscribeReply{Success: true}
+	}
+	return response
+}
+
+// subscribe the client to the events [args] describes, replacing its
+// subscription to the same chain and kind of containers, if it has one
+func (c *wsConn) subscribe(args *SubscribeArgs) error {
+	chainID, kind, err := c.parseSubscription(args)
+	if err != nil {
+		return err
+	}
+	key := subscription{chainID: chainID.Key(), kind: kind}
+	replaced := c.subscriptions[key]
+	if replaced == nil && len(c.subscriptions) >= maxSubscriptions {
+		return errTooManySubscriptions
+	}
+	if len(args.IDs)+len(args.Addresses) > maxFilters {
+		return errTooManyFilters
+	}
+
+	sub := &subscriber{
+		kind:    kind,
+		payload: args.Payload,
+		sink:    c.sink,
+	}
+	for _, idStr := range args.IDs {
+		id, err := ids.FromString(idStr)
+		if err != nil {
+			return fmt.Errorf("couldn't parse ID %q: %w", idStr, err)
+		}
+		sub.ids.Add(id)
+	}
+	for _, addrStr := range args.Addresses {
+		_, addr, err := address.ParseAny(addrStr, c.stream.networkID)
+		if err != nil {
+			return fmt.Errorf("couldn't parse address %q: %w", addrStr, err)
+		}
+		sub.addresses = append(sub.addresses, addr.Bytes())
+	}
+
+	c.stream.subscribe(chainID, sub, replaced)
+	c.subscriptions[key] = sub
+	return nil
+}
+
+// unsubscribe the client from the events [args] describes
+func (c *wsConn) unsubscribe(args *SubscribeArgs) error {
+	chainID, kind, err := c.parseSubscription(args)
+	if err != nil {
+		return err
+	}
+	key := subscription{chainID: chainID.Key(), kind: kind}
+	sub, exists := c.subscriptions[key]
+	if !exists {
+		return errNotSubscribed
+	}
+	c.stream.unsubscribe(chainID, sub)
+	delete(c.subscriptions, key)
+	return nil
+}
+
+// parseSubscription returns the chain and kind of containers [args] describes
+func (c *wsConn) parseSubscription(args *SubscribeArgs) (ids.ID, string, error) {
+	chainID, err := c.stream.chainLookup.Lookup(args.Chain)
+	if err != nil {
+		return ids.ID{}, "", fmt.Errorf("unknown chain %q", args.Chain)
+	}
+	switch args.Type {
+	case "":
+		return chainID, containers, nil
+	case containers, decisions:
+		return chainID, args.Type, nil
+	default:
+		return ids.ID{}, "", fmt.Errorf("unknown type %q. Should be %q or %q", args.Type, containers, decisions)
+	}
+}
+
+// writePump writes the responses and events to the client, and pings the
+// client, until the connection closes or the client falls behind
+func (c *wsConn) writePump() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		close(c.writerDone)
+		c.conn.Close()
+	}()
+
+	for {
+		var err error
+		select {
+		case response := <-c.responses:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = c.conn.WriteMessage(websocket.TextMessage, response)
+		case event := <-c.sink.events:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = c.conn.WriteJSON(&wsNotification{
+				Version: "2.0",
+				Method:  acceptMethod,
+				Params: &Notification{
+					Type:  event.kind,
+					Event: event.data,
+				},
+			})
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = c.conn.WriteMessage(websocket.PingMessage, nil)
+		case <-c.sink.dropped:
+			c.stream.log.Debug("disconnecting a websocket event stream that fell behind")
+			_ = c.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fell behind"),
+				time.Now().Add(wsWriteWait),
+			)
+			return
+		case <-c.closed:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
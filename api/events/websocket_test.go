@@ -0,0 +1,168 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/components/address"
+)
+
+// dialEvents returns a websocket connection to the events of [s]
+func dialEvents(t *testing.T, s *Stream) (*websocket.Conn, func()) {
+	router := mux.NewRouter()
+	router.Handle("/ext/events"+WebSocketEndpoint, s.CreateWebSocketHandler().Handler)
+	server := httptest.NewServer(router)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ext/events", nil)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn, func() {
+		conn.Close()
+		server.Close()
+	}
+}
+
+// call [method] with [args] over [conn], and return the response
+func call(t *testing.T, conn *websocket.Conn, method string, args *SubscribeArgs) *wsResponse {
+	t.Helper()
+	if err := conn.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  args,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	response := &wsResponse{}
+	if err := conn.ReadJSON(response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+// readNotification returns the next notification sent over [conn]
+func readNotification(t *testing.T, conn *websocket.Conn) (*Notification, *Event) {
+	t.Helper()
+	notification := struct {
+		Method string        `json:"method"`
+		Params *Notification `json:"params"`
+	}{}
+	if err := conn.ReadJSON(&notification); err != nil {
+		t.Fatal(err)
+	}
+	if notification.Method != acceptMethod || notification.Params == nil {
+		t.Fatalf("expected a notification but got %+v", notification)
+	}
+	event := &Event{}
+	if err := json.Unmarshal(notification.Params.Event, event); err != nil {
+		t.Fatal(err)
+	}
+	return notification.Params, event
+}
+
+func TestWebSocket(t *testing.T) {
+	s, decisionDispatcher, consensusDispatcher, server := newStream(t)
+	server.Close()
+	conn, closeConn := dialEvents(t, s)
+	defer closeConn()
+
+	addr := ids.NewShortID([20]byte{5})
+	if response := call(t, conn, subscribeMethod, &SubscribeArgs{
+		Chain:     "X",
+		Type:      decisions,
+		Addresses: []string{address.Format("X", addr.Bytes())},
+	}); response.Error != nil || !response.Result.Success {
+		t.Fatalf("subscribing failed: %+v", response.Error)
+	}
+	vtxID := ids.NewID([32]byte{2})
+	if response := call(t, conn, subscribeMethod, &SubscribeArgs{
+		Chain:   chainID.String(),
+		IDs:     []string{vtxID.String()},
+		Payload: true,
+	}); response.Error != nil {
+		t.Fatalf("subscribing failed: %+v", response.Error)
+	}
+
+	// Only the decision that holds the address and the vertex with the ID are
+	// sent
+	txID := ids.NewID([32]byte{3})
+	decisionDispatcher.Accept(chainID, ids.NewID([32]byte{4}), []byte{1, 2, 3})
+	decisionDispatcher.Accept(chainID, txID, append([]byte{1}, addr.Bytes()...))
+	consensusDispatcher.Accept(chainID, ids.NewID([32]byte{6}), nil)
+	consensusDispatcher.Accept(chainID, vtxID, []byte{7})
+
+	notification, event := readNotification(t, conn)
+	if notification.Type != decisions || event.ID != txID.String() || event.Bytes != nil {
+		t.Fatalf("unexpected notification of %s %+v", notification.Type, event)
+	}
+	notification, event = readNotification(t, conn)
+	if notification.Type != containers || event.ID != vtxID.String() || event.Bytes == nil || event.Bytes.Bytes[0] != 7 {
+		t.Fatalf("unexpected notification of %s %+v", notification.Type, event)
+	}
+
+	if response := call(t, conn, unsubscribeMethod, &SubscribeArgs{Chain: "X", Type: decisions}); response.Error != nil {
+		t.Fatalf("unsubscribing failed: %+v", response.Error)
+	}
+	if response := call(t, conn, unsubscribeMethod, &SubscribeArgs{Chain: "X", Type: decisions}); response.Error == nil {
+		t.Fatalf("shouldn't have unsubscribed twice")
+	}
+	subscribed(t, s, 1)
+}
+
+func TestWebSocketInvalidSubscriptions(t *testing.T) {
+	s, _, _, server := newStream(t)
+	server.Close()
+	conn, closeConn := dialEvents(t, s)
+	defer closeConn()
+
+	for _, args := range []*SubscribeArgs{
+		{Chain: "Y"},
+		{Chain: "X", Type: "blocks"},
+		{Chain: "X", IDs: []string{"not an ID"}},
+		{Chain: "X", Addresses: []string{"not an address"}},
+	} {
+		if response := call(t, conn, subscribeMethod, args); response.Error == nil {
+			t.Fatalf("subscribing with %+v should have failed", args)
+		}
+	}
+	if response := call(t, conn, "publish", &SubscribeArgs{Chain: "X"}); response.Error == nil {
+		t.Fatalf("calling an unknown method should have failed")
+	}
+	subscribed(t, s, 0)
+}
+
+func TestWebSocketDropsSlowClients(t *testing.T) {
+	s, _, consensus, server := newStream(t)
+	server.Close()
+	conn, closeConn := dialEvents(t, s)
+	defer closeConn()
+
+	if response := call(t, conn, subscribeMethod, &SubscribeArgs{Chain: "X"}); response.Error != nil {
+		t.Fatalf("subscribing failed: %+v", response.Error)
+	}
+	// The client doesn't read the events, so they pile up until it's dropped
+	for i := 0; i < 100*bufferSize; i++ {
+		consensus.Accept(chainID, ids.Empty, make([]byte, 1024))
+	}
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+				t.Fatalf("expected the client to be dropped but got %s", err)
+			}
+			break
+		}
+	}
+	subscribed(t, s, 0)
+}
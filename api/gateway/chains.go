@@ -0,0 +1,127 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gateway
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ava-labs/gecko/api/gateway/gatewayproto"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+// Caller calls the JSON-RPC APIs of chains
+type Caller interface {
+	ChainMethods(chainID ids.ID) []string
+	CallChain(chainID ids.ID, method string, args, reply interface{}) error
+}
+
+// chainsServer serves the Chains service by calling the issueTx and
+// getTxStatus methods of the chains' APIs
+type chainsServer struct {
+	gatewayproto.UnimplementedChainsServer
+
+	chains ChainManager
+	caller Caller
+}
+
+func (s *chainsServer) IssueTx(_ context.Context, req *gatewayproto.IssueTxRequest) (*gatewayproto.IssueTxResponse, error) {
+	chainID, method, err := s.method(req.Chain, "issueTx")
+	if err != nil {
+		return nil, err
+	}
+
+	reply := struct {
+		TxID ids.ID `json:"txID"`
+	}{}
+	args := map[string]string{"tx": formatting.CB58{Bytes: req.Tx}.String()}
+	if err := s.caller.CallChain(chainID, method, args, &reply); err != nil {
+		return nil, err
+	}
+	if reply.TxID.IsZero() {
+		return nil, status.Errorf(codes.Internal, "chain %s didn't return the ID of the issued transaction", req.Chain)
+	}
+	return &gatewayproto.IssueTxResponse{TxID: reply.TxID.Bytes()}, nil
+}
+
+func (s *chainsServer) GetTxStatus(_ context.Context, req *gatewayproto.TxStatusRequest) (*gatewayproto.TxStatusResponse, error) {
+	txStatus, err := s.txStatus(req)
+	if err != nil {
+		return nil, err
+	}
+	return &gatewayproto.TxStatusResponse{Status: txStatus.String()}, nil
+}
+
+// WatchTx sends the status of a transaction whenever it changes, until the
+// transaction is decided
+func (s *chainsServer) WatchTx(req *gatewayproto.TxStatusRequest, stream gatewayproto.Chains_WatchTxServer) error {
+	ticker := time.NewTicker(watchFrequency)
+	defer ticker.Stop()
+
+	sent := false
+	last := choices.Unknown
+	for {
+		txStatus, err := s.txStatus(req)
+		if err != nil {
+			return err
+		}
+		if !sent || txStatus != last {
+			if err := stream.Send(&gatewayproto.TxStatusResponse{Status: txStatus.String()}); err != nil {
+				return err
+			}
+			sent = true
+			last = txStatus
+		}
+		if txStatus.Decided() {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *chainsServer) txStatus(req *gatewayproto.TxStatusRequest) (choices.Status, error) {
+	txID, err := ids.ToID(req.TxID)
+	if err != nil {
+		return choices.Unknown, status.Error(codes.InvalidArgument, err.Error())
+	}
+	chainID, method, err := s.method(req.Chain, "getTxStatus")
+	if err != nil {
+		return choices.Unknown, err
+	}
+
+	reply := struct {
+		Status choices.Status `json:"status"`
+	}{}
+	args := map[string]string{"txID": txID.String()}
+	if err := s.caller.CallChain(chainID, method, args, &reply); err != nil {
+		return choices.Unknown, err
+	}
+	return reply.Status, nil
+}
+
+// method returns the ID of [chain] and the full name of the method of its API
+// named [name], such as "avm.issueTx"
+func (s *chainsServer) method(chain, name string) (ids.ID, string, error) {
+	chainID, err := s.chains.Lookup(chain)
+	if err != nil {
+		return ids.ID{}, "", status.Errorf(codes.NotFound, "couldn't find chain %q", chain)
+	}
+	for _, method := range s.caller.ChainMethods(chainID) {
+		if strings.HasSuffix(method, "."+name) {
+			return chainID, method, nil
+		}
+	}
+	return ids.ID{}, "", status.Errorf(codes.Unimplemented, "chain %q has no %s method", chain, name)
+}
@@ -0,0 +1,184 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ava-labs/gecko/api"
+	"github.com/ava-labs/gecko/api/gateway/gatewayproto"
+	"github.com/ava-labs/gecko/api/health"
+	"github.com/ava-labs/gecko/api/keystore"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// endpoints are the HTTP API endpoints whose authorization tokens authorize
+// calls to each gRPC service. Calls to the Chains service are authorized by the
+// endpoint of the chain they're made to.
+var endpoints = map[string]string{
+	"gatewayproto.Info":     "admin",
+	"gatewayproto.Health":   "health",
+	"gatewayproto.Keystore": "keystore",
+}
+
+// chainRequest is a request made to a chain
+type chainRequest interface {
+	GetChain() string
+}
+
+// Gateway serves the node's core services over gRPC, on a port separate from
+// the HTTP API, for clients that want typed calls and streaming. Services are
+// registered before the gateway is dispatched.
+type Gateway struct {
+	log        logging.Logger
+	portURL    string
+	authorizer api.Authorizer
+	server     *grpc.Server
+}
+
+// Initialize creates the gateway at the provided port. If [certFile] isn't
+// empty, the gateway serves TLS with the certificate in it and the key in
+// [keyFile]. If [authorizer] isn't nil, calls must carry a token in their
+// "authorization" metadata, as HTTP API requests do in their Authorization
+// header.
+func (g *Gateway) Initialize(log logging.Logger, port uint16, certFile, keyFile string, authorizer api.Authorizer) error {
+	g.log = log
+	g.portURL = fmt.Sprintf(":%d", port)
+	g.authorizer = authorizer
+
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(g.authorizeUnary),
+		grpc.StreamInterceptor(g.authorizeStream),
+	}
+	if certFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	g.server = grpc.NewServer(opts...)
+	return nil
+}
+
+// RegisterInfo serves the Info service, which describes this node and the
+// chains it runs
+func (g *Gateway) RegisterInfo(nodeID ids.ShortID, nodeVersion string, networkID uint32, chains ChainManager) {
+	gatewayproto.RegisterInfoServer(g.server, &infoServer{
+		nodeID:      nodeID,
+		nodeVersion: nodeVersion,
+		networkID:   networkID,
+		chains:      chains,
+	})
+}
+
+// RegisterHealth serves the Health service, which reports the checks of [h]
+func (g *Gateway) RegisterHealth(h *health.Health) {
+	gatewayproto.RegisterHealthServer(g.server, &healthServer{health: h})
+}
+
+// RegisterKeystore serves the Keystore service, which manages the users of
+// [ks]
+func (g *Gateway) RegisterKeystore(ks *keystore.Keystore) {
+	gatewayproto.RegisterKeystoreServer(g.server, &keystoreServer{keystore: ks})
+}
+
+// RegisterChains serves the Chains service, which issues transactions to
+// chains and reports their status through the chains' JSON-RPC APIs
+func (g *Gateway) RegisterChains(chains ChainManager, caller Caller) {
+	gatewayproto.RegisterChainsServer(g.server, &chainsServer{chains: chains, caller: caller})
+}
+
+// Dispatch starts the gateway
+func (g *Gateway) Dispatch() error {
+	listener, err := net.Listen("tcp", g.portURL)
+	if err != nil {
+		return err
+	}
+	return g.server.Serve(listener)
+}
+
+// Shutdown stops the gateway, after the calls being served finish
+func (g *Gateway) Shutdown() {
+	if g.server != nil {
+		g.server.GracefulStop()
+	}
+}
+
+// authorizeUnary authorizes calls before they're served
+func (g *Gateway) authorizeUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := g.authorize(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authorizeStream authorizes streams when their request is received, as calls
+// to the Chains service are authorized by the chain in the request
+func (g *Gateway) authorizeStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &authorizedStream{
+		ServerStream: stream,
+		authorize: func(req interface{}) error {
+			return g.authorize(stream.Context(), info.FullMethod, req)
+		},
+	})
+}
+
+// authorize returns an error with status Unauthenticated if the call to
+// [fullMethod] with the request [req] isn't authorized
+func (g *Gateway) authorize(ctx context.Context, fullMethod string, req interface{}) error {
+	if g.authorizer == nil {
+		return nil
+	}
+
+	// Full methods are of the form "/gatewayproto.Service/Method"
+	service := strings.SplitN(strings.TrimPrefix(fullMethod, "/"), "/", 2)[0]
+	endpoint, ok := endpoints[service]
+	if !ok {
+		chainReq, ok := req.(chainRequest)
+		if !ok {
+			return status.Errorf(codes.PermissionDenied, "unknown service %s", service)
+		}
+		endpoint = "bc/" + chainReq.GetChain()
+	}
+
+	r := &http.Request{
+		URL:    &url.URL{Path: "/ext/" + endpoint},
+		Header: make(http.Header),
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			r.Header.Set("Authorization", values[0])
+		}
+	}
+	if err := g.authorizer.Authorize(r); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+// authorizedStream authorizes the request of a stream when it's received
+type authorizedStream struct {
+	grpc.ServerStream
+	authorize func(req interface{}) error
+}
+
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.authorize(m)
+}
@@ -0,0 +1,150 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ava-labs/gecko/api/gateway/gatewayproto"
+	"github.com/ava-labs/gecko/api/keystore"
+	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+type testChains struct{ chainID ids.ID }
+
+func (c *testChains) Lookup(alias string) (ids.ID, error) {
+	if alias != "X" {
+		return ids.ID{}, errors.New("unknown alias")
+	}
+	return c.chainID, nil
+}
+
+func (c *testChains) Aliases(ids.ID) []string { return []string{"X"} }
+
+func (c *testChains) SubnetID(ids.ID) (ids.ID, bool) { return ids.Empty, true }
+
+func (c *testChains) BootstrapProgress() []chains.ChainProgress {
+	return []chains.ChainProgress{{
+		ChainID:        c.chainID,
+		ProgressReport: common.ProgressReport{Phase: common.Bootstrapped},
+	}}
+}
+
+// testAuthorizer authorizes requests to [endpoint] with the token "token"
+type testAuthorizer struct{ endpoint string }
+
+func (a *testAuthorizer) Authorize(r *http.Request) error {
+	if r.URL.Path != "/ext/"+a.endpoint || r.Header.Get("Authorization") != "Bearer token" {
+		return errors.New("unauthorized")
+	}
+	return nil
+}
+
+// dial serves [g] and returns a connection to it
+func dial(t *testing.T, g *Gateway) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	go g.server.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestInfo(t *testing.T) {
+	g := Gateway{}
+	if err := g.Initialize(logging.NoLog{}, 0, "", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	defer g.Shutdown()
+
+	chainID := ids.NewID([32]byte{1})
+	nodeID := ids.NewShortID([20]byte{2})
+	g.RegisterInfo(nodeID, "avalanche/0.0.1", 12345, &testChains{chainID: chainID})
+
+	conn := dial(t, &g)
+	defer conn.Close()
+	client := gatewayproto.NewInfoClient(conn)
+
+	nodeIDResponse, err := client.GetNodeID(context.Background(), &gatewayproto.GetNodeIDRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotID, err := ids.ToShortID(nodeIDResponse.NodeID); err != nil || !gotID.Equals(nodeID) {
+		t.Fatalf("wrong node ID")
+	}
+
+	chainsResponse, err := client.GetChains(context.Background(), &gatewayproto.GetChainsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chainsResponse.Chains) != 1 {
+		t.Fatalf("should have returned one chain but returned %d", len(chainsResponse.Chains))
+	}
+	chain := chainsResponse.Chains[0]
+	if gotID, err := ids.ToID(chain.ChainID); err != nil || !gotID.Equals(chainID) {
+		t.Fatalf("wrong chain ID")
+	}
+	if len(chain.Aliases) != 1 || chain.Aliases[0] != "X" || !chain.Bootstrapped {
+		t.Fatalf("wrong chain: %+v", chain)
+	}
+}
+
+func TestKeystoreAuthorization(t *testing.T) {
+	g := Gateway{}
+	if err := g.Initialize(logging.NoLog{}, 0, "", "", &testAuthorizer{endpoint: "keystore"}); err != nil {
+		t.Fatal(err)
+	}
+	defer g.Shutdown()
+
+	ks := &keystore.Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+	g.RegisterKeystore(ks)
+
+	conn := dial(t, &g)
+	defer conn.Close()
+	client := gatewayproto.NewKeystoreClient(conn)
+
+	_, err := client.ListUsers(context.Background(), &gatewayproto.ListUsersRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("should have required a token but returned %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+	if _, err := client.CreateUser(ctx, &gatewayproto.CreateUserRequest{Username: "bob", Password: "launchpad#2020"}); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := client.ExportUser(ctx, &gatewayproto.ExportUserRequest{Username: "bob", Password: "launchpad#2020"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ImportUser(ctx, &gatewayproto.ImportUserRequest{Username: "alice", Password: "launchpad#2020", User: exported.User}); err != nil {
+		t.Fatal(err)
+	}
+	users, err := client.ListUsers(ctx, &gatewayproto.ListUsersRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users.Users) != 2 {
+		t.Fatalf("should have listed the created and imported users but listed %v", users.Users)
+	}
+}
@@ -0,0 +1,95 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/gecko/api/gateway/gatewayproto"
+	"github.com/ava-labs/gecko/api/health"
+)
+
+// watchFrequency is how often watched values are polled for changes
+var watchFrequency = time.Second
+
+// healthServer serves the Health service
+type healthServer struct {
+	gatewayproto.UnimplementedHealthServer
+
+	health *health.Health
+}
+
+// Check returns the results of the node's liveness or readiness checks
+func (s *healthServer) Check(_ context.Context, req *gatewayproto.HealthRequest) (*gatewayproto.HealthResponse, error) {
+	return s.report(req.Readiness)
+}
+
+// Watch sends the node's health, then sends it again whenever whether the node
+// is healthy changes
+func (s *healthServer) Watch(req *gatewayproto.HealthRequest, stream gatewayproto.Health_WatchServer) error {
+	ticker := time.NewTicker(watchFrequency)
+	defer ticker.Stop()
+
+	sent := false
+	healthy := false
+	for {
+		response, err := s.report(req.Readiness)
+		if err != nil {
+			return err
+		}
+		if !sent || response.Healthy != healthy {
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+			sent = true
+			healthy = response.Healthy
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *healthServer) report(readiness bool) (*gatewayproto.HealthResponse, error) {
+	results, healthy := s.health.Liveness()
+	if readiness {
+		results, healthy = s.health.Readiness()
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	response := &gatewayproto.HealthResponse{
+		Healthy: healthy,
+		Checks:  make([]*gatewayproto.CheckResult, len(names)),
+	}
+	for i, name := range names {
+		result := results[name]
+		check := &gatewayproto.CheckResult{
+			Name:               name,
+			Healthy:            result.Healthy,
+			Error:              result.Error,
+			Timestamp:          result.Timestamp.UnixNano(),
+			ContiguousFailures: uint32(result.ContiguousFailures),
+		}
+		if result.Details != nil {
+			details, err := json.Marshal(result.Details)
+			if err != nil {
+				return nil, err
+			}
+			check.Details = details
+		}
+		response.Checks[i] = check
+	}
+	return response, nil
+}
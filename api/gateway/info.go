@@ -0,0 +1,70 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/ava-labs/gecko/api/gateway/gatewayproto"
+	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+)
+
+// ChainManager is the part of the chain manager the gateway uses to describe
+// and find chains
+type ChainManager interface {
+	Lookup(string) (ids.ID, error)
+	Aliases(ids.ID) []string
+	SubnetID(ids.ID) (ids.ID, bool)
+	BootstrapProgress() []chains.ChainProgress
+}
+
+// infoServer serves the Info service
+type infoServer struct {
+	gatewayproto.UnimplementedInfoServer
+
+	nodeID      ids.ShortID
+	nodeVersion string
+	networkID   uint32
+	chains      ChainManager
+}
+
+func (s *infoServer) GetNodeID(context.Context, *gatewayproto.GetNodeIDRequest) (*gatewayproto.GetNodeIDResponse, error) {
+	return &gatewayproto.GetNodeIDResponse{NodeID: s.nodeID.Bytes()}, nil
+}
+
+func (s *infoServer) GetNodeVersion(context.Context, *gatewayproto.GetNodeVersionRequest) (*gatewayproto.GetNodeVersionResponse, error) {
+	return &gatewayproto.GetNodeVersionResponse{Version: s.nodeVersion}, nil
+}
+
+func (s *infoServer) GetNetworkID(context.Context, *gatewayproto.GetNetworkIDRequest) (*gatewayproto.GetNetworkIDResponse, error) {
+	return &gatewayproto.GetNetworkIDResponse{NetworkID: s.networkID}, nil
+}
+
+// GetChains returns the chains this node runs, sorted by ID
+func (s *infoServer) GetChains(context.Context, *gatewayproto.GetChainsRequest) (*gatewayproto.GetChainsResponse, error) {
+	progress := s.chains.BootstrapProgress()
+	response := &gatewayproto.GetChainsResponse{
+		Chains: make([]*gatewayproto.Chain, len(progress)),
+	}
+	for i, chain := range progress {
+		c := &gatewayproto.Chain{
+			ChainID:      chain.ChainID.Bytes(),
+			Aliases:      s.chains.Aliases(chain.ChainID),
+			Phase:        chain.Phase.String(),
+			Bootstrapped: chain.Phase == common.Bootstrapped,
+		}
+		if subnetID, ok := s.chains.SubnetID(chain.ChainID); ok {
+			c.SubnetID = subnetID.Bytes()
+		}
+		response.Chains[i] = c
+	}
+	sort.Slice(response.Chains, func(i, j int) bool {
+		return bytes.Compare(response.Chains[i].ChainID, response.Chains[j].ChainID) < 0
+	})
+	return response, nil
+}
@@ -0,0 +1,69 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gateway
+
+import (
+	"context"
+
+	"github.com/ava-labs/gecko/api/gateway/gatewayproto"
+	"github.com/ava-labs/gecko/api/keystore"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+// keystoreServer serves the Keystore service by calling the keystore's API
+type keystoreServer struct {
+	gatewayproto.UnimplementedKeystoreServer
+
+	keystore *keystore.Keystore
+}
+
+func (s *keystoreServer) CreateUser(_ context.Context, req *gatewayproto.CreateUserRequest) (*gatewayproto.CreateUserResponse, error) {
+	reply := keystore.CreateUserReply{}
+	err := s.keystore.CreateUser(nil, &keystore.CreateUserArgs{
+		Username: req.Username,
+		Password: req.Password,
+	}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &gatewayproto.CreateUserResponse{}, nil
+}
+
+func (s *keystoreServer) ListUsers(context.Context, *gatewayproto.ListUsersRequest) (*gatewayproto.ListUsersResponse, error) {
+	reply := keystore.ListUsersReply{}
+	if err := s.keystore.ListUsers(nil, &keystore.ListUsersArgs{}, &reply); err != nil {
+		return nil, err
+	}
+	return &gatewayproto.ListUsersResponse{Users: reply.Users}, nil
+}
+
+func (s *keystoreServer) ExportUser(_ context.Context, req *gatewayproto.ExportUserRequest) (*gatewayproto.ExportUserResponse, error) {
+	reply := keystore.ExportUserReply{}
+	err := s.keystore.ExportUser(nil, &keystore.ExportUserArgs{
+		Username: req.Username,
+		Password: req.Password,
+	}, &reply)
+	if err != nil {
+		return nil, err
+	}
+
+	user := formatting.CB58{}
+	if err := user.FromString(reply.User); err != nil {
+		return nil, err
+	}
+	return &gatewayproto.ExportUserResponse{User: user.Bytes}, nil
+}
+
+func (s *keystoreServer) ImportUser(_ context.Context, req *gatewayproto.ImportUserRequest) (*gatewayproto.ImportUserResponse, error) {
+	reply := keystore.ImportUserReply{}
+	err := s.keystore.ImportUser(nil, &keystore.ImportUserArgs{
+		Username: req.Username,
+		Password: req.Password,
+		User:     formatting.CB58{Bytes: req.User}.String(),
+	}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &gatewayproto.ImportUserResponse{}, nil
+}
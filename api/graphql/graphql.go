@@ -0,0 +1,470 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package graphql executes GraphQL queries against a schema of objects whose
+// fields are resolved by Go functions. Queries may use aliases, arguments,
+// variables, fragments and the @skip and @include directives. Mutations,
+// subscriptions and introspection aren't supported.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+const (
+	// maxDepth is how deeply selection sets may be nested in a query
+	maxDepth = 16
+)
+
+var (
+	errNoOperation   = errors.New("query has no operations")
+	errUnexpectedEnd = errors.New("unexpected end of query")
+	errNoQuery       = errors.New("request has no query")
+)
+
+// Resolver returns the value of a field of [source], which is the value the
+// object the field belongs to was resolved to. [args] are the arguments the
+// field was queried with.
+type Resolver func(source interface{}, args Args) (interface{}, error)
+
+// Field of an object
+type Field struct {
+	// Type of the field's value if it's an object, or a list of objects, in
+	// which case the resolver returns a slice. If nil, the value is returned
+	// in the response as it's marshalled to JSON.
+	Type *Object
+
+	Resolve Resolver
+}
+
+// Object is a type whose fields are selected in a query
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Schema is the types a query is executed against
+type Schema struct {
+	// Query is the type of the root of every query
+	Query *Object
+
+	// Root is the value the fields of Query are resolved on
+	Root interface{}
+}
+
+// Request is a query and the variables it's executed with
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response is the result of executing a query. Data is omitted if the query
+// couldn't be executed.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error is an error in executing a query. If the error was resolving a field,
+// Path is the keys and list indices that lead to the field in Data.
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Execute the query in [request] against [schema]
+func (schema *Schema) Execute(request *Request) *Response {
+	doc, err := parse(request.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	op, err := doc.operation(request.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	variables, err := op.variableValues(request.Variables)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{
+		doc:       doc,
+		variables: variables,
+	}
+	data := e.selectionSet(schema.Query, schema.Root, op.selections, nil)
+	return &Response{
+		Data:   data,
+		Errors: e.errors,
+	}
+}
+
+// operation returns the operation named [name], or the only operation if
+// [name] is empty
+func (doc *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) != 1 {
+			return nil, errors.New("operationName must be given when the query has more than one operation")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("query has no operation named %q", name)
+}
+
+// variableValues returns the values of the operation's variables given
+// [values]
+func (op *operation) variableValues(values map[string]interface{}) (map[string]interface{}, error) {
+	variables := make(map[string]interface{}, len(op.variables))
+	for _, def := range op.variables {
+		value, ok := values[def.name]
+		if !ok {
+			value = def.defaultValue
+		}
+		value, err := resolveValue(value, nil)
+		if err != nil {
+			return nil, err
+		}
+		variables[def.name] = value
+	}
+	return variables, nil
+}
+
+// executor executes an operation of a document
+type executor struct {
+	doc       *document
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+// selectionSet returns the fields [selections] selects of [source], whose
+// type is [object]
+func (e *executor) selectionSet(object *Object, source interface{}, selections []selection, path []interface{}) *orderedMap {
+	result := &orderedMap{}
+	if depth := len(path); depth > maxDepth {
+		e.error(path, fmt.Errorf("query is nested more than %d levels deep", maxDepth))
+		return result
+	}
+
+	keys, fields, err := e.collect(object, selections, nil, nil, map[string]bool{})
+	if err != nil {
+		e.error(path, err)
+		return result
+	}
+	for _, key := range keys {
+		fieldPath := append(append([]interface{}(nil), path...), key)
+		result.set(key, e.field(object, source, fields[key], fieldPath))
+	}
+	return result
+}
+
+// collect the fields [selections] selects of an object of type [object]. The
+// fields are returned by the key they're given in the response, with the keys
+// in the order they were selected in.
+func (e *executor) collect(object *Object, selections []selection, keys []string, fields map[string][]*field, visited map[string]bool) ([]string, map[string][]*field, error) {
+	if fields == nil {
+		fields = make(map[string][]*field)
+	}
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if include, err := e.include(sel.directives); err != nil {
+				return nil, nil, err
+			} else if !include {
+				continue
+			}
+			key := sel.key()
+			if _, exists := fields[key]; !exists {
+				keys = append(keys, key)
+			}
+			fields[key] = append(fields[key], sel)
+		case *fragmentSpread:
+			if include, err := e.include(sel.directives); err != nil {
+				return nil, nil, err
+			} else if !include {
+				continue
+			}
+			if visited[sel.name] {
+				continue
+			}
+			visited[sel.name] = true
+			frag, ok := e.doc.fragments[sel.name]
+			if !ok {
+				return nil, nil, fmt.Errorf("unknown fragment %q", sel.name)
+			}
+			if frag.typeCondition != object.Name {
+				continue
+			}
+			var err error
+			if keys, fields, err = e.collect(object, frag.selections, keys, fields, visited); err != nil {
+				return nil, nil, err
+			}
+		case *inlineFragment:
+			if include, err := e.include(sel.directives); err != nil {
+				return nil, nil, err
+			} else if !include {
+				continue
+			}
+			if sel.typeCondition != "" && sel.typeCondition != object.Name {
+				continue
+			}
+			var err error
+			if keys, fields, err = e.collect(object, sel.selections, keys, fields, visited); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return keys, fields, nil
+}
+
+// include returns false if [directives] skip the selection they're on
+func (e *executor) include(directives []*directive) (bool, error) {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			return false, fmt.Errorf("unknown directive @%s", d.name)
+		}
+		args, err := e.arguments(d.arguments)
+		if err != nil {
+			return false, err
+		}
+		condition, ok := args["if"].(bool)
+		if !ok {
+			return false, fmt.Errorf("@%s must have a boolean argument \"if\"", d.name)
+		}
+		if condition == (d.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// field returns the value of the field [fields] select of [source]. Fields
+// selected more than once under the same key are merged.
+func (e *executor) field(object *Object, source interface{}, fields []*field, path []interface{}) interface{} {
+	f := fields[0]
+	if f.name == "__typename" {
+		return object.Name
+	}
+	def, ok := object.Fields[f.name]
+	if !ok {
+		e.error(path, fmt.Errorf("type %s has no field %q", object.Name, f.name))
+		return nil
+	}
+	args, err := e.arguments(f.arguments)
+	if err != nil {
+		e.error(path, err)
+		return nil
+	}
+	value, err := def.Resolve(source, args)
+	if err != nil {
+		e.error(path, err)
+		return nil
+	}
+
+	selections := []selection(nil)
+	for _, f := range fields {
+		selections = append(selections, f.selections...)
+	}
+	switch {
+	case def.Type == nil && len(selections) != 0:
+		e.error(path, fmt.Errorf("field %q of type %s can't have a selection of subfields", f.name, object.Name))
+		return nil
+	case def.Type == nil:
+		return value
+	case len(selections) == 0:
+		e.error(path, fmt.Errorf("field %q of type %s must have a selection of subfields", f.name, object.Name))
+		return nil
+	}
+	return e.complete(def.Type, value, selections, path)
+}
+
+// complete returns the fields [selections] select of [value], which is an
+// object of type [object] or a slice of them
+func (e *executor) complete(object *Object, value interface{}, selections []selection, path []interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			itemPath := append(append([]interface{}(nil), path...), i)
+			list[i] = e.selectionSet(object, v.Index(i).Interface(), selections, itemPath)
+		}
+		return list
+	}
+	return e.selectionSet(object, value, selections, path)
+}
+
+// arguments returns the values of [args], with their variables resolved
+func (e *executor) arguments(args map[string]interface{}) (Args, error) {
+	values := make(Args, len(args))
+	for name, arg := range args {
+		value, err := resolveValue(arg, e.variables)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+func (e *executor) error(path []interface{}, err error) {
+	e.errors = append(e.errors, &Error{
+		Message: err.Error(),
+		Path:    path,
+	})
+}
+
+// resolveValue returns the Go value of [value], with the variables it refers
+// to set to their values in [variables]. Numbers given in variables are
+// int64s if they're integers and float64s otherwise.
+func resolveValue(value interface{}, variables map[string]interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case variableRef:
+		v, ok := variables[string(value)]
+		if !ok {
+			return nil, fmt.Errorf("variable $%s isn't defined", value)
+		}
+		return v, nil
+	case listValue:
+		list := make([]interface{}, len(value))
+		for i, item := range value {
+			v, err := resolveValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	case objectValue:
+		object := make(map[string]interface{}, len(value))
+		for name, item := range value {
+			v, err := resolveValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			object[name] = v
+		}
+		return object, nil
+	case []interface{}:
+		return resolveValue(listValue(value), variables)
+	case map[string]interface{}:
+		return resolveValue(objectValue(value), variables)
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n, nil
+		}
+		return value.Float64()
+	case float64:
+		if n := int64(value); float64(n) == value {
+			return n, nil
+		}
+		return value, nil
+	}
+	return value, nil
+}
+
+// Args are the values of the arguments a field was queried with
+type Args map[string]interface{}
+
+// String returns the argument [name], or [def] if it wasn't given
+func (a Args) String(name, def string) (string, error) {
+	switch value := a[name].(type) {
+	case nil:
+		return def, nil
+	case string:
+		return value, nil
+	default:
+		return "", fmt.Errorf("argument %q must be a string", name)
+	}
+}
+
+// Uint64 returns the argument [name], or [def] if it wasn't given. The
+// argument may be given as an integer or, as values too large to be integers
+// in JSON are, as a decimal string.
+func (a Args) Uint64(name string, def uint64) (uint64, error) {
+	switch value := a[name].(type) {
+	case nil:
+		return def, nil
+	case int64:
+		if value >= 0 {
+			return uint64(value), nil
+		}
+	case string:
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("argument %q must be a non-negative integer", name)
+}
+
+// Bool returns the argument [name], or [def] if it wasn't given
+func (a Args) Bool(name string, def bool) (bool, error) {
+	switch value := a[name].(type) {
+	case nil:
+		return def, nil
+	case bool:
+		return value, nil
+	default:
+		return false, fmt.Errorf("argument %q must be a boolean", name)
+	}
+}
+
+// orderedMap is an object in a response. Its fields are marshalled in the
+// order they were selected in.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	b := bytes.Buffer{}
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		keyBytes, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueBytes, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(keyBytes)
+		b.WriteByte(':')
+		b.Write(valueBytes)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
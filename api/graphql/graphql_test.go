@@ -0,0 +1,173 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type book struct {
+	title string
+	pages uint64
+}
+
+func testSchema() *Schema {
+	books := []*book{
+		{title: "a", pages: 10},
+		{title: "b", pages: 20},
+		{title: "c", pages: 30},
+	}
+	bookType := &Object{
+		Name: "Book",
+		Fields: map[string]*Field{
+			"title": {Resolve: func(b interface{}, _ Args) (interface{}, error) { return b.(*book).title, nil }},
+			"pages": {Resolve: func(b interface{}, _ Args) (interface{}, error) { return b.(*book).pages, nil }},
+			"error": {Resolve: func(interface{}, Args) (interface{}, error) { return nil, errors.New("failed") }},
+		},
+	}
+	return &Schema{
+		Query: &Object{
+			Name: "Query",
+			Fields: map[string]*Field{
+				"books": {
+					Type: bookType,
+					Resolve: func(_ interface{}, args Args) (interface{}, error) {
+						first, err := args.Uint64("first", uint64(len(books)))
+						if err != nil {
+							return nil, err
+						}
+						if first > uint64(len(books)) {
+							first = uint64(len(books))
+						}
+						return books[:first], nil
+					},
+				},
+				"book": {
+					Type: bookType,
+					Resolve: func(_ interface{}, args Args) (interface{}, error) {
+						title, err := args.String("title", "")
+						if err != nil {
+							return nil, err
+						}
+						for _, b := range books {
+							if b.title == title {
+								return b, nil
+							}
+						}
+						return (*book)(nil), nil
+					},
+				},
+			},
+		},
+	}
+}
+
+// execute [query] and return the response as JSON
+func execute(t *testing.T, query string, variables map[string]interface{}) string {
+	t.Helper()
+	response := testSchema().Execute(&Request{Query: query, Variables: variables})
+	b, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name, query string
+		variables   map[string]interface{}
+		expected    string
+	}{
+		{
+			name:     "fields in selection order",
+			query:    `{ books { pages title } }`,
+			expected: `{"data":{"books":[{"pages":10,"title":"a"},{"pages":20,"title":"b"},{"pages":30,"title":"c"}]}}`,
+		},
+		{
+			name:     "arguments and aliases",
+			query:    `query { first: books(first: 1) { title } b: book(title: "b") { pages, __typename } }`,
+			expected: `{"data":{"first":[{"title":"a"}],"b":{"pages":20,"__typename":"Book"}}}`,
+		},
+		{
+			name:      "variables",
+			query:     `query Q($n: Int, $t: String = "c") { books(first: $n) { title } book(title: $t) { pages } }`,
+			variables: map[string]interface{}{"n": json.Number("2")},
+			expected:  `{"data":{"books":[{"title":"a"},{"title":"b"}],"book":{"pages":30}}}`,
+		},
+		{
+			name:     "fragments and directives",
+			query:    `{ book(title: "a") { ...F ... on Book { pages } title @skip(if: true) } } fragment F on Book { title @include(if: true) }`,
+			expected: `{"data":{"book":{"title":"a","pages":10}}}`,
+		},
+		{
+			name:     "null object",
+			query:    `{ book(title: "d") { title } }`,
+			expected: `{"data":{"book":null}}`,
+		},
+		{
+			name:     "field error",
+			query:    `{ book(title: "a") { title error } }`,
+			expected: `{"data":{"book":{"title":"a","error":null}},"errors":[{"message":"failed","path":["book","error"]}]}`,
+		},
+		{
+			name:     "unknown field",
+			query:    `{ books(first: 1) { isbn } }`,
+			expected: `{"data":{"books":[{"isbn":null}]},"errors":[{"message":"type Book has no field \"isbn\"","path":["books",0,"isbn"]}]}`,
+		},
+		{
+			name:     "object without selection",
+			query:    `{ books }`,
+			expected: `{"data":{"books":null},"errors":[{"message":"field \"books\" of type Query must have a selection of subfields","path":["books"]}]}`,
+		},
+		{
+			name:     "syntax error",
+			query:    `{ books { title }`,
+			expected: `{"errors":[{"message":"unexpected end of query"}]}`,
+		},
+		{
+			name:     "mutation",
+			query:    `mutation { books { title } }`,
+			expected: `{"errors":[{"message":"mutations aren't supported"}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := execute(t, test.query, test.variables); got != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	h := &Handler{Schema: testSchema()}
+
+	w := httptest.NewRecorder()
+	body := `{"query": "query($t: String) { book(title: $t) { pages } }", "variables": {"t": "b"}}`
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, w.Code)
+	}
+	if got, expected := strings.TrimSpace(w.Body.String()), `{"data":{"book":{"pages":20}}}`; got != expected {
+		t.Fatalf("expected %s but got %s", expected, got)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?query=%7Bbooks(first:1)%7Btitle%7D%7D", nil))
+	if got, expected := strings.TrimSpace(w.Body.String()), `{"data":{"books":[{"title":"a"}]}}`; got != expected {
+		t.Fatalf("expected %s but got %s", expected, got)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d but got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+const (
+	// maxRequestSize is the largest request body that's read, in bytes
+	maxRequestSize = 1 << 20
+)
+
+// Handler serves the queries sent to it over HTTP, as described at
+// https://graphql.org/learn/serving-over-http/. Queries are given in the
+// "query" parameter of GET requests or in the body of POST requests.
+type Handler struct{ Schema *Schema }
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request, err := readRequest(r)
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: err.Error()}}})
+		return
+	}
+	writeResponse(w, http.StatusOK, h.Schema.Execute(request))
+}
+
+// readRequest returns the query that [r] sends
+func readRequest(r *http.Request) (*Request, error) {
+	request := &Request{}
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := decode([]byte(variables), &request.Variables); err != nil {
+				return nil, fmt.Errorf("couldn't parse variables: %w", err)
+			}
+		}
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, maxRequestSize))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read request: %w", err)
+		}
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if contentType == "application/graphql" {
+			request.Query = string(body)
+		} else if err := decode(body, request); err != nil {
+			return nil, fmt.Errorf("couldn't parse request: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("method %s isn't supported", r.Method)
+	}
+	if request.Query == "" {
+		return nil, errNoQuery
+	}
+	return request, nil
+}
+
+// decode [b] into [v], keeping numbers as json.Numbers so that large integers
+// aren't rounded
+func decode(b []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func writeResponse(w http.ResponseWriter, status int, response *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,635 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kinds of tokens in a query document
+const (
+	eofToken = iota
+	punctuatorToken
+	nameToken
+	intToken
+	floatToken
+	stringToken
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+// lexer splits a query document into tokens. Whitespace, commas and comments
+// are skipped.
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return l.token()
+		}
+	}
+	return token{kind: eofToken, pos: l.pos}, nil
+}
+
+func (l *lexer) token() (token, error) {
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: punctuatorToken, value: "...", pos: start}, nil
+	case strings.IndexByte("!$():=@[]{}|", c) != -1:
+		l.pos++
+		return token{kind: punctuatorToken, value: string(c), pos: start}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: nameToken, value: l.src[start:l.pos], pos: start}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		return l.string()
+	default:
+		return token{}, fmt.Errorf("unexpected character %q at position %d", c, start)
+	}
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	if l.digits() == 0 {
+		return token{}, fmt.Errorf("invalid number at position %d", start)
+	}
+	kind := intToken
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.pos++
+		if l.digits() == 0 {
+			return token{}, fmt.Errorf("invalid number at position %d", start)
+		}
+		kind = floatToken
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if l.digits() == 0 {
+			return token{}, fmt.Errorf("invalid number at position %d", start)
+		}
+		kind = floatToken
+	}
+	return token{kind: kind, value: l.src[start:l.pos], pos: start}, nil
+}
+
+// digits skips a run of digits and returns how many there were
+func (l *lexer) digits() int {
+	start := l.pos
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	return l.pos - start
+}
+
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	l.pos++ // Skip the opening quote
+	b := strings.Builder{}
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: stringToken, value: b.String(), pos: start}, nil
+		case c == '\n' || c == '\r':
+			return token{}, fmt.Errorf("unterminated string at position %d", start)
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, fmt.Errorf("unterminated string at position %d", start)
+			}
+			escaped := l.src[l.pos+1]
+			l.pos += 2
+			switch escaped {
+			case '"', '\\', '/':
+				b.WriteByte(escaped)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					return token{}, fmt.Errorf("invalid escape in string at position %d", start)
+				}
+				r, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, fmt.Errorf("invalid escape in string at position %d", start)
+				}
+				b.WriteRune(rune(r))
+				l.pos += 4
+			default:
+				return token{}, fmt.Errorf("invalid escape in string at position %d", start)
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			b.WriteRune(r)
+			l.pos += size
+		}
+	}
+	return token{}, fmt.Errorf("unterminated string at position %d", start)
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+// document is a parsed query document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is a query. Mutations and subscriptions aren't supported.
+type operation struct {
+	name       string
+	variables  []*variableDefinition
+	selections []selection
+}
+
+type variableDefinition struct {
+	name         string
+	defaultValue interface{}
+}
+
+// selection is a *field, *fragmentSpread or *inlineFragment
+type selection interface{}
+
+type field struct {
+	alias, name string
+	arguments   map[string]interface{}
+	directives  []*directive
+	selections  []selection
+}
+
+// key is the name the field's value is given in the response
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+}
+
+type inlineFragment struct {
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+}
+
+type fragment struct {
+	typeCondition string
+	selections    []selection
+}
+
+type directive struct {
+	name      string
+	arguments map[string]interface{}
+}
+
+// Values in a document that are resolved when the query is executed. Other
+// values are parsed into their Go representation.
+type (
+	variableRef string
+	listValue   []interface{}
+	objectValue map[string]interface{}
+)
+
+// parser parses a query document
+type parser struct {
+	lexer lexer
+	tok   token
+}
+
+func parse(query string) (*document, error) {
+	p := &parser{lexer: lexer{src: query}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != eofToken {
+		switch {
+		case p.peek(punctuatorToken, "{"):
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{selections: selections})
+		case p.peek(nameToken, "query"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peek(nameToken, "fragment"):
+			name, frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := doc.fragments[name]; exists {
+				return nil, fmt.Errorf("fragment %q is defined more than once", name)
+			}
+			doc.fragments[name] = frag
+		case p.peek(nameToken, "mutation"), p.peek(nameToken, "subscription"):
+			return nil, fmt.Errorf("%ss aren't supported", p.tok.value)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, errNoOperation
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	p.tok = tok
+	return err
+}
+
+// peek returns true if the current token is of kind [kind] and has value
+// [value]
+func (p *parser) peek(kind int, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+// skip advances past the current token and returns true if it's the
+// punctuator [value]
+func (p *parser) skip(value string) (bool, error) {
+	if !p.peek(punctuatorToken, value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+// expect advances past the punctuator [value], or errors if the current token
+// isn't it
+func (p *parser) expect(value string) error {
+	if !p.peek(punctuatorToken, value) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != nameToken {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == eofToken {
+		return errUnexpectedEnd
+	}
+	return fmt.Errorf("unexpected %q at position %d", p.tok.value, p.tok.pos)
+}
+
+func (p *parser) operation() (*operation, error) {
+	if err := p.advance(); err != nil { // Skip "query"
+		return nil, err
+	}
+	op := &operation{}
+	if p.tok.kind == nameToken {
+		op.name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(punctuatorToken, ")") {
+			def, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, def)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	op.selections = selections
+	return op, err
+}
+
+func (p *parser) variableDefinition() (*variableDefinition, error) {
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	// The types of variables aren't checked, as arguments are checked when
+	// they're used
+	if err := p.skipType(); err != nil {
+		return nil, err
+	}
+	def := &variableDefinition{name: name}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if def.defaultValue, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+func (p *parser) skipType() error {
+	if ok, err := p.skip("["); err != nil {
+		return err
+	} else if ok {
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	_, err := p.skip("!")
+	return err
+}
+
+func (p *parser) fragment() (string, *fragment, error) {
+	if err := p.advance(); err != nil { // Skip "fragment"
+		return "", nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return "", nil, err
+	}
+	if !p.peek(nameToken, "on") {
+		return "", nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return "", nil, err
+	}
+	typeCondition, err := p.name()
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return "", nil, err
+	}
+	selections, err := p.selectionSet()
+	return name, &fragment{typeCondition: typeCondition, selections: selections}, err
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	selections := []selection(nil)
+	for !p.peek(punctuatorToken, "}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	if len(selections) == 0 {
+		return nil, p.unexpected()
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) selection() (selection, error) {
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragmentSelection()
+	}
+
+	f := &field{}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	f.name = name
+	if f.arguments, err = p.arguments(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek(punctuatorToken, "{") {
+		if f.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// fragmentSelection parses what follows "..." in a selection set
+func (p *parser) fragmentSelection() (selection, error) {
+	if p.tok.kind == nameToken && p.tok.value != "on" {
+		spread := &fragmentSpread{name: p.tok.value}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		directives, err := p.directives()
+		spread.directives = directives
+		return spread, err
+	}
+
+	inline := &inlineFragment{}
+	if p.peek(nameToken, "on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		typeCondition, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		inline.typeCondition = typeCondition
+	}
+	directives, err := p.directives()
+	if err != nil {
+		return nil, err
+	}
+	inline.directives = directives
+	inline.selections, err = p.selectionSet()
+	return inline, err
+}
+
+func (p *parser) arguments() (map[string]interface{}, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	args := make(map[string]interface{})
+	for !p.peek(punctuatorToken, ")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if _, exists := args[name]; exists {
+			return nil, fmt.Errorf("argument %q is given more than once", name)
+		}
+		if args[name], err = p.value(false); err != nil {
+			return nil, err
+		}
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	directives := []*directive(nil)
+	for p.peek(punctuatorToken, "@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &directive{name: name, arguments: args})
+	}
+	return directives, nil
+}
+
+// value parses a value. If [constant] is true, the value can't refer to
+// variables.
+func (p *parser) value(constant bool) (interface{}, error) {
+	tok := p.tok
+	switch tok.kind {
+	case punctuatorToken:
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			return variableRef(name), err
+		case "[":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			list := listValue{}
+			for !p.peek(punctuatorToken, "]") {
+				item, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+			return list, p.advance()
+		case "{":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			object := objectValue{}
+			for !p.peek(punctuatorToken, "}") {
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if object[name], err = p.value(constant); err != nil {
+					return nil, err
+				}
+			}
+			return object, p.advance()
+		}
+	case intToken:
+		n, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %s at position %d", tok.value, tok.pos)
+		}
+		return n, p.advance()
+	case floatToken:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %s at position %d", tok.value, tok.pos)
+		}
+		return f, p.advance()
+	case stringToken:
+		return tok.value, p.advance()
+	case nameToken:
+		switch tok.value {
+		case "true":
+			return true, p.advance()
+		case "false":
+			return false, p.advance()
+		case "null":
+			return nil, p.advance()
+		default:
+			// Enum values are given to resolvers as their names
+			return tok.value, p.advance()
+		}
+	}
+	return nil, p.unexpected()
+}
@@ -0,0 +1,165 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipWriters are reused across responses, as each holds large buffers
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// gzipHandler compresses the responses of [handler] with gzip if the client
+// accepts it and the response is at least [minSize] bytes. Smaller responses
+// aren't worth the time it takes to compress them.
+type gzipHandler struct {
+	handler http.Handler
+	minSize int
+}
+
+func (h *gzipHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	// Websocket upgrades and responses without bodies aren't compressed
+	if r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" || !acceptsGzip(r) {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	writer := &gzipWriter{
+		ResponseWriter: w,
+		minSize:        h.minSize,
+		status:         http.StatusOK,
+	}
+	defer writer.close()
+	h.handler.ServeHTTP(writer, r)
+}
+
+// acceptsGzip returns true if the Accept-Encoding header of [r] allows the
+// response to be compressed with gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, header := range r.Header["Accept-Encoding"] {
+		for _, encoding := range strings.Split(header, ",") {
+			fields := strings.Split(encoding, ";")
+			if name := strings.TrimSpace(fields[0]); name != "gzip" && name != "*" {
+				continue
+			}
+			accepted := true
+			for _, param := range fields[1:] {
+				param = strings.TrimSpace(param)
+				if !strings.HasPrefix(param, "q=") {
+					continue
+				}
+				if q, err := strconv.ParseFloat(param[2:], 64); err != nil || q <= 0 {
+					accepted = false
+				}
+			}
+			if accepted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// gzipWriter buffers the start of a response until it's known whether the
+// response is large enough to compress
+type gzipWriter struct {
+	http.ResponseWriter
+	minSize int
+	status  int
+
+	// Start of the response, held until the response is decided to be
+	// compressed or not
+	buffer bytes.Buffer
+
+	// True once the header is written. [compressor] is set if the response is
+	// compressed.
+	decided    bool
+	compressor *gzip.Writer
+}
+
+// WriteHeader holds the status until the header can be written
+func (w *gzipWriter) WriteHeader(status int) {
+	if !w.decided {
+		w.status = status
+	}
+}
+
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buffer.Write(b)
+		if w.buffer.Len() < w.minSize {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.compressor != nil {
+		return w.compressor.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush writes the response so far. If it hasn't been decided whether to
+// compress the response, it isn't compressed.
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return
+		}
+	}
+	if w.compressor != nil {
+		_ = w.compressor.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// decide writes the header and the buffered start of the response. The
+// response is compressed if [compress] is true, unless the handler already
+// encoded it.
+func (w *gzipWriter) decide(compress bool) error {
+	w.decided = true
+
+	header := w.ResponseWriter.Header()
+	if compress && header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.compressor = gzipWriters.Get().(*gzip.Writer)
+		w.compressor.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buffered := w.buffer.Bytes()
+	w.buffer = bytes.Buffer{}
+	if w.compressor != nil {
+		_, err := w.compressor.Write(buffered)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buffered)
+	return err
+}
+
+// close writes the rest of the response once the handler returns
+func (w *gzipWriter) close() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.compressor != nil {
+		_ = w.compressor.Close()
+		gzipWriters.Put(w.compressor)
+		w.compressor = nil
+	}
+}
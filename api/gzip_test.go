@@ -0,0 +1,75 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipHandler(t *testing.T) {
+	large := strings.Repeat("utxo", 1024)
+	handler := &gzipHandler{
+		minSize: 1024,
+		handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			if r.URL.Path == "/large" {
+				// Written in pieces, so the response is decided mid-write
+				for i := 0; i < len(large); i += 100 {
+					end := i + 100
+					if end > len(large) {
+						end = len(large)
+					}
+					_, _ = w.Write([]byte(large[i:end]))
+				}
+				return
+			}
+			_, _ = w.Write([]byte("small"))
+		}),
+	}
+
+	serve := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		if acceptEncoding != "" {
+			r.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/large", "deflate, gzip;q=0.5")
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("should have compressed the large response, returned %d with encoding %q", w.Code, w.Header().Get("Content-Encoding"))
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != large {
+		t.Fatalf("compressed response should have decompressed to the response")
+	}
+
+	for _, test := range []struct{ path, acceptEncoding, body string }{
+		{"/small", "gzip", "small"},
+		{"/large", "", large},
+		{"/large", "gzip;q=0", large},
+	} {
+		w := serve(test.path, test.acceptEncoding)
+		if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != "" || w.Body.String() != test.body {
+			t.Fatalf("shouldn't have compressed the response to %s with Accept-Encoding %q", test.path, test.acceptEncoding)
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Fatalf("should have varied the response on Accept-Encoding")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseCheckConfigs parses configurations of checks of the form
+// "name=interval:timeout:threshold,name=interval:timeout:threshold", such as
+// "network=10s:2s:5,database=::3". Fields left empty aren't configured.
+func ParseCheckConfigs(configs string) (map[string]CheckConfig, error) {
+	parsed := make(map[string]CheckConfig)
+	if configs == "" {
+		return parsed, nil
+	}
+	for _, entry := range strings.Split(configs, ",") {
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) != 2 || fields[0] == "" {
+			return nil, fmt.Errorf("health check config %q should be of the form name=interval:timeout:threshold", entry)
+		}
+		name := fields[0]
+		values := strings.Split(fields[1], ":")
+		if len(values) != 3 {
+			return nil, fmt.Errorf("config of health check %s should be of the form interval:timeout:threshold but is %q", name, fields[1])
+		}
+
+		config := CheckConfig{}
+		var err error
+		if values[0] != "" {
+			if config.Interval, err = time.ParseDuration(values[0]); err != nil || config.Interval <= 0 {
+				return nil, fmt.Errorf("health check %s should have a positive interval but has %q", name, values[0])
+			}
+		}
+		if values[1] != "" {
+			if config.Timeout, err = time.ParseDuration(values[1]); err != nil || config.Timeout <= 0 {
+				return nil, fmt.Errorf("health check %s should have a positive timeout but has %q", name, values[1])
+			}
+		}
+		if values[2] != "" {
+			if config.FailureThreshold, err = strconv.Atoi(values[2]); err != nil || config.FailureThreshold < 1 {
+				return nil, fmt.Errorf("health check %s should have a failure threshold of at least 1 but has %q", name, values[2])
+			}
+		}
+		parsed[name] = config
+	}
+	return parsed, nil
+}
+
+// override returns [c] with the fields that [o] configures replaced
+func (c CheckConfig) override(o CheckConfig) CheckConfig {
+	if o.Interval > 0 {
+		c.Interval = o.Interval
+	}
+	if o.Timeout > 0 {
+		c.Timeout = o.Timeout
+	}
+	if o.FailureThreshold > 0 {
+		c.FailureThreshold = o.FailureThreshold
+	}
+	return c
+}
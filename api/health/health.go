@@ -0,0 +1,351 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+const (
+	// maxTick is the longest time between checking whether checks are due to
+	// run
+	maxTick = time.Second
+)
+
+var (
+	errDuplicateCheck = errors.New("a health check with that name is already registered")
+	errNotRun         = errors.New("health check hasn't run yet")
+)
+
+// Checker checks the health of a subsystem. It returns details about the
+// subsystem's state, which are reported whether or not it's healthy, and an
+// error if the subsystem is unhealthy.
+type Checker interface {
+	HealthCheck() (interface{}, error)
+}
+
+// CheckerFunc is a function that implements the Checker interface
+type CheckerFunc func() (interface{}, error)
+
+// HealthCheck calls [f]
+func (f CheckerFunc) HealthCheck() (interface{}, error) { return f() }
+
+// Result is the outcome of the most recent run of a health check
+type Result struct {
+	// Details that the check returned
+	Details interface{} `json:"details,omitempty"`
+
+	// Why the check failed, if it did
+	Error string `json:"error,omitempty"`
+
+	// When the check last ran, and how long it took
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  time.Duration `json:"duration"`
+
+	// Number of times in a row the check has failed, and when the first of
+	// those failures happened
+	ContiguousFailures int       `json:"contiguousFailures"`
+	TimeOfFirstFailure time.Time `json:"timeOfFirstFailure"`
+
+	// Whether the check has failed fewer times in a row than its threshold
+	Healthy bool `json:"healthy"`
+}
+
+// CheckConfig configures how a health check is run
+type CheckConfig struct {
+	// How often the check is run. If 0, the check is run as often as the
+	// health checks were initialized to be run. Reports between runs return
+	// the result of the last run, so frequent probes don't run the check.
+	Interval time.Duration
+
+	// How long the check may take before it's considered to have failed. If
+	// 0, the check may take as long as it needs to.
+	Timeout time.Duration
+
+	// Number of times in a row the check must fail before it's unhealthy. At
+	// least 1.
+	FailureThreshold int
+}
+
+type check struct {
+	checker Checker
+	config  CheckConfig
+
+	// If true, the check only determines whether the node is ready, not
+	// whether it's alive
+	readiness bool
+
+	// True while the check is being run. A check that's still running, such
+	// as one that timed out, isn't run again until it returns.
+	running bool
+
+	result Result
+}
+
+// Health runs health checks that subsystems register, and reports whether the
+// node is alive and whether it's ready to serve requests. Checks are run
+// periodically in the background, so reports are cheap to make.
+//
+// The node is alive if every liveness check is healthy. A node that isn't
+// alive should be restarted. The node is ready if it's alive and every
+// readiness check is healthy, for example once its chains are bootstrapped. A
+// node that isn't ready shouldn't be sent requests.
+type Health struct {
+	lock  sync.RWMutex
+	log   logging.Logger
+	clock timer.Clock
+
+	// How often checks that don't configure an interval are run
+	frequency time.Duration
+
+	// Key: Name of a check
+	// Value: Configuration that overrides the one the check is registered with
+	overrides map[string]CheckConfig
+
+	// Key: Name of the check
+	checks map[string]*check
+
+	repeater *timer.Repeater
+}
+
+// Initialize the health checks, which are run every [frequency] unless they're
+// configured to be run at another interval
+func (h *Health) Initialize(log logging.Logger, frequency time.Duration) {
+	h.log = log
+	h.frequency = frequency
+	h.checks = make(map[string]*check)
+
+	// Checks may be configured to run more often than [frequency]
+	tick := frequency
+	if tick > maxTick {
+		tick = maxTick
+	}
+	h.repeater = timer.NewRepeater(h.runDueChecks, tick)
+	go log.RecoverAndPanic(h.repeater.Dispatch)
+}
+
+// Shutdown stops running the health checks
+func (h *Health) Shutdown() {
+	if h.repeater != nil {
+		h.repeater.Stop()
+	}
+}
+
+// SetOverrides configures the checks named in [overrides], in place of the
+// configuration they're registered with. Fields that an override leaves empty
+// aren't overridden. Must be called before the checks are registered.
+func (h *Health) SetOverrides(overrides map[string]CheckConfig) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.overrides = overrides
+}
+
+// RegisterCheck registers the liveness check [checker] named [name]. The node
+// isn't alive while the check has failed at least [failureThreshold] times in
+// a row. The check is run once before this returns.
+func (h *Health) RegisterCheck(name string, checker Checker, failureThreshold int) error {
+	return h.register(name, checker, CheckConfig{FailureThreshold: failureThreshold}, false)
+}
+
+// RegisterReadinessCheck registers the readiness check [checker] named [name].
+// The node isn't ready while the check has failed at least [failureThreshold]
+// times in a row. The check is run once before this returns.
+func (h *Health) RegisterReadinessCheck(name string, checker Checker, failureThreshold int) error {
+	return h.register(name, checker, CheckConfig{FailureThreshold: failureThreshold}, true)
+}
+
+// RegisterCheckWithConfig registers the liveness check [checker] named [name],
+// which is run as [config] configures. The check is run once before this
+// returns.
+func (h *Health) RegisterCheckWithConfig(name string, checker Checker, config CheckConfig) error {
+	return h.register(name, checker, config, false)
+}
+
+// RegisterReadinessCheckWithConfig registers the readiness check [checker]
+// named [name], which is run as [config] configures. The check is run once
+// before this returns.
+func (h *Health) RegisterReadinessCheckWithConfig(name string, checker Checker, config CheckConfig) error {
+	return h.register(name, checker, config, true)
+}
+
+func (h *Health) register(name string, checker Checker, config CheckConfig, readiness bool) error {
+	h.lock.Lock()
+	if _, exists := h.checks[name]; exists {
+		h.lock.Unlock()
+		return errDuplicateCheck
+	}
+
+	config = config.override(h.overrides[name])
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	if config.Interval <= 0 {
+		config.Interval = h.frequency
+	}
+	c := &check{
+		checker:   checker,
+		config:    config,
+		readiness: readiness,
+		running:   true,
+		result:    Result{Error: errNotRun.Error()},
+	}
+	h.checks[name] = c
+	h.lock.Unlock()
+
+	h.run(name, c)
+	return nil
+}
+
+// Liveness returns the results of every check, and whether the node is alive
+func (h *Health) Liveness() (map[string]Result, bool) { return h.report(false) }
+
+// Readiness returns the results of every check, and whether the node is ready
+func (h *Health) Readiness() (map[string]Result, bool) { return h.report(true) }
+
+func (h *Health) report(readiness bool) (map[string]Result, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	results := make(map[string]Result, len(h.checks))
+	healthy := true
+	for name, c := range h.checks {
+		results[name] = c.result
+		if !c.result.Healthy && (readiness || !c.readiness) {
+			healthy = false
+		}
+	}
+	return results, healthy
+}
+
+// runChecks runs every check that isn't already running
+func (h *Health) runChecks() { h.runWhere(func(*check) bool { return true }) }
+
+// runDueChecks runs every check whose interval has passed since it last ran
+func (h *Health) runDueChecks() {
+	now := h.clock.Time()
+	h.runWhere(func(c *check) bool {
+		return now.Sub(c.result.Timestamp) >= c.config.Interval
+	})
+}
+
+// runWhere runs, in parallel, every check that isn't already running for
+// which [due] returns true, and returns once they've run or timed out
+func (h *Health) runWhere(due func(*check) bool) {
+	h.lock.Lock()
+	toRun := make(map[string]*check)
+	for name, c := range h.checks {
+		if !c.running && due(c) {
+			c.running = true
+			toRun[name] = c
+		}
+	}
+	h.lock.Unlock()
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(toRun))
+	for name, c := range toRun {
+		go func(name string, c *check) {
+			defer wg.Done()
+			h.run(name, c)
+		}(name, c)
+	}
+	wg.Wait()
+}
+
+// outcome is what a run of a check returned
+type outcome struct {
+	details interface{}
+	err     error
+}
+
+// run the check [c] named [name], and record its result. If the check times
+// out, the timeout is recorded as a failure and the check's result is
+// discarded when it returns.
+// Assumes [c.running] was set.
+func (h *Health) run(name string, c *check) {
+	// Guarded by the lock. If the check times out, whichever of the check
+	// returning and the timeout being recorded happens last marks the check
+	// as no longer running.
+	finished, timedOut := false, false
+
+	start := h.clock.Time()
+	done := make(chan outcome, 1)
+	go h.log.RecoverAndPanic(func() {
+		details, err := c.checker.HealthCheck()
+
+		h.lock.Lock()
+		finished = true
+		if timedOut {
+			c.running = false
+		}
+		h.lock.Unlock()
+
+		done <- outcome{details: details, err: err}
+	})
+
+	var timeout <-chan time.Time
+	if c.config.Timeout > 0 {
+		timer := time.NewTimer(c.config.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	result := outcome{}
+	select {
+	case result = <-done:
+	case <-timeout:
+		result.err = fmt.Errorf("health check timed out after %s", c.config.Timeout)
+	}
+	end := h.clock.Time()
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if finished {
+		c.running = false
+	} else {
+		timedOut = true
+	}
+	h.record(name, c, result, start, end)
+}
+
+// record [outcome] as the result of the run of the check [c] named [name] that
+// started at [start] and ended at [end]
+// Assumes the lock is held
+func (h *Health) record(name string, c *check, o outcome, start, end time.Time) {
+	result := Result{
+		Details:            o.details,
+		Timestamp:          end,
+		Duration:           end.Sub(start),
+		ContiguousFailures: c.result.ContiguousFailures,
+		TimeOfFirstFailure: c.result.TimeOfFirstFailure,
+	}
+	if o.err != nil {
+		if result.ContiguousFailures == 0 {
+			result.TimeOfFirstFailure = end
+		}
+		result.ContiguousFailures++
+		result.Error = o.err.Error()
+	} else {
+		result.ContiguousFailures = 0
+		result.TimeOfFirstFailure = time.Time{}
+	}
+	result.Healthy = result.ContiguousFailures < c.config.FailureThreshold
+
+	if result.Healthy != c.result.Healthy || c.result.Timestamp.IsZero() {
+		if result.Healthy {
+			h.log.Info("health check %s is healthy", name)
+		} else {
+			h.log.Warn("health check %s is unhealthy due to %s", name, result.Error)
+		}
+	}
+	c.result = result
+}
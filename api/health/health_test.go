@@ -0,0 +1,235 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// newHealth returns health checks that are only run when the test calls
+// runChecks
+func newHealth() *Health {
+	h := &Health{}
+	h.Initialize(logging.NoLog{}, time.Hour)
+	h.Shutdown()
+	return h
+}
+
+func TestLiveness(t *testing.T) {
+	h := newHealth()
+
+	failing := false
+	if err := h.RegisterCheck("db", CheckerFunc(func() (interface{}, error) {
+		if failing {
+			return "down", errors.New("database is closed")
+		}
+		return "up", nil
+	}), 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.RegisterCheck("db", CheckerFunc(func() (interface{}, error) { return nil, nil }), 1); err != errDuplicateCheck {
+		t.Fatalf("shouldn't have registered two checks with the same name")
+	}
+
+	results, alive := h.Liveness()
+	if !alive || !results["db"].Healthy || results["db"].Details != "up" {
+		t.Fatalf("node should be alive: %+v", results)
+	}
+
+	// A single failure is under the threshold
+	failing = true
+	h.runChecks()
+	results, alive = h.Liveness()
+	if !alive || results["db"].ContiguousFailures != 1 || results["db"].Error == "" {
+		t.Fatalf("node should still be alive after one failure: %+v", results)
+	}
+
+	h.runChecks()
+	results, alive = h.Liveness()
+	if alive || results["db"].Healthy || results["db"].ContiguousFailures != 2 {
+		t.Fatalf("node shouldn't be alive after two failures: %+v", results)
+	}
+
+	failing = false
+	h.runChecks()
+	results, alive = h.Liveness()
+	if !alive || results["db"].ContiguousFailures != 0 || !results["db"].TimeOfFirstFailure.IsZero() {
+		t.Fatalf("node should be alive once the check passes: %+v", results)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	h := newHealth()
+
+	bootstrapped := false
+	if err := h.RegisterCheck("network", CheckerFunc(func() (interface{}, error) { return nil, nil }), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.RegisterReadinessCheck("chains", CheckerFunc(func() (interface{}, error) {
+		if !bootstrapped {
+			return nil, errors.New("chains are bootstrapping")
+		}
+		return nil, nil
+	}), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, alive := h.Liveness(); !alive {
+		t.Fatalf("a failing readiness check shouldn't affect liveness")
+	}
+	if _, ready := h.Readiness(); ready {
+		t.Fatalf("node shouldn't be ready while its chains are bootstrapping")
+	}
+
+	bootstrapped = true
+	h.runChecks()
+	if _, ready := h.Readiness(); !ready {
+		t.Fatalf("node should be ready once its chains are bootstrapped")
+	}
+}
+
+func TestCheckInterval(t *testing.T) {
+	h := newHealth()
+	start := time.Unix(1000, 0)
+	h.clock.Set(start)
+
+	runs := 0
+	if err := h.RegisterCheckWithConfig("db", CheckerFunc(func() (interface{}, error) {
+		runs++
+		return nil, nil
+	}), CheckConfig{Interval: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Probes are served the result of the last run
+	h.Liveness()
+	h.runDueChecks()
+	if runs != 1 {
+		t.Fatalf("check shouldn't have run again before its interval passed, ran %d times", runs)
+	}
+
+	h.clock.Set(start.Add(time.Minute))
+	h.runDueChecks()
+	if runs != 2 {
+		t.Fatalf("check should have run again once its interval passed, ran %d times", runs)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	h := newHealth()
+
+	block := make(chan struct{})
+	returned := make(chan struct{}, 1)
+	blocking := true
+	if err := h.RegisterCheckWithConfig("db", CheckerFunc(func() (interface{}, error) {
+		if blocking {
+			<-block
+			returned <- struct{}{}
+		}
+		return nil, nil
+	}), CheckConfig{Timeout: 10 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, alive := h.Liveness()
+	if alive || results["db"].ContiguousFailures != 1 || results["db"].Error == "" {
+		t.Fatalf("check should have failed by timing out: %+v", results)
+	}
+
+	// The check is still running, so it isn't run again
+	h.runChecks()
+	if results, _ := h.Liveness(); results["db"].ContiguousFailures != 1 {
+		t.Fatalf("check shouldn't have run while it was still running: %+v", results)
+	}
+
+	close(block)
+	<-returned
+	blocking = false
+	for {
+		h.lock.RLock()
+		running := h.checks["db"].running
+		h.lock.RUnlock()
+		if !running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	h.runChecks()
+	if _, alive := h.Liveness(); !alive {
+		t.Fatalf("check should pass once it returns in time")
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	h := newHealth()
+	if err := h.RegisterReadinessCheck("chains", CheckerFunc(func() (interface{}, error) {
+		return nil, errors.New("chains are bootstrapping")
+	}), 1); err != nil {
+		t.Fatal(err)
+	}
+	handlers := h.CreateHandlers()
+
+	for extension, expectedCode := range map[string]int{
+		"":           http.StatusOK,
+		"/readiness": http.StatusServiceUnavailable,
+	} {
+		writer := httptest.NewRecorder()
+		handlers[extension].Handler.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/ext/health"+extension, nil))
+		if writer.Code != expectedCode {
+			t.Fatalf("%q should have returned status %d but returned %d", extension, expectedCode, writer.Code)
+		}
+		reply := HealthReply{}
+		if err := json.Unmarshal(writer.Body.Bytes(), &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Healthy != (expectedCode == http.StatusOK) || reply.Checks["chains"].Error == "" {
+			t.Fatalf("%q returned the wrong report: %+v", extension, reply)
+		}
+	}
+
+	s := &Service{health: h}
+	reply := HealthReply{}
+	if err := s.GetReadiness(nil, &HealthArgs{}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Healthy {
+		t.Fatalf("node shouldn't be ready")
+	}
+}
+
+func TestParseCheckConfigs(t *testing.T) {
+	configs, err := ParseCheckConfigs("network=10s:2s:5,database=::3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configs["network"] != (CheckConfig{Interval: 10 * time.Second, Timeout: 2 * time.Second, FailureThreshold: 5}) {
+		t.Fatalf("wrong network config: %+v", configs["network"])
+	}
+	if configs["database"] != (CheckConfig{FailureThreshold: 3}) {
+		t.Fatalf("wrong database config: %+v", configs["database"])
+	}
+
+	for _, invalid := range []string{"network", "network=10s:2s", "network=-1s::", "network=::0", "=::1"} {
+		if _, err := ParseCheckConfigs(invalid); err == nil {
+			t.Fatalf("should have failed to parse %q", invalid)
+		}
+	}
+
+	h := newHealth()
+	h.SetOverrides(configs)
+	if err := h.RegisterCheck("database", CheckerFunc(func() (interface{}, error) { return nil, nil }), 1); err != nil {
+		t.Fatal(err)
+	}
+	if threshold := h.checks["database"].config.FailureThreshold; threshold != 3 {
+		t.Fatalf("should have overridden the failure threshold with 3 but it's %d", threshold)
+	}
+}
@@ -0,0 +1,92 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// CreateHandlers returns the deep health check, served at "", and the
+// readiness check, served at "/readiness". A GET request to either returns the
+// results of the checks, with status 200 if the node is healthy and 503 if it
+// isn't, so that load balancers can use them. Other requests are handled by
+// the health API service.
+func (h *Health) CreateHandlers() map[string]*common.HTTPHandler {
+	newServer := cjson.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Service{health: h}, "health")
+	return map[string]*common.HTTPHandler{
+		"": &common.HTTPHandler{
+			LockOptions: common.NoLock,
+			Handler:     &handler{report: h.Liveness, rpc: newServer},
+		},
+		"/readiness": &common.HTTPHandler{
+			LockOptions: common.NoLock,
+			Handler:     &handler{report: h.Readiness, rpc: newServer},
+		},
+	}
+}
+
+// handler answers GET requests with a health report, and passes other requests
+// to the API service
+type handler struct {
+	report func() (map[string]Result, bool)
+	rpc    *cjson.Server
+}
+
+// Describe returns a description of the health API service's methods
+func (h *handler) Describe(title string) *cjson.Document { return h.rpc.Describe(title) }
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.rpc.ServeHTTP(w, r)
+		return
+	}
+
+	checks, healthy := h.report()
+	w.Header().Set("Content-Type", "application/json")
+	if healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	// The status code was already sent, so an error can't be reported
+	_ = json.NewEncoder(w).Encode(&HealthReply{Checks: checks, Healthy: healthy})
+}
+
+// Service is the API service for the health checks
+type Service struct{ health *Health }
+
+// HealthArgs are the arguments for calling GetLiveness and GetReadiness
+type HealthArgs struct{}
+
+// HealthReply is the reply from calling GetLiveness and GetReadiness
+type HealthReply struct {
+	// Key: Name of the check
+	Checks  map[string]Result `json:"checks"`
+	Healthy bool              `json:"healthy"`
+}
+
+// GetLiveness returns the results of the health checks, and whether the node
+// is alive
+func (service *Service) GetLiveness(_ *http.Request, _ *HealthArgs, reply *HealthReply) error {
+	service.health.log.Debug("Health: GetLiveness called")
+	reply.Checks, reply.Healthy = service.health.Liveness()
+	return nil
+}
+
+// GetReadiness returns the results of the health checks, and whether the node
+// is ready to serve requests
+func (service *Service) GetReadiness(_ *http.Request, _ *HealthArgs, reply *HealthReply) error {
+	service.health.log.Debug("Health: GetReadiness called")
+	reply.Checks, reply.Healthy = service.health.Readiness()
+	return nil
+}
@@ -0,0 +1,70 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// addressTxKeyLen is the length of the keys of an address index
+	addressTxKeyLen = hashing.HashLen + wrappers.LongLen
+)
+
+// addressIndex is the record of the transactions that touched each address of
+// a chain. A transaction is identified by the index it was accepted at in the
+// chain's decisions index, so the history of an address is in the order it
+// was accepted in and the transactions can be fetched from that index.
+type addressIndex struct {
+	// Key: hash of the address + index the transaction was accepted at
+	// Value: ID of the transaction
+	db database.Database
+}
+
+// accept records that the transaction [txID], which was accepted at [index],
+// touched [addrs]
+func (a *addressIndex) accept(txID ids.ID, index uint64, addrs [][]byte) error {
+	batch := a.db.NewBatch()
+	for _, addr := range addrs {
+		if err := batch.Put(addressTxKey(addr, index), txID.Bytes()); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// txs returns, in the order they were accepted in, the indices of up to
+// [limit] of the transactions that touched [addr] and were accepted at
+// [start] or later
+func (a *addressIndex) txs(addr []byte, start, limit uint64) ([]uint64, error) {
+	addrHash := hashing.ComputeHash256(addr)
+	iter := a.db.NewIteratorWithStartAndPrefix(addressTxKey(addr, start), addrHash)
+	defer iter.Release()
+
+	indices := []uint64(nil)
+	for uint64(len(indices)) < limit && iter.Next() {
+		key := iter.Key()
+		if len(key) != addressTxKeyLen {
+			continue
+		}
+		index, err := unpackIndex(key[hashing.HashLen:])
+		if err != nil {
+			return nil, err
+		}
+		indices = append(indices, index)
+	}
+	return indices, iter.Error()
+}
+
+// addressTxKey returns the key that the transaction that touched [addr] and
+// was accepted at [index] is stored under
+func addressTxKey(addr []byte, index uint64) []byte {
+	p := wrappers.Packer{Bytes: make([]byte, addressTxKeyLen)}
+	p.PackFixedBytes(hashing.ComputeHash256(addr))
+	p.PackLong(index)
+	return p.Bytes
+}
@@ -0,0 +1,262 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/json"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// ChainEndpoint is the endpoint, under the Index API, of the API of each
+	// chain's indices. The chain is given by its ID or alias, as in
+	// /ext/index/X.
+	ChainEndpoint = "/{" + chainVar + "}"
+
+	// chainVar is the name of the path variable the chain is given by
+	chainVar = "chain"
+
+	// defaultLimit is the most containers returned by a call that doesn't
+	// give a limit
+	defaultLimit = 100
+
+	// Directions a cursor pages in
+	forward  byte = 0
+	backward byte = 1
+)
+
+var (
+	errNoChain       = errors.New("no chain given in the path")
+	errInvalidCursor = errors.New("invalid cursor")
+)
+
+// ChainService is the API service for the indices of the chain given in the
+// request's path
+type ChainService struct{ indexer *Indexer }
+
+// ChainIndexArgs identify one of the chain's indices
+type ChainIndexArgs struct {
+	// The index the containers are fetched from, either "containers" or
+	// "decisions". Defaults to "containers".
+	Type string `json:"type"`
+}
+
+// lookupIndex returns the index of the chain in the path of [r] that [args]
+// identify
+// Assumes [s.indexer.lock] is held
+func (s *ChainService) lookupIndex(r *http.Request, args *ChainIndexArgs) (*index, string, error) {
+	chain := mux.Vars(r)[chainVar]
+	if chain == "" {
+		return nil, "", errNoChain
+	}
+	name := args.Type
+	if name == "" {
+		name = ContainersIndex
+	}
+	idx, err := s.indexer.lookupIndex(chain, name)
+	return idx, chain, err
+}
+
+// GetByIndexArgs are the arguments for calling GetByIndex
+type GetByIndexArgs struct {
+	ChainIndexArgs
+	Index json.Uint64 `json:"index"`
+}
+
+// GetByIndex returns the container accepted at the given index
+func (s *ChainService) GetByIndex(r *http.Request, args *GetByIndexArgs, reply *FormattedContainer) error {
+	s.indexer.lock.Lock()
+	defer s.indexer.lock.Unlock()
+
+	idx, chain, err := s.lookupIndex(r, &args.ChainIndexArgs)
+	if err != nil {
+		return err
+	}
+	s.indexer.log.Verbo("GetByIndex called for index %d of the %s index of chain %s", args.Index, args.Type, chain)
+
+	container, err := idx.container(uint64(args.Index))
+	if err != nil {
+		return err
+	}
+	*reply = newFormattedContainer(container, uint64(args.Index))
+	return nil
+}
+
+// GetRangeArgs are the arguments for calling GetRange. If a cursor is given,
+// the page after the one it was returned with is fetched, and the start index
+// and direction are ignored.
+type GetRangeArgs struct {
+	ChainIndexArgs
+	Cursor     string      `json:"cursor"`
+	StartIndex json.Uint64 `json:"startIndex"`
+	Limit      json.Uint32 `json:"limit"`
+
+	// If true, containers are returned from the start index back to the first
+	// accepted container, most recently accepted first
+	Reverse bool `json:"reverse"`
+}
+
+// GetRangeReply is the response from calling GetRange or GetLatest
+type GetRangeReply struct {
+	Containers []FormattedContainer `json:"containers"`
+
+	// Fetches the next page when passed to GetRange. Pages that go forward
+	// always have a cursor, which returns the containers accepted after them,
+	// if there are any yet. Pages that go back don't once they reach the
+	// first accepted container.
+	Cursor string `json:"cursor,omitempty"`
+
+	// Number of containers in the index
+	NumAccepted json.Uint64 `json:"numAccepted"`
+}
+
+// GetRange returns up to [limit] containers, starting at [startIndex] or where
+// [cursor] left off. At most 1024 containers are returned, and 100 if no limit
+// is given.
+func (s *ChainService) GetRange(r *http.Request, args *GetRangeArgs, reply *GetRangeReply) error {
+	s.indexer.lock.Lock()
+	defer s.indexer.lock.Unlock()
+
+	idx, chain, err := s.lookupIndex(r, &args.ChainIndexArgs)
+	if err != nil {
+		return err
+	}
+	s.indexer.log.Verbo("GetRange called for %d containers from index %d of the %s index of chain %s", args.Limit, args.StartIndex, args.Type, chain)
+
+	limit, err := parseLimit(args.Limit)
+	if err != nil {
+		return err
+	}
+	start, direction := uint64(args.StartIndex), forward
+	if args.Reverse {
+		direction = backward
+	}
+	if args.Cursor != "" {
+		if start, direction, err = parseCursor(args.Cursor); err != nil {
+			return err
+		}
+	}
+	return page(idx, start, direction, limit, reply)
+}
+
+// GetLatestArgs are the arguments for calling GetLatest
+type GetLatestArgs struct {
+	ChainIndexArgs
+	Limit json.Uint32 `json:"limit"`
+}
+
+// GetLatest returns up to [limit] of the most recently accepted containers,
+// most recently accepted first. The cursor it returns pages back through the
+// earlier containers.
+func (s *ChainService) GetLatest(r *http.Request, args *GetLatestArgs, reply *GetRangeReply) error {
+	s.indexer.lock.Lock()
+	defer s.indexer.lock.Unlock()
+
+	idx, chain, err := s.lookupIndex(r, &args.ChainIndexArgs)
+	if err != nil {
+		return err
+	}
+	s.indexer.log.Verbo("GetLatest called for %d containers of the %s index of chain %s", args.Limit, args.Type, chain)
+
+	limit, err := parseLimit(args.Limit)
+	if err != nil {
+		return err
+	}
+	numAccepted := idx.numAccepted()
+	if numAccepted == 0 {
+		reply.Containers = []FormattedContainer{}
+		return nil
+	}
+	return page(idx, numAccepted-1, backward, limit, reply)
+}
+
+// page fetches up to [limit] containers of [idx] into [reply], starting at
+// [start] and going in [direction]
+func page(idx *index, start uint64, direction byte, limit uint64, reply *GetRangeReply) error {
+	numAccepted := idx.numAccepted()
+	reply.NumAccepted = json.Uint64(numAccepted)
+	reply.Containers = []FormattedContainer{}
+	reply.Cursor = ""
+
+	if direction == forward {
+		end := start + limit
+		if end > numAccepted || end < start {
+			end = numAccepted
+		}
+		for index := start; index < end; index++ {
+			container, err := idx.container(index)
+			if err != nil {
+				return err
+			}
+			reply.Containers = append(reply.Containers, newFormattedContainer(container, index))
+		}
+		if end < start {
+			end = start
+		}
+		reply.Cursor = newCursor(end, forward)
+		return nil
+	}
+
+	if numAccepted == 0 {
+		return nil
+	}
+	if start >= numAccepted {
+		start = numAccepted - 1
+	}
+	for fetched := uint64(0); fetched < limit; fetched++ {
+		index := start - fetched
+		container, err := idx.container(index)
+		if err != nil {
+			return err
+		}
+		reply.Containers = append(reply.Containers, newFormattedContainer(container, index))
+		if index == 0 {
+			return nil
+		}
+	}
+	reply.Cursor = newCursor(start-limit, backward)
+	return nil
+}
+
+// parseLimit returns the number of containers to fetch for the limit [limit]
+func parseLimit(limit json.Uint32) (uint64, error) {
+	switch {
+	case limit == 0:
+		return defaultLimit, nil
+	case limit > maxFetch:
+		return 0, fmt.Errorf("limit must be at most %d", maxFetch)
+	}
+	return uint64(limit), nil
+}
+
+// newCursor returns a cursor of the page that starts at [index] and goes in
+// [direction]
+func newCursor(index uint64, direction byte) string {
+	p := wrappers.Packer{Bytes: make([]byte, wrappers.ByteLen+wrappers.LongLen)}
+	p.PackByte(direction)
+	p.PackLong(index)
+	return formatting.CB58{Bytes: p.Bytes}.String()
+}
+
+// parseCursor returns the index and direction of the page [cursor] starts
+func parseCursor(cursor string) (uint64, byte, error) {
+	cb58 := formatting.CB58{}
+	if err := cb58.FromString(cursor); err != nil {
+		return 0, 0, errInvalidCursor
+	}
+	p := wrappers.Packer{Bytes: cb58.Bytes}
+	direction := p.UnpackByte()
+	index := p.UnpackLong()
+	if p.Errored() || p.Offset != len(cb58.Bytes) || (direction != forward && direction != backward) {
+		return 0, 0, errInvalidCursor
+	}
+	return index, direction, nil
+}
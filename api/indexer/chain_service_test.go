@@ -0,0 +1,108 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestChainServicePaging(t *testing.T) {
+	decisions, consensus := dispatchers()
+
+	i := &Indexer{}
+	i.Initialize(logging.NoLog{}, memdb.New(), nil, chainLookup(t))
+	if err := i.Register(decisions, consensus); err != nil {
+		t.Fatal(err)
+	}
+	s := &ChainService{indexer: i}
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/ext/index/X", nil), map[string]string{chainVar: "X"})
+
+	latest := GetRangeReply{}
+	if err := s.GetLatest(r, &GetLatestArgs{Limit: 2}, &latest); err != nil {
+		t.Fatal(err)
+	}
+	if len(latest.Containers) != 0 || latest.Cursor != "" {
+		t.Fatalf("an empty index should have no containers: %+v", latest)
+	}
+
+	for b := byte(0); b < 5; b++ {
+		consensus.Accept(chainID, ids.NewID([32]byte{10 + b}), []byte{b})
+	}
+
+	indices := func(reply *GetRangeReply) []int {
+		indices := []int{}
+		for _, container := range reply.Containers {
+			indices = append(indices, int(container.Index))
+		}
+		return indices
+	}
+	expect := func(reply *GetRangeReply, expected ...int) {
+		t.Helper()
+		got := indices(reply)
+		if len(got) != len(expected) {
+			t.Fatalf("expected containers %v but got %v", expected, got)
+		}
+		for i := range got {
+			if got[i] != expected[i] {
+				t.Fatalf("expected containers %v but got %v", expected, got)
+			}
+		}
+	}
+
+	// Page back from the latest container
+	if err := s.GetLatest(r, &GetLatestArgs{Limit: 2}, &latest); err != nil {
+		t.Fatal(err)
+	}
+	expect(&latest, 4, 3)
+	page := GetRangeReply{}
+	if err := s.GetRange(r, &GetRangeArgs{Cursor: latest.Cursor, Limit: 2}, &page); err != nil {
+		t.Fatal(err)
+	}
+	expect(&page, 2, 1)
+	if err := s.GetRange(r, &GetRangeArgs{Cursor: page.Cursor, Limit: 2}, &page); err != nil {
+		t.Fatal(err)
+	}
+	expect(&page, 0)
+	if page.Cursor != "" || page.NumAccepted != 5 {
+		t.Fatalf("the last page back should have no cursor: %+v", page)
+	}
+
+	// Page forward, then pick up a container accepted later
+	if err := s.GetRange(r, &GetRangeArgs{StartIndex: 3, Limit: 10}, &page); err != nil {
+		t.Fatal(err)
+	}
+	expect(&page, 3, 4)
+	consensus.Accept(chainID, ids.NewID([32]byte{20}), []byte{20})
+	if err := s.GetRange(r, &GetRangeArgs{Cursor: page.Cursor}, &page); err != nil {
+		t.Fatal(err)
+	}
+	expect(&page, 5)
+
+	byIndex := FormattedContainer{}
+	if err := s.GetByIndex(r, &GetByIndexArgs{Index: 5}, &byIndex); err != nil {
+		t.Fatal(err)
+	}
+	if byIndex.ID != ids.NewID([32]byte{20}).String() {
+		t.Fatalf("wrong container at index 5: %+v", byIndex)
+	}
+
+	if err := s.GetRange(r, &GetRangeArgs{Cursor: "not a cursor"}, &page); err != errInvalidCursor {
+		t.Fatalf("should have failed with an invalid cursor but returned %v", err)
+	}
+	if err := s.GetRange(r, &GetRangeArgs{Limit: maxFetch + 1}, &page); err == nil {
+		t.Fatal("should have failed with a limit above the maximum")
+	}
+	unknown := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/ext/index/Y", nil), map[string]string{chainVar: "Y"})
+	if err := s.GetLatest(unknown, &GetLatestArgs{}, &latest); err == nil {
+		t.Fatal("should have failed with an unknown chain")
+	}
+}
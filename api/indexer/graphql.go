@@ -0,0 +1,427 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/gecko/api/graphql"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+var (
+	errNoContainer = errors.New("the id or index of the container must be given")
+	errNoAddress   = errors.New("no address given")
+)
+
+// ChainCaller calls the JSON-RPC APIs of chains. The addresses and assets of a
+// chain are queried through the API of the chain, as the indexer doesn't know
+// how to parse the chain's transactions.
+type ChainCaller interface {
+	ChainMethods(chainID ids.ID) []string
+	CallChain(chainID ids.ID, method string, args, reply interface{}) error
+}
+
+// CreateGraphQLHandler returns a handler of GraphQL queries over the indexed
+// chains. The schema is:
+//
+//	type Query {
+//	  chain(id: String!): Chain
+//	}
+//	type Chain {
+//	  id: String
+//	  container(id: String, index: Uint64): Container
+//	  containers(first: Int, start: Uint64, reverse: Boolean, cursor: String): Page
+//	  decision(id: String, index: Uint64): Container
+//	  decisions(first: Int, start: Uint64, reverse: Boolean, cursor: String): Page
+//	  address(address: String!): Address
+//	  asset(id: String!): Asset
+//	}
+//	type Page {
+//	  containers: [Container]
+//	  cursor: String
+//	  numAccepted: Uint64
+//	}
+//	type Container {
+//	  id: String
+//	  bytes: String
+//	  timestamp: Uint64
+//	  index: Uint64
+//	}
+//	type Address {
+//	  address: String
+//	  balance(assetID: String!): Uint64
+//	  transactions(first: Int, startHeight: Uint64): [AddressTx]
+//	}
+//	type AddressTx {
+//	  id: String
+//	  height: Uint64
+//	  timestamp: Uint64
+//	  decision: Container
+//	}
+//	type Asset {
+//	  id: String
+//	  name: String
+//	  symbol: String
+//	  denomination: Int
+//	  decision: Container
+//	}
+//
+// Containers are the blocks of a linear chain and the vertices of a DAG.
+// Decisions are the blocks of a linear chain and the transactions of a DAG.
+// Pages work as they do in GetRange of the chain's Index API. Uint64s are
+// returned as strings, and may be given as integers or strings.
+func (i *Indexer) CreateGraphQLHandler(caller ChainCaller) *common.HTTPHandler {
+	return &common.HTTPHandler{
+		LockOptions: common.NoLock,
+		Handler:     &graphql.Handler{Schema: newSchema(&resolver{indexer: i, caller: caller})},
+	}
+}
+
+func newSchema(r *resolver) *graphql.Schema {
+	containerType := &graphql.Object{
+		Name: "Container",
+		Fields: map[string]*graphql.Field{
+			"id":    {Resolve: func(c interface{}, _ graphql.Args) (interface{}, error) { return c.(*FormattedContainer).ID, nil }},
+			"bytes": {Resolve: func(c interface{}, _ graphql.Args) (interface{}, error) { return c.(*FormattedContainer).Bytes, nil }},
+			"timestamp": {Resolve: func(c interface{}, _ graphql.Args) (interface{}, error) {
+				return c.(*FormattedContainer).Timestamp, nil
+			}},
+			"index": {Resolve: func(c interface{}, _ graphql.Args) (interface{}, error) { return c.(*FormattedContainer).Index, nil }},
+		},
+	}
+	pageType := &graphql.Object{
+		Name: "Page",
+		Fields: map[string]*graphql.Field{
+			"containers": {
+				Type: containerType,
+				Resolve: func(p interface{}, _ graphql.Args) (interface{}, error) {
+					containers := p.(*GetRangeReply).Containers
+					list := make([]*FormattedContainer, len(containers))
+					for i := range containers {
+						list[i] = &containers[i]
+					}
+					return list, nil
+				},
+			},
+			"cursor":      {Resolve: func(p interface{}, _ graphql.Args) (interface{}, error) { return p.(*GetRangeReply).Cursor, nil }},
+			"numAccepted": {Resolve: func(p interface{}, _ graphql.Args) (interface{}, error) { return p.(*GetRangeReply).NumAccepted, nil }},
+		},
+	}
+	addressTxType := &graphql.Object{
+		Name: "AddressTx",
+		Fields: map[string]*graphql.Field{
+			"id":        {Resolve: func(tx interface{}, _ graphql.Args) (interface{}, error) { return tx.(*addressTx).TxID.String(), nil }},
+			"height":    {Resolve: func(tx interface{}, _ graphql.Args) (interface{}, error) { return tx.(*addressTx).Height, nil }},
+			"timestamp": {Resolve: func(tx interface{}, _ graphql.Args) (interface{}, error) { return tx.(*addressTx).Timestamp, nil }},
+			"decision": {
+				Type: containerType,
+				Resolve: func(tx interface{}, _ graphql.Args) (interface{}, error) {
+					return r.containerByID(tx.(*addressTx).chainID, DecisionsIndex, tx.(*addressTx).TxID)
+				},
+			},
+		},
+	}
+	addressType := &graphql.Object{
+		Name: "Address",
+		Fields: map[string]*graphql.Field{
+			"address":      {Resolve: func(a interface{}, _ graphql.Args) (interface{}, error) { return a.(*address).address, nil }},
+			"balance":      {Resolve: r.balance},
+			"transactions": {Type: addressTxType, Resolve: r.addressTxs},
+		},
+	}
+	assetType := &graphql.Object{
+		Name: "Asset",
+		Fields: map[string]*graphql.Field{
+			"id":           {Resolve: func(a interface{}, _ graphql.Args) (interface{}, error) { return a.(*asset).AssetID.String(), nil }},
+			"name":         {Resolve: func(a interface{}, _ graphql.Args) (interface{}, error) { return a.(*asset).Name, nil }},
+			"symbol":       {Resolve: func(a interface{}, _ graphql.Args) (interface{}, error) { return a.(*asset).Symbol, nil }},
+			"denomination": {Resolve: func(a interface{}, _ graphql.Args) (interface{}, error) { return a.(*asset).Denomination, nil }},
+			"decision": {
+				Type: containerType,
+				Resolve: func(a interface{}, _ graphql.Args) (interface{}, error) {
+					return r.containerByID(a.(*asset).chainID, DecisionsIndex, a.(*asset).AssetID)
+				},
+			},
+		},
+	}
+	chainType := &graphql.Object{
+		Name: "Chain",
+		Fields: map[string]*graphql.Field{
+			"id":         {Resolve: func(c interface{}, _ graphql.Args) (interface{}, error) { return c.(ids.ID).String(), nil }},
+			"container":  {Type: containerType, Resolve: r.container(ContainersIndex)},
+			"containers": {Type: pageType, Resolve: r.page(ContainersIndex)},
+			"decision":   {Type: containerType, Resolve: r.container(DecisionsIndex)},
+			"decisions":  {Type: pageType, Resolve: r.page(DecisionsIndex)},
+			"address":    {Type: addressType, Resolve: r.address},
+			"asset":      {Type: assetType, Resolve: r.asset},
+		},
+	}
+	return &graphql.Schema{
+		Query: &graphql.Object{
+			Name: "Query",
+			Fields: map[string]*graphql.Field{
+				"chain": {Type: chainType, Resolve: r.chain},
+			},
+		},
+	}
+}
+
+// resolver resolves the fields of GraphQL queries over the indexer
+type resolver struct {
+	indexer *Indexer
+	caller  ChainCaller
+}
+
+// address is an address on a chain
+type address struct {
+	chainID ids.ID
+	address string
+}
+
+// addressTx is a transaction in the history of an address
+type addressTx struct {
+	chainID   ids.ID
+	TxID      ids.ID      `json:"txID"`
+	Height    json.Uint64 `json:"height"`
+	Timestamp json.Uint64 `json:"timestamp"`
+}
+
+// asset is an asset on a chain
+type asset struct {
+	chainID      ids.ID
+	AssetID      ids.ID     `json:"assetID"`
+	Name         string     `json:"name"`
+	Symbol       string     `json:"symbol"`
+	Denomination json.Uint8 `json:"denomination"`
+}
+
+// chain resolves Query.chain to the ID of the chain
+func (r *resolver) chain(_ interface{}, args graphql.Args) (interface{}, error) {
+	chain, err := args.String("id", "")
+	if err != nil {
+		return nil, err
+	}
+	if chain == "" {
+		return nil, errNoChain
+	}
+	return r.indexer.lookupChain(chain)
+}
+
+// container returns the resolver of the field of Chain that is a container of
+// the chain's index named [name], given by its ID or index
+func (r *resolver) container(name string) graphql.Resolver {
+	return func(chain interface{}, args graphql.Args) (interface{}, error) {
+		id, err := args.String("id", "")
+		if err != nil {
+			return nil, err
+		}
+		if id != "" {
+			containerID, err := ids.FromString(id)
+			if err != nil {
+				return nil, fmt.Errorf("problem parsing id %q: %w", id, err)
+			}
+			return r.containerByID(chain.(ids.ID), name, containerID)
+		}
+
+		if _, ok := args["index"]; !ok {
+			return nil, errNoContainer
+		}
+		index, err := args.Uint64("index", 0)
+		if err != nil {
+			return nil, err
+		}
+
+		r.indexer.lock.Lock()
+		defer r.indexer.lock.Unlock()
+
+		idx, err := r.indexer.getIndex(chain.(ids.ID), name)
+		if err != nil {
+			return nil, err
+		}
+		c, err := idx.container(index)
+		if err != nil {
+			return nil, err
+		}
+		formatted := newFormattedContainer(c, index)
+		return &formatted, nil
+	}
+}
+
+// containerByID returns the container [containerID] of the index named [name]
+// of the chain [chainID], or nil if it isn't in the index
+func (r *resolver) containerByID(chainID ids.ID, name string, containerID ids.ID) (interface{}, error) {
+	r.indexer.lock.Lock()
+	defer r.indexer.lock.Unlock()
+
+	idx, err := r.indexer.getIndex(chainID, name)
+	if err != nil {
+		return nil, err
+	}
+	has, err := idx.indices.Has(containerID.Bytes())
+	if err != nil || !has {
+		return nil, err
+	}
+	index, err := idx.indexOf(containerID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := idx.container(index)
+	if err != nil {
+		return nil, err
+	}
+	formatted := newFormattedContainer(c, index)
+	return &formatted, nil
+}
+
+// page returns the resolver of the field of Chain that is a page of the
+// chain's index named [name]
+func (r *resolver) page(name string) graphql.Resolver {
+	return func(chain interface{}, args graphql.Args) (interface{}, error) {
+		limit, err := parseFirst(args)
+		if err != nil {
+			return nil, err
+		}
+		start, err := args.Uint64("start", 0)
+		if err != nil {
+			return nil, err
+		}
+		reverse, err := args.Bool("reverse", false)
+		if err != nil {
+			return nil, err
+		}
+		cursor, err := args.String("cursor", "")
+		if err != nil {
+			return nil, err
+		}
+
+		r.indexer.lock.Lock()
+		defer r.indexer.lock.Unlock()
+
+		idx, err := r.indexer.getIndex(chain.(ids.ID), name)
+		if err != nil {
+			return nil, err
+		}
+
+		direction := forward
+		if reverse {
+			direction = backward
+			// Without a start, a reversed page starts at the most recently
+			// accepted container
+			if _, ok := args["start"]; !ok && idx.numAccepted() != 0 {
+				start = idx.numAccepted() - 1
+			}
+		}
+		if cursor != "" {
+			if start, direction, err = parseCursor(cursor); err != nil {
+				return nil, err
+			}
+		}
+		reply := &GetRangeReply{}
+		return reply, page(idx, start, direction, limit, reply)
+	}
+}
+
+// parseFirst returns the number of items to fetch for the argument "first" in
+// [args]
+func parseFirst(args graphql.Args) (uint64, error) {
+	first, err := args.Uint64("first", 0)
+	switch {
+	case err != nil:
+		return 0, err
+	case first > maxFetch:
+		return 0, fmt.Errorf("first must be at most %d", maxFetch)
+	}
+	return parseLimit(json.Uint32(first))
+}
+
+// address resolves Chain.address
+func (r *resolver) address(chain interface{}, args graphql.Args) (interface{}, error) {
+	addr, err := args.String("address", "")
+	if err != nil {
+		return nil, err
+	}
+	if addr == "" {
+		return nil, errNoAddress
+	}
+	return &address{
+		chainID: chain.(ids.ID),
+		address: addr,
+	}, nil
+}
+
+// balance resolves Address.balance by calling getBalance on the chain
+func (r *resolver) balance(addr interface{}, args graphql.Args) (interface{}, error) {
+	assetID, err := args.String("assetID", "")
+	if err != nil {
+		return nil, err
+	}
+	reply := struct {
+		Balance json.Uint64 `json:"balance"`
+	}{}
+	err = r.call(addr.(*address).chainID, "getBalance", map[string]string{
+		"address": addr.(*address).address,
+		"assetID": assetID,
+	}, &reply)
+	return reply.Balance, err
+}
+
+// addressTxs resolves Address.transactions by calling getAddressTxs on the
+// chain
+func (r *resolver) addressTxs(addr interface{}, args graphql.Args) (interface{}, error) {
+	limit, err := parseFirst(args)
+	if err != nil {
+		return nil, err
+	}
+	startHeight, err := args.Uint64("startHeight", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	chainID := addr.(*address).chainID
+	reply := struct {
+		Txs []*addressTx `json:"txs"`
+	}{}
+	err = r.call(chainID, "getAddressTxs", map[string]interface{}{
+		"address":     addr.(*address).address,
+		"startHeight": json.Uint64(startHeight),
+		"limit":       json.Uint32(limit),
+	}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range reply.Txs {
+		tx.chainID = chainID
+	}
+	if reply.Txs == nil {
+		reply.Txs = []*addressTx{}
+	}
+	return reply.Txs, nil
+}
+
+// asset resolves Chain.asset by calling getAssetDescription on the chain
+func (r *resolver) asset(chain interface{}, args graphql.Args) (interface{}, error) {
+	assetID, err := args.String("id", "")
+	if err != nil {
+		return nil, err
+	}
+	reply := &asset{chainID: chain.(ids.ID)}
+	err = r.call(chain.(ids.ID), "getAssetDescription", map[string]string{"assetID": assetID}, reply)
+	return reply, err
+}
+
+// call the method of the API of the chain [chainID] that's named [method] in
+// whichever service the chain's API registers it under
+func (r *resolver) call(chainID ids.ID, method string, args, reply interface{}) error {
+	for _, m := range r.caller.ChainMethods(chainID) {
+		if strings.HasSuffix(m, "."+method) {
+			return r.caller.CallChain(chainID, m, args, reply)
+		}
+	}
+	return fmt.Errorf("chain %s has no %s method", chainID, method)
+}
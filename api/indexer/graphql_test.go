@@ -0,0 +1,86 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ava-labs/gecko/api/graphql"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// testCaller replies to calls of the chain's API with canned replies
+type testCaller struct{ replies map[string]string }
+
+func (c *testCaller) ChainMethods(ids.ID) []string {
+	return []string{"avm.getAddressTxs", "avm.getAssetDescription", "avm.getBalance"}
+}
+
+func (c *testCaller) CallChain(_ ids.ID, method string, _, reply interface{}) error {
+	return json.Unmarshal([]byte(c.replies[method]), reply)
+}
+
+func TestGraphQL(t *testing.T) {
+	decisions, consensus := dispatchers()
+
+	i := &Indexer{}
+	i.Initialize(logging.NoLog{}, memdb.New(), nil, chainLookup(t))
+	if err := i.Register(decisions, consensus); err != nil {
+		t.Fatal(err)
+	}
+	txID := ids.NewID([32]byte{20})
+	for b := byte(0); b < 3; b++ {
+		consensus.Accept(chainID, ids.NewID([32]byte{10 + b}), []byte{b})
+	}
+	decisions.Accept(chainID, txID, []byte{0})
+
+	caller := &testCaller{
+		replies: map[string]string{
+			"avm.getAddressTxs":       `{"txs": [{"txID": "` + txID.String() + `", "height": "0", "timestamp": "5"}]}`,
+			"avm.getAssetDescription": `{"assetID": "` + txID.String() + `", "name": "Asset", "symbol": "A", "denomination": 2}`,
+			"avm.getBalance":          `{"balance": "7"}`,
+		},
+	}
+	schema := newSchema(&resolver{indexer: i, caller: caller})
+
+	query := `{
+		chain(id: "X") {
+			containers(first: 2, reverse: true) { containers { index } cursor numAccepted }
+			first: container(index: 0) { id }
+			decision(id: "` + txID.String() + `") { index }
+			address(address: "X-addr") {
+				balance(assetID: "A")
+				transactions { id timestamp decision { index } }
+			}
+			asset(id: "A") { name denomination decision { index } }
+		}
+	}`
+	response := schema.Execute(&graphql.Request{Query: query})
+	if len(response.Errors) != 0 {
+		t.Fatalf("query shouldn't have errored but got %s", response.Errors[0].Message)
+	}
+
+	b, err := json.Marshal(response.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"chain":{` +
+		`"containers":{"containers":[{"index":"2"},{"index":"1"}],"cursor":"` + newCursor(0, backward) + `","numAccepted":"3"},` +
+		`"first":{"id":"` + ids.NewID([32]byte{10}).String() + `"},` +
+		`"decision":{"index":"0"},` +
+		`"address":{"balance":"7","transactions":[{"id":"` + txID.String() + `","timestamp":"5","decision":{"index":"0"}}]},` +
+		`"asset":{"name":"Asset","denomination":"2","decision":{"index":"0"}}` +
+		`}}`
+	if string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, b)
+	}
+
+	response = schema.Execute(&graphql.Request{Query: `{ chain(id: "Y") { id } }`})
+	if len(response.Errors) != 1 {
+		t.Fatal("querying an unknown chain should have errored")
+	}
+}
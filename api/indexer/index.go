@@ -0,0 +1,171 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+var (
+	errNoContainers = errors.New("no containers have been accepted")
+
+	nextIndexKey = []byte("next index")
+)
+
+// Container is a container, a block, vertex or transaction, accepted by a
+// chain
+type Container struct {
+	// ID of the container
+	ID ids.ID `serialize:"true"`
+
+	// Byte representation of the container
+	Bytes []byte `serialize:"true"`
+
+	// Unix time, in seconds, at which this node accepted the container
+	Timestamp uint64 `serialize:"true"`
+}
+
+// index is the record of the containers accepted by a chain, in the order they
+// were accepted in. The first container accepted has index 0.
+type index struct {
+	codec codec.Codec
+	clock *timer.Clock
+
+	db *versiondb.Database
+
+	// Key: index
+	// Value: the container accepted at that index
+	containers database.Database
+
+	// Key: container ID
+	// Value: index the container was accepted at
+	indices database.Database
+
+	// The index the next accepted container is given
+	nextIndex uint64
+}
+
+// newIndex returns the index stored in [db]
+func newIndex(db database.Database, c codec.Codec, clock *timer.Clock) (*index, error) {
+	vdb := versiondb.New(db)
+	i := &index{
+		codec:      c,
+		clock:      clock,
+		db:         vdb,
+		containers: prefixdb.New([]byte("containers"), vdb),
+		indices:    prefixdb.New([]byte("indices"), vdb),
+	}
+
+	nextIndexBytes, err := vdb.Get(nextIndexKey)
+	switch err {
+	case nil:
+		nextIndex, err := unpackIndex(nextIndexBytes)
+		if err != nil {
+			return nil, err
+		}
+		i.nextIndex = nextIndex
+	case database.ErrNotFound:
+	default:
+		return nil, err
+	}
+	return i, nil
+}
+
+// accept records that the container [containerID], whose byte representation
+// is [container], was accepted. Containers that are already indexed are
+// ignored.
+func (i *index) accept(containerID ids.ID, container []byte) error {
+	if has, err := i.indices.Has(containerID.Bytes()); err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+
+	containerBytes, err := i.codec.Marshal(&Container{
+		ID:        containerID,
+		Bytes:     container,
+		Timestamp: i.clock.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	indexBytes := packIndex(i.nextIndex)
+	nextIndexBytes := packIndex(i.nextIndex + 1)
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		i.containers.Put(indexBytes, containerBytes),
+		i.indices.Put(containerID.Bytes(), indexBytes),
+		i.db.Put(nextIndexKey, nextIndexBytes),
+	)
+	if errs.Errored() {
+		return errs.Err
+	}
+	if err := i.db.Commit(); err != nil {
+		return err
+	}
+	i.nextIndex++
+	return nil
+}
+
+// numAccepted returns the number of containers in this index
+func (i *index) numAccepted() uint64 { return i.nextIndex }
+
+// container returns the container accepted at [index]
+func (i *index) container(index uint64) (*Container, error) {
+	if index >= i.nextIndex {
+		return nil, fmt.Errorf("no container has index %d", index)
+	}
+	containerBytes, err := i.containers.Get(packIndex(index))
+	if err != nil {
+		return nil, err
+	}
+	container := &Container{}
+	return container, i.codec.Unmarshal(containerBytes, container)
+}
+
+// lastAccepted returns the most recently accepted container and its index
+func (i *index) lastAccepted() (*Container, uint64, error) {
+	if i.nextIndex == 0 {
+		return nil, 0, errNoContainers
+	}
+	container, err := i.container(i.nextIndex - 1)
+	return container, i.nextIndex - 1, err
+}
+
+// indexOf returns the index the container [containerID] was accepted at
+func (i *index) indexOf(containerID ids.ID) (uint64, error) {
+	indexBytes, err := i.indices.Get(containerID.Bytes())
+	if err == database.ErrNotFound {
+		return 0, fmt.Errorf("container %s isn't indexed", containerID)
+	} else if err != nil {
+		return 0, err
+	}
+	return unpackIndex(indexBytes)
+}
+
+// packIndex returns the key [index] is stored under. Indices are packed big
+// endian, so that the keys sort in the order the containers were accepted in.
+func packIndex(index uint64) []byte {
+	p := wrappers.Packer{Bytes: make([]byte, wrappers.LongLen)}
+	p.PackLong(index)
+	return p.Bytes
+}
+
+// unpackIndex returns the index stored as [bytes]
+func unpackIndex(bytes []byte) (uint64, error) {
+	p := wrappers.Packer{Bytes: bytes}
+	index := p.UnpackLong()
+	return index, p.Err
+}
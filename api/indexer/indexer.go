@@ -0,0 +1,257 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/codec"
+
+	jsoncodec "github.com/ava-labs/gecko/utils/json"
+)
+
+const (
+	// ContainersIndex is the name of the index of the containers a chain runs
+	// consensus on: the blocks of a linear chain and the vertices of a DAG.
+	ContainersIndex = "containers"
+
+	// DecisionsIndex is the name of the index of the decisions a chain makes:
+	// the blocks of a linear chain and the transactions of a DAG.
+	DecisionsIndex = "decisions"
+
+	// Name the indexer registers with the event dispatchers under
+	dispatcherID = "indexer"
+
+	// Prefix of each chain's address index
+	addressesPrefix = "addresses"
+)
+
+// ChainLookup returns the ID of the chain that has ID or alias [alias]
+type ChainLookup interface {
+	Lookup(alias string) (ids.ID, error)
+}
+
+// Indexer records every container accepted by the chains it indexes, so that
+// they can be fetched, in the order they were accepted in, through its API
+type Indexer struct {
+	lock  sync.Mutex
+	log   logging.Logger
+	codec codec.Codec
+	clock timer.Clock
+
+	chainLookup ChainLookup
+
+	// The chains whose containers are indexed. If empty, the containers of
+	// every chain are indexed.
+	chains ids.Set
+
+	// Used to persist the indices
+	db database.Database
+	//               BaseDB
+	//           /     |      \
+	//     ChainID  ChainID  ChainID
+	//       /   |   \
+	// containers decisions addresses
+
+	// Key: Chain ID
+	// Value: The chain's indices, by name
+	indices map[[32]byte]map[string]*index
+
+	// Key: Chain ID
+	// Value: The VM of the chain, if it reports the addresses its
+	// transactions touch
+	vms map[[32]byte]common.AddressableVM
+
+	// Key: Chain ID
+	// Value: The chain's address index
+	addresses map[[32]byte]*addressIndex
+}
+
+// Initialize the indexer. The containers of the chains in [chains], or of
+// every chain if [chains] is empty, are indexed in [db].
+func (i *Indexer) Initialize(log logging.Logger, db database.Database, chains []ids.ID, chainLookup ChainLookup) {
+	i.log = log
+	i.codec = codec.NewDefault()
+	i.chainLookup = chainLookup
+	i.chains.Add(chains...)
+	i.db = db
+	i.indices = make(map[[32]byte]map[string]*index)
+	i.vms = make(map[[32]byte]common.AddressableVM)
+	i.addresses = make(map[[32]byte]*addressIndex)
+}
+
+// RegisterChain implements the chains.Registrant interface. If the chain's VM
+// reports the addresses its transactions touch, the transactions the chain
+// accepts from now on are indexed by address.
+func (i *Indexer) RegisterChain(ctx *snow.Context, vm interface{}) {
+	addressable, ok := vm.(common.AddressableVM)
+	if !ok {
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.chains.Len() != 0 && !i.chains.Contains(ctx.ChainID) {
+		return
+	}
+	i.vms[ctx.ChainID.Key()] = addressable
+	i.log.Info("indexing the transactions of chain %s by address", ctx.ChainID)
+}
+
+// Register the indexer with the dispatchers of the events that chains make
+// decisions and accept containers
+func (i *Indexer) Register(decisions, consensus *triggers.EventDispatcher) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		decisions.Register(dispatcherID, &acceptor{indexer: i, name: DecisionsIndex}),
+		consensus.Register(dispatcherID, &acceptor{indexer: i, name: ContainersIndex}),
+	)
+	return errs.Err
+}
+
+// CreateHandler returns a new service object that can send requests to this
+// API
+func (i *Indexer) CreateHandler() *common.HTTPHandler {
+	newServer := jsoncodec.NewServer()
+	codec := jsoncodec.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Service{indexer: i}, "index")
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: newServer}
+}
+
+// CreateChainHandler returns a new service object that can send requests to
+// the API of a chain's indices. It's served at ChainEndpoint, which gives the
+// chain in the path.
+func (i *Indexer) CreateChainHandler() *common.HTTPHandler {
+	newServer := jsoncodec.NewServer()
+	codec := jsoncodec.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&ChainService{indexer: i}, "index")
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: newServer}
+}
+
+// accept adds the container [containerID], accepted by chain [chainID], to the
+// chain's index named [name]
+func (i *Indexer) accept(name string, chainID, containerID ids.ID, container []byte) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.chains.Len() != 0 && !i.chains.Contains(chainID) {
+		return nil
+	}
+
+	idx, err := i.getIndex(chainID, name)
+	if err != nil {
+		return err
+	}
+	if err := idx.accept(containerID, container); err != nil {
+		return err
+	}
+	i.log.Verbo("indexed container %s of chain %s in the %s index", containerID, chainID, name)
+
+	vm, addressable := i.vms[chainID.Key()]
+	if name != DecisionsIndex || !addressable {
+		return nil
+	}
+	addrs, err := vm.TxAddresses(containerID)
+	if err != nil {
+		return fmt.Errorf("couldn't find the addresses of tx %s: %w", containerID, err)
+	}
+	index, err := idx.indexOf(containerID)
+	if err != nil {
+		return err
+	}
+	return i.getAddressIndex(chainID).accept(containerID, index, addrs)
+}
+
+// getAddressIndex returns chain [chainID]'s address index
+// Assumes [i.lock] is held
+func (i *Indexer) getAddressIndex(chainID ids.ID) *addressIndex {
+	chainKey := chainID.Key()
+	if addrIdx, exists := i.addresses[chainKey]; exists {
+		return addrIdx
+	}
+	chainDB := prefixdb.New(chainID.Bytes(), i.db)
+	addrIdx := &addressIndex{db: prefixdb.New([]byte(addressesPrefix), chainDB)}
+	i.addresses[chainKey] = addrIdx
+	return addrIdx
+}
+
+// getIndex returns chain [chainID]'s index named [name]
+// Assumes [i.lock] is held
+func (i *Indexer) getIndex(chainID ids.ID, name string) (*index, error) {
+	if name != ContainersIndex && name != DecisionsIndex {
+		return nil, fmt.Errorf("unknown index %q", name)
+	}
+
+	chainKey := chainID.Key()
+	chainIndices, exists := i.indices[chainKey]
+	if !exists {
+		chainIndices = make(map[string]*index)
+		i.indices[chainKey] = chainIndices
+	}
+	if idx, exists := chainIndices[name]; exists {
+		return idx, nil
+	}
+
+	chainDB := prefixdb.New(chainID.Bytes(), i.db)
+	idx, err := newIndex(prefixdb.New([]byte(name), chainDB), i.codec, &i.clock)
+	if err != nil {
+		return nil, err
+	}
+	chainIndices[name] = idx
+	return idx, nil
+}
+
+// lookupIndex returns the index named [name] of the chain that has ID or alias
+// [chain]
+// Assumes [i.lock] is held
+func (i *Indexer) lookupIndex(chain, name string) (*index, error) {
+	chainID, err := i.lookupChain(chain)
+	if err != nil {
+		return nil, err
+	}
+	return i.getIndex(chainID, name)
+}
+
+// lookupChain returns the ID of the indexed chain that has ID or alias [chain]
+func (i *Indexer) lookupChain(chain string) (ids.ID, error) {
+	chainID, err := i.chainLookup.Lookup(chain)
+	if err != nil {
+		// The chain may have been indexed before this node stopped running it
+		chainID, err = ids.FromString(chain)
+		if err != nil {
+			return ids.ID{}, fmt.Errorf("couldn't find chain %q", chain)
+		}
+	}
+	if i.chains.Len() != 0 && !i.chains.Contains(chainID) {
+		return ids.ID{}, fmt.Errorf("chain %s isn't indexed", chainID)
+	}
+	return chainID, nil
+}
+
+// acceptor adds the containers an event dispatcher reports as accepted to the
+// index named [name]
+type acceptor struct {
+	indexer *Indexer
+	name    string
+}
+
+// Accept implements the triggers.Acceptor interface
+func (a *acceptor) Accept(chainID, containerID ids.ID, container []byte) error {
+	return a.indexer.accept(a.name, chainID, containerID, container)
+}
@@ -0,0 +1,206 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+var (
+	chainID      = ids.NewID([32]byte{1})
+	otherChainID = ids.NewID([32]byte{2})
+)
+
+func dispatchers() (*triggers.EventDispatcher, *triggers.EventDispatcher) {
+	decisions := &triggers.EventDispatcher{}
+	decisions.Initialize(logging.NoLog{})
+	consensus := &triggers.EventDispatcher{}
+	consensus.Initialize(logging.NoLog{})
+	return decisions, consensus
+}
+
+func chainLookup(t *testing.T) *ids.Aliaser {
+	aliaser := &ids.Aliaser{}
+	aliaser.Initialize()
+	if err := aliaser.Alias(chainID, "X"); err != nil {
+		t.Fatal(err)
+	}
+	return aliaser
+}
+
+func TestIndexerAccept(t *testing.T) {
+	db := memdb.New()
+	decisions, consensus := dispatchers()
+
+	i := &Indexer{}
+	i.Initialize(logging.NoLog{}, db, nil, chainLookup(t))
+	i.clock.Set(time.Unix(1000, 0))
+	if err := i.Register(decisions, consensus); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{indexer: i}
+
+	vtxID := ids.NewID([32]byte{3})
+	txID0 := ids.NewID([32]byte{4})
+	txID1 := ids.NewID([32]byte{5})
+	consensus.Accept(chainID, vtxID, []byte{3})
+	decisions.Accept(chainID, txID0, []byte{4})
+	decisions.Accept(chainID, txID1, []byte{5})
+	decisions.Accept(chainID, txID0, []byte{4}) // already indexed
+
+	reply := FormattedContainer{}
+	if err := s.GetLastAccepted(nil, &IndexArgs{ChainID: "X"}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.ID != vtxID.String() || reply.Index != 0 || reply.Timestamp != 1000 || !bytes.Equal(reply.Bytes.Bytes, []byte{3}) {
+		t.Fatalf("wrong last accepted container: %+v", reply)
+	}
+
+	rangeReply := GetContainerRangeReply{}
+	if err := s.GetContainerRange(nil, &GetContainerRangeArgs{
+		IndexArgs:  IndexArgs{ChainID: chainID.String(), Type: DecisionsIndex},
+		NumToFetch: 10,
+	}, &rangeReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(rangeReply.Containers) != 2 {
+		t.Fatalf("should have fetched 2 containers but fetched %d", len(rangeReply.Containers))
+	}
+	for index, txID := range []ids.ID{txID0, txID1} {
+		if container := rangeReply.Containers[index]; container.ID != txID.String() || int(container.Index) != index {
+			t.Fatalf("wrong container at index %d: %+v", index, container)
+		}
+	}
+
+	// The index is persisted
+	i = &Indexer{}
+	i.Initialize(logging.NoLog{}, db, nil, chainLookup(t))
+	s = &Service{indexer: i}
+
+	indexReply := GetIndexReply{}
+	if err := s.GetIndex(nil, &GetIndexArgs{
+		IndexArgs:   IndexArgs{ChainID: "X", Type: DecisionsIndex},
+		ContainerID: txID1.String(),
+	}, &indexReply); err != nil {
+		t.Fatal(err)
+	}
+	if indexReply.Index != 1 {
+		t.Fatalf("container should have index 1 but has %d", indexReply.Index)
+	}
+
+	if err := s.GetContainerByIndex(nil, &GetContainerByIndexArgs{
+		IndexArgs: IndexArgs{ChainID: "X", Type: DecisionsIndex},
+		Index:     2,
+	}, &reply); err == nil {
+		t.Fatal("should have failed because no container has index 2")
+	}
+}
+
+func TestIndexerEnabledChains(t *testing.T) {
+	decisions, consensus := dispatchers()
+
+	i := &Indexer{}
+	i.Initialize(logging.NoLog{}, memdb.New(), []ids.ID{chainID}, chainLookup(t))
+	if err := i.Register(decisions, consensus); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{indexer: i}
+
+	consensus.Accept(otherChainID, ids.NewID([32]byte{3}), []byte{3})
+
+	reply := FormattedContainer{}
+	if err := s.GetLastAccepted(nil, &IndexArgs{ChainID: otherChainID.String()}, &reply); err == nil {
+		t.Fatal("should have failed because the chain isn't indexed")
+	}
+	if err := s.GetLastAccepted(nil, &IndexArgs{ChainID: "X"}, &reply); err == nil {
+		t.Fatal("should have failed because the chain hasn't accepted any containers")
+	}
+}
+
+// testAddressableVM reports that each transaction touched the addresses it's
+// mapped to, and parses addresses as their string
+type testAddressableVM struct{ addrs map[[32]byte][][]byte }
+
+func (vm *testAddressableVM) TxAddresses(txID ids.ID) ([][]byte, error) {
+	return vm.addrs[txID.Key()], nil
+}
+
+func (vm *testAddressableVM) Parse(addr string) ([]byte, error) { return []byte(addr), nil }
+
+func TestIndexerAddresses(t *testing.T) {
+	decisions, consensus := dispatchers()
+
+	i := &Indexer{}
+	i.Initialize(logging.NoLog{}, memdb.New(), nil, chainLookup(t))
+	if err := i.Register(decisions, consensus); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{indexer: i}
+
+	txIDs := []ids.ID{ids.NewID([32]byte{3}), ids.NewID([32]byte{4}), ids.NewID([32]byte{5})}
+	vm := &testAddressableVM{addrs: map[[32]byte][][]byte{
+		txIDs[0].Key(): {[]byte("alice")},
+		txIDs[1].Key(): {[]byte("bob")},
+		txIDs[2].Key(): {[]byte("alice"), []byte("bob")},
+	}}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = chainID
+	i.RegisterChain(ctx, vm)
+	for j, txID := range txIDs {
+		decisions.Accept(chainID, txID, []byte{byte(j)})
+	}
+
+	reply := GetTxsByAddressReply{}
+	if err := s.GetTxsByAddress(nil, &GetTxsByAddressArgs{
+		ChainID:    "X",
+		Address:    "alice",
+		NumToFetch: 1,
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Txs) != 1 || reply.Txs[0].ID != txIDs[0].String() || reply.Txs[0].Index != 0 {
+		t.Fatalf("wrong first page of transactions: %+v", reply.Txs)
+	}
+
+	// The next page starts after the last transaction of the first
+	if err := s.GetTxsByAddress(nil, &GetTxsByAddressArgs{
+		ChainID:    "X",
+		Address:    "alice",
+		StartIndex: reply.NextIndex,
+		NumToFetch: 10,
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Txs) != 1 || reply.Txs[0].ID != txIDs[2].String() || reply.Txs[0].Index != 2 || reply.NextIndex != 3 {
+		t.Fatalf("wrong second page of transactions: %+v", reply)
+	}
+
+	if err := s.GetTxsByAddress(nil, &GetTxsByAddressArgs{
+		ChainID:    "X",
+		Address:    "carol",
+		NumToFetch: 10,
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Txs) != 0 {
+		t.Fatalf("an address without transactions shouldn't have any: %+v", reply.Txs)
+	}
+
+	// Chains whose VMs don't report addresses aren't indexed by address
+	if err := s.GetTxsByAddress(nil, &GetTxsByAddressArgs{
+		ChainID:    otherChainID.String(),
+		Address:    "alice",
+		NumToFetch: 10,
+	}, &reply); err == nil {
+		t.Fatal("should have failed because the chain isn't indexed by address")
+	}
+}
@@ -0,0 +1,272 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+const (
+	// maxFetch is the most containers that can be fetched in one call to
+	// GetContainerRange
+	maxFetch = 1024
+)
+
+var (
+	errZeroNumToFetch = errors.New("numToFetch must be at least 1")
+)
+
+// Service is the API service for the indexer
+type Service struct{ indexer *Indexer }
+
+// FormattedContainer is the API representation of a container
+type FormattedContainer struct {
+	ID        string          `json:"id"`
+	Bytes     formatting.CB58 `json:"bytes"`
+	Timestamp json.Uint64     `json:"timestamp"`
+	Index     json.Uint64     `json:"index"`
+}
+
+func newFormattedContainer(container *Container, index uint64) FormattedContainer {
+	return FormattedContainer{
+		ID:        container.ID.String(),
+		Bytes:     formatting.CB58{Bytes: container.Bytes},
+		Timestamp: json.Uint64(container.Timestamp),
+		Index:     json.Uint64(index),
+	}
+}
+
+// IndexArgs identify an index
+type IndexArgs struct {
+	// ID or alias of the chain whose containers are fetched
+	ChainID string `json:"chainID"`
+
+	// The index the containers are fetched from, either "containers" or
+	// "decisions". Defaults to "containers".
+	Type string `json:"type"`
+}
+
+// lookupIndex returns the index [args] identify
+// Assumes [s.indexer.lock] is held
+func (s *Service) lookupIndex(args *IndexArgs) (*index, error) {
+	name := args.Type
+	if name == "" {
+		name = ContainersIndex
+	}
+	return s.indexer.lookupIndex(args.ChainID, name)
+}
+
+// GetLastAccepted returns the most recently accepted container
+func (s *Service) GetLastAccepted(_ *http.Request, args *IndexArgs, reply *FormattedContainer) error {
+	s.indexer.lock.Lock()
+	defer s.indexer.lock.Unlock()
+
+	s.indexer.log.Verbo("GetLastAccepted called for the %s index of chain %s", args.Type, args.ChainID)
+
+	idx, err := s.lookupIndex(args)
+	if err != nil {
+		return err
+	}
+	container, index, err := idx.lastAccepted()
+	if err != nil {
+		return err
+	}
+	*reply = newFormattedContainer(container, index)
+	return nil
+}
+
+// GetContainerByIndexArgs are the arguments for calling GetContainerByIndex
+type GetContainerByIndexArgs struct {
+	IndexArgs
+	Index json.Uint64 `json:"index"`
+}
+
+// GetContainerByIndex returns the container accepted at the given index
+func (s *Service) GetContainerByIndex(_ *http.Request, args *GetContainerByIndexArgs, reply *FormattedContainer) error {
+	s.indexer.lock.Lock()
+	defer s.indexer.lock.Unlock()
+
+	s.indexer.log.Verbo("GetContainerByIndex called for index %d of the %s index of chain %s", args.Index, args.Type, args.ChainID)
+
+	idx, err := s.lookupIndex(&args.IndexArgs)
+	if err != nil {
+		return err
+	}
+	container, err := idx.container(uint64(args.Index))
+	if err != nil {
+		return err
+	}
+	*reply = newFormattedContainer(container, uint64(args.Index))
+	return nil
+}
+
+// GetContainerRangeArgs are the arguments for calling GetContainerRange
+type GetContainerRangeArgs struct {
+	IndexArgs
+	StartIndex json.Uint64 `json:"startIndex"`
+	NumToFetch json.Uint64 `json:"numToFetch"`
+}
+
+// GetContainerRangeReply is the response from calling GetContainerRange
+type GetContainerRangeReply struct {
+	Containers []FormattedContainer `json:"containers"`
+}
+
+// GetContainerRange returns, in the order they were accepted in, up to
+// [numToFetch] containers starting at [startIndex]. At most 1024 containers
+// are returned. Fewer are returned if the range goes past the last accepted
+// container.
+func (s *Service) GetContainerRange(_ *http.Request, args *GetContainerRangeArgs, reply *GetContainerRangeReply) error {
+	s.indexer.lock.Lock()
+	defer s.indexer.lock.Unlock()
+
+	s.indexer.log.Verbo("GetContainerRange called for %d containers from index %d of the %s index of chain %s", args.NumToFetch, args.StartIndex, args.Type, args.ChainID)
+
+	switch {
+	case args.NumToFetch == 0:
+		return errZeroNumToFetch
+	case args.NumToFetch > maxFetch:
+		return fmt.Errorf("numToFetch must be at most %d", maxFetch)
+	}
+
+	idx, err := s.lookupIndex(&args.IndexArgs)
+	if err != nil {
+		return err
+	}
+
+	start := uint64(args.StartIndex)
+	end := start + uint64(args.NumToFetch)
+	if numAccepted := idx.numAccepted(); end > numAccepted || end < start {
+		end = numAccepted
+	}
+
+	reply.Containers = []FormattedContainer{}
+	for index := start; index < end; index++ {
+		container, err := idx.container(index)
+		if err != nil {
+			return err
+		}
+		reply.Containers = append(reply.Containers, newFormattedContainer(container, index))
+	}
+	return nil
+}
+
+// GetIndexArgs are the arguments for calling GetIndex
+type GetIndexArgs struct {
+	IndexArgs
+	ContainerID string `json:"containerID"`
+}
+
+// GetIndexReply is the response from calling GetIndex
+type GetIndexReply struct {
+	Index json.Uint64 `json:"index"`
+}
+
+// GetIndex returns the index the given container was accepted at
+func (s *Service) GetIndex(_ *http.Request, args *GetIndexArgs, reply *GetIndexReply) error {
+	s.indexer.lock.Lock()
+	defer s.indexer.lock.Unlock()
+
+	s.indexer.log.Verbo("GetIndex called for container %s of the %s index of chain %s", args.ContainerID, args.Type, args.ChainID)
+
+	containerID, err := ids.FromString(args.ContainerID)
+	if err != nil {
+		return fmt.Errorf("problem parsing containerID %q: %w", args.ContainerID, err)
+	}
+
+	idx, err := s.lookupIndex(&args.IndexArgs)
+	if err != nil {
+		return err
+	}
+	index, err := idx.indexOf(containerID)
+	if err != nil {
+		return err
+	}
+	reply.Index = json.Uint64(index)
+	return nil
+}
+
+// GetTxsByAddressArgs are the arguments for calling GetTxsByAddress
+type GetTxsByAddressArgs struct {
+	// ID or alias of the chain whose transactions are fetched
+	ChainID string `json:"chainID"`
+
+	// Address whose transactions are fetched, formatted as the chain formats
+	// its addresses
+	Address string `json:"address"`
+
+	StartIndex json.Uint64 `json:"startIndex"`
+	NumToFetch json.Uint64 `json:"numToFetch"`
+}
+
+// GetTxsByAddressReply is the response from calling GetTxsByAddress
+type GetTxsByAddressReply struct {
+	// The transactions, with the index they were accepted at in the chain's
+	// decisions index
+	Txs []FormattedContainer `json:"txs"`
+
+	// Fetches the next page when passed as the start index
+	NextIndex json.Uint64 `json:"nextIndex"`
+}
+
+// GetTxsByAddress returns, in the order they were accepted in, up to
+// [numToFetch] of the transactions that touched [address] and were accepted
+// at [startIndex] of the chain's decisions index or later. At most 1024
+// transactions are returned. Only the transactions accepted since the chain's
+// addresses started being indexed are returned.
+func (s *Service) GetTxsByAddress(_ *http.Request, args *GetTxsByAddressArgs, reply *GetTxsByAddressReply) error {
+	s.indexer.lock.Lock()
+	defer s.indexer.lock.Unlock()
+
+	s.indexer.log.Verbo("GetTxsByAddress called for %d txs of address %s from index %d of chain %s", args.NumToFetch, args.Address, args.StartIndex, args.ChainID)
+
+	switch {
+	case args.NumToFetch == 0:
+		return errZeroNumToFetch
+	case args.NumToFetch > maxFetch:
+		return fmt.Errorf("numToFetch must be at most %d", maxFetch)
+	case args.Address == "":
+		return errNoAddress
+	}
+
+	chainID, err := s.indexer.lookupChain(args.ChainID)
+	if err != nil {
+		return err
+	}
+	vm, addressable := s.indexer.vms[chainID.Key()]
+	if !addressable {
+		return fmt.Errorf("the transactions of chain %s aren't indexed by address", chainID)
+	}
+	addr, err := vm.Parse(args.Address)
+	if err != nil {
+		return fmt.Errorf("problem parsing address %q: %w", args.Address, err)
+	}
+	idx, err := s.indexer.getIndex(chainID, DecisionsIndex)
+	if err != nil {
+		return err
+	}
+
+	indices, err := s.indexer.getAddressIndex(chainID).txs(addr, uint64(args.StartIndex), uint64(args.NumToFetch))
+	if err != nil {
+		return fmt.Errorf("problem retrieving the transactions of %s: %w", args.Address, err)
+	}
+
+	reply.Txs = []FormattedContainer{}
+	reply.NextIndex = args.StartIndex
+	for _, index := range indices {
+		container, err := idx.container(index)
+		if err != nil {
+			return err
+		}
+		reply.Txs = append(reply.Txs, newFormattedContainer(container, index))
+		reply.NextIndex = json.Uint64(index + 1)
+	}
+	return nil
+}
@@ -17,11 +17,17 @@ type ChainIPC struct {
 	socket mangos.Socket
 }
 
-// Accept delivers a message to the ChainIPC
+// Accept publishes an accepted container to the ChainIPC's subscribers. Each
+// message is the 32 byte ID of the container followed by the container's
+// bytes.
 func (cipc *ChainIPC) Accept(chainID, containerID ids.ID, container []byte) error {
-	err := cipc.socket.Send(container)
+	msg := make([]byte, 0, len(containerID.Bytes())+len(container))
+	msg = append(msg, containerID.Bytes()...)
+	msg = append(msg, container...)
+
+	err := cipc.socket.Send(msg)
 	if err != nil {
-		cipc.log.Error("%s while trying to send:\n%s", err, formatting.DumpBytes{Bytes: container})
+		cipc.log.Error("%s while trying to send %s:\n%s", err, containerID, formatting.DumpBytes{Bytes: container})
 	}
 	return err
 }
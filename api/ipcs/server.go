@@ -6,15 +6,15 @@ package ipcs
 import (
 	"fmt"
 	"net/http"
+	"sort"
 
 	"nanomsg.org/go/mangos/v2/protocol/pub"
 
 	_ "nanomsg.org/go/mangos/v2/transport/ipc" // registers the IPC transport
 
-	"github.com/gorilla/rpc/v2"
-
 	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/snow/triggers"
 	"github.com/ava-labs/gecko/utils/json"
@@ -35,7 +35,7 @@ type IPCs struct {
 
 // NewService returns a new IPCs API service
 func NewService(log logging.Logger, chainManager chains.Manager, events *triggers.EventDispatcher, httpServer *api.Server) *common.HTTPHandler {
-	newServer := rpc.NewServer()
+	newServer := json.NewServer()
 	codec := json.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
 	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
@@ -59,7 +59,8 @@ type PublishBlockchainReply struct {
 	URL string `json:"url"`
 }
 
-// PublishBlockchain publishes the finalized accepted transactions from the blockchainID over the IPC
+// PublishBlockchain publishes the ID and bytes of each container the
+// blockchainID accepts over the IPC
 func (ipc *IPCs) PublishBlockchain(r *http.Request, args *PublishBlockchainArgs, reply *PublishBlockchainReply) error {
 	chainID, err := ipc.chainManager.Lookup(args.BlockchainID)
 	if err != nil {
@@ -69,7 +70,7 @@ func (ipc *IPCs) PublishBlockchain(r *http.Request, args *PublishBlockchainArgs,
 
 	chainIDKey := chainID.Key()
 	chainIDStr := chainID.String()
-	url := baseURL + chainIDStr + ".ipc"
+	url := chainURL(chainID)
 
 	reply.URL = url
 
@@ -139,3 +140,41 @@ func (ipc *IPCs) UnpublishBlockchain(r *http.Request, args *UnpublishBlockchainA
 	reply.Success = true
 	return errs.Err
 }
+
+// GetPublishedBlockchainsArgs are the arguments for calling
+// GetPublishedBlockchains
+type GetPublishedBlockchainsArgs struct{}
+
+// PublishedBlockchain is a blockchainID whose accepted containers are being
+// published, and the URL they're published at
+type PublishedBlockchain struct {
+	BlockchainID ids.ID `json:"blockchainID"`
+	URL          string `json:"url"`
+}
+
+// GetPublishedBlockchainsReply are the results from calling
+// GetPublishedBlockchains
+type GetPublishedBlockchainsReply struct {
+	Blockchains []PublishedBlockchain `json:"blockchains"`
+}
+
+// GetPublishedBlockchains returns the blockchainIDs being published, sorted by
+// ID
+func (ipc *IPCs) GetPublishedBlockchains(r *http.Request, args *GetPublishedBlockchainsArgs, reply *GetPublishedBlockchainsReply) error {
+	reply.Blockchains = make([]PublishedBlockchain, 0, len(ipc.chains))
+	for chainIDKey := range ipc.chains {
+		chainID := ids.NewID(chainIDKey)
+		reply.Blockchains = append(reply.Blockchains, PublishedBlockchain{
+			BlockchainID: chainID,
+			URL:          chainURL(chainID),
+		})
+	}
+	sort.Slice(reply.Blockchains, func(i, j int) bool {
+		return reply.Blockchains[i].BlockchainID.String() < reply.Blockchains[j].BlockchainID.String()
+	})
+	return nil
+}
+
+// chainURL returns the URL that the accepted containers of [chainID] are
+// published at
+func chainURL(chainID ids.ID) string { return baseURL + chainID.String() + ".ipc" }
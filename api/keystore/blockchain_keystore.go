@@ -14,7 +14,16 @@ type BlockchainKeystore struct {
 	ks           *Keystore
 }
 
-// GetDatabase ...
+// GetDatabase returns the database of [username]'s data on this blockchain.
+// [password] is either the user's password or a token issued to them by Login.
 func (bks *BlockchainKeystore) GetDatabase(username, password string) (database.Database, error) {
 	return bks.ks.GetDatabase(bks.blockchainID, username, password)
 }
+
+// GetReadOnlyDatabase returns the database of [username]'s data on this
+// blockchain, which fails to be written to, for calls that only read the
+// user's data. [password] is either the user's password or a token issued to
+// them by Login.
+func (bks *BlockchainKeystore) GetReadOnlyDatabase(username, password string) (database.Database, error) {
+	return bks.ks.GetReadOnlyDatabase(bks.blockchainID, username, password)
+}
@@ -0,0 +1,204 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package keystore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/encdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+// MinMasterKeyLen is the fewest bytes a master key may have
+const MinMasterKeyLen = 32
+
+var (
+	errShortMasterKey      = fmt.Errorf("master key must be at least %d bytes", MinMasterKeyLen)
+	errEncryptionEnabled   = errors.New("the username index is already encrypted")
+	errMismatchedUsername  = errors.New("user record is stored under another username")
+	indexKeyDerivationTag  = []byte("keystore username index")
+	recordKeyDerivationTag = []byte("keystore user records")
+)
+
+// userRecord is how a user is stored when the username index is encrypted,
+// since their username can't be recovered from the key it's stored under
+type userRecord struct {
+	Username string `serialize:"true"`
+	User     `serialize:"true"`
+}
+
+// EnableEncryption makes the keystore store users under a MAC of their
+// username, and encrypt their records, with keys derived from [masterKey]. Their
+// blockchain data is stored under the MAC of their username too, so a copy of
+// the database doesn't reveal who has users on this node. Users stored before
+// encryption was enabled are migrated. Users stored while it's enabled can only
+// be found with the same master key.
+func (ks *Keystore) EnableEncryption(masterKey []byte) error {
+	if len(masterKey) < MinMasterKeyLen {
+		return errShortMasterKey
+	}
+
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	if ks.indexKey != nil {
+		return errEncryptionEnabled
+	}
+	indexKey := deriveKey(masterKey, indexKeyDerivationTag)
+	encryptedUserDB, err := encdb.New(
+		deriveKey(masterKey, recordKeyDerivationTag),
+		prefixdb.New([]byte("encryptedUsers"), ks.db),
+	)
+	if err != nil {
+		return err
+	}
+
+	plainUserDB := ks.userDB
+	ks.indexKey, ks.userDB = indexKey, encryptedUserDB
+	if err := ks.migrate(plainUserDB); err != nil {
+		ks.indexKey, ks.userDB = nil, plainUserDB
+		return fmt.Errorf("couldn't encrypt the users stored in plaintext: %w", err)
+	}
+	return nil
+}
+
+// migrate moves the users in [plainUserDB], and their blockchain data, to
+// where they're stored now that the username index is encrypted. Assumes the
+// lock is held for writing.
+func (ks *Keystore) migrate(plainUserDB database.Database) error {
+	batch := database.NewSharedBatch(ks.db)
+	plainBatch, err := batch.Add(plainUserDB)
+	if err != nil {
+		return err
+	}
+	userBatch, err := batch.Add(ks.userDB)
+	if err != nil {
+		return err
+	}
+
+	it := plainUserDB.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		username := string(it.Key())
+		usr, err := ks.unmarshalPlainUser(it.Value())
+		if err != nil {
+			return err
+		}
+		if exists, err := ks.userDB.Has(ks.userKey(username)); err != nil {
+			return err
+		} else if exists {
+			return fmt.Errorf("user is stored both in plaintext and encrypted: %s", username)
+		}
+		usrBytes, err := ks.marshalUser(username, usr)
+		if err != nil {
+			return err
+		}
+		if err := userBatch.Put(ks.userKey(username), usrBytes); err != nil {
+			return err
+		}
+		if err := plainBatch.Delete(it.Key()); err != nil {
+			return err
+		}
+
+		plainDataDB := prefixdb.New([]byte(username), ks.bcDB)
+		plainDataBatch, err := batch.Add(plainDataDB)
+		if err != nil {
+			return err
+		}
+		dataBatch, err := batch.Add(ks.dataDB(username))
+		if err != nil {
+			return err
+		}
+		dataIt := plainDataDB.NewIterator()
+		for dataIt.Next() {
+			if err := dataBatch.Put(dataIt.Key(), dataIt.Value()); err != nil {
+				dataIt.Release()
+				return err
+			}
+			if err := plainDataBatch.Delete(dataIt.Key()); err != nil {
+				dataIt.Release()
+				return err
+			}
+		}
+		err = dataIt.Error()
+		dataIt.Release()
+		if err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// userKey returns the key that [username]'s record, and the prefix that their
+// blockchain data, is stored under
+func (ks *Keystore) userKey(username string) []byte {
+	if ks.indexKey == nil {
+		return []byte(username)
+	}
+	mac := hmac.New(sha256.New, ks.indexKey)
+	mac.Write([]byte(username))
+	return mac.Sum(nil)
+}
+
+// dataDB returns the database of [username]'s blockchain data
+func (ks *Keystore) dataDB(username string) database.Database {
+	return prefixdb.New(ks.userKey(username), ks.bcDB)
+}
+
+// marshalUser returns the record that [usr], whose name is [username], is
+// stored as
+func (ks *Keystore) marshalUser(username string, usr *User) ([]byte, error) {
+	if ks.indexKey == nil {
+		return ks.codec.Marshal(usr)
+	}
+	return ks.codec.Marshal(&userRecord{
+		Username: username,
+		User:     *usr,
+	})
+}
+
+// unmarshalUser returns the username and user that [usrBytes] is the record of.
+// If the username index isn't encrypted, the username isn't in the record, so
+// it returns [username].
+func (ks *Keystore) unmarshalUser(username string, usrBytes []byte) (string, *User, error) {
+	if ks.indexKey == nil {
+		usr, err := ks.unmarshalPlainUser(usrBytes)
+		return username, usr, err
+	}
+	record := userRecord{}
+	if _, err := ks.codec.Unmarshal(usrBytes, &record); err != nil {
+		return "", nil, err
+	}
+	return record.Username, &record.User, nil
+}
+
+// unmarshalPlainUser returns the user whose record, stored in plaintext, is
+// [usrBytes]
+func (ks *Keystore) unmarshalPlainUser(usrBytes []byte) (*User, error) {
+	usr := &User{}
+	version, err := ks.codec.Unmarshal(usrBytes, usr)
+	if err != nil {
+		return nil, err
+	}
+	if version == codec.Unversioned {
+		// The user was stored before the codec was versioned
+		usr.Params = legacyHashParams
+	}
+	return usr, nil
+}
+
+// deriveKey returns the key for [tag] derived from [masterKey]
+func deriveKey(masterKey, tag []byte) []byte {
+	mac := hmac.New(sha256.New, masterKey)
+	mac.Write(tag)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,98 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package keystore
+
+import (
+	"sync"
+)
+
+// userLock serializes the calls that change a user, or their data, with the
+// other calls on that user
+type userLock struct {
+	sync.RWMutex
+
+	// Number of calls that hold or are waiting for the lock. The lock is
+	// dropped once there are none, so locks of users that were deleted, or
+	// never existed, don't build up.
+	refs int
+}
+
+// lockUser locks [username] for the duration of a call, exclusively if the
+// call [writes] to the user or their data, and returns the function that
+// unlocks it. Assumes the keystore's lock is held for reading.
+func (ks *Keystore) lockUser(username string, writes bool) func() {
+	ks.stateLock.Lock()
+	lock, exists := ks.userLocks[username]
+	if !exists {
+		lock = &userLock{}
+		ks.userLocks[username] = lock
+	}
+	lock.refs++
+	ks.stateLock.Unlock()
+
+	if writes {
+		lock.Lock()
+	} else {
+		lock.RLock()
+	}
+	return func() {
+		if writes {
+			lock.Unlock()
+		} else {
+			lock.RUnlock()
+		}
+
+		ks.stateLock.Lock()
+		defer ks.stateLock.Unlock()
+
+		lock.refs--
+		if lock.refs == 0 {
+			delete(ks.userLocks, username)
+		}
+	}
+}
+
+// cachedUser returns [username] if they're in memory
+func (ks *Keystore) cachedUser(username string) (*User, bool) {
+	ks.stateLock.Lock()
+	defer ks.stateLock.Unlock()
+
+	usr, exists := ks.users[username]
+	return usr, exists
+}
+
+// setUser keeps [usr] in memory as [username], or forgets [username] if [usr]
+// is nil
+func (ks *Keystore) setUser(username string, usr *User) {
+	ks.stateLock.Lock()
+	defer ks.stateLock.Unlock()
+
+	if usr == nil {
+		delete(ks.users, username)
+	} else {
+		ks.users[username] = usr
+	}
+}
+
+// importing returns the user being imported as [username], if any
+func (ks *Keystore) importing(username string) (*User, bool) {
+	ks.stateLock.Lock()
+	defer ks.stateLock.Unlock()
+
+	usr, importing := ks.imports[username]
+	return usr, importing
+}
+
+// setImport records that [usr] is being imported as [username], or that
+// nothing is if [usr] is nil
+func (ks *Keystore) setImport(username string, usr *User) {
+	ks.stateLock.Lock()
+	defer ks.stateLock.Unlock()
+
+	if usr == nil {
+		delete(ks.imports, username)
+	} else {
+		ks.imports[username] = usr
+	}
+}
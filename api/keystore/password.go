@@ -0,0 +1,144 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package keystore
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// MaxPasswordScore is the score of the passwords that are hardest to guess
+const MaxPasswordScore = 4
+
+var (
+	// DefaultPasswordPolicy is the policy new passwords must satisfy, unless
+	// the keystore is configured otherwise
+	DefaultPasswordPolicy = StrengthPolicy{
+		MinLength: 8,
+		MinScore:  2,
+	}
+
+	// commonPasswords are guessed before anything else, so passwords made of
+	// them are only as strong as the number of them
+	commonPasswords = []string{
+		"password", "passw0rd", "123456", "qwerty", "letmein", "welcome",
+		"admin", "monkey", "dragon", "iloveyou", "abc123", "football",
+		"baseball", "master", "sunshine", "shadow", "princess", "trustno1",
+		"login", "starwars", "hello", "freedom", "whatever", "qazwsx",
+		"avalanche", "secret",
+	}
+)
+
+// PasswordPolicy decides whether a password is strong enough to protect a user
+type PasswordPolicy interface {
+	// Verify returns an error if [password] isn't allowed
+	Verify(password string) error
+}
+
+// StrengthPolicy allows passwords that are at least MinLength characters long
+// and have a PasswordScore of at least MinScore
+type StrengthPolicy struct {
+	MinLength int
+	MinScore  int
+}
+
+// Valid returns nil if the policy can be satisfied
+func (p StrengthPolicy) Valid() error {
+	switch {
+	case p.MinLength < 0:
+		return fmt.Errorf("min length = %d: Fails the condition that: 0 <= min length", p.MinLength)
+	case p.MinScore < 0 || p.MinScore > MaxPasswordScore:
+		return fmt.Errorf("min score = %d: Fails the condition that: 0 <= min score <= %d", p.MinScore, MaxPasswordScore)
+	}
+	return nil
+}
+
+// Verify implements the PasswordPolicy interface
+func (p StrengthPolicy) Verify(password string) error {
+	if length := len([]rune(password)); length < p.MinLength {
+		return fmt.Errorf("password is %d characters long but must be at least %d", length, p.MinLength)
+	}
+	if score := PasswordScore(password); score < p.MinScore {
+		return fmt.Errorf("password is too easy to guess: it scored %d but must score at least %d out of %d", score, p.MinScore, MaxPasswordScore)
+	}
+	return nil
+}
+
+// PasswordScore estimates how hard [password] is to guess, in the manner of
+// zxcvbn. It scores 0 if the password could be guessed in under 10^3 guesses,
+// 1 under 10^6, 2 under 10^8, 3 under 10^10 and 4 otherwise.
+//
+// Common passwords in the password multiply the guesses by the number of common
+// passwords. A run of characters that each repeat or continue a sequence of the
+// previous character multiplies them by the length of the run. Other characters
+// multiply them by the number of characters of their kind.
+func PasswordScore(password string) int {
+	runes := []rune(password)
+	lower := []rune(strings.ToLower(password))
+
+	// log10 of the number of guesses
+	guesses := 0.
+	// The number of characters in the current run that followed its first
+	run := 0
+	for i := 0; i < len(runes); {
+		if length := commonPasswordAt(lower[i:]); length > 0 {
+			guesses += math.Log10(float64(len(commonPasswords)))
+			run = 0
+			i += length
+			continue
+		}
+		if i > 0 {
+			if diff := lower[i] - lower[i-1]; diff >= -1 && diff <= 1 {
+				// Takes the guesses from a multiple of [run] to a multiple of
+				// [run]+1
+				run++
+				guesses += math.Log10(float64(run+1) / float64(run))
+				i++
+				continue
+			}
+		}
+		guesses += math.Log10(cardinality(runes[i]))
+		run = 0
+		i++
+	}
+
+	switch {
+	case guesses < 3:
+		return 0
+	case guesses < 6:
+		return 1
+	case guesses < 8:
+		return 2
+	case guesses < 10:
+		return 3
+	default:
+		return MaxPasswordScore
+	}
+}
+
+// commonPasswordAt returns the length of the longest common password that
+// [password] starts with, or 0 if it doesn't start with one
+func commonPasswordAt(password []rune) int {
+	longest := 0
+	for _, common := range commonPasswords {
+		if length := len(common); length > longest && strings.HasPrefix(string(password), common) {
+			longest = length
+		}
+	}
+	return longest
+}
+
+// cardinality returns the number of characters of the kind of [r]
+func cardinality(r rune) float64 {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case unicode.IsLower(r), unicode.IsUpper(r):
+		return 26
+	default:
+		return 33
+	}
+}
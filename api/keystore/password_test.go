@@ -0,0 +1,50 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package keystore
+
+import (
+	"testing"
+)
+
+func TestPasswordScore(t *testing.T) {
+	scores := map[string]int{
+		"":                     0,
+		"123":                  0,
+		"password1":            0,
+		"Avalanche2020":        1,
+		"aaaaaaaaaaaa":         0,
+		"12345678":             0,
+		"secret!!2020":         2,
+		"launchpad#2020":       MaxPasswordScore,
+		"correct horse staple": MaxPasswordScore,
+	}
+	for password, expected := range scores {
+		if score := PasswordScore(password); score != expected {
+			t.Fatalf("%q should have scored %d but scored %d", password, expected, score)
+		}
+	}
+}
+
+func TestStrengthPolicy(t *testing.T) {
+	policy := StrengthPolicy{MinLength: 10, MinScore: 3}
+	if err := policy.Valid(); err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Verify("launchpad#2020"); err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Verify("x#9Lq!"); err == nil {
+		t.Fatalf("Shouldn't have allowed a password that is too short")
+	}
+	if err := policy.Verify("1234567890"); err == nil {
+		t.Fatalf("Shouldn't have allowed a password that is too easy to guess")
+	}
+
+	if err := (StrengthPolicy{MinScore: MaxPasswordScore + 1}).Valid(); err == nil {
+		t.Fatalf("Shouldn't have allowed a score that can't be reached")
+	}
+	if err := (StrengthPolicy{MinLength: -1}).Valid(); err == nil {
+		t.Fatalf("Shouldn't have allowed a negative length")
+	}
+}
@@ -7,22 +7,39 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"sync"
+	"time"
 
-	"github.com/gorilla/rpc/v2"
-
+	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/database"
-	"github.com/ava-labs/gecko/database/encdb"
 	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/readonlydb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/vms/components/codec"
 
 	jsoncodec "github.com/ava-labs/gecko/utils/json"
 )
 
+// codecVersion is the version of the codec that users are marshalled with.
+// Version 1 added the parameters that the user's password was hashed with, and
+// version 2 added the key that the user's data is encrypted with.
+const codecVersion = 2
+
+const (
+	// maxChunkPairs is the most key/value pairs in an exported chunk
+	maxChunkPairs = 1024
+
+	// maxChunkSize is roughly the most bytes of key/value pairs in an
+	// exported chunk. CB58 encoding takes time quadratic in the number of
+	// bytes, so chunks are kept small.
+	maxChunkSize = 16 * 1024
+)
+
 var (
 	errEmptyUsername = errors.New("username can't be the empty string")
 )
@@ -39,18 +56,69 @@ type UserDB struct {
 	Data []KeyValuePair `serialize:"true"`
 }
 
-// Keystore is the RPC interface for keystore management
+// UserChunk describes part of the content of a user. Large users are exported
+// and imported in chunks, so the whole user isn't held in memory at once.
+type UserChunk struct {
+	UserDB `serialize:"true"`
+
+	// True if this is the user's last chunk
+	Last bool `serialize:"true"`
+}
+
+// Keystore is the RPC interface for keystore management. Every call holds
+// [lock] for reading, except for the calls that change the keystore's
+// settings, or how users are stored, which hold it for writing. Calls on the
+// same user are serialized by that user's lock instead, so that calls on other
+// users, which hash passwords and iterate over data, don't wait for them.
 type Keystore struct {
-	lock sync.Mutex
+	lock sync.RWMutex
 	log  logging.Logger
 
-	codec codec.Codec
+	// Guards the maps of users, imports, sessions and user locks, and the
+	// token secret. It's only held while they're read or changed.
+	stateLock sync.Mutex
+
+	// Key: username
+	// Value: The lock of the calls on that user
+	userLocks map[string]*userLock
+
+	// Marshals users with the current codec version. Users marshalled before
+	// the codec was versioned are unmarshalled with its legacy codec.
+	codec *codec.Manager
+
+	// The policy that new passwords must satisfy, and the parameters that they
+	// are hashed with
+	policy     PasswordPolicy
+	hashParams HashParams
+
+	// Signs login tokens. It's generated when the first token is issued, so
+	// tokens don't outlive the node.
+	tokenSecret []byte
+
+	// How long login tokens are valid for
+	tokenDuration time.Duration
+
+	// Key: The name of a user whose chunks are being imported
+	// Value: The user, which is stored once their last chunk is imported
+	imports map[string]*User
+
+	// Key: The ID of a login token
+	// Value: The user it was issued to, and when it expires
+	sessions map[[tokenIDLen]byte]*session
+
+	// If set, users are stored under the MAC of their name under this key,
+	// rather than their name. Set by EnableEncryption.
+	indexKey []byte
+
+	clock timer.Clock
 
 	// Key: username
 	// Value: The user with that name
 	users map[string]*User
 
-	// Used to persist users and their data
+	// Used to persist users and their data. userDB and bcDB are views of db,
+	// so writes to both can be committed together.
+	db     database.Database
 	userDB database.Database
 	bcDB   database.Database
 	//           BaseDB
@@ -65,15 +133,59 @@ type Keystore struct {
 // Initialize the keystore
 func (ks *Keystore) Initialize(log logging.Logger, db database.Database) {
 	ks.log = log
-	ks.codec = codec.NewDefault()
+	ks.codec = codec.NewManager()
+	for version := uint16(1); version <= codecVersion; version++ {
+		ks.codec.RegisterCodec(version, codec.NewDefaultVersioned(version))
+	}
+	ks.codec.RegisterLegacyCodec(codec.NewDefault())
+	ks.policy = DefaultPasswordPolicy
+	ks.hashParams = DefaultHashParams
+	ks.tokenDuration = DefaultTokenDuration
+	ks.sessions = make(map[[tokenIDLen]byte]*session)
+	ks.imports = make(map[string]*User)
 	ks.users = make(map[string]*User)
+	ks.userLocks = make(map[string]*userLock)
+	ks.db = db
 	ks.userDB = prefixdb.New([]byte("users"), db)
 	ks.bcDB = prefixdb.New([]byte("bcs"), db)
 }
 
+// SetPasswordPolicy sets the policy that the passwords of new users, and the new
+// passwords of existing users, must satisfy
+func (ks *Keystore) SetPasswordPolicy(policy PasswordPolicy) {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.policy = policy
+}
+
+// SetHashParams sets the parameters that new passwords are hashed with. Users
+// keep the parameters that their password was hashed with, so they can still
+// log in after the parameters change.
+func (ks *Keystore) SetHashParams(params HashParams) error {
+	if err := params.Valid(); err != nil {
+		return err
+	}
+
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.hashParams = params
+	return nil
+}
+
+// SetTokenDuration sets how long login tokens are valid for. Tokens that were
+// already issued keep their expiry.
+func (ks *Keystore) SetTokenDuration(duration time.Duration) {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.tokenDuration = duration
+}
+
 // CreateHandler returns a new service object that can send requests to thisAPI.
 func (ks *Keystore) CreateHandler() *common.HTTPHandler {
-	newServer := rpc.NewServer()
+	newServer := jsoncodec.NewServer()
 	codec := jsoncodec.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
 	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
@@ -84,18 +196,24 @@ func (ks *Keystore) CreateHandler() *common.HTTPHandler {
 // Get the user whose name is [username]
 func (ks *Keystore) getUser(username string) (*User, error) {
 	// If the user is already in memory, return it
-	usr, exists := ks.users[username]
+	usr, exists := ks.cachedUser(username)
 	if exists {
 		return usr, nil
 	}
 	// The user is not in memory; try the database
-	usrBytes, err := ks.userDB.Get([]byte(username))
+	usrBytes, err := ks.userDB.Get(ks.userKey(username))
 	if err != nil { // Most likely bc user doesn't exist in database
 		return nil, err
 	}
 
-	usr = &User{}
-	return usr, ks.codec.Unmarshal(usrBytes, usr)
+	storedName, usr, err := ks.unmarshalUser(username, usrBytes)
+	switch {
+	case err != nil:
+		return &User{}, err
+	case storedName != username:
+		return &User{}, errMismatchedUsername
+	}
+	return usr, nil
 }
 
 // CreateUserArgs are arguments for passing into CreateUser requests
@@ -110,11 +228,13 @@ type CreateUserReply struct {
 }
 
 // CreateUser creates an empty user with the provided username and password
-func (ks *Keystore) CreateUser(_ *http.Request, args *CreateUserArgs, reply *CreateUserReply) error {
-	ks.lock.Lock()
-	defer ks.lock.Unlock()
+func (ks *Keystore) CreateUser(r *http.Request, args *CreateUserArgs, reply *CreateUserReply) error {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	unlock := ks.lockUser(args.Username, true)
+	defer unlock()
 
-	ks.log.Verbo("CreateUser called with %s", args.Username)
+	ks.log.Verbo("CreateUser called with %s in request %s", args.Username, api.RequestID(r))
 
 	if args.Username == "" {
 		return errEmptyUsername
@@ -122,21 +242,30 @@ func (ks *Keystore) CreateUser(_ *http.Request, args *CreateUserArgs, reply *Cre
 	if usr, err := ks.getUser(args.Username); err == nil || usr != nil {
 		return fmt.Errorf("user already exists: %s", args.Username)
 	}
+	if _, importing := ks.importing(args.Username); importing {
+		return fmt.Errorf("user is being imported: %s", args.Username)
+	}
+	if err := ks.policy.Verify(args.Password); err != nil {
+		return err
+	}
 
 	usr := &User{}
-	if err := usr.Initialize(args.Password); err != nil {
+	if err := usr.Initialize(args.Password, ks.hashParams); err != nil {
+		return err
+	}
+	if err := usr.InitializeDataKey(args.Password, ks.hashParams); err != nil {
 		return err
 	}
 
-	usrBytes, err := ks.codec.Marshal(usr)
+	usrBytes, err := ks.marshalUser(args.Username, usr)
 	if err != nil {
 		return err
 	}
 
-	if err := ks.userDB.Put([]byte(args.Username), usrBytes); err != nil {
+	if err := ks.userDB.Put(ks.userKey(args.Username), usrBytes); err != nil {
 		return err
 	}
-	ks.users[args.Username] = usr
+	ks.setUser(args.Username, usr)
 	reply.Success = true
 	return nil
 }
@@ -150,18 +279,31 @@ type ListUsersReply struct {
 }
 
 // ListUsers lists all the registered usernames
-func (ks *Keystore) ListUsers(_ *http.Request, args *ListUsersArgs, reply *ListUsersReply) error {
-	ks.lock.Lock()
-	defer ks.lock.Unlock()
+func (ks *Keystore) ListUsers(r *http.Request, args *ListUsersArgs, reply *ListUsersReply) error {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
 
-	ks.log.Verbo("ListUsers called")
+	ks.log.Verbo("ListUsers called in request %s", api.RequestID(r))
 
 	reply.Users = []string{}
 
+	// userDB is a prefixed view, so this only reads the users' prefix of the
+	// keystore's database
 	it := ks.userDB.NewIterator()
 	defer it.Release()
 	for it.Next() {
-		reply.Users = append(reply.Users, string(it.Key()))
+		username := string(it.Key())
+		if ks.indexKey != nil {
+			// The username is only in the encrypted record
+			var err error
+			if username, _, err = ks.unmarshalUser(username, it.Value()); err != nil {
+				return err
+			}
+		}
+		reply.Users = append(reply.Users, username)
+	}
+	if ks.indexKey != nil {
+		sort.Strings(reply.Users)
 	}
 	return it.Error()
 }
@@ -170,19 +312,26 @@ func (ks *Keystore) ListUsers(_ *http.Request, args *ListUsersArgs, reply *ListU
 type ExportUserArgs struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+
+	// Encoding of the exported user, either "cb58" or "hex". Defaults to
+	// "cb58".
+	Encoding formatting.Encoding `json:"encoding"`
 }
 
 // ExportUserReply is the reply from ExportUser
 type ExportUserReply struct {
-	User string `json:"user"`
+	User     string              `json:"user"`
+	Encoding formatting.Encoding `json:"encoding"`
 }
 
 // ExportUser exports a serialized encoding of a user's information complete with encrypted database values
-func (ks *Keystore) ExportUser(_ *http.Request, args *ExportUserArgs, reply *ExportUserReply) error {
-	ks.lock.Lock()
-	defer ks.lock.Unlock()
+func (ks *Keystore) ExportUser(r *http.Request, args *ExportUserArgs, reply *ExportUserReply) error {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	unlock := ks.lockUser(args.Username, false)
+	defer unlock()
 
-	ks.log.Verbo("ExportUser called for %s", args.Username)
+	ks.log.Verbo("ExportUser called for %s in request %s", args.Username, api.RequestID(r))
 
 	usr, err := ks.getUser(args.Username)
 	if err != nil {
@@ -192,12 +341,13 @@ func (ks *Keystore) ExportUser(_ *http.Request, args *ExportUserArgs, reply *Exp
 		return fmt.Errorf("incorrect password for %s", args.Username)
 	}
 
-	userDB := prefixdb.New([]byte(args.Username), ks.bcDB)
+	userDB := ks.dataDB(args.Username)
 
 	userData := UserDB{
 		User: *usr,
 	}
 
+	// Only the user's prefix of the keystore's database is read
 	it := userDB.NewIterator()
 	defer it.Release()
 	for it.Next() {
@@ -214,8 +364,10 @@ func (ks *Keystore) ExportUser(_ *http.Request, args *ExportUserArgs, reply *Exp
 	if err != nil {
 		return err
 	}
-	cb58 := formatting.CB58{Bytes: b}
-	reply.User = cb58.String()
+	if reply.User, err = args.Encoding.Encode(b); err != nil {
+		return err
+	}
+	reply.Encoding, _ = formatting.ParseEncoding(string(args.Encoding))
 	return nil
 }
 
@@ -224,6 +376,9 @@ type ImportUserArgs struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	User     string `json:"user"`
+
+	// Encoding of User, either "cb58" or "hex". Defaults to "cb58".
+	Encoding formatting.Encoding `json:"encoding"`
 }
 
 // ImportUserReply is the response for ImportUser
@@ -233,46 +388,523 @@ type ImportUserReply struct {
 
 // ImportUser imports a serialized encoding of a user's information complete with encrypted database values, integrity checks the password, and adds it to the database
 func (ks *Keystore) ImportUser(r *http.Request, args *ImportUserArgs, reply *ImportUserReply) error {
-	ks.lock.Lock()
-	defer ks.lock.Unlock()
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	unlock := ks.lockUser(args.Username, true)
+	defer unlock()
 
-	ks.log.Verbo("ImportUser called for %s", args.Username)
+	ks.log.Verbo("ImportUser called for %s in request %s", args.Username, api.RequestID(r))
 
 	if usr, err := ks.getUser(args.Username); err == nil || usr != nil {
 		return fmt.Errorf("user already exists: %s", args.Username)
 	}
+	if _, importing := ks.importing(args.Username); importing {
+		return fmt.Errorf("user is being imported: %s", args.Username)
+	}
 
-	cb58 := formatting.CB58{}
-	if err := cb58.FromString(args.User); err != nil {
+	userBytes, err := args.Encoding.Decode(args.User)
+	if err != nil {
 		return err
 	}
 
 	userData := UserDB{}
-	if err := ks.codec.Unmarshal(cb58.Bytes, &userData); err != nil {
+	version, err := ks.codec.Unmarshal(userBytes, &userData)
+	switch {
+	case err != nil:
 		return err
+	case version == codec.Unversioned:
+		// The user was exported before the codec was versioned
+		userData.Params = legacyHashParams
+	default:
+		if err := userData.Params.Valid(); err != nil {
+			return err
+		}
 	}
 
-	usrBytes, err := ks.codec.Marshal(&userData.User)
+	usrBytes, err := ks.marshalUser(args.Username, &userData.User)
 	if err != nil {
 		return err
 	}
 
-	// TODO: Should add batching to prevent creating a user without importing
-	// the account
-	if err := ks.userDB.Put([]byte(args.Username), usrBytes); err != nil {
+	// The user and their data are written together, so an import that fails
+	// part way through doesn't leave a user without their data
+	batch := database.NewSharedBatch(ks.db)
+	userBatch, err := batch.Add(ks.userDB)
+	if err != nil {
+		return err
+	}
+	if err := userBatch.Put(ks.userKey(args.Username), usrBytes); err != nil {
 		return err
 	}
-	ks.users[args.Username] = &userData.User
-
-	userDB := prefixdb.New([]byte(args.Username), ks.bcDB)
-	batch := userDB.NewBatch()
 
+	dataBatch, err := batch.Add(ks.dataDB(args.Username))
+	if err != nil {
+		return err
+	}
 	for _, kvp := range userData.Data {
-		batch.Put(kvp.Key, kvp.Value)
+		if err := dataBatch.Put(kvp.Key, kvp.Value); err != nil {
+			return err
+		}
+	}
+
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	ks.setUser(args.Username, &userData.User)
+	reply.Success = true
+	return nil
+}
+
+// ExportUserChunkArgs are the arguments to ExportUserChunk
+type ExportUserChunkArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// Limit is the most key/value pairs to export. If it's 0 or more than
+	// 1024, at most 1024 pairs are exported.
+	Limit jsoncodec.Uint32 `json:"limit"`
+
+	// StartKey is the endKey of the previous chunk. If it's empty, the first
+	// chunk is exported.
+	StartKey string `json:"startKey"`
+
+	// Encoding of the exported chunk, either "cb58" or "hex". Defaults to
+	// "cb58". The keys are always in CB58.
+	Encoding formatting.Encoding `json:"encoding"`
+}
+
+// ExportUserChunkReply is the reply from ExportUserChunk
+type ExportUserChunkReply struct {
+	Chunk    string              `json:"chunk"`
+	Encoding formatting.Encoding `json:"encoding"`
+
+	// EndKey is where the next chunk starts. It's empty if this chunk is the
+	// last one.
+	EndKey string `json:"endKey"`
+}
+
+// ExportUserChunk exports a serialized encoding of a chunk of a user's
+// information, complete with encrypted database values. To get the next chunk,
+// call ExportUserChunk again with [args.StartKey] set to the [reply.EndKey] of
+// this chunk. Each chunk can be given to ImportUserChunk, in order.
+func (ks *Keystore) ExportUserChunk(r *http.Request, args *ExportUserChunkArgs, reply *ExportUserChunkReply) error {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	unlock := ks.lockUser(args.Username, false)
+	defer unlock()
+
+	ks.log.Verbo("ExportUserChunk called for %s in request %s", args.Username, api.RequestID(r))
+
+	usr, err := ks.getUser(args.Username)
+	if err != nil {
+		return err
+	}
+	if !usr.CheckPassword(args.Password) {
+		return fmt.Errorf("incorrect password for %s", args.Username)
+	}
+
+	startKey := formatting.CB58{}
+	if args.StartKey != "" {
+		if err := startKey.FromString(args.StartKey); err != nil {
+			return err
+		}
+	}
+	limit := int(args.Limit)
+	if limit <= 0 || limit > maxChunkPairs {
+		limit = maxChunkPairs
+	}
+
+	chunk := UserChunk{
+		UserDB: UserDB{User: *usr},
+		Last:   true,
+	}
+	size := 0
+
+	userDB := ks.dataDB(args.Username)
+	it := userDB.NewIteratorWithStart(startKey.Bytes)
+	defer it.Release()
+	for it.Next() {
+		if len(chunk.Data) == limit || size >= maxChunkSize {
+			chunk.Last = false
+			reply.EndKey = formatting.CB58{Bytes: it.Key()}.String()
+			break
+		}
+		chunk.Data = append(chunk.Data, KeyValuePair{
+			Key:   it.Key(),
+			Value: it.Value(),
+		})
+		size += len(it.Key()) + len(it.Value())
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	b, err := ks.codec.Marshal(&chunk)
+	if err != nil {
+		return err
+	}
+	if reply.Chunk, err = args.Encoding.Encode(b); err != nil {
+		return err
+	}
+	reply.Encoding, _ = formatting.ParseEncoding(string(args.Encoding))
+	return nil
+}
+
+// ImportUserChunkArgs are the arguments to ImportUserChunk
+type ImportUserChunkArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Chunk    string `json:"chunk"`
+
+	// Encoding of Chunk, either "cb58" or "hex". Defaults to "cb58".
+	Encoding formatting.Encoding `json:"encoding"`
+}
+
+// ImportUserChunkReply is the reply from ImportUserChunk
+type ImportUserChunkReply struct {
+	Success bool `json:"success"`
+
+	// True if the chunk was the user's last, so the user has been imported
+	Done bool `json:"done"`
+}
+
+// ImportUserChunk imports a chunk exported by ExportUserChunk. The user's
+// chunks must be imported in the order they were exported. The user is added
+// once their last chunk is imported.
+func (ks *Keystore) ImportUserChunk(r *http.Request, args *ImportUserChunkArgs, reply *ImportUserChunkReply) error {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	unlock := ks.lockUser(args.Username, true)
+	defer unlock()
+
+	ks.log.Verbo("ImportUserChunk called for %s in request %s", args.Username, api.RequestID(r))
+
+	if args.Username == "" {
+		return errEmptyUsername
+	}
+	if usr, err := ks.getUser(args.Username); err == nil || usr != nil {
+		return fmt.Errorf("user already exists: %s", args.Username)
+	}
+
+	chunkBytes, err := args.Encoding.Decode(args.Chunk)
+	if err != nil {
+		return err
+	}
+	chunk := UserChunk{}
+	if _, err := ks.codec.Unmarshal(chunkBytes, &chunk); err != nil {
+		return err
+	}
+	if err := chunk.Params.Valid(); err != nil {
+		return err
+	}
+
+	usr, importing := ks.importing(args.Username)
+	if importing && !usr.equals(&chunk.User) {
+		return fmt.Errorf("chunk is of a different user than the one being imported as %s", args.Username)
+	}
+	if !chunk.CheckPassword(args.Password) {
+		return fmt.Errorf("incorrect password for %s", args.Username)
+	}
+
+	batch := database.NewSharedBatch(ks.db)
+	if !importing {
+		// Data left behind by an import that was never finished is replaced
+		if err := ks.deleteData(batch, args.Username); err != nil {
+			return err
+		}
+	}
+	dataBatch, err := batch.Add(ks.dataDB(args.Username))
+	if err != nil {
+		return err
+	}
+	for _, kvp := range chunk.Data {
+		if err := dataBatch.Put(kvp.Key, kvp.Value); err != nil {
+			return err
+		}
+	}
+
+	// The user is written with their last chunk, so they don't exist until
+	// all their data does
+	if chunk.Last {
+		usrBytes, err := ks.marshalUser(args.Username, &chunk.User)
+		if err != nil {
+			return err
+		}
+		userBatch, err := batch.Add(ks.userDB)
+		if err != nil {
+			return err
+		}
+		if err := userBatch.Put(ks.userKey(args.Username), usrBytes); err != nil {
+			return err
+		}
+	}
+
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if chunk.Last {
+		ks.setImport(args.Username, nil)
+		ks.setUser(args.Username, &chunk.User)
+	} else {
+		ks.setImport(args.Username, &chunk.User)
+	}
+	reply.Success = true
+	reply.Done = chunk.Last
+	return nil
+}
+
+// deleteData adds the deletion of all of [username]'s blockchain data to
+// [batch]
+func (ks *Keystore) deleteData(batch *database.SharedBatch, username string) error {
+	userDB := ks.dataDB(username)
+	dataBatch, err := batch.Add(userDB)
+	if err != nil {
+		return err
+	}
+	// Only the user's prefix of the keystore's database is read
+	it := userDB.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		if err := dataBatch.Delete(it.Key()); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// DeleteUserArgs are the arguments to DeleteUser
+type DeleteUserArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// DeleteUserReply is the reply from DeleteUser
+type DeleteUserReply struct {
+	Success bool `json:"success"`
+}
+
+// DeleteUser deletes a user and all of their blockchain data
+func (ks *Keystore) DeleteUser(r *http.Request, args *DeleteUserArgs, reply *DeleteUserReply) error {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	unlock := ks.lockUser(args.Username, true)
+	defer unlock()
+
+	ks.log.Verbo("DeleteUser called for %s in request %s", args.Username, api.RequestID(r))
+
+	usr, err := ks.getUser(args.Username)
+	if err != nil {
+		return fmt.Errorf("user doesn't exist: %s", args.Username)
+	}
+	if !usr.CheckPassword(args.Password) {
+		return fmt.Errorf("incorrect password for %s", args.Username)
+	}
+
+	// The user and their data are deleted together, so a deletion that fails
+	// part way through doesn't leave data without a user
+	batch := database.NewSharedBatch(ks.db)
+	userBatch, err := batch.Add(ks.userDB)
+	if err != nil {
+		return err
+	}
+	if err := userBatch.Delete(ks.userKey(args.Username)); err != nil {
+		return err
+	}
+
+	if err := ks.deleteData(batch, args.Username); err != nil {
+		return err
+	}
+
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	ks.setUser(args.Username, nil)
+	ks.revokeTokens(args.Username)
+	reply.Success = true
+	return nil
+}
+
+// ChangePasswordArgs are the arguments to ChangePassword
+type ChangePasswordArgs struct {
+	Username    string `json:"username"`
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+}
+
+// ChangePasswordReply is the reply from ChangePassword
+type ChangePasswordReply struct {
+	Success bool `json:"success"`
+}
+
+// ChangePassword changes the password of a user. The user's data key is
+// wrapped with the new password, so their blockchain data doesn't have to be
+// re-encrypted. The data of a user without a data key is encrypted with a key
+// derived from their old password, so it's re-encrypted with a new random data
+// key.
+func (ks *Keystore) ChangePassword(r *http.Request, args *ChangePasswordArgs, reply *ChangePasswordReply) error {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	unlock := ks.lockUser(args.Username, true)
+	defer unlock()
+
+	ks.log.Verbo("ChangePassword called for %s in request %s", args.Username, api.RequestID(r))
+
+	usr, err := ks.getUser(args.Username)
+	if err != nil {
+		return fmt.Errorf("user doesn't exist: %s", args.Username)
+	}
+	if !usr.CheckPassword(args.OldPassword) {
+		return fmt.Errorf("incorrect password for %s", args.Username)
+	}
+	if err := ks.policy.Verify(args.NewPassword); err != nil {
+		return err
+	}
+
+	newUsr := &User{}
+	if err := newUsr.Initialize(args.NewPassword, ks.hashParams); err != nil {
+		return err
+	}
+	userDB := ks.dataDB(args.Username)
+	oldDB, err := usr.dataDB(args.OldPassword, userDB)
+	if err != nil {
+		return err
 	}
 
+	// The new password and any re-encrypted data are written together, so a
+	// change that fails part way through leaves the user with the old password
+	// and data they can still decrypt
+	batch := database.NewSharedBatch(ks.db)
+	if len(usr.DataKey) == 0 {
+		if err := newUsr.InitializeDataKey(args.NewPassword, ks.hashParams); err != nil {
+			return err
+		}
+		newDB, err := newUsr.dataDB(args.NewPassword, userDB)
+		if err != nil {
+			return err
+		}
+		dataBatch, err := batch.Add(newDB)
+		if err != nil {
+			return err
+		}
+		// Keys aren't encrypted, so every blockchain's data can be
+		// re-encrypted through one encrypted database of the user's data
+		it := oldDB.NewIterator()
+		defer it.Release()
+		for it.Next() {
+			if err := dataBatch.Put(it.Key(), it.Value()); err != nil {
+				return err
+			}
+		}
+		if err := it.Error(); err != nil {
+			return err
+		}
+	} else {
+		header, err := oldDB.Rotate([]byte(args.NewPassword))
+		if err != nil {
+			return err
+		}
+		if newUsr.DataKey, err = header.Bytes(); err != nil {
+			return err
+		}
+	}
+
+	usrBytes, err := ks.marshalUser(args.Username, newUsr)
+	if err != nil {
+		return err
+	}
+	userBatch, err := batch.Add(ks.userDB)
+	if err != nil {
+		return err
+	}
+	if err := userBatch.Put(ks.userKey(args.Username), usrBytes); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	ks.setUser(args.Username, newUsr)
+	// Tokens stand for the old password, which no longer decrypts the data
+	ks.revokeTokens(args.Username)
 	reply.Success = true
-	return batch.Write()
+	return nil
+}
+
+// LoginArgs are the arguments to Login
+type LoginArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LoginReply is the reply from Login
+type LoginReply struct {
+	Token  string    `json:"token"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// Login returns a token that can be given instead of the user's password to
+// APIs that use the user's blockchain data, until it expires or is revoked
+func (ks *Keystore) Login(r *http.Request, args *LoginArgs, reply *LoginReply) error {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	unlock := ks.lockUser(args.Username, false)
+	defer unlock()
+
+	ks.log.Verbo("Login called for %s in request %s", args.Username, api.RequestID(r))
+
+	usr, err := ks.getUser(args.Username)
+	if err != nil {
+		return fmt.Errorf("user doesn't exist: %s", args.Username)
+	}
+	if !usr.CheckPassword(args.Password) {
+		return fmt.Errorf("incorrect password for %s", args.Username)
+	}
+
+	reply.Token, reply.Expiry, err = ks.newToken(args.Username, args.Password)
+	return err
+}
+
+// LogoutArgs are the arguments to Logout
+type LogoutArgs struct {
+	Token string `json:"token"`
+}
+
+// LogoutReply is the reply from Logout
+type LogoutReply struct {
+	Success bool `json:"success"`
+}
+
+// Logout revokes a token issued by Login
+func (ks *Keystore) Logout(r *http.Request, args *LogoutArgs, reply *LogoutReply) error {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+
+	ks.log.Verbo("Logout called in request %s", api.RequestID(r))
+
+	if err := ks.revokeToken(args.Token); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
+// HealthCheck implements the health.Checker interface. The keystore is healthy
+// if its users can be read from its database. The details are the number of
+// login tokens issued and users being imported.
+func (ks *Keystore) HealthCheck() (interface{}, error) {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+
+	ks.stateLock.Lock()
+	details := map[string]int{
+		"sessions":       len(ks.sessions),
+		"pendingImports": len(ks.imports),
+	}
+	ks.stateLock.Unlock()
+
+	it := ks.userDB.NewIterator()
+	defer it.Release()
+	it.Next()
+	return details, it.Error()
 }
 
 // NewBlockchainKeyStore ...
@@ -283,10 +915,19 @@ func (ks *Keystore) NewBlockchainKeyStore(blockchainID ids.ID) *BlockchainKeysto
 	}
 }
 
-// GetDatabase ...
+// GetDatabase returns the database of [username]'s data on the blockchain
+// [bID]. [password] is either the user's password or a token issued to them by
+// Login.
 func (ks *Keystore) GetDatabase(bID ids.ID, username, password string) (database.Database, error) {
-	ks.lock.Lock()
-	defer ks.lock.Unlock()
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	unlock := ks.lockUser(username, false)
+	defer unlock()
+
+	password, err := ks.password(username, password)
+	if err != nil {
+		return nil, err
+	}
 
 	usr, err := ks.getUser(username)
 	if err != nil {
@@ -296,13 +937,22 @@ func (ks *Keystore) GetDatabase(bID ids.ID, username, password string) (database
 		return nil, fmt.Errorf("incorrect password for user '%s'", username)
 	}
 
-	userDB := prefixdb.New([]byte(username), ks.bcDB)
+	userDB := ks.dataDB(username)
 	bcDB := prefixdb.NewNested(bID.Bytes(), userDB)
-	encDB, err := encdb.New([]byte(password), bcDB)
-
+	encDB, err := usr.dataDB(password, bcDB)
 	if err != nil {
 		return nil, err
 	}
-
 	return encDB, nil
 }
+
+// GetReadOnlyDatabase returns the database of [username]'s data on the
+// blockchain [bID], which fails to be written to. [password] is either the
+// user's password or a token issued to them by Login.
+func (ks *Keystore) GetReadOnlyDatabase(bID ids.ID, username, password string) (database.Database, error) {
+	db, err := ks.GetDatabase(bID, username, password)
+	if err != nil {
+		return nil, err
+	}
+	return readonlydb.New(db), nil
+}
@@ -5,11 +5,21 @@ package keystore
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/encdb"
 	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/readonlydb"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms/components/codec"
 )
 
 func TestServiceListNoUsers(t *testing.T) {
@@ -33,7 +43,7 @@ func TestServiceCreateUser(t *testing.T) {
 		reply := CreateUserReply{}
 		if err := ks.CreateUser(nil, &CreateUserArgs{
 			Username: "bob",
-			Password: "launch",
+			Password: "launchpad#2020",
 		}, &reply); err != nil {
 			t.Fatal(err)
 		}
@@ -64,7 +74,7 @@ func TestServiceCreateDuplicate(t *testing.T) {
 		reply := CreateUserReply{}
 		if err := ks.CreateUser(nil, &CreateUserArgs{
 			Username: "bob",
-			Password: "launch",
+			Password: "launchpad#2020",
 		}, &reply); err != nil {
 			t.Fatal(err)
 		}
@@ -77,7 +87,7 @@ func TestServiceCreateDuplicate(t *testing.T) {
 		reply := CreateUserReply{}
 		if err := ks.CreateUser(nil, &CreateUserArgs{
 			Username: "bob",
-			Password: "launch!",
+			Password: "launchpad#2020!",
 		}, &reply); err == nil {
 			t.Fatalf("Should have errored due to the username already existing")
 		}
@@ -90,7 +100,7 @@ func TestServiceCreateUserNoName(t *testing.T) {
 
 	reply := CreateUserReply{}
 	if err := ks.CreateUser(nil, &CreateUserArgs{
-		Password: "launch",
+		Password: "launchpad#2020",
 	}, &reply); err == nil {
 		t.Fatalf("Shouldn't have allowed empty username")
 	}
@@ -104,7 +114,7 @@ func TestServiceUseBlockchainDB(t *testing.T) {
 		reply := CreateUserReply{}
 		if err := ks.CreateUser(nil, &CreateUserArgs{
 			Username: "bob",
-			Password: "launch",
+			Password: "launchpad#2020",
 		}, &reply); err != nil {
 			t.Fatal(err)
 		}
@@ -114,7 +124,7 @@ func TestServiceUseBlockchainDB(t *testing.T) {
 	}
 
 	{
-		db, err := ks.GetDatabase(ids.Empty, "bob", "launch")
+		db, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -124,7 +134,7 @@ func TestServiceUseBlockchainDB(t *testing.T) {
 	}
 
 	{
-		db, err := ks.GetDatabase(ids.Empty, "bob", "launch")
+		db, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -144,7 +154,7 @@ func TestServiceExportImport(t *testing.T) {
 		reply := CreateUserReply{}
 		if err := ks.CreateUser(nil, &CreateUserArgs{
 			Username: "bob",
-			Password: "launch",
+			Password: "launchpad#2020",
 		}, &reply); err != nil {
 			t.Fatal(err)
 		}
@@ -154,7 +164,7 @@ func TestServiceExportImport(t *testing.T) {
 	}
 
 	{
-		db, err := ks.GetDatabase(ids.Empty, "bob", "launch")
+		db, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -166,7 +176,7 @@ func TestServiceExportImport(t *testing.T) {
 	exportReply := ExportUserReply{}
 	if err := ks.ExportUser(nil, &ExportUserArgs{
 		Username: "bob",
-		Password: "launch",
+		Password: "launchpad#2020",
 	}, &exportReply); err != nil {
 		t.Fatal(err)
 	}
@@ -178,7 +188,7 @@ func TestServiceExportImport(t *testing.T) {
 		reply := ImportUserReply{}
 		if err := newKS.ImportUser(nil, &ImportUserArgs{
 			Username: "bob",
-			Password: "launch",
+			Password: "launchpad#2020",
 			User:     exportReply.User,
 		}, &reply); err != nil {
 			t.Fatal(err)
@@ -189,7 +199,7 @@ func TestServiceExportImport(t *testing.T) {
 	}
 
 	{
-		db, err := newKS.GetDatabase(ids.Empty, "bob", "launch")
+		db, err := newKS.GetDatabase(ids.Empty, "bob", "launchpad#2020")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -200,3 +210,976 @@ func TestServiceExportImport(t *testing.T) {
 		}
 	}
 }
+
+func TestServiceDeleteUser(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	db, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ks.DeleteUser(nil, &DeleteUserArgs{
+		Username: "bob",
+		Password: "wrong",
+	}, &DeleteUserReply{}); err == nil {
+		t.Fatalf("Shouldn't have deleted the user with the wrong password")
+	}
+
+	reply := DeleteUserReply{}
+	if err := ks.DeleteUser(nil, &DeleteUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.Success {
+		t.Fatalf("User should have been deleted successfully")
+	}
+
+	listReply := ListUsersReply{}
+	if err := ks.ListUsers(nil, &ListUsersArgs{}, &listReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(listReply.Users) != 0 {
+		t.Fatalf("No users should remain")
+	}
+	if _, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020"); err == nil {
+		t.Fatalf("Shouldn't have gotten the database of a deleted user")
+	}
+	it := prefixdb.New([]byte("bob"), ks.bcDB).NewIterator()
+	defer it.Release()
+	if it.Next() {
+		t.Fatalf("The deleted user's data should have been deleted")
+	}
+
+	// The name can be used again, without the old user's data
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// boundedDB fails the test if it's iterated without a prefix, which would
+// read every key of the database
+type boundedDB struct {
+	*memdb.Database
+	t *testing.T
+}
+
+func (db *boundedDB) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+func (db *boundedDB) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+func (db *boundedDB) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+func (db *boundedDB) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	if len(prefix) == 0 {
+		db.t.Fatalf("Shouldn't have iterated over the whole database")
+	}
+	return db.Database.NewIteratorWithStartAndPrefix(start, prefix)
+}
+
+func TestServiceBoundedIterators(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, &boundedDB{Database: memdb.New(), t: t})
+
+	for _, username := range []string{"alice", "bob"} {
+		if err := ks.CreateUser(nil, &CreateUserArgs{
+			Username: username,
+			Password: "launchpad#2020",
+		}, &CreateUserReply{}); err != nil {
+			t.Fatal(err)
+		}
+		db, err := ks.GetDatabase(ids.Empty, username, "launchpad#2020")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Put([]byte(username), []byte(username)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	listReply := ListUsersReply{}
+	if err := ks.ListUsers(nil, &ListUsersArgs{}, &listReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(listReply.Users) != 2 {
+		t.Fatalf("Expected 2 users but found %d", len(listReply.Users))
+	}
+
+	exportReply := ExportUserReply{}
+	if err := ks.ExportUser(nil, &ExportUserArgs{
+		Username: "alice",
+		Password: "launchpad#2020",
+	}, &exportReply); err != nil {
+		t.Fatal(err)
+	}
+	userData := UserDB{}
+	if b, err := formatting.CB58Encoding.Decode(exportReply.User); err != nil {
+		t.Fatal(err)
+	} else if _, err := ks.codec.Unmarshal(b, &userData); err != nil {
+		t.Fatal(err)
+	}
+	if len(userData.Data) != 1 {
+		t.Fatalf("Should have only exported alice's data, but exported %d values", len(userData.Data))
+	}
+
+	if err := ks.DeleteUser(nil, &DeleteUserArgs{
+		Username: "alice",
+		Password: "launchpad#2020",
+	}, &DeleteUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	db, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has, err := db.Has([]byte("bob")); err != nil {
+		t.Fatal(err)
+	} else if !has {
+		t.Fatalf("Deleting alice shouldn't have deleted bob's data")
+	}
+}
+
+func TestServiceChangePassword(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	chains := []ids.ID{ids.Empty, ids.NewID([32]byte{1})}
+	for i, chainID := range chains {
+		db, err := ks.GetDatabase(chainID, "bob", "launchpad#2020")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Put([]byte("hello"), []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ks.ChangePassword(nil, &ChangePasswordArgs{
+		Username:    "bob",
+		OldPassword: "wrong",
+		NewPassword: "liftoff-t0-orbit",
+	}, &ChangePasswordReply{}); err == nil {
+		t.Fatalf("Shouldn't have changed the password with the wrong old password")
+	}
+
+	// The stored data isn't re-encrypted when the password changes
+	storedData := map[string][]byte{}
+	it := ks.dataDB("bob").NewIterator()
+	for it.Next() {
+		storedData[string(it.Key())] = it.Value()
+	}
+	it.Release()
+	if len(storedData) != len(chains) {
+		t.Fatalf("Should have stored %d values but stored %d", len(chains), len(storedData))
+	}
+
+	reply := ChangePasswordReply{}
+	if err := ks.ChangePassword(nil, &ChangePasswordArgs{
+		Username:    "bob",
+		OldPassword: "launchpad#2020",
+		NewPassword: "liftoff-t0-orbit",
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.Success {
+		t.Fatalf("Password should have been changed successfully")
+	}
+
+	if _, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020"); err == nil {
+		t.Fatalf("Old password should no longer work")
+	}
+	for i, chainID := range chains {
+		db, err := ks.GetDatabase(chainID, "bob", "liftoff-t0-orbit")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, err := db.Get([]byte("hello")); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(val, []byte{byte(i)}) {
+			t.Fatalf("Should have read %v from the db but read %v", []byte{byte(i)}, val)
+		}
+	}
+	it = ks.dataDB("bob").NewIterator()
+	for it.Next() {
+		if !bytes.Equal(it.Value(), storedData[string(it.Key())]) {
+			t.Fatalf("Changing the password shouldn't have re-encrypted the data")
+		}
+	}
+	it.Release()
+
+	// The user is read from the database, rather than the cache, by a new
+	// keystore
+	newKS := Keystore{}
+	newKS.Initialize(logging.NoLog{}, ks.db)
+	if _, err := newKS.GetDatabase(ids.Empty, "bob", "liftoff-t0-orbit"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServiceChangePasswordWithoutDataKey(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	// Users created before data keys encrypt their data with a key derived
+	// from their password
+	usr := User{}
+	if err := usr.Initialize("launchpad#2020", ks.hashParams); err != nil {
+		t.Fatal(err)
+	}
+	usrBytes, err := ks.codec.MarshalVersion(1, &usr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.userDB.Put([]byte("bob"), usrBytes); err != nil {
+		t.Fatal(err)
+	}
+	db, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ks.ChangePassword(nil, &ChangePasswordArgs{
+		Username:    "bob",
+		OldPassword: "launchpad#2020",
+		NewPassword: "liftoff-t0-orbit",
+	}, &ChangePasswordReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	newKS := Keystore{}
+	newKS.Initialize(logging.NoLog{}, ks.db)
+	db, err = newKS.GetDatabase(ids.Empty, "bob", "liftoff-t0-orbit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, err := db.Get([]byte("hello")); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(val, []byte("world")) {
+		t.Fatalf("Should have read %s from the db but read %s", []byte("world"), val)
+	}
+
+	// The data is re-encrypted with a new data key, so the key derived from
+	// the old password no longer decrypts it
+	if usr, err := newKS.getUser("bob"); err != nil {
+		t.Fatal(err)
+	} else if len(usr.DataKey) == 0 {
+		t.Fatalf("Should have given the user a data key")
+	}
+	oldDB, err := encdb.New([]byte("launchpad#2020"), prefixdb.NewNested(ids.Empty.Bytes(), newKS.dataDB("bob")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := oldDB.Get([]byte("hello")); err == nil {
+		t.Fatalf("Shouldn't have decrypted the data with the old password")
+	}
+}
+
+func TestServicePasswordPolicy(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	for _, password := range []string{"", "123", "password1"} {
+		if err := ks.CreateUser(nil, &CreateUserArgs{
+			Username: "bob",
+			Password: password,
+		}, &CreateUserReply{}); err == nil {
+			t.Fatalf("Shouldn't have allowed the password %q", password)
+		}
+	}
+
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.ChangePassword(nil, &ChangePasswordArgs{
+		Username:    "bob",
+		OldPassword: "launchpad#2020",
+		NewPassword: "123",
+	}, &ChangePasswordReply{}); err == nil {
+		t.Fatalf("Shouldn't have allowed the new password")
+	}
+
+	ks.SetPasswordPolicy(StrengthPolicy{})
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "alice",
+		Password: "123",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServiceHashParams(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	if err := ks.SetHashParams(HashParams{KDF: Scrypt}); err == nil {
+		t.Fatalf("Shouldn't have allowed invalid parameters")
+	}
+	params := HashParams{KDF: Scrypt, N: 1 << 10, R: 8, P: 1}
+	if err := ks.SetHashParams(params); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The user is still verified with the parameters they were created with
+	// after the defaults change
+	newKS := Keystore{}
+	newKS.Initialize(logging.NoLog{}, ks.db)
+	usr, err := newKS.getUser("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usr.Params != params {
+		t.Fatalf("Should have stored the parameters the password was hashed with")
+	}
+	if _, err := newKS.GetDatabase(ids.Empty, "bob", "launchpad#2020"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServiceLegacyUser(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	// Users used to be stored without a codec version or hash parameters
+	usr := User{}
+	if err := usr.Initialize("launch", legacyHashParams); err != nil {
+		t.Fatal(err)
+	}
+	usrBytes, err := codec.NewDefault().Marshal(&usr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.userDB.Put([]byte("bob"), usrBytes); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ks.GetDatabase(ids.Empty, "bob", "launch"); err != nil {
+		t.Fatal(err)
+	}
+
+	exportReply := ExportUserReply{}
+	if err := ks.ExportUser(nil, &ExportUserArgs{
+		Username: "bob",
+		Password: "launch",
+	}, &exportReply); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.ImportUser(nil, &ImportUserArgs{
+		Username: "alice",
+		Password: "launch",
+		User:     exportReply.User,
+	}, &ImportUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.GetDatabase(ids.Empty, "alice", "launch"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Users exported before the codec was versioned can still be imported
+	legacyBytes, err := codec.NewDefault().Marshal(&UserDB{User: usr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.ImportUser(nil, &ImportUserArgs{
+		Username: "carol",
+		Password: "launch",
+		User:     formatting.CB58{Bytes: legacyBytes}.String(),
+	}, &ImportUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.GetDatabase(ids.Empty, "carol", "launch"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServiceLogin(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	for _, username := range []string{"bob", "alice"} {
+		if err := ks.CreateUser(nil, &CreateUserArgs{
+			Username: username,
+			Password: "launchpad#2020",
+		}, &CreateUserReply{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ks.Login(nil, &LoginArgs{
+		Username: "bob",
+		Password: "wrong",
+	}, &LoginReply{}); err == nil {
+		t.Fatalf("Shouldn't have logged in with the wrong password")
+	}
+
+	now := time.Now()
+	ks.clock.Set(now)
+	reply := LoginReply{}
+	if err := ks.Login(nil, &LoginArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if expiry := now.Add(DefaultTokenDuration); reply.Expiry.After(expiry) || !reply.Expiry.After(expiry.Add(-time.Second)) {
+		t.Fatalf("Token should have expired at %s but expires at %s", expiry, reply.Expiry)
+	}
+
+	// The token stands for the password
+	db, err := ks.NewBlockchainKeyStore(ids.Empty).GetDatabase("bob", reply.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+	db, err = ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, err := db.Get([]byte("hello")); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(val, []byte("world")) {
+		t.Fatalf("Should have read '%s' from the db but read '%s'", []byte("world"), val)
+	}
+
+	if _, err := ks.GetDatabase(ids.Empty, "alice", reply.Token); err != errWrongUser {
+		t.Fatalf("Should have errored with %s but errored with %v", errWrongUser, err)
+	}
+
+	ks.clock.Set(reply.Expiry)
+	if _, err := ks.GetDatabase(ids.Empty, "bob", reply.Token); err != errExpiredToken {
+		t.Fatalf("Should have errored with %s but errored with %v", errExpiredToken, err)
+	}
+	if len(ks.sessions) != 0 {
+		t.Fatalf("The expired session should have been dropped")
+	}
+}
+
+func TestServiceLogout(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := make([]string, 2)
+	for i := range tokens {
+		reply := LoginReply{}
+		if err := ks.Login(nil, &LoginArgs{
+			Username: "bob",
+			Password: "launchpad#2020",
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		tokens[i] = reply.Token
+	}
+
+	reply := LogoutReply{}
+	if err := ks.Logout(nil, &LogoutArgs{Token: tokens[0]}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.Success {
+		t.Fatalf("Should have logged out successfully")
+	}
+	if _, err := ks.GetDatabase(ids.Empty, "bob", tokens[0]); err != errUnknownToken {
+		t.Fatalf("Should have errored with %s but errored with %v", errUnknownToken, err)
+	}
+	if err := ks.Logout(nil, &LogoutArgs{Token: tokens[0]}, &LogoutReply{}); err != errUnknownToken {
+		t.Fatalf("Should have errored with %s but errored with %v", errUnknownToken, err)
+	}
+	if err := ks.Logout(nil, &LogoutArgs{Token: "launchpad#2020"}, &LogoutReply{}); err != errInvalidToken {
+		t.Fatalf("Should have errored with %s but errored with %v", errInvalidToken, err)
+	}
+
+	// Other tokens are unaffected
+	if _, err := ks.GetDatabase(ids.Empty, "bob", tokens[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing the password revokes every token
+	if err := ks.ChangePassword(nil, &ChangePasswordArgs{
+		Username:    "bob",
+		OldPassword: "launchpad#2020",
+		NewPassword: "liftoff-t0-orbit",
+	}, &ChangePasswordReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.GetDatabase(ids.Empty, "bob", tokens[1]); err != errUnknownToken {
+		t.Fatalf("Should have errored with %s but errored with %v", errUnknownToken, err)
+	}
+}
+
+func TestServiceExportImportUserChunks(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	for _, username := range []string{"bob", "carol"} {
+		if err := ks.CreateUser(nil, &CreateUserArgs{
+			Username: username,
+			Password: "launchpad#2020",
+		}, &CreateUserReply{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	numPairs := 250
+	for i := 0; i < numPairs; i++ {
+		if err := db.Put([]byte{byte(i >> 8), byte(i)}, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chunks := []string{}
+	startKey := ""
+	for {
+		reply := ExportUserChunkReply{}
+		if err := ks.ExportUserChunk(nil, &ExportUserChunkArgs{
+			Username: "bob",
+			Password: "launchpad#2020",
+			Limit:    100,
+			StartKey: startKey,
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, reply.Chunk)
+		if reply.EndKey == "" {
+			break
+		}
+		startKey = reply.EndKey
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("Should have exported 3 chunks but exported %d", len(chunks))
+	}
+
+	carolReply := ExportUserChunkReply{}
+	if err := ks.ExportUserChunk(nil, &ExportUserChunkArgs{
+		Username: "carol",
+		Password: "launchpad#2020",
+	}, &carolReply); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, chunk := range chunks {
+		if i == 1 {
+			if err := ks.ImportUserChunk(nil, &ImportUserChunkArgs{
+				Username: "alice",
+				Password: "launchpad#2020",
+				Chunk:    carolReply.Chunk,
+			}, &ImportUserChunkReply{}); err == nil {
+				t.Fatalf("Shouldn't have imported another user's chunk")
+			}
+			if err := ks.CreateUser(nil, &CreateUserArgs{
+				Username: "alice",
+				Password: "launchpad#2020",
+			}, &CreateUserReply{}); err == nil {
+				t.Fatalf("Shouldn't have created a user that is being imported")
+			}
+			if _, err := ks.GetDatabase(ids.Empty, "alice", "launchpad#2020"); err == nil {
+				t.Fatalf("The user shouldn't exist until their last chunk is imported")
+			}
+		}
+
+		reply := ImportUserChunkReply{}
+		if err := ks.ImportUserChunk(nil, &ImportUserChunkArgs{
+			Username: "alice",
+			Password: "launchpad#2020",
+			Chunk:    chunk,
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if !reply.Success {
+			t.Fatalf("Should have imported the chunk")
+		}
+		if last := i == len(chunks)-1; reply.Done != last {
+			t.Fatalf("Import should have been done after the last chunk")
+		}
+	}
+
+	db, err = ks.GetDatabase(ids.Empty, "alice", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < numPairs; i++ {
+		if val, err := db.Get([]byte{byte(i >> 8), byte(i)}); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(val, []byte{byte(i)}) {
+			t.Fatalf("Should have read %v from the db but read %v", []byte{byte(i)}, val)
+		}
+	}
+	if err := ks.ImportUserChunk(nil, &ImportUserChunkArgs{
+		Username: "alice",
+		Password: "launchpad#2020",
+		Chunk:    chunks[0],
+	}, &ImportUserChunkReply{}); err == nil {
+		t.Fatalf("Shouldn't have imported a chunk of a user that already exists")
+	}
+}
+
+func TestServiceExportUserChunkSize(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	db, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Few enough pairs to be exported in one chunk, but too many bytes
+	value := make([]byte, 1024)
+	for i := 0; i < 30; i++ {
+		if err := db.Put([]byte{byte(i)}, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reply := ExportUserChunkReply{}
+	if err := ks.ExportUserChunk(nil, &ExportUserChunkArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.EndKey == "" {
+		t.Fatalf("The chunk should have been cut short by its size")
+	}
+}
+
+func TestServiceEncryptedIndex(t *testing.T) {
+	baseDB := memdb.New()
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, baseDB)
+
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	db, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	masterKey := make([]byte, MinMasterKeyLen)
+	masterKey[0] = 1
+	if err := ks.EnableEncryption(masterKey[1:]); err != errShortMasterKey {
+		t.Fatalf("Should have errored with %s but errored with %v", errShortMasterKey, err)
+	}
+	if err := ks.EnableEncryption(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.EnableEncryption(masterKey); err != errEncryptionEnabled {
+		t.Fatalf("Should have errored with %s but errored with %v", errEncryptionEnabled, err)
+	}
+
+	// Neither the username nor its hash, which data used to be prefixed with,
+	// is stored anymore
+	plainPrefix := prefixdb.New([]byte("bob"), ks.bcDB).NewIterator()
+	if plainPrefix.Next() {
+		t.Fatalf("Data should have been moved from under the plaintext username")
+	}
+	plainPrefix.Release()
+	it := baseDB.NewIterator()
+	for it.Next() {
+		if bytes.Contains(it.Key(), []byte("bob")) || bytes.Contains(it.Value(), []byte("bob")) {
+			t.Fatalf("The username shouldn't have been stored in plaintext")
+		}
+	}
+	it.Release()
+
+	// The migrated user is found with the same master key after a restart
+	ks = Keystore{}
+	ks.Initialize(logging.NoLog{}, baseDB)
+	if err := ks.EnableEncryption(masterKey); err != nil {
+		t.Fatal(err)
+	}
+	db, err = ks.GetDatabase(ids.Empty, "bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, err := db.Get([]byte("hello")); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(val, []byte("world")) {
+		t.Fatalf("Should have read '%s' from the db but read '%s'", []byte("world"), val)
+	}
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "alice",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	listReply := ListUsersReply{}
+	if err := ks.ListUsers(nil, &ListUsersArgs{}, &listReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(listReply.Users) != 2 || listReply.Users[0] != "alice" || listReply.Users[1] != "bob" {
+		t.Fatalf("Should have listed [alice bob] but listed %v", listReply.Users)
+	}
+
+	// Without the master key, the users can't be found
+	ks = Keystore{}
+	ks.Initialize(logging.NoLog{}, baseDB)
+	listReply = ListUsersReply{}
+	if err := ks.ListUsers(nil, &ListUsersArgs{}, &listReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(listReply.Users) != 0 {
+		t.Fatalf("Shouldn't have listed the encrypted users but listed %v", listReply.Users)
+	}
+	otherKey := make([]byte, MinMasterKeyLen)
+	if err := ks.EnableEncryption(otherKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.GetDatabase(ids.Empty, "bob", "launchpad#2020"); err == nil {
+		t.Fatalf("Shouldn't have found the user with another master key")
+	}
+}
+
+func TestServiceHealthCheck(t *testing.T) {
+	db := memdb.New()
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, db)
+
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.HealthCheck(); err != nil {
+		t.Fatalf("keystore should have been healthy but: %s", err)
+	}
+
+	db.Close()
+	if _, err := ks.HealthCheck(); err == nil {
+		t.Fatalf("keystore should have been unhealthy once its database closed")
+	}
+}
+
+func TestServiceExportImportHex(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	exportReply := ExportUserReply{}
+	if err := ks.ExportUser(nil, &ExportUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+		Encoding: formatting.HexEncoding,
+	}, &exportReply); err != nil {
+		t.Fatal(err)
+	}
+	if exportReply.Encoding != formatting.HexEncoding || !strings.HasPrefix(exportReply.User, "0x") {
+		t.Fatalf("User should have been exported in hex but was %q in %q", exportReply.User, exportReply.Encoding)
+	}
+
+	newKS := Keystore{}
+	newKS.Initialize(logging.NoLog{}, memdb.New())
+	if err := newKS.ImportUser(nil, &ImportUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+		User:     exportReply.User,
+	}, &ImportUserReply{}); err == nil {
+		t.Fatalf("User in hex shouldn't have been imported as CB58")
+	}
+	if err := newKS.ImportUser(nil, &ImportUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+		User:     exportReply.User,
+		Encoding: formatting.HexEncoding,
+	}, &ImportUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newKS.GetDatabase(ids.Empty, "bob", "launchpad#2020"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ks.ExportUser(nil, &ExportUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+		Encoding: "base64",
+	}, &ExportUserReply{}); err == nil {
+		t.Fatalf("User shouldn't have been exported in an unknown encoding")
+	}
+}
+
+func TestServiceReadOnlyDatabase(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	bks := ks.NewBlockchainKeyStore(ids.Empty)
+	db, err := bks.GetDatabase("bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	readOnlyDB, err := bks.GetReadOnlyDatabase("bob", "launchpad#2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, err := readOnlyDB.Get([]byte("hello")); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(val, []byte("world")) {
+		t.Fatalf("Should have read '%s' from the db", "world")
+	}
+	if err := readOnlyDB.Put([]byte("hello"), []byte("there")); err != readonlydb.ErrReadOnly {
+		t.Fatalf("Writing to a read-only database should have failed with %s but returned %v", readonlydb.ErrReadOnly, err)
+	}
+
+	if _, err := bks.GetReadOnlyDatabase("bob", "wrong#password2020"); err == nil {
+		t.Fatalf("Should have failed to open the database with the wrong password")
+	}
+}
+
+func TestServiceConcurrentBlockchains(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+	if err := ks.SetHashParams(HashParams{KDF: Scrypt, N: 1 << 10, R: 8, P: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: "launchpad#2020",
+	}, &CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Several blockchains use bob's data at once, while other users are
+	// created and bob logs in
+	const numChains = 4
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 3*numChains)
+	for i := 0; i < numChains; i++ {
+		bks := ks.NewBlockchainKeyStore(ids.NewID([32]byte{byte(i)}))
+		value := []byte{byte(i)}
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				db, err := bks.GetDatabase("bob", "launchpad#2020")
+				if err != nil {
+					errs <- err
+					return
+				}
+				if err := db.Put([]byte("chain"), value); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			errs <- ks.CreateUser(nil, &CreateUserArgs{
+				Username: fmt.Sprintf("alice%d", i),
+				Password: "launchpad#2020",
+			}, &CreateUserReply{})
+		}(i)
+		go func() {
+			defer wg.Done()
+			errs <- ks.Login(nil, &LoginArgs{
+				Username: "bob",
+				Password: "launchpad#2020",
+			}, &LoginReply{})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each blockchain only sees its own data
+	for i := 0; i < numChains; i++ {
+		db, err := ks.GetReadOnlyDatabase(ids.NewID([32]byte{byte(i)}), "bob", "launchpad#2020")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, err := db.Get([]byte("chain")); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(val, []byte{byte(i)}) {
+			t.Fatalf("Chain %d should have read %v but read %v", i, []byte{byte(i)}, val)
+		}
+	}
+
+	reply := ListUsersReply{}
+	if err := ks.ListUsers(nil, &ListUsersArgs{}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Users) != numChains+1 {
+		t.Fatalf("Expected %d users but found %v", numChains+1, reply.Users)
+	}
+
+	// The locks of users are dropped once no call holds them
+	if len(ks.userLocks) != 0 {
+		t.Fatalf("Expected no user locks to be left but found %d", len(ks.userLocks))
+	}
+}
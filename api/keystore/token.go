@@ -0,0 +1,177 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package keystore
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// DefaultTokenDuration is how long login tokens are valid for, unless the
+	// keystore is configured otherwise
+	DefaultTokenDuration = 30 * time.Minute
+
+	tokenIDLen     = 16
+	tokenSecretLen = 32
+	tokenMACLen    = sha256.Size
+
+	// A token is its ID, the unix time it expires at and its MAC
+	tokenLen = tokenIDLen + wrappers.LongLen + tokenMACLen
+)
+
+var (
+	errInvalidToken = errors.New("not a login token")
+	errExpiredToken = errors.New("login token has expired")
+	errUnknownToken = errors.New("unknown or revoked login token")
+	errWrongUser    = errors.New("login token was issued to another user")
+)
+
+// session is what a login token stands for. The keystore holds the user's
+// password while they're logged in, since their data is encrypted with it.
+type session struct {
+	username string
+	password string
+	expiry   time.Time
+}
+
+// newToken logs in [username], whose password is [password], and returns a
+// token that stands for their password until it expires. Assumes the lock is
+// held for reading.
+func (ks *Keystore) newToken(username, password string) (string, time.Time, error) {
+	ks.stateLock.Lock()
+	defer ks.stateLock.Unlock()
+
+	if ks.tokenSecret == nil {
+		ks.tokenSecret = make([]byte, tokenSecretLen)
+		if _, err := rand.Read(ks.tokenSecret); err != nil {
+			ks.tokenSecret = nil
+			return "", time.Time{}, err
+		}
+	}
+
+	// Expired sessions are dropped here, so they don't build up
+	now := ks.clock.Time()
+	for id, s := range ks.sessions {
+		if !now.Before(s.expiry) {
+			delete(ks.sessions, id)
+		}
+	}
+
+	id := [tokenIDLen]byte{}
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", time.Time{}, err
+	}
+	// Tokens expire on a whole second, so the expiry they carry is exact
+	expiry := now.Add(ks.tokenDuration).Truncate(time.Second)
+
+	p := wrappers.Packer{MaxSize: tokenLen, Bytes: make([]byte, 0, tokenLen)}
+	p.PackFixedBytes(id[:])
+	p.PackLong(uint64(expiry.Unix()))
+	p.PackFixedBytes(ks.tokenMAC(p.Bytes))
+	if p.Errored() {
+		return "", time.Time{}, p.Err
+	}
+
+	ks.sessions[id] = &session{
+		username: username,
+		password: password,
+		expiry:   expiry,
+	}
+	return formatting.CB58{Bytes: p.Bytes}.String(), expiry, nil
+}
+
+// tokenID returns the ID of [token]. If [token] isn't a token signed by this
+// keystore, it returns false, since it may be a password. Assumes the state
+// lock is held.
+func (ks *Keystore) tokenID(token string) ([tokenIDLen]byte, bool, error) {
+	id := [tokenIDLen]byte{}
+	if ks.tokenSecret == nil {
+		return id, false, nil
+	}
+	cb58 := formatting.CB58{}
+	if err := cb58.FromString(token); err != nil || len(cb58.Bytes) != tokenLen {
+		return id, false, nil
+	}
+
+	p := wrappers.Packer{Bytes: cb58.Bytes}
+	copy(id[:], p.UnpackFixedBytes(tokenIDLen))
+	expiry := time.Unix(int64(p.UnpackLong()), 0)
+	signed := cb58.Bytes[:p.Offset]
+	if !hmac.Equal(p.UnpackFixedBytes(tokenMACLen), ks.tokenMAC(signed)) {
+		return id, false, nil
+	}
+	if !ks.clock.Time().Before(expiry) {
+		delete(ks.sessions, id)
+		return id, true, errExpiredToken
+	}
+	return id, true, nil
+}
+
+// tokenMAC returns the MAC of a token whose ID and expiry are [signed]
+func (ks *Keystore) tokenMAC(signed []byte) []byte {
+	mac := hmac.New(sha256.New, ks.tokenSecret)
+	mac.Write(signed)
+	return mac.Sum(nil)
+}
+
+// revokeToken revokes [token]
+func (ks *Keystore) revokeToken(token string) error {
+	ks.stateLock.Lock()
+	defer ks.stateLock.Unlock()
+
+	id, isToken, err := ks.tokenID(token)
+	switch {
+	case err != nil:
+		return err
+	case !isToken:
+		return errInvalidToken
+	}
+	if _, exists := ks.sessions[id]; !exists {
+		return errUnknownToken
+	}
+	delete(ks.sessions, id)
+	return nil
+}
+
+// revokeTokens revokes every token issued to [username]
+func (ks *Keystore) revokeTokens(username string) {
+	ks.stateLock.Lock()
+	defer ks.stateLock.Unlock()
+
+	for id, s := range ks.sessions {
+		if s.username == username {
+			delete(ks.sessions, id)
+		}
+	}
+}
+
+// password returns the password of [username], given either their password or
+// a login token issued to them
+func (ks *Keystore) password(username, passwordOrToken string) (string, error) {
+	ks.stateLock.Lock()
+	defer ks.stateLock.Unlock()
+
+	id, isToken, err := ks.tokenID(passwordOrToken)
+	switch {
+	case err != nil:
+		return "", err
+	case !isToken:
+		return passwordOrToken, nil
+	}
+	s, exists := ks.sessions[id]
+	switch {
+	case !exists:
+		return "", errUnknownToken
+	case s.username != username:
+		return "", errWrongUser
+	}
+	return s.password, nil
+}
@@ -6,30 +6,180 @@ package keystore
 import (
 	"bytes"
 	"crypto/rand"
+	"fmt"
 
 	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/encdb"
+)
+
+// The key derivation functions that passwords can be hashed with
+const (
+	Argon2id = "argon2id"
+	Scrypt   = "scrypt"
+)
+
+const (
+	hashLen = 32
+	saltLen = 16
+)
+
+var (
+	// DefaultHashParams are the parameters new users' passwords are hashed
+	// with, unless the keystore is configured otherwise
+	DefaultHashParams = HashParams{
+		KDF:     Argon2id,
+		Time:    1,
+		Memory:  64 * 1024,
+		Threads: 4,
+	}
+
+	// DefaultScryptParams are the parameters passwords are hashed with when
+	// the keystore is configured to use scrypt, unless it's configured with
+	// other scrypt parameters
+	DefaultScryptParams = HashParams{
+		KDF: Scrypt,
+		N:   1 << 15,
+		R:   8,
+		P:   1,
+	}
+
+	// legacyHashParams are the parameters that the passwords of users created
+	// before the parameters were stored with the user were hashed with
+	legacyHashParams = DefaultHashParams
 )
 
+// HashParams are the key derivation function, and its cost parameters, that a
+// password is hashed with
+type HashParams struct {
+	KDF string `serialize:"true"`
+
+	// Parameters of argon2id. Memory is in KiB.
+	Time    uint32 `serialize:"true"`
+	Memory  uint32 `serialize:"true"`
+	Threads uint8  `serialize:"true"`
+
+	// Parameters of scrypt
+	N uint32 `serialize:"true"`
+	R uint32 `serialize:"true"`
+	P uint32 `serialize:"true"`
+}
+
+// Valid returns nil if passwords can be hashed with these parameters. They're
+// held to the same conditions as the parameters encdb derives keys with.
+func (p HashParams) Valid() error {
+	switch p.KDF {
+	case Argon2id, Scrypt:
+		return p.kdfParams().Valid()
+	default:
+		return fmt.Errorf("unknown key derivation function %q", p.KDF)
+	}
+}
+
+// hash returns [password] salted with [salt] and hashed
+func (p HashParams) hash(password string, salt []byte) ([]byte, error) {
+	switch p.KDF {
+	case Argon2id:
+		return argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, hashLen), nil
+	case Scrypt:
+		return scrypt.Key([]byte(password), salt, int(p.N), int(p.R), int(p.P), hashLen)
+	default:
+		return nil, fmt.Errorf("unknown key derivation function %q", p.KDF)
+	}
+}
+
+// kdfParams returns the parameters that encdb derives keys with that match
+// these
+func (p HashParams) kdfParams() encdb.KDFParams {
+	params := encdb.KDFParams{
+		KDF:     encdb.Argon2id,
+		Time:    p.Time,
+		Memory:  p.Memory,
+		Threads: p.Threads,
+		N:       p.N,
+		R:       p.R,
+		P:       p.P,
+	}
+	if p.KDF == Scrypt {
+		params.KDF = encdb.Scrypt
+	}
+	return params
+}
+
 // User describes a user of the keystore
 type User struct {
-	Password [32]byte `serialize:"true"` // The salted, hashed password
-	Salt     [16]byte `serialize:"true"` // The salt
+	Password [hashLen]byte `serialize:"true"` // The salted, hashed password
+	Salt     [saltLen]byte `serialize:"true"` // The salt
+
+	// How the password was hashed. Users serialized before version 1 don't
+	// have them, and were hashed with the legacy parameters.
+	Params HashParams `serialize:"true" version:"1"`
+
+	// The encdb header of the key that the user's blockchain data is encrypted
+	// with, wrapped with their password. Users serialized before version 2
+	// don't have one, and their data is encrypted with a key derived from
+	// their password alone.
+	DataKey []byte `serialize:"true" version:"2"`
 }
 
-// Initialize ...
-func (usr *User) Initialize(password string) error {
-	_, err := rand.Read(usr.Salt[:])
-	if err != nil {
+// Initialize the user with [password], hashed with [params]
+func (usr *User) Initialize(password string, params HashParams) error {
+	if err := params.Valid(); err != nil {
+		return err
+	}
+	if _, err := rand.Read(usr.Salt[:]); err != nil {
 		return err
 	}
 	// pw is the salted, hashed password
-	pw := argon2.IDKey([]byte(password), usr.Salt[:], 1, 64*1024, 4, 32)
-	copy(usr.Password[:], pw[:32])
+	pw, err := params.hash(password, usr.Salt[:])
+	if err != nil {
+		return err
+	}
+	copy(usr.Password[:], pw)
+	usr.Params = params
 	return nil
 }
 
-// CheckPassword ...
+// InitializeDataKey gives the user a new random key for their blockchain data,
+// wrapped with [password], which is derived with [params]
+func (usr *User) InitializeDataKey(password string, params HashParams) error {
+	header, err := encdb.NewHeader([]byte(password), encdb.XChaCha20Poly1305, params.kdfParams())
+	if err != nil {
+		return err
+	}
+	usr.DataKey, err = header.Bytes()
+	return err
+}
+
+// dataDB returns [db] with its values encrypted with the user's data key,
+// which [password] unwraps
+func (usr *User) dataDB(password string, db database.Database) (*encdb.Database, error) {
+	if len(usr.DataKey) == 0 {
+		return encdb.New([]byte(password), db)
+	}
+	header, err := encdb.ParseHeader(usr.DataKey)
+	if err != nil {
+		return nil, err
+	}
+	return encdb.NewWithHeader([]byte(password), header, db)
+}
+
+// equals returns true if [other] is the same user, with the same password
+func (usr *User) equals(other *User) bool {
+	return usr.Password == other.Password &&
+		usr.Salt == other.Salt &&
+		usr.Params == other.Params &&
+		bytes.Equal(usr.DataKey, other.DataKey)
+}
+
+// CheckPassword returns true if [password] is the user's password
 func (usr *User) CheckPassword(password string) bool {
-	pw := argon2.IDKey([]byte(password), usr.Salt[:], 1, 64*1024, 4, 32)
-	return bytes.Equal(pw, usr.Password[:])
+	params := usr.Params
+	if params.KDF == "" {
+		params = legacyHashParams
+	}
+	pw, err := params.hash(password, usr.Salt[:])
+	return err == nil && bytes.Equal(pw, usr.Password[:])
 }
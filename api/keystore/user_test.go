@@ -9,7 +9,7 @@ import (
 
 func TestUser(t *testing.T) {
 	usr := User{}
-	if err := usr.Initialize("heytherepal"); err != nil {
+	if err := usr.Initialize("heytherepal", DefaultHashParams); err != nil {
 		t.Fatal(err)
 	}
 	if !usr.CheckPassword("heytherepal") {
@@ -22,3 +22,60 @@ func TestUser(t *testing.T) {
 		t.Fatalf("Shouldn't have verified the password")
 	}
 }
+
+func TestUserScrypt(t *testing.T) {
+	params := HashParams{KDF: Scrypt, N: 1 << 10, R: 8, P: 1}
+	usr := User{}
+	if err := usr.Initialize("heytherepal", params); err != nil {
+		t.Fatal(err)
+	}
+	if usr.Params != params {
+		t.Fatalf("Should have stored the parameters the password was hashed with")
+	}
+	if !usr.CheckPassword("heytherepal") {
+		t.Fatalf("Should have verified the password")
+	}
+	if usr.CheckPassword("heytherepal!") {
+		t.Fatalf("Shouldn't have verified the password")
+	}
+}
+
+func TestUserLegacy(t *testing.T) {
+	usr := User{}
+	if err := usr.Initialize("heytherepal", legacyHashParams); err != nil {
+		t.Fatal(err)
+	}
+	// Users stored before the parameters were don't have them
+	usr.Params = HashParams{}
+	if !usr.CheckPassword("heytherepal") {
+		t.Fatalf("Should have verified the password with the legacy parameters")
+	}
+}
+
+func TestHashParamsValid(t *testing.T) {
+	invalid := []HashParams{
+		{},
+		{KDF: "bcrypt"},
+		{KDF: Argon2id, Memory: 64 * 1024, Threads: 4},
+		{KDF: Argon2id, Time: 1, Memory: 64 * 1024},
+		{KDF: Argon2id, Time: 1, Memory: 8, Threads: 4},
+		{KDF: Scrypt, N: 1000, R: 8, P: 1},
+		{KDF: Scrypt, N: 1, R: 8, P: 1},
+		{KDF: Scrypt, N: 1 << 10, P: 1},
+		{KDF: Scrypt, N: 1 << 10, R: 1 << 15, P: 1 << 15},
+		{KDF: Argon2id, Time: 1, Memory: 0xFFFFFFFF, Threads: 4},
+		{KDF: Argon2id, Time: 1 << 20, Memory: 64 * 1024, Threads: 4},
+		{KDF: Argon2id, Time: 1, Memory: 64 * 1024, Threads: 255},
+		{KDF: Scrypt, N: 1 << 31, R: 8, P: 1},
+		{KDF: Scrypt, N: 1 << 20, R: 16, P: 1},
+		{KDF: Scrypt, N: 1 << 10, R: 8, P: 1 << 10},
+	}
+	for _, params := range invalid {
+		if err := params.Valid(); err == nil {
+			t.Fatalf("%+v shouldn't have been valid", params)
+		}
+	}
+	if err := DefaultHashParams.Valid(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,250 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestLimitRule limits how long requests to an endpoint may take to be
+// handled and how large their bodies may be
+type RequestLimitRule struct {
+	// Endpoint the rule applies to, along with the endpoints under it, such as
+	// "bc/X" for "/ext/bc/X/wallet". "*" applies to every endpoint. Only the
+	// rule of the most specific endpoint applies to a request.
+	Endpoint string
+
+	// Requests that aren't handled within this long are answered with 408
+	// Request Timeout. If 0, requests may take any time.
+	Timeout time.Duration
+
+	// Requests with larger bodies, in bytes, are answered with 413 Request
+	// Entity Too Large. If 0, bodies may be any size.
+	MaxBodySize int64
+}
+
+// ParseRequestLimitRules parses rules of the form
+// "endpoint=timeout:maxBodySize,endpoint=timeout:maxBodySize", such as
+// "*=30s:1048576,keystore=2m:0", where a timeout or size of 0 is unlimited
+func ParseRequestLimitRules(rules string) ([]RequestLimitRule, error) {
+	parsed := []RequestLimitRule(nil)
+	if rules == "" {
+		return parsed, nil
+	}
+	for _, entry := range strings.Split(rules, ",") {
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) != 2 || fields[0] == "" {
+			return nil, fmt.Errorf("request limit %q should be of the form endpoint=timeout:maxBodySize", entry)
+		}
+		limits := strings.SplitN(fields[1], ":", 2)
+		if len(limits) != 2 {
+			return nil, fmt.Errorf("request limit of %s should be of the form timeout:maxBodySize but is %q", fields[0], fields[1])
+		}
+		timeout, err := time.ParseDuration(limits[0])
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("request limit of %s should have a non-negative timeout but has %q", fields[0], limits[0])
+		}
+		maxBodySize, err := strconv.ParseInt(limits[1], 10, 64)
+		if err != nil || maxBodySize < 0 {
+			return nil, fmt.Errorf("request limit of %s should have a non-negative maximum body size but has %q", fields[0], limits[1])
+		}
+		parsed = append(parsed, RequestLimitRule{
+			Endpoint:    normalizeEndpoint(fields[0]),
+			Timeout:     timeout,
+			MaxBodySize: maxBodySize,
+		})
+	}
+	return parsed, nil
+}
+
+// requestLimiter limits how long requests take to be handled and how large
+// their bodies are
+type requestLimiter struct {
+	metrics *metrics
+
+	// canonical, if it isn't nil, returns the endpoint that an endpoint is
+	// an alias of, so requests under an alias are limited by the rules of
+	// the endpoint it's an alias of
+	canonical func(endpoint string) string
+
+	lock  sync.RWMutex
+	rules []RequestLimitRule
+}
+
+// setRules replaces the rules with [rules]
+func (r *requestLimiter) setRules(rules []RequestLimitRule) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rules = rules
+}
+
+// rule returns the rule of the most specific endpoint that applies to
+// [endpoint], which is already resolved, or nil if none apply. A rule of an
+// alias applies to the endpoint the alias is of.
+func (r *requestLimiter) rule(endpoint string) *RequestLimitRule {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var (
+		matched *RequestLimitRule
+		length  = -1
+	)
+	for i, rule := range r.rules {
+		if rule.Endpoint == allEndpoints {
+			if length < 0 {
+				matched, length = &r.rules[i], 0
+			}
+			continue
+		}
+		ruleEndpoint := resolveEndpoint(r.canonical, rule.Endpoint)
+		if (ruleEndpoint == endpoint || strings.HasPrefix(endpoint, ruleEndpoint+"/")) && len(ruleEndpoint) > length {
+			matched, length = &r.rules[i], len(ruleEndpoint)
+		}
+	}
+	return matched
+}
+
+// wrap [handler] so requests are limited by the rule of their endpoint.
+// Websocket connections and event streams aren't timed out, since they're
+// meant to stay open, but their requests are still limited in size. Requests
+// under an alias are limited by the rule of the endpoint it's an alias of.
+func (r *requestLimiter) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rule := r.rule(resolveEndpoint(r.canonical, normalizeEndpoint(req.URL.Path)))
+		if rule == nil {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		if rule.MaxBodySize > 0 && req.Body != nil {
+			if req.ContentLength > rule.MaxBodySize {
+				r.metrics.tooLarge.WithLabelValues(rule.Endpoint).Inc()
+				http.Error(w, fmt.Sprintf("request body is larger than the limit of %d bytes", rule.MaxBodySize), http.StatusRequestEntityTooLarge)
+				return
+			}
+			// The body is read before it's handled, so a body that's too
+			// large is answered the same way whether or not its length was
+			// given
+			body, err := ioutil.ReadAll(io.LimitReader(req.Body, rule.MaxBodySize+1))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("couldn't read request body: %s", err), http.StatusBadRequest)
+				return
+			}
+			if int64(len(body)) > rule.MaxBodySize {
+				r.metrics.tooLarge.WithLabelValues(rule.Endpoint).Inc()
+				http.Error(w, fmt.Sprintf("request body is larger than the limit of %d bytes", rule.MaxBodySize), http.StatusRequestEntityTooLarge)
+				return
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		if rule.Timeout <= 0 || req.Header.Get("Upgrade") != "" || isEventStream(req) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		if serveWithTimeout(w, req, handler, rule.Timeout) {
+			r.metrics.timedOut.WithLabelValues(rule.Endpoint).Inc()
+		}
+	})
+}
+
+// isEventStream returns true if [req] asks for a stream of server-sent events
+func isEventStream(req *http.Request) bool {
+	for _, accept := range req.Header["Accept"] {
+		if strings.Contains(accept, "text/event-stream") {
+			return true
+		}
+	}
+	return false
+}
+
+// serveWithTimeout serves [req] with [handler], answering it with 408 Request
+// Timeout if it isn't handled within [timeout]. The request's context is
+// cancelled when it times out, so that the handler can stop early. The
+// response is buffered until the handler returns. Returns true if the request
+// timed out.
+func serveWithTimeout(w http.ResponseWriter, req *http.Request, handler http.Handler, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+
+	writer := &timeoutWriter{header: make(http.Header), status: http.StatusOK}
+	done := make(chan struct{})
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		handler.ServeHTTP(writer, req.WithContext(ctx))
+		close(done)
+	}()
+
+	select {
+	case p := <-panicked:
+		panic(p)
+	case <-done:
+		writer.lock.Lock()
+		defer writer.lock.Unlock()
+
+		for key, values := range writer.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(writer.status)
+		_, _ = w.Write(writer.body.Bytes())
+		return false
+	case <-ctx.Done():
+		writer.lock.Lock()
+		defer writer.lock.Unlock()
+
+		writer.timedOut = true
+		http.Error(w, fmt.Sprintf("request wasn't handled within %s", timeout), http.StatusRequestTimeout)
+		return true
+	}
+}
+
+// timeoutWriter is an http.ResponseWriter that buffers the response until the
+// request is handled, and drops it if the request times out first
+type timeoutWriter struct {
+	lock     sync.Mutex
+	header   http.Header
+	status   int
+	wrote    bool
+	timedOut bool
+	body     bytes.Buffer
+}
+
+func (w *timeoutWriter) Header() http.Header { return w.header }
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.wrote = true
+	return w.body.Write(b)
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.timedOut || w.wrote {
+		return
+	}
+	w.status = status
+	w.wrote = true
+}
@@ -0,0 +1,155 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestParseRequestLimitRules(t *testing.T) {
+	rules, err := ParseRequestLimitRules("*=30s:1024,/ext/keystore=2m:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []RequestLimitRule{
+		{Endpoint: "*", Timeout: 30 * time.Second, MaxBodySize: 1024},
+		{Endpoint: "keystore", Timeout: 2 * time.Minute},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("parsed %d rules but expected %d", len(rules), len(expected))
+	}
+	for i, rule := range rules {
+		if rule != expected[i] {
+			t.Fatalf("parsed %+v but expected %+v", rule, expected[i])
+		}
+	}
+
+	if rules, err := ParseRequestLimitRules(""); err != nil || len(rules) != 0 {
+		t.Fatalf("an empty string should have no rules")
+	}
+	for _, invalid := range []string{"keystore", "=1s:1", "keystore=1s", "keystore=-1s:1", "keystore=1s:-1", "keystore=a:1", "keystore=1s:a"} {
+		if _, err := ParseRequestLimitRules(invalid); err == nil {
+			t.Fatalf("should have failed to parse %q", invalid)
+		}
+	}
+}
+
+func TestRequestLimiterRule(t *testing.T) {
+	r := &requestLimiter{metrics: newMetrics(), rules: []RequestLimitRule{
+		{Endpoint: "bc/X", Timeout: time.Second},
+		{Endpoint: "*", Timeout: time.Minute},
+		{Endpoint: "bc/X/wallet", Timeout: time.Hour},
+	}}
+	for endpoint, expected := range map[string]time.Duration{
+		"bc/X":          time.Second,
+		"bc/X/events":   time.Second,
+		"bc/X/wallet":   time.Hour,
+		"bc/XY":         time.Minute,
+		"keystore":      time.Minute,
+		"bc/X/wallet/a": time.Hour,
+	} {
+		if rule := r.rule(endpoint); rule == nil || rule.Timeout != expected {
+			t.Fatalf("expected the rule with timeout %s to apply to %s but got %+v", expected, endpoint, rule)
+		}
+	}
+	if rule := (&requestLimiter{}).rule("keystore"); rule != nil {
+		t.Fatalf("no rule should apply without rules but got %+v", rule)
+	}
+}
+
+func TestRequestLimitHandler(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	r := &requestLimiter{metrics: newMetrics(), rules: []RequestLimitRule{
+		{Endpoint: "keystore", MaxBodySize: 4},
+		{Endpoint: "bc/X", Timeout: 10 * time.Millisecond},
+	}}
+	handler := r.wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "/slow") {
+			select {
+			case <-release:
+			case <-req.Context().Done():
+			}
+			return
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write(body)
+	}))
+
+	tests := []struct {
+		name, path, body string
+		status           int
+	}{
+		{name: "small body", path: "/ext/keystore", body: "abcd", status: http.StatusAccepted},
+		{name: "large body", path: "/ext/keystore", body: "abcde", status: http.StatusRequestEntityTooLarge},
+		{name: "unlimited endpoint", path: "/ext/admin", body: "abcde", status: http.StatusAccepted},
+		{name: "fast request", path: "/ext/bc/X", body: "abcde", status: http.StatusAccepted},
+		{name: "slow request", path: "/ext/bc/X/slow", status: http.StatusRequestTimeout},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+			// The body's length isn't given, so it has to be read to be limited
+			request.ContentLength = -1
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, request)
+
+			if writer.Code != test.status {
+				t.Fatalf("expected status %d but got %d", test.status, writer.Code)
+			}
+			if test.status == http.StatusAccepted && writer.Body.String() != test.body {
+				t.Fatalf("expected body %q but got %q", test.body, writer.Body.String())
+			}
+		})
+	}
+
+	if count := testutil.ToFloat64(r.metrics.tooLarge.WithLabelValues("keystore")); count != 1 {
+		t.Fatalf("expected 1 request to be counted as too large but counted %v", count)
+	}
+	if count := testutil.ToFloat64(r.metrics.timedOut.WithLabelValues("bc/X")); count != 1 {
+		t.Fatalf("expected 1 request to be counted as timed out but counted %v", count)
+	}
+}
+
+func TestRequestLimitHandlerAliases(t *testing.T) {
+	s := &Server{router: newRouter()}
+	if err := s.router.AddAlias(baseURL+"/bc/chainID", baseURL+"/bc/X"); err != nil {
+		t.Fatal(err)
+	}
+	r := &requestLimiter{metrics: newMetrics(), canonical: s.CanonicalEndpoint, rules: []RequestLimitRule{
+		{Endpoint: "bc/chainID", MaxBodySize: 4},
+		{Endpoint: "bc/X/wallet", MaxBodySize: 2},
+	}}
+	handler := r.wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusAccepted) }))
+
+	tests := []struct {
+		path, body string
+		status     int
+	}{
+		{path: "/ext/bc/chainID", body: "abcd", status: http.StatusAccepted},
+		{path: "/ext/bc/X", body: "abcde", status: http.StatusRequestEntityTooLarge},
+		{path: "/ext/bc/X/events", body: "abcde", status: http.StatusRequestEntityTooLarge},
+		{path: "/ext/bc/chainID/wallet", body: "abc", status: http.StatusRequestEntityTooLarge},
+		{path: "/ext/bc/X/wallet", body: "ab", status: http.StatusAccepted},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		if writer.Code != test.status {
+			t.Fatalf("request to %s should have returned %d but returned %d", test.path, test.status, writer.Code)
+		}
+	}
+	if count := testutil.ToFloat64(r.metrics.tooLarge.WithLabelValues("bc/chainID")); count != 2 {
+		t.Fatalf("expected 2 requests to be counted as too large but counted %v", count)
+	}
+}
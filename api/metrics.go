@@ -0,0 +1,61 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// metricsNamespace is the namespace of the API server's metrics
+const metricsNamespace = "gecko_api"
+
+// metrics counts the requests that the server's limits rejected. Requests are
+// counted by the endpoint, and method, of the rule that rejected them, rather
+// than by the endpoint they were made to, so that there are only as many
+// counters as there are rules.
+type metrics struct {
+	rateLimited, tooLarge, timedOut *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		rateLimited: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "rate_limited",
+				Help:      "Number of requests answered with 429 Too Many Requests",
+			},
+			[]string{"endpoint", "method"},
+		),
+		tooLarge: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "too_large",
+				Help:      "Number of requests answered with 413 Request Entity Too Large",
+			},
+			[]string{"endpoint"},
+		),
+		timedOut: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "timed_out",
+				Help:      "Number of requests answered with 408 Request Timeout",
+			},
+			[]string{"endpoint"},
+		),
+	}
+}
+
+// Register the metrics with [registerer]
+func (m *metrics) Register(registerer prometheus.Registerer) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.rateLimited),
+		registerer.Register(m.tooLarge),
+		registerer.Register(m.timedOut),
+	)
+	return errs.Err
+}
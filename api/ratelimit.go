@@ -0,0 +1,311 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+const (
+	// allEndpoints is the endpoint of a rate limit rule that applies to every
+	// endpoint
+	allEndpoints = "*"
+
+	// methodSeparator separates the endpoint of a rate limit rule from the
+	// JSON-RPC method it applies to
+	methodSeparator = "#"
+
+	// rateLimitedLogWindow is how long after an IP's requests are rate limited
+	// by a rule that they aren't logged again
+	rateLimitedLogWindow = time.Minute
+
+	// pruneFrequency is how often the buckets of IPs that haven't made
+	// requests recently are forgotten
+	pruneFrequency = time.Minute
+)
+
+// RateLimitRule limits how often each IP may make requests to an endpoint
+type RateLimitRule struct {
+	// Endpoint the rule applies to, along with the endpoints under it, such as
+	// "bc/X" for "/ext/bc/X/wallet". "*" applies to every endpoint.
+	Endpoint string
+
+	// JSON-RPC method the rule applies to, such as "keystore.exportUser". If
+	// empty, the rule applies to every request to the endpoint. Otherwise, it
+	// only applies to calls of the method, and each IP has a separate limit
+	// on them.
+	Method string
+
+	// Requests per second each IP may make, on average
+	Rate float64
+
+	// Requests each IP may make at once after not making any for a while
+	Burst int
+}
+
+// ParseRateLimitRules parses rules of the form
+// "endpoint=rate:burst,endpoint#method=rate:burst", such as
+// "*=20:40,keystore=1:5,keystore#keystore.exportUser=0.1:1"
+func ParseRateLimitRules(rules string) ([]RateLimitRule, error) {
+	parsed := []RateLimitRule(nil)
+	if rules == "" {
+		return parsed, nil
+	}
+	for _, entry := range strings.Split(rules, ",") {
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) != 2 || fields[0] == "" {
+			return nil, fmt.Errorf("rate limit %q should be of the form endpoint=rate:burst", entry)
+		}
+		limits := strings.SplitN(fields[1], ":", 2)
+		if len(limits) != 2 {
+			return nil, fmt.Errorf("rate limit of %s should be of the form rate:burst but is %q", fields[0], fields[1])
+		}
+		rate, err := strconv.ParseFloat(limits[0], 64)
+		if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+			return nil, fmt.Errorf("rate limit of %s should have a positive rate but has %q", fields[0], limits[0])
+		}
+		burst, err := strconv.Atoi(limits[1])
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("rate limit of %s should have a burst of at least 1 but has %q", fields[0], limits[1])
+		}
+		endpoint, method := fields[0], ""
+		if i := strings.Index(endpoint, methodSeparator); i >= 0 {
+			endpoint, method = endpoint[:i], endpoint[i+len(methodSeparator):]
+			if endpoint == "" || method == "" {
+				return nil, fmt.Errorf("rate limit of %s should be of the form endpoint#method", fields[0])
+			}
+		}
+		parsed = append(parsed, RateLimitRule{
+			Endpoint: normalizeEndpoint(endpoint),
+			Method:   method,
+			Rate:     rate,
+			Burst:    burst,
+		})
+	}
+	return parsed, nil
+}
+
+// bucket holds the requests an IP may make under a rule. It refills at the
+// rule's rate, up to the rule's burst.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter limits the requests each IP makes to each endpoint. A request
+// must be allowed by every rule that applies to its endpoint.
+type rateLimiter struct {
+	log     logging.Logger
+	metrics *metrics
+
+	// canonical, if it isn't nil, returns the endpoint that an endpoint is
+	// an alias of, so requests under an alias are limited by the rules of
+	// the endpoint it's an alias of
+	canonical func(endpoint string) string
+
+	lock  sync.Mutex
+	clock timer.Clock
+	rules []RateLimitRule
+
+	// buckets[i] holds the bucket of each IP under rules[i]
+	// Key: IP
+	buckets   []map[string]*bucket
+	lastPrune time.Time
+}
+
+func newRateLimiter(log logging.Logger, metrics *metrics, rules []RateLimitRule) *rateLimiter {
+	r := &rateLimiter{
+		log:     log,
+		metrics: metrics,
+	}
+	r.setRules(rules)
+	return r
+}
+
+// setRules replaces the rules with [rules]. Every IP starts with a full bucket
+// under the new rules.
+func (r *rateLimiter) setRules(rules []RateLimitRule) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rules = rules
+	r.buckets = make([]map[string]*bucket, len(rules))
+	for i := range r.buckets {
+		r.buckets[i] = make(map[string]*bucket)
+	}
+}
+
+// appliesTo returns true if [rule] applies to requests to [endpoint], whatever
+// method they call
+func (rule RateLimitRule) appliesTo(endpoint string) bool {
+	return rule.Endpoint == allEndpoints || rule.Endpoint == endpoint || strings.HasPrefix(endpoint, rule.Endpoint+"/")
+}
+
+// applies returns true if [rule], or the endpoint its endpoint is an alias of,
+// applies to requests to [endpoint], which is already resolved
+func (r *rateLimiter) applies(rule RateLimitRule, endpoint string) bool {
+	if rule.Endpoint != allEndpoints {
+		rule.Endpoint = resolveEndpoint(r.canonical, rule.Endpoint)
+	}
+	return rule.appliesTo(endpoint)
+}
+
+// limits returns true if a rule applies to requests to [endpoint]
+func (r *rateLimiter) limits(endpoint string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, rule := range r.rules {
+		if r.applies(rule, endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// cost returns how many of the requests [rule] allows are taken by a request
+// that makes the JSON-RPC calls [methods]. Each call of a batch is counted as
+// its own request.
+func (rule RateLimitRule) cost(methods []string) int {
+	if rule.Method == "" {
+		if len(methods) == 0 {
+			return 1
+		}
+		return len(methods)
+	}
+	calls := 0
+	for _, method := range methods {
+		if strings.EqualFold(rule.Method, method) {
+			calls++
+		}
+	}
+	return calls
+}
+
+// allow returns true if [ip] may make a request to [endpoint] that makes the
+// JSON-RPC calls [methods] now. Otherwise, it returns how long until it may,
+// and the rule that limited the request the longest. A batch with more calls
+// than a rule's burst is never allowed.
+func (r *rateLimiter) allow(ip, endpoint string, methods ...string) (bool, time.Duration, RateLimitRule) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.clock.Time()
+	if now.Sub(r.lastPrune) >= pruneFrequency {
+		r.prune(now)
+	}
+
+	applied := []*bucket(nil)
+	costs := []float64(nil)
+	wait := time.Duration(0)
+	limitedBy := RateLimitRule{}
+	for i, rule := range r.rules {
+		if !r.applies(rule, endpoint) {
+			continue
+		}
+		cost := float64(rule.cost(methods))
+		if cost == 0 {
+			continue
+		}
+		b, exists := r.buckets[i][ip]
+		if !exists {
+			b = &bucket{tokens: float64(rule.Burst)}
+			r.buckets[i][ip] = b
+		}
+		b.tokens = math.Min(float64(rule.Burst), b.tokens+rule.Rate*now.Sub(b.last).Seconds())
+		b.last = now
+		if b.tokens < cost {
+			if ruleWait := time.Duration((cost - b.tokens) / rule.Rate * float64(time.Second)); ruleWait > wait {
+				wait, limitedBy = ruleWait, rule
+			}
+		}
+		applied = append(applied, b)
+		costs = append(costs, cost)
+	}
+	if wait > 0 {
+		return false, wait, limitedBy
+	}
+	// Only take from the buckets once every rule allows the request
+	for i, b := range applied {
+		b.tokens -= costs[i]
+	}
+	return true, 0, RateLimitRule{}
+}
+
+// prune forgets the buckets that have refilled, since they're the same as new
+// buckets. Assumes the lock is held.
+func (r *rateLimiter) prune(now time.Time) {
+	for i, rule := range r.rules {
+		for ip, b := range r.buckets[i] {
+			if b.tokens+rule.Rate*now.Sub(b.last).Seconds() >= float64(rule.Burst) {
+				delete(r.buckets[i], ip)
+			}
+		}
+	}
+	r.lastPrune = now
+}
+
+// wrap [handler] so requests that exceed the limits are answered with 429 Too
+// Many Requests, and a Retry-After header of when to try again. The limits
+// apply to the IP the request was received from. Forwarding headers, such as
+// X-Forwarded-For, are ignored since clients can forge them. The body of a
+// request is only read, to find the JSON-RPC methods it calls, if a rule
+// applies to its endpoint. Each call of a batch is limited as its own request.
+// Requests under an alias are limited as requests to the endpoint it's an
+// alias of, so aliases don't have limits of their own.
+func (r *rateLimiter) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ip, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			ip = req.RemoteAddr
+		}
+		endpoint, methods := resolveEndpoint(r.canonical, normalizeEndpoint(req.URL.Path)), []string(nil)
+		if r.limits(endpoint) {
+			methods = jsonRPCMethods(req)
+		}
+		if allowed, wait, rule := r.allow(ip, endpoint, methods...); !allowed {
+			r.metrics.rateLimited.WithLabelValues(rule.Endpoint, rule.Method).Inc()
+			r.log.Every(rateLimitedLogWindow).Info("rate limited requests from %s under the limit of %s%s", ip, rule.Endpoint, methodSuffix(rule.Method))
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// methodSuffix returns the suffix that names [method] after the endpoint of a
+// rate limit rule, or "" if the rule applies to every method
+func methodSuffix(method string) string {
+	if method == "" {
+		return ""
+	}
+	return methodSeparator + method
+}
+
+// normalizeEndpoint returns [endpoint] without the API's base URL or
+// surrounding slashes, so "/ext/bc/X/" and "bc/X" are the same endpoint
+func normalizeEndpoint(endpoint string) string {
+	endpoint = strings.TrimPrefix(endpoint, baseURL+"/")
+	return strings.Trim(endpoint, "/")
+}
+
+// resolveEndpoint returns the endpoint that [endpoint] is an alias of,
+// according to [canonical], or [endpoint] if [canonical] is nil
+func resolveEndpoint(canonical func(endpoint string) string, endpoint string) string {
+	if canonical == nil {
+		return endpoint
+	}
+	return canonical(endpoint)
+}
@@ -0,0 +1,254 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestParseRateLimitRules(t *testing.T) {
+	rules, err := ParseRateLimitRules("*=20:40,/ext/keystore=0.5:1,keystore#keystore.exportUser=0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []RateLimitRule{
+		{Endpoint: "*", Rate: 20, Burst: 40},
+		{Endpoint: "keystore", Rate: 0.5, Burst: 1},
+		{Endpoint: "keystore", Method: "keystore.exportUser", Rate: 0.1, Burst: 1},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("parsed %d rules but expected %d", len(rules), len(expected))
+	}
+	for i, rule := range rules {
+		if rule != expected[i] {
+			t.Fatalf("parsed %+v but expected %+v", rule, expected[i])
+		}
+	}
+
+	if rules, err := ParseRateLimitRules(""); err != nil || len(rules) != 0 {
+		t.Fatalf("an empty string should have no rules")
+	}
+	for _, invalid := range []string{"keystore", "=1:1", "keystore=1", "keystore=0:1", "keystore=1:0", "keystore=a:1", "keystore#=1:1", "#keystore.exportUser=1:1"} {
+		if _, err := ParseRateLimitRules(invalid); err == nil {
+			t.Fatalf("should have failed to parse %q", invalid)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	r := newRateLimiter(logging.NoLog{}, newMetrics(), []RateLimitRule{
+		{Endpoint: "*", Rate: 10, Burst: 10},
+		{Endpoint: "keystore", Rate: 1, Burst: 2},
+	})
+	now := time.Unix(1000, 0)
+	r.clock.Set(now)
+
+	for i := 0; i < 2; i++ {
+		if allowed, _, _ := r.allow("1.2.3.4", "keystore", ""); !allowed {
+			t.Fatalf("request %d should have been within the burst", i)
+		}
+	}
+	allowed, wait, _ := r.allow("1.2.3.4", "keystore", "")
+	if allowed || wait != time.Second {
+		t.Fatalf("request should have had to wait a second but was allowed %v after %s", allowed, wait)
+	}
+
+	// Limits are per IP and per endpoint
+	if allowed, _, _ := r.allow("5.6.7.8", "keystore", ""); !allowed {
+		t.Fatalf("another IP should have its own limit")
+	}
+	if allowed, _, _ := r.allow("1.2.3.4", "admin", ""); !allowed {
+		t.Fatalf("another endpoint should have its own limit")
+	}
+
+	r.clock.Set(now.Add(time.Second))
+	if allowed, _, _ := r.allow("1.2.3.4", "keystore/sub", ""); !allowed {
+		t.Fatalf("request should have been allowed once the bucket refilled")
+	}
+
+	// The keystore request took 1 of the 10 requests that every endpoint
+	// allows at once
+	for i := 0; i < 9; i++ {
+		if allowed, _, _ := r.allow("1.2.3.4", "admin", ""); !allowed {
+			t.Fatalf("admin request %d should have been within the burst", i)
+		}
+	}
+	if allowed, _, _ := r.allow("1.2.3.4", "admin", ""); allowed {
+		t.Fatalf("rule for every endpoint should have limited the request")
+	}
+
+	r.clock.Set(now.Add(time.Hour))
+	r.prune(r.clock.Time())
+	for i, buckets := range r.buckets {
+		if len(buckets) != 0 {
+			t.Fatalf("rule %d should have forgotten the refilled buckets", i)
+		}
+	}
+}
+
+func TestRateLimitHandler(t *testing.T) {
+	r := newRateLimiter(logging.NoLog{}, newMetrics(), []RateLimitRule{{Endpoint: "keystore", Rate: 0.25, Burst: 1}})
+	handler := r.wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }))
+
+	for _, expected := range []struct {
+		code  int
+		retry string
+	}{
+		{http.StatusOK, ""},
+		{http.StatusTooManyRequests, "4"},
+	} {
+		request := httptest.NewRequest(http.MethodPost, "/ext/keystore", nil)
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		if writer.Code != expected.code || writer.Header().Get("Retry-After") != expected.retry {
+			t.Fatalf("should have returned %d with Retry-After %q but returned %d with %q",
+				expected.code, expected.retry, writer.Code, writer.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestRateLimitHandlerAliases(t *testing.T) {
+	s := &Server{router: newRouter()}
+	if err := s.router.AddAlias(baseURL+"/bc/chainID", baseURL+"/bc/X"); err != nil {
+		t.Fatal(err)
+	}
+	r := newRateLimiter(logging.NoLog{}, newMetrics(), []RateLimitRule{{Endpoint: "bc/chainID", Rate: 0.25, Burst: 1}})
+	r.canonical = s.CanonicalEndpoint
+	handler := r.wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }))
+
+	// The alias shares the limit of the endpoint it's an alias of, whichever
+	// path the rule and the requests use
+	for _, expected := range []struct {
+		path string
+		code int
+	}{
+		{"/ext/bc/X", http.StatusOK},
+		{"/ext/bc/chainID", http.StatusTooManyRequests},
+		{"/ext/bc/X/wallet", http.StatusTooManyRequests},
+	} {
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, httptest.NewRequest(http.MethodPost, expected.path, nil))
+		if writer.Code != expected.code {
+			t.Fatalf("request to %s should have returned %d but returned %d", expected.path, expected.code, writer.Code)
+		}
+	}
+
+	r.setRules([]RateLimitRule{{Endpoint: "bc/X", Rate: 0.25, Burst: 1}})
+	for _, expected := range []struct {
+		path string
+		code int
+	}{
+		{"/ext/bc/chainID", http.StatusOK},
+		{"/ext/bc/X", http.StatusTooManyRequests},
+	} {
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, httptest.NewRequest(http.MethodPost, expected.path, nil))
+		if writer.Code != expected.code {
+			t.Fatalf("request to %s should have returned %d but returned %d", expected.path, expected.code, writer.Code)
+		}
+	}
+}
+
+func TestRateLimiterMethods(t *testing.T) {
+	r := newRateLimiter(logging.NoLog{}, newMetrics(), []RateLimitRule{
+		{Endpoint: "keystore", Rate: 10, Burst: 10},
+		{Endpoint: "keystore", Method: "keystore.exportUser", Rate: 1, Burst: 1},
+	})
+	r.clock.Set(time.Unix(1000, 0))
+
+	if r.limits("admin") || !r.limits("keystore") {
+		t.Fatalf("only the keystore's requests should be limited")
+	}
+	if allowed, _, _ := r.allow("1.2.3.4", "keystore", "keystore.exportUser"); !allowed {
+		t.Fatalf("first call of the method should have been allowed")
+	}
+	// Methods are matched regardless of case, since the services' methods may
+	// be called either way
+	allowed, wait, rule := r.allow("1.2.3.4", "keystore", "keystore.ExportUser")
+	if allowed || wait != time.Second || rule.Method != "keystore.exportUser" {
+		t.Fatalf("second call of the method should have been limited by its rule, but was allowed %v after %s by %+v", allowed, wait, rule)
+	}
+	for i := 0; i < 8; i++ {
+		if allowed, _, _ := r.allow("1.2.3.4", "keystore", "keystore.listUsers"); !allowed {
+			t.Fatalf("call %d of another method should have been allowed", i)
+		}
+	}
+}
+
+func TestRateLimitHandlerMethods(t *testing.T) {
+	r := newRateLimiter(logging.NoLog{}, newMetrics(), []RateLimitRule{
+		{Endpoint: "keystore", Method: "keystore.exportUser", Rate: 0.25, Burst: 1},
+	})
+	handler := r.wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// The body is still readable after the limiter read the method
+		if _, err := ioutil.ReadAll(req.Body); err != nil || req.ContentLength == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, test := range []struct {
+		method string
+		code   int
+	}{
+		{"keystore.exportUser", http.StatusOK},
+		{"keystore.exportUser", http.StatusTooManyRequests},
+		{"keystore.listUsers", http.StatusOK},
+	} {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + test.method + `","params":{}}`
+		request := httptest.NewRequest(http.MethodPost, "/ext/keystore", strings.NewReader(body))
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		if writer.Code != test.code {
+			t.Fatalf("call of %s should have returned %d but returned %d", test.method, test.code, writer.Code)
+		}
+	}
+
+	if count := testutil.ToFloat64(r.metrics.rateLimited.WithLabelValues("keystore", "keystore.exportUser")); count != 1 {
+		t.Fatalf("expected 1 request to be counted as rate limited but counted %v", count)
+	}
+}
+
+func TestRateLimitHandlerBatches(t *testing.T) {
+	r := newRateLimiter(logging.NoLog{}, newMetrics(), []RateLimitRule{
+		{Endpoint: "keystore", Rate: 0.01, Burst: 5},
+		{Endpoint: "keystore", Method: "keystore.exportUser", Rate: 0.01, Burst: 1},
+	})
+	handler := r.wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func(method string) string {
+		return `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":{}}`
+	}
+
+	for _, test := range []struct {
+		body string
+		code int
+	}{
+		// Each call of a batch is charged against its method's rule
+		{"[" + call("keystore.exportUser") + "," + call("keystore.exportUser") + "]", http.StatusTooManyRequests},
+		{"[" + call("keystore.exportUser") + "," + call("keystore.listUsers") + "]", http.StatusOK},
+		{call("keystore.exportUser"), http.StatusTooManyRequests},
+		// Each call of a batch is counted as its own request to the endpoint
+		{"[" + call("keystore.listUsers") + "," + call("keystore.listUsers") + "," + call("keystore.listUsers") + "]", http.StatusOK},
+		{"[" + call("keystore.listUsers") + "," + call("keystore.listUsers") + "]", http.StatusTooManyRequests},
+	} {
+		request := httptest.NewRequest(http.MethodPost, "/ext/keystore", strings.NewReader(test.body))
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		if writer.Code != test.code {
+			t.Fatalf("request %s should have returned %d but returned %d", test.body, test.code, writer.Code)
+		}
+	}
+}
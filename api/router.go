@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/gorilla/mux"
@@ -15,6 +17,7 @@ import (
 var (
 	errUnknownBaseURL  = errors.New("unknown base url")
 	errUnknownEndpoint = errors.New("unknown endpoint")
+	errDisabled        = errors.New("this API is disabled")
 )
 
 type router struct {
@@ -25,6 +28,10 @@ type router struct {
 	reservedRoutes map[string]bool                    // Reserves routes so that there can't be alias that conflict
 	aliases        map[string][]string                // Maps a route to a set of reserved routes
 	routes         map[string]map[string]http.Handler // Maps routes to a handler
+
+	// Routes that aren't served. A route's aliases aren't served either.
+	disabledLock sync.RWMutex
+	disabled     map[string]bool
 }
 
 func newRouter() *router {
@@ -33,6 +40,7 @@ func newRouter() *router {
 		reservedRoutes: make(map[string]bool),
 		aliases:        make(map[string][]string),
 		routes:         make(map[string]map[string]http.Handler),
+		disabled:       make(map[string]bool),
 	}
 }
 
@@ -87,7 +95,7 @@ func (r *router) forceAddRouter(base, endpoint string, handler http.Handler) err
 
 	endpoints[endpoint] = handler
 	r.routes[base] = endpoints
-	r.router.Handle(url, handler)
+	r.router.Handle(url, r.toggle(base, handler))
 
 	var err error
 	if aliases, exists := r.aliases[base]; exists {
@@ -130,3 +138,178 @@ func (r *router) AddAlias(base string, aliases ...string) error {
 	}
 	return err
 }
+
+// RemoveAlias removes [alias] as an alias of [base], along with the routes the
+// alias added
+func (r *router) RemoveAlias(base, alias string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.routeLock.Lock()
+	defer r.routeLock.Unlock()
+
+	aliases := r.aliases[base]
+	index := -1
+	for i, existing := range aliases {
+		if existing == alias {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("%s isn't an alias of %s", alias, base)
+	}
+
+	if len(aliases) == 1 {
+		delete(r.aliases, base)
+	} else {
+		remaining := make([]string, 0, len(aliases)-1)
+		remaining = append(remaining, aliases[:index]...)
+		r.aliases[base] = append(remaining, aliases[index+1:]...)
+	}
+	delete(r.reservedRoutes, alias)
+	r.removeRoutes(alias)
+	r.rebuild()
+	return nil
+}
+
+// RemoveRouter removes the routes of [base], and the routes its aliases added.
+// The aliases remain, so routes added to [base] later are routed to from them.
+func (r *router) RemoveRouter(base string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.routeLock.Lock()
+	defer r.routeLock.Unlock()
+
+	r.removeRoutes(base)
+	r.rebuild()
+}
+
+// rebuild the mux router from the routes. Routes can't be removed from the mux
+// router, so it's rebuilt whenever they are.
+func (r *router) rebuild() {
+	r.router = mux.NewRouter()
+	for base, endpoints := range r.routes {
+		for endpoint, handler := range endpoints {
+			r.router.Handle(base+endpoint, r.toggle(base, handler))
+		}
+	}
+}
+
+// removeRoutes removes the routes of [base], and of its aliases
+func (r *router) removeRoutes(base string) {
+	delete(r.routes, base)
+	for _, alias := range r.aliases[base] {
+		r.removeRoutes(alias)
+	}
+}
+
+// Disable stops serving the routes of [base], or of the route [base] is an
+// alias of, until they're enabled again, and returns the route disabled.
+// Routes can be disabled before they're added.
+func (r *router) Disable(base string) string {
+	r.routeLock.Lock()
+	base = r.resolve(base)
+	r.routeLock.Unlock()
+
+	r.disabledLock.Lock()
+	defer r.disabledLock.Unlock()
+
+	r.disabled[base] = true
+	return base
+}
+
+// Enable serves the routes of [base], or of the route [base] is an alias of,
+// again, and returns the route enabled
+func (r *router) Enable(base string) string {
+	r.routeLock.Lock()
+	base = r.resolve(base)
+	r.routeLock.Unlock()
+
+	r.disabledLock.Lock()
+	defer r.disabledLock.Unlock()
+
+	delete(r.disabled, base)
+	return base
+}
+
+// Disabled returns the routes that are disabled, sorted
+func (r *router) Disabled() []string {
+	r.disabledLock.RLock()
+	defer r.disabledLock.RUnlock()
+
+	bases := make([]string, 0, len(r.disabled))
+	for base := range r.disabled {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+	return bases
+}
+
+func (r *router) isDisabled(base string) bool {
+	r.disabledLock.RLock()
+	defer r.disabledLock.RUnlock()
+
+	return r.disabled[base]
+}
+
+// resolve returns the route [alias] is an alias of, or [alias] if it isn't
+// an alias
+// Assumes [r.routeLock] is held
+func (r *router) resolve(alias string) string {
+	for base, aliases := range r.aliases {
+		for _, existing := range aliases {
+			if existing == alias {
+				return base
+			}
+		}
+	}
+	return alias
+}
+
+// Canonical returns [path] with the route it's under replaced by the route
+// that route is an alias of, so that the routes of a handler and of its
+// aliases are the same path. Returns [path] if it isn't under an alias.
+func (r *router) Canonical(path string) string {
+	r.routeLock.Lock()
+	defer r.routeLock.Unlock()
+
+	// An alias may be of another alias, but each alias is of one route, so
+	// following more aliases than there are means they loop
+	for i := 0; i <= len(r.reservedRoutes); i++ {
+		matched, base := "", ""
+		for route, aliases := range r.aliases {
+			for _, alias := range aliases {
+				if len(alias) > len(matched) && (path == alias || strings.HasPrefix(path, alias+"/")) {
+					matched, base = alias, route
+				}
+			}
+		}
+		if matched == "" {
+			break
+		}
+		path = base + path[len(matched):]
+	}
+	return path
+}
+
+// toggle returns [handler], wrapped to not serve requests while the route
+// [base], or the route it's an alias of, is disabled
+// Assumes [r.routeLock] is held
+func (r *router) toggle(base string, handler http.Handler) http.Handler {
+	return &toggleHandler{router: r, base: r.resolve(base), handler: handler}
+}
+
+// toggleHandler serves requests to the route [base] unless it's disabled
+type toggleHandler struct {
+	router  *router
+	base    string
+	handler http.Handler
+}
+
+func (h *toggleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.router.isDisabled(h.base) {
+		http.Error(w, errDisabled.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	h.handler.ServeHTTP(w, r)
+}
@@ -5,6 +5,7 @@ package api
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -67,3 +68,132 @@ func TestBlock(t *testing.T) {
 		t.Fatalf("Permanently locked %s", "1")
 	}
 }
+
+func TestRemoveAlias(t *testing.T) {
+	r := newRouter()
+
+	if err := r.AddAlias("1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddAlias("2", "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	handler1 := &testHandler{}
+	if err := r.AddRouter("1", "", handler1); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := r.routes["3"][""]; !exists {
+		t.Fatalf("Should have added %s", "3")
+	}
+
+	if err := r.RemoveAlias("1", "3"); err == nil {
+		t.Fatalf("%s isn't an alias of %s", "3", "1")
+	}
+	if err := r.RemoveAlias("1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := r.routes["2"]; exists {
+		t.Fatalf("Should have removed %s", "2")
+	}
+	if _, exists := r.routes["3"]; exists {
+		t.Fatalf("Should have removed %s", "3")
+	}
+	if _, exists := r.routes["1"][""]; !exists {
+		t.Fatalf("Shouldn't have removed %s", "1")
+	}
+
+	// The alias is no longer reserved
+	if err := r.AddRouter("2", "", handler1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoveRouter(t *testing.T) {
+	r := newRouter()
+
+	if err := r.AddAlias("1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	handler1 := &testHandler{}
+	if err := r.AddRouter("1", "", handler1); err != nil {
+		t.Fatal(err)
+	}
+
+	r.RemoveRouter("1")
+	if _, err := r.GetHandler("1", ""); err == nil {
+		t.Fatalf("Should have removed %s", "1")
+	}
+	if _, err := r.GetHandler("2", ""); err == nil {
+		t.Fatalf("Should have removed the route of alias %s", "2")
+	}
+
+	handler2 := &testHandler{}
+	if err := r.AddRouter("1", "", handler2); err != nil {
+		t.Fatal(err)
+	}
+	if handler, err := r.GetHandler("2", ""); err != nil {
+		t.Fatal(err)
+	} else if handler != handler2 {
+		t.Fatalf("Alias %s should route to the new handler", "2")
+	}
+}
+
+func TestDisable(t *testing.T) {
+	r := newRouter()
+
+	if err := r.AddAlias("/1", "/2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddRouter("/1", "", &testHandler{}); err != nil {
+		t.Fatal(err)
+	}
+
+	serve := func(url string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w.Code
+	}
+
+	if disabled := r.Disable("/2"); disabled != "/1" {
+		t.Fatalf("disabling alias %s should have disabled %s but disabled %s", "/2", "/1", disabled)
+	}
+	for _, url := range []string{"/1", "/2"} {
+		if code := serve(url); code != http.StatusServiceUnavailable {
+			t.Fatalf("%s should have been disabled but returned %d", url, code)
+		}
+	}
+	if disabled := r.Disabled(); len(disabled) != 1 || disabled[0] != "/1" {
+		t.Fatalf("wrong routes disabled: %v", disabled)
+	}
+
+	r.Enable("/1")
+	for _, url := range []string{"/1", "/2"} {
+		if code := serve(url); code != http.StatusOK {
+			t.Fatalf("%s should have been enabled but returned %d", url, code)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	r := newRouter()
+
+	if err := r.AddAlias("/ext/bc/abc", "/ext/bc/X"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddAlias("/ext/bc/X", "/ext/wallet"); err != nil {
+		t.Fatal(err)
+	}
+	for path, expected := range map[string]string{
+		"/ext/bc/X":        "/ext/bc/abc",
+		"/ext/bc/X/wallet": "/ext/bc/abc/wallet",
+		"/ext/wallet/rpc":  "/ext/bc/abc/rpc",
+		"/ext/bc/abc":      "/ext/bc/abc",
+		"/ext/bc/XY":       "/ext/bc/XY",
+		"/ext/keystore":    "/ext/keystore",
+	} {
+		if canonical := r.Canonical(path); canonical != expected {
+			t.Fatalf("%s should have resolved to %s but resolved to %s", path, expected, canonical)
+		}
+	}
+}
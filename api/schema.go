@@ -0,0 +1,102 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// schemaEndpoint is the route the descriptions of the API's services are
+// served at
+const schemaEndpoint = baseURL + "/schema"
+
+// describer is a handler whose JSON-RPC methods can be described
+type describer interface {
+	Describe(title string) *cjson.Document
+}
+
+// schemas serves the OpenRPC documents describing the JSON-RPC services of
+// each route, so that clients can be generated from them
+type schemas struct {
+	lock sync.RWMutex
+	// Key: Route
+	// Value: The route's handler
+	describers map[string]describer
+}
+
+func newSchemas() *schemas { return &schemas{describers: make(map[string]describer)} }
+
+// add the service at [route], if its methods can be described
+func (s *schemas) add(route string, handler interface{}) {
+	d, ok := handler.(describer)
+	if !ok {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.describers[route] = d
+}
+
+// remove the services at [base], and at the routes under it
+func (s *schemas) remove(base string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for route := range s.describers {
+		if route == base || strings.HasPrefix(route, base+"/") {
+			delete(s.describers, route)
+		}
+	}
+}
+
+// methods returns the names of the JSON-RPC methods of the service at [route]
+func (s *schemas) methods(route string) []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	d, ok := s.describers[route]
+	if !ok {
+		return nil
+	}
+	doc := d.Describe(route)
+	methods := make([]string, len(doc.Methods))
+	for i, method := range doc.Methods {
+		methods[i] = method.Name
+	}
+	return methods
+}
+
+// ServeHTTP writes the documents of every route, keyed by route, or the
+// document of the route given by the "route" query parameter
+func (s *schemas) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var reply interface{}
+	if route := r.URL.Query().Get("route"); route != "" {
+		d, ok := s.describers[route]
+		if !ok {
+			http.Error(w, "no service is served at "+route, http.StatusNotFound)
+			return
+		}
+		reply = d.Describe(route)
+	} else {
+		docs := make(map[string]*cjson.Document, len(s.describers))
+		for route, d := range s.describers {
+			docs[route] = d.Describe(route)
+		}
+		reply = docs
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// The response was already started, so an error can't be reported
+	_ = json.NewEncoder(w).Encode(reply)
+}
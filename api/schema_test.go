@@ -0,0 +1,49 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+func TestSchema(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+
+	newServer := cjson.NewServer()
+	newServer.RegisterCodec(cjson.NewCodec(), "application/json")
+	newServer.RegisterService(&Service{}, "test")
+	if err := s.AddRoute(&common.HTTPHandler{Handler: newServer}, new(sync.RWMutex), "test", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	writer := httptest.NewRecorder()
+	s.handler().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, schemaEndpoint, nil))
+	docs := map[string]*cjson.Document{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &docs); err != nil {
+		t.Fatal(err)
+	}
+	doc, ok := docs["/ext/test"]
+	if len(docs) != 1 || !ok {
+		t.Fatalf("should have described the route's service but described %v", docs)
+	}
+	if len(doc.Methods) != 2 || doc.Methods[0].Name != "test.call" || doc.Methods[1].Name != "test.echo" {
+		t.Fatalf("wrong methods described: %+v", doc.Methods)
+	}
+
+	writer = httptest.NewRecorder()
+	s.handler().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, schemaEndpoint+"?route=/ext/unknown", nil))
+	if writer.Code != http.StatusNotFound {
+		t.Fatalf("should have failed to describe an unknown route but returned %d", writer.Code)
+	}
+}
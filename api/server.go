@@ -4,54 +4,331 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
 	"sync"
 
-	"github.com/gorilla/handlers"
-
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/cors"
 
+	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/wrappers"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
 )
 
 const baseURL = "/ext"
 
 var (
 	errUnknownLockOption = errors.New("invalid lock options")
+	errNotSocket         = errors.New("a file that isn't a socket is at the socket's path")
 )
 
+// CORSConfig is the cross-origin requests that the API server allows. An
+// empty list allows the defaults: every origin, the methods GET, POST and
+// HEAD, and the headers Origin, Accept, Content-Type and X-Requested-With.
+type CORSConfig struct {
+	// Origins that may make requests. "*" allows every origin, and an origin
+	// may contain one "*" wildcard, such as "https://*.example.com".
+	AllowedOrigins []string
+
+	// Methods that requests may use
+	AllowedMethods []string
+
+	// Headers that requests may set. "*" allows every header.
+	AllowedHeaders []string
+}
+
+// Authorizer decides whether requests to the API server may be served
+type Authorizer interface {
+	// Authorize returns nil if [r] may be served, or why it may not be
+	Authorize(r *http.Request) error
+}
+
+// WebSocketHandler is a handler that upgrades requests to websocket
+// connections itself. Otherwise the server does, and passes each call made
+// over a connection to the handler as its own request.
+type WebSocketHandler interface {
+	http.Handler
+
+	// HandlesWebSockets returns true if the handler upgrades requests to
+	// websocket connections itself
+	HandlesWebSockets() bool
+}
+
 // Server maintains the HTTP router
 type Server struct {
-	log     logging.Logger
-	factory logging.Factory
-	router  *router
-	portURL string
+	log        logging.Logger
+	factory    logging.Factory
+	router     *router
+	cors       *cors.Cors
+	limiter    *rateLimiter
+	limits     *requestLimiter
+	metrics    *metrics
+	gzipSize   int
+	wsCalls    bool
+	authorizer Authorizer
+	schemas    *schemas
+	portURL    string
+
+	// The HTTP servers the API is dispatched on, which are shut down together.
+	// The contexts of requests are cancelled when [s.stop] is called, so that
+	// streams of events end when the server shuts down.
+	lock     sync.Mutex
+	servers  []*http.Server
+	certs    []*certReloader
+	stopping bool
+	ctx      context.Context
+	stop     context.CancelFunc
 }
 
-// Initialize creates the API server at the provided port
-func (s *Server) Initialize(log logging.Logger, factory logging.Factory, port uint16) {
+// Initialize creates the API server at the provided port. Cross-origin
+// requests to every route are handled according to [corsConfig]. The
+// descriptions of the JSON-RPC services of every route are served at
+// /ext/schema. Every route is also served at /ext/v<version>/, in each of
+// the versions of its API.
+func (s *Server) Initialize(log logging.Logger, factory logging.Factory, port uint16, corsConfig CORSConfig) {
 	s.log = log
 	s.factory = factory
 	s.portURL = fmt.Sprintf(":%d", port)
 	s.router = newRouter()
+	s.cors = cors.New(cors.Options{
+		AllowedOrigins: corsConfig.AllowedOrigins,
+		AllowedMethods: corsConfig.AllowedMethods,
+		AllowedHeaders: corsConfig.AllowedHeaders,
+	})
+	s.schemas = newSchemas()
+	s.metrics = newMetrics()
+	s.limiter = newRateLimiter(log, s.metrics, nil)
+	s.limiter.canonical = s.CanonicalEndpoint
+	s.limits = &requestLimiter{metrics: s.metrics, canonical: s.CanonicalEndpoint}
+	s.ctx, s.stop = context.WithCancel(context.Background())
+	if err := s.router.AddRouter(schemaEndpoint, "", s.schemas); err != nil {
+		log.Error("Failed to add the schema route: %s", err)
+	}
+}
+
+// SetAuthorizer requires every request to every route to be authorized by
+// [authorizer]. Requests that aren't are answered with 401 Unauthorized. Must
+// be called before the server is dispatched.
+func (s *Server) SetAuthorizer(authorizer Authorizer) { s.authorizer = authorizer }
+
+// SetRateLimits limits how often each IP may make requests, according to
+// [rules], which replace any previous rules. May be called while the server is
+// dispatched.
+func (s *Server) SetRateLimits(rules []RateLimitRule) { s.limiter.setRules(rules) }
+
+// RegisterMetrics registers the counts of the requests that the rate limits
+// and request limits rejected with [registerer]
+func (s *Server) RegisterMetrics(registerer prometheus.Registerer) error {
+	return s.metrics.Register(registerer)
 }
 
-// Dispatch starts the API server
+// SetRequestLimits limits how long requests may take to be handled and how
+// large their bodies may be, according to [rules], which replace any previous
+// rules. May be called while the server is dispatched.
+func (s *Server) SetRequestLimits(rules []RequestLimitRule) { s.limits.setRules(rules) }
+
+// SetWebSocketCalls lets clients make any JSON-RPC call over websocket
+// connections, rather than only subscribe to pubsub channels, if [enabled].
+// Must be called before routes are added.
+func (s *Server) SetWebSocketCalls(enabled bool) { s.wsCalls = enabled }
+
+// SetCompression compresses responses of at least [minSize] bytes with gzip,
+// for clients that accept it. Must be called before the server is dispatched.
+func (s *Server) SetCompression(minSize int) { s.gzipSize = minSize }
+
+// Dispatch starts the API server. Returns nil once the server is shut down.
 func (s *Server) Dispatch() error {
-	handler := cors.Default().Handler(s.router)
-	return http.ListenAndServe(s.portURL, handler)
+	server, err := s.newServer()
+	if err != nil {
+		return closed(err)
+	}
+	return closed(server.ListenAndServe())
 }
 
-// DispatchTLS starts the API server with the provided TLS certificate
+// DispatchTLS starts the API server with the provided TLS certificate. The
+// certificate is reloaded when its files change or ReloadCertificates is
+// called.
 func (s *Server) DispatchTLS(certFile, keyFile string) error {
-	handler := cors.Default().Handler(s.router)
-	return http.ListenAndServeTLS(s.portURL, certFile, keyFile, handler)
+	certs, err := newCertReloader(s.log, certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	certs.watch()
+	defer certs.stop()
+
+	s.lock.Lock()
+	s.certs = append(s.certs, certs)
+	s.lock.Unlock()
+
+	server, err := s.newServer()
+	if err != nil {
+		return closed(err)
+	}
+	server.TLSConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+	return closed(server.ListenAndServeTLS("", ""))
+}
+
+// ReloadCertificates reloads the TLS certificates the server is dispatched
+// with from their files. If a certificate can't be reloaded, the previous one
+// is kept.
+func (s *Server) ReloadCertificates() error {
+	s.lock.Lock()
+	certs := s.certs
+	s.lock.Unlock()
+
+	errs := wrappers.Errs{}
+	for _, c := range certs {
+		errs.Add(c.reload())
+	}
+	return errs.Err
+}
+
+// DispatchUnix starts the API server on a Unix domain socket at [path], which
+// only the users that [mode] gives permission to write to the socket may
+// connect to. It lets services on the same machine call the API without it
+// being exposed on any network interface. The socket left by a server that
+// didn't stop cleanly is replaced. Since every client of the socket has the
+// same address, they share the rate limits of an IP.
+func (s *Server) DispatchUnix(path string, mode os.FileMode) error {
+	server, err := s.newServer()
+	if err != nil {
+		return closed(err)
+	}
+	listener, err := listenUnix(path, mode)
+	if err != nil {
+		return err
+	}
+	return closed(server.Serve(listener))
+}
+
+// Shutdown stops the API server from accepting requests, ends streams of
+// events and waits for the requests being handled to finish, or for [ctx] to
+// be done. Websocket connections are closed by their clients once the server
+// stops responding.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.lock.Lock()
+	s.stopping = true
+	servers := s.servers
+	s.servers = nil
+	s.lock.Unlock()
+
+	if s.stop != nil {
+		s.stop()
+	}
+	errs := wrappers.Errs{}
+	for _, server := range servers {
+		errs.Add(server.Shutdown(ctx))
+	}
+	return errs.Err
+}
+
+// newServer returns an HTTP server of the API, which is shut down with the
+// API server
+func (s *Server) newServer() (*http.Server, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stopping {
+		return nil, http.ErrServerClosed
+	}
+	server := &http.Server{
+		Addr:        s.portURL,
+		Handler:     s.handler(),
+		BaseContext: func(net.Listener) context.Context { return s.ctx },
+	}
+	s.servers = append(s.servers, server)
+	return server, nil
+}
+
+// closed returns [err], unless it's because the server was shut down
+func closed(err error) error {
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// listenUnix listens on a Unix domain socket at [path] with permissions [mode]
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("couldn't listen on %s: %w", path, errNotSocket)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// handler returns the router, wrapped to handle cross-origin requests, to
+// assign each request an ID, to serve requests made in a given version of an
+// API, to limit how long requests take and how large they are, to limit the
+// rate of requests, to compress responses and to authorize requests. Requests
+// are limited in size before they're rate limited, since the rate limits may
+// read their bodies to find the JSON-RPC methods they call. Websocket
+// connections may only be opened from the origins that may make cross-origin
+// requests.
+func (s *Server) handler() http.Handler {
+	handler := http.Handler(s.router)
+	if s.authorizer != nil {
+		router := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := s.authorizer.Authorize(r); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			router.ServeHTTP(w, r)
+		})
+	}
+	if s.gzipSize > 0 {
+		handler = &gzipHandler{handler: handler, minSize: s.gzipSize}
+	}
+	handler = s.limiter.wrap(handler)
+	handler = s.limits.wrap(handler)
+	handler = s.checkOrigin(handler)
+	return s.cors.Handler(requestIDHandler(versionHandler(handler)))
+}
+
+// checkOrigin wraps [handler] so requests to open websocket connections from
+// an origin that may not make cross-origin requests are answered with 403
+// Forbidden. Browsers don't apply the same-origin policy to websocket
+// connections, so otherwise any web page could call the API from its
+// visitors' browsers. Requests without an origin aren't made by browsers, and
+// are allowed.
+func (s *Server) checkOrigin(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) && r.Header.Get("Origin") != "" && !s.cors.OriginAllowed(r) {
+			http.Error(w, fmt.Sprintf("websocket connections from %s aren't allowed", r.Header.Get("Origin")), http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
 }
 
 // RegisterChain registers the API endpoints associated with this chain That
@@ -72,8 +349,18 @@ func (s *Server) RegisterChain(ctx *snow.Context, vmIntf interface{}) {
 	}
 	s.log.Verbo("About to add API endpoints for chain with ID %s", ctx.ChainID)
 
+	// Calls to the chain's APIs over websocket connections can subscribe to
+	// the channels of the chain's pubsub server, if it has one
+	handlers := vm.CreateHandlers()
+	var pubsub *cjson.PubSubServer
+	for _, service := range handlers {
+		if server, ok := service.Handler.(*cjson.PubSubServer); ok {
+			pubsub = server
+		}
+	}
+
 	// Register each endpoint
-	for extension, service := range vm.CreateHandlers() {
+	for extension, service := range handlers {
 		// Validate that the route being added is valid
 		// e.g. "/foo" and "" are ok but "\n" is not
 		_, err := url.ParseRequestURI(extension)
@@ -82,35 +369,124 @@ func (s *Server) RegisterChain(ctx *snow.Context, vmIntf interface{}) {
 			continue
 		}
 		s.log.Verbo("adding API endpoint: %s", defaultEndpoint+extension)
-		if err := s.AddRoute(service, &ctx.Lock, defaultEndpoint, extension, httpLogger); err != nil {
+		if err := s.addRoute(service, &ctx.Lock, defaultEndpoint, extension, httpLogger, pubsub); err != nil {
 			s.log.Error("error adding route: %s", err)
 		}
 	}
 }
 
+// DisableEndpoint stops serving the routes of [endpoint], such as "keystore",
+// and of its aliases. Requests to them are answered with 503 Service
+// Unavailable until the endpoint is enabled again. If [endpoint] is an alias,
+// the endpoint it refers to is disabled. Returns the endpoint disabled.
+func (s *Server) DisableEndpoint(endpoint string) string {
+	base := s.router.Disable(fmt.Sprintf("%s/%s", baseURL, endpoint))
+	return strings.TrimPrefix(base, baseURL+"/")
+}
+
+// EnableEndpoint serves the routes of [endpoint] again. Returns the endpoint
+// enabled.
+func (s *Server) EnableEndpoint(endpoint string) string {
+	base := s.router.Enable(fmt.Sprintf("%s/%s", baseURL, endpoint))
+	return strings.TrimPrefix(base, baseURL+"/")
+}
+
+// CanonicalEndpoint returns the endpoint that [endpoint], such as "bc/X/wallet",
+// is served by, with any alias it's under, such as "bc/X", replaced by the
+// endpoint it's an alias of
+func (s *Server) CanonicalEndpoint(endpoint string) string {
+	url := s.router.Canonical(fmt.Sprintf("%s/%s", baseURL, normalizeEndpoint(endpoint)))
+	return normalizeEndpoint(url)
+}
+
+// DisabledEndpoints returns the endpoints that are disabled, sorted
+func (s *Server) DisabledEndpoints() []string {
+	bases := s.router.Disabled()
+	endpoints := make([]string, len(bases))
+	for i, base := range bases {
+		endpoints[i] = strings.TrimPrefix(base, baseURL+"/")
+	}
+	return endpoints
+}
+
+// RemoveChain removes the API endpoints of the chain [chainID], such as when
+// it's stopped. The chain's aliases remain, so if the chain is registered again
+// its endpoints are served at them again.
+func (s *Server) RemoveChain(chainID ids.ID) {
+	url := fmt.Sprintf("%s/bc/%s", baseURL, chainID)
+	s.log.Info("removing routes %s", url)
+	s.router.RemoveRouter(url)
+	s.schemas.remove(url)
+}
+
 // AddRoute registers the appropriate endpoint for the vm given an endpoint
 func (s *Server) AddRoute(handler *common.HTTPHandler, lock *sync.RWMutex, base, endpoint string, log logging.Logger) error {
+	return s.addRoute(handler, lock, base, endpoint, log, nil)
+}
+
+// addRoute registers the endpoint. Clients can subscribe to the channels of
+// [pubsub], if it isn't nil, over websocket connections to the endpoint. If
+// websocket calls are enabled, they can also call the endpoint over websocket
+// connections. Each call made over a connection is limited in rate, size and
+// duration as if it were its own request.
+func (s *Server) addRoute(handler *common.HTTPHandler, lock *sync.RWMutex, base, endpoint string, log logging.Logger, pubsub *cjson.PubSubServer) error {
 	url := fmt.Sprintf("%s/%s", baseURL, base)
 	s.log.Info("adding route %s%s", url, endpoint)
-	h := handlers.CombinedLoggingHandler(log, handler.Handler)
+	h := &accessLogHandler{log: log, service: url + endpoint, handler: handler.Handler}
+
+	var routeHandler http.Handler
 	switch handler.LockOptions {
 	case common.WriteLock:
-		return s.router.AddRouter(url, endpoint, middlewareHandler{
+		routeHandler = middlewareHandler{
 			before:  lock.Lock,
 			after:   lock.Unlock,
 			handler: h,
-		})
+		}
 	case common.ReadLock:
-		return s.router.AddRouter(url, endpoint, middlewareHandler{
+		routeHandler = middlewareHandler{
 			before:  lock.RLock,
 			after:   lock.RUnlock,
 			handler: h,
-		})
+		}
 	case common.NoLock:
-		return s.router.AddRouter(url, endpoint, h)
+		routeHandler = h
 	default:
 		return errUnknownLockOption
 	}
+
+	// Calls made in earlier versions of the API are adapted to its latest
+	// version
+	versions := Versions{Latest: DefaultVersion}
+	if versioned, ok := handler.Handler.(Versioned); ok {
+		versions = versioned.Versions()
+	}
+	routeHandler = &shimHandler{versions: versions, handler: routeHandler}
+
+	// Pubsub servers, and other websocket handlers, handle their own
+	// websocket connections
+	handlesWebSockets := false
+	switch h := handler.Handler.(type) {
+	case *cjson.PubSubServer:
+		handlesWebSockets = true
+	case WebSocketHandler:
+		handlesWebSockets = h.HandlesWebSockets()
+	}
+	if !handlesWebSockets && (s.wsCalls || pubsub != nil) {
+		// The lock is only held while each call is handled, not for as long as
+		// the connection is open
+		routeHandler = &wsHandler{
+			log:     s.log,
+			handler: routeHandler,
+			calls:   s.limits.wrap(s.limiter.wrap(routeHandler)),
+			rpc:     s.wsCalls,
+			pubsub:  pubsub,
+		}
+	}
+	if err := s.router.AddRouter(url, endpoint, routeHandler); err != nil {
+		return err
+	}
+	s.schemas.add(url+endpoint, handler.Handler)
+	return nil
 }
 
 // AddAliases registers aliases to the server
@@ -135,6 +511,27 @@ func (s *Server) AddAliasesWithReadLock(endpoint string, aliases ...string) erro
 	return s.AddAliases(endpoint, aliases...)
 }
 
+// RemoveAliases removes [aliases] as aliases of [endpoint]
+func (s *Server) RemoveAliases(endpoint string, aliases ...string) error {
+	url := fmt.Sprintf("%s/%s", baseURL, endpoint)
+	for _, alias := range aliases {
+		if err := s.router.RemoveAlias(url, fmt.Sprintf("%s/%s", baseURL, alias)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveAliasesWithReadLock removes [aliases] as aliases of [endpoint]
+// assuming the http read lock is currently held.
+func (s *Server) RemoveAliasesWithReadLock(endpoint string, aliases ...string) error {
+	// See AddAliasesWithReadLock
+	s.router.lock.RUnlock()
+	defer s.router.lock.RLock()
+
+	return s.RemoveAliases(endpoint, aliases...)
+}
+
 // Call ...
 func (s *Server) Call(
 	writer http.ResponseWriter,
@@ -163,3 +560,61 @@ func (s *Server) Call(
 
 	return nil
 }
+
+// ChainMethods returns the names of the JSON-RPC methods of the API of the
+// chain [chainID], sorted, or nil if it has none
+func (s *Server) ChainMethods(chainID ids.ID) []string {
+	return s.schemas.methods(fmt.Sprintf("%s/bc/%s", baseURL, chainID))
+}
+
+// CallChain calls the JSON-RPC method [method] of the API of the chain
+// [chainID] with the arguments [args], and unmarshals the result into [reply]
+func (s *Server) CallChain(chainID ids.ID, method string, args, reply interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  args,
+	})
+	if err != nil {
+		return err
+	}
+
+	handler, err := s.router.GetHandler(fmt.Sprintf("%s/bc/%s", baseURL, chainID), "")
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", "*", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	writer := &responseBuffer{header: make(http.Header)}
+	handler.ServeHTTP(writer, req)
+
+	response := struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	if err := json.Unmarshal(writer.body.Bytes(), &response); err != nil {
+		return fmt.Errorf("couldn't parse the response to %s: %w", method, err)
+	}
+	if response.Error != nil {
+		return errors.New(response.Error.Message)
+	}
+	return json.Unmarshal(response.Result, reply)
+}
+
+// responseBuffer is an http.ResponseWriter that stores the response
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseBuffer) Header() http.Header         { return w.header }
+func (w *responseBuffer) Write(b []byte) (int, error) { return w.body.Write(b) }
+func (w *responseBuffer) WriteHeader(status int)      { w.status = status }
@@ -5,14 +5,22 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/rpc/v2"
 	"github.com/gorilla/rpc/v2/json2"
 
+	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/logging"
 )
@@ -28,9 +36,21 @@ func (s *Service) Call(_ *http.Request, args *Args, reply *Reply) error {
 	return nil
 }
 
+type EchoArgs struct {
+	Message string `json:"message"`
+}
+
+func (s *Service) Echo(_ *http.Request, args *EchoArgs, reply *EchoArgs) error {
+	if args.Message == "" {
+		return errors.New("no message")
+	}
+	reply.Message = args.Message
+	return nil
+}
+
 func TestCall(t *testing.T) {
 	s := Server{}
-	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080)
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
 
 	serv := &Service{}
 	newServer := rpc.NewServer()
@@ -58,3 +78,254 @@ func TestCall(t *testing.T) {
 		t.Fatalf("Should have been called")
 	}
 }
+
+func TestCallChain(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+
+	newServer := rpc.NewServer()
+	newServer.RegisterCodec(json2.NewCodec(), "application/json")
+	newServer.RegisterService(&Service{}, "test")
+
+	chainID := ids.NewID([32]byte{1})
+	if err := s.AddRoute(&common.HTTPHandler{Handler: newServer}, new(sync.RWMutex), "bc/"+chainID.String(), "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := EchoArgs{}
+	if err := s.CallChain(chainID, "test.Echo", &EchoArgs{Message: "hi"}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "hi" {
+		t.Fatalf("should have echoed the message but replied %q", reply.Message)
+	}
+
+	if err := s.CallChain(chainID, "test.Echo", &EchoArgs{}, &reply); err == nil {
+		t.Fatalf("should have returned the error of the method")
+	}
+	if err := s.CallChain(ids.NewID([32]byte{2}), "test.Echo", &EchoArgs{Message: "hi"}, &reply); err == nil {
+		t.Fatalf("should have failed to call an unknown chain")
+	}
+}
+
+func TestCORS(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{
+		AllowedOrigins: []string{"https://wallet.example.com"},
+		AllowedMethods: []string{http.MethodPost},
+	})
+
+	newServer := rpc.NewServer()
+	newServer.RegisterCodec(json2.NewCodec(), "application/json")
+	newServer.RegisterService(&Service{}, "test")
+	if err := s.AddRoute(&common.HTTPHandler{Handler: newServer}, new(sync.RWMutex), "keystore", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for origin, allowed := range map[string]bool{
+		"https://wallet.example.com": true,
+		"https://evil.example.com":   false,
+	} {
+		request := httptest.NewRequest(http.MethodOptions, "/ext/keystore", nil)
+		request.Header.Set("Origin", origin)
+		request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		writer := httptest.NewRecorder()
+		s.handler().ServeHTTP(writer, request)
+
+		if got := writer.Header().Get("Access-Control-Allow-Origin"); (got == origin) != allowed {
+			t.Fatalf("%s should be allowed: %v, but was allowed %q", origin, allowed, got)
+		}
+	}
+}
+
+// denyAll is an Authorizer that doesn't authorize any request
+type denyAll struct{}
+
+func (denyAll) Authorize(*http.Request) error { return errors.New("unauthorized") }
+
+func TestAuthorizer(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+	s.SetAuthorizer(denyAll{})
+
+	serv := &Service{}
+	newServer := rpc.NewServer()
+	newServer.RegisterCodec(json2.NewCodec(), "application/json")
+	newServer.RegisterService(serv, "test")
+	if err := s.AddRoute(&common.HTTPHandler{Handler: newServer}, new(sync.RWMutex), "admin", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := json2.EncodeClientRequest("test.Call", &Args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/ext/admin", bytes.NewBuffer(buf))
+	request.Header.Set("Content-Type", "application/json")
+	writer := httptest.NewRecorder()
+	s.handler().ServeHTTP(writer, request)
+
+	if writer.Code != http.StatusUnauthorized || serv.called {
+		t.Fatalf("shouldn't have served an unauthorized request")
+	}
+}
+
+func TestSetLimitsWhileServing(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+	if err := s.AddRoute(&common.HTTPHandler{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})}, new(sync.RWMutex), "keystore", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+	handler := s.handler()
+	status := func() int {
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/ext/keystore", nil))
+		return writer.Code
+	}
+
+	if code := status(); code != http.StatusOK {
+		t.Fatalf("expected %d without limits but got %d", http.StatusOK, code)
+	}
+	s.SetRateLimits([]RateLimitRule{{Endpoint: "keystore", Rate: 0.25, Burst: 1}})
+	if code := status(); code != http.StatusOK {
+		t.Fatalf("expected %d under the new limit but got %d", http.StatusOK, code)
+	}
+	if code := status(); code != http.StatusTooManyRequests {
+		t.Fatalf("expected %d over the new limit but got %d", http.StatusTooManyRequests, code)
+	}
+	s.SetRateLimits(nil)
+	if code := status(); code != http.StatusOK {
+		t.Fatalf("expected %d once the limits were removed but got %d", http.StatusOK, code)
+	}
+}
+
+func TestDispatchUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+	serv := &Service{}
+	newServer := rpc.NewServer()
+	newServer.RegisterCodec(json2.NewCodec(), "application/json")
+	newServer.RegisterService(serv, "test")
+	if err := s.AddRoute(&common.HTTPHandler{Handler: newServer}, new(sync.RWMutex), "admin", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file that isn't a socket isn't replaced
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DispatchUnix(file, 0600); !errors.Is(err, errNotSocket) {
+		t.Fatalf("expected %s but got %v", errNotSocket, err)
+	}
+
+	// A socket left by a server that didn't stop cleanly is replaced
+	path := filepath.Join(dir, "api.sock")
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err := listenUnix(path, 0640)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = http.Serve(listener, s.handler()) }()
+	defer listener.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0640 {
+		t.Fatalf("expected the socket to have mode %o but it has %o", 0640, mode)
+	}
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+	buf, err := json2.EncodeClientRequest("test.Call", &Args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.Post("http://unix/ext/admin", "application/json", bytes.NewBuffer(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || !serv.called {
+		t.Fatalf("should have been called through the socket, but got status %d", response.StatusCode)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+	streaming := make(chan struct{})
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		close(streaming)
+		// Streams last until the server shuts down
+		<-r.Context().Done()
+	})
+	if err := s.AddRoute(&common.HTTPHandler{LockOptions: common.NoLock, Handler: stream}, new(sync.RWMutex), "events", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "api.sock")
+	dispatched := make(chan error, 1)
+	go func() { dispatched <- s.DispatchUnix(path, 0600) }()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+	var response *http.Response
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		if response, err = client.Get("http://unix/ext/events"); err == nil {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal(err)
+		}
+	}
+	defer response.Body.Close()
+	<-streaming
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("the stream should have ended when the server shut down but got %s", err)
+	}
+	if err := <-dispatched; err != nil {
+		t.Fatalf("dispatching should have stopped cleanly but got %s", err)
+	}
+	if err := s.Dispatch(); err != nil {
+		t.Fatalf("dispatching a shut down server should do nothing but got %s", err)
+	}
+}
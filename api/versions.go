@@ -0,0 +1,243 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultVersion is the version of an API that requests without a version
+	// in their path are made in, so that integrations written before an API
+	// had versions keep working as its later versions change it
+	DefaultVersion uint32 = 1
+
+	// versionPrefix starts the path of requests made in a given version of an
+	// API, as in /ext/v2/bc/X
+	versionPrefix = baseURL + "/v"
+)
+
+// versionKey is the key of the version a request is made in, in its context
+type versionKey struct{}
+
+// Shim adapts the calls of a JSON-RPC method made in one version of an API to
+// the next version, and the results back
+type Shim struct {
+	// Method, such as "avm.getBalance", in the older version
+	Method string
+
+	// Name of the method in the next version. Empty if it isn't renamed.
+	Rename string
+
+	// Args converts the params of a call in the older version into the params
+	// of the next version. If nil, the params are unchanged.
+	Args func(params json.RawMessage) (json.RawMessage, error)
+
+	// Reply converts the result of a call in the next version into the result
+	// of the older version. If nil, the result is unchanged.
+	Reply func(result json.RawMessage) (json.RawMessage, error)
+}
+
+// Versions of a JSON-RPC API. The API's handler serves its latest version.
+// Calls made in an earlier version are adapted by the shims of that version
+// to the next version, then by the shims of that version, and so on, until
+// they reach the latest version. Methods without shims in a version are the
+// same in the next version.
+type Versions struct {
+	// Latest version of the API. Versions start at 1.
+	Latest uint32
+
+	// Shims[v] adapt calls made in version v to version v+1
+	Shims map[uint32][]Shim
+}
+
+// Versioned is implemented by the handlers of APIs that have more than one
+// version
+type Versioned interface {
+	Versions() Versions
+}
+
+// Version returns the version of the API that [r] was made in
+func Version(r *http.Request) uint32 {
+	if r == nil {
+		return DefaultVersion
+	}
+	if version, ok := r.Context().Value(versionKey{}).(uint32); ok {
+		return version
+	}
+	return DefaultVersion
+}
+
+// withVersion returns [r] made in version [version]
+func withVersion(r *http.Request, version uint32) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), versionKey{}, version))
+}
+
+// versionHandler serves requests made in a given version of an API, as in
+// /ext/v2/bc/X, at the path without the version, as in /ext/bc/X. The version
+// is kept in the request's context.
+func versionHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if version, path, ok := parseVersion(r.URL.Path); ok {
+			r = withVersion(r, version)
+			r.URL.Path = path
+			r.URL.RawPath = ""
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// parseVersion returns the version the request to [path] is made in, and the
+// path without the version. Returns false if [path] doesn't have a version.
+func parseVersion(path string) (uint32, string, bool) {
+	if !strings.HasPrefix(path, versionPrefix) {
+		return 0, "", false
+	}
+	rest := path[len(versionPrefix):]
+	end := strings.IndexByte(rest, '/')
+	if end == -1 {
+		return 0, "", false
+	}
+	version, err := strconv.ParseUint(rest[:end], 10, 32)
+	if err != nil || version == 0 || rest[0] == '0' || rest[0] == '+' {
+		return 0, "", false
+	}
+	return uint32(version), baseURL + rest[end:], true
+}
+
+// shimHandler serves calls of the JSON-RPC API [handler], whose versions are
+// [versions], made in any of the API's versions
+type shimHandler struct {
+	versions Versions
+	handler  http.Handler
+}
+
+func (h *shimHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	version := Version(r)
+	if version > h.versions.Latest {
+		http.Error(w, fmt.Sprintf("version %d of this API doesn't exist. The latest version is %d.", version, h.versions.Latest), http.StatusNotFound)
+		return
+	}
+	// Websocket connections are shimmed call by call
+	if version == h.versions.Latest || r.Method != http.MethodPost || r.Header.Get("Upgrade") != "" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request := map[string]json.RawMessage{}
+	call := wsRequest{}
+	if json.Unmarshal(body, &request) != nil || json.Unmarshal(body, &call) != nil {
+		// Not a single call, so it can't be shimmed
+		h.serve(w, r, body)
+		return
+	}
+
+	shims := []*Shim(nil)
+	params := call.Params
+	method := call.Method
+	for v := version; v < h.versions.Latest; v++ {
+		shim := h.shim(v, method)
+		if shim == nil {
+			continue
+		}
+		if shim.Args != nil {
+			if params, err = shim.Args(params); err != nil {
+				writeCallError(w, call.ID, -32602, fmt.Sprintf("couldn't convert params to version %d: %s", v+1, err))
+				return
+			}
+		}
+		if shim.Rename != "" {
+			method = shim.Rename
+		}
+		shims = append(shims, shim)
+	}
+	if len(shims) == 0 {
+		h.serve(w, r, body)
+		return
+	}
+
+	if request["method"], err = json.Marshal(method); err != nil {
+		writeCallError(w, call.ID, -32603, err.Error())
+		return
+	}
+	if params != nil {
+		request["params"] = params
+	}
+	if body, err = json.Marshal(request); err != nil {
+		writeCallError(w, call.ID, -32603, err.Error())
+		return
+	}
+
+	writer := &responseBuffer{header: make(http.Header), status: http.StatusOK}
+	h.serve(writer, r, body)
+
+	response := map[string]json.RawMessage{}
+	result := writer.body.Bytes()
+	if err := json.Unmarshal(result, &response); err == nil {
+		if reply, ok := response["result"]; ok && string(reply) != "null" {
+			for i := len(shims) - 1; i >= 0 && err == nil; i-- {
+				if shims[i].Reply != nil {
+					reply, err = shims[i].Reply(reply)
+				}
+			}
+			if err != nil {
+				writeCallError(w, call.ID, -32603, fmt.Sprintf("couldn't convert result to version %d: %s", version, err))
+				return
+			}
+			response["result"] = reply
+			if result, err = json.Marshal(response); err != nil {
+				writeCallError(w, call.ID, -32603, err.Error())
+				return
+			}
+		}
+	}
+
+	for key, values := range writer.header {
+		w.Header()[key] = values
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(writer.status)
+	_, _ = w.Write(result)
+}
+
+// serve the request [r] with the body [body]
+func (h *shimHandler) serve(w http.ResponseWriter, r *http.Request, body []byte) {
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	h.handler.ServeHTTP(w, r)
+}
+
+// shim returns the shim of [method] in version [version], or nil if the method
+// is the same in the next version
+func (h *shimHandler) shim(version uint32, method string) *Shim {
+	shims := h.versions.Shims[version]
+	for i := range shims {
+		if shims[i].Method == method {
+			return &shims[i]
+		}
+	}
+	return nil
+}
+
+// writeCallError writes a JSON-RPC 2.0 response to the call [id] with an error
+func writeCallError(w http.ResponseWriter, id *json.RawMessage, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&wsResponse{
+		Version: "2.0",
+		ID:      id,
+		Error:   &wsError{Code: code, Message: message},
+	})
+}
@@ -0,0 +1,193 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/rpc/v2"
+	"github.com/gorilla/rpc/v2/json2"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// versionedServer is a JSON-RPC server whose test.Say method, which took and
+// replied with a "text", became test.Echo in version 2
+type versionedServer struct{ *rpc.Server }
+
+// rename the field [from] of the JSON object [b] to [to]
+func rename(b json.RawMessage, from, to string) (json.RawMessage, error) {
+	object := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &object); err != nil {
+		return nil, err
+	}
+	object[to] = object[from]
+	delete(object, from)
+	return json.Marshal(object)
+}
+
+func (versionedServer) Versions() Versions {
+	return Versions{
+		Latest: 2,
+		Shims: map[uint32][]Shim{
+			1: {{
+				Method: "test.Say",
+				Rename: "test.Echo",
+				Args: func(params json.RawMessage) (json.RawMessage, error) {
+					args := []json.RawMessage{}
+					if err := json.Unmarshal(params, &args); err != nil || len(args) != 1 {
+						return rename(params, "text", "message")
+					}
+					arg, err := rename(args[0], "text", "message")
+					if err != nil {
+						return nil, err
+					}
+					return json.Marshal([]json.RawMessage{arg})
+				},
+				Reply: func(result json.RawMessage) (json.RawMessage, error) {
+					return rename(result, "message", "text")
+				},
+			}},
+		},
+	}
+}
+
+func TestVersions(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+
+	newServer := rpc.NewServer()
+	newServer.RegisterCodec(json2.NewCodec(), "application/json")
+	newServer.RegisterService(&Service{}, "test")
+	if err := s.AddRoute(&common.HTTPHandler{Handler: versionedServer{newServer}}, new(sync.RWMutex), "vm/lol", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, path, body string
+		status           int
+		expected         string
+	}{
+		{
+			name:     "unversioned path is version 1",
+			path:     "/ext/vm/lol",
+			body:     `{"jsonrpc": "2.0", "id": 1, "method": "test.Say", "params": {"text": "hi"}}`,
+			status:   http.StatusOK,
+			expected: `"result":{"text":"hi"}`,
+		},
+		{
+			name:     "version 1",
+			path:     "/ext/v1/vm/lol",
+			body:     `{"jsonrpc": "2.0", "id": 1, "method": "test.Say", "params": {"text": "hi"}}`,
+			status:   http.StatusOK,
+			expected: `"result":{"text":"hi"}`,
+		},
+		{
+			name:     "latest version",
+			path:     "/ext/v2/vm/lol",
+			body:     `{"jsonrpc": "2.0", "id": 1, "method": "test.Echo", "params": {"message": "hi"}}`,
+			status:   http.StatusOK,
+			expected: `"result":{"message":"hi"}`,
+		},
+		{
+			name:     "method that wasn't changed",
+			path:     "/ext/v1/vm/lol",
+			body:     `{"jsonrpc": "2.0", "id": 1, "method": "test.Call", "params": {}}`,
+			status:   http.StatusOK,
+			expected: `"result":{}`,
+		},
+		{
+			name:     "error from the latest version",
+			path:     "/ext/v1/vm/lol",
+			body:     `{"jsonrpc": "2.0", "id": 1, "method": "test.Say", "params": {"text": ""}}`,
+			status:   http.StatusOK,
+			expected: `"message":"no message"`,
+		},
+		{
+			name:     "params that can't be converted",
+			path:     "/ext/v1/vm/lol",
+			body:     `{"jsonrpc": "2.0", "id": 1, "method": "test.Say", "params": 5}`,
+			status:   http.StatusOK,
+			expected: `"code":-32602`,
+		},
+		{
+			name:   "future version",
+			path:   "/ext/v3/vm/lol",
+			body:   `{"jsonrpc": "2.0", "id": 1, "method": "test.Echo", "params": {"message": "hi"}}`,
+			status: http.StatusNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", "application/json")
+			writer := httptest.NewRecorder()
+			s.handler().ServeHTTP(writer, request)
+
+			if writer.Code != test.status {
+				t.Fatalf("expected status %d but got %d", test.status, writer.Code)
+			}
+			if !strings.Contains(writer.Body.String(), test.expected) {
+				t.Fatalf("expected %s in %s", test.expected, writer.Body.String())
+			}
+		})
+	}
+}
+
+func TestUnversionedRoute(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+
+	serv := &Service{}
+	newServer := rpc.NewServer()
+	newServer.RegisterCodec(json2.NewCodec(), "application/json")
+	newServer.RegisterService(serv, "test")
+	if err := s.AddRoute(&common.HTTPHandler{Handler: newServer}, new(sync.RWMutex), "keystore", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, status := range map[string]int{
+		"/ext/v1/keystore": http.StatusOK,
+		"/ext/v2/keystore": http.StatusNotFound,
+	} {
+		buf, err := json2.EncodeClientRequest("test.Call", &Args{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		request := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(buf))
+		request.Header.Set("Content-Type", "application/json")
+		writer := httptest.NewRecorder()
+		s.handler().ServeHTTP(writer, request)
+
+		if writer.Code != status {
+			t.Fatalf("expected status %d from %s but got %d", status, path, writer.Code)
+		}
+	}
+	if !serv.called {
+		t.Fatal("should have been called in version 1")
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for path, expected := range map[string]uint32{
+		"/ext/v2/bc/X":  2,
+		"/ext/v10/info": 10,
+		"/ext/v0/info":  0,
+		"/ext/v02/info": 0,
+		"/ext/vm/lol":   0,
+		"/ext/v2":       0,
+	} {
+		version, _, ok := parseVersion(path)
+		if ok != (expected != 0) || version != expected {
+			t.Fatalf("expected version %d of %s but got %d", expected, path, version)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+const (
+	// Time allowed to write a message to the client
+	wsWriteWait = 10 * time.Second
+
+	// Time allowed to read the next pong message from the client
+	wsPongWait = 60 * time.Second
+
+	// Send pings to the client with this period. Must be less than wsPongWait.
+	wsPingPeriod = (wsPongWait * 9) / 10
+
+	// Largest call a client may send
+	wsMaxMessageSize = 1 << 20 // bytes
+
+	// Most notifications that may be waiting to be sent to a client. Later
+	// notifications are dropped.
+	wsMaxPendingNotifications = 256
+
+	// Methods that subscribe to and unsubscribe from a channel of the chain's
+	// pubsub server
+	subscribeMethod   = "subscribe"
+	unsubscribeMethod = "unsubscribe"
+
+	// Method of the notifications that are pushed to subscribed clients
+	notificationMethod = "subscription"
+)
+
+// The origins of websocket connections are checked by the server against the
+// origins that may make cross-origin requests, before they're upgraded
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+// wsRequest is the part of a JSON-RPC request that's needed to route it
+type wsRequest struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+// wsResponse is a JSON-RPC 2.0 response to a call handled by the transport
+// itself
+type wsResponse struct {
+	Version string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *wsError         `json:"error,omitempty"`
+}
+
+type wsError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// wsNotification is a JSON-RPC 2.0 notification of a message published to a
+// channel the client subscribed to
+type wsNotification struct {
+	Version string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// SubscribeArgs are the params of the subscribe and unsubscribe methods
+type SubscribeArgs struct {
+	Channel string `json:"channel"`
+
+	// If not empty, only messages published with one of these keys are sent
+	Filters []string `json:"filters"`
+}
+
+// SubscribeReply is the result of the subscribe and unsubscribe methods
+type SubscribeReply struct {
+	Success bool `json:"success"`
+}
+
+// wsHandler serves requests with [handler], and lets clients open websocket
+// connections to it. If [rpc] is true, clients can call the JSON-RPC service
+// of [handler] over the connection, as well as over HTTP POST. Each call
+// received on the connection is passed to [calls] as a POST request, and its
+// response is sent back on the connection, in the order the calls were
+// received. If [pubsub] isn't nil, the client can also subscribe to its
+// channels with the subscribe and unsubscribe methods, and the messages
+// published to them are pushed to the client as notifications.
+type wsHandler struct {
+	log     logging.Logger
+	handler http.Handler
+
+	// [handler], limited in the rate, size and duration of the calls made to
+	// it over connections
+	calls http.Handler
+	rpc   bool
+
+	pubsub *cjson.PubSubServer
+}
+
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.log.Debug("failed to upgrade to a websocket connection due to %s", err)
+		return
+	}
+	c := &wsConn{
+		h:             h,
+		request:       r,
+		conn:          conn,
+		responses:     make(chan []byte),
+		notifications: make(chan interface{}, wsMaxPendingNotifications),
+		closed:        make(chan struct{}),
+		writerDone:    make(chan struct{}),
+	}
+	if h.pubsub != nil {
+		c.subscriptions = h.pubsub.NewConnection(c.notifications)
+	}
+	go h.log.RecoverAndPanic(c.writePump)
+	c.readPump()
+}
+
+// wsConn is a client's websocket connection
+type wsConn struct {
+	h *wsHandler
+
+	// The request the connection was upgraded from
+	request *http.Request
+
+	conn *websocket.Conn
+
+	// Responses to the client's calls, and notifications of published
+	// messages, waiting to be written
+	responses     chan []byte
+	notifications chan interface{}
+
+	// The client's subscriptions. Nil if the chain has no pubsub server.
+	subscriptions *cjson.Connection
+
+	// Closed once the connection stops being read from, and once it stops
+	// being written to
+	closed, writerDone chan struct{}
+}
+
+// readPump handles the calls the client sends until the connection closes
+func (c *wsConn) readPump() {
+	defer func() {
+		if c.subscriptions != nil {
+			c.subscriptions.Close()
+		}
+		close(c.closed)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error { return c.conn.SetReadDeadline(time.Now().Add(wsPongWait)) })
+
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				c.h.log.Debug("unexpected close of websocket connection: %s", err)
+			}
+			return
+		}
+
+		response := c.handle(msg)
+		if len(response) == 0 {
+			continue
+		}
+		select {
+		case c.responses <- response:
+		case <-c.writerDone:
+			return
+		}
+	}
+}
+
+// handle the call [msg], and return the response to send to the client
+func (c *wsConn) handle(msg []byte) []byte {
+	request := wsRequest{}
+	if err := json.Unmarshal(msg, &request); err == nil && c.subscriptions != nil {
+		switch request.Method {
+		case subscribeMethod, unsubscribeMethod:
+			return c.subscribe(&request)
+		}
+	}
+	if !c.h.rpc {
+		return c.errorResponse(request.ID, -32601, "only the subscribe and unsubscribe methods may be called over websocket connections")
+	}
+
+	httpRequest, err := http.NewRequest(http.MethodPost, c.request.URL.String(), bytes.NewReader(msg))
+	if err != nil {
+		c.h.log.Debug("couldn't create request for websocket call due to %s", err)
+		return nil
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.RemoteAddr = c.request.RemoteAddr
+	// Each call is assigned its own ID, and is made in the version of the API
+	// the connection was
+	httpRequest = withVersion(withRequestID(httpRequest), Version(c.request))
+	writer := &responseBuffer{header: make(http.Header), status: http.StatusOK}
+	c.h.calls.ServeHTTP(writer, httpRequest)
+
+	// Calls that are rate limited, too large or time out are answered with
+	// plain text, which is sent to the client as a JSON-RPC error
+	response := writer.body.Bytes()
+	if writer.status >= http.StatusBadRequest && !json.Valid(response) {
+		return c.errorResponse(request.ID, -32000, string(bytes.TrimSpace(response)))
+	}
+	return response
+}
+
+// errorResponse returns the JSON-RPC response to the call [id] that failed
+// with [message]
+func (c *wsConn) errorResponse(id *json.RawMessage, code int, message string) []byte {
+	responseBytes, err := json.Marshal(&wsResponse{
+		Version: "2.0",
+		ID:      id,
+		Error:   &wsError{Code: code, Message: message},
+	})
+	if err != nil {
+		c.h.log.Debug("couldn't marshal error response due to %s", err)
+		return nil
+	}
+	return responseBytes
+}
+
+// subscribe handles a call of the subscribe or unsubscribe method
+func (c *wsConn) subscribe(request *wsRequest) []byte {
+	response := wsResponse{Version: "2.0", ID: request.ID}
+
+	args := SubscribeArgs{}
+	if err := json.Unmarshal(request.Params, &args); err != nil {
+		response.Error = &wsError{Code: -32602, Message: err.Error()}
+	} else if request.Method == unsubscribeMethod {
+		c.subscriptions.Unsubscribe(args.Channel)
+		response.Result = &SubscribeReply{Success: true}
+	} else if err := c.subscriptions.Subscribe(args.Channel, args.Filters); err != nil {
+		response.Error = &wsError{Code: -32000, Message: err.Error()}
+	} else {
+		response.Result = &SubscribeReply{Success: true}
+	}
+
+	responseBytes, err := json.Marshal(&response)
+	if err != nil {
+		c.h.log.Debug("couldn't marshal response to %s due to %s", request.Method, err)
+		return nil
+	}
+	return responseBytes
+}
+
+// writePump writes the responses and notifications to the client, and pings
+// the client, until the connection closes
+func (c *wsConn) writePump() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		close(c.writerDone)
+		c.conn.Close()
+	}()
+
+	for {
+		var err error
+		select {
+		case response := <-c.responses:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = c.conn.WriteMessage(websocket.TextMessage, response)
+		case notification := <-c.notifications:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = c.conn.WriteJSON(&wsNotification{
+				Version: "2.0",
+				Method:  notificationMethod,
+				Params:  notification,
+			})
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = c.conn.WriteMessage(websocket.PingMessage, nil)
+		case <-c.closed:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/rpc/v2"
+	"github.com/gorilla/websocket"
+
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+func TestWebsocket(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+	s.SetWebSocketCalls(true)
+
+	newServer := rpc.NewServer()
+	newServer.RegisterCodec(cjson.NewCodec(), "application/json")
+	newServer.RegisterService(&Service{}, "test")
+
+	pubsub := cjson.NewPubSubServer(snow.DefaultContextTest())
+	if err := pubsub.Register("accepted"); err != nil {
+		t.Fatal(err)
+	}
+	lock := new(sync.RWMutex)
+	if err := s.addRoute(&common.HTTPHandler{Handler: newServer}, lock, "bc/X", "", logging.NoLog{}, pubsub); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.addRoute(&common.HTTPHandler{LockOptions: common.NoLock, Handler: pubsub}, lock, "bc/X", "/pubsub", logging.NoLog{}, pubsub); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ext/bc/X", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	call := func(request string) map[string]interface{} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(request)); err != nil {
+			t.Fatal(err)
+		}
+		response := map[string]interface{}{}
+		if err := conn.ReadJSON(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	response := call(`{"jsonrpc":"2.0","id":1,"method":"test.echo","params":{"message":"hi"}}`)
+	if result, ok := response["result"].(map[string]interface{}); !ok || result["message"] != "hi" {
+		t.Fatalf("should have echoed the message over the connection but replied %v", response)
+	}
+
+	response = call(`{"jsonrpc":"2.0","id":2,"method":"subscribe","params":{"channel":"unknown"}}`)
+	if response["error"] == nil {
+		t.Fatalf("shouldn't have subscribed to an unknown channel")
+	}
+	response = call(`{"jsonrpc":"2.0","id":3,"method":"subscribe","params":{"channel":"accepted","filters":["a"]}}`)
+	if response["error"] != nil || response["id"] != float64(3) {
+		t.Fatalf("should have subscribed to the channel but replied %v", response)
+	}
+
+	pubsub.PublishFiltered("accepted", "skipped", []string{"b"})
+	pubsub.PublishFiltered("accepted", "published", []string{"a"})
+	notification := map[string]interface{}{}
+	if err := conn.ReadJSON(&notification); err != nil {
+		t.Fatal(err)
+	}
+	params, ok := notification["params"].(map[string]interface{})
+	if notification["method"] != notificationMethod || !ok || params["channel"] != "accepted" || params["value"] != "published" {
+		t.Fatalf("should have been notified of the published message but received %v", notification)
+	}
+
+	response = call(`{"jsonrpc":"2.0","id":4,"method":"unsubscribe","params":{"channel":"accepted"}}`)
+	if response["error"] != nil {
+		t.Fatalf("should have unsubscribed from the channel but replied %v", response)
+	}
+	pubsub.Publish("accepted", "unsubscribed")
+	response = call(`{"jsonrpc":"2.0","id":5,"method":"test.echo","params":{"message":"bye"}}`)
+	if result, ok := response["result"].(map[string]interface{}); !ok || result["message"] != "bye" {
+		t.Fatalf("shouldn't have been notified after unsubscribing but received %v", response)
+	}
+}
+
+// echoHandler upgrades requests to websocket connections itself, and echoes
+// the first message it's sent
+type echoHandler struct{}
+
+func (echoHandler) HandlesWebSockets() bool { return true }
+
+func (echoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if messageType, msg, err := conn.ReadMessage(); err == nil {
+		_ = conn.WriteMessage(messageType, msg)
+	}
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+	if err := s.AddRoute(&common.HTTPHandler{LockOptions: common.NoLock, Handler: echoHandler{}}, new(sync.RWMutex), "echo", "", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ext/echo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// The call isn't passed to the handler as a JSON-RPC request
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, msg, err := conn.ReadMessage(); err != nil {
+		t.Fatal(err)
+	} else if string(msg) != "hello" {
+		t.Fatalf("expected the handler to echo %q but got %q", "hello", msg)
+	}
+}
+
+// newWebsocketServer returns a server that serves the test service at
+// /ext/bc/X, along with a pubsub server
+func newWebsocketServer(t *testing.T, s *Server) *httptest.Server {
+	newServer := rpc.NewServer()
+	newServer.RegisterCodec(cjson.NewCodec(), "application/json")
+	newServer.RegisterService(&Service{}, "test")
+
+	pubsub := cjson.NewPubSubServer(snow.DefaultContextTest())
+	if err := pubsub.Register("accepted"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.addRoute(&common.HTTPHandler{Handler: newServer}, new(sync.RWMutex), "bc/X", "", logging.NoLog{}, pubsub); err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(s.handler())
+}
+
+func TestWebsocketCallsDisabled(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+	server := newWebsocketServer(t, &s)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ext/bc/X", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"test.echo","params":{"message":"hi"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	response := map[string]interface{}{}
+	if err := conn.ReadJSON(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response["error"] == nil || response["result"] != nil {
+		t.Fatalf("shouldn't have handled the call over the connection but replied %v", response)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":2,"method":"subscribe","params":{"channel":"accepted"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	response = map[string]interface{}{}
+	if err := conn.ReadJSON(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response["error"] != nil {
+		t.Fatalf("should have subscribed to the channel but replied %v", response)
+	}
+}
+
+func TestWebsocketOrigin(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{AllowedOrigins: []string{"https://wallet.example.com"}})
+	server := newWebsocketServer(t, &s)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ext/bc/X"
+
+	_, response, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": []string{"https://attacker.example.com"}})
+	if err == nil {
+		t.Fatal("shouldn't have opened a connection from an origin that isn't allowed")
+	}
+	if response == nil || response.StatusCode != http.StatusForbidden {
+		t.Fatalf("should have been forbidden but got %v", response)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": []string{"https://wallet.example.com"}})
+	if err != nil {
+		t.Fatalf("should have opened a connection from an allowed origin but got %s", err)
+	}
+	conn.Close()
+
+	conn, _, err = websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("should have opened a connection without an origin but got %s", err)
+	}
+	conn.Close()
+}
+
+func TestWebsocketCallsRateLimited(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080, CORSConfig{})
+	s.SetWebSocketCalls(true)
+	server := newWebsocketServer(t, &s)
+	defer server.Close()
+
+	// The upgrade takes the only token, so the call over the connection is
+	// rate limited
+	s.SetRateLimits([]RateLimitRule{{Endpoint: "bc/X", Rate: 0.001, Burst: 1}})
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ext/bc/X", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"test.echo","params":{"message":"hi"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	response := map[string]interface{}{}
+	if err := conn.ReadJSON(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response["error"] == nil || response["id"] != float64(1) {
+		t.Fatalf("should have rate limited the call but replied %v", response)
+	}
+}
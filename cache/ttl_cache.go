@@ -0,0 +1,73 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cache
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+type ttlEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// TTL is an LRU cache whose entries also expire once [Duration] has passed
+// since they were put. Expired entries are removed when they are next
+// accessed.
+type TTL struct {
+	lru      LRU
+	Size     int
+	Duration time.Duration
+	clock    timer.Clock
+}
+
+// Put implements the cache interface
+func (c *TTL) Put(key ids.ID, value interface{}) {
+	c.lru.lock.Lock()
+	defer c.lru.lock.Unlock()
+
+	c.lru.Size = c.Size
+	c.lru.put(key, &ttlEntry{
+		value:     value,
+		expiresAt: c.clock.Time().Add(c.Duration),
+	})
+}
+
+// Get implements the cache interface
+func (c *TTL) Get(key ids.ID) (interface{}, bool) {
+	c.lru.lock.Lock()
+	defer c.lru.lock.Unlock()
+
+	c.lru.Size = c.Size
+	value, ok := c.lru.get(key)
+	if !ok {
+		return value, false
+	}
+	entry := value.(*ttlEntry)
+	if !c.clock.Time().Before(entry.expiresAt) {
+		c.lru.evict(key)
+		return struct{}{}, false
+	}
+	return entry.value, true
+}
+
+// Evict implements the cache interface
+func (c *TTL) Evict(key ids.ID) {
+	c.lru.lock.Lock()
+	defer c.lru.lock.Unlock()
+
+	c.lru.Size = c.Size
+	c.lru.evict(key)
+}
+
+// Flush implements the cache interface
+func (c *TTL) Flush() {
+	c.lru.lock.Lock()
+	defer c.lru.lock.Unlock()
+
+	c.lru.flush()
+}
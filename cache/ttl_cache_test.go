@@ -0,0 +1,54 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestTTL(t *testing.T) {
+	cache := TTL{Size: 2, Duration: time.Minute}
+	cache.clock.Set(time.Unix(0, 0))
+
+	id1 := ids.NewID([32]byte{1})
+	id2 := ids.NewID([32]byte{2})
+
+	cache.Put(id1, 1)
+	cache.clock.Advance(30 * time.Second)
+	cache.Put(id2, 2)
+
+	if val, found := cache.Get(id1); !found {
+		t.Fatalf("Failed to retrieve value when one exists")
+	} else if val != 1 {
+		t.Fatalf("Retrieved wrong value")
+	}
+
+	cache.clock.Advance(30 * time.Second)
+
+	if _, found := cache.Get(id1); found {
+		t.Fatalf("Retrieved value after it expired")
+	} else if val, found := cache.Get(id2); !found {
+		t.Fatalf("Failed to retrieve value when one exists")
+	} else if val != 2 {
+		t.Fatalf("Retrieved wrong value")
+	}
+
+	cache.Put(id2, 3)
+	cache.clock.Advance(59 * time.Second)
+
+	if val, found := cache.Get(id2); !found {
+		t.Fatalf("Putting a value should have reset when it expires")
+	} else if val != 3 {
+		t.Fatalf("Retrieved wrong value")
+	}
+
+	cache.Evict(id2)
+
+	if _, found := cache.Get(id2); found {
+		t.Fatalf("Retrieved value when none exists")
+	}
+}
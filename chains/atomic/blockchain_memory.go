@@ -0,0 +1,27 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+)
+
+// BlockchainMemory is a chain's view of the shared memory
+type BlockchainMemory struct {
+	blockchainID ids.ID
+	m            *Memory
+}
+
+// GetDatabase returns the region of the shared memory that this chain shares
+// with [id]. The region is locked until ReleaseDatabase is called.
+func (bm *BlockchainMemory) GetDatabase(id ids.ID) database.Database {
+	return bm.m.GetDatabase(SharedID(bm.blockchainID, id))
+}
+
+// ReleaseDatabase unlocks the region of the shared memory that this chain
+// shares with [id]
+func (bm *BlockchainMemory) ReleaseDatabase(id ids.ID) {
+	bm.m.ReleaseDatabase(SharedID(bm.blockchainID, id))
+}
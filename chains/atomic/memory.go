@@ -0,0 +1,108 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package atomic provides the memory that chains running on this node share.
+// Each pair of chains has its own region of the shared memory, which is how
+// funds are moved from one chain to the other: the exporting chain writes to
+// the region when it accepts an export, and the importing chain reads from it
+// when it verifies an import.
+package atomic
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+type rcLock struct {
+	lock  sync.Mutex
+	count int
+}
+
+// Memory is the memory shared by the chains running on this node
+type Memory struct {
+	log logging.Logger
+	db  database.Database
+
+	lock  sync.Mutex
+	locks map[[32]byte]*rcLock
+}
+
+// Initialize the shared memory, which is persisted in [db]
+func (m *Memory) Initialize(log logging.Logger, db database.Database) {
+	m.log = log
+	m.db = db
+	m.locks = make(map[[32]byte]*rcLock)
+}
+
+// NewBlockchainMemory returns the view of the shared memory that the chain
+// [blockchainID] has
+func (m *Memory) NewBlockchainMemory(blockchainID ids.ID) *BlockchainMemory {
+	return &BlockchainMemory{
+		blockchainID: blockchainID,
+		m:            m,
+	}
+}
+
+// GetDatabase returns the region of the shared memory with ID [sharedID]. The
+// region is locked until ReleaseDatabase is called.
+func (m *Memory) GetDatabase(sharedID ids.ID) database.Database {
+	lock := m.makeLock(sharedID)
+	lock.Lock()
+	return prefixdb.New(sharedID.Bytes(), m.db)
+}
+
+// ReleaseDatabase unlocks the region of the shared memory with ID [sharedID]
+func (m *Memory) ReleaseDatabase(sharedID ids.ID) {
+	lock := m.releaseLock(sharedID)
+	lock.Unlock()
+}
+
+func (m *Memory) makeLock(sharedID ids.ID) *sync.Mutex {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := sharedID.Key()
+	rc, exists := m.locks[key]
+	if !exists {
+		rc = &rcLock{}
+		m.locks[key] = rc
+	}
+	rc.count++
+	return &rc.lock
+}
+
+func (m *Memory) releaseLock(sharedID ids.ID) *sync.Mutex {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := sharedID.Key()
+	rc, exists := m.locks[key]
+	if !exists {
+		m.log.Error("Attempted to release the unlocked shared memory %s", sharedID)
+		return &sync.Mutex{}
+	}
+	rc.count--
+	if rc.count == 0 {
+		delete(m.locks, key)
+	}
+	return &rc.lock
+}
+
+// SharedID returns the ID of the region of the shared memory that [id1] and
+// [id2] share. The order of the arguments doesn't matter.
+func SharedID(id1, id2 ids.ID) ids.ID {
+	idBytes1, idBytes2 := id1.Bytes(), id2.Bytes()
+	if bytes.Compare(idBytes1, idBytes2) > 0 {
+		idBytes1, idBytes2 = idBytes2, idBytes1
+	}
+	combined := make([]byte, 0, len(idBytes1)+len(idBytes2))
+	combined = append(combined, idBytes1...)
+	combined = append(combined, idBytes2...)
+	return ids.NewID(hashing.ComputeHash256Array(combined))
+}
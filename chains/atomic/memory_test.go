@@ -0,0 +1,69 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+var (
+	blockchainID0 = ids.Empty.Prefix(0)
+	blockchainID1 = ids.Empty.Prefix(1)
+	blockchainID2 = ids.Empty.Prefix(2)
+)
+
+func TestSharedID(t *testing.T) {
+	sharedID0 := SharedID(blockchainID0, blockchainID1)
+	sharedID1 := SharedID(blockchainID1, blockchainID0)
+
+	if !sharedID0.Equals(sharedID1) {
+		t.Fatalf("SharedID should be commutative")
+	}
+	if sharedID2 := SharedID(blockchainID0, blockchainID2); sharedID0.Equals(sharedID2) {
+		t.Fatalf("Different chain pairs should have different shared IDs")
+	}
+}
+
+func TestMemorySharedRegions(t *testing.T) {
+	m := Memory{}
+	m.Initialize(logging.NoLog{}, memdb.New())
+
+	bm0 := m.NewBlockchainMemory(blockchainID0)
+	bm1 := m.NewBlockchainMemory(blockchainID1)
+	bm2 := m.NewBlockchainMemory(blockchainID2)
+
+	key := []byte("key")
+	value := []byte("value")
+
+	db := bm0.GetDatabase(blockchainID1)
+	if err := db.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+	bm0.ReleaseDatabase(blockchainID1)
+
+	db = bm1.GetDatabase(blockchainID0)
+	if got, err := db.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(got, value) {
+		t.Fatalf("Wrong value returned from the shared region")
+	}
+	bm1.ReleaseDatabase(blockchainID0)
+
+	db = bm2.GetDatabase(blockchainID0)
+	if has, err := db.Has(key); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("The value shouldn't be visible to a third chain")
+	}
+	bm2.ReleaseDatabase(blockchainID0)
+
+	if len(m.locks) != 0 {
+		t.Fatalf("Released regions shouldn't remain locked")
+	}
+}
@@ -4,13 +4,17 @@
 package chains
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/api/keystore"
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/database"
-	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/consensus/snowball"
@@ -19,13 +23,17 @@ import (
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/snow/engine/common/queue"
 	"github.com/ava-labs/gecko/snow/networking"
+	"github.com/ava-labs/gecko/snow/networking/benchlist"
 	"github.com/ava-labs/gecko/snow/networking/handler"
+	"github.com/ava-labs/gecko/snow/networking/latency"
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/snow/networking/sender"
 	"github.com/ava-labs/gecko/snow/networking/timeout"
 	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/snow/upgrades"
 	"github.com/ava-labs/gecko/snow/validators"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/vms"
 
 	avacon "github.com/ava-labs/gecko/snow/consensus/avalanche"
@@ -37,12 +45,18 @@ import (
 
 const (
 	defaultChannelSize = 1000
-	requestTimeout     = 2 * time.Second
+	// defaultRequestTimeout is used if the adaptive timeout config is invalid
+	defaultRequestTimeout = 2 * time.Second
+)
+
+var (
+	errNotRunning = errors.New("chain isn't running")
 )
 
 // Manager manages the chains running on this node.
 // It can:
 //   * Create a chain
+//   * Stop and restart a chain
 //   * Add a registrant. When a chain is created, each registrant calls
 //     RegisterChain with the new chain as the argument.
 //   * Get the aliases associated with a given chain.
@@ -51,12 +65,22 @@ type Manager interface {
 	// Return the router this Manager is using to route consensus messages to chains
 	Router() router.Router
 
-	// Create a chain in the future
+	// Create a chain in the future, if its subnet is tracked
 	CreateChain(ChainParameters)
 
 	// Create a chain now
 	ForceCreateChain(ChainParameters)
 
+	// Create a chain now, returning why it couldn't be created
+	StartChain(ChainParameters) error
+
+	// Stop a chain: shut down its VM, which flushes its database, and remove
+	// its API endpoints. Its aliases are kept.
+	StopChain(ids.ID) error
+
+	// Stop a chain, then create it again from the same parameters
+	RestartChain(ids.ID) error
+
 	// Add a registrant [r]. Every time a chain is
 	// created, [r].RegisterChain([new chain]) is called
 	AddRegistrant(Registrant)
@@ -73,9 +97,29 @@ type Manager interface {
 	// Add an alias to a chain
 	Alias(ids.ID, string) error
 
+	// Remove an alias from the chain it's associated with
+	RemoveAlias(string) error
+
+	// Return the smoothed round trip time of requests to each validator
+	Latencies() []latency.PeerLatency
+
+	// Return the ID of the subnet that validates a chain
+	SubnetID(ids.ID) (ids.ID, bool)
+
+	// Return the progress of bootstrapping each chain
+	BootstrapProgress() []ChainProgress
+	// Return the progress of pruning each chain that's pruned
+	PruningProgress() []PruningProgress
+
 	Shutdown()
 }
 
+// ChainProgress is the progress of bootstrapping a chain
+type ChainProgress struct {
+	ChainID ids.ID
+	common.ProgressReport
+}
+
 // ChainParameters defines the chain being created
 type ChainParameters struct {
 	ID          ids.ID   // The ID of the chain being created
@@ -102,6 +146,9 @@ type manager struct {
 	sender          sender.ExternalSender // Sends consensus messages to other validators
 	timeoutManager  *timeout.Manager      // Manages request timeouts when sending messages to other validators
 	consensusParams avacon.Parameters     // The consensus parameters (alpha, beta, etc.) for new chains
+	snowballFactory snowball.Factory      // Creates the snowball instances of new snowman chains. If nil, they're trees.
+	traceDir        string                // Directory the consensus of snowman chains is traced to. If empty, it isn't traced.
+	latencyBias     float64               // How much to favor low latency validators when sampling
 	validators      validators.Manager    // Validators validating on this chain
 	registrants     []Registrant          // Those notified when a chain is created
 	nodeID          ids.ShortID           // The ID of this node
@@ -109,15 +156,56 @@ type manager struct {
 	awaiter         Awaiter               // Waits for required connections before running bootstrapping
 	server          *api.Server           // Handles HTTP API calls
 	keystore        *keystore.Keystore
+	sharedMemory    *atomic.Memory
+	upgrades        *upgrades.Manager // Upgrades the chains recognize
+	dbQuotas        DBQuotas          // Limits on how much the chains store
+	pruningConfig   PruningConfig     // When the chains delete the containers they accepted
+	trackedSubnets  ids.Set           // Subnets whose chains are created. If empty, every subnet's are.
 
 	unblocked     bool
 	blockedChains []ChainParameters
+
+	// Chain ID --> ID of the subnet validating the chain. Read by the
+	// networking threads, so guarded by a lock.
+	subnetsLock sync.RWMutex
+	subnets     map[[32]byte]ids.ID
+
+	// Chain ID --> Progress of bootstrapping the chain. Read by the API, so
+	// guarded by a lock.
+	progressLock sync.RWMutex
+	progress     map[[32]byte]*common.Progress
+
+	// Chain ID --> The running chain. Stopped and restarted by the API, so
+	// guarded by a lock.
+	chainsLock sync.Mutex
+	chains     map[[32]byte]*runningChain
+
+	// Chain ID --> Pruner of the chain. Read by the API, so guarded by a lock.
+	pruningLock sync.RWMutex
+	pruners     map[[32]byte]*pruner
+}
+
+// runningChain is a chain this node is running
+type runningChain struct {
+	params  ChainParameters
+	metrics *chainMetrics // nil if metrics aren't registered
+	trace   *os.File      // nil if consensus isn't traced
 }
 
 // New returns a new Manager where:
 //     <db> is this node's database
 //     <sender> sends messages to other validators
+//     <timeoutConfig> determines how long requests to other validators may take
+//     <benchlistConfig> determines when unresponsive validators stop being queried
+//     <snowballFactory> creates the snowball instances of snowman chains, or trees if it's nil
+//     <traceDir> is where the consensus of each snowman chain is traced, if it's not empty
+//     <latencyBias> is the largest fraction of stake a slow validator loses when sampling
 //     <validators> validate this chain
+//     <sharedMemory> is the memory that the chains running on this node share
+//     <upgrades> is where the chains register the upgrades they recognize
+//     <dbQuotas> limit how many bytes each chain may store in <db>
+//     <pruningConfig> determines when chains delete the containers they accepted from <db>
+//     <trackedSubnets> are the subnets whose chains are created, or every subnet if it's empty
 // TODO: Make this function take less arguments
 func New(
 	log logging.Logger,
@@ -129,15 +217,41 @@ func New(
 	router router.Router,
 	sender sender.ExternalSender,
 	consensusParams avacon.Parameters,
+	snowballFactory snowball.Factory,
+	traceDir string,
+	timeoutConfig timer.AdaptiveTimeoutConfig,
+	peerTimeoutConfig timeout.PeerTimeoutConfig,
+	benchlistConfig benchlist.Config,
+	latencyBias float64,
 	validators validators.Manager,
 	nodeID ids.ShortID,
 	networkID uint32,
 	awaiter Awaiter,
 	server *api.Server,
 	keystore *keystore.Keystore,
+	sharedMemory *atomic.Memory,
+	upgrades *upgrades.Manager,
+	dbQuotas DBQuotas,
+	pruningConfig PruningConfig,
+	trackedSubnets ids.Set,
 ) Manager {
+	bench, err := benchlist.New(benchlistConfig, "gecko", consensusParams.Metrics)
+	if err != nil {
+		log.Error("Failed to initialize the benchlist due to %s", err)
+		bench = benchlist.NewNoBenchlist()
+	}
+
 	timeoutManager := timeout.Manager{}
-	timeoutManager.Initialize(requestTimeout)
+	if err := timeoutManager.InitializeAdaptive(timeoutConfig, bench); err != nil {
+		log.Error("Failed to initialize the adaptive request timeout due to %s", err)
+		timeoutManager.Initialize(defaultRequestTimeout, bench)
+	}
+	if err := timeoutManager.InitializePeerTimeouts(peerTimeoutConfig); err != nil {
+		log.Error("Failed to initialize the request timeouts of each validator due to %s", err)
+	}
+	if err := timeoutManager.InitializeMetrics("gecko", consensusParams.Metrics); err != nil {
+		log.Error("Failed to register the request timeout metrics due to %s", err)
+	}
 	go log.RecoverAndPanic(timeoutManager.Dispatch)
 
 	router.Initialize(log, &timeoutManager)
@@ -153,12 +267,24 @@ func New(
 		sender:          sender,
 		timeoutManager:  &timeoutManager,
 		consensusParams: consensusParams,
+		snowballFactory: snowballFactory,
+		traceDir:        traceDir,
+		latencyBias:     latencyBias,
 		validators:      validators,
 		nodeID:          nodeID,
 		networkID:       networkID,
 		awaiter:         awaiter,
 		server:          server,
 		keystore:        keystore,
+		sharedMemory:    sharedMemory,
+		upgrades:        upgrades,
+		dbQuotas:        dbQuotas,
+		pruningConfig:   pruningConfig,
+		trackedSubnets:  trackedSubnets,
+		subnets:         make(map[[32]byte]ids.ID),
+		progress:        make(map[[32]byte]*common.Progress),
+		chains:          make(map[[32]byte]*runningChain),
+		pruners:         make(map[[32]byte]*pruner),
 	}
 	m.Initialize()
 	return m
@@ -167,8 +293,41 @@ func New(
 // Router that this chain manager is using to route consensus messages to chains
 func (m *manager) Router() router.Router { return m.chainRouter }
 
-// Create a chain
+// Latencies returns the smoothed round trip time of requests to each validator
+func (m *manager) Latencies() []latency.PeerLatency {
+	return m.timeoutManager.Latencies().Latencies()
+}
+
+// SubnetID returns the ID of the subnet that validates [chainID]
+func (m *manager) SubnetID(chainID ids.ID) (ids.ID, bool) {
+	m.subnetsLock.RLock()
+	defer m.subnetsLock.RUnlock()
+
+	subnetID, exists := m.subnets[chainID.Key()]
+	return subnetID, exists
+}
+
+// BootstrapProgress returns the progress of bootstrapping each chain
+func (m *manager) BootstrapProgress() []ChainProgress {
+	m.progressLock.RLock()
+	defer m.progressLock.RUnlock()
+
+	chains := make([]ChainProgress, 0, len(m.progress))
+	for chainKey, progress := range m.progress {
+		chains = append(chains, ChainProgress{
+			ChainID:        ids.NewID(chainKey),
+			ProgressReport: progress.Report(),
+		})
+	}
+	return chains
+}
+
+// Create a chain, unless its subnet isn't tracked
 func (m *manager) CreateChain(chain ChainParameters) {
+	if m.trackedSubnets.Len() != 0 && !m.trackedSubnets.Contains(chain.SubnetID) {
+		m.log.Info("not creating chain %s because its subnet, %s, isn't tracked", chain.ID, chain.SubnetID)
+		return
+	}
 	if !m.unblocked {
 		m.blockedChains = append(m.blockedChains, chain)
 	} else {
@@ -178,6 +337,13 @@ func (m *manager) CreateChain(chain ChainParameters) {
 
 // Create a chain
 func (m *manager) ForceCreateChain(chain ChainParameters) {
+	if err := m.StartChain(chain); err != nil {
+		m.log.Error("chain %s not created: %s", chain.ID, err)
+	}
+}
+
+// StartChain creates a chain now
+func (m *manager) StartChain(chain ChainParameters) error {
 	m.log.Info("creating chain:\n"+
 		"    ID: %s\n"+
 		"    VMID:%s",
@@ -188,21 +354,18 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 	// Assert that there isn't already a chain with an alias in [chain].Aliases
 	// (Recall that the string repr. of a chain's ID is also an alias for a chain)
 	if alias, isRepeat := m.isChainWithAlias(chain.ID.String()); isRepeat {
-		m.log.Error("there is already a chain with alias '%s'. Chain not created.", alias)
-		return
+		return fmt.Errorf("there is already a chain with alias '%s'", alias)
 	}
 
 	vmID, err := m.vmManager.Lookup(chain.VMAlias)
 	if err != nil {
-		m.log.Error("error while looking up VM: %s", err)
-		return
+		return fmt.Errorf("error while looking up VM: %w", err)
 	}
 
 	// Get a factory for the vm we want to use on our chain
 	vmFactory, err := m.vmManager.GetVMFactory(vmID)
 	if err != nil {
-		m.log.Error("error while getting vmFactory: %s", err)
-		return
+		return fmt.Errorf("error while getting vmFactory: %w", err)
 	}
 
 	// Create the chain
@@ -212,15 +375,13 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 	for i, fxAlias := range chain.FxAliases {
 		fxID, err := m.vmManager.Lookup(fxAlias)
 		if err != nil {
-			m.log.Error("error while looking up Fx: %s", err)
-			return
+			return fmt.Errorf("error while looking up Fx: %w", err)
 		}
 
 		// Get a factory for the fx we want to use on our chain
 		fxFactory, err := m.vmManager.GetVMFactory(fxID)
 		if err != nil {
-			m.log.Error("error while getting fxFactory: %s", err)
-			return
+			return fmt.Errorf("error while getting fxFactory: %w", err)
 		}
 
 		// Create the fx
@@ -233,8 +394,7 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 	// Create the log and context of the chain
 	chainLog, err := m.logFactory.MakeChain(chain.ID, "")
 	if err != nil {
-		m.log.Error("error while creating chain's log %s", err)
-		return
+		return fmt.Errorf("error while creating chain's log: %w", err)
 	}
 
 	ctx := &snow.Context{
@@ -246,7 +406,9 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 		NodeID:              m.nodeID,
 		HTTP:                m.server,
 		Keystore:            m.keystore.NewBlockchainKeyStore(chain.ID),
+		SharedMemory:        m.sharedMemory.NewBlockchainMemory(chain.ID),
 		BCLookup:            m,
+		Upgrades:            m.upgrades,
 	}
 	consensusParams := m.consensusParams
 	if alias, err := m.PrimaryAlias(ctx.ChainID); err == nil {
@@ -254,19 +416,41 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 	} else {
 		consensusParams.Namespace = fmt.Sprintf("gecko_%s", ctx.ChainID)
 	}
+	// The chain's metrics are remembered so they can be unregistered if the
+	// chain is stopped
+	var metrics *chainMetrics
+	if consensusParams.Metrics != nil {
+		metrics = &chainMetrics{Registerer: consensusParams.Metrics}
+		consensusParams.Metrics = metrics
+	}
+	ctx.Namespace = consensusParams.Namespace
+	ctx.Metrics = consensusParams.Metrics
 
 	// The validators of this blockchain
-	validators, ok := m.validators.GetValidatorSet(ids.Empty) // TODO: Change argument to chain.SubnetID
+	validators, ok := m.validators.GetValidatorSet(chain.SubnetID)
 	if !ok {
-		m.log.Error("couldn't get validator set of subnet with ID %s. The subnet may not exist", chain.SubnetID)
-		return
+		return fmt.Errorf("couldn't get validator set of subnet with ID %s. The subnet may not exist", chain.SubnetID)
 	}
 
+	m.subnetsLock.Lock()
+	m.subnets[chain.ID.Key()] = chain.SubnetID
+	m.subnetsLock.Unlock()
+
 	beacons := validators
 	if chain.CustomBeacons != nil {
 		beacons = chain.CustomBeacons
 	}
 
+	// Bootstrapping samples the beacons, so only bias the consensus queries
+	if m.latencyBias > 0 {
+		validators = latency.NewBiasedSet(validators, m.timeoutManager.Latencies(), m.latencyBias)
+	}
+
+	// Initialized by the chain's bootstrapper
+	progress := &common.Progress{}
+
+	// Only the consensus of snowman chains is traced
+	var trace *os.File
 	switch vm := vm.(type) {
 	case avalanche.DAGVM:
 		err := m.createAvalancheChain(
@@ -277,13 +461,19 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 			vm,
 			fxs,
 			consensusParams,
+			progress,
 		)
 		if err != nil {
-			m.log.Error("error while creating new avalanche vm %s", err)
-			return
+			m.unregisterMetrics(metrics)
+			return fmt.Errorf("error while creating new avalanche vm: %w", err)
 		}
 	case smeng.ChainVM:
-		err := m.createSnowmanChain(
+		trace, err = m.openTrace(chain.ID)
+		if err != nil {
+			m.unregisterMetrics(metrics)
+			return fmt.Errorf("error while opening the consensus trace: %w", err)
+		}
+		err = m.createSnowmanChain(
 			ctx,
 			chain.GenesisData,
 			validators,
@@ -291,21 +481,114 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 			vm,
 			fxs,
 			consensusParams.Parameters,
+			progress,
+			trace,
 		)
 		if err != nil {
-			m.log.Error("error while creating new snowman vm %s", err)
-			return
+			m.unregisterMetrics(metrics)
+			m.closeTrace(trace)
+			return fmt.Errorf("error while creating new snowman vm: %w", err)
 		}
 	default:
-		m.log.Error("the vm should have type avalanche.DAGVM or snowman.ChainVM. Chain not created")
-		return
+		return errors.New("the vm should have type avalanche.DAGVM or snowman.ChainVM")
 	}
 
+	m.progressLock.Lock()
+	m.progress[chain.ID.Key()] = progress
+	m.progressLock.Unlock()
+
+	m.chainsLock.Lock()
+	m.chains[chain.ID.Key()] = &runningChain{params: chain, metrics: metrics, trace: trace}
+	m.chainsLock.Unlock()
+
 	// Associate the newly created chain with its default alias
 	m.log.AssertNoError(m.Alias(chain.ID, chain.ID.String()))
 
 	// Notify those that registered to be notified when a new chain is created
 	m.notifyRegistrants(ctx, vm)
+	return nil
+}
+
+// StopChain stops the chain [chainID]. It can be started again by RestartChain.
+func (m *manager) StopChain(chainID ids.ID) error {
+	m.chainsLock.Lock()
+	running, exists := m.chains[chainID.Key()]
+	delete(m.chains, chainID.Key())
+	m.chainsLock.Unlock()
+	if !exists {
+		return errNotRunning
+	}
+
+	m.log.Info("stopping chain %s", chainID)
+
+	// Calls to the chain's API stop before the chain does, so they don't reach
+	// a VM that was shut down
+	m.server.RemoveChain(chainID)
+	m.chainRouter.RemoveChain(chainID)
+	m.stopPruning(chainID)
+	m.unregisterMetrics(running.metrics)
+	m.closeTrace(running.trace)
+
+	m.progressLock.Lock()
+	delete(m.progress, chainID.Key())
+	m.progressLock.Unlock()
+
+	m.subnetsLock.Lock()
+	delete(m.subnets, chainID.Key())
+	m.subnetsLock.Unlock()
+
+	// The chain's ID is its default alias, which is given to it again when it's
+	// created. Its other aliases remain.
+	return m.RemoveAlias(chainID.String())
+}
+
+// RestartChain stops the chain [chainID] and creates it again
+func (m *manager) RestartChain(chainID ids.ID) error {
+	m.chainsLock.Lock()
+	running, exists := m.chains[chainID.Key()]
+	m.chainsLock.Unlock()
+	if !exists {
+		return errNotRunning
+	}
+
+	if err := m.StopChain(chainID); err != nil {
+		return err
+	}
+	return m.StartChain(running.params)
+}
+
+// openTrace opens the file that the consensus of chain [chainID] is traced to,
+// or returns nil if consensus isn't traced. Each run of a chain is traced to
+// its own file, named by when the run started, in the chain's directory.
+func (m *manager) openTrace(chainID ids.ID) (*os.File, error) {
+	if m.traceDir == "" {
+		return nil, nil
+	}
+	dir := filepath.Join(m.traceDir, chainID.String())
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(
+		filepath.Join(dir, fmt.Sprintf("%d.trace", time.Now().UnixNano())),
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0600,
+	)
+}
+
+// closeTrace closes the file a chain's consensus was traced to, if any
+func (m *manager) closeTrace(trace *os.File) {
+	if trace != nil {
+		if err := trace.Close(); err != nil {
+			m.log.Warn("Failed to close consensus trace %s due to %s", trace.Name(), err)
+		}
+	}
+}
+
+// unregisterMetrics unregisters the metrics a chain registered, if any
+func (m *manager) unregisterMetrics(metrics *chainMetrics) {
+	if metrics != nil {
+		metrics.unregisterAll()
+	}
 }
 
 // Implements Manager.AddRegistrant
@@ -329,15 +612,16 @@ func (m *manager) createAvalancheChain(
 	vm avalanche.DAGVM,
 	fxs []*common.Fx,
 	consensusParams avacon.Parameters,
+	progress *common.Progress,
 ) error {
 	ctx.Lock.Lock()
 	defer ctx.Lock.Unlock()
 
-	db := prefixdb.New(ctx.ChainID.Bytes(), m.db)
-	vmDB := prefixdb.New([]byte("vm"), db)
-	vertexDB := prefixdb.New([]byte("vertex"), db)
-	vertexBootstrappingDB := prefixdb.New([]byte("vertex_bootstrapping"), db)
-	txBootstrappingDB := prefixdb.New([]byte("tx_bootstrapping"), db)
+	dbs, err := m.chainDBs(ctx, avalancheDBNames...)
+	if err != nil {
+		return err
+	}
+	vmDB, vertexDB, vertexBootstrappingDB, txBootstrappingDB, pruningDB := dbs[0], dbs[1], dbs[2], dbs[3], dbs[4]
 
 	vtxBlocker, err := queue.New(vertexBootstrappingDB)
 	if err != nil {
@@ -355,6 +639,9 @@ func (m *manager) createAvalancheChain(
 	if err := vm.Initialize(ctx, vmDB, genesisData, msgChan, fxs); err != nil {
 		return err
 	}
+	if err := vm.Metrics(consensusParams.Metrics); err != nil {
+		return err
+	}
 
 	// Handles serialization/deserialization of vertices and also the
 	// persistence of vertices
@@ -376,7 +663,7 @@ func (m *manager) createAvalancheChain(
 		},
 	}
 
-	engine.Initialize(avaeng.Config{
+	engineConfig := avaeng.Config{
 		BootstrapConfig: avaeng.BootstrapConfig{
 			Config: common.Config{
 				Context:    ctx,
@@ -384,6 +671,7 @@ func (m *manager) createAvalancheChain(
 				Beacons:    beacons,
 				Alpha:      (beacons.Len() + 1) / 2,
 				Sender:     &sender,
+				Progress:   progress,
 			},
 			VtxBlocked: vtxBlocker,
 			TxBlocked:  txBlocker,
@@ -392,11 +680,24 @@ func (m *manager) createAvalancheChain(
 		},
 		Params:    consensusParams,
 		Consensus: &avacon.Topological{},
-	})
+	}
+	if err := engineConfig.Valid(); err != nil {
+		return err
+	}
+	engine.Initialize(engineConfig)
 
 	// Asynchronously passes messages from the network to the consensus engine
 	handler := &handler.Handler{}
-	handler.Initialize(&engine, msgChan, defaultChannelSize)
+	if err := handler.Initialize(&engine, msgChan, defaultChannelSize, consensusParams.Namespace, consensusParams.Metrics); err != nil {
+		return err
+	}
+
+	// Vertices are pruned by the serializer, since it stores them
+	if m.pruningConfig.Enabled {
+		if err := m.startPruning(ctx, pruningDB, vtxState.PruneVertex); err != nil {
+			return err
+		}
+	}
 
 	// Allows messages to be routed to the new chain
 	m.chainRouter.AddChain(handler)
@@ -428,13 +729,17 @@ func (m *manager) createSnowmanChain(
 	vm smeng.ChainVM,
 	fxs []*common.Fx,
 	consensusParams snowball.Parameters,
+	progress *common.Progress,
+	trace *os.File,
 ) error {
 	ctx.Lock.Lock()
 	defer ctx.Lock.Unlock()
 
-	db := prefixdb.New(ctx.ChainID.Bytes(), m.db)
-	vmDB := prefixdb.New([]byte("vm"), db)
-	bootstrappingDB := prefixdb.New([]byte("bootstrapping"), db)
+	dbs, err := m.chainDBs(ctx, snowmanDBNames...)
+	if err != nil {
+		return err
+	}
+	vmDB, bootstrappingDB, pruningDB := dbs[0], dbs[1], dbs[2]
 
 	blocked, err := queue.New(bootstrappingDB)
 	if err != nil {
@@ -449,14 +754,23 @@ func (m *manager) createSnowmanChain(
 	if err := vm.Initialize(ctx, vmDB, genesisData, msgChan, fxs); err != nil {
 		return err
 	}
+	if err := vm.Metrics(consensusParams.Metrics); err != nil {
+		return err
+	}
 
 	// Passes messages from the consensus engine to the network
 	sender := sender.Sender{}
 	sender.Initialize(ctx, m.sender, m.chainRouter, m.timeoutManager)
 
+	// Each chain's trace records the calls to its own snowball instances
+	snowballFactory := m.snowballFactory
+	if trace != nil {
+		snowballFactory = snowball.NewTracedFactory(snowballFactory, trace)
+	}
+
 	// The engine handles consensus
 	engine := smeng.Transitive{}
-	engine.Initialize(smeng.Config{
+	engineConfig := smeng.Config{
 		BootstrapConfig: smeng.BootstrapConfig{
 			Config: common.Config{
 				Context:    ctx,
@@ -464,18 +778,36 @@ func (m *manager) createSnowmanChain(
 				Beacons:    beacons,
 				Alpha:      (beacons.Len() + 1) / 2,
 				Sender:     &sender,
+				Progress:   progress,
 			},
 			Blocked:      blocked,
 			VM:           vm,
 			Bootstrapped: m.unblockChains,
 		},
 		Params:    consensusParams,
-		Consensus: &smcon.Topological{},
-	})
+		Consensus: &smcon.Topological{SnowballFactory: snowballFactory},
+	}
+	if err := engineConfig.Valid(); err != nil {
+		return err
+	}
+	engine.Initialize(engineConfig)
 
 	// Asynchronously passes messages from the network to the consensus engine
 	handler := &handler.Handler{}
-	handler.Initialize(&engine, msgChan, defaultChannelSize)
+	if err := handler.Initialize(&engine, msgChan, defaultChannelSize, consensusParams.Namespace, consensusParams.Metrics); err != nil {
+		return err
+	}
+
+	// Blocks are stored by the VM, so they're only pruned if it supports it
+	if m.pruningConfig.Enabled {
+		if vm, ok := vm.(common.PrunableVM); ok {
+			if err := m.startPruning(ctx, pruningDB, vm.PruneContainer); err != nil {
+				return err
+			}
+		} else {
+			ctx.Log.Info("not pruning the chain because its VM doesn't support pruning")
+		}
+	}
 
 	// Allow incoming messages to be routed to the new chain
 	m.chainRouter.AddChain(handler)
@@ -0,0 +1,53 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// chainMetrics registers the metrics of a chain, and remembers them so that
+// they can be unregistered when the chain stops. Otherwise, the chain's metrics
+// couldn't be registered again when it's restarted.
+type chainMetrics struct {
+	prometheus.Registerer
+
+	lock       sync.Mutex
+	collectors []prometheus.Collector
+}
+
+// Register implements the prometheus.Registerer interface
+func (m *chainMetrics) Register(collector prometheus.Collector) error {
+	if err := m.Registerer.Register(collector); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.collectors = append(m.collectors, collector)
+	return nil
+}
+
+// MustRegister implements the prometheus.Registerer interface
+func (m *chainMetrics) MustRegister(collectors ...prometheus.Collector) {
+	for _, collector := range collectors {
+		if err := m.Register(collector); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// unregisterAll unregisters every metric the chain registered
+func (m *chainMetrics) unregisterAll() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, collector := range m.collectors {
+		m.Registerer.Unregister(collector)
+	}
+	m.collectors = nil
+}
@@ -0,0 +1,310 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// maxPruneBatch is the most containers pruned each time a container is
+	// accepted, so a chain that falls behind catches up without stalling
+	// consensus
+	maxPruneBatch = 16
+
+	// pruningHandler identifies pruners to the consensus dispatcher
+	pruningHandler = "pruning"
+
+	// entryLen is the length of a stored container ID and acceptance time
+	entryLen = 32 + wrappers.LongLen
+)
+
+var (
+	errZeroPruningDepth      = errors.New("pruning depth must be at least 1")
+	errNegativeHistoryWindow = errors.New("pruning history window must be non-negative")
+	errBadPruningEntry       = errors.New("stored pruning entry is malformed")
+
+	headKey   = []byte("head")
+	nextKey   = []byte("next")
+	prunedKey = []byte("pruned")
+)
+
+// PruningConfig determines when the bytes of accepted containers are deleted
+// from the database. Containers that are deleted can't be served to peers
+// that are bootstrapping, so they're kept until they're both deep and old.
+type PruningConfig struct {
+	// If false, containers are never pruned
+	Enabled bool
+
+	// Number of containers that must be accepted after a container before it
+	// may be pruned
+	Depth uint64
+
+	// How long after a container is accepted it must be kept, so peers that
+	// are bootstrapping can still fetch it
+	HistoryWindow time.Duration
+}
+
+// Valid returns nil if the config can be used to prune chains
+func (c PruningConfig) Valid() error {
+	switch {
+	case !c.Enabled:
+		return nil
+	case c.Depth == 0:
+		return errZeroPruningDepth
+	case c.HistoryWindow < 0:
+		return errNegativeHistoryWindow
+	default:
+		return nil
+	}
+}
+
+// PruningProgress is the progress of pruning a chain
+type PruningProgress struct {
+	ChainID ids.ID
+
+	// Number of containers this node has pruned from the chain
+	Pruned uint64
+
+	// Number of accepted containers that are kept until they're deep and old
+	// enough to be pruned
+	Retained uint64
+
+	// The error that pruning last failed with, or nil if it succeeded
+	Err error
+}
+
+// pruner deletes the bytes of the containers a chain accepts once they're
+// deep and old enough. The containers waiting to be pruned are stored in
+// order in its database, so pruning resumes where it left off after a
+// restart. Containers accepted while the chain was bootstrapping aren't
+// reported to the consensus dispatcher, so they're never pruned.
+type pruner struct {
+	log    logging.Logger
+	config PruningConfig
+	clock  timer.Clock
+	db     database.Database
+
+	// prune deletes the bytes of an accepted container. If it fails, the
+	// container is kept and isn't tried again.
+	prune func(ids.ID) error
+
+	// The containers with sequence numbers in [head, next) are retained.
+	// Accept is called by the chain while progress is read by the API, so
+	// they're guarded by a lock.
+	lock       sync.Mutex
+	head, next uint64
+	pruned     uint64
+	err        error
+
+	numPruned   prometheus.Counter
+	numRetained prometheus.Gauge
+}
+
+// newPruner returns a pruner of the containers accepted by the chain in [ctx]
+// that deletes them with [prune] and stores its queue in [db]
+func newPruner(ctx *snow.Context, config PruningConfig, db database.Database, prune func(ids.ID) error) (*pruner, error) {
+	p := &pruner{
+		log:    ctx.Log,
+		config: config,
+		db:     db,
+		prune:  prune,
+		numPruned: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ctx.Namespace,
+			Name:      "pruned_containers",
+			Help:      "Number of accepted containers whose bytes were deleted from the database",
+		}),
+		numRetained: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: ctx.Namespace,
+			Name:      "retained_containers",
+			Help:      "Number of accepted containers kept until they're deep and old enough to be pruned",
+		}),
+	}
+
+	var err error
+	if p.head, err = p.getLong(headKey); err != nil {
+		return nil, err
+	}
+	if p.next, err = p.getLong(nextKey); err != nil {
+		return nil, err
+	}
+	if p.pruned, err = p.getLong(prunedKey); err != nil {
+		return nil, err
+	}
+	p.numRetained.Set(float64(p.next - p.head))
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		ctx.Metrics.Register(p.numPruned),
+		ctx.Metrics.Register(p.numRetained),
+	)
+	return p, errs.Err
+}
+
+// Accept implements the triggers.Acceptor interface. It records that
+// [containerID] was accepted, and prunes the containers that are now deep and
+// old enough.
+func (p *pruner) Accept(chainID, containerID ids.ID, container []byte) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.err = p.accept(containerID)
+	return p.err
+}
+
+// Progress returns how many containers have been pruned and are retained
+func (p *pruner) Progress() PruningProgress {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return PruningProgress{
+		Pruned:   p.pruned,
+		Retained: p.next - p.head,
+		Err:      p.err,
+	}
+}
+
+func (p *pruner) accept(containerID ids.ID) error {
+	now := p.clock.Time()
+	batch := p.db.NewBatch()
+
+	entry := wrappers.Packer{Bytes: make([]byte, entryLen)}
+	entry.PackFixedBytes(containerID.Bytes())
+	entry.PackLong(uint64(now.Unix()))
+	if err := batch.Put(seqKey(p.next), entry.Bytes); err != nil {
+		return err
+	}
+
+	head, next, pruned := p.head, p.next+1, p.pruned
+	for i := 0; i < maxPruneBatch && next-head > p.config.Depth; i++ {
+		entryBytes, err := p.db.Get(seqKey(head))
+		if err != nil {
+			return err
+		}
+		entry := wrappers.Packer{Bytes: entryBytes}
+		retainedIDBytes := entry.UnpackFixedBytes(32)
+		acceptedAt := time.Unix(int64(entry.UnpackLong()), 0)
+		if entry.Errored() || entry.Offset != entryLen {
+			return errBadPruningEntry
+		}
+		retainedID, err := ids.ToID(retainedIDBytes)
+		if err != nil {
+			return err
+		}
+		if now.Sub(acceptedAt) < p.config.HistoryWindow {
+			break
+		}
+
+		if err := p.prune(retainedID); err != nil {
+			p.log.Debug("keeping container %s because pruning it failed due to %s", retainedID, err)
+		} else {
+			pruned++
+		}
+		if err := batch.Delete(seqKey(head)); err != nil {
+			return err
+		}
+		head++
+	}
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		batch.Put(headKey, longBytes(head)),
+		batch.Put(nextKey, longBytes(next)),
+		batch.Put(prunedKey, longBytes(pruned)),
+	)
+	if errs.Errored() {
+		return errs.Err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	p.numPruned.Add(float64(pruned - p.pruned))
+	p.head, p.next, p.pruned = head, next, pruned
+	p.numRetained.Set(float64(next - head))
+	return nil
+}
+
+// getLong returns the number stored at [key], or 0 if there is none
+func (p *pruner) getLong(key []byte) (uint64, error) {
+	b, err := p.db.Get(key)
+	if err == database.ErrNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	packer := wrappers.Packer{Bytes: b}
+	value := packer.UnpackLong()
+	return value, packer.Err
+}
+
+// seqKey returns the key that the container with sequence number [seq] is
+// stored at. Its prefix keeps it apart from the other keys.
+func seqKey(seq uint64) []byte {
+	packer := wrappers.Packer{Bytes: make([]byte, 1+wrappers.LongLen)}
+	packer.PackByte('c')
+	packer.PackLong(seq)
+	return packer.Bytes
+}
+
+func longBytes(value uint64) []byte {
+	packer := wrappers.Packer{Bytes: make([]byte, wrappers.LongLen)}
+	packer.PackLong(value)
+	return packer.Bytes
+}
+
+// startPruning prunes the containers the chain in [ctx] accepts with [prune],
+// storing its queue in [db]
+func (m *manager) startPruning(ctx *snow.Context, db database.Database, prune func(ids.ID) error) error {
+	p, err := newPruner(ctx, m.pruningConfig, db, prune)
+	if err != nil {
+		return err
+	}
+	if err := m.consensusEvents.RegisterChain(ctx.ChainID, pruningHandler, p); err != nil {
+		return err
+	}
+
+	m.pruningLock.Lock()
+	defer m.pruningLock.Unlock()
+
+	m.pruners[ctx.ChainID.Key()] = p
+	return nil
+}
+
+// stopPruning stops pruning the chain [chainID], if it's being pruned
+func (m *manager) stopPruning(chainID ids.ID) {
+	m.pruningLock.Lock()
+	_, pruning := m.pruners[chainID.Key()]
+	delete(m.pruners, chainID.Key())
+	m.pruningLock.Unlock()
+
+	if pruning {
+		m.log.AssertNoError(m.consensusEvents.DeregisterChain(chainID, pruningHandler))
+	}
+}
+
+// PruningProgress returns the progress of pruning each chain that's pruned
+func (m *manager) PruningProgress() []PruningProgress {
+	m.pruningLock.RLock()
+	defer m.pruningLock.RUnlock()
+
+	chains := make([]PruningProgress, 0, len(m.pruners))
+	for chainKey, p := range m.pruners {
+		progress := p.Progress()
+		progress.ChainID = ids.NewID(chainKey)
+		chains = append(chains, progress)
+	}
+	return chains
+}
@@ -0,0 +1,152 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+)
+
+// testPruner returns a pruner that records the containers it prunes in
+// [pruned]
+func testPruner(t *testing.T, config PruningConfig, db database.Database, pruned *[]ids.ID) *pruner {
+	ctx := snow.DefaultContextTest()
+	ctx.Metrics = prometheus.NewRegistry()
+	p, err := newPruner(ctx, config, db, func(containerID ids.ID) error {
+		*pruned = append(*pruned, containerID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.clock.Set(time.Unix(1000, 0))
+	return p
+}
+
+func TestPrunerDepth(t *testing.T) {
+	pruned := []ids.ID(nil)
+	p := testPruner(t, PruningConfig{Enabled: true, Depth: 2}, memdb.New(), &pruned)
+
+	containerIDs := []ids.ID{}
+	for i := byte(0); i < 5; i++ {
+		containerIDs = append(containerIDs, ids.NewID([32]byte{i}))
+		if err := p.Accept(ids.Empty, containerIDs[i], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The last two containers are kept
+	if len(pruned) != 3 {
+		t.Fatalf("Should have pruned 3 containers but pruned %d", len(pruned))
+	}
+	for i, containerID := range pruned {
+		if !containerID.Equals(containerIDs[i]) {
+			t.Fatalf("Should have pruned %s but pruned %s", containerIDs[i], containerID)
+		}
+	}
+
+	progress := p.Progress()
+	if progress.Pruned != 3 || progress.Retained != 2 || progress.Err != nil {
+		t.Fatalf("Unexpected progress %+v", progress)
+	}
+	if numPruned := testutil.ToFloat64(p.numPruned); numPruned != 3 {
+		t.Fatalf("Expected 3 pruned containers but found %v", numPruned)
+	}
+	if numRetained := testutil.ToFloat64(p.numRetained); numRetained != 2 {
+		t.Fatalf("Expected 2 retained containers but found %v", numRetained)
+	}
+}
+
+func TestPrunerHistoryWindow(t *testing.T) {
+	pruned := []ids.ID(nil)
+	p := testPruner(t, PruningConfig{Enabled: true, Depth: 1, HistoryWindow: time.Hour}, memdb.New(), &pruned)
+
+	start := p.clock.Time()
+	for i := byte(0); i < 3; i++ {
+		if err := p.Accept(ids.Empty, ids.NewID([32]byte{i}), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(pruned) != 0 {
+		t.Fatalf("Shouldn't have pruned containers in the history window")
+	}
+
+	// Once the window passes, the containers that are deep enough are pruned
+	p.clock.Set(start.Add(time.Hour))
+	if err := p.Accept(ids.Empty, ids.NewID([32]byte{3}), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 3 {
+		t.Fatalf("Should have pruned 3 containers but pruned %d", len(pruned))
+	}
+}
+
+func TestPrunerRestart(t *testing.T) {
+	db := memdb.New()
+	pruned := []ids.ID(nil)
+	config := PruningConfig{Enabled: true, Depth: 2}
+	p := testPruner(t, config, db, &pruned)
+	for i := byte(0); i < 3; i++ {
+		if err := p.Accept(ids.Empty, ids.NewID([32]byte{i}), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The queue is read back from the database
+	p = testPruner(t, config, db, &pruned)
+	if progress := p.Progress(); progress.Pruned != 1 || progress.Retained != 2 {
+		t.Fatalf("Unexpected progress after restarting %+v", progress)
+	}
+	if err := p.Accept(ids.Empty, ids.NewID([32]byte{3}), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 2 || !pruned[1].Equals(ids.NewID([32]byte{1})) {
+		t.Fatalf("Should have resumed pruning where it left off")
+	}
+}
+
+func TestPrunerKeepsFailedContainers(t *testing.T) {
+	ctx := snow.DefaultContextTest()
+	ctx.Metrics = prometheus.NewRegistry()
+	p, err := newPruner(ctx, PruningConfig{Enabled: true, Depth: 1}, memdb.New(), func(ids.ID) error {
+		return errors.New("can't be pruned")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := byte(0); i < 3; i++ {
+		if err := p.Accept(ids.Empty, ids.NewID([32]byte{i}), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Containers that can't be pruned are given up on rather than retried
+	if progress := p.Progress(); progress.Pruned != 0 || progress.Retained != 1 {
+		t.Fatalf("Unexpected progress %+v", progress)
+	}
+}
+
+func TestPruningConfigValid(t *testing.T) {
+	invalid := []PruningConfig{
+		{Enabled: true},
+		{Enabled: true, Depth: 1, HistoryWindow: -time.Second},
+	}
+	for _, config := range invalid {
+		if err := config.Valid(); err == nil {
+			t.Fatalf("Config %+v should have been invalid", config)
+		}
+	}
+	if err := (PruningConfig{}).Valid(); err != nil {
+		t.Fatalf("A disabled config should have been valid but failed with %s", err)
+	}
+}
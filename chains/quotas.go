@@ -0,0 +1,92 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/quotadb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	// avalancheDBNames are the databases of a chain run by Avalanche
+	avalancheDBNames = []string{"vm", "vertex", "vertex_bootstrapping", "tx_bootstrapping", "pruning"}
+
+	// snowmanDBNames are the databases of a chain run by Snowman
+	snowmanDBNames = []string{"vm", "bootstrapping", "pruning"}
+)
+
+// DBQuotas are the limits on the number of bytes that chains may store in this
+// node's database. A limit of 0 means the chain is unlimited.
+type DBQuotas struct {
+	// Limit of every chain that doesn't have its own limit
+	Default uint64
+
+	// Key: ID or alias of a chain
+	// Value: Limit of the chain
+	Chains map[string]uint64
+}
+
+// quota returns the limit of the chain with ID or aliases [aliases]
+func (q DBQuotas) quota(aliases []string) uint64 {
+	for _, alias := range aliases {
+		if limit, exists := q.Chains[alias]; exists {
+			return limit
+		}
+	}
+	return q.Default
+}
+
+// DBPrefixes returns the prefixes of the node's database that the databases of
+// the chain [chainID] store their keys under. prefixdb.New collapses nested
+// prefixes, so each database's prefix is the hash of the chain's prefix and the
+// database's name. The data that a VM stores under prefixes of its own may be
+// under other prefixes.
+func DBPrefixes(chainID ids.ID) [][]byte {
+	chainPrefix := hashing.ComputeHash256(chainID.Bytes())
+
+	names := map[string]bool{}
+	prefixes := [][]byte(nil)
+	for _, name := range append(avalancheDBNames, snowmanDBNames...) {
+		if names[name] {
+			continue
+		}
+		names[name] = true
+		prefixes = append(prefixes, hashing.ComputeHash256(append(chainPrefix, name...)))
+	}
+	return prefixes
+}
+
+// chainDBs returns the databases named [names] of the chain in [ctx]. If the
+// chain has a quota, the databases share it.
+func (m *manager) chainDBs(ctx *snow.Context, names ...string) ([]database.Database, error) {
+	db := prefixdb.New(ctx.ChainID.Bytes(), m.db)
+
+	aliases := append([]string{ctx.ChainID.String()}, m.Aliases(ctx.ChainID)...)
+	var quota *quotadb.Quota
+	if limit := m.dbQuotas.quota(aliases); limit != 0 {
+		var err error
+		quota, err = quotadb.NewQuota(limit, ctx.Log, ctx.Namespace, ctx.Metrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dbs := make([]database.Database, len(names))
+	for i, name := range names {
+		dbs[i] = prefixdb.New([]byte(name), db)
+		if quota == nil {
+			continue
+		}
+		quotaDB, err := quotadb.New(dbs[i], quota)
+		if err != nil {
+			return nil, err
+		}
+		dbs[i] = quotaDB
+	}
+	return dbs, nil
+}
@@ -0,0 +1,41 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+func TestDBPrefixes(t *testing.T) {
+	chainID := ids.NewID([32]byte{1})
+	db := memdb.New()
+	chainDB := prefixdb.New(chainID.Bytes(), db)
+	for _, name := range append(avalancheDBNames, snowmanDBNames...) {
+		if err := prefixdb.New([]byte(name), chainDB).Put([]byte(name), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prefixes := DBPrefixes(chainID)
+	it := db.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		found := false
+		for _, prefix := range prefixes {
+			found = found || bytes.Equal(it.Key()[:hashing.HashLen], prefix)
+		}
+		if !found {
+			t.Fatalf("Key %x isn't under any of the chain's prefixes", it.Key())
+		}
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+}
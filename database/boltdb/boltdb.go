@@ -0,0 +1,322 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package boltdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+const (
+	// StatsProperty is the property that Stat returns the JSON encoded
+	// statistics of the database for
+	StatsProperty = "boltdb.stats"
+
+	// iteratorChunkSize is the number of key/value pairs an iterator reads in
+	// each read transaction
+	iteratorChunkSize = 256
+
+	// openTimeout is how long to wait for another process to release the file
+	openTimeout = time.Second
+)
+
+var (
+	// Every key is stored in this bucket
+	bucketName = []byte("gecko")
+
+	// Keys are stored after this byte, because bolt doesn't allow empty keys.
+	// It doesn't change the order of the keys.
+	keyPrefix = []byte{0}
+)
+
+// Database is a persistent key-value store in a single file, backed by bolt.
+// It supports batch writes and iterating over the keyspace in
+// binary-alphabetical order.
+type Database struct{ db *bolt.DB }
+
+// New returns a database stored in [file], which is created if it doesn't
+// exist
+func New(file string) (*Database, error) {
+	db, err := bolt.Open(file, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Database{db: db}, nil
+}
+
+// Has implements the Database interface
+func (db *Database) Has(key []byte) (bool, error) {
+	has := false
+	err := db.db.View(func(tx *bolt.Tx) error {
+		has = tx.Bucket(bucketName).Get(dbKey(key)) != nil
+		return nil
+	})
+	return has, updateError(err)
+}
+
+// Get implements the Database interface
+func (db *Database) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := db.db.View(func(tx *bolt.Tx) error {
+		// The value is only valid during the transaction
+		if v := tx.Bucket(bucketName).Get(dbKey(key)); v != nil {
+			value = copyBytes(v)
+			return nil
+		}
+		return database.ErrNotFound
+	})
+	return value, updateError(err)
+}
+
+// Put implements the Database interface
+func (db *Database) Put(key []byte, value []byte) error {
+	return updateError(db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put(dbKey(key), copyBytes(value))
+	}))
+}
+
+// Delete implements the Database interface
+func (db *Database) Delete(key []byte) error {
+	return updateError(db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Delete(dbKey(key))
+	}))
+}
+
+// NewBatch implements the Database interface
+func (db *Database) NewBatch() database.Batch { return &batch{db: db} }
+
+// NewIterator implements the Database interface
+func (db *Database) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+// NewIteratorWithStart implements the Database interface
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+// NewIteratorWithPrefix implements the Database interface
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix implements the Database interface. The
+// iterator reads the database in chunks, each in its own read transaction,
+// rather than holding one open while the database is written to. So it sees
+// writes to keys it hasn't reached yet.
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	seek := prefix
+	if bytes.Compare(start, prefix) == 1 {
+		seek = start
+	}
+	return &iterator{
+		db:     db,
+		seek:   dbKey(seek),
+		prefix: dbKey(prefix),
+	}
+}
+
+// Stat implements the Database interface
+func (db *Database) Stat(property string) (string, error) {
+	if property != StatsProperty {
+		return "", database.ErrNotFound
+	}
+	stats, err := json.Marshal(db.db.Stats())
+	return string(stats), err
+}
+
+// Compact implements the Database interface. Bolt reuses the pages of deleted
+// data rather than compacting it, so this does nothing.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	return updateError(db.db.View(func(*bolt.Tx) error { return nil }))
+}
+
+// Close implements the Database interface
+func (db *Database) Close() error {
+	// Bolt allows closing a database twice
+	if err := db.db.View(func(*bolt.Tx) error { return nil }); err != nil {
+		return updateError(err)
+	}
+	return updateError(db.db.Close())
+}
+
+type keyValue struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// batch buffers writes, which are written in one transaction
+type batch struct {
+	db     *Database
+	writes []keyValue
+	size   int
+}
+
+// Put implements the Batch interface
+func (b *batch) Put(key, value []byte) error {
+	b.writes = append(b.writes, keyValue{copyBytes(key), copyBytes(value), false})
+	b.size += len(value)
+	return nil
+}
+
+// Delete implements the Batch interface
+func (b *batch) Delete(key []byte) error {
+	b.writes = append(b.writes, keyValue{copyBytes(key), nil, true})
+	b.size++
+	return nil
+}
+
+// ValueSize implements the Batch interface
+func (b *batch) ValueSize() int { return b.size }
+
+// Write implements the Batch interface
+func (b *batch) Write() error {
+	return updateError(b.db.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		for _, kv := range b.writes {
+			var err error
+			if kv.delete {
+				err = bucket.Delete(dbKey(kv.key))
+			} else {
+				err = bucket.Put(dbKey(kv.key), kv.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// Reset implements the Batch interface
+func (b *batch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// Replay implements the Batch interface
+func (b *batch) Replay(w database.KeyValueWriter) error {
+	for _, kv := range b.writes {
+		if kv.delete {
+			if err := w.Delete(kv.key); err != nil {
+				return err
+			}
+		} else if err := w.Put(kv.key, kv.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterator reads the key/value pairs of the database in chunks
+type iterator struct {
+	db *Database
+
+	// seek is the stored key that the next chunk starts at, and prefix is the
+	// prefix of the stored keys that are iterated over
+	seek, prefix []byte
+
+	keys, values [][]byte
+	// index is the index of the current key/value pair in keys and values
+	index int
+
+	// exhausted is true once the last chunk has been read
+	exhausted bool
+	err       error
+}
+
+// Next implements the Iterator interface
+func (it *iterator) Next() bool {
+	if it.index+1 < len(it.keys) {
+		it.index++
+		return true
+	}
+	if it.exhausted || it.err != nil {
+		it.keys, it.values = nil, nil
+		return false
+	}
+
+	it.keys, it.values, it.index = it.keys[:0], it.values[:0], 0
+	it.err = updateError(it.db.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketName).Cursor()
+		for k, v := c.Seek(it.seek); len(it.keys) < iteratorChunkSize; k, v = c.Next() {
+			if k == nil || !bytes.HasPrefix(k, it.prefix) {
+				it.exhausted = true
+				return nil
+			}
+			it.keys = append(it.keys, copyBytes(k[len(keyPrefix):]))
+			it.values = append(it.values, copyBytes(v))
+		}
+		return nil
+	}))
+	if it.err != nil || len(it.keys) == 0 {
+		it.keys, it.values = nil, nil
+		return false
+	}
+	// The next chunk starts at the key right after this chunk's last key
+	it.seek = append(dbKey(it.keys[len(it.keys)-1]), 0)
+	return true
+}
+
+// Error implements the Iterator interface
+func (it *iterator) Error() error { return it.err }
+
+// Key implements the Iterator interface
+func (it *iterator) Key() []byte {
+	if it.index < len(it.keys) {
+		return it.keys[it.index]
+	}
+	return nil
+}
+
+// Value implements the Iterator interface
+func (it *iterator) Value() []byte {
+	if it.index < len(it.values) {
+		return it.values[it.index]
+	}
+	return nil
+}
+
+// Release implements the Iterator interface
+func (it *iterator) Release() {
+	it.keys, it.values = nil, nil
+	it.exhausted = true
+}
+
+// dbKey returns the key that [key] is stored under
+func dbKey(key []byte) []byte {
+	stored := make([]byte, len(keyPrefix)+len(key))
+	copy(stored, keyPrefix)
+	copy(stored[len(keyPrefix):], key)
+	return stored
+}
+
+func copyBytes(bytes []byte) []byte {
+	copiedBytes := make([]byte, len(bytes))
+	copy(copiedBytes, bytes)
+	return copiedBytes
+}
+
+func updateError(err error) error {
+	switch err {
+	case bolt.ErrDatabaseNotOpen:
+		return database.ErrClosed
+	default:
+		return err
+	}
+}
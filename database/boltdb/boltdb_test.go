@@ -0,0 +1,108 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package boltdb
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+func TestInterface(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boltdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, test := range database.Tests {
+		file := filepath.Join(dir, fmt.Sprintf("db%d", i))
+
+		db, err := New(file)
+		if err != nil {
+			t.Fatalf("boltdb.New(%s) errored with %s", file, err)
+		}
+		defer db.Close()
+
+		test(t, db)
+	}
+}
+
+func TestEmptyKeyAndValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boltdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := New(filepath.Join(dir, "db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.Put(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := db.Has(nil); err != nil {
+		t.Fatal(err)
+	} else if !has {
+		t.Fatalf("db.Has should have found the empty key")
+	}
+	if value, err := db.Get(nil); err != nil {
+		t.Fatal(err)
+	} else if len(value) != 0 {
+		t.Fatalf("db.Get returned %v expected an empty value", value)
+	}
+}
+
+// Iterating over more pairs than fit in a chunk, while writing to the database,
+// shouldn't skip or repeat pairs
+func TestIteratorChunks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boltdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := New(filepath.Join(dir, "db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	numPairs := 3*iteratorChunkSize + 1
+	for i := 0; i < numPairs; i++ {
+		if err := db.Put([]byte(fmt.Sprintf("key %04d", i)), []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Put([]byte("other"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	it := db.NewIteratorWithPrefix([]byte("key "))
+	defer it.Release()
+	i := 0
+	for ; it.Next(); i++ {
+		if key := []byte(fmt.Sprintf("key %04d", i)); !bytes.Equal(it.Key(), key) {
+			t.Fatalf("Iterator returned key %s expected %s", it.Key(), key)
+		} else if !bytes.Equal(it.Value(), []byte{byte(i)}) {
+			t.Fatalf("Iterator returned the wrong value for %s", key)
+		}
+		if err := db.Delete(it.Key()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	} else if i != numPairs {
+		t.Fatalf("Iterator returned %d pairs expected %d", i, numPairs)
+	}
+}
@@ -0,0 +1,94 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package driver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/boltdb"
+	"github.com/ava-labs/gecko/database/leveldb"
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+// The names of the drivers that are registered by default
+const (
+	LevelDB = "leveldb"
+	BoltDB  = "boltdb"
+	MemDB   = "memdb"
+)
+
+// boltFile is the name of the file a bolt database is stored in, in the
+// directory it's opened in
+const boltFile = "gecko.bolt"
+
+// Opener opens the database stored in the directory [dir], creating it if it
+// doesn't exist
+type Opener func(dir string) (database.Database, error)
+
+var (
+	lock    sync.RWMutex
+	drivers = map[string]Opener{
+		LevelDB: openLevelDB,
+		BoltDB:  openBoltDB,
+		MemDB:   openMemDB,
+	}
+)
+
+// Register makes the database backend [name] available to Open
+func Register(name string, opener Opener) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, exists := drivers[name]; exists {
+		return fmt.Errorf("database driver %q is already registered", name)
+	}
+	drivers[name] = opener
+	return nil
+}
+
+// Open opens the database stored in the directory [dir] with the backend
+// [name]
+func Open(name, dir string) (database.Database, error) {
+	lock.RLock()
+	opener, exists := drivers[name]
+	lock.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown database driver %q. Registered drivers are %v", name, Names())
+	}
+	return opener(dir)
+}
+
+// Names returns the names of the registered drivers, in sorted order
+func Names() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func openLevelDB(dir string) (database.Database, error) {
+	// TODO: Add better params here
+	return leveldb.New(dir, 0, 0, 0)
+}
+
+func openBoltDB(dir string) (database.Database, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return boltdb.New(filepath.Join(dir, boltFile))
+}
+
+// openMemDB ignores [dir], since the database isn't persisted
+func openMemDB(string) (database.Database, error) { return memdb.New(), nil }
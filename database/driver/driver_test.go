@@ -0,0 +1,63 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package driver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "driver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{LevelDB, BoltDB, MemDB} {
+		db, err := Open(name, filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Open(%s) errored with %s", name, err)
+		}
+		if err := db.Put([]byte("key"), []byte("value")); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := Open("unknown", dir); err == nil {
+		t.Fatalf("Should have failed to open an unknown driver")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	opener := func(string) (database.Database, error) { return memdb.New(), nil }
+	if err := Register("test", opener); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register("test", opener); err == nil {
+		t.Fatalf("Should have failed to register a driver twice")
+	}
+	if err := Register(LevelDB, opener); err == nil {
+		t.Fatalf("Should have failed to replace a default driver")
+	}
+
+	found := false
+	for _, name := range Names() {
+		found = found || name == "test"
+	}
+	if !found {
+		t.Fatalf("Names should include the registered driver")
+	}
+	if _, err := Open("test", ""); err != nil {
+		t.Fatal(err)
+	}
+}
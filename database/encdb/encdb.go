@@ -8,11 +8,8 @@ import (
 	"crypto/rand"
 	"sync"
 
-	"golang.org/x/crypto/chacha20poly1305"
-
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/nodb"
-	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/vms/components/codec"
 )
 
@@ -22,22 +19,82 @@ type Database struct {
 	codec  codec.Codec
 	cipher cipher.AEAD
 	db     database.Database
+
+	// header wraps dataKey, which the values are encrypted with
+	header  *Header
+	dataKey []byte
 }
 
-// New returns a new encrypted database
+// New returns a new encrypted database, whose values are encrypted with a key
+// derived from [password] alone. Its header wraps that key, so that rotating
+// its password doesn't re-encrypt its values.
 func New(password []byte, db database.Database) (*Database, error) {
-	h := hashing.ComputeHash256(password)
-	aead, err := chacha20poly1305.NewX(h)
+	params := KDFParams{KDF: SHA256}
+	dataKey, err := params.deriveKey(password, nil)
+	if err != nil {
+		return nil, err
+	}
+	header, err := wrapKey(password, XChaCha20Poly1305, params, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return newDatabase(header, dataKey, db)
+}
+
+// NewWithHeader returns the encrypted database whose values are encrypted with
+// the data key of [header], which is wrapped with a key derived from
+// [password]
+func NewWithHeader(password []byte, header *Header, db database.Database) (*Database, error) {
+	dataKey, err := header.unwrapKey(password)
+	if err != nil {
+		return nil, err
+	}
+	return newDatabase(header, dataKey, db)
+}
+
+func newDatabase(header *Header, dataKey []byte, db database.Database) (*Database, error) {
+	aead, err := header.Suite.aead(dataKey)
 	if err != nil {
 		return nil, err
 	}
 	return &Database{
-		codec:  codec.NewDefault(),
-		cipher: aead,
-		db:     db,
+		codec:   codec.NewDefault(),
+		cipher:  aead,
+		db:      db,
+		header:  header,
+		dataKey: dataKey,
 	}, nil
 }
 
+// Header returns the header that this database's data key is wrapped in
+func (db *Database) Header() *Header {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.header
+}
+
+// Rotate wraps this database's data key with a key derived from [newPassword],
+// and returns the new header, which should replace the stored one. The values
+// aren't re-encrypted. The key is derived with the parameters of the current
+// header, except that the key of a database made by New is derived with the
+// default parameters.
+func (db *Database) Rotate(newPassword []byte) (*Header, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	params := db.header.Params
+	if params.KDF == SHA256 {
+		params = DefaultKDFParams
+	}
+	header, err := wrapKey(newPassword, db.header.Suite, params, db.dataKey)
+	if err != nil {
+		return nil, err
+	}
+	db.header = header
+	return header, nil
+}
+
 // Has implements the Database interface
 func (db *Database) Has(key []byte) (bool, error) {
 	db.lock.RLock()
@@ -99,6 +156,22 @@ func (db *Database) NewBatch() database.Batch {
 	}
 }
 
+// Inner implements the database.View interface
+func (db *Database) Inner() database.Database {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.db
+}
+
+// WrapBatch implements the database.View interface
+func (db *Database) WrapBatch(b database.Batch) database.Batch {
+	return &batch{
+		Batch: b,
+		db:    db,
+	}
+}
+
 // NewIterator implements the Database interface
 func (db *Database) NewIterator() database.Iterator { return db.NewIteratorWithStartAndPrefix(nil, nil) }
 
@@ -263,7 +336,7 @@ type encryptedValue struct {
 }
 
 func (db *Database) encrypt(plaintext []byte) ([]byte, error) {
-	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	nonce := make([]byte, db.cipher.NonceSize())
 	if _, err := rand.Read(nonce); err != nil {
 		return nil, err
 	}
@@ -279,5 +352,8 @@ func (db *Database) decrypt(ciphertext []byte) ([]byte, error) {
 	if err := db.codec.Unmarshal(ciphertext, &val); err != nil {
 		return nil, err
 	}
+	if len(val.Nonce) != db.cipher.NonceSize() {
+		return nil, errInvalidNonce
+	}
 	return db.cipher.Open(nil, val.Nonce, val.Ciphertext, nil)
 }
@@ -4,6 +4,7 @@
 package encdb
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ava-labs/gecko/database"
@@ -22,3 +23,157 @@ func TestInterface(t *testing.T) {
 		test(t, db)
 	}
 }
+
+// testKDFParams are cheap to derive keys with
+var testKDFParams = KDFParams{
+	KDF:     Argon2id,
+	Time:    1,
+	Memory:  64,
+	Threads: 1,
+}
+
+func TestInterfaceWithHeader(t *testing.T) {
+	pw := []byte("lol totally a secure password")
+	for _, suite := range []Suite{XChaCha20Poly1305, AES256GCM} {
+		for _, test := range database.Tests {
+			header, err := NewHeader(pw, suite, testKDFParams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			db, err := NewWithHeader(pw, header, memdb.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			test(t, db)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	oldPW, newPW := []byte("old password"), []byte("new password")
+	key, value := []byte("key"), []byte("value")
+	for _, suite := range []Suite{XChaCha20Poly1305, AES256GCM} {
+		baseDB := memdb.New()
+		header, err := NewHeader(oldPW, suite, testKDFParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db, err := NewWithHeader(oldPW, header, baseDB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Put(key, value); err != nil {
+			t.Fatal(err)
+		}
+		encValue, err := baseDB.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header, err = db.Rotate(newPW)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != db.Header() {
+			t.Fatalf("Header didn't return the rotated header")
+		}
+		headerBytes, err := header.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		header, err = ParseHeader(headerBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Suite != suite {
+			t.Fatalf("Rotated header has suite %s, expected %s", header.Suite, suite)
+		}
+
+		if _, err := NewWithHeader(oldPW, header, baseDB); err != errIncorrectPassword {
+			t.Fatalf("Opening with the old password should have failed with %s, but returned %v", errIncorrectPassword, err)
+		}
+		db, err = NewWithHeader(newPW, header, baseDB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := db.Get(key); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(got, value) {
+			t.Fatalf("Get returned %s, expected %s", got, value)
+		}
+
+		// The value wasn't re-encrypted
+		if got, err := baseDB.Get(key); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(got, encValue) {
+			t.Fatalf("Rotate re-encrypted the stored value")
+		}
+	}
+}
+
+func TestRotateNew(t *testing.T) {
+	oldPW, newPW := []byte("old password"), []byte("new password")
+	key, value := []byte("key"), []byte("value")
+
+	baseDB := memdb.New()
+	db, err := New(oldPW, baseDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+
+	header, err := db.Rotate(newPW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Params != DefaultKDFParams {
+		t.Fatalf("Rotated header has parameters %v, expected %v", header.Params, DefaultKDFParams)
+	}
+
+	// The values written with New can be read with the rotated header
+	db, err = NewWithHeader(newPW, header, baseDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := db.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(got, value) {
+		t.Fatalf("Get returned %s, expected %s", got, value)
+	}
+}
+
+func TestParseHeaderInvalid(t *testing.T) {
+	header, err := NewHeader([]byte("password"), XChaCha20Poly1305, testKDFParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header.Suite = 2
+	headerBytes, err := header.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseHeader(headerBytes); err == nil {
+		t.Fatalf("Should have errored due to the unknown suite")
+	}
+}
+
+func TestKDFParamsValidBounds(t *testing.T) {
+	invalid := []KDFParams{
+		{KDF: Argon2id, Time: 1, Memory: 0xFFFFFFFF, Threads: 4},
+		{KDF: Argon2id, Time: MaxArgon2idTime + 1, Memory: 64 * 1024, Threads: 4},
+		{KDF: Scrypt, N: 1 << 31, R: 8, P: 1},
+		{KDF: Scrypt, N: MaxScryptN, R: MaxScryptR, P: 1},
+		{KDF: Scrypt, N: 1 << 10, R: 8, P: MaxScryptP + 1},
+	}
+	for _, params := range invalid {
+		if err := params.Valid(); err == nil {
+			t.Fatalf("%+v shouldn't have been valid", params)
+		}
+	}
+	if err := DefaultKDFParams.Valid(); err != nil {
+		t.Fatal(err)
+	}
+}
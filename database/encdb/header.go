@@ -0,0 +1,278 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+// Suite is the AEAD that a database's values, and its data key, are encrypted
+// with
+type Suite byte
+
+// The suites that databases can be encrypted with
+const (
+	XChaCha20Poly1305 Suite = iota
+	AES256GCM
+)
+
+// KDF is the key derivation function that derives the key that wraps a
+// database's data key from a password
+type KDF byte
+
+// The functions that keys can be derived with
+const (
+	// SHA256 derives the key by hashing the password once, without a salt. It
+	// is only meant for the databases made by New.
+	SHA256 KDF = iota
+	Argon2id
+	Scrypt
+)
+
+const (
+	keyLen  = 32
+	saltLen = 16
+)
+
+// The most costly parameters that keys may be derived with. Parameters are
+// stored with, and may be imported along with, the data they protect, so
+// deriving a key mustn't exhaust the node's memory or time however they're
+// set.
+const (
+	// MaxArgon2idTime is the most passes argon2id may make over its memory
+	MaxArgon2idTime = 16
+
+	// MaxArgon2idMemory is the most memory, in KiB, argon2id may use: 1 GiB
+	MaxArgon2idMemory = 1 << 20
+
+	// MaxArgon2idThreads is the most threads argon2id may use
+	MaxArgon2idThreads = 64
+
+	// MaxScryptN is the largest CPU/memory cost scrypt may use
+	MaxScryptN = 1 << 20
+
+	// MaxScryptR is the largest block size scrypt may use
+	MaxScryptR = 32
+
+	// MaxScryptP is the most parallelization scrypt may use
+	MaxScryptP = 16
+
+	// maxScryptMemory is the most memory, in bytes, scrypt may use, which is
+	// 128 * N * r: 1 GiB
+	maxScryptMemory = 1 << 30
+)
+
+var (
+	// DefaultKDFParams are the parameters that the keys wrapping new data
+	// keys are derived with
+	DefaultKDFParams = KDFParams{
+		KDF:     Argon2id,
+		Time:    1,
+		Memory:  64 * 1024,
+		Threads: 4,
+	}
+
+	errIncorrectPassword = errors.New("password doesn't decrypt the data key")
+	errInvalidNonce      = errors.New("nonce has the wrong length")
+)
+
+func (s Suite) String() string {
+	switch s {
+	case XChaCha20Poly1305:
+		return "xchacha20-poly1305"
+	case AES256GCM:
+		return "aes-256-gcm"
+	default:
+		return fmt.Sprintf("Unknown(%d)", byte(s))
+	}
+}
+
+// aead returns the AEAD of this suite with [key]
+func (s Suite) aead(key []byte) (cipher.AEAD, error) {
+	switch s {
+	case XChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	case AES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	default:
+		return nil, fmt.Errorf("unknown cipher suite %s", s)
+	}
+}
+
+// KDFParams are the key derivation function, and its cost parameters, that
+// the key wrapping a data key is derived with
+type KDFParams struct {
+	KDF KDF `serialize:"true"`
+
+	// Parameters of argon2id. Memory is in KiB.
+	Time    uint32 `serialize:"true"`
+	Memory  uint32 `serialize:"true"`
+	Threads uint8  `serialize:"true"`
+
+	// Parameters of scrypt
+	N uint32 `serialize:"true"`
+	R uint32 `serialize:"true"`
+	P uint32 `serialize:"true"`
+}
+
+// Valid returns nil if keys can be derived with these parameters, within the
+// limits on their cost
+func (p KDFParams) Valid() error {
+	switch p.KDF {
+	case SHA256:
+	case Argon2id:
+		switch {
+		case p.Time == 0 || p.Time > MaxArgon2idTime:
+			return fmt.Errorf("argon2id time = %d: Fails the condition that: 0 < time <= %d", p.Time, MaxArgon2idTime)
+		case p.Threads == 0 || p.Threads > MaxArgon2idThreads:
+			return fmt.Errorf("argon2id threads = %d: Fails the condition that: 0 < threads <= %d", p.Threads, MaxArgon2idThreads)
+		case p.Memory < 8*uint32(p.Threads) || p.Memory > MaxArgon2idMemory:
+			return fmt.Errorf("argon2id memory = %d, threads = %d: Fails the condition that: 8 * threads <= memory <= %d", p.Memory, p.Threads, MaxArgon2idMemory)
+		}
+	case Scrypt:
+		switch {
+		case p.N <= 1 || p.N&(p.N-1) != 0 || p.N > MaxScryptN:
+			return fmt.Errorf("scrypt N = %d: Fails the condition that: N is a power of 2 greater than 1 and at most %d", p.N, MaxScryptN)
+		case p.R == 0 || p.R > MaxScryptR:
+			return fmt.Errorf("scrypt r = %d: Fails the condition that: 0 < r <= %d", p.R, MaxScryptR)
+		case p.P == 0 || p.P > MaxScryptP:
+			return fmt.Errorf("scrypt p = %d: Fails the condition that: 0 < p <= %d", p.P, MaxScryptP)
+		case 128*uint64(p.N)*uint64(p.R) > maxScryptMemory:
+			return fmt.Errorf("scrypt N = %d, r = %d: Fails the condition that: 128 * N * r <= %d", p.N, p.R, maxScryptMemory)
+		}
+	default:
+		return fmt.Errorf("unknown key derivation function %d", p.KDF)
+	}
+	return nil
+}
+
+// deriveKey returns the key derived from [password] and [salt]
+func (p KDFParams) deriveKey(password, salt []byte) ([]byte, error) {
+	switch p.KDF {
+	case SHA256:
+		return hashing.ComputeHash256(password), nil
+	case Argon2id:
+		return argon2.IDKey(password, salt, p.Time, p.Memory, p.Threads, keyLen), nil
+	case Scrypt:
+		return scrypt.Key(password, salt, int(p.N), int(p.R), int(p.P), keyLen)
+	default:
+		return nil, fmt.Errorf("unknown key derivation function %d", p.KDF)
+	}
+}
+
+// Header is the record of how a database is encrypted. Its values are
+// encrypted with a random data key, which the header stores wrapped with a key
+// derived from a password. So changing the password only changes the header.
+//
+// The header isn't stored in the database. Whoever opens the database stores
+// it, such as with the record of the database's owner.
+type Header struct {
+	// Suite encrypts both the values and the data key
+	Suite Suite `serialize:"true"`
+
+	// Params and Salt derive the key that wraps the data key from the password
+	Params KDFParams `serialize:"true"`
+	Salt   []byte    `serialize:"true"`
+
+	// WrappedKey is the data key, encrypted with the key derived from the
+	// password
+	WrappedKey encryptedValue `serialize:"true"`
+}
+
+// NewHeader returns the header of a new random data key that values are
+// encrypted with by [suite], wrapped with a key derived from [password] with
+// [params]
+func NewHeader(password []byte, suite Suite, params KDFParams) (*Header, error) {
+	dataKey := make([]byte, keyLen)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	return wrapKey(password, suite, params, dataKey)
+}
+
+// ParseHeader returns the header whose bytes are [headerBytes]
+func ParseHeader(headerBytes []byte) (*Header, error) {
+	header := &Header{}
+	if err := codec.NewDefault().Unmarshal(headerBytes, header); err != nil {
+		return nil, err
+	}
+	if _, err := header.Suite.aead(make([]byte, keyLen)); err != nil {
+		return nil, err
+	}
+	return header, header.Params.Valid()
+}
+
+// Bytes returns the bytes of this header, which ParseHeader parses
+func (h *Header) Bytes() ([]byte, error) { return codec.NewDefault().Marshal(h) }
+
+// wrapKey returns the header of [dataKey], wrapped with a key derived from
+// [password] with [params]
+func wrapKey(password []byte, suite Suite, params KDFParams, dataKey []byte) (*Header, error) {
+	if err := params.Valid(); err != nil {
+		return nil, err
+	}
+	header := &Header{
+		Suite:  suite,
+		Params: params,
+	}
+	if params.KDF != SHA256 {
+		header.Salt = make([]byte, saltLen)
+		if _, err := rand.Read(header.Salt); err != nil {
+			return nil, err
+		}
+	}
+	wrappingKey, err := params.deriveKey(password, header.Salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := suite.aead(wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+	header.WrappedKey.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(header.WrappedKey.Nonce); err != nil {
+		return nil, err
+	}
+	header.WrappedKey.Ciphertext = aead.Seal(nil, header.WrappedKey.Nonce, dataKey, nil)
+	return header, nil
+}
+
+// unwrapKey returns the data key of this header, which is wrapped with a key
+// derived from [password]
+func (h *Header) unwrapKey(password []byte) ([]byte, error) {
+	if err := h.Params.Valid(); err != nil {
+		return nil, err
+	}
+	wrappingKey, err := h.Params.deriveKey(password, h.Salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := h.Suite.aead(wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(h.WrappedKey.Nonce) != aead.NonceSize() {
+		return nil, errInvalidNonce
+	}
+	dataKey, err := aead.Open(nil, h.WrappedKey.Nonce, h.WrappedKey.Ciphertext, nil)
+	if err != nil {
+		return nil, errIncorrectPassword
+	}
+	return dataKey, nil
+}
@@ -5,9 +5,11 @@ package leveldb
 
 import (
 	"bytes"
+	"sync"
 
 	"github.com/ava-labs/gecko/database"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
 	"github.com/syndtr/goleveldb/leveldb/errors"
 	"github.com/syndtr/goleveldb/leveldb/filter"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
@@ -32,7 +34,15 @@ const (
 // Database is a persistent key-value store. Apart from basic data storage
 // functionality it also supports batch writes and iterating over the keyspace
 // in binary-alphabetical order.
-type Database struct{ *leveldb.DB }
+type Database struct {
+	file    string
+	options *opt.Options
+
+	// lock is held exclusively while the database is repaired, since it's
+	// closed and reopened
+	lock sync.RWMutex
+	*leveldb.DB
+}
 
 // New returns a wrapped LevelDB object.
 func New(file string, blockCacheSize, writeBufferSize, handleCap int) (*Database, error) {
@@ -47,62 +57,82 @@ func New(file string, blockCacheSize, writeBufferSize, handleCap int) (*Database
 		handleCap = minHandleCap
 	}
 
-	// Open the db and recover any potential corruptions
-	db, err := leveldb.OpenFile(file, &opt.Options{
+	options := &opt.Options{
 		OpenFilesCacheCapacity: handleCap,
 		BlockCacheCapacity:     blockCacheSize,
 		// There are two buffers of size WriteBuffer used.
 		WriteBuffer: writeBufferSize / 2,
 		Filter:      filter.NewBloomFilter(10),
-	})
+	}
+
+	// Open the db and recover any potential corruptions
+	db, err := leveldb.OpenFile(file, options)
 	if _, corrupted := err.(*errors.ErrCorrupted); corrupted {
 		db, err = leveldb.RecoverFile(file, nil)
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &Database{DB: db}, nil
+	return &Database{
+		file:    file,
+		options: options,
+		DB:      db,
+	}, nil
 }
 
 // Has returns if the key is set in the database
 func (db *Database) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
 	has, err := db.DB.Has(key, nil)
 	return has, updateError(err)
 }
 
 // Get returns the value the key maps to in the database
 func (db *Database) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
 	value, err := db.DB.Get(key, nil)
 	return value, updateError(err)
 }
 
 // Put sets the value of the provided key to the provided value
 func (db *Database) Put(key []byte, value []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
 	return updateError(db.DB.Put(key, value, nil))
 }
 
 // Delete removes the key from the database
-func (db *Database) Delete(key []byte) error { return updateError(db.DB.Delete(key, nil)) }
+func (db *Database) Delete(key []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return updateError(db.DB.Delete(key, nil))
+}
 
 // NewBatch creates a write/delete-only buffer that is atomically committed to
 // the database when write is called
-func (db *Database) NewBatch() database.Batch { return &batch{db: db.DB} }
+func (db *Database) NewBatch() database.Batch { return &batch{db: db} }
 
 // NewIterator creates a lexicographically ordered iterator over the database
 func (db *Database) NewIterator() database.Iterator {
-	return &iter{db.DB.NewIterator(new(util.Range), nil)}
+	return db.newIterator(new(util.Range))
 }
 
 // NewIteratorWithStart creates a lexicographically ordered iterator over the
 // database starting at the provided key
 func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
-	return &iter{db.DB.NewIterator(&util.Range{Start: start}, nil)}
+	return db.newIterator(&util.Range{Start: start})
 }
 
 // NewIteratorWithPrefix creates a lexicographically ordered iterator over the
 // database ignoring keys that do not start with the provided prefix
 func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
-	return &iter{db.DB.NewIterator(util.BytesPrefix(prefix), nil)}
+	return db.newIterator(util.BytesPrefix(prefix))
 }
 
 // NewIteratorWithStartAndPrefix creates a lexicographically ordered iterator
@@ -113,11 +143,23 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	if bytes.Compare(start, prefix) == 1 {
 		iterRange.Start = start
 	}
-	return &iter{db.DB.NewIterator(iterRange, nil)}
+	return db.newIterator(iterRange)
+}
+
+// newIterator returns an iterator over the keys in [keys]. An iterator made
+// before the database is repaired fails with database.ErrClosed after it.
+func (db *Database) newIterator(keys *util.Range) database.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return &iter{db.DB.NewIterator(keys, nil)}
 }
 
 // Stat returns a particular internal stat of the database.
 func (db *Database) Stat(property string) (string, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
 	stat, err := db.DB.GetProperty(property)
 	return stat, updateError(err)
 }
@@ -134,17 +176,114 @@ func (db *Database) Stat(property string) (string, error) {
 // And a nil limit is treated as a key after all keys in the DB.
 // Therefore if both are nil then it will compact entire DB.
 func (db *Database) Compact(start []byte, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
 	return updateError(db.DB.CompactRange(util.Range{Start: start, Limit: limit}))
 }
 
+// Verify implements the database.Verifier interface. Reading every key/value
+// pair checks the blocks they're stored in against their checksums. After a
+// corrupt block, reading resumes at the next key that can be read.
+func (db *Database) Verify() ([]database.CorruptRange, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	corrupt := []database.CorruptRange(nil)
+	start := []byte(nil)
+	for {
+		// The last key read is [start], if reading resumed after corrupt data
+		last := start
+		it := db.DB.NewIterator(&util.Range{Start: start}, nil)
+		for it.Next() {
+			last = copyBytes(it.Key())
+		}
+		err := it.Error()
+		it.Release()
+		if err == nil {
+			return corrupt, nil
+		}
+		if !errors.IsCorrupted(err) {
+			return corrupt, updateError(err)
+		}
+
+		// Without strict reads, the corrupt blocks are skipped, so the first
+		// key read after [last] is the first key after the corrupt data
+		skipStart := []byte(nil)
+		if last != nil {
+			skipStart = append(copyBytes(last), 0)
+		}
+		skip := db.DB.NewIterator(&util.Range{Start: skipStart}, &opt.ReadOptions{Strict: opt.StrictOverride})
+		limit := []byte(nil)
+		if skip.Next() {
+			limit = copyBytes(skip.Key())
+		}
+		err = skip.Error()
+		skip.Release()
+		if err != nil {
+			return corrupt, updateError(err)
+		}
+
+		corrupt = append(corrupt, database.CorruptRange{
+			Start: last,
+			Limit: limit,
+		})
+		if limit == nil {
+			return corrupt, nil
+		}
+		start = limit
+	}
+}
+
+// Repair implements the database.Repairer interface. Compacting corrupt data
+// doesn't drop it, since strict compactions fail on it and a range compaction
+// doesn't rewrite the tables of the last level it covers, so the database is
+// closed and recovered instead. Recovering rewrites each table with corrupt
+// blocks without them, then the database is reopened as it was. Operations
+// wait for the repair to finish.
+func (db *Database) Repair() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if err := db.DB.Close(); err != nil {
+		return updateError(err)
+	}
+
+	// Recovering rewrites tables with the options it's given, rather than
+	// with the ones for internal keys, so their filters and index keys would
+	// be built from internal keys as if they were keys. The tables are
+	// rewritten without filters or shortened index keys instead.
+	recoverOptions := *db.options
+	recoverOptions.Filter = nil
+	recoverOptions.Comparer = recoveryComparer{comparer.DefaultComparer}
+	recovered, err := leveldb.RecoverFile(db.file, &recoverOptions)
+	if err == nil {
+		err = recovered.Close()
+	}
+
+	// Reopen the database, even if it couldn't be recovered, so that it can
+	// still be used
+	reopened, reopenErr := leveldb.OpenFile(db.file, db.options)
+	if reopenErr != nil {
+		return reopenErr
+	}
+	db.DB = reopened
+	return updateError(err)
+}
+
 // Close implements the Database interface
-func (db *Database) Close() error { return updateError(db.DB.Close()) }
+func (db *Database) Close() error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return updateError(db.DB.Close())
+}
 
 // batch is a wrapper around a levelDB batch to contain sizes.
 type batch struct {
 	leveldb.Batch
 
-	db   *leveldb.DB
+	db   *Database
 	size int
 }
 
@@ -166,7 +305,12 @@ func (b *batch) Delete(key []byte) error {
 func (b *batch) ValueSize() int { return b.size }
 
 // Write flushes any accumulated data to disk.
-func (b *batch) Write() error { return updateError(b.db.Write(&b.Batch, nil)) }
+func (b *batch) Write() error {
+	b.db.lock.RLock()
+	defer b.db.lock.RUnlock()
+
+	return updateError(b.db.DB.Write(&b.Batch, nil))
+}
 
 // Reset resets the batch for reuse.
 func (b *batch) Reset() {
@@ -203,10 +347,25 @@ func (r *replayer) Delete(key []byte) {
 	r.err = r.writer.Delete(key)
 }
 
+// recoveryComparer orders keys as the comparer it wraps does, but doesn't
+// shorten index keys, so the tables it's used to write are valid whether their
+// keys are internal keys or not
+type recoveryComparer struct{ comparer.Comparer }
+
+func (recoveryComparer) Separator(_, _, _ []byte) []byte { return nil }
+
+func (recoveryComparer) Successor(_, _ []byte) []byte { return nil }
+
 type iter struct{ iterator.Iterator }
 
 func (i *iter) Error() error { return updateError(i.Iterator.Error()) }
 
+func copyBytes(bytes []byte) []byte {
+	copiedBytes := make([]byte, len(bytes))
+	copy(copiedBytes, bytes)
+	return copiedBytes
+}
+
 func updateError(err error) error {
 	switch err {
 	case leveldb.ErrClosed:
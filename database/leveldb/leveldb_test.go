@@ -4,8 +4,11 @@
 package leveldb
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ava-labs/gecko/database"
@@ -25,3 +28,149 @@ func TestInterface(t *testing.T) {
 		test(t, db)
 	}
 }
+
+// newCorruptDB returns a database in [folder] whose only table has a corrupt
+// block in its middle, among the keys "0000" to "0999"
+func newCorruptDB(t *testing.T, folder string) *Database {
+	t.Helper()
+
+	db, err := New(folder, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := bytes.Repeat([]byte{1}, 100)
+	for i := 0; i < 1000; i++ {
+		if err := db.Put([]byte(fmt.Sprintf("%04d", i)), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Write the pairs to a table
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if corrupt, err := db.Verify(); err != nil {
+		t.Fatal(err)
+	} else if len(corrupt) != 0 {
+		t.Fatalf("Verify reported %d corrupt ranges of an intact database", len(corrupt))
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrupt a block in the middle of the table
+	tables, err := filepath.Glob(filepath.Join(folder, "*.ldb"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(tables) != 1 {
+		t.Fatalf("Expected 1 table, found %d", len(tables))
+	}
+	table, err := ioutil.ReadFile(tables[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := len(table) / 2; i < len(table)/2+16; i++ {
+		table[i] ^= 0xFF
+	}
+	if err := ioutil.WriteFile(tables[0], table, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = New(folder, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestVerify(t *testing.T) {
+	folder := "verify"
+	defer os.RemoveAll(folder)
+
+	db := newCorruptDB(t, folder)
+	defer db.Close()
+
+	corrupt, err := db.Verify()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(corrupt) != 1 {
+		t.Fatalf("Verify reported %d corrupt ranges, expected 1", len(corrupt))
+	}
+	start, limit := string(corrupt[0].Start), string(corrupt[0].Limit)
+	if start <= "0000" || limit >= "0999" || start >= limit {
+		t.Fatalf("Verify reported the corrupt range (%s, %s)", start, limit)
+	}
+
+	// The keys around the corrupt range can still be read
+	if _, err := db.Get([]byte(start)); err != nil {
+		t.Fatal(err)
+	} else if _, err := db.Get([]byte(limit)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	folder := "repair"
+	defer os.RemoveAll(folder)
+
+	db := newCorruptDB(t, folder)
+	defer db.Close()
+
+	corrupt, err := db.Verify()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(corrupt) != 1 {
+		t.Fatalf("Verify reported %d corrupt ranges, expected 1", len(corrupt))
+	}
+
+	// Compacting the range doesn't drop the corrupt data
+	if err := db.Compact(corrupt[0].Start, corrupt[0].Limit); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, err := db.Verify(); err != nil {
+		t.Fatal(err)
+	} else if len(remaining) != 1 {
+		t.Fatalf("Verify reported %d corrupt ranges after compacting, expected 1", len(remaining))
+	}
+
+	if err := db.Repair(); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, err := db.Verify(); err != nil {
+		t.Fatal(err)
+	} else if len(remaining) != 0 {
+		t.Fatalf("Verify reported %d corrupt ranges after repairing", len(remaining))
+	}
+
+	// The keys around the corrupt range are kept, and the database can still
+	// be written to
+	for _, key := range [][]byte{[]byte("0000"), corrupt[0].Start, corrupt[0].Limit, []byte("0999")} {
+		if _, err := db.Get(key); err != nil {
+			t.Fatalf("couldn't get %s after repairing: %s", key, err)
+		}
+	}
+	if err := db.Put([]byte("1000"), []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Repair(); err != database.ErrClosed {
+		t.Fatalf("Repair of a closed database returned %v, expected %s", err, database.ErrClosed)
+	}
+
+	db, err = New(folder, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if remaining, err := db.Verify(); err != nil {
+		t.Fatal(err)
+	} else if len(remaining) != 0 {
+		t.Fatalf("Verify reported %d corrupt ranges after reopening", len(remaining))
+	}
+	if _, err := db.Get([]byte("1000")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,220 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package meterdb
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Names of the operations that are measured
+const (
+	hasOp     = "has"
+	getOp     = "get"
+	putOp     = "put"
+	deleteOp  = "delete"
+	writeOp   = "batch_write"
+	iterateOp = "iterate"
+	compactOp = "compact"
+	verifyOp  = "verify"
+	repairOp  = "repair"
+)
+
+// Database reports the number, duration and size of the operations on the
+// database it wraps as metrics
+type Database struct {
+	database.Database
+
+	clock timer.Clock
+
+	duration              *prometheus.HistogramVec
+	bytesRead, bytesWrite prometheus.Counter
+}
+
+// New returns [db], with its operations measured by metrics registered with
+// [registerer] in [namespace]
+func New(namespace string, registerer prometheus.Registerer, db database.Database) (*Database, error) {
+	meterDB := &Database{
+		Database: db,
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "db_op_duration",
+				Help:      "Time spent on a database operation, in milliseconds",
+				Buckets:   prometheus.ExponentialBuckets(0.01, 2, 16),
+			},
+			[]string{"op"},
+		),
+		bytesRead: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "db_bytes_read",
+				Help:      "Number of key and value bytes read from the database",
+			}),
+		bytesWrite: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "db_bytes_written",
+				Help:      "Number of key and value bytes written to the database",
+			}),
+	}
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(meterDB.duration),
+		registerer.Register(meterDB.bytesRead),
+		registerer.Register(meterDB.bytesWrite),
+	)
+	return meterDB, errs.Err
+}
+
+// Has implements the Database interface
+func (db *Database) Has(key []byte) (bool, error) {
+	start := db.clock.Time()
+	has, err := db.Database.Has(key)
+	db.observe(hasOp, start)
+	db.bytesRead.Add(float64(len(key)))
+	return has, err
+}
+
+// Get implements the Database interface
+func (db *Database) Get(key []byte) ([]byte, error) {
+	start := db.clock.Time()
+	value, err := db.Database.Get(key)
+	db.observe(getOp, start)
+	db.bytesRead.Add(float64(len(key) + len(value)))
+	return value, err
+}
+
+// Put implements the Database interface
+func (db *Database) Put(key, value []byte) error {
+	start := db.clock.Time()
+	err := db.Database.Put(key, value)
+	db.observe(putOp, start)
+	db.bytesWrite.Add(float64(len(key) + len(value)))
+	return err
+}
+
+// Delete implements the Database interface
+func (db *Database) Delete(key []byte) error {
+	start := db.clock.Time()
+	err := db.Database.Delete(key)
+	db.observe(deleteOp, start)
+	db.bytesWrite.Add(float64(len(key)))
+	return err
+}
+
+// NewBatch implements the Database interface
+func (db *Database) NewBatch() database.Batch {
+	return &batch{
+		Batch: db.Database.NewBatch(),
+		db:    db,
+	}
+}
+
+// NewIterator implements the Database interface
+func (db *Database) NewIterator() database.Iterator {
+	return db.newIterator(db.Database.NewIterator())
+}
+
+// NewIteratorWithStart implements the Database interface
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.newIterator(db.Database.NewIteratorWithStart(start))
+}
+
+// NewIteratorWithPrefix implements the Database interface
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.newIterator(db.Database.NewIteratorWithPrefix(prefix))
+}
+
+// NewIteratorWithStartAndPrefix implements the Database interface
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return db.newIterator(db.Database.NewIteratorWithStartAndPrefix(start, prefix))
+}
+
+// Compact implements the Database interface
+func (db *Database) Compact(start, limit []byte) error {
+	startTime := db.clock.Time()
+	err := db.Database.Compact(start, limit)
+	db.observe(compactOp, startTime)
+	return err
+}
+
+// Verify implements the database.Verifier interface, if the database this
+// wraps does
+func (db *Database) Verify() ([]database.CorruptRange, error) {
+	startTime := db.clock.Time()
+	corrupt, err := database.Verify(db.Database)
+	db.observe(verifyOp, startTime)
+	return corrupt, err
+}
+
+// Repair implements the database.Repairer interface, if the database this
+// wraps does
+func (db *Database) Repair() error {
+	startTime := db.clock.Time()
+	err := database.Repair(db.Database)
+	db.observe(repairOp, startTime)
+	return err
+}
+
+func (db *Database) newIterator(it database.Iterator) database.Iterator {
+	return &iterator{
+		Iterator: it,
+		db:       db,
+		start:    db.clock.Time(),
+	}
+}
+
+// observe that the operation [op] started at [start] and just finished
+func (db *Database) observe(op string, start time.Time) {
+	duration := db.clock.Time().Sub(start)
+	db.duration.WithLabelValues(op).Observe(float64(duration) / float64(time.Millisecond))
+}
+
+type batch struct {
+	database.Batch
+	db *Database
+}
+
+// Write implements the Batch interface
+func (b *batch) Write() error {
+	start := b.db.clock.Time()
+	err := b.Batch.Write()
+	b.db.observe(writeOp, start)
+	b.db.bytesWrite.Add(float64(b.Batch.ValueSize()))
+	return err
+}
+
+// iterator measures the time from when it's created until it's released, and
+// the bytes it reads
+type iterator struct {
+	database.Iterator
+	db       *Database
+	start    time.Time
+	released bool
+}
+
+// Next implements the Iterator interface
+func (it *iterator) Next() bool {
+	next := it.Iterator.Next()
+	if next {
+		it.db.bytesRead.Add(float64(len(it.Iterator.Key()) + len(it.Iterator.Value())))
+	}
+	return next
+}
+
+// Release implements the Iterator interface
+func (it *iterator) Release() {
+	it.Iterator.Release()
+	if !it.released {
+		it.released = true
+		it.db.observe(iterateOp, it.start)
+	}
+}
@@ -0,0 +1,137 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package meterdb
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+func TestInterface(t *testing.T) {
+	for _, test := range database.Tests {
+		db, err := New("", prometheus.NewRegistry(), memdb.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		test(t, db)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	db, err := New("gecko", registry, memdb.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Put([]byte{1}, []byte{2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Get([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	batch := db.NewBatch()
+	if err := batch.Put([]byte{4}, []byte{5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	it := db.NewIterator()
+	for it.Next() {
+	}
+	it.Release()
+	it.Release()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := map[string]uint64{}
+	counters := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			switch family.GetName() {
+			case "gecko_db_op_duration":
+				ops[metric.GetLabel()[0].GetValue()] = metric.GetHistogram().GetSampleCount()
+			default:
+				counters[family.GetName()] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	for op, count := range map[string]uint64{putOp: 1, getOp: 1, writeOp: 1, iterateOp: 1} {
+		if ops[op] != count {
+			t.Fatalf("should have measured %d %s operations but measured %d", count, op, ops[op])
+		}
+	}
+	// The put writes 3 bytes and the batch 1. The get reads 3 bytes and the
+	// iterator 5.
+	if written := counters["gecko_db_bytes_written"]; written != 4 {
+		t.Fatalf("should have written 4 bytes but wrote %v", written)
+	}
+	if read := counters["gecko_db_bytes_read"]; read != 8 {
+		t.Fatalf("should have read 8 bytes but read %v", read)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	db, err := New("", prometheus.NewRegistry(), memdb.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Verify(db); err != database.ErrNotVerifiable {
+		t.Fatalf("Verify of a memory database returned %v, expected %s", err, database.ErrNotVerifiable)
+	}
+
+	db, err = New("", prometheus.NewRegistry(), &verifiableDB{Database: memdb.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corrupt, err := database.Verify(db); err != nil {
+		t.Fatal(err)
+	} else if len(corrupt) != 1 {
+		t.Fatalf("Verify returned %d corrupt ranges, expected 1", len(corrupt))
+	}
+}
+
+func TestRepair(t *testing.T) {
+	db, err := New("", prometheus.NewRegistry(), memdb.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Repair(db); err != database.ErrNotRepairable {
+		t.Fatalf("Repair of a memory database returned %v, expected %s", err, database.ErrNotRepairable)
+	}
+
+	verifiable := &verifiableDB{Database: memdb.New()}
+	db, err = New("", prometheus.NewRegistry(), verifiable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Repair(db); err != nil {
+		t.Fatal(err)
+	} else if !verifiable.repaired {
+		t.Fatalf("Repair wasn't passed to the wrapped database")
+	}
+}
+
+// verifiableDB reports one corrupt range when verified
+type verifiableDB struct {
+	database.Database
+	repaired bool
+}
+
+func (*verifiableDB) Verify() ([]database.CorruptRange, error) {
+	return []database.CorruptRange{{Start: []byte{1}, Limit: []byte{2}}}, nil
+}
+
+func (db *verifiableDB) Repair() error {
+	db.repaired = true
+	return nil
+}
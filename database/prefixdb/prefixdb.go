@@ -92,6 +92,22 @@ func (db *Database) NewBatch() database.Batch {
 	}
 }
 
+// Inner implements the database.View interface
+func (db *Database) Inner() database.Database {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.db
+}
+
+// WrapBatch implements the database.View interface
+func (db *Database) WrapBatch(b database.Batch) database.Batch {
+	return &batch{
+		Batch: b,
+		db:    db,
+	}
+}
+
 // NewIterator implements the Database interface
 func (db *Database) NewIterator() database.Iterator { return db.NewIteratorWithStartAndPrefix(nil, nil) }
 
@@ -21,3 +21,49 @@ func TestInterface(t *testing.T) {
 		test(t, NewNested([]byte("ld"), New([]byte("wor"), db)))
 	}
 }
+
+func TestSharedBatch(t *testing.T) {
+	db := memdb.New()
+	users := New([]byte("users"), db)
+	data := NewNested([]byte("bob"), New([]byte("data"), db))
+
+	batch := database.NewSharedBatch(db)
+	usersWriter, err := batch.Add(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataWriter, err := batch.Add(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := usersWriter.Put([]byte("bob"), []byte("user")); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataWriter.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	if has, err := users.Has([]byte("bob")); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Shouldn't have written before the shared batch was written")
+	}
+
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := users.Get([]byte("bob")); err != nil {
+		t.Fatal(err)
+	} else if string(value) != "user" {
+		t.Fatalf("Wrong value written to the users' database: %s", value)
+	}
+	if value, err := data.Get([]byte("key")); err != nil {
+		t.Fatal(err)
+	} else if string(value) != "value" {
+		t.Fatalf("Wrong value written to the nested database: %s", value)
+	}
+
+	if _, err := batch.Add(memdb.New()); err == nil {
+		t.Fatalf("Shouldn't have added a database that isn't a view of the base database")
+	}
+}
@@ -0,0 +1,277 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quotadb
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// WarningRatio is the fraction of a quota that, once used, causes a warning to
+// be logged
+const WarningRatio = 0.9
+
+var (
+	// ErrQuotaExceeded is returned when a write would make the databases that
+	// share a quota store more bytes than the quota allows
+	ErrQuotaExceeded = errors.New("database quota exceeded")
+)
+
+// Quota limits the number of bytes that a set of databases may store. An entry
+// uses the length of its key plus the length of its value.
+type Quota struct {
+	lock  sync.Mutex
+	log   logging.Logger
+	limit uint64
+	usage uint64
+
+	// True once a warning was logged that the usage is close to the limit
+	warned bool
+
+	usageMetric, limitMetric prometheus.Gauge
+	rejectedMetric           prometheus.Counter
+}
+
+// NewQuota returns a quota of [limit] bytes. Warnings are logged to [log] and
+// metrics are registered with [registerer] in [namespace].
+func NewQuota(limit uint64, log logging.Logger, namespace string, registerer prometheus.Registerer) (*Quota, error) {
+	q := &Quota{
+		log:   log,
+		limit: limit,
+		usageMetric: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "db_usage",
+				Help:      "Number of bytes stored in the database",
+			}),
+		limitMetric: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "db_quota",
+				Help:      "Number of bytes the database may store",
+			}),
+		rejectedMetric: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "db_quota_rejected",
+				Help:      "Number of writes rejected because they would exceed the database quota",
+			}),
+	}
+	q.limitMetric.Set(float64(limit))
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(q.usageMetric),
+		registerer.Register(q.limitMetric),
+		registerer.Register(q.rejectedMetric),
+	)
+	return q, errs.Err
+}
+
+// Limit returns the number of bytes the databases may store
+func (q *Quota) Limit() uint64 { return q.limit }
+
+// Usage returns the number of bytes the databases store
+func (q *Quota) Usage() uint64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.usage
+}
+
+// charge adds [delta] bytes to the usage. If [enforce] is true, an increase
+// that would make the usage exceed the limit fails and isn't added.
+func (q *Quota) charge(delta int64, enforce bool) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	switch {
+	case delta >= 0:
+		if enforce && delta > 0 && q.usage+uint64(delta) > q.limit {
+			q.rejectedMetric.Inc()
+			return ErrQuotaExceeded
+		}
+		q.usage += uint64(delta)
+	case uint64(-delta) > q.usage:
+		q.usage = 0
+	default:
+		q.usage -= uint64(-delta)
+	}
+	q.usageMetric.Set(float64(q.usage))
+
+	warnAt := uint64(float64(q.limit) * WarningRatio)
+	switch {
+	case q.usage >= warnAt && !q.warned:
+		q.warned = true
+		q.log.Warn("database is storing %d bytes, which is close to its quota of %d bytes. Writes that exceed the quota will fail", q.usage, q.limit)
+	case q.usage < warnAt:
+		q.warned = false
+	}
+	return nil
+}
+
+// Database is a database whose writes fail if they would make it, and the
+// other databases sharing its quota, store more bytes than the quota allows
+type Database struct {
+	database.Database
+
+	lock  sync.Mutex
+	quota *Quota
+}
+
+// New returns [db], limited by [quota]. The entries already in [db] count
+// towards the quota, even if they exceed it.
+func New(db database.Database, quota *Quota) (*Database, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	usage := int64(0)
+	for it.Next() {
+		usage += size(it.Key(), it.Value())
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if err := quota.charge(usage, false); err != nil {
+		return nil, err
+	}
+	return &Database{
+		Database: db,
+		quota:    quota,
+	}, nil
+}
+
+// Put implements the Database interface
+func (db *Database) Put(key, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	oldSize, err := db.entrySize(key)
+	if err != nil {
+		return err
+	}
+	delta := size(key, value) - oldSize
+	if err := db.quota.charge(delta, true); err != nil {
+		return err
+	}
+	if err := db.Database.Put(key, value); err != nil {
+		db.quota.charge(-delta, false)
+		return err
+	}
+	return nil
+}
+
+// Delete implements the Database interface
+func (db *Database) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	oldSize, err := db.entrySize(key)
+	if err != nil {
+		return err
+	}
+	if err := db.Database.Delete(key); err != nil {
+		return err
+	}
+	return db.quota.charge(-oldSize, false)
+}
+
+// NewBatch implements the Database interface
+func (db *Database) NewBatch() database.Batch {
+	return &batch{
+		Batch: db.Database.NewBatch(),
+		db:    db,
+	}
+}
+
+// entrySize returns the number of bytes the entry with key [key] uses, or 0 if
+// there is no such entry
+func (db *Database) entrySize(key []byte) (int64, error) {
+	value, err := db.Database.Get(key)
+	switch err {
+	case nil:
+		return size(key, value), nil
+	case database.ErrNotFound:
+		return 0, nil
+	default:
+		return 0, err
+	}
+}
+
+type keyValue struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+type batch struct {
+	database.Batch
+	db     *Database
+	writes []keyValue
+}
+
+// Put implements the Batch interface
+func (b *batch) Put(key, value []byte) error {
+	b.writes = append(b.writes, keyValue{copyBytes(key), copyBytes(value), false})
+	return b.Batch.Put(key, value)
+}
+
+// Delete implements the Batch interface
+func (b *batch) Delete(key []byte) error {
+	b.writes = append(b.writes, keyValue{copyBytes(key), nil, true})
+	return b.Batch.Delete(key)
+}
+
+// Write fails if the batch would make the database exceed its quota
+func (b *batch) Write() error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	// Only the last write to a key determines the size of its entry
+	last := make(map[string]keyValue, len(b.writes))
+	for _, kv := range b.writes {
+		last[string(kv.key)] = kv
+	}
+
+	delta := int64(0)
+	for _, kv := range last {
+		oldSize, err := b.db.entrySize(kv.key)
+		if err != nil {
+			return err
+		}
+		delta -= oldSize
+		if !kv.delete {
+			delta += size(kv.key, kv.value)
+		}
+	}
+
+	if err := b.db.quota.charge(delta, true); err != nil {
+		return err
+	}
+	if err := b.Batch.Write(); err != nil {
+		b.db.quota.charge(-delta, false)
+		return err
+	}
+	return nil
+}
+
+// Reset implements the Batch interface
+func (b *batch) Reset() {
+	b.writes = b.writes[:0]
+	b.Batch.Reset()
+}
+
+func size(key, value []byte) int64 { return int64(len(key) + len(value)) }
+
+func copyBytes(bytes []byte) []byte {
+	copiedBytes := make([]byte, len(bytes))
+	copy(copiedBytes, bytes)
+	return copiedBytes
+}
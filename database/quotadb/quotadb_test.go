@@ -0,0 +1,138 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package quotadb
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func newQuota(t *testing.T, limit uint64) *Quota {
+	quota, err := NewQuota(limit, logging.NoLog{}, "", prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return quota
+}
+
+func TestInterface(t *testing.T) {
+	for _, test := range database.Tests {
+		db, err := New(memdb.New(), newQuota(t, 1<<20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		test(t, db)
+	}
+}
+
+func TestQuota(t *testing.T) {
+	quota := newQuota(t, 10)
+	db, err := New(memdb.New(), quota)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Put([]byte{1}, []byte{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if usage := quota.Usage(); usage != 5 {
+		t.Fatalf("usage should be 5 but is %d", usage)
+	}
+
+	// Overwriting an entry only uses the difference in size
+	if err := db.Put([]byte{1}, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}); err != nil {
+		t.Fatal(err)
+	}
+	if usage := quota.Usage(); usage != 10 {
+		t.Fatalf("usage should be 10 but is %d", usage)
+	}
+
+	if err := db.Put([]byte{2}, nil); err != ErrQuotaExceeded {
+		t.Fatalf("put should have exceeded the quota but returned %v", err)
+	}
+	if has, err := db.Has([]byte{2}); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("rejected put shouldn't have been written")
+	}
+
+	if err := db.Delete([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if usage := quota.Usage(); usage != 0 {
+		t.Fatalf("usage should be 0 but is %d", usage)
+	}
+}
+
+func TestQuotaBatch(t *testing.T) {
+	quota := newQuota(t, 10)
+	db, err := New(memdb.New(), quota)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := db.NewBatch()
+	if err := batch.Put([]byte{1}, []byte{1, 2, 3, 4, 5, 6}); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Put([]byte{2}, []byte{1, 2, 3, 4, 5, 6}); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != ErrQuotaExceeded {
+		t.Fatalf("batch should have exceeded the quota but returned %v", err)
+	}
+	if has, err := db.Has([]byte{1}); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("rejected batch shouldn't have been written")
+	}
+
+	// Deleting the second entry in the same batch keeps it within the quota
+	if err := batch.Delete([]byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if usage := quota.Usage(); usage != 7 {
+		t.Fatalf("usage should be 7 but is %d", usage)
+	}
+}
+
+func TestQuotaShared(t *testing.T) {
+	baseDB := memdb.New()
+	if err := baseDB.Put([]byte{1}, []byte{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	quota := newQuota(t, 10)
+	db0, err := New(baseDB, quota)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db1, err := New(memdb.New(), quota)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The existing entry counts towards the quota
+	if usage := quota.Usage(); usage != 5 {
+		t.Fatalf("usage should be 5 but is %d", usage)
+	}
+
+	if err := db1.Put([]byte{1}, []byte{1, 2, 3, 4, 5}); err != ErrQuotaExceeded {
+		t.Fatalf("put should have exceeded the shared quota but returned %v", err)
+	}
+	if err := db0.Delete([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db1.Put([]byte{1}, []byte{1, 2, 3, 4, 5}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package readonlydb
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+var (
+	// ErrReadOnly is returned when a read-only database is written to
+	ErrReadOnly = errors.New("database is read-only")
+)
+
+// Database is a view of another database that can be read but not written.
+// Closing it doesn't close the database it's a view of.
+type Database struct {
+	db database.Database
+}
+
+// New returns a read-only view of [db]
+func New(db database.Database) *Database { return &Database{db: db} }
+
+// Has implements the Database interface
+func (db *Database) Has(key []byte) (bool, error) { return db.db.Has(key) }
+
+// Get implements the Database interface
+func (db *Database) Get(key []byte) ([]byte, error) { return db.db.Get(key) }
+
+// Put returns ErrReadOnly
+func (*Database) Put(_, _ []byte) error { return ErrReadOnly }
+
+// Delete returns ErrReadOnly
+func (*Database) Delete([]byte) error { return ErrReadOnly }
+
+// NewBatch returns a batch that can't be written
+func (*Database) NewBatch() database.Batch { return &batch{} }
+
+// NewIterator implements the Database interface
+func (db *Database) NewIterator() database.Iterator { return db.db.NewIterator() }
+
+// NewIteratorWithStart implements the Database interface
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.db.NewIteratorWithStart(start)
+}
+
+// NewIteratorWithPrefix implements the Database interface
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.db.NewIteratorWithPrefix(prefix)
+}
+
+// NewIteratorWithStartAndPrefix implements the Database interface
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return db.db.NewIteratorWithStartAndPrefix(start, prefix)
+}
+
+// Stat implements the Database interface
+func (db *Database) Stat(property string) (string, error) { return db.db.Stat(property) }
+
+// Compact returns ErrReadOnly
+func (*Database) Compact(_, _ []byte) error { return ErrReadOnly }
+
+// Close does nothing, since the database this is a view of may still be used
+func (*Database) Close() error { return nil }
+
+// batch rejects every write
+type batch struct{}
+
+// Put returns ErrReadOnly
+func (*batch) Put(_, _ []byte) error { return ErrReadOnly }
+
+// Delete returns ErrReadOnly
+func (*batch) Delete([]byte) error { return ErrReadOnly }
+
+// ValueSize returns 0
+func (*batch) ValueSize() int { return 0 }
+
+// Write does nothing, since nothing could be added to the batch
+func (*batch) Write() error { return nil }
+
+// Reset does nothing
+func (*batch) Reset() {}
+
+// Replay does nothing
+func (*batch) Replay(database.KeyValueWriter) error { return nil }
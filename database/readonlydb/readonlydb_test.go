@@ -0,0 +1,54 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package readonlydb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+func TestReadOnly(t *testing.T) {
+	baseDB := memdb.New()
+	if err := baseDB.Put([]byte{1}, []byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	db := New(baseDB)
+
+	if value, err := db.Get([]byte{1}); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(value, []byte{2}) {
+		t.Fatalf("Expected %v but got %v", []byte{2}, value)
+	}
+	it := db.NewIterator()
+	if !it.Next() || !bytes.Equal(it.Key(), []byte{1}) {
+		t.Fatalf("Should have iterated over the entry of the underlying database")
+	}
+	it.Release()
+
+	if err := db.Put([]byte{3}, []byte{4}); err != ErrReadOnly {
+		t.Fatalf("Put should have failed with %s but returned %v", ErrReadOnly, err)
+	}
+	if err := db.Delete([]byte{1}); err != ErrReadOnly {
+		t.Fatalf("Delete should have failed with %s but returned %v", ErrReadOnly, err)
+	}
+	batch := db.NewBatch()
+	if err := batch.Put([]byte{3}, []byte{4}); err != ErrReadOnly {
+		t.Fatalf("Batch put should have failed with %s but returned %v", ErrReadOnly, err)
+	}
+	if has, err := baseDB.Has([]byte{3}); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Rejected writes shouldn't reach the underlying database")
+	}
+
+	// Closing the view leaves the underlying database open
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := baseDB.Get([]byte{1}); err != nil {
+		t.Fatalf("Underlying database should still be open but failed with %s", err)
+	}
+}
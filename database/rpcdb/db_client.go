@@ -0,0 +1,265 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcdb
+
+import (
+	"context"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/nodb"
+	"github.com/ava-labs/gecko/database/rpcdb/rpcdbproto"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// DatabaseClient is an implementation of database that talks over RPC.
+type DatabaseClient struct{ client rpcdbproto.DatabaseClient }
+
+// NewClient returns a database instance connected to a remote database instance
+func NewClient(client rpcdbproto.DatabaseClient) *DatabaseClient {
+	return &DatabaseClient{client: client}
+}
+
+// Has returns whether [key] is in the database
+func (db *DatabaseClient) Has(key []byte) (bool, error) {
+	resp, err := db.client.Has(context.Background(), &rpcdbproto.HasRequest{
+		Key: key,
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.Has, errCodeToError[resp.Err]
+}
+
+// Get returns the value of [key]
+func (db *DatabaseClient) Get(key []byte) ([]byte, error) {
+	resp, err := db.client.Get(context.Background(), &rpcdbproto.GetRequest{
+		Key: key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Value, errCodeToError[resp.Err]
+}
+
+// Put sets the value of [key] to [value]
+func (db *DatabaseClient) Put(key, value []byte) error {
+	resp, err := db.client.Put(context.Background(), &rpcdbproto.PutRequest{
+		Key:   key,
+		Value: value,
+	})
+	if err != nil {
+		return err
+	}
+	return errCodeToError[resp.Err]
+}
+
+// Delete removes [key] from the database
+func (db *DatabaseClient) Delete(key []byte) error {
+	resp, err := db.client.Delete(context.Background(), &rpcdbproto.DeleteRequest{
+		Key: key,
+	})
+	if err != nil {
+		return err
+	}
+	return errCodeToError[resp.Err]
+}
+
+// NewBatch returns a new batch
+func (db *DatabaseClient) NewBatch() database.Batch { return &batch{db: db} }
+
+// NewIterator implements the Database interface
+func (db *DatabaseClient) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+// NewIteratorWithStart implements the Database interface
+func (db *DatabaseClient) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+// NewIteratorWithPrefix implements the Database interface
+func (db *DatabaseClient) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix returns an iterator over the keys with
+// [prefix] that are at least [start]. The iterator lives on the server and
+// its key/value pairs are fetched in batches.
+func (db *DatabaseClient) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	resp, err := db.client.NewIterator(context.Background(), &rpcdbproto.NewIteratorRequest{
+		Start:  start,
+		Prefix: prefix,
+	})
+	if err != nil {
+		return &nodb.Iterator{Err: err}
+	}
+	return &iterator{
+		db: db,
+		id: resp.Id,
+	}
+}
+
+// Stat attempts to return the statistic of this database
+func (db *DatabaseClient) Stat(property string) (string, error) {
+	resp, err := db.client.Stat(context.Background(), &rpcdbproto.StatRequest{
+		Property: property,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Stat, errCodeToError[resp.Err]
+}
+
+// Compact attempts to optimize the space utilization in the provided range
+func (db *DatabaseClient) Compact(start, limit []byte) error {
+	resp, err := db.client.Compact(context.Background(), &rpcdbproto.CompactRequest{
+		Start: start,
+		Limit: limit,
+	})
+	if err != nil {
+		return err
+	}
+	return errCodeToError[resp.Err]
+}
+
+// Close attempts to close the database
+func (db *DatabaseClient) Close() error {
+	resp, err := db.client.Close(context.Background(), &rpcdbproto.CloseRequest{})
+	if err != nil {
+		return err
+	}
+	return errCodeToError[resp.Err]
+}
+
+// batch buffers writes until Write is called, when they are sent to the
+// server in one call
+type batch struct {
+	db   *DatabaseClient
+	ops  []*rpcdbproto.BatchOp
+	size int
+}
+
+func (b *batch) Put(key, value []byte) error {
+	b.ops = append(b.ops, &rpcdbproto.BatchOp{
+		Key:   copyBytes(key),
+		Value: copyBytes(value),
+	})
+	b.size += len(value)
+	return nil
+}
+
+func (b *batch) Delete(key []byte) error {
+	b.ops = append(b.ops, &rpcdbproto.BatchOp{
+		Key:    copyBytes(key),
+		Delete: true,
+	})
+	b.size++
+	return nil
+}
+
+// ValueSize implements the Batch interface
+func (b *batch) ValueSize() int { return b.size }
+
+// Write implements the Batch interface
+func (b *batch) Write() error {
+	resp, err := b.db.client.WriteBatch(context.Background(), &rpcdbproto.WriteBatchRequest{
+		Ops: b.ops,
+	})
+	if err != nil {
+		return err
+	}
+	return errCodeToError[resp.Err]
+}
+
+// Reset implements the Batch interface
+func (b *batch) Reset() {
+	b.ops = nil
+	b.size = 0
+}
+
+// Replay implements the Batch interface
+func (b *batch) Replay(w database.KeyValueWriter) error {
+	for _, op := range b.ops {
+		if op.Delete {
+			if err := w.Delete(op.Key); err != nil {
+				return err
+			}
+		} else if err := w.Put(op.Key, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterator iterates over a database's key/value pairs on the server
+type iterator struct {
+	db *DatabaseClient
+	id uint64
+
+	// The pairs fetched from the server that haven't been iterated past. The
+	// first is the current pair.
+	data      []*rpcdbproto.KeyValue
+	exhausted bool
+	errs      wrappers.Errs
+}
+
+// Next attempts to move the iterator to the next element and returns if this
+// succeeded
+func (it *iterator) Next() bool {
+	if len(it.data) > 0 {
+		it.data[0] = nil
+		it.data = it.data[1:]
+	}
+	if len(it.data) == 0 && !it.exhausted && !it.errs.Errored() {
+		resp, err := it.db.client.IteratorNext(context.Background(), &rpcdbproto.IteratorNextRequest{
+			Id: it.id,
+		})
+		if err != nil {
+			it.errs.Add(err)
+			return false
+		}
+		it.data = resp.Data
+		it.exhausted = len(it.data) == 0
+	}
+	return len(it.data) > 0
+}
+
+// Error returns any errors that occurred while iterating
+func (it *iterator) Error() error {
+	if it.errs.Errored() {
+		return it.errs.Err
+	}
+	resp, err := it.db.client.IteratorError(context.Background(), &rpcdbproto.IteratorErrorRequest{
+		Id: it.id,
+	})
+	if err != nil {
+		it.errs.Add(err)
+		return err
+	}
+	return errCodeToError[resp.Err]
+}
+
+// Key returns the key of the current element
+func (it *iterator) Key() []byte {
+	if len(it.data) == 0 {
+		return nil
+	}
+	return it.data[0].Key
+}
+
+// Value returns the value of the current element
+func (it *iterator) Value() []byte {
+	if len(it.data) == 0 {
+		return nil
+	}
+	return it.data[0].Value
+}
+
+// Release frees any resources held by the iterator
+func (it *iterator) Release() {
+	it.data = nil
+	it.db.client.IteratorRelease(context.Background(), &rpcdbproto.IteratorReleaseRequest{
+		Id: it.id,
+	})
+}
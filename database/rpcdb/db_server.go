@@ -0,0 +1,196 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/rpcdb/rpcdbproto"
+)
+
+const (
+	// maxIteratorBatchSize is the most key and value bytes that are returned
+	// by one call to IteratorNext, unless a single pair is larger
+	maxIteratorBatchSize = 128 * 1024
+)
+
+var (
+	errUnknownIterator = errors.New("unknown iterator")
+)
+
+// DatabaseServer is a database that is managed over RPC.
+type DatabaseServer struct {
+	rpcdbproto.UnimplementedDatabaseServer
+
+	lock  sync.Mutex
+	db    database.Database
+	batch database.Batch
+
+	nextIteratorID uint64
+	iterators      map[uint64]database.Iterator
+}
+
+// NewServer returns a database instance that is managed remotely
+func NewServer(db database.Database) *DatabaseServer {
+	return &DatabaseServer{
+		db:        db,
+		batch:     db.NewBatch(),
+		iterators: make(map[uint64]database.Iterator),
+	}
+}
+
+// Has delegates the Has call to the managed database and returns the result
+func (db *DatabaseServer) Has(_ context.Context, req *rpcdbproto.HasRequest) (*rpcdbproto.HasResponse, error) {
+	has, err := db.db.Has(req.Key)
+	return &rpcdbproto.HasResponse{
+		Has: has,
+		Err: errorToErrCode[err],
+	}, errorToRPCError(err)
+}
+
+// Get delegates the Get call to the managed database and returns the result
+func (db *DatabaseServer) Get(_ context.Context, req *rpcdbproto.GetRequest) (*rpcdbproto.GetResponse, error) {
+	value, err := db.db.Get(req.Key)
+	return &rpcdbproto.GetResponse{
+		Value: value,
+		Err:   errorToErrCode[err],
+	}, errorToRPCError(err)
+}
+
+// Put delegates the Put call to the managed database and returns the result
+func (db *DatabaseServer) Put(_ context.Context, req *rpcdbproto.PutRequest) (*rpcdbproto.PutResponse, error) {
+	err := db.db.Put(req.Key, req.Value)
+	return &rpcdbproto.PutResponse{Err: errorToErrCode[err]}, errorToRPCError(err)
+}
+
+// Delete delegates the Delete call to the managed database and returns the
+// result
+func (db *DatabaseServer) Delete(_ context.Context, req *rpcdbproto.DeleteRequest) (*rpcdbproto.DeleteResponse, error) {
+	err := db.db.Delete(req.Key)
+	return &rpcdbproto.DeleteResponse{Err: errorToErrCode[err]}, errorToRPCError(err)
+}
+
+// Stat delegates the Stat call to the managed database and returns the result
+func (db *DatabaseServer) Stat(_ context.Context, req *rpcdbproto.StatRequest) (*rpcdbproto.StatResponse, error) {
+	stat, err := db.db.Stat(req.Property)
+	return &rpcdbproto.StatResponse{
+		Stat: stat,
+		Err:  errorToErrCode[err],
+	}, errorToRPCError(err)
+}
+
+// Compact delegates the Compact call to the managed database and returns the
+// result
+func (db *DatabaseServer) Compact(_ context.Context, req *rpcdbproto.CompactRequest) (*rpcdbproto.CompactResponse, error) {
+	err := db.db.Compact(req.Start, req.Limit)
+	return &rpcdbproto.CompactResponse{Err: errorToErrCode[err]}, errorToRPCError(err)
+}
+
+// Close delegates the Close call to the managed database and returns the result
+func (db *DatabaseServer) Close(context.Context, *rpcdbproto.CloseRequest) (*rpcdbproto.CloseResponse, error) {
+	err := db.db.Close()
+	return &rpcdbproto.CloseResponse{Err: errorToErrCode[err]}, errorToRPCError(err)
+}
+
+// WriteBatch takes in a set of key-value pairs and atomically writes them to
+// the internal database
+func (db *DatabaseServer) WriteBatch(_ context.Context, req *rpcdbproto.WriteBatchRequest) (*rpcdbproto.WriteBatchResponse, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.batch.Reset()
+	defer db.batch.Reset()
+
+	for _, op := range req.Ops {
+		var err error
+		if op.Delete {
+			err = db.batch.Delete(op.Key)
+		} else {
+			err = db.batch.Put(op.Key, op.Value)
+		}
+		if err != nil {
+			return &rpcdbproto.WriteBatchResponse{Err: errorToErrCode[err]}, errorToRPCError(err)
+		}
+	}
+
+	err := db.batch.Write()
+	return &rpcdbproto.WriteBatchResponse{Err: errorToErrCode[err]}, errorToRPCError(err)
+}
+
+// NewIterator creates a new iterator, over the keys with [req.Prefix] that are
+// at least [req.Start], and returns its ID
+func (db *DatabaseServer) NewIterator(_ context.Context, req *rpcdbproto.NewIteratorRequest) (*rpcdbproto.NewIteratorResponse, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	id := db.nextIteratorID
+	db.nextIteratorID++
+	db.iterators[id] = db.db.NewIteratorWithStartAndPrefix(req.Start, req.Prefix)
+	return &rpcdbproto.NewIteratorResponse{Id: id}, nil
+}
+
+// IteratorNext returns the next batch of key/value pairs of an iterator
+func (db *DatabaseServer) IteratorNext(_ context.Context, req *rpcdbproto.IteratorNextRequest) (*rpcdbproto.IteratorNextResponse, error) {
+	it, err := db.iterator(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []*rpcdbproto.KeyValue(nil)
+	size := 0
+	for size < maxIteratorBatchSize && it.Next() {
+		// The iterator may reuse the memory of its key and value
+		key := copyBytes(it.Key())
+		value := copyBytes(it.Value())
+		data = append(data, &rpcdbproto.KeyValue{
+			Key:   key,
+			Value: value,
+		})
+		size += len(key) + len(value)
+	}
+	return &rpcdbproto.IteratorNextResponse{Data: data}, nil
+}
+
+// IteratorError returns the error of an iterator
+func (db *DatabaseServer) IteratorError(_ context.Context, req *rpcdbproto.IteratorErrorRequest) (*rpcdbproto.IteratorErrorResponse, error) {
+	it, err := db.iterator(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	err = it.Error()
+	return &rpcdbproto.IteratorErrorResponse{Err: errorToErrCode[err]}, errorToRPCError(err)
+}
+
+// IteratorRelease releases an iterator
+func (db *DatabaseServer) IteratorRelease(_ context.Context, req *rpcdbproto.IteratorReleaseRequest) (*rpcdbproto.IteratorReleaseResponse, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if it, exists := db.iterators[req.Id]; exists {
+		delete(db.iterators, req.Id)
+		it.Release()
+	}
+	return &rpcdbproto.IteratorReleaseResponse{}, nil
+}
+
+// iterator returns the iterator with ID [id]
+func (db *DatabaseServer) iterator(id uint64) (database.Iterator, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	it, exists := db.iterators[id]
+	if !exists {
+		return nil, errUnknownIterator
+	}
+	return it, nil
+}
+
+func copyBytes(bytes []byte) []byte {
+	copiedBytes := make([]byte, len(bytes))
+	copy(copiedBytes, bytes)
+	return copiedBytes
+}
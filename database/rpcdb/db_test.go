@@ -0,0 +1,80 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcdb
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/rpcdb/rpcdbproto"
+)
+
+const (
+	bufSize = 1 << 20
+)
+
+func setupDB(t *testing.T) *DatabaseClient {
+	listener := bufconn.Listen(bufSize)
+	server := grpc.NewServer()
+	rpcdbproto.RegisterDatabaseServer(server, NewServer(memdb.New()))
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			t.Logf("Server exited with error: %v", err)
+		}
+	}()
+
+	dialer := grpc.WithContextDialer(
+		func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		})
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "", dialer, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Failed to dial: %s", err)
+	}
+	return NewClient(rpcdbproto.NewDatabaseClient(conn))
+}
+
+func TestInterface(t *testing.T) {
+	for _, test := range database.Tests {
+		test(t, setupDB(t))
+	}
+}
+
+func TestIteratorBatches(t *testing.T) {
+	db := setupDB(t)
+
+	// Enough data that the iterator must fetch it in more than one batch
+	value := make([]byte, 1024)
+	numKeys := 2 * maxIteratorBatchSize / len(value)
+	for i := 0; i < numKeys; i++ {
+		if err := db.Put([]byte{byte(i >> 8), byte(i)}, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		if key := it.Key(); key[0] != byte(count>>8) || key[1] != byte(count) {
+			t.Fatalf("Wrong key 0x%x at position %d", key, count)
+		}
+		count++
+	}
+	if count != numKeys {
+		t.Fatalf("Iterated over %d keys but expected %d", count, numKeys)
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,29 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcdb
+
+import (
+	"github.com/ava-labs/gecko/database"
+)
+
+// Errors that are returned as codes, rather than as gRPC errors, so that they
+// can be compared against on the other side of the connection
+var (
+	errCodeToError = map[uint32]error{
+		1: database.ErrClosed,
+		2: database.ErrNotFound,
+	}
+	errorToErrCode = map[error]uint32{
+		database.ErrClosed:   1,
+		database.ErrNotFound: 2,
+	}
+)
+
+// errorToRPCError returns [err] if it can't be sent as a code
+func errorToRPCError(err error) error {
+	if _, ok := errorToErrCode[err]; ok {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"errors"
+)
+
+var errNotShared = errors.New("database isn't a view of the shared batch's database")
+
+// View is a database whose keys and values are stored in another database, such
+// as a prefixed sub-database.
+type View interface {
+	Database
+
+	// Inner returns the database this view stores its keys and values in
+	Inner() Database
+
+	// WrapBatch returns a batch that writes the keys and values this view
+	// would store to [batch], which is a batch of the inner database
+	WrapBatch(batch Batch) Batch
+}
+
+// SharedBatch is a batch of a base database that views of the base database
+// can add writes to. The writes of every view are committed to the base
+// database together, so either all of them or none of them are.
+type SharedBatch struct {
+	db    Database
+	batch Batch
+}
+
+// NewSharedBatch returns a shared batch of [db]
+func NewSharedBatch(db Database) *SharedBatch {
+	return &SharedBatch{
+		db:    db,
+		batch: db.NewBatch(),
+	}
+}
+
+// Add returns a writer whose writes to [db] are added to this batch. [db] must
+// be the base database or a view of it, possibly through other views.
+func (b *SharedBatch) Add(db Database) (KeyValueWriter, error) {
+	return b.add(db)
+}
+
+func (b *SharedBatch) add(db Database) (Batch, error) {
+	if db == b.db {
+		return b.batch, nil
+	}
+	view, ok := db.(View)
+	if !ok {
+		return nil, errNotShared
+	}
+	inner, err := b.add(view.Inner())
+	if err != nil {
+		return nil, err
+	}
+	return view.WrapBatch(inner), nil
+}
+
+// ValueSize returns the amount of data added to this batch
+func (b *SharedBatch) ValueSize() int { return b.batch.ValueSize() }
+
+// Write commits the writes of every view to the base database atomically
+func (b *SharedBatch) Write() error { return b.batch.Write() }
+
+// Reset discards the writes added to this batch
+func (b *SharedBatch) Reset() { b.batch.Reset() }
@@ -21,6 +21,7 @@ var (
 		TestIteratorStart,
 		TestIteratorPrefix,
 		TestIteratorStartPrefix,
+		TestIteratorStartPrefixBounds,
 		TestIteratorClosed,
 		TestStatNoPanic,
 		TestCompactNoPanic,
@@ -464,6 +465,57 @@ func TestIteratorStartPrefix(t *testing.T, db Database) {
 	}
 }
 
+// TestIteratorStartPrefixBounds ...
+func TestIteratorStartPrefixBounds(t *testing.T, db Database) {
+	keys := [][]byte{
+		[]byte("a"),
+		[]byte("hello1"),
+		[]byte("hello3"),
+		[]byte("z"),
+	}
+	for _, key := range keys {
+		if err := db.Put(key, key); err != nil {
+			t.Fatalf("Unexpected error on db.Put: %s", err)
+		}
+	}
+
+	tests := []struct {
+		start, prefix []byte
+		expected      [][]byte
+	}{
+		// A start before the prefix begins at the first key with the prefix
+		{start: []byte("a"), prefix: []byte("h"), expected: keys[1:3]},
+		// A start between the keys with the prefix begins at the next one
+		{start: []byte("hello2"), prefix: []byte("h"), expected: keys[2:3]},
+		// A start after the keys with the prefix ends the iteration
+		{start: []byte("i"), prefix: []byte("h"), expected: nil},
+		// A prefix that no key has ends the iteration
+		{start: nil, prefix: []byte("b"), expected: nil},
+	}
+	for _, test := range tests {
+		iterator := db.NewIteratorWithStartAndPrefix(test.start, test.prefix)
+		if iterator == nil {
+			t.Fatalf("db.NewIteratorWithStartAndPrefix returned nil")
+		}
+
+		for _, expected := range test.expected {
+			if !iterator.Next() {
+				t.Fatalf("iterator.Next Returned: %v ; Expected: %v", false, true)
+			} else if key := iterator.Key(); !bytes.Equal(key, expected) {
+				t.Fatalf("iterator.Key Returned: 0x%x ; Expected: 0x%x", key, expected)
+			} else if value := iterator.Value(); !bytes.Equal(value, expected) {
+				t.Fatalf("iterator.Value Returned: 0x%x ; Expected: 0x%x", value, expected)
+			}
+		}
+		if iterator.Next() {
+			t.Fatalf("iterator.Next Returned: %v ; Expected: %v", true, false)
+		} else if err := iterator.Error(); err != nil {
+			t.Fatalf("iterator.Error Returned: %s ; Expected: nil", err)
+		}
+		iterator.Release()
+	}
+}
+
 // TestIteratorClosed ...
 func TestIteratorClosed(t *testing.T, db Database) {
 	key1 := []byte("hello1")
@@ -0,0 +1,63 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"errors"
+)
+
+// ErrNotVerifiable is returned when verifying a database that can't check its
+// data
+var ErrNotVerifiable = errors.New("database doesn't support verification")
+
+// ErrNotRepairable is returned when repairing a database that can't drop its
+// corrupt data
+var ErrNotRepairable = errors.New("database doesn't support repairs")
+
+// CorruptRange is a range of keys whose stored data failed its checksum
+type CorruptRange struct {
+	// Start is the last key that was read before the corrupt data, or nil if
+	// it's at the start of the database
+	Start []byte
+
+	// Limit is the first key that was read after the corrupt data, or nil if
+	// it's at the end of the database
+	Limit []byte
+}
+
+// Verifier wraps the Verify method of a backing data store.
+type Verifier interface {
+	// Verify reads all of the data in the data store, checking it against its
+	// checksums, and returns the ranges of keys whose data is corrupt. It
+	// keeps reading after corrupt data.
+	Verify() ([]CorruptRange, error)
+}
+
+// Verify checks the data of [db] against its checksums, if [db] can. Otherwise
+// it returns ErrNotVerifiable.
+func Verify(db Database) ([]CorruptRange, error) {
+	verifier, ok := db.(Verifier)
+	if !ok {
+		return nil, ErrNotVerifiable
+	}
+	return verifier.Verify()
+}
+
+// Repairer wraps the Repair method of a backing data store.
+type Repairer interface {
+	// Repair drops the data in the data store that fails its checksums, so
+	// that Verify no longer finds corrupt ranges. The keys whose data was
+	// corrupt are lost.
+	Repair() error
+}
+
+// Repair drops the corrupt data of [db], if [db] can. Otherwise it returns
+// ErrNotRepairable.
+func Repair(db Database) error {
+	repairer, ok := db.(Repairer)
+	if !ok {
+		return ErrNotRepairable
+	}
+	return repairer.Repair()
+}
@@ -0,0 +1,270 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package versiondb
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	errUnknownCheckpoint = errors.New("unknown checkpoint")
+
+	// checkpointsKey is shorter than the prefix of an undo log, so it's never
+	// iterated over as part of one
+	checkpointsKey = []byte("meta")
+)
+
+// checkpoint is a state of the underlying database that can be rolled back
+// to. The undo logs numbered after [log] hold the commits made after it.
+type checkpoint struct {
+	id  ids.ID
+	log uint64
+}
+
+// Checkpoints records how to undo the commits of a Database, so that the
+// database it writes to can be rolled back to its state at one of the last
+// [max] checkpoints.
+//
+// Commits are recorded in undo logs, which hold the value each key that was
+// committed had before the log was started. Every checkpoint starts a new undo
+// log, so rolling back to a checkpoint applies the logs started after it,
+// newest first.
+type Checkpoints struct {
+	lock sync.Mutex
+
+	// db holds the undo logs. It must not overlap the database being
+	// checkpointed.
+	db  database.Database
+	max int
+
+	// checkpoints that can be rolled back to, oldest first
+	checkpoints []checkpoint
+
+	// number of the undo log that commits are recorded in
+	log uint64
+
+	// ID of the checkpoint to mark after the next commit, if not empty
+	pending ids.ID
+}
+
+// NewCheckpoints returns the checkpoints stored in [db], which keeps at most
+// [max] of them
+func NewCheckpoints(db database.Database, max int) (*Checkpoints, error) {
+	c := &Checkpoints{
+		db:  db,
+		max: max,
+	}
+
+	bytes, err := db.Get(checkpointsKey)
+	if err == database.ErrNotFound {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := wrappers.Packer{Bytes: bytes}
+	c.log = p.UnpackLong()
+	numCheckpoints := p.UnpackInt()
+	for i := uint32(0); i < numCheckpoints && !p.Errored(); i++ {
+		id, _ := ids.ToID(p.UnpackFixedBytes(32))
+		c.checkpoints = append(c.checkpoints, checkpoint{
+			id:  id,
+			log: p.UnpackLong(),
+		})
+	}
+	return c, p.Err
+}
+
+// IDs returns the IDs of the checkpoints that can be rolled back to, oldest
+// first
+func (c *Checkpoints) IDs() []ids.ID {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	checkpointIDs := make([]ids.ID, len(c.checkpoints))
+	for i, cp := range c.checkpoints {
+		checkpointIDs[i] = cp.id
+	}
+	return checkpointIDs
+}
+
+// Checkpoint marks the state of the underlying database after the next commit
+// as the checkpoint [checkpointID]
+func (c *Checkpoints) Checkpoint(checkpointID ids.ID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.pending = checkpointID
+}
+
+// record adds the values that the keys of [changes] have in [db] to the
+// current undo log, unless the log already holds a value for them. Must be
+// called before [changes] are written to [db].
+func (c *Checkpoints) record(db database.Database, changes map[string]valueDelete) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Commits made before the first checkpoint never need to be undone
+	if len(c.checkpoints) == 0 {
+		return nil
+	}
+
+	batch := c.db.NewBatch()
+	for key := range changes {
+		undoKey := logKey(c.log, []byte(key))
+		if recorded, err := c.db.Has(undoKey); err != nil {
+			return err
+		} else if recorded {
+			continue
+		}
+
+		value, err := db.Get([]byte(key))
+		switch err {
+		case nil:
+			err = batch.Put(undoKey, append([]byte{1}, value...))
+		case database.ErrNotFound:
+			err = batch.Put(undoKey, []byte{0})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// committed marks the pending checkpoint, if there is one, after a commit
+func (c *Checkpoints) committed() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.pending.IsZero() {
+		return nil
+	}
+
+	c.checkpoints = append(c.checkpoints, checkpoint{
+		id:  c.pending,
+		log: c.log,
+	})
+	c.log++
+	c.pending = ids.ID{}
+
+	// The logs up to the oldest checkpoint are no longer needed
+	for len(c.checkpoints) > c.max {
+		oldest, next := c.checkpoints[0], c.checkpoints[1]
+		for log := oldest.log + 1; log <= next.log; log++ {
+			if err := c.deleteLog(log); err != nil {
+				return err
+			}
+		}
+		c.checkpoints = c.checkpoints[1:]
+	}
+	return c.put()
+}
+
+// rollback sets [db] back to its state at the checkpoint [checkpointID].
+// Checkpoints after it are forgotten.
+func (c *Checkpoints) rollback(db database.Database, checkpointID ids.ID) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	index := -1
+	for i, cp := range c.checkpoints {
+		if cp.id.Equals(checkpointID) {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return errUnknownCheckpoint
+	}
+	target := c.checkpoints[index]
+
+	for log := c.log; log > target.log; log-- {
+		if err := c.undoLog(db, log); err != nil {
+			return err
+		}
+		if err := c.deleteLog(log); err != nil {
+			return err
+		}
+	}
+
+	c.checkpoints = c.checkpoints[:index+1]
+	c.log = target.log + 1
+	c.pending = ids.ID{}
+	return c.put()
+}
+
+// undoLog writes the values held by the undo log [log] to [db]
+func (c *Checkpoints) undoLog(db database.Database, log uint64) error {
+	prefix := logKey(log, nil)
+	iter := c.db.NewIteratorWithPrefix(prefix)
+	defer iter.Release()
+
+	batch := db.NewBatch()
+	for iter.Next() {
+		key := iter.Key()[len(prefix):]
+		value := iter.Value()
+
+		var err error
+		if len(value) > 0 && value[0] == 1 {
+			err = batch.Put(key, value[1:])
+		} else {
+			err = batch.Delete(key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// deleteLog removes the undo log [log]
+func (c *Checkpoints) deleteLog(log uint64) error {
+	iter := c.db.NewIteratorWithPrefix(logKey(log, nil))
+	defer iter.Release()
+
+	batch := c.db.NewBatch()
+	for iter.Next() {
+		if err := batch.Delete(iter.Key()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// put persists the checkpoints
+func (c *Checkpoints) put() error {
+	p := wrappers.Packer{MaxSize: wrappers.LongLen + wrappers.IntLen + len(c.checkpoints)*(32+wrappers.LongLen)}
+	p.PackLong(c.log)
+	p.PackInt(uint32(len(c.checkpoints)))
+	for _, cp := range c.checkpoints {
+		p.PackFixedBytes(cp.id.Bytes())
+		p.PackLong(cp.log)
+	}
+	if p.Errored() {
+		return p.Err
+	}
+	return c.db.Put(checkpointsKey, p.Bytes)
+}
+
+// logKey returns the key that the undo log [log] holds the value of [key]
+// under
+func logKey(log uint64, key []byte) []byte {
+	undoKey := make([]byte, wrappers.LongLen, wrappers.LongLen+len(key))
+	binary.BigEndian.PutUint64(undoKey, log)
+	return append(undoKey, key...)
+}
@@ -0,0 +1,170 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package versiondb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestCheckpointsRollback(t *testing.T) {
+	baseDB := memdb.New()
+	undoDB := memdb.New()
+
+	checkpoints, err := NewCheckpoints(undoDB, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := New(baseDB)
+	db.SetCheckpoints(checkpoints)
+
+	key1 := []byte("hello1")
+	key2 := []byte("hello2")
+	value1 := []byte("world1")
+	value2 := []byte("world2")
+
+	id0 := ids.Empty.Prefix(0)
+	id1 := ids.Empty.Prefix(1)
+	id2 := ids.Empty.Prefix(2)
+
+	// Checkpoint 0: key1 = value1
+	if err := db.Put(key1, value1); err != nil {
+		t.Fatal(err)
+	}
+	checkpoints.Checkpoint(id0)
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Checkpoint 1: key1 = value2, key2 = value2
+	if err := db.Put(key1, value2); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(key2, value2); err != nil {
+		t.Fatal(err)
+	}
+	checkpoints.Checkpoint(id1)
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// After checkpoint 1: key1 deleted
+	if err := db.Delete(key1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Uncommitted changes are discarded by a rollback
+	if err := db.Put(key2, value1); err != nil {
+		t.Fatal(err)
+	}
+
+	if checkpointIDs := checkpoints.IDs(); len(checkpointIDs) != 2 ||
+		!checkpointIDs[0].Equals(id0) || !checkpointIDs[1].Equals(id1) {
+		t.Fatalf("Wrong checkpoints: %v", checkpointIDs)
+	}
+
+	if err := db.Rollback(id2); err != errUnknownCheckpoint {
+		t.Fatalf("Should have errored with %s", errUnknownCheckpoint)
+	}
+
+	if err := db.Rollback(id1); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := db.Get(key1); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(value, value2) {
+		t.Fatalf("Returned: 0x%x ; Expected: 0x%x", value, value2)
+	}
+	if value, err := db.Get(key2); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(value, value2) {
+		t.Fatalf("Returned: 0x%x ; Expected: 0x%x", value, value2)
+	}
+
+	// The checkpoints should be reloaded from the undo database
+	checkpoints, err = NewCheckpoints(undoDB, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetCheckpoints(checkpoints)
+
+	if err := db.Rollback(id0); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := db.Get(key1); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(value, value1) {
+		t.Fatalf("Returned: 0x%x ; Expected: 0x%x", value, value1)
+	}
+	if _, err := db.Get(key2); err != database.ErrNotFound {
+		t.Fatalf("Should have errored with %s", database.ErrNotFound)
+	}
+	if checkpointIDs := checkpoints.IDs(); len(checkpointIDs) != 1 || !checkpointIDs[0].Equals(id0) {
+		t.Fatalf("Wrong checkpoints: %v", checkpointIDs)
+	}
+}
+
+func TestCheckpointsPrune(t *testing.T) {
+	baseDB := memdb.New()
+	undoDB := memdb.New()
+
+	checkpoints, err := NewCheckpoints(undoDB, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := New(baseDB)
+	db.SetCheckpoints(checkpoints)
+
+	key := []byte("hello")
+	for i := uint64(0); i < 4; i++ {
+		if err := db.Put(key, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+		checkpoints.Checkpoint(ids.Empty.Prefix(i))
+		if err := db.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkpointIDs := checkpoints.IDs()
+	if len(checkpointIDs) != 2 {
+		t.Fatalf("Should have kept 2 checkpoints but kept %d", len(checkpointIDs))
+	}
+	if !checkpointIDs[0].Equals(ids.Empty.Prefix(2)) || !checkpointIDs[1].Equals(ids.Empty.Prefix(3)) {
+		t.Fatalf("Wrong checkpoints: %v", checkpointIDs)
+	}
+
+	if err := db.Rollback(ids.Empty.Prefix(1)); err != errUnknownCheckpoint {
+		t.Fatalf("Should have errored with %s", errUnknownCheckpoint)
+	}
+	if err := db.Rollback(ids.Empty.Prefix(2)); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := db.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(value, []byte{2}) {
+		t.Fatalf("Returned: 0x%x ; Expected: 0x%x", value, []byte{2})
+	}
+
+	// Only the metadata should remain in the undo database
+	iter := undoDB.NewIterator()
+	defer iter.Release()
+
+	numKeys := 0
+	for iter.Next() {
+		numKeys++
+	}
+	if numKeys != 1 {
+		t.Fatalf("Undo database should hold 1 key but holds %d", numKeys)
+	}
+}
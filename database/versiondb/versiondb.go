@@ -11,6 +11,7 @@ import (
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/database/nodb"
+	"github.com/ava-labs/gecko/ids"
 )
 
 // Database implements the Database interface by living on top of another
@@ -20,6 +21,9 @@ type Database struct {
 	lock sync.RWMutex
 	mem  map[string]valueDelete
 	db   database.Database
+
+	// checkpoints the underlying database can be rolled back to, if not nil
+	checkpoints *Checkpoints
 }
 
 type valueDelete struct {
@@ -179,6 +183,15 @@ func (db *Database) GetDatabase() database.Database {
 	return db.db
 }
 
+// SetCheckpoints records how to undo the commits of this database in
+// [checkpoints], so that the underlying database can be rolled back
+func (db *Database) SetCheckpoints(checkpoints *Checkpoints) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.checkpoints = checkpoints
+}
+
 // Commit writes all the operations of this database to the underlying database
 func (db *Database) Commit() error {
 	db.lock.Lock()
@@ -188,7 +201,13 @@ func (db *Database) Commit() error {
 		return database.ErrClosed
 	}
 	if len(db.mem) == 0 {
-		return nil
+		return db.committed()
+	}
+
+	if db.checkpoints != nil {
+		if err := db.checkpoints.record(db.db, db.mem); err != nil {
+			return err
+		}
 	}
 
 	batch := db.db.NewBatch()
@@ -206,7 +225,32 @@ func (db *Database) Commit() error {
 	}
 
 	db.mem = make(map[string]valueDelete, memdb.DefaultSize)
-	return nil
+	return db.committed()
+}
+
+func (db *Database) committed() error {
+	if db.checkpoints == nil {
+		return nil
+	}
+	return db.checkpoints.committed()
+}
+
+// Rollback discards the operations of this database that haven't been
+// committed and sets the underlying database back to its state at the
+// checkpoint [checkpointID]
+func (db *Database) Rollback(checkpointID ids.ID) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.mem == nil {
+		return database.ErrClosed
+	}
+	if db.checkpoints == nil {
+		return errUnknownCheckpoint
+	}
+
+	db.mem = make(map[string]valueDelete, memdb.DefaultSize)
+	return db.checkpoints.rollback(db.db, checkpointID)
 }
 
 // Close implements the database.Database interface
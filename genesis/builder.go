@@ -0,0 +1,176 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package genesis
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/vms/avm"
+	"github.com/ava-labs/gecko/vms/platformvm"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+var (
+	errNoValidators       = errors.New("genesis must have at least one validator")
+	errNoAVMAllocations   = errors.New("an AVM without genesis data needs at least one allocation of $AVA on the AVM")
+	errEmptyAllocation    = errors.New("allocation doesn't give any $AVA")
+	errDuplicateValidator = errors.New("duplicate validator")
+)
+
+// Spec is a declarative description of the genesis state of a network. Build
+// turns it into the genesis bytes of the Platform Chain.
+// [NetworkID] is the ID of the network.
+// [StartTime] is the Platform Chain's time at network genesis, in Unix time.
+// It's also when the genesis validators start validating.
+// [Allocations] are the $AVA held at genesis.
+// [Validators] are the validators of the default subnet at genesis.
+// [Chains] are the chains that exist at genesis.
+type Spec struct {
+	NetworkID   uint32       `json:"networkID"`
+	StartTime   uint64       `json:"startTime"`
+	Allocations []Allocation `json:"allocations"`
+	Validators  []Validator  `json:"validators"`
+	Chains      []Chain      `json:"chains"`
+}
+
+// Allocation gives [Address] [PlatformAmount] $nAva on the Platform Chain and
+// [AVMAmount] $nAva on every AVM whose genesis data is built from the
+// allocations
+type Allocation struct {
+	Address        ids.ShortID `json:"address"`
+	PlatformAmount uint64      `json:"platformAmount"`
+	AVMAmount      uint64      `json:"avmAmount"`
+}
+
+// Validator validates the default subnet from the network's start time until
+// [EndTime], with weight [Weight]. When it stops validating, its stake is
+// returned to [Destination].
+type Validator struct {
+	NodeID      ids.ShortID `json:"nodeID"`
+	Weight      uint64      `json:"weight"`
+	EndTime     uint64      `json:"endTime"`
+	Destination ids.ShortID `json:"destination"`
+}
+
+// Chain is created at genesis.
+// [Name] is a human-readable, non-unique name for the chain.
+// [VMID] is the ID of the VM this chain runs.
+// [FxIDs] are the IDs of the Fxs the chain supports.
+// [GenesisData] is the initial state of the chain. If it's empty and the
+// chain runs the AVM, it's built to create the $AVA asset, held according to
+// the allocations.
+type Chain struct {
+	Name        string          `json:"name"`
+	VMID        ids.ID          `json:"vmID"`
+	FxIDs       []ids.ID        `json:"fxIDs"`
+	GenesisData formatting.CB58 `json:"genesisData"`
+}
+
+// Build returns the genesis bytes of the Platform Chain described by [spec]
+func Build(spec *Spec) ([]byte, error) {
+	if len(spec.Validators) == 0 {
+		return nil, errNoValidators
+	}
+
+	args := platformvm.BuildGenesisArgs{
+		NetworkID: cjson.Uint32(spec.NetworkID),
+		Time:      cjson.Uint64(spec.StartTime),
+	}
+	for _, allocation := range spec.Allocations {
+		if allocation.PlatformAmount == 0 && allocation.AVMAmount == 0 {
+			return nil, fmt.Errorf("%w: %s", errEmptyAllocation, allocation.Address)
+		}
+		if allocation.PlatformAmount == 0 {
+			continue
+		}
+		args.Accounts = append(args.Accounts, platformvm.APIAccount{
+			Address: allocation.Address,
+			Balance: cjson.Uint64(allocation.PlatformAmount),
+		})
+	}
+
+	nodeIDs := ids.ShortSet{}
+	for _, validator := range spec.Validators {
+		if nodeIDs.Contains(validator.NodeID) {
+			return nil, fmt.Errorf("%w: %s", errDuplicateValidator, validator.NodeID)
+		}
+		nodeIDs.Add(validator.NodeID)
+
+		weight := cjson.Uint64(validator.Weight)
+		args.Validators = append(args.Validators, platformvm.APIDefaultSubnetValidator{
+			APIValidator: platformvm.APIValidator{
+				StartTime: cjson.Uint64(spec.StartTime),
+				EndTime:   cjson.Uint64(validator.EndTime),
+				Weight:    &weight,
+				ID:        validator.NodeID,
+			},
+			Destination: validator.Destination,
+		})
+	}
+
+	for _, chain := range spec.Chains {
+		genesisData := chain.GenesisData
+		if len(genesisData.Bytes) == 0 && chain.VMID.Equals(avm.ID) {
+			bytes, err := avaGenesis(spec.Allocations)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't build the genesis data of chain %q: %w", chain.Name, err)
+			}
+			genesisData.Bytes = bytes
+		}
+		args.Chains = append(args.Chains, platformvm.APIChain{
+			GenesisData: genesisData,
+			VMID:        chain.VMID,
+			FxIDs:       chain.FxIDs,
+			Name:        chain.Name,
+		})
+	}
+
+	reply := platformvm.BuildGenesisReply{}
+	ss := platformvm.StaticService{}
+	if err := ss.BuildGenesis(nil, &args, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Bytes.Bytes, nil
+}
+
+// avaGenesis returns the genesis data of an AVM that creates the $AVA asset,
+// with the $AVA on the AVM given by [allocations]
+func avaGenesis(allocations []Allocation) ([]byte, error) {
+	holders := []interface{}(nil)
+	for _, allocation := range allocations {
+		if allocation.AVMAmount == 0 {
+			continue
+		}
+		holders = append(holders, avm.Holder{
+			Amount:  cjson.Uint64(allocation.AVMAmount),
+			Address: formatting.CB58{Bytes: allocation.Address.Bytes()}.String(),
+		})
+	}
+	if len(holders) == 0 {
+		return nil, errNoAVMAllocations
+	}
+
+	args := avm.BuildGenesisArgs{
+		GenesisData: map[string]avm.AssetDefinition{
+			"AVA": avm.AssetDefinition{
+				Name:         "AVA",
+				Symbol:       "AVA",
+				Denomination: 9,
+				InitialState: map[string][]interface{}{
+					"fixedCap": holders,
+				},
+			},
+		},
+	}
+	reply := avm.BuildGenesisReply{}
+	ss := avm.StaticService{}
+	if err := ss.BuildGenesis(nil, &args, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Bytes.Bytes, nil
+}
@@ -0,0 +1,99 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package genesis
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/vms/avm"
+	"github.com/ava-labs/gecko/vms/platformvm"
+)
+
+func TestFiveNodePresetIsLocalGenesis(t *testing.T) {
+	spec, err := Preset(FiveNodePreset, LocalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesisBytes, err := Build(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(genesisBytes, Genesis(LocalID)) {
+		t.Fatalf("the five node preset should build the local network's genesis")
+	}
+}
+
+func TestBuildPreset(t *testing.T) {
+	networkID := uint32(4321)
+	spec, err := Preset(SingleNodePreset, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesisBytes, err := Build(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesis := platformvm.Genesis{}
+	if err := platformvm.Codec.Unmarshal(genesisBytes, &genesis); err != nil {
+		t.Fatal(err)
+	}
+	if err := genesis.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if numValidators := genesis.Validators.Len(); numValidators != 1 {
+		t.Fatalf("should have 1 validator but has %d", numValidators)
+	}
+	if numChains := len(genesis.Chains); numChains != 5 {
+		t.Fatalf("should create 5 chains but creates %d", numChains)
+	}
+	for _, chain := range genesis.Chains {
+		if chain.NetworkID != networkID {
+			t.Fatalf("chain %q is created on network %d", chain.ChainName, chain.NetworkID)
+		}
+	}
+	if !genesis.Chains[0].VMID.Equals(avm.ID) || len(genesis.Chains[0].GenesisData) == 0 {
+		t.Fatalf("the AVM's genesis data should have been built from the allocations")
+	}
+
+	if _, err := Preset("unknown", networkID); err == nil {
+		t.Fatalf("should have errored due to the unknown preset")
+	}
+}
+
+func TestBuildInvalidSpec(t *testing.T) {
+	spec, err := Preset(SingleNodePreset, LocalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noValidators := *spec
+	noValidators.Validators = nil
+	if _, err := Build(&noValidators); err != errNoValidators {
+		t.Fatalf("should have errored with %s but errored with %v", errNoValidators, err)
+	}
+
+	duplicateValidators := *spec
+	duplicateValidators.Validators = append(spec.Validators, spec.Validators...)
+	if _, err := Build(&duplicateValidators); !errors.Is(err, errDuplicateValidator) {
+		t.Fatalf("should have errored with %s but errored with %v", errDuplicateValidator, err)
+	}
+
+	noAVA := *spec
+	noAVA.Allocations = []Allocation{{
+		Address:        ParsedAddresses[0],
+		PlatformAmount: presetPlatformAmount,
+	}}
+	if _, err := Build(&noAVA); !errors.Is(err, errNoAVMAllocations) {
+		t.Fatalf("should have errored with %s but errored with %v", errNoAVMAllocations, err)
+	}
+
+	emptyAllocation := *spec
+	emptyAllocation.Allocations = []Allocation{{Address: ParsedAddresses[0]}}
+	if _, err := Build(&emptyAllocation); !errors.Is(err, errEmptyAllocation) {
+		t.Fatalf("should have errored with %s but errored with %v", errEmptyAllocation, err)
+	}
+}
@@ -6,19 +6,29 @@ package genesis
 // TODO: Move this to a separate repo and leave only a byte array
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
 	"github.com/ava-labs/gecko/vms/avm"
 	"github.com/ava-labs/gecko/vms/evm"
+	"github.com/ava-labs/gecko/vms/managedfx"
+	"github.com/ava-labs/gecko/vms/nftfx"
 	"github.com/ava-labs/gecko/vms/platformvm"
+	"github.com/ava-labs/gecko/vms/schnorrfx"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
 	"github.com/ava-labs/gecko/vms/spchainvm"
 	"github.com/ava-labs/gecko/vms/spdagvm"
 	"github.com/ava-labs/gecko/vms/timestampvm"
+	"github.com/ava-labs/gecko/vms/wasmvm"
 )
 
 // Note that since an AVA network has exactly one Platform Chain,
@@ -73,8 +83,37 @@ var (
 		"P7oB2McjBGgW2NXXWVYjV8JEDFoW9xDE5",
 	}
 	ParsedStakerIDs = []ids.ShortID{}
+
+	// The networks this node can run. Networks other than the hardcoded ones
+	// are added with Register.
+	// Key: The ID of a network
+	networks = map[uint32]Network{
+		LocalID: Network{
+			Genesis: localGenesis(),
+			AVMFees: avm.FeeConfig{
+				TxFee:        1000000,
+				ByteFee:      1000,
+				OperationFee: 10000,
+			},
+		},
+	}
+)
+
+var (
+	errNoGenesis = errors.New("network has no genesis")
 )
 
+// Network defines the parameters of a network
+type Network struct {
+	// Genesis is the genesis data of the network's Platform Chain
+	Genesis []byte
+
+	// AVMFees are the fees that the AVM charges, in $nAva, from the time the
+	// schedule starts. The asset that fees are paid in is ignored: fees are
+	// paid in the network's $AVA.
+	AVMFees avm.FeeConfig
+}
+
 func init() {
 	for _, addrStr := range Addresses {
 		addr, err := ids.ShortFromString(addrStr)
@@ -135,6 +174,7 @@ func Aliases(networkID uint32) (generalAliases map[string][]string, chainAliases
 		"vm/" + spdagvm.ID.String():     []string{"vm/spdag"},
 		"vm/" + spchainvm.ID.String():   []string{"vm/spchain"},
 		"vm/" + timestampvm.ID.String(): []string{"vm/timestamp"},
+		"vm/" + wasmvm.ID.String():      []string{"vm/wasm"},
 		"bc/" + ids.Empty.String():      []string{"P", "platform", "bc/P", "bc/platform"},
 	}
 	chainAliases = map[[32]byte][]string{
@@ -147,6 +187,7 @@ func Aliases(networkID uint32) (generalAliases map[string][]string, chainAliases
 		spdagvm.ID.Key():     []string{"spdag"},
 		spchainvm.ID.Key():   []string{"spchain"},
 		timestampvm.ID.Key(): []string{"timestamp"},
+		wasmvm.ID.Key():      []string{"wasm"},
 	}
 
 	genesisBytes := Genesis(networkID)
@@ -171,20 +212,48 @@ func Aliases(networkID uint32) (generalAliases map[string][]string, chainAliases
 		case timestampvm.ID.Equals(chain.VMID):
 			generalAliases["bc/"+chain.ID().String()] = []string{"bc/timestamp"}
 			chainAliases[chain.ID().Key()] = []string{"timestamp"}
+		case wasmvm.ID.Equals(chain.VMID):
+			generalAliases["bc/"+chain.ID().String()] = []string{"bc/wasm"}
+			chainAliases[chain.ID().Key()] = []string{"wasm"}
 		}
 	}
 	return
 }
 
+// Register the network [networkID], replacing its parameters if it's already
+// registered. It isn't safe to call Register concurrently with the other
+// functions in this package, so networks should be registered before the node
+// starts.
+func Register(networkID uint32, network Network) error {
+	if len(network.Genesis) == 0 {
+		return fmt.Errorf("%w: %s", errNoGenesis, NetworkName(networkID))
+	}
+	networks[networkID] = network
+	return nil
+}
+
+// Lookup returns the parameters of the network [networkID], and true if it's
+// registered
+func Lookup(networkID uint32) (Network, bool) {
+	network, exists := networks[networkID]
+	return network, exists
+}
+
 // Genesis returns the genesis data of the Platform Chain.
 // Since the Platform Chain causes the creation of all other
 // chains, this function returns the genesis data of the entire network.
 // The ID of the new network is [networkID].
 func Genesis(networkID uint32) []byte {
-	if networkID != LocalID {
+	network, exists := networks[networkID]
+	if !exists {
 		panic("unknown network ID provided")
 	}
+	return network.Genesis
+}
 
+// localGenesis returns the genesis data of the local network, which is built
+// from the five node preset
+func localGenesis() []byte {
 	return []byte{
 		0x00, 0x00, 0x00, 0x01, 0x3c, 0xb7, 0xd3, 0x84,
 		0x2e, 0x8c, 0xee, 0x6a, 0x0e, 0xbd, 0x09, 0xf1,
@@ -286,15 +355,31 @@ func Genesis(networkID uint32) []byte {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x05, 0x00, 0x00, 0x30, 0x39, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03,
 		0x41, 0x56, 0x4d, 0x61, 0x76, 0x6d, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x73,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x73,
 		0x65, 0x63, 0x70, 0x32, 0x35, 0x36, 0x6b, 0x31,
 		0x66, 0x78, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x6e,
+		0x66, 0x74, 0x66, 0x78, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x6d,
+		0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x66, 0x78,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x73,
+		0x63, 0x68, 0x6e, 0x6f, 0x72, 0x72, 0x66, 0x78,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x7c, 0x00, 0x00, 0x00, 0x01, 0x00,
 		0x03, 0x41, 0x56, 0x41, 0x00, 0x00, 0x00, 0x00,
@@ -320,62 +405,67 @@ func Genesis(networkID uint32) []byte {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x30, 0x39, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0x41, 0x74,
-		0x68, 0x65, 0x72, 0x65, 0x75, 0x6d, 0x65, 0x76,
-		0x6d, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x02, 0xc9, 0x7b, 0x22,
-		0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x3a,
-		0x7b, 0x22, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49,
-		0x64, 0x22, 0x3a, 0x34, 0x33, 0x31, 0x31, 0x30,
-		0x2c, 0x22, 0x68, 0x6f, 0x6d, 0x65, 0x73, 0x74,
-		0x65, 0x61, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
-		0x22, 0x3a, 0x30, 0x2c, 0x22, 0x64, 0x61, 0x6f,
-		0x46, 0x6f, 0x72, 0x6b, 0x42, 0x6c, 0x6f, 0x63,
-		0x6b, 0x22, 0x3a, 0x30, 0x2c, 0x22, 0x64, 0x61,
-		0x6f, 0x46, 0x6f, 0x72, 0x6b, 0x53, 0x75, 0x70,
-		0x70, 0x6f, 0x72, 0x74, 0x22, 0x3a, 0x74, 0x72,
-		0x75, 0x65, 0x2c, 0x22, 0x65, 0x69, 0x70, 0x31,
-		0x35, 0x30, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22,
-		0x3a, 0x30, 0x2c, 0x22, 0x65, 0x69, 0x70, 0x31,
-		0x35, 0x30, 0x48, 0x61, 0x73, 0x68, 0x22, 0x3a,
-		0x22, 0x30, 0x78, 0x32, 0x30, 0x38, 0x36, 0x37,
-		0x39, 0x39, 0x61, 0x65, 0x65, 0x62, 0x65, 0x61,
-		0x65, 0x31, 0x33, 0x35, 0x63, 0x32, 0x34, 0x36,
-		0x63, 0x36, 0x35, 0x30, 0x32, 0x31, 0x63, 0x38,
-		0x32, 0x62, 0x34, 0x65, 0x31, 0x35, 0x61, 0x32,
-		0x63, 0x34, 0x35, 0x31, 0x33, 0x34, 0x30, 0x39,
-		0x39, 0x33, 0x61, 0x61, 0x63, 0x66, 0x64, 0x32,
-		0x37, 0x35, 0x31, 0x38, 0x38, 0x36, 0x35, 0x31,
-		0x34, 0x66, 0x30, 0x22, 0x2c, 0x22, 0x65, 0x69,
-		0x70, 0x31, 0x35, 0x35, 0x42, 0x6c, 0x6f, 0x63,
-		0x6b, 0x22, 0x3a, 0x30, 0x2c, 0x22, 0x65, 0x69,
-		0x70, 0x31, 0x35, 0x38, 0x42, 0x6c, 0x6f, 0x63,
-		0x6b, 0x22, 0x3a, 0x30, 0x2c, 0x22, 0x62, 0x79,
-		0x7a, 0x61, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x42,
-		0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x3a, 0x30, 0x2c,
-		0x22, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x61, 0x6e,
-		0x74, 0x69, 0x6e, 0x6f, 0x70, 0x6c, 0x65, 0x42,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x30, 0x39,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x08, 0x41, 0x74, 0x68, 0x65, 0x72, 0x65,
+		0x75, 0x6d, 0x65, 0x76, 0x6d, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x02, 0xc9, 0x7b, 0x22, 0x63, 0x6f, 0x6e, 0x66,
+		0x69, 0x67, 0x22, 0x3a, 0x7b, 0x22, 0x63, 0x68,
+		0x61, 0x69, 0x6e, 0x49, 0x64, 0x22, 0x3a, 0x34,
+		0x33, 0x31, 0x31, 0x30, 0x2c, 0x22, 0x68, 0x6f,
+		0x6d, 0x65, 0x73, 0x74, 0x65, 0x61, 0x64, 0x42,
 		0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x3a, 0x30, 0x2c,
-		0x22, 0x70, 0x65, 0x74, 0x65, 0x72, 0x73, 0x62,
-		0x75, 0x72, 0x67, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
-		0x22, 0x3a, 0x30, 0x7d, 0x2c, 0x22, 0x6e, 0x6f,
-		0x6e, 0x63, 0x65, 0x22, 0x3a, 0x22, 0x30, 0x78,
-		0x30, 0x22, 0x2c, 0x22, 0x74, 0x69, 0x6d, 0x65,
-		0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x3a, 0x22,
-		0x30, 0x78, 0x30, 0x22, 0x2c, 0x22, 0x65, 0x78,
-		0x74, 0x72, 0x61, 0x44, 0x61, 0x74, 0x61, 0x22,
-		0x3a, 0x22, 0x30, 0x78, 0x30, 0x30, 0x22, 0x2c,
-		0x22, 0x67, 0x61, 0x73, 0x4c, 0x69, 0x6d, 0x69,
-		0x74, 0x22, 0x3a, 0x22, 0x30, 0x78, 0x35, 0x66,
-		0x35, 0x65, 0x31, 0x30, 0x30, 0x22, 0x2c, 0x22,
-		0x64, 0x69, 0x66, 0x66, 0x69, 0x63, 0x75, 0x6c,
-		0x74, 0x79, 0x22, 0x3a, 0x22, 0x30, 0x78, 0x30,
-		0x22, 0x2c, 0x22, 0x6d, 0x69, 0x78, 0x48, 0x61,
-		0x73, 0x68, 0x22, 0x3a, 0x22, 0x30, 0x78, 0x30,
+		0x22, 0x64, 0x61, 0x6f, 0x46, 0x6f, 0x72, 0x6b,
+		0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x3a, 0x30,
+		0x2c, 0x22, 0x64, 0x61, 0x6f, 0x46, 0x6f, 0x72,
+		0x6b, 0x53, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74,
+		0x22, 0x3a, 0x74, 0x72, 0x75, 0x65, 0x2c, 0x22,
+		0x65, 0x69, 0x70, 0x31, 0x35, 0x30, 0x42, 0x6c,
+		0x6f, 0x63, 0x6b, 0x22, 0x3a, 0x30, 0x2c, 0x22,
+		0x65, 0x69, 0x70, 0x31, 0x35, 0x30, 0x48, 0x61,
+		0x73, 0x68, 0x22, 0x3a, 0x22, 0x30, 0x78, 0x32,
+		0x30, 0x38, 0x36, 0x37, 0x39, 0x39, 0x61, 0x65,
+		0x65, 0x62, 0x65, 0x61, 0x65, 0x31, 0x33, 0x35,
+		0x63, 0x32, 0x34, 0x36, 0x63, 0x36, 0x35, 0x30,
+		0x32, 0x31, 0x63, 0x38, 0x32, 0x62, 0x34, 0x65,
+		0x31, 0x35, 0x61, 0x32, 0x63, 0x34, 0x35, 0x31,
+		0x33, 0x34, 0x30, 0x39, 0x39, 0x33, 0x61, 0x61,
+		0x63, 0x66, 0x64, 0x32, 0x37, 0x35, 0x31, 0x38,
+		0x38, 0x36, 0x35, 0x31, 0x34, 0x66, 0x30, 0x22,
+		0x2c, 0x22, 0x65, 0x69, 0x70, 0x31, 0x35, 0x35,
+		0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x3a, 0x30,
+		0x2c, 0x22, 0x65, 0x69, 0x70, 0x31, 0x35, 0x38,
+		0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x3a, 0x30,
+		0x2c, 0x22, 0x62, 0x79, 0x7a, 0x61, 0x6e, 0x74,
+		0x69, 0x75, 0x6d, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+		0x22, 0x3a, 0x30, 0x2c, 0x22, 0x63, 0x6f, 0x6e,
+		0x73, 0x74, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x6f,
+		0x70, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+		0x22, 0x3a, 0x30, 0x2c, 0x22, 0x70, 0x65, 0x74,
+		0x65, 0x72, 0x73, 0x62, 0x75, 0x72, 0x67, 0x42,
+		0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x3a, 0x30, 0x7d,
+		0x2c, 0x22, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x22,
+		0x3a, 0x22, 0x30, 0x78, 0x30, 0x22, 0x2c, 0x22,
+		0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+		0x70, 0x22, 0x3a, 0x22, 0x30, 0x78, 0x30, 0x22,
+		0x2c, 0x22, 0x65, 0x78, 0x74, 0x72, 0x61, 0x44,
+		0x61, 0x74, 0x61, 0x22, 0x3a, 0x22, 0x30, 0x78,
+		0x30, 0x30, 0x22, 0x2c, 0x22, 0x67, 0x61, 0x73,
+		0x4c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x3a, 0x22,
+		0x30, 0x78, 0x35, 0x66, 0x35, 0x65, 0x31, 0x30,
+		0x30, 0x22, 0x2c, 0x22, 0x64, 0x69, 0x66, 0x66,
+		0x69, 0x63, 0x75, 0x6c, 0x74, 0x79, 0x22, 0x3a,
+		0x22, 0x30, 0x78, 0x30, 0x22, 0x2c, 0x22, 0x6d,
+		0x69, 0x78, 0x48, 0x61, 0x73, 0x68, 0x22, 0x3a,
+		0x22, 0x30, 0x78, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
@@ -383,32 +473,32 @@ func Genesis(networkID uint32) []byte {
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
-		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x22,
-		0x2c, 0x22, 0x63, 0x6f, 0x69, 0x6e, 0x62, 0x61,
-		0x73, 0x65, 0x22, 0x3a, 0x22, 0x30, 0x78, 0x30,
+		0x30, 0x30, 0x30, 0x22, 0x2c, 0x22, 0x63, 0x6f,
+		0x69, 0x6e, 0x62, 0x61, 0x73, 0x65, 0x22, 0x3a,
+		0x22, 0x30, 0x78, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
-		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x22,
-		0x2c, 0x22, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x22,
-		0x3a, 0x7b, 0x22, 0x37, 0x35, 0x31, 0x61, 0x30,
-		0x62, 0x39, 0x36, 0x65, 0x31, 0x30, 0x34, 0x32,
-		0x62, 0x65, 0x65, 0x37, 0x38, 0x39, 0x34, 0x35,
-		0x32, 0x65, 0x63, 0x62, 0x32, 0x30, 0x32, 0x35,
-		0x33, 0x66, 0x62, 0x61, 0x34, 0x30, 0x64, 0x62,
-		0x65, 0x38, 0x35, 0x22, 0x3a, 0x7b, 0x22, 0x62,
-		0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x3a,
-		0x22, 0x30, 0x78, 0x33, 0x33, 0x62, 0x32, 0x65,
-		0x33, 0x63, 0x39, 0x66, 0x64, 0x30, 0x38, 0x30,
-		0x34, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
-		0x30, 0x30, 0x22, 0x7d, 0x7d, 0x2c, 0x22, 0x6e,
-		0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x3a, 0x22,
-		0x30, 0x78, 0x30, 0x22, 0x2c, 0x22, 0x67, 0x61,
-		0x73, 0x55, 0x73, 0x65, 0x64, 0x22, 0x3a, 0x22,
-		0x30, 0x78, 0x30, 0x22, 0x2c, 0x22, 0x70, 0x61,
-		0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68,
-		0x22, 0x3a, 0x22, 0x30, 0x78, 0x30, 0x30, 0x30,
+		0x30, 0x30, 0x30, 0x22, 0x2c, 0x22, 0x61, 0x6c,
+		0x6c, 0x6f, 0x63, 0x22, 0x3a, 0x7b, 0x22, 0x37,
+		0x35, 0x31, 0x61, 0x30, 0x62, 0x39, 0x36, 0x65,
+		0x31, 0x30, 0x34, 0x32, 0x62, 0x65, 0x65, 0x37,
+		0x38, 0x39, 0x34, 0x35, 0x32, 0x65, 0x63, 0x62,
+		0x32, 0x30, 0x32, 0x35, 0x33, 0x66, 0x62, 0x61,
+		0x34, 0x30, 0x64, 0x62, 0x65, 0x38, 0x35, 0x22,
+		0x3a, 0x7b, 0x22, 0x62, 0x61, 0x6c, 0x61, 0x6e,
+		0x63, 0x65, 0x22, 0x3a, 0x22, 0x30, 0x78, 0x33,
+		0x33, 0x62, 0x32, 0x65, 0x33, 0x63, 0x39, 0x66,
+		0x64, 0x30, 0x38, 0x30, 0x34, 0x30, 0x30, 0x30,
+		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x22, 0x7d,
+		0x7d, 0x2c, 0x22, 0x6e, 0x75, 0x6d, 0x62, 0x65,
+		0x72, 0x22, 0x3a, 0x22, 0x30, 0x78, 0x30, 0x22,
+		0x2c, 0x22, 0x67, 0x61, 0x73, 0x55, 0x73, 0x65,
+		0x64, 0x22, 0x3a, 0x22, 0x30, 0x78, 0x30, 0x22,
+		0x2c, 0x22, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74,
+		0x48, 0x61, 0x73, 0x68, 0x22, 0x3a, 0x22, 0x30,
+		0x78, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
@@ -416,7 +506,7 @@ func Genesis(networkID uint32) []byte {
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
 		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30,
-		0x30, 0x30, 0x30, 0x30, 0x30, 0x22, 0x7d, 0x00,
+		0x30, 0x22, 0x7d, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
@@ -426,6 +516,10 @@ func Genesis(networkID uint32) []byte {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x30, 0x39, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x13, 0x53, 0x69,
 		0x6d, 0x70, 0x6c, 0x65, 0x20, 0x44, 0x41, 0x47,
 		0x20, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
@@ -454,21 +548,26 @@ func Genesis(networkID uint32) []byte {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x30, 0x39, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x15,
-		0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x20, 0x43,
-		0x68, 0x61, 0x69, 0x6e, 0x20, 0x50, 0x61, 0x79,
-		0x6d, 0x65, 0x6e, 0x74, 0x73, 0x73, 0x70, 0x63,
-		0x68, 0x61, 0x69, 0x6e, 0x76, 0x6d, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x30, 0x39, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x28, 0x00, 0x00, 0x00,
-		0x01, 0x3c, 0xb7, 0xd3, 0x84, 0x2e, 0x8c, 0xee,
-		0x6a, 0x0e, 0xbd, 0x09, 0xf1, 0xfe, 0x88, 0x4f,
-		0x68, 0x61, 0xe1, 0xb2, 0x9c, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x12,
-		0x30, 0x9c, 0xe5, 0x40, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x15, 0x53, 0x69, 0x6d, 0x70,
+		0x6c, 0x65, 0x20, 0x43, 0x68, 0x61, 0x69, 0x6e,
+		0x20, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
+		0x73, 0x73, 0x70, 0x63, 0x68, 0x61, 0x69, 0x6e,
+		0x76, 0x6d, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x28, 0x00, 0x00, 0x00, 0x01, 0x3c, 0xb7, 0xd3,
+		0x84, 0x2e, 0x8c, 0xee, 0x6a, 0x0e, 0xbd, 0x09,
+		0xf1, 0xfe, 0x88, 0x4f, 0x68, 0x61, 0xe1, 0xb2,
+		0x9c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x12, 0x30, 0x9c, 0xe5, 0x40,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
@@ -478,6 +577,10 @@ func Genesis(networkID uint32) []byte {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x30, 0x39, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x17, 0x53, 0x69, 0x6d, 0x70,
 		0x6c, 0x65, 0x20, 0x54, 0x69, 0x6d, 0x65, 0x73,
 		0x74, 0x61, 0x6d, 0x70, 0x20, 0x53, 0x65, 0x72,
@@ -495,7 +598,7 @@ func Genesis(networkID uint32) []byte {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x5d, 0xbb, 0x75, 0x80,
+		0x00, 0x00, 0x00, 0x00, 0x5d, 0xbb, 0x75, 0x80,
 	}
 }
 
@@ -504,6 +607,7 @@ func VMGenesis(networkID uint32, vmID ids.ID) *platformvm.CreateChainTx {
 	genesisBytes := Genesis(networkID)
 	genesis := platformvm.Genesis{}
 	platformvm.Codec.Unmarshal(genesisBytes, &genesis)
+	genesis.Initialize()
 	for _, chain := range genesis.Chains {
 		if chain.VMID.Equals(vmID) {
 			return chain
@@ -511,3 +615,66 @@ func VMGenesis(networkID uint32, vmID ids.ID) *platformvm.CreateChainTx {
 	}
 	return nil
 }
+
+// AVAAssetID returns the ID of the $AVA asset, as created by the genesis of the
+// AVM on the network [networkID]
+func AVAAssetID(networkID uint32) (ids.ID, error) {
+	chain := VMGenesis(networkID, avm.ID)
+	if chain == nil {
+		return ids.ID{}, errors.New("genesis doesn't create an AVM")
+	}
+
+	fxs := make([]*common.Fx, len(chain.FxIDs))
+	for i, fxID := range chain.FxIDs {
+		switch {
+		case fxID.Equals(secp256k1fx.ID):
+			fxs[i] = &common.Fx{ID: fxID, Fx: &secp256k1fx.Fx{}}
+		case fxID.Equals(nftfx.ID):
+			fxs[i] = &common.Fx{ID: fxID, Fx: &nftfx.Fx{}}
+		case fxID.Equals(managedfx.ID):
+			fxs[i] = &common.Fx{ID: fxID, Fx: &managedfx.Fx{}}
+		case fxID.Equals(schnorrfx.ID):
+			fxs[i] = &common.Fx{ID: fxID, Fx: &schnorrfx.Fx{}}
+		default:
+			return ids.ID{}, fmt.Errorf("unknown Fx %s", fxID)
+		}
+	}
+
+	vm := avm.VM{}
+	err := vm.Initialize(
+		&snow.Context{
+			NetworkID: networkID,
+			ChainID:   chain.ID(),
+			Log:       logging.NoLog{},
+		},
+		memdb.New(),
+		chain.GenesisData,
+		make(chan common.Message, 1),
+		fxs,
+	)
+	if err != nil {
+		return ids.ID{}, err
+	}
+	defer vm.Shutdown()
+
+	return vm.Lookup("AVA")
+}
+
+// AVMFees returns the fee schedule of the AVM on the network [networkID]. Fees
+// are paid in $AVA.
+func AVMFees(networkID uint32) (avm.FeeConfig, error) {
+	network, exists := networks[networkID]
+	if !exists {
+		return avm.FeeConfig{}, nil
+	}
+	fees := network.AVMFees
+	if fees.TxFee == 0 && fees.ByteFee == 0 && fees.OperationFee == 0 {
+		return avm.FeeConfig{}, nil
+	}
+	avaAssetID, err := AVAAssetID(networkID)
+	if err != nil {
+		return avm.FeeConfig{}, err
+	}
+	fees.AssetID = avaAssetID
+	return fees, nil
+}
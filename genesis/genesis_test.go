@@ -4,7 +4,10 @@
 package genesis
 
 import (
+	"bytes"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/gecko/vms/avm"
 	"github.com/ava-labs/gecko/vms/evm"
@@ -112,3 +115,72 @@ func TestGenesis(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestAVMFees(t *testing.T) {
+	fees, err := AVMFees(LocalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	avaAssetID, err := AVAAssetID(LocalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fees.AssetID.Equals(avaAssetID) {
+		t.Fatalf("fees should be paid in %s but are paid in %s", avaAssetID, fees.AssetID)
+	}
+	if !fees.Enabled(time.Now()) {
+		t.Fatalf("the local network should charge fees")
+	}
+
+	if fees, err := AVMFees(LocalID + 1); err != nil {
+		t.Fatal(err)
+	} else if fees.Enabled(time.Now()) {
+		t.Fatalf("an unknown network shouldn't charge fees")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	networkID := uint32(4321)
+	defer delete(networks, networkID)
+
+	if _, registered := Lookup(networkID); registered {
+		t.Fatalf("network %d shouldn't be registered yet", networkID)
+	}
+	if err := Register(networkID, Network{}); !errors.Is(err, errNoGenesis) {
+		t.Fatalf("should have errored with %s but errored with %v", errNoGenesis, err)
+	}
+
+	spec, err := Preset(SingleNodePreset, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesisBytes, err := Build(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Register(networkID, Network{
+		Genesis: genesisBytes,
+		AVMFees: avm.FeeConfig{TxFee: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(Genesis(networkID), genesisBytes) {
+		t.Fatalf("the registered network has the wrong genesis")
+	}
+
+	fees, err := AVMFees(networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	avaAssetID, err := AVAAssetID(networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fees.AssetID.Equals(avaAssetID) {
+		t.Fatalf("fees should be paid in %s but are paid in %s", avaAssetID, fees.AssetID)
+	}
+	if fees.TxFee != 5 {
+		t.Fatalf("the transaction fee should be 5 but is %d", fees.TxFee)
+	}
+}
@@ -0,0 +1,140 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package genesis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/vms/avm"
+	"github.com/ava-labs/gecko/vms/evm"
+	"github.com/ava-labs/gecko/vms/managedfx"
+	"github.com/ava-labs/gecko/vms/nftfx"
+	"github.com/ava-labs/gecko/vms/schnorrfx"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+	"github.com/ava-labs/gecko/vms/spchainvm"
+	"github.com/ava-labs/gecko/vms/spdagvm"
+	"github.com/ava-labs/gecko/vms/timestampvm"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// Names of the built-in genesis presets
+const (
+	// A network validated by one node, run with the staking key in keys/keys1
+	SingleNodePreset = "single-node"
+
+	// A network validated by five nodes, run with the staking keys in
+	// keys/keys1 through keys/keys5. On the local network, this is the
+	// network's genesis.
+	FiveNodePreset = "five-node"
+)
+
+// Parameters shared by the presets
+const (
+	presetStartTime uint64 = 1572566400 // 11/01/2019 @ 12:00am (UTC)
+	presetEndTime   uint64 = 1604102400 // 10/31/2020 @ 12:00am (UTC)
+
+	presetPlatformAmount uint64 = 20 * 1000 * 1000 * 1000 * 1000
+	presetAVMAmount      uint64 = 45 * 1000 * 1000 * 1000 * 1000 * 1000
+	presetWeight         uint64 = 20 * 1000 * 1000 * 1000 * 1000
+
+	// The EVM's genesis funds the Ethereum address of the key in [Keys]
+	presetEVMGenesis = `{"config":{"chainId":43110,"homesteadBlock":0,"daoForkBlock":0,"daoForkSupport":true,"eip150Block":0,"eip150Hash":"0x2086799aeebeae135c246c65021c82b4e15a2c451340993aacfd2751886514f0","eip155Block":0,"eip158Block":0,"byzantiumBlock":0,"constantinopleBlock":0,"petersburgBlock":0},"nonce":"0x0","timestamp":"0x0","extraData":"0x00","gasLimit":"0x5f5e100","difficulty":"0x0","mixHash":"0x0000000000000000000000000000000000000000000000000000000000000000","coinbase":"0x0000000000000000000000000000000000000000","alloc":{"751a0b96e1042bee789452ecb20253fba40dbe85":{"balance":"0x33b2e3c9fd0804000000000"}},"number":"0x0","gasUsed":"0x0","parentHash":"0x0000000000000000000000000000000000000000000000000000000000000000"}`
+)
+
+// presets maps the name of each preset to the number of stakers, from
+// [ParsedStakerIDs], that validate it
+var presets = map[string]int{
+	SingleNodePreset: 1,
+	FiveNodePreset:   5,
+}
+
+// PresetNames returns the names of the built-in presets, sorted
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preset returns the spec of the built-in preset named [name], for the
+// network [networkID]. The key in [Keys] holds all of the network's $AVA and
+// the balances of the payment VMs.
+func Preset(name string, networkID uint32) (*Spec, error) {
+	numStakers, exists := presets[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown genesis preset %q", name)
+	}
+
+	addr := ParsedAddresses[0]
+	spec := &Spec{
+		NetworkID: networkID,
+		StartTime: presetStartTime,
+		Allocations: []Allocation{{
+			Address:        addr,
+			PlatformAmount: presetPlatformAmount,
+			AVMAmount:      presetAVMAmount,
+		}},
+	}
+	for _, stakerID := range ParsedStakerIDs[:numStakers] {
+		spec.Validators = append(spec.Validators, Validator{
+			NodeID:      stakerID,
+			Weight:      presetWeight,
+			EndTime:     presetEndTime,
+			Destination: addr,
+		})
+	}
+
+	spdagGenesis := spdagvm.BuildGenesisReply{}
+	spdagArgs := spdagvm.BuildGenesisArgs{Outputs: []spdagvm.APIOutput{{
+		Amount:    cjson.Uint64(presetPlatformAmount),
+		Threshold: 1,
+		Addresses: []ids.ShortID{addr},
+	}}}
+	if err := (&spdagvm.StaticService{}).BuildGenesis(nil, &spdagArgs, &spdagGenesis); err != nil {
+		return nil, err
+	}
+
+	spchainGenesis := spchainvm.BuildGenesisReply{}
+	spchainArgs := spchainvm.BuildGenesisArgs{Accounts: []spchainvm.APIAccount{{
+		Address: addr,
+		Balance: cjson.Uint64(presetPlatformAmount),
+	}}}
+	if err := (&spchainvm.StaticService{}).BuildGenesis(nil, &spchainArgs, &spchainGenesis); err != nil {
+		return nil, err
+	}
+
+	spec.Chains = []Chain{
+		{
+			Name:  "AVM",
+			VMID:  avm.ID,
+			FxIDs: []ids.ID{secp256k1fx.ID, nftfx.ID, managedfx.ID, schnorrfx.ID},
+		},
+		{
+			Name:        "Athereum",
+			VMID:        evm.ID,
+			GenesisData: formatting.CB58{Bytes: []byte(presetEVMGenesis)},
+		},
+		{
+			Name:        "Simple DAG Payments",
+			VMID:        spdagvm.ID,
+			GenesisData: spdagGenesis.Bytes,
+		},
+		{
+			Name:        "Simple Chain Payments",
+			VMID:        spchainvm.ID,
+			GenesisData: spchainGenesis.Bytes,
+		},
+		{
+			Name: "Simple Timestamp Server",
+			VMID: timestampvm.ID,
+		},
+	}
+	return spec, nil
+}
@@ -5,24 +5,32 @@ package ids
 
 import (
 	"fmt"
+	"sync"
 )
 
 // Aliaser allows one to give an ID aliases and lookup the aliases given to an
 // ID. An ID can have arbitrarily many aliases; two IDs may not have the same
-// alias.
+// alias. An Aliaser is safe for concurrent use.
 type Aliaser struct {
+	lock    sync.RWMutex
 	dealias map[string]ID
 	aliases map[[32]byte][]string
 }
 
 // Initialize the aliaser to have no aliases
 func (a *Aliaser) Initialize() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
 	a.dealias = make(map[string]ID)
 	a.aliases = make(map[[32]byte][]string)
 }
 
 // Lookup returns the ID associated with alias
 func (a *Aliaser) Lookup(alias string) (ID, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
 	if ID, ok := a.dealias[alias]; ok {
 		return ID, nil
 	}
@@ -30,10 +38,18 @@ func (a *Aliaser) Lookup(alias string) (ID, error) {
 }
 
 // Aliases returns the aliases of an ID
-func (a Aliaser) Aliases(id ID) []string { return a.aliases[id.Key()] }
+func (a *Aliaser) Aliases(id ID) []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return append([]string(nil), a.aliases[id.Key()]...)
+}
 
 // PrimaryAlias returns the first alias of [id]
-func (a Aliaser) PrimaryAlias(id ID) (string, error) {
+func (a *Aliaser) PrimaryAlias(id ID) (string, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
 	aliases, exists := a.aliases[id.Key()]
 	if !exists || len(aliases) == 0 {
 		return "", fmt.Errorf("there is no alias for ID %s", id)
@@ -42,7 +58,10 @@ func (a Aliaser) PrimaryAlias(id ID) (string, error) {
 }
 
 // Alias gives [id] the alias [alias]
-func (a Aliaser) Alias(id ID, alias string) error {
+func (a *Aliaser) Alias(id ID, alias string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
 	if _, exists := a.dealias[alias]; exists {
 		return fmt.Errorf("%s is already used as an alias for an ID", alias)
 	}
@@ -52,3 +71,30 @@ func (a Aliaser) Alias(id ID, alias string) error {
 	a.aliases[key] = append(a.aliases[key], alias)
 	return nil
 }
+
+// RemoveAlias removes [alias] as an alias of the ID it refers to
+func (a *Aliaser) RemoveAlias(alias string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	id, exists := a.dealias[alias]
+	if !exists {
+		return fmt.Errorf("there is no ID with alias %s", alias)
+	}
+	delete(a.dealias, alias)
+
+	key := id.Key()
+	aliases := a.aliases[key]
+	remaining := make([]string, 0, len(aliases)-1)
+	for _, existing := range aliases {
+		if existing != alias {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(a.aliases, key)
+	} else {
+		a.aliases[key] = remaining
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"testing"
+)
+
+func TestAliaserRemoveAlias(t *testing.T) {
+	id := NewID([32]byte{1})
+
+	a := Aliaser{}
+	a.Initialize()
+	if err := a.Alias(id, "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Alias(id, "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.RemoveAlias("first"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Lookup("first"); err == nil {
+		t.Fatalf("Alias should have been removed")
+	}
+	if aliases := a.Aliases(id); len(aliases) != 1 || aliases[0] != "second" {
+		t.Fatalf("Wrong aliases: %v", aliases)
+	}
+	if err := a.RemoveAlias("first"); err == nil {
+		t.Fatalf("Should have failed to remove an unknown alias")
+	}
+
+	// The alias can be reused
+	if err := a.Alias(NewID([32]byte{2}), "first"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAliaserConcurrentAccess(t *testing.T) {
+	a := Aliaser{}
+	a.Initialize()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			id := NewID([32]byte{byte(i)})
+			if err := a.Alias(id, id.String()); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		id := NewID([32]byte{byte(i)})
+		_, _ = a.Lookup(id.String())
+		_, _ = a.PrimaryAlias(id)
+	}
+	<-done
+}
@@ -12,17 +12,37 @@ import (
 //
 // A bag has the ability to split and filter on it's bits for ease of use for
 // binary voting.
+//
+// The threshold set isn't maintained as IDs are added, because most bags are
+// never asked for it. It is built by Threshold instead.
+//
+// A bag keeps its counts in a map, unless it was created by NewSortedBag, in
+// which case it keeps them in a slice sorted by ID.
 type Bag struct {
 	counts map[[32]byte]int
 	size   int
 
+	// If sorted is true, the counts are kept in entries rather than counts
+	sorted  bool
+	entries []bagEntry
+
 	mode     ID
 	modeFreq int
 
-	threshold    int
-	metThreshold Set
+	threshold int
 }
 
+// NewBag returns an empty bag with room for [size] distinct IDs
+func NewBag(size int) Bag { return Bag{counts: make(map[[32]byte]int, size)} }
+
+// NewSortedBag returns an empty bag with room for [size] distinct IDs, which
+// keeps its counts in a slice sorted by ID rather than in a map. It allocates
+// less than a bag made by NewBag when it holds few distinct IDs, such as the
+// votes of a poll, and its IDs are iterated over in order. Finding an ID's
+// count takes time logarithmic in the number of distinct IDs, and adding one
+// takes linear time, so it shouldn't be used for large bags.
+func NewSortedBag(size int) Bag { return Bag{sorted: true, entries: make([]bagEntry, 0, size)} }
+
 func (b *Bag) init() {
 	if b.counts == nil {
 		b.counts = make(map[[32]byte]int)
@@ -31,19 +51,7 @@ func (b *Bag) init() {
 
 // SetThreshold sets the number of times an ID must be added to be contained in
 // the threshold set.
-func (b *Bag) SetThreshold(threshold int) {
-	if b.threshold == threshold {
-		return
-	}
-
-	b.threshold = threshold
-	b.metThreshold.Clear()
-	for vote, count := range b.counts {
-		if count >= threshold {
-			b.metThreshold.Add(NewID(vote))
-		}
-	}
-}
+func (b *Bag) SetThreshold(threshold int) { b.threshold = threshold }
 
 // Add increases the number of times each id has been seen by one.
 func (b *Bag) Add(ids ...ID) {
@@ -56,49 +64,103 @@ func (b *Bag) Add(ids ...ID) {
 //
 // count must be >= 1
 func (b *Bag) AddCount(id ID, count int) {
-	b.init()
-
-	totalCount := b.counts[*id.ID] + count
-	b.counts[*id.ID] = totalCount
+	totalCount := 0
+	if b.sorted {
+		totalCount = b.addSorted(id, count)
+	} else {
+		b.init()
+		totalCount = b.counts[*id.ID] + count
+		b.counts[*id.ID] = totalCount
+	}
 	b.size += count
 
 	if totalCount > b.modeFreq {
 		b.mode = id
 		b.modeFreq = totalCount
 	}
-	if totalCount >= b.threshold {
-		b.metThreshold.Add(id)
-	}
 }
 
 // Count returns the number of times the id has been added.
-func (b *Bag) Count(id ID) int { return b.counts[*id.ID] }
+func (b *Bag) Count(id ID) int {
+	if b.sorted {
+		if i, found := b.search(id); found {
+			return b.entries[i].count
+		}
+		return 0
+	}
+	return b.counts[*id.ID]
+}
 
 // Len returns the number of times an id has been added.
 func (b *Bag) Len() int { return b.size }
 
 // List returns a list of all ids that have been added.
 func (b *Bag) List() []ID {
-	idList := []ID(nil)
+	if b.sorted {
+		if len(b.entries) == 0 {
+			return nil
+		}
+		idList := make([]ID, len(b.entries))
+		for i, entry := range b.entries {
+			idList[i] = entry.id
+		}
+		return idList
+	}
+	if len(b.counts) == 0 {
+		return nil
+	}
+	idList := make([]ID, 0, len(b.counts))
 	for id := range b.counts {
 		idList = append(idList, NewID(id))
 	}
 	return idList
 }
 
+// ForEach calls [f] with the key and count of each id that has been added. It
+// doesn't allocate the ids, so it's cheaper than List when only their keys are
+// needed.
+func (b *Bag) ForEach(f func(key [32]byte, count int)) {
+	if b.sorted {
+		for _, entry := range b.entries {
+			f(*entry.id.ID, entry.count)
+		}
+		return
+	}
+	for key, count := range b.counts {
+		f(key, count)
+	}
+}
+
 // Mode returns the id that has been seen the most and the number of times it
 // has been seen. Ties are broken by the first id to be seen the reported number
 // of times.
 func (b *Bag) Mode() (ID, int) { return b.mode, b.modeFreq }
 
 // Threshold returns the ids that have been seen at least threshold times.
-func (b *Bag) Threshold() Set { return b.metThreshold }
+func (b *Bag) Threshold() Set {
+	metThreshold := Set{}
+	b.ForEach(func(vote [32]byte, count int) {
+		if count >= b.threshold {
+			metThreshold[vote] = true
+		}
+	})
+	return metThreshold
+}
 
 // Filter returns the bag of ids with the same counts as this bag, except all
 // the ids in the returned bag must have the same bits in the range [start, end]
 // as id.
 func (b *Bag) Filter(start, end int, id ID) Bag {
-	newBag := Bag{}
+	if b.sorted {
+		newBag := NewSortedBag(len(b.entries))
+		for _, entry := range b.entries {
+			if EqualSubset(start, end, id, entry.id) {
+				newBag.AddCount(entry.id, entry.count)
+			}
+		}
+		return newBag
+	}
+	newBag := NewBag(len(b.counts))
 	for vote, count := range b.counts {
 		voteID := NewID(vote)
 		if EqualSubset(start, end, id, voteID) {
@@ -112,7 +174,15 @@ func (b *Bag) Filter(start, end int, id ID) Bag {
 // in the 0th index have a 0 at bit [index], and all ids in the 1st index have a
 // 1 at bit [index].
 func (b *Bag) Split(index uint) [2]Bag {
-	splitVotes := [2]Bag{}
+	// Votes are usually split roughly evenly
+	if b.sorted {
+		splitVotes := [2]Bag{NewSortedBag(len(b.entries) / 2), NewSortedBag(len(b.entries) / 2)}
+		for _, entry := range b.entries {
+			splitVotes[entry.id.Bit(index)].AddCount(entry.id, entry.count)
+		}
+		return splitVotes
+	}
+	splitVotes := [2]Bag{NewBag(len(b.counts) / 2), NewBag(len(b.counts) / 2)}
 	for vote, count := range b.counts {
 		voteID := NewID(vote)
 		bit := voteID.Bit(index)
@@ -125,10 +195,10 @@ func (b *Bag) String() string {
 	sb := strings.Builder{}
 
 	sb.WriteString(fmt.Sprintf("Bag: (Size = %d)", b.Len()))
-	for idBytes, count := range b.counts {
+	b.ForEach(func(idBytes [32]byte, count int) {
 		id := NewID(idBytes)
 		sb.WriteString(fmt.Sprintf("\n    ID[%s]: Count = %d", id, count))
-	}
+	})
 
 	return sb.String()
 }
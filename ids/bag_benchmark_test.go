@@ -0,0 +1,215 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"testing"
+)
+
+// benchmarkVotes returns the votes of a poll of [k] validators, split between
+// [numChoices] choices
+func benchmarkVotes(k, numChoices int) []ID {
+	votes := make([]ID, k)
+	for i := range votes {
+		votes[i] = Empty.Prefix(uint64(i % numChoices))
+	}
+	return votes
+}
+
+// BenchmarkBagAdd benchmarks adding the votes of a poll to a bag
+func BenchmarkBagAdd(b *testing.B) {
+	votes := benchmarkVotes(20, 2)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bag := Bag{}
+		bag.Add(votes...)
+	}
+}
+
+// BenchmarkBagThreshold benchmarks finding the votes that met alpha
+func BenchmarkBagThreshold(b *testing.B) {
+	bag := Bag{}
+	bag.Add(benchmarkVotes(20, 2)...)
+	bag.SetThreshold(15)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bag.Threshold()
+	}
+}
+
+// BenchmarkBagFilter benchmarks filtering the votes of a poll, as is done at
+// each node of a snowball tree
+func BenchmarkBagFilter(b *testing.B) {
+	bag := Bag{}
+	bag.Add(benchmarkVotes(20, 4)...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bag.Filter(0, NumBits, Empty)
+	}
+}
+
+// BenchmarkBagSplit benchmarks splitting the votes of a poll on a bit
+func BenchmarkBagSplit(b *testing.B) {
+	bag := Bag{}
+	bag.Add(benchmarkVotes(20, 4)...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bag.Split(0)
+	}
+}
+
+// BenchmarkUniqueBagBag benchmarks counting the votes of an avalanche poll
+func BenchmarkUniqueBagBag(b *testing.B) {
+	ub := UniqueBag{}
+	for i, vote := range benchmarkVotes(20, 4) {
+		ub.Add(uint(i), vote)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		ub.Bag(15)
+	}
+}
+
+// BenchmarkUniqueBagSortedBag benchmarks counting the votes of an avalanche
+// poll in a sorted bag
+func BenchmarkUniqueBagSortedBag(b *testing.B) {
+	ub := UniqueBag{}
+	for i, vote := range benchmarkVotes(20, 4) {
+		ub.Add(uint(i), vote)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		ub.SortedBag(15)
+	}
+}
+
+// BenchmarkShortSetAdd benchmarks building the set of sampled validators
+func BenchmarkShortSetAdd(b *testing.B) {
+	vdrs := make([]ShortID, 20)
+	for i := range vdrs {
+		vdrs[i] = NewShortID([20]byte{byte(i)})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		vdrSet := NewShortSet(len(vdrs))
+		for _, vdr := range vdrs {
+			vdrSet.Add(vdr)
+		}
+	}
+}
+
+// BenchmarkSortedBagAdd benchmarks adding the votes of a poll to a sorted bag
+func BenchmarkSortedBagAdd(b *testing.B) {
+	votes := benchmarkVotes(20, 2)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bag := NewSortedBag(2)
+		bag.Add(votes...)
+	}
+}
+
+// BenchmarkSortedBagThreshold benchmarks finding the votes that met alpha in a
+// sorted bag
+func BenchmarkSortedBagThreshold(b *testing.B) {
+	bag := NewSortedBag(2)
+	bag.Add(benchmarkVotes(20, 2)...)
+	bag.SetThreshold(15)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bag.Threshold()
+	}
+}
+
+// BenchmarkSortedBagFilter benchmarks filtering the votes of a poll in a
+// sorted bag
+func BenchmarkSortedBagFilter(b *testing.B) {
+	bag := NewSortedBag(4)
+	bag.Add(benchmarkVotes(20, 4)...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bag.Filter(0, NumBits, Empty)
+	}
+}
+
+// BenchmarkSortedBagSplit benchmarks splitting the votes of a poll in a sorted
+// bag on a bit
+func BenchmarkSortedBagSplit(b *testing.B) {
+	bag := NewSortedBag(4)
+	bag.Add(benchmarkVotes(20, 4)...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bag.Split(0)
+	}
+}
+
+// benchmarkIDs returns [size] distinct IDs, in no particular order
+func benchmarkIDs(size int) []ID {
+	idList := make([]ID, size)
+	for i := range idList {
+		idList[i] = NewID([32]byte{byte(i * 7), byte(i >> 8)})
+	}
+	return idList
+}
+
+// BenchmarkSetUnion benchmarks merging the IDs of two sets
+func BenchmarkSetUnion(b *testing.B) {
+	idList := benchmarkIDs(1000)
+	set1, set2 := NewSet(500), NewSet(500)
+	set1.Add(idList[:600]...)
+	set2.Add(idList[400:]...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		union := NewSet(set1.Len())
+		union.Union(set1)
+		union.Union(set2)
+	}
+}
+
+// BenchmarkSortedSetUnion benchmarks merging the IDs of two sorted sets
+func BenchmarkSortedSetUnion(b *testing.B) {
+	idList := benchmarkIDs(1000)
+	set1, set2 := NewSortedSet(500), NewSortedSet(500)
+	set1.Add(idList[:600]...)
+	set2.Add(idList[400:]...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		union := SortedSet(set1.List())
+		union.Union(set2)
+	}
+}
+
+// BenchmarkSetAdd benchmarks adding a batch of IDs to a set
+func BenchmarkSetAdd(b *testing.B) {
+	idList := benchmarkIDs(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		set := NewSet(len(idList))
+		set.Add(idList...)
+	}
+}
+
+// BenchmarkSortedSetAdd benchmarks adding a batch of IDs to a sorted set
+func BenchmarkSortedSetAdd(b *testing.B) {
+	idList := benchmarkIDs(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		set := NewSortedSet(len(idList))
+		set.Add(idList...)
+	}
+}
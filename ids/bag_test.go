@@ -132,6 +132,25 @@ func TestBagSetThreshold(t *testing.T) {
 	}
 }
 
+func TestBagForEach(t *testing.T) {
+	id0 := Empty.Prefix(0)
+	id1 := Empty.Prefix(1)
+
+	bag := Bag{}
+	bag.AddCount(id0, 2)
+	bag.AddCount(id1, 3)
+
+	counts := map[[32]byte]int{}
+	bag.ForEach(func(key [32]byte, count int) { counts[key] = count })
+	if len(counts) != 2 {
+		t.Fatalf("Bag.ForEach visited %d ids expected %d", len(counts), 2)
+	} else if count := counts[id0.Key()]; count != 2 {
+		t.Fatalf("Bag.ForEach gave count %d expected %d", count, 2)
+	} else if count := counts[id1.Key()]; count != 3 {
+		t.Fatalf("Bag.ForEach gave count %d expected %d", count, 3)
+	}
+}
+
 func TestBagFilter(t *testing.T) {
 	id0 := Empty
 	id1 := NewID([32]byte{1})
@@ -10,6 +10,9 @@ import (
 // Set is a set of IDs
 type Set map[[32]byte]bool
 
+// NewSet returns an empty set with room for [size] IDs
+func NewSet(size int) Set { return make(map[[32]byte]bool, size) }
+
 func (ids *Set) init(size int) {
 	if *ids == nil {
 		*ids = make(map[[32]byte]bool, size)
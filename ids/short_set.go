@@ -8,6 +8,9 @@ import "strings"
 // ShortSet is a set of ShortIDs
 type ShortSet map[[20]byte]bool
 
+// NewShortSet returns an empty set with room for [size] ShortIDs
+func NewShortSet(size int) ShortSet { return make(map[[20]byte]bool, size) }
+
 func (ids *ShortSet) init(size int) {
 	if *ids == nil {
 		*ids = make(map[[20]byte]bool, size)
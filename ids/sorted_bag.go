@@ -0,0 +1,60 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"bytes"
+	"sort"
+)
+
+// bagEntry is the number of times an ID was added to a sorted bag
+type bagEntry struct {
+	id    ID
+	count int
+}
+
+type sortBagEntries []bagEntry
+
+func (entries sortBagEntries) Less(i, j int) bool {
+	return bytes.Compare(entries[i].id.ID[:], entries[j].id.ID[:]) < 0
+}
+func (entries sortBagEntries) Len() int      { return len(entries) }
+func (entries sortBagEntries) Swap(i, j int) { entries[j], entries[i] = entries[i], entries[j] }
+
+// search returns the index of [id] in the entries of this sorted bag, and
+// whether it's there. If it isn't, the index is where it would be inserted.
+func (b *Bag) search(id ID) (int, bool) {
+	i := sort.Search(len(b.entries), func(i int) bool {
+		return bytes.Compare(b.entries[i].id.ID[:], id.ID[:]) >= 0
+	})
+	return i, i < len(b.entries) && *b.entries[i].id.ID == *id.ID
+}
+
+// addSorted increases the count of [id] in this sorted bag by [count], and
+// returns its new count
+func (b *Bag) addSorted(id ID, count int) int {
+	i, found := b.search(id)
+	if found {
+		b.entries[i].count += count
+		return b.entries[i].count
+	}
+	b.entries = append(b.entries, bagEntry{})
+	copy(b.entries[i+1:], b.entries[i:])
+	b.entries[i] = bagEntry{id: id, count: count}
+	return count
+}
+
+// sortEntries sorts the entries of this sorted bag, which were appended
+// without being sorted, and counts them. Assumes the bag was empty before they
+// were appended, and that the entries have distinct IDs.
+func (b *Bag) sortEntries() {
+	sort.Sort(sortBagEntries(b.entries))
+	for _, entry := range b.entries {
+		b.size += entry.count
+		if entry.count > b.modeFreq {
+			b.mode = entry.id
+			b.modeFreq = entry.count
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"testing"
+)
+
+// TestSortedBag checks that a sorted bag counts the same as a bag
+func TestSortedBag(t *testing.T) {
+	id0 := Empty
+	id1 := NewID([32]byte{1})
+	id2 := NewID([32]byte{2})
+
+	bag := Bag{}
+	sorted := NewSortedBag(2)
+	for _, b := range []*Bag{&bag, &sorted} {
+		b.SetThreshold(2)
+		b.Add(id2, id0, id2)
+		b.AddCount(id1, 3)
+	}
+
+	for _, id := range []ID{id0, id1, id2, NewID([32]byte{3})} {
+		if bag.Count(id) != sorted.Count(id) {
+			t.Fatalf("Sorted bag counted %s %d times but expected %d", id, sorted.Count(id), bag.Count(id))
+		}
+	}
+	if mode, freq := sorted.Mode(); !mode.Equals(id1) || freq != 3 || sorted.Len() != 6 {
+		t.Fatalf("Sorted bag has mode %s seen %d times and size %d", mode, freq, sorted.Len())
+	}
+	if list := sorted.List(); len(list) != 3 || !list[0].Equals(id0) || !list[1].Equals(id1) || !list[2].Equals(id2) {
+		t.Fatalf("Sorted bag listed %v in the wrong order", list)
+	}
+	if !sorted.Threshold().Equals(bag.Threshold()) {
+		t.Fatalf("Sorted bag's threshold was %s but expected %s", sorted.Threshold(), bag.Threshold())
+	}
+
+	filtered := sorted.Filter(0, 1, id0)
+	if !filtered.sorted || filtered.Count(id0) != 1 || filtered.Count(id2) != 2 || filtered.Count(id1) != 0 {
+		t.Fatalf("Sorted bag was filtered to %s", &filtered)
+	}
+	split := sorted.Split(0)
+	if !split[1].sorted || split[1].Count(id1) != 3 || split[1].Len() != 3 || split[0].Len() != 3 {
+		t.Fatalf("Sorted bag was split into %s and %s", &split[0], &split[1])
+	}
+}
@@ -0,0 +1,212 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// SortedSet is a set of IDs kept in a slice sorted by ID. It allocates less
+// than a Set when it holds few IDs, or when IDs are added and removed in
+// batches, and its IDs are iterated over in order. Finding an ID takes time
+// logarithmic in the size of the set, and adding or removing a single ID takes
+// linear time.
+type SortedSet []ID
+
+// NewSortedSet returns an empty set with room for [size] IDs
+func NewSortedSet(size int) SortedSet { return make([]ID, 0, size) }
+
+// compareIDs returns an integer comparing [a] and [b] lexicographically
+func compareIDs(a, b ID) int { return bytes.Compare(a.ID[:], b.ID[:]) }
+
+// search returns the index of [id] in the set, and whether it's there. If it
+// isn't, the index is where it would be inserted.
+func (ids SortedSet) search(id ID) (int, bool) {
+	i := sort.Search(len(ids), func(i int) bool { return compareIDs(ids[i], id) >= 0 })
+	return i, i < len(ids) && *ids[i].ID == *id.ID
+}
+
+// Add all the ids to this set, if the id is already in the set, nothing happens.
+// The ids are sorted together, so adding many at once is cheaper than adding
+// them one at a time.
+func (ids *SortedSet) Add(idList ...ID) {
+	if len(idList) == 1 {
+		i, found := ids.search(idList[0])
+		if !found {
+			*ids = append(*ids, ID{})
+			copy((*ids)[i+1:], (*ids)[i:])
+			(*ids)[i] = idList[0]
+		}
+		return
+	}
+	added := make([]ID, len(idList))
+	copy(added, idList)
+	sort.Slice(added, func(i, j int) bool { return compareIDs(added[i], added[j]) < 0 })
+	ids.Union(compactIDs(added))
+}
+
+// Union adds all the ids from the provided set to this set
+func (ids *SortedSet) Union(set SortedSet) {
+	if len(set) == 0 {
+		return
+	}
+	union := make([]ID, 0, len(*ids)+len(set))
+	i, j := 0, 0
+	for i < len(*ids) && j < len(set) {
+		switch cmp := compareIDs((*ids)[i], set[j]); {
+		case cmp < 0:
+			union = append(union, (*ids)[i])
+			i++
+		case cmp > 0:
+			union = append(union, set[j])
+			j++
+		default:
+			union = append(union, (*ids)[i])
+			i++
+			j++
+		}
+	}
+	union = append(union, (*ids)[i:]...)
+	*ids = append(union, set[j:]...)
+}
+
+// Intersect removes the ids from this set that aren't in the provided set
+func (ids *SortedSet) Intersect(set SortedSet) {
+	intersection := (*ids)[:0]
+	i, j := 0, 0
+	for i < len(*ids) && j < len(set) {
+		switch cmp := compareIDs((*ids)[i], set[j]); {
+		case cmp < 0:
+			i++
+		case cmp > 0:
+			j++
+		default:
+			intersection = append(intersection, (*ids)[i])
+			i++
+			j++
+		}
+	}
+	*ids = intersection
+}
+
+// Difference removes the ids in the provided set from this set
+func (ids *SortedSet) Difference(set SortedSet) {
+	difference := (*ids)[:0]
+	i, j := 0, 0
+	for i < len(*ids) && j < len(set) {
+		switch cmp := compareIDs((*ids)[i], set[j]); {
+		case cmp < 0:
+			difference = append(difference, (*ids)[i])
+			i++
+		case cmp > 0:
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	*ids = append(difference, (*ids)[i:]...)
+}
+
+// Contains returns true if the set contains this id, false otherwise
+func (ids SortedSet) Contains(id ID) bool {
+	_, found := ids.search(id)
+	return found
+}
+
+// Overlaps returns true if the intersection of the set is non-empty
+func (ids SortedSet) Overlaps(set SortedSet) bool {
+	i, j := 0, 0
+	for i < len(ids) && j < len(set) {
+		switch cmp := compareIDs(ids[i], set[j]); {
+		case cmp < 0:
+			i++
+		case cmp > 0:
+			j++
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of ids in this set
+func (ids SortedSet) Len() int { return len(ids) }
+
+// Remove all the id from this set, if the id isn't in the set, nothing happens
+func (ids *SortedSet) Remove(idList ...ID) {
+	if len(idList) == 1 {
+		if i, found := ids.search(idList[0]); found {
+			*ids = append((*ids)[:i], (*ids)[i+1:]...)
+		}
+		return
+	}
+	removed := make([]ID, len(idList))
+	copy(removed, idList)
+	sort.Slice(removed, func(i, j int) bool { return compareIDs(removed[i], removed[j]) < 0 })
+	ids.Difference(compactIDs(removed))
+}
+
+// Clear empties this set
+func (ids *SortedSet) Clear() { *ids = (*ids)[:0] }
+
+// List converts this set into a sorted list
+func (ids SortedSet) List() []ID {
+	if len(ids) == 0 {
+		return nil
+	}
+	idList := make([]ID, len(ids))
+	copy(idList, ids)
+	return idList
+}
+
+// Set returns the ids in this set as a Set
+func (ids SortedSet) Set() Set {
+	set := NewSet(len(ids))
+	set.Add(ids...)
+	return set
+}
+
+// Equals returns true if the sets contain the same elements
+func (ids SortedSet) Equals(oIDs SortedSet) bool {
+	if len(ids) != len(oIDs) {
+		return false
+	}
+	for i, id := range ids {
+		if !id.Equals(oIDs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the string representation of a set
+func (ids SortedSet) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("{")
+	for i, id := range ids {
+		if i != 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(id.String())
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// compactIDs removes the duplicates from the sorted list [ids], in place
+func compactIDs(ids []ID) SortedSet {
+	if len(ids) == 0 {
+		return ids
+	}
+	compacted := ids[:1]
+	for _, id := range ids[1:] {
+		if !id.Equals(compacted[len(compacted)-1]) {
+			compacted = append(compacted, id)
+		}
+	}
+	return compacted
+}
@@ -0,0 +1,78 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"testing"
+)
+
+func TestSortedSet(t *testing.T) {
+	id1 := NewID([32]byte{1})
+	id2 := NewID([32]byte{2})
+
+	ids := SortedSet{}
+	ids.Add(id2)
+	ids.Add(id1)
+	ids.Add(id2)
+	if ids.Len() != 2 || !ids.Contains(id1) || !ids.Contains(id2) {
+		t.Fatalf("Values not added correctly: %s", ids)
+	} else if list := ids.List(); !list[0].Equals(id1) || !list[1].Equals(id2) {
+		t.Fatalf("Values not sorted: %s", ids)
+	}
+
+	ids.Remove(id1)
+	if ids.Contains(id1) || ids.Len() != 1 {
+		t.Fatalf("Value not removed correctly")
+	}
+
+	ids.Clear()
+	if ids.Contains(id2) || ids.Len() != 0 || ids.List() != nil {
+		t.Fatalf("Values not removed correctly")
+	}
+}
+
+func TestSortedSetBatch(t *testing.T) {
+	id1 := NewID([32]byte{1})
+	id2 := NewID([32]byte{2})
+	id3 := NewID([32]byte{3})
+	id4 := NewID([32]byte{4})
+
+	ids := NewSortedSet(4)
+	ids.Add(id3, id1, id3, id2)
+	expected := SortedSet{id1, id2, id3}
+	if !ids.Equals(expected) {
+		t.Fatalf("Added %s but expected %s", ids, expected)
+	}
+
+	other := SortedSet{id2, id4}
+	if !ids.Overlaps(other) || ids.Overlaps(SortedSet{id4}) {
+		t.Fatalf("Overlaps returned the wrong result")
+	}
+
+	union := SortedSet(ids.List())
+	union.Union(other)
+	if expected := (SortedSet{id1, id2, id3, id4}); !union.Equals(expected) {
+		t.Fatalf("Union was %s but expected %s", union, expected)
+	}
+
+	intersection := SortedSet(ids.List())
+	intersection.Intersect(other)
+	if expected := (SortedSet{id2}); !intersection.Equals(expected) {
+		t.Fatalf("Intersection was %s but expected %s", intersection, expected)
+	}
+
+	difference := SortedSet(ids.List())
+	difference.Difference(other)
+	if expected := (SortedSet{id1, id3}); !difference.Equals(expected) {
+		t.Fatalf("Difference was %s but expected %s", difference, expected)
+	}
+
+	ids.Remove(id3, id4, id1)
+	if expected := (SortedSet{id2}); !ids.Equals(expected) {
+		t.Fatalf("Removing left %s but expected %s", ids, expected)
+	}
+	if set := ids.Set(); set.Len() != 1 || !set.Contains(id2) {
+		t.Fatalf("Converted to %s but expected %s", set, ids)
+	}
+}
@@ -0,0 +1,212 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// SortedShortSet is a set of ShortIDs kept in a slice sorted by ID. It
+// allocates less than a ShortSet when it holds few IDs, or when IDs are added
+// and removed in batches, and its IDs are iterated over in order. Finding an ID
+// takes time logarithmic in the size of the set, and adding or removing a
+// single ID takes linear time.
+type SortedShortSet []ShortID
+
+// NewSortedShortSet returns an empty set with room for [size] ShortIDs
+func NewSortedShortSet(size int) SortedShortSet { return make([]ShortID, 0, size) }
+
+// compareShortIDs returns an integer comparing [a] and [b] lexicographically
+func compareShortIDs(a, b ShortID) int { return bytes.Compare(a.ID[:], b.ID[:]) }
+
+// search returns the index of [id] in the set, and whether it's there. If it
+// isn't, the index is where it would be inserted.
+func (ids SortedShortSet) search(id ShortID) (int, bool) {
+	i := sort.Search(len(ids), func(i int) bool { return compareShortIDs(ids[i], id) >= 0 })
+	return i, i < len(ids) && *ids[i].ID == *id.ID
+}
+
+// Add all the ids to this set, if the id is already in the set, nothing happens.
+// The ids are sorted together, so adding many at once is cheaper than adding
+// them one at a time.
+func (ids *SortedShortSet) Add(idList ...ShortID) {
+	if len(idList) == 1 {
+		i, found := ids.search(idList[0])
+		if !found {
+			*ids = append(*ids, ShortID{})
+			copy((*ids)[i+1:], (*ids)[i:])
+			(*ids)[i] = idList[0]
+		}
+		return
+	}
+	added := make([]ShortID, len(idList))
+	copy(added, idList)
+	sort.Slice(added, func(i, j int) bool { return compareShortIDs(added[i], added[j]) < 0 })
+	ids.Union(compactShortIDs(added))
+}
+
+// Union adds all the ids from the provided set to this set
+func (ids *SortedShortSet) Union(set SortedShortSet) {
+	if len(set) == 0 {
+		return
+	}
+	union := make([]ShortID, 0, len(*ids)+len(set))
+	i, j := 0, 0
+	for i < len(*ids) && j < len(set) {
+		switch cmp := compareShortIDs((*ids)[i], set[j]); {
+		case cmp < 0:
+			union = append(union, (*ids)[i])
+			i++
+		case cmp > 0:
+			union = append(union, set[j])
+			j++
+		default:
+			union = append(union, (*ids)[i])
+			i++
+			j++
+		}
+	}
+	union = append(union, (*ids)[i:]...)
+	*ids = append(union, set[j:]...)
+}
+
+// Intersect removes the ids from this set that aren't in the provided set
+func (ids *SortedShortSet) Intersect(set SortedShortSet) {
+	intersection := (*ids)[:0]
+	i, j := 0, 0
+	for i < len(*ids) && j < len(set) {
+		switch cmp := compareShortIDs((*ids)[i], set[j]); {
+		case cmp < 0:
+			i++
+		case cmp > 0:
+			j++
+		default:
+			intersection = append(intersection, (*ids)[i])
+			i++
+			j++
+		}
+	}
+	*ids = intersection
+}
+
+// Difference removes the ids in the provided set from this set
+func (ids *SortedShortSet) Difference(set SortedShortSet) {
+	difference := (*ids)[:0]
+	i, j := 0, 0
+	for i < len(*ids) && j < len(set) {
+		switch cmp := compareShortIDs((*ids)[i], set[j]); {
+		case cmp < 0:
+			difference = append(difference, (*ids)[i])
+			i++
+		case cmp > 0:
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	*ids = append(difference, (*ids)[i:]...)
+}
+
+// Contains returns true if the set contains this id, false otherwise
+func (ids SortedShortSet) Contains(id ShortID) bool {
+	_, found := ids.search(id)
+	return found
+}
+
+// Overlaps returns true if the intersection of the set is non-empty
+func (ids SortedShortSet) Overlaps(set SortedShortSet) bool {
+	i, j := 0, 0
+	for i < len(ids) && j < len(set) {
+		switch cmp := compareShortIDs(ids[i], set[j]); {
+		case cmp < 0:
+			i++
+		case cmp > 0:
+			j++
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of ids in this set
+func (ids SortedShortSet) Len() int { return len(ids) }
+
+// Remove all the id from this set, if the id isn't in the set, nothing happens
+func (ids *SortedShortSet) Remove(idList ...ShortID) {
+	if len(idList) == 1 {
+		if i, found := ids.search(idList[0]); found {
+			*ids = append((*ids)[:i], (*ids)[i+1:]...)
+		}
+		return
+	}
+	removed := make([]ShortID, len(idList))
+	copy(removed, idList)
+	sort.Slice(removed, func(i, j int) bool { return compareShortIDs(removed[i], removed[j]) < 0 })
+	ids.Difference(compactShortIDs(removed))
+}
+
+// Clear empties this set
+func (ids *SortedShortSet) Clear() { *ids = (*ids)[:0] }
+
+// List converts this set into a sorted list
+func (ids SortedShortSet) List() []ShortID {
+	if len(ids) == 0 {
+		return nil
+	}
+	idList := make([]ShortID, len(ids))
+	copy(idList, ids)
+	return idList
+}
+
+// Set returns the ids in this set as a ShortSet
+func (ids SortedShortSet) Set() ShortSet {
+	set := NewShortSet(len(ids))
+	set.Add(ids...)
+	return set
+}
+
+// Equals returns true if the sets contain the same elements
+func (ids SortedShortSet) Equals(oIDs SortedShortSet) bool {
+	if len(ids) != len(oIDs) {
+		return false
+	}
+	for i, id := range ids {
+		if !id.Equals(oIDs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the string representation of a set
+func (ids SortedShortSet) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("{")
+	for i, id := range ids {
+		if i != 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(id.String())
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// compactShortIDs removes the duplicates from the sorted list [ids], in place
+func compactShortIDs(ids []ShortID) SortedShortSet {
+	if len(ids) == 0 {
+		return ids
+	}
+	compacted := ids[:1]
+	for _, id := range ids[1:] {
+		if !id.Equals(compacted[len(compacted)-1]) {
+			compacted = append(compacted, id)
+		}
+	}
+	return compacted
+}
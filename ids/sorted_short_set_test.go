@@ -0,0 +1,38 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"testing"
+)
+
+func TestSortedShortSetBatch(t *testing.T) {
+	id1 := NewShortID([20]byte{1})
+	id2 := NewShortID([20]byte{2})
+	id3 := NewShortID([20]byte{3})
+
+	ids := NewSortedShortSet(3)
+	ids.Add(id3, id1, id3)
+	ids.Add(id2)
+	expected := SortedShortSet{id1, id2, id3}
+	if !ids.Equals(expected) {
+		t.Fatalf("Added %s but expected %s", ids, expected)
+	}
+
+	other := SortedShortSet{id2, id3}
+	difference := SortedShortSet(ids.List())
+	difference.Difference(other)
+	if expected := (SortedShortSet{id1}); !difference.Equals(expected) {
+		t.Fatalf("Difference was %s but expected %s", difference, expected)
+	}
+
+	ids.Intersect(other)
+	if !ids.Equals(other) {
+		t.Fatalf("Intersection was %s but expected %s", ids, other)
+	}
+	ids.Remove(id2)
+	if ids.Contains(id2) || !ids.Contains(id3) || ids.Set().Len() != 1 {
+		t.Fatalf("Value not removed correctly")
+	}
+}
@@ -73,7 +73,7 @@ func (b *UniqueBag) List() []ID {
 
 // Bag ...
 func (b *UniqueBag) Bag(alpha int) Bag {
-	bag := Bag{}
+	bag := NewBag(len(*b))
 	bag.SetThreshold(alpha)
 	for id, bs := range *b {
 		bag.AddCount(NewID(id), bs.Len())
@@ -81,6 +81,18 @@ func (b *UniqueBag) Bag(alpha int) Bag {
 	return bag
 }
 
+// SortedBag returns the same bag as Bag, made by NewSortedBag. The counts are
+// sorted once they've all been added, rather than as each is added.
+func (b *UniqueBag) SortedBag(alpha int) Bag {
+	bag := NewSortedBag(len(*b))
+	bag.SetThreshold(alpha)
+	for id, bs := range *b {
+		bag.entries = append(bag.entries, bagEntry{id: NewID(id), count: bs.Len()})
+	}
+	bag.sortEntries()
+	return bag
+}
+
 func (b *UniqueBag) String() string {
 	sb := strings.Builder{}
 
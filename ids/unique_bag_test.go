@@ -104,3 +104,25 @@ func TestUniqueBag(t *testing.T) {
 		t.Fatalf("Set of Unique Bag missing element")
 	}
 }
+
+func TestUniqueBagSortedBag(t *testing.T) {
+	id1 := NewID([32]byte{1})
+	id2 := NewID([32]byte{2})
+
+	ub := UniqueBag{}
+	ub.Add(0, id2)
+	ub.Add(1, id1)
+	ub.Add(2, id2)
+
+	bag := ub.Bag(2)
+	sorted := ub.SortedBag(2)
+	if !sorted.sorted || sorted.Len() != bag.Len() || sorted.Count(id1) != 1 || sorted.Count(id2) != 2 {
+		t.Fatalf("Sorted bag %s should have had the same counts as %s", &sorted, &bag)
+	}
+	if mode, freq := sorted.Mode(); !mode.Equals(id2) || freq != 2 {
+		t.Fatalf("Sorted bag has mode %s seen %d times but expected %s seen twice", mode, freq, id2)
+	}
+	if !sorted.Threshold().Equals(bag.Threshold()) {
+		t.Fatalf("Sorted bag's threshold was %s but expected %s", sorted.Threshold(), bag.Threshold())
+	}
+}
@@ -0,0 +1,48 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ava-labs/gecko/genesis"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+// buildGenesis prints the CB58 genesis bytes built from [BuildGenesis], and
+// returns the status to exit with
+func buildGenesis() int {
+	spec, err := loadGenesisSpec(BuildGenesis, Config.NetworkID)
+	if err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+	genesisBytes, err := genesis.Build(spec)
+	if err != nil {
+		fmt.Printf("couldn't build the genesis: %s\n", err)
+		return exitError
+	}
+	fmt.Println(formatting.CB58{Bytes: genesisBytes})
+	return exitClean
+}
+
+// loadGenesisSpec returns the genesis spec [source], which is either the name
+// of a preset, for the network [networkID], or a JSON spec file. A spec file
+// that doesn't give a network ID is for the network [networkID].
+func loadGenesisSpec(source string, networkID uint32) (*genesis.Spec, error) {
+	spec, err := genesis.Preset(source, networkID)
+	if err != nil {
+		specBytes, err := ioutil.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("%q isn't a genesis preset and couldn't be read: %w", source, err)
+		}
+		spec = &genesis.Spec{NetworkID: networkID}
+		if err := json.Unmarshal(specBytes, spec); err != nil {
+			return nil, fmt.Errorf("couldn't parse the genesis spec %s: %w", source, err)
+		}
+	}
+	return spec, nil
+}
@@ -5,7 +5,11 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
 	"path"
+	"syscall"
+	"time"
 
 	"github.com/ava-labs/gecko/node"
 	"github.com/ava-labs/gecko/utils/crypto"
@@ -13,13 +17,34 @@ import (
 	"github.com/ava-labs/go-ethereum/p2p/nat"
 )
 
+// Statuses the node exits with
+const (
+	// The node shut down cleanly
+	exitClean = 0
+
+	// The node couldn't start
+	exitError = 1
+
+	// The node was stopped before it shut down cleanly, because it took too
+	// long or it received a second signal to stop
+	exitForced = 2
+)
+
 // main is the primary entry point to Ava. This can either create a CLI to an
 //     existing node or create a new node.
-func main() {
+func main() { os.Exit(run()) }
+
+// run the node until it's signalled to stop, and return the status to exit
+// with
+func run() (status int) {
 	// Err is set based on the CLI arguments
 	if Err != nil {
 		fmt.Printf("parsing parameters returned with error %s\n", Err)
-		return
+		return exitError
+	}
+
+	if BuildGenesis != "" {
+		return buildGenesis()
 	}
 
 	config := Config.LoggingConfig
@@ -30,14 +55,20 @@ func main() {
 	log, err := factory.Make()
 	if err != nil {
 		fmt.Printf("starting logger failed with: %s\n", err)
-		return
+		return exitError
 	}
 	fmt.Println(gecko)
 
-	defer func() { recover() }()
+	defer func() {
+		if recover() != nil {
+			status = exitError
+		}
+	}()
 
 	defer log.Stop()
 	defer log.StopOnPanic()
+	// The database is closed when the node shuts down cleanly. Closing it
+	// again fails harmlessly.
 	defer Config.DB.Close()
 
 	// Track if sybil control is enforced
@@ -53,7 +84,42 @@ func main() {
 
 	if err := Config.ConsensusParams.Valid(); err != nil {
 		log.Fatal("consensus parameters are invalid: %s", err)
-		return
+		return exitError
+	}
+
+	if err := Config.ConnectionLimits.Valid(); err != nil {
+		log.Fatal("connection limits are invalid: %s", err)
+		return exitError
+	}
+
+	if err := Config.NetworkTimeout.Valid(); err != nil {
+		log.Fatal("network timeout parameters are invalid: %s", err)
+		return exitError
+	}
+
+	if err := Config.PeerTimeout.Valid(); err != nil {
+		log.Fatal("peer timeout parameters are invalid: %s", err)
+		return exitError
+	}
+
+	if err := Config.PeerListGossip.Valid(); err != nil {
+		log.Fatal("peer list gossip parameters are invalid: %s", err)
+		return exitError
+	}
+
+	if err := Config.ContainerGossip.Valid(); err != nil {
+		log.Fatal("container gossip parameters are invalid: %s", err)
+		return exitError
+	}
+
+	if err := Config.CaptureConfig.Valid(); err != nil {
+		log.Fatal("message capture parameters are invalid: %s", err)
+		return exitError
+	}
+
+	if err := Config.BenchlistConfig.Valid(); err != nil {
+		log.Fatal("benchlist parameters are invalid: %s", err)
+		return exitError
 	}
 
 	// Track if assertions should be executed
@@ -86,17 +152,44 @@ func main() {
 	// MainNode is a global variable in the node.go file
 	if err := node.MainNode.Initialize(&Config, log, factory); err != nil {
 		log.Fatal("error initializing node state: %s", err)
-		return
+		return exitError
 	}
 
 	log.Debug("Starting servers")
 	if err := node.MainNode.StartConsensusServer(); err != nil {
 		log.Fatal("problem starting servers: %s", err)
-		return
+		return exitError
 	}
 
-	defer node.MainNode.Shutdown()
-
 	log.Debug("Dispatching node handlers")
 	node.MainNode.Dispatch()
+
+	// The node stops dispatching once it receives SIGINT or SIGTERM. If it
+	// doesn't shut down in time, or it receives either again, it's stopped at
+	// once.
+	log.Info("shutting down. Send SIGINT or SIGTERM again to stop at once")
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Warn("received %s while shutting down. Stopping at once", sig)
+		case <-time.After(Config.ShutdownTimeout):
+			log.Warn("didn't shut down within %s. Stopping at once", Config.ShutdownTimeout)
+		case <-done:
+			return
+		}
+		factory.Flush()
+		os.Exit(exitForced)
+	}()
+
+	node.MainNode.Shutdown()
+	log.Info("closing the database")
+	if err := Config.DB.Close(); err != nil {
+		log.Error("couldn't close the database: %s", err)
+	}
+	close(done)
+	log.Info("shut down cleanly")
+	return exitClean
 }
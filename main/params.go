@@ -7,33 +7,67 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"math"
 	"net"
+	"os"
 	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ava-labs/go-ethereum/p2p/nat"
 
-	"github.com/ava-labs/gecko/database/leveldb"
+	"github.com/ava-labs/gecko/api"
+	"github.com/ava-labs/gecko/api/health"
+	"github.com/ava-labs/gecko/api/keystore"
+	"github.com/ava-labs/gecko/database/driver"
 	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/genesis"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/networking"
 	"github.com/ava-labs/gecko/node"
+	"github.com/ava-labs/gecko/snow/consensus/snowball"
 	"github.com/ava-labs/gecko/snow/networking/router"
+	"github.com/ava-labs/gecko/snow/upgrades"
 	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/config"
 	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/supervisor"
 	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/platformvm"
 )
 
 // Results of parsing the CLI
 var (
 	Config = node.Config{}
 	Err    error
+
+	// BuildGenesis is the genesis preset, or JSON spec file, to print the
+	// genesis bytes of instead of running a node
+	BuildGenesis string
+)
+
+const (
+	// Name of the flag that gives the config file
+	configFileFlag = "config-file"
+
+	// Prefix of the environment variables that set flags
+	envPrefix = "GECKO_"
 )
 
 var (
-	errBootstrapMismatch = errors.New("more bootstrap IDs provided than bootstrap IPs")
+	errBootstrapMismatch     = errors.New("more bootstrap IDs provided than bootstrap IPs")
+	errInvalidMaxMessageSize = fmt.Errorf("max message size must be in the range [%d, 2^32)", networking.MinMessageSize)
+	errInvalidReconnectDelay = fmt.Errorf("max reconnect delay must be at least %s", networking.InitialReconnectDelay)
+	errInvalidLatencyBias    = errors.New("latency sampling bias must be in the range [0, 1)")
+	errInvalidListenIP       = errors.New("the first staking listen address must be an IPv4 address")
+	errNoHTTPListener        = errors.New("the HTTP server must listen on TCP, a Unix socket or both")
+	errNoAuthPassword        = errors.New("a password must be given when API authorization is required or endpoints are protected")
+
+	errAuthProtectedAndRequired = errors.New("api-auth-protected-endpoints can't be given when api-auth-required is set, since every endpoint that isn't public is protected")
 )
 
 // Parse the CLI arguments
@@ -44,9 +78,22 @@ func init() {
 	loggingConfig, err := logging.DefaultConfig()
 	errs.Add(err)
 
+	// Config file:
+	flag.String(configFileFlag, "", "JSON, TOML or YAML file of options, by flag name, such as {\"http-port\": 9650} or {\"http\": {\"port\": 9650}}. Options given on the command line take precedence over environment variables, such as "+config.EnvName(envPrefix, "http-port")+"=9650, which take precedence over the file")
+
 	// NetworkID:
 	networkName := flag.String("network-id", genesis.LocalName, "Network ID this node will connect to")
 
+	// Genesis:
+	flag.StringVar(&BuildGenesis, "build-genesis", "", "Print the genesis bytes built from a preset for the network given by --network-id ("+strings.Join(genesis.PresetNames(), ", ")+") or from a JSON spec file, and exit")
+	genesisSource := flag.String("genesis", "", "Genesis preset ("+strings.Join(genesis.PresetNames(), ", ")+"), or JSON spec file, of the network given by --network-id. Required for networks other than "+genesis.LocalName)
+
+	// AVM fees:
+	avmTxFee := flag.Uint64("avm-tx-fee", 0, "Fee the AVM charges per transaction, in $nAva. Defaults to the network's fee")
+	avmByteFee := flag.Uint64("avm-byte-fee", 0, "Fee the AVM charges per byte of a transaction, in $nAva. Defaults to the network's fee")
+	avmOperationFee := flag.Uint64("avm-operation-fee", 0, "Fee the AVM charges per UTXO a transaction consumes or produces, in $nAva. Defaults to the network's fee")
+	avmFeeStartTime := flag.String("avm-fee-start-time", "", "RFC3339 time the AVM starts charging fees at. Defaults to the network's start time for fees")
+
 	// Ava fees:
 	flag.Uint64Var(&Config.AvaTxFee, "ava-tx-fee", 0, "Ava transaction fee, in $nAva")
 
@@ -59,22 +106,68 @@ func init() {
 	// Database:
 	db := flag.Bool("db-enabled", true, "Turn on persistent storage")
 	dbDir := flag.String("db-dir", "db", "Database directory for Ava state")
+	dbBackend := flag.String("db-backend", driver.LevelDB, fmt.Sprintf("Storage engine of the database when db-enabled is set, one of %v", driver.Names()))
+	flag.Uint64Var(&Config.DBQuotas.Default, "db-chain-quota", 0, "Number of bytes each chain may store in the database. If 0, chains are unlimited")
+	trackSubnets := flag.String("track-subnets", "", "Comma separated list of the IDs of the subnets whose chains this node runs, besides the default subnet's. If empty, it runs every subnet's chains")
+	dbChainQuotas := flag.String("db-chain-quotas", "", "Comma separated list of the number of bytes specific chains may store in the database, as chain=bytes where the chain is an ID or alias. Overrides db-chain-quota. Example: X=0,P=0")
+	flag.BoolVar(&Config.Pruning.Enabled, "pruning-enabled", false, "Delete the bytes of accepted containers from the database once they're deep and old enough. Peers can't bootstrap pruned containers from this node")
+	flag.Uint64Var(&Config.Pruning.Depth, "pruning-depth", 4096, "Number of containers a chain must accept after a container before it's pruned")
+	flag.DurationVar(&Config.Pruning.HistoryWindow, "pruning-history-window", 7*24*time.Hour, "How long after a container is accepted it's kept, so peers can bootstrap it from this node")
+
+	// VM Plugins:
+	flag.StringVar(&Config.PluginDir, "plugin-dir", "./build/plugins", "Directory of the VM plugins. Each plugin's file name is the ID of the VM it serves")
 
 	// IP:
 	consensusIP := flag.String("public-ip", "", "Public IP of this node")
 
 	// HTTP Server:
 	httpPort := flag.Uint("http-port", 9650, "Port of the HTTP server")
+	flag.BoolVar(&Config.HTTPTCPEnabled, "http-tcp-enabled", true, "If true, the HTTP server listens on http-port. If false, it's only reachable through http-socket-path")
+	flag.StringVar(&Config.HTTPSocketPath, "http-socket-path", "", "Path of a Unix domain socket the HTTP server also listens on, for services on this machine. Disabled if empty")
+	httpSocketMode := flag.String("http-socket-mode", "0660", "Permissions, in octal, of the HTTP server's Unix domain socket. Only users with permission to write to the socket may connect to it")
 	flag.BoolVar(&Config.EnableHTTPS, "http-tls-enabled", false, "Upgrade the HTTP server to HTTPs")
 	flag.StringVar(&Config.HTTPSKeyFile, "http-tls-key-file", "", "TLS private key file for the HTTPs server")
-	flag.StringVar(&Config.HTTPSCertFile, "http-tls-cert-file", "", "TLS certificate file for the HTTPs server")
+	flag.StringVar(&Config.HTTPSCertFile, "http-tls-cert-file", "", "TLS certificate file for the HTTPs server. The certificate and key are reloaded when either file changes or the configuration is reloaded")
+	allowedOrigins := flag.String("http-allowed-origins", "*", "Comma separated list of origins that may make cross-origin requests to the HTTP server. \"*\" allows every origin. Example: https://wallet.example.com,https://*.example.org")
+	allowedMethods := flag.String("http-allowed-methods", "", "Comma separated list of methods that cross-origin requests may use. Defaults to GET,POST,HEAD")
+	rateLimits := flag.String("http-rate-limits", "", "Comma separated list of limits on how often each IP may make requests to an API endpoint, of the form endpoint=rate:burst or endpoint#method=rate:burst, where rate is requests per second and burst is requests at once. \"*\" limits every endpoint. A rule with a JSON-RPC method only limits calls of that method. Each call of a batch counts as a request. Example: *=20:40,keystore=1:5,keystore#keystore.exportUser=0.1:1")
+	requestLimits := flag.String("http-request-limits", "", "Comma separated list of limits on how long requests to an API endpoint may take and how large their bodies may be, of the form endpoint=timeout:maxBodySize, where maxBodySize is in bytes and 0 is unlimited. Only the most specific endpoint's limit applies. Example: *=30s:1048576,keystore=2m:0")
+	disabledEndpoints := flag.String("http-disabled-endpoints", "", "Comma separated list of API endpoints that aren't served, such as keystore,ipcs. The Admin API can't be disabled")
+	flag.IntVar(&Config.HTTPCompressionMinSize, "http-compression-min-size", 1024, "Size, in bytes, at which responses are compressed with gzip for clients that accept it. If 0, responses aren't compressed")
+	flag.BoolVar(&Config.HTTPWebSocketCalls, "http-websocket-calls-enabled", false, "If true, clients can make any API call over websocket connections, rather than only subscribe to a chain's notifications. Websocket connections may only be opened from http-allowed-origins")
+	allowedHeaders := flag.String("http-allowed-headers", "", "Comma separated list of headers that cross-origin requests may set. \"*\" allows every header. Defaults to Origin,Accept,Content-Type,X-Requested-With")
+
+	// gRPC Gateway:
+	grpcPort := flag.Uint("grpc-port", 9652, "Port of the gRPC gateway")
+	flag.BoolVar(&Config.GRPCEnabled, "grpc-enabled", false, "If true, this node serves the Info, Health, Keystore and Chains services over gRPC. It uses TLS if the HTTP server does")
 
 	// Bootstrapping:
 	bootstrapIPs := flag.String("bootstrap-ips", "", "Comma separated list of bootstrap peer ips to connect to. Example: 127.0.0.1:9630,127.0.0.1:9631")
 	bootstrapIDs := flag.String("bootstrap-ids", "", "Comma separated list of bootstrap peer ids to connect to. Example: JR4dVmy6ffUGAKCBDkyCbeZbyHQBeDsET,8CrVPQZ4VSqgL8zTdvL14G8HqAfrBr4z")
 
+	// Networking:
+	maxMessageSize := flag.Uint("max-message-size", 1<<25, "Maximum size, in bytes, of a message exchanged with a peer. Peers that send larger messages are disconnected")
+
+	flag.IntVar(&Config.ConnectionLimits.MaxInbound, "max-inbound-conns", 1024, "Maximum number of inbound peer connections that may be open at once")
+	flag.IntVar(&Config.ConnectionLimits.MaxInboundPerIP, "max-inbound-conns-per-ip", 8, "Maximum number of inbound peer connections that may be open at once from a single IP")
+	flag.DurationVar(&Config.ConnectionLimits.HandshakeTimeout, "handshake-timeout", 10*time.Second, "Amount of time a peer has to complete the handshake before it is disconnected")
+	flag.DurationVar(&Config.MaxReconnectDelay, "max-reconnect-delay", time.Minute, "Longest amount of time to wait between attempts to reconnect to a peer. Each attempt waits twice as long as the last, starting from a second")
+	flag.Float64Var(&Config.Throttle.MsgRate, "inbound-msg-rate", 1000, "Messages per second each peer may send, on average. Messages over the limit are dropped. If 0, messages aren't limited")
+	flag.IntVar(&Config.Throttle.MsgBurst, "inbound-msg-burst", 4000, "Messages each peer may send at once after not sending any for a while")
+	flag.Float64Var(&Config.Throttle.ByteRate, "inbound-bytes-rate", 1<<23, "Message bytes per second each peer may send, on average. Messages over the limit are dropped. If 0, bytes aren't limited")
+	flag.IntVar(&Config.Throttle.ByteBurst, "inbound-bytes-burst", 1<<25, "Message bytes each peer may send at once after not sending any for a while")
+
+	// Gossip:
+	flag.IntVar(&Config.PeerListGossip.Fanout, "gossip-peerlist-fanout", 100, "Number of peers this node's peer list is gossiped to each round. If 0, it is gossiped to every peer")
+	flag.DurationVar(&Config.PeerListGossip.Frequency, "gossip-peerlist-frequency", time.Minute, "Amount of time between rounds of peer list gossip. If 0, peer lists are only sent when connecting")
+	flag.IntVar(&Config.ContainerGossip.Fanout, "gossip-container-fanout", 0, "Number of non-validators each accepted container is gossiped to. If 0, it is gossiped to every non-validator")
+	flag.DurationVar(&Config.ContainerGossip.Frequency, "gossip-container-frequency", 0, "Amount of time between rounds of re-gossiping recently accepted containers. If 0, containers are only gossiped when they are accepted")
+	flag.IntVar(&Config.ContainerGossip.MaxPending, "gossip-container-max-pending", 1024, "Most accepted containers held for the next round of re-gossip")
+
 	// Staking:
 	consensusPort := flag.Uint("staking-port", 9651, "Port of the consensus server")
+	listenIPs := flag.String("staking-listen-ips", "", "Comma separated list of addresses to accept staking connections on. Connections to all but the first, which must be IPv4, are relayed to the first. Defaults to the public IP and staking port. Example: 10.0.0.2:9651,[2001:db8::2]:9651")
+	advertisedIPs := flag.String("staking-advertised-ips", "", "Comma separated list of addresses advertised to peers as accepting staking connections. Defaults to the public IP and staking port")
 	flag.BoolVar(&Config.EnableStaking, "staking-tls-enabled", true, "Require TLS to authenticate staking connections")
 	flag.StringVar(&Config.StakingKeyFile, "staking-tls-key-file", "", "TLS private key file for staking connections")
 	flag.StringVar(&Config.StakingCertFile, "staking-tls-cert-file", "", "TLS certificate file for staking connections")
@@ -82,7 +175,17 @@ func init() {
 	// Logging:
 	logsDir := flag.String("log-dir", "", "Logging directory for Ava")
 	logLevel := flag.String("log-level", "info", "The log level. Should be one of {verbo, debug, info, warn, error, fatal, off}")
+	captureDir := flag.String("capture-dir", "", "Directory that captured network messages are written to. Defaults to the capture folder in the logging directory")
+	profileDir := flag.String("profile-dir", "", "Directory that the admin API writes profiles to. Defaults to the profiles folder in the logging directory")
+	flag.IntVar(&Config.CaptureConfig.FileSize, "capture-file-size", 1<<23, "Number of bytes of captured messages written to a file before moving on to the next file")
+	flag.IntVar(&Config.CaptureConfig.RotationSize, "capture-rotation-size", 7, "Number of capture files that are kept")
+	flag.IntVar(&Config.CaptureConfig.MaxPayloadSize, "capture-max-payload-size", 256, "Number of bytes of each captured message's payload that are written")
 	logDisplayLevel := flag.String("log-display-level", "", "The log display level. If left blank, will inherit the value of log-level. Otherwise, should be one of {verbo, debug, info, warn, error, fatal, off}")
+	logFormat := flag.String("log-format", "plain", "The format of log messages. Should be one of {plain, json}")
+	flag.BoolVar(&loggingConfig.CompressRotated, "log-compress-rotated", false, "Gzip each log file once the log rotates away from it")
+	flag.Int64Var(&loggingConfig.MaxDiskUsage, "log-max-disk-usage", 0, "Number of bytes the log files may use. The oldest rotated log files are removed to stay under it. If 0, there's no limit")
+	flag.DurationVar(&loggingConfig.MaxAge, "log-max-age", 0, "How long rotated log files are kept. If 0, they're kept until they're overwritten")
+	flag.DurationVar(&loggingConfig.DuplicateWindow, "log-duplicate-window", 0, "How long duplicates of a log message aren't logged for. The number of duplicates that weren't logged is logged once the window ends. If 0, every message is logged")
 
 	flag.IntVar(&Config.ConsensusParams.K, "snow-sample-size", 20, "Number of nodes to query for each network poll")
 	flag.IntVar(&Config.ConsensusParams.Alpha, "snow-quorum-size", 18, "Alpha value to use for required number positive results")
@@ -90,33 +193,134 @@ func init() {
 	flag.IntVar(&Config.ConsensusParams.BetaRogue, "snow-rogue-commit-threshold", 30, "Beta value to use for rogue transactions")
 	flag.IntVar(&Config.ConsensusParams.Parents, "snow-avalanche-num-parents", 5, "Number of vertexes for reference from each new vertex")
 	flag.IntVar(&Config.ConsensusParams.BatchSize, "snow-avalanche-batch-size", 30, "Number of operations to batch in each new vertex")
+	snowballFactory := flag.String("snow-snowball", "tree", "How snowman chains decide between the children of a block, either tree, flat or decaying-flat. Decaying-flat decays the successful polls of each choice after every poll, so it switches preference sooner under long running conflicts")
+	flag.StringVar(&Config.ConsensusTraceDir, "snow-trace-dir", "", "Directory that the polls of each snowman chain's snowball instances are written to, as a binary log per run of the chain that can be replayed offline. If empty, consensus isn't traced")
+	snowballDecay := flag.Float64("snow-snowball-decay", 0.9, "Fraction, in (0, 1], of each choice's successful polls that remain after each poll when snow-snowball is decaying-flat")
+
+	// Request timeouts:
+	flag.DurationVar(&Config.NetworkTimeout.InitialTimeout, "network-initial-timeout", 2*time.Second, "Amount of time a request to another validator is given before it times out, until there are response times to adapt to")
+	flag.DurationVar(&Config.NetworkTimeout.MinimumTimeout, "network-minimum-timeout", 500*time.Millisecond, "Lower bound on the adaptive request timeout")
+	flag.DurationVar(&Config.NetworkTimeout.MaximumTimeout, "network-maximum-timeout", 10*time.Second, "Upper bound on the adaptive request timeout")
+	flag.Float64Var(&Config.NetworkTimeout.Percentile, "network-timeout-percentile", 0.9, "Percentile, in (0, 1], of recent response times that the request timeout is based on")
+	flag.Float64Var(&Config.NetworkTimeout.Multiplier, "network-timeout-multiplier", 2, "Multiple of the percentile response time that requests are given before they time out")
+	flag.IntVar(&Config.NetworkTimeout.WindowSize, "network-timeout-window", 1000, "Number of recent response times the request timeout adapts to")
+	flag.BoolVar(&Config.PeerTimeout.Enabled, "network-peer-timeouts-enabled", true, "If true, requests to a validator that has answered enough requests are given its smoothed latency plus a multiple of how much it varies, rather than the adaptive request timeout")
+	flag.Uint64Var(&Config.PeerTimeout.MinimumSamples, "network-peer-timeout-samples", 10, "Number of answered requests to a validator before its requests are given a timeout based on its own response times")
+	flag.Float64Var(&Config.PeerTimeout.DeviationMultiplier, "network-peer-timeout-deviations", 4, "Multiple of the deviation of a validator's response times that its requests are given on top of its latency")
+	flag.DurationVar(&Config.PeerTimeout.MinimumTimeout, "network-peer-minimum-timeout", 500*time.Millisecond, "Lower bound on the request timeout based on a validator's response times")
+	flag.DurationVar(&Config.PeerTimeout.MaximumTimeout, "network-peer-maximum-timeout", 10*time.Second, "Upper bound on the request timeout based on a validator's response times")
+
+	// Benchlist:
+	flag.IntVar(&Config.BenchlistConfig.Threshold, "benchlist-fail-threshold", 10, "Number of consecutive failed requests after which a validator is benched. If 0, validators are never benched")
+	flag.DurationVar(&Config.BenchlistConfig.Duration, "benchlist-duration", 5*time.Minute, "Amount of time a validator stays benched after its last failed request")
+	flag.Float64Var(&Config.BenchlistConfig.ProbeProbability, "benchlist-probe-probability", 0.05, "Probability that a benched validator is queried anyway to check if it has recovered")
+
+	// Latency:
+	flag.Float64Var(&Config.LatencySamplingBias, "latency-sampling-bias", 0, "Largest fraction, in [0, 1), of a validator's stake weight that is discounted when sampling because it responds slower than other validators. If 0, sampling is purely stake weighted")
+
+	// Upgrades:
+	upgradeSchedule := flag.String("upgrade-schedule", "", "Comma separated list of the upgrades this node activates and when, as name=value where the value is an RFC3339 time or a height. Example: fees=2020-09-01T00:00:00Z,limits=100000")
 
 	// Enable/Disable APIs:
 	flag.BoolVar(&Config.AdminAPIEnabled, "api-admin-enabled", true, "If true, this node exposes the Admin API")
 	flag.BoolVar(&Config.KeystoreAPIEnabled, "api-keystore-enabled", true, "If true, this node exposes the Keystore API")
+	flag.IntVar(&Config.KeystorePasswordPolicy.MinLength, "keystore-password-min-length", keystore.DefaultPasswordPolicy.MinLength, "Fewest characters a new keystore password may have")
+	flag.IntVar(&Config.KeystorePasswordPolicy.MinScore, "keystore-password-min-score", keystore.DefaultPasswordPolicy.MinScore, "Lowest strength score, from 0 to 4, a new keystore password may have. A password scores 0 if it could be guessed in under 10^3 guesses, 1 under 10^6, 2 under 10^8, 3 under 10^10 and 4 otherwise")
+	flag.StringVar(&Config.KeystoreHashParams.KDF, "keystore-kdf", keystore.DefaultHashParams.KDF, "Key derivation function new keystore passwords are hashed with, either argon2id or scrypt. Existing users keep the function and parameters their password was hashed with")
+	keystoreArgon2Time := flag.Uint("keystore-argon2-time", uint(keystore.DefaultHashParams.Time), "Number of passes argon2id makes over its memory when hashing keystore passwords")
+	keystoreArgon2Memory := flag.Uint("keystore-argon2-memory", uint(keystore.DefaultHashParams.Memory), "Memory, in KiB, argon2id uses when hashing keystore passwords")
+	keystoreArgon2Threads := flag.Uint("keystore-argon2-threads", uint(keystore.DefaultHashParams.Threads), "Number of threads argon2id uses when hashing keystore passwords")
+	keystoreScryptN := flag.Uint("keystore-scrypt-n", uint(keystore.DefaultScryptParams.N), "CPU and memory cost, a power of 2, of scrypt when hashing keystore passwords")
+	keystoreScryptR := flag.Uint("keystore-scrypt-r", uint(keystore.DefaultScryptParams.R), "Block size of scrypt when hashing keystore passwords")
+	keystoreScryptP := flag.Uint("keystore-scrypt-p", uint(keystore.DefaultScryptParams.P), "Parallelization of scrypt when hashing keystore passwords")
+	keystoreMasterKeyFile := flag.String("keystore-master-key-file", "", "File holding the CB58 encoded master key, of at least 32 bytes, that the keystore encrypts usernames and user records with. Users stored in plaintext are encrypted when it's first given, and can only be found with the same key afterwards. If empty, they're stored in plaintext")
+	keystoreMasterKey := flag.String("keystore-master-key", "", "CB58 encoded master key to use instead of keystore-master-key-file. Prefer giving it as the environment variable "+config.EnvName(envPrefix, "keystore-master-key")+" to passing it on the command line")
+	flag.DurationVar(&Config.KeystoreTokenDuration, "keystore-token-duration", keystore.DefaultTokenDuration, "How long the tokens issued by keystore.login can be given instead of a user's password")
 	flag.BoolVar(&Config.MetricsAPIEnabled, "api-metrics-enabled", true, "If true, this node exposes the Metrics API")
+	flag.BoolVar(&Config.HealthAPIEnabled, "api-health-enabled", true, "If true, this node runs health checks and exposes the Health API")
+	healthChecks := flag.String("health-checks", "", "Comma separated list of how the health checks are run, of the form name=interval:timeout:threshold, where the check runs every interval, fails if it takes longer than timeout, and is unhealthy after failing threshold times in a row. Empty fields keep their defaults. Checks are database, network, chains and processes. Example: network=10s:2s:5,database=::3")
 	flag.BoolVar(&Config.IPCEnabled, "api-ipcs-enabled", false, "If true, IPCs can be opened")
+	flag.BoolVar(&Config.IndexAPIEnabled, "api-index-enabled", false, "If true, the containers accepted by chains are indexed and this node exposes the Index API")
+	flag.BoolVar(&Config.IndexAddresses, "index-addresses", false, "If true, the transactions accepted by indexed chains are also indexed by the addresses they touch, for chains whose VM reports them. Requires the Index API")
+	flag.BoolVar(&Config.GraphQLAPIEnabled, "api-graphql-enabled", false, "If true, this node exposes the GraphQL API, which queries the indexed chains. Requires the Index API")
+	flag.BoolVar(&Config.EventsAPIEnabled, "api-events-enabled", false, "If true, this node exposes the Events API, which streams the containers chains accept as server-sent events")
+	flag.BoolVar(&Config.CoordinatorAPIEnabled, "api-coordinator-enabled", false, "If true, this node exposes the Coordinator API, which issues workflows of transactions across chains")
+	flag.BoolVar(&Config.AuthRequired, "api-auth-required", false, "If true, requests to APIs other than the public ones must carry a token issued by the Auth API")
+	flag.StringVar(&Config.AuthPassword, "api-auth-password", "", "Password that Auth API tokens are issued and revoked with")
+	authPublicEndpoints := flag.String("api-auth-public-endpoints", "health", "Comma separated list of API endpoints that don't need a token when authorization is required. Example: health,metrics,bc/X")
+	authProtectedEndpoints := flag.String("api-auth-protected-endpoints", "", "Comma separated list of API endpoints that need a token, while other endpoints don't. Can't be given with api-auth-required, which protects every endpoint that isn't public. Example: keystore,admin")
+	indexedChains := flag.String("index-chains", "", "Comma separated list of IDs or aliases of the chains that are indexed. Defaults to every chain. Example: X,P")
+
+	// Shutdown:
+	flag.DurationVar(&Config.ShutdownTimeout, "shutdown-timeout", 30*time.Second, "How long the node has to shut down cleanly after receiving SIGINT or SIGTERM. If it doesn't, or it receives either signal again, it stops at once and exits with status 2")
+
+	// Service mode:
+	flag.StringVar(&Config.PIDDir, "pid-dir", "", "Directory the PID files of the node, named gecko.pid, and of its supervised processes, named after them, are written to. If empty, no PID files are written")
+	supervisedProcesses := flag.String("supervised-processes", "", "Comma separated list of processes, such as IPC consumers, that are run alongside the node and restarted if they exit, of the form name=path arg1 arg2... Example: consumer=./build/ipc-consumer --chain X")
+	supervisorMinBackoff := flag.Duration("supervisor-min-backoff", time.Second, "How long to wait before restarting a supervised process the first time it exits. The wait doubles each time it exits again")
+	supervisorMaxBackoff := flag.Duration("supervisor-max-backoff", time.Minute, "Longest wait before restarting a supervised process. The wait is reset once the process has run this long without exiting")
 
 	// Throughput Server
 	throughputPort := flag.Uint("xput-server-port", 9652, "Port of the deprecated throughput test server")
 	flag.BoolVar(&Config.ThroughputServerEnabled, "xput-server-enabled", false, "If true, throughput test server is created")
 
 	flag.Parse()
+	if err := config.Apply(flag.CommandLine, configFileFlag, envPrefix); err != nil {
+		errs.Add(err)
+		return
+	}
 
 	networkID, err := genesis.NetworkID(*networkName)
 	errs.Add(err)
 
-	if networkID != genesis.LocalID {
-		errs.Add(fmt.Errorf("the only supported networkID is: %s", genesis.LocalName))
+	Config.NetworkID = networkID
+
+	// Building genesis bytes doesn't run a node, so it may be for any network
+	if BuildGenesis != "" {
+		return
 	}
 
-	Config.NetworkID = networkID
+	// Network:
+	network, registered := genesis.Lookup(networkID)
+	if *genesisSource != "" {
+		spec, err := loadGenesisSpec(*genesisSource, networkID)
+		switch {
+		case err != nil:
+			errs.Add(err)
+		case spec.NetworkID != networkID:
+			errs.Add(fmt.Errorf("the genesis is for network %s, not %s", genesis.NetworkName(spec.NetworkID), genesis.NetworkName(networkID)))
+		default:
+			network.Genesis, err = genesis.Build(spec)
+			errs.Add(err)
+		}
+	} else if !registered {
+		errs.Add(fmt.Errorf("network %s has no genesis. It must be given with --genesis", genesis.NetworkName(networkID)))
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "avm-tx-fee":
+			network.AVMFees.TxFee = *avmTxFee
+		case "avm-byte-fee":
+			network.AVMFees.ByteFee = *avmByteFee
+		case "avm-operation-fee":
+			network.AVMFees.OperationFee = *avmOperationFee
+		case "avm-fee-start-time":
+			startTime, err := time.Parse(time.RFC3339, *avmFeeStartTime)
+			if err != nil {
+				errs.Add(fmt.Errorf("avm-fee-start-time should be an RFC3339 time but is %q", *avmFeeStartTime))
+			}
+			network.AVMFees.StartTime = startTime
+		}
+	})
+	if errs.Errored() {
+		return
+	}
+	errs.Add(genesis.Register(networkID, network))
 
 	// DB:
 	if *db && err == nil {
-		// TODO: Add better params here
 		dbPath := path.Join(*dbDir, genesis.NetworkName(Config.NetworkID))
-		db, err := leveldb.New(dbPath, 0, 0, 0)
+		db, err := driver.Open(*dbBackend, dbPath)
 		Config.DB = db
 		errs.Add(err)
 	} else {
@@ -142,12 +346,278 @@ func init() {
 		Port: uint16(*consensusPort),
 	}
 
+	for _, ip := range strings.Split(*listenIPs, ",") {
+		if ip != "" {
+			addr, err := utils.ToIPDesc(ip)
+			errs.Add(err)
+			Config.ListenIPs = append(Config.ListenIPs, addr)
+		}
+	}
+	if len(Config.ListenIPs) == 0 {
+		Config.ListenIPs = []utils.IPDesc{Config.StakingIP}
+	}
+	// The peer network only supports IPv4, other addresses can only be relayed
+	if Config.ListenIPs[0].IP.To4() == nil {
+		errs.Add(errInvalidListenIP)
+	}
+	for _, ip := range strings.Split(*advertisedIPs, ",") {
+		if ip != "" {
+			addr, err := utils.ToIPDesc(ip)
+			errs.Add(err)
+			Config.AdvertisedIPs = append(Config.AdvertisedIPs, addr)
+		}
+	}
+	if len(Config.AdvertisedIPs) == 0 {
+		Config.AdvertisedIPs = []utils.IPDesc{Config.StakingIP}
+	}
+
 	// Bootstrapping:
-	for _, ip := range strings.Split(*bootstrapIPs, ",") {
+	Config.BootstrapPeers, err = parseBootstrapPeers(*bootstrapIPs, *bootstrapIDs)
+	errs.Add(err)
+
+	// Consensus:
+	errs.Add(Config.ConsensusParams.Valid())
+	switch *snowballFactory {
+	case "tree":
+		// Snowman chains use trees when no factory is given
+	case "flat":
+		Config.SnowballFactory = snowball.FlatFactory{}
+	case "decaying-flat":
+		factory := snowball.DecayingFlatFactory{Decay: *snowballDecay}
+		if err := factory.Valid(); err != nil {
+			errs.Add(fmt.Errorf("invalid snow-snowball-decay: %w", err))
+		}
+		Config.SnowballFactory = factory
+	default:
+		errs.Add(fmt.Errorf("unknown snow-snowball %q", *snowballFactory))
+	}
+
+	// Latency:
+	if Config.LatencySamplingBias < 0 || Config.LatencySamplingBias >= 1 {
+		errs.Add(errInvalidLatencyBias)
+	}
+
+	// Networking:
+	if *maxMessageSize < networking.MinMessageSize || *maxMessageSize > math.MaxUint32 {
+		errs.Add(errInvalidMaxMessageSize)
+	}
+	Config.MaxMessageSize = uint32(*maxMessageSize)
+	if Config.MaxReconnectDelay < networking.InitialReconnectDelay {
+		errs.Add(errInvalidReconnectDelay)
+	}
+	if err := Config.Throttle.Valid(); err != nil {
+		errs.Add(fmt.Errorf("inbound message limits are invalid: %w", err))
+	}
+
+	// HTTP:
+	Config.HTTPPort = uint16(*httpPort)
+	socketMode, err := strconv.ParseUint(*httpSocketMode, 8, 32)
+	if err != nil || socketMode > 0777 {
+		errs.Add(fmt.Errorf("http-socket-mode should be octal permissions, such as 0660, but is %q", *httpSocketMode))
+	}
+	Config.HTTPSocketMode = os.FileMode(socketMode)
+	if !Config.HTTPTCPEnabled && Config.HTTPSocketPath == "" {
+		errs.Add(errNoHTTPListener)
+	}
+	Config.GRPCPort = uint16(*grpcPort)
+	for _, origin := range strings.Split(*allowedOrigins, ",") {
+		if origin != "" {
+			Config.CORSConfig.AllowedOrigins = append(Config.CORSConfig.AllowedOrigins, origin)
+		}
+	}
+	for _, method := range strings.Split(*allowedMethods, ",") {
+		if method != "" {
+			Config.CORSConfig.AllowedMethods = append(Config.CORSConfig.AllowedMethods, method)
+		}
+	}
+	for _, header := range strings.Split(*allowedHeaders, ",") {
+		if header != "" {
+			Config.CORSConfig.AllowedHeaders = append(Config.CORSConfig.AllowedHeaders, header)
+		}
+	}
+	Config.DisabledEndpoints = parseList(*disabledEndpoints)
+	Config.RateLimits, err = api.ParseRateLimitRules(*rateLimits)
+	errs.Add(err)
+	Config.RequestLimits, err = api.ParseRequestLimitRules(*requestLimits)
+	errs.Add(err)
+
+	// Health:
+	Config.HealthChecks, err = health.ParseCheckConfigs(*healthChecks)
+	errs.Add(err)
+
+	// Keystore:
+	errs.Add(Config.KeystorePasswordPolicy.Valid())
+	if *keystoreArgon2Threads > math.MaxUint8 {
+		errs.Add(fmt.Errorf("keystore-argon2-threads = %d: Fails the condition that: threads <= %d", *keystoreArgon2Threads, math.MaxUint8))
+	}
+	switch Config.KeystoreHashParams.KDF {
+	case keystore.Argon2id:
+		Config.KeystoreHashParams.Time = uint32(*keystoreArgon2Time)
+		Config.KeystoreHashParams.Memory = uint32(*keystoreArgon2Memory)
+		Config.KeystoreHashParams.Threads = uint8(*keystoreArgon2Threads)
+	case keystore.Scrypt:
+		Config.KeystoreHashParams.N = uint32(*keystoreScryptN)
+		Config.KeystoreHashParams.R = uint32(*keystoreScryptR)
+		Config.KeystoreHashParams.P = uint32(*keystoreScryptP)
+	}
+	errs.Add(Config.KeystoreHashParams.Valid())
+	if *keystoreMasterKeyFile != "" && *keystoreMasterKey == "" {
+		keyBytes, err := ioutil.ReadFile(*keystoreMasterKeyFile)
+		if err != nil {
+			errs.Add(fmt.Errorf("couldn't read keystore-master-key-file: %w", err))
+		}
+		*keystoreMasterKey = strings.TrimSpace(string(keyBytes))
+	}
+	if *keystoreMasterKey != "" {
+		masterKey := formatting.CB58{}
+		if err := masterKey.FromString(*keystoreMasterKey); err != nil {
+			errs.Add(fmt.Errorf("keystore master key should be CB58 encoded: %w", err))
+		} else if len(masterKey.Bytes) < keystore.MinMasterKeyLen {
+			errs.Add(fmt.Errorf("keystore master key is %d bytes but must be at least %d", len(masterKey.Bytes), keystore.MinMasterKeyLen))
+		}
+		Config.KeystoreMasterKey = masterKey.Bytes
+	}
+	if Config.KeystoreTokenDuration <= 0 {
+		errs.Add(fmt.Errorf("keystore-token-duration = %s: Fails the condition that: 0 < duration", Config.KeystoreTokenDuration))
+	}
+
+	// Service mode:
+	Config.SupervisedProcesses, err = supervisor.ParseConfigs(*supervisedProcesses, *supervisorMinBackoff, *supervisorMaxBackoff)
+	errs.Add(err)
+
+	// Upgrades:
+	Config.UpgradeSchedule, err = upgrades.ParseSchedule(*upgradeSchedule)
+	errs.Add(err)
+
+	// DB quotas:
+	Config.DBQuotas.Chains = make(map[string]uint64)
+	for _, entry := range strings.Split(*dbChainQuotas, ",") {
+		if entry == "" {
+			continue
+		}
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) != 2 || fields[0] == "" {
+			errs.Add(fmt.Errorf("database quota %q should be of the form chain=bytes", entry))
+			continue
+		}
+		quota, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			errs.Add(fmt.Errorf("database quota of chain %s should be a number of bytes but is %q", fields[0], fields[1]))
+			continue
+		}
+		Config.DBQuotas.Chains[fields[0]] = quota
+	}
+
+	// Pruning:
+	if err := Config.Pruning.Valid(); err != nil {
+		errs.Add(fmt.Errorf("pruning config is invalid: %w", err))
+	}
+
+	// Tracked subnets:
+	for _, subnet := range strings.Split(*trackSubnets, ",") {
+		if subnet == "" {
+			continue
+		}
+		subnetID, err := ids.FromString(subnet)
+		if err != nil {
+			errs.Add(fmt.Errorf("couldn't parse tracked subnet %q: %w", subnet, err))
+			continue
+		}
+		Config.TrackedSubnets.Add(subnetID)
+	}
+	if Config.TrackedSubnets.Len() != 0 {
+		Config.TrackedSubnets.Add(platformvm.DefaultSubnetID)
+	}
+
+	// Auth:
+	for _, endpoint := range strings.Split(*authPublicEndpoints, ",") {
+		if endpoint != "" {
+			Config.AuthPublicEndpoints = append(Config.AuthPublicEndpoints, endpoint)
+		}
+	}
+	for _, endpoint := range strings.Split(*authProtectedEndpoints, ",") {
+		if endpoint != "" {
+			Config.AuthProtectedEndpoints = append(Config.AuthProtectedEndpoints, endpoint)
+		}
+	}
+	if Config.AuthRequired && len(Config.AuthProtectedEndpoints) > 0 {
+		errs.Add(errAuthProtectedAndRequired)
+	}
+	if (Config.AuthRequired || len(Config.AuthProtectedEndpoints) > 0) && Config.AuthPassword == "" {
+		errs.Add(errNoAuthPassword)
+	}
+
+	// Index:
+	for _, chain := range strings.Split(*indexedChains, ",") {
+		if chain != "" {
+			Config.IndexedChains = append(Config.IndexedChains, chain)
+		}
+	}
+
+	// Logging:
+	if *logsDir != "" {
+		loggingConfig.Directory = *logsDir
+	}
+	logFileLevel, err := logging.ToLevel(*logLevel)
+	errs.Add(err)
+	loggingConfig.LogLevel = logFileLevel
+
+	if *logDisplayLevel == "" {
+		*logDisplayLevel = *logLevel
+	}
+	displayLevel, err := logging.ToLevel(*logDisplayLevel)
+	errs.Add(err)
+	loggingConfig.DisplayLevel = displayLevel
+
+	format, err := logging.ToFormat(*logFormat)
+	errs.Add(err)
+	loggingConfig.Format = format
+
+	if loggingConfig.MaxDiskUsage < 0 {
+		errs.Add(fmt.Errorf("log-max-disk-usage = %d: Fails the condition that: 0 <= bytes", loggingConfig.MaxDiskUsage))
+	}
+	if loggingConfig.MaxAge < 0 {
+		errs.Add(fmt.Errorf("log-max-age = %s: Fails the condition that: 0 <= duration", loggingConfig.MaxAge))
+	}
+	if loggingConfig.DuplicateWindow < 0 {
+		errs.Add(fmt.Errorf("log-duplicate-window = %s: Fails the condition that: 0 <= duration", loggingConfig.DuplicateWindow))
+	}
+
+	Config.LoggingConfig = loggingConfig
+
+	// Message capture:
+	Config.CaptureConfig.Directory = path.Join(loggingConfig.Directory, "capture")
+	if *captureDir != "" {
+		Config.CaptureConfig.Directory = *captureDir
+	}
+
+	// Profiles:
+	Config.ProfileDir = path.Join(loggingConfig.Directory, "profiles")
+	if *profileDir != "" {
+		Config.ProfileDir = *profileDir
+	}
+
+	// Throughput:
+	Config.ThroughputPort = uint16(*throughputPort)
+
+	// Router used for consensus
+	Config.ConsensusRouter = &router.ChainRouter{}
+
+	// Reloading:
+	Config.Reload = reload
+}
+
+// parseBootstrapPeers returns the peers at the comma separated [ips]. If
+// staking is enabled, the peers' IDs are the comma separated [nodeIDs], in the
+// same order. Otherwise, they're derived from the peers' IPs.
+func parseBootstrapPeers(ips, nodeIDs string) ([]*node.Peer, error) {
+	peers := []*node.Peer(nil)
+	errs := wrappers.Errs{}
+	for _, ip := range strings.Split(ips, ",") {
 		if ip != "" {
 			addr, err := utils.ToIPDesc(ip)
 			errs.Add(err)
-			Config.BootstrapPeers = append(Config.BootstrapPeers, &node.Peer{
+			peers = append(peers, &node.Peer{
 				IP: addr,
 			})
 		}
@@ -155,52 +625,133 @@ func init() {
 	if Config.EnableStaking {
 		i := 0
 		cb58 := formatting.CB58{}
-		for _, id := range strings.Split(*bootstrapIDs, ",") {
+		for _, id := range strings.Split(nodeIDs, ",") {
 			if id != "" {
 				errs.Add(cb58.FromString(id))
 				cert, err := ids.ToShortID(cb58.Bytes)
 				errs.Add(err)
 
-				if len(Config.BootstrapPeers) <= i {
+				if len(peers) <= i {
 					errs.Add(errBootstrapMismatch)
 					continue
 				}
-				Config.BootstrapPeers[i].ID = cert
+				peers[i].ID = cert
 				i++
 			}
 		}
-		if len(Config.BootstrapPeers) != i {
-			errs.Add(fmt.Errorf("More bootstrap IPs, %d, provided than bootstrap IDs, %d", len(Config.BootstrapPeers), i))
+		if len(peers) != i {
+			errs.Add(fmt.Errorf("More bootstrap IPs, %d, provided than bootstrap IDs, %d", len(peers), i))
 		}
 	} else {
-		for _, peer := range Config.BootstrapPeers {
+		for _, peer := range peers {
 			peer.ID = ids.NewShortID(hashing.ComputeHash160Array([]byte(peer.IP.String())))
 		}
 	}
+	return peers, errs.Err
+}
 
-	// HTTP:
-	Config.HTTPPort = uint16(*httpPort)
+// parseList returns the non-empty elements of the comma separated [list]
+func parseList(list string) []string {
+	elements := []string(nil)
+	for _, element := range strings.Split(list, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
 
-	// Logging:
-	if *logsDir != "" {
-		loggingConfig.Directory = *logsDir
+// reload rereads the options that may be changed while the node is running
+// from the command line, environment variables and config file
+func reload() (node.ReloadableConfig, error) {
+	values, err := config.Snapshot(flag.CommandLine, os.Args[1:], configFileFlag, envPrefix)
+	if err != nil {
+		return node.ReloadableConfig{}, err
 	}
-	logFileLevel, err := logging.ToLevel(*logLevel)
-	errs.Add(err)
-	loggingConfig.LogLevel = logFileLevel
 
-	if *logDisplayLevel == "" {
-		*logDisplayLevel = *logLevel
+	reloaded := node.ReloadableConfig{}
+	errs := wrappers.Errs{}
+	parseInt := func(name string) int {
+		value, err := strconv.Atoi(values[name])
+		if err != nil {
+			errs.Add(fmt.Errorf("%s should be an integer but is %q", name, values[name]))
+		}
+		return value
 	}
-	displayLevel, err := logging.ToLevel(*logDisplayLevel)
+	parseDuration := func(name string) time.Duration {
+		value, err := time.ParseDuration(values[name])
+		if err != nil {
+			errs.Add(fmt.Errorf("%s should be a duration but is %q", name, values[name]))
+		}
+		return value
+	}
+	parseFloat := func(name string) float64 {
+		value, err := strconv.ParseFloat(values[name], 64)
+		if err != nil {
+			errs.Add(fmt.Errorf("%s should be a number but is %q", name, values[name]))
+		}
+		return value
+	}
+
+	// Logging:
+	reloaded.LogLevel, err = logging.ToLevel(values["log-level"])
 	errs.Add(err)
-	loggingConfig.DisplayLevel = displayLevel
+	if displayLevel := values["log-display-level"]; displayLevel != "" {
+		reloaded.DisplayLevel, err = logging.ToLevel(displayLevel)
+		errs.Add(err)
+	} else {
+		reloaded.DisplayLevel = reloaded.LogLevel
+	}
 
-	Config.LoggingConfig = loggingConfig
+	// HTTP:
+	reloaded.RateLimits, err = api.ParseRateLimitRules(values["http-rate-limits"])
+	errs.Add(err)
+	reloaded.RequestLimits, err = api.ParseRequestLimitRules(values["http-request-limits"])
+	errs.Add(err)
 
-	// Throughput:
-	Config.ThroughputPort = uint16(*throughputPort)
+	// Connection limits:
+	reloaded.ConnectionLimits.MaxInbound = parseInt("max-inbound-conns")
+	reloaded.ConnectionLimits.MaxInboundPerIP = parseInt("max-inbound-conns-per-ip")
+	reloaded.ConnectionLimits.HandshakeTimeout = parseDuration("handshake-timeout")
+	reloaded.MaxReconnectDelay = parseDuration("max-reconnect-delay")
+
+	// Inbound message limits:
+	reloaded.Throttle.MsgRate = parseFloat("inbound-msg-rate")
+	reloaded.Throttle.MsgBurst = parseInt("inbound-msg-burst")
+	reloaded.Throttle.ByteRate = parseFloat("inbound-bytes-rate")
+	reloaded.Throttle.ByteBurst = parseInt("inbound-bytes-burst")
+
+	// Gossip:
+	reloaded.PeerListGossip.Fanout = parseInt("gossip-peerlist-fanout")
+	reloaded.PeerListGossip.Frequency = parseDuration("gossip-peerlist-frequency")
+	reloaded.ContainerGossip.Fanout = parseInt("gossip-container-fanout")
+	reloaded.ContainerGossip.Frequency = parseDuration("gossip-container-frequency")
+	reloaded.ContainerGossip.MaxPending = parseInt("gossip-container-max-pending")
 
-	// Router used for consensus
-	Config.ConsensusRouter = &router.ChainRouter{}
+	// Bootstrapping:
+	reloaded.BootstrapPeers, err = parseBootstrapPeers(values["bootstrap-ips"], values["bootstrap-ids"])
+	errs.Add(err)
+
+	// APIs:
+	reloaded.DisabledEndpoints = parseList(values["http-disabled-endpoints"])
+
+	if errs.Errored() {
+		return node.ReloadableConfig{}, errs.Err
+	}
+	if err := reloaded.ConnectionLimits.Valid(); err != nil {
+		return node.ReloadableConfig{}, fmt.Errorf("connection limits are invalid: %w", err)
+	}
+	if reloaded.MaxReconnectDelay < networking.InitialReconnectDelay {
+		return node.ReloadableConfig{}, errInvalidReconnectDelay
+	}
+	if err := reloaded.Throttle.Valid(); err != nil {
+		return node.ReloadableConfig{}, fmt.Errorf("inbound message limits are invalid: %w", err)
+	}
+	if err := reloaded.PeerListGossip.Valid(); err != nil {
+		return node.ReloadableConfig{}, fmt.Errorf("peer list gossip parameters are invalid: %w", err)
+	}
+	if err := reloaded.ContainerGossip.Valid(); err != nil {
+		return node.ReloadableConfig{}, fmt.Errorf("container gossip parameters are invalid: %w", err)
+	}
+	return reloaded, nil
 }
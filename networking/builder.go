@@ -32,6 +32,46 @@ func (m Builder) PeerList(ipDescs []utils.IPDesc) (Msg, error) {
 	return m.Pack(PeerList, map[Field]interface{}{Peers: ipDescs})
 }
 
+// PeerMetadata message
+func (m Builder) PeerMetadata(metadata []byte) (Msg, error) {
+	return m.Pack(PeerMetadata, map[Field]interface{}{Bytes: metadata})
+}
+
+// GetStateSummary message
+func (m Builder) GetStateSummary(chainID ids.ID, requestID uint32) (Msg, error) {
+	return m.Pack(GetStateSummary, map[Field]interface{}{
+		ChainID:   chainID.Bytes(),
+		RequestID: requestID,
+	})
+}
+
+// StateSummary message
+func (m Builder) StateSummary(chainID ids.ID, requestID uint32, summary []byte) (Msg, error) {
+	return m.Pack(StateSummary, map[Field]interface{}{
+		ChainID:      chainID.Bytes(),
+		RequestID:    requestID,
+		SummaryBytes: summary,
+	})
+}
+
+// GetStateChunk message
+func (m Builder) GetStateChunk(chainID ids.ID, requestID uint32, request []byte) (Msg, error) {
+	return m.Pack(GetStateChunk, map[Field]interface{}{
+		ChainID:         chainID.Bytes(),
+		RequestID:       requestID,
+		StateChunkBytes: request,
+	})
+}
+
+// StateChunk message
+func (m Builder) StateChunk(chainID ids.ID, requestID uint32, response []byte) (Msg, error) {
+	return m.Pack(StateChunk, map[Field]interface{}{
+		ChainID:         chainID.Bytes(),
+		RequestID:       requestID,
+		StateChunkBytes: response,
+	})
+}
+
 // GetAcceptedFrontier message
 func (m Builder) GetAcceptedFrontier(chainID ids.ID, requestID uint32) (Msg, error) {
 	return m.Pack(GetAcceptedFrontier, map[Field]interface{}{
@@ -98,6 +138,18 @@ func (m Builder) Put(chainID ids.ID, requestID uint32, containerID ids.ID, conta
 	})
 }
 
+// PutChunk message
+func (m Builder) PutChunk(chainID ids.ID, requestID uint32, containerID ids.ID, index, numChunks uint32, chunk []byte) (Msg, error) {
+	return m.Pack(PutChunk, map[Field]interface{}{
+		ChainID:        chainID.Bytes(),
+		RequestID:      requestID,
+		ContainerID:    containerID.Bytes(),
+		ChunkIndex:     index,
+		NumChunks:      numChunks,
+		ContainerBytes: chunk,
+	})
+}
+
 // PushQuery message
 func (m Builder) PushQuery(chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) (Msg, error) {
 	return m.Pack(PushQuery, map[Field]interface{}{
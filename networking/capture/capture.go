@@ -0,0 +1,212 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package capture writes the messages exchanged on behalf of a chain to
+// rotating files, so that a consensus stall can be analyzed after the fact.
+package capture
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+var (
+	errAlreadyCapturing = errors.New("already capturing messages")
+	errNotCapturing     = errors.New("not capturing messages")
+)
+
+// Direction a captured message travelled in
+type Direction string
+
+// Directions a message can travel in
+const (
+	Sent     Direction = "sent"
+	Received Direction = "received"
+)
+
+// Config determines where captured messages are written and how much of them
+// is kept
+type Config struct {
+	// Directory the capture files are written to
+	Directory string
+	// FileSize is the number of bytes written to a file before moving on to
+	// the next one
+	FileSize int
+	// RotationSize is the number of files that are kept
+	RotationSize int
+	// MaxPayloadSize is the number of bytes of each payload that are written
+	MaxPayloadSize int
+}
+
+// Valid returns nil if the parameters describe a valid initialization.
+func (c Config) Valid() error {
+	switch {
+	case c.FileSize <= 0:
+		return fmt.Errorf("FileSize = %d: Fails the condition that: 0 < FileSize", c.FileSize)
+	case c.RotationSize <= 0:
+		return fmt.Errorf("RotationSize = %d: Fails the condition that: 0 < RotationSize", c.RotationSize)
+	case c.MaxPayloadSize < 0:
+		return fmt.Errorf("MaxPayloadSize = %d: Fails the condition that: 0 <= MaxPayloadSize", c.MaxPayloadSize)
+	default:
+		return nil
+	}
+}
+
+// Record is the captured form of a message. Records are written to the
+// capture files as one JSON object per line.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Direction Direction `json:"direction"`
+	Type      string    `json:"type"`
+	Peer      string    `json:"peer"`
+	ChainID   ids.ID    `json:"chainID"`
+	// Size is the length of the full payload, which may have been truncated
+	Size    int    `json:"size"`
+	Payload string `json:"payload"`
+}
+
+// Capturer writes the messages of the chain being captured to rotating files
+type Capturer struct {
+	lock   sync.Mutex
+	config Config
+	clock  timer.Clock
+
+	capturing bool
+	chainID   ids.ID
+
+	file        *os.File
+	w           *bufio.Writer
+	fileIndex   int
+	currentSize int
+}
+
+// Initialize the capturer. Nothing is captured until Start is called.
+func (c *Capturer) Initialize(config Config) { c.config = config }
+
+// Start capturing the messages sent and received on behalf of [chainID]
+func (c *Capturer) Start(chainID ids.ID) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.capturing {
+		return errAlreadyCapturing
+	}
+	if err := os.MkdirAll(c.config.Directory, os.ModePerm); err != nil {
+		return err
+	}
+	c.fileIndex = 0
+	if err := c.open(); err != nil {
+		return err
+	}
+	c.capturing = true
+	c.chainID = chainID
+	return nil
+}
+
+// Stop capturing messages and flush the capture file
+func (c *Capturer) Stop() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.capturing {
+		return errNotCapturing
+	}
+	c.capturing = false
+	return c.close()
+}
+
+// Capturing returns true if messages on behalf of [chainID] are being
+// captured. This allows callers to avoid copying payloads that won't be
+// written.
+func (c *Capturer) Capturing(chainID ids.ID) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.capturing && c.chainID.Equals(chainID)
+}
+
+// Capture [payload], a message of type [msgType] that travelled in [direction]
+// to or from [peer] on behalf of [chainID]. If the chain isn't being
+// captured, this is a no-op.
+func (c *Capturer) Capture(direction Direction, msgType string, peer string, chainID ids.ID, payload []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.capturing || !c.chainID.Equals(chainID) {
+		return nil
+	}
+
+	truncated := payload
+	if len(truncated) > c.config.MaxPayloadSize {
+		truncated = truncated[:c.config.MaxPayloadSize]
+	}
+	line, err := json.Marshal(Record{
+		Time:      c.clock.Time(),
+		Direction: direction,
+		Type:      msgType,
+		Peer:      peer,
+		ChainID:   chainID,
+		Size:      len(payload),
+		Payload:   hex.EncodeToString(truncated),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Rotate before writing, rather than after, so a full file isn't
+	// replaced by an empty one
+	if c.currentSize > c.config.FileSize {
+		closeErr := c.close()
+		c.fileIndex = (c.fileIndex + 1) % c.config.RotationSize
+		if err := c.open(); err != nil {
+			// There is nowhere left to write to
+			c.capturing = false
+			return err
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+	}
+
+	n, err := c.w.Write(append(line, '\n'))
+	c.currentSize += n
+	if err != nil {
+		return err
+	}
+	// Flush every record so a capture survives the node crashing
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Capturer) open() error {
+	filename := path.Join(c.config.Directory, fmt.Sprintf("%d.capture", c.fileIndex))
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	c.file = f
+	c.w = bufio.NewWriter(f)
+	c.currentSize = 0
+	return nil
+}
+
+func (c *Capturer) close() error {
+	flushErr := c.w.Flush()
+	closeErr := c.file.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
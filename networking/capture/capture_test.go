@@ -0,0 +1,127 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func readRecords(t *testing.T, filename string) []Record {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records := []Record(nil)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		record := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestCapture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "capture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chainID := ids.NewID([32]byte{1})
+	otherChainID := ids.NewID([32]byte{2})
+
+	c := Capturer{}
+	c.Initialize(Config{
+		Directory:      dir,
+		FileSize:       1 << 20,
+		RotationSize:   2,
+		MaxPayloadSize: 2,
+	})
+
+	if err := c.Capture(Sent, "Get", "127.0.0.1:9651", chainID, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(chainID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(chainID); err == nil {
+		t.Fatalf("Should have errored due to already capturing")
+	}
+	if !c.Capturing(chainID) || c.Capturing(otherChainID) {
+		t.Fatalf("Should only be capturing %s", chainID)
+	}
+	if err := c.Capture(Received, "Put", "127.0.0.1:9651", chainID, []byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Capture(Sent, "Put", "127.0.0.1:9651", otherChainID, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Stop(); err == nil {
+		t.Fatalf("Should have errored due to not capturing")
+	}
+
+	records := readRecords(t, path.Join(dir, "0.capture"))
+	switch {
+	case len(records) != 1:
+		t.Fatalf("Expected 1 record but got %d", len(records))
+	case records[0].Direction != Received || records[0].Type != "Put":
+		t.Fatalf("Wrong record captured: %+v", records[0])
+	case !records[0].ChainID.Equals(chainID):
+		t.Fatalf("Wrong chain captured: %s", records[0].ChainID)
+	case records[0].Size != 3 || records[0].Payload != "0102":
+		t.Fatalf("Payload should have been truncated: %+v", records[0])
+	}
+}
+
+func TestCaptureRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "capture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chainID := ids.NewID([32]byte{1})
+
+	c := Capturer{}
+	c.Initialize(Config{
+		Directory:      dir,
+		FileSize:       1,
+		RotationSize:   2,
+		MaxPayloadSize: 16,
+	})
+	if err := c.Start(chainID); err != nil {
+		t.Fatal(err)
+	}
+	// Every record fills a file, so the third overwrites the first file
+	for _, msgType := range []string{"Get", "Put", "Chits"} {
+		if err := c.Capture(Sent, msgType, "127.0.0.1:9651", chainID, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if records := readRecords(t, path.Join(dir, "0.capture")); len(records) != 1 || records[0].Type != "Chits" {
+		t.Fatalf("The oldest file should have been overwritten: %+v", records)
+	}
+	if records := readRecords(t, path.Join(dir, "1.capture")); len(records) != 1 || records[0].Type != "Put" {
+		t.Fatalf("Wrong records in the second file: %+v", records)
+	}
+}
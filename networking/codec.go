@@ -44,6 +44,7 @@ func (Codec) Pack(op salticidae.Opcode, fields map[Field]interface{}) (Msg, erro
 	return &msg{
 		op:     op,
 		ds:     salticidae.NewDataStreamFromBytes(p.Bytes, false),
+		bytes:  p.Bytes,
 		fields: fields,
 	}, nil
 }
@@ -76,6 +77,7 @@ func (Codec) Parse(op salticidae.Opcode, ds salticidae.DataStream) (Msg, error)
 	return &msg{
 		op:     op,
 		ds:     ds,
+		bytes:  p.Bytes,
 		fields: fields,
 	}, p.Err
 }
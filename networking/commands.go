@@ -14,19 +14,23 @@ type Field uint32
 
 // Fields that may be packed. These values are not sent over the wire.
 const (
-	VersionStr     Field = iota // Used in handshake
-	NetworkID                   // Used in handshake
-	MyTime                      // Used in handshake
-	Peers                       // Used in handshake
-	ChainID                     // Used for dispatching
-	RequestID                   // Used for all messages
-	ContainerID                 // Used for querying
-	ContainerBytes              // Used for gossiping
-	ContainerIDs                // Used for querying
-	Bytes                       // Used as arbitrary data
-	TxID                        // Used for throughput tests
-	Tx                          // Used for throughput tests
-	Status                      // Used for throughput tests
+	VersionStr      Field = iota // Used in handshake
+	NetworkID                    // Used in handshake
+	MyTime                       // Used in handshake
+	Peers                        // Used in handshake
+	ChainID                      // Used for dispatching
+	RequestID                    // Used for all messages
+	ContainerID                  // Used for querying
+	ContainerBytes               // Used for gossiping
+	ContainerIDs                 // Used for querying
+	Bytes                        // Used as arbitrary data
+	TxID                         // Used for throughput tests
+	Tx                           // Used for throughput tests
+	Status                       // Used for throughput tests
+	ChunkIndex                   // Used for chunked transfers
+	NumChunks                    // Used for chunked transfers
+	SummaryBytes                 // Used for state sync
+	StateChunkBytes              // Used for state sync
 )
 
 // Packer returns the packer function that can be used to pack this field.
@@ -58,6 +62,14 @@ func (f Field) Packer() func(*wrappers.Packer, interface{}) {
 		return wrappers.TryPackBytes
 	case Status:
 		return wrappers.TryPackInt
+	case ChunkIndex:
+		return wrappers.TryPackInt
+	case NumChunks:
+		return wrappers.TryPackInt
+	case SummaryBytes:
+		return wrappers.TryPackBytes
+	case StateChunkBytes:
+		return wrappers.TryPackBytes
 	default:
 		return nil
 	}
@@ -92,6 +104,14 @@ func (f Field) Unpacker() func(*wrappers.Packer) interface{} {
 		return wrappers.TryUnpackBytes
 	case Status:
 		return wrappers.TryUnpackInt
+	case ChunkIndex:
+		return wrappers.TryUnpackInt
+	case NumChunks:
+		return wrappers.TryUnpackInt
+	case SummaryBytes:
+		return wrappers.TryUnpackBytes
+	case StateChunkBytes:
+		return wrappers.TryUnpackBytes
 	default:
 		return nil
 	}
@@ -123,6 +143,14 @@ func (f Field) String() string {
 		return "Tx"
 	case Status:
 		return "Status"
+	case ChunkIndex:
+		return "Chunk Index"
+	case NumChunks:
+		return "Number of Chunks"
+	case SummaryBytes:
+		return "Summary Bytes"
+	case StateChunkBytes:
+		return "State Chunk Bytes"
 	default:
 		return "Unknown Field"
 	}
@@ -154,6 +182,15 @@ const (
 	// Throughput test:
 	IssueTx
 	DecidedTx
+	// Chunked transfers:
+	PutChunk
+	// Peer metadata:
+	PeerMetadata
+	// State sync:
+	GetStateSummary
+	StateSummary
+	GetStateChunk
+	StateChunk
 )
 
 // Defines the messages that can be sent/received with this network
@@ -183,5 +220,70 @@ var (
 		// Throughput test:
 		IssueTx:   []Field{ChainID, Tx},
 		DecidedTx: []Field{TxID, Status},
+		// Chunked transfers:
+		PutChunk: []Field{ChainID, RequestID, ContainerID, ChunkIndex, NumChunks, ContainerBytes},
+		// Peer metadata:
+		PeerMetadata: []Field{Bytes},
+		// State sync:
+		GetStateSummary: []Field{ChainID, RequestID},
+		StateSummary:    []Field{ChainID, RequestID, SummaryBytes},
+		GetStateChunk:   []Field{ChainID, RequestID, StateChunkBytes},
+		StateChunk:      []Field{ChainID, RequestID, StateChunkBytes},
 	}
 )
+
+// OpName returns the human readable name of [op]
+func OpName(op salticidae.Opcode) string {
+	switch op {
+	case GetVersion:
+		return "GetVersion"
+	case Version:
+		return "Version"
+	case GetPeerList:
+		return "GetPeerList"
+	case PeerList:
+		return "PeerList"
+	case GetAcceptedFrontier:
+		return "GetAcceptedFrontier"
+	case AcceptedFrontier:
+		return "AcceptedFrontier"
+	case GetAccepted:
+		return "GetAccepted"
+	case Accepted:
+		return "Accepted"
+	case Get:
+		return "Get"
+	case Put:
+		return "Put"
+	case PushQuery:
+		return "PushQuery"
+	case PullQuery:
+		return "PullQuery"
+	case Chits:
+		return "Chits"
+	case Ping:
+		return "Ping"
+	case Pong:
+		return "Pong"
+	case Data:
+		return "Data"
+	case IssueTx:
+		return "IssueTx"
+	case DecidedTx:
+		return "DecidedTx"
+	case PutChunk:
+		return "PutChunk"
+	case PeerMetadata:
+		return "PeerMetadata"
+	case GetStateSummary:
+		return "GetStateSummary"
+	case StateSummary:
+		return "StateSummary"
+	case GetStateChunk:
+		return "GetStateChunk"
+	case StateChunk:
+		return "StateChunk"
+	default:
+		return "Unknown Op"
+	}
+}
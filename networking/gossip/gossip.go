@@ -0,0 +1,138 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package gossip contains the parameters that determine how each class of
+// message is gossiped, which trade redundancy for bandwidth.
+package gossip
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/sampler"
+)
+
+// Config determines how a class of message is gossiped
+type Config struct {
+	// Fanout is the number of peers each message is gossiped to. If 0, each
+	// message is gossiped to every eligible peer.
+	Fanout int
+	// Frequency is the amount of time between rounds of gossip. If 0,
+	// messages are only gossiped once, when they are first sent.
+	Frequency time.Duration
+	// MaxPending is the most messages that are held for the next round of
+	// gossip. Once exceeded, the oldest messages are dropped.
+	MaxPending int
+}
+
+// Valid returns nil if the parameters describe a valid initialization.
+func (c Config) Valid() error {
+	switch {
+	case c.Fanout < 0:
+		return fmt.Errorf("Fanout = %d: Fails the condition that: 0 <= Fanout", c.Fanout)
+	case c.Frequency < 0:
+		return fmt.Errorf("Frequency = %s: Fails the condition that: 0 <= Frequency", c.Frequency)
+	case c.MaxPending < 0:
+		return fmt.Errorf("MaxPending = %d: Fails the condition that: 0 <= MaxPending", c.MaxPending)
+	default:
+		return nil
+	}
+}
+
+// Params holds a Config that may be changed while messages are being gossiped
+type Params struct {
+	lock   sync.RWMutex
+	config Config
+}
+
+// Get returns the current config
+func (p *Params) Get() Config {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.config
+}
+
+// Set the config to [config]
+func (p *Params) Set(config Config) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.config = config
+}
+
+// Sample returns the indices of [fanout] of [n] peers, chosen uniformly at
+// random. If [fanout] is 0 or at least [n], every index is returned.
+func Sample(n, fanout int) []int {
+	if fanout == 0 || fanout >= n {
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
+	}
+
+	return sampler.NewUniform(n).Sample(fanout)
+}
+
+// Queue holds the messages waiting for the next round of gossip
+type Queue struct {
+	lock    sync.Mutex
+	max     int
+	pending []interface{}
+}
+
+// Initialize the queue to hold at most [maxPending] messages
+func (q *Queue) Initialize(maxPending int) { q.max = maxPending }
+
+// SetMax changes the most messages the queue holds to [maxPending]. If more
+// are pending, the oldest are dropped.
+func (q *Queue) SetMax(maxPending int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.max = maxPending
+	if dropped := len(q.pending) - q.max; dropped > 0 {
+		for i := 0; i < dropped; i++ {
+			q.pending[i] = nil
+		}
+		q.pending = q.pending[dropped:]
+	}
+}
+
+// Push [msg] onto the queue. Returns false if the oldest message had to be
+// dropped to make room.
+func (q *Queue) Push(msg interface{}) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.max == 0 {
+		return false
+	}
+	q.pending = append(q.pending, msg)
+	if len(q.pending) > q.max {
+		q.pending[0] = nil
+		q.pending = q.pending[1:]
+		return false
+	}
+	return true
+}
+
+// PopAll removes and returns the pending messages, oldest first
+func (q *Queue) PopAll() []interface{} {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	pending := q.pending
+	q.pending = nil
+	return pending
+}
+
+// Len returns the number of pending messages
+func (q *Queue) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return len(q.pending)
+}
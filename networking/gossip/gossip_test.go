@@ -0,0 +1,85 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gossip
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigValid(t *testing.T) {
+	if err := (Config{Fanout: 10, Frequency: time.Second, MaxPending: 5}).Valid(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (Config{Fanout: -1}).Valid(); err == nil {
+		t.Fatalf("Should have errored due to a negative fanout")
+	}
+	if err := (Config{Frequency: -time.Second}).Valid(); err == nil {
+		t.Fatalf("Should have errored due to a negative frequency")
+	}
+}
+
+func TestSample(t *testing.T) {
+	if indices := Sample(5, 0); len(indices) != 5 {
+		t.Fatalf("A fanout of 0 should select every peer")
+	}
+	if indices := Sample(5, 10); len(indices) != 5 {
+		t.Fatalf("A fanout larger than the number of peers should select every peer")
+	}
+
+	indices := Sample(10, 3)
+	if len(indices) != 3 {
+		t.Fatalf("Expected 3 peers but got %d", len(indices))
+	}
+	seen := map[int]bool{}
+	for _, i := range indices {
+		if i < 0 || i >= 10 {
+			t.Fatalf("Index %d is out of range", i)
+		}
+		if seen[i] {
+			t.Fatalf("Index %d was sampled twice", i)
+		}
+		seen[i] = true
+	}
+}
+
+func TestQueue(t *testing.T) {
+	q := Queue{}
+	q.Initialize(2)
+
+	if !q.Push(1) || !q.Push(2) {
+		t.Fatalf("Shouldn't have dropped a message")
+	}
+	if q.Push(3) {
+		t.Fatalf("Should have dropped the oldest message")
+	}
+	if pending := q.PopAll(); len(pending) != 2 || pending[0] != 2 || pending[1] != 3 {
+		t.Fatalf("Wrong pending messages: %v", pending)
+	}
+	if q.Len() != 0 {
+		t.Fatalf("Queue should be empty")
+	}
+
+	q.Initialize(0)
+	if q.Push(1) || q.Len() != 0 {
+		t.Fatalf("A queue without room shouldn't hold messages")
+	}
+}
+
+func TestQueueSetMax(t *testing.T) {
+	q := Queue{}
+	q.Initialize(3)
+	q.Push(1)
+	q.Push(2)
+	q.Push(3)
+
+	q.SetMax(1)
+	if pending := q.PopAll(); len(pending) != 1 || pending[0] != 3 {
+		t.Fatalf("Shrinking the queue should have kept only the newest message but kept %v", pending)
+	}
+	q.SetMax(2)
+	if !q.Push(4) || !q.Push(5) {
+		t.Fatalf("Growing the queue should have made room")
+	}
+}
@@ -13,6 +13,7 @@ package networking
 // void version(msg_t *, msgnetwork_conn_t *, void *);
 // void getPeerList(msg_t *, msgnetwork_conn_t *, void *);
 // void peerList(msg_t *, msgnetwork_conn_t *, void *);
+// void peerMetadata(msg_t *, msgnetwork_conn_t *, void *);
 import "C"
 
 import (
@@ -28,12 +29,15 @@ import (
 	"github.com/ava-labs/salticidae-go"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/networking/gossip"
+	"github.com/ava-labs/gecko/networking/limiter"
+	"github.com/ava-labs/gecko/networking/peers"
 	"github.com/ava-labs/gecko/snow/networking"
 	"github.com/ava-labs/gecko/snow/validators"
 	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/logging"
-	"github.com/ava-labs/gecko/utils/random"
+	"github.com/ava-labs/gecko/utils/sampler"
 	"github.com/ava-labs/gecko/utils/timer"
 )
 
@@ -59,10 +63,10 @@ const (
 	CurrentVersion = "avalanche/0.0.1"
 	// MaxClockDifference allowed between connected nodes.
 	MaxClockDifference = time.Minute
-	// PeerListGossipSpacing is the amount of time to wait between pushing this
-	// node's peer list to other nodes.
+	// PeerListGossipSpacing is the default amount of time to wait between
+	// pushing this node's peer list to other nodes.
 	PeerListGossipSpacing = time.Minute
-	// PeerListGossipSize is the number of peers to gossip each period.
+	// PeerListGossipSize is the default number of peers to gossip each period.
 	PeerListGossipSize = 100
 	// PeerListStakerGossipFraction calculates the fraction of stakers that are
 	// gossiped to. If set to 1, then only stakers will be gossiped to.
@@ -70,6 +74,19 @@ const (
 	// GetVersionTimeout is the amount of time to wait before sending a
 	// getVersion message to a partially connected peer
 	GetVersionTimeout = 2 * time.Second
+	// ParseWarningWindow is how long duplicates of a warning that a message
+	// failed to parse aren't logged for
+	ParseWarningWindow = time.Second
+	// InitialReconnectDelay is how long to wait before first reconnecting to
+	// a peer. Each following attempt waits twice as long, up to the maximum
+	// reconnect delay.
+	InitialReconnectDelay = time.Second
+	// MaxReconnectAttempts is how many times in a row reconnecting to a peer
+	// may fail before it's given up on
+	MaxReconnectAttempts = 100
+	// reconnectFrequency is how often peers are checked for being due to be
+	// reconnected to
+	reconnectFrequency = time.Second
 )
 
 // Manager is the struct that will be accessed on event calls
@@ -78,7 +95,8 @@ var (
 )
 
 var (
-	errDSValidators = errors.New("couldn't get validator set of default subnet")
+	errDSValidators  = errors.New("couldn't get validator set of default subnet")
+	errGossipToggled = errors.New("periodic gossip can only be turned on or off by restarting the node")
 )
 
 // Handshake handles the authentication of new peers. Only valid stakers
@@ -95,15 +113,27 @@ type Handshake struct {
 	net           salticidae.PeerNetwork
 	enableStaking bool // Should only be false for local tests
 
+	inbound limiter.Inbound // Inbound connections that are currently open, and their limits
+
+	metadata  peers.Metadata // What this node advertises about itself
+	startTime time.Time
+	peerInfo  peers.Store // What connected peers have advertised about themselves
+	peerDB    *peers.DB   // Peers to reconnect to after a restart
+
+	reconnects  peers.Backoff // Peers to reconnect to, and when
+	reconnector *timer.Repeater
+
 	clock       timer.Clock
 	pending     AddrCert // Connections that I haven't gotten version messages from
 	connections AddrCert // Connections that I think are connected
 
 	versionTimeout   timer.TimeoutManager
+	peerListGossip   gossip.Params
 	peerListGossiper *timer.Repeater
 
 	awaitingLock sync.Mutex
 	awaiting     []*networking.AwaitingConnections
+	connectors   []validators.Connector
 }
 
 // Initialize to the c networking library. This should only be done once during
@@ -117,6 +147,11 @@ func (nm *Handshake) Initialize(
 	registerer prometheus.Registerer,
 	enableStaking bool,
 	networkID uint32,
+	limits limiter.Config,
+	metadata peers.Metadata,
+	peerDB *peers.DB,
+	peerListGossip gossip.Config,
+	maxReconnectDelay time.Duration,
 ) {
 	log.AssertTrue(nm.net == nil, "Should only register network handlers once")
 	nm.log = log
@@ -126,6 +161,12 @@ func (nm *Handshake) Initialize(
 	nm.net = peerNet
 	nm.enableStaking = enableStaking
 	nm.networkID = networkID
+	nm.inbound.Initialize(limits)
+	nm.metadata = metadata
+	nm.peerDB = peerDB
+	nm.peerListGossip.Set(peerListGossip)
+	nm.reconnects.Initialize(InitialReconnectDelay, maxReconnectDelay, MaxReconnectAttempts)
+	nm.startTime = nm.clock.Time()
 
 	net := peerNet.AsMsgNetwork()
 
@@ -138,13 +179,19 @@ func (nm *Handshake) Initialize(
 	net.RegHandler(Version, salticidae.MsgNetworkMsgCallback(C.version), nil)
 	net.RegHandler(GetPeerList, salticidae.MsgNetworkMsgCallback(C.getPeerList), nil)
 	net.RegHandler(PeerList, salticidae.MsgNetworkMsgCallback(C.peerList), nil)
+	net.RegHandler(PeerMetadata, salticidae.MsgNetworkMsgCallback(C.peerMetadata), nil)
 
 	nm.handshakeMetrics.Initialize(nm.log, registerer)
 
 	nm.versionTimeout.Initialize(GetVersionTimeout)
 	go nm.log.RecoverAndPanic(nm.versionTimeout.Dispatch)
-	nm.peerListGossiper = timer.NewRepeater(nm.gossipPeerList, PeerListGossipSpacing)
-	go nm.log.RecoverAndPanic(nm.peerListGossiper.Dispatch)
+	// Peer lists are always sent on connection, so periodic gossip is optional
+	if peerListGossip.Frequency > 0 {
+		nm.peerListGossiper = timer.NewRepeater(nm.gossipPeerList, peerListGossip.Frequency)
+		go nm.log.RecoverAndPanic(nm.peerListGossiper.Dispatch)
+	}
+	nm.reconnector = timer.NewRepeater(nm.reconnect, reconnectFrequency)
+	go nm.log.RecoverAndPanic(nm.reconnector.Dispatch)
 }
 
 // AwaitConnections ...
@@ -163,6 +210,18 @@ func (nm *Handshake) AwaitConnections(awaiting *networking.AwaitingConnections)
 	}
 }
 
+// RegisterConnector notifies [connector] of the peers that are connected now
+// and of every peer that connects or disconnects from now on
+func (nm *Handshake) RegisterConnector(connector validators.Connector) {
+	nm.awaitingLock.Lock()
+	defer nm.awaitingLock.Unlock()
+
+	for _, cert := range nm.connections.IDs().List() {
+		connector.Connected(cert)
+	}
+	nm.connectors = append(nm.connectors, connector)
+}
+
 func (nm *Handshake) gossipPeerList() {
 	stakers := []ids.ShortID{}
 	nonStakers := []ids.ShortID{}
@@ -174,24 +233,25 @@ func (nm *Handshake) gossipPeerList() {
 		}
 	}
 
-	numStakersToSend := (PeerListGossipSize + PeerListStakerGossipFraction - 1) / PeerListStakerGossipFraction
+	gossipSize := nm.peerListGossip.Get().Fanout
+	if gossipSize == 0 {
+		gossipSize = len(stakers) + len(nonStakers)
+	}
+	numStakersToSend := (gossipSize + PeerListStakerGossipFraction - 1) / PeerListStakerGossipFraction
 	if len(stakers) < numStakersToSend {
 		numStakersToSend = len(stakers)
 	}
-	numNonStakersToSend := PeerListGossipSize - numStakersToSend
+	numNonStakersToSend := gossipSize - numStakersToSend
 	if len(nonStakers) < numNonStakersToSend {
 		numNonStakersToSend = len(nonStakers)
 	}
 
 	idsToSend := []ids.ShortID{}
-	sampler := random.Uniform{N: len(stakers)}
-	for i := 0; i < numStakersToSend; i++ {
-		idsToSend = append(idsToSend, stakers[sampler.Sample()])
+	for _, i := range sampler.NewUniform(len(stakers)).Sample(numStakersToSend) {
+		idsToSend = append(idsToSend, stakers[i])
 	}
-	sampler.N = len(nonStakers)
-	sampler.Replace()
-	for i := 0; i < numNonStakersToSend; i++ {
-		idsToSend = append(idsToSend, nonStakers[sampler.Sample()])
+	for _, i := range sampler.NewUniform(len(nonStakers)).Sample(numNonStakersToSend) {
+		idsToSend = append(idsToSend, nonStakers[i])
 	}
 
 	ips := []salticidae.NetAddr{}
@@ -208,10 +268,77 @@ func (nm *Handshake) gossipPeerList() {
 // connected to this node.
 func (nm *Handshake) Connections() Connections { return &nm.connections }
 
+// PeerInfo returns the metadata that connected peers have advertised
+func (nm *Handshake) PeerInfo() []peers.Info { return nm.peerInfo.Peers() }
+
+// Tracks returns true if [nodeID] may be interested in the chains of
+// [subnetID], based on the subnets it advertised
+func (nm *Handshake) Tracks(nodeID ids.ShortID, subnetID ids.ID) bool {
+	return nm.peerInfo.Tracks(nodeID, subnetID)
+}
+
+// SetConnectionLimits replaces the limits on inbound connections. Connections
+// that are already open aren't closed, even if they exceed the new limits.
+func (nm *Handshake) SetConnectionLimits(limits limiter.Config) { nm.inbound.SetConfig(limits) }
+
+// Inbound returns the open inbound connections and their limits, so that
+// connections relayed to the peer network can be counted under the addresses
+// they came from
+func (nm *Handshake) Inbound() *limiter.Inbound { return &nm.inbound }
+
+// SetPeerListGossip changes how peer lists are gossiped. Periodic gossip can't
+// be turned on or off without restarting the node.
+func (nm *Handshake) SetPeerListGossip(peerListGossip gossip.Config) error {
+	if (peerListGossip.Frequency > 0) != (nm.peerListGossiper != nil) {
+		return errGossipToggled
+	}
+	nm.peerListGossip.Set(peerListGossip)
+	if nm.peerListGossiper != nil {
+		nm.peerListGossiper.SetFrequency(peerListGossip.Frequency)
+	}
+	return nil
+}
+
+// Reconnect keeps attempting to connect to [nodeID] at [ip], backing off
+// after each failed attempt, until a handshake with it completes
+func (nm *Handshake) Reconnect(nodeID ids.ShortID, ip utils.IPDesc) {
+	nm.reconnects.Disconnected(nodeID, ip)
+}
+
+// SetMaxReconnectDelay changes the longest that reconnecting to a peer waits
+func (nm *Handshake) SetMaxReconnectDelay(maxReconnectDelay time.Duration) {
+	nm.reconnects.SetMaxDelay(maxReconnectDelay)
+}
+
+// reconnect to the disconnected peers whose next attempt is due
+func (nm *Handshake) reconnect() {
+	for _, target := range nm.reconnects.Due() {
+		if nm.connections.ContainsID(target.NodeID) {
+			nm.reconnects.Connected(target.NodeID)
+			continue
+		}
+		addr := toAddr(target.IP, false)
+		switch {
+		case nm.connections.ContainsIP(addr):
+			nm.reconnects.Connected(target.NodeID)
+		case !nm.pending.ContainsIP(addr):
+			nm.log.Debug("Reconnecting to %s at %s", target.NodeID, target.IP)
+			nm.numReconnects.Inc()
+			nm.net.AddPeer(addr)
+		}
+		addr.Free()
+	}
+}
+
 // Shutdown the network
 func (nm *Handshake) Shutdown() {
 	nm.versionTimeout.Stop()
-	nm.peerListGossiper.Stop()
+	if nm.peerListGossiper != nil {
+		nm.peerListGossiper.Stop()
+	}
+	if nm.reconnector != nil {
+		nm.reconnector.Stop()
+	}
 }
 
 // SendGetVersion to the requested peer
@@ -267,6 +394,24 @@ func (nm *Handshake) SendPeerList(addrs ...salticidae.NetAddr) error {
 	return nil
 }
 
+// SendMetadata to the requested peer
+func (nm *Handshake) SendMetadata(addr salticidae.NetAddr) error {
+	metadata := nm.metadata
+	metadata.Uptime = nm.clock.Time().Sub(nm.startTime)
+	metadataBytes, err := metadata.Marshal()
+	if err != nil {
+		return fmt.Errorf("marshalling metadata failed due to %w", err)
+	}
+
+	build := Builder{}
+	msg, err := build.PeerMetadata(metadataBytes)
+	if err != nil {
+		return fmt.Errorf("packing PeerMetadata failed due to %w", err)
+	}
+	nm.send(msg, addr)
+	return nil
+}
+
 func (nm *Handshake) send(msg Msg, addrs ...salticidae.NetAddr) {
 	ds := msg.DataStream()
 	defer ds.Free()
@@ -284,10 +429,29 @@ func (nm *Handshake) send(msg Msg, addrs ...salticidae.NetAddr) {
 	}
 }
 
-// checkPeerCertificate of a new inbound connection
+// checkPeerCertificate of a new inbound connection. Inbound connections that
+// would exceed the connection limits are refused before any handshake state is
+// allocated for them.
 //export checkPeerCertificate
-func checkPeerCertificate(_ *C.struct_msgnetwork_conn_t, connected C.bool, _ unsafe.Pointer) C.bool {
-	return connected
+func checkPeerCertificate(_conn *C.struct_msgnetwork_conn_t, connected C.bool, _ unsafe.Pointer) C.bool {
+	conn := salticidae.MsgNetworkConnFromC(salticidae.CMsgNetworkConn(_conn))
+	if conn.GetMode() != salticidae.CONN_MODE_PASSIVE {
+		return connected // Only inbound connections are limited
+	}
+
+	addr := conn.GetAddr()
+	ip := toIPDesc(addr)
+	if !connected {
+		HandshakeNet.inbound.Remove(ip.String())
+		return connected
+	}
+
+	if !HandshakeNet.inbound.Add(ip.IP.String(), ip.String()) {
+		HandshakeNet.log.Debug("Refusing inbound connection from %s as it would exceed the connection limits", ip)
+		HandshakeNet.numRefusedConnections.Inc()
+		return false
+	}
+	return true
 }
 
 // peerHandler notifies a change to the set of connected peers
@@ -316,10 +480,12 @@ func peerHandler(_conn *C.struct_peernetwork_conn_t, connected C.bool, _ unsafe.
 
 		HandshakeNet.pending.RemoveIP(addr)
 		HandshakeNet.connections.RemoveIP(addr)
+		HandshakeNet.peerInfo.Remove(cert)
 
 		HandshakeNet.numPeers.Set(float64(HandshakeNet.connections.Len()))
 
 		HandshakeNet.log.Warn("Disconnected from %s", ip)
+		HandshakeNet.reconnects.Disconnected(cert, ip)
 
 		HandshakeNet.awaitingLock.Lock()
 		defer HandshakeNet.awaitingLock.Unlock()
@@ -327,6 +493,9 @@ func peerHandler(_conn *C.struct_peernetwork_conn_t, connected C.bool, _ unsafe.
 		for _, awaiting := range HandshakeNet.awaiting {
 			awaiting.Remove(cert)
 		}
+		for _, connector := range HandshakeNet.connectors {
+			connector.Disconnected(cert)
+		}
 
 		return
 	}
@@ -344,12 +513,24 @@ func peerHandler(_conn *C.struct_peernetwork_conn_t, connected C.bool, _ unsafe.
 	HandshakeNet.pending.Add(addr, cert)
 
 	certID := cert.LongID()
+	deadline := HandshakeNet.clock.Time().Add(HandshakeNet.inbound.Config().HandshakeTimeout)
 	handler := new(func())
 	*handler = func() {
-		if HandshakeNet.pending.ContainsIP(addr) {
-			HandshakeNet.SendGetVersion(addr)
-			HandshakeNet.versionTimeout.Put(certID, *handler)
+		if !HandshakeNet.pending.ContainsIP(addr) {
+			return
+		}
+		if HandshakeNet.clock.Time().After(deadline) {
+			HandshakeNet.log.Debug("Disconnecting from %s as it didn't complete the handshake in time", ip)
+			HandshakeNet.numHandshakeTimeouts.Inc()
+			if err := HandshakeNet.peerDB.Unreachable(cert); err != nil {
+				HandshakeNet.log.Warn("Failed to update the peer database due to %s", err)
+			}
+			HandshakeNet.reconnects.Disconnected(cert, ip)
+			HandshakeNet.net.DelPeer(addr)
+			return
 		}
+		HandshakeNet.SendGetVersion(addr)
+		HandshakeNet.versionTimeout.Put(certID, *handler)
 	}
 	(*handler)()
 }
@@ -428,7 +609,7 @@ func version(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.P
 	build := Builder{}
 	pMsg, err := build.Parse(Version, msg.GetPayloadByMove())
 	if err != nil {
-		HandshakeNet.log.Warn("Failed to parse Version message")
+		HandshakeNet.log.WarnEvery(ParseWarningWindow, "Failed to parse Version message")
 
 		HandshakeNet.net.DelPeer(addr)
 		return
@@ -449,7 +630,8 @@ func version(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.P
 		return
 	}
 
-	if peerVersion := pMsg.Get(VersionStr).(string); !checkCompatibility(CurrentVersion, peerVersion) {
+	peerVersion := pMsg.Get(VersionStr).(string)
+	if !checkCompatibility(CurrentVersion, peerVersion) {
 		HandshakeNet.log.Warn("Bad version")
 
 		HandshakeNet.net.DelPeer(addr)
@@ -460,6 +642,13 @@ func version(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.P
 
 	HandshakeNet.SendPeerList(addr)
 	HandshakeNet.connections.Add(addr, cert)
+	if err := HandshakeNet.SendMetadata(addr); err != nil {
+		HandshakeNet.log.Warn("Failed to send metadata to %s due to %s", toIPDesc(addr), err)
+	}
+	if err := HandshakeNet.peerDB.Connected(cert, toIPDesc(addr), peerVersion); err != nil {
+		HandshakeNet.log.Warn("Failed to update the peer database due to %s", err)
+	}
+	HandshakeNet.reconnects.Connected(cert)
 
 	HandshakeNet.versionTimeout.Remove(cert.LongID())
 
@@ -472,6 +661,9 @@ func version(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.P
 	HandshakeNet.awaitingLock.Lock()
 	defer HandshakeNet.awaitingLock.Unlock()
 
+	for _, connector := range HandshakeNet.connectors {
+		connector.Connected(cert)
+	}
 	for i := 0; i < len(HandshakeNet.awaiting); i++ {
 		awaiting := HandshakeNet.awaiting[i]
 		awaiting.Add(cert)
@@ -513,7 +705,7 @@ func peerList(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.
 	build := Builder{}
 	pMsg, err := build.Parse(PeerList, msg.GetPayloadByMove())
 	if err != nil {
-		HandshakeNet.log.Warn("Failed to parse PeerList message due to %s", err)
+		HandshakeNet.log.WarnEvery(ParseWarningWindow, "Failed to parse PeerList message due to %s", err)
 		// TODO: What should we do here?
 		return
 	}
@@ -535,6 +727,45 @@ func peerList(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.
 	}
 }
 
+// peerMetadata handles the recept of a peerMetadata message
+//export peerMetadata
+func peerMetadata(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	conn := salticidae.PeerNetworkConnFromC(salticidae.CPeerNetworkConn(_conn))
+	addr := conn.GetPeerAddr(false)
+	defer addr.Free()
+	if addr.IsNull() {
+		HandshakeNet.log.Warn("PeerMetadata sent from unknown peer")
+		return
+	}
+
+	cert, exists := HandshakeNet.connections.GetID(addr)
+	if !exists {
+		HandshakeNet.log.Debug("PeerMetadata sent from %s before finishing the handshake", toIPDesc(addr))
+		return
+	}
+
+	msg := salticidae.MsgFromC(salticidae.CMsg(_msg))
+	build := Builder{}
+	pMsg, err := build.Parse(PeerMetadata, msg.GetPayloadByMove())
+	if err != nil {
+		HandshakeNet.log.WarnEvery(ParseWarningWindow, "Failed to parse PeerMetadata message due to %s", err)
+		return
+	}
+
+	metadata := peers.Metadata{}
+	if err := metadata.Unmarshal(pMsg.Get(Bytes).([]byte)); err != nil {
+		HandshakeNet.log.Warn("Failed to parse metadata from %s due to %s", toIPDesc(addr), err)
+		return
+	}
+
+	HandshakeNet.peerInfo.Put(peers.Info{
+		Metadata: metadata,
+		NodeID:   cert,
+		IP:       toIPDesc(addr),
+		Received: HandshakeNet.clock.Time(),
+	})
+}
+
 func getMsgCert(_conn *C.struct_msgnetwork_conn_t) ids.ShortID {
 	conn := salticidae.MsgNetworkConnFromC(salticidae.CMsgNetworkConn(_conn))
 	return getCert(conn.GetPeerCert())
@@ -15,7 +15,9 @@ type handshakeMetrics struct {
 	numGetVersionSent, numGetVersionReceived,
 	numVersionSent, numVersionReceived,
 	numGetPeerlistSent, numGetPeerlistReceived,
-	numPeerlistSent, numPeerlistReceived prometheus.Counter
+	numPeerlistSent, numPeerlistReceived,
+	numRefusedConnections, numHandshakeTimeouts,
+	numReconnects prometheus.Counter
 }
 
 func (hm *handshakeMetrics) Initialize(log logging.Logger, registerer prometheus.Registerer) {
@@ -73,6 +75,24 @@ func (hm *handshakeMetrics) Initialize(log logging.Logger, registerer prometheus
 			Name:      "peerlist_received",
 			Help:      "Number of peerlist messages received",
 		})
+	hm.numRefusedConnections = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "refused_connections",
+			Help:      "Number of inbound connections refused due to connection limits",
+		})
+	hm.numHandshakeTimeouts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "handshake_timeouts",
+			Help:      "Number of peers disconnected for not completing the handshake in time",
+		})
+	hm.numReconnects = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "reconnects",
+			Help:      "Number of attempts to reconnect to disconnected peers",
+		})
 
 	if err := registerer.Register(hm.numPeers); err != nil {
 		log.Error("Failed to register peers statistics due to %s", err)
@@ -101,4 +121,13 @@ func (hm *handshakeMetrics) Initialize(log logging.Logger, registerer prometheus
 	if err := registerer.Register(hm.numPeerlistReceived); err != nil {
 		log.Error("Failed to register peerlist_received statistics due to %s", err)
 	}
+	if err := registerer.Register(hm.numRefusedConnections); err != nil {
+		log.Error("Failed to register refused_connections statistics due to %s", err)
+	}
+	if err := registerer.Register(hm.numHandshakeTimeouts); err != nil {
+		log.Error("Failed to register handshake_timeouts statistics due to %s", err)
+	}
+	if err := registerer.Register(hm.numReconnects); err != nil {
+		log.Error("Failed to register reconnects statistics due to %s", err)
+	}
 }
@@ -0,0 +1,137 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package limiter
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	errNonPositiveLimit = errors.New("connection limits must be positive")
+	errNonPositiveTime  = errors.New("handshake timeout must be positive")
+)
+
+// Config bounds the resources that unauthenticated peers may consume
+type Config struct {
+	// MaxInbound is the most inbound connections that may be open at once
+	MaxInbound int
+	// MaxInboundPerIP is the most inbound connections that may be open at once
+	// from a single IP
+	MaxInboundPerIP int
+	// HandshakeTimeout is how long a peer has to complete the handshake before
+	// it is disconnected
+	HandshakeTimeout time.Duration
+}
+
+// Valid returns nil if the config describes usable limits
+func (c Config) Valid() error {
+	switch {
+	case c.MaxInbound <= 0 || c.MaxInboundPerIP <= 0:
+		return errNonPositiveLimit
+	case c.HandshakeTimeout <= 0:
+		return errNonPositiveTime
+	default:
+		return nil
+	}
+}
+
+// Inbound tracks the open inbound connections and rejects connections that
+// would exceed the configured limits
+type Inbound struct {
+	lock   sync.Mutex
+	config Config
+	// conns maps a connection's remote address to its IP
+	conns map[string]string
+	// perIP is the number of open connections from each IP
+	perIP map[string]int
+	// exempt are the remote addresses of connections that were counted
+	// against the limits of another address, such as relayed connections
+	exempt map[string]bool
+}
+
+// Initialize the limiter with [config]
+func (l *Inbound) Initialize(config Config) {
+	l.config = config
+	l.conns = make(map[string]string)
+	l.perIP = make(map[string]int)
+	l.exempt = make(map[string]bool)
+}
+
+// SetConfig replaces the limits with [config]. Connections that are already
+// open aren't closed, even if they exceed the new limits.
+func (l *Inbound) SetConfig(config Config) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.config = config
+}
+
+// Config returns the current limits
+func (l *Inbound) Config() Config {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.config
+}
+
+// Add attempts to register the connection from [ip] with remote address
+// [addr]. Returns false if the connection should be rejected.
+func (l *Inbound) Add(ip, addr string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, exists := l.conns[addr]; exists || l.exempt[addr] {
+		return true
+	}
+	if len(l.conns) >= l.config.MaxInbound || l.perIP[ip] >= l.config.MaxInboundPerIP {
+		return false
+	}
+	l.conns[addr] = ip
+	l.perIP[ip]++
+	return true
+}
+
+// Remove the connection with remote address [addr], if it was registered
+func (l *Inbound) Remove(addr string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	ip, exists := l.conns[addr]
+	if !exists {
+		return
+	}
+	delete(l.conns, addr)
+	if l.perIP[ip] <= 1 {
+		delete(l.perIP, ip)
+	} else {
+		l.perIP[ip]--
+	}
+}
+
+// Exempt the connection with remote address [addr] from the limits, because
+// it was already counted against them under another address
+func (l *Inbound) Exempt(addr string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.exempt[addr] = true
+}
+
+// Unexempt the connection with remote address [addr]
+func (l *Inbound) Unexempt(addr string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.exempt, addr)
+}
+
+// Len returns the number of open inbound connections
+func (l *Inbound) Len() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return len(l.conns)
+}
@@ -0,0 +1,121 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package limiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInboundPerIPLimit(t *testing.T) {
+	l := Inbound{}
+	l.Initialize(Config{
+		MaxInbound:       10,
+		MaxInboundPerIP:  2,
+		HandshakeTimeout: time.Second,
+	})
+
+	if !l.Add("1.2.3.4", "1.2.3.4:1") || !l.Add("1.2.3.4", "1.2.3.4:2") {
+		t.Fatalf("Should have allowed connections under the per IP limit")
+	}
+	if l.Add("1.2.3.4", "1.2.3.4:3") {
+		t.Fatalf("Should have rejected a connection over the per IP limit")
+	}
+	if !l.Add("5.6.7.8", "5.6.7.8:1") {
+		t.Fatalf("Should have allowed a connection from a different IP")
+	}
+
+	l.Remove("1.2.3.4:1")
+	l.Remove("1.2.3.4:1") // Removing twice should be a no-op
+	l.Remove("1.2.3.4:3") // Rejected connections were never registered
+	if l.Len() != 2 {
+		t.Fatalf("Expected 2 connections but found %d", l.Len())
+	}
+	if !l.Add("1.2.3.4", "1.2.3.4:3") {
+		t.Fatalf("Should have allowed a connection after one was closed")
+	}
+}
+
+func TestInboundExempt(t *testing.T) {
+	l := Inbound{}
+	l.Initialize(Config{
+		MaxInbound:       1,
+		MaxInboundPerIP:  1,
+		HandshakeTimeout: time.Second,
+	})
+
+	if !l.Add("1.2.3.4", "1.2.3.4:1") {
+		t.Fatalf("Should have allowed a connection under the limits")
+	}
+	l.Exempt("127.0.0.1:1")
+	if !l.Add("127.0.0.1", "127.0.0.1:1") {
+		t.Fatalf("Should have allowed an exempt connection over the limits")
+	}
+	if l.Len() != 1 {
+		t.Fatalf("Exempt connections shouldn't be counted, found %d", l.Len())
+	}
+
+	l.Unexempt("127.0.0.1:1")
+	if l.Add("127.0.0.1", "127.0.0.1:1") {
+		t.Fatalf("Should have rejected a connection that is no longer exempt")
+	}
+}
+
+func TestInboundTotalLimit(t *testing.T) {
+	l := Inbound{}
+	l.Initialize(Config{
+		MaxInbound:       1,
+		MaxInboundPerIP:  1,
+		HandshakeTimeout: time.Second,
+	})
+
+	if !l.Add("1.2.3.4", "1.2.3.4:1") {
+		t.Fatalf("Should have allowed the first connection")
+	}
+	if !l.Add("1.2.3.4", "1.2.3.4:1") {
+		t.Fatalf("Should have allowed re-adding a registered connection")
+	}
+	if l.Add("5.6.7.8", "5.6.7.8:1") {
+		t.Fatalf("Should have rejected a connection over the total limit")
+	}
+}
+
+func TestInboundSetConfig(t *testing.T) {
+	l := Inbound{}
+	l.Initialize(Config{
+		MaxInbound:       2,
+		MaxInboundPerIP:  2,
+		HandshakeTimeout: time.Second,
+	})
+
+	if !l.Add("1.2.3.4", "1.2.3.4:1") || !l.Add("1.2.3.4", "1.2.3.4:2") {
+		t.Fatalf("Should have allowed connections under the limits")
+	}
+	l.SetConfig(Config{
+		MaxInbound:       3,
+		MaxInboundPerIP:  1,
+		HandshakeTimeout: time.Minute,
+	})
+	if l.Len() != 2 {
+		t.Fatalf("Open connections shouldn't be closed when the limits change")
+	}
+	if l.Add("1.2.3.4", "1.2.3.4:3") {
+		t.Fatalf("Should have rejected a connection over the new per IP limit")
+	}
+	if !l.Add("5.6.7.8", "5.6.7.8:1") {
+		t.Fatalf("Should have allowed a connection under the new total limit")
+	}
+	if timeout := l.Config().HandshakeTimeout; timeout != time.Minute {
+		t.Fatalf("Expected the new handshake timeout but got %s", timeout)
+	}
+}
+
+func TestConfigValid(t *testing.T) {
+	if err := (Config{MaxInbound: 1, MaxInboundPerIP: 1}).Valid(); err == nil {
+		t.Fatalf("Should have errored due to a missing handshake timeout")
+	}
+	if err := (Config{MaxInboundPerIP: 1, HandshakeTimeout: time.Second}).Valid(); err == nil {
+		t.Fatalf("Should have errored due to a missing total limit")
+	}
+}
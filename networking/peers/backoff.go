@@ -0,0 +1,136 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peers
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// Target is a peer that should be reconnected to
+type Target struct {
+	NodeID ids.ShortID
+	IP     utils.IPDesc
+}
+
+// attempt is when a peer should next be reconnected to, and how many times in
+// a row reconnecting to it has failed
+type attempt struct {
+	ip       utils.IPDesc
+	failures int
+	next     time.Time
+}
+
+// Backoff schedules attempts to reconnect to peers. Each consecutive attempt
+// waits twice as long as the last, up to a maximum delay, so unreachable
+// peers are retried quickly at first without being retried constantly.
+type Backoff struct {
+	lock  sync.Mutex
+	clock timer.Clock
+
+	initialDelay, maxDelay time.Duration
+	// maxAttempts is how many times in a row a peer is reconnected to before
+	// it is given up on
+	maxAttempts int
+
+	// Key: Node ID
+	peers map[[20]byte]*attempt
+}
+
+// Initialize the backoff. The first attempt to reconnect to a peer waits
+// [initialDelay], and no attempt waits longer than [maxDelay].
+func (b *Backoff) Initialize(initialDelay, maxDelay time.Duration, maxAttempts int) {
+	b.initialDelay = initialDelay
+	b.maxDelay = maxDelay
+	b.maxAttempts = maxAttempts
+	b.peers = make(map[[20]byte]*attempt)
+}
+
+// SetMaxDelay changes the longest that an attempt waits. Attempts that are
+// already scheduled aren't moved.
+func (b *Backoff) SetMaxDelay(maxDelay time.Duration) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.maxDelay = maxDelay
+}
+
+// Disconnected schedules an attempt to reconnect to [nodeID] at [ip]. If an
+// attempt is already scheduled, it isn't moved.
+func (b *Backoff) Disconnected(nodeID ids.ShortID, ip utils.IPDesc) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	key := nodeID.Key()
+	if _, exists := b.peers[key]; exists {
+		return
+	}
+	b.peers[key] = &attempt{
+		ip:   ip,
+		next: b.clock.Time().Add(b.delay(0)),
+	}
+}
+
+// Connected stops attempting to reconnect to [nodeID]
+func (b *Backoff) Connected(nodeID ids.ShortID) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.peers, nodeID.Key())
+}
+
+// Due returns the peers that should be reconnected to now. Each is assumed to
+// fail until Connected is called, so its next attempt is scheduled after a
+// longer delay. Peers that have failed [maxAttempts] times are given up on.
+func (b *Backoff) Due() []Target {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.clock.Time()
+	due := []Target(nil)
+	for key, a := range b.peers {
+		if now.Before(a.next) {
+			continue
+		}
+		due = append(due, Target{NodeID: ids.NewShortID(key), IP: a.ip})
+
+		a.failures++
+		if b.maxAttempts > 0 && a.failures >= b.maxAttempts {
+			delete(b.peers, key)
+			continue
+		}
+		a.next = now.Add(b.delay(a.failures))
+	}
+	return due
+}
+
+// Len returns the number of peers that are being reconnected to
+func (b *Backoff) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return len(b.peers)
+}
+
+// delay returns how long to wait after [failures] consecutive failures. Up to
+// half the delay is added at random, so peers that disconnected at the same
+// time don't all reconnect at the same time.
+func (b *Backoff) delay(failures int) time.Duration {
+	delay := b.initialDelay
+	for i := 0; i < failures && delay < b.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > b.maxDelay {
+		delay = b.maxDelay
+	}
+	if delay > 0 {
+		delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	}
+	return delay
+}
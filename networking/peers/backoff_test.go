@@ -0,0 +1,73 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peers
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+)
+
+func TestBackoffDelays(t *testing.T) {
+	b := Backoff{}
+	b.Initialize(time.Second, 4*time.Second, 0)
+	start := time.Unix(1000, 0)
+	b.clock.Set(start)
+
+	nodeID := ids.NewShortID([20]byte{1})
+	ip := utils.IPDesc{IP: net.IPv4(1, 2, 3, 4), Port: 9651}
+	b.Disconnected(nodeID, ip)
+
+	// Each attempt waits between the delay and one and a half times the delay,
+	// which doubles until it reaches the maximum
+	now := start
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		b.clock.Set(now.Add(delay - 1))
+		if due := b.Due(); len(due) != 0 {
+			t.Fatalf("Shouldn't have reconnected before waiting %s", delay)
+		}
+		now = now.Add(delay * 3 / 2)
+		b.clock.Set(now)
+		due := b.Due()
+		if len(due) != 1 {
+			t.Fatalf("Should have reconnected after waiting %s", delay*3/2)
+		}
+		if !due[0].NodeID.Equals(nodeID) || !due[0].IP.Equal(ip) {
+			t.Fatalf("Should have reconnected to %s at %s but reconnected to %s at %s", nodeID, ip, due[0].NodeID, due[0].IP)
+		}
+	}
+
+	b.Connected(nodeID)
+	b.clock.Set(now.Add(time.Hour))
+	if due := b.Due(); len(due) != 0 || b.Len() != 0 {
+		t.Fatalf("Shouldn't reconnect to a connected peer")
+	}
+}
+
+func TestBackoffMaxAttempts(t *testing.T) {
+	b := Backoff{}
+	b.Initialize(time.Second, time.Second, 2)
+	now := time.Unix(1000, 0)
+	b.clock.Set(now)
+
+	nodeID := ids.NewShortID([20]byte{1})
+	b.Disconnected(nodeID, utils.IPDesc{IP: net.IPv4(1, 2, 3, 4), Port: 9651})
+	// Disconnecting again doesn't reschedule the attempt
+	b.clock.Set(now.Add(time.Minute))
+	b.Disconnected(nodeID, utils.IPDesc{IP: net.IPv4(1, 2, 3, 4), Port: 9651})
+
+	for i := 0; i < 2; i++ {
+		now = now.Add(time.Minute)
+		b.clock.Set(now)
+		if due := b.Due(); len(due) != 1 {
+			t.Fatalf("Attempt %d should have been due", i)
+		}
+	}
+	if b.Len() != 0 {
+		t.Fatalf("Should have given up after the maximum number of attempts")
+	}
+}
@@ -0,0 +1,188 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peers
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// maxScore caps how much credit a peer can accumulate, so a peer that was
+	// reliable long ago can't outrank recently reliable peers forever
+	maxScore = 100
+
+	// maxVersionLen is the longest version string that is persisted
+	maxVersionLen = 64
+
+	recordLen = 16 + wrappers.ShortLen + wrappers.LongLen + wrappers.IntLen + wrappers.ShortLen + maxVersionLen
+)
+
+// Record is what is persisted about a peer that this node has successfully
+// connected to
+type Record struct {
+	NodeID   ids.ShortID
+	IP       utils.IPDesc
+	LastSeen time.Time
+	// Score is the number of successful handshakes with the peer, capped at
+	// maxScore, less the number of times it was found to be unreachable
+	Score uint32
+	// Version the peer reported in its last handshake. Empty for peers that
+	// were persisted before versions were.
+	Version string
+}
+
+// DB persists the peers this node has connected to, so they can be reconnected
+// to after a restart without relying only on the bootstrap nodes
+type DB struct {
+	lock  sync.Mutex
+	db    database.Database
+	clock timer.Clock
+}
+
+// Initialize the peer store on top of [db]
+func (d *DB) Initialize(db database.Database) { d.db = db }
+
+// Connected records a successful handshake with [nodeID] at [ip], which
+// reported that it's running [version]
+func (d *DB) Connected(nodeID ids.ShortID, ip utils.IPDesc, version string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	record, err := d.get(nodeID)
+	if err != nil {
+		return err
+	}
+	record.NodeID = nodeID
+	record.IP = ip
+	record.LastSeen = d.clock.Time()
+	if len(version) > maxVersionLen {
+		version = version[:maxVersionLen]
+	}
+	record.Version = version
+	if record.Score < maxScore {
+		record.Score++
+	}
+	return d.put(record)
+}
+
+// Unreachable records that a connection to [nodeID] failed. Peers whose score
+// drops to zero are forgotten.
+func (d *DB) Unreachable(nodeID ids.ShortID) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	record, err := d.get(nodeID)
+	if err != nil {
+		return err
+	}
+	if record.Score <= 1 {
+		return d.db.Delete(nodeID.Bytes())
+	}
+	record.Score--
+	return d.put(record)
+}
+
+// Peers returns up to [max] peers that were seen within [maxAge], ordered by
+// descending score and then by how recently they were seen. Peers that haven't
+// been seen within [maxAge] are removed.
+func (d *DB) Peers(max int, maxAge time.Duration) ([]Record, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	cutoff := d.clock.Time().Add(-maxAge)
+	records := []Record(nil)
+	stale := [][]byte(nil)
+
+	it := d.db.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		record, err := unmarshalRecord(it.Key(), it.Value())
+		if err != nil || record.LastSeen.Before(cutoff) {
+			key := make([]byte, len(it.Key()))
+			copy(key, it.Key())
+			stale = append(stale, key)
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+
+	for _, key := range stale {
+		if err := d.db.Delete(key); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Score != records[j].Score {
+			return records[i].Score > records[j].Score
+		}
+		return records[i].LastSeen.After(records[j].LastSeen)
+	})
+	if len(records) > max {
+		records = records[:max]
+	}
+	return records, nil
+}
+
+// get the record of [nodeID], or an empty record if there isn't one
+func (d *DB) get(nodeID ids.ShortID) (Record, error) {
+	value, err := d.db.Get(nodeID.Bytes())
+	if err == database.ErrNotFound {
+		return Record{}, nil
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	return unmarshalRecord(nodeID.Bytes(), value)
+}
+
+func (d *DB) put(record Record) error {
+	p := wrappers.Packer{MaxSize: recordLen}
+	p.PackIP(record.IP)
+	p.PackLong(uint64(record.LastSeen.Unix()))
+	p.PackInt(record.Score)
+	p.PackStr(record.Version)
+	if p.Errored() {
+		return p.Err
+	}
+	return d.db.Put(record.NodeID.Bytes(), p.Bytes)
+}
+
+func unmarshalRecord(key, value []byte) (Record, error) {
+	nodeID, err := ids.ToShortID(key)
+	if err != nil {
+		return Record{}, err
+	}
+	// The IP references the unpacked bytes, which may be reused by an iterator
+	p := wrappers.Packer{Bytes: make([]byte, len(value))}
+	copy(p.Bytes, value)
+	record := Record{
+		NodeID:   nodeID,
+		IP:       p.UnpackIP(),
+		LastSeen: time.Unix(int64(p.UnpackLong()), 0),
+		Score:    p.UnpackInt(),
+	}
+	// Records persisted before versions were end after the score
+	if p.Offset < len(value) {
+		record.Version = p.UnpackStr()
+	}
+	if p.Errored() {
+		return Record{}, p.Err
+	}
+	if p.Offset != len(value) {
+		return Record{}, errTrailingBytes
+	}
+	return record, nil
+}
@@ -0,0 +1,112 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peers
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+func TestDBPeers(t *testing.T) {
+	db := DB{}
+	db.Initialize(memdb.New())
+	db.clock.Set(time.Unix(1000, 0))
+
+	nodeID0 := ids.NewShortID([20]byte{0})
+	nodeID1 := ids.NewShortID([20]byte{1})
+	ip0 := utils.IPDesc{IP: net.IPv4(1, 2, 3, 4), Port: 9651}
+	ip1 := utils.IPDesc{IP: net.IPv4(5, 6, 7, 8), Port: 9651}
+
+	if err := db.Connected(nodeID0, ip0, "avalanche/0.5.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connected(nodeID1, ip1, "avalanche/0.5.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connected(nodeID1, ip1, "avalanche/0.5.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := db.Peers(10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case len(records) != 2:
+		t.Fatalf("Expected 2 peers but got %d", len(records))
+	case !records[0].NodeID.Equals(nodeID1) || records[0].Score != 2:
+		t.Fatalf("The peer with the highest score should be first")
+	case !records[1].IP.Equal(ip0):
+		t.Fatalf("Wrong IP persisted: %s", records[1].IP)
+	case !records[1].LastSeen.Equal(time.Unix(1000, 0)):
+		t.Fatalf("Wrong last seen time persisted: %s", records[1].LastSeen)
+	case records[1].Version != "avalanche/0.5.0":
+		t.Fatalf("Wrong version persisted: %q", records[1].Version)
+	}
+
+	if records, err := db.Peers(1, time.Hour); err != nil {
+		t.Fatal(err)
+	} else if len(records) != 1 {
+		t.Fatalf("Should have returned at most 1 peer")
+	}
+
+	if err := db.Unreachable(nodeID0); err != nil {
+		t.Fatal(err)
+	}
+	if records, err := db.Peers(10, time.Hour); err != nil {
+		t.Fatal(err)
+	} else if len(records) != 1 {
+		t.Fatalf("Should have forgotten the unreachable peer")
+	}
+
+	db.clock.Set(time.Unix(1000, 0).Add(2 * time.Hour))
+	if records, err := db.Peers(10, time.Hour); err != nil {
+		t.Fatal(err)
+	} else if len(records) != 0 {
+		t.Fatalf("Should have pruned the stale peer")
+	}
+}
+
+func TestDBRecordWithoutVersion(t *testing.T) {
+	db := DB{}
+	db.Initialize(memdb.New())
+	db.clock.Set(time.Unix(1000, 0))
+
+	// Records persisted before versions were end after the score
+	nodeID := ids.NewShortID([20]byte{1})
+	ip := utils.IPDesc{IP: net.IPv4(1, 2, 3, 4), Port: 9651}
+	p := wrappers.Packer{MaxSize: recordLen}
+	p.PackIP(ip)
+	p.PackLong(1000)
+	p.PackInt(3)
+	if err := db.db.Put(nodeID.Bytes(), p.Bytes); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := db.Peers(10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 peer but got %d", len(records))
+	}
+	if !records[0].IP.Equal(ip) || records[0].Score != 3 || records[0].Version != "" {
+		t.Fatalf("Wrong record unmarshalled: %+v", records[0])
+	}
+
+	if err := db.Connected(nodeID, ip, "avalanche/0.5.0"); err != nil {
+		t.Fatal(err)
+	}
+	if records, err := db.Peers(10, time.Hour); err != nil {
+		t.Fatal(err)
+	} else if records[0].Score != 4 || records[0].Version != "avalanche/0.5.0" {
+		t.Fatalf("Wrong record after reconnecting: %+v", records[0])
+	}
+}
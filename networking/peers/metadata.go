@@ -0,0 +1,120 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peers
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// maxSubnets is the most tracked subnets a peer may advertise
+	maxSubnets = 1024
+	// maxCapabilities is the most capabilities a peer may advertise
+	maxCapabilities = 64
+	// maxIPs is the most addresses a peer may advertise
+	maxIPs = 16
+)
+
+var (
+	errTooManySubnets      = errors.New("too many tracked subnets")
+	errTooManyCapabilities = errors.New("too many capabilities")
+	errTooManyIPs          = errors.New("too many advertised IPs")
+	errTrailingBytes       = errors.New("metadata has unexpected trailing bytes")
+)
+
+// Metadata that a node advertises about itself to its peers
+type Metadata struct {
+	// NodeVersion is the software version the node is running
+	NodeVersion string
+	// Uptime is how long the node has been running
+	Uptime time.Duration
+	// TrackedSubnets are the subnets whose chains the node validates or syncs.
+	// If empty, the node tracks every subnet.
+	TrackedSubnets []ids.ID
+	// Capabilities are the APIs and optional features the node has enabled
+	Capabilities []string
+	// IPs are the addresses the node accepts peer connections on
+	IPs []utils.IPDesc
+}
+
+// Marshal the metadata into its wire format
+func (m *Metadata) Marshal() ([]byte, error) {
+	if len(m.TrackedSubnets) > maxSubnets {
+		return nil, errTooManySubnets
+	}
+	if len(m.Capabilities) > maxCapabilities {
+		return nil, errTooManyCapabilities
+	}
+	if len(m.IPs) > maxIPs {
+		return nil, errTooManyIPs
+	}
+
+	p := wrappers.Packer{MaxSize: math.MaxInt32}
+	p.PackStr(m.NodeVersion)
+	p.PackLong(uint64(m.Uptime / time.Second))
+	p.PackInt(uint32(len(m.TrackedSubnets)))
+	for _, subnetID := range m.TrackedSubnets {
+		p.PackFixedBytes(subnetID.Bytes())
+	}
+	p.PackInt(uint32(len(m.Capabilities)))
+	for _, capability := range m.Capabilities {
+		p.PackStr(capability)
+	}
+	p.PackInt(uint32(len(m.IPs)))
+	for _, ip := range m.IPs {
+		p.PackIP(ip)
+	}
+	return p.Bytes, p.Err
+}
+
+// Unmarshal the metadata from its wire format
+func (m *Metadata) Unmarshal(b []byte) error {
+	p := wrappers.Packer{Bytes: b}
+	m.NodeVersion = p.UnpackStr()
+	m.Uptime = time.Duration(p.UnpackLong()) * time.Second
+
+	numSubnets := p.UnpackInt()
+	if numSubnets > maxSubnets {
+		return errTooManySubnets
+	}
+	m.TrackedSubnets = nil
+	for i := uint32(0); i < numSubnets && !p.Errored(); i++ {
+		subnetID, err := ids.ToID(p.UnpackFixedBytes(hashing.HashLen))
+		p.Add(err)
+		m.TrackedSubnets = append(m.TrackedSubnets, subnetID)
+	}
+
+	numCapabilities := p.UnpackInt()
+	if numCapabilities > maxCapabilities {
+		return errTooManyCapabilities
+	}
+	m.Capabilities = nil
+	for i := uint32(0); i < numCapabilities && !p.Errored(); i++ {
+		m.Capabilities = append(m.Capabilities, p.UnpackStr())
+	}
+
+	numIPs := p.UnpackInt()
+	if numIPs > maxIPs {
+		return errTooManyIPs
+	}
+	m.IPs = nil
+	for i := uint32(0); i < numIPs && !p.Errored(); i++ {
+		m.IPs = append(m.IPs, p.UnpackIP())
+	}
+
+	if p.Errored() {
+		return p.Err
+	}
+	if p.Offset != len(b) {
+		return errTrailingBytes
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+)
+
+func TestMetadataMarshal(t *testing.T) {
+	metadata := Metadata{
+		NodeVersion:    "avalanche/0.0.1",
+		Uptime:         time.Hour,
+		TrackedSubnets: []ids.ID{ids.Empty, ids.NewID([32]byte{1})},
+		Capabilities:   []string{"admin", "metrics"},
+		IPs: []utils.IPDesc{
+			{IP: net.IPv4(1, 2, 3, 4).To16(), Port: 9651},
+			{IP: net.ParseIP("2001:db8::1"), Port: 9651},
+		},
+	}
+
+	b, err := metadata.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := Metadata{}
+	if err := parsed.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(metadata, parsed) {
+		t.Fatalf("Expected %+v but got %+v", metadata, parsed)
+	}
+
+	if err := parsed.Unmarshal(append(b, 0)); err == nil {
+		t.Fatalf("Should have errored due to trailing bytes")
+	}
+	if err := parsed.Unmarshal(b[:len(b)-1]); err == nil {
+		t.Fatalf("Should have errored due to truncated bytes")
+	}
+}
+
+func TestMetadataUnmarshalTooManySubnets(t *testing.T) {
+	// An empty version, zero uptime, then an enormous subnet count
+	b := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF}
+
+	metadata := Metadata{}
+	if err := metadata.Unmarshal(b); err != errTooManySubnets {
+		t.Fatalf("Should have errored due to too many subnets, got %v", err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := Store{}
+
+	nodeID0 := ids.NewShortID([20]byte{0})
+	nodeID1 := ids.NewShortID([20]byte{1})
+	store.Put(Info{NodeID: nodeID1})
+	store.Put(Info{NodeID: nodeID0})
+	store.Put(Info{NodeID: nodeID0, Metadata: Metadata{NodeVersion: "v2"}})
+
+	peers := store.Peers()
+	switch {
+	case len(peers) != 2:
+		t.Fatalf("Expected 2 peers but got %d", len(peers))
+	case !peers[0].NodeID.Equals(nodeID0) || !peers[1].NodeID.Equals(nodeID1):
+		t.Fatalf("Peers should be sorted by node ID")
+	case peers[0].NodeVersion != "v2":
+		t.Fatalf("Should have replaced the peer's info")
+	}
+
+	store.Remove(nodeID0)
+	if peers := store.Peers(); len(peers) != 1 {
+		t.Fatalf("Should have removed the peer")
+	}
+}
+
+func TestStoreTracks(t *testing.T) {
+	store := Store{}
+
+	nodeID := ids.NewShortID([20]byte{1})
+	subnetID := ids.NewID([32]byte{1})
+	otherSubnetID := ids.NewID([32]byte{2})
+
+	if !store.Tracks(nodeID, subnetID) {
+		t.Fatalf("A peer without metadata should be assumed to track every subnet")
+	}
+
+	store.Put(Info{NodeID: nodeID, Metadata: Metadata{TrackedSubnets: []ids.ID{subnetID}}})
+	if !store.Tracks(nodeID, subnetID) {
+		t.Fatalf("Peer should track the subnet it advertised")
+	}
+	if store.Tracks(nodeID, otherSubnetID) {
+		t.Fatalf("Peer shouldn't track a subnet it didn't advertise")
+	}
+
+	store.Put(Info{NodeID: nodeID, Metadata: Metadata{}})
+	if !store.Tracks(nodeID, otherSubnetID) {
+		t.Fatalf("A peer that advertised no subnets should be assumed to track every subnet")
+	}
+}
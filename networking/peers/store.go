@@ -0,0 +1,83 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peers
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+)
+
+// Info is what is known about a connected peer
+type Info struct {
+	Metadata
+
+	NodeID ids.ShortID
+	IP     utils.IPDesc
+	// Received is when the peer's metadata was received
+	Received time.Time
+}
+
+// Store tracks the metadata advertised by connected peers
+type Store struct {
+	lock  sync.RWMutex
+	peers map[[20]byte]Info
+}
+
+// Put the latest info about a peer
+func (s *Store) Put(info Info) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.peers == nil {
+		s.peers = make(map[[20]byte]Info)
+	}
+	s.peers[info.NodeID.Key()] = info
+}
+
+// Remove the info about [nodeID]
+func (s *Store) Remove(nodeID ids.ShortID) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.peers, nodeID.Key())
+}
+
+// Peers returns the info of every peer, sorted by node ID
+func (s *Store) Peers() []Info {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	peers := make([]Info, 0, len(s.peers))
+	for _, info := range s.peers {
+		peers = append(peers, info)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return bytes.Compare(peers[i].NodeID.Bytes(), peers[j].NodeID.Bytes()) == -1
+	})
+	return peers
+}
+
+// Tracks returns true if [nodeID] may be interested in messages about the
+// chains of [subnetID]. Peers that haven't advertised the subnets they track,
+// or that advertised none, are assumed to track every subnet.
+func (s *Store) Tracks(nodeID ids.ShortID, subnetID ids.ID) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	info, exists := s.peers[nodeID.Key()]
+	if !exists || len(info.TrackedSubnets) == 0 {
+		return true
+	}
+	for _, tracked := range info.TrackedSubnets {
+		if tracked.Equals(subnetID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package relay accepts peer connections on additional addresses and forwards
+// them to the address the peer network listens on. The peer network can only
+// listen on a single address, so this is how a multi-homed or dual-stack node
+// accepts connections on each of its interfaces.
+//
+// Connections that arrive through a relay appear to the peer network to come
+// from the relay's own address, so the relay counts each connection against
+// the inbound connection limits of the address it came from, and exempts the
+// address it forwards it from.
+package relay
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/networking/limiter"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	dialTimeout = 5 * time.Second
+
+	// minAcceptDelay and maxAcceptDelay bound how long the relay waits before
+	// accepting again after accepting failed
+	minAcceptDelay = 5 * time.Millisecond
+	maxAcceptDelay = time.Second
+)
+
+// Relay forwards the connections accepted on one address to another address
+type Relay struct {
+	log      logging.Logger
+	listener net.Listener
+	target   string
+	inbound  *limiter.Inbound
+
+	lock   sync.Mutex
+	closed bool
+	conns  map[net.Conn]struct{}
+	wg     sync.WaitGroup
+}
+
+// New returns a relay that accepts connections on [addr] and forwards them to
+// [target], within the limits of [inbound]. Connections aren't accepted until
+// Serve is called.
+func New(log logging.Logger, addr, target string, inbound *limiter.Inbound) (*Relay, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Relay{
+		log:      log,
+		listener: listener,
+		target:   target,
+		inbound:  inbound,
+		conns:    make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Addr returns the address the relay accepts connections on
+func (r *Relay) Addr() net.Addr { return r.listener.Addr() }
+
+// Serve accepts connections until the relay is closed
+func (r *Relay) Serve() {
+	delay := time.Duration(0)
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			if r.isClosed() {
+				return
+			}
+			// Accepting may keep failing, such as when the node is out of
+			// file descriptors, so back off rather than spin
+			if delay == 0 {
+				delay = minAcceptDelay
+			} else if delay *= 2; delay > maxAcceptDelay {
+				delay = maxAcceptDelay
+			}
+			r.log.Debug("Failed to accept a connection on %s due to %s. Retrying in %s", r.Addr(), err, delay)
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+
+		if !r.track(conn) {
+			conn.Close()
+			return
+		}
+
+		r.wg.Add(1)
+		go r.forward(conn)
+	}
+}
+
+// Close stops accepting connections and closes the connections being forwarded
+func (r *Relay) Close() error {
+	r.lock.Lock()
+	r.closed = true
+	for conn := range r.conns {
+		conn.Close()
+	}
+	r.lock.Unlock()
+
+	err := r.listener.Close()
+	r.wg.Wait()
+	return err
+}
+
+func (r *Relay) forward(conn net.Conn) {
+	defer r.wg.Done()
+	defer r.untrack(conn)
+	defer conn.Close()
+
+	addr := conn.RemoteAddr().String()
+	ip := addr
+	if tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		ip = tcpAddr.IP.String()
+	}
+	if !r.inbound.Add(ip, addr) {
+		r.log.Debug("Refusing relayed connection from %s as it would exceed the connection limits", addr)
+		return
+	}
+	defer r.inbound.Remove(addr)
+
+	target, err := net.DialTimeout("tcp", r.target, dialTimeout)
+	if err != nil {
+		r.log.Debug("Failed to forward a connection from %s to %s due to %s", conn.RemoteAddr(), r.target, err)
+		return
+	}
+	if !r.track(target) {
+		target.Close()
+		return
+	}
+	defer r.untrack(target)
+	defer target.Close()
+
+	// The connection was counted under the address it came from, so it isn't
+	// counted again under the address the peer network sees it come from
+	relayedAddr := target.LocalAddr().String()
+	r.inbound.Exempt(relayedAddr)
+	defer r.inbound.Unexempt(relayedAddr)
+
+	done := make(chan struct{}, 2)
+	go copyAndSignal(target, conn, done)
+	go copyAndSignal(conn, target, done)
+
+	// Once either side hangs up, there is nothing left to relay
+	<-done
+}
+
+func copyAndSignal(dst io.Writer, src io.Reader, done chan<- struct{}) {
+	_, _ = io.Copy(dst, src)
+	done <- struct{}{}
+}
+
+func (r *Relay) isClosed() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.closed
+}
+
+// track returns false if the relay has been closed
+func (r *Relay) track(conn net.Conn) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return false
+	}
+	r.conns[conn] = struct{}{}
+	return true
+}
+
+func (r *Relay) untrack(conn net.Conn) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.conns, conn)
+}
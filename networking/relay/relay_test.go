@@ -0,0 +1,140 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package relay
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/networking/limiter"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func newInbound() *limiter.Inbound {
+	inbound := &limiter.Inbound{}
+	inbound.Initialize(limiter.Config{
+		MaxInbound:       10,
+		MaxInboundPerIP:  1,
+		HandshakeTimeout: time.Second,
+	})
+	return inbound
+}
+
+func TestRelay(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// Echo everything back to the client
+	go func() {
+		conn, err := server.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	relay, err := New(logging.NoLog{}, "127.0.0.1:0", server.Addr().String(), newInbound())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go relay.Serve()
+
+	conn, err := net.Dial("tcp", relay.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	msg := []byte("ping")
+	if _, err := conn.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, len(msg))
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(msg, reply) {
+		t.Fatalf("Expected %q but got %q", msg, reply)
+	}
+
+	if err := relay.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(conn, reply); err == nil {
+		t.Fatalf("Closing the relay should have closed the forwarded connection")
+	}
+	if _, err := net.Dial("tcp", relay.Addr().String()); err == nil {
+		t.Fatalf("Closed relay shouldn't accept connections")
+	}
+}
+
+func TestRelayLimits(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	inbound := newInbound()
+	relay, err := New(logging.NoLog{}, "127.0.0.1:0", server.Addr().String(), inbound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go relay.Serve()
+	defer relay.Close()
+
+	conn, err := net.Dial("tcp", relay.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	relayed := <-accepted
+	defer relayed.Close()
+
+	// Data is only forwarded once the relay has set up the connection
+	if _, err := conn.Write([]byte{0}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(relayed, make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The connection is counted under the client's IP, so the peer network
+	// shouldn't count it again under the relay's
+	if !inbound.Add("127.0.0.1", relayed.RemoteAddr().String()) {
+		t.Fatalf("Should have exempted the relayed connection from the limits")
+	}
+	if inbound.Len() != 1 {
+		t.Fatalf("Expected 1 connection but found %d", inbound.Len())
+	}
+
+	// A second connection from the same IP exceeds its limit
+	refused, err := net.Dial("tcp", relay.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer refused.Close()
+	if _, err := refused.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("Should have refused a connection over the per IP limit")
+	}
+}
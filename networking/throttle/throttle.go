@@ -0,0 +1,253 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package throttle
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// pruneFrequency is how often the peers that haven't sent or received a
+	// message recently are forgotten
+	pruneFrequency = time.Minute
+
+	// idleTimeout is how long a peer may go without sending or receiving a
+	// message before it's forgotten
+	idleTimeout = 10 * time.Minute
+)
+
+var (
+	errInvalidRate  = errors.New("throttle rates must be non-negative and finite")
+	errInvalidBurst = errors.New("throttle bursts must be at least 1 when their rate is positive")
+)
+
+// Config limits the messages and bytes each peer may send this node
+type Config struct {
+	// Messages per second each peer may send, on average. If 0, the number of
+	// messages isn't limited.
+	MsgRate float64
+	// Messages each peer may send at once after not sending any for a while
+	MsgBurst int
+
+	// Bytes per second each peer may send, on average. If 0, the number of
+	// bytes isn't limited.
+	ByteRate float64
+	// Bytes each peer may send at once after not sending any for a while. A
+	// message is allowed as long as some bytes are left, even if it's larger
+	// than the bytes left, so this doesn't need to exceed the largest message.
+	ByteBurst int
+}
+
+// Valid returns nil if the config describes usable limits
+func (c Config) Valid() error {
+	for _, rate := range []float64{c.MsgRate, c.ByteRate} {
+		if rate < 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return errInvalidRate
+		}
+	}
+	if (c.MsgRate > 0 && c.MsgBurst < 1) || (c.ByteRate > 0 && c.ByteBurst < 1) {
+		return errInvalidBurst
+	}
+	return nil
+}
+
+// PeerTraffic is the number of messages and bytes sent to and received from a
+// peer, along with the number of received ones that were dropped for
+// exceeding the peer's limits
+type PeerTraffic struct {
+	NodeID ids.ShortID
+
+	MsgsSent, MsgsReceived, MsgsThrottled    uint64
+	BytesSent, BytesReceived, BytesThrottled uint64
+}
+
+// peer is the traffic of a peer along with the messages and bytes it may still
+// send. They refill at the config's rates, up to the config's bursts.
+type peer struct {
+	traffic PeerTraffic
+
+	msgTokens, byteTokens float64
+	// last is when the tokens were refilled, and seen is when a message was
+	// last exchanged with the peer
+	last, seen time.Time
+}
+
+// Throttler accounts for the messages exchanged with each peer and drops
+// received messages once a peer exceeds its limits, so that a single peer
+// can't starve the processing of every other peer's messages.
+type Throttler struct {
+	lock   sync.Mutex
+	clock  timer.Clock
+	config Config
+
+	// Key: Node ID
+	peers     map[[20]byte]*peer
+	lastPrune time.Time
+
+	// Per peer counters are only exposed through Traffic, since labeling
+	// metrics by node ID would let peers create unbounded series
+	msgsThrottled, bytesThrottled prometheus.Counter
+}
+
+// Initialize the throttler with [config] and register its metrics with
+// [registerer]
+func (t *Throttler) Initialize(log logging.Logger, registerer prometheus.Registerer, config Config) {
+	t.config = config
+	t.peers = make(map[[20]byte]*peer)
+	t.lastPrune = t.clock.Time()
+	t.msgsThrottled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "gecko",
+		Name:      "peer_msgs_throttled",
+		Help:      "Number of messages dropped because their peer exceeded its inbound message or byte limit",
+	})
+	t.bytesThrottled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "gecko",
+		Name:      "peer_bytes_throttled",
+		Help:      "Number of message bytes dropped because their peer exceeded its inbound message or byte limit",
+	})
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(t.msgsThrottled),
+		registerer.Register(t.bytesThrottled),
+	)
+	if errs.Errored() {
+		log.Error("Failed to register throttling statistics due to %s", errs.Err)
+	}
+}
+
+// SetConfig replaces the limits with [config]. Every peer starts with its
+// full burst under the new limits.
+func (t *Throttler) SetConfig(config Config) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.config = config
+	for _, p := range t.peers {
+		p.msgTokens = float64(config.MsgBurst)
+		p.byteTokens = float64(config.ByteBurst)
+	}
+}
+
+// Config returns the current limits
+func (t *Throttler) Config() Config {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.config
+}
+
+// Sent records that a message of [numBytes] was sent to [nodeID]
+func (t *Throttler) Sent(nodeID ids.ShortID, numBytes int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p := t.peer(nodeID, t.clock.Time())
+	p.traffic.MsgsSent++
+	p.traffic.BytesSent += uint64(numBytes)
+}
+
+// Received records that a message of [numBytes] was received from [nodeID].
+// Returns false if the message exceeds the peer's limits and should be
+// dropped.
+func (t *Throttler) Received(nodeID ids.ShortID, numBytes int) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Time()
+	p := t.peer(nodeID, now)
+	p.refill(t.config, now)
+
+	allowed := (t.config.MsgRate == 0 || p.msgTokens >= 1) &&
+		(t.config.ByteRate == 0 || p.byteTokens > 0)
+	if !allowed {
+		p.traffic.MsgsThrottled++
+		p.traffic.BytesThrottled += uint64(numBytes)
+		t.msgsThrottled.Inc()
+		t.bytesThrottled.Add(float64(numBytes))
+		return false
+	}
+
+	if t.config.MsgRate > 0 {
+		p.msgTokens--
+	}
+	if t.config.ByteRate > 0 {
+		p.byteTokens -= float64(numBytes)
+	}
+	p.traffic.MsgsReceived++
+	p.traffic.BytesReceived += uint64(numBytes)
+	return true
+}
+
+// Traffic returns the traffic of each peer that has exchanged a message
+// recently, sorted by node ID
+func (t *Throttler) Traffic() []PeerTraffic {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	traffic := make([]PeerTraffic, 0, len(t.peers))
+	for _, p := range t.peers {
+		traffic = append(traffic, p.traffic)
+	}
+	sort.Slice(traffic, func(i, j int) bool {
+		return bytes.Compare(traffic[i].NodeID.Bytes(), traffic[j].NodeID.Bytes()) == -1
+	})
+	return traffic
+}
+
+// peer returns the peer with ID [nodeID], which exchanged a message at [now]
+func (t *Throttler) peer(nodeID ids.ShortID, now time.Time) *peer {
+	if now.Sub(t.lastPrune) >= pruneFrequency {
+		t.prune(now)
+	}
+
+	key := nodeID.Key()
+	p, exists := t.peers[key]
+	if !exists {
+		p = &peer{
+			traffic:    PeerTraffic{NodeID: nodeID},
+			msgTokens:  float64(t.config.MsgBurst),
+			byteTokens: float64(t.config.ByteBurst),
+			last:       now,
+		}
+		t.peers[key] = p
+	}
+	p.seen = now
+	return p
+}
+
+// prune forgets the peers that haven't exchanged a message since [idleTimeout]
+// before [now]
+func (t *Throttler) prune(now time.Time) {
+	t.lastPrune = now
+	for key, p := range t.peers {
+		if now.Sub(p.seen) >= idleTimeout {
+			delete(t.peers, key)
+		}
+	}
+}
+
+// refill the peer's tokens for the time that passed since it last received a
+// message
+func (p *peer) refill(config Config, now time.Time) {
+	elapsed := now.Sub(p.last).Seconds()
+	p.last = now
+	if elapsed <= 0 {
+		return
+	}
+	p.msgTokens = math.Min(p.msgTokens+elapsed*config.MsgRate, float64(config.MsgBurst))
+	p.byteTokens = math.Min(p.byteTokens+elapsed*config.ByteRate, float64(config.ByteBurst))
+}
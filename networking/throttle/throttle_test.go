@@ -0,0 +1,145 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func newThrottler(t *testing.T, config Config) *Throttler {
+	if err := config.Valid(); err != nil {
+		t.Fatal(err)
+	}
+	th := &Throttler{}
+	th.clock.Set(time.Unix(1000, 0))
+	th.Initialize(logging.NoLog{}, prometheus.NewRegistry(), config)
+	return th
+}
+
+func TestThrottlerMsgLimit(t *testing.T) {
+	th := newThrottler(t, Config{MsgRate: 1, MsgBurst: 2})
+	abusive, other := ids.NewShortID([20]byte{1}), ids.NewShortID([20]byte{2})
+
+	if !th.Received(abusive, 10) || !th.Received(abusive, 10) {
+		t.Fatalf("Should have allowed messages within the burst")
+	}
+	if th.Received(abusive, 10) {
+		t.Fatalf("Should have dropped a message over the burst")
+	}
+	if !th.Received(other, 10) {
+		t.Fatalf("Should have allowed a message from a different peer")
+	}
+
+	th.clock.Set(th.clock.Time().Add(time.Second))
+	if !th.Received(abusive, 10) {
+		t.Fatalf("Should have allowed a message after the bucket refilled")
+	}
+	if th.Received(abusive, 10) {
+		t.Fatalf("Should have only refilled one message")
+	}
+
+	if throttled := testutil.ToFloat64(th.msgsThrottled); throttled != 2 {
+		t.Fatalf("Expected 2 throttled messages but found %v", throttled)
+	}
+	if throttled := testutil.ToFloat64(th.bytesThrottled); throttled != 20 {
+		t.Fatalf("Expected 20 throttled bytes but found %v", throttled)
+	}
+}
+
+func TestThrottlerByteLimit(t *testing.T) {
+	th := newThrottler(t, Config{ByteRate: 100, ByteBurst: 100})
+	nodeID := ids.NewShortID([20]byte{1})
+
+	// A message larger than the burst is allowed while bytes are left
+	if !th.Received(nodeID, 150) {
+		t.Fatalf("Should have allowed a message while bytes were left")
+	}
+	if th.Received(nodeID, 1) {
+		t.Fatalf("Should have dropped a message after the bytes ran out")
+	}
+
+	th.clock.Set(th.clock.Time().Add(time.Second / 2))
+	if th.Received(nodeID, 1) {
+		t.Fatalf("Should have still been in debt for the large message")
+	}
+	th.clock.Set(th.clock.Time().Add(time.Second / 2))
+	if !th.Received(nodeID, 1) {
+		t.Fatalf("Should have allowed a message after paying off the debt")
+	}
+}
+
+func TestThrottlerTraffic(t *testing.T) {
+	th := newThrottler(t, Config{MsgRate: 1, MsgBurst: 1})
+	first, second := ids.NewShortID([20]byte{1}), ids.NewShortID([20]byte{2})
+
+	th.Sent(second, 5)
+	th.Sent(second, 7)
+	th.Received(second, 3)
+	th.Received(first, 4)
+	th.Received(first, 6)
+
+	traffic := th.Traffic()
+	if len(traffic) != 2 {
+		t.Fatalf("Expected the traffic of 2 peers but found %d", len(traffic))
+	}
+	expected := []PeerTraffic{
+		{NodeID: first, MsgsReceived: 1, BytesReceived: 4, MsgsThrottled: 1, BytesThrottled: 6},
+		{NodeID: second, MsgsSent: 2, BytesSent: 12, MsgsReceived: 1, BytesReceived: 3},
+	}
+	for i, peerTraffic := range traffic {
+		if !peerTraffic.NodeID.Equals(expected[i].NodeID) {
+			t.Fatalf("Expected peer %s at index %d but found %s", expected[i].NodeID, i, peerTraffic.NodeID)
+		}
+		if peerTraffic != expected[i] {
+			t.Fatalf("Expected traffic %+v but found %+v", expected[i], peerTraffic)
+		}
+	}
+
+	// Peers are forgotten once they've been idle for a while
+	th.clock.Set(th.clock.Time().Add(idleTimeout))
+	th.Sent(first, 1)
+	if traffic := th.Traffic(); len(traffic) != 1 || traffic[0].MsgsSent != 1 {
+		t.Fatalf("Expected only the traffic of the active peer but found %+v", traffic)
+	}
+}
+
+func TestThrottlerSetConfig(t *testing.T) {
+	th := newThrottler(t, Config{MsgRate: 1, MsgBurst: 1})
+	nodeID := ids.NewShortID([20]byte{1})
+
+	th.Received(nodeID, 1)
+	if th.Received(nodeID, 1) {
+		t.Fatalf("Should have dropped a message over the burst")
+	}
+
+	th.SetConfig(Config{})
+	for i := 0; i < 10; i++ {
+		if !th.Received(nodeID, 1) {
+			t.Fatalf("Should have allowed every message without limits")
+		}
+	}
+}
+
+func TestConfigValid(t *testing.T) {
+	invalid := []Config{
+		{MsgRate: -1},
+		{MsgRate: 1},
+		{ByteRate: 1},
+	}
+	for _, config := range invalid {
+		if err := config.Valid(); err == nil {
+			t.Fatalf("Config %+v should have been invalid", config)
+		}
+	}
+	if err := (Config{}).Valid(); err != nil {
+		t.Fatalf("An unlimited config should have been valid but failed with %s", err)
+	}
+}
@@ -13,11 +13,17 @@ package networking
 // void pushQuery(msg_t *, msgnetwork_conn_t *, void *);
 // void pullQuery(msg_t *, msgnetwork_conn_t *, void *);
 // void chits(msg_t *, msgnetwork_conn_t *, void *);
+// void putChunk(msg_t *, msgnetwork_conn_t *, void *);
+// void getStateSummary(msg_t *, msgnetwork_conn_t *, void *);
+// void stateSummary(msg_t *, msgnetwork_conn_t *, void *);
+// void getStateChunk(msg_t *, msgnetwork_conn_t *, void *);
+// void stateChunk(msg_t *, msgnetwork_conn_t *, void *);
 import "C"
 
 import (
 	"errors"
 	"fmt"
+	"time"
 	"unsafe"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -25,6 +31,11 @@ import (
 	"github.com/ava-labs/salticidae-go"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/networking/capture"
+	"github.com/ava-labs/gecko/networking/gossip"
+	"github.com/ava-labs/gecko/networking/throttle"
+	"github.com/ava-labs/gecko/snow/networking"
+	"github.com/ava-labs/gecko/snow/networking/chunk"
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/snow/validators"
 	"github.com/ava-labs/gecko/utils/formatting"
@@ -37,10 +48,49 @@ var (
 	VotingNet = Voting{}
 )
 
+const (
+	// chunkOverhead is the space reserved in each PutChunk message for the
+	// fields other than the chunk itself
+	chunkOverhead = 256
+
+	// chunkTimeout is how long to wait for the next chunk of a container
+	// before abandoning its transfer
+	chunkTimeout = 5 * time.Second
+
+	// maxChunkedContainerSize is the largest container that will be
+	// reassembled from chunks
+	maxChunkedContainerSize = 1 << 27
+
+	// minChunkSize is the smallest chunk, other than the last, of a container
+	// that will be reassembled. This bounds the number of chunks a peer can
+	// claim a container is split into.
+	minChunkSize = 1 << 16
+
+	// maxPendingChunkedContainers is the number of chunked containers that may
+	// be reassembled from a peer at once
+	maxPendingChunkedContainers = 16
+
+	// MinMessageSize is the smallest maximum message size that leaves room
+	// for a chunk of at least the minimum chunk size
+	MinMessageSize = minChunkSize + chunkOverhead
+)
+
 var (
 	errConnectionDropped = errors.New("connection dropped before receiving message")
+	errMessageTooLarge   = errors.New("message exceeds the maximum message size")
+	errThrottled         = errors.New("peer exceeded its inbound message limits")
 )
 
+// Subnets returns the ID of the subnet that validates a chain
+type Subnets interface {
+	SubnetID(chainID ids.ID) (ids.ID, bool)
+}
+
+// SubnetTracker reports whether a peer is interested in the chains of a subnet
+type SubnetTracker interface {
+	Tracks(nodeID ids.ShortID, subnetID ids.ID) bool
+}
+
 // Voting implements the SenderExternal interface with a c++ library.
 type Voting struct {
 	votingMetrics
@@ -52,10 +102,44 @@ type Voting struct {
 
 	router   router.Router
 	executor timer.Executor
+
+	// subnets and peerSubnets scope chain messages to the peers that track
+	// the chain's subnet
+	subnets     Subnets
+	peerSubnets SubnetTracker
+
+	bandwidth networking.BandwidthTracker
+
+	// throttle accounts for the traffic of each peer and drops the messages
+	// of peers that exceed their inbound limits
+	throttle throttle.Throttler
+
+	// maxMessageSize is the largest payload, in bytes, that will be sent or
+	// accepted from a peer
+	maxMessageSize uint32
+
+	// chunks reassembles containers that were too large to fit in a single
+	// message
+	chunks chunk.Reassembler
+
+	// capture records the messages of a chain for debugging, when enabled
+	capture capture.Capturer
+
+	// containerGossip determines how accepted containers are gossiped to
+	// non-validators
+	containerGossip   gossip.Params
+	pendingContainers gossip.Queue
+	containerGossiper *timer.Repeater
+}
+
+// gossipedContainer is an accepted container waiting to be re-gossiped
+type gossipedContainer struct {
+	chainID, containerID ids.ID
+	container            []byte
 }
 
 // Initialize to the c networking library. Should only be called once ever.
-func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet salticidae.PeerNetwork, conns Connections, router router.Router, registerer prometheus.Registerer) {
+func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet salticidae.PeerNetwork, conns Connections, router router.Router, registerer prometheus.Registerer, maxMessageSize uint32, captureConfig capture.Config, containerGossip gossip.Config, subnets Subnets, peerSubnets SubnetTracker, throttleConfig throttle.Config) {
 	log.AssertTrue(s.net == nil, "Should only register network handlers once")
 	log.AssertTrue(s.conns == nil, "Should only set connections once")
 	log.AssertTrue(s.router == nil, "Should only set the router once")
@@ -65,8 +149,20 @@ func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet sal
 	s.net = peerNet
 	s.conns = conns
 	s.router = router
+	s.maxMessageSize = maxMessageSize
+	s.subnets = subnets
+	s.peerSubnets = peerSubnets
+	s.chunks.Initialize(chunkTimeout, maxChunkedContainerSize, minChunkSize, maxPendingChunkedContainers)
+	s.capture.Initialize(captureConfig)
+	s.containerGossip.Set(containerGossip)
+	s.pendingContainers.Initialize(containerGossip.MaxPending)
 
 	s.votingMetrics.Initialize(log, registerer)
+	s.bandwidth.Initialize(log, registerer, func(chainID ids.ID) bool {
+		_, exists := subnets.SubnetID(chainID)
+		return exists
+	})
+	s.throttle.Initialize(log, registerer, throttleConfig)
 
 	net := peerNet.AsMsgNetwork()
 
@@ -79,23 +175,104 @@ func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet sal
 	net.RegHandler(PushQuery, salticidae.MsgNetworkMsgCallback(C.pushQuery), nil)
 	net.RegHandler(PullQuery, salticidae.MsgNetworkMsgCallback(C.pullQuery), nil)
 	net.RegHandler(Chits, salticidae.MsgNetworkMsgCallback(C.chits), nil)
+	net.RegHandler(PutChunk, salticidae.MsgNetworkMsgCallback(C.putChunk), nil)
+	net.RegHandler(GetStateSummary, salticidae.MsgNetworkMsgCallback(C.getStateSummary), nil)
+	net.RegHandler(StateSummary, salticidae.MsgNetworkMsgCallback(C.stateSummary), nil)
+	net.RegHandler(GetStateChunk, salticidae.MsgNetworkMsgCallback(C.getStateChunk), nil)
+	net.RegHandler(StateChunk, salticidae.MsgNetworkMsgCallback(C.stateChunk), nil)
 
 	s.executor.Initialize()
 	go log.RecoverAndPanic(s.executor.Dispatch)
+
+	if containerGossip.Frequency > 0 {
+		s.containerGossiper = timer.NewRepeater(s.regossip, containerGossip.Frequency)
+		go log.RecoverAndPanic(s.containerGossiper.Dispatch)
+	}
 }
 
 // Shutdown threads
-func (s *Voting) Shutdown() { s.executor.Stop() }
+func (s *Voting) Shutdown() {
+	s.executor.Stop()
+	if s.containerGossiper != nil {
+		s.containerGossiper.Stop()
+	}
+}
+
+// SetContainerGossip changes how accepted containers are gossiped to
+// non-validators. Periodic gossip can't be turned on or off without restarting
+// the node.
+func (s *Voting) SetContainerGossip(containerGossip gossip.Config) error {
+	if (containerGossip.Frequency > 0) != (s.containerGossiper != nil) {
+		return errGossipToggled
+	}
+	s.containerGossip.Set(containerGossip)
+	s.pendingContainers.SetMax(containerGossip.MaxPending)
+	if s.containerGossiper != nil {
+		s.containerGossiper.SetFrequency(containerGossip.Frequency)
+	}
+	return nil
+}
+
+// Bandwidth returns the number of message bytes sent and received on behalf of
+// each chain
+func (s *Voting) Bandwidth() []networking.ChainBandwidth { return s.bandwidth.Bandwidth() }
+
+// PeerTraffic returns the number of messages and bytes exchanged with each
+// peer, along with the number that were dropped for exceeding its limits
+func (s *Voting) PeerTraffic() []throttle.PeerTraffic { return s.throttle.Traffic() }
+
+// SetThrottle changes the limits on the messages each peer may send
+func (s *Voting) SetThrottle(config throttle.Config) { s.throttle.SetConfig(config) }
+
+// StartCapture starts writing the messages sent and received on behalf of
+// [chainID] to the capture files
+func (s *Voting) StartCapture(chainID ids.ID) error { return s.capture.Start(chainID) }
+
+// StopCapture stops writing messages to the capture files
+func (s *Voting) StopCapture() error { return s.capture.Stop() }
 
 // Accept is called after every consensus decision
 func (s *Voting) Accept(chainID, containerID ids.ID, container []byte) error {
-	addrs := []salticidae.NetAddr(nil)
+	if s.containerGossiper != nil && !s.pendingContainers.Push(gossipedContainer{
+		chainID:     chainID,
+		containerID: containerID,
+		container:   container,
+	}) {
+		s.log.Debug("Dropped the oldest container pending re-gossip")
+	}
+	return s.gossipContainer(chainID, containerID, container)
+}
 
+// regossip the containers that were accepted since the last round of gossip
+func (s *Voting) regossip() {
+	for _, pending := range s.pendingContainers.PopAll() {
+		c := pending.(gossipedContainer)
+		if err := s.gossipContainer(c.chainID, c.containerID, c.container); err != nil {
+			s.log.Debug("Failed to re-gossip container %s due to %s", c.containerID, err)
+		}
+	}
+}
+
+// gossipContainer sends an accepted container to a sample of the connected
+// non-validators that track the chain's subnet
+func (s *Voting) gossipContainer(chainID, containerID ids.ID, container []byte) error {
+	subnetID, hasSubnet := s.subnets.SubnetID(chainID)
+
+	nonValidators := []salticidae.NetAddr(nil)
 	allAddrs, allIDs := s.conns.RawConns()
 	for i, id := range allIDs {
-		if !s.vdrs.Contains(id) {
-			addrs = append(addrs, allAddrs[i])
+		if s.vdrs.Contains(id) {
+			continue
 		}
+		if hasSubnet && !s.peerSubnets.Tracks(id, subnetID) {
+			continue
+		}
+		nonValidators = append(nonValidators, allAddrs[i])
+	}
+
+	addrs := []salticidae.NetAddr(nil)
+	for _, i := range gossip.Sample(len(nonValidators), s.containerGossip.Get().Fanout) {
+		addrs = append(addrs, nonValidators[i])
 	}
 
 	build := Builder{}
@@ -114,7 +291,7 @@ func (s *Voting) Accept(chainID, containerID ids.ID, container []byte) error {
 		containerID,
 		formatting.DumpBytes{Bytes: container},
 	)
-	s.send(msg, addrs...)
+	s.send(chainID, msg, addrs...)
 	s.numPutSent.Add(float64(len(addrs)))
 	return nil
 }
@@ -146,7 +323,7 @@ func (s *Voting) GetAcceptedFrontier(validatorIDs ids.ShortSet, chainID ids.ID,
 		chainID,
 		requestID,
 	)
-	s.send(msg, addrs...)
+	s.send(chainID, msg, addrs...)
 	s.numGetAcceptedFrontierSent.Add(float64(len(addrs)))
 }
 
@@ -177,10 +354,136 @@ func (s *Voting) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, reque
 		requestID,
 		containerIDs,
 	)
-	s.send(msg, addr)
+	s.send(chainID, msg, addr)
 	s.numAcceptedFrontierSent.Inc()
 }
 
+// GetStateSummary implements the Sender interface.
+func (s *Voting) GetStateSummary(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	addrs := []salticidae.NetAddr(nil)
+	validatorIDList := validatorIDs.List()
+	for _, validatorID := range validatorIDList {
+		vID := validatorID
+		if addr, exists := s.conns.GetIP(vID); exists {
+			addrs = append(addrs, addr)
+			s.log.Verbo("Sending a GetStateSummary to %s", toIPDesc(addr))
+		} else {
+			s.log.Debug("Attempted to send a GetStateSummary message to a disconnected validator: %s", vID)
+			s.executor.Add(func() { s.router.GetStateSummaryFailed(vID, chainID, requestID) })
+		}
+	}
+
+	build := Builder{}
+	msg, err := build.GetStateSummary(chainID, requestID)
+	s.log.AssertNoError(err)
+
+	s.log.Verbo("Sending a GetStateSummary message."+
+		"\nNumber of Validators: %d"+
+		"\nChain: %s"+
+		"\nRequest ID: %d",
+		len(addrs),
+		chainID,
+		requestID,
+	)
+	s.send(chainID, msg, addrs...)
+	s.numGetStateSummarySent.Add(float64(len(addrs)))
+}
+
+// StateSummary implements the Sender interface.
+func (s *Voting) StateSummary(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summary []byte) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a StateSummary message to a disconnected validator: %s", validatorID)
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.StateSummary(chainID, requestID, summary)
+	if err != nil {
+		s.log.Error("Attempted to pack too large of a StateSummary message.\nSummary length: %d", len(summary))
+		return // Packing message failed
+	}
+
+	s.log.Verbo("Sending a StateSummary message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nSummary length: %d",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		len(summary),
+	)
+	s.send(chainID, msg, addr)
+	s.numStateSummarySent.Inc()
+}
+
+// GetStateChunk implements the Sender interface.
+func (s *Voting) GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, request []byte) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a GetStateChunk message to a disconnected validator: %s", validatorID)
+		s.executor.Add(func() { s.router.GetStateChunkFailed(validatorID, chainID, requestID) })
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.GetStateChunk(chainID, requestID, request)
+	if err != nil {
+		s.log.Error("Attempted to pack too large of a GetStateChunk message.\nRequest length: %d", len(request))
+		s.executor.Add(func() { s.router.GetStateChunkFailed(validatorID, chainID, requestID) })
+		return // Packing message failed
+	}
+
+	s.log.Verbo("Sending a GetStateChunk message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nRequest length: %d",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		len(request),
+	)
+	s.send(chainID, msg, addr)
+	s.numGetStateChunkSent.Inc()
+}
+
+// StateChunk implements the Sender interface.
+func (s *Voting) StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, response []byte) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a StateChunk message to a disconnected validator: %s", validatorID)
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.StateChunk(chainID, requestID, response)
+	if err != nil {
+		s.log.Error("Attempted to pack too large of a StateChunk message.\nResponse length: %d", len(response))
+		return // Packing message failed
+	}
+
+	s.log.Verbo("Sending a StateChunk message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nResponse length: %d",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		len(response),
+	)
+	s.send(chainID, msg, addr)
+	s.numStateChunkSent.Inc()
+}
+
 // GetAccepted implements the Sender interface.
 func (s *Voting) GetAccepted(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
 	addrs := []salticidae.NetAddr(nil)
@@ -218,7 +521,7 @@ func (s *Voting) GetAccepted(validatorIDs ids.ShortSet, chainID ids.ID, requestI
 		requestID,
 		containerIDs,
 	)
-	s.send(msg, addrs...)
+	s.send(chainID, msg, addrs...)
 	s.numGetAcceptedSent.Add(float64(len(addrs)))
 }
 
@@ -249,7 +552,7 @@ func (s *Voting) Accepted(validatorID ids.ShortID, chainID ids.ID, requestID uin
 		requestID,
 		containerIDs,
 	)
-	s.send(msg, addr)
+	s.send(chainID, msg, addr)
 	s.numAcceptedSent.Inc()
 }
 
@@ -266,6 +569,10 @@ func (s *Voting) Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 	msg, err := build.Get(chainID, requestID, containerID)
 	s.log.AssertNoError(err)
 
+	// The container may be sent back in chunks, which are only reassembled if
+	// they were requested
+	s.chunks.Expect(validatorID, chainID, requestID, containerID)
+
 	s.log.Verbo("Sending a Get message."+
 		"\nValidator: %s"+
 		"\nDestination: %s"+
@@ -278,7 +585,7 @@ func (s *Voting) Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 		requestID,
 		containerID,
 	)
-	s.send(msg, addr)
+	s.send(chainID, msg, addr)
 	s.numGetSent.Inc()
 }
 
@@ -290,6 +597,11 @@ func (s *Voting) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 		return // Validator is not connected
 	}
 
+	if len(container) > s.chunkSize() {
+		s.putChunks(validatorID, addr, chainID, requestID, containerID, container)
+		return
+	}
+
 	build := Builder{}
 	msg, err := build.Put(chainID, requestID, containerID, container)
 	if err != nil {
@@ -311,12 +623,20 @@ func (s *Voting) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 		containerID,
 		formatting.DumpBytes{Bytes: container},
 	)
-	s.send(msg, addr)
+	s.send(chainID, msg, addr)
 	s.numPutSent.Inc()
 }
 
 // PushQuery implements the Sender interface.
 func (s *Voting) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) {
+	if len(container) > s.chunkSize() {
+		// The container doesn't fit in a single message. The validators will
+		// fetch it with a Get, which is answered in chunks.
+		s.log.Debug("Sending a PullQuery rather than a PushQuery for container %s as it is %d bytes", containerID, len(container))
+		s.PullQuery(validatorIDs, chainID, requestID, containerID)
+		return
+	}
+
 	addrs := []salticidae.NetAddr(nil)
 	validatorIDList := validatorIDs.List()
 	for _, validatorID := range validatorIDList {
@@ -354,7 +674,7 @@ func (s *Voting) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 		containerID,
 		formatting.DumpBytes{Bytes: container},
 	)
-	s.send(msg, addrs...)
+	s.send(chainID, msg, addrs...)
 	s.numPushQuerySent.Add(float64(len(addrs)))
 }
 
@@ -387,7 +707,7 @@ func (s *Voting) PullQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 		requestID,
 		containerID,
 	)
-	s.send(msg, addrs...)
+	s.send(chainID, msg, addrs...)
 	s.numPullQuerySent.Add(float64(len(addrs)))
 }
 
@@ -418,13 +738,63 @@ func (s *Voting) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32
 		requestID,
 		votes.Len(),
 	)
-	s.send(msg, addr)
+	s.send(chainID, msg, addr)
 	s.numChitsSent.Inc()
 }
 
-func (s *Voting) send(msg Msg, addrs ...salticidae.NetAddr) {
+// putChunks sends [container] to [validatorID] split over multiple PutChunk
+// messages
+func (s *Voting) putChunks(validatorID ids.ShortID, addr salticidae.NetAddr, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) {
+	chunks := chunk.Split(container, s.chunkSize())
+	s.log.Verbo("Sending a Container message in %d chunks."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nContainer ID: %s",
+		len(chunks),
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		containerID,
+	)
+
+	build := Builder{}
+	for i, chunkBytes := range chunks {
+		msg, err := build.PutChunk(chainID, requestID, containerID, uint32(i), uint32(len(chunks)), chunkBytes)
+		if err != nil {
+			s.log.Error("Failed to pack chunk %d of container %s due to %s", i, containerID, err)
+			return
+		}
+		s.send(chainID, msg, addr)
+	}
+	s.numPutSent.Inc()
+}
+
+// chunkSize returns the largest chunk of a container that fits in a message
+func (s *Voting) chunkSize() int { return int(s.maxMessageSize) - chunkOverhead }
+
+func (s *Voting) send(chainID ids.ID, msg Msg, addrs ...salticidae.NetAddr) {
 	ds := msg.DataStream()
 	defer ds.Free()
+	if size := ds.Size(); size > int(s.maxMessageSize) {
+		s.log.Warn("Dropping outbound message with opcode %d of %d bytes as it exceeds the maximum message size of %d bytes", msg.Op(), size, s.maxMessageSize)
+		return
+	}
+	s.bandwidth.Sent(chainID, ds.Size()*len(addrs))
+	for _, addr := range addrs {
+		if nodeID, exists := s.conns.GetID(addr); exists {
+			s.throttle.Sent(nodeID, ds.Size())
+		}
+	}
+	if s.capture.Capturing(chainID) {
+		for _, addr := range addrs {
+			if err := s.capture.Capture(capture.Sent, OpName(msg.Op()), toIPDesc(addr).String(), chainID, msg.Bytes()); err != nil {
+				s.log.Warn("Failed to capture a message due to %s", err)
+			}
+		}
+	}
 	ba := salticidae.NewByteArrayMovedFromDataStream(ds, false)
 	defer ba.Free()
 	cMsg := salticidae.NewMsgMovedFromByteArray(msg.Op(), ba, false)
@@ -447,7 +817,7 @@ func getAcceptedFrontier(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t
 
 	validatorID, chainID, requestID, _, err := VotingNet.sanitize(_msg, _conn, GetAcceptedFrontier)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -461,7 +831,7 @@ func acceptedFrontier(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, AcceptedFrontier)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -478,6 +848,68 @@ func acceptedFrontier(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _
 	VotingNet.router.AcceptedFrontier(validatorID, chainID, requestID, containerIDs)
 }
 
+// getStateSummary handles the recept of a getStateSummary message for a chain
+//export getStateSummary
+func getStateSummary(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numGetStateSummaryReceived.Inc()
+
+	validatorID, chainID, requestID, _, err := VotingNet.sanitize(_msg, _conn, GetStateSummary)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	VotingNet.router.GetStateSummary(validatorID, chainID, requestID)
+}
+
+// stateSummary handles the recept of a stateSummary message
+//export stateSummary
+func stateSummary(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numStateSummaryReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, StateSummary)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	summary := msg.Get(SummaryBytes).([]byte)
+
+	VotingNet.router.StateSummary(validatorID, chainID, requestID, summary)
+}
+
+// getStateChunk handles the recept of a getStateChunk message for a chain
+//export getStateChunk
+func getStateChunk(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numGetStateChunkReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, GetStateChunk)
+	if err != nil {
+		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		return
+	}
+
+	request := msg.Get(StateChunkBytes).([]byte)
+
+	VotingNet.router.GetStateChunk(validatorID, chainID, requestID, request)
+}
+
+// stateChunk handles the recept of a stateChunk message
+//export stateChunk
+func stateChunk(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numStateChunkReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, StateChunk)
+	if err != nil {
+		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		return
+	}
+
+	response := msg.Get(StateChunkBytes).([]byte)
+
+	VotingNet.router.StateChunk(validatorID, chainID, requestID, response)
+}
+
 // getAccepted handles the recept of a getAccepted message
 //export getAccepted
 func getAccepted(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
@@ -485,7 +917,7 @@ func getAccepted(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsa
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, GetAccepted)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -509,7 +941,7 @@ func accepted(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, Accepted)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -533,7 +965,7 @@ func get(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Point
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, Get)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -549,7 +981,7 @@ func put(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Point
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, Put)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -567,7 +999,7 @@ func pushQuery(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, PushQuery)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -585,7 +1017,7 @@ func pullQuery(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, PullQuery)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -601,7 +1033,7 @@ func chits(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Poi
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, Chits)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -618,6 +1050,16 @@ func chits(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Poi
 	VotingNet.router.Chits(validatorID, chainID, requestID, votes)
 }
 
+// sanitizeFailed logs why a message couldn't be sanitized. Throttled messages
+// are expected from busy peers, so they're only logged occasionally.
+func (s *Voting) sanitizeFailed(err error) {
+	if err == errThrottled {
+		s.log.Every(time.Minute).Debug("Dropping messages due to: %s", err)
+		return
+	}
+	s.log.Error("Failed to sanitize message due to: %s", err)
+}
+
 func (s *Voting) sanitize(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, op salticidae.Opcode) (ids.ShortID, ids.ID, uint32, Msg, error) {
 	conn := salticidae.PeerNetworkConnFromC(salticidae.CPeerNetworkConn((*C.peernetwork_conn_t)(_conn)))
 	addr := conn.GetPeerAddr(false)
@@ -633,8 +1075,22 @@ func (s *Voting) sanitize(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_
 	}
 
 	msg := salticidae.MsgFromC(salticidae.CMsg(_msg))
+	payload := msg.GetPayloadByMove()
+	size := payload.Size()
+	if size > int(s.maxMessageSize) {
+		payload.Free()
+		// A peer that ignores the frame limit is either faulty or malicious,
+		// so stop spending resources on it
+		s.log.Warn("Disconnecting from %s due to a message of %d bytes, which exceeds the maximum message size of %d bytes", toIPDesc(addr), size, s.maxMessageSize)
+		s.net.DelPeer(addr)
+		return ids.ShortID{}, ids.ID{}, 0, nil, errMessageTooLarge
+	}
+	if !s.throttle.Received(validatorID, size) {
+		payload.Free()
+		return ids.ShortID{}, ids.ID{}, 0, nil, errThrottled
+	}
 	codec := Codec{}
-	pMsg, err := codec.Parse(op, msg.GetPayloadByMove())
+	pMsg, err := codec.Parse(op, payload)
 	if err != nil {
 		return ids.ShortID{}, ids.ID{}, 0, nil, err // The message couldn't be parsed
 	}
@@ -642,7 +1098,44 @@ func (s *Voting) sanitize(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_
 	chainID, err := ids.ToID(pMsg.Get(ChainID).([]byte))
 	s.log.AssertNoError(err)
 
+	s.bandwidth.Received(chainID, size)
+	if err := s.capture.Capture(capture.Received, OpName(op), toIPDesc(addr).String(), chainID, pMsg.Bytes()); err != nil {
+		s.log.Warn("Failed to capture a message due to %s", err)
+	}
+
 	requestID := pMsg.Get(RequestID).(uint32)
 
 	return validatorID, chainID, requestID, pMsg, nil
 }
+
+// putChunk handles the recept of a chunk of a container message
+//export putChunk
+func putChunk(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, PutChunk)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	containerID, _ := ids.ToID(msg.Get(ContainerID).([]byte))
+
+	containerBytes, err := VotingNet.chunks.Add(
+		validatorID,
+		chainID,
+		requestID,
+		containerID,
+		msg.Get(ChunkIndex).(uint32),
+		msg.Get(NumChunks).(uint32),
+		msg.Get(ContainerBytes).([]byte),
+	)
+	if err != nil {
+		VotingNet.log.Debug("Dropping chunked container %s from %s due to: %s", containerID, validatorID, err)
+		return
+	}
+	if containerBytes == nil {
+		return // Waiting on more chunks
+	}
+
+	VotingNet.numPutReceived.Inc()
+	VotingNet.router.Put(validatorID, chainID, requestID, containerID, containerBytes)
+}
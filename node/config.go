@@ -4,13 +4,31 @@
 package node
 
 import (
+	"os"
+	"time"
+
 	"github.com/ava-labs/go-ethereum/p2p/nat"
 
+	"github.com/ava-labs/gecko/api"
+	"github.com/ava-labs/gecko/api/health"
+	"github.com/ava-labs/gecko/api/keystore"
+	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/networking/capture"
+	"github.com/ava-labs/gecko/networking/gossip"
+	"github.com/ava-labs/gecko/networking/limiter"
+	"github.com/ava-labs/gecko/networking/throttle"
 	"github.com/ava-labs/gecko/snow/consensus/avalanche"
+	"github.com/ava-labs/gecko/snow/consensus/snowball"
+	"github.com/ava-labs/gecko/snow/networking/benchlist"
 	"github.com/ava-labs/gecko/snow/networking/router"
+	"github.com/ava-labs/gecko/snow/networking/timeout"
+	"github.com/ava-labs/gecko/snow/upgrades"
 	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/supervisor"
+	"github.com/ava-labs/gecko/utils/timer"
 )
 
 // Config contains all of the configurations of an Ava node.
@@ -33,8 +51,23 @@ type Config struct {
 	// Database to use for the node
 	DB database.Database
 
+	// Limits on how many bytes each chain may store in the database
+	DBQuotas chains.DBQuotas
+
+	// When chains delete the containers they accepted from the database
+	Pruning chains.PruningConfig
+
+	// Subnets whose chains this node runs. If empty, it runs every subnet's.
+	TrackedSubnets ids.Set
+
+	// Directory of the VM plugins to run. Each plugin is named by the ID of the
+	// VM it serves.
+	PluginDir string
+
 	// Staking configuration
 	StakingIP       utils.IPDesc
+	ListenIPs       []utils.IPDesc // Addresses staking connections are accepted on
+	AdvertisedIPs   []utils.IPDesc // Addresses peers are told to connect to
 	EnableStaking   bool
 	StakingKeyFile  string
 	StakingCertFile string
@@ -42,23 +75,102 @@ type Config struct {
 	// Bootstrapping configuration
 	BootstrapPeers []*Peer
 
+	// Maximum size, in bytes, of a message exchanged with a peer
+	MaxMessageSize uint32
+
+	// Limits on inbound connections and unauthenticated peers
+	ConnectionLimits limiter.Config
+
+	// Limits on the messages each connected peer may send
+	Throttle throttle.Config
+
+	// Longest to wait between attempts to reconnect to a peer
+	MaxReconnectDelay time.Duration
+
 	// HTTP configuration
 	HTTPPort      uint16
 	EnableHTTPS   bool
 	HTTPSKeyFile  string
 	HTTPSCertFile string
+	CORSConfig    api.CORSConfig
+	RateLimits    []api.RateLimitRule
+	RequestLimits []api.RequestLimitRule
+
+	// If HTTPTCPEnabled, the API server listens on HTTPPort. If HTTPSocketPath
+	// is set, it also listens on a Unix domain socket there, with permissions
+	// HTTPSocketMode. Only the TCP listener uses TLS.
+	HTTPTCPEnabled bool
+	HTTPSocketPath string
+	HTTPSocketMode os.FileMode
+
+	// Responses of at least this many bytes are compressed. If 0, responses
+	// aren't compressed.
+	HTTPCompressionMinSize int
+
+	// If true, clients can make any JSON-RPC call over websocket connections,
+	// rather than only subscribe to pubsub channels
+	HTTPWebSocketCalls bool
+
+	// Endpoints that aren't served, such as "keystore". The Admin API can't be
+	// disabled.
+	DisabledEndpoints []string
 
 	// Enable/Disable APIs
 	AdminAPIEnabled    bool
 	KeystoreAPIEnabled bool
 	MetricsAPIEnabled  bool
 
+	// The policy that keystore passwords must satisfy, and the parameters
+	// that new passwords are hashed with
+	KeystorePasswordPolicy keystore.StrengthPolicy
+	KeystoreHashParams     keystore.HashParams
+
+	// How long keystore login tokens are valid for
+	KeystoreTokenDuration time.Duration
+
+	// If set, the keystore stores usernames and user records encrypted under
+	// keys derived from this master key
+	KeystoreMasterKey []byte
+
+	// How peer lists and accepted containers are gossiped
+	PeerListGossip  gossip.Config
+	ContainerGossip gossip.Config
+
+	// Where and how much of a chain's messages are captured when enabled
+	CaptureConfig capture.Config
+
+	// Directory that the admin API writes profiles to
+	ProfileDir string
+
 	// Logging configuration
 	LoggingConfig logging.Config
 
 	// Consensus configuration
 	ConsensusParams avalanche.Parameters
 
+	// Creates the snowball instances of snowman chains. If nil, they're trees.
+	SnowballFactory snowball.Factory
+
+	// Directory the consensus of snowman chains is traced to. If empty, it
+	// isn't traced.
+	ConsensusTraceDir string
+
+	// Determines how long requests to other validators may take
+	NetworkTimeout timer.AdaptiveTimeoutConfig
+
+	// Determines how long requests to validators that have answered enough
+	// requests may take, based on their own response times
+	PeerTimeout timeout.PeerTimeoutConfig
+
+	// Benchlist configuration
+	BenchlistConfig benchlist.Config
+
+	// Largest fraction of stake weight a slow validator loses when sampling
+	LatencySamplingBias float64
+
+	// When the upgrades, by name, activate
+	UpgradeSchedule map[string]upgrades.Activation
+
 	// Throughput configuration
 	ThroughputPort          uint16
 	ThroughputServerEnabled bool
@@ -66,6 +178,90 @@ type Config struct {
 	// IPCEnabled configuration
 	IPCEnabled bool
 
+	// Index configuration. If no chains are listed, every chain is indexed.
+	// The GraphQL API queries the indexed chains. If IndexAddresses is set,
+	// the transactions of indexed chains whose VMs report their addresses are
+	// also indexed by address.
+	IndexAPIEnabled   bool
+	IndexedChains     []string
+	IndexAddresses    bool
+	GraphQLAPIEnabled bool
+
+	// Health configuration. The checks named in HealthChecks are run as
+	// configured there instead of by default.
+	HealthAPIEnabled bool
+	HealthChecks     map[string]health.CheckConfig
+
+	// gRPC gateway configuration
+	GRPCEnabled bool
+	GRPCPort    uint16
+
+	// Events configuration
+	EventsAPIEnabled bool
+
+	// Coordinator configuration
+	CoordinatorAPIEnabled bool
+
+	// Auth configuration. If required, requests to endpoints other than the
+	// public ones must carry a token issued with the password. Otherwise, if
+	// endpoints are protected, only requests to them must.
+	AuthRequired           bool
+	AuthPassword           string
+	AuthPublicEndpoints    []string
+	AuthProtectedEndpoints []string
+
 	// Router that is used to handle incoming consensus messages
 	ConsensusRouter router.Router
+
+	// How long the node has to shut down cleanly before it's stopped
+	ShutdownTimeout time.Duration
+
+	// Directory the PID files of the node, named gecko.pid, and of its
+	// supervised processes are written to. If empty, no PID files are written.
+	PIDDir string
+
+	// Child processes, such as IPC consumers, that are run alongside the node
+	// and restarted if they exit
+	SupervisedProcesses []supervisor.Config
+
+	// Reload rereads the options that may be changed while the node is
+	// running. If nil, the configuration can't be reloaded.
+	Reload func() (ReloadableConfig, error)
+}
+
+// ReloadableConfig is the part of the configuration that may be changed while
+// the node is running. Consensus parameters aren't part of it, since nodes
+// that disagree on them could fork.
+type ReloadableConfig struct {
+	// Levels of the messages the node's logs write to their files and display
+	LogLevel, DisplayLevel logging.Level
+
+	// Limits on API requests
+	RateLimits    []api.RateLimitRule
+	RequestLimits []api.RequestLimitRule
+
+	// Limits on inbound connections. The handshake timeout applies to peers
+	// that connect after the limits change.
+	ConnectionLimits limiter.Config
+
+	// Limits on the messages each connected peer may send
+	Throttle throttle.Config
+
+	// Longest to wait between attempts to reconnect to a peer. Attempts that
+	// are already scheduled aren't moved.
+	MaxReconnectDelay time.Duration
+
+	// How peer lists and accepted containers are gossiped. Periodic gossip
+	// can't be turned on or off by reloading.
+	PeerListGossip  gossip.Config
+	ContainerGossip gossip.Config
+
+	// Peers the node connects to. Peers that are added are connected to, but
+	// peers that are removed aren't disconnected from, and the beacons that
+	// chains bootstrap from don't change until the node restarts.
+	BootstrapPeers []*Peer
+
+	// Endpoints that aren't served. Endpoints that are no longer listed are
+	// served again, unless they were disabled through the Admin API.
+	DisabledEndpoints []string
 }
@@ -9,44 +9,109 @@ package node
 import "C"
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/ava-labs/salticidae-go"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/api/admin"
+	"github.com/ava-labs/gecko/api/auth"
+	"github.com/ava-labs/gecko/api/coordinator"
+	"github.com/ava-labs/gecko/api/events"
+	"github.com/ava-labs/gecko/api/gateway"
+	"github.com/ava-labs/gecko/api/health"
+	"github.com/ava-labs/gecko/api/indexer"
 	"github.com/ava-labs/gecko/api/ipcs"
 	"github.com/ava-labs/gecko/api/keystore"
 	"github.com/ava-labs/gecko/api/metrics"
 	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/meterdb"
 	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/genesis"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/networking"
+	"github.com/ava-labs/gecko/networking/peers"
+	"github.com/ava-labs/gecko/networking/relay"
 	"github.com/ava-labs/gecko/networking/xputtest"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/networking/sender"
 	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/snow/upgrades"
 	"github.com/ava-labs/gecko/snow/validators"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/supervisor"
 	"github.com/ava-labs/gecko/vms"
 	"github.com/ava-labs/gecko/vms/avm"
 	"github.com/ava-labs/gecko/vms/evm"
+	"github.com/ava-labs/gecko/vms/managedfx"
+	"github.com/ava-labs/gecko/vms/nftfx"
 	"github.com/ava-labs/gecko/vms/platformvm"
+	"github.com/ava-labs/gecko/vms/rpcchainvm"
+	"github.com/ava-labs/gecko/vms/schnorrfx"
 	"github.com/ava-labs/gecko/vms/secp256k1fx"
 	"github.com/ava-labs/gecko/vms/spchainvm"
 	"github.com/ava-labs/gecko/vms/spdagvm"
 	"github.com/ava-labs/gecko/vms/timestampvm"
+	"github.com/ava-labs/gecko/vms/wasmvm"
 )
 
 const (
-	maxMessageSize = 1 << 25 // maximum size of a message sent with salticidae
+	// maxStoredPeers is the number of previously connected peers that are
+	// reconnected to on startup
+	maxStoredPeers = 100
+
+	// maxStoredPeerAge is how long a peer can go unseen before it is forgotten
+	maxStoredPeerAge = 7 * 24 * time.Hour
+
+	// healthCheckFrequency is how often the health checks are run
+	healthCheckFrequency = 30 * time.Second
+
+	// networkFailureThreshold is how many times in a row the node can have no
+	// peers before it's considered unhealthy, since connections are sometimes
+	// briefly lost
+	networkFailureThreshold = 3
+
+	// databaseCheckTimeout is how long the database has to respond to the
+	// health check before it's unhealthy
+	databaseCheckTimeout = 10 * time.Second
+
+	// processesFailureThreshold is how many times in a row a supervised
+	// process can be found not running before the node is unhealthy, since
+	// processes are briefly down while they're restarted
+	processesFailureThreshold = 2
+
+	// pidFileName is the name of the node's PID file
+	pidFileName = "gecko.pid"
+
+	// sendQueueSize is how many messages of each priority the chains can
+	// queue to be sent before sending blocks
+	sendQueueSize = 1024
+)
+
+var (
+	errNoPeers               = errors.New("not connected to any peers")
+	errChainsBootstrapping   = errors.New("chains are still bootstrapping")
+	errGraphQLWithoutIndex   = errors.New("the GraphQL API requires the Index API to be enabled")
+	errAddressesWithoutIndex = errors.New("indexing addresses requires the Index API to be enabled")
+	errLogDiskUsage          = errors.New("log files use more disk than allowed")
+	errPruningFailed         = errors.New("pruning failed on some chains")
+
+	healthCheckKey = []byte("health")
 )
 
 // MainNode is the reference for node callbacks
@@ -68,12 +133,56 @@ type Node struct {
 	// Handles calls to Keystore API
 	keystoreServer keystore.Keystore
 
+	// Authorizes requests to the APIs and handles calls to the Auth API
+	auth auth.Auth
+
+	// Indexes the containers accepted by chains and handles calls to the Index
+	// API
+	indexer indexer.Indexer
+
+	// Streams the containers accepted by chains to clients of the Events API
+	events events.Stream
+
+	// Runs the checks of the node's health and handles calls to the Health API
+	health health.Health
+
+	// Serves the metrics in the node-wide registry to the Metrics API
+	metricsHandler *common.HTTPHandler
+
+	// Runs workflows of transactions across chains and handles calls to the
+	// Coordinator API
+	coordinator coordinator.Coordinator
+
+	// Serves the node's core services over gRPC
+	gateway gateway.Gateway
+
+	// Runs the child processes, such as IPC consumers, that run alongside the
+	// node
+	supervisor supervisor.Supervisor
+
+	// Memory that the chains running on this node share
+	sharedMemory atomic.Memory
+
 	// Manages creation of blockchains and routing messages to them
 	chainManager chains.Manager
 
+	// Sends the chains' messages to the network, sending queries and chits
+	// before bulk traffic
+	sender sender.Prioritized
+
 	// Manages Virtual Machines
 	vmManager vms.Manager
 
+	// Chain and VM aliases added at runtime through the Admin API
+	aliases admin.Aliases
+
+	// Endpoint aliases added, and endpoints disabled, at runtime through the
+	// Admin API
+	endpoints admin.Endpoints
+
+	// Upgrades the chains running on this node recognize
+	upgrades upgrades.Manager
+
 	// dispatcher for events as they happen in consensus
 	DecisionDispatcher  *triggers.EventDispatcher
 	ConsensusDispatcher *triggers.EventDispatcher
@@ -90,6 +199,12 @@ type Node struct {
 	// API that handles voting messages
 	ConsensusAPI *networking.Voting
 
+	// Forward connections on the additional listen addresses to the peer network
+	relays []*relay.Relay
+
+	// Peers that this node has connected to in the past
+	peerDB peers.DB
+
 	// current validators of the network
 	vdrs validators.Manager
 
@@ -103,6 +218,9 @@ type Node struct {
 
 	// This node's configuration
 	Config *Config
+
+	// Receives SIGHUP, which reloads the configuration
+	reloadSignals chan os.Signal
 }
 
 /*
@@ -140,9 +258,11 @@ func (n *Node) initNetlib() error {
 
 	// Create peer network config, may have tls enabled
 	peerConfig := salticidae.NewPeerNetworkConfig()
+	msgConfig := peerConfig.AsMsgNetworkConfig()
+	// salticidae drops connections that send frames larger than this before
+	// the payload is buffered
+	msgConfig.MaxMsgSize(int(n.Config.MaxMessageSize))
 	if n.Config.EnableStaking {
-		msgConfig := peerConfig.AsMsgNetworkConfig()
-		msgConfig.MaxMsgSize(maxMessageSize)
 		msgConfig.EnableTLS(true)
 		msgConfig.TLSKeyFile(n.Config.StakingKeyFile)
 		msgConfig.TLSCertFile(n.Config.StakingCertFile)
@@ -161,7 +281,7 @@ func (n *Node) initNetlib() error {
 	if n.Config.ThroughputServerEnabled {
 		// Create the client network
 		msgConfig := salticidae.NewMsgNetworkConfig()
-		msgConfig.MaxMsgSize(maxMessageSize)
+		msgConfig.MaxMsgSize(int(n.Config.MaxMessageSize))
 		n.ClientNet = salticidae.NewMsgNetwork(n.EC, msgConfig, &err)
 		if code := err.GetCode(); code != 0 {
 			return errors.New(salticidae.StrError(code))
@@ -188,6 +308,8 @@ func (n *Node) initValidatorNet() error {
 		return errors.New(salticidae.StrError(code))
 	}
 
+	n.peerDB.Initialize(prefixdb.New([]byte("peers"), n.DB))
+
 	n.ValidatorAPI = &networking.HandshakeNet
 	n.ValidatorAPI.Initialize(
 		/*log=*/ n.Log,
@@ -198,17 +320,48 @@ func (n *Node) initValidatorNet() error {
 		/*metrics=*/ n.Config.ConsensusParams.Metrics,
 		/*enableStaking=*/ n.Config.EnableStaking,
 		/*networkID=*/ n.Config.NetworkID,
+		/*limits=*/ n.Config.ConnectionLimits,
+		/*metadata=*/ n.metadata(),
+		/*peerDB=*/ &n.peerDB,
+		/*peerListGossip=*/ n.Config.PeerListGossip,
+		/*maxReconnectDelay=*/ n.Config.MaxReconnectDelay,
 	)
 
 	return nil
 }
 
+// metadata returns what this node advertises about itself to its peers
+func (n *Node) metadata() peers.Metadata {
+	capabilities := []string(nil)
+	if n.Config.AdminAPIEnabled {
+		capabilities = append(capabilities, "admin")
+	}
+	if n.Config.KeystoreAPIEnabled {
+		capabilities = append(capabilities, "keystore")
+	}
+	if n.Config.MetricsAPIEnabled {
+		capabilities = append(capabilities, "metrics")
+	}
+	if n.Config.IPCEnabled {
+		capabilities = append(capabilities, "ipcs")
+	}
+	if n.Config.EnableStaking {
+		capabilities = append(capabilities, "staking")
+	}
+	return peers.Metadata{
+		NodeVersion:    networking.CurrentVersion,
+		TrackedSubnets: n.Config.TrackedSubnets.List(),
+		Capabilities:   capabilities,
+		IPs:            n.Config.AdvertisedIPs,
+	}
+}
+
 func (n *Node) initConsensusNet() {
 	vdrs, ok := n.vdrs.GetValidatorSet(platformvm.DefaultSubnetID)
 	n.Log.AssertTrue(ok, "should have initialize the validator set already")
 
 	n.ConsensusAPI = &networking.VotingNet
-	n.ConsensusAPI.Initialize(n.Log, vdrs, n.PeerNet, n.ValidatorAPI.Connections(), n.chainManager.Router(), n.Config.ConsensusParams.Metrics)
+	n.ConsensusAPI.Initialize(n.Log, vdrs, n.PeerNet, n.ValidatorAPI.Connections(), n.chainManager.Router(), n.Config.ConsensusParams.Metrics, n.Config.MaxMessageSize, n.Config.CaptureConfig, n.Config.ContainerGossip, n.chainManager, n.ValidatorAPI, n.Config.Throttle)
 
 	n.Log.AssertNoError(n.ConsensusDispatcher.Register("gossip", n.ConsensusAPI))
 }
@@ -233,7 +386,8 @@ func (n *Node) StartConsensusServer() error {
 	err := salticidae.NewError()
 
 	// The IP this node listens on for P2P messaging
-	serverIP := salticidae.NewNetAddrFromIPPortString(n.Config.StakingIP.String(), true, &err)
+	listenIP := n.Config.ListenIPs[0]
+	serverIP := salticidae.NewNetAddrFromIPPortString(listenIP.String(), true, &err)
 	if code := err.GetCode(); code != 0 {
 		return fmt.Errorf("failed to create ip addr: %s", salticidae.StrError(code))
 	}
@@ -244,6 +398,18 @@ func (n *Node) StartConsensusServer() error {
 		return fmt.Errorf("failed to start consensus server: %s", salticidae.StrError(code))
 	}
 
+	// The peer network can only listen on one address, so connections to the
+	// other addresses are relayed to it
+	for _, ip := range n.Config.ListenIPs[1:] {
+		r, err := relay.New(n.Log, ip.String(), listenIP.String(), n.ValidatorAPI.Inbound())
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", ip, err)
+		}
+		n.relays = append(n.relays, r)
+		go n.Log.RecoverAndPanic(r.Serve)
+		n.Log.Info("relaying staking connections from %s to %s", ip, listenIP)
+	}
+
 	// Start a server to handle throughput tests if configuration says to. Disabled by default.
 	if n.Config.ThroughputServerEnabled {
 		n.ClientNet.Start()
@@ -267,11 +433,31 @@ func (n *Node) StartConsensusServer() error {
 				return fmt.Errorf("failed to create bootstrap ip addr: %s", salticidae.StrError(code))
 			}
 			n.PeerNet.AddPeer(bootstrapIP)
+			n.ValidatorAPI.Reconnect(peer.ID, peer.IP)
 		} else {
 			n.Log.Error("can't add self as a bootstrapper")
 		}
 	}
 
+	// Reconnect to the peers this node was connected to before it restarted
+	storedPeers, dbErr := n.peerDB.Peers(maxStoredPeers, maxStoredPeerAge)
+	if dbErr != nil {
+		n.Log.Warn("Failed to read stored peers due to %s", dbErr)
+	}
+	for _, peer := range storedPeers {
+		if peer.NodeID.Equals(n.ID) || peer.IP.Equal(n.Config.StakingIP) {
+			continue
+		}
+		peerIP := salticidae.NewNetAddrFromIPPortString(peer.IP.String(), true, &err)
+		if code := err.GetCode(); code != 0 {
+			n.Log.Warn("Failed to create stored peer ip addr %s: %s", peer.IP, salticidae.StrError(code))
+			continue
+		}
+		n.Log.Debug("Reconnecting to stored peer %s at %s", peer.NodeID, peer.IP)
+		n.PeerNet.AddPeer(peerIP)
+		n.ValidatorAPI.Reconnect(peer.NodeID, peer.IP)
+	}
+
 	return nil
 }
 
@@ -285,7 +471,18 @@ func (n *Node) Dispatch() { n.EC.Dispatch() }
  ******************************************************************************
  */
 
-func (n *Node) initDatabase() { n.DB = n.Config.DB }
+// initDatabase sets up the node's database. If the Metrics API is enabled, the
+// database's operations are measured.
+// Assumes n.Config.ConsensusParams.Metrics already initialized
+func (n *Node) initDatabase() error {
+	if !n.Config.MetricsAPIEnabled {
+		n.DB = n.Config.DB
+		return nil
+	}
+	db, err := meterdb.New("gecko", n.Config.ConsensusParams.Metrics, n.Config.DB)
+	n.DB = db
+	return err
+}
 
 // Initialize this node's ID
 // If staking is disabled, a node's ID is a hash of its IP
@@ -322,12 +519,55 @@ func (n *Node) initNodeID() error {
 // its factory needs to reference n.chainManager, which is nil right now
 func (n *Node) initVMManager() {
 	n.vmManager = vms.NewManager(&n.APIServer, n.HTTPLog)
-	n.vmManager.RegisterVMFactory(avm.ID, &avm.Factory{})
+
+	avmFees, err := genesis.AVMFees(n.Config.NetworkID)
+	if err != nil {
+		n.Log.Error("couldn't determine the AVM's fees: %s", err)
+	}
+	n.vmManager.RegisterVMFactory(avm.ID, &avm.Factory{Fees: avmFees})
 	n.vmManager.RegisterVMFactory(evm.ID, &evm.Factory{})
 	n.vmManager.RegisterVMFactory(spdagvm.ID, &spdagvm.Factory{TxFee: n.Config.AvaTxFee})
 	n.vmManager.RegisterVMFactory(spchainvm.ID, &spchainvm.Factory{})
 	n.vmManager.RegisterVMFactory(secp256k1fx.ID, &secp256k1fx.Factory{})
+	n.vmManager.RegisterVMFactory(nftfx.ID, &nftfx.Factory{})
+	n.vmManager.RegisterVMFactory(managedfx.ID, &managedfx.Factory{})
+	n.vmManager.RegisterVMFactory(schnorrfx.ID, &schnorrfx.Factory{})
 	n.vmManager.RegisterVMFactory(timestampvm.ID, &timestampvm.Factory{})
+	n.vmManager.RegisterVMFactory(wasmvm.ID, &wasmvm.Factory{})
+	n.initVMPlugins()
+}
+
+// initVMPlugins registers a VM for each plugin in the plugin directory. The
+// file name of a plugin is the ID of the VM it serves.
+func (n *Node) initVMPlugins() {
+	if n.Config.PluginDir == "" {
+		return
+	}
+	files, err := ioutil.ReadDir(n.Config.PluginDir)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		n.Log.Warn("couldn't read the plugin directory %s: %s", n.Config.PluginDir, err)
+		return
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		vmID, err := ids.FromString(file.Name())
+		if err != nil {
+			n.Log.Warn("skipping plugin %s, as its name isn't a VM ID: %s", file.Name(), err)
+			continue
+		}
+		path := filepath.Join(n.Config.PluginDir, file.Name())
+		if err := n.vmManager.RegisterVMFactory(vmID, &rpcchainvm.Factory{Path: path}); err != nil {
+			n.Log.Warn("couldn't register the plugin %s: %s", path, err)
+			continue
+		}
+		n.Log.Info("registered the VM %s served by the plugin %s", vmID, path)
+	}
 }
 
 // Create the EventDispatcher used for hooking events
@@ -353,11 +593,21 @@ func (n *Node) initChains() {
 		vdrs.PutValidatorSet(platformvm.DefaultSubnetID, defaultSubnetValidators)
 	}
 
+	// The platform chain moves $AVA to and from the AVM
+	avaAssetID, err := genesis.AVAAssetID(n.Config.NetworkID)
+	if err != nil {
+		n.Log.Error("couldn't determine the $AVA asset ID: %s", err)
+	}
+
 	n.vmManager.RegisterVMFactory(
 		/*vmID=*/ platformvm.ID,
 		/*vmFactory=*/ &platformvm.Factory{
 			ChainManager: n.chainManager,
+			VMManager:    n.vmManager,
 			Validators:   vdrs,
+			Network:      n.ValidatorAPI,
+			AVM:          genesis.VMGenesis(n.Config.NetworkID, avm.ID).ID(),
+			AVA:          avaAssetID,
 		},
 	)
 
@@ -378,10 +628,38 @@ func (n *Node) initChains() {
 }
 
 // initAPIServer initializes the server that handles HTTP calls
-func (n *Node) initAPIServer() {
+// Assumes n.DB already initialized
+func (n *Node) initAPIServer() error {
 	n.Log.Info("Initializing API server")
 
-	n.APIServer.Initialize(n.Log, n.LogFactory, n.Config.HTTPPort)
+	n.APIServer.Initialize(n.Log, n.LogFactory, n.Config.HTTPPort, n.Config.CORSConfig)
+	if err := n.APIServer.RegisterMetrics(n.Config.ConsensusParams.Metrics); err != nil {
+		return err
+	}
+	if len(n.Config.RateLimits) > 0 {
+		n.APIServer.SetRateLimits(n.Config.RateLimits)
+	}
+	if len(n.Config.RequestLimits) > 0 {
+		n.APIServer.SetRequestLimits(n.Config.RequestLimits)
+	}
+	n.APIServer.SetCompression(n.Config.HTTPCompressionMinSize)
+	n.APIServer.SetWebSocketCalls(n.Config.HTTPWebSocketCalls)
+	if err := n.initAuthAPI(); err != nil {
+		return err
+	}
+
+	if n.Config.HTTPSocketPath != "" {
+		n.Log.Debug("Initializing API server on the Unix socket %s", n.Config.HTTPSocketPath)
+		go n.Log.RecoverAndPanic(func() {
+			if err := n.APIServer.DispatchUnix(n.Config.HTTPSocketPath, n.Config.HTTPSocketMode); err != nil {
+				n.Log.Error("API server on the Unix socket %s stopped with %s", n.Config.HTTPSocketPath, err)
+			}
+		})
+	}
+	if !n.Config.HTTPTCPEnabled {
+		n.Log.Debug("Initializing API server without TCP")
+		return nil
+	}
 
 	if n.Config.EnableHTTPS {
 		n.Log.Debug("Initializing API server with TLS Enabled")
@@ -395,10 +673,38 @@ func (n *Node) initAPIServer() {
 		n.Log.Debug("Initializing API server with TLS Disabled")
 		go n.Log.RecoverAndPanic(func() { n.APIServer.Dispatch() })
 	}
+	return nil
+}
+
+// initAuthAPI requires requests to the APIs, or to the protected ones, to be
+// authorized by token, if configured to, and initializes the Auth API that
+// issues the tokens
+// Assumes n.APIServer is initialized but not yet dispatched
+func (n *Node) initAuthAPI() error {
+	if !n.authEnabled() {
+		return nil
+	}
+	n.Log.Info("initializing Auth API")
+	authDB := prefixdb.New([]byte("auth"), n.DB)
+	if err := n.auth.Initialize(n.Log, authDB, n.Config.AuthPassword, n.Config.AuthPublicEndpoints, n.Config.AuthProtectedEndpoints); err != nil {
+		return err
+	}
+	n.auth.SetCanonical(n.APIServer.CanonicalEndpoint)
+	n.APIServer.SetAuthorizer(&n.auth)
+	return n.APIServer.AddRoute(n.auth.CreateHandler(), &sync.RWMutex{}, "auth", "", n.HTTPLog)
+}
+
+// authEnabled returns true if requests to any of the APIs must be authorized
+// by token
+func (n *Node) authEnabled() bool {
+	return n.Config.AuthRequired || len(n.Config.AuthProtectedEndpoints) > 0
 }
 
 // Assumes n.DB, n.vdrs all initialized (non-nil)
 func (n *Node) initChainManager() {
+	n.upgrades.Initialize(n.Log, n.Config.UpgradeSchedule)
+	n.sender.Initialize(&networking.VotingNet, sendQueueSize)
+	go n.Log.RecoverAndPanic(n.sender.Dispatch)
 	n.chainManager = chains.New(
 		n.Log,
 		n.LogFactory,
@@ -407,48 +713,95 @@ func (n *Node) initChainManager() {
 		n.ConsensusDispatcher,
 		n.DB,
 		n.Config.ConsensusRouter,
-		&networking.VotingNet,
+		&n.sender,
 		n.Config.ConsensusParams,
+		n.Config.SnowballFactory,
+		n.Config.ConsensusTraceDir,
+		n.Config.NetworkTimeout,
+		n.Config.PeerTimeout,
+		n.Config.BenchlistConfig,
+		n.Config.LatencySamplingBias,
 		n.vdrs,
 		n.ID,
 		n.Config.NetworkID,
 		n.ValidatorAPI,
 		&n.APIServer,
 		&n.keystoreServer,
+		&n.sharedMemory,
+		&n.upgrades,
+		n.Config.DBQuotas,
+		n.Config.Pruning,
+		n.Config.TrackedSubnets,
 	)
 
 	n.chainManager.AddRegistrant(&n.APIServer)
 }
 
+// initSharedMemory initializes the memory that the chains running on this node
+// share
+func (n *Node) initSharedMemory() {
+	n.Log.Info("initializing SharedMemory")
+	sharedMemoryDB := prefixdb.New([]byte("shared memory"), n.DB)
+	n.sharedMemory.Initialize(n.Log, sharedMemoryDB)
+}
+
 // initWallet initializes the Wallet service
 // Assumes n.APIServer is already set
-func (n *Node) initKeystoreAPI() {
+func (n *Node) initKeystoreAPI() error {
 	n.Log.Info("initializing Keystore API")
 	keystoreDB := prefixdb.New([]byte("keystore"), n.DB)
 	n.keystoreServer.Initialize(n.Log, keystoreDB)
+	n.keystoreServer.SetPasswordPolicy(n.Config.KeystorePasswordPolicy)
+	n.keystoreServer.SetTokenDuration(n.Config.KeystoreTokenDuration)
+	if err := n.keystoreServer.SetHashParams(n.Config.KeystoreHashParams); err != nil {
+		return err
+	}
+	if len(n.Config.KeystoreMasterKey) != 0 {
+		if err := n.keystoreServer.EnableEncryption(n.Config.KeystoreMasterKey); err != nil {
+			return err
+		}
+	}
 	keystoreHandler := n.keystoreServer.CreateHandler()
 	if n.Config.KeystoreAPIEnabled {
 		n.APIServer.AddRoute(keystoreHandler, &sync.RWMutex{}, "keystore", "", n.HTTPLog)
 	}
+	return nil
+}
+
+// initMetrics creates the node-wide registry that consensus, networking, the
+// database and the VMs register their metrics with, and registers the disk
+// usage of the node's logs
+func (n *Node) initMetrics() error {
+	registry, handler := metrics.NewService()
+	n.Config.ConsensusParams.Metrics = registry
+	n.metricsHandler = handler
+
+	return registry.Register(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: "gecko",
+			Name:      "log_disk_usage",
+			Help:      "Number of bytes of the log files",
+		},
+		func() float64 { return float64(n.LogFactory.DiskUsage()) },
+	))
 }
 
 // initMetricsAPI initializes the Metrics API
 // Assumes n.APIServer is already set
 func (n *Node) initMetricsAPI() {
-	n.Log.Info("initializing Metrics API")
-	registry, handler := metrics.NewService()
 	if n.Config.MetricsAPIEnabled {
-		n.APIServer.AddRoute(handler, &sync.RWMutex{}, "metrics", "", n.HTTPLog)
+		n.Log.Info("initializing Metrics API")
+		n.APIServer.AddRoute(n.metricsHandler, &sync.RWMutex{}, "metrics", "", n.HTTPLog)
 	}
-	n.Config.ConsensusParams.Metrics = registry
 }
 
 // initAdminAPI initializes the Admin API service
-// Assumes n.log, n.chainManager, and n.ValidatorAPI already initialized
+// Assumes n.log, n.chainManager, n.vmManager, n.aliases, n.upgrades,
+// n.ValidatorAPI, and n.ConsensusAPI already initialized
 func (n *Node) initAdminAPI() {
 	if n.Config.AdminAPIEnabled {
 		n.Log.Info("initializing Admin API")
-		service := admin.NewService(n.ID, n.Config.NetworkID, n.Log, n.chainManager, n.ValidatorAPI.Connections(), &n.APIServer)
+		service := admin.NewService(n.ID, networking.CurrentVersion, n.Config.NetworkID, n.Config.AdvertisedIPs, n.Log, n.LogFactory, n.DB, n.Config.ProfileDir, n.chainManager, n.vmManager, &n.aliases, &n.endpoints, &n.upgrades, n.ValidatorAPI.Connections(), n.ConsensusAPI, n.chainManager, n.ValidatorAPI, n.ConsensusAPI, n.ConsensusAPI, &n.APIServer, n)
 		n.APIServer.AddRoute(service, &sync.RWMutex{}, "admin", "", n.HTTPLog)
 	}
 }
@@ -463,6 +816,299 @@ func (n *Node) initIPCAPI() {
 	}
 }
 
+// initIndexAPI initializes the indexer and the Index API service
+// Assumes n.DB, n.chainManager and the event dispatchers already initialized,
+// and chains already aliased
+func (n *Node) initIndexAPI() error {
+	if !n.Config.IndexAPIEnabled {
+		switch {
+		case n.Config.GraphQLAPIEnabled:
+			return errGraphQLWithoutIndex
+		case n.Config.IndexAddresses:
+			return errAddressesWithoutIndex
+		}
+		return nil
+	}
+	n.Log.Info("initializing Index API")
+
+	chainIDs := []ids.ID(nil)
+	for _, chain := range n.Config.IndexedChains {
+		chainID, err := n.chainManager.Lookup(chain)
+		if err != nil {
+			if chainID, err = ids.FromString(chain); err != nil {
+				return fmt.Errorf("couldn't find indexed chain %s", chain)
+			}
+		}
+		chainIDs = append(chainIDs, chainID)
+	}
+
+	indexDB := prefixdb.New([]byte("index"), n.DB)
+	n.indexer.Initialize(n.Log, indexDB, chainIDs, n.chainManager)
+	if err := n.indexer.Register(n.DecisionDispatcher, n.ConsensusDispatcher); err != nil {
+		return err
+	}
+	if n.Config.IndexAddresses {
+		n.chainManager.AddRegistrant(&n.indexer)
+	}
+	n.APIServer.AddRoute(n.indexer.CreateHandler(), &sync.RWMutex{}, "index", "", n.HTTPLog)
+	n.APIServer.AddRoute(n.indexer.CreateChainHandler(), &sync.RWMutex{}, "index", indexer.ChainEndpoint, n.HTTPLog)
+
+	if n.Config.GraphQLAPIEnabled {
+		n.Log.Info("initializing GraphQL API")
+		n.APIServer.AddRoute(n.indexer.CreateGraphQLHandler(&n.APIServer), &sync.RWMutex{}, "graphql", "", n.HTTPLog)
+	}
+	return nil
+}
+
+// initEventsAPI initializes the stream of accepted containers served by the
+// Events API, as server-sent events of each chain and over websockets
+// Assumes n.chainManager and the event dispatchers already initialized
+func (n *Node) initEventsAPI() error {
+	if !n.Config.EventsAPIEnabled {
+		return nil
+	}
+	n.Log.Info("initializing Events API")
+	n.events.Initialize(n.Log, n.chainManager, n.Config.NetworkID)
+	if err := n.events.Register(n.DecisionDispatcher, n.ConsensusDispatcher); err != nil {
+		return err
+	}
+	if err := n.APIServer.AddRoute(n.events.CreateWebSocketHandler(), &sync.RWMutex{}, "events", events.WebSocketEndpoint, n.HTTPLog); err != nil {
+		return err
+	}
+	return n.APIServer.AddRoute(n.events.CreateHandler(), &sync.RWMutex{}, "events", events.ChainEndpoint, n.HTTPLog)
+}
+
+// initCoordinatorAPI initializes the coordinator and the Coordinator API
+// service
+// Assumes n.DB, n.chainManager and n.APIServer already initialized
+func (n *Node) initCoordinatorAPI() error {
+	if !n.Config.CoordinatorAPIEnabled {
+		return nil
+	}
+	n.Log.Info("initializing Coordinator API")
+	coordinatorDB := prefixdb.New([]byte("coordinator"), n.DB)
+	if err := n.coordinator.Initialize(n.Log, coordinatorDB, n.chainManager, &n.APIServer); err != nil {
+		return err
+	}
+	return n.APIServer.AddRoute(n.coordinator.CreateHandler(), &sync.RWMutex{}, "coordinator", "", n.HTTPLog)
+}
+
+// initHealthAPI initializes the health checks of the database, networking,
+// chains, logs and keystore, and the Health API service
+// Assumes n.DB, n.ValidatorAPI, n.chainManager, n.keystoreServer and
+// n.APIServer already initialized, and the Platform Chain already created
+func (n *Node) initHealthAPI() error {
+	if !n.Config.HealthAPIEnabled {
+		return nil
+	}
+	n.Log.Info("initializing Health API")
+	n.health.Initialize(n.Log, healthCheckFrequency)
+	n.health.SetOverrides(n.Config.HealthChecks)
+
+	healthDB := prefixdb.New([]byte("health"), n.DB)
+	dbCheck := health.CheckerFunc(func() (interface{}, error) {
+		if err := healthDB.Put(healthCheckKey, healthCheckKey); err != nil {
+			return nil, err
+		}
+		if _, err := healthDB.Get(healthCheckKey); err != nil {
+			return nil, err
+		}
+		return nil, healthDB.Delete(healthCheckKey)
+	})
+
+	// A node without bootstrap peers may be the only node of its network
+	requirePeers := len(n.Config.BootstrapPeers) > 0
+	networkCheck := health.CheckerFunc(func() (interface{}, error) {
+		numPeers := n.ValidatorAPI.Connections().Len()
+		details := map[string]int{"connectedPeers": numPeers}
+		if requirePeers && numPeers == 0 {
+			return details, errNoPeers
+		}
+		return details, nil
+	})
+
+	// The details have the phase of each chain, so a probe shows which
+	// chains the node is waiting on
+	chainsCheck := health.CheckerFunc(func() (interface{}, error) {
+		bootstrapping := []string{}
+		phases := map[string]string{}
+		for _, chain := range n.chainManager.BootstrapProgress() {
+			phases[chain.ChainID.String()] = chain.Phase.String()
+			if chain.Phase != common.Bootstrapped {
+				bootstrapping = append(bootstrapping, chain.ChainID.String())
+			}
+		}
+		sort.Strings(bootstrapping)
+		details := map[string]interface{}{
+			"bootstrapping": bootstrapping,
+			"phases":        phases,
+		}
+		if len(bootstrapping) > 0 {
+			return details, errChainsBootstrapping
+		}
+		return details, nil
+	})
+
+	// The details have the progress of pruning each chain, so a probe shows
+	// how much each chain has pruned and retains
+	pruningCheck := health.CheckerFunc(func() (interface{}, error) {
+		failing := []string{}
+		progress := map[string]interface{}{}
+		for _, chain := range n.chainManager.PruningProgress() {
+			details := map[string]interface{}{
+				"pruned":   chain.Pruned,
+				"retained": chain.Retained,
+			}
+			if chain.Err != nil {
+				details["error"] = chain.Err.Error()
+				failing = append(failing, chain.ChainID.String())
+			}
+			progress[chain.ChainID.String()] = details
+		}
+		sort.Strings(failing)
+		details := map[string]interface{}{
+			"failing": failing,
+			"chains":  progress,
+		}
+		if len(failing) > 0 {
+			return details, errPruningFailed
+		}
+		return details, nil
+	})
+
+	// The janitor keeps the rotated log files under the budget, but not the
+	// files being written to
+	maxLogDiskUsage := n.Config.LoggingConfig.MaxDiskUsage
+	logsCheck := health.CheckerFunc(func() (interface{}, error) {
+		usage := n.LogFactory.DiskUsage()
+		details := map[string]int64{"diskUsage": usage}
+		if maxLogDiskUsage > 0 {
+			details["maxDiskUsage"] = maxLogDiskUsage
+			if usage > maxLogDiskUsage {
+				return details, errLogDiskUsage
+			}
+		}
+		return details, nil
+	})
+
+	// A database that doesn't respond is as bad as one that fails
+	if err := n.health.RegisterCheckWithConfig("database", dbCheck, health.CheckConfig{
+		Timeout:          databaseCheckTimeout,
+		FailureThreshold: 1,
+	}); err != nil {
+		return err
+	}
+	if err := n.health.RegisterCheck("network", networkCheck, networkFailureThreshold); err != nil {
+		return err
+	}
+	if err := n.health.RegisterReadinessCheck("chains", chainsCheck, 1); err != nil {
+		return err
+	}
+	if err := n.health.RegisterCheck("logs", logsCheck, 1); err != nil {
+		return err
+	}
+	if err := n.health.RegisterCheck("keystore", &n.keystoreServer, 1); err != nil {
+		return err
+	}
+	if n.Config.Pruning.Enabled {
+		if err := n.health.RegisterCheck("pruning", pruningCheck, 1); err != nil {
+			return err
+		}
+	}
+	for extension, handler := range n.health.CreateHandlers() {
+		if err := n.APIServer.AddRoute(handler, &sync.RWMutex{}, "health", extension, n.HTTPLog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// initSupervisor writes the node's PID file and starts the processes that run
+// alongside the node.
+// Assumes n.health is initialized if the Health API is enabled
+func (n *Node) initSupervisor() error {
+	if n.Config.PIDDir != "" {
+		if err := os.MkdirAll(n.Config.PIDDir, os.ModePerm); err != nil {
+			return err
+		}
+		if err := supervisor.WritePIDFile(filepath.Join(n.Config.PIDDir, pidFileName), os.Getpid()); err != nil {
+			return err
+		}
+	}
+
+	n.supervisor.Initialize(n.Log, n.Config.PIDDir)
+	for _, config := range n.Config.SupervisedProcesses {
+		n.Log.Info("starting supervised process %s", config.Name)
+		if err := n.supervisor.Start(config); err != nil {
+			return err
+		}
+	}
+	if !n.Config.HealthAPIEnabled || len(n.Config.SupervisedProcesses) == 0 {
+		return nil
+	}
+	return n.health.RegisterCheck("processes", &n.supervisor, processesFailureThreshold)
+}
+
+// initGatewayAPI serves the Info, Health, Keystore and Chains services over
+// gRPC on their own port, if configured to. Calls are authorized with the same
+// tokens as the HTTP APIs, and use TLS if the HTTP APIs do.
+// Assumes n.APIServer, n.chainManager, n.keystoreServer and n.health already
+// initialized
+func (n *Node) initGatewayAPI() error {
+	if !n.Config.GRPCEnabled {
+		return nil
+	}
+	n.Log.Info("initializing gRPC gateway")
+
+	var authorizer api.Authorizer
+	if n.authEnabled() {
+		authorizer = &n.auth
+	}
+	certFile, keyFile := "", ""
+	if n.Config.EnableHTTPS {
+		certFile, keyFile = n.Config.HTTPSCertFile, n.Config.HTTPSKeyFile
+	}
+	if err := n.gateway.Initialize(n.Log, n.Config.GRPCPort, certFile, keyFile, authorizer); err != nil {
+		return err
+	}
+
+	n.gateway.RegisterInfo(n.ID, networking.CurrentVersion, n.Config.NetworkID, n.chainManager)
+	if n.Config.HealthAPIEnabled {
+		n.gateway.RegisterHealth(&n.health)
+	}
+	if n.Config.KeystoreAPIEnabled {
+		n.gateway.RegisterKeystore(&n.keystoreServer)
+	}
+	n.gateway.RegisterChains(n.chainManager, &n.APIServer)
+
+	go n.Log.RecoverAndPanic(func() {
+		if err := n.gateway.Dispatch(); err != nil {
+			n.Log.Error("gRPC gateway stopped with %s", err)
+		}
+	})
+	return nil
+}
+
+// initRuntimeAliases gives chains, VMs and endpoints the aliases that were
+// added to them through the Admin API before the node last stopped, and
+// disables the endpoints that were disabled, either then or by the config
+// Assumes n.DB, n.chainManager and n.vmManager already initialized, and the
+// genesis aliases already given
+func (n *Node) initRuntimeAliases() error {
+	aliasDB := prefixdb.New([]byte("aliases"), n.DB)
+	n.aliases.Initialize(n.Log, aliasDB, n.chainManager, n.vmManager, &n.APIServer)
+	if err := n.aliases.Restore(); err != nil {
+		return err
+	}
+
+	endpointDB := prefixdb.New([]byte("endpoints"), n.DB)
+	n.endpoints.Initialize(n.Log, endpointDB, &n.APIServer)
+	if err := n.endpoints.Restore(); err != nil {
+		return err
+	}
+	return n.endpoints.SetConfigured(n.Config.DisabledEndpoints)
+}
+
 // Give chains and VMs aliases as specified by the genesis information
 func (n *Node) initAliases() {
 	n.Log.Info("initializing aliases")
@@ -496,16 +1142,27 @@ func (n *Node) Initialize(Config *Config, logger logging.Logger, logFactory logg
 	}
 	n.HTTPLog = httpLog
 
-	n.initDatabase() // Set up the node's database
+	if err = n.initMetrics(); err != nil { // Set up the node-wide metrics registry
+		return fmt.Errorf("problem initializing metrics: %w", err)
+	}
+
+	if err = n.initDatabase(); err != nil { // Set up the node's database
+		return fmt.Errorf("problem initializing database: %w", err)
+	}
+	n.initSharedMemory() // Set up the memory the chains share
 
 	if err = n.initNodeID(); err != nil { // Derive this node's ID
 		return fmt.Errorf("problem initializing staker ID: %w", err)
 	}
 
 	// Start HTTP APIs
-	n.initAPIServer()   // Start the API Server
-	n.initKeystoreAPI() // Start the Keystore API
-	n.initMetricsAPI()  // Start the Metrics API
+	if err = n.initAPIServer(); err != nil { // Start the API Server
+		return fmt.Errorf("problem initializing API server: %w", err)
+	}
+	if err = n.initKeystoreAPI(); err != nil { // Start the Keystore API
+		return fmt.Errorf("problem initializing keystore: %w", err)
+	}
+	n.initMetricsAPI() // Start the Metrics API
 
 	// Start node-to-node consensus server
 	if err = n.initNetlib(); err != nil { // Set up all networking
@@ -522,10 +1179,49 @@ func (n *Node) Initialize(Config *Config, logger logging.Logger, logFactory logg
 		n.initClients() // Set up the client servers
 	}
 
+	n.initAliases() // Set up aliases
+
+	// Restore the aliases added through the Admin API
+	if err := n.initRuntimeAliases(); err != nil {
+		return fmt.Errorf("problem restoring aliases: %w", err)
+	}
+
 	n.initAdminAPI() // Start the Admin API
 	n.initIPCAPI()   // Start the IPC API
-	n.initAliases()  // Set up aliases
-	n.initChains()   // Start the Platform chain
+
+	// Start indexing accepted containers
+	if err := n.initIndexAPI(); err != nil {
+		return fmt.Errorf("problem initializing indexer: %w", err)
+	}
+
+	// Start streaming accepted containers
+	if err := n.initEventsAPI(); err != nil {
+		return fmt.Errorf("problem initializing event stream: %w", err)
+	}
+
+	// Start running cross-chain workflows
+	if err := n.initCoordinatorAPI(); err != nil {
+		return fmt.Errorf("problem initializing coordinator: %w", err)
+	}
+
+	n.initChains() // Start the Platform chain
+
+	// Start checking the node's health
+	if err := n.initHealthAPI(); err != nil {
+		return fmt.Errorf("problem initializing health checks: %w", err)
+	}
+
+	// Start the processes that run alongside the node
+	if err := n.initSupervisor(); err != nil {
+		return fmt.Errorf("problem initializing supervised processes: %w", err)
+	}
+
+	// Start serving over gRPC
+	if err := n.initGatewayAPI(); err != nil {
+		return fmt.Errorf("problem initializing gRPC gateway: %w", err)
+	}
+
+	n.initReload() // Reload the configuration on SIGHUP
 
 	return nil
 }
@@ -533,7 +1229,45 @@ func (n *Node) Initialize(Config *Config, logger logging.Logger, logFactory logg
 // Shutdown this node
 func (n *Node) Shutdown() {
 	n.Log.Info("shutting down the node")
+	n.stopReload()
+
+	// Stop accepting API requests, giving the ones being handled half of the
+	// time to shut down to finish, so that they don't use the chains or the
+	// database as they're closed
+	n.Log.Info("stopping the API servers")
+	ctx, cancel := context.WithTimeout(context.Background(), n.Config.ShutdownTimeout/2)
+	defer cancel()
+	if err := n.APIServer.Shutdown(ctx); err != nil {
+		n.Log.Warn("API requests didn't finish before the API server stopped: %s", err)
+	}
+	n.gateway.Shutdown()
+
+	n.Log.Info("disconnecting from the network")
 	n.ValidatorAPI.Shutdown()
 	n.ConsensusAPI.Shutdown()
+	n.coordinator.Shutdown()
+	n.health.Shutdown()
+
+	// Each chain's engine stops once it's handled the message it's handling
+	n.Log.Info("halting the chains")
 	n.chainManager.Shutdown()
+	n.sender.Shutdown()
+	for _, r := range n.relays {
+		if err := r.Close(); err != nil {
+			n.Log.Debug("failed to close the relay on %s due to %s", r.Addr(), err)
+		}
+	}
+
+	// The supervised processes get a quarter of the time to shut down before
+	// they're killed. VM plugins that are still running, because their chain
+	// didn't shut down, are killed at once.
+	n.Log.Info("stopping the supervised processes and plugins")
+	n.supervisor.Shutdown(n.Config.ShutdownTimeout / 4)
+	rpcchainvm.KillPlugins()
+	if n.Config.PIDDir != "" {
+		if err := supervisor.RemovePIDFile(filepath.Join(n.Config.PIDDir, pidFileName)); err != nil {
+			n.Log.Warn("couldn't remove the node's PID file due to %s", err)
+		}
+	}
+	n.LogFactory.Flush()
 }
@@ -0,0 +1,87 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	errNoReload = errors.New("the node's configuration can't be reloaded")
+)
+
+// ReloadConfig rereads the part of the configuration that may be changed while
+// the node is running, and applies it. The API server's TLS certificate is
+// also reloaded from its files. Options that were read are applied even if
+// others couldn't be.
+func (n *Node) ReloadConfig() error {
+	if n.Config.Reload == nil {
+		return errNoReload
+	}
+	config, err := n.Config.Reload()
+	if err != nil {
+		return fmt.Errorf("couldn't reread the configuration: %w", err)
+	}
+
+	n.LogFactory.SetLogLevel(config.LogLevel)
+	n.LogFactory.SetDisplayLevel(config.DisplayLevel)
+	n.APIServer.SetRateLimits(config.RateLimits)
+	n.APIServer.SetRequestLimits(config.RequestLimits)
+	n.ValidatorAPI.SetConnectionLimits(config.ConnectionLimits)
+	n.ValidatorAPI.SetMaxReconnectDelay(config.MaxReconnectDelay)
+	n.ConsensusAPI.SetThrottle(config.Throttle)
+	for _, peer := range config.BootstrapPeers {
+		if !peer.IP.Equal(n.Config.StakingIP) {
+			n.ValidatorAPI.Reconnect(peer.ID, peer.IP)
+		}
+	}
+
+	errs := wrappers.Errs{}
+	if err := n.ValidatorAPI.SetPeerListGossip(config.PeerListGossip); err != nil {
+		errs.Add(fmt.Errorf("couldn't change peer list gossip: %w", err))
+	}
+	if err := n.ConsensusAPI.SetContainerGossip(config.ContainerGossip); err != nil {
+		errs.Add(fmt.Errorf("couldn't change container gossip: %w", err))
+	}
+	if err := n.endpoints.SetConfigured(config.DisabledEndpoints); err != nil {
+		errs.Add(fmt.Errorf("couldn't change the disabled endpoints: %w", err))
+	}
+	if err := n.APIServer.ReloadCertificates(); err != nil {
+		errs.Add(fmt.Errorf("couldn't reload the API server's TLS certificate: %w", err))
+	}
+	if errs.Errored() {
+		return errs.Err
+	}
+	n.Log.Info("reloaded the configuration")
+	return nil
+}
+
+// initReload reloads the configuration whenever the node receives SIGHUP,
+// until it shuts down
+func (n *Node) initReload() {
+	n.reloadSignals = make(chan os.Signal, 1)
+	signal.Notify(n.reloadSignals, syscall.SIGHUP)
+	go n.Log.RecoverAndPanic(func() {
+		for range n.reloadSignals {
+			n.Log.Info("reloading the configuration due to SIGHUP")
+			if err := n.ReloadConfig(); err != nil {
+				n.Log.Warn("failed to reload the configuration: %s", err)
+			}
+		}
+	})
+}
+
+// stopReload stops reloading the configuration on SIGHUP
+func (n *Node) stopReload() {
+	if n.reloadSignals != nil {
+		signal.Stop(n.reloadSignals)
+		close(n.reloadSignals)
+	}
+}
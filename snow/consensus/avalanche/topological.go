@@ -291,7 +291,9 @@ func (ta *Topological) pushVotes(
 		}
 	}
 
-	return votes.Bag(ta.params.Alpha)
+	// The votes are only counted, so the order they're iterated over in
+	// doesn't matter
+	return votes.SortedBag(ta.params.Alpha)
 }
 
 // If I've already checked, do nothing
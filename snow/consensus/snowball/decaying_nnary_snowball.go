@@ -0,0 +1,96 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+// decayingNnarySnowball is a multi-color snowball instance whose counts of
+// successful polls decay, so that recent polls outweigh old ones. Under a long
+// running conflict, a choice that has stopped receiving votes loses the
+// preference after far fewer successful polls for another choice than it had.
+type decayingNnarySnowball struct {
+	// decay is the fraction, in (0, 1], of each choice's count that remains
+	// after each poll. If it's 1, counts never decay.
+	decay float64
+
+	// preference is the choice with the largest decayed number of successful
+	// polls. Ties are broken by switching choice lazily
+	preference ids.ID
+
+	// numSuccessfulPolls tracks the decayed number of successful network polls
+	// of the choices
+	numSuccessfulPolls map[[32]byte]float64
+
+	// snowflake wraps the n-nary snowflake logic
+	snowflake nnarySnowflake
+}
+
+// Initialize implements the NnarySnowball interface
+func (sb *decayingNnarySnowball) Initialize(betaVirtuous, betaRogue int, choice ids.ID) {
+	sb.preference = choice
+	sb.numSuccessfulPolls = make(map[[32]byte]float64)
+	sb.snowflake.Initialize(betaVirtuous, betaRogue, choice)
+}
+
+// Add implements the NnarySnowball interface
+func (sb *decayingNnarySnowball) Add(choice ids.ID) { sb.snowflake.Add(choice) }
+
+// Preference implements the NnarySnowball interface
+func (sb *decayingNnarySnowball) Preference() ids.ID {
+	// As in the naive snowball, a finalized snowflake choice is preferred
+	if sb.Finalized() {
+		return sb.snowflake.Preference()
+	}
+	return sb.preference
+}
+
+// RecordSuccessfulPoll implements the NnarySnowball interface
+func (sb *decayingNnarySnowball) RecordSuccessfulPoll(choice ids.ID) {
+	if sb.Finalized() {
+		return
+	}
+
+	sb.decayPolls()
+	key := choice.Key()
+	numSuccessfulPolls := sb.numSuccessfulPolls[key] + 1
+	sb.numSuccessfulPolls[key] = numSuccessfulPolls
+
+	// Every count decays by the same fraction, so only the choice that was
+	// voted for can overtake the preference
+	if numSuccessfulPolls > sb.numSuccessfulPolls[sb.preference.Key()] {
+		sb.preference = choice
+	}
+
+	sb.snowflake.RecordSuccessfulPoll(choice)
+}
+
+// RecordUnsuccessfulPoll implements the NnarySnowball interface
+func (sb *decayingNnarySnowball) RecordUnsuccessfulPoll() {
+	if !sb.Finalized() {
+		sb.decayPolls()
+	}
+	sb.snowflake.RecordUnsuccessfulPoll()
+}
+
+// Finalized implements the NnarySnowball interface
+func (sb *decayingNnarySnowball) Finalized() bool { return sb.snowflake.Finalized() }
+
+func (sb *decayingNnarySnowball) String() string {
+	return fmt.Sprintf("SB(Preference = %s, NumSuccessfulPolls = %.2f, SF = %s)",
+		sb.preference, sb.numSuccessfulPolls[sb.preference.Key()], &sb.snowflake)
+}
+
+// decayPolls decays the count of every choice by one poll
+func (sb *decayingNnarySnowball) decayPolls() {
+	if sb.decay >= 1 {
+		return
+	}
+	for key, numSuccessfulPolls := range sb.numSuccessfulPolls {
+		sb.numSuccessfulPolls[key] = numSuccessfulPolls * sb.decay
+	}
+}
@@ -0,0 +1,118 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"testing"
+)
+
+func TestDecayingNnarySnowball(t *testing.T) {
+	betaVirtuous := 2
+	betaRogue := 2
+
+	sb := decayingNnarySnowball{decay: 1}
+	sb.Initialize(betaVirtuous, betaRogue, Red)
+	sb.Add(Blue)
+	sb.Add(Green)
+
+	if pref := sb.Preference(); !Red.Equals(pref) {
+		t.Fatalf("Wrong preference. Expected %s got %s", Red, pref)
+	} else if sb.Finalized() {
+		t.Fatalf("Finalized too early")
+	}
+
+	sb.RecordSuccessfulPoll(Blue)
+
+	if pref := sb.Preference(); !Blue.Equals(pref) {
+		t.Fatalf("Wrong preference. Expected %s got %s", Blue, pref)
+	} else if sb.Finalized() {
+		t.Fatalf("Finalized too early")
+	}
+
+	sb.RecordSuccessfulPoll(Red)
+
+	if pref := sb.Preference(); !Blue.Equals(pref) {
+		t.Fatalf("Wrong preference. Expected %s got %s", Blue, pref)
+	} else if sb.Finalized() {
+		t.Fatalf("Finalized too early")
+	}
+
+	sb.RecordSuccessfulPoll(Blue)
+	sb.RecordSuccessfulPoll(Blue)
+
+	if pref := sb.Preference(); !Blue.Equals(pref) {
+		t.Fatalf("Wrong preference. Expected %s got %s", Blue, pref)
+	} else if !sb.Finalized() {
+		t.Fatalf("Should be finalized")
+	}
+}
+
+func TestDecayingNnarySnowballSwitchesSooner(t *testing.T) {
+	betaVirtuous := 100
+	betaRogue := 100
+
+	naive := nnarySnowball{}
+	naive.Initialize(betaVirtuous, betaRogue, Red)
+	naive.Add(Blue)
+
+	decaying := decayingNnarySnowball{decay: .5}
+	decaying.Initialize(betaVirtuous, betaRogue, Red)
+	decaying.Add(Blue)
+
+	// Red has built up a lead over a long running conflict
+	for i := 0; i < 10; i++ {
+		naive.RecordSuccessfulPoll(Red)
+		naive.RecordUnsuccessfulPoll()
+		decaying.RecordSuccessfulPoll(Red)
+		decaying.RecordUnsuccessfulPoll()
+	}
+
+	// Red's decayed polls are 0.5 + 0.25 + ... < 1, so one poll for blue
+	// overtakes them
+	naive.RecordSuccessfulPoll(Blue)
+	decaying.RecordSuccessfulPoll(Blue)
+
+	if pref := naive.Preference(); !Red.Equals(pref) {
+		t.Fatalf("Wrong preference. Expected %s got %s", Red, pref)
+	}
+	if pref := decaying.Preference(); !Blue.Equals(pref) {
+		t.Fatalf("Wrong preference. Expected %s got %s", Blue, pref)
+	}
+}
+
+func TestDecayingNnarySnowballUnsuccessfulPolls(t *testing.T) {
+	betaVirtuous := 3
+	betaRogue := 3
+
+	sb := decayingNnarySnowball{decay: .5}
+	sb.Initialize(betaVirtuous, betaRogue, Red)
+	sb.Add(Blue)
+
+	sb.RecordSuccessfulPoll(Blue)
+	sb.RecordSuccessfulPoll(Blue)
+
+	// Decaying every count equally doesn't change the preference
+	for i := 0; i < 10; i++ {
+		sb.RecordUnsuccessfulPoll()
+	}
+
+	if pref := sb.Preference(); !Blue.Equals(pref) {
+		t.Fatalf("Wrong preference. Expected %s got %s", Blue, pref)
+	} else if sb.Finalized() {
+		t.Fatalf("Finalized too early")
+	}
+
+	sb.RecordSuccessfulPoll(Red)
+
+	if pref := sb.Preference(); !Red.Equals(pref) {
+		t.Fatalf("Wrong preference. Expected %s got %s", Red, pref)
+	} else if sb.Finalized() {
+		t.Fatalf("Finalized too early")
+	}
+
+	expected := "SB(Preference = LUC1cmcxnfNR9LdkACS2ccGKLEK7SYqB4gLLTycQfg1koyfSq, NumSuccessfulPolls = 1.00, SF = SF(Preference = LUC1cmcxnfNR9LdkACS2ccGKLEK7SYqB4gLLTycQfg1koyfSq, Confidence = 1, Finalized = false))"
+	if str := sb.String(); str != expected {
+		t.Fatalf("Wrong state. Expected:\n%s\nGot:\n%s", expected, str)
+	}
+}
@@ -4,6 +4,8 @@
 package snowball
 
 import (
+	"fmt"
+
 	"github.com/ava-labs/gecko/ids"
 )
 
@@ -13,18 +15,39 @@ type FlatFactory struct{}
 // New implements Factory
 func (FlatFactory) New() Consensus { return &Flat{} }
 
+// DecayingFlatFactory implements Factory by returning a flat struct whose
+// counts of successful polls decay by Decay, in (0, 1], after each poll
+type DecayingFlatFactory struct{ Decay float64 }
+
+// New implements Factory
+func (f DecayingFlatFactory) New() Consensus {
+	return &Flat{snowball: &decayingNnarySnowball{decay: f.Decay}}
+}
+
+// Valid returns nil if successful polls can be decayed by Decay
+func (f DecayingFlatFactory) Valid() error {
+	if !(f.Decay > 0 && f.Decay <= 1) {
+		return fmt.Errorf("decay = %f: Fails the condition that: 0 < decay <= 1", f.Decay)
+	}
+	return nil
+}
+
 // Flat is a naive implementation of a multi-choice snowball instance
 type Flat struct {
 	// params contains all the configurations of a snowball instance
 	params Parameters
 
-	// snowball wraps the n-nary snowball logic
-	snowball nnarySnowball
+	// snowball wraps the n-nary snowball logic. If nil, the naive
+	// implementation is used.
+	snowball NnarySnowball
 }
 
 // Initialize implements the Consensus interface
 func (f *Flat) Initialize(params Parameters, choice ids.ID) {
 	f.params = params
+	if f.snowball == nil {
+		f.snowball = &nnarySnowball{}
+	}
 	f.snowball.Initialize(params.BetaVirtuous, params.BetaRogue, choice)
 }
 
@@ -13,6 +13,21 @@ import (
 
 func TestFlatParams(t *testing.T) { ParamsTest(t, FlatFactory{}) }
 
+func TestDecayingFlatParams(t *testing.T) { ParamsTest(t, DecayingFlatFactory{Decay: .5}) }
+
+func TestDecayingFlatFactoryValid(t *testing.T) {
+	for _, decay := range []float64{.5, 1} {
+		if err := (DecayingFlatFactory{Decay: decay}).Valid(); err != nil {
+			t.Fatalf("Decay %f should be valid: %s", decay, err)
+		}
+	}
+	for _, decay := range []float64{0, -.5, 1.5} {
+		if err := (DecayingFlatFactory{Decay: decay}).Valid(); err == nil {
+			t.Fatalf("Decay %f should be invalid", decay)
+		}
+	}
+}
+
 func TestFlat(t *testing.T) {
 	params := Parameters{
 		Metrics: prometheus.NewRegistry(),
@@ -0,0 +1,148 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Discarder is a Consensus instance that should be told when it's dropped
+// before it finalizes
+type Discarder interface {
+	// Discard marks that the instance won't be polled again
+	Discard()
+}
+
+// metrics are shared by every instance a metered factory creates
+type metrics struct {
+	pollsToFinalization prometheus.Histogram
+	preferenceChanges   prometheus.Counter
+	unsuccessfulPolls   prometheus.Counter
+	outstanding         prometheus.Gauge
+}
+
+// MeteredFactory implements Factory by returning the instances of another
+// factory, whose polls are recorded in prometheus metrics
+type MeteredFactory struct {
+	factory Factory
+	metrics *metrics
+}
+
+// NewMeteredFactory returns a factory whose instances are created by
+// [factory], or are trees if it's nil, and whose polls are recorded in metrics
+// registered with [registerer] under [namespace]
+func NewMeteredFactory(factory Factory, namespace string, registerer prometheus.Registerer) (*MeteredFactory, error) {
+	if factory == nil {
+		factory = TreeFactory{}
+	}
+	m := &metrics{
+		pollsToFinalization: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "snowball_polls_to_finalization",
+			Help:      "Number of polls snowball instances took to finalize",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		}),
+		preferenceChanges: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "snowball_preference_changes",
+			Help:      "Number of times a snowball instance changed its preference",
+		}),
+		unsuccessfulPolls: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "snowball_unsuccessful_polls",
+			Help:      "Number of polls of snowball instances in which no choice got alpha votes",
+		}),
+		outstanding: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "snowball_outstanding",
+			Help:      "Number of snowball instances that haven't finalized",
+		}),
+	}
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.pollsToFinalization),
+		registerer.Register(m.preferenceChanges),
+		registerer.Register(m.unsuccessfulPolls),
+		registerer.Register(m.outstanding),
+	)
+	return &MeteredFactory{
+		factory: factory,
+		metrics: m,
+	}, errs.Err
+}
+
+// New implements Factory
+func (f *MeteredFactory) New() Consensus {
+	return &meteredConsensus{
+		Consensus: f.factory.New(),
+		metrics:   f.metrics,
+	}
+}
+
+// meteredConsensus records the polls of the instance it wraps
+type meteredConsensus struct {
+	Consensus
+	metrics *metrics
+
+	// numPolls is the number of polls this instance has recorded
+	numPolls int
+
+	// done is true once this instance stopped being outstanding
+	done bool
+}
+
+// Initialize implements the Consensus interface
+func (sb *meteredConsensus) Initialize(params Parameters, choice ids.ID) {
+	sb.Consensus.Initialize(params, choice)
+	sb.metrics.outstanding.Inc()
+	sb.finalize()
+}
+
+// RecordPoll implements the Consensus interface
+func (sb *meteredConsensus) RecordPoll(votes ids.Bag) {
+	if sb.Finalized() {
+		sb.Consensus.RecordPoll(votes)
+		return
+	}
+
+	pref := sb.Preference()
+	sb.numPolls++
+	if _, numVotes := votes.Mode(); numVotes < sb.Parameters().Alpha {
+		sb.metrics.unsuccessfulPolls.Inc()
+	}
+	sb.Consensus.RecordPoll(votes)
+	if !pref.Equals(sb.Preference()) {
+		sb.metrics.preferenceChanges.Inc()
+	}
+	sb.finalize()
+}
+
+// RecordUnsuccessfulPoll implements the Consensus interface
+func (sb *meteredConsensus) RecordUnsuccessfulPoll() {
+	if !sb.Finalized() {
+		sb.metrics.unsuccessfulPolls.Inc()
+	}
+	sb.Consensus.RecordUnsuccessfulPoll()
+}
+
+// Discard implements the Discarder interface
+func (sb *meteredConsensus) Discard() {
+	if !sb.done {
+		sb.done = true
+		sb.metrics.outstanding.Dec()
+	}
+}
+
+// finalize records that this instance finalized, if it has
+func (sb *meteredConsensus) finalize() {
+	if !sb.done && sb.Finalized() {
+		sb.done = true
+		sb.metrics.outstanding.Dec()
+		sb.metrics.pollsToFinalization.Observe(float64(sb.numPolls))
+	}
+}
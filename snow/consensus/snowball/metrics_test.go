@@ -0,0 +1,117 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestMeteredParams(t *testing.T) {
+	factory, err := NewMeteredFactory(FlatFactory{}, "", prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ParamsTest(t, factory)
+}
+
+func TestMeteredFactory(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	factory, err := NewMeteredFactory(FlatFactory{}, "", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := Parameters{
+		Metrics: prometheus.NewRegistry(),
+		K:       2, Alpha: 2, BetaVirtuous: 1, BetaRogue: 2,
+	}
+	finalized := factory.New()
+	finalized.Initialize(params, Red)
+	finalized.Add(Blue)
+	discarded := factory.New()
+	discarded.Initialize(params, Red)
+
+	if outstanding := metricValue(t, registry, "snowball_outstanding"); outstanding != 2 {
+		t.Fatalf("Expected 2 outstanding instances, got %f", outstanding)
+	}
+
+	oneBlue := ids.Bag{}
+	oneBlue.Add(Blue)
+	twoBlue := ids.Bag{}
+	twoBlue.Add(Blue, Blue)
+
+	finalized.RecordPoll(oneBlue)
+	finalized.RecordPoll(twoBlue)
+	finalized.RecordPoll(twoBlue)
+	if !finalized.Finalized() {
+		t.Fatalf("Should be finalized")
+	}
+	// Polls after finalization aren't counted
+	finalized.RecordPoll(oneBlue)
+	discarded.(Discarder).Discard()
+	discarded.(Discarder).Discard()
+
+	if outstanding := metricValue(t, registry, "snowball_outstanding"); outstanding != 0 {
+		t.Fatalf("Expected 0 outstanding instances, got %f", outstanding)
+	}
+	if unsuccessful := metricValue(t, registry, "snowball_unsuccessful_polls"); unsuccessful != 1 {
+		t.Fatalf("Expected 1 unsuccessful poll, got %f", unsuccessful)
+	}
+	if changes := metricValue(t, registry, "snowball_preference_changes"); changes != 1 {
+		t.Fatalf("Expected 1 preference change, got %f", changes)
+	}
+
+	if count, sum := histogramValue(t, registry, "snowball_polls_to_finalization"); count != 1 {
+		t.Fatalf("Expected 1 finalization, got %d", count)
+	} else if sum != 3 {
+		t.Fatalf("Expected finalization after 3 polls, got %f", sum)
+	}
+}
+
+func TestMeteredFactoryRegisterError(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if _, err := NewMeteredFactory(nil, "", registry); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewMeteredFactory(nil, "", registry); err == nil {
+		t.Fatalf("Should have failed to register the metrics twice")
+	}
+}
+
+// metricValue returns the value of the gauge or counter [name] in [registry]
+func metricValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() == name {
+			metric := family.GetMetric()[0]
+			return metric.GetGauge().GetValue() + metric.GetCounter().GetValue()
+		}
+	}
+	t.Fatalf("Metric %s wasn't registered", name)
+	return 0
+}
+
+// histogramValue returns the number and sum of the observations of the
+// histogram [name] in [registry]
+func histogramValue(t *testing.T, registry *prometheus.Registry, name string) (uint64, float64) {
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() == name {
+			histogram := family.GetMetric()[0].GetHistogram()
+			return histogram.GetSampleCount(), histogram.GetSampleSum()
+		}
+	}
+	t.Fatalf("Metric %s wasn't registered", name)
+	return 0, 0
+}
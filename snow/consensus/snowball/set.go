@@ -0,0 +1,270 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+// noChoice marks the end of an instance's list of choices
+const noChoice = -1
+
+var (
+	errDuplicateChoice = errors.New("choice is already in the set")
+	errUnknownInstance = errors.New("unknown snowball instance")
+)
+
+// Set is many flat snowball instances, whose state is kept in storage that is
+// shared between them and reused once they're removed. A poll of the whole set
+// is recorded with one call, which reuses the set's storage instead of
+// allocating.
+//
+// Each choice belongs to one instance. The instances behave as Flat instances
+// that are each given the votes for their own choices.
+type Set struct {
+	params Parameters
+
+	// choiceIndices maps each choice to its index in choices
+	choiceIndices map[[32]byte]int
+	choices       []setChoice
+	freeChoices   []int
+
+	instances     []setInstance
+	freeInstances []int
+
+	// The votes of the poll being recorded, by instance. Only the instances in
+	// polled have been written to.
+	pollVotes  []int
+	pollChoice []int
+	polled     []int
+}
+
+// setChoice is a choice of an instance of a Set
+type setChoice struct {
+	id       ids.ID
+	instance int
+
+	// numSuccessfulPolls is the number of successful polls of this choice
+	numSuccessfulPolls int
+
+	// next is the index of the instance's next choice, or noChoice
+	next int
+}
+
+// setInstance is the state of an instance of a Set. Its choices are indices
+// into the Set's choices.
+type setInstance struct {
+	live bool
+
+	// firstChoice starts the list of the instance's choices
+	firstChoice int
+
+	// preference is the choice with the most successful polls. Ties are
+	// broken by switching choice lazily.
+	preference         int
+	maxSuccessfulPolls int
+
+	// sfPreference is the choice that last had a successful poll, and
+	// confidence is how many successful polls in a row it had
+	sfPreference int
+	confidence   int
+
+	rogue     bool
+	finalized bool
+}
+
+// Initialize the set of instances that are decided with [params]
+func (s *Set) Initialize(params Parameters) {
+	s.params = params
+	s.choiceIndices = make(map[[32]byte]int)
+}
+
+// Parameters returns the parameters the instances are decided with
+func (s *Set) Parameters() Parameters { return s.params }
+
+// Len returns the number of instances in the set
+func (s *Set) Len() int { return len(s.instances) - len(s.freeInstances) }
+
+// NewInstance adds an instance whose initial choice is [choice], and returns
+// the instance's number
+func (s *Set) NewInstance(choice ids.ID) (int, error) {
+	if _, exists := s.choiceIndices[choice.Key()]; exists {
+		return 0, errDuplicateChoice
+	}
+
+	instance := len(s.instances)
+	if numFree := len(s.freeInstances); numFree > 0 {
+		instance = s.freeInstances[numFree-1]
+		s.freeInstances = s.freeInstances[:numFree-1]
+	} else {
+		s.instances = append(s.instances, setInstance{})
+		s.pollVotes = append(s.pollVotes, 0)
+		s.pollChoice = append(s.pollChoice, noChoice)
+	}
+
+	index := s.newChoice(choice, instance, noChoice)
+	s.instances[instance] = setInstance{
+		live:         true,
+		firstChoice:  index,
+		preference:   index,
+		sfPreference: index,
+	}
+	return instance, nil
+}
+
+// Add adds [choice] to the choices of [instance]
+func (s *Set) Add(instance int, choice ids.ID) error {
+	inst, err := s.instance(instance)
+	if err != nil {
+		return err
+	}
+	if _, exists := s.choiceIndices[choice.Key()]; exists {
+		return errDuplicateChoice
+	}
+
+	inst.firstChoice = s.newChoice(choice, instance, inst.firstChoice)
+	inst.rogue = true
+	return nil
+}
+
+// Remove removes [instance] and its choices from the set. Its number and
+// storage are reused by instances added later.
+func (s *Set) Remove(instance int) error {
+	inst, err := s.instance(instance)
+	if err != nil {
+		return err
+	}
+
+	for index := inst.firstChoice; index != noChoice; {
+		choice := &s.choices[index]
+		delete(s.choiceIndices, choice.id.Key())
+		next := choice.next
+		*choice = setChoice{}
+		s.freeChoices = append(s.freeChoices, index)
+		index = next
+	}
+	*inst = setInstance{}
+	s.freeInstances = append(s.freeInstances, instance)
+	return nil
+}
+
+// Preference returns the preferred choice of [instance]
+func (s *Set) Preference(instance int) (ids.ID, error) {
+	inst, err := s.instance(instance)
+	if err != nil {
+		return ids.ID{}, err
+	}
+	// As in the naive snowball, a finalized snowflake choice is preferred
+	if inst.finalized {
+		return s.choices[inst.sfPreference].id, nil
+	}
+	return s.choices[inst.preference].id, nil
+}
+
+// Finalized returns whether [instance] has finalized
+func (s *Set) Finalized(instance int) (bool, error) {
+	inst, err := s.instance(instance)
+	if err != nil {
+		return false, err
+	}
+	return inst.finalized, nil
+}
+
+// RecordPoll records the votes of a poll of every instance. An instance's poll
+// is successful if one of its choices got at least alpha votes. Votes for
+// choices that aren't in the set are ignored.
+func (s *Set) RecordPoll(votes ids.Bag) {
+	votes.ForEach(s.tallyVotes)
+
+	for instance := range s.instances {
+		inst := &s.instances[instance]
+		if !inst.live || inst.finalized {
+			continue
+		}
+		if s.pollVotes[instance] >= s.params.Alpha {
+			s.recordSuccessfulPoll(inst, s.pollChoice[instance])
+		} else {
+			inst.confidence = 0
+		}
+	}
+
+	for _, instance := range s.polled {
+		s.pollVotes[instance] = 0
+		s.pollChoice[instance] = noChoice
+	}
+	s.polled = s.polled[:0]
+}
+
+// tallyVotes records that the choice [key] got [numVotes] votes in the poll
+// being recorded
+func (s *Set) tallyVotes(key [32]byte, numVotes int) {
+	index, exists := s.choiceIndices[key]
+	if !exists {
+		return
+	}
+	instance := s.choices[index].instance
+	if s.pollChoice[instance] == noChoice {
+		s.polled = append(s.polled, instance)
+	}
+	if numVotes > s.pollVotes[instance] {
+		s.pollVotes[instance] = numVotes
+		s.pollChoice[instance] = index
+	}
+}
+
+// RecordUnsuccessfulPoll records an unsuccessful poll of every instance
+func (s *Set) RecordUnsuccessfulPoll() {
+	for instance := range s.instances {
+		s.instances[instance].confidence = 0
+	}
+}
+
+// recordSuccessfulPoll records a successful poll of [index] by [inst]
+func (s *Set) recordSuccessfulPoll(inst *setInstance, index int) {
+	choice := &s.choices[index]
+	choice.numSuccessfulPolls++
+	if choice.numSuccessfulPolls > inst.maxSuccessfulPolls {
+		inst.preference = index
+		inst.maxSuccessfulPolls = choice.numSuccessfulPolls
+	}
+
+	if inst.sfPreference == index {
+		inst.confidence++
+	} else {
+		inst.confidence = 1
+		inst.sfPreference = index
+	}
+	inst.finalized = (!inst.rogue && inst.confidence >= s.params.BetaVirtuous) ||
+		inst.confidence >= s.params.BetaRogue
+}
+
+// newChoice stores [choice] of [instance], followed by [next] in the list of
+// the instance's choices, and returns its index
+func (s *Set) newChoice(choice ids.ID, instance, next int) int {
+	c := setChoice{
+		id:       choice,
+		instance: instance,
+		next:     next,
+	}
+	index := len(s.choices)
+	if numFree := len(s.freeChoices); numFree > 0 {
+		index = s.freeChoices[numFree-1]
+		s.freeChoices = s.freeChoices[:numFree-1]
+		s.choices[index] = c
+	} else {
+		s.choices = append(s.choices, c)
+	}
+	s.choiceIndices[choice.Key()] = index
+	return index
+}
+
+// instance returns the state of [instance]
+func (s *Set) instance(instance int) (*setInstance, error) {
+	if instance < 0 || instance >= len(s.instances) || !s.instances[instance].live {
+		return nil, errUnknownInstance
+	}
+	return &s.instances[instance], nil
+}
@@ -0,0 +1,200 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestSet(t *testing.T) {
+	params := Parameters{
+		Metrics: prometheus.NewRegistry(),
+		K:       2, Alpha: 2, BetaVirtuous: 1, BetaRogue: 2,
+	}
+	s := Set{}
+	s.Initialize(params)
+
+	rogue, err := s.NewInstance(Red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(rogue, Blue); err != nil {
+		t.Fatal(err)
+	}
+	virtuous, err := s.NewInstance(Green)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.NewInstance(Blue); err != errDuplicateChoice {
+		t.Fatalf("Should have refused a choice of another instance")
+	}
+	if s.Len() != 2 {
+		t.Fatalf("Expected 2 instances, got %d", s.Len())
+	}
+
+	votes := ids.Bag{}
+	votes.AddCount(Blue, 2)
+	votes.AddCount(Green, 1)
+	s.RecordPoll(votes)
+
+	if pref, err := s.Preference(rogue); err != nil {
+		t.Fatal(err)
+	} else if !pref.Equals(Blue) {
+		t.Fatalf("Wrong preference. Expected %s got %s", Blue, pref)
+	}
+	if finalized, err := s.Finalized(rogue); err != nil {
+		t.Fatal(err)
+	} else if finalized {
+		t.Fatalf("Finalized too early")
+	}
+	if finalized, _ := s.Finalized(virtuous); finalized {
+		t.Fatalf("Finalized without alpha votes")
+	}
+
+	votes = ids.Bag{}
+	votes.AddCount(Blue, 2)
+	votes.AddCount(Green, 2)
+	s.RecordPoll(votes)
+
+	if finalized, _ := s.Finalized(rogue); !finalized {
+		t.Fatalf("Finalized too late")
+	}
+	if finalized, _ := s.Finalized(virtuous); !finalized {
+		t.Fatalf("Finalized too late")
+	}
+
+	if err := s.Remove(rogue); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Preference(rogue); err != errUnknownInstance {
+		t.Fatalf("Should have removed the instance")
+	}
+	// The removed instance's choices and number are reused
+	reused, err := s.NewInstance(Blue)
+	if err != nil {
+		t.Fatal(err)
+	} else if reused != rogue {
+		t.Fatalf("Expected instance %d to be reused, got %d", rogue, reused)
+	}
+	if finalized, _ := s.Finalized(reused); finalized {
+		t.Fatalf("Reused instance shouldn't be finalized")
+	}
+}
+
+// Instances of a set should decide as flat instances given the same votes
+func TestSetMatchesFlat(t *testing.T) {
+	params := Parameters{
+		Metrics: prometheus.NewRegistry(),
+		K:       5, Alpha: 3, BetaVirtuous: 3, BetaRogue: 5,
+	}
+	numInstances := 20
+	numChoices := 3
+
+	s := Set{}
+	s.Initialize(params)
+	flats := make([]Flat, numInstances)
+	choices := make([][]ids.ID, numInstances)
+	numbers := make([]int, numInstances)
+	for i := range flats {
+		for j := 0; j < numChoices; j++ {
+			choices[i] = append(choices[i], ids.Empty.Prefix(uint64(i), uint64(j)))
+		}
+		flats[i].Initialize(params, choices[i][0])
+		number, err := s.NewInstance(choices[i][0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		numbers[i] = number
+		for _, choice := range choices[i][1:] {
+			flats[i].Add(choice)
+			if err := s.Add(number, choice); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	source := rand.New(rand.NewSource(0))
+	for round := 0; round < 100; round++ {
+		all := ids.Bag{}
+		polls := make([]ids.Bag, numInstances)
+		for i := range flats {
+			for k := 0; k < params.K; k++ {
+				// Most votes go to the first two choices, so that some
+				// instances finalize and some switch preference
+				choice := choices[i][source.Intn(numChoices-1)]
+				if source.Intn(4) == 0 {
+					choice = choices[i][numChoices-1]
+				}
+				polls[i].Add(choice)
+				all.Add(choice)
+			}
+		}
+		if round%10 == 0 {
+			s.RecordUnsuccessfulPoll()
+		} else {
+			s.RecordPoll(all)
+		}
+
+		for i := range flats {
+			if round%10 == 0 {
+				flats[i].RecordUnsuccessfulPoll()
+			} else {
+				flats[i].RecordPoll(polls[i])
+			}
+			pref, _ := s.Preference(numbers[i])
+			finalized, _ := s.Finalized(numbers[i])
+			if !pref.Equals(flats[i].Preference()) {
+				t.Fatalf("Round %d, instance %d: expected preference %s got %s", round, i, flats[i].Preference(), pref)
+			} else if finalized != flats[i].Finalized() {
+				t.Fatalf("Round %d, instance %d: expected finalized = %v got %v", round, i, flats[i].Finalized(), finalized)
+			}
+		}
+	}
+}
+
+func BenchmarkSetRecordPoll(b *testing.B) {
+	params := Parameters{K: 20, Alpha: 14, BetaVirtuous: 1 << 30, BetaRogue: 1 << 30}
+	s := Set{}
+	s.Initialize(params)
+	votes := ids.Bag{}
+	for i := 0; i < 1000; i++ {
+		number, _ := s.NewInstance(ids.Empty.Prefix(uint64(i), 0))
+		choice := ids.Empty.Prefix(uint64(i), 1)
+		_ = s.Add(number, choice)
+		votes.AddCount(choice, 14)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.RecordPoll(votes)
+	}
+}
+
+// Without a set, the votes of a poll are split into a bag per instance
+func BenchmarkFlatRecordPoll(b *testing.B) {
+	params := Parameters{K: 20, Alpha: 14, BetaVirtuous: 1 << 30, BetaRogue: 1 << 30}
+	flats := make([]Flat, 1000)
+	choices := make([]ids.ID, len(flats))
+	votes := ids.Bag{}
+	for i := range flats {
+		flats[i].Initialize(params, ids.Empty.Prefix(uint64(i), 0))
+		choices[i] = ids.Empty.Prefix(uint64(i), 1)
+		flats[i].Add(choices[i])
+		votes.AddCount(choices[i], 14)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range flats {
+			poll := ids.Bag{}
+			poll.AddCount(choices[j], votes.Count(choices[j]))
+			flats[j].RecordPoll(poll)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// TraceEventType is the kind of call a trace event records
+type TraceEventType byte
+
+// The calls that are traced
+const (
+	TraceInitialize TraceEventType = iota
+	TraceAdd
+	TracePoll
+	TraceUnsuccessfulPoll
+	TraceFinalize
+)
+
+const (
+	// An event is its length, then its type, the unix time in nanoseconds it
+	// happened at and the number of the instance it happened to
+	traceLengthLen = wrappers.IntLen
+	traceHeaderLen = wrappers.ByteLen + wrappers.LongLen + wrappers.IntLen
+
+	// maxTraceEventLen bounds the events a reader accepts, so a corrupt length
+	// can't make it allocate without bound
+	maxTraceEventLen = 1 << 20
+)
+
+var (
+	errUnknownTraceEvent = errors.New("unknown trace event type")
+	errLongTraceEvent    = errors.New("trace event is too long")
+)
+
+func (t TraceEventType) String() string {
+	switch t {
+	case TraceInitialize:
+		return "Initialize"
+	case TraceAdd:
+		return "Add"
+	case TracePoll:
+		return "Poll"
+	case TraceUnsuccessfulPoll:
+		return "UnsuccessfulPoll"
+	case TraceFinalize:
+		return "Finalize"
+	default:
+		return fmt.Sprintf("Unknown(%d)", byte(t))
+	}
+}
+
+// TraceEvent is a call to a traced instance
+type TraceEvent struct {
+	Type TraceEventType
+	Time time.Time
+
+	// Instance is the number of the instance, in the order the instances were
+	// created
+	Instance uint32
+
+	// Params are the parameters of an Initialize event. Their namespace and
+	// metrics aren't traced.
+	Params Parameters
+
+	// Choice is the initial choice of an Initialize event, the new choice of
+	// an Add event and the finalized choice of a Finalize event
+	Choice ids.ID
+
+	// Votes are the votes of a Poll event
+	Votes ids.Bag
+}
+
+// TracedFactory implements Factory by returning the instances of another
+// factory, whose calls are appended to an event log. The instances must be used
+// by one goroutine at a time, as consensus is.
+type TracedFactory struct {
+	factory Factory
+	w       io.Writer
+	clock   timer.Clock
+
+	// numInstances is the number of instances this factory has created
+	numInstances uint32
+
+	// err is the first error writing the log. Events aren't written after it.
+	err error
+}
+
+// NewTracedFactory returns a factory whose instances are created by [factory],
+// or are trees if it's nil, and whose calls are written to [w]. Each event is
+// written to [w] with one call to Write, so [w] should be opened for appending.
+func NewTracedFactory(factory Factory, w io.Writer) *TracedFactory {
+	if factory == nil {
+		factory = TreeFactory{}
+	}
+	return &TracedFactory{
+		factory: factory,
+		w:       w,
+	}
+}
+
+// New implements Factory
+func (f *TracedFactory) New() Consensus {
+	sb := &tracedConsensus{
+		Consensus: f.factory.New(),
+		factory:   f,
+		instance:  f.numInstances,
+	}
+	f.numInstances++
+	return sb
+}
+
+// Err returns the error that stopped events from being written, if any
+func (f *TracedFactory) Err() error { return f.err }
+
+// write appends [event] to the log
+func (f *TracedFactory) write(event *TraceEvent) {
+	if f.err != nil {
+		return
+	}
+	event.Time = f.clock.Time()
+	bytes, err := marshalTraceEvent(event)
+	if err == nil {
+		_, err = f.w.Write(bytes)
+	}
+	f.err = err
+}
+
+// tracedConsensus traces the calls to the instance it wraps
+type tracedConsensus struct {
+	Consensus
+	factory  *TracedFactory
+	instance uint32
+
+	// finalized is true once this instance's finalization was traced
+	finalized bool
+}
+
+// Initialize implements the Consensus interface
+func (sb *tracedConsensus) Initialize(params Parameters, choice ids.ID) {
+	sb.factory.write(&TraceEvent{
+		Type:     TraceInitialize,
+		Instance: sb.instance,
+		Params:   params,
+		Choice:   choice,
+	})
+	sb.Consensus.Initialize(params, choice)
+	sb.finalize()
+}
+
+// Add implements the Consensus interface
+func (sb *tracedConsensus) Add(choice ids.ID) {
+	sb.factory.write(&TraceEvent{
+		Type:     TraceAdd,
+		Instance: sb.instance,
+		Choice:   choice,
+	})
+	sb.Consensus.Add(choice)
+}
+
+// RecordPoll implements the Consensus interface
+func (sb *tracedConsensus) RecordPoll(votes ids.Bag) {
+	sb.factory.write(&TraceEvent{
+		Type:     TracePoll,
+		Instance: sb.instance,
+		Votes:    votes,
+	})
+	sb.Consensus.RecordPoll(votes)
+	sb.finalize()
+}
+
+// RecordUnsuccessfulPoll implements the Consensus interface
+func (sb *tracedConsensus) RecordUnsuccessfulPoll() {
+	sb.factory.write(&TraceEvent{
+		Type:     TraceUnsuccessfulPoll,
+		Instance: sb.instance,
+	})
+	sb.Consensus.RecordUnsuccessfulPoll()
+	sb.finalize()
+}
+
+// Discard implements the Discarder interface
+func (sb *tracedConsensus) Discard() {
+	if discarder, ok := sb.Consensus.(Discarder); ok {
+		discarder.Discard()
+	}
+}
+
+// finalize traces that this instance finalized, if it has
+func (sb *tracedConsensus) finalize() {
+	if !sb.finalized && sb.Finalized() {
+		sb.finalized = true
+		sb.factory.write(&TraceEvent{
+			Type:     TraceFinalize,
+			Instance: sb.instance,
+			Choice:   sb.Preference(),
+		})
+	}
+}
+
+// marshalTraceEvent returns [event] as it's written to the log
+func marshalTraceEvent(event *TraceEvent) ([]byte, error) {
+	p := wrappers.Packer{MaxSize: traceLengthLen + maxTraceEventLen}
+	// The length is filled in once the event is packed
+	p.PackInt(0)
+	p.PackByte(byte(event.Type))
+	p.PackLong(uint64(event.Time.UnixNano()))
+	p.PackInt(event.Instance)
+
+	switch event.Type {
+	case TraceInitialize:
+		p.PackInt(uint32(event.Params.K))
+		p.PackInt(uint32(event.Params.Alpha))
+		p.PackInt(uint32(event.Params.BetaVirtuous))
+		p.PackInt(uint32(event.Params.BetaRogue))
+		p.PackFixedBytes(event.Choice.Bytes())
+	case TraceAdd, TraceFinalize:
+		p.PackFixedBytes(event.Choice.Bytes())
+	case TracePoll:
+		choices := event.Votes.List()
+		p.PackInt(uint32(len(choices)))
+		for _, choice := range choices {
+			p.PackFixedBytes(choice.Bytes())
+			p.PackInt(uint32(event.Votes.Count(choice)))
+		}
+	case TraceUnsuccessfulPoll:
+	default:
+		return nil, errUnknownTraceEvent
+	}
+	if p.Errored() {
+		return nil, p.Err
+	}
+
+	length := wrappers.Packer{Bytes: p.Bytes}
+	length.PackInt(uint32(len(p.Bytes) - traceLengthLen))
+	return p.Bytes, nil
+}
+
+// TraceReader reads the events of a log written by a TracedFactory
+type TraceReader struct{ r io.Reader }
+
+// NewTraceReader returns a reader of the log in [r]
+func NewTraceReader(r io.Reader) *TraceReader { return &TraceReader{r: r} }
+
+// Next returns the next event of the log. It returns io.EOF at the end of the
+// log, and io.ErrUnexpectedEOF if the log ends partway through an event, as it
+// may if the node stopped while writing it.
+func (r *TraceReader) Next() (TraceEvent, error) {
+	lengthBytes := make([]byte, traceLengthLen)
+	if _, err := io.ReadFull(r.r, lengthBytes); err != nil {
+		return TraceEvent{}, err
+	}
+	length := (&wrappers.Packer{Bytes: lengthBytes}).UnpackInt()
+	if length < traceHeaderLen || length > maxTraceEventLen {
+		return TraceEvent{}, errLongTraceEvent
+	}
+	bytes := make([]byte, length)
+	if _, err := io.ReadFull(r.r, bytes); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return TraceEvent{}, err
+	}
+
+	p := wrappers.Packer{Bytes: bytes}
+	event := TraceEvent{
+		Type:     TraceEventType(p.UnpackByte()),
+		Time:     time.Unix(0, int64(p.UnpackLong())),
+		Instance: p.UnpackInt(),
+	}
+	switch event.Type {
+	case TraceInitialize:
+		event.Params.K = int(p.UnpackInt())
+		event.Params.Alpha = int(p.UnpackInt())
+		event.Params.BetaVirtuous = int(p.UnpackInt())
+		event.Params.BetaRogue = int(p.UnpackInt())
+		event.Choice = unpackTraceID(&p)
+	case TraceAdd, TraceFinalize:
+		event.Choice = unpackTraceID(&p)
+	case TracePoll:
+		numChoices := p.UnpackInt()
+		for i := uint32(0); i < numChoices && !p.Errored(); i++ {
+			choice := unpackTraceID(&p)
+			event.Votes.AddCount(choice, int(p.UnpackInt()))
+		}
+	case TraceUnsuccessfulPoll:
+	default:
+		return TraceEvent{}, errUnknownTraceEvent
+	}
+	return event, p.Err
+}
+
+// unpackTraceID unpacks an ID of a trace event
+func unpackTraceID(p *wrappers.Packer) ids.ID {
+	bytes := p.UnpackFixedBytes(hashing.HashLen)
+	if p.Errored() {
+		return ids.ID{}
+	}
+	id, err := ids.ToID(bytes)
+	p.Add(err)
+	return id
+}
+
+// Replay makes the calls in the log in [r] to instances created by [factory],
+// and returns the instances by their number. It returns an error if an instance
+// finalizes differently than it did when the log was written.
+func Replay(r io.Reader, factory Factory) (map[uint32]Consensus, error) {
+	instances := make(map[uint32]Consensus)
+	reader := NewTraceReader(r)
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			return instances, nil
+		}
+		if err != nil {
+			return instances, err
+		}
+
+		sb, exists := instances[event.Instance]
+		switch {
+		case event.Type == TraceInitialize && exists:
+			return instances, fmt.Errorf("instance %d was initialized twice", event.Instance)
+		case event.Type != TraceInitialize && !exists:
+			return instances, fmt.Errorf("%s of instance %d before it was initialized", event.Type, event.Instance)
+		}
+
+		switch event.Type {
+		case TraceInitialize:
+			sb = factory.New()
+			sb.Initialize(event.Params, event.Choice)
+			instances[event.Instance] = sb
+		case TraceAdd:
+			sb.Add(event.Choice)
+		case TracePoll:
+			sb.RecordPoll(event.Votes)
+		case TraceUnsuccessfulPoll:
+			sb.RecordUnsuccessfulPoll()
+		case TraceFinalize:
+			if !sb.Finalized() || !sb.Preference().Equals(event.Choice) {
+				return instances, fmt.Errorf("instance %d finalized %s when traced but has preference %s, finalized = %v, when replayed",
+					event.Instance, event.Choice, sb.Preference(), sb.Finalized())
+			}
+		}
+	}
+}
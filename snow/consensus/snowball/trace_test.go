@@ -0,0 +1,127 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowball
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+func TestTracedParams(t *testing.T) { ParamsTest(t, NewTracedFactory(FlatFactory{}, &bytes.Buffer{})) }
+
+func TestTracedFactory(t *testing.T) {
+	log := &bytes.Buffer{}
+	factory := NewTracedFactory(FlatFactory{}, log)
+	factory.clock.Set(time.Unix(1000, 0))
+
+	params := Parameters{
+		Metrics: prometheus.NewRegistry(),
+		K:       2, Alpha: 2, BetaVirtuous: 1, BetaRogue: 2,
+	}
+	sb := factory.New()
+	sb.Initialize(params, Red)
+	sb.Add(Blue)
+	other := factory.New()
+	other.Initialize(params, Green)
+
+	twoBlue := ids.Bag{}
+	twoBlue.Add(Blue, Blue)
+	sb.RecordPoll(twoBlue)
+	sb.RecordUnsuccessfulPoll()
+	sb.RecordPoll(twoBlue)
+	sb.RecordPoll(twoBlue)
+	if !sb.Finalized() {
+		t.Fatalf("Should be finalized")
+	} else if err := factory.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []TraceEvent{
+		{Type: TraceInitialize, Instance: 0, Params: params, Choice: Red},
+		{Type: TraceAdd, Instance: 0, Choice: Blue},
+		{Type: TraceInitialize, Instance: 1, Params: params, Choice: Green},
+		{Type: TracePoll, Instance: 0, Votes: twoBlue},
+		{Type: TraceUnsuccessfulPoll, Instance: 0},
+		{Type: TracePoll, Instance: 0, Votes: twoBlue},
+		{Type: TracePoll, Instance: 0, Votes: twoBlue},
+		{Type: TraceFinalize, Instance: 0, Choice: Blue},
+	}
+	reader := NewTraceReader(bytes.NewReader(log.Bytes()))
+	for i, expectedEvent := range expected {
+		event, err := reader.Next()
+		switch {
+		case err != nil:
+			t.Fatalf("Event %d: %s", i, err)
+		case event.Type != expectedEvent.Type:
+			t.Fatalf("Event %d: expected type %s but got %s", i, expectedEvent.Type, event.Type)
+		case event.Instance != expectedEvent.Instance:
+			t.Fatalf("Event %d: expected instance %d but got %d", i, expectedEvent.Instance, event.Instance)
+		case !event.Time.Equal(time.Unix(1000, 0)):
+			t.Fatalf("Event %d: wrong time %s", i, event.Time)
+		case !event.Choice.Equals(expectedEvent.Choice):
+			t.Fatalf("Event %d: expected choice %s but got %s", i, expectedEvent.Choice, event.Choice)
+		case event.Votes.Len() != expectedEvent.Votes.Len() || event.Votes.Count(Blue) != expectedEvent.Votes.Count(Blue):
+			t.Fatalf("Event %d: expected votes %s but got %s", i, &expectedEvent.Votes, &event.Votes)
+		case event.Params.K != expectedEvent.Params.K || event.Params.BetaRogue != expectedEvent.Params.BetaRogue:
+			t.Fatalf("Event %d: wrong parameters", i)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Fatalf("Expected the end of the log, got %v", err)
+	}
+
+	instances, err := Replay(bytes.NewReader(log.Bytes()), FlatFactory{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("Expected 2 instances, got %d", len(instances))
+	} else if !instances[0].Finalized() || !instances[0].Preference().Equals(Blue) {
+		t.Fatalf("Replayed instance should have finalized Blue")
+	} else if instances[1].Finalized() || !instances[1].Preference().Equals(Green) {
+		t.Fatalf("Replayed instance should prefer Green")
+	}
+}
+
+func TestTraceReaderTruncated(t *testing.T) {
+	log := &bytes.Buffer{}
+	factory := NewTracedFactory(nil, log)
+	factory.New().Initialize(Parameters{K: 1, Alpha: 1, BetaVirtuous: 1, BetaRogue: 1}, Red)
+
+	reader := NewTraceReader(bytes.NewReader(log.Bytes()[:log.Len()-1]))
+	if _, err := reader.Next(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected a truncated event, got %v", err)
+	}
+}
+
+func TestReplayDiverged(t *testing.T) {
+	log := &bytes.Buffer{}
+	factory := NewTracedFactory(FlatFactory{}, log)
+
+	params := Parameters{K: 1, Alpha: 1, BetaVirtuous: 1, BetaRogue: 1}
+	sb := factory.New()
+	sb.Initialize(params, Red)
+	votes := ids.Bag{}
+	votes.Add(Red)
+	sb.RecordPoll(votes)
+	if !sb.Finalized() {
+		t.Fatalf("Should be finalized")
+	}
+
+	// Raising the betas of the initialize event means the poll is no longer
+	// enough to finalize
+	betaVirtuousOffset := traceLengthLen + traceHeaderLen + 2*wrappers.IntLen
+	log.Bytes()[betaVirtuousOffset+wrappers.IntLen-1] = 2
+	log.Bytes()[betaVirtuousOffset+2*wrappers.IntLen-1] = 2
+	if _, err := Replay(bytes.NewReader(log.Bytes()), FlatFactory{}); err == nil {
+		t.Fatalf("Replay should have diverged")
+	}
+}
@@ -12,18 +12,29 @@ import (
 )
 
 // TopologicalFactory implements Factory by returning a topological struct
-type TopologicalFactory struct{}
+// whose snowball instances are created by SnowballFactory
+type TopologicalFactory struct{ SnowballFactory snowball.Factory }
 
 // New implements Factory
-func (TopologicalFactory) New() Consensus { return &Topological{} }
+func (f TopologicalFactory) New() Consensus {
+	return &Topological{SnowballFactory: f.SnowballFactory}
+}
 
 // Topological implements the Snowman interface by using a tree tracking the
 // strongly preferred branch. This tree structure amortizes network polls to
 // vote on more than just the next position.
 type Topological struct {
+	// SnowballFactory creates the snowball instances that decide between the
+	// children of a block. If nil, they're snowball trees.
+	SnowballFactory snowball.Factory
+
 	ctx    *snow.Context
 	params snowball.Parameters
 
+	// snowballFactory creates the snowball instances, whose polls are recorded
+	// in this chain's metrics
+	snowballFactory snowball.Factory
+
 	numProcessing            prometheus.Gauge
 	numAccepted, numRejected prometheus.Counter
 
@@ -90,6 +101,11 @@ func (ts *Topological) Initialize(ctx *snow.Context, params snowball.Parameters,
 	if err := ts.params.Metrics.Register(ts.numRejected); err != nil {
 		ts.ctx.Log.Error("Failed to register rejected statistics due to %s", err)
 	}
+	snowballFactory, err := snowball.NewMeteredFactory(ts.SnowballFactory, params.Namespace, params.Metrics)
+	if err != nil {
+		ts.ctx.Log.Error("Failed to register snowball statistics due to %s", err)
+	}
+	ts.snowballFactory = snowballFactory
 
 	ts.head = rootID
 	ts.nodes = map[[32]byte]node{
@@ -397,6 +413,9 @@ func (ts *Topological) rejectTransitively(rejected ...ids.ID) {
 		rejectKey := rejectID.Key()
 		rejectNode := ts.nodes[rejectKey]
 		delete(ts.nodes, rejectKey)
+		if sb, ok := rejectNode.sb.(snowball.Discarder); ok {
+			sb.Discard()
+		}
 		ts.numProcessing.Dec()
 
 		for childIDBytes, child := range rejectNode.children {
@@ -416,7 +435,7 @@ func (ts *Topological) rejectTransitively(rejected ...ids.ID) {
 func (n *node) Add(child Block) {
 	childID := child.ID()
 	if n.sb == nil {
-		n.sb = &snowball.Tree{}
+		n.sb = n.ts.snowballFactory.New()
 		n.sb.Initialize(n.ts.params, childID)
 	} else {
 		n.sb.Add(childID)
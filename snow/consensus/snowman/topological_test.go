@@ -5,6 +5,8 @@ package snowman
 
 import (
 	"testing"
+
+	"github.com/ava-labs/gecko/snow/consensus/snowball"
 )
 
 func TestTopologicalParams(t *testing.T) { ParamsTest(t, TopologicalFactory{}) }
@@ -30,3 +32,15 @@ func TestTopologicalIssuedTest(t *testing.T) { IssuedTest(t, TopologicalFactory{
 func TestTopologicalMetricsError(t *testing.T) { MetricsErrorTest(t, TopologicalFactory{}) }
 
 func TestTopologicalConsistent(t *testing.T) { ConsistentTest(t, TopologicalFactory{}) }
+
+func TestTopologicalDecayingFlatAdd(t *testing.T) {
+	AddTest(t, TopologicalFactory{SnowballFactory: snowball.DecayingFlatFactory{Decay: .5}})
+}
+
+func TestTopologicalDecayingFlatCollectNothing(t *testing.T) {
+	CollectNothingTest(t, TopologicalFactory{SnowballFactory: snowball.DecayingFlatFactory{Decay: .5}})
+}
+
+func TestTopologicalDecayingFlatCollectTransVote(t *testing.T) {
+	CollectTransVoteTest(t, TopologicalFactory{SnowballFactory: snowball.DecayingFlatFactory{Decay: .5}})
+}
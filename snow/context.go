@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/snow/upgrades"
 	"github.com/ava-labs/gecko/utils/logging"
 )
 
@@ -22,6 +25,17 @@ type Callable interface {
 // Keystore ...
 type Keystore interface {
 	GetDatabase(username, password string) (database.Database, error)
+
+	// GetReadOnlyDatabase returns the user's database, which fails to be
+	// written to, for calls that only read the user's data
+	GetReadOnlyDatabase(username, password string) (database.Database, error)
+}
+
+// SharedMemory is the memory this chain shares with the other chains running
+// on this node
+type SharedMemory interface {
+	GetDatabase(id ids.ID) database.Database
+	ReleaseDatabase(id ids.ID)
 }
 
 // AliasLookup ...
@@ -34,6 +48,9 @@ type AliasLookup interface {
 // [NetworkID] is the ID of the network this context exists within.
 // [ChainID] is the ID of the chain this context exists within.
 // [NodeID] is the ID of this node
+// [Namespace] is the namespace of the chain's metrics, which are registered
+// with [Metrics]
+// [Upgrades] is where the chain registers the rule changes it recognizes
 type Context struct {
 	NetworkID           uint32
 	ChainID             ids.ID
@@ -44,7 +61,11 @@ type Context struct {
 	Lock                sync.RWMutex
 	HTTP                Callable
 	Keystore            Keystore
+	SharedMemory        SharedMemory
 	BCLookup            AliasLookup
+	Namespace           string
+	Metrics             prometheus.Registerer
+	Upgrades            *upgrades.Manager
 }
 
 // DefaultContextTest ...
@@ -53,6 +74,8 @@ func DefaultContextTest() *Context {
 	decisionED.Initialize(logging.NoLog{})
 	consensusED := triggers.EventDispatcher{}
 	consensusED.Initialize(logging.NoLog{})
+	upgradeManager := upgrades.Manager{}
+	upgradeManager.Initialize(logging.NoLog{}, nil)
 	return &Context{
 		ChainID:             ids.Empty,
 		NodeID:              ids.ShortEmpty,
@@ -60,5 +83,6 @@ func DefaultContextTest() *Context {
 		DecisionDispatcher:  &decisionED,
 		ConsensusDispatcher: &consensusED,
 		BCLookup:            &ids.Aliaser{},
+		Upgrades:            &upgradeManager,
 	}
 }
@@ -135,6 +135,7 @@ func (b *bootstrapper) sendRequest(vtxID ids.ID) {
 	b.BootstrapConfig.Sender.Get(validatorID, b.RequestID, vtxID)
 
 	b.numPendingRequests.Set(float64(b.pending.Len()))
+	b.Bootstrapper.Progress.Pending(b.pending.Len())
 }
 
 func (b *bootstrapper) addVertex(vtx avalanche.Vertex) {
@@ -158,7 +159,9 @@ func (b *bootstrapper) addVertex(vtx avalanche.Vertex) {
 				vtx:         vtx,
 			}); err == nil {
 				b.numBlockedVtx.Inc()
+				b.Bootstrapper.Progress.Queued()
 			}
+			b.Bootstrapper.Progress.Fetched(b.pending.Len())
 			for _, tx := range vtx.Txs() {
 				if err := b.TxBlocked.Push(&txJob{
 					numAccepted: b.numBootstrappedVtx,
@@ -166,6 +169,7 @@ func (b *bootstrapper) addVertex(vtx avalanche.Vertex) {
 					tx:          tx,
 				}); err == nil {
 					b.numBlockedTx.Inc()
+					b.Bootstrapper.Progress.Queued()
 				}
 			}
 
@@ -191,12 +195,14 @@ func (b *bootstrapper) finish() {
 		return
 	}
 
+	b.Bootstrapper.Progress.SetPhase(common.ExecutingContainers)
 	b.executeAll(b.TxBlocked, b.numBlockedTx)
 	b.executeAll(b.VtxBlocked, b.numBlockedVtx)
 
 	// Start consensus
 	b.onFinished()
 	b.finished = true
+	b.Bootstrapper.Progress.SetPhase(common.Bootstrapped)
 }
 
 func (b *bootstrapper) executeAll(jobs *queue.Jobs, numBlocked prometheus.Gauge) {
@@ -205,5 +211,6 @@ func (b *bootstrapper) executeAll(jobs *queue.Jobs, numBlocked prometheus.Gauge)
 		if err := jobs.Execute(job); err != nil {
 			b.BootstrapConfig.Context.Log.Warn("Error executing: %s", err)
 		}
+		b.Bootstrapper.Progress.Executed()
 	}
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/snow/engine/common/queue"
+	"github.com/ava-labs/gecko/snow/networking/benchlist"
 	"github.com/ava-labs/gecko/snow/networking/handler"
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/snow/networking/timeout"
@@ -58,8 +59,8 @@ func newConfig(t *testing.T) (BootstrapConfig, ids.ShortID, *common.SenderTest,
 	peerID := peer.ID()
 	peers.Add(peer)
 
-	handler.Initialize(engine, make(chan common.Message), 1)
-	timeouts.Initialize(0)
+	handler.Initialize(engine, make(chan common.Message), 1, "", prometheus.NewRegistry())
+	timeouts.Initialize(0, benchlist.NewNoBenchlist())
 	router.Initialize(ctx.Log, timeouts)
 
 	vtxBlocker, _ := queue.New(prefixdb.New([]byte("vtx"), db))
@@ -4,6 +4,8 @@
 package avalanche
 
 import (
+	"fmt"
+
 	"github.com/ava-labs/gecko/snow/consensus/avalanche"
 )
 
@@ -14,3 +16,11 @@ type Config struct {
 	Params    avalanche.Parameters
 	Consensus avalanche.Consensus
 }
+
+// Valid returns nil if an engine can be initialized with this config
+func (c Config) Valid() error {
+	if err := c.Params.Valid(); err != nil {
+		return fmt.Errorf("invalid consensus parameters: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,9 @@
 package avalanche
 
 import (
+	"errors"
+	"testing"
+
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/database/memdb"
@@ -38,3 +41,17 @@ func DefaultConfig() Config {
 		Consensus: &avalanche.Topological{},
 	}
 }
+
+func TestConfigValid(t *testing.T) {
+	config := DefaultConfig()
+	if err := config.Valid(); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Params.BetaRogue = config.Params.BetaVirtuous - 1
+	if err := config.Valid(); err == nil {
+		t.Fatalf("Should have errored due to BetaRogue < BetaVirtuous")
+	} else if err := errors.Unwrap(err); err == nil || err.Error() != config.Params.Valid().Error() {
+		t.Fatalf("Should have wrapped the parameters' error but errored with %v", err)
+	}
+}
@@ -59,7 +59,7 @@ func (i *issuer) Update() {
 	p := i.t.Consensus.Parameters()
 	vdrs := i.t.Config.Validators.Sample(p.K) // Validators to sample
 
-	vdrSet := ids.ShortSet{} // Validators to sample repr. as a set
+	vdrSet := ids.NewShortSet(len(vdrs)) // Validators to sample repr. as a set
 	for _, vdr := range vdrs {
 		vdrSet.Add(vdr.ID())
 	}
@@ -63,6 +63,18 @@ func (s *prefixedState) SetVertex(vtx *vertex) {
 	s.state.SetVertex(vID, vtx)
 }
 
+func (s *prefixedState) DeleteVertex(id ids.ID) {
+	vID := ids.ID{}
+	if cachedVtxIDIntf, found := s.vtx.Get(id); found {
+		vID = cachedVtxIDIntf.(ids.ID)
+	} else {
+		vID = id.Prefix(vtxID)
+		s.vtx.Put(id, vID)
+	}
+
+	s.state.SetVertex(vID, nil)
+}
+
 func (s *prefixedState) Status(id ids.ID) choices.Status {
 	sID := ids.ID{}
 	if cachedStatusIDIntf, found := s.status.Get(id); found {
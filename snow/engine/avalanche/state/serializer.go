@@ -28,8 +28,11 @@ const (
 )
 
 var (
-	errUnknownVertex = errors.New("unknown vertex")
-	errWrongChainID  = errors.New("wrong ChainID in vertex")
+	errUnknownVertex  = errors.New("unknown vertex")
+	errWrongChainID   = errors.New("wrong ChainID in vertex")
+	errPrunedVertex   = errors.New("vertex was pruned")
+	errNotAccepted    = errors.New("only accepted vertices can be pruned")
+	errFrontierVertex = errors.New("vertices in the accepted frontier can't be pruned")
 )
 
 // Serializer manages the state of multiple vertices
@@ -72,8 +75,12 @@ func (s *Serializer) ParseVertex(b []byte) (avacon.Vertex, error) {
 		serializer: s,
 		vtxID:      vtx.ID(),
 	}
-	if uVtx.Status() == choices.Unknown {
+	switch {
+	case uVtx.Status() == choices.Unknown:
 		uVtx.setVertex(vtx)
+	case uVtx.v.vtx == nil:
+		// The vertex was pruned, so it's only kept in memory
+		uVtx.v.vtx = vtx
 	}
 
 	s.db.Commit()
@@ -129,6 +136,26 @@ func (s *Serializer) GetVertex(vtxID ids.ID) (avacon.Vertex, error) { return s.g
 // Edge implements the avalanche.State interface
 func (s *Serializer) Edge() []ids.ID { return s.edge.List() }
 
+// PruneVertex deletes the bytes of the accepted vertex [vtxID], keeping its
+// status. Vertices in the accepted frontier are kept, since new vertices are
+// built on top of them.
+func (s *Serializer) PruneVertex(vtxID ids.ID) error {
+	if s.edge.Contains(vtxID) {
+		return errFrontierVertex
+	}
+	vtx := &uniqueVertex{
+		serializer: s,
+		vtxID:      vtxID,
+	}
+	if vtx.Status() != choices.Accepted {
+		return errNotAccepted
+	}
+
+	// A vertex that's loaded stays usable until it's evicted from memory
+	s.state.DeleteVertex(vtxID)
+	return s.db.Commit()
+}
+
 func (s *Serializer) parseVertex(b []byte) (*vertex, error) {
 	vtx := &vertex{}
 	if err := vtx.Unmarshal(b, s.vm); err != nil {
@@ -147,5 +174,8 @@ func (s *Serializer) getVertex(vtxID ids.ID) (*uniqueVertex, error) {
 	if vtx.Status() == choices.Unknown {
 		return nil, errUnknownVertex
 	}
+	if vtx.v.vtx == nil {
+		return nil, errPrunedVertex
+	}
 	return vtx, nil
 }
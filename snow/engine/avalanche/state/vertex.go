@@ -104,11 +104,9 @@ func (vtx *vertex) Unmarshal(b []byte, vm avalanche.DAGVM) error {
 		parentIDs = append(parentIDs, parentID)
 	}
 
-	txs := []snowstorm.Tx(nil)
+	txBytes := [][]byte(nil)
 	for i := p.UnpackInt(); i > 0 && !p.Errored(); i-- {
-		tx, err := vm.ParseTx(p.UnpackBytes())
-		p.Add(err)
-		txs = append(txs, tx)
+		txBytes = append(txBytes, p.UnpackBytes())
 	}
 
 	if p.Offset != len(b) {
@@ -119,6 +117,11 @@ func (vtx *vertex) Unmarshal(b []byte, vm avalanche.DAGVM) error {
 		return p.Err
 	}
 
+	txs, err := parseTxs(vm, txBytes)
+	if err != nil {
+		return err
+	}
+
 	*vtx = vertex{
 		id:        ids.NewID(hashing.ComputeHash256Array(b)),
 		parentIDs: parentIDs,
@@ -130,6 +133,23 @@ func (vtx *vertex) Unmarshal(b []byte, vm avalanche.DAGVM) error {
 	return nil
 }
 
+// parseTxs parses the transactions of a vertex, as a batch if [vm] supports it
+func parseTxs(vm avalanche.DAGVM, txBytes [][]byte) ([]snowstorm.Tx, error) {
+	if batchVM, ok := vm.(avalanche.BatchedTxParser); ok {
+		return batchVM.ParseTxs(txBytes)
+	}
+
+	txs := make([]snowstorm.Tx, len(txBytes))
+	for i, b := range txBytes {
+		tx, err := vm.ParseTx(b)
+		if err != nil {
+			return nil, err
+		}
+		txs[i] = tx
+	}
+	return txs, nil
+}
+
 type sortTxsData []snowstorm.Tx
 
 func (txs sortTxsData) Less(i, j int) bool {
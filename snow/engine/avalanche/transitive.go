@@ -11,7 +11,7 @@ import (
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/snow/events"
 	"github.com/ava-labs/gecko/utils/formatting"
-	"github.com/ava-labs/gecko/utils/random"
+	"github.com/ava-labs/gecko/utils/sampler"
 )
 
 // Transitive implements the Engine interface by attempting to fetch all
@@ -325,10 +325,9 @@ func (t *Transitive) issueBatch(txs []snowstorm.Tx) {
 	t.Config.Context.Log.Verbo("Batching %d transactions into a new vertex", len(txs))
 
 	virtuousIDs := t.Consensus.Virtuous().List()
-	sampler := random.Uniform{N: len(virtuousIDs)}
 	parentIDs := ids.Set{}
-	for i := 0; i < t.Params.Parents && sampler.CanSample(); i++ {
-		parentIDs.Add(virtuousIDs[sampler.Sample()])
+	for _, i := range sampler.NewUniform(len(virtuousIDs)).Sample(t.Params.Parents) {
+		parentIDs.Add(virtuousIDs[i])
 	}
 
 	if vtx, err := t.Config.State.BuildVertex(parentIDs, txs); err == nil {
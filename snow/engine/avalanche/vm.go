@@ -23,3 +23,10 @@ type DAGVM interface {
 	// Retrieve a transaction that was submitted previously
 	GetTx(ids.ID) (snowstorm.Tx, error)
 }
+
+// BatchedTxParser is a DAGVM that can parse the transactions of a vertex
+// together, such as to recover their signatures in parallel
+type BatchedTxParser interface {
+	// Convert each stream of bytes to a transaction or return an error
+	ParseTxs(txs [][]byte) ([]snowstorm.Tx, error)
+}
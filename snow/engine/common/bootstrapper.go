@@ -5,12 +5,26 @@ package common
 
 import (
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
 )
 
 // Bootstrapper implements the Engine interface.
 type Bootstrapper struct {
 	Config
 
+	// Summaries of the chain's state that the beacons returned, by the hash
+	// of the summary
+	pendingStateSummary ids.ShortSet
+	stateSummaryIDs     ids.Bag
+	stateSummaries      map[[32]byte][]byte
+	stateSummaryVdrs    map[[32]byte][]ids.ShortID
+
+	// The beacons that returned the summary being synced to, which the state
+	// is fetched from, and the VM's next request for the state
+	pendingState ids.ShortSet
+	stateVdrs    []ids.ShortID
+	stateRequest []byte
+
 	pendingAcceptedFrontier ids.ShortSet
 	acceptedFrontier        ids.Set
 
@@ -23,12 +37,21 @@ type Bootstrapper struct {
 // Initialize implements the Engine interface.
 func (b *Bootstrapper) Initialize(config Config) {
 	b.Config = config
+	if b.Progress == nil {
+		b.Progress = &Progress{}
+	}
+	b.Progress.Initialize(config.Context.Log)
 
 	for _, vdr := range b.Beacons.List() {
 		vdrID := vdr.ID()
+		if b.StateSyncVM != nil {
+			b.pendingStateSummary.Add(vdrID)
+		}
 		b.pendingAcceptedFrontier.Add(vdrID)
 		b.pendingAccepted.Add(vdrID)
 	}
+	b.stateSummaries = make(map[[32]byte][]byte)
+	b.stateSummaryVdrs = make(map[[32]byte][]ids.ShortID)
 
 	b.accepted.SetThreshold(config.Alpha)
 }
@@ -37,10 +60,177 @@ func (b *Bootstrapper) Initialize(config Config) {
 func (b *Bootstrapper) Startup() {
 	if b.pendingAcceptedFrontier.Len() == 0 {
 		b.Context.Log.Info("Bootstrapping skipped due to no provided bootstraps")
+		b.Progress.SetPhase(FetchingContainers)
 		b.Bootstrapable.ForceAccepted(ids.Set{})
 		return
 	}
 
+	if b.pendingStateSummary.Len() != 0 {
+		vdrs := ids.ShortSet{}
+		vdrs.Union(b.pendingStateSummary)
+
+		b.Progress.SetPhase(SyncingState)
+		b.RequestID++
+		b.Sender.GetStateSummary(vdrs, b.RequestID)
+		return
+	}
+
+	b.getAcceptedFrontier()
+}
+
+// GetStateSummary implements the Engine interface.
+func (b *Bootstrapper) GetStateSummary(validatorID ids.ShortID, requestID uint32) {
+	summary := []byte(nil)
+	if b.StateSyncVM != nil {
+		vmSummary, err := b.StateSyncVM.StateSummary()
+		if err != nil {
+			b.Context.Log.Warn("Failed to summarize the chain's state due to %s", err)
+		} else {
+			summary = vmSummary
+		}
+	}
+	b.Sender.StateSummary(validatorID, requestID, summary)
+}
+
+// GetStateSummaryFailed implements the Engine interface.
+func (b *Bootstrapper) GetStateSummaryFailed(validatorID ids.ShortID, requestID uint32) {
+	b.StateSummary(validatorID, requestID, nil)
+}
+
+// StateSummary implements the Engine interface.
+func (b *Bootstrapper) StateSummary(validatorID ids.ShortID, requestID uint32, summary []byte) {
+	if requestID != b.RequestID {
+		b.Context.Log.Debug("Received a StateSummary message from %s with an unexpected request ID %d", validatorID, requestID)
+		return
+	}
+	if !b.pendingStateSummary.Contains(validatorID) {
+		b.Context.Log.Debug("Received a StateSummary message from %s unexpectedly", validatorID)
+		return
+	}
+	b.pendingStateSummary.Remove(validatorID)
+
+	if len(summary) != 0 {
+		summaryID := ids.NewID(hashing.ComputeHash256Array(summary))
+		key := summaryID.Key()
+		b.stateSummaryIDs.Add(summaryID)
+		b.stateSummaries[key] = summary
+		b.stateSummaryVdrs[key] = append(b.stateSummaryVdrs[key], validatorID)
+	}
+
+	if b.pendingStateSummary.Len() == 0 {
+		b.syncState()
+	}
+}
+
+// syncState starts setting the VM's state to the summary that at least Alpha
+// of the beacons returned, if there is one. Once the state is synced, or can't
+// be, the accepted frontier is fetched.
+func (b *Bootstrapper) syncState() {
+	summaries, summaryVdrs := b.stateSummaries, b.stateSummaryVdrs
+	// The summaries may be large, so don't hold onto them
+	b.stateSummaries = nil
+	b.stateSummaryVdrs = nil
+
+	summaryID, count := b.stateSummaryIDs.Mode()
+	switch {
+	case count == 0:
+		b.Context.Log.Info("Bootstrapping without syncing state, as no state summaries were provided")
+	case count < b.Config.Alpha:
+		b.Context.Log.Info("Bootstrapping without syncing state, as only %d beacons provided the most common state summary", count)
+	default:
+		b.stateVdrs = summaryVdrs[summaryID.Key()]
+		request, err := b.StateSyncVM.SyncState(summaries[summaryID.Key()])
+		switch {
+		case err != nil:
+			b.Context.Log.Warn("Bootstrapping without syncing state, as syncing to state summary %s failed due to %s", summaryID, err)
+		case request == nil:
+			b.Context.Log.Info("State is already synced to state summary %s, provided by %d beacons", summaryID, count)
+		default:
+			b.Context.Log.Info("Fetching the state of state summary %s, provided by %d beacons", summaryID, count)
+			b.stateRequest = request
+			b.fetchState()
+			return
+		}
+	}
+	b.getAcceptedFrontier()
+}
+
+// fetchState sends the VM's current request for the state to the first of the
+// beacons that returned the summary that hasn't failed to provide it
+func (b *Bootstrapper) fetchState() {
+	if len(b.stateVdrs) == 0 {
+		b.Context.Log.Warn("Bootstrapping without syncing state, as no beacon provided the summarized state")
+		b.stateRequest = nil
+		b.getAcceptedFrontier()
+		return
+	}
+
+	vdrID := b.stateVdrs[0]
+	b.pendingState.Clear()
+	b.pendingState.Add(vdrID)
+
+	b.RequestID++
+	b.Sender.GetStateChunk(vdrID, b.RequestID, b.stateRequest)
+}
+
+// GetStateChunk implements the Engine interface.
+func (b *Bootstrapper) GetStateChunk(validatorID ids.ShortID, requestID uint32, request []byte) {
+	response := []byte(nil)
+	if b.StateSyncVM != nil {
+		vmResponse, err := b.StateSyncVM.GetState(request)
+		if err != nil {
+			b.Context.Log.Debug("Failed to get the summarized state requested by %s due to %s", validatorID, err)
+		} else {
+			response = vmResponse
+		}
+	}
+	b.Sender.StateChunk(validatorID, requestID, response)
+}
+
+// GetStateChunkFailed implements the Engine interface.
+func (b *Bootstrapper) GetStateChunkFailed(validatorID ids.ShortID, requestID uint32) {
+	b.StateChunk(validatorID, requestID, nil)
+}
+
+// StateChunk implements the Engine interface.
+func (b *Bootstrapper) StateChunk(validatorID ids.ShortID, requestID uint32, response []byte) {
+	if requestID != b.RequestID || !b.pendingState.Contains(validatorID) {
+		b.Context.Log.Debug("Received a StateChunk message from %s unexpectedly", validatorID)
+		return
+	}
+	b.pendingState.Remove(validatorID)
+
+	if len(response) == 0 {
+		b.Context.Log.Debug("%s didn't provide the summarized state", validatorID)
+		b.stateVdrs = b.stateVdrs[1:]
+		b.fetchState()
+		return
+	}
+
+	request, err := b.StateSyncVM.PutState(response)
+	switch {
+	case err != nil && request == nil:
+		b.Context.Log.Warn("Bootstrapping without syncing state, as syncing the state provided by %s failed due to %s", validatorID, err)
+		b.stateRequest = nil
+		b.getAcceptedFrontier()
+	case err != nil:
+		b.Context.Log.Warn("Dropping the summarized state provided by %s due to %s", validatorID, err)
+		b.stateVdrs = b.stateVdrs[1:]
+		b.stateRequest = request
+		b.fetchState()
+	case request == nil:
+		b.Context.Log.Info("Synced the summarized state")
+		b.stateRequest = nil
+		b.getAcceptedFrontier()
+	default:
+		b.stateRequest = request
+		b.fetchState()
+	}
+}
+
+func (b *Bootstrapper) getAcceptedFrontier() {
+	b.Progress.SetPhase(FetchingFrontier)
+
 	vdrs := ids.ShortSet{}
 	vdrs.Union(b.pendingAcceptedFrontier)
 
@@ -105,6 +295,7 @@ func (b *Bootstrapper) Accepted(validatorID ids.ShortID, requestID uint32, conta
 			b.Context.Log.Info("Bootstrapping finished with %d vertices in the accepted frontier", size)
 		}
 
+		b.Progress.SetPhase(FetchingContainers)
 		b.Bootstrapable.ForceAccepted(accepted)
 	}
 }
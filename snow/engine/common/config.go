@@ -18,4 +18,12 @@ type Config struct {
 	Alpha         int
 	Sender        Sender
 	Bootstrapable Bootstrapable
+
+	// StateSyncVM is the VM of the chain, if its state can be synced to a
+	// summary while bootstrapping
+	StateSyncVM StateSyncableVM
+
+	// Progress of bootstrapping the chain. If nil, the bootstrapper tracks its
+	// progress privately.
+	Progress *Progress
 }
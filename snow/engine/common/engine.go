@@ -25,12 +25,50 @@ type Handler interface {
 // ExternalHandler defines how a consensus engine reacts to messages and
 // requests from other validators
 type ExternalHandler interface {
+	StateSummaryHandler
+	StateChunkHandler
 	FrontierHandler
 	AcceptedHandler
 	FetchHandler
 	QueryHandler
 }
 
+// StateSummaryHandler defines how a consensus engine reacts to state summary
+// messages from other validators
+type StateSummaryHandler interface {
+	// GetStateSummary notifies this consensus engine that a summary of its
+	// chain's state is requested by the specified validator
+	GetStateSummary(validatorID ids.ShortID, requestID uint32)
+
+	// StateSummary notifies this consensus engine of the specified
+	// validator's summary of its chain's state. An empty summary means the
+	// validator has no summary to offer.
+	StateSummary(validatorID ids.ShortID, requestID uint32, summary []byte)
+
+	// GetStateSummaryFailed notifies this consensus engine that the requested
+	// state summary from the specified validator should be considered lost
+	GetStateSummaryFailed(validatorID ids.ShortID, requestID uint32)
+}
+
+// StateChunkHandler defines how a consensus engine reacts to messages that transfer
+// a summarized state from other validators
+type StateChunkHandler interface {
+	// GetStateChunk notifies this consensus engine that the part of its chain's
+	// summarized state described by [request] is requested by the specified
+	// validator
+	GetStateChunk(validatorID ids.ShortID, requestID uint32, request []byte)
+
+	// StateChunk notifies this consensus engine of the part of the summarized
+	// state that the specified validator returned. An empty response means
+	// the validator can't provide it.
+	StateChunk(validatorID ids.ShortID, requestID uint32, response []byte)
+
+	// GetStateChunkFailed notifies this consensus engine that the requested part of
+	// the summarized state from the specified validator should be considered
+	// lost
+	GetStateChunkFailed(validatorID ids.ShortID, requestID uint32)
+}
+
 // FrontierHandler defines how a consensus engine reacts to frontier messages
 // from other validators
 type FrontierHandler interface {
@@ -0,0 +1,210 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// progressLogInterval is how often bootstrapping progress is logged
+const progressLogInterval = 10 * time.Second
+
+// Phase of bootstrapping a chain
+type Phase uint8
+
+// Phases of bootstrapping, in the order they happen
+const (
+	AwaitingConnections Phase = iota
+	SyncingState
+	FetchingFrontier
+	FetchingContainers
+	ExecutingContainers
+	Bootstrapped
+)
+
+func (p Phase) String() string {
+	switch p {
+	case AwaitingConnections:
+		return "awaiting connections"
+	case SyncingState:
+		return "syncing state"
+	case FetchingFrontier:
+		return "fetching accepted frontier"
+	case FetchingContainers:
+		return "fetching containers"
+	case ExecutingContainers:
+		return "executing containers"
+	case Bootstrapped:
+		return "bootstrapped"
+	default:
+		return "unknown phase"
+	}
+}
+
+// ProgressReport is a snapshot of the progress of bootstrapping a chain
+type ProgressReport struct {
+	Phase Phase
+
+	// Containers that were fetched, and the containers that are known to be
+	// needed. More containers may be needed once the pending ones are
+	// fetched, so Expected only grows.
+	Fetched, Expected uint64
+
+	// Operations that were executed, and the operations to execute
+	Executed, ToExecute uint64
+
+	// When bootstrapping started, and when it last made progress. A chain
+	// that hasn't made progress in a while may be stuck.
+	Started, LastProgress time.Time
+
+	// When the current phase is estimated to finish, based on the rate of
+	// progress so far. Zero if there's no estimate.
+	EstimatedCompletion time.Time
+}
+
+// Progress tracks how far a chain is through bootstrapping. It's updated by
+// the bootstrapper, and may be read by other threads.
+type Progress struct {
+	lock  sync.RWMutex
+	log   logging.Logger
+	clock timer.Clock
+
+	report ProgressReport
+
+	// When the current phase started
+	phaseStarted time.Time
+
+	// Containers fetched or operations executed when the phase started
+	phaseStartCount uint64
+
+	lastLogged time.Time
+}
+
+// Initialize the progress, which is logged to [log]
+func (p *Progress) Initialize(log logging.Logger) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.log = log
+	p.report = ProgressReport{}
+	p.phaseStarted = p.clock.Time()
+}
+
+// SetPhase sets the current phase of bootstrapping
+func (p *Progress) SetPhase(phase Phase) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.clock.Time()
+	if p.report.Phase == AwaitingConnections && phase != AwaitingConnections {
+		p.report.Started = now
+	}
+	p.report.Phase = phase
+	p.report.LastProgress = now
+	p.phaseStarted = now
+	switch phase {
+	case FetchingContainers:
+		p.phaseStartCount = p.report.Fetched
+	case ExecutingContainers:
+		p.phaseStartCount = p.report.Executed
+	}
+	if p.log != nil {
+		p.log.Info("Bootstrapping is %s", phase)
+	}
+}
+
+// Fetched records that a container was fetched. [numPending] containers are
+// still being fetched.
+func (p *Progress) Fetched(numPending int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.report.Fetched++
+	p.setPending(numPending)
+	p.progressed()
+}
+
+// Pending records that [numPending] containers are being fetched
+func (p *Progress) Pending(numPending int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.setPending(numPending)
+}
+
+// Queued records that an operation was queued to be executed once the
+// containers are fetched
+func (p *Progress) Queued() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.report.ToExecute++
+}
+
+// Executed records that an operation was executed
+func (p *Progress) Executed() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.report.Executed++
+	// Operations queued before the node restarted weren't counted as queued
+	if p.report.Executed > p.report.ToExecute {
+		p.report.ToExecute = p.report.Executed
+	}
+	p.progressed()
+}
+
+// Report returns a snapshot of the progress
+func (p *Progress) Report() ProgressReport {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	report := p.report
+	now := p.clock.Time()
+	elapsed := now.Sub(p.phaseStarted)
+
+	var done, remaining uint64
+	switch report.Phase {
+	case FetchingContainers:
+		done = report.Fetched - p.phaseStartCount
+		remaining = report.Expected - report.Fetched
+	case ExecutingContainers:
+		done = report.Executed - p.phaseStartCount
+		remaining = report.ToExecute - report.Executed
+	}
+	if done > 0 && elapsed > 0 {
+		perContainer := elapsed / time.Duration(done)
+		report.EstimatedCompletion = now.Add(perContainer * time.Duration(remaining))
+	}
+	return report
+}
+
+// setPending assumes the lock is held
+func (p *Progress) setPending(numPending int) {
+	if expected := p.report.Fetched + uint64(numPending); expected > p.report.Expected {
+		p.report.Expected = expected
+	}
+}
+
+// progressed logs the progress if it hasn't been logged recently. Assumes the
+// lock is held.
+func (p *Progress) progressed() {
+	now := p.clock.Time()
+	p.report.LastProgress = now
+	if p.log == nil || now.Sub(p.lastLogged) < progressLogInterval {
+		return
+	}
+	p.lastLogged = now
+
+	switch p.report.Phase {
+	case FetchingContainers:
+		p.log.Info("Bootstrapping has fetched %d of at least %d containers", p.report.Fetched, p.report.Expected)
+	case ExecutingContainers:
+		p.log.Info("Bootstrapping has executed %d of %d operations", p.report.Executed, p.report.ToExecute)
+	}
+}
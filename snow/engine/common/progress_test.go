@@ -0,0 +1,73 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestProgress(t *testing.T) {
+	p := &Progress{}
+	p.Initialize(logging.NoLog{})
+
+	start := time.Unix(1000, 0)
+	p.clock.Set(start)
+
+	if report := p.Report(); report.Phase != AwaitingConnections || !report.Started.IsZero() {
+		t.Fatalf("bootstrapping shouldn't have started")
+	}
+
+	p.SetPhase(FetchingFrontier)
+	p.SetPhase(FetchingContainers)
+	p.Pending(2)
+
+	p.clock.Set(start.Add(time.Second))
+	p.Queued()
+	p.Fetched(1)
+	p.Queued()
+	p.Fetched(2)
+
+	report := p.Report()
+	switch {
+	case report.Phase != FetchingContainers:
+		t.Fatalf("should be fetching containers but is %s", report.Phase)
+	case !report.Started.Equal(start):
+		t.Fatalf("should have started at %s but started at %s", start, report.Started)
+	case report.Fetched != 2 || report.Expected != 4:
+		t.Fatalf("should have fetched 2 of 4 containers but fetched %d of %d", report.Fetched, report.Expected)
+	case report.ToExecute != 2:
+		t.Fatalf("should have 2 operations to execute but has %d", report.ToExecute)
+	case !report.LastProgress.Equal(start.Add(time.Second)):
+		t.Fatalf("progressed at the wrong time")
+	case !report.EstimatedCompletion.Equal(start.Add(2 * time.Second)):
+		t.Fatalf("should be estimated to finish at %s but is estimated to finish at %s", start.Add(2*time.Second), report.EstimatedCompletion)
+	}
+
+	p.SetPhase(ExecutingContainers)
+	p.clock.Set(start.Add(3 * time.Second))
+	p.Executed()
+
+	report = p.Report()
+	switch {
+	case report.Executed != 1 || report.ToExecute != 2:
+		t.Fatalf("should have executed 1 of 2 operations but executed %d of %d", report.Executed, report.ToExecute)
+	case !report.EstimatedCompletion.Equal(start.Add(5 * time.Second)):
+		t.Fatalf("should be estimated to finish at %s but is estimated to finish at %s", start.Add(5*time.Second), report.EstimatedCompletion)
+	}
+
+	// Operations queued before a restart are still counted once executed
+	p.Executed()
+	p.Executed()
+	if report := p.Report(); report.Executed != 3 || report.ToExecute != 3 {
+		t.Fatalf("should have executed 3 of 3 operations but executed %d of %d", report.Executed, report.ToExecute)
+	}
+
+	p.SetPhase(Bootstrapped)
+	if report := p.Report(); !report.EstimatedCompletion.IsZero() {
+		t.Fatalf("shouldn't estimate the completion of a bootstrapped chain")
+	}
+}
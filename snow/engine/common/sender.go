@@ -10,12 +10,38 @@ import (
 // Sender defines how a consensus engine sends messages and requests to other
 // validators
 type Sender interface {
+	StateSummarySender
+	StateChunkSender
 	FrontierSender
 	AcceptedSender
 	FetchSender
 	QuerySender
 }
 
+// StateSummarySender defines how a consensus engine sends state summary
+// messages to other validators
+type StateSummarySender interface {
+	// GetStateSummary requests that every validator in [validatorIDs] sends a
+	// StateSummary message.
+	GetStateSummary(validatorIDs ids.ShortSet, requestID uint32)
+
+	// StateSummary responds to a GetStateSummary message with a summary of
+	// this engine's chain's state.
+	StateSummary(validatorID ids.ShortID, requestID uint32, summary []byte)
+}
+
+// StateChunkSender defines how a consensus engine sends messages that transfer a
+// summarized state to other validators
+type StateChunkSender interface {
+	// GetStateChunk requests that the validator [validatorID] sends a StateChunk message
+	// with the part of the summarized state described by [request].
+	GetStateChunk(validatorID ids.ShortID, requestID uint32, request []byte)
+
+	// StateChunk responds to a GetStateChunk message with part of this engine's chain's
+	// summarized state.
+	StateChunk(validatorID ids.ShortID, requestID uint32, response []byte)
+}
+
 // FrontierSender defines how a consensus engine sends frontier messages to
 // other validators
 type FrontierSender interface {
@@ -21,6 +21,14 @@ type EngineTest struct {
 
 	CantNotify,
 
+	CantGetStateSummary,
+	CantGetStateSummaryFailed,
+	CantStateSummary,
+
+	CantGetStateChunk,
+	CantGetStateChunkFailed,
+	CantStateChunk,
+
 	CantGetAcceptedFrontier,
 	CantGetAcceptedFrontierFailed,
 	CantAcceptedFrontier,
@@ -45,6 +53,10 @@ type EngineTest struct {
 	PutF, PushQueryF                                                                   func(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte)
 	GetAcceptedFrontierF, GetAcceptedFrontierFailedF, GetAcceptedFailedF, QueryFailedF func(validatorID ids.ShortID, requestID uint32)
 	AcceptedFrontierF, GetAcceptedF, AcceptedF, ChitsF                                 func(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set)
+	GetStateSummaryF, GetStateSummaryFailedF                                           func(validatorID ids.ShortID, requestID uint32)
+	StateSummaryF                                                                      func(validatorID ids.ShortID, requestID uint32, summary []byte)
+	GetStateChunkF, StateChunkF                                                        func(validatorID ids.ShortID, requestID uint32, state []byte)
+	GetStateChunkFailedF                                                               func(validatorID ids.ShortID, requestID uint32)
 }
 
 // Default ...
@@ -56,6 +68,14 @@ func (e *EngineTest) Default(cant bool) {
 
 	e.CantNotify = cant
 
+	e.CantGetStateSummary = cant
+	e.CantGetStateSummaryFailed = cant
+	e.CantStateSummary = cant
+
+	e.CantGetStateChunk = cant
+	e.CantGetStateChunkFailed = cant
+	e.CantStateChunk = cant
+
 	e.CantGetAcceptedFrontier = cant
 	e.CantGetAcceptedFrontierFailed = cant
 	e.CantAcceptedFrontier = cant
@@ -112,6 +132,60 @@ func (e *EngineTest) Notify(msg Message) {
 	}
 }
 
+// GetStateSummary ...
+func (e *EngineTest) GetStateSummary(validatorID ids.ShortID, requestID uint32) {
+	if e.GetStateSummaryF != nil {
+		e.GetStateSummaryF(validatorID, requestID)
+	} else if e.CantGetStateSummary && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetStateSummary")
+	}
+}
+
+// GetStateSummaryFailed ...
+func (e *EngineTest) GetStateSummaryFailed(validatorID ids.ShortID, requestID uint32) {
+	if e.GetStateSummaryFailedF != nil {
+		e.GetStateSummaryFailedF(validatorID, requestID)
+	} else if e.CantGetStateSummaryFailed && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetStateSummaryFailed")
+	}
+}
+
+// StateSummary ...
+func (e *EngineTest) StateSummary(validatorID ids.ShortID, requestID uint32, summary []byte) {
+	if e.StateSummaryF != nil {
+		e.StateSummaryF(validatorID, requestID, summary)
+	} else if e.CantStateSummary && e.T != nil {
+		e.T.Fatalf("Unexpectedly called StateSummary")
+	}
+}
+
+// GetStateChunk ...
+func (e *EngineTest) GetStateChunk(validatorID ids.ShortID, requestID uint32, request []byte) {
+	if e.GetStateChunkF != nil {
+		e.GetStateChunkF(validatorID, requestID, request)
+	} else if e.CantGetStateChunk && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetStateChunk")
+	}
+}
+
+// GetStateChunkFailed ...
+func (e *EngineTest) GetStateChunkFailed(validatorID ids.ShortID, requestID uint32) {
+	if e.GetStateChunkFailedF != nil {
+		e.GetStateChunkFailedF(validatorID, requestID)
+	} else if e.CantGetStateChunkFailed && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetStateChunkFailed")
+	}
+}
+
+// StateChunk ...
+func (e *EngineTest) StateChunk(validatorID ids.ShortID, requestID uint32, response []byte) {
+	if e.StateChunkF != nil {
+		e.StateChunkF(validatorID, requestID, response)
+	} else if e.CantStateChunk && e.T != nil {
+		e.T.Fatalf("Unexpectedly called StateChunk")
+	}
+}
+
 // GetAcceptedFrontier ...
 func (e *EngineTest) GetAcceptedFrontier(validatorID ids.ShortID, requestID uint32) {
 	if e.GetAcceptedFrontierF != nil {
@@ -13,11 +13,17 @@ import (
 type SenderTest struct {
 	T *testing.T
 
+	CantGetStateSummary, CantStateSummary,
+	CantGetStateChunk, CantStateChunk,
 	CantGetAcceptedFrontier, CantAcceptedFrontier,
 	CantGetAccepted, CantAccepted,
 	CantGet, CantPut,
 	CantPullQuery, CantPushQuery, CantChits bool
 
+	GetStateSummaryF     func(ids.ShortSet, uint32)
+	StateSummaryF        func(ids.ShortID, uint32, []byte)
+	GetStateChunkF       func(ids.ShortID, uint32, []byte)
+	StateChunkF          func(ids.ShortID, uint32, []byte)
 	GetAcceptedFrontierF func(ids.ShortSet, uint32)
 	AcceptedFrontierF    func(ids.ShortID, uint32, ids.Set)
 	GetAcceptedF         func(ids.ShortSet, uint32, ids.Set)
@@ -31,6 +37,10 @@ type SenderTest struct {
 
 // Default set the default callable value to [cant]
 func (s *SenderTest) Default(cant bool) {
+	s.CantGetStateSummary = cant
+	s.CantStateSummary = cant
+	s.CantGetStateChunk = cant
+	s.CantStateChunk = cant
 	s.CantGetAcceptedFrontier = cant
 	s.CantAcceptedFrontier = cant
 	s.CantGetAccepted = cant
@@ -42,6 +52,50 @@ func (s *SenderTest) Default(cant bool) {
 	s.CantChits = cant
 }
 
+// GetStateSummary calls GetStateSummaryF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *SenderTest) GetStateSummary(validatorIDs ids.ShortSet, requestID uint32) {
+	if s.GetStateSummaryF != nil {
+		s.GetStateSummaryF(validatorIDs, requestID)
+	} else if s.CantGetStateSummary && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetStateSummary")
+	}
+}
+
+// StateSummary calls StateSummaryF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *SenderTest) StateSummary(validatorID ids.ShortID, requestID uint32, summary []byte) {
+	if s.StateSummaryF != nil {
+		s.StateSummaryF(validatorID, requestID, summary)
+	} else if s.CantStateSummary && s.T != nil {
+		s.T.Fatalf("Unexpectedly called StateSummary")
+	}
+}
+
+// GetStateChunk calls GetStateChunkF if it was initialized. If it wasn't initialized and
+// this function shouldn't be called and testing was initialized, then testing
+// will fail.
+func (s *SenderTest) GetStateChunk(validatorID ids.ShortID, requestID uint32, request []byte) {
+	if s.GetStateChunkF != nil {
+		s.GetStateChunkF(validatorID, requestID, request)
+	} else if s.CantGetStateChunk && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetStateChunk")
+	}
+}
+
+// StateChunk calls StateChunkF if it was initialized. If it wasn't initialized and this
+// function shouldn't be called and testing was initialized, then testing will
+// fail.
+func (s *SenderTest) StateChunk(validatorID ids.ShortID, requestID uint32, response []byte) {
+	if s.StateChunkF != nil {
+		s.StateChunkF(validatorID, requestID, response)
+	} else if s.CantStateChunk && s.T != nil {
+		s.T.Fatalf("Unexpectedly called StateChunk")
+	}
+}
+
 // GetAcceptedFrontier calls GetAcceptedFrontierF if it was initialized. If it
 // wasn't initialized and this function shouldn't be called and testing was
 // initialized, then testing will fail.
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/snow"
 )
@@ -19,12 +21,13 @@ var (
 type VMTest struct {
 	T *testing.T
 
-	CantInitialize, CantShutdown, CantCreateHandlers, CantCreateStaticHandlers bool
+	CantInitialize, CantShutdown, CantCreateHandlers, CantCreateStaticHandlers, CantMetrics bool
 
 	InitializeF           func(*snow.Context, database.Database, []byte, chan<- Message, []*Fx) error
 	ShutdownF             func()
 	CreateHandlersF       func() map[string]*HTTPHandler
 	CreateStaticHandlersF func() map[string]*HTTPHandler
+	MetricsF              func(prometheus.Registerer) error
 }
 
 // Default ...
@@ -32,6 +35,7 @@ func (vm *VMTest) Default(cant bool) {
 	vm.CantInitialize = cant
 	vm.CantShutdown = cant
 	vm.CantCreateHandlers = cant
+	vm.CantMetrics = cant
 }
 
 // Initialize ...
@@ -75,3 +79,14 @@ func (vm *VMTest) CreateStaticHandlers() map[string]*HTTPHandler {
 	}
 	return nil
 }
+
+// Metrics ...
+func (vm *VMTest) Metrics(registerer prometheus.Registerer) error {
+	if vm.MetricsF != nil {
+		return vm.MetricsF(registerer)
+	}
+	if vm.CantMetrics && vm.T != nil {
+		vm.T.Fatalf("Unexpectedly called Metrics")
+	}
+	return nil
+}
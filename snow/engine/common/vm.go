@@ -4,7 +4,10 @@
 package common
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
 )
 
@@ -54,6 +57,13 @@ type VM interface {
 	// it have an extension called `accounts`, where clients could get
 	// information about their accounts.
 	CreateHandlers() map[string]*HTTPHandler
+
+	// Metrics registers the VM's metrics with [registerer]. It's called once,
+	// after Initialize. The metrics should be in the namespace of the chain,
+	// [ctx.Namespace], and should include those that every VM reports, such as
+	// the number of transactions accepted and the time spent verifying
+	// containers, so that every chain can be monitored the same way.
+	Metrics(registerer prometheus.Registerer) error
 }
 
 // StaticVM describes the functionality that allows a user to interact with a VM
@@ -73,3 +83,100 @@ type StaticVM interface {
 	// genesis bytes this VM can interpret.
 	CreateStaticHandlers() map[string]*HTTPHandler
 }
+
+// GenesisVerifier describes the functionality that allows a VM to check genesis
+// data without being initialized. This lets the data that a chain will be
+// created with be checked before the chain is proposed.
+type GenesisVerifier interface {
+	// VerifyGenesis returns nil iff [genesisData] is valid genesis data for a
+	// chain running this VM.
+	VerifyGenesis(genesisData []byte) error
+}
+
+// StateSyncableVM describes the functionality that allows a VM's state to be
+// set to a summary of another node's state while bootstrapping, instead of by
+// executing every container in the chain's history.
+type StateSyncableVM interface {
+	// StateSummary returns a summary of the VM's state, or nil if it has none
+	// to offer. Bootstrapping nodes only sync to a summary that enough of
+	// their beacons return, so nodes whose accepted state is the same should
+	// return the same summary. For example, a VM might summarize its state as
+	// of the most recent of a set of checkpoints.
+	StateSummary() ([]byte, error)
+
+	// SyncState starts setting the VM's state to the one summarized by
+	// [summary], which was returned by StateSummary on other nodes. It
+	// returns the first request for the summarized state, which is sent to
+	// the nodes that returned the summary, or nil if the state doesn't need to
+	// be fetched. If the VM's state is already the result of the container
+	// that the summarized state is the result of, or a later one, its state
+	// should be left unchanged.
+	SyncState(summary []byte) ([]byte, error)
+
+	// GetState returns the part of the VM's summarized state that [request]
+	// describes. [request] was returned by SyncState or PutState on another
+	// node.
+	GetState(request []byte) ([]byte, error)
+
+	// PutState adds [response], which another node's GetState returned, to the
+	// state being synced. It returns the next request for the summarized
+	// state, or nil once the whole state was fetched. Then the VM's state must
+	// be set to the fetched state if it matches the summary, and the container
+	// that the summarized state is the result of must be reported as
+	// accepted, so that bootstrapping only executes the containers after it.
+	// If [response] is invalid, an error is returned along with the request
+	// to send to another node instead. If the state can't be synced, an error
+	// and no request are returned.
+	PutState(response []byte) ([]byte, error)
+}
+
+// CheckpointableVM describes the functionality that allows a VM's state to be
+// rolled back to one of its recent checkpoints. This lets an operator who
+// discovers that a bug in the VM corrupted its state recover the chain without
+// resyncing it from genesis.
+type CheckpointableVM interface {
+	// Checkpoints returns the IDs of the checkpoints that the VM's state can be
+	// rolled back to, oldest first. The ID of a checkpoint is the ID of the
+	// accepted container that the checkpointed state is the result of.
+	Checkpoints() []ids.ID
+
+	// RollbackTo sets the VM's state back to its state at the checkpoint
+	// [checkpointID]. The containers accepted after it are no longer marked as
+	// accepted, so the chain should be restarted afterwards to bootstrap them
+	// again.
+	RollbackTo(checkpointID ids.ID) error
+}
+
+// CachingVM describes the functionality that allows the caches a VM keeps in
+// memory to be cleared. This lets an operator release the memory they hold,
+// or make the VM reload its state from its database, without restarting the
+// chain.
+type CachingVM interface {
+	// ClearCaches removes every entry from the VM's caches. The VM must behave
+	// the same afterwards, only reading from its database more.
+	ClearCaches()
+}
+
+// PrunableVM describes the functionality that allows the bytes of containers
+// the VM accepted long ago to be deleted from its database. This bounds how
+// much disk a chain uses as it grows.
+type PrunableVM interface {
+	// PruneContainer deletes the bytes of the accepted container
+	// [containerID]. The VM's state must be unaffected, but the container may
+	// no longer be returned by the VM. Returns an error if the container must
+	// be kept, for example because the VM's state can be rolled back to it.
+	PruneContainer(containerID ids.ID) error
+}
+
+// AddressableVM describes the functionality that allows the transactions that
+// touched an address to be indexed outside of the VM. This lets a node index
+// the history of each address without knowing how to parse the VM's
+// transactions.
+type AddressableVM interface {
+	// TxAddresses returns the addresses whose history includes the accepted
+	// transaction [txID]
+	TxAddresses(txID ids.ID) ([][]byte, error)
+
+	// Parse returns the address that the string [addr] formats
+	Parse(addr string) ([]byte, error)
+}
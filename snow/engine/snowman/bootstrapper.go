@@ -45,6 +45,9 @@ func (b *bootstrapper) Initialize(config BootstrapConfig) {
 		vm:          b.VM,
 	})
 
+	if vm, ok := b.VM.(common.StateSyncableVM); ok {
+		config.StateSyncVM = vm
+	}
 	config.Bootstrapable = b
 	b.Bootstrapper.Initialize(config.Config)
 }
@@ -129,6 +132,7 @@ func (b *bootstrapper) sendRequest(blkID ids.ID) {
 	b.BootstrapConfig.Sender.Get(validatorID, b.RequestID, blkID)
 
 	b.numPendingRequests.Set(float64(b.pending.Len()))
+	b.Bootstrapper.Progress.Pending(b.pending.Len())
 }
 
 func (b *bootstrapper) addBlock(blk snowman.Block) {
@@ -143,7 +147,9 @@ func (b *bootstrapper) addBlock(blk snowman.Block) {
 			blk:         blk,
 		}); err == nil {
 			b.numBlocked.Inc()
+			b.Bootstrapper.Progress.Queued()
 		}
+		b.Bootstrapper.Progress.Fetched(b.pending.Len())
 
 		blk = blk.Parent()
 		status = blk.Status()
@@ -171,11 +177,13 @@ func (b *bootstrapper) finish() {
 		return
 	}
 
+	b.Bootstrapper.Progress.SetPhase(common.ExecutingContainers)
 	b.executeAll(b.Blocked, b.numBlocked)
 
 	// Start consensus
 	b.onFinished()
 	b.finished = true
+	b.Bootstrapper.Progress.SetPhase(common.Bootstrapped)
 
 	if b.Bootstrapped != nil {
 		b.Bootstrapped()
@@ -188,5 +196,6 @@ func (b *bootstrapper) executeAll(jobs *queue.Jobs, numBlocked prometheus.Gauge)
 		if err := jobs.Execute(job); err != nil {
 			b.BootstrapConfig.Context.Log.Warn("Error executing: %s", err)
 		}
+		b.Bootstrapper.Progress.Executed()
 	}
 }
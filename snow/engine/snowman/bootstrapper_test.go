@@ -18,6 +18,7 @@ import (
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/snow/engine/common/queue"
+	"github.com/ava-labs/gecko/snow/networking/benchlist"
 	"github.com/ava-labs/gecko/snow/networking/handler"
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/snow/networking/timeout"
@@ -52,8 +53,8 @@ func newConfig(t *testing.T) (BootstrapConfig, ids.ShortID, *common.SenderTest,
 	peerID := peer.ID()
 	peers.Add(peer)
 
-	handler.Initialize(engine, make(chan common.Message), 1)
-	timeouts.Initialize(0)
+	handler.Initialize(engine, make(chan common.Message), 1, "", prometheus.NewRegistry())
+	timeouts.Initialize(0, benchlist.NewNoBenchlist())
 	router.Initialize(ctx.Log, timeouts)
 
 	blocker, _ := queue.New(db)
@@ -425,3 +426,249 @@ func TestBootstrapperFilterAccepted(t *testing.T) {
 		t.Fatalf("Blk shouldn't be accepted")
 	}
 }
+
+type stateSyncVMTest struct {
+	*VMTest
+
+	summary                       []byte
+	syncState, getState, putState func([]byte) ([]byte, error)
+}
+
+func (vm *stateSyncVMTest) StateSummary() ([]byte, error) { return vm.summary, nil }
+
+func (vm *stateSyncVMTest) SyncState(summary []byte) ([]byte, error) { return vm.syncState(summary) }
+
+func (vm *stateSyncVMTest) GetState(request []byte) ([]byte, error) { return vm.getState(request) }
+
+func (vm *stateSyncVMTest) PutState(response []byte) ([]byte, error) { return vm.putState(response) }
+
+func TestBootstrapperStateSync(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	summary := []byte{1, 2, 3}
+
+	synced := []byte(nil)
+	stateSyncVM := &stateSyncVMTest{
+		VMTest: vm,
+		syncState: func(summary []byte) ([]byte, error) {
+			synced = summary
+			return nil, nil
+		},
+	}
+	config.VM = stateSyncVM
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	reqID := new(uint32)
+	sender.GetStateSummaryF = func(vdrs ids.ShortSet, innerReqID uint32) {
+		if !vdrs.Contains(peerID) {
+			t.Fatalf("Should have requested a state summary from %s", peerID)
+		}
+		*reqID = innerReqID
+	}
+	sender.CantGetAcceptedFrontier = true
+
+	bs.Startup()
+
+	sender.GetStateSummaryF = nil
+	sender.CantGetAcceptedFrontier = false
+
+	requestedFrontier := new(bool)
+	sender.GetAcceptedFrontierF = func(ids.ShortSet, uint32) { *requestedFrontier = true }
+
+	bs.StateSummary(peerID, *reqID, summary)
+
+	if !bytes.Equal(synced, summary) {
+		t.Fatalf("Should have synced to %v but synced to %v", summary, synced)
+	}
+	if !*requestedFrontier {
+		t.Fatalf("Should have requested the accepted frontier after syncing state")
+	}
+
+	// Replying with the VM's summary
+	replied := []byte(nil)
+	sender.StateSummaryF = func(vdr ids.ShortID, _ uint32, summary []byte) { replied = summary }
+	stateSyncVM.summary = summary
+	bs.GetStateSummary(peerID, 0)
+	if !bytes.Equal(replied, summary) {
+		t.Fatalf("Should have replied with %v but replied with %v", summary, replied)
+	}
+}
+
+func TestBootstrapperStateSyncNoSummary(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	config.VM = &stateSyncVMTest{
+		VMTest: vm,
+		syncState: func([]byte) ([]byte, error) {
+			t.Fatalf("Shouldn't have synced state without a summary")
+			return nil, nil
+		},
+	}
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	reqID := new(uint32)
+	sender.GetStateSummaryF = func(_ ids.ShortSet, innerReqID uint32) { *reqID = innerReqID }
+
+	bs.Startup()
+
+	requestedFrontier := new(bool)
+	sender.GetAcceptedFrontierF = func(ids.ShortSet, uint32) { *requestedFrontier = true }
+
+	bs.GetStateSummaryFailed(peerID, *reqID)
+
+	if !*requestedFrontier {
+		t.Fatalf("Should have requested the accepted frontier")
+	}
+}
+
+func TestBootstrapperStateSummaryWrongRequestID(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	config.VM = &stateSyncVMTest{
+		VMTest: vm,
+		syncState: func([]byte) ([]byte, error) {
+			t.Fatalf("Shouldn't have synced state to a summary from another request")
+			return nil, nil
+		},
+	}
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	reqID := new(uint32)
+	sender.GetStateSummaryF = func(_ ids.ShortSet, innerReqID uint32) { *reqID = innerReqID }
+	sender.CantGetAcceptedFrontier = true
+
+	bs.Startup()
+
+	bs.StateSummary(peerID, *reqID+1, []byte{1, 2, 3})
+}
+
+func TestBootstrapperStateFetch(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	summary := []byte{1, 2, 3}
+	firstRequest := []byte{4}
+	secondRequest := []byte{5}
+	response := []byte{6}
+
+	stateSyncVM := &stateSyncVMTest{
+		VMTest:    vm,
+		syncState: func([]byte) ([]byte, error) { return firstRequest, nil },
+	}
+	config.VM = stateSyncVM
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	reqID := new(uint32)
+	sender.GetStateSummaryF = func(_ ids.ShortSet, innerReqID uint32) { *reqID = innerReqID }
+
+	bs.Startup()
+
+	requested := []byte(nil)
+	sender.GetStateChunkF = func(vdr ids.ShortID, innerReqID uint32, request []byte) {
+		if !vdr.Equals(peerID) {
+			t.Fatalf("Should have requested the state from %s", peerID)
+		}
+		*reqID = innerReqID
+		requested = request
+	}
+	sender.CantGetAcceptedFrontier = true
+
+	bs.StateSummary(peerID, *reqID, summary)
+
+	if !bytes.Equal(requested, firstRequest) {
+		t.Fatalf("Should have requested %v but requested %v", firstRequest, requested)
+	}
+
+	put := [][]byte(nil)
+	stateSyncVM.putState = func(response []byte) ([]byte, error) {
+		put = append(put, response)
+		if len(put) == 1 {
+			return secondRequest, nil
+		}
+		return nil, nil
+	}
+
+	// A response to an earlier request is dropped
+	bs.StateChunk(peerID, *reqID-1, response)
+	if len(put) != 0 {
+		t.Fatalf("Shouldn't have put a response to another request")
+	}
+
+	bs.StateChunk(peerID, *reqID, response)
+	if !bytes.Equal(requested, secondRequest) {
+		t.Fatalf("Should have requested %v but requested %v", secondRequest, requested)
+	}
+
+	sender.CantGetAcceptedFrontier = false
+	requestedFrontier := new(bool)
+	sender.GetAcceptedFrontierF = func(ids.ShortSet, uint32) { *requestedFrontier = true }
+
+	bs.StateChunk(peerID, *reqID, response)
+	if len(put) != 2 {
+		t.Fatalf("Should have put 2 responses but put %d", len(put))
+	}
+	if !*requestedFrontier {
+		t.Fatalf("Should have requested the accepted frontier after syncing state")
+	}
+
+	// Replying with the VM's state
+	replied := []byte(nil)
+	stateSyncVM.getState = func(request []byte) ([]byte, error) {
+		if !bytes.Equal(request, firstRequest) {
+			t.Fatalf("Should have gotten the state for %v but got it for %v", firstRequest, request)
+		}
+		return response, nil
+	}
+	sender.StateChunkF = func(_ ids.ShortID, _ uint32, state []byte) { replied = state }
+	bs.GetStateChunk(peerID, 0, firstRequest)
+	if !bytes.Equal(replied, response) {
+		t.Fatalf("Should have replied with %v but replied with %v", response, replied)
+	}
+}
+
+func TestBootstrapperStateFetchFailed(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	config.VM = &stateSyncVMTest{
+		VMTest:    vm,
+		syncState: func([]byte) ([]byte, error) { return []byte{4}, nil },
+		putState: func([]byte) ([]byte, error) {
+			t.Fatalf("Shouldn't have put a response after the request failed")
+			return nil, nil
+		},
+	}
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	reqID := new(uint32)
+	sender.GetStateSummaryF = func(_ ids.ShortSet, innerReqID uint32) { *reqID = innerReqID }
+
+	bs.Startup()
+
+	sender.GetStateChunkF = func(_ ids.ShortID, innerReqID uint32, _ []byte) { *reqID = innerReqID }
+
+	bs.StateSummary(peerID, *reqID, []byte{1, 2, 3})
+
+	requestedFrontier := new(bool)
+	sender.GetAcceptedFrontierF = func(ids.ShortSet, uint32) { *requestedFrontier = true }
+
+	// The only beacon that provided the summary failed to provide its state
+	bs.GetStateChunkFailed(peerID, *reqID)
+
+	if !*requestedFrontier {
+		t.Fatalf("Should have requested the accepted frontier without syncing state")
+	}
+}
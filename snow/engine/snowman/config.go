@@ -4,6 +4,8 @@
 package snowman
 
 import (
+	"fmt"
+
 	"github.com/ava-labs/gecko/snow/consensus/snowball"
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 )
@@ -15,3 +17,11 @@ type Config struct {
 	Params    snowball.Parameters
 	Consensus snowman.Consensus
 }
+
+// Valid returns nil if an engine can be initialized with this config
+func (c Config) Valid() error {
+	if err := c.Params.Valid(); err != nil {
+		return fmt.Errorf("invalid consensus parameters: %w", err)
+	}
+	return nil
+}
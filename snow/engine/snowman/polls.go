@@ -27,6 +27,9 @@ func (p *polls) Add(requestID uint32, numPolled int) bool {
 	if !exists {
 		poll.alpha = p.alpha
 		poll.numPolled = numPolled
+		// The votes are only counted, so the order they're iterated over in
+		// doesn't matter, and most polls are for a single block
+		poll.votes = ids.NewSortedBag(1)
 		p.m[requestID] = poll
 
 		p.numPolls.Set(float64(len(p.m))) // Tracks performance statistics
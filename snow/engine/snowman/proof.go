@@ -0,0 +1,112 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowman
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	errInvalidProof = errors.New("transaction isn't in the block the proof is for")
+)
+
+// ProvableChainVM describes the functionality that allows a Snowman VM to
+// prove that a transaction is in an accepted block. Light clients can check
+// the proofs without the VM's state, as long as they trust the block's header.
+type ProvableChainVM interface {
+	// Header returns the header of the accepted block [blkID]
+	Header(blkID ids.ID) (*BlockHeader, error)
+
+	// Prove returns a proof that the transaction [txID] is in the accepted
+	// block [blkID]
+	Prove(blkID, txID ids.ID) (*InclusionProof, error)
+}
+
+// BlockHeader is a compact summary of a block that commits to the block's
+// transactions
+type BlockHeader struct {
+	// ID of the block
+	BlockID ids.ID `serialize:"true"`
+
+	// ID of the block's parent, so that headers can be chained
+	ParentID ids.ID `serialize:"true"`
+
+	// Number of transactions in the block
+	NumTxs uint32 `serialize:"true"`
+
+	// Root of the Merkle tree whose leaves are the IDs of the block's
+	// transactions, in the order they are in the block
+	TxRoot ids.ID `serialize:"true"`
+}
+
+// NewBlockHeader returns the header of the block [blkID], with parent
+// [parentID], that contains the transactions [txIDs], in order
+func NewBlockHeader(blkID, parentID ids.ID, txIDs []ids.ID) *BlockHeader {
+	return &BlockHeader{
+		BlockID:  blkID,
+		ParentID: parentID,
+		NumTxs:   uint32(len(txIDs)),
+		TxRoot:   ids.NewID(hashing.MerkleRoot(txLeaves(txIDs))),
+	}
+}
+
+// InclusionProof proves that a transaction is in a block
+type InclusionProof struct {
+	// Header of the block the transaction is in
+	Header BlockHeader `serialize:"true"`
+
+	// ID of the transaction
+	TxID ids.ID `serialize:"true"`
+
+	// Position of the transaction in the block
+	Index uint32 `serialize:"true"`
+
+	// Siblings of the nodes on the path from the transaction to the root of
+	// the header's Merkle tree
+	Path []ids.ID `serialize:"true"`
+}
+
+// NewInclusionProof returns a proof that the transaction at [index] of [txIDs]
+// is in the block with header [header]. [txIDs] must be the IDs of the block's
+// transactions, in order.
+func NewInclusionProof(header *BlockHeader, txIDs []ids.ID, index int) (*InclusionProof, error) {
+	path, err := hashing.MerkleProof(txLeaves(txIDs), index)
+	if err != nil {
+		return nil, err
+	}
+	proof := &InclusionProof{
+		Header: *header,
+		TxID:   txIDs[index],
+		Index:  uint32(index),
+		Path:   make([]ids.ID, len(path)),
+	}
+	for i, node := range path {
+		proof.Path[i] = ids.NewID(node)
+	}
+	return proof, nil
+}
+
+// Verify returns nil if the proof shows that the transaction is in the block
+// the header is for
+func (p *InclusionProof) Verify() error {
+	path := make([]hashing.Hash256, len(p.Path))
+	for i, node := range p.Path {
+		path[i] = node.Key()
+	}
+	if !hashing.VerifyMerkleProof(p.Header.TxRoot.Key(), p.TxID.Key(), int(p.Index), int(p.Header.NumTxs), path) {
+		return errInvalidProof
+	}
+	return nil
+}
+
+func txLeaves(txIDs []ids.ID) []hashing.Hash256 {
+	leaves := make([]hashing.Hash256, len(txIDs))
+	for i, txID := range txIDs {
+		leaves[i] = txID.Key()
+	}
+	return leaves
+}
@@ -292,7 +292,7 @@ func (t *Transitive) pullSample(blkID ids.ID) {
 	t.Config.Context.Log.Verbo("About to sample from: %s", t.Config.Validators)
 	p := t.Consensus.Parameters()
 	vdrs := t.Config.Validators.Sample(p.K)
-	vdrSet := ids.ShortSet{}
+	vdrSet := ids.NewShortSet(len(vdrs))
 	for _, vdr := range vdrs {
 		vdrSet.Add(vdr.ID())
 	}
@@ -309,7 +309,7 @@ func (t *Transitive) pushSample(blk snowman.Block) {
 	t.Config.Context.Log.Verbo("About to sample from: %s", t.Config.Validators)
 	p := t.Consensus.Parameters()
 	vdrs := t.Config.Validators.Sample(p.K)
-	vdrSet := ids.ShortSet{}
+	vdrSet := ids.NewShortSet(len(vdrs))
 	for _, vdr := range vdrs {
 		vdrSet.Add(vdr.ID())
 	}
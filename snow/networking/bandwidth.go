@@ -0,0 +1,137 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networking
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// ChainBandwidth is the number of message bytes that have been attributed to a
+// chain.
+type ChainBandwidth struct {
+	ChainID       ids.ID
+	BytesSent     uint64
+	BytesReceived uint64
+}
+
+// unknownChain is the label of the bytes received on behalf of chains that
+// this node doesn't know. The chain IDs of received messages are chosen by
+// peers, so they can't be used as labels without bounding them.
+const unknownChain = "unknown"
+
+// BandwidthTracker attributes the bytes of consensus messages to the chain the
+// messages were sent on behalf of.
+type BandwidthTracker struct {
+	lock    sync.Mutex
+	known   func(chainID ids.ID) bool
+	chains  map[[32]byte]*ChainBandwidth
+	unknown uint64
+
+	bytesSent, bytesReceived *prometheus.CounterVec
+}
+
+// Initialize the tracker and register its metrics with [registerer]. Received
+// bytes are only attributed to the chains that [known] reports.
+func (bt *BandwidthTracker) Initialize(log logging.Logger, registerer prometheus.Registerer, known func(chainID ids.ID) bool) {
+	bt.known = known
+	bt.chains = make(map[[32]byte]*ChainBandwidth)
+	bt.bytesSent = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "chain_bytes_sent",
+			Help:      "Number of message bytes sent on behalf of a chain",
+		},
+		[]string{"chain"},
+	)
+	bt.bytesReceived = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "chain_bytes_received",
+			Help:      "Number of message bytes received on behalf of a chain",
+		},
+		[]string{"chain"},
+	)
+
+	if err := registerer.Register(bt.bytesSent); err != nil {
+		log.Error("Failed to register chain_bytes_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(bt.bytesReceived); err != nil {
+		log.Error("Failed to register chain_bytes_received statistics due to %s", err)
+	}
+}
+
+// Sent records that [numBytes] were sent on behalf of [chainID]
+func (bt *BandwidthTracker) Sent(chainID ids.ID, numBytes int) {
+	if numBytes <= 0 {
+		return
+	}
+
+	bt.lock.Lock()
+	defer bt.lock.Unlock()
+
+	bt.chain(chainID).BytesSent += uint64(numBytes)
+	bt.bytesSent.WithLabelValues(chainID.String()).Add(float64(numBytes))
+}
+
+// Received records that [numBytes] were received on behalf of [chainID]. If
+// [chainID] isn't known, the bytes are recorded as received on behalf of an
+// unknown chain.
+func (bt *BandwidthTracker) Received(chainID ids.ID, numBytes int) {
+	if numBytes <= 0 {
+		return
+	}
+
+	bt.lock.Lock()
+	defer bt.lock.Unlock()
+
+	if !bt.known(chainID) {
+		bt.unknown += uint64(numBytes)
+		bt.bytesReceived.WithLabelValues(unknownChain).Add(float64(numBytes))
+		return
+	}
+
+	bt.chain(chainID).BytesReceived += uint64(numBytes)
+	bt.bytesReceived.WithLabelValues(chainID.String()).Add(float64(numBytes))
+}
+
+// Bandwidth returns the bandwidth attributed to each chain, sorted by chain ID
+func (bt *BandwidthTracker) Bandwidth() []ChainBandwidth {
+	bt.lock.Lock()
+	defer bt.lock.Unlock()
+
+	bandwidth := make([]ChainBandwidth, 0, len(bt.chains))
+	for _, chain := range bt.chains {
+		bandwidth = append(bandwidth, *chain)
+	}
+	sort.Slice(bandwidth, func(i, j int) bool {
+		return bytes.Compare(bandwidth[i].ChainID.Bytes(), bandwidth[j].ChainID.Bytes()) == -1
+	})
+	return bandwidth
+}
+
+// UnknownBytesReceived returns the number of bytes received on behalf of chains
+// that this node doesn't know
+func (bt *BandwidthTracker) UnknownBytesReceived() uint64 {
+	bt.lock.Lock()
+	defer bt.lock.Unlock()
+
+	return bt.unknown
+}
+
+func (bt *BandwidthTracker) chain(chainID ids.ID) *ChainBandwidth {
+	key := chainID.Key()
+	chain, exists := bt.chains[key]
+	if !exists {
+		chain = &ChainBandwidth{ChainID: chainID}
+		bt.chains[key] = chain
+	}
+	return chain
+}
@@ -0,0 +1,57 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networking
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestBandwidthTracker(t *testing.T) {
+	chain0 := ids.NewID([32]byte{0})
+	chain1 := ids.NewID([32]byte{1})
+	chain2 := ids.NewID([32]byte{2})
+
+	bt := BandwidthTracker{}
+	bt.Initialize(logging.NoLog{}, prometheus.NewRegistry(), func(chainID ids.ID) bool {
+		return !chainID.Equals(chain2)
+	})
+
+	bt.Sent(chain1, 10)
+	bt.Sent(chain1, 5)
+	bt.Received(chain1, 7)
+	bt.Received(chain0, 3)
+	bt.Sent(chain0, 0)
+	bt.Received(chain2, 4)
+	bt.Received(chain2, 2)
+
+	bandwidth := bt.Bandwidth()
+	if len(bandwidth) != 2 {
+		t.Fatalf("Should have tracked 2 chains, tracked %d", len(bandwidth))
+	}
+
+	if !bandwidth[0].ChainID.Equals(chain0) {
+		t.Fatalf("Wrong chain ID returned")
+	} else if bandwidth[0].BytesSent != 0 {
+		t.Fatalf("Wrong number of bytes sent: %d", bandwidth[0].BytesSent)
+	} else if bandwidth[0].BytesReceived != 3 {
+		t.Fatalf("Wrong number of bytes received: %d", bandwidth[0].BytesReceived)
+	}
+
+	if !bandwidth[1].ChainID.Equals(chain1) {
+		t.Fatalf("Wrong chain ID returned")
+	} else if bandwidth[1].BytesSent != 15 {
+		t.Fatalf("Wrong number of bytes sent: %d", bandwidth[1].BytesSent)
+	} else if bandwidth[1].BytesReceived != 7 {
+		t.Fatalf("Wrong number of bytes received: %d", bandwidth[1].BytesReceived)
+	}
+
+	if unknown := bt.UnknownBytesReceived(); unknown != 6 {
+		t.Fatalf("Wrong number of bytes received on behalf of unknown chains: %d", unknown)
+	}
+}
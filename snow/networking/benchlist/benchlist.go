@@ -0,0 +1,179 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package benchlist
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/random"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// Benchlist tracks validators that repeatedly fail to respond to requests in
+// time. Queries to a benched validator are failed immediately, rather than
+// waiting for the request to time out.
+type Benchlist interface {
+	// RegisterResponse notes that [validatorID] responded to a request on
+	// [chainID] before the request timed out.
+	RegisterResponse(validatorID ids.ShortID, chainID ids.ID)
+
+	// RegisterFailure notes that a request sent to [validatorID] on [chainID]
+	// timed out.
+	RegisterFailure(validatorID ids.ShortID, chainID ids.ID)
+
+	// Benched returns true if a query to [validatorID] on [chainID] should not
+	// be sent. A benched validator is occasionally re-probed, in which case
+	// false is returned.
+	Benched(validatorID ids.ShortID, chainID ids.ID) bool
+}
+
+// Config of a benchlist
+type Config struct {
+	// Threshold is the number of consecutive failed requests after which a
+	// validator is benched. If zero, validators are never benched.
+	Threshold int
+
+	// Duration is the amount of time a validator stays benched after its most
+	// recent failure.
+	Duration time.Duration
+
+	// ProbeProbability is the probability that a query is sent to a benched
+	// validator anyway, so that a recovered validator can leave the bench early.
+	ProbeProbability float64
+}
+
+// Valid returns nil if the config describes a valid benchlist.
+func (c Config) Valid() error {
+	switch {
+	case c.Threshold < 0:
+		return fmt.Errorf("Threshold = %d: Fails the condition that: 0 <= Threshold", c.Threshold)
+	case c.Duration < 0:
+		return fmt.Errorf("Duration = %s: Fails the condition that: 0 <= Duration", c.Duration)
+	case c.ProbeProbability < 0 || c.ProbeProbability > 1:
+		return fmt.Errorf("ProbeProbability = %f: Fails the condition that: 0 <= ProbeProbability <= 1", c.ProbeProbability)
+	default:
+		return nil
+	}
+}
+
+type validator struct {
+	failures     int
+	benched      bool
+	benchedUntil time.Time
+}
+
+type benchlist struct {
+	config Config
+	clock  timer.Clock
+
+	lock       sync.Mutex
+	validators map[[32]byte]*validator
+
+	numBenched prometheus.Gauge
+}
+
+// New returns a new benchlist. The number of benched validators is reported to
+// [registerer] under [namespace].
+func New(config Config, namespace string, registerer prometheus.Registerer) (Benchlist, error) {
+	if err := config.Valid(); err != nil {
+		return nil, err
+	}
+
+	b := &benchlist{
+		config:     config,
+		validators: make(map[[32]byte]*validator),
+		numBenched: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "benched_validators",
+				Help:      "Number of validators currently benched",
+			}),
+	}
+	return b, registerer.Register(b.numBenched)
+}
+
+// RegisterResponse implements the Benchlist interface
+func (b *benchlist) RegisterResponse(validatorID ids.ShortID, chainID ids.ID) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	key := benchKey(validatorID, chainID)
+	if vdr, exists := b.validators[key]; exists {
+		b.unbench(vdr)
+		delete(b.validators, key)
+	}
+}
+
+// RegisterFailure implements the Benchlist interface
+func (b *benchlist) RegisterFailure(validatorID ids.ShortID, chainID ids.ID) {
+	if b.config.Threshold == 0 {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	key := benchKey(validatorID, chainID)
+	vdr, exists := b.validators[key]
+	if !exists {
+		vdr = &validator{}
+		b.validators[key] = vdr
+	}
+
+	vdr.failures++
+	if vdr.failures < b.config.Threshold {
+		return
+	}
+
+	vdr.benchedUntil = b.clock.Time().Add(b.config.Duration)
+	if !vdr.benched {
+		vdr.benched = true
+		b.numBenched.Inc()
+	}
+}
+
+// Benched implements the Benchlist interface
+func (b *benchlist) Benched(validatorID ids.ShortID, chainID ids.ID) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	key := benchKey(validatorID, chainID)
+	vdr, exists := b.validators[key]
+	if !exists || !vdr.benched {
+		return false
+	}
+	if !b.clock.Time().Before(vdr.benchedUntil) {
+		// The bench has expired, so the validator gets a fresh start
+		b.unbench(vdr)
+		delete(b.validators, key)
+		return false
+	}
+	return !random.Bernoulli(b.config.ProbeProbability)
+}
+
+func (b *benchlist) unbench(vdr *validator) {
+	if vdr.benched {
+		vdr.benched = false
+		b.numBenched.Dec()
+	}
+}
+
+func benchKey(validatorID ids.ShortID, chainID ids.ID) [32]byte {
+	return hashing.ByteArraysToHash256Array(validatorID.Bytes(), chainID.Bytes())
+}
+
+type noBenchlist struct{}
+
+// NewNoBenchlist returns a benchlist that never benches a validator
+func NewNoBenchlist() Benchlist { return noBenchlist{} }
+
+func (noBenchlist) RegisterResponse(ids.ShortID, ids.ID) {}
+func (noBenchlist) RegisterFailure(ids.ShortID, ids.ID)  {}
+func (noBenchlist) Benched(ids.ShortID, ids.ID) bool     { return false }
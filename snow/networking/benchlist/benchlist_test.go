@@ -0,0 +1,105 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package benchlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestBenchlistBenchesAfterThreshold(t *testing.T) {
+	bl, err := New(Config{
+		Threshold: 3,
+		Duration:  time.Minute,
+	}, "", prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := bl.(*benchlist)
+	b.clock.Set(time.Unix(0, 0))
+
+	vdr := ids.NewShortID([20]byte{1})
+	chainID := ids.Empty
+
+	b.RegisterFailure(vdr, chainID)
+	b.RegisterFailure(vdr, chainID)
+	if b.Benched(vdr, chainID) {
+		t.Fatalf("Shouldn't have benched the validator before reaching the threshold")
+	}
+
+	b.RegisterFailure(vdr, chainID)
+	if !b.Benched(vdr, chainID) {
+		t.Fatalf("Should have benched the validator")
+	}
+	if b.Benched(vdr, ids.NewID([32]byte{1})) {
+		t.Fatalf("Shouldn't have benched the validator on a different chain")
+	}
+
+	b.clock.Set(time.Unix(0, 0).Add(time.Minute))
+	if b.Benched(vdr, chainID) {
+		t.Fatalf("Should have unbenched the validator after the duration passed")
+	}
+
+	b.RegisterFailure(vdr, chainID)
+	if b.Benched(vdr, chainID) {
+		t.Fatalf("Should have reset the validator's failures when unbenched")
+	}
+}
+
+func TestBenchlistResponseResets(t *testing.T) {
+	bl, err := New(Config{
+		Threshold: 2,
+		Duration:  time.Minute,
+	}, "", prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vdr := ids.NewShortID([20]byte{1})
+	chainID := ids.Empty
+
+	bl.RegisterFailure(vdr, chainID)
+	bl.RegisterResponse(vdr, chainID)
+	bl.RegisterFailure(vdr, chainID)
+	if bl.Benched(vdr, chainID) {
+		t.Fatalf("A response should have reset the consecutive failures")
+	}
+
+	bl.RegisterFailure(vdr, chainID)
+	if !bl.Benched(vdr, chainID) {
+		t.Fatalf("Should have benched the validator")
+	}
+
+	bl.RegisterResponse(vdr, chainID)
+	if bl.Benched(vdr, chainID) {
+		t.Fatalf("A response should have unbenched the validator")
+	}
+}
+
+func TestBenchlistProbe(t *testing.T) {
+	bl, err := New(Config{
+		Threshold:        1,
+		Duration:         time.Hour,
+		ProbeProbability: 1,
+	}, "", prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vdr := ids.NewShortID([20]byte{1})
+	bl.RegisterFailure(vdr, ids.Empty)
+	if bl.Benched(vdr, ids.Empty) {
+		t.Fatalf("Should always have re-probed the validator")
+	}
+}
+
+func TestBenchlistInvalidConfig(t *testing.T) {
+	if _, err := New(Config{ProbeProbability: 2}, "", prometheus.NewRegistry()); err == nil {
+		t.Fatalf("Should have errored due to an invalid probe probability")
+	}
+}
@@ -0,0 +1,211 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chunk
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	errNoChunks         = errors.New("container must be split into at least one chunk")
+	errBadChunkIndex    = errors.New("chunk index is out of range")
+	errTooManyChunks    = errors.New("container is split into more chunks than the smallest chunk size allows")
+	errChunkTooSmall    = errors.New("only the last chunk may be smaller than the minimum chunk size")
+	errNumChunksChanged = errors.New("number of chunks changed during the transfer")
+	errTooLarge         = errors.New("reassembled container would exceed the maximum container size")
+	errWrongID          = errors.New("reassembled container doesn't match its ID")
+	errUnrequested      = errors.New("container wasn't requested from the sender")
+	errTooManyTransfers = errors.New("sender has too many transfers in progress")
+)
+
+// Split [container] into chunks of at most [chunkSize] bytes. The returned
+// chunks reference the memory of [container].
+func Split(container []byte, chunkSize int) [][]byte {
+	chunks := make([][]byte, 0, (len(container)+chunkSize-1)/chunkSize)
+	for len(container) > chunkSize {
+		chunks = append(chunks, container[:chunkSize])
+		container = container[chunkSize:]
+	}
+	return append(chunks, container)
+}
+
+// transfer is a container that is partially received
+type transfer struct {
+	validatorID ids.ShortID
+	chunks      [][]byte
+	received    int
+	size        int
+	expiresAt   time.Time
+}
+
+// Reassembler collects the chunks of containers until they are complete.
+// Only containers that were requested from their sender are reassembled, and
+// transfers that don't make progress within the timeout are abandoned.
+type Reassembler struct {
+	lock              sync.Mutex
+	clock             timer.Clock
+	timeout           time.Duration
+	maxContainerSize  int
+	minChunkSize      int
+	maxPendingPerPeer int
+
+	// requested maps the key of each outstanding request to when it expires
+	requested map[[32]byte]time.Time
+	transfers map[[32]byte]*transfer
+
+	// pending is the number of transfers in progress from each sender
+	pending map[[20]byte]int
+}
+
+// Initialize the reassembler. A transfer is abandoned if a chunk isn't received
+// for [timeout]. Containers may be at most [maxContainerSize] bytes, every
+// chunk but the last must be at least [minChunkSize] bytes, and each sender may
+// have at most [maxPendingPerPeer] transfers in progress.
+func (r *Reassembler) Initialize(timeout time.Duration, maxContainerSize, minChunkSize, maxPendingPerPeer int) {
+	r.timeout = timeout
+	r.maxContainerSize = maxContainerSize
+	r.minChunkSize = minChunkSize
+	r.maxPendingPerPeer = maxPendingPerPeer
+	r.requested = make(map[[32]byte]time.Time)
+	r.transfers = make(map[[32]byte]*transfer)
+	r.pending = make(map[[20]byte]int)
+}
+
+// Expect marks the container with ID [containerID] as requested from
+// [validatorID] in request [requestID], so that its chunks will be accepted.
+// The request is forgotten if no chunk of it is received for the timeout.
+func (r *Reassembler) Expect(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.expire()
+	r.requested[transferKey(validatorID, chainID, requestID, containerID)] = r.clock.Time().Add(r.timeout)
+}
+
+// Add the [index]th of [numChunks] chunks of the container with ID
+// [containerID] that was sent by [validatorID] in response to request
+// [requestID]. If the container is now complete and matches its ID, it is
+// returned. Otherwise, nil is returned.
+func (r *Reassembler) Add(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, index, numChunks uint32, chunk []byte) ([]byte, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.expire()
+
+	switch {
+	case numChunks == 0:
+		return nil, errNoChunks
+	case index >= numChunks:
+		return nil, errBadChunkIndex
+	case int64(numChunks-1)*int64(r.minChunkSize) > int64(r.maxContainerSize):
+		// Every chunk except the last is at least the minimum size, so this
+		// bounds the allocation below without trusting the sender
+		return nil, errTooManyChunks
+	case index < numChunks-1 && len(chunk) < r.minChunkSize:
+		return nil, errChunkTooSmall
+	case int64(numChunks-1)*int64(len(chunk)) > int64(r.maxContainerSize):
+		return nil, errTooLarge
+	}
+
+	key := transferKey(validatorID, chainID, requestID, containerID)
+	t, exists := r.transfers[key]
+	if !exists {
+		if _, requested := r.requested[key]; !requested {
+			return nil, errUnrequested
+		}
+		if r.pending[validatorID.Key()] >= r.maxPendingPerPeer {
+			return nil, errTooManyTransfers
+		}
+		delete(r.requested, key)
+
+		t = &transfer{
+			validatorID: validatorID,
+			chunks:      make([][]byte, numChunks),
+		}
+		r.transfers[key] = t
+		r.pending[validatorID.Key()]++
+	}
+	if len(t.chunks) != int(numChunks) {
+		r.remove(key, t)
+		return nil, errNumChunksChanged
+	}
+
+	t.expiresAt = r.clock.Time().Add(r.timeout)
+	if t.chunks[index] != nil {
+		return nil, nil // Duplicated chunk
+	}
+
+	t.size += len(chunk)
+	if t.size > r.maxContainerSize {
+		r.remove(key, t)
+		return nil, errTooLarge
+	}
+	t.chunks[index] = chunk
+	t.received++
+	if t.received < len(t.chunks) {
+		return nil, nil
+	}
+
+	r.remove(key, t)
+
+	container := make([]byte, 0, t.size)
+	for _, chunk := range t.chunks {
+		container = append(container, chunk...)
+	}
+	if !containerID.Equals(ids.NewID(hashing.ComputeHash256Array(container))) {
+		return nil, errWrongID
+	}
+	return container, nil
+}
+
+// Pending returns the number of transfers that are in progress
+func (r *Reassembler) Pending() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.expire()
+	return len(r.transfers)
+}
+
+// expire removes the requests and transfers that have timed out. Assumes the
+// lock is held.
+func (r *Reassembler) expire() {
+	now := r.clock.Time()
+	for key, expiresAt := range r.requested {
+		if !now.Before(expiresAt) {
+			delete(r.requested, key)
+		}
+	}
+	for key, t := range r.transfers {
+		if !now.Before(t.expiresAt) {
+			r.remove(key, t)
+		}
+	}
+}
+
+// remove the transfer [t], which has key [key]. Assumes the lock is held.
+func (r *Reassembler) remove(key [32]byte, t *transfer) {
+	delete(r.transfers, key)
+
+	peerKey := t.validatorID.Key()
+	if r.pending[peerKey]--; r.pending[peerKey] <= 0 {
+		delete(r.pending, peerKey)
+	}
+}
+
+func transferKey(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) [32]byte {
+	p := wrappers.Packer{MaxSize: 2*hashing.HashLen + hashing.AddrLen + wrappers.IntLen}
+	p.PackFixedBytes(validatorID.Bytes())
+	p.PackFixedBytes(chainID.Bytes())
+	p.PackInt(requestID)
+	p.PackFixedBytes(containerID.Bytes())
+	return hashing.ComputeHash256Array(p.Bytes)
+}
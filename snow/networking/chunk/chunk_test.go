@@ -0,0 +1,180 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chunk
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+func TestSplit(t *testing.T) {
+	container := []byte{0, 1, 2, 3, 4, 5, 6}
+
+	chunks := Split(container, 3)
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks but got %d", len(chunks))
+	}
+	if !bytes.Equal(bytes.Join(chunks, nil), container) {
+		t.Fatalf("Chunks don't join back into the container")
+	}
+
+	if chunks := Split(nil, 3); len(chunks) != 1 || len(chunks[0]) != 0 {
+		t.Fatalf("An empty container should be sent as a single empty chunk")
+	}
+}
+
+func TestReassemble(t *testing.T) {
+	r := Reassembler{}
+	r.Initialize(time.Second, 1024, 3, 1)
+
+	container := []byte{0, 1, 2, 3, 4, 5, 6}
+	containerID := ids.NewID(hashing.ComputeHash256Array(container))
+	vdr := ids.NewShortID([20]byte{1})
+	chunks := Split(container, 3)
+	r.Expect(vdr, ids.Empty, 0, containerID)
+
+	// Deliver the chunks out of order, with a duplicate
+	for _, i := range []int{2, 0, 2} {
+		result, err := r.Add(vdr, ids.Empty, 0, containerID, uint32(i), uint32(len(chunks)), chunks[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != nil {
+			t.Fatalf("Shouldn't have completed the container early")
+		}
+	}
+
+	result, err := r.Add(vdr, ids.Empty, 0, containerID, 1, uint32(len(chunks)), chunks[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, container) {
+		t.Fatalf("Reassembled the wrong container")
+	}
+	if r.Pending() != 0 {
+		t.Fatalf("Should have cleaned up the completed transfer")
+	}
+}
+
+func TestReassembleWrongID(t *testing.T) {
+	r := Reassembler{}
+	r.Initialize(time.Second, 1024, 1, 1)
+
+	vdr := ids.NewShortID([20]byte{1})
+	r.Expect(vdr, ids.Empty, 0, ids.Empty)
+	if _, err := r.Add(vdr, ids.Empty, 0, ids.Empty, 0, 1, []byte{1}); err == nil {
+		t.Fatalf("Should have errored due to the container not matching its ID")
+	}
+}
+
+func TestReassembleTimeout(t *testing.T) {
+	r := Reassembler{}
+	r.Initialize(time.Second, 1024, 2, 1)
+	r.clock.Set(time.Unix(0, 0))
+
+	container := []byte{0, 1, 2, 3}
+	containerID := ids.NewID(hashing.ComputeHash256Array(container))
+	vdr := ids.NewShortID([20]byte{1})
+	r.Expect(vdr, ids.Empty, 0, containerID)
+
+	if _, err := r.Add(vdr, ids.Empty, 0, containerID, 0, 2, container[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if r.Pending() != 1 {
+		t.Fatalf("Should be tracking the transfer")
+	}
+
+	r.clock.Set(time.Unix(1, 0))
+	if r.Pending() != 0 {
+		t.Fatalf("Should have abandoned the stalled transfer")
+	}
+
+	if _, err := r.Add(vdr, ids.Empty, 0, containerID, 1, 2, container[2:]); err == nil {
+		t.Fatalf("Shouldn't have accepted a chunk of an abandoned transfer")
+	}
+}
+
+func TestReassembleLimits(t *testing.T) {
+	r := Reassembler{}
+	r.Initialize(time.Second, 4, 1, 1)
+
+	vdr := ids.NewShortID([20]byte{1})
+	r.Expect(vdr, ids.Empty, 0, ids.Empty)
+	r.Expect(vdr, ids.Empty, 1, ids.Empty)
+	if _, err := r.Add(vdr, ids.Empty, 0, ids.Empty, 0, 0, nil); err == nil {
+		t.Fatalf("Should have errored due to no chunks")
+	}
+	if _, err := r.Add(vdr, ids.Empty, 0, ids.Empty, 2, 2, nil); err == nil {
+		t.Fatalf("Should have errored due to an out of range index")
+	}
+	if _, err := r.Add(vdr, ids.Empty, 0, ids.Empty, 0, 3, []byte{0, 1, 2}); err == nil {
+		t.Fatalf("Should have errored due to an oversized container")
+	}
+	if _, err := r.Add(vdr, ids.Empty, 0, ids.Empty, 0, 1<<31, nil); err == nil {
+		t.Fatalf("Should have errored due to too many chunks")
+	}
+	if _, err := r.Add(vdr, ids.Empty, 0, ids.Empty, 0, 2, nil); err == nil {
+		t.Fatalf("Should have errored due to an undersized chunk")
+	}
+	if _, err := r.Add(vdr, ids.Empty, 1, ids.Empty, 0, 3, []byte{0}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Add(vdr, ids.Empty, 1, ids.Empty, 1, 2, []byte{0}); err == nil {
+		t.Fatalf("Should have errored due to the number of chunks changing")
+	}
+}
+
+func TestReassembleUnrequested(t *testing.T) {
+	r := Reassembler{}
+	r.Initialize(time.Second, 1024, 1, 1)
+
+	container := []byte{0, 1}
+	containerID := ids.NewID(hashing.ComputeHash256Array(container))
+	vdr := ids.NewShortID([20]byte{1})
+	r.Expect(vdr, ids.Empty, 0, containerID)
+
+	if _, err := r.Add(vdr, ids.Empty, 1, containerID, 0, 2, container[:1]); err == nil {
+		t.Fatalf("Should have dropped a chunk for a different request")
+	}
+	if _, err := r.Add(ids.NewShortID([20]byte{2}), ids.Empty, 0, containerID, 0, 2, container[:1]); err == nil {
+		t.Fatalf("Should have dropped a chunk from a different sender")
+	}
+	if r.Pending() != 0 {
+		t.Fatalf("Shouldn't have started any transfers")
+	}
+}
+
+func TestReassembleMaxPendingPerPeer(t *testing.T) {
+	r := Reassembler{}
+	r.Initialize(time.Second, 1024, 1, 1)
+
+	container := []byte{0, 1}
+	containerID := ids.NewID(hashing.ComputeHash256Array(container))
+	vdr := ids.NewShortID([20]byte{1})
+	r.Expect(vdr, ids.Empty, 0, containerID)
+	r.Expect(vdr, ids.Empty, 1, containerID)
+
+	if _, err := r.Add(vdr, ids.Empty, 0, containerID, 0, 2, container[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Add(vdr, ids.Empty, 1, containerID, 0, 2, container[:1]); err == nil {
+		t.Fatalf("Should have errored due to too many transfers from the sender")
+	}
+
+	result, err := r.Add(vdr, ids.Empty, 0, containerID, 1, 2, container[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, container) {
+		t.Fatalf("Reassembled the wrong container")
+	}
+
+	if _, err := r.Add(vdr, ids.Empty, 1, containerID, 0, 2, container[:1]); err != nil {
+		t.Fatalf("Should have started the transfer once the sender's other transfer completed: %s", err)
+	}
+}
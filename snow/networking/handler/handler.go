@@ -6,6 +6,8 @@ package handler
 import (
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/engine/common"
@@ -13,20 +15,36 @@ import (
 
 // Handler passes incoming messages from the network to the consensus engine
 // (Actually, it receives the incoming messages from a ChainRouter, but same difference)
+//
+// Messages needed to make progress on finality are queued separately from
+// bootstrapping, fetching and gossip messages, so that a node saturated with
+// bulk traffic still answers queries in a timely manner.
 type Handler struct {
-	msgs    chan message
-	wg      sync.WaitGroup
-	engine  common.Engine
-	msgChan <-chan common.Message
+	metrics
+
+	msgs     chan message // consensus priority messages
+	bulkMsgs chan message // bulk priority messages
+	wg       sync.WaitGroup
+	engine   common.Engine
+	msgChan  <-chan common.Message
 }
 
 // Initialize this consensus handler
-func (h *Handler) Initialize(engine common.Engine, msgChan <-chan common.Message, bufferSize int) {
+func (h *Handler) Initialize(
+	engine common.Engine,
+	msgChan <-chan common.Message,
+	bufferSize int,
+	namespace string,
+	registerer prometheus.Registerer,
+) error {
 	h.msgs = make(chan message, bufferSize)
+	h.bulkMsgs = make(chan message, bufferSize)
 	h.engine = engine
 	h.msgChan = msgChan
 
 	h.wg.Add(1)
+
+	return h.metrics.Initialize(namespace, registerer)
 }
 
 // Context of this Handler
@@ -38,8 +56,26 @@ func (h *Handler) Dispatch() {
 	defer h.wg.Done()
 
 	for {
+		// Pending consensus messages are always handled before any bulk
+		// messages
+		select {
+		case msg := <-h.msgs:
+			h.consensusQueueDepth.Dec()
+			if !h.dispatchMsg(msg) {
+				return
+			}
+			continue
+		default:
+		}
+
 		select {
 		case msg := <-h.msgs:
+			h.consensusQueueDepth.Dec()
+			if !h.dispatchMsg(msg) {
+				return
+			}
+		case msg := <-h.bulkMsgs:
+			h.bulkQueueDepth.Dec()
 			if !h.dispatchMsg(msg) {
 				return
 			}
@@ -63,6 +99,18 @@ func (h *Handler) dispatchMsg(msg message) bool {
 	ctx.Log.Verbo("Forwarding message to consensus: %s", msg)
 
 	switch msg.messageType {
+	case getStateSummaryMsg:
+		h.engine.GetStateSummary(msg.validatorID, msg.requestID)
+	case stateSummaryMsg:
+		h.engine.StateSummary(msg.validatorID, msg.requestID, msg.container)
+	case getStateSummaryFailedMsg:
+		h.engine.GetStateSummaryFailed(msg.validatorID, msg.requestID)
+	case getStateChunkMsg:
+		h.engine.GetStateChunk(msg.validatorID, msg.requestID, msg.container)
+	case stateChunkMsg:
+		h.engine.StateChunk(msg.validatorID, msg.requestID, msg.container)
+	case getStateChunkFailedMsg:
+		h.engine.GetStateChunkFailed(msg.validatorID, msg.requestID)
 	case getAcceptedFrontierMsg:
 		h.engine.GetAcceptedFrontier(msg.validatorID, msg.requestID)
 	case acceptedFrontierMsg:
@@ -98,147 +146,221 @@ func (h *Handler) dispatchMsg(msg message) bool {
 	return true
 }
 
+// GetStateSummary passes a GetStateSummary message received from the network
+// to the consensus engine.
+func (h *Handler) GetStateSummary(validatorID ids.ShortID, requestID uint32) {
+	h.push(message{
+		messageType: getStateSummaryMsg,
+		validatorID: validatorID,
+		requestID:   requestID,
+	})
+}
+
+// StateSummary passes a StateSummary message received from the network to the
+// consensus engine.
+func (h *Handler) StateSummary(validatorID ids.ShortID, requestID uint32, summary []byte) {
+	h.push(message{
+		messageType: stateSummaryMsg,
+		validatorID: validatorID,
+		requestID:   requestID,
+		container:   summary,
+	})
+}
+
+// GetStateSummaryFailed passes a GetStateSummaryFailed message received from
+// the network to the consensus engine.
+func (h *Handler) GetStateSummaryFailed(validatorID ids.ShortID, requestID uint32) {
+	h.push(message{
+		messageType: getStateSummaryFailedMsg,
+		validatorID: validatorID,
+		requestID:   requestID,
+	})
+}
+
+// GetStateChunk passes a GetStateChunk message received from the network to the
+// consensus engine.
+func (h *Handler) GetStateChunk(validatorID ids.ShortID, requestID uint32, request []byte) {
+	h.push(message{
+		messageType: getStateChunkMsg,
+		validatorID: validatorID,
+		requestID:   requestID,
+		container:   request,
+	})
+}
+
+// StateChunk passes a StateChunk message received from the network to the consensus
+// engine.
+func (h *Handler) StateChunk(validatorID ids.ShortID, requestID uint32, response []byte) {
+	h.push(message{
+		messageType: stateChunkMsg,
+		validatorID: validatorID,
+		requestID:   requestID,
+		container:   response,
+	})
+}
+
+// GetStateChunkFailed passes a GetStateChunkFailed message received from the network to
+// the consensus engine.
+func (h *Handler) GetStateChunkFailed(validatorID ids.ShortID, requestID uint32) {
+	h.push(message{
+		messageType: getStateChunkFailedMsg,
+		validatorID: validatorID,
+		requestID:   requestID,
+	})
+}
+
 // GetAcceptedFrontier passes a GetAcceptedFrontier message received from the
 // network to the consensus engine.
 func (h *Handler) GetAcceptedFrontier(validatorID ids.ShortID, requestID uint32) {
-	h.msgs <- message{
+	h.push(message{
 		messageType: getAcceptedFrontierMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
-	}
+	})
 }
 
 // AcceptedFrontier passes a AcceptedFrontier message received from the network
 // to the consensus engine.
 func (h *Handler) AcceptedFrontier(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
-	h.msgs <- message{
+	h.push(message{
 		messageType:  acceptedFrontierMsg,
 		validatorID:  validatorID,
 		requestID:    requestID,
 		containerIDs: containerIDs,
-	}
+	})
 }
 
 // GetAcceptedFrontierFailed passes a GetAcceptedFrontierFailed message received
 // from the network to the consensus engine.
 func (h *Handler) GetAcceptedFrontierFailed(validatorID ids.ShortID, requestID uint32) {
-	h.msgs <- message{
+	h.push(message{
 		messageType: getAcceptedFrontierFailedMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
-	}
+	})
 }
 
 // GetAccepted passes a GetAccepted message received from the
 // network to the consensus engine.
 func (h *Handler) GetAccepted(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
-	h.msgs <- message{
+	h.push(message{
 		messageType:  getAcceptedMsg,
 		validatorID:  validatorID,
 		requestID:    requestID,
 		containerIDs: containerIDs,
-	}
+	})
 }
 
 // Accepted passes a Accepted message received from the network to the consensus
 // engine.
 func (h *Handler) Accepted(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
-	h.msgs <- message{
+	h.push(message{
 		messageType:  acceptedMsg,
 		validatorID:  validatorID,
 		requestID:    requestID,
 		containerIDs: containerIDs,
-	}
+	})
 }
 
 // GetAcceptedFailed passes a GetAcceptedFailed message received from the
 // network to the consensus engine.
 func (h *Handler) GetAcceptedFailed(validatorID ids.ShortID, requestID uint32) {
-	h.msgs <- message{
+	h.push(message{
 		messageType: getAcceptedFailedMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
-	}
+	})
 }
 
 // Get passes a Get message received from the network to the consensus engine.
 func (h *Handler) Get(validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
-	h.msgs <- message{
+	h.push(message{
 		messageType: getMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: containerID,
-	}
+	})
 }
 
 // Put passes a Put message received from the network to the consensus engine.
 func (h *Handler) Put(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte) {
-	h.msgs <- message{
+	h.push(message{
 		messageType: putMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: containerID,
 		container:   container,
-	}
+	})
 }
 
 // GetFailed passes a GetFailed message to the consensus engine.
 func (h *Handler) GetFailed(validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
-	h.msgs <- message{
+	h.push(message{
 		messageType: getFailedMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: containerID,
-	}
+	})
 }
 
 // PushQuery passes a PushQuery message received from the network to the consensus engine.
 func (h *Handler) PushQuery(validatorID ids.ShortID, requestID uint32, blockID ids.ID, block []byte) {
-	h.msgs <- message{
+	h.push(message{
 		messageType: pushQueryMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: blockID,
 		container:   block,
-	}
+	})
 }
 
 // PullQuery passes a PullQuery message received from the network to the consensus engine.
 func (h *Handler) PullQuery(validatorID ids.ShortID, requestID uint32, blockID ids.ID) {
-	h.msgs <- message{
+	h.push(message{
 		messageType: pullQueryMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: blockID,
-	}
+	})
 }
 
 // Chits passes a Chits message received from the network to the consensus engine.
 func (h *Handler) Chits(validatorID ids.ShortID, requestID uint32, votes ids.Set) {
-	h.msgs <- message{
+	h.push(message{
 		messageType:  chitsMsg,
 		validatorID:  validatorID,
 		requestID:    requestID,
 		containerIDs: votes,
-	}
+	})
 }
 
 // QueryFailed passes a QueryFailed message received from the network to the consensus engine.
 func (h *Handler) QueryFailed(validatorID ids.ShortID, requestID uint32) {
-	h.msgs <- message{
+	h.push(message{
 		messageType: queryFailedMsg,
 		validatorID: validatorID,
 		requestID:   requestID,
-	}
+	})
 }
 
 // Shutdown shuts down the dispatcher
-func (h *Handler) Shutdown() { h.msgs <- message{messageType: shutdownMsg}; h.wg.Wait() }
+func (h *Handler) Shutdown() { h.push(message{messageType: shutdownMsg}); h.wg.Wait() }
 
 // Notify ...
 func (h *Handler) Notify(msg common.Message) {
-	h.msgs <- message{
+	h.push(message{
 		messageType:  notifyMsg,
 		notification: msg,
+	})
+}
+
+// push the message onto the queue matching its priority
+func (h *Handler) push(msg message) {
+	p := msg.messageType.priority()
+	h.queueDepth(p).Inc()
+	if p == consensusPriority {
+		h.msgs <- msg
+	} else {
+		h.bulkMsgs <- msg
 	}
 }
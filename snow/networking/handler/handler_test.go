@@ -0,0 +1,67 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handler
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+)
+
+func TestHandlerConsensusPriority(t *testing.T) {
+	engine := common.EngineTest{T: t}
+	engine.Default(true)
+
+	engine.ContextF = snow.DefaultContextTest
+
+	order := []string(nil)
+	wg := sync.WaitGroup{}
+	wg.Add(3)
+
+	engine.GetF = func(ids.ShortID, uint32, ids.ID) {
+		order = append(order, "get")
+		wg.Done()
+	}
+	engine.PullQueryF = func(ids.ShortID, uint32, ids.ID) {
+		order = append(order, "pullQuery")
+		wg.Done()
+	}
+
+	handler := Handler{}
+	handler.Initialize(&engine, nil, 2, "", prometheus.NewRegistry())
+
+	// Queue the bulk messages before the consensus message, the consensus
+	// message should still be handled first
+	handler.Get(ids.NewShortID([20]byte{1}), 0, ids.Empty)
+	handler.Get(ids.NewShortID([20]byte{1}), 1, ids.Empty)
+	handler.PullQuery(ids.NewShortID([20]byte{1}), 2, ids.Empty)
+
+	go handler.Dispatch()
+
+	wg.Wait()
+
+	if len(order) != 3 {
+		t.Fatalf("Should have handled 3 messages, handled %d", len(order))
+	} else if order[0] != "pullQuery" {
+		t.Fatalf("Should have handled the query first, handled %s", order[0])
+	}
+}
+
+func TestMsgTypePriority(t *testing.T) {
+	for _, msgType := range []msgType{pushQueryMsg, pullQueryMsg, chitsMsg, queryFailedMsg} {
+		if msgType.priority() != consensusPriority {
+			t.Fatalf("%s should have consensus priority", msgType)
+		}
+	}
+	for _, msgType := range []msgType{getAcceptedFrontierMsg, acceptedFrontierMsg, getAcceptedMsg, acceptedMsg, getMsg, putMsg, getFailedMsg} {
+		if msgType.priority() != bulkPriority {
+			t.Fatalf("%s should have bulk priority", msgType)
+		}
+	}
+}
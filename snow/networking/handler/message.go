@@ -15,6 +15,12 @@ type msgType int
 
 const (
 	nullMsg msgType = iota
+	getStateSummaryMsg
+	stateSummaryMsg
+	getStateSummaryFailedMsg
+	getStateChunkMsg
+	stateChunkMsg
+	getStateChunkFailedMsg
 	getAcceptedFrontierMsg
 	acceptedFrontierMsg
 	getAcceptedFrontierFailedMsg
@@ -32,6 +38,17 @@ const (
 	shutdownMsg
 )
 
+// priority of a message. Messages with a lower priority value are processed
+// first.
+type priority int
+
+const (
+	// consensusPriority messages are needed to make progress on finality
+	consensusPriority priority = iota
+	// bulkPriority messages are used for bootstrapping, fetching and gossip
+	bulkPriority
+)
+
 type message struct {
 	messageType  msgType
 	validatorID  ids.ShortID
@@ -55,10 +72,31 @@ func (m message) String() string {
 	return sb.String()
 }
 
+func (t msgType) priority() priority {
+	switch t {
+	case pushQueryMsg, pullQueryMsg, chitsMsg, queryFailedMsg, notifyMsg, shutdownMsg:
+		return consensusPriority
+	default:
+		return bulkPriority
+	}
+}
+
 func (t msgType) String() string {
 	switch t {
 	case nullMsg:
 		return "Null Message"
+	case getStateSummaryMsg:
+		return "Get State Summary Message"
+	case stateSummaryMsg:
+		return "State Summary Message"
+	case getStateSummaryFailedMsg:
+		return "Get State Summary Failed Message"
+	case getStateChunkMsg:
+		return "Get State Chunk Message"
+	case stateChunkMsg:
+		return "State Chunk Message"
+	case getStateChunkFailedMsg:
+		return "Get State Chunk Failed Message"
 	case getAcceptedFrontierMsg:
 		return "Get Accepted Frontier Message"
 	case acceptedFrontierMsg:
@@ -0,0 +1,44 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handler
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+type metrics struct {
+	consensusQueueDepth, bulkQueueDepth prometheus.Gauge
+}
+
+// Initialize the handler's metrics
+func (m *metrics) Initialize(namespace string, registerer prometheus.Registerer) error {
+	m.consensusQueueDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "handler_consensus_queue_depth",
+			Help:      "Number of pending consensus messages",
+		})
+	m.bulkQueueDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "handler_bulk_queue_depth",
+			Help:      "Number of pending bootstrapping and gossip messages",
+		})
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.consensusQueueDepth),
+		registerer.Register(m.bulkQueueDepth),
+	)
+	return errs.Err
+}
+
+func (m *metrics) queueDepth(p priority) prometheus.Gauge {
+	if p == consensusPriority {
+		return m.consensusQueueDepth
+	}
+	return m.bulkQueueDepth
+}
@@ -0,0 +1,122 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package latency
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/utils/sampler"
+
+	safemath "github.com/ava-labs/gecko/utils/math"
+)
+
+// biasScale is the factor that the validators' weights are scaled up by before
+// they're biased, so that small weights aren't truncated to the same value
+// however slow their validators are
+const biasScale = 1 << 16
+
+// biasedSet is a validator set that samples low latency validators slightly
+// more often than their stake alone would imply
+type biasedSet struct {
+	vdrs    validators.Set
+	tracker *Tracker
+	bias    float64
+
+	// source of the samples, or nil to draw them from the global source
+	source sampler.Source
+}
+
+// NewBiasedSet returns a validator set that samples from [vdrs] with each
+// validator's weight reduced by up to [bias], which must be in [0, 1), in
+// proportion to how slow it has been relative to the other validators.
+// Validators without any latency observations keep their full weight.
+func NewBiasedSet(vdrs validators.Set, tracker *Tracker, bias float64) validators.Set {
+	return &biasedSet{
+		vdrs:    vdrs,
+		tracker: tracker,
+		bias:    bias,
+	}
+}
+
+// Set implements the validators.Set interface
+func (s *biasedSet) Set(vdrs []validators.Validator) { s.vdrs.Set(vdrs) }
+
+// Add implements the validators.Set interface
+func (s *biasedSet) Add(vdr validators.Validator) { s.vdrs.Add(vdr) }
+
+// Remove implements the validators.Set interface
+func (s *biasedSet) Remove(vdrID ids.ShortID) { s.vdrs.Remove(vdrID) }
+
+// Contains implements the validators.Set interface
+func (s *biasedSet) Contains(vdrID ids.ShortID) bool { return s.vdrs.Contains(vdrID) }
+
+// Len implements the validators.Set interface
+func (s *biasedSet) Len() int { return s.vdrs.Len() }
+
+// List implements the validators.Set interface
+func (s *biasedSet) List() []validators.Validator { return s.vdrs.List() }
+
+func (s *biasedSet) String() string { return s.vdrs.String() }
+
+// Sample implements the validators.Set interface
+func (s *biasedSet) Sample(size int) []validators.Validator {
+	vdrs := s.vdrs.List()
+
+	latencies := make([]time.Duration, len(vdrs))
+	known := make([]bool, len(vdrs))
+	min, max := time.Duration(0), time.Duration(0)
+	for i, vdr := range vdrs {
+		latency, ok := s.tracker.Latency(vdr.ID())
+		latencies[i] = latency
+		known[i] = ok
+		if !ok {
+			continue
+		}
+		if min == 0 || latency < min {
+			min = latency
+		}
+		if latency > max {
+			max = latency
+		}
+	}
+
+	// Weights that are too large to scale up are large enough to be biased
+	// as they are
+	scale := uint64(biasScale)
+	total := uint64(0)
+	for _, vdr := range vdrs {
+		total = safemath.SaturatingAdd64(total, vdr.Weight())
+	}
+	if _, err := safemath.Mul64(total, scale); err != nil {
+		scale = 1
+	}
+
+	weighted := sampler.NewWeighted()
+	if s.source != nil {
+		weighted.SetSource(s.source)
+	}
+	for i, vdr := range vdrs {
+		weight := vdr.Weight() * scale
+		if known[i] && max > min {
+			slowness := float64(latencies[i]-min) / float64(max-min)
+			weight = uint64(float64(weight) * (1 - s.bias*slowness))
+			if weight == 0 {
+				weight = 1 // Never exclude a validator entirely
+			}
+		}
+		if err := weighted.Append(weight); err != nil {
+			// The weights are at most the validators' weights, so this can't
+			// happen unless the set changed since it was listed
+			return s.vdrs.Sample(size)
+		}
+	}
+
+	sampled := make([]validators.Validator, 0, size)
+	for _, i := range weighted.Sample(size) {
+		sampled = append(sampled, vdrs[i])
+	}
+	return sampled
+}
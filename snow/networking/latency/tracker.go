@@ -0,0 +1,110 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package latency
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+// DefaultAlpha is the weight given to each new round trip time observation
+const DefaultAlpha = 0.1
+
+// PeerLatency is the smoothed round trip time to a validator
+type PeerLatency struct {
+	ValidatorID ids.ShortID
+	Latency     time.Duration
+	// Deviation is the smoothed difference between the round trip times and
+	// the latency, so it's large when the round trip times vary
+	Deviation time.Duration
+	Samples   uint64
+}
+
+// Tracker maintains exponentially weighted moving averages of the round trip
+// time of requests to each validator, and of how much it varies
+type Tracker struct {
+	lock      sync.RWMutex
+	alpha     float64
+	latencies map[[20]byte]*PeerLatency
+}
+
+// Initialize the tracker. Each new observation is given weight [alpha], which
+// must be in (0, 1].
+func (t *Tracker) Initialize(alpha float64) {
+	t.alpha = alpha
+	t.latencies = make(map[[20]byte]*PeerLatency)
+}
+
+// Observe that a request to [validatorID] was answered after [rtt]
+func (t *Tracker) Observe(validatorID ids.ShortID, rtt time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := validatorID.Key()
+	peer, exists := t.latencies[key]
+	if !exists {
+		// As in TCP, the first observation is assumed to vary by half
+		t.latencies[key] = &PeerLatency{
+			ValidatorID: validatorID,
+			Latency:     rtt,
+			Deviation:   rtt / 2,
+			Samples:     1,
+		}
+		return
+	}
+
+	difference := rtt - peer.Latency
+	if difference < 0 {
+		difference = -difference
+	}
+	peer.Deviation = time.Duration(t.alpha*float64(difference) + (1-t.alpha)*float64(peer.Deviation))
+	peer.Latency = time.Duration(t.alpha*float64(rtt) + (1-t.alpha)*float64(peer.Latency))
+	peer.Samples++
+}
+
+// Peer returns the smoothed round trip time to [validatorID], and how much it
+// varies, if any requests to it have been answered
+func (t *Tracker) Peer(validatorID ids.ShortID) (PeerLatency, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	peer, exists := t.latencies[validatorID.Key()]
+	if !exists {
+		return PeerLatency{}, false
+	}
+	return *peer, true
+}
+
+// Latency returns the smoothed round trip time to [validatorID], if any
+// requests to it have been answered
+func (t *Tracker) Latency(validatorID ids.ShortID) (time.Duration, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	peer, exists := t.latencies[validatorID.Key()]
+	if !exists {
+		return 0, false
+	}
+	return peer.Latency, true
+}
+
+// Latencies returns the smoothed round trip time to every validator that has
+// answered a request, sorted by validator ID
+func (t *Tracker) Latencies() []PeerLatency {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	latencies := make([]PeerLatency, 0, len(t.latencies))
+	for _, peer := range t.latencies {
+		latencies = append(latencies, *peer)
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return bytes.Compare(latencies[i].ValidatorID.Bytes(), latencies[j].ValidatorID.Bytes()) == -1
+	})
+	return latencies
+}
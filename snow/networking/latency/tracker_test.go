@@ -0,0 +1,102 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/utils/sampler"
+)
+
+func TestTrackerEWMA(t *testing.T) {
+	tracker := Tracker{}
+	tracker.Initialize(0.5)
+
+	vdr := ids.NewShortID([20]byte{1})
+	if _, ok := tracker.Latency(vdr); ok {
+		t.Fatalf("Shouldn't have a latency before any observations")
+	}
+
+	tracker.Observe(vdr, 100*time.Millisecond)
+	tracker.Observe(vdr, 200*time.Millisecond)
+
+	latency, ok := tracker.Latency(vdr)
+	switch {
+	case !ok:
+		t.Fatalf("Should have a latency after observations")
+	case latency != 150*time.Millisecond:
+		t.Fatalf("Expected a latency of 150ms but got %s", latency)
+	}
+
+	latencies := tracker.Latencies()
+	if len(latencies) != 1 || latencies[0].Samples != 2 || !latencies[0].ValidatorID.Equals(vdr) {
+		t.Fatalf("Wrong latencies returned: %v", latencies)
+	}
+}
+
+func TestTrackerDeviation(t *testing.T) {
+	tracker := Tracker{}
+	tracker.Initialize(0.5)
+
+	vdr := ids.NewShortID([20]byte{1})
+	if _, ok := tracker.Peer(vdr); ok {
+		t.Fatalf("Shouldn't have a deviation before any observations")
+	}
+
+	// The first observation is assumed to vary by half
+	tracker.Observe(vdr, 100*time.Millisecond)
+	if peer, _ := tracker.Peer(vdr); peer.Deviation != 50*time.Millisecond {
+		t.Fatalf("Expected a deviation of 50ms but got %s", peer.Deviation)
+	}
+
+	// The deviation moves toward the difference from the previous latency
+	tracker.Observe(vdr, 250*time.Millisecond)
+	peer, _ := tracker.Peer(vdr)
+	switch {
+	case peer.Deviation != 100*time.Millisecond:
+		t.Fatalf("Expected a deviation of 100ms but got %s", peer.Deviation)
+	case peer.Latency != 175*time.Millisecond:
+		t.Fatalf("Expected a latency of 175ms but got %s", peer.Latency)
+	}
+}
+
+func TestBiasedSetSample(t *testing.T) {
+	tracker := &Tracker{}
+	tracker.Initialize(DefaultAlpha)
+
+	fast := validators.NewValidator(ids.NewShortID([20]byte{1}), 1)
+	slow := validators.NewValidator(ids.NewShortID([20]byte{2}), 1)
+	unknown := validators.NewValidator(ids.NewShortID([20]byte{3}), 1)
+
+	vdrs := validators.NewSet()
+	vdrs.Add(fast)
+	vdrs.Add(slow)
+	vdrs.Add(unknown)
+
+	tracker.Observe(fast.ID(), time.Millisecond)
+	tracker.Observe(slow.ID(), time.Second)
+
+	set := NewBiasedSet(vdrs, tracker, 0.5)
+	set.(*biasedSet).source = sampler.NewSource(0)
+	if sampled := set.Sample(3); len(sampled) != 3 {
+		t.Fatalf("Should have sampled every validator, got %d", len(sampled))
+	}
+	if sampled := set.Sample(4); len(sampled) != 3 {
+		t.Fatalf("Shouldn't have sampled more validators than exist, got %d", len(sampled))
+	}
+	if set.Len() != 3 {
+		t.Fatalf("Should have kept the underlying validator set")
+	}
+
+	counts := map[[20]byte]int{}
+	for i := 0; i < 1000; i++ {
+		counts[set.Sample(1)[0].ID().Key()]++
+	}
+	if counts[slow.ID().Key()] >= counts[fast.ID().Key()] {
+		t.Fatalf("Should have sampled the fast validator more often than the slow one")
+	}
+}
@@ -25,6 +25,10 @@ type Router interface {
 // ExternalRouter routes messages from the network to the
 // Handler of the consensus engine that the message is intended for
 type ExternalRouter interface {
+	GetStateSummary(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
+	StateSummary(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summary []byte)
+	GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, request []byte)
+	StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, response []byte)
 	GetAcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
 	AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set)
 	GetAccepted(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set)
@@ -38,6 +42,8 @@ type ExternalRouter interface {
 
 // InternalRouter deals with messages internal to this node
 type InternalRouter interface {
+	GetStateSummaryFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
+	GetStateChunkFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
 	GetAcceptedFrontierFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
 	GetAcceptedFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
 	GetFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID)
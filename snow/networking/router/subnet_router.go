@@ -42,14 +42,103 @@ func (sr *ChainRouter) AddChain(chain *handler.Handler) {
 }
 
 // RemoveChain removes the specified chain so that incoming
-// messages can't be routed to it
+// messages can't be routed to it, and shuts it down
 func (sr *ChainRouter) RemoveChain(chainID ids.ID) {
 	sr.lock.Lock()
-	defer sr.lock.Unlock()
+	chain, exists := sr.chains[chainID.Key()]
+	delete(sr.chains, chainID.Key())
+	sr.lock.Unlock()
+
+	if !exists {
+		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+		return
+	}
+	// The chain is shut down without holding the lock, as it may route
+	// messages while it finishes handling the messages it has queued
+	chain.Shutdown()
+}
+
+// GetStateSummary routes an incoming GetStateSummary request from the
+// validator with ID [validatorID] to the consensus engine working on the chain
+// with ID [chainID]
+func (sr *ChainRouter) GetStateSummary(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
 
 	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.Shutdown()
-		delete(sr.chains, chainID.Key())
+		chain.GetStateSummary(validatorID, requestID)
+	} else {
+		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	}
+}
+
+// StateSummary routes an incoming StateSummary request from the validator with
+// ID [validatorID] to the consensus engine working on the chain with ID
+// [chainID]
+func (sr *ChainRouter) StateSummary(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summary []byte) {
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
+
+	sr.timeouts.Cancel(validatorID, chainID, requestID)
+	if chain, exists := sr.chains[chainID.Key()]; exists {
+		chain.StateSummary(validatorID, requestID, summary)
+	} else {
+		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	}
+}
+
+// GetStateSummaryFailed routes an incoming GetStateSummaryFailed request from
+// the validator with ID [validatorID] to the consensus engine working on the
+// chain with ID [chainID]
+func (sr *ChainRouter) GetStateSummaryFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
+
+	sr.timeouts.Cancel(validatorID, chainID, requestID)
+	if chain, exists := sr.chains[chainID.Key()]; exists {
+		chain.GetStateSummaryFailed(validatorID, requestID)
+	} else {
+		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	}
+}
+
+// GetStateChunk routes an incoming GetStateChunk request from the validator with ID
+// [validatorID] to the consensus engine working on the chain with ID [chainID]
+func (sr *ChainRouter) GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, request []byte) {
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
+
+	if chain, exists := sr.chains[chainID.Key()]; exists {
+		chain.GetStateChunk(validatorID, requestID, request)
+	} else {
+		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	}
+}
+
+// StateChunk routes an incoming StateChunk request from the validator with ID
+// [validatorID] to the consensus engine working on the chain with ID [chainID]
+func (sr *ChainRouter) StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, response []byte) {
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
+
+	sr.timeouts.Cancel(validatorID, chainID, requestID)
+	if chain, exists := sr.chains[chainID.Key()]; exists {
+		chain.StateChunk(validatorID, requestID, response)
+	} else {
+		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	}
+}
+
+// GetStateChunkFailed routes an incoming GetStateChunkFailed request from the validator
+// with ID [validatorID] to the consensus engine working on the chain with ID
+// [chainID]
+func (sr *ChainRouter) GetStateChunkFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
+	sr.lock.RLock()
+	defer sr.lock.RUnlock()
+
+	sr.timeouts.Cancel(validatorID, chainID, requestID)
+	if chain, exists := sr.chains[chainID.Key()]; exists {
+		chain.GetStateChunkFailed(validatorID, requestID)
 	} else {
 		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
 	}
@@ -8,6 +8,12 @@ import "github.com/ava-labs/gecko/ids"
 // ExternalSender sends consensus messages to other validators
 // Right now this is implemented in the networking package
 type ExternalSender interface {
+	GetStateSummary(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32)
+	StateSummary(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summary []byte)
+
+	GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, request []byte)
+	StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, response []byte)
+
 	GetAcceptedFrontier(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32)
 	AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set)
 
@@ -0,0 +1,172 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sender
+
+import (
+	"sync"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+// Prioritized is an ExternalSender that queues the messages it's given and
+// passes them to another ExternalSender, always sending the pending queries and
+// chits before any bootstrapping, fetching and gossip messages. This keeps a
+// node that's serving bulk traffic answering queries in a timely manner.
+type Prioritized struct {
+	sender ExternalSender // Actually does the sending over the network
+
+	msgs      chan func() // consensus priority messages
+	bulkMsgs  chan func() // bulk priority messages
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Initialize this sender. [bufferSize] messages of each priority can be queued
+// before sending blocks.
+func (p *Prioritized) Initialize(sender ExternalSender, bufferSize int) {
+	p.sender = sender
+	p.msgs = make(chan func(), bufferSize)
+	p.bulkMsgs = make(chan func(), bufferSize)
+	p.closed = make(chan struct{})
+
+	p.wg.Add(1)
+}
+
+// Dispatch sends the queued messages until the sender is shut down
+func (p *Prioritized) Dispatch() {
+	defer p.wg.Done()
+
+	for {
+		// Pending consensus messages are always sent before any bulk messages
+		select {
+		case send := <-p.msgs:
+			send()
+			continue
+		default:
+		}
+
+		select {
+		case send := <-p.msgs:
+			send()
+		case send := <-p.bulkMsgs:
+			send()
+		case <-p.closed:
+			return
+		}
+	}
+}
+
+// Shutdown stops sending messages, dropping the ones that are still queued,
+// and waits for the message being sent to be sent
+func (p *Prioritized) Shutdown() {
+	p.closeOnce.Do(func() { close(p.closed) })
+	p.wg.Wait()
+}
+
+// GetStateSummary ...
+func (p *Prioritized) GetStateSummary(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	validatorIDs = copyShortSet(validatorIDs)
+	p.bulk(func() { p.sender.GetStateSummary(validatorIDs, chainID, requestID) })
+}
+
+// StateSummary ...
+func (p *Prioritized) StateSummary(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summary []byte) {
+	p.bulk(func() { p.sender.StateSummary(validatorID, chainID, requestID, summary) })
+}
+
+// GetStateChunk ...
+func (p *Prioritized) GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, request []byte) {
+	p.bulk(func() { p.sender.GetStateChunk(validatorID, chainID, requestID, request) })
+}
+
+// StateChunk ...
+func (p *Prioritized) StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, response []byte) {
+	p.bulk(func() { p.sender.StateChunk(validatorID, chainID, requestID, response) })
+}
+
+// GetAcceptedFrontier ...
+func (p *Prioritized) GetAcceptedFrontier(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	validatorIDs = copyShortSet(validatorIDs)
+	p.bulk(func() { p.sender.GetAcceptedFrontier(validatorIDs, chainID, requestID) })
+}
+
+// AcceptedFrontier ...
+func (p *Prioritized) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
+	containerIDs = copySet(containerIDs)
+	p.bulk(func() { p.sender.AcceptedFrontier(validatorID, chainID, requestID, containerIDs) })
+}
+
+// GetAccepted ...
+func (p *Prioritized) GetAccepted(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
+	validatorIDs = copyShortSet(validatorIDs)
+	containerIDs = copySet(containerIDs)
+	p.bulk(func() { p.sender.GetAccepted(validatorIDs, chainID, requestID, containerIDs) })
+}
+
+// Accepted ...
+func (p *Prioritized) Accepted(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
+	containerIDs = copySet(containerIDs)
+	p.bulk(func() { p.sender.Accepted(validatorID, chainID, requestID, containerIDs) })
+}
+
+// Get ...
+func (p *Prioritized) Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) {
+	p.bulk(func() { p.sender.Get(validatorID, chainID, requestID, containerID) })
+}
+
+// Put ...
+func (p *Prioritized) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) {
+	p.bulk(func() { p.sender.Put(validatorID, chainID, requestID, containerID, container) })
+}
+
+// PushQuery ...
+func (p *Prioritized) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) {
+	validatorIDs = copyShortSet(validatorIDs)
+	p.consensus(func() { p.sender.PushQuery(validatorIDs, chainID, requestID, containerID, container) })
+}
+
+// PullQuery ...
+func (p *Prioritized) PullQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerID ids.ID) {
+	validatorIDs = copyShortSet(validatorIDs)
+	p.consensus(func() { p.sender.PullQuery(validatorIDs, chainID, requestID, containerID) })
+}
+
+// Chits ...
+func (p *Prioritized) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes ids.Set) {
+	votes = copySet(votes)
+	p.consensus(func() { p.sender.Chits(validatorID, chainID, requestID, votes) })
+}
+
+// consensus queues [send] ahead of the bulk messages. It's dropped if the
+// sender has been shut down.
+func (p *Prioritized) consensus(send func()) {
+	select {
+	case p.msgs <- send:
+	case <-p.closed:
+	}
+}
+
+// bulk queues [send] behind the consensus messages. It's dropped if the sender
+// has been shut down.
+func (p *Prioritized) bulk(send func()) {
+	select {
+	case p.bulkMsgs <- send:
+	case <-p.closed:
+	}
+}
+
+// The sets are sent after the caller returns, so they're copied in case the
+// caller modifies them
+func copyShortSet(set ids.ShortSet) ids.ShortSet {
+	copied := ids.ShortSet{}
+	copied.Add(set.List()...)
+	return copied
+}
+
+func copySet(set ids.Set) ids.Set {
+	copied := ids.Set{}
+	copied.Add(set.List()...)
+	return copied
+}
@@ -29,6 +29,52 @@ func (s *Sender) Initialize(ctx *snow.Context, sender ExternalSender, router rou
 // Context of this sender
 func (s *Sender) Context() *snow.Context { return s.ctx }
 
+// GetStateSummary ...
+func (s *Sender) GetStateSummary(validatorIDs ids.ShortSet, requestID uint32) {
+	if validatorIDs.Contains(s.ctx.NodeID) {
+		validatorIDs.Remove(s.ctx.NodeID)
+		go s.router.GetStateSummary(s.ctx.NodeID, s.ctx.ChainID, requestID)
+	}
+	validatorList := validatorIDs.List()
+	for _, validatorID := range validatorList {
+		vID := validatorID
+		s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+			s.router.GetStateSummaryFailed(vID, s.ctx.ChainID, requestID)
+		})
+	}
+	s.sender.GetStateSummary(validatorIDs, s.ctx.ChainID, requestID)
+}
+
+// StateSummary ...
+func (s *Sender) StateSummary(validatorID ids.ShortID, requestID uint32, summary []byte) {
+	if validatorID.Equals(s.ctx.NodeID) {
+		go s.router.StateSummary(validatorID, s.ctx.ChainID, requestID, summary)
+		return
+	}
+	s.sender.StateSummary(validatorID, s.ctx.ChainID, requestID, summary)
+}
+
+// GetStateChunk ...
+func (s *Sender) GetStateChunk(validatorID ids.ShortID, requestID uint32, request []byte) {
+	if validatorID.Equals(s.ctx.NodeID) {
+		go s.router.GetStateChunk(validatorID, s.ctx.ChainID, requestID, request)
+		return
+	}
+	s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+		s.router.GetStateChunkFailed(validatorID, s.ctx.ChainID, requestID)
+	})
+	s.sender.GetStateChunk(validatorID, s.ctx.ChainID, requestID, request)
+}
+
+// StateChunk ...
+func (s *Sender) StateChunk(validatorID ids.ShortID, requestID uint32, response []byte) {
+	if validatorID.Equals(s.ctx.NodeID) {
+		go s.router.StateChunk(validatorID, s.ctx.ChainID, requestID, response)
+		return
+	}
+	s.sender.StateChunk(validatorID, s.ctx.ChainID, requestID, response)
+}
+
 // GetAcceptedFrontier ...
 func (s *Sender) GetAcceptedFrontier(validatorIDs ids.ShortSet, requestID uint32) {
 	if validatorIDs.Contains(s.ctx.NodeID) {
@@ -120,6 +166,13 @@ func (s *Sender) PushQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 	validatorList := validatorIDs.List() // Convert set to list for easier iteration
 	for _, validatorID := range validatorList {
 		vID := validatorID
+		// Don't wait for a benched validator to time out, fail the query
+		// immediately
+		if s.timeouts.Benched(vID, s.ctx.ChainID) {
+			validatorIDs.Remove(vID)
+			go s.router.QueryFailed(vID, s.ctx.ChainID, requestID)
+			continue
+		}
 		s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
 			s.router.QueryFailed(vID, s.ctx.ChainID, requestID)
 		})
@@ -145,6 +198,13 @@ func (s *Sender) PullQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 	validatorList := validatorIDs.List() // Convert set to list for easier iteration
 	for _, validatorID := range validatorList {
 		vID := validatorID
+		// Don't wait for a benched validator to time out, fail the query
+		// immediately
+		if s.timeouts.Benched(vID, s.ctx.ChainID) {
+			validatorIDs.Remove(vID)
+			go s.router.QueryFailed(vID, s.ctx.ChainID, requestID)
+			continue
+		}
 		s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
 			s.router.QueryFailed(vID, s.ctx.ChainID, requestID)
 		})
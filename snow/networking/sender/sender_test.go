@@ -8,9 +8,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/networking/benchlist"
 	"github.com/ava-labs/gecko/snow/networking/handler"
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/snow/networking/timeout"
@@ -19,7 +22,7 @@ import (
 
 func TestTimeout(t *testing.T) {
 	tm := timeout.Manager{}
-	tm.Initialize(time.Millisecond)
+	tm.Initialize(time.Millisecond, benchlist.NewNoBenchlist())
 	go tm.Dispatch()
 
 	router := router.ChainRouter{}
@@ -43,7 +46,7 @@ func TestTimeout(t *testing.T) {
 	}
 
 	handler := handler.Handler{}
-	handler.Initialize(&engine, nil, 1)
+	handler.Initialize(&engine, nil, 1, "", prometheus.NewRegistry())
 	go handler.Dispatch()
 
 	router.AddChain(&handler)
@@ -60,3 +63,65 @@ func TestTimeout(t *testing.T) {
 		t.Fatalf("Timeouts should have fired")
 	}
 }
+
+func TestPrioritizedSendsQueriesFirst(t *testing.T) {
+	external := &ExternalSenderTest{T: t}
+	external.Default(true)
+
+	// The first put is being sent while the rest of the messages are queued
+	sending := make(chan struct{})
+	release := make(chan struct{})
+	sent := make(chan uint32, 4)
+	external.PutF = func(_ ids.ShortID, _ ids.ID, requestID uint32, _ ids.ID, _ []byte) {
+		if requestID == 0 {
+			close(sending)
+			<-release
+		}
+		sent <- requestID
+	}
+	external.PullQueryF = func(validatorIDs ids.ShortSet, _ ids.ID, requestID uint32, _ ids.ID) {
+		if validatorIDs.Len() != 1 {
+			t.Errorf("the query should have been sent to 1 validator but was sent to %d", validatorIDs.Len())
+		}
+		sent <- requestID
+	}
+
+	p := Prioritized{}
+	p.Initialize(external, 4)
+	go p.Dispatch()
+	defer p.Shutdown()
+
+	vdrID := ids.NewShortID([20]byte{1})
+	p.Put(vdrID, ids.Empty, 0, ids.Empty, nil)
+	<-sending
+	p.Put(vdrID, ids.Empty, 1, ids.Empty, nil)
+	p.Put(vdrID, ids.Empty, 2, ids.Empty, nil)
+
+	vdrIDs := ids.ShortSet{}
+	vdrIDs.Add(vdrID)
+	p.PullQuery(vdrIDs, ids.Empty, 3, ids.Empty)
+	// The query was queued with a copy of the set
+	vdrIDs.Add(ids.NewShortID([20]byte{2}))
+	close(release)
+
+	for _, expected := range []uint32{0, 3, 1, 2} {
+		if requestID := <-sent; requestID != expected {
+			t.Fatalf("request %d should have been sent but request %d was", expected, requestID)
+		}
+	}
+}
+
+func TestPrioritizedShutdown(t *testing.T) {
+	external := &ExternalSenderTest{T: t}
+	external.Default(true)
+
+	p := Prioritized{}
+	p.Initialize(external, 1)
+	go p.Dispatch()
+	p.Shutdown()
+
+	// Messages given to the sender after it's shut down are dropped
+	p.Get(ids.NewShortID([20]byte{1}), ids.Empty, 0, ids.Empty)
+	p.Get(ids.NewShortID([20]byte{1}), ids.Empty, 1, ids.Empty)
+	p.Chits(ids.NewShortID([20]byte{1}), ids.Empty, 2, ids.Set{})
+}
@@ -14,11 +14,17 @@ type ExternalSenderTest struct {
 	T *testing.T
 	B *testing.B
 
+	CantGetStateSummary, CantStateSummary,
+	CantGetStateChunk, CantStateChunk,
 	CantGetAcceptedFrontier, CantAcceptedFrontier,
 	CantGetAccepted, CantAccepted,
 	CantGet, CantPut,
 	CantPullQuery, CantPushQuery, CantChits bool
 
+	GetStateSummaryF     func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32)
+	StateSummaryF        func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summary []byte)
+	GetStateChunkF       func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, request []byte)
+	StateChunkF          func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, response []byte)
 	GetAcceptedFrontierF func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32)
 	AcceptedFrontierF    func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set)
 	GetAcceptedF         func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerIDs ids.Set)
@@ -32,6 +38,10 @@ type ExternalSenderTest struct {
 
 // Default set the default callable value to [cant]
 func (s *ExternalSenderTest) Default(cant bool) {
+	s.CantGetStateSummary = cant
+	s.CantStateSummary = cant
+	s.CantGetStateChunk = cant
+	s.CantStateChunk = cant
 	s.CantGetAcceptedFrontier = cant
 	s.CantAcceptedFrontier = cant
 	s.CantGetAccepted = cant
@@ -43,6 +53,58 @@ func (s *ExternalSenderTest) Default(cant bool) {
 	s.CantChits = cant
 }
 
+// GetStateSummary calls GetStateSummaryF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *ExternalSenderTest) GetStateSummary(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	if s.GetStateSummaryF != nil {
+		s.GetStateSummaryF(validatorIDs, chainID, requestID)
+	} else if s.CantGetStateSummary && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetStateSummary")
+	} else if s.CantGetStateSummary && s.B != nil {
+		s.B.Fatalf("Unexpectedly called GetStateSummary")
+	}
+}
+
+// StateSummary calls StateSummaryF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *ExternalSenderTest) StateSummary(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summary []byte) {
+	if s.StateSummaryF != nil {
+		s.StateSummaryF(validatorID, chainID, requestID, summary)
+	} else if s.CantStateSummary && s.T != nil {
+		s.T.Fatalf("Unexpectedly called StateSummary")
+	} else if s.CantStateSummary && s.B != nil {
+		s.B.Fatalf("Unexpectedly called StateSummary")
+	}
+}
+
+// GetStateChunk calls GetStateChunkF if it was initialized. If it wasn't initialized and
+// this function shouldn't be called and testing was initialized, then testing
+// will fail.
+func (s *ExternalSenderTest) GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, request []byte) {
+	if s.GetStateChunkF != nil {
+		s.GetStateChunkF(validatorID, chainID, requestID, request)
+	} else if s.CantGetStateChunk && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetStateChunk")
+	} else if s.CantGetStateChunk && s.B != nil {
+		s.B.Fatalf("Unexpectedly called GetStateChunk")
+	}
+}
+
+// StateChunk calls StateChunkF if it was initialized. If it wasn't initialized and this
+// function shouldn't be called and testing was initialized, then testing will
+// fail.
+func (s *ExternalSenderTest) StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, response []byte) {
+	if s.StateChunkF != nil {
+		s.StateChunkF(validatorID, chainID, requestID, response)
+	} else if s.CantStateChunk && s.T != nil {
+		s.T.Fatalf("Unexpectedly called StateChunk")
+	} else if s.CantStateChunk && s.B != nil {
+		s.B.Fatalf("Unexpectedly called StateChunk")
+	}
+}
+
 // GetAcceptedFrontier calls GetAcceptedFrontierF if it was initialized. If it
 // wasn't initialized and this function shouldn't be called and testing was
 // initialized, then testing will fail.
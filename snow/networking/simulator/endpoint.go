@@ -0,0 +1,129 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package simulator
+
+import (
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/networking/router"
+)
+
+// endpoint sends messages through the simulated network on behalf of a node
+type endpoint struct {
+	network *Network
+	nodeID  ids.ShortID
+}
+
+// GetStateSummary implements the ExternalSender interface
+func (e *endpoint) GetStateSummary(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	e.network.send(e.nodeID, validatorIDs.List(), func(r router.ExternalRouter) {
+		r.GetStateSummary(e.nodeID, chainID, requestID)
+	})
+}
+
+// StateSummary implements the ExternalSender interface
+func (e *endpoint) StateSummary(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summary []byte) {
+	summary = copyBytes(summary)
+	e.network.send(e.nodeID, []ids.ShortID{validatorID}, func(r router.ExternalRouter) {
+		r.StateSummary(e.nodeID, chainID, requestID, summary)
+	})
+}
+
+// GetStateChunk implements the ExternalSender interface
+func (e *endpoint) GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, request []byte) {
+	request = copyBytes(request)
+	e.network.send(e.nodeID, []ids.ShortID{validatorID}, func(r router.ExternalRouter) {
+		r.GetStateChunk(e.nodeID, chainID, requestID, request)
+	})
+}
+
+// StateChunk implements the ExternalSender interface
+func (e *endpoint) StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, response []byte) {
+	response = copyBytes(response)
+	e.network.send(e.nodeID, []ids.ShortID{validatorID}, func(r router.ExternalRouter) {
+		r.StateChunk(e.nodeID, chainID, requestID, response)
+	})
+}
+
+// GetAcceptedFrontier implements the ExternalSender interface
+func (e *endpoint) GetAcceptedFrontier(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	e.network.send(e.nodeID, validatorIDs.List(), func(r router.ExternalRouter) {
+		r.GetAcceptedFrontier(e.nodeID, chainID, requestID)
+	})
+}
+
+// AcceptedFrontier implements the ExternalSender interface
+func (e *endpoint) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
+	containerIDs = copySet(containerIDs)
+	e.network.send(e.nodeID, []ids.ShortID{validatorID}, func(r router.ExternalRouter) {
+		r.AcceptedFrontier(e.nodeID, chainID, requestID, containerIDs)
+	})
+}
+
+// GetAccepted implements the ExternalSender interface
+func (e *endpoint) GetAccepted(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
+	containerIDs = copySet(containerIDs)
+	e.network.send(e.nodeID, validatorIDs.List(), func(r router.ExternalRouter) {
+		r.GetAccepted(e.nodeID, chainID, requestID, copySet(containerIDs))
+	})
+}
+
+// Accepted implements the ExternalSender interface
+func (e *endpoint) Accepted(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
+	containerIDs = copySet(containerIDs)
+	e.network.send(e.nodeID, []ids.ShortID{validatorID}, func(r router.ExternalRouter) {
+		r.Accepted(e.nodeID, chainID, requestID, containerIDs)
+	})
+}
+
+// Get implements the ExternalSender interface
+func (e *endpoint) Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) {
+	e.network.send(e.nodeID, []ids.ShortID{validatorID}, func(r router.ExternalRouter) {
+		r.Get(e.nodeID, chainID, requestID, containerID)
+	})
+}
+
+// Put implements the ExternalSender interface
+func (e *endpoint) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) {
+	container = copyBytes(container)
+	e.network.send(e.nodeID, []ids.ShortID{validatorID}, func(r router.ExternalRouter) {
+		r.Put(e.nodeID, chainID, requestID, containerID, container)
+	})
+}
+
+// PushQuery implements the ExternalSender interface
+func (e *endpoint) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) {
+	container = copyBytes(container)
+	e.network.send(e.nodeID, validatorIDs.List(), func(r router.ExternalRouter) {
+		r.PushQuery(e.nodeID, chainID, requestID, containerID, copyBytes(container))
+	})
+}
+
+// PullQuery implements the ExternalSender interface
+func (e *endpoint) PullQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerID ids.ID) {
+	e.network.send(e.nodeID, validatorIDs.List(), func(r router.ExternalRouter) {
+		r.PullQuery(e.nodeID, chainID, requestID, containerID)
+	})
+}
+
+// Chits implements the ExternalSender interface
+func (e *endpoint) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes ids.Set) {
+	votes = copySet(votes)
+	e.network.send(e.nodeID, []ids.ShortID{validatorID}, func(r router.ExternalRouter) {
+		r.Chits(e.nodeID, chainID, requestID, votes)
+	})
+}
+
+// copySet ensures that the recipient of a message never shares memory with the
+// sender, as would be the case if the message went over the wire
+func copySet(s ids.Set) ids.Set {
+	newSet := ids.Set{}
+	newSet.Add(s.List()...)
+	return newSet
+}
+
+func copyBytes(b []byte) []byte {
+	newBytes := make([]byte, len(b))
+	copy(newBytes, b)
+	return newBytes
+}
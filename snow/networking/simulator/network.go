@@ -0,0 +1,187 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package simulator
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/networking/router"
+	"github.com/ava-labs/gecko/snow/networking/sender"
+	"github.com/ava-labs/gecko/utils/random"
+)
+
+var (
+	errDuplicateNode     = errors.New("node is already part of the network")
+	errInvalidDropRate   = errors.New("drop rate must be in the range [0, 1]")
+	errNegativeLatency   = errors.New("latency and jitter must be non-negative")
+	errUnknownPartitions = errors.New("partition contains a node that isn't part of the network")
+)
+
+// Config describes the links between the nodes of a simulated network
+type Config struct {
+	// Latency is the minimum delay before a message is delivered
+	Latency time.Duration
+	// Jitter is the maximum additional random delay added to each message
+	Jitter time.Duration
+	// DropRate is the probability that a message is silently dropped
+	DropRate float64
+}
+
+// Valid returns nil if the config describes a possible network
+func (c Config) Valid() error {
+	switch {
+	case c.Latency < 0 || c.Jitter < 0:
+		return errNegativeLatency
+	case c.DropRate < 0 || c.DropRate > 1:
+		return errInvalidDropRate
+	default:
+		return nil
+	}
+}
+
+// Network connects multiple in-process nodes to each other. Messages sent by a
+// node are handed to the router of the receiving node after the configured
+// delay, unless they are dropped or the nodes are partitioned from each other.
+type Network struct {
+	lock      sync.RWMutex
+	config    Config
+	routers   map[[20]byte]router.ExternalRouter
+	partition map[[20]byte]int
+	closed    bool
+
+	// pending tracks the messages that haven't been delivered yet
+	pending sync.WaitGroup
+}
+
+// Initialize the network with the provided link configuration
+func (n *Network) Initialize(config Config) error {
+	if err := config.Valid(); err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.config = config
+	n.routers = make(map[[20]byte]router.ExternalRouter)
+	n.partition = make(map[[20]byte]int)
+	return nil
+}
+
+// SetConfig changes the link configuration of the network. Messages that are
+// already in flight are unaffected.
+func (n *Network) SetConfig(config Config) error {
+	if err := config.Valid(); err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.config = config
+	return nil
+}
+
+// AddNode connects [nodeID] to the network. Messages sent to [nodeID] will be
+// passed to [router]. The returned sender sends messages on behalf of
+// [nodeID].
+func (n *Network) AddNode(nodeID ids.ShortID, router router.ExternalRouter) (sender.ExternalSender, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	key := nodeID.Key()
+	if _, exists := n.routers[key]; exists {
+		return nil, errDuplicateNode
+	}
+	n.routers[key] = router
+	return &endpoint{
+		network: n,
+		nodeID:  nodeID,
+	}, nil
+}
+
+// Partition splits the network into the provided groups. Nodes are only able
+// to communicate with nodes in the same group. Nodes that aren't mentioned are
+// placed into their own shared group.
+func (n *Network) Partition(groups ...ids.ShortSet) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	partition := make(map[[20]byte]int)
+	for i, group := range groups {
+		for _, nodeID := range group.List() {
+			key := nodeID.Key()
+			if _, exists := n.routers[key]; !exists {
+				return errUnknownPartitions
+			}
+			partition[key] = i + 1
+		}
+	}
+	n.partition = partition
+	return nil
+}
+
+// Heal removes all partitions from the network
+func (n *Network) Heal() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.partition = make(map[[20]byte]int)
+}
+
+// Wait blocks until all the messages that are currently in flight have either
+// been delivered or dropped
+func (n *Network) Wait() { n.pending.Wait() }
+
+// Close stops all future message delivery. Messages that are in flight will be
+// dropped.
+func (n *Network) Close() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.closed = true
+}
+
+// send a message from [from] to each of [to] by applying [deliver] to the
+// router of each recipient
+func (n *Network) send(from ids.ShortID, to []ids.ShortID, deliver func(router.ExternalRouter)) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if n.closed {
+		return
+	}
+
+	fromKey := from.Key()
+	for _, nodeID := range to {
+		toKey := nodeID.Key()
+		r, exists := n.routers[toKey]
+		if !exists ||
+			n.partition[fromKey] != n.partition[toKey] ||
+			random.Bernoulli(n.config.DropRate) {
+			continue
+		}
+
+		delay := n.config.Latency
+		if n.config.Jitter > 0 {
+			delay += time.Duration(random.Rand(0, int(n.config.Jitter)+1))
+		}
+
+		n.pending.Add(1)
+		time.AfterFunc(delay, func() {
+			defer n.pending.Done()
+
+			n.lock.RLock()
+			closed := n.closed
+			n.lock.RUnlock()
+
+			if !closed {
+				deliver(r)
+			}
+		})
+	}
+}
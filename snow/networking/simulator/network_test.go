@@ -0,0 +1,148 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package simulator
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/networking/router"
+)
+
+// pullQueryRouter records the pull queries it receives
+type pullQueryRouter struct {
+	router.ExternalRouter
+
+	lock    sync.Mutex
+	senders ids.ShortSet
+}
+
+func (r *pullQueryRouter) PullQuery(validatorID ids.ShortID, _ ids.ID, _ uint32, _ ids.ID) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.senders.Add(validatorID)
+}
+
+func (r *pullQueryRouter) receivedFrom(validatorID ids.ShortID) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.senders.Contains(validatorID)
+}
+
+func TestNetworkDelivers(t *testing.T) {
+	net := Network{}
+	if err := net.Initialize(Config{
+		Latency: time.Millisecond,
+		Jitter:  time.Millisecond,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	vdr0 := ids.NewShortID([20]byte{0})
+	vdr1 := ids.NewShortID([20]byte{1})
+	router0 := &pullQueryRouter{}
+	router1 := &pullQueryRouter{}
+
+	sender0, err := net.AddNode(vdr0, router0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.AddNode(vdr1, router1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.AddNode(vdr1, router1); err == nil {
+		t.Fatalf("Should have errored due to a duplicated node")
+	}
+
+	vdrs := ids.ShortSet{}
+	vdrs.Add(vdr0, vdr1)
+	sender0.PullQuery(vdrs, ids.Empty, 0, ids.Empty)
+	net.Wait()
+
+	if !router0.receivedFrom(vdr0) {
+		t.Fatalf("Should have delivered the query to the sender")
+	}
+	if !router1.receivedFrom(vdr0) {
+		t.Fatalf("Should have delivered the query to the other node")
+	}
+}
+
+func TestNetworkPartition(t *testing.T) {
+	net := Network{}
+	if err := net.Initialize(Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	vdr0 := ids.NewShortID([20]byte{0})
+	vdr1 := ids.NewShortID([20]byte{1})
+	router1 := &pullQueryRouter{}
+
+	sender0, err := net.AddNode(vdr0, &pullQueryRouter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.AddNode(vdr1, router1); err != nil {
+		t.Fatal(err)
+	}
+
+	group := ids.ShortSet{}
+	group.Add(vdr0)
+	if err := net.Partition(group); err != nil {
+		t.Fatal(err)
+	}
+
+	vdrs := ids.ShortSet{}
+	vdrs.Add(vdr1)
+	sender0.PullQuery(vdrs, ids.Empty, 0, ids.Empty)
+	net.Wait()
+
+	if router1.receivedFrom(vdr0) {
+		t.Fatalf("Shouldn't have delivered a message across the partition")
+	}
+
+	net.Heal()
+	sender0.PullQuery(vdrs, ids.Empty, 0, ids.Empty)
+	net.Wait()
+
+	if !router1.receivedFrom(vdr0) {
+		t.Fatalf("Should have delivered the message after healing the partition")
+	}
+}
+
+func TestNetworkDrops(t *testing.T) {
+	net := Network{}
+	if err := net.Initialize(Config{DropRate: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	vdr0 := ids.NewShortID([20]byte{0})
+	router0 := &pullQueryRouter{}
+	sender0, err := net.AddNode(vdr0, router0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vdrs := ids.ShortSet{}
+	vdrs.Add(vdr0)
+	sender0.PullQuery(vdrs, ids.Empty, 0, ids.Empty)
+	net.Wait()
+
+	if router0.receivedFrom(vdr0) {
+		t.Fatalf("Should have dropped the message")
+	}
+}
+
+func TestNetworkInvalidConfig(t *testing.T) {
+	net := Network{}
+	if err := net.Initialize(Config{DropRate: 2}); err == nil {
+		t.Fatalf("Should have errored due to an invalid drop rate")
+	}
+	if err := net.Initialize(Config{Latency: -1}); err == nil {
+		t.Fatalf("Should have errored due to a negative latency")
+	}
+}
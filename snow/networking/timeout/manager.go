@@ -4,16 +4,95 @@
 package timeout
 
 import (
+	"errors"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/networking/benchlist"
+	"github.com/ava-labs/gecko/snow/networking/latency"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/utils/wrappers"
 )
 
+var (
+	errNoPeerSamples            = errors.New("peer timeouts need at least one response time")
+	errNegativePeerDeviation    = errors.New("peer timeout deviation multiplier must be non-negative")
+	errInvalidPeerTimeoutBounds = errors.New("peer timeout bounds must satisfy 0 < minimum <= maximum")
+)
+
+// maxBackoffs is the most times the timeout of a validator is doubled, which
+// is more than enough to reach any maximum timeout from any minimum timeout
+const maxBackoffs = 32
+
+// PeerTimeoutConfig determines how the time a request to a validator is given
+// follows the response times of that validator. As in TCP, a request is given
+// the validator's smoothed latency plus a multiple of how much it varies, so
+// validators on slow but steady links aren't timed out, while requests to
+// validators that usually respond quickly are retried sooner. Also as in TCP,
+// the timeout of a validator is doubled every time a request to it times out,
+// until it answers a request again.
+type PeerTimeoutConfig struct {
+	// If false, every request is given the timeout of the adaptive timeout
+	// manager, which follows the response times of every validator
+	Enabled bool
+
+	// Number of answered requests to a validator before its requests are
+	// given a timeout based on its own response times
+	MinimumSamples uint64
+
+	// Multiple of the deviation of a validator's response times that its
+	// requests are given on top of its latency
+	DeviationMultiplier float64
+
+	// MinimumTimeout and MaximumTimeout bound the time a request to a
+	// validator is given
+	MinimumTimeout, MaximumTimeout time.Duration
+}
+
+// Valid returns nil if the config can be used to time out requests
+func (c PeerTimeoutConfig) Valid() error {
+	switch {
+	case !c.Enabled:
+		return nil
+	case c.MinimumSamples == 0:
+		return errNoPeerSamples
+	case c.DeviationMultiplier < 0:
+		return errNegativePeerDeviation
+	case c.MinimumTimeout <= 0 || c.MaximumTimeout < c.MinimumTimeout:
+		return errInvalidPeerTimeoutBounds
+	default:
+		return nil
+	}
+}
+
 // Manager registers and fires timeouts for the snow API.
-type Manager struct{ tm timer.TimeoutManager }
+type Manager struct {
+	tm        timer.AdaptiveTimeoutManager
+	benchlist benchlist.Benchlist
+	latencies latency.Tracker
+	clock     timer.Clock
+	metrics   metrics
+
+	// peerConfig is set before requests are registered, so it isn't guarded
+	peerConfig PeerTimeoutConfig
+
+	// pending tracks when each pending request was registered, and whether
+	// it was given a timeout based on its validator's response times.
+	// backoffs is the number of times in a row that such requests to each
+	// validator have timed out.
+	lock     sync.Mutex
+	pending  map[[32]byte]request
+	backoffs map[[20]byte]uint
+}
+
+type request struct {
+	sentAt      time.Time
+	peerTimeout bool
+}
 
 // Initialize this timeout manager.
 //
@@ -22,7 +101,58 @@ type Manager struct{ tm timer.TimeoutManager }
 //
 // [duration] is the amount of time to allow for external requests
 // before the request times out.
-func (m *Manager) Initialize(duration time.Duration) { m.tm.Initialize(duration) }
+//
+// [benchlist] is informed of every request that times out and every request
+// that is answered in time.
+func (m *Manager) Initialize(duration time.Duration, benchlist benchlist.Benchlist) {
+	// A fixed timeout is an adaptive timeout that can't adapt
+	err := m.InitializeAdaptive(timer.AdaptiveTimeoutConfig{
+		InitialTimeout: duration,
+		MinimumTimeout: duration,
+		MaximumTimeout: duration,
+		Percentile:     1,
+		Multiplier:     1,
+		WindowSize:     1,
+	}, benchlist)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// InitializeAdaptive initializes this timeout manager so that the amount of
+// time allowed for external requests follows the response times of recent
+// requests, as described by [config].
+func (m *Manager) InitializeAdaptive(config timer.AdaptiveTimeoutConfig, benchlist benchlist.Benchlist) error {
+	if err := m.tm.Initialize(config); err != nil {
+		return err
+	}
+	m.benchlist = benchlist
+	m.latencies.Initialize(latency.DefaultAlpha)
+	m.metrics.Initialize("")
+	m.pending = make(map[[32]byte]request)
+	m.backoffs = make(map[[20]byte]uint)
+	return nil
+}
+
+// InitializePeerTimeouts gives requests to validators that have answered
+// enough requests a timeout based on their own response times, as described
+// by [config], rather than on the response times of every validator. Must be
+// called before any requests are registered.
+func (m *Manager) InitializePeerTimeouts(config PeerTimeoutConfig) error {
+	if err := config.Valid(); err != nil {
+		return err
+	}
+	m.peerConfig = config
+	return nil
+}
+
+// InitializeMetrics registers the metrics of the requests this manager times
+// out with [registerer] under [namespace]. Must be called before any requests
+// are registered.
+func (m *Manager) InitializeMetrics(namespace string, registerer prometheus.Registerer) error {
+	m.metrics.Initialize(namespace)
+	return m.metrics.Register(registerer)
+}
 
 // Dispatch ...
 func (m *Manager) Dispatch() { m.tm.Dispatch() }
@@ -30,12 +160,103 @@ func (m *Manager) Dispatch() { m.tm.Dispatch() }
 // Register request to time out unless Manager.Cancel is called
 // before the timeout duration passes, with the same request parameters.
 func (m *Manager) Register(validatorID ids.ShortID, chainID ids.ID, requestID uint32, timeout func()) {
-	m.tm.Put(createRequestID(validatorID, chainID, requestID), timeout)
+	id := createRequestID(validatorID, chainID, requestID)
+	duration, peerTimeout := m.timeoutFor(validatorID)
+
+	m.lock.Lock()
+	m.pending[id.Key()] = request{
+		sentAt:      m.clock.Time(),
+		peerTimeout: peerTimeout,
+	}
+	m.lock.Unlock()
+
+	m.metrics.durations.Observe(float64(duration) / float64(time.Millisecond))
+	m.tm.PutWithDuration(id, duration, func() {
+		m.lock.Lock()
+		delete(m.pending, id.Key())
+		if peerTimeout && m.backoffs[validatorID.Key()] < maxBackoffs {
+			m.backoffs[validatorID.Key()]++
+		}
+		m.lock.Unlock()
+
+		m.metrics.timeouts.Inc()
+		if peerTimeout {
+			m.metrics.peerTimeouts.Inc()
+		}
+		m.benchlist.RegisterFailure(validatorID, chainID)
+		timeout()
+	})
 }
 
 // Cancel request timeout with the specified parameters.
 func (m *Manager) Cancel(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
-	m.tm.Remove(createRequestID(validatorID, chainID, requestID))
+	id := createRequestID(validatorID, chainID, requestID)
+	if !m.tm.Remove(id) {
+		return
+	}
+	m.benchlist.RegisterResponse(validatorID, chainID)
+
+	m.lock.Lock()
+	pending, exists := m.pending[id.Key()]
+	delete(m.pending, id.Key())
+	delete(m.backoffs, validatorID.Key())
+	m.lock.Unlock()
+
+	if exists {
+		rtt := m.clock.Time().Sub(pending.sentAt)
+		m.latencies.Observe(validatorID, rtt)
+		m.metrics.latency.Observe(float64(rtt) / float64(time.Millisecond))
+	}
+}
+
+// TimeoutDuration returns the amount of time newly registered requests are
+// given before they time out, unless they're given a timeout based on the
+// response times of the validator they're sent to
+func (m *Manager) TimeoutDuration() time.Duration { return m.tm.TimeoutDuration() }
+
+// PeerTimeoutDuration returns the amount of time a newly registered request to
+// [validatorID] is given before it times out
+func (m *Manager) PeerTimeoutDuration(validatorID ids.ShortID) time.Duration {
+	duration, _ := m.timeoutFor(validatorID)
+	return duration
+}
+
+// timeoutFor returns the amount of time a request to [validatorID] is given,
+// and true if it's based on the validator's own response times
+func (m *Manager) timeoutFor(validatorID ids.ShortID) (time.Duration, bool) {
+	if !m.peerConfig.Enabled {
+		return m.tm.TimeoutDuration(), false
+	}
+	peer, ok := m.latencies.Peer(validatorID)
+	if !ok || peer.Samples < m.peerConfig.MinimumSamples {
+		return m.tm.TimeoutDuration(), false
+	}
+
+	m.lock.Lock()
+	backoffs := m.backoffs[validatorID.Key()]
+	m.lock.Unlock()
+
+	duration := peer.Latency + time.Duration(m.peerConfig.DeviationMultiplier*float64(peer.Deviation))
+	for i := uint(0); i < backoffs && duration < m.peerConfig.MaximumTimeout; i++ {
+		duration *= 2
+	}
+	switch {
+	case duration < m.peerConfig.MinimumTimeout:
+		duration = m.peerConfig.MinimumTimeout
+	case duration > m.peerConfig.MaximumTimeout:
+		duration = m.peerConfig.MaximumTimeout
+	}
+	return duration, true
+}
+
+// Latencies returns the tracker of the round trip times of answered requests
+func (m *Manager) Latencies() *latency.Tracker { return &m.latencies }
+
+// Benched returns true if queries to [validatorID] on [chainID] should be
+// failed immediately, rather than sent, because the validator has repeatedly
+// failed to respond in time.
+func (m *Manager) Benched(validatorID ids.ShortID, chainID ids.ID) bool {
+	return m.benchlist.Benched(validatorID, chainID)
 }
 
 func createRequestID(validatorID ids.ShortID, chainID ids.ID, requestID uint32) ids.ID {
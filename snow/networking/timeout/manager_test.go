@@ -9,11 +9,12 @@ import (
 	"time"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/networking/benchlist"
 )
 
 func TestManagerFire(t *testing.T) {
 	manager := Manager{}
-	manager.Initialize(time.Millisecond)
+	manager.Initialize(time.Millisecond, benchlist.NewNoBenchlist())
 	go manager.Dispatch()
 
 	wg := sync.WaitGroup{}
@@ -26,7 +27,7 @@ func TestManagerFire(t *testing.T) {
 
 func TestManagerCancel(t *testing.T) {
 	manager := Manager{}
-	manager.Initialize(50 * time.Millisecond)
+	manager.Initialize(50*time.Millisecond, benchlist.NewNoBenchlist())
 	go manager.Dispatch()
 
 	wg := sync.WaitGroup{}
@@ -46,3 +47,144 @@ func TestManagerCancel(t *testing.T) {
 		t.Fatalf("Should have cancelled the function")
 	}
 }
+
+func TestManagerTracksLatency(t *testing.T) {
+	manager := Manager{}
+	manager.Initialize(time.Hour, benchlist.NewNoBenchlist())
+	manager.clock.Set(time.Unix(0, 0))
+
+	vdr := ids.NewShortID([20]byte{1})
+	manager.Register(vdr, ids.Empty, 0, func() {})
+
+	manager.clock.Set(time.Unix(0, 0).Add(time.Second))
+	manager.Cancel(vdr, ids.Empty, 0)
+
+	latency, ok := manager.Latencies().Latency(vdr)
+	if !ok {
+		t.Fatalf("Should have recorded the round trip time")
+	}
+	if latency != time.Second {
+		t.Fatalf("Expected a latency of %s but got %s", time.Second, latency)
+	}
+
+	// Cancelling a request that isn't pending shouldn't be observed
+	manager.Cancel(vdr, ids.Empty, 0)
+	if latencies := manager.Latencies().Latencies(); len(latencies) != 1 || latencies[0].Samples != 1 {
+		t.Fatalf("Should have only observed the answered request")
+	}
+}
+
+func TestManagerPeerTimeouts(t *testing.T) {
+	manager := Manager{}
+	manager.Initialize(time.Hour, benchlist.NewNoBenchlist())
+	if err := manager.InitializePeerTimeouts(PeerTimeoutConfig{
+		Enabled:             true,
+		MinimumSamples:      2,
+		DeviationMultiplier: 4,
+		MinimumTimeout:      time.Second,
+		MaximumTimeout:      time.Minute,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(0, 0)
+	manager.clock.Set(start)
+
+	vdr := ids.NewShortID([20]byte{1})
+	answer := func(requestID uint32, rtt time.Duration) {
+		manager.clock.Set(start)
+		manager.Register(vdr, ids.Empty, requestID, func() {})
+		manager.clock.Set(start.Add(rtt))
+		manager.Cancel(vdr, ids.Empty, requestID)
+	}
+
+	answer(0, 10*time.Second)
+	if duration := manager.PeerTimeoutDuration(vdr); duration != manager.TimeoutDuration() {
+		t.Fatalf("Shouldn't have used the validator's response times before enough samples")
+	}
+
+	// The latency is 10s and the deviation 4.5s, so requests are given 28s
+	answer(1, 10*time.Second)
+	if duration := manager.PeerTimeoutDuration(vdr); duration != 28*time.Second {
+		t.Fatalf("Expected a timeout of %s but got %s", 28*time.Second, duration)
+	}
+
+	// Validators that haven't answered are given the adaptive timeout
+	other := ids.NewShortID([20]byte{2})
+	if duration := manager.PeerTimeoutDuration(other); duration != manager.TimeoutDuration() {
+		t.Fatalf("Validators without response times should be given the adaptive timeout")
+	}
+}
+
+func TestManagerPeerTimeoutBackoff(t *testing.T) {
+	manager := Manager{}
+	manager.Initialize(time.Hour, benchlist.NewNoBenchlist())
+	if err := manager.InitializePeerTimeouts(PeerTimeoutConfig{
+		Enabled:             true,
+		MinimumSamples:      1,
+		DeviationMultiplier: 4,
+		MinimumTimeout:      time.Millisecond,
+		MaximumTimeout:      20 * time.Millisecond,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go manager.Dispatch()
+
+	vdr := ids.NewShortID([20]byte{1})
+	requestID := uint32(0)
+	answer := func() {
+		requestID++
+		manager.Register(vdr, ids.Empty, requestID, func() {})
+		manager.clock.Set(manager.clock.Time().Add(time.Millisecond))
+		manager.Cancel(vdr, ids.Empty, requestID)
+	}
+	timeOut := func() {
+		requestID++
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		manager.Register(vdr, ids.Empty, requestID, wg.Done)
+		wg.Wait()
+	}
+
+	// The latency is 1ms and the deviation 0.5ms, so requests are given 3ms
+	manager.clock.Set(time.Unix(0, 0))
+	answer()
+	if duration := manager.PeerTimeoutDuration(vdr); duration != 3*time.Millisecond {
+		t.Fatalf("Expected a timeout of %s but got %s", 3*time.Millisecond, duration)
+	}
+
+	// Every request that times out doubles the timeout, up to the maximum
+	timeOut()
+	if duration := manager.PeerTimeoutDuration(vdr); duration != 6*time.Millisecond {
+		t.Fatalf("Expected a timeout of %s but got %s", 6*time.Millisecond, duration)
+	}
+	timeOut()
+	if duration := manager.PeerTimeoutDuration(vdr); duration != 12*time.Millisecond {
+		t.Fatalf("Expected a timeout of %s but got %s", 12*time.Millisecond, duration)
+	}
+	timeOut()
+	if duration := manager.PeerTimeoutDuration(vdr); duration != 20*time.Millisecond {
+		t.Fatalf("Expected a timeout of %s but got %s", 20*time.Millisecond, duration)
+	}
+
+	// An answered request stops the backoff
+	answer()
+	if duration := manager.PeerTimeoutDuration(vdr); duration > 3*time.Millisecond {
+		t.Fatalf("Should have stopped backing off but got a timeout of %s", duration)
+	}
+}
+
+func TestPeerTimeoutConfigValid(t *testing.T) {
+	invalid := []PeerTimeoutConfig{
+		{Enabled: true, DeviationMultiplier: 4, MinimumTimeout: time.Second, MaximumTimeout: time.Minute},
+		{Enabled: true, MinimumSamples: 1, DeviationMultiplier: -1, MinimumTimeout: time.Second, MaximumTimeout: time.Minute},
+		{Enabled: true, MinimumSamples: 1, DeviationMultiplier: 4, MinimumTimeout: time.Minute, MaximumTimeout: time.Second},
+	}
+	for _, config := range invalid {
+		if err := config.Valid(); err == nil {
+			t.Fatalf("Config %+v should have been invalid", config)
+		}
+	}
+	if err := (PeerTimeoutConfig{}).Valid(); err != nil {
+		t.Fatalf("A disabled config should have been valid but failed with %s", err)
+	}
+}
@@ -0,0 +1,56 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timeout
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// metrics of the requests a timeout manager registers. They're created when
+// the manager is initialized, so they can be updated whether or not they're
+// registered.
+type metrics struct {
+	latency      prometheus.Histogram
+	timeouts     prometheus.Counter
+	peerTimeouts prometheus.Counter
+	durations    prometheus.Histogram
+}
+
+func (m *metrics) Initialize(namespace string) {
+	m.latency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_latency",
+		Help:      "Time, in milliseconds, requests to validators took to be answered",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 16),
+	})
+	m.timeouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "request_timeouts",
+		Help:      "Number of requests to validators that timed out",
+	})
+	m.peerTimeouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "request_peer_timeouts",
+		Help:      "Number of requests to validators that timed out after the timeout based on that validator's response times",
+	})
+	m.durations = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_timeout_duration",
+		Help:      "Time, in milliseconds, requests to validators were given before they time out",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 16),
+	})
+}
+
+func (m *metrics) Register(registerer prometheus.Registerer) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.latency),
+		registerer.Register(m.timeouts),
+		registerer.Register(m.peerTimeouts),
+		registerer.Register(m.durations),
+	)
+	return errs.Err
+}
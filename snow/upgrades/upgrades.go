@@ -0,0 +1,215 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package upgrades
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	// WarningPeriod is how long before a time activated upgrade activates that
+	// warnings start being logged about it
+	WarningPeriod = 24 * time.Hour
+
+	// WarningHeight is how many heights before a height activated upgrade
+	// activates that warnings start being logged about it
+	WarningHeight = 1000
+)
+
+// Activation is when an upgrade activates. An upgrade activates either at a
+// time or at a height.
+type Activation struct {
+	// Time the upgrade activates at, or the zero time if it activates at a
+	// height
+	Time time.Time
+
+	// Height the upgrade activates at, if it doesn't activate at a time
+	Height uint64
+}
+
+func (a Activation) String() string {
+	if !a.Time.IsZero() {
+		return a.Time.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("height %d", a.Height)
+}
+
+// ParseSchedule parses a schedule of the form "name=value,name=value", where
+// each value is either an RFC3339 time or a height
+func ParseSchedule(schedule string) (map[string]Activation, error) {
+	activations := make(map[string]Activation)
+	if schedule == "" {
+		return activations, nil
+	}
+	for _, entry := range strings.Split(schedule, ",") {
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) != 2 || fields[0] == "" {
+			return nil, fmt.Errorf("upgrade %q should be of the form name=value", entry)
+		}
+		name, value := fields[0], fields[1]
+		if _, exists := activations[name]; exists {
+			return nil, fmt.Errorf("upgrade %s is scheduled more than once", name)
+		}
+		if height, err := strconv.ParseUint(value, 10, 64); err == nil {
+			activations[name] = Activation{Height: height}
+		} else if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+			activations[name] = Activation{Time: timestamp}
+		} else {
+			return nil, fmt.Errorf("upgrade %s should activate at a height or an RFC3339 time but activates at %q", name, value)
+		}
+	}
+	return activations, nil
+}
+
+// Manager keeps track of the upgrades, rule changes that activate at a
+// scheduled time or height, that this node recognizes
+type Manager struct {
+	lock sync.Mutex
+	log  logging.Logger
+
+	// Key: Name of an upgrade
+	// Value: When the upgrade is scheduled to activate
+	schedule map[string]Activation
+
+	// Key: Name of an upgrade
+	// Value: The upgrade
+	upgrades map[string]*Upgrade
+}
+
+// Initialize the manager with the upgrades in [schedule]
+func (m *Manager) Initialize(log logging.Logger, schedule map[string]Activation) {
+	m.log = log
+	m.schedule = schedule
+	m.upgrades = make(map[string]*Upgrade)
+}
+
+// Register returns the upgrade named [name], registering it if it hasn't been
+// registered already. If the upgrade isn't in the schedule, it never
+// activates.
+func (m *Manager) Register(name, description string) *Upgrade {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if upgrade, exists := m.upgrades[name]; exists {
+		return upgrade
+	}
+
+	activation, scheduled := m.schedule[name]
+	upgrade := &Upgrade{
+		log:         m.log,
+		name:        name,
+		description: description,
+		activation:  activation,
+		scheduled:   scheduled,
+	}
+	m.upgrades[name] = upgrade
+
+	if scheduled {
+		m.log.Info("upgrade %s (%s) is scheduled to activate at %s", name, description, activation)
+	} else {
+		m.log.Debug("upgrade %s (%s) isn't scheduled", name, description)
+	}
+	return upgrade
+}
+
+// Status describes an upgrade
+type Status struct {
+	Name        string
+	Description string
+	Activation  Activation
+	// True if the upgrade is scheduled to activate
+	Scheduled bool
+	// True if a chain running on this node registered the upgrade
+	Recognized bool
+	// True if the upgrade has been observed to be active
+	Active bool
+}
+
+// Statuses returns the status of every upgrade that was either registered or
+// scheduled, sorted by name
+func (m *Manager) Statuses() []Status {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	statuses := []Status{}
+	for name, upgrade := range m.upgrades {
+		upgrade.lock.Lock()
+		statuses = append(statuses, Status{
+			Name:        name,
+			Description: upgrade.description,
+			Activation:  upgrade.activation,
+			Scheduled:   upgrade.scheduled,
+			Recognized:  true,
+			Active:      upgrade.active,
+		})
+		upgrade.lock.Unlock()
+	}
+	for name, activation := range m.schedule {
+		if _, exists := m.upgrades[name]; !exists {
+			statuses = append(statuses, Status{
+				Name:       name,
+				Activation: activation,
+				Scheduled:  true,
+			})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Upgrade is a rule change that activates at a scheduled time or height
+type Upgrade struct {
+	lock sync.Mutex
+	log  logging.Logger
+
+	name, description string
+	activation        Activation
+	scheduled         bool
+
+	// True once a warning was logged that the upgrade will soon be active
+	warned bool
+	// True once the upgrade has been observed to be active
+	active bool
+}
+
+// Name returns the name of this upgrade
+func (u *Upgrade) Name() string { return u.name }
+
+// Active returns true if the upgrade is active for a container with timestamp
+// [timestamp] and height [height]. A container that doesn't have a height
+// should pass 0, in which case height activated upgrades are never active.
+func (u *Upgrade) Active(timestamp time.Time, height uint64) bool {
+	if !u.scheduled {
+		return false
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	var active, imminent bool
+	if !u.activation.Time.IsZero() {
+		active = !timestamp.Before(u.activation.Time)
+		imminent = !timestamp.Add(WarningPeriod).Before(u.activation.Time)
+	} else {
+		active = height != 0 && height >= u.activation.Height
+		imminent = height != 0 && height+WarningHeight >= u.activation.Height
+	}
+
+	switch {
+	case active && !u.active:
+		u.active = true
+		u.log.Info("upgrade %s (%s) is now active", u.name, u.description)
+	case !active && imminent && !u.warned:
+		u.warned = true
+		u.log.Warn("upgrade %s (%s) will activate at %s", u.name, u.description, u.activation)
+	}
+	return active
+}
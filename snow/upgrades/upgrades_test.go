@@ -0,0 +1,92 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package upgrades
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestParseSchedule(t *testing.T) {
+	schedule, err := ParseSchedule("fees=2020-09-01T00:00:00Z,limits=100000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedule) != 2 {
+		t.Fatalf("should have parsed 2 upgrades but parsed %d", len(schedule))
+	}
+	if activation := schedule["fees"]; !activation.Time.Equal(time.Date(2020, time.September, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("wrong activation of fees: %s", activation)
+	}
+	if activation := schedule["limits"]; !activation.Time.IsZero() || activation.Height != 100000 {
+		t.Fatalf("wrong activation of limits: %s", activation)
+	}
+
+	for _, invalid := range []string{"fees", "=5", "fees=soon", "fees=5,fees=6"} {
+		if _, err := ParseSchedule(invalid); err == nil {
+			t.Fatalf("should have failed to parse %q", invalid)
+		}
+	}
+}
+
+func TestUpgradeActive(t *testing.T) {
+	activationTime := time.Unix(1000, 0)
+
+	m := Manager{}
+	m.Initialize(logging.NoLog{}, map[string]Activation{
+		"time":   {Time: activationTime},
+		"height": {Height: 10},
+	})
+
+	timeUpgrade := m.Register("time", "activates at a time")
+	if timeUpgrade.Active(activationTime.Add(-time.Second), 0) {
+		t.Fatalf("upgrade shouldn't be active before its activation time")
+	}
+	if !timeUpgrade.Active(activationTime, 0) {
+		t.Fatalf("upgrade should be active at its activation time")
+	}
+
+	heightUpgrade := m.Register("height", "activates at a height")
+	if heightUpgrade.Active(time.Time{}, 9) {
+		t.Fatalf("upgrade shouldn't be active before its activation height")
+	}
+	if !heightUpgrade.Active(time.Time{}, 10) {
+		t.Fatalf("upgrade should be active at its activation height")
+	}
+
+	if m.Register("height", "activates at a height") != heightUpgrade {
+		t.Fatalf("registering an upgrade again should return the registered upgrade")
+	}
+
+	unscheduled := m.Register("unscheduled", "never activates")
+	if unscheduled.Active(activationTime, 1000) {
+		t.Fatalf("unscheduled upgrades should never be active")
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	m := Manager{}
+	m.Initialize(logging.NoLog{}, map[string]Activation{
+		"a": {Height: 10},
+		"b": {Height: 20},
+	})
+	m.Register("b", "recognized").Active(time.Time{}, 20)
+	m.Register("c", "unscheduled")
+
+	statuses := m.Statuses()
+	if len(statuses) != 3 {
+		t.Fatalf("should have 3 upgrades but has %d", len(statuses))
+	}
+	if s := statuses[0]; s.Name != "a" || !s.Scheduled || s.Recognized || s.Active {
+		t.Fatalf("wrong status: %+v", s)
+	}
+	if s := statuses[1]; s.Name != "b" || !s.Scheduled || !s.Recognized || !s.Active {
+		t.Fatalf("wrong status: %+v", s)
+	}
+	if s := statuses[2]; s.Name != "c" || s.Scheduled || !s.Recognized || s.Active {
+		t.Fatalf("wrong status: %+v", s)
+	}
+}
@@ -0,0 +1,14 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validators
+
+import (
+	"github.com/ava-labs/gecko/ids"
+)
+
+// Connector is notified when peers connect to and disconnect from this node
+type Connector interface {
+	Connected(validatorID ids.ShortID)
+	Disconnected(validatorID ids.ShortID)
+}
@@ -10,7 +10,7 @@ import (
 
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/utils/formatting"
-	"github.com/ava-labs/gecko/utils/random"
+	"github.com/ava-labs/gecko/utils/sampler"
 )
 
 // Set of validators that can be sampled
@@ -45,18 +45,23 @@ type Set interface {
 }
 
 // NewSet returns a new, empty set of validators.
-func NewSet() Set { return &set{vdrMap: make(map[[20]byte]int)} }
+func NewSet() Set {
+	return &set{
+		vdrMap:  make(map[[20]byte]int),
+		sampler: sampler.NewWeighted(),
+	}
+}
 
 // set of validators. Validator function results are cached. Therefore, to
 // update a validators weight, one should ensure to call add with the updated
-// validator. Sample will run in O(NumValidators) time. All other functions run
-// in O(1) time.
+// validator. Sample will run in O(size * log(NumValidators)) time, Add and
+// Remove in O(log(NumValidators)) time. All other functions run in O(1) time.
 // set implements Set
 type set struct {
 	lock     sync.Mutex
 	vdrMap   map[[20]byte]int
 	vdrSlice []Validator
-	sampler  random.Weighted
+	sampler  *sampler.Weighted
 }
 
 // Set implements the Set interface.
@@ -70,7 +75,7 @@ func (s *set) Set(vdrs []Validator) {
 func (s *set) set(vdrs []Validator) {
 	s.vdrMap = make(map[[20]byte]int, len(vdrs))
 	s.vdrSlice = s.vdrSlice[:0]
-	s.sampler.Weights = s.sampler.Weights[:0]
+	s.sampler = sampler.NewWeighted()
 
 	for _, vdr := range vdrs {
 		s.add(vdr)
@@ -96,10 +101,13 @@ func (s *set) add(vdr Validator) {
 		return // This validator would never be sampled anyway
 	}
 
+	if err := s.sampler.Append(w); err != nil {
+		// The total stake would have to be more than the largest uint64
+		panic(err)
+	}
 	i := len(s.vdrSlice)
 	s.vdrMap[vdrID.Key()] = i
 	s.vdrSlice = append(s.vdrSlice, vdr)
-	s.sampler.Weights = append(s.sampler.Weights, w)
 }
 
 // Remove implements the Set interface.
@@ -126,12 +134,16 @@ func (s *set) remove(vdrID ids.ShortID) {
 	// Move e -> i
 	s.vdrMap[eKey] = i
 	s.vdrSlice[i] = eVdr
-	s.sampler.Weights[i] = s.sampler.Weights[e]
+	eWeight := s.sampler.Weight(e)
+	s.sampler.RemoveLast()
+	if i != e {
+		// The total weight only decreases, so this can't overflow
+		_ = s.sampler.Update(i, eWeight)
+	}
 
 	// Remove i
 	delete(s.vdrMap, iKey)
 	s.vdrSlice = s.vdrSlice[:e]
-	s.sampler.Weights = s.sampler.Weights[:e]
 }
 
 // Contains implements the Set interface.
@@ -181,10 +193,7 @@ func (s *set) Sample(size int) []Validator {
 
 func (s *set) sample(size int) []Validator {
 	list := make([]Validator, size)[:0]
-
-	s.sampler.Replace() // Must replace, otherwise changes won't be reflected
-	for ; size > 0 && s.sampler.CanSample(); size-- {
-		i := s.sampler.Sample()
+	for _, i := range s.sampler.Sample(size) {
 		list = append(list, s.vdrSlice[i])
 	}
 	return list
@@ -203,7 +212,7 @@ func (s *set) string() string {
 	sb.WriteString(fmt.Sprintf("Validator Set: (Size = %d)", len(s.vdrSlice)))
 	format := fmt.Sprintf("\n    Validator[%s]: %%33s, %%d", formatting.IntFormat(len(s.vdrSlice)-1))
 	for i, vdr := range s.vdrSlice {
-		sb.WriteString(fmt.Sprintf(format, i, vdr.ID(), s.sampler.Weights[i]))
+		sb.WriteString(fmt.Sprintf(format, i, vdr.ID(), s.sampler.Weight(i)))
 	}
 
 	return sb.String()
@@ -0,0 +1,237 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	errUnknownFormat = errors.New("config files should end in .json, .toml, .yaml or .yml")
+)
+
+// Apply sets the flags of [fs] that weren't given on the command line from
+// environment variables and from the config file given by the flag named
+// [fileFlag], if it's set. Must be called after [fs] is parsed.
+//
+// A flag is set from, in order of precedence:
+//  1. The command line, as in --http-port=9650
+//  2. The environment variable named [envPrefix] followed by the flag's name
+//     in upper case with dashes replaced by underscores, as in
+//     GECKO_HTTP_PORT=9650
+//  3. The config file, as in {"http-port": 9650}. Keys of nested objects are
+//     joined to their parents' with dashes, so {"http": {"port": 9650}} sets
+//     the same flag. Lists are joined with commas.
+//  4. The flag's default
+func Apply(fs *flag.FlagSet, fileFlag, envPrefix string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	errs := []string(nil)
+	fs.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] {
+			return
+		}
+		value, ok := os.LookupEnv(EnvName(envPrefix, f.Name))
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid value %q of %s: %s", value, EnvName(envPrefix, f.Name), err))
+			return
+		}
+		set[f.Name] = true
+	})
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	file := fs.Lookup(fileFlag)
+	if file == nil || file.Value.String() == "" {
+		return nil
+	}
+	values, err := ReadFile(file.Value.String())
+	if err != nil {
+		return err
+	}
+
+	// Flags are set in a fixed order so that errors are reported consistently
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch {
+		case name == fileFlag:
+			return fmt.Errorf("config file %s can't give another config file", file.Value)
+		case fs.Lookup(name) == nil:
+			return fmt.Errorf("config file %s has unknown option %q", file.Value, name)
+		case set[name]:
+			continue
+		}
+		if err := fs.Set(name, values[name]); err != nil {
+			return fmt.Errorf("config file %s has invalid value %q of %s: %w", file.Value, values[name], name, err)
+		}
+	}
+	return nil
+}
+
+// Snapshot returns the value of each flag of [fs], by name, as it would be
+// after parsing [args] and calling Apply, without changing [fs]. It lets the
+// options be reread while the node is running. Values are returned as they
+// were given, so callers must check that they're valid.
+func Snapshot(fs *flag.FlagSet, args []string, fileFlag, envPrefix string) (map[string]string, error) {
+	snapshot := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	snapshot.SetOutput(ioutil.Discard)
+	fs.VisitAll(func(f *flag.Flag) {
+		value := &stringValue{value: f.DefValue}
+		if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); ok {
+			value.isBool = boolFlag.IsBoolFlag()
+		}
+		snapshot.Var(value, f.Name, f.Usage)
+	})
+	if err := snapshot.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := Apply(snapshot, fileFlag, envPrefix); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	snapshot.VisitAll(func(f *flag.Flag) { values[f.Name] = f.Value.String() })
+	return values, nil
+}
+
+// stringValue is a flag.Value that holds any flag's value as it was given
+type stringValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *stringValue) String() string     { return v.value }
+func (v *stringValue) Set(s string) error { v.value = s; return nil }
+func (v *stringValue) IsBoolFlag() bool   { return v.isBool }
+
+// EnvName returns the name of the environment variable that sets the flag
+// [name]
+func EnvName(envPrefix, name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ReadFile returns the options in the JSON, TOML or YAML config file at
+// [path], by flag name, as they'd be given on the command line
+func ReadFile(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read config file: %w", err)
+	}
+
+	var options interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		// Numbers are kept as they were written, so that large integers
+		// aren't rounded
+		decoder.UseNumber()
+		err = decoder.Decode(&options)
+	case ".toml":
+		var table map[string]interface{}
+		_, err = toml.Decode(string(b), &table)
+		options = table
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &options)
+	default:
+		return nil, fmt.Errorf("couldn't read config file %s: %w", path, errUnknownFormat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse config file %s: %w", path, err)
+	}
+	if options == nil {
+		return map[string]string{}, nil
+	}
+
+	values := make(map[string]string)
+	if err := flatten("", options, values); err != nil {
+		return nil, fmt.Errorf("couldn't parse config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flatten adds the options in [value], whose name is [name], to [values]
+func flatten(name string, value interface{}, values map[string]string) error {
+	prefix := name
+	if prefix != "" {
+		prefix += "-"
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if err := flatten(prefix+key, child, values); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[interface{}]interface{}:
+		for key, child := range value {
+			if err := flatten(prefix+fmt.Sprint(key), child, values); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if name == "" {
+		return errors.New("options should be an object")
+	}
+	if _, exists := values[name]; exists {
+		return fmt.Errorf("option %q is given more than once", name)
+	}
+	formatted, err := format(value)
+	if err != nil {
+		return fmt.Errorf("option %q %w", name, err)
+	}
+	values[name] = formatted
+	return nil
+}
+
+// format returns [value] as it'd be given on the command line
+func format(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case []interface{}:
+		elements := make([]string, len(value))
+		for i, element := range value {
+			formatted, err := format(element)
+			if err != nil {
+				return "", err
+			}
+			if _, isList := element.([]interface{}); isList {
+				return "", errors.New("can't have lists of lists")
+			}
+			elements[i] = formatted
+		}
+		return strings.Join(elements, ","), nil
+	case map[string]interface{}, map[interface{}]interface{}, []map[string]interface{}:
+		return "", errors.New("can't have objects in lists")
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
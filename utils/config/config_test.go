@@ -0,0 +1,215 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newFlagSet returns a flag set with options like the node's
+func newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.String("config-file", "", "")
+	fs.Uint("http-port", 9650, "")
+	fs.Bool("api-admin-enabled", true, "")
+	fs.String("log-level", "info", "")
+	fs.String("bootstrap-ips", "", "")
+	fs.Uint64("ava-tx-fee", 0, "")
+	return fs
+}
+
+// writeFile writes [contents] to a file named [name] in [dir]
+func writeFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct{ name, contents string }{
+		{
+			name: "config.json",
+			contents: `{
+				"http": {"port": 9000},
+				"api-admin-enabled": false,
+				"log-level": "debug",
+				"bootstrap-ips": ["127.0.0.1:9651", "127.0.0.1:9653"],
+				"ava-tx-fee": 18446744073709551615
+			}`,
+		},
+		{
+			name: "config.yaml",
+			contents: `
+http:
+  port: 9000
+api-admin-enabled: false
+log-level: debug
+bootstrap-ips:
+  - 127.0.0.1:9651
+  - 127.0.0.1:9653
+ava-tx-fee: 18446744073709551615
+`,
+		},
+		{
+			name: "config.toml",
+			contents: `
+# Options may be grouped in tables
+api-admin-enabled = false
+'log-level' = "debug"
+bootstrap-ips = [
+	"127.0.0.1:9651", # The first beacon
+	"127.0.0.1:9653",
+]
+# TOML integers are signed 64 bit integers, so larger values are strings
+ava-tx-fee = "18446744073709551615"
+
+[http]
+port = 9000
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeFile(t, dir, test.name, test.contents)
+			fs := newFlagSet()
+			if err := fs.Parse([]string{"--config-file", path, "--log-level=warn"}); err != nil {
+				t.Fatal(err)
+			}
+			os.Setenv("TEST_HTTP_PORT", "9100")
+			defer os.Unsetenv("TEST_HTTP_PORT")
+
+			if err := Apply(fs, "config-file", "TEST_"); err != nil {
+				t.Fatal(err)
+			}
+			for name, expected := range map[string]string{
+				// The command line takes precedence over the file
+				"log-level": "warn",
+				// Environment variables take precedence over the file
+				"http-port":         "9100",
+				"api-admin-enabled": "false",
+				"bootstrap-ips":     "127.0.0.1:9651,127.0.0.1:9653",
+				"ava-tx-fee":        "18446744073709551615",
+			} {
+				if value := fs.Lookup(name).Value.String(); value != expected {
+					t.Fatalf("expected %s to be %s but it's %s", name, expected, value)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyWithoutFile(t *testing.T) {
+	fs := newFlagSet()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TEST_API_ADMIN_ENABLED", "false")
+	defer os.Unsetenv("TEST_API_ADMIN_ENABLED")
+
+	if err := Apply(fs, "config-file", "TEST_"); err != nil {
+		t.Fatal(err)
+	}
+	if value := fs.Lookup("api-admin-enabled").Value.String(); value != "false" {
+		t.Fatalf("expected the environment variable to disable the API but it's %s", value)
+	}
+	if value := fs.Lookup("http-port").Value.String(); value != "9650" {
+		t.Fatalf("expected the default port but got %s", value)
+	}
+
+	os.Setenv("TEST_HTTP_PORT", "port")
+	defer os.Unsetenv("TEST_HTTP_PORT")
+	if err := Apply(newFlagSet(), "config-file", "TEST_"); err == nil {
+		t.Fatal("should have failed to set an invalid port")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "config.json", `{"log-level": "debug", "http-port": 9000}`)
+	args := []string{"--config-file", path, "--api-admin-enabled", "--http-port=9100"}
+	fs := newFlagSet()
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(fs, "config-file", "TEST_"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file is changed after the flags were parsed
+	writeFile(t, dir, "config.json", `{"log-level": "warn", "bootstrap-ips": ["127.0.0.1:9651"]}`)
+	values, err := Snapshot(fs, args, "config-file", "TEST_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{
+		"log-level":         "warn",
+		"bootstrap-ips":     "127.0.0.1:9651",
+		"http-port":         "9100",
+		"api-admin-enabled": "true",
+		"ava-tx-fee":        "0",
+	} {
+		if values[name] != expected {
+			t.Fatalf("expected %s to be %s but it's %s", name, expected, values[name])
+		}
+	}
+	if value := fs.Lookup("log-level").Value.String(); value != "debug" {
+		t.Fatalf("taking a snapshot shouldn't have changed the flags but log-level is %s", value)
+	}
+
+	writeFile(t, dir, "config.json", `{"http-host": "localhost"}`)
+	if _, err := Snapshot(fs, args, "config-file", "TEST_"); err == nil {
+		t.Fatal("should have failed to read an unknown option")
+	}
+}
+
+func TestApplyInvalidFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct{ name, contents string }{
+		{name: "unknown.json", contents: `{"http-host": "localhost"}`},
+		{name: "invalid.json", contents: `{"http-port": "port"}`},
+		{name: "recursive.json", contents: `{"config-file": "other.json"}`},
+		{name: "duplicate.json", contents: `{"http-port": 1, "http": {"port": 2}}`},
+		{name: "nested.json", contents: `{"bootstrap-ips": [["127.0.0.1:9651"]]}`},
+		{name: "list.json", contents: `["http-port"]`},
+		{name: "malformed.json", contents: `{"http-port": [9650`},
+		{name: "malformed.yaml", contents: "http-port: [9650"},
+		{name: "malformed.toml", contents: "http-port = [9650"},
+		{name: "redefined.toml", contents: "http-port = 1\nhttp-port = 2"},
+		{name: "tables.toml", contents: "[[http]]\nport = 9650"},
+		{name: "config.txt", contents: `http-port = 9650`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := newFlagSet()
+			if err := fs.Parse([]string{"--config-file", writeFile(t, dir, test.name, test.contents)}); err != nil {
+				t.Fatal(err)
+			}
+			if err := Apply(fs, "config-file", "TEST_"); err == nil {
+				t.Fatal("should have failed to apply the config file")
+			}
+		})
+	}
+}
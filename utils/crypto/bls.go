@@ -0,0 +1,264 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+
+	blst "github.com/supranational/blst/bindings/go"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+const (
+	// BLSSKLen is the number of bytes in a BLS private key
+	BLSSKLen = 32
+
+	// BLSPKLen is the number of bytes in a BLS public key, which is a
+	// compressed point of G1
+	BLSPKLen = 48
+
+	// BLSSigLen is the number of bytes in a BLS signature, which is a
+	// compressed point of G2
+	BLSSigLen = 96
+)
+
+var (
+	// Messages are signed, and public keys are proven to be possessed, with the
+	// proof of possession ciphersuite of the IETF's BLS signature draft. The
+	// domains differ, so a proof of possession can't be passed off as a
+	// signature.
+	blsSignatureDomain  = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+	blsPossessionDomain = []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+	errInvalidPoint         = errors.New("bytes aren't a compressed point of the group")
+	errInfinityPoint        = errors.New("point is the point at infinity")
+	errInvalidBLSPrivateKey = errors.New("BLS private key isn't in [1, r)")
+	errNoSignatures         = errors.New("no signatures provided")
+	errInvalidAggregateSig  = errors.New("signatures aggregate to an invalid signature")
+)
+
+// FactoryBLS creates keys of the BLS signature scheme over BLS12-381, as
+// implemented by blst.
+//
+// Public keys are in G1 and signatures are in G2. Signatures of the same
+// message can be aggregated into one signature that verifies against the
+// aggregate of the signers' public keys. Because a key could be chosen to
+// cancel out the others in an aggregate, keys should only be aggregated after
+// their proofs of possession have been verified.
+type FactoryBLS struct{}
+
+// NewPrivateKey implements the Factory interface
+func (*FactoryBLS) NewPrivateKey() (PrivateKey, error) {
+	ikm := make([]byte, BLSSKLen)
+	if _, err := rand.Read(ikm); err != nil {
+		return nil, err
+	}
+	return &PrivateKeyBLS{sk: blst.KeyGen(ikm)}, nil
+}
+
+// ToPublicKey implements the Factory interface
+func (*FactoryBLS) ToPublicKey(b []byte) (PublicKey, error) {
+	if len(b) != BLSPKLen {
+		return nil, errWrongPublicKeySize
+	}
+	pk := new(blst.P1Affine).Uncompress(b)
+	switch {
+	case pk == nil:
+		return nil, errInvalidPoint
+	case pk.Equals(new(blst.P1Affine)):
+		return nil, errInfinityPoint
+	case !pk.KeyValidate():
+		return nil, errInvalidPoint
+	}
+	return &PublicKeyBLS{pk: pk, bytes: b}, nil
+}
+
+// ToPrivateKey implements the Factory interface
+func (*FactoryBLS) ToPrivateKey(b []byte) (PrivateKey, error) {
+	if len(b) != BLSSKLen {
+		return nil, errWrongPrivateKeySize
+	}
+	sk := new(blst.SecretKey).Deserialize(b)
+	if sk == nil || !sk.Valid() {
+		return nil, errInvalidBLSPrivateKey
+	}
+	return &PrivateKeyBLS{sk: sk, bytes: b}, nil
+}
+
+// PublicKeyBLS ...
+type PublicKeyBLS struct {
+	pk    *blst.P1Affine
+	addr  ids.ShortID
+	bytes []byte
+}
+
+// Verify implements the PublicKey interface
+func (k *PublicKeyBLS) Verify(msg, sig []byte) bool {
+	return k.verify(blsSignatureDomain, msg, sig)
+}
+
+// VerifyHash implements the PublicKey interface
+func (k *PublicKeyBLS) VerifyHash(hash, sig []byte) bool {
+	return k.Verify(hash, sig)
+}
+
+// VerifyProofOfPossession returns true if [proof] shows that the signer holds
+// the private key of this public key
+func (k *PublicKeyBLS) VerifyProofOfPossession(proof []byte) bool {
+	return k.verify(blsPossessionDomain, k.Bytes(), proof)
+}
+
+func (k *PublicKeyBLS) verify(domain, msg, sig []byte) bool {
+	sigPoint, err := blsSignature(sig)
+	if err != nil {
+		return false
+	}
+	return sigPoint.Verify(true, k.pk, false, msg, domain)
+}
+
+// Address implements the PublicKey interface
+func (k *PublicKeyBLS) Address() ids.ShortID {
+	if k.addr.IsZero() {
+		addr, err := ids.ToShortID(hashing.PubkeyBytesToAddress(k.Bytes()))
+		if err != nil {
+			panic(err)
+		}
+		k.addr = addr
+	}
+	return k.addr
+}
+
+// Bytes implements the PublicKey interface
+func (k *PublicKeyBLS) Bytes() []byte {
+	if k.bytes == nil {
+		k.bytes = k.pk.Compress()
+	}
+	return k.bytes
+}
+
+// PrivateKeyBLS ...
+type PrivateKeyBLS struct {
+	sk    *blst.SecretKey
+	pk    *PublicKeyBLS
+	bytes []byte
+}
+
+// PublicKey implements the PrivateKey interface
+func (k *PrivateKeyBLS) PublicKey() PublicKey {
+	if k.pk == nil {
+		k.pk = &PublicKeyBLS{pk: new(blst.P1Affine).From(k.sk)}
+	}
+	return k.pk
+}
+
+// Sign implements the PrivateKey interface
+func (k *PrivateKeyBLS) Sign(msg []byte) ([]byte, error) {
+	return k.sign(blsSignatureDomain, msg), nil
+}
+
+// SignHash implements the PrivateKey interface
+func (k *PrivateKeyBLS) SignHash(hash []byte) ([]byte, error) {
+	return k.Sign(hash)
+}
+
+// ProofOfPossession returns a proof that the holder of this private key knows
+// it, which must be verified before its public key is aggregated
+func (k *PrivateKeyBLS) ProofOfPossession() []byte {
+	return k.sign(blsPossessionDomain, k.PublicKey().Bytes())
+}
+
+func (k *PrivateKeyBLS) sign(domain, msg []byte) []byte {
+	return new(blst.P2Affine).Sign(k.sk, msg, domain).Compress()
+}
+
+// Bytes implements the PrivateKey interface
+func (k *PrivateKeyBLS) Bytes() []byte {
+	if k.bytes == nil {
+		k.bytes = k.sk.Serialize()
+	}
+	return k.bytes
+}
+
+// AggregateBLSPublicKeys returns the public key that the aggregate of
+// signatures of one message by [pks] verifies against. The proof of possession
+// of each of [pks] should have been verified.
+func AggregateBLSPublicKeys(pks []*PublicKeyBLS) (*PublicKeyBLS, error) {
+	if len(pks) == 0 {
+		return nil, errNoSigners
+	}
+	points := make([]*blst.P1Affine, len(pks))
+	for i, pk := range pks {
+		points[i] = pk.pk
+	}
+	aggregate := new(blst.P1Aggregate)
+	if !aggregate.Aggregate(points, false) {
+		return nil, errInvalidAggregateKey
+	}
+	pk := aggregate.ToAffine()
+	if !pk.KeyValidate() {
+		return nil, errInvalidAggregateKey
+	}
+	return &PublicKeyBLS{pk: pk}, nil
+}
+
+// AggregateBLSSignatures returns the signature that combines [sigs]
+func AggregateBLSSignatures(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, errNoSignatures
+	}
+	points := make([]*blst.P2Affine, len(sigs))
+	for i, sig := range sigs {
+		sigPoint, err := blsSignature(sig)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = sigPoint
+	}
+	aggregate := new(blst.P2Aggregate)
+	if !aggregate.Aggregate(points, false) {
+		return nil, errInvalidAggregateSig
+	}
+	sig := aggregate.ToAffine()
+	if sig.Equals(new(blst.P2Affine)) {
+		return nil, errInvalidAggregateSig
+	}
+	return sig.Compress(), nil
+}
+
+// VerifyAggregateBLS returns true if [sig] is the aggregate of the signatures
+// of [msgs[i]] by [pks[i]]
+func VerifyAggregateBLS(pks []*PublicKeyBLS, msgs [][]byte, sig []byte) bool {
+	if len(pks) == 0 || len(pks) != len(msgs) {
+		return false
+	}
+	sigPoint, err := blsSignature(sig)
+	if err != nil {
+		return false
+	}
+	points := make([]*blst.P1Affine, len(pks))
+	for i, pk := range pks {
+		points[i] = pk.pk
+	}
+	return sigPoint.AggregateVerify(true, points, false, msgs, blsSignatureDomain)
+}
+
+// blsSignature returns the point of G2 that [sig] is the compression of
+func blsSignature(sig []byte) (*blst.P2Affine, error) {
+	if len(sig) != BLSSigLen {
+		return nil, errInvalidSigLen
+	}
+	sigPoint := new(blst.P2Affine).Uncompress(sig)
+	switch {
+	case sigPoint == nil:
+		return nil, errInvalidPoint
+	case sigPoint.Equals(new(blst.P2Affine)):
+		return nil, errInfinityPoint
+	case !sigPoint.SigValidate(false):
+		return nil, errInvalidPoint
+	}
+	return sigPoint, nil
+}
@@ -0,0 +1,191 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// The key, message and signature are a test vector of Ethereum 2.0, which signs
+// with the same ciphersuite
+func TestBLSKnownAnswer(t *testing.T) {
+	skBytes, _ := hex.DecodeString("263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3")
+	msg := make([]byte, 32)
+	expectedSig, _ := hex.DecodeString("b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb5158090352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55")
+
+	f := FactoryBLS{}
+	sk, err := f.ToPrivateKey(skBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := sk.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, expectedSig) {
+		t.Fatalf("Signature should be %x but is %x", expectedSig, sig)
+	}
+	if !sk.PublicKey().Verify(msg, expectedSig) {
+		t.Fatalf("Should have verified the signature")
+	}
+}
+
+func TestBLSSignVerify(t *testing.T) {
+	f := FactoryBLS{}
+	skIntf, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk := skIntf.(*PrivateKeyBLS)
+	pk := sk.PublicKey().(*PublicKeyBLS)
+
+	msg := []byte("hello")
+	sig, err := sk.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != BLSSigLen {
+		t.Fatalf("Signature should be %d bytes but is %d", BLSSigLen, len(sig))
+	}
+	if !pk.Verify(msg, sig) {
+		t.Fatalf("Should have verified the signature")
+	}
+	if pk.Verify([]byte("hellp"), sig) {
+		t.Fatalf("Shouldn't have verified the signature of another message")
+	}
+	if pk.VerifyProofOfPossession(sig) {
+		t.Fatalf("A signature shouldn't be a proof of possession")
+	}
+
+	otherSK, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherSK.PublicKey().Verify(msg, sig) {
+		t.Fatalf("Shouldn't have verified the signature with another key")
+	}
+
+	parsedSK, err := f.ToPrivateKey(sk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedPK, err := f.ToPublicKey(pk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsedSK.PublicKey().Bytes(), pk.Bytes()) {
+		t.Fatalf("Parsed private key should have the same public key")
+	}
+	if !parsedPK.Verify(msg, sig) {
+		t.Fatalf("Parsed public key should have verified the signature")
+	}
+
+	if _, err := f.ToPrivateKey(make([]byte, BLSSKLen)); err != errInvalidBLSPrivateKey {
+		t.Fatalf("Should have errored with %s but errored with %v", errInvalidBLSPrivateKey, err)
+	}
+	notOnCurve := append([]byte(nil), pk.Bytes()...)
+	notOnCurve[BLSPKLen-1]++
+	if _, err := f.ToPublicKey(notOnCurve); err != errInvalidPoint {
+		t.Fatalf("Should have errored with %s but errored with %v", errInvalidPoint, err)
+	}
+	infinity := make([]byte, BLSPKLen)
+	infinity[0] = 0xc0
+	if _, err := f.ToPublicKey(infinity); err != errInfinityPoint {
+		t.Fatalf("Should have errored with %s but errored with %v", errInfinityPoint, err)
+	}
+}
+
+func TestBLSAggregate(t *testing.T) {
+	f := FactoryBLS{}
+	msg := []byte("hello")
+
+	sks := []*PrivateKeyBLS{}
+	pks := []*PublicKeyBLS{}
+	sigs := [][]byte{}
+	msgs := [][]byte{}
+	distinctSigs := [][]byte{}
+	for i := byte(0); i < 3; i++ {
+		skIntf, err := f.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sk := skIntf.(*PrivateKeyBLS)
+		pk := sk.PublicKey().(*PublicKeyBLS)
+		if !pk.VerifyProofOfPossession(sk.ProofOfPossession()) {
+			t.Fatalf("Should have verified the proof of possession")
+		}
+		sks = append(sks, sk)
+		pks = append(pks, pk)
+
+		sig, err := sk.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+
+		distinctMsg := []byte{i}
+		distinctSig, err := sk.Sign(distinctMsg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, distinctMsg)
+		distinctSigs = append(distinctSigs, distinctSig)
+	}
+	if pks[0].VerifyProofOfPossession(sks[1].ProofOfPossession()) {
+		t.Fatalf("Shouldn't have verified another key's proof of possession")
+	}
+
+	aggSig, err := AggregateBLSSignatures(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggPK, err := AggregateBLSPublicKeys(pks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !aggPK.Verify(msg, aggSig) {
+		t.Fatalf("Should have verified the aggregate signature")
+	}
+	partialPK, err := AggregateBLSPublicKeys(pks[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partialPK.Verify(msg, aggSig) {
+		t.Fatalf("Shouldn't have verified the aggregate signature without every signer")
+	}
+
+	aggDistinctSig, err := AggregateBLSSignatures(distinctSigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyAggregateBLS(pks, msgs, aggDistinctSig) {
+		t.Fatalf("Should have verified the aggregate signature of distinct messages")
+	}
+	msgs[0], msgs[1] = msgs[1], msgs[0]
+	if VerifyAggregateBLS(pks, msgs, aggDistinctSig) {
+		t.Fatalf("Shouldn't have verified the aggregate signature with swapped messages")
+	}
+
+	if _, err := AggregateBLSSignatures(nil); err != errNoSignatures {
+		t.Fatalf("Should have errored with %s but errored with %v", errNoSignatures, err)
+	}
+	if _, err := AggregateBLSPublicKeys(nil); err != errNoSigners {
+		t.Fatalf("Should have errored with %s but errored with %v", errNoSigners, err)
+	}
+	// The key of r - sk is the negation of the key of sk
+	r, _ := new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+	negatedSKBytes := make([]byte, BLSSKLen)
+	negatedSK := new(big.Int).Sub(r, new(big.Int).SetBytes(sks[0].Bytes()))
+	copy(negatedSKBytes[BLSSKLen-len(negatedSK.Bytes()):], negatedSK.Bytes())
+	negated, err := f.ToPrivateKey(negatedSKBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AggregateBLSPublicKeys([]*PublicKeyBLS{pks[0], negated.PublicKey().(*PublicKeyBLS)}); err != errInvalidAggregateKey {
+		t.Fatalf("Should have errored with %s but errored with %v", errInvalidAggregateKey, err)
+	}
+}
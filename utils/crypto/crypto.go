@@ -45,3 +45,11 @@ type PrivateKey interface {
 
 	Bytes() []byte
 }
+
+// Signer signs hashes with a private key that it may not expose, such as a key
+// held on a hardware device. Every PrivateKey is a Signer.
+type Signer interface {
+	PublicKey() PublicKey
+
+	SignHash(hash []byte) ([]byte, error)
+}
@@ -18,6 +18,7 @@ const (
 	RSAPSS
 	ED25519
 	SECP256K1
+	BLS
 )
 
 var (
@@ -42,6 +43,7 @@ func init() {
 		RSAPSS:    &FactoryRSAPSS{},
 		ED25519:   &FactoryED25519{},
 		SECP256K1: &FactorySECP256K1{},
+		BLS:       &FactoryBLS{},
 	}
 	for _, f := range factories {
 		fKeys := []PublicKey{}
@@ -101,3 +103,10 @@ func BenchmarkSECP256k1Verify(b *testing.B) {
 		verify(SECP256K1)
 	}
 }
+
+// BenchmarkBLSVerify runs the benchmark with BLS keys
+func BenchmarkBLSVerify(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		verify(BLS)
+	}
+}
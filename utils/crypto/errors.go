@@ -10,4 +10,7 @@ import (
 var (
 	errInvalidSigLen = errors.New("invalid signature length")
 	errMutatedSig    = errors.New("signature was mutated from its original format")
+
+	errNoSigners           = errors.New("no signers provided")
+	errInvalidAggregateKey = errors.New("signers aggregate to an invalid key")
 )
@@ -0,0 +1,150 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ledger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ava-labs/gecko/utils/crypto"
+)
+
+// swInvalidData is the status word of a request whose data is malformed
+const swInvalidData = 0x6a80
+
+// Emulator emulates a Ledger device running the AVA app, so that signing with
+// a Ledger can be tested without one. It signs with one key, whatever the
+// requested path.
+type Emulator struct {
+	key *crypto.PrivateKeySECP256K1R
+
+	// Approve, if it isn't nil, is called before each signature is returned,
+	// as the user approving it on the device would be waited for. The
+	// signature is rejected if it returns false.
+	Approve func() bool
+
+	lock      sync.Mutex
+	request   bytes.Buffer
+	responses [][]byte
+}
+
+// NewEmulator returns an emulated device that signs with [key]
+func NewEmulator(key *crypto.PrivateKeySECP256K1R) *Emulator {
+	return &Emulator{key: key}
+}
+
+// Key returns the key the device signs with
+func (e *Emulator) Key() *crypto.PrivateKeySECP256K1R {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.key
+}
+
+// SetKey changes the key the device signs with to [key]
+func (e *Emulator) SetKey(key *crypto.PrivateKeySECP256K1R) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.key = key
+}
+
+// Write implements the io.Writer interface. Each write must be a single HID
+// packet.
+func (e *Emulator) Write(packet []byte) (int, error) {
+	if len(packet) != packetSize {
+		return 0, fmt.Errorf("%w: packet is %d bytes", errInvalidPacket, len(packet))
+	}
+
+	e.lock.Lock()
+	e.request.Write(packet)
+	apdu, err := unwrap(bytes.NewReader(e.request.Bytes()))
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// The APDU hasn't been completely written yet
+		e.lock.Unlock()
+		return len(packet), nil
+	}
+	e.request.Reset()
+	key := e.key
+	e.lock.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	// The device is waited for without holding the lock, as it would be
+	// waited for while the user approves a signature
+	response := e.respond(key, apdu)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.responses = append(e.responses, wrap(response)...)
+	return len(packet), nil
+}
+
+// Read implements the io.Reader interface. Each read returns a single HID
+// packet.
+func (e *Emulator) Read(packet []byte) (int, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if len(e.responses) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(packet, e.responses[0])
+	e.responses = e.responses[1:]
+	return n, nil
+}
+
+// Close implements the io.Closer interface
+func (e *Emulator) Close() error { return nil }
+
+// respond returns the response to [apdu], signing with [key]
+func (e *Emulator) respond(key *crypto.PrivateKeySECP256K1R, apdu []byte) []byte {
+	status := make([]byte, 2)
+	if len(apdu) < 5 || int(apdu[4]) != len(apdu)-5 {
+		binary.BigEndian.PutUint16(status, swInvalidData)
+		return status
+	}
+	if apdu[0] != claAVA {
+		binary.BigEndian.PutUint16(status, swWrongClass)
+		return status
+	}
+	data := apdu[5:]
+
+	var response []byte
+	switch apdu[1] {
+	case insVersion:
+		response = []byte{1, 2, 3}
+	case insGetPublicKey:
+		if len(data) != len(path(0)) {
+			binary.BigEndian.PutUint16(status, swInvalidData)
+			return status
+		}
+		response = key.PublicKey().Bytes()
+	case insSignHash:
+		if len(data) != len(path(0))+32 {
+			binary.BigEndian.PutUint16(status, swInvalidData)
+			return status
+		}
+		if e.Approve != nil && !e.Approve() {
+			binary.BigEndian.PutUint16(status, swRejected)
+			return status
+		}
+		sig, err := key.SignHash(data[len(path(0)):])
+		if err != nil {
+			binary.BigEndian.PutUint16(status, swInvalidData)
+			return status
+		}
+		response = sig
+	default:
+		binary.BigEndian.PutUint16(status, swWrongInstruction)
+		return status
+	}
+	binary.BigEndian.PutUint16(status, swOK)
+	return append(response, status...)
+}
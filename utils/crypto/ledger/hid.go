@@ -0,0 +1,74 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ledger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// APDUs are carried over HID in fixed size packets. Each packet starts with
+// the channel, the tag and the index of the packet in the sequence. The first
+// packet of a sequence then has the length of the APDU.
+const (
+	packetSize       = 64
+	packetHeaderSize = 5
+	hidChannel       = 0x0101
+	hidTagAPDU       = 0x05
+)
+
+// wrap returns the packets that carry [apdu]
+func wrap(apdu []byte) [][]byte {
+	data := make([]byte, 2+len(apdu))
+	binary.BigEndian.PutUint16(data, uint16(len(apdu)))
+	copy(data[2:], apdu)
+
+	packets := [][]byte(nil)
+	for seq := uint16(0); len(data) > 0 || seq == 0; seq++ {
+		packet := make([]byte, packetSize)
+		binary.BigEndian.PutUint16(packet, hidChannel)
+		packet[2] = hidTagAPDU
+		binary.BigEndian.PutUint16(packet[3:], seq)
+		n := copy(packet[packetHeaderSize:], data)
+		data = data[n:]
+		packets = append(packets, packet)
+	}
+	return packets
+}
+
+// unwrap reads packets from [r] until it has read a whole APDU and returns
+// that APDU
+func unwrap(r io.Reader) ([]byte, error) {
+	var (
+		apdu   []byte
+		length int
+		packet = make([]byte, packetSize)
+	)
+	for seq := uint16(0); seq == 0 || len(apdu) < length; seq++ {
+		if _, err := io.ReadFull(r, packet); err != nil {
+			return nil, err
+		}
+		switch {
+		case binary.BigEndian.Uint16(packet) != hidChannel:
+			return nil, fmt.Errorf("%w: unexpected channel %#x", errInvalidPacket, binary.BigEndian.Uint16(packet))
+		case packet[2] != hidTagAPDU:
+			return nil, fmt.Errorf("%w: unexpected tag %#x", errInvalidPacket, packet[2])
+		case binary.BigEndian.Uint16(packet[3:]) != seq:
+			return nil, fmt.Errorf("%w: expected packet %d but got %d", errInvalidPacket, seq, binary.BigEndian.Uint16(packet[3:]))
+		}
+
+		data := packet[packetHeaderSize:]
+		if seq == 0 {
+			length = int(binary.BigEndian.Uint16(data))
+			apdu = make([]byte, 0, length)
+			data = data[2:]
+		}
+		if remaining := length - len(apdu); len(data) > remaining {
+			data = data[:remaining]
+		}
+		apdu = append(apdu, data...)
+	}
+	return apdu, nil
+}
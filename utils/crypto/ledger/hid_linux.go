@@ -0,0 +1,66 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ledger
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	hidrawClassDir = "/sys/class/hidraw"
+
+	// ledgerHIDID is the bus, USB, and vendor ID of Ledger devices in the
+	// HID_ID of their uevent
+	ledgerHIDID = "HID_ID=0003:00002C97:"
+
+	// apduInterface is the USB interface that Ledger devices exchange APDUs
+	// over. Other interfaces, such as U2F, are ignored.
+	apduInterface = "00"
+)
+
+// hidraw is a hidraw device node. Writes are prefixed with the report ID,
+// which is always 0 as Ledger devices don't number their reports.
+type hidraw struct{ *os.File }
+
+func (h hidraw) Write(packet []byte) (int, error) {
+	n, err := h.File.Write(append([]byte{0}, packet...))
+	if n > 0 {
+		n--
+	}
+	return n, err
+}
+
+// openDevice opens the hidraw node of the first Ledger device
+func openDevice() (io.ReadWriteCloser, error) {
+	nodes, err := ioutil.ReadDir(hidrawClassDir)
+	if err != nil {
+		return nil, errNoDevice
+	}
+	for _, node := range nodes {
+		deviceDir, err := filepath.EvalSymlinks(filepath.Join(hidrawClassDir, node.Name(), "device"))
+		if err != nil {
+			continue
+		}
+		uevent, err := ioutil.ReadFile(filepath.Join(deviceDir, "uevent"))
+		if err != nil || !strings.Contains(strings.ToUpper(string(uevent)), ledgerHIDID) {
+			continue
+		}
+		// The HID device is a child of the USB interface it's exposed on
+		iface, err := ioutil.ReadFile(filepath.Join(filepath.Dir(deviceDir), "bInterfaceNumber"))
+		if err == nil && strings.TrimSpace(string(iface)) != apduInterface {
+			continue
+		}
+
+		f, err := os.OpenFile(filepath.Join("/dev", node.Name()), os.O_RDWR, 0)
+		if err != nil {
+			return nil, err
+		}
+		return hidraw{File: f}, nil
+	}
+	return nil, errNoDevice
+}
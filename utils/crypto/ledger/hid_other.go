@@ -0,0 +1,18 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !linux
+// +build !linux
+
+package ledger
+
+import (
+	"errors"
+	"io"
+)
+
+var errUnsupportedPlatform = errors.New("Ledger devices can only be opened on linux")
+
+// openDevice isn't supported on this platform. A device can still be used by
+// passing a connection to it to New.
+func openDevice() (io.ReadWriteCloser, error) { return nil, errUnsupportedPlatform }
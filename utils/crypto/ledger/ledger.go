@@ -0,0 +1,213 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package ledger signs with keys held on a Ledger hardware device that is
+// running the AVA app. Keys never leave the device: the device derives them
+// from its seed along the path m/44'/9000'/0'/0/[index], and every signature
+// has to be approved on the device.
+package ledger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// APDU class and instructions of the AVA app
+const (
+	claAVA          = 0x80
+	insVersion      = 0x00
+	insGetPublicKey = 0x02
+	insSignHash     = 0x04
+)
+
+// Status words that end the responses of the device
+const (
+	swOK               = 0x9000
+	swRejected         = 0x6985
+	swWrongInstruction = 0x6d00
+	swWrongClass       = 0x6e00
+	swLocked           = 0x5515
+)
+
+const (
+	// hardened marks an index of a derivation path as hardened
+	hardened = 0x80000000
+
+	// coinType is the BIP-44 coin type of AVA
+	coinType = 9000
+)
+
+var (
+	errInvalidPacket   = errors.New("invalid HID packet")
+	errInvalidResponse = errors.New("invalid response from the device")
+	errRejected        = errors.New("request was rejected on the device")
+	errAppNotOpen      = errors.New("the AVA app isn't open on the device")
+	errLocked          = errors.New("the device is locked")
+	errDataTooLong     = errors.New("APDU data is too long")
+	errInvalidHashLen  = errors.New("hash must be 32 bytes")
+	errWrongSigner     = errors.New("device signed with an unexpected key")
+	errNoDevice        = errors.New("no Ledger device found")
+)
+
+// Ledger is a connection to a Ledger device
+type Ledger struct {
+	// lock serializes exchanges with the device, as the packets of concurrent
+	// exchanges can't be interleaved
+	lock   sync.Mutex
+	device io.ReadWriteCloser
+}
+
+// New returns a connection to the Ledger device that [device] reads from and
+// writes to. Each write to [device] must be a single HID packet.
+func New(device io.ReadWriteCloser) *Ledger { return &Ledger{device: device} }
+
+// Open returns a connection to the first Ledger device connected to this
+// machine
+func Open() (*Ledger, error) {
+	device, err := openDevice()
+	if err != nil {
+		return nil, err
+	}
+	return New(device), nil
+}
+
+// Close the connection to the device
+func (l *Ledger) Close() error { return l.device.Close() }
+
+// Version returns the version of the AVA app running on the device
+func (l *Ledger) Version() (string, error) {
+	response, err := l.exchange(insVersion, 0, 0, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(response) < 3 {
+		return "", fmt.Errorf("%w: version is %d bytes", errInvalidResponse, len(response))
+	}
+	return fmt.Sprintf("v%d.%d.%d", response[0], response[1], response[2]), nil
+}
+
+// PublicKey returns the public key of the key at [index]
+func (l *Ledger) PublicKey(index uint32) (crypto.PublicKey, error) {
+	response, err := l.exchange(insGetPublicKey, 0, 0, path(index))
+	if err != nil {
+		return nil, err
+	}
+	if len(response) != crypto.SECP256K1RPKLen {
+		return nil, fmt.Errorf("%w: public key is %d bytes", errInvalidResponse, len(response))
+	}
+	factory := crypto.FactorySECP256K1R{}
+	return factory.ToPublicKey(response)
+}
+
+// Signer returns a signer that signs with the key at [index]
+func (l *Ledger) Signer(index uint32) (*Signer, error) {
+	pk, err := l.PublicKey(index)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{
+		ledger: l,
+		index:  index,
+		pk:     pk,
+	}, nil
+}
+
+// exchange sends an APDU to the device and returns the data of the response
+func (l *Ledger) exchange(ins, p1, p2 byte, data []byte) ([]byte, error) {
+	if len(data) > 0xff {
+		return nil, errDataTooLong
+	}
+	apdu := make([]byte, 5+len(data))
+	apdu[0] = claAVA
+	apdu[1] = ins
+	apdu[2] = p1
+	apdu[3] = p2
+	apdu[4] = byte(len(data))
+	copy(apdu[5:], data)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, packet := range wrap(apdu) {
+		if _, err := l.device.Write(packet); err != nil {
+			return nil, err
+		}
+	}
+	response, err := unwrap(l.device)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < 2 {
+		return nil, fmt.Errorf("%w: missing status word", errInvalidResponse)
+	}
+
+	status := binary.BigEndian.Uint16(response[len(response)-2:])
+	switch status {
+	case swOK:
+		return response[:len(response)-2], nil
+	case swRejected:
+		return nil, errRejected
+	case swWrongInstruction, swWrongClass:
+		return nil, errAppNotOpen
+	case swLocked:
+		return nil, errLocked
+	default:
+		return nil, fmt.Errorf("device returned status %#04x", status)
+	}
+}
+
+// path returns the serialized derivation path of the key at [index]
+func path(index uint32) []byte {
+	indices := []uint32{44 | hardened, coinType | hardened, 0 | hardened, 0, index}
+	p := make([]byte, 1+4*len(indices))
+	p[0] = byte(len(indices))
+	for i, index := range indices {
+		binary.BigEndian.PutUint32(p[1+4*i:], index)
+	}
+	return p
+}
+
+// Signer signs with a key held on a Ledger device. It implements
+// crypto.Signer.
+type Signer struct {
+	ledger  *Ledger
+	index   uint32
+	pk      crypto.PublicKey
+	factory crypto.FactorySECP256K1R
+}
+
+// Index returns the index of the key this signer signs with
+func (s *Signer) Index() uint32 { return s.index }
+
+// PublicKey implements the crypto.Signer interface
+func (s *Signer) PublicKey() crypto.PublicKey { return s.pk }
+
+// SignHash implements the crypto.Signer interface. The signature must be
+// approved on the device, so this blocks until the user responds.
+func (s *Signer) SignHash(hash []byte) ([]byte, error) {
+	if len(hash) != hashing.HashLen {
+		return nil, errInvalidHashLen
+	}
+	data := append(path(s.index), hash...)
+	sig, err := s.ledger.exchange(insSignHash, 0, 0, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the signature recovers to this key, as it will be checked by
+	// recovering the signer's address
+	pk, err := s.factory.RecoverHashPublicKey(hash, sig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidResponse, err)
+	}
+	if !pk.Address().Equals(s.pk.Address()) {
+		return nil, errWrongSigner
+	}
+	return sig, nil
+}
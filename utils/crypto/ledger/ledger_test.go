@@ -0,0 +1,107 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ledger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// newTestDevice returns an emulated device that signs with a new key
+func newTestDevice(t *testing.T) *Emulator {
+	factory := crypto.FactorySECP256K1R{}
+	key, err := factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewEmulator(key.(*crypto.PrivateKeySECP256K1R))
+}
+
+func TestWrapUnwrap(t *testing.T) {
+	for _, size := range []int{0, 1, packetSize - packetHeaderSize - 2, packetSize, 300} {
+		apdu := make([]byte, size)
+		for i := range apdu {
+			apdu[i] = byte(i)
+		}
+		packets := wrap(apdu)
+		stream := bytes.Buffer{}
+		for _, packet := range packets {
+			if len(packet) != packetSize {
+				t.Fatalf("Packet is %d bytes", len(packet))
+			}
+			stream.Write(packet)
+		}
+		unwrapped, err := unwrap(&stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(apdu, unwrapped) {
+			t.Fatalf("Unwrapped APDU of %d bytes differs from the wrapped APDU", size)
+		}
+	}
+
+	packets := wrap(make([]byte, 100))
+	packets[1][4] = 2
+	stream := bytes.NewReader(append(packets[0], packets[1]...))
+	if _, err := unwrap(stream); !errors.Is(err, errInvalidPacket) {
+		t.Fatalf("Should have errored with %s but errored with %v", errInvalidPacket, err)
+	}
+}
+
+func TestLedgerSign(t *testing.T) {
+	device := newTestDevice(t)
+	l := New(device)
+
+	version, err := l.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "v1.2.3" {
+		t.Fatalf("Expected version v1.2.3 but got %s", version)
+	}
+
+	signer, err := l.Signer(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signer.PublicKey().Address().Equals(device.Key().PublicKey().Address()) {
+		t.Fatalf("Signer has the wrong public key")
+	}
+
+	hash := hashing.ComputeHash256([]byte("hello"))
+	sig, err := signer.SignHash(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signer.PublicKey().VerifyHash(hash, sig) {
+		t.Fatalf("Device's signature should have verified")
+	}
+
+	if _, err := signer.SignHash(hash[1:]); err != errInvalidHashLen {
+		t.Fatalf("Should have errored with %s but errored with %v", errInvalidHashLen, err)
+	}
+
+	device.Approve = func() bool { return false }
+	if _, err := signer.SignHash(hash); err != errRejected {
+		t.Fatalf("Should have errored with %s but errored with %v", errRejected, err)
+	}
+}
+
+func TestLedgerWrongSigner(t *testing.T) {
+	device := newTestDevice(t)
+	signer, err := New(device).Signer(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The device now signs with a different key than it reported
+	device.SetKey(newTestDevice(t).Key())
+	if _, err := signer.SignHash(hashing.ComputeHash256([]byte("hello"))); err != errWrongSigner {
+		t.Fatalf("Should have errored with %s but errored with %v", errWrongSigner, err)
+	}
+}
@@ -0,0 +1,256 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+)
+
+const (
+	// SchnorrSigLen is the number of bytes in an aggregated schnorr signature,
+	// which is a BIP-340 signature
+	SchnorrSigLen = schnorr.SignatureSize
+
+	// SchnorrNonceLen is the number of bytes in the public nonce each signer
+	// sends the others before signing
+	SchnorrNonceLen = musig2.PubNonceSize
+
+	// SchnorrPartialSigLen is the number of bytes in a signer's partial
+	// signature
+	SchnorrPartialSigLen = 32
+)
+
+var (
+	errNotASigner           = errors.New("key isn't one of the signers")
+	errInvalidHashLen       = errors.New("hash should be 32 bytes")
+	errWrongNumNonces       = errors.New("expected a nonce from each signer")
+	errInvalidNonceLen      = errors.New("invalid nonce length")
+	errWrongNumPartialSigs  = errors.New("expected a partial signature from each signer")
+	errInvalidPartialSigLen = errors.New("invalid partial signature length")
+	errNotSigned            = errors.New("partial signature hasn't been made yet")
+	errAlreadySigned        = errors.New("a partial signature was already made with this signer's nonce")
+)
+
+// AggregateSigner is one signer's side of signing a hash with MuSig2, so that
+// the signature verifies against the aggregate of the signers' public keys.
+// Each signer holds only its own private key. First, each signer sends the
+// others its Nonce. Once it has every signer's nonce, each signer sends the
+// partial signature that Sign returns to whoever combines them, which can be
+// any of the signers.
+//
+// A signer must be used for one signature only, since signing twice with the
+// same nonce leaks the private key.
+type AggregateSigner struct {
+	key     *btcec.PrivateKey
+	signers []*btcec.PublicKey
+	index   int
+	hash    [32]byte
+
+	nonces        *musig2.Nonces
+	pubNonces     [][musig2.PubNonceSize]byte
+	combinedNonce [musig2.PubNonceSize]byte
+	partialSig    *musig2.PartialSignature
+}
+
+// NewAggregateSigner returns the signer with [key] among [signers], in the
+// order their keys are aggregated, of [hash]
+func NewAggregateSigner(key *PrivateKeySECP256K1R, signers []*PublicKeySECP256K1R, hash []byte) (*AggregateSigner, error) {
+	if len(hash) != 32 {
+		return nil, errInvalidHashLen
+	}
+	pks, err := schnorrKeys(signers)
+	if err != nil {
+		return nil, err
+	}
+	sk, _ := btcec.PrivKeyFromBytes(key.Bytes())
+
+	s := &AggregateSigner{
+		key:     sk,
+		signers: pks,
+		index:   -1,
+	}
+	copy(s.hash[:], hash)
+	for i, pk := range pks {
+		if pk.IsEqual(sk.PubKey()) {
+			s.index = i
+			break
+		}
+	}
+	if s.index < 0 {
+		return nil, errNotASigner
+	}
+
+	aggregate, _, _, err := musig2.AggregateKeys(pks, false)
+	if err != nil {
+		return nil, err
+	}
+	s.nonces, err = musig2.GenNonces(
+		musig2.WithPublicKey(sk.PubKey()),
+		musig2.WithNonceSecretKeyAux(sk),
+		musig2.WithNonceCombinedKeyAux(aggregate.FinalKey),
+		musig2.WithNonceMessageAux(s.hash),
+	)
+	return s, err
+}
+
+// Nonce returns the public nonce that this signer sends the other signers
+// before any of them signs
+func (s *AggregateSigner) Nonce() []byte {
+	return append([]byte(nil), s.nonces.PubNonce[:]...)
+}
+
+// Sign returns this signer's partial signature, given the public nonce of each
+// signer in the order of the signers. The partial signature is sent to whoever
+// combines the signature.
+func (s *AggregateSigner) Sign(nonces [][]byte) ([]byte, error) {
+	if s.partialSig != nil {
+		return nil, errAlreadySigned
+	}
+	if len(nonces) != len(s.signers) {
+		return nil, errWrongNumNonces
+	}
+	pubNonces := make([][musig2.PubNonceSize]byte, len(nonces))
+	for i, nonce := range nonces {
+		if len(nonce) != musig2.PubNonceSize {
+			return nil, errInvalidNonceLen
+		}
+		copy(pubNonces[i][:], nonce)
+	}
+	if pubNonces[s.index] != s.nonces.PubNonce {
+		return nil, fmt.Errorf("nonce %d isn't this signer's", s.index)
+	}
+
+	combinedNonce, err := musig2.AggregateNonces(pubNonces)
+	if err != nil {
+		return nil, err
+	}
+	partialSig, err := musig2.Sign(s.nonces.SecNonce, s.key, combinedNonce, s.signers, s.hash)
+	if err != nil {
+		return nil, err
+	}
+	s.pubNonces = pubNonces
+	s.combinedNonce = combinedNonce
+	s.partialSig = partialSig
+
+	b := bytes.Buffer{}
+	if err := partialSig.Encode(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Combine returns the signature that the partial signature of each signer, in
+// the order of the signers, combine to. This signer must have signed already.
+func (s *AggregateSigner) Combine(partialSigs [][]byte) ([]byte, error) {
+	if s.partialSig == nil {
+		return nil, errNotSigned
+	}
+	if len(partialSigs) != len(s.signers) {
+		return nil, errWrongNumPartialSigs
+	}
+	sigs := make([]*musig2.PartialSignature, len(partialSigs))
+	for i, partialSigBytes := range partialSigs {
+		if len(partialSigBytes) != SchnorrPartialSigLen {
+			return nil, errInvalidPartialSigLen
+		}
+		sig := &musig2.PartialSignature{}
+		if err := sig.Decode(bytes.NewReader(partialSigBytes)); err != nil {
+			return nil, err
+		}
+		// Each partial signature is verified so that the signer that made
+		// an invalid one can be named
+		if !sig.Verify(s.pubNonces[i], s.combinedNonce, s.signers, s.signers[i], s.hash) {
+			return nil, fmt.Errorf("partial signature %d is invalid", i)
+		}
+		sigs[i] = sig
+	}
+
+	sig := musig2.CombineSigs(s.partialSig.R, sigs)
+	aggregate, _, _, err := musig2.AggregateKeys(s.signers, false)
+	if err != nil {
+		return nil, err
+	}
+	if !sig.Verify(s.hash[:], aggregate.FinalKey) {
+		return nil, errInvalidAggregateSig
+	}
+	return sig.Serialize(), nil
+}
+
+// SignAggregateHash returns the signature of [hash] by all of [keys], which
+// verifies against the aggregate of their public keys in the same order. It's
+// for a wallet, such as a keystore user, that holds every one of the keys; it
+// runs the signing protocol of AggregateSigner between them.
+func SignAggregateHash(keys []*PrivateKeySECP256K1R, hash []byte) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, errNoSigners
+	}
+	pks := make([]*PublicKeySECP256K1R, len(keys))
+	for i, key := range keys {
+		pks[i] = key.PublicKey().(*PublicKeySECP256K1R)
+	}
+
+	signers := make([]*AggregateSigner, len(keys))
+	nonces := make([][]byte, len(keys))
+	for i, key := range keys {
+		signer, err := NewAggregateSigner(key, pks, hash)
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = signer
+		nonces[i] = signer.Nonce()
+	}
+
+	partialSigs := make([][]byte, len(keys))
+	for i, signer := range signers {
+		partialSig, err := signer.Sign(nonces)
+		if err != nil {
+			return nil, err
+		}
+		partialSigs[i] = partialSig
+	}
+	return signers[0].Combine(partialSigs)
+}
+
+// VerifyAggregateHash returns true if [sig] is a signature of [hash] by all of
+// [pks], in the order their keys were aggregated when signing
+func VerifyAggregateHash(pks []*PublicKeySECP256K1R, hash, sig []byte) bool {
+	if len(pks) == 0 || len(hash) != 32 || len(sig) != SchnorrSigLen {
+		return false
+	}
+	keys, err := schnorrKeys(pks)
+	if err != nil {
+		return false
+	}
+	aggregate, _, _, err := musig2.AggregateKeys(keys, false)
+	if err != nil {
+		return false
+	}
+	parsedSig, err := schnorr.ParseSignature(sig)
+	if err != nil {
+		return false
+	}
+	return parsedSig.Verify(hash, aggregate.FinalKey)
+}
+
+// schnorrKeys returns [pks] as keys of the btcec library
+func schnorrKeys(pks []*PublicKeySECP256K1R) ([]*btcec.PublicKey, error) {
+	if len(pks) == 0 {
+		return nil, errNoSigners
+	}
+	keys := make([]*btcec.PublicKey, len(pks))
+	for i, pk := range pks {
+		key, err := btcec.ParsePubKey(pk.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
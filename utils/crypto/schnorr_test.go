@@ -0,0 +1,160 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+func TestAggregateSignature(t *testing.T) {
+	f := FactorySECP256K1R{}
+
+	keys := []*PrivateKeySECP256K1R{}
+	pks := []*PublicKeySECP256K1R{}
+	for i := 0; i < 3; i++ {
+		keyIntf, err := f.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := keyIntf.(*PrivateKeySECP256K1R)
+		keys = append(keys, key)
+		pks = append(pks, key.PublicKey().(*PublicKeySECP256K1R))
+	}
+
+	hash := hashing.ComputeHash256([]byte{1, 2, 3})
+	sig, err := SignAggregateHash(keys, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != SchnorrSigLen {
+		t.Fatalf("Signature should be %d bytes but is %d", SchnorrSigLen, len(sig))
+	}
+
+	if !VerifyAggregateHash(pks, hash, sig) {
+		t.Fatalf("Should have verified the aggregate signature")
+	}
+
+	otherHash := hashing.ComputeHash256([]byte{1, 2, 4})
+	if VerifyAggregateHash(pks, otherHash, sig) {
+		t.Fatalf("Shouldn't have verified a signature of a different message")
+	}
+	if VerifyAggregateHash(pks[:2], hash, sig) {
+		t.Fatalf("Shouldn't have verified with a missing signer")
+	}
+	if VerifyAggregateHash([]*PublicKeySECP256K1R{pks[1], pks[0], pks[2]}, hash, sig) {
+		t.Fatalf("Shouldn't have verified with the signers reordered")
+	}
+
+	mutatedSig := make([]byte, len(sig))
+	copy(mutatedSig, sig)
+	mutatedSig[SchnorrSigLen-1]++
+	if VerifyAggregateHash(pks, hash, mutatedSig) {
+		t.Fatalf("Shouldn't have verified a mutated signature")
+	}
+}
+
+func TestAggregateSignatureSingleSigner(t *testing.T) {
+	f := FactorySECP256K1R{}
+	keyIntf, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := keyIntf.(*PrivateKeySECP256K1R)
+
+	// The public key should be usable after being serialized
+	pkIntf, err := f.ToPublicKey(key.PublicKey().Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := pkIntf.(*PublicKeySECP256K1R)
+
+	hash := hashing.ComputeHash256([]byte{1, 2, 3})
+	sig, err := SignAggregateHash([]*PrivateKeySECP256K1R{key}, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyAggregateHash([]*PublicKeySECP256K1R{pk}, hash, sig) {
+		t.Fatalf("Should have verified the signature")
+	}
+
+	if _, err := SignAggregateHash(nil, hash); err != errNoSigners {
+		t.Fatalf("Should have errored with %s", errNoSigners)
+	}
+}
+
+func TestAggregateSigners(t *testing.T) {
+	f := FactorySECP256K1R{}
+
+	keys := []*PrivateKeySECP256K1R{}
+	pks := []*PublicKeySECP256K1R{}
+	for i := 0; i < 3; i++ {
+		keyIntf, err := f.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := keyIntf.(*PrivateKeySECP256K1R)
+		keys = append(keys, key)
+		pks = append(pks, key.PublicKey().(*PublicKeySECP256K1R))
+	}
+	hash := hashing.ComputeHash256([]byte{1, 2, 3})
+
+	// Each signer only holds its own key
+	signers := []*AggregateSigner{}
+	nonces := [][]byte{}
+	for _, key := range keys {
+		signer, err := NewAggregateSigner(key, pks, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers = append(signers, signer)
+		nonces = append(nonces, signer.Nonce())
+	}
+	if _, err := signers[0].Combine(make([][]byte, 3)); err != errNotSigned {
+		t.Fatalf("Should have errored with %s but errored with %v", errNotSigned, err)
+	}
+	if _, err := signers[0].Sign(nonces[:2]); err != errWrongNumNonces {
+		t.Fatalf("Should have errored with %s but errored with %v", errWrongNumNonces, err)
+	}
+
+	partialSigs := [][]byte{}
+	for _, signer := range signers {
+		partialSig, err := signer.Sign(nonces)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(partialSig) != SchnorrPartialSigLen {
+			t.Fatalf("Partial signature should be %d bytes but is %d", SchnorrPartialSigLen, len(partialSig))
+		}
+		partialSigs = append(partialSigs, partialSig)
+	}
+	if _, err := signers[0].Sign(nonces); err != errAlreadySigned {
+		t.Fatalf("Should have errored with %s but errored with %v", errAlreadySigned, err)
+	}
+
+	// Any signer can combine the partial signatures
+	sig, err := signers[2].Combine(partialSigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyAggregateHash(pks, hash, sig) {
+		t.Fatalf("Should have verified the aggregate signature")
+	}
+
+	mutatedPartialSigs := append([][]byte(nil), partialSigs...)
+	mutatedPartialSigs[1] = append([]byte(nil), partialSigs[1]...)
+	mutatedPartialSigs[1][SchnorrPartialSigLen-1]++
+	if _, err := signers[0].Combine(mutatedPartialSigs); err == nil {
+		t.Fatalf("Shouldn't have combined a mutated partial signature")
+	}
+
+	otherKeyIntf, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAggregateSigner(otherKeyIntf.(*PrivateKeySECP256K1R), pks, hash); err != errNotASigner {
+		t.Fatalf("Should have errored with %s but errored with %v", errNotASigner, err)
+	}
+}
@@ -0,0 +1,214 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package formatting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Bech32 strings, as described by BIP 173, are a human-readable part (HRP),
+// the separator '1', and data in base 32 followed by a 6 character checksum.
+// The checksum detects any error that changes at most 4 characters.
+
+const (
+	bech32Charset      = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32Separator    = '1'
+	bech32ChecksumSize = 6
+	bech32MaxLength    = 90
+)
+
+// Generators of the BCH code that the checksum is computed with
+var bech32Generators = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+var (
+	errBech32Length      = fmt.Errorf("bech32 string must be at most %d characters", bech32MaxLength)
+	errBech32MixedCase   = errors.New("bech32 string mixes upper and lower case")
+	errBech32NoSeparator = errors.New("bech32 string has no separator")
+	errBech32EmptyHRP    = errors.New("bech32 string has an empty human-readable part")
+	errBech32InvalidHRP  = errors.New("human-readable part has a character outside of [33, 126]")
+	errBech32NoChecksum  = errors.New("bech32 string is shorter than its checksum")
+	errBech32InvalidChar = errors.New("bech32 data has a character outside of the bech32 charset")
+	errBech32BadChecksum = errors.New("invalid bech32 checksum")
+	errBech32BadPadding  = errors.New("bech32 data isn't padded to whole bytes")
+)
+
+// Bech32Encode returns [data] as a bech32 string with the human-readable part
+// [hrp]
+func Bech32Encode(hrp string, data []byte) (string, error) {
+	if err := verifyHRP(hrp); err != nil {
+		return "", err
+	}
+	hrp = strings.ToLower(hrp)
+
+	values := convertBits(data, 8, 5)
+	checksum := bech32Checksum(hrp, values)
+
+	builder := strings.Builder{}
+	builder.WriteString(hrp)
+	builder.WriteByte(bech32Separator)
+	for _, value := range append(values, checksum...) {
+		builder.WriteByte(bech32Charset[value])
+	}
+	str := builder.String()
+	if len(str) > bech32MaxLength {
+		return "", errBech32Length
+	}
+	return str, nil
+}
+
+// Bech32Decode returns the human-readable part and the data of the bech32
+// string [str], after verifying its checksum. The human-readable part is
+// returned in lower case.
+func Bech32Decode(str string) (string, []byte, error) {
+	hrp, values, err := bech32Split(str)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := unconvertBits(values[:len(values)-bech32ChecksumSize])
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
+
+// Bech32Verify returns nil if [str] is a well-formed bech32 string with a
+// valid checksum. Unlike Bech32Decode, it doesn't require the data to be
+// whole bytes.
+func Bech32Verify(str string) error {
+	_, _, err := bech32Split(str)
+	return err
+}
+
+// bech32Split returns the lower case human-readable part of [str] and the
+// values of its data and checksum, after verifying the checksum
+func bech32Split(str string) (string, []byte, error) {
+	if len(str) > bech32MaxLength {
+		return "", nil, errBech32Length
+	}
+	lower := strings.ToLower(str)
+	if lower != str && strings.ToUpper(str) != str {
+		return "", nil, errBech32MixedCase
+	}
+
+	separator := strings.LastIndexByte(lower, bech32Separator)
+	switch {
+	case separator == -1:
+		return "", nil, errBech32NoSeparator
+	case separator == 0:
+		return "", nil, errBech32EmptyHRP
+	case len(lower)-separator-1 < bech32ChecksumSize:
+		return "", nil, errBech32NoChecksum
+	}
+	hrp := lower[:separator]
+	if err := verifyHRP(hrp); err != nil {
+		return "", nil, err
+	}
+
+	values := make([]byte, 0, len(lower)-separator-1)
+	for _, char := range lower[separator+1:] {
+		value := strings.IndexRune(bech32Charset, char)
+		if value == -1 {
+			return "", nil, errBech32InvalidChar
+		}
+		values = append(values, byte(value))
+	}
+	if bech32Polymod(append(bech32ExpandHRP(hrp), values...)) != 1 {
+		return "", nil, errBech32BadChecksum
+	}
+	return hrp, values, nil
+}
+
+// verifyHRP returns nil if [hrp] is a non-empty human-readable part
+func verifyHRP(hrp string) error {
+	if hrp == "" {
+		return errBech32EmptyHRP
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return errBech32InvalidHRP
+		}
+	}
+	return nil
+}
+
+// bech32Checksum returns the checksum of [values] under the human-readable part
+// [hrp]
+func bech32Checksum(hrp string, values []byte) []byte {
+	checked := append(bech32ExpandHRP(hrp), values...)
+	checked = append(checked, make([]byte, bech32ChecksumSize)...)
+	mod := bech32Polymod(checked) ^ 1
+
+	checksum := make([]byte, bech32ChecksumSize)
+	for i := range checksum {
+		checksum[i] = byte(mod>>uint(5*(bech32ChecksumSize-1-i))) & 31
+	}
+	return checksum
+}
+
+// bech32ExpandHRP returns the values that [hrp] contributes to the checksum
+func bech32ExpandHRP(hrp string) []byte {
+	expanded := make([]byte, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded[i] = hrp[i] >> 5
+		expanded[len(hrp)+1+i] = hrp[i] & 31
+	}
+	return expanded
+}
+
+// bech32Polymod returns the remainder of [values] divided by the generator of
+// the checksum's BCH code
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, value := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(value)
+		for i, generator := range bech32Generators {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator
+			}
+		}
+	}
+	return chk
+}
+
+// convertBits regroups [data], which is in groups of [from] bits, into groups
+// of [to] bits. The last group is padded with zeros.
+func convertBits(data []byte, from, to uint) []byte {
+	converted := []byte(nil)
+	acc, bits := uint32(0), uint(0)
+	maxValue := uint32(1)<<to - 1
+	for _, value := range data {
+		acc = acc<<from | uint32(value)
+		bits += from
+		for bits >= to {
+			bits -= to
+			converted = append(converted, byte(acc>>bits&maxValue))
+		}
+	}
+	if bits > 0 {
+		converted = append(converted, byte(acc<<(to-bits)&maxValue))
+	}
+	return converted
+}
+
+// unconvertBits regroups the 5 bit [values] into bytes. The values must have
+// been padded with fewer than 5 zero bits.
+func unconvertBits(values []byte) ([]byte, error) {
+	data := []byte(nil)
+	acc, bits := uint32(0), uint(0)
+	for _, value := range values {
+		acc = acc<<5 | uint32(value)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return nil, errBech32BadPadding
+	}
+	return data, nil
+}
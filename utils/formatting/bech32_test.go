@@ -0,0 +1,106 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package formatting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Test vectors from BIP 173
+func TestBech32Verify(t *testing.T) {
+	valid := []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+		"?1ezyfcl",
+	}
+	for _, str := range valid {
+		if err := Bech32Verify(str); err != nil {
+			t.Fatalf("%s should be valid but failed with %s", str, err)
+		}
+	}
+
+	invalid := map[string]error{
+		"\x201nwldj5": errBech32InvalidHRP,
+		"\x7f1axkwrx": errBech32InvalidHRP,
+		"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx": errBech32Length,
+		"pzry9x0s0muk":  errBech32NoSeparator,
+		"1pzry9x0s0muk": errBech32EmptyHRP,
+		"x1b4n0q5v":     errBech32InvalidChar,
+		"li1dgmt3":      errBech32NoChecksum,
+		"A1G7SGD8":      errBech32BadChecksum,
+		"10a06t8":       errBech32EmptyHRP,
+		"1qzzfhee":      errBech32EmptyHRP,
+		"a12UEL5L":      errBech32MixedCase,
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx": errBech32BadChecksum,
+	}
+	for str, expected := range invalid {
+		if err := Bech32Verify(str); err != expected {
+			t.Fatalf("%q should have failed with %s but failed with %v", str, expected, err)
+		}
+	}
+}
+
+func TestBech32EncodeDecode(t *testing.T) {
+	data := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 255}
+	str, err := Bech32Encode("ava", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(str, "ava1") {
+		t.Fatalf("%s should start with the human-readable part and separator", str)
+	}
+
+	hrp, decoded, err := Bech32Decode(str)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case hrp != "ava":
+		t.Fatalf("decoded human-readable part %s", hrp)
+	case !bytes.Equal(decoded, data):
+		t.Fatalf("expected 0x%x but decoded 0x%x", data, decoded)
+	}
+
+	if _, decoded, err := Bech32Decode(strings.ToUpper(str)); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(decoded, data) {
+		t.Fatalf("expected 0x%x but decoded 0x%x from upper case", data, decoded)
+	}
+
+	// Changing any character should be detected
+	for i := len("ava1"); i < len(str); i++ {
+		changed := []byte(str)
+		if changed[i] == 'q' {
+			changed[i] = 'p'
+		} else {
+			changed[i] = 'q'
+		}
+		if _, _, err := Bech32Decode(string(changed)); err != errBech32BadChecksum {
+			t.Fatalf("changing character %d of %s should have failed the checksum but failed with %v", i, str, err)
+		}
+	}
+
+	if _, err := Bech32Encode("", data); err != errBech32EmptyHRP {
+		t.Fatalf("should have failed with %s but failed with %v", errBech32EmptyHRP, err)
+	}
+	if _, err := Bech32Encode("ava", make([]byte, 64)); err != errBech32Length {
+		t.Fatalf("should have failed with %s but failed with %v", errBech32Length, err)
+	}
+
+	// A single value is 5 bits, which isn't a whole byte
+	unpadded := "ava1"
+	for _, value := range append([]byte{31}, bech32Checksum("ava", []byte{31})...) {
+		unpadded += string(bech32Charset[value])
+	}
+	if err := Bech32Verify(unpadded); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Bech32Decode(unpadded); err != errBech32BadPadding {
+		t.Fatalf("should have failed with %s but failed with %v", errBech32BadPadding, err)
+	}
+}
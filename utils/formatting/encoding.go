@@ -0,0 +1,104 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package formatting
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Encoding is a way of formatting bytes as a string. APIs that return
+// serialized bytes take an optional encoding, which defaults to CB58 when it's
+// empty.
+type Encoding string
+
+const (
+	// CB58Encoding is checksummed base-58
+	CB58Encoding Encoding = "cb58"
+
+	// HexEncoding is base-16 with a "0x" prefix. The prefix is optional when
+	// decoding.
+	HexEncoding Encoding = "hex"
+
+	// Bech32Encoding is bech32, as described by BIP 173. Bech32 strings are at
+	// most 90 characters, including the human-readable part, so it can only
+	// encode short values, such as addresses.
+	Bech32Encoding Encoding = "bech32"
+
+	hexPrefix = "0x"
+)
+
+var errNoHRP = errors.New("bech32 encoding requires a human-readable part")
+
+// ParseEncoding returns the encoding named [name], ignoring case. The empty
+// name is CB58.
+func ParseEncoding(name string) (Encoding, error) {
+	enc := Encoding(name).normalize()
+	switch enc {
+	case CB58Encoding, HexEncoding, Bech32Encoding:
+		return enc, nil
+	default:
+		return "", fmt.Errorf("unknown encoding %q. Should be %q, %q or %q", name, CB58Encoding, HexEncoding, Bech32Encoding)
+	}
+}
+
+// normalize returns the encoding in lower case, and CB58 if it's empty
+func (enc Encoding) normalize() Encoding {
+	if enc == "" {
+		return CB58Encoding
+	}
+	return Encoding(strings.ToLower(string(enc)))
+}
+
+// Valid returns nil if the encoding is known
+func (enc Encoding) Valid() error {
+	_, err := ParseEncoding(string(enc))
+	return err
+}
+
+// Encode returns [b] in this encoding. Bech32 requires a human-readable part,
+// so it can't be encoded by Encode. Use EncodeWithHRP instead.
+func (enc Encoding) Encode(b []byte) (string, error) { return enc.EncodeWithHRP("", b) }
+
+// EncodeWithHRP returns [b] in this encoding. [hrp] is the human-readable part
+// of a bech32 string, and is ignored by the other encodings.
+func (enc Encoding) EncodeWithHRP(hrp string, b []byte) (string, error) {
+	enc, err := ParseEncoding(string(enc))
+	if err != nil {
+		return "", err
+	}
+	switch enc {
+	case HexEncoding:
+		return hexPrefix + hex.EncodeToString(b), nil
+	case Bech32Encoding:
+		if hrp == "" {
+			return "", errNoHRP
+		}
+		return Bech32Encode(hrp, b)
+	default:
+		return CB58{Bytes: b}.String(), nil
+	}
+}
+
+// Decode returns the bytes that [str], in this encoding, encodes. The
+// human-readable part of a bech32 string is ignored.
+func (enc Encoding) Decode(str string) ([]byte, error) {
+	enc, err := ParseEncoding(string(enc))
+	if err != nil {
+		return nil, err
+	}
+	switch enc {
+	case HexEncoding:
+		return hex.DecodeString(strings.TrimPrefix(str, hexPrefix))
+	case Bech32Encoding:
+		_, b, err := Bech32Decode(str)
+		return b, err
+	default:
+		cb58 := CB58{}
+		err := cb58.FromString(str)
+		return cb58.Bytes, err
+	}
+}
@@ -0,0 +1,77 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package formatting
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	b := []byte{0, 1, 2, 3, 0xff}
+	tests := []struct {
+		enc     Encoding
+		encoded string
+	}{
+		{"", CB58{Bytes: b}.String()},
+		{CB58Encoding, CB58{Bytes: b}.String()},
+		{"HEX", "0x00010203ff"},
+	}
+	for _, test := range tests {
+		encoded, err := test.enc.Encode(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded != test.encoded {
+			t.Fatalf("%q encoded %x as %s but expected %s", test.enc, b, encoded, test.encoded)
+		}
+		decoded, err := test.enc.Decode(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, b) {
+			t.Fatalf("%q decoded %s as %x but expected %x", test.enc, encoded, decoded, b)
+		}
+	}
+
+	if decoded, err := HexEncoding.Decode("00010203ff"); err != nil || !bytes.Equal(decoded, b) {
+		t.Fatalf("hex should have been decoded without its prefix")
+	}
+}
+
+func TestEncodingBech32(t *testing.T) {
+	b := []byte{0, 1, 2, 3}
+	if _, err := Bech32Encoding.Encode(b); err == nil {
+		t.Fatalf("bech32 shouldn't have been encoded without a human-readable part")
+	}
+	encoded, err := Bech32Encoding.EncodeWithHRP("avax", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Bech32Encoding.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, b) {
+		t.Fatalf("decoded %s as %x but expected %x", encoded, decoded, b)
+	}
+	if _, err := Bech32Encoding.EncodeWithHRP("avax", make([]byte, 1024)); err == nil {
+		t.Fatalf("bech32 shouldn't have encoded a long value")
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	if enc, err := ParseEncoding(""); err != nil || enc != CB58Encoding {
+		t.Fatalf("the empty encoding should have been CB58")
+	}
+	if enc, err := ParseEncoding("Bech32"); err != nil || enc != Bech32Encoding {
+		t.Fatalf("encodings should have been parsed regardless of case")
+	}
+	if err := Encoding("base64").Valid(); err == nil {
+		t.Fatalf("base64 isn't a known encoding")
+	}
+	if _, err := Encoding("base64").Decode("AA=="); err == nil {
+		t.Fatalf("base64 shouldn't have been decoded")
+	}
+}
@@ -0,0 +1,106 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package hashing
+
+import (
+	"fmt"
+)
+
+// Prefixes that separate the hashes of leaves from the hashes of inner nodes,
+// so that an inner node can't be passed off as a leaf
+const (
+	merkleLeafPrefix byte = iota
+	merkleNodePrefix
+)
+
+// MerkleRoot returns the root of the Merkle tree whose leaves are [leaves], in
+// order. The root of a tree without leaves is the zero hash. A node without a
+// sibling is moved up the tree unchanged rather than hashed with itself.
+func MerkleRoot(leaves []Hash256) Hash256 {
+	if len(leaves) == 0 {
+		return Hash256{}
+	}
+	level := make([]Hash256, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = merkleLeaf(leaf)
+	}
+	for len(level) > 1 {
+		level = merkleParents(level)
+	}
+	return level[0]
+}
+
+// MerkleProof returns the siblings of the nodes on the path from the leaf at
+// [index] to the root, starting with the leaf's sibling. Together with the
+// leaf and its index, they let the root be recomputed without the other
+// leaves.
+func MerkleProof(leaves []Hash256, index int) ([]Hash256, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf index %d is out of range [0, %d)", index, len(leaves))
+	}
+	level := make([]Hash256, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = merkleLeaf(leaf)
+	}
+	path := []Hash256{}
+	for len(level) > 1 {
+		if sibling := index ^ 1; sibling < len(level) {
+			path = append(path, level[sibling])
+		}
+		level = merkleParents(level)
+		index /= 2
+	}
+	return path, nil
+}
+
+// VerifyMerkleProof returns true if [path], returned by MerkleProof, proves
+// that [leaf] is the leaf at [index] of the tree with [numLeaves] leaves and
+// root [root]
+func VerifyMerkleProof(root, leaf Hash256, index, numLeaves int, path []Hash256) bool {
+	if index < 0 || index >= numLeaves {
+		return false
+	}
+	node := merkleLeaf(leaf)
+	for width := numLeaves; width > 1; width = (width + 1) / 2 {
+		if sibling := index ^ 1; sibling < width {
+			if len(path) == 0 {
+				return false
+			}
+			if index%2 == 0 {
+				node = merkleNode(node, path[0])
+			} else {
+				node = merkleNode(path[0], node)
+			}
+			path = path[1:]
+		}
+		index /= 2
+	}
+	return len(path) == 0 && node == root
+}
+
+func merkleParents(level []Hash256) []Hash256 {
+	parents := make([]Hash256, 0, (len(level)+1)/2)
+	for i := 0; i+1 < len(level); i += 2 {
+		parents = append(parents, merkleNode(level[i], level[i+1]))
+	}
+	if len(level)%2 == 1 {
+		parents = append(parents, level[len(level)-1])
+	}
+	return parents
+}
+
+func merkleLeaf(leaf Hash256) Hash256 {
+	buf := make([]byte, 1+HashLen)
+	buf[0] = merkleLeafPrefix
+	copy(buf[1:], leaf[:])
+	return ComputeHash256Array(buf)
+}
+
+func merkleNode(left, right Hash256) Hash256 {
+	buf := make([]byte, 1+2*HashLen)
+	buf[0] = merkleNodePrefix
+	copy(buf[1:], left[:])
+	copy(buf[1+HashLen:], right[:])
+	return ComputeHash256Array(buf)
+}
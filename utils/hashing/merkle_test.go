@@ -0,0 +1,44 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package hashing
+
+import (
+	"testing"
+)
+
+func TestMerkleRootEmpty(t *testing.T) {
+	if root := MerkleRoot(nil); root != (Hash256{}) {
+		t.Fatalf("root of an empty tree should be the zero hash")
+	}
+}
+
+func TestMerkleProof(t *testing.T) {
+	for numLeaves := 1; numLeaves <= 9; numLeaves++ {
+		leaves := make([]Hash256, numLeaves)
+		for i := range leaves {
+			leaves[i] = ComputeHash256Array([]byte{byte(i)})
+		}
+		root := MerkleRoot(leaves)
+
+		for index, leaf := range leaves {
+			path, err := MerkleProof(leaves, index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyMerkleProof(root, leaf, index, numLeaves, path) {
+				t.Fatalf("proof of leaf %d of %d should be valid", index, numLeaves)
+			}
+			if numLeaves > 1 && VerifyMerkleProof(root, leaf, (index+1)%numLeaves, numLeaves, path) {
+				t.Fatalf("proof of leaf %d of %d shouldn't be valid at another index", index, numLeaves)
+			}
+			if VerifyMerkleProof(root, ComputeHash256Array([]byte{byte(numLeaves)}), index, numLeaves, path) {
+				t.Fatalf("proof of leaf %d of %d shouldn't be valid for another leaf", index, numLeaves)
+			}
+		}
+	}
+
+	if _, err := MerkleProof([]Hash256{{}}, 1); err == nil {
+		t.Fatalf("should have failed to prove a leaf that isn't in the tree")
+	}
+}
@@ -31,7 +31,7 @@ const (
 	pingPeriod = (pongWait * 9) / 10
 
 	// Maximum message size allowed from peer.
-	maxMessageSize = 512 // bytes
+	maxMessageSize = 64 * 1024 // bytes
 
 	// Maximum number of pending messages to send to a peer.
 	maxPendingMessages = 256 // messages
@@ -45,15 +45,38 @@ var upgrader = websocket.Upgrader{
 
 var (
 	errDuplicateChannel = errors.New("duplicate channel")
+	errUnknownChannel   = errors.New("unknown channel")
 )
 
 // PubSubServer maintains the set of active clients and sends messages to the clients.
+//
+// A client may subscribe to a channel with a filter, which is a set of keys. It
+// is then only sent the messages that are published to the channel with at
+// least one of those keys.
 type PubSubServer struct {
 	ctx *snow.Context
 
 	lock     sync.Mutex
 	conns    map[*Connection]map[string]struct{}
-	channels map[string]map[*Connection]struct{}
+	channels map[string]map[*Connection]filter
+}
+
+// filter is the set of keys a connection subscribed to a channel with. A nil
+// filter matches every message.
+type filter map[string]struct{}
+
+// matches returns true if a message published with [keys] should be sent to a
+// connection that subscribed with this filter
+func (f filter) matches(keys []string) bool {
+	if f == nil {
+		return true
+	}
+	for _, key := range keys {
+		if _, ok := f[key]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 // NewPubSubServer ...
@@ -61,7 +84,7 @@ func NewPubSubServer(ctx *snow.Context) *PubSubServer {
 	return &PubSubServer{
 		ctx:      ctx,
 		conns:    make(map[*Connection]map[string]struct{}),
-		channels: make(map[string]map[*Connection]struct{}),
+		channels: make(map[string]map[*Connection]filter),
 	}
 }
 
@@ -75,8 +98,32 @@ func (s *PubSubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.addConnection(conn)
 }
 
-// Publish ...
+// NewConnection returns a connection whose messages are sent to [send] instead
+// of to a websocket of its own, so clients of other transports can subscribe
+// to channels. The connection must be closed once it's no longer used.
+func (s *PubSubServer) NewConnection(send chan interface{}) *Connection {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	conn := &Connection{s: s, send: send}
+	s.conns[conn] = make(map[string]struct{})
+	return conn
+}
+
+// Publish [msg] to every connection subscribed to [channel], regardless of the
+// filters they subscribed with
 func (s *PubSubServer) Publish(channel string, msg interface{}) {
+	s.publish(channel, msg, nil, false)
+}
+
+// PublishFiltered publishes [msg], which is described by [keys], to the
+// connections subscribed to [channel] without a filter or with a filter that
+// contains one of [keys]
+func (s *PubSubServer) PublishFiltered(channel string, msg interface{}, keys []string) {
+	s.publish(channel, msg, keys, true)
+}
+
+func (s *PubSubServer) publish(channel string, msg interface{}, keys []string, filtered bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -91,7 +138,10 @@ func (s *PubSubServer) Publish(channel string, msg interface{}) {
 		Value:   msg,
 	}
 
-	for conn := range conns {
+	for conn, f := range conns {
+		if filtered && !f.matches(keys) {
+			continue
+		}
 		select {
 		case conn.send <- pubMsg:
 		default:
@@ -109,7 +159,7 @@ func (s *PubSubServer) Register(channel string) error {
 		return errDuplicateChannel
 	}
 
-	s.channels[channel] = make(map[*Connection]struct{})
+	s.channels[channel] = make(map[*Connection]filter)
 	return nil
 }
 
@@ -135,9 +185,13 @@ func (s *PubSubServer) removeConnection(conn *Connection) {
 	for channel := range channels {
 		delete(s.channels[channel], conn)
 	}
+	delete(s.conns, conn)
 }
 
-func (s *PubSubServer) addChannel(conn *Connection, channel string) {
+// addChannel subscribes [conn] to [channel]. If [keys] is empty, the
+// subscription isn't filtered. Subscribing to a channel again replaces the
+// filter.
+func (s *PubSubServer) addChannel(conn *Connection, channel string, keys []string) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -151,8 +205,16 @@ func (s *PubSubServer) addChannel(conn *Connection, channel string) {
 		return
 	}
 
+	var f filter
+	if len(keys) != 0 {
+		f = make(filter, len(keys))
+		for _, key := range keys {
+			f[key] = struct{}{}
+		}
+	}
+
 	channels[channel] = struct{}{}
-	conns[conn] = struct{}{}
+	conns[conn] = f
 }
 
 func (s *PubSubServer) removeChannel(conn *Connection, channel string) {
@@ -179,21 +241,44 @@ type publish struct {
 }
 
 type subscribe struct {
-	Channel     string `json:"channel"`
-	Unsubscribe bool   `json:"unsubscribe"`
+	Channel     string   `json:"channel"`
+	Unsubscribe bool     `json:"unsubscribe"`
+	Filters     []string `json:"filters"`
 }
 
 // Connection is a representation of the websocket connection.
 type Connection struct {
 	s *PubSubServer
 
-	// The websocket connection.
+	// The websocket connection. Nil if the connection was created by
+	// NewConnection.
 	conn *websocket.Conn
 
 	// Buffered channel of outbound messages.
 	send chan interface{}
 }
 
+// Subscribe the connection to [channel]. If [filters] is empty, the
+// subscription isn't filtered. Subscribing to a channel again replaces the
+// filter.
+func (c *Connection) Subscribe(channel string, filters []string) error {
+	c.s.lock.Lock()
+	_, exists := c.s.channels[channel]
+	c.s.lock.Unlock()
+
+	if !exists {
+		return errUnknownChannel
+	}
+	c.s.addChannel(c, channel, filters)
+	return nil
+}
+
+// Unsubscribe the connection from [channel]
+func (c *Connection) Unsubscribe(channel string) { c.s.removeChannel(c, channel) }
+
+// Close unsubscribes the connection from every channel
+func (c *Connection) Close() { c.s.removeConnection(c) }
+
 // readPump pumps messages from the websocket connection to the hub.
 //
 // The application runs readPump in a per-connection goroutine. The application
@@ -221,7 +306,7 @@ func (c *Connection) readPump() {
 		if msg.Unsubscribe {
 			c.s.removeChannel(c, msg.Channel)
 		} else {
-			c.s.addChannel(c, msg.Channel)
+			c.s.addChannel(c, msg.Channel, msg.Filters)
 		}
 	}
 }
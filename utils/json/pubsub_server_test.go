@@ -0,0 +1,51 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/snow"
+)
+
+func TestPubSubServerFilters(t *testing.T) {
+	s := NewPubSubServer(snow.DefaultContextTest())
+	if err := s.Register("channel"); err != nil {
+		t.Fatal(err)
+	}
+
+	filtered := &Connection{s: s, send: make(chan interface{}, maxPendingMessages)}
+	unfiltered := &Connection{s: s, send: make(chan interface{}, maxPendingMessages)}
+	s.conns[filtered] = make(map[string]struct{})
+	s.conns[unfiltered] = make(map[string]struct{})
+	s.addChannel(filtered, "channel", []string{"a", "b"})
+	s.addChannel(unfiltered, "channel", nil)
+
+	s.PublishFiltered("channel", 0, []string{"c"})
+	s.PublishFiltered("channel", 1, []string{"b", "c"})
+	s.Publish("channel", 2)
+
+	expectMsgs(t, filtered, 1, 2)
+	expectMsgs(t, unfiltered, 0, 1, 2)
+
+	// Subscribing again replaces the filter
+	s.addChannel(filtered, "channel", []string{"c"})
+	s.PublishFiltered("channel", 3, []string{"a"})
+	s.PublishFiltered("channel", 4, []string{"c"})
+	expectMsgs(t, filtered, 4)
+}
+
+func expectMsgs(t *testing.T, conn *Connection, values ...int) {
+	t.Helper()
+
+	if len(conn.send) != len(values) {
+		t.Fatalf("expected %d messages but got %d", len(values), len(conn.send))
+	}
+	for _, value := range values {
+		msg := (<-conn.send).(*publish)
+		if msg.Value != value {
+			t.Fatalf("expected message %d but got %v", value, msg.Value)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// openRPCVersion is the version of the OpenRPC specification that documents
+// follow
+const openRPCVersion = "1.2.6"
+
+var (
+	typeOfError     = reflect.TypeOf((*error)(nil)).Elem()
+	typeOfRequest   = reflect.TypeOf((*http.Request)(nil))
+	typeOfMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeOfRaw       = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// Document is an OpenRPC document describing the methods of a JSON-RPC server,
+// from which clients can be generated
+type Document struct {
+	OpenRPC string   `json:"openrpc"`
+	Info    Info     `json:"info"`
+	Methods []Method `json:"methods"`
+}
+
+// Info describes the server a Document is about
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Method describes a JSON-RPC method. Its params are the fields of its
+// arguments, given by name.
+type Method struct {
+	Name           string              `json:"name"`
+	ParamStructure string              `json:"paramStructure"`
+	Params         []ContentDescriptor `json:"params"`
+	Result         ContentDescriptor   `json:"result"`
+}
+
+// ContentDescriptor describes a param or result of a method
+type ContentDescriptor struct {
+	Name   string  `json:"name"`
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the JSON schema of a value. An empty schema matches any value.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// describe the methods of [services], following the rules gorilla/rpc uses to
+// register methods. Methods are named as this package's codec expects them to
+// be called, with the first letter of the method lowercase.
+func describe(title string, services map[string]interface{}) *Document {
+	doc := &Document{
+		OpenRPC: openRPCVersion,
+		Info:    Info{Title: title, Version: "1.0.0"},
+		Methods: []Method{},
+	}
+	for name, receiver := range services {
+		receiverType := reflect.TypeOf(receiver)
+		for i := 0; i < receiverType.NumMethod(); i++ {
+			method := receiverType.Method(i)
+			if !isRPCMethod(method) {
+				continue
+			}
+			argsType := method.Type.In(2).Elem()
+			replyType := method.Type.In(3).Elem()
+
+			params := []ContentDescriptor{}
+			if args := schemaOf(argsType, map[reflect.Type]bool{}); args.Properties != nil {
+				for _, param := range sortedKeys(args.Properties) {
+					params = append(params, ContentDescriptor{Name: param, Schema: args.Properties[param]})
+				}
+			}
+			doc.Methods = append(doc.Methods, Method{
+				Name:           name + "." + lowercaseFirst(method.Name),
+				ParamStructure: "by-name",
+				Params:         params,
+				Result: ContentDescriptor{
+					Name:   replyType.Name(),
+					Schema: schemaOf(replyType, map[reflect.Type]bool{}),
+				},
+			})
+		}
+	}
+	sort.Slice(doc.Methods, func(i, j int) bool { return doc.Methods[i].Name < doc.Methods[j].Name })
+	return doc
+}
+
+// isRPCMethod returns true if gorilla/rpc registers [method]. That is, if it's
+// exported and of the form
+// func (receiver) Method(*http.Request, *Args, *Reply) error
+func isRPCMethod(method reflect.Method) bool {
+	methodType := method.Type
+	return method.PkgPath == "" &&
+		methodType.NumIn() == 4 &&
+		methodType.In(1) == typeOfRequest &&
+		methodType.In(2).Kind() == reflect.Ptr &&
+		methodType.In(3).Kind() == reflect.Ptr &&
+		methodType.NumOut() == 1 &&
+		methodType.Out(0) == typeOfError
+}
+
+// schemaOf returns the schema of the JSON encoding of values of type [t].
+// Types that implement json.Marshaler are assumed to be encoded as strings,
+// which holds for the IDs, addresses and numbers in this repo. [visiting] is
+// the struct types being described, so recursive types terminate.
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	switch {
+	case t == typeOfRaw:
+		return &Schema{}
+	case t.Implements(typeOfMarshaler) || reflect.PtrTo(t).Implements(typeOfMarshaler):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem(), visiting)
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are encoded in base64
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), visiting)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{Type: "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		addFields(schema, t, visiting)
+		return schema
+	default:
+		// Interfaces can hold any value
+		return &Schema{}
+	}
+}
+
+// addFields adds the properties that the fields of the struct type [t] are
+// encoded as to [schema]. The fields of embedded structs are promoted.
+func addFields(schema *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct &&
+			!fieldType.Implements(typeOfMarshaler) && !reflect.PtrTo(fieldType).Implements(typeOfMarshaler) {
+			addFields(schema, fieldType, visiting)
+			continue
+		}
+		if field.PkgPath != "" {
+			// Unexported
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = schemaOf(field.Type, visiting)
+	}
+}
+
+// serviceName returns the name gorilla/rpc gives a service registered without
+// a name
+func serviceName(receiver interface{}) string {
+	return reflect.Indirect(reflect.ValueOf(receiver)).Type().Name()
+}
+
+func lowercaseFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,178 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/rpc/v2"
+	"github.com/gorilla/rpc/v2/json2"
+)
+
+// maxBatchSize is the most calls a batch may contain
+const maxBatchSize = 100
+
+// batchResponse is the response to a call in a batch that couldn't be served
+type batchResponse struct {
+	Version string          `json:"jsonrpc"`
+	Error   *json2.Error    `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Server is a JSON-RPC server that remembers the services registered with it,
+// so that their methods can be described by Describe. Besides single calls, it
+// serves batches of calls.
+type Server struct {
+	*rpc.Server
+
+	lock sync.Mutex
+	// Key: Name of the service
+	// Value: The service's receiver
+	services map[string]interface{}
+}
+
+// NewServer returns a new JSON-RPC server
+func NewServer() *Server {
+	return &Server{
+		Server:   rpc.NewServer(),
+		services: make(map[string]interface{}),
+	}
+}
+
+// RegisterService registers the methods of [receiver] as the service [name].
+// If [name] is empty, the name of [receiver]'s type is used.
+func (s *Server) RegisterService(receiver interface{}, name string) error {
+	if err := s.Server.RegisterService(receiver, name); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if name == "" {
+		name = serviceName(receiver)
+	}
+	s.services[name] = receiver
+	return nil
+}
+
+// Describe returns a description of the methods of the registered services,
+// as an OpenRPC document titled [title]
+func (s *Server) Describe(title string) *Document {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return describe(title, s.services)
+}
+
+// ServeHTTP serves the JSON-RPC call in the body of [r]. If the body is an
+// array of calls, each of them is served, in order, and the response is the
+// array of their responses. A call that fails doesn't fail the others.
+// Notifications, calls without an ID, have no response.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Body == nil {
+		s.Server.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		writeBatchError(w, json2.E_PARSE, fmt.Sprintf("couldn't read the request: %s", err))
+		return
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '[' {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		s.Server.ServeHTTP(w, r)
+		return
+	}
+
+	calls := []json.RawMessage{}
+	if err := json.Unmarshal(body, &calls); err != nil {
+		writeBatchError(w, json2.E_PARSE, fmt.Sprintf("couldn't parse the batch: %s", err))
+		return
+	}
+	switch {
+	case len(calls) == 0:
+		writeBatchError(w, json2.E_INVALID_REQ, "batch is empty")
+		return
+	case len(calls) > maxBatchSize:
+		writeBatchError(w, json2.E_INVALID_REQ, fmt.Sprintf("batch has %d calls but may have at most %d", len(calls), maxBatchSize))
+		return
+	}
+
+	responses := make([]json.RawMessage, 0, len(calls))
+	for _, call := range calls {
+		if response := s.serveCall(r, call); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if len(responses) == 0 {
+		// The batch only contained notifications
+		return
+	}
+	// The response was already started, so an error can't be reported
+	_ = json.NewEncoder(w).Encode(responses)
+}
+
+// serveCall serves [call], one of the calls in the batch [r], and returns its
+// response, or nil if it's a notification
+func (s *Server) serveCall(r *http.Request, call json.RawMessage) json.RawMessage {
+	request := r.WithContext(r.Context())
+	request.Body = ioutil.NopCloser(bytes.NewReader(call))
+	request.ContentLength = int64(len(call))
+
+	writer := &responseBuffer{header: make(http.Header)}
+	s.Server.ServeHTTP(writer, request)
+
+	response := bytes.TrimSpace(writer.body.Bytes())
+	switch {
+	case len(response) == 0:
+		return nil
+	case json.Valid(response):
+		return response
+	}
+
+	// The server rejected the call without a JSON-RPC response, such as for
+	// having the wrong Content-Type, so one is made for it
+	id := struct {
+		ID json.RawMessage `json:"id"`
+	}{}
+	if err := json.Unmarshal(call, &id); err != nil || len(id.ID) == 0 {
+		id.ID = json.RawMessage("null")
+	}
+	failure, _ := json.Marshal(&batchResponse{
+		Version: json2.Version,
+		Error:   &json2.Error{Code: json2.E_SERVER, Message: string(response)},
+		ID:      id.ID,
+	})
+	return failure
+}
+
+// writeBatchError writes the response to a batch that couldn't be served
+func writeBatchError(w http.ResponseWriter, code json2.ErrorCode, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	// The response was already started, so an error can't be reported
+	_ = json.NewEncoder(w).Encode(&batchResponse{
+		Version: json2.Version,
+		Error:   &json2.Error{Code: code, Message: message},
+		ID:      json.RawMessage("null"),
+	})
+}
+
+// responseBuffer is an http.ResponseWriter that stores the response body
+type responseBuffer struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *responseBuffer) Header() http.Header         { return w.header }
+func (w *responseBuffer) Write(b []byte) (int, error) { return w.body.Write(b) }
+func (w *responseBuffer) WriteHeader(int)             {}
@@ -0,0 +1,177 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type Node struct {
+	Value    Uint64  `json:"value"`
+	Children []*Node `json:"children"`
+}
+
+type Page struct {
+	Limit Uint32 `json:"limit"`
+}
+
+type ListArgs struct {
+	Page
+	Owner   string          `json:"owner"`
+	Raw     json.RawMessage `json:"raw"`
+	Bytes   []byte          `json:"bytes"`
+	Labels  map[string]bool `json:"labels"`
+	Ignored string          `json:"-"`
+	Untyped interface{}     `json:"untyped"`
+	hidden  string
+}
+
+type ListReply struct {
+	Nodes []Node `json:"nodes"`
+}
+
+type TreeService struct{}
+
+func (s *TreeService) List(_ *http.Request, args *ListArgs, reply *ListReply) error { return nil }
+
+func (s *TreeService) notExported(_ *http.Request, args *ListArgs, reply *ListReply) error {
+	return nil
+}
+
+func (s *TreeService) WrongSignature(args *ListArgs) error { return nil }
+
+func TestDescribe(t *testing.T) {
+	server := NewServer()
+	if err := server.RegisterService(&TreeService{}, "tree"); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterService(&TreeService{}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := server.Describe("/ext/tree")
+	if doc.OpenRPC != openRPCVersion || doc.Info.Title != "/ext/tree" {
+		t.Fatalf("wrong document info: %+v", doc.Info)
+	}
+	if len(doc.Methods) != 2 || doc.Methods[0].Name != "TreeService.list" || doc.Methods[1].Name != "tree.list" {
+		t.Fatalf("should have described the list method of both services but described %+v", doc.Methods)
+	}
+
+	params := map[string]string{}
+	for _, param := range doc.Methods[1].Params {
+		params[param.Name] = param.Schema.Type
+	}
+	expected := map[string]string{
+		"limit":   "string",
+		"owner":   "string",
+		"raw":     "",
+		"bytes":   "string",
+		"labels":  "object",
+		"untyped": "",
+	}
+	if len(params) != len(expected) {
+		t.Fatalf("expected params %v but described %v", expected, params)
+	}
+	for name, typ := range expected {
+		if described, ok := params[name]; !ok || described != typ {
+			t.Fatalf("expected param %q to be of type %q but described %v", name, typ, params)
+		}
+	}
+
+	result := doc.Methods[1].Result
+	nodes := result.Schema.Properties["nodes"]
+	switch {
+	case result.Name != "ListReply":
+		t.Fatalf("wrong result name %q", result.Name)
+	case nodes == nil || nodes.Type != "array" || nodes.Items.Type != "object":
+		t.Fatalf("should have described the nodes as an array of objects")
+	case nodes.Items.Properties["children"].Items.Type != "object" || nodes.Items.Properties["children"].Items.Properties != nil:
+		t.Fatalf("should have stopped describing the recursive type")
+	}
+
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type EchoService struct{}
+
+type EchoArgs struct {
+	Message string `json:"message"`
+}
+
+func (s *EchoService) Echo(_ *http.Request, args *EchoArgs, reply *EchoArgs) error {
+	if args.Message == "" {
+		return errors.New("no message")
+	}
+	reply.Message = args.Message
+	return nil
+}
+
+func TestBatch(t *testing.T) {
+	server := NewServer()
+	server.RegisterCodec(NewCodec(), "application/json")
+	if err := server.RegisterService(&EchoService{}, "echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	serve := func(body string) string {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		writer := httptest.NewRecorder()
+		server.ServeHTTP(writer, request)
+		return writer.Body.String()
+	}
+
+	type response struct {
+		ID     json.RawMessage `json:"id"`
+		Result *EchoArgs       `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	single := response{}
+	if err := json.Unmarshal([]byte(serve(`{"jsonrpc":"2.0","id":1,"method":"echo.echo","params":{"message":"hi"}}`)), &single); err != nil {
+		t.Fatal(err)
+	}
+	if single.Result == nil || single.Result.Message != "hi" {
+		t.Fatalf("should have served a single call")
+	}
+
+	responses := []response{}
+	body := serve(` [
+		{"jsonrpc":"2.0","id":1,"method":"echo.echo","params":{"message":"a"}},
+		{"jsonrpc":"2.0","id":2,"method":"echo.echo","params":{}},
+		{"jsonrpc":"2.0","method":"echo.echo","params":{"message":"notification"}},
+		{"jsonrpc":"2.0","id":"c","method":"echo.echo","params":{"message":"c"}}
+	]`)
+	if err := json.Unmarshal([]byte(body), &responses); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case len(responses) != 3:
+		t.Fatalf("should have responded to each call but the notification: %s", body)
+	case string(responses[0].ID) != "1" || responses[0].Result == nil || responses[0].Result.Message != "a":
+		t.Fatalf("wrong response to the first call: %s", body)
+	case string(responses[1].ID) != "2" || responses[1].Error == nil || responses[1].Error.Message != "no message":
+		t.Fatalf("the second call should have failed: %s", body)
+	case string(responses[2].ID) != `"c"` || responses[2].Result == nil || responses[2].Result.Message != "c":
+		t.Fatalf("the failed call shouldn't have failed the others: %s", body)
+	}
+
+	for _, batch := range []string{"[]", "[1,", "[" + strings.Repeat(`{},`, maxBatchSize) + "{}]"} {
+		if err := json.Unmarshal([]byte(serve(batch)), &single); err != nil {
+			t.Fatal(err)
+		}
+		if single.Error == nil {
+			t.Fatalf("batch %q should have failed", batch)
+		}
+	}
+}
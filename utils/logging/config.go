@@ -19,6 +19,22 @@ type Config struct {
 	DisableLogging, DisableDisplaying, DisableContextualDisplaying, DisableFlushOnWrite, Assertions bool
 	LogLevel, DisplayLevel                                                                          Level
 	Directory, MsgPrefix                                                                            string
+	Format                                                                                          Format
+
+	// CompressRotated gzips each log file once the log rotates away from it
+	CompressRotated bool
+	// MaxDiskUsage is the number of bytes that the log files of a factory's
+	// loggers may use. The oldest rotated files are removed to stay under it.
+	// If 0, there's no limit.
+	MaxDiskUsage int64
+	// MaxAge is how long rotated log files are kept. If 0, they're kept until
+	// they're overwritten.
+	MaxAge time.Duration
+
+	// DuplicateWindow is how long duplicates of a message aren't logged for.
+	// The number of duplicates that weren't logged is logged once the window
+	// ends. If 0, every message is logged.
+	DuplicateWindow time.Duration
 }
 
 // DefaultConfig ...
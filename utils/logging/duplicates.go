@@ -0,0 +1,131 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// duplicate is a message that was logged, whose duplicates aren't logged until
+// its window ends
+type duplicate struct {
+	level  Level
+	caller string
+	msg    string
+	fields []Field
+
+	// end is when the window ends
+	end time.Time
+	// suppressed is the number of duplicates that weren't logged
+	suppressed int
+}
+
+// isDuplicate returns true if the message [msg] is a duplicate of one logged
+// less than its window ago, and counts it as suppressed. Otherwise, the
+// message starts a window of [window] in which its duplicates are suppressed.
+// Assumes the config lock is held.
+func (l *Log) isDuplicate(level Level, caller, msg string, fields []Field, window time.Duration, now time.Time) bool {
+	l.sweepDuplicates(now)
+
+	key := duplicateKey(level, msg, fields)
+	if dup, ok := l.duplicates[key]; ok {
+		dup.suppressed++
+		return true
+	}
+	if l.duplicates == nil {
+		l.duplicates = make(map[string]*duplicate)
+	}
+	end := now.Add(window)
+	l.duplicates[key] = &duplicate{
+		level:  level,
+		caller: caller,
+		msg:    msg,
+		fields: fields,
+		end:    end,
+	}
+	if len(l.duplicates) == 1 || end.Before(l.nextSweep) {
+		l.nextSweep = end
+		l.scheduleSweep(now)
+	}
+	return false
+}
+
+// scheduleSweep sweeps the duplicates once the first of their windows ends, so
+// that their summaries are logged even if nothing else is. Assumes the config
+// lock is held.
+func (l *Log) scheduleSweep(now time.Time) {
+	if len(l.duplicates) == 0 {
+		return
+	}
+	delay := l.nextSweep.Sub(now)
+	if l.sweepTimer == nil {
+		l.sweepTimer = time.AfterFunc(delay, l.sweep)
+		return
+	}
+	l.sweepTimer.Reset(delay)
+}
+
+// sweep the duplicates whose windows have ended, and schedule the next sweep
+func (l *Log) sweep() {
+	l.configLock.Lock()
+	defer l.configLock.Unlock()
+
+	now := l.clock.Time()
+	l.sweepDuplicates(now)
+	l.scheduleSweep(now)
+}
+
+// sweepDuplicates forgets the messages whose windows ended by [now], logging
+// how many duplicates of each were suppressed. Assumes the config lock is held.
+func (l *Log) sweepDuplicates(now time.Time) {
+	if len(l.duplicates) == 0 || now.Before(l.nextSweep) {
+		return
+	}
+	l.nextSweep = time.Time{}
+	for key, dup := range l.duplicates {
+		if now.Before(dup.end) {
+			if l.nextSweep.IsZero() || dup.end.Before(l.nextSweep) {
+				l.nextSweep = dup.end
+			}
+			continue
+		}
+		l.summarizeDuplicate(dup, now)
+		delete(l.duplicates, key)
+	}
+}
+
+// summarizeDuplicates logs how many duplicates of each message were suppressed
+// in its current window, and forgets the messages. Assumes the config lock is
+// held.
+func (l *Log) summarizeDuplicates(now time.Time) {
+	for _, dup := range l.duplicates {
+		l.summarizeDuplicate(dup, now)
+	}
+	l.duplicates = nil
+	if l.sweepTimer != nil {
+		l.sweepTimer.Stop()
+	}
+}
+
+// summarizeDuplicate logs how many duplicates of [dup] were suppressed, if any
+// were. Assumes the config lock is held.
+func (l *Log) summarizeDuplicate(dup *duplicate, now time.Time) {
+	if dup.suppressed == 0 {
+		return
+	}
+	msg := fmt.Sprintf("suppressed %d duplicates of: %s", dup.suppressed, dup.msg)
+	l.write(dup.level, now, dup.caller, msg, dup.fields)
+}
+
+// duplicateKey returns the key that duplicates of the message [msg] share
+func duplicateKey(level Level, msg string, fields []Field) string {
+	key := strings.Builder{}
+	fmt.Fprintf(&key, "%d\x00%s", level, msg)
+	for _, field := range fields {
+		fmt.Fprintf(&key, "\x00%s=%v", field.Key, field.Value)
+	}
+	return key.String()
+}
@@ -0,0 +1,115 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestLog returns a log whose messages are kept in memory, rather than
+// written to a file
+func newTestLog(config Config) *Log {
+	config.LogLevel = Info
+	config.DisableDisplaying = true
+	l := &Log{config: config}
+	l.needsFlush = sync.NewCond(&l.flushLock)
+	l.clock.Set(time.Unix(1600000000, 0))
+	return l
+}
+
+func TestWarnEvery(t *testing.T) {
+	l := newTestLog(Config{})
+
+	for i := 0; i < 5; i++ {
+		l.WarnEvery(time.Second, "peer %d disconnected", 1)
+	}
+	l.WarnEvery(time.Second, "peer %d disconnected", 2)
+	if len(l.messages) != 2 {
+		t.Fatalf("Logged %d messages, but duplicates should have been suppressed: %v", len(l.messages), l.messages)
+	}
+
+	l.clock.Advance(time.Second)
+	l.WarnEvery(time.Second, "peer %d disconnected", 1)
+	if len(l.messages) != 4 {
+		t.Fatalf("Logged %d messages, expected a summary and the message once the window ended: %v", len(l.messages), l.messages)
+	}
+	if !strings.Contains(l.messages[2], "suppressed 4 duplicates of: peer 1 disconnected") {
+		t.Fatalf("Unexpected summary %q", l.messages[2])
+	}
+	if !strings.Contains(l.messages[3], "peer 1 disconnected") {
+		t.Fatalf("Unexpected message %q", l.messages[3])
+	}
+	for _, msg := range l.messages {
+		if strings.Contains(msg, "duplicates of: peer 2") {
+			t.Fatalf("Message without duplicates shouldn't have been summarized")
+		}
+	}
+}
+
+func TestEvery(t *testing.T) {
+	l := newTestLog(Config{})
+	sampled := l.Every(time.Minute).With(Field{Key: "peer", Value: 1})
+
+	sampled.Info("failed to parse message")
+	sampled.Info("failed to parse message")
+	sampled.Error("failed to parse message")
+	l.Info("failed to parse message")
+	if len(l.messages) != 3 {
+		t.Fatalf("Logged %d messages, expected 3: %v", len(l.messages), l.messages)
+	}
+
+	l.Stop()
+	if len(l.messages) != 4 || !strings.Contains(l.messages[3], "suppressed 1 duplicates of: failed to parse message peer=1") {
+		t.Fatalf("Stopping should have logged the summary, but logged %v", l.messages)
+	}
+}
+
+func TestDuplicateWindow(t *testing.T) {
+	l := newTestLog(Config{DuplicateWindow: time.Second})
+
+	l.Info("hello")
+	l.Info("hello")
+	if len(l.messages) != 1 {
+		t.Fatalf("Logged %d messages, but the duplicate should have been suppressed", len(l.messages))
+	}
+	l.WarnEvery(time.Second, "hello")
+	l.WarnEvery(time.Second, "hello")
+	if len(l.messages) != 2 {
+		t.Fatalf("Logged %d messages, but a message at another level isn't a duplicate", len(l.messages))
+	}
+}
+
+func TestDuplicatesSweptWithoutLogging(t *testing.T) {
+	l := newTestLog(Config{})
+	defer l.Stop()
+
+	l.WarnEvery(10*time.Millisecond, "hello")
+	l.WarnEvery(10*time.Millisecond, "hello")
+
+	l.configLock.Lock()
+	l.clock.Advance(10 * time.Millisecond)
+	l.configLock.Unlock()
+
+	// The summary is logged once the window ends, without logging anything
+	// else
+	for i := 0; ; i++ {
+		l.flushLock.Lock()
+		messages := append([]string(nil), l.messages...)
+		l.flushLock.Unlock()
+
+		if len(messages) == 2 {
+			if !strings.Contains(messages[1], "suppressed 1 duplicates of: hello") {
+				t.Fatalf("Unexpected summary %q", messages[1])
+			}
+			return
+		}
+		if i == 100 {
+			t.Fatalf("Should have logged the summary once the window ended, but logged %v", messages)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
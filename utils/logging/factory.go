@@ -4,71 +4,352 @@
 package logging
 
 import (
+	"errors"
 	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ava-labs/gecko/ids"
 )
 
+const (
+	// MainLoggerName is the name of the logger made by Make
+	MainLoggerName = "main"
+
+	// janitorInterval is how often the log files that are too old, or over
+	// the disk usage budget, are removed
+	janitorInterval = time.Minute
+)
+
+var errUnknownLogger = errors.New("no logger has that name")
+
+// LoggerLevels are the levels of the messages that a logger writes to its log
+// file and displays
+type LoggerLevels struct {
+	LogLevel, DisplayLevel Level
+}
+
 // Factory ...
 type Factory interface {
 	Make() (Logger, error)
 	MakeChain(chainID ids.ID, subdir string) (Logger, error)
 	MakeSubdir(subdir string) (Logger, error)
+
+	// Flush writes the messages logged so far by every logger made
+	Flush()
+	// Rotate starts a new log file for every logger made
+	Rotate()
+
+	// SetLogLevel changes the level of the messages written to the log files
+	// of every logger made, and of those made later
+	SetLogLevel(Level)
+	// SetDisplayLevel changes the level of the messages displayed by every
+	// logger made, and by those made later
+	SetDisplayLevel(Level)
+
+	// SetLoggerLevels changes the levels of the loggers named [name], or whose
+	// names start with [name] followed by a slash, and of those made later
+	// with those names.
+	// They keep these levels until SetLogLevel or SetDisplayLevel is called.
+	// The logger made by Make is named MainLoggerName, those made by
+	// MakeSubdir are named their subdirectory, and those made by MakeChain are
+	// named the chain's ID, followed by a slash and their subdirectory if it
+	// isn't empty.
+	SetLoggerLevels(name string, levels LoggerLevels) error
+	// GetLoggerLevels returns the levels of the loggers made, by name
+	GetLoggerLevels() map[string]LoggerLevels
+
+	// DiskUsage returns the number of bytes of the files written by the
+	// loggers made
+	DiskUsage() int64
+
 	Close()
 }
 
 // factory ...
 type factory struct {
-	config Config
+	lock    sync.Mutex
+	config  Config
+	loggers []namedLogger
 
-	loggers []Logger
+	// levels are the levels that were set by name
+	levels map[string]LoggerLevels
+
+	// stopJanitor is closed to stop the janitor, if it's running
+	stopJanitor chan struct{}
+}
+
+type namedLogger struct {
+	name string
+	log  Logger
 }
 
 // NewFactory ...
 func NewFactory(config Config) Factory {
-	return &factory{
+	f := &factory{
 		config: config,
 	}
+	if config.MaxAge > 0 || config.MaxDiskUsage > 0 {
+		f.stopJanitor = make(chan struct{})
+		go f.runJanitor(f.stopJanitor)
+	}
+	return f
 }
 
 // Make ...
 func (f *factory) Make() (Logger, error) {
-	l, err := New(f.config)
-	if err == nil {
-		f.loggers = append(f.loggers, l)
-	}
-	return l, err
+	return f.make(MainLoggerName, f.getConfig(MainLoggerName))
 }
 
 // MakeChain ...
 func (f *factory) MakeChain(chainID ids.ID, subdir string) (Logger, error) {
-	config := f.config
+	name := chainID.String()
+	if subdir != "" {
+		name += "/" + subdir
+	}
+	config := f.getConfig(name)
 	config.MsgPrefix = "SN " + chainID.String()
 	config.Directory = path.Join(config.Directory, "chain", chainID.String(), subdir)
-
-	log, err := New(config)
-	if err == nil {
-		f.loggers = append(f.loggers, log)
-	}
-	return log, err
+	return f.make(name, config)
 }
 
 // MakeSubdir ...
 func (f *factory) MakeSubdir(subdir string) (Logger, error) {
-	config := f.config
+	config := f.getConfig(subdir)
 	config.Directory = path.Join(config.Directory, subdir)
+	return f.make(subdir, config)
+}
 
+// make the logger [name] with [config]
+func (f *factory) make(name string, config Config) (Logger, error) {
 	log, err := New(config)
 	if err == nil {
-		f.loggers = append(f.loggers, log)
+		f.add(name, log)
 	}
 	return log, err
 }
 
+// getConfig returns the config of the new logger [name]
+func (f *factory) getConfig(name string) Config {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	config := f.config
+	levels := f.levelsOf(name)
+	config.LogLevel = levels.LogLevel
+	config.DisplayLevel = levels.DisplayLevel
+	return config
+}
+
+// levelsOf returns the levels of the logger [name], which are the levels set
+// for it or for the closest of the names it's nested in. Assumes the lock is
+// held.
+func (f *factory) levelsOf(name string) LoggerLevels {
+	for {
+		if levels, ok := f.levels[name]; ok {
+			return levels
+		}
+		i := strings.LastIndex(name, "/")
+		if i == -1 {
+			return LoggerLevels{
+				LogLevel:     f.config.LogLevel,
+				DisplayLevel: f.config.DisplayLevel,
+			}
+		}
+		name = name[:i]
+	}
+}
+
+// add [log], named [name], to the loggers made
+func (f *factory) add(name string, log Logger) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.loggers = append(f.loggers, namedLogger{
+		name: name,
+		log:  log,
+	})
+}
+
+// Flush ...
+func (f *factory) Flush() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, logger := range f.loggers {
+		logger.log.Flush()
+	}
+}
+
+// Rotate ...
+func (f *factory) Rotate() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, logger := range f.loggers {
+		logger.log.Rotate()
+	}
+}
+
+// SetLogLevel ...
+func (f *factory) SetLogLevel(level Level) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.config.LogLevel = level
+	f.levels = nil
+	for _, logger := range f.loggers {
+		logger.log.SetLogLevel(level)
+	}
+}
+
+// SetDisplayLevel ...
+func (f *factory) SetDisplayLevel(level Level) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.config.DisplayLevel = level
+	f.levels = nil
+	for _, logger := range f.loggers {
+		logger.log.SetDisplayLevel(level)
+	}
+}
+
+// SetLoggerLevels ...
+func (f *factory) SetLoggerLevels(name string, levels LoggerLevels) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	found := false
+	for _, logger := range f.loggers {
+		if logger.name != name && !strings.HasPrefix(logger.name, name+"/") {
+			continue
+		}
+		found = true
+		logger.log.SetLogLevel(levels.LogLevel)
+		logger.log.SetDisplayLevel(levels.DisplayLevel)
+	}
+	if !found {
+		return errUnknownLogger
+	}
+
+	// The levels replace those set for the loggers nested in [name]
+	if f.levels == nil {
+		f.levels = make(map[string]LoggerLevels)
+	}
+	for nested := range f.levels {
+		if strings.HasPrefix(nested, name+"/") {
+			delete(f.levels, nested)
+		}
+	}
+	f.levels[name] = levels
+	return nil
+}
+
+// GetLoggerLevels ...
+func (f *factory) GetLoggerLevels() map[string]LoggerLevels {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	loggerLevels := make(map[string]LoggerLevels, len(f.loggers))
+	for _, logger := range f.loggers {
+		loggerLevels[logger.name] = f.levelsOf(logger.name)
+	}
+	return loggerLevels
+}
+
+// DiskUsage ...
+func (f *factory) DiskUsage() int64 {
+	usage := int64(0)
+	for _, file := range f.files() {
+		usage += file.size
+	}
+	return usage
+}
+
+// runJanitor cleans up the log files every janitorInterval, until [stop] is
+// closed
+func (f *factory) runJanitor(stop <-chan struct{}) {
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.clean()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// clean removes the rotated log files that are older than the max age, then
+// the oldest rotated log files until the log files fit in the disk usage
+// budget. The files being written to are never removed.
+func (f *factory) clean() {
+	f.lock.Lock()
+	maxAge, maxDiskUsage := f.config.MaxAge, f.config.MaxDiskUsage
+	f.lock.Unlock()
+
+	files := f.files()
+	usage := int64(0)
+	for _, file := range files {
+		usage += file.size
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, file := range files {
+		tooOld := maxAge > 0 && file.modTime.Before(cutoff)
+		overBudget := maxDiskUsage > 0 && usage > maxDiskUsage
+		if file.current || (!tooOld && !overBudget) {
+			continue
+		}
+		if err := file.log.removeRotated(file.path); err != nil {
+			file.log.Warn("failed to remove the log file %s: %s", file.path, err)
+			continue
+		}
+		usage -= file.size
+	}
+}
+
+// files returns the files written by the loggers made
+func (f *factory) files() []logFile {
+	f.lock.Lock()
+	logs := make([]*Log, 0, len(f.loggers))
+	for _, logger := range f.loggers {
+		if log, ok := logger.log.(*Log); ok {
+			logs = append(logs, log)
+		}
+	}
+	f.lock.Unlock()
+
+	files := []logFile(nil)
+	for _, log := range logs {
+		logFiles, err := log.files()
+		if err != nil {
+			log.Warn("failed to list the log files: %s", err)
+			continue
+		}
+		files = append(files, logFiles...)
+	}
+	return files
+}
+
 // Close ...
 func (f *factory) Close() {
-	for _, log := range f.loggers {
-		log.Stop()
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.stopJanitor != nil {
+		close(f.stopJanitor)
+		f.stopJanitor = nil
+	}
+
+	for _, logger := range f.loggers {
+		logger.log.Stop()
 	}
 	f.loggers = nil
 }
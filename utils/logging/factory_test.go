@@ -0,0 +1,213 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func newTestFactory(t *testing.T) (Factory, func()) {
+	return newTestFactoryWith(t, func(*Config) {})
+}
+
+// newTestFactoryWith returns a factory whose config is changed by [configure]
+func newTestFactoryWith(t *testing.T, configure func(*Config)) (Factory, func()) {
+	dir, err := ioutil.TempDir("", "gecko-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := DefaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.DisableDisplaying = true
+	config.LogLevel = Info
+	config.DisplayLevel = Info
+	config.Directory = dir
+	configure(&config)
+	f := NewFactory(config)
+	return f, func() {
+		f.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestFactorySetLoggerLevels(t *testing.T) {
+	f, cleanup := newTestFactory(t)
+	defer cleanup()
+
+	chainID := ids.NewID([32]byte{1})
+	if _, err := f.Make(); err != nil {
+		t.Fatal(err)
+	}
+	chainLog, err := f.MakeChain(chainID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	debug := LoggerLevels{LogLevel: Debug, DisplayLevel: Warn}
+	if err := f.SetLoggerLevels(chainID.String(), debug); err != nil {
+		t.Fatal(err)
+	}
+	if level := chainLog.(*Log).config.LogLevel; level != Debug {
+		t.Fatalf("Chain's logger has log level %s, expected %s", level, Debug)
+	}
+
+	// The chain's API logger is made after its levels were set
+	if _, err := f.MakeChain(chainID, "http"); err != nil {
+		t.Fatal(err)
+	}
+	levels := f.GetLoggerLevels()
+	if got := levels[chainID.String()+"/http"]; got != debug {
+		t.Fatalf("Chain's API logger has levels %v, expected %v", got, debug)
+	}
+	if got := levels[MainLoggerName]; got.LogLevel != Info || got.DisplayLevel != Info {
+		t.Fatalf("Main logger has levels %v, but its levels weren't set", got)
+	}
+
+	f.SetLogLevel(Error)
+	levels = f.GetLoggerLevels()
+	if got := levels[chainID.String()+"/http"]; got.LogLevel != Error || got.DisplayLevel != Info {
+		t.Fatalf("Chain's API logger has levels %v, but SetLogLevel should have cleared the chain's levels", got)
+	}
+}
+
+func TestFactorySetUnknownLoggerLevels(t *testing.T) {
+	f, cleanup := newTestFactory(t)
+	defer cleanup()
+
+	if _, err := f.MakeSubdir("http"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetLoggerLevels("ht", LoggerLevels{}); err == nil {
+		t.Fatalf("Should have errored due to the unknown logger")
+	}
+}
+
+func TestFactoryCompressRotated(t *testing.T) {
+	var dir string
+	f, cleanup := newTestFactoryWith(t, func(config *Config) {
+		config.CompressRotated = true
+		dir = config.Directory
+	})
+	defer cleanup()
+
+	log, err := f.Make()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Info("hello")
+	log.Rotate()
+
+	if _, err := os.Stat(filepath.Join(dir, "0.log")); !os.IsNotExist(err) {
+		t.Fatalf("Rotated log file should have been replaced by its compressed copy")
+	}
+	file, err := os.Open(filepath.Join(dir, "0.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	r, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), "hello") {
+		t.Fatalf("Compressed log file has %q, but should have the logged message", contents)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "1.log")); err != nil {
+		t.Fatalf("Log should be writing to the next file, but: %s", err)
+	}
+}
+
+func TestFactoryCleanMaxAge(t *testing.T) {
+	var dir string
+	f, cleanup := newTestFactoryWith(t, func(config *Config) {
+		config.MaxAge = time.Hour
+		dir = config.Directory
+	})
+	defer cleanup()
+
+	log, err := f.Make()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Rotate()
+	log.Rotate()
+
+	old := time.Now().Add(-2 * time.Hour)
+	for _, name := range []string{"0.log", "2.log"} {
+		if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.(*factory).clean()
+
+	if _, err := os.Stat(filepath.Join(dir, "0.log")); !os.IsNotExist(err) {
+		t.Fatalf("Rotated log file older than the max age should have been removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "1.log")); err != nil {
+		t.Fatalf("Rotated log file younger than the max age should have been kept, but: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2.log")); err != nil {
+		t.Fatalf("Log file being written to should have been kept, but: %s", err)
+	}
+}
+
+func TestFactoryCleanMaxDiskUsage(t *testing.T) {
+	var dir string
+	f, cleanup := newTestFactoryWith(t, func(config *Config) {
+		config.MaxDiskUsage = 1
+		dir = config.Directory
+	})
+	defer cleanup()
+
+	log, err := f.Make()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainLog, err := f.MakeChain(ids.NewID([32]byte{1}), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []Logger{log, chainLog} {
+		l.Info("hello")
+		l.Rotate()
+		l.Info("hello")
+		l.Flush()
+	}
+	if usage := f.DiskUsage(); usage <= 1 {
+		t.Fatalf("Log files use %d bytes, but should be over the budget", usage)
+	}
+
+	f.(*factory).clean()
+	if _, err := os.Stat(filepath.Join(dir, "0.log")); !os.IsNotExist(err) {
+		t.Fatalf("Rotated log file should have been removed to fit the budget")
+	}
+	files := f.(*factory).files()
+	if len(files) != 2 {
+		t.Fatalf("%d log files were kept, but only the 2 being written to should have been", len(files))
+	}
+	usage := int64(0)
+	for _, file := range files {
+		if !file.current {
+			t.Fatalf("Rotated log file %s should have been removed", file.path)
+		}
+		usage += file.size
+	}
+	if got := f.DiskUsage(); got != usage || usage == 0 {
+		t.Fatalf("DiskUsage returned %d, expected %d", got, usage)
+	}
+}
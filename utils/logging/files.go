@@ -0,0 +1,112 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"time"
+)
+
+// compressedExt is the extension of the log files that were compressed once
+// the log rotated away from them
+const compressedExt = ".gz"
+
+// logFileName matches the names of the files that a log writes, whether or not
+// they were compressed
+var logFileName = regexp.MustCompile(`^[0-9]+\.log(\.gz)?$`)
+
+// logFile is a file that a log wrote
+type logFile struct {
+	log     *Log
+	path    string
+	size    int64
+	modTime time.Time
+
+	// current is true if the log is still writing to this file
+	current bool
+}
+
+// logFilePath returns the path of the log file numbered [index] in [dir]
+func logFilePath(dir string, index int) string {
+	return path.Join(dir, fmt.Sprintf("%d.log", index))
+}
+
+// files returns the files that this log wrote to its directory
+func (l *Log) files() ([]logFile, error) {
+	l.writeLock.Lock()
+	defer l.writeLock.Unlock()
+
+	infos, err := ioutil.ReadDir(l.config.Directory)
+	if err != nil {
+		return nil, err
+	}
+	files := []logFile(nil)
+	for _, info := range infos {
+		if info.IsDir() || !logFileName.MatchString(info.Name()) {
+			continue
+		}
+		filePath := path.Join(l.config.Directory, info.Name())
+		files = append(files, logFile{
+			log:     l,
+			path:    filePath,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+			current: filePath == l.current,
+		})
+	}
+	return files, nil
+}
+
+// removeRotated removes the log file [filePath], unless the log rotated back to
+// it and is writing to it again
+func (l *Log) removeRotated(filePath string) error {
+	l.writeLock.Lock()
+	defer l.writeLock.Unlock()
+
+	if filePath == l.current {
+		return nil
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// compressFile replaces the file [filePath] with its gzipped copy. The copy is
+// written to a temporary file first, so a partial copy never replaces it.
+func compressFile(filePath string) error {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpPath := filePath + compressedExt + ".tmp"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(dst)
+	_, err = io.Copy(w, src)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, filePath+compressedExt)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return os.Remove(filePath)
+}
@@ -0,0 +1,127 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Format is how log messages are written
+type Format int
+
+// Enum ...
+const (
+	// Plain messages are lines of text
+	Plain Format = iota
+	// JSON messages are JSON objects, one per line
+	JSON
+)
+
+// ToFormat ...
+func ToFormat(f string) (Format, error) {
+	switch strings.ToUpper(f) {
+	case "PLAIN":
+		return Plain, nil
+	case "JSON":
+		return JSON, nil
+	default:
+		return Plain, fmt.Errorf("unknown log format: %s", f)
+	}
+}
+
+func (f Format) String() string {
+	switch f {
+	case Plain:
+		return "plain"
+	case JSON:
+		return "json"
+	default:
+		return "?????"
+	}
+}
+
+// Field is a key-value pair that's logged with every message of a logger made
+// by With
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// jsonMessage is a message logged in the JSON format
+type jsonMessage struct {
+	Level     string                 `json:"level"`
+	Timestamp time.Time              `json:"timestamp"`
+	Caller    string                 `json:"caller"`
+	Prefix    string                 `json:"prefix,omitempty"`
+	Msg       string                 `json:"msg"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// formatPlain returns the message [msg], logged at [level] and [time] by
+// [caller], as a line of text
+func formatPlain(level Level, time time.Time, caller, prefix, msg string, fields []Field) string {
+	if prefix != "" {
+		prefix = fmt.Sprintf(" <%s>", prefix)
+	}
+	text := strings.Builder{}
+	text.WriteString(msg)
+	for _, field := range fields {
+		fmt.Fprintf(&text, " %s=%v", field.Key, field.Value)
+	}
+	return fmt.Sprintf("%s[%s]%s %s: %s\n",
+		level,
+		time.Format("01-02|15:04:05.000"),
+		prefix,
+		caller,
+		text.String())
+}
+
+// formatJSON returns the message [msg], logged at [level] and [time] by
+// [caller], as a line holding a JSON object
+func formatJSON(level Level, time time.Time, caller, prefix, msg string, fields []Field) string {
+	message := jsonMessage{
+		Level:     strings.TrimSpace(level.String()),
+		Timestamp: time,
+		Caller:    caller,
+		Prefix:    prefix,
+		Msg:       msg,
+	}
+	if len(fields) > 0 {
+		message.Fields = make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			message.Fields[field.Key] = jsonValue(field.Value)
+		}
+	}
+
+	bytes, err := json.Marshal(&message)
+	if err != nil {
+		// A field can't be marshalled, so every field is logged as text
+		for key, value := range message.Fields {
+			message.Fields[key] = fmt.Sprint(value)
+		}
+		bytes, err = json.Marshal(&message)
+	}
+	if err != nil {
+		return fmt.Sprintf("{\"level\":\"ERROR\",\"msg\":%q}\n", err)
+	}
+	return string(bytes) + "\n"
+}
+
+// jsonValue returns [value] as it should be marshalled. Errors and stringers
+// that aren't JSON marshallers are logged as their text.
+func jsonValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Marshaler:
+		return value
+	case error:
+		return value.Error()
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return value
+	}
+}
@@ -0,0 +1,102 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToFormat(t *testing.T) {
+	if format, err := ToFormat("json"); err != nil {
+		t.Fatal(err)
+	} else if format != JSON {
+		t.Fatalf("ToFormat returned %s, expected %s", format, JSON)
+	}
+	if format, err := ToFormat("PLAIN"); err != nil {
+		t.Fatal(err)
+	} else if format != Plain {
+		t.Fatalf("ToFormat returned %s, expected %s", format, Plain)
+	}
+	if _, err := ToFormat("xml"); err == nil {
+		t.Fatalf("Should have errored due to the unknown format")
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	now := time.Unix(1600000000, 0).UTC()
+	output := formatJSON(Warn, now, "snow/engine.go#12", "SN chain", "hello 5", []Field{
+		{Key: "height", Value: 5},
+		{Key: "err", Value: errors.New("oops")},
+	})
+	if !strings.HasSuffix(output, "\n") || strings.Count(output, "\n") != 1 {
+		t.Fatalf("Message should be one line, but is %q", output)
+	}
+
+	message := struct {
+		jsonMessage
+		Fields map[string]interface{} `json:"fields"`
+	}{}
+	if err := json.Unmarshal([]byte(output), &message); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case message.Level != "WARN":
+		t.Fatalf("Logged level %q, expected %q", message.Level, "WARN")
+	case !message.Timestamp.Equal(now):
+		t.Fatalf("Logged timestamp %s, expected %s", message.Timestamp, now)
+	case message.Caller != "snow/engine.go#12":
+		t.Fatalf("Logged caller %q", message.Caller)
+	case message.Prefix != "SN chain":
+		t.Fatalf("Logged prefix %q", message.Prefix)
+	case message.Msg != "hello 5":
+		t.Fatalf("Logged message %q", message.Msg)
+	case message.Fields["height"] != 5.0:
+		t.Fatalf("Logged height %v", message.Fields["height"])
+	case message.Fields["err"] != "oops":
+		t.Fatalf("Logged err %v", message.Fields["err"])
+	}
+}
+
+func TestFormatJSONUnmarshallableField(t *testing.T) {
+	output := formatJSON(Info, time.Now(), "", "", "hello", []Field{
+		{Key: "ch", Value: make(chan int)},
+	})
+	message := jsonMessage{}
+	if err := json.Unmarshal([]byte(output), &message); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := message.Fields["ch"].(string); !ok {
+		t.Fatalf("Field that can't be marshalled should have been logged as text")
+	}
+}
+
+func TestFormatPlain(t *testing.T) {
+	output := formatPlain(Info, time.Now(), "snow/engine.go#12", "SN chain", "hello", []Field{
+		{Key: "height", Value: 5},
+	})
+	if !strings.Contains(output, " <SN chain> snow/engine.go#12: hello height=5\n") {
+		t.Fatalf("Unexpected message %q", output)
+	}
+}
+
+func TestWith(t *testing.T) {
+	log := &Log{}
+	child := log.With(Field{Key: "a", Value: 1})
+	grandchild := child.With(Field{Key: "b", Value: 2})
+	otherChild := child.With(Field{Key: "c", Value: 3})
+
+	if fields := grandchild.(*fieldLog).fields; len(fields) != 2 || fields[0].Key != "a" || fields[1].Key != "b" {
+		t.Fatalf("Grandchild has fields %v", fields)
+	}
+	if fields := otherChild.(*fieldLog).fields; len(fields) != 2 || fields[0].Key != "a" || fields[1].Key != "c" {
+		t.Fatalf("Other child has fields %v", fields)
+	}
+	if fields := child.(*fieldLog).fields; len(fields) != 1 {
+		t.Fatalf("Child has fields %v", fields)
+	}
+}
@@ -7,11 +7,12 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"path"
 	"runtime"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/ava-labs/gecko/utils/timer"
 )
 
 // Log ...
@@ -26,7 +27,26 @@ type Log struct {
 	needsFlush                       *sync.Cond
 	w                                *bufio.Writer
 
+	// current is the path of the file being written to
+	current string
+
+	// Requests to flush, or to rotate, the log file that are waiting on the
+	// pending messages to be written. Each channel is closed once its request
+	// is done.
+	flushes []chan struct{}
+	rotate  bool
+
 	closed bool
+
+	// duplicates are the messages logged whose duplicates are being
+	// suppressed, by key, nextSweep is when the first of their windows ends,
+	// and sweepTimer sweeps them then. They're guarded by the config lock.
+	duplicates map[string]*duplicate
+	nextSweep  time.Time
+	sweepTimer *time.Timer
+
+	// clock decides when the log file is rotated and timestamps messages
+	clock timer.Clock
 }
 
 // New ...
@@ -51,26 +71,29 @@ func (l *Log) run() {
 	defer l.writeLock.Unlock()
 
 	fileIndex := 0
-	filename := path.Join(l.config.Directory, fmt.Sprintf("%d.log", fileIndex))
-	f, err := os.Create(filename)
+	f, err := l.create(fileIndex)
 	if err != nil {
 		panic(err)
 	}
 	l.w = bufio.NewWriter(f)
 
 	closed := false
-	nextRotation := time.Now().Add(l.config.RotationInterval)
+	nextRotation := l.clock.Time().Add(l.config.RotationInterval)
 	currentSize := 0
 	for !closed {
 		l.writeLock.Unlock()
 		l.flushLock.Lock()
-		for l.size < l.config.FlushSize && !l.closed {
+		for l.size < l.config.FlushSize && len(l.flushes) == 0 && !l.closed {
 			l.needsFlush.Wait()
 		}
 		closed = l.closed
 		prevMessages := l.messages
 		l.messages = nil
 		l.size = 0
+		flushes := l.flushes
+		l.flushes = nil
+		rotate := l.rotate
+		l.rotate = false
 		l.flushLock.Unlock()
 		l.writeLock.Lock()
 
@@ -79,29 +102,53 @@ func (l *Log) run() {
 			currentSize += n
 		}
 
-		if !l.config.DisableFlushOnWrite {
+		if !l.config.DisableFlushOnWrite || len(flushes) != 0 {
 			l.w.Flush()
 		}
 
-		if now := time.Now(); nextRotation.Before(now) || currentSize > l.config.FileSize {
+		if now := l.clock.Time(); rotate || nextRotation.Before(now) || currentSize > l.config.FileSize {
 			nextRotation = now.Add(l.config.RotationInterval)
 			currentSize = 0
 			l.w.Flush()
 			f.Close()
 
+			rotated := l.current
 			fileIndex = (fileIndex + 1) % l.config.RotationSize
-			filename := path.Join(l.config.Directory, fmt.Sprintf("%d.log", fileIndex))
-			f, err = os.Create(filename)
+			f, err = l.create(fileIndex)
 			if err != nil {
 				panic(err)
 			}
 			l.w = bufio.NewWriter(f)
+
+			if l.config.CompressRotated && rotated != l.current {
+				if err := compressFile(rotated); err != nil {
+					l.Warn("failed to compress the rotated log file %s: %s", rotated, err)
+				}
+			}
+		}
+
+		for _, flushed := range flushes {
+			close(flushed)
 		}
 	}
 	l.w.Flush()
 	f.Close()
 }
 
+// create the log file numbered [index], replacing the file that was written
+// the last time the log rotated to it. Assumes the write lock is held.
+func (l *Log) create(index int) (*os.File, error) {
+	filePath := logFilePath(l.config.Directory, index)
+	if err := os.Remove(filePath + compressedExt); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := os.Create(filePath)
+	if err == nil {
+		l.current = filePath
+	}
+	return f, err
+}
+
 func (l *Log) Write(p []byte) (int, error) {
 	l.writeLock.Lock()
 	defer l.writeLock.Unlock()
@@ -109,8 +156,37 @@ func (l *Log) Write(p []byte) (int, error) {
 	return l.w.Write(p)
 }
 
+// Flush writes all the messages logged so far to the log file. It returns once
+// they've been written.
+func (l *Log) Flush() { l.request(false) }
+
+// Rotate writes all the messages logged so far to the log file, then starts
+// writing to the next log file. It returns once the new file is created.
+func (l *Log) Rotate() { l.request(true) }
+
+// request that the logged messages be written, rotating the log file after if
+// [rotate] is true, and wait until that's done
+func (l *Log) request(rotate bool) {
+	l.flushLock.Lock()
+	if l.closed {
+		l.flushLock.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	l.flushes = append(l.flushes, done)
+	l.rotate = l.rotate || rotate
+	l.needsFlush.Signal()
+	l.flushLock.Unlock()
+
+	<-done
+}
+
 // Stop ...
 func (l *Log) Stop() {
+	l.configLock.Lock()
+	l.summarizeDuplicates(l.clock.Time())
+	l.configLock.Unlock()
+
 	l.flushLock.Lock()
 	l.closed = true
 	l.needsFlush.Signal()
@@ -119,8 +195,10 @@ func (l *Log) Stop() {
 	l.wg.Wait()
 }
 
-// Should only be called from [Level] functions.
-func (l *Log) log(level Level, format string, args ...interface{}) {
+// Should only be called from [Level] functions. If [window] isn't 0, the
+// message isn't logged if it's a duplicate of one logged less than [window]
+// ago. Otherwise the config's duplicate window is used.
+func (l *Log) log(level Level, fields []Field, window time.Duration, format string, args ...interface{}) {
 	if l == nil {
 		return
 	}
@@ -128,14 +206,45 @@ func (l *Log) log(level Level, format string, args ...interface{}) {
 	l.configLock.Lock()
 	defer l.configLock.Unlock()
 
-	shouldLog := !l.config.DisableLogging && level <= l.config.LogLevel
-	shouldDisplay := (!l.config.DisableDisplaying && level <= l.config.DisplayLevel) || level == Fatal
+	if !l.shouldLog(level) && !l.shouldDisplay(level) {
+		return
+	}
+
+	caller := l.caller()
+	msg := fmt.Sprintf(format, args...)
+	now := l.clock.Time()
+	if window == 0 {
+		window = l.config.DuplicateWindow
+	}
+	if window > 0 && l.isDuplicate(level, caller, msg, fields, window, now) {
+		return
+	}
+	l.write(level, now, caller, msg, fields)
+}
+
+// shouldLog returns true if messages at [level] are written to the log file.
+// Assumes the config lock is held.
+func (l *Log) shouldLog(level Level) bool {
+	return !l.config.DisableLogging && level <= l.config.LogLevel
+}
+
+// shouldDisplay returns true if messages at [level] are displayed. Assumes the
+// config lock is held.
+func (l *Log) shouldDisplay(level Level) bool {
+	return (!l.config.DisableDisplaying && level <= l.config.DisplayLevel) || level == Fatal
+}
 
+// write the message [msg], logged at [level] and [now] by [caller], to the log
+// file and display, if messages at [level] are. Assumes the config lock is
+// held.
+func (l *Log) write(level Level, now time.Time, caller, msg string, fields []Field) {
+	shouldLog := l.shouldLog(level)
+	shouldDisplay := l.shouldDisplay(level)
 	if !shouldLog && !shouldDisplay {
 		return
 	}
 
-	output := l.format(level, format, args...)
+	output := l.format(level, now, caller, msg, fields)
 
 	if shouldLog {
 		l.flushLock.Lock()
@@ -146,15 +255,21 @@ func (l *Log) log(level Level, format string, args ...interface{}) {
 	}
 
 	if shouldDisplay {
-		if l.config.DisableContextualDisplaying {
-			fmt.Println(fmt.Sprintf(format, args...))
-		} else {
+		switch {
+		case l.config.DisableContextualDisplaying:
+			fmt.Println(msg)
+		case l.config.Format == JSON:
+			// Colors would break the JSON
+			fmt.Print(output)
+		default:
 			fmt.Print(level.Color().Wrap(output))
 		}
 	}
 }
 
-func (l *Log) format(level Level, format string, args ...interface{}) string {
+// caller returns the location of the code that logged the message. Should
+// only be called from log.
+func (l *Log) caller() string {
 	loc := "?"
 	if _, file, no, ok := runtime.Caller(3); ok {
 		loc = fmt.Sprintf("%s#%d", file, no)
@@ -162,42 +277,61 @@ func (l *Log) format(level Level, format string, args ...interface{}) string {
 	if i := strings.Index(loc, "gecko/"); i != -1 {
 		loc = loc[i+5:]
 	}
-	text := fmt.Sprintf("%s: %s", loc, fmt.Sprintf(format, args...))
+	return loc
+}
 
-	prefix := ""
-	if l.config.MsgPrefix != "" {
-		prefix = fmt.Sprintf(" <%s>", l.config.MsgPrefix)
+func (l *Log) format(level Level, now time.Time, caller, msg string, fields []Field) string {
+	if l.config.Format == JSON {
+		return formatJSON(level, now, caller, l.config.MsgPrefix, msg, fields)
 	}
+	return formatPlain(level, now, caller, l.config.MsgPrefix, msg, fields)
+}
 
-	return fmt.Sprintf("%s[%s]%s %s\n",
-		level,
-		time.Now().Format("01-02|15:04:05.000"),
-		prefix,
-		text)
+// With returns a logger that logs [fields] with every message, and writes to
+// this log
+func (l *Log) With(fields ...Field) Logger {
+	return &fieldLog{
+		Log:    l,
+		fields: fields,
+	}
 }
 
 // Fatal ...
-func (l *Log) Fatal(format string, args ...interface{}) { l.log(Fatal, format, args...) }
+func (l *Log) Fatal(format string, args ...interface{}) { l.log(Fatal, nil, 0, format, args...) }
 
 // Error ...
-func (l *Log) Error(format string, args ...interface{}) { l.log(Error, format, args...) }
+func (l *Log) Error(format string, args ...interface{}) { l.log(Error, nil, 0, format, args...) }
 
 // Warn ...
-func (l *Log) Warn(format string, args ...interface{}) { l.log(Warn, format, args...) }
+func (l *Log) Warn(format string, args ...interface{}) { l.log(Warn, nil, 0, format, args...) }
 
 // Info ...
-func (l *Log) Info(format string, args ...interface{}) { l.log(Info, format, args...) }
+func (l *Log) Info(format string, args ...interface{}) { l.log(Info, nil, 0, format, args...) }
 
 // Debug ...
-func (l *Log) Debug(format string, args ...interface{}) { l.log(Debug, format, args...) }
+func (l *Log) Debug(format string, args ...interface{}) { l.log(Debug, nil, 0, format, args...) }
 
 // Verbo ...
-func (l *Log) Verbo(format string, args ...interface{}) { l.log(Verbo, format, args...) }
+func (l *Log) Verbo(format string, args ...interface{}) { l.log(Verbo, nil, 0, format, args...) }
+
+// WarnEvery ...
+func (l *Log) WarnEvery(window time.Duration, format string, args ...interface{}) {
+	l.log(Warn, nil, window, format, args...)
+}
+
+// Every returns a logger that doesn't log duplicates of a message logged less
+// than [window] ago, and writes to this log
+func (l *Log) Every(window time.Duration) Logger {
+	return &fieldLog{
+		Log:    l,
+		window: window,
+	}
+}
 
 // AssertNoError ...
 func (l *Log) AssertNoError(err error) {
 	if err != nil {
-		l.log(Fatal, "%s", err)
+		l.log(Fatal, nil, 0, "%s", err)
 	}
 	if l.config.Assertions && err != nil {
 		l.Stop()
@@ -208,7 +342,7 @@ func (l *Log) AssertNoError(err error) {
 // AssertTrue ...
 func (l *Log) AssertTrue(b bool, format string, args ...interface{}) {
 	if !b {
-		l.log(Fatal, format, args...)
+		l.log(Fatal, nil, 0, format, args...)
 	}
 	if l.config.Assertions && !b {
 		l.Stop()
@@ -221,7 +355,7 @@ func (l *Log) AssertDeferredTrue(f func() bool, format string, args ...interface
 	// Note, the logger will only be notified here if assertions are enabled
 	if l.config.Assertions && !f() {
 		err := fmt.Sprintf(format, args...)
-		l.log(Fatal, err)
+		l.log(Fatal, nil, 0, err)
 		l.Stop()
 		panic(err)
 	}
@@ -232,7 +366,7 @@ func (l *Log) AssertDeferredNoError(f func() error) {
 	if l.config.Assertions {
 		err := f()
 		if err != nil {
-			l.log(Fatal, "%s", err)
+			l.log(Fatal, nil, 0, "%s", err)
 		}
 		if l.config.Assertions && err != nil {
 			l.Stop()
@@ -300,3 +434,68 @@ func (l *Log) SetContextualDisplayingEnabled(enabled bool) {
 
 	l.config.DisableContextualDisplaying = !enabled
 }
+
+// fieldLog writes messages, along with its fields, to a log
+type fieldLog struct {
+	*Log
+	fields []Field
+
+	// window is how long duplicates of a message aren't logged for, or 0 to
+	// use the log's duplicate window
+	window time.Duration
+}
+
+// With ...
+func (l *fieldLog) With(fields ...Field) Logger {
+	allFields := make([]Field, 0, len(l.fields)+len(fields))
+	allFields = append(allFields, l.fields...)
+	return &fieldLog{
+		Log:    l.Log,
+		fields: append(allFields, fields...),
+		window: l.window,
+	}
+}
+
+// Every ...
+func (l *fieldLog) Every(window time.Duration) Logger {
+	return &fieldLog{
+		Log:    l.Log,
+		fields: l.fields,
+		window: window,
+	}
+}
+
+// WarnEvery ...
+func (l *fieldLog) WarnEvery(window time.Duration, format string, args ...interface{}) {
+	l.log(Warn, l.fields, window, format, args...)
+}
+
+// Fatal ...
+func (l *fieldLog) Fatal(format string, args ...interface{}) {
+	l.log(Fatal, l.fields, l.window, format, args...)
+}
+
+// Error ...
+func (l *fieldLog) Error(format string, args ...interface{}) {
+	l.log(Error, l.fields, l.window, format, args...)
+}
+
+// Warn ...
+func (l *fieldLog) Warn(format string, args ...interface{}) {
+	l.log(Warn, l.fields, l.window, format, args...)
+}
+
+// Info ...
+func (l *fieldLog) Info(format string, args ...interface{}) {
+	l.log(Info, l.fields, l.window, format, args...)
+}
+
+// Debug ...
+func (l *fieldLog) Debug(format string, args ...interface{}) {
+	l.log(Debug, l.fields, l.window, format, args...)
+}
+
+// Verbo ...
+func (l *fieldLog) Verbo(format string, args ...interface{}) {
+	l.log(Verbo, l.fields, l.window, format, args...)
+}
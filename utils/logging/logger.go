@@ -5,6 +5,7 @@ package logging
 
 import (
 	"io"
+	"time"
 )
 
 // Logger defines the interface that is used to keep a record of all events that
@@ -31,6 +32,18 @@ type Logger interface {
 	// aspect of the program
 	Verbo(format string, args ...interface{})
 
+	// Log a warning, unless a duplicate of it was logged less than [window]
+	// ago. Once the window ends, the number of duplicates that weren't logged
+	// is.
+	WarnEvery(window time.Duration, format string, args ...interface{})
+	// Returns a logger that doesn't log duplicates of a message logged less
+	// than [window] ago, and writes to the same log as this logger
+	Every(window time.Duration) Logger
+
+	// Returns a logger that logs [fields] with every message, and writes to
+	// the same log as this logger
+	With(fields ...Field) Logger
+
 	// If assertions are enabled, will result in a panic if err is non-nil
 	AssertNoError(err error)
 	// If assertions are enabled, will result in a panic if b is false
@@ -55,6 +68,11 @@ type Logger interface {
 	SetDisplayingEnabled(bool)
 	SetContextualDisplayingEnabled(bool)
 
+	// Write all the messages logged so far to the log file
+	Flush()
+	// Write all the messages logged so far, then start a new log file
+	Rotate()
+
 	// Stop this logger and write back all meta-data.
 	Stop()
 }
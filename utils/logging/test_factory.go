@@ -19,5 +19,26 @@ func (NoFactory) MakeChain(ids.ID, string) (Logger, error) { return NoLog{}, nil
 // MakeSubdir ...
 func (NoFactory) MakeSubdir(string) (Logger, error) { return NoLog{}, nil }
 
+// Flush ...
+func (NoFactory) Flush() {}
+
+// Rotate ...
+func (NoFactory) Rotate() {}
+
+// SetLogLevel ...
+func (NoFactory) SetLogLevel(Level) {}
+
+// SetDisplayLevel ...
+func (NoFactory) SetDisplayLevel(Level) {}
+
+// SetLoggerLevels ...
+func (NoFactory) SetLoggerLevels(string, LoggerLevels) error { return nil }
+
+// GetLoggerLevels ...
+func (NoFactory) GetLoggerLevels() map[string]LoggerLevels { return map[string]LoggerLevels{} }
+
+// DiskUsage ...
+func (NoFactory) DiskUsage() int64 { return 0 }
+
 // Close ...
 func (NoFactory) Close() {}
@@ -5,6 +5,7 @@ package logging
 
 import (
 	"errors"
+	"time"
 )
 
 var (
@@ -34,6 +35,15 @@ func (NoLog) Debug(format string, args ...interface{}) {}
 // Verbo ...
 func (NoLog) Verbo(format string, args ...interface{}) {}
 
+// WarnEvery ...
+func (NoLog) WarnEvery(window time.Duration, format string, args ...interface{}) {}
+
+// Every ...
+func (NoLog) Every(time.Duration) Logger { return NoLog{} }
+
+// With ...
+func (NoLog) With(...Field) Logger { return NoLog{} }
+
 // AssertNoError ...
 func (NoLog) AssertNoError(error) {}
 
@@ -72,3 +82,9 @@ func (NoLog) SetDisplayingEnabled(bool) {}
 
 // SetContextualDisplayingEnabled ...
 func (NoLog) SetContextualDisplayingEnabled(bool) {}
+
+// Flush ...
+func (NoLog) Flush() {}
+
+// Rotate ...
+func (NoLog) Rotate() {}
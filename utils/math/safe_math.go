@@ -9,7 +9,10 @@ import (
 )
 
 var (
-	errOverflow = errors.New("overflow occurred")
+	// ErrOverflow is returned when a result is larger than the largest uint64
+	ErrOverflow = errors.New("overflow occurred")
+	// ErrUnderflow is returned when a result is less than zero
+	ErrUnderflow = errors.New("underflow occurred")
 )
 
 // Max64 ...
@@ -31,7 +34,7 @@ func Min64(a, b uint64) uint64 {
 // Add64 ...
 func Add64(a, b uint64) (uint64, error) {
 	if a > math.MaxUint64-b {
-		return 0, errOverflow
+		return 0, ErrOverflow
 	}
 	return a + b, nil
 }
@@ -41,7 +44,7 @@ func Add64(a, b uint64) (uint64, error) {
 // 2) If there is underflow, an error
 func Sub64(a, b uint64) (uint64, error) {
 	if a < b {
-		return 0, errOverflow
+		return 0, ErrUnderflow
 	}
 	return a - b, nil
 }
@@ -49,11 +52,35 @@ func Sub64(a, b uint64) (uint64, error) {
 // Mul64 ...
 func Mul64(a, b uint64) (uint64, error) {
 	if b != 0 && a > math.MaxUint64/b {
-		return 0, errOverflow
+		return 0, ErrOverflow
 	}
 	return a * b, nil
 }
 
+// SaturatingAdd64 returns a + b, or the largest uint64 if that overflows
+func SaturatingAdd64(a, b uint64) uint64 {
+	if sum, err := Add64(a, b); err == nil {
+		return sum
+	}
+	return math.MaxUint64
+}
+
+// SaturatingSub64 returns a - b, or 0 if that underflows
+func SaturatingSub64(a, b uint64) uint64 {
+	if diff, err := Sub64(a, b); err == nil {
+		return diff
+	}
+	return 0
+}
+
+// SaturatingMul64 returns a * b, or the largest uint64 if that overflows
+func SaturatingMul64(a, b uint64) uint64 {
+	if product, err := Mul64(a, b); err == nil {
+		return product
+	}
+	return math.MaxUint64
+}
+
 // Diff64 ...
 func Diff64(a, b uint64) uint64 {
 	return Max64(a, b) - Min64(a, b)
@@ -68,3 +68,39 @@ func TestMul64(t *testing.T) {
 		t.Fatalf("Mul64 overflowed")
 	}
 }
+
+func TestSub64(t *testing.T) {
+	if diff, err := Sub64(maxUint64, maxUint64); err != nil {
+		t.Fatalf("Sub64 failed unexpectedly")
+	} else if diff != 0 {
+		t.Fatalf("Sub64 returned wrong value")
+	}
+
+	if _, err := Sub64(0, 1); err != ErrUnderflow {
+		t.Fatalf("Sub64 should have failed with %s but failed with %v", ErrUnderflow, err)
+	}
+	if _, err := Add64(maxUint64, 1); err != ErrOverflow {
+		t.Fatalf("Add64 should have failed with %s but failed with %v", ErrOverflow, err)
+	}
+}
+
+func TestSaturating64(t *testing.T) {
+	if sum := SaturatingAdd64(1, 2); sum != 3 {
+		t.Fatalf("Expected %d, got %d", 3, sum)
+	}
+	if sum := SaturatingAdd64(maxUint64, 1); sum != maxUint64 {
+		t.Fatalf("Expected %d, got %d", maxUint64, sum)
+	}
+	if diff := SaturatingSub64(3, 2); diff != 1 {
+		t.Fatalf("Expected %d, got %d", 1, diff)
+	}
+	if diff := SaturatingSub64(2, 3); diff != 0 {
+		t.Fatalf("Expected %d, got %d", 0, diff)
+	}
+	if prod := SaturatingMul64(3, 2); prod != 6 {
+		t.Fatalf("Expected %d, got %d", 6, prod)
+	}
+	if prod := SaturatingMul64(maxUint64, 2); prod != maxUint64 {
+		t.Fatalf("Expected %d, got %d", maxUint64, prod)
+	}
+}
@@ -0,0 +1,46 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package sampler samples indices, uniformly or in proportion to their weights,
+// with or without replacement.
+package sampler
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+var (
+	errOutOfRange     = errors.New("index is out of range")
+	errWeightOverflow = errors.New("total weight overflows uint64")
+)
+
+// Source is the randomness that samples are drawn from
+type Source interface {
+	Uint64() uint64
+}
+
+// NewSource returns a source seeded with [seed]. Samples drawn from it are
+// deterministic, which is useful for tests.
+func NewSource(seed int64) Source { return rand.New(rand.NewSource(seed)) }
+
+// globalSource draws from math/rand's global source, which is safe for
+// concurrent use
+type globalSource struct{}
+
+func (globalSource) Uint64() uint64 { return rand.Uint64() }
+
+// uniform64 returns a number drawn uniformly from [0, n). n must be positive.
+func uniform64(source Source, n uint64) uint64 {
+	// Values at or past the largest multiple of n that fits in a uint64 would
+	// bias the result towards small numbers, so they're redrawn
+	excess := (math.MaxUint64%n + 1) % n
+	limit := -excess // 2^64 - excess
+	for {
+		value := source.Uint64()
+		if excess == 0 || value < limit {
+			return value % n
+		}
+	}
+}
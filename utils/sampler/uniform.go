@@ -0,0 +1,69 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sampler
+
+// Uniform samples indices in [0, Len()) with equal probability. It isn't safe
+// for concurrent use.
+type Uniform struct {
+	length int
+	source Source
+}
+
+// NewUniform returns a sampler of the indices in [0, [length])
+func NewUniform(length int) *Uniform {
+	return &Uniform{
+		length: length,
+		source: globalSource{},
+	}
+}
+
+// Seed makes the samples drawn after it deterministic
+func (u *Uniform) Seed(seed int64) { u.source = NewSource(seed) }
+
+// Len returns the number of indices that can be sampled
+func (u *Uniform) Len() int { return u.length }
+
+// Sample returns [count] distinct indices, or every index in a random order if
+// there are fewer than [count]. Runs in O(count) time.
+func (u *Uniform) Sample(count int) []int {
+	if count > u.length {
+		count = u.length
+	}
+	if count <= 0 {
+		return nil
+	}
+
+	// A Fisher-Yates shuffle that stops after [count] indices. Only the
+	// positions that have been swapped are stored.
+	swapped := make(map[int]int, count)
+	indices := make([]int, count)
+	for i := range indices {
+		j := i + int(uniform64(u.source, uint64(u.length-i)))
+
+		index, ok := swapped[j]
+		if !ok {
+			index = j
+		}
+		replacement, ok := swapped[i]
+		if !ok {
+			replacement = i
+		}
+		swapped[j] = replacement
+		indices[i] = index
+	}
+	return indices
+}
+
+// SampleReplace returns [count] indices, which may repeat. It returns nothing
+// if there are no indices.
+func (u *Uniform) SampleReplace(count int) []int {
+	if u.length <= 0 || count <= 0 {
+		return nil
+	}
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = int(uniform64(u.source, uint64(u.length)))
+	}
+	return indices
+}
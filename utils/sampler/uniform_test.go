@@ -0,0 +1,67 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sampler
+
+import (
+	"testing"
+)
+
+func TestUniformSample(t *testing.T) {
+	u := NewUniform(10)
+	indices := u.Sample(10)
+	if len(indices) != 10 {
+		t.Fatalf("Should have sampled %d indices but sampled %d", 10, len(indices))
+	}
+	seen := map[int]bool{}
+	for _, index := range indices {
+		if index < 0 || index >= 10 {
+			t.Fatalf("Sampled index %d out of range", index)
+		}
+		if seen[index] {
+			t.Fatalf("Sampled index %d twice", index)
+		}
+		seen[index] = true
+	}
+
+	if indices := u.Sample(11); len(indices) != 10 {
+		t.Fatalf("Should have sampled every index but sampled %d", len(indices))
+	}
+	if indices := NewUniform(0).Sample(1); len(indices) != 0 {
+		t.Fatalf("Shouldn't have sampled from no indices")
+	}
+}
+
+func TestUniformSampleReplace(t *testing.T) {
+	u := NewUniform(2)
+	indices := u.SampleReplace(100)
+	if len(indices) != 100 {
+		t.Fatalf("Should have sampled %d indices but sampled %d", 100, len(indices))
+	}
+	counts := [2]int{}
+	for _, index := range indices {
+		counts[index]++
+	}
+	if counts[0] == 0 || counts[1] == 0 {
+		t.Fatalf("Should have sampled both indices but sampled %v", counts)
+	}
+
+	if indices := NewUniform(0).SampleReplace(1); len(indices) != 0 {
+		t.Fatalf("Shouldn't have sampled from no indices")
+	}
+}
+
+func TestUniformSeed(t *testing.T) {
+	u0 := NewUniform(1000)
+	u1 := NewUniform(1000)
+	u0.Seed(5)
+	u1.Seed(5)
+
+	indices0 := u0.Sample(20)
+	indices1 := u1.Sample(20)
+	for i, index := range indices0 {
+		if index != indices1[i] {
+			t.Fatalf("Samplers with the same seed should sample the same indices")
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sampler
+
+import (
+	safemath "github.com/ava-labs/gecko/utils/math"
+)
+
+// Weighted samples indices with probability proportional to their weights. An
+// index with weight 0 is never sampled. It isn't safe for concurrent use.
+//
+// The weights are kept in a Fenwick tree, so changing a weight, appending a
+// weight, and drawing a sample all run in O(log(Len())) time.
+type Weighted struct {
+	weights []uint64
+	// tree[i-1] is the sum of the weights of the indices in
+	// [i - lowbit(i), i), where lowbit(i) is the lowest set bit of i
+	tree   []uint64
+	total  uint64
+	source Source
+}
+
+// NewWeighted returns a sampler without any indices
+func NewWeighted() *Weighted { return &Weighted{source: globalSource{}} }
+
+// Seed makes the samples drawn after it deterministic
+func (w *Weighted) Seed(seed int64) { w.source = NewSource(seed) }
+
+// SetSource makes the samples drawn after it come from [source]
+func (w *Weighted) SetSource(source Source) { w.source = source }
+
+// Len returns the number of indices
+func (w *Weighted) Len() int { return len(w.weights) }
+
+// Weight returns the weight of [index]
+func (w *Weighted) Weight(index int) uint64 { return w.weights[index] }
+
+// TotalWeight returns the sum of the weights
+func (w *Weighted) TotalWeight() uint64 { return w.total }
+
+// Append adds an index, Len(), with weight [weight]
+func (w *Weighted) Append(weight uint64) error {
+	total, err := safemath.Add64(w.total, weight)
+	if err != nil {
+		return errWeightOverflow
+	}
+
+	// The new node's sum includes the nodes whose ranges end in its range
+	i := len(w.tree) + 1
+	sum := weight
+	for j := i - 1; j > i-lowbit(i); j -= lowbit(j) {
+		sum += w.tree[j-1]
+	}
+
+	w.weights = append(w.weights, weight)
+	w.tree = append(w.tree, sum)
+	w.total = total
+	return nil
+}
+
+// RemoveLast removes the index Len() - 1
+func (w *Weighted) RemoveLast() {
+	last := len(w.weights) - 1
+	if last < 0 {
+		return
+	}
+	w.total -= w.weights[last]
+	w.weights = w.weights[:last]
+	w.tree = w.tree[:last]
+}
+
+// Update sets the weight of [index] to [weight]
+func (w *Weighted) Update(index int, weight uint64) error {
+	if index < 0 || index >= len(w.weights) {
+		return errOutOfRange
+	}
+
+	previous := w.weights[index]
+	if weight >= previous {
+		increase := weight - previous
+		total, err := safemath.Add64(w.total, increase)
+		if err != nil {
+			return errWeightOverflow
+		}
+		w.total = total
+		for i := index + 1; i <= len(w.tree); i += lowbit(i) {
+			w.tree[i-1] += increase
+		}
+	} else {
+		decrease := previous - weight
+		w.total -= decrease
+		for i := index + 1; i <= len(w.tree); i += lowbit(i) {
+			w.tree[i-1] -= decrease
+		}
+	}
+	w.weights[index] = weight
+	return nil
+}
+
+// Sample returns [count] distinct indices, or every index with a positive
+// weight if there are fewer than [count]
+func (w *Weighted) Sample(count int) []int {
+	indices := []int(nil)
+	weights := []uint64(nil)
+	for len(indices) < count && w.total > 0 {
+		index := w.search(uniform64(w.source, w.total))
+		indices = append(indices, index)
+		weights = append(weights, w.weights[index])
+
+		// Decreasing a weight can't fail
+		_ = w.Update(index, 0)
+	}
+
+	// Restoring the weights that were sampled can't overflow
+	for i, index := range indices {
+		_ = w.Update(index, weights[i])
+	}
+	return indices
+}
+
+// SampleReplace returns [count] indices, which may repeat. It returns nothing
+// if every weight is 0.
+func (w *Weighted) SampleReplace(count int) []int {
+	if w.total == 0 || count <= 0 {
+		return nil
+	}
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = w.search(uniform64(w.source, w.total))
+	}
+	return indices
+}
+
+// search returns the index whose weight covers [value], when the weights are
+// laid end to end. [value] must be less than the total weight.
+func (w *Weighted) search(value uint64) int {
+	step := 1
+	for step*2 <= len(w.tree) {
+		step *= 2
+	}
+
+	// Find the most indices whose weights sum to at most [value]
+	numIndices := 0
+	for ; step > 0; step /= 2 {
+		if next := numIndices + step; next <= len(w.tree) && w.tree[next-1] <= value {
+			numIndices = next
+			value -= w.tree[next-1]
+		}
+	}
+	return numIndices
+}
+
+// lowbit returns the lowest set bit of [i]
+func lowbit(i int) int { return i & -i }
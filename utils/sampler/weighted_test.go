@@ -0,0 +1,155 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sampler
+
+import (
+	"math"
+	"testing"
+)
+
+// verifyTree checks that the sum of every prefix of the weights is what the
+// tree says it is
+func verifyTree(t *testing.T, w *Weighted) {
+	sum := uint64(0)
+	for i, weight := range w.weights {
+		sum += weight
+
+		treeSum := uint64(0)
+		for j := i + 1; j > 0; j -= lowbit(j) {
+			treeSum += w.tree[j-1]
+		}
+		if treeSum != sum {
+			t.Fatalf("The first %d weights sum to %d but the tree has %d", i+1, sum, treeSum)
+		}
+	}
+	if sum != w.TotalWeight() {
+		t.Fatalf("The weights sum to %d but the total weight is %d", sum, w.TotalWeight())
+	}
+}
+
+func TestWeightedUpdates(t *testing.T) {
+	w := NewWeighted()
+	for i := uint64(0); i < 13; i++ {
+		if err := w.Append(i * 3); err != nil {
+			t.Fatal(err)
+		}
+		verifyTree(t, w)
+	}
+
+	if err := w.Update(4, 100); err != nil {
+		t.Fatal(err)
+	}
+	verifyTree(t, w)
+	if err := w.Update(7, 0); err != nil {
+		t.Fatal(err)
+	}
+	verifyTree(t, w)
+
+	w.RemoveLast()
+	verifyTree(t, w)
+	if w.Len() != 12 {
+		t.Fatalf("Should have %d indices but has %d", 12, w.Len())
+	}
+	if err := w.Append(5); err != nil {
+		t.Fatal(err)
+	}
+	verifyTree(t, w)
+
+	if err := w.Update(w.Len(), 1); err != errOutOfRange {
+		t.Fatalf("Should have errored with %s but errored with %v", errOutOfRange, err)
+	}
+	if err := w.Append(math.MaxUint64); err != errWeightOverflow {
+		t.Fatalf("Should have errored with %s but errored with %v", errWeightOverflow, err)
+	}
+	if err := w.Update(0, math.MaxUint64); err != errWeightOverflow {
+		t.Fatalf("Should have errored with %s but errored with %v", errWeightOverflow, err)
+	}
+	verifyTree(t, w)
+}
+
+func TestWeightedSample(t *testing.T) {
+	w := NewWeighted()
+	for _, weight := range []uint64{1, 0, 2, 0, 3} {
+		if err := w.Append(weight); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	indices := w.Sample(5)
+	if len(indices) != 3 {
+		t.Fatalf("Should have sampled the %d indices with positive weights but sampled %d", 3, len(indices))
+	}
+	seen := map[int]bool{}
+	for _, index := range indices {
+		if w.Weight(index) == 0 {
+			t.Fatalf("Sampled index %d, which has no weight", index)
+		}
+		if seen[index] {
+			t.Fatalf("Sampled index %d twice", index)
+		}
+		seen[index] = true
+	}
+	if w.TotalWeight() != 6 {
+		t.Fatalf("Sampling should have restored the weights")
+	}
+	verifyTree(t, w)
+
+	if indices := NewWeighted().Sample(1); len(indices) != 0 {
+		t.Fatalf("Shouldn't have sampled from no indices")
+	}
+}
+
+func TestWeightedSampleReplace(t *testing.T) {
+	w := NewWeighted()
+	w.Seed(0)
+	for _, weight := range []uint64{1, 0, 9} {
+		if err := w.Append(weight); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts := [3]int{}
+	for _, index := range w.SampleReplace(1000) {
+		counts[index]++
+	}
+	if counts[1] != 0 {
+		t.Fatalf("Sampled an index without weight")
+	}
+	if counts[2] < 5*counts[0] {
+		t.Fatalf("Should have sampled in proportion to the weights but sampled %v", counts)
+	}
+
+	if err := w.Update(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Update(2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if indices := w.SampleReplace(1); len(indices) != 0 {
+		t.Fatalf("Shouldn't have sampled when every weight is 0")
+	}
+}
+
+func TestWeightedSeed(t *testing.T) {
+	w0 := NewWeighted()
+	w1 := NewWeighted()
+	for i := uint64(1); i <= 100; i++ {
+		if err := w0.Append(i); err != nil {
+			t.Fatal(err)
+		}
+		if err := w1.Append(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w0.Seed(5)
+	w1.Seed(5)
+
+	indices0 := w0.Sample(20)
+	indices1 := w1.Sample(20)
+	for i, index := range indices0 {
+		if index != indices1[i] {
+			t.Fatalf("Samplers with the same seed should sample the same indices")
+		}
+	}
+}
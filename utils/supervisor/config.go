@@ -0,0 +1,80 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package supervisor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config of a supervised process
+type Config struct {
+	// Name of the process, which its status and PID file are reported under
+	Name string
+
+	// Path of the executable and the arguments it's run with
+	Path string
+	Args []string
+
+	// How long to wait before restarting the process after it exits. The wait
+	// doubles after each exit, up to MaxBackoff, and is reset once the process
+	// has run for MaxBackoff without exiting.
+	MinBackoff, MaxBackoff time.Duration
+}
+
+// Valid returns nil if the config describes a process that can be supervised
+func (c Config) Valid() error {
+	switch {
+	case c.Name == "" || strings.ContainsAny(c.Name, "/\\"):
+		return fmt.Errorf("Name = %q: Fails the condition that: Name is a non-empty file name", c.Name)
+	case c.Path == "":
+		return fmt.Errorf("Path = %q: Fails the condition that: Path is non-empty", c.Path)
+	case c.MinBackoff <= 0:
+		return fmt.Errorf("MinBackoff = %s: Fails the condition that: 0 < MinBackoff", c.MinBackoff)
+	case c.MaxBackoff < c.MinBackoff:
+		return fmt.Errorf("MinBackoff = %s, MaxBackoff = %s: Fails the condition that: MinBackoff <= MaxBackoff", c.MinBackoff, c.MaxBackoff)
+	default:
+		return nil
+	}
+}
+
+// ParseConfigs parses a comma separated list of processes, of the form
+// name=path arg1 arg2..., into configs that are restarted with the provided
+// backoffs
+func ParseConfigs(processes string, minBackoff, maxBackoff time.Duration) ([]Config, error) {
+	configs := []Config(nil)
+	names := make(map[string]bool)
+	for _, entry := range strings.Split(processes, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("supervised process %q should be of the form name=path arg1 arg2...", entry)
+		}
+		name := strings.TrimSpace(fields[0])
+		command := strings.Fields(fields[1])
+		if len(command) == 0 {
+			return nil, fmt.Errorf("supervised process %s is missing the path of its executable", name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("supervised process %s is listed more than once", name)
+		}
+		names[name] = true
+
+		config := Config{
+			Name:       name,
+			Path:       command[0],
+			Args:       command[1:],
+			MinBackoff: minBackoff,
+			MaxBackoff: maxBackoff,
+		}
+		if err := config.Valid(); err != nil {
+			return nil, fmt.Errorf("supervised process %s: %w", name, err)
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
@@ -0,0 +1,303 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package supervisor runs long-running child processes, such as IPC
+// consumers, alongside the node. Processes that exit are restarted with
+// exponential backoff until the supervisor is shut down, at which point they're
+// asked to stop and killed if they don't.
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+var (
+	errDuplicateProcess = errors.New("a process with that name is already supervised")
+	errShutdown         = errors.New("supervisor has been shut down")
+	errNotRunning       = errors.New("supervised processes aren't running")
+)
+
+// Status of a supervised process
+type Status struct {
+	// PID of the process, if it's running
+	PID int `json:"pid,omitempty"`
+
+	Running bool `json:"running"`
+
+	// Number of times the process has been restarted
+	Restarts int `json:"restarts"`
+
+	// How the process last exited, or why it couldn't be started
+	LastExit string `json:"lastExit,omitempty"`
+}
+
+type process struct {
+	config Config
+
+	lock sync.Mutex
+	// cmd is the running instance of the process, or nil if it isn't running
+	cmd      *exec.Cmd
+	restarts int
+	lastExit string
+	stopped  bool
+	// stop is closed once the process should no longer be restarted
+	stop chan struct{}
+}
+
+// signal sends [sig] to the process, if it's running
+func (p *process) signal(sig os.Signal) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.cmd != nil {
+		_ = p.cmd.Process.Signal(sig)
+	}
+}
+
+// Supervisor runs processes and restarts them when they exit
+type Supervisor struct {
+	log logging.Logger
+
+	// Directory the PID files of the processes are written to. If empty, no
+	// PID files are written.
+	pidDir string
+
+	lock sync.Mutex
+	// Key: Name of a process
+	processes map[string]*process
+	shutdown  bool
+
+	// running is done once every process has exited for the last time
+	running sync.WaitGroup
+}
+
+// Initialize the supervisor. If [pidDir] isn't empty, the PID of each process
+// is written to [pidDir]/[name].pid while the process is running.
+func (s *Supervisor) Initialize(log logging.Logger, pidDir string) {
+	s.log = log
+	s.pidDir = pidDir
+	s.processes = make(map[string]*process)
+}
+
+// Start running the process that [config] describes
+func (s *Supervisor) Start(config Config) error {
+	if err := config.Valid(); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch {
+	case s.shutdown:
+		return errShutdown
+	case s.processes[config.Name] != nil:
+		return fmt.Errorf("%w: %s", errDuplicateProcess, config.Name)
+	}
+	p := &process{
+		config: config,
+		stop:   make(chan struct{}),
+	}
+	s.processes[config.Name] = p
+
+	s.running.Add(1)
+	go s.log.RecoverAndPanic(func() { s.supervise(p) })
+	return nil
+}
+
+// supervise runs [p] until the supervisor is shut down
+func (s *Supervisor) supervise(p *process) {
+	defer s.running.Done()
+
+	backoff := p.config.MinBackoff
+	for {
+		started := time.Now()
+		exit := s.run(p)
+
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		if time.Since(started) >= p.config.MaxBackoff {
+			backoff = p.config.MinBackoff
+		}
+		s.log.Warn("supervised process %s stopped (%s). Restarting it in %s", p.config.Name, exit, backoff)
+
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > p.config.MaxBackoff {
+			backoff = p.config.MaxBackoff
+		}
+
+		p.lock.Lock()
+		p.restarts++
+		p.lock.Unlock()
+	}
+}
+
+// run an instance of [p] and return how it exited
+func (s *Supervisor) run(p *process) string {
+	cmd := exec.Command(p.config.Path, p.config.Args...)
+
+	p.lock.Lock()
+	if p.stopped {
+		p.lock.Unlock()
+		return errShutdown.Error()
+	}
+	if err := cmd.Start(); err != nil {
+		p.lastExit = err.Error()
+		p.lock.Unlock()
+		return p.lastExit
+	}
+	p.cmd = cmd
+	p.lock.Unlock()
+
+	pid := cmd.Process.Pid
+	s.log.Info("started supervised process %s with PID %d", p.config.Name, pid)
+	pidFile := s.pidFile(p.config.Name)
+	if pidFile != "" {
+		if err := WritePIDFile(pidFile, pid); err != nil {
+			s.log.Warn("couldn't write the PID file of supervised process %s due to %s", p.config.Name, err)
+		}
+	}
+
+	err := cmd.Wait()
+
+	if pidFile != "" {
+		if err := RemovePIDFile(pidFile); err != nil {
+			s.log.Warn("couldn't remove the PID file of supervised process %s due to %s", p.config.Name, err)
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.cmd = nil
+	if cmd.ProcessState != nil {
+		p.lastExit = cmd.ProcessState.String()
+	} else {
+		p.lastExit = err.Error()
+	}
+	return p.lastExit
+}
+
+func (s *Supervisor) pidFile(name string) string {
+	if s.pidDir == "" {
+		return ""
+	}
+	return filepath.Join(s.pidDir, name+".pid")
+}
+
+// Status returns the status of each process, keyed by its name
+func (s *Supervisor) Status() map[string]Status {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	statuses := make(map[string]Status, len(s.processes))
+	for name, p := range s.processes {
+		p.lock.Lock()
+		status := Status{
+			Running:  p.cmd != nil,
+			Restarts: p.restarts,
+			LastExit: p.lastExit,
+		}
+		if p.cmd != nil {
+			status.PID = p.cmd.Process.Pid
+		}
+		p.lock.Unlock()
+		statuses[name] = status
+	}
+	return statuses
+}
+
+// HealthCheck implements the health.Checker interface. The processes are
+// unhealthy if any of them isn't running.
+func (s *Supervisor) HealthCheck() (interface{}, error) {
+	statuses := s.Status()
+	notRunning := []string(nil)
+	for name, status := range statuses {
+		if !status.Running {
+			notRunning = append(notRunning, name)
+		}
+	}
+	if len(notRunning) > 0 {
+		sort.Strings(notRunning)
+		return statuses, fmt.Errorf("%w: %s", errNotRunning, strings.Join(notRunning, ", "))
+	}
+	return statuses, nil
+}
+
+// Shutdown stops restarting the processes and sends each of them SIGTERM.
+// Processes that haven't exited within [timeout] are killed. Returns once every
+// process has exited.
+func (s *Supervisor) Shutdown(timeout time.Duration) {
+	s.lock.Lock()
+	s.shutdown = true
+	processes := make([]*process, 0, len(s.processes))
+	for _, p := range s.processes {
+		processes = append(processes, p)
+	}
+	s.lock.Unlock()
+
+	for _, p := range processes {
+		p.lock.Lock()
+		if !p.stopped {
+			p.stopped = true
+			close(p.stop)
+		}
+		p.lock.Unlock()
+		p.signal(syscall.SIGTERM)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(timeout):
+		s.log.Warn("supervised processes didn't stop within %s. Killing them", timeout)
+		for _, p := range processes {
+			p.signal(os.Kill)
+		}
+		<-exited
+	}
+}
+
+// WritePIDFile writes [pid] to the file at [path]. The file is replaced
+// atomically, so it's never read while partially written.
+func WritePIDFile(path string, pid int) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RemovePIDFile removes the PID file at [path], if it exists
+func RemovePIDFile(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package supervisor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func shell(name, script string) Config {
+	return Config{
+		Name:       name,
+		Path:       "/bin/sh",
+		Args:       []string{"-c", script},
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	}
+}
+
+// waitFor fails the test if [condition] isn't met within a few seconds
+func waitFor(t *testing.T, condition func() bool, msg string) {
+	for deadline := time.Now().Add(5 * time.Second); !condition(); {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSupervisorRestarts(t *testing.T) {
+	s := Supervisor{}
+	s.Initialize(logging.NoLog{}, "")
+	defer s.Shutdown(time.Second)
+
+	if err := s.Start(shell("crashing", "exit 3")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return s.Status()["crashing"].Restarts >= 3 }, "Should have restarted the process")
+
+	status := s.Status()["crashing"]
+	if status.LastExit != "exit status 3" {
+		t.Fatalf("Expected the last exit to be %q but was %q", "exit status 3", status.LastExit)
+	}
+
+	missing := shell("missing", "")
+	missing.Path = "/nonexistent"
+	if err := s.Start(missing); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return s.Status()["missing"].LastExit != "" }, "Should have tried to start the process")
+	if _, err := s.HealthCheck(); err == nil {
+		t.Fatalf("Should be unhealthy while a process can't be started")
+	}
+	if err := s.Start(shell("crashing", "exit 3")); err == nil {
+		t.Fatalf("Shouldn't have supervised two processes with the same name")
+	}
+}
+
+func TestSupervisorShutdown(t *testing.T) {
+	pidDir, err := ioutil.TempDir("", "supervisor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(pidDir)
+
+	s := Supervisor{}
+	s.Initialize(logging.NoLog{}, pidDir)
+	if err := s.Start(shell("stopping", "exec sleep 60")); err != nil {
+		t.Fatal(err)
+	}
+	ready := filepath.Join(pidDir, "ready")
+	if err := s.Start(shell("stubborn", "trap '' TERM; touch "+ready+"; while true; do sleep 0.01; done")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		_, err := os.Stat(ready)
+		return err == nil
+	}, "Process should have started ignoring SIGTERM")
+	waitFor(t, func() bool {
+		_, err := s.HealthCheck()
+		return err == nil
+	}, "Processes should have started")
+
+	pidFile := filepath.Join(pidDir, "stopping.pid")
+	waitFor(t, func() bool {
+		_, err := os.Stat(pidFile)
+		return err == nil
+	}, "Should have written the PID file")
+	pidBytes, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid := strings.TrimSpace(string(pidBytes)); pid != strconv.Itoa(s.Status()["stopping"].PID) {
+		t.Fatalf("PID file has PID %s but the process has PID %d", pid, s.Status()["stopping"].PID)
+	}
+
+	s.Shutdown(100 * time.Millisecond)
+	for name, status := range s.Status() {
+		if status.Running {
+			t.Fatalf("Process %s should have stopped", name)
+		}
+	}
+	if status := s.Status()["stubborn"]; status.LastExit != "signal: killed" {
+		t.Fatalf("Process ignoring SIGTERM should have been killed but %s", status.LastExit)
+	}
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Fatalf("Should have removed the PID file")
+	}
+	if err := s.Start(shell("late", "exit 0")); err != errShutdown {
+		t.Fatalf("Should have errored with %s but errored with %v", errShutdown, err)
+	}
+}
+
+func TestParseConfigs(t *testing.T) {
+	configs, err := ParseConfigs("consumer=/usr/bin/consumer --chain X, other=other", time.Second, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("Expected 2 configs but got %d", len(configs))
+	}
+	if c := configs[0]; c.Name != "consumer" || c.Path != "/usr/bin/consumer" || strings.Join(c.Args, " ") != "--chain X" {
+		t.Fatalf("Parsed the wrong config: %+v", c)
+	}
+
+	for _, processes := range []string{"consumer", "consumer=", "a=x,a=y", "a/b=x"} {
+		if _, err := ParseConfigs(processes, time.Second, time.Minute); err == nil {
+			t.Fatalf("Should have failed to parse %q", processes)
+		}
+	}
+	if _, err := ParseConfigs("a=x", time.Minute, time.Second); err == nil {
+		t.Fatalf("Should have failed with a max backoff below the min backoff")
+	}
+}
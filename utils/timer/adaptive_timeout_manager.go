@@ -0,0 +1,255 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timer
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+// AdaptiveTimeoutConfig contains the parameters that determine how the timeout
+// duration follows the response times of recent requests
+type AdaptiveTimeoutConfig struct {
+	// InitialTimeout is used until there are response times to adapt to
+	InitialTimeout time.Duration
+	// MinimumTimeout and MaximumTimeout bound the timeout duration
+	MinimumTimeout, MaximumTimeout time.Duration
+	// Percentile, in (0, 1], of the recent response times that the timeout
+	// is based on
+	Percentile float64
+	// Multiplier applied to the percentile response time to give slow, but
+	// live, requests some slack
+	Multiplier float64
+	// WindowSize is the number of recent response times that are considered
+	WindowSize int
+}
+
+// Valid returns nil if the parameters describe a valid initialization.
+func (c AdaptiveTimeoutConfig) Valid() error {
+	switch {
+	case c.MinimumTimeout < 0:
+		return fmt.Errorf("MinimumTimeout = %s: Fails the condition that: 0 <= MinimumTimeout", c.MinimumTimeout)
+	case c.MaximumTimeout < c.MinimumTimeout:
+		return fmt.Errorf("MinimumTimeout = %s, MaximumTimeout = %s: Fails the condition that: MinimumTimeout <= MaximumTimeout", c.MinimumTimeout, c.MaximumTimeout)
+	case c.InitialTimeout < c.MinimumTimeout || c.InitialTimeout > c.MaximumTimeout:
+		return fmt.Errorf("InitialTimeout = %s: Fails the condition that: MinimumTimeout <= InitialTimeout <= MaximumTimeout", c.InitialTimeout)
+	case c.Percentile <= 0 || c.Percentile > 1:
+		return fmt.Errorf("Percentile = %f: Fails the condition that: 0 < Percentile <= 1", c.Percentile)
+	case c.Multiplier < 1:
+		return fmt.Errorf("Multiplier = %f: Fails the condition that: 1 <= Multiplier", c.Multiplier)
+	case c.WindowSize <= 0:
+		return fmt.Errorf("WindowSize = %d: Fails the condition that: 0 < WindowSize", c.WindowSize)
+	default:
+		return nil
+	}
+}
+
+type adaptiveTimeout struct {
+	id       ids.ID
+	handler  timeoutHandler
+	sentAt   time.Time
+	deadline time.Time
+	index    int
+}
+
+// timeoutQueue orders the pending timeouts by their deadline. Since the timeout
+// duration changes over time, the order timeouts were added in isn't the order
+// they expire in.
+type timeoutQueue []*adaptiveTimeout
+
+func (q timeoutQueue) Len() int           { return len(q) }
+func (q timeoutQueue) Less(i, j int) bool { return q[i].deadline.Before(q[j].deadline) }
+func (q timeoutQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *timeoutQueue) Push(x interface{}) {
+	timeout := x.(*adaptiveTimeout)
+	timeout.index = len(*q)
+	*q = append(*q, timeout)
+}
+func (q *timeoutQueue) Pop() interface{} {
+	old := *q
+	n := len(old) - 1
+	timeout := old[n]
+	old[n] = nil
+	*q = old[:n]
+	return timeout
+}
+
+// AdaptiveTimeoutManager is a manager for timeouts whose duration follows the
+// response times of recent requests. Requests that time out count as having
+// taken the full timeout, so if many requests time out, for example while the
+// network is flooded with bootstrapping requests, the timeout grows rather than
+// failing requests that would have been answered.
+type AdaptiveTimeoutManager struct {
+	lock   sync.Mutex
+	config AdaptiveTimeoutConfig
+
+	currentTimeout time.Duration
+	// responseTimes is a ring buffer of the most recent response times
+	responseTimes []time.Duration
+	next          int
+
+	timeoutMap   map[[32]byte]*adaptiveTimeout
+	timeoutQueue timeoutQueue
+	timer        *Timer // Timer that will fire to clear the timeouts
+	clock        Clock
+}
+
+// Initialize the manager. Returns an error if [config] isn't valid.
+func (tm *AdaptiveTimeoutManager) Initialize(config AdaptiveTimeoutConfig) error {
+	if err := config.Valid(); err != nil {
+		return err
+	}
+	tm.config = config
+	tm.currentTimeout = config.InitialTimeout
+	tm.responseTimes = make([]time.Duration, 0, config.WindowSize)
+	tm.timeoutMap = make(map[[32]byte]*adaptiveTimeout)
+	tm.timer = NewTimer(tm.Timeout)
+	return nil
+}
+
+// Dispatch ...
+func (tm *AdaptiveTimeoutManager) Dispatch() { tm.timer.Dispatch() }
+
+// Stop executing timeouts
+func (tm *AdaptiveTimeoutManager) Stop() { tm.timer.Stop() }
+
+// TimeoutDuration returns the duration that newly registered timeouts are
+// given
+func (tm *AdaptiveTimeoutManager) TimeoutDuration() time.Duration {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	return tm.currentTimeout
+}
+
+// Put registers [handler] to be called once the current timeout duration has
+// passed, unless [id] is removed first
+func (tm *AdaptiveTimeoutManager) Put(id ids.ID, handler func()) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	tm.put(id, tm.currentTimeout, handler)
+}
+
+// PutWithDuration registers [handler] to be called once [duration] has passed,
+// rather than the current timeout duration, unless [id] is removed first
+func (tm *AdaptiveTimeoutManager) PutWithDuration(id ids.ID, duration time.Duration, handler func()) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	tm.put(id, duration, handler)
+}
+
+func (tm *AdaptiveTimeoutManager) put(id ids.ID, duration time.Duration, handler func()) {
+	tm.remove(id)
+
+	now := tm.clock.Time()
+	timeout := &adaptiveTimeout{
+		id:       id,
+		handler:  handler,
+		sentAt:   now,
+		deadline: now.Add(duration),
+	}
+	tm.timeoutMap[id.Key()] = timeout
+	heap.Push(&tm.timeoutQueue, timeout)
+
+	if tm.timeoutQueue[0] == timeout {
+		tm.registerTimeout()
+	}
+}
+
+// Remove the item that no longer needs to be there. Returns true if the
+// timeout was pending, in which case the time since it was registered is
+// recorded as a response time.
+func (tm *AdaptiveTimeoutManager) Remove(id ids.ID) bool {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	timeout := tm.remove(id)
+	if timeout == nil {
+		return false
+	}
+	tm.observe(tm.clock.Time().Sub(timeout.sentAt))
+	return true
+}
+
+// Timeout executes the handlers of the timeouts that have expired
+func (tm *AdaptiveTimeoutManager) Timeout() {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	now := tm.clock.Time()
+	for len(tm.timeoutQueue) > 0 {
+		timeout := tm.timeoutQueue[0]
+		if timeout.deadline.After(now) {
+			break
+		}
+		tm.remove(timeout.id)
+		tm.observe(timeout.deadline.Sub(timeout.sentAt))
+
+		// Don't execute a callback with a lock held
+		tm.lock.Unlock()
+		timeout.handler()
+		tm.lock.Lock()
+	}
+	tm.registerTimeout()
+}
+
+func (tm *AdaptiveTimeoutManager) remove(id ids.ID) *adaptiveTimeout {
+	key := id.Key()
+	timeout, exists := tm.timeoutMap[key]
+	if !exists {
+		return nil
+	}
+	delete(tm.timeoutMap, key)
+	heap.Remove(&tm.timeoutQueue, timeout.index)
+	return timeout
+}
+
+// observe records [responseTime] and recalculates the timeout duration
+func (tm *AdaptiveTimeoutManager) observe(responseTime time.Duration) {
+	if len(tm.responseTimes) < tm.config.WindowSize {
+		tm.responseTimes = append(tm.responseTimes, responseTime)
+	} else {
+		tm.responseTimes[tm.next] = responseTime
+	}
+	tm.next = (tm.next + 1) % tm.config.WindowSize
+
+	sorted := make([]time.Duration, len(tm.responseTimes))
+	copy(sorted, tm.responseTimes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	index := int(math.Ceil(tm.config.Percentile*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	timeout := time.Duration(float64(sorted[index]) * tm.config.Multiplier)
+	switch {
+	case timeout < tm.config.MinimumTimeout:
+		timeout = tm.config.MinimumTimeout
+	case timeout > tm.config.MaximumTimeout:
+		timeout = tm.config.MaximumTimeout
+	}
+	tm.currentTimeout = timeout
+}
+
+func (tm *AdaptiveTimeoutManager) registerTimeout() {
+	if len(tm.timeoutQueue) == 0 {
+		// There are no pending timeouts
+		tm.timer.Cancel()
+		return
+	}
+
+	tm.timer.SetTimeoutIn(tm.timeoutQueue[0].deadline.Sub(tm.clock.Time()))
+}
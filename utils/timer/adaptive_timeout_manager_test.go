@@ -0,0 +1,93 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timer
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestAdaptiveTimeoutManagerInvalidConfig(t *testing.T) {
+	tm := AdaptiveTimeoutManager{}
+	if err := tm.Initialize(AdaptiveTimeoutConfig{
+		InitialTimeout: time.Second,
+		MinimumTimeout: 2 * time.Second,
+		MaximumTimeout: 3 * time.Second,
+		Percentile:     0.9,
+		Multiplier:     2,
+		WindowSize:     10,
+	}); err == nil {
+		t.Fatalf("Should have errored due to an initial timeout below the minimum")
+	}
+}
+
+func TestAdaptiveTimeoutManagerFire(t *testing.T) {
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	defer wg.Wait()
+
+	tm := AdaptiveTimeoutManager{}
+	if err := tm.Initialize(AdaptiveTimeoutConfig{
+		InitialTimeout: time.Millisecond,
+		MinimumTimeout: time.Millisecond,
+		MaximumTimeout: time.Second,
+		Percentile:     0.9,
+		Multiplier:     2,
+		WindowSize:     10,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go tm.Dispatch()
+
+	tm.Put(ids.NewID([32]byte{}), wg.Done)
+	tm.Put(ids.NewID([32]byte{1}), wg.Done)
+}
+
+func TestAdaptiveTimeoutManagerAdapts(t *testing.T) {
+	tm := AdaptiveTimeoutManager{}
+	if err := tm.Initialize(AdaptiveTimeoutConfig{
+		InitialTimeout: time.Second,
+		MinimumTimeout: 100 * time.Millisecond,
+		MaximumTimeout: 10 * time.Second,
+		Percentile:     0.5,
+		Multiplier:     2,
+		WindowSize:     3,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(0, 0)
+	tm.clock.Set(start)
+
+	for i, responseTime := range []time.Duration{
+		time.Millisecond,
+		200 * time.Millisecond,
+		300 * time.Millisecond,
+	} {
+		id := ids.NewID([32]byte{byte(i)})
+		tm.clock.Set(start)
+		tm.Put(id, func() { t.Fatalf("Shouldn't have timed out") })
+		tm.clock.Set(start.Add(responseTime))
+		if !tm.Remove(id) {
+			t.Fatalf("Timeout should have been pending")
+		}
+	}
+	if timeout := tm.TimeoutDuration(); timeout != 400*time.Millisecond {
+		t.Fatalf("Timeout should be twice the median response time, but was %s", timeout)
+	}
+
+	// Timed out requests push the timeout up to the maximum
+	for i := 0; i < 20; i++ {
+		tm.clock.Set(start)
+		tm.Put(ids.NewID([32]byte{byte(i)}), func() {})
+		tm.clock.Set(start.Add(time.Hour))
+		tm.Timeout()
+		start = start.Add(time.Hour)
+	}
+	if timeout := tm.TimeoutDuration(); timeout != 10*time.Second {
+		t.Fatalf("Timeout should have grown to the maximum, but was %s", timeout)
+	}
+}
@@ -16,6 +16,10 @@ type Clock struct {
 // Set the time on the clock
 func (c *Clock) Set(time time.Time) { c.faked = true; c.time = time }
 
+// Advance the time on the clock by [duration]. If the clock was following
+// global time, it's first set to the current global time.
+func (c *Clock) Advance(duration time.Duration) { c.Set(c.Time().Add(duration)) }
+
 // Sync this clock with global time
 func (c *Clock) Sync() { c.faked = false }
 
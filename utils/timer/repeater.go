@@ -69,12 +69,21 @@ func (r *Repeater) Dispatch() {
 			r.handler()
 		}
 
-		timer.Reset(r.frequency)
-
 		r.lock.Lock()
+		timer.Reset(r.frequency)
 	}
 }
 
+// SetFrequency changes the amount of time between calls of the handler. The
+// wait for the next call is restarted with the new frequency.
+func (r *Repeater) SetFrequency(frequency time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.frequency = frequency
+	r.reset()
+}
+
 func (r *Repeater) reset() {
 	select {
 	case r.timeout <- struct{}{}:
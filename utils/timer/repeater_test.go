@@ -25,3 +25,22 @@ func TestRepeater(t *testing.T) {
 	wg.Wait()
 	repeater.Stop()
 }
+
+func TestRepeaterSetFrequency(t *testing.T) {
+	called := make(chan struct{}, 1)
+	repeater := NewRepeater(func() {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+	}, time.Hour)
+	go repeater.Dispatch()
+	defer repeater.Stop()
+
+	repeater.SetFrequency(time.Millisecond)
+	select {
+	case <-called:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the handler should have been called at the new frequency")
+	}
+}
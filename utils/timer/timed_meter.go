@@ -18,6 +18,7 @@ type TimedMeter struct {
 	// sustainable at high tick numbers. We should be batching ticks with
 	// similar times into the same bucket.
 	tickList *list.List
+	clock    Clock
 }
 
 // Tick implements the Meter interface
@@ -44,13 +45,13 @@ func (tm *TimedMeter) init() {
 
 func (tm *TimedMeter) tick() {
 	tm.init()
-	tm.tickList.PushBack(time.Now())
+	tm.tickList.PushBack(tm.clock.Time())
 }
 
 func (tm *TimedMeter) ticks() int {
 	tm.init()
 
-	timeBound := time.Now().Add(-tm.Duration)
+	timeBound := tm.clock.Time().Add(-tm.Duration)
 	// removeExpiredHead returns false once there is nothing left to remove
 	for tm.removeExpiredHead(timeBound) {
 	}
@@ -26,6 +26,7 @@ type TimeoutManager struct {
 	timeoutMap  map[[32]byte]*list.Element
 	timeoutList *list.List
 	timer       *Timer // Timer that will fire to clear the timeouts
+	clock       Clock
 }
 
 // Initialize is a constructor b/c Golang, in its wisdom, doesn't ... have them?
@@ -50,12 +51,13 @@ func (tm *TimeoutManager) Put(id ids.ID, handler func()) {
 	tm.put(id, handler)
 }
 
-// Remove the item that no longer needs to be there.
-func (tm *TimeoutManager) Remove(id ids.ID) {
+// Remove the item that no longer needs to be there. Returns true if the
+// timeout was pending.
+func (tm *TimeoutManager) Remove(id ids.ID) bool {
 	tm.lock.Lock()
 	defer tm.lock.Unlock()
 
-	tm.remove(id)
+	return tm.remove(id)
 }
 
 // Timeout registers a timeout
@@ -67,7 +69,7 @@ func (tm *TimeoutManager) Timeout() {
 }
 
 func (tm *TimeoutManager) timeout() {
-	timeBound := time.Now().Add(-tm.duration)
+	timeBound := tm.clock.Time().Add(-tm.duration)
 	// removeExpiredHead returns false once there is nothing left to remove
 	for {
 		timeout := tm.removeExpiredHead(timeBound)
@@ -89,7 +91,7 @@ func (tm *TimeoutManager) put(id ids.ID, handler timeoutHandler) {
 	tm.timeoutMap[id.Key()] = tm.timeoutList.PushBack(timeout{
 		id:      id,
 		handler: handler,
-		timer:   time.Now(),
+		timer:   tm.clock.Time(),
 	})
 
 	if tm.timeoutList.Len() == 1 {
@@ -97,14 +99,15 @@ func (tm *TimeoutManager) put(id ids.ID, handler timeoutHandler) {
 	}
 }
 
-func (tm *TimeoutManager) remove(id ids.ID) {
+func (tm *TimeoutManager) remove(id ids.ID) bool {
 	key := id.Key()
 	e, exists := tm.timeoutMap[key]
 	if !exists {
-		return
+		return false
 	}
 	delete(tm.timeoutMap, key)
 	tm.timeoutList.Remove(e)
+	return true
 }
 
 // Returns true if the head was removed, false otherwise
@@ -134,7 +137,7 @@ func (tm *TimeoutManager) registerTimeout() {
 	e := tm.timeoutList.Front()
 	head := e.Value.(timeout)
 
-	timeBound := time.Now().Add(-tm.duration)
+	timeBound := tm.clock.Time().Add(-tm.duration)
 	headTime := head.timer
 	duration := headTime.Sub(timeBound)
 
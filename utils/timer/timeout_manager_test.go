@@ -23,3 +23,24 @@ func TestTimeoutManager(t *testing.T) {
 	tm.Put(ids.NewID([32]byte{}), wg.Done)
 	tm.Put(ids.NewID([32]byte{1}), wg.Done)
 }
+
+func TestTimeoutManagerClock(t *testing.T) {
+	tm := TimeoutManager{}
+	tm.Initialize(time.Second)
+	tm.clock.Set(time.Unix(0, 0))
+
+	fired := false
+	tm.Put(ids.NewID([32]byte{}), func() { fired = true })
+
+	tm.clock.Advance(time.Second / 2)
+	tm.Timeout()
+	if fired {
+		t.Fatalf("Shouldn't have timed out before the duration passed")
+	}
+
+	tm.clock.Advance(time.Second)
+	tm.Timeout()
+	if !fired {
+		t.Fatalf("Should have timed out once the duration passed")
+	}
+}
@@ -0,0 +1,204 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+const (
+	maxWebsiteLen = 256
+)
+
+var (
+	errNilControlInput      = errors.New("nil control input is not valid")
+	errNilControlOutput     = errors.New("nil control output is not valid")
+	errWebsiteTooLong       = fmt.Errorf("website is too long, maximum size is %d", maxWebsiteLen)
+	errInvalidLogoHash      = fmt.Errorf("logo hash must be empty or %d bytes", hashing.HashLen)
+	errControlOutputChanged = errors.New("control output must be produced unchanged")
+	errUncontrollableFx     = errors.New("feature extension doesn't support controlling assets")
+)
+
+// AssetMetadata is the information about an asset that wallets display
+type AssetMetadata struct {
+	Decimals byte   `serialize:"true"` // Number of decimal places amounts are displayed with
+	LogoHash []byte `serialize:"true"` // SHA256 hash of the asset's logo, or empty
+	Website  string `serialize:"true"` // URL of the asset's website, or empty
+}
+
+// Verify that this metadata is well-formed
+func (md *AssetMetadata) Verify() error {
+	switch {
+	case md.Decimals > maxDenomination:
+		return errDenominationTooLarge
+	case len(md.LogoHash) != 0 && len(md.LogoHash) != hashing.HashLen:
+		return errInvalidLogoHash
+	case len(md.Website) > maxWebsiteLen:
+		return errWebsiteTooLong
+	}
+
+	for _, r := range md.Website {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return errUnprintableASCIICharacter
+		}
+	}
+	return nil
+}
+
+// AssetMetadataTx is a transaction that sets the metadata of an asset. It
+// consumes one of the asset's control outputs, such as a mint output, with the
+// signatures of its owners and produces the output again unchanged. Assets
+// without control outputs, such as fixed cap assets, have no metadata.
+type AssetMetadataTx struct {
+	BaseTx `serialize:"true"`
+
+	AssetID    ids.ID          `serialize:"true"` // The asset whose metadata is set
+	ControlIn  *OperableInput  `serialize:"true"` // Consumes a control output of the asset
+	ControlOut *OperableOutput `serialize:"true"` // Produces the consumed control output again
+	Metadata   AssetMetadata   `serialize:"true"` // The asset's new metadata
+}
+
+// InputUTXOs track which UTXOs this transaction is consuming.
+func (t *AssetMetadataTx) InputUTXOs() []*UTXOID {
+	return append(t.BaseTx.InputUTXOs(), &t.ControlIn.UTXOID)
+}
+
+// AssetIDs returns the IDs of the assets this transaction depends on
+func (t *AssetMetadataTx) AssetIDs() ids.Set {
+	assets := t.BaseTx.AssetIDs()
+	assets.Add(t.AssetID)
+	return assets
+}
+
+// UTXOs returns the UTXOs transaction is producing. The control output is
+// indexed after the outputs of the BaseTx.
+func (t *AssetMetadataTx) UTXOs() []*UTXO {
+	utxos := t.BaseTx.UTXOs()
+	return append(utxos, &UTXO{
+		UTXOID: UTXOID{
+			TxID:        t.ID(),
+			OutputIndex: uint32(len(utxos)),
+		},
+		Asset: Asset{
+			ID: t.AssetID,
+		},
+		Out: t.ControlOut.Out,
+	})
+}
+
+// SyntacticVerify that this transaction is well-formed.
+func (t *AssetMetadataTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error {
+	switch {
+	case t == nil:
+		return errNilTx
+	case t.ControlIn == nil:
+		return errNilControlInput
+	case t.ControlOut == nil:
+		return errNilControlOutput
+	}
+
+	if err := t.verifyFormat(ctx, c); err != nil {
+		return err
+	}
+	if err := t.ControlIn.Verify(); err != nil {
+		return err
+	}
+	if err := t.ControlOut.Verify(); err != nil {
+		return err
+	}
+	if err := t.Metadata.Verify(); err != nil {
+		return err
+	}
+
+	controlInputID := t.ControlIn.InputID()
+	for _, in := range t.Ins {
+		if in.InputID().Equals(controlInputID) {
+			return errDoubleSpend
+		}
+	}
+
+	if err := verifyFunds(t.Ins, t.Outs); err != nil {
+		return err
+	}
+	return t.metadata.Verify()
+}
+
+// SemanticVerify that this transaction is valid to be spent.
+func (t *AssetMetadataTx) SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) error {
+	if err := t.BaseTx.SemanticVerify(vm, uTx, creds); err != nil {
+		return err
+	}
+
+	utxo, err := vm.getUTXO(&t.ControlIn.UTXOID)
+	if err != nil {
+		return err
+	}
+	if utxoAssetID := utxo.AssetID(); !utxoAssetID.Equals(t.AssetID) {
+		return errAssetIDMismatch
+	}
+
+	utxoBytes, err := vm.codec.Marshal(&utxo.Out)
+	if err != nil {
+		return err
+	}
+	outBytes, err := vm.codec.Marshal(&t.ControlOut.Out)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(utxoBytes, outBytes) {
+		return errControlOutputChanged
+	}
+
+	fxIndex, err := vm.getFx(t.ControlIn.In)
+	if err != nil {
+		return err
+	}
+	if !vm.verifyFxUsage(fxIndex, t.AssetID) {
+		return errIncompatibleFx
+	}
+	controller, ok := vm.fxs[fxIndex].Fx.(FxController)
+	if !ok {
+		return errUncontrollableFx
+	}
+	return controller.VerifyControl(uTx, utxo.Out, t.ControlIn.In, creds[len(t.Ins)].Cred)
+}
+
+// ExecuteSideEffects records that this transaction set the metadata of the
+// asset
+func (t *AssetMetadataTx) ExecuteSideEffects(vm *VM) error {
+	return vm.state.SetAssetMetadata(t.AssetID, t.ID())
+}
+
+// getUTXO returns the UTXO [utxoID], which is either in the UTXO set or
+// produced by a processing transaction
+func (vm *VM) getUTXO(utxoID *UTXOID) (*UTXO, error) {
+	if utxo, err := vm.state.UTXO(utxoID.InputID()); err == nil {
+		return utxo, nil
+	}
+
+	inputTx, inputIndex := utxoID.InputSource()
+	parent := UniqueTx{
+		vm:   vm,
+		txID: inputTx,
+	}
+	if err := parent.Verify(); err != nil {
+		return nil, errMissingUTXO
+	} else if status := parent.Status(); status.Decided() {
+		return nil, errMissingUTXO
+	}
+
+	utxos := parent.UTXOs()
+	if uint32(len(utxos)) <= inputIndex {
+		return nil, errInvalidUTXO
+	}
+	return utxos[int(inputIndex)], nil
+}
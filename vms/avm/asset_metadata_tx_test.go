@@ -0,0 +1,36 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+func TestAssetMetadataVerify(t *testing.T) {
+	valid := []AssetMetadata{
+		{LogoHash: []byte{}},
+		{Decimals: maxDenomination, LogoHash: hashing.ComputeHash256(nil), Website: "https://example.com"},
+	}
+	for i, md := range valid {
+		if err := md.Verify(); err != nil {
+			t.Fatalf("Metadata %d should have been valid: %s", i, err)
+		}
+	}
+
+	invalid := []AssetMetadata{
+		{Decimals: maxDenomination + 1},
+		{LogoHash: []byte{1, 2, 3}},
+		{Website: strings.Repeat("a", maxWebsiteLen+1)},
+		{Website: "https://example.com/a b"},
+		{Website: "https://例子.com"},
+	}
+	for i, md := range invalid {
+		if err := md.Verify(); err == nil {
+			t.Fatalf("Metadata %d should have been invalid", i)
+		}
+	}
+}
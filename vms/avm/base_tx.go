@@ -86,8 +86,28 @@ func (t *BaseTx) UTXOs() []*UTXO {
 	return utxos
 }
 
+// Burned returns the amount of [assetID] that this transaction consumes but
+// doesn't produce
+func (t *BaseTx) Burned(assetID ids.ID) (uint64, error) { return burned(assetID, t.Ins, t.Outs) }
+
+// ExecuteSideEffects performs the changes, outside of this chain's state, that
+// accepting this transaction causes. A BaseTx has none.
+func (t *BaseTx) ExecuteSideEffects(*VM) error { return nil }
+
 // SyntacticVerify that this transaction is well-formed.
 func (t *BaseTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error {
+	if err := t.verifyFormat(ctx, c); err != nil {
+		return err
+	}
+	if err := verifyFunds(t.Ins, t.Outs); err != nil {
+		return err
+	}
+	return t.metadata.Verify()
+}
+
+// verifyFormat verifies that the fields of this transaction are well-formed,
+// without verifying that the inputs fund the outputs
+func (t *BaseTx) verifyFormat(ctx *snow.Context, c codec.Codec) error {
 	switch {
 	case t == nil:
 		return errNilTx
@@ -114,9 +134,14 @@ func (t *BaseTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error
 	if !isSortedAndUniqueTransferableInputs(t.Ins) {
 		return errInputsNotSortedUnique
 	}
+	return nil
+}
 
+// verifyFunds verifies that [ins] consume at least as much of each asset as
+// [outs] produce
+func verifyFunds(ins []*TransferableInput, outs []*TransferableOutput) error {
 	consumedFunds := map[[32]byte]uint64{}
-	for _, in := range t.Ins {
+	for _, in := range ins {
 		assetID := in.AssetID()
 		amount := in.Input().Amount()
 
@@ -129,7 +154,7 @@ func (t *BaseTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error
 		}
 	}
 	producedFunds := map[[32]byte]uint64{}
-	for _, out := range t.Outs {
+	for _, out := range outs {
 		assetID := out.AssetID()
 		amount := out.Output().Amount()
 
@@ -142,16 +167,13 @@ func (t *BaseTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error
 		}
 	}
 
-	// TODO: Add the Tx fee to the producedFunds
-
 	for assetID, producedAssetAmount := range producedFunds {
 		consumedAssetAmount := consumedFunds[assetID]
 		if producedAssetAmount > consumedAssetAmount {
 			return errInsufficientFunds
 		}
 	}
-
-	return t.metadata.Verify()
+	return nil
 }
 
 // SemanticVerify that this transaction is valid to be spent.
@@ -178,11 +200,13 @@ func (t *BaseTx) SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) erro
 				return errIncompatibleFx
 			}
 
-			err = fx.VerifyTransfer(uTx, utxo.Out, in.In, cred.Cred)
-			if err == nil {
-				continue
+			if err := fx.VerifyTransfer(uTx, utxo.Out, in.In, cred.Cred); err != nil {
+				return err
 			}
-			return err
+			if err := vm.verifyManagedTransfer(fx, utxo); err != nil {
+				return err
+			}
+			continue
 		}
 
 		inputTx, inputIndex := in.InputSource()
@@ -218,6 +242,9 @@ func (t *BaseTx) SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) erro
 		if err := fx.VerifyTransfer(uTx, utxo.Out, in.In, cred); err != nil {
 			return err
 		}
+		if err := vm.verifyManagedTransfer(fx, utxo); err != nil {
+			return err
+		}
 	}
 	return nil
 }
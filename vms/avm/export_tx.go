@@ -0,0 +1,123 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/shared"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNoExportOutputs        = errors.New("no export outputs")
+	errExportToSameChain      = errors.New("can't export to the chain the tx is issued on")
+	errUnsupportedExportedOut = errors.New("only secp256k1fx transfer outputs can be exported")
+)
+
+// ExportTx is a transaction that exports some of the funds it consumes to
+// another chain, through the memory that the chains share
+type ExportTx struct {
+	BaseTx `serialize:"true"`
+
+	DestinationChain ids.ID                `serialize:"true"` // The chain the outputs are exported to
+	ExportedOuts     []*TransferableOutput `serialize:"true"` // The outputs exported to the destination chain
+}
+
+// ExportedUTXOs returns the UTXOs this transaction exports to the destination
+// chain. They are indexed after the outputs that remain on this chain.
+func (t *ExportTx) ExportedUTXOs() []*shared.UTXO {
+	txID := t.ID()
+	utxos := make([]*shared.UTXO, len(t.ExportedOuts))
+	for i, out := range t.ExportedOuts {
+		utxos[i] = &shared.UTXO{
+			TxID:        txID,
+			OutputIndex: uint32(len(t.Outs) + i),
+			AssetID:     out.AssetID(),
+			Out:         *out.Out.(*secp256k1fx.TransferOutput),
+		}
+	}
+	return utxos
+}
+
+// Burned returns the amount of [assetID] that this transaction consumes but
+// neither produces nor exports
+func (t *ExportTx) Burned(assetID ids.ID) (uint64, error) {
+	outs := make([]*TransferableOutput, 0, len(t.Outs)+len(t.ExportedOuts))
+	outs = append(outs, t.Outs...)
+	outs = append(outs, t.ExportedOuts...)
+	return burned(assetID, t.Ins, outs)
+}
+
+// SyntacticVerify that this transaction is well-formed.
+func (t *ExportTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error {
+	switch {
+	case t == nil:
+		return errNilTx
+	case len(t.ExportedOuts) == 0:
+		return errNoExportOutputs
+	case t.DestinationChain.Equals(ctx.ChainID):
+		return errExportToSameChain
+	}
+
+	if err := t.verifyFormat(ctx, c); err != nil {
+		return err
+	}
+
+	for _, out := range t.ExportedOuts {
+		if err := out.Verify(); err != nil {
+			return err
+		}
+		if _, ok := out.Out.(*secp256k1fx.TransferOutput); !ok {
+			return errUnsupportedExportedOut
+		}
+	}
+	if !isSortedTransferableOutputs(t.ExportedOuts, c) {
+		return errOutputsNotSorted
+	}
+
+	outs := make([]*TransferableOutput, 0, len(t.Outs)+len(t.ExportedOuts))
+	outs = append(outs, t.Outs...)
+	outs = append(outs, t.ExportedOuts...)
+	if err := verifyFunds(t.Ins, outs); err != nil {
+		return err
+	}
+	return t.metadata.Verify()
+}
+
+// SemanticVerify that this transaction is valid to be spent.
+func (t *ExportTx) SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) error {
+	if err := t.BaseTx.SemanticVerify(vm, uTx, creds); err != nil {
+		return err
+	}
+
+	fxIndex, err := vm.secpFxIndex()
+	if err != nil {
+		return err
+	}
+	for _, out := range t.ExportedOuts {
+		if !vm.verifyFxUsage(fxIndex, out.AssetID()) {
+			return errIncompatibleFx
+		}
+	}
+	return nil
+}
+
+// ExecuteSideEffects writes the exported UTXOs to the memory shared with the
+// destination chain
+func (t *ExportTx) ExecuteSideEffects(vm *VM) error {
+	db := vm.ctx.SharedMemory.GetDatabase(t.DestinationChain)
+	defer vm.ctx.SharedMemory.ReleaseDatabase(t.DestinationChain)
+
+	state := shared.NewState(db, t.DestinationChain)
+	for _, utxo := range t.ExportedUTXOs() {
+		if err := state.FundUTXO(utxo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
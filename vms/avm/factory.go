@@ -13,7 +13,10 @@ var (
 )
 
 // Factory ...
-type Factory struct{}
+type Factory struct {
+	// Fees is the fee schedule of the chains this factory creates
+	Fees FeeConfig
+}
 
 // New ...
-func (f *Factory) New() interface{} { return &VM{} }
+func (f *Factory) New() interface{} { return &VM{fees: f.Fees} }
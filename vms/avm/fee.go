@@ -0,0 +1,124 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/math"
+)
+
+var (
+	errFeeOverflow     = errors.New("fee overflowed uint64")
+	errBurnOverflow    = errors.New("burned amount overflowed uint64")
+	errFeeNotConverged = errors.New("couldn't find inputs that pay the transaction's fee")
+)
+
+// FeeConfig is the schedule of the fees that transactions pay. A transaction's
+// fee is paid by consuming more of the fee asset than it produces; the
+// difference is burned.
+//
+// The fee of a transaction is:
+// TxFee + ByteFee * [size of the signed tx] + OperationFee * [number of UTXOs
+// the tx consumes and produces on this chain]
+type FeeConfig struct {
+	// AssetID is the asset that fees are paid in
+	AssetID ids.ID
+
+	// StartTime is when fees start being charged, according to the local
+	// clock. Transactions verified before then don't pay a fee.
+	StartTime time.Time
+
+	TxFee        uint64
+	ByteFee      uint64
+	OperationFee uint64
+}
+
+// Enabled returns true if transactions verified at [now] pay fees
+func (f *FeeConfig) Enabled(now time.Time) bool {
+	return !f.AssetID.IsZero() && !now.Before(f.StartTime) &&
+		(f.TxFee != 0 || f.ByteFee != 0 || f.OperationFee != 0)
+}
+
+// Fee returns the fee of a transaction that is [size] bytes long and consumes
+// and produces [numOperations] UTXOs
+func (f *FeeConfig) Fee(size, numOperations int) (uint64, error) {
+	byteFee, err := math.Mul64(f.ByteFee, uint64(size))
+	if err != nil {
+		return 0, errFeeOverflow
+	}
+	operationFee, err := math.Mul64(f.OperationFee, uint64(numOperations))
+	if err != nil {
+		return 0, errFeeOverflow
+	}
+	fee, err := math.Add64(f.TxFee, byteFee)
+	if err != nil {
+		return 0, errFeeOverflow
+	}
+	fee, err = math.Add64(fee, operationFee)
+	if err != nil {
+		return 0, errFeeOverflow
+	}
+	return fee, nil
+}
+
+// txFee returns the fee that [tx], whose signed bytes are [txBytes], must pay
+// now. If fees aren't being charged, the fee is 0.
+func (vm *VM) txFee(tx *Tx, txBytes []byte) (uint64, error) {
+	if !vm.fees.Enabled(vm.clock.Time()) {
+		return 0, nil
+	}
+	return vm.fees.Fee(len(txBytes), len(tx.InputUTXOs())+len(tx.UTXOs()))
+}
+
+// verifyFee verifies that [tx], whose signed bytes are [txBytes], burns at
+// least the fee it must pay
+func (vm *VM) verifyFee(tx *Tx, txBytes []byte) error {
+	fee, err := vm.txFee(tx, txBytes)
+	if err != nil || fee == 0 {
+		return err
+	}
+	burned, err := tx.Burned(vm.fees.AssetID)
+	if err != nil {
+		return err
+	}
+	if burned < fee {
+		return fmt.Errorf("tx burns %d of the fee asset but its fee is %d", burned, fee)
+	}
+	return nil
+}
+
+// burned returns the amount of [assetID] that [ins] consume but [outs] don't
+// produce
+func burned(assetID ids.ID, ins []*TransferableInput, outs []*TransferableOutput) (uint64, error) {
+	consumed := uint64(0)
+	for _, in := range ins {
+		if !in.AssetID().Equals(assetID) {
+			continue
+		}
+		amount, err := math.Add64(consumed, in.Input().Amount())
+		if err != nil {
+			return 0, errBurnOverflow
+		}
+		consumed = amount
+	}
+	produced := uint64(0)
+	for _, out := range outs {
+		if !out.AssetID().Equals(assetID) {
+			continue
+		}
+		amount, err := math.Add64(produced, out.Output().Amount())
+		if err != nil {
+			return 0, errBurnOverflow
+		}
+		produced = amount
+	}
+	if produced > consumed {
+		return 0, errInsufficientFunds
+	}
+	return consumed - produced, nil
+}
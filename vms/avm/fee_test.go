@@ -0,0 +1,264 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+func TestFeeConfigFee(t *testing.T) {
+	fees := FeeConfig{
+		AssetID:      ids.Empty.Prefix(1),
+		TxFee:        1000,
+		ByteFee:      10,
+		OperationFee: 100,
+	}
+	if fee, err := fees.Fee(200, 3); err != nil {
+		t.Fatal(err)
+	} else if fee != 3300 {
+		t.Fatalf("fee should be 3300 but is %d", fee)
+	}
+
+	fees.ByteFee = math.MaxUint64
+	if _, err := fees.Fee(2, 0); err == nil {
+		t.Fatalf("should have failed due to the fee overflowing")
+	}
+}
+
+func TestFeeConfigEnabled(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	fees := FeeConfig{TxFee: 1}
+	if fees.Enabled(now) {
+		t.Fatalf("fees without an asset shouldn't be enabled")
+	}
+
+	fees.AssetID = ids.Empty.Prefix(1)
+	if !fees.Enabled(now) {
+		t.Fatalf("fees should be enabled")
+	}
+
+	fees.StartTime = now.Add(time.Second)
+	if fees.Enabled(now) {
+		t.Fatalf("fees shouldn't be enabled before their start time")
+	}
+
+	fees = FeeConfig{AssetID: ids.Empty.Prefix(1)}
+	if fees.Enabled(now) {
+		t.Fatalf("fees of zero shouldn't be enabled")
+	}
+}
+
+func TestSendPaysFee(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	keystore := testKeystore{}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{fees: FeeConfig{
+		AssetID:      genesisTx.ID(),
+		TxFee:        1000,
+		ByteFee:      10,
+		OperationFee: 100,
+	}}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	db := memdb.New()
+	user := userState{vm: vm}
+	if err := user.SetKey(db, keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	addr := ids.NewID(hashing.ComputeHash256Array(keys[0].PublicKey().Address().Bytes()))
+	if err := user.SetAddresses(db, []ids.ID{addr}); err != nil {
+		t.Fatal(err)
+	}
+	keystore["alice"] = db
+
+	s := Service{vm: vm}
+
+	// A transaction that doesn't burn its fee is rejected
+	ins, outs, signers, err := s.spend("alice", "", map[[32]byte]uint64{
+		genesisTx.ID().Key(): 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outs = append(outs, &TransferableOutput{
+		Asset: Asset{ID: genesisTx.ID()},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{keys[1].PublicKey().Address()},
+			},
+		},
+	})
+	sortTransferableOutputs(outs, vm.codec)
+	tx, err := s.sign(&BaseTx{
+		NetID: vm.ctx.NetworkID,
+		BCID:  vm.ctx.ChainID,
+		Outs:  outs,
+		Ins:   ins,
+	}, signers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBytes, err := vm.codec.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.IssueTx(txBytes); err == nil {
+		t.Fatalf("should have failed to issue a transaction that doesn't pay its fee")
+	}
+
+	// The wallet pays the fee
+	reply := SendReply{}
+	if err := s.Send(nil, &SendArgs{
+		Username: "alice",
+		Amount:   1000,
+		AssetID:  genesisTx.ID().String(),
+		To:       vm.Format(keys[1].PublicKey().Address().Bytes()),
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+
+	sendTx := UniqueTx{vm: vm, txID: reply.TxID}
+	sendBytes := sendTx.Bytes()
+	fee, err := vm.txFee(sendTx.t.tx, sendBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if burned, err := sendTx.t.tx.Burned(genesisTx.ID()); err != nil {
+		t.Fatal(err)
+	} else if burned != fee {
+		t.Fatalf("should have burned %d but burned %d", fee, burned)
+	}
+}
+
+func TestEstimateFee(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	keystore := testKeystore{}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{fees: FeeConfig{
+		AssetID:      genesisTx.ID(),
+		TxFee:        1000,
+		ByteFee:      10,
+		OperationFee: 100,
+	}}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	db := memdb.New()
+	user := userState{vm: vm}
+	if err := user.SetKey(db, keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	addr := ids.NewID(hashing.ComputeHash256Array(keys[0].PublicKey().Address().Bytes()))
+	if err := user.SetAddresses(db, []ids.ID{addr}); err != nil {
+		t.Fatal(err)
+	}
+	keystore["alice"] = db
+
+	s := Service{vm: vm}
+
+	reply := EstimateFeeReply{}
+	if err := s.EstimateFee(nil, &EstimateFeeArgs{}, &reply); err != errNoTxOrSize {
+		t.Fatalf("Should have errored with %s", errNoTxOrSize)
+	}
+	if err := s.EstimateFee(nil, &EstimateFeeArgs{Size: 200, NumOperations: 3}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Fee != 1000+10*200+100*3 {
+		t.Fatalf("Wrong fee returned: %d", reply.Fee)
+	}
+	if !reply.AssetID.Equals(genesisTx.ID()) {
+		t.Fatalf("Wrong fee asset returned: %s", reply.AssetID)
+	}
+
+	ins, outs, signers, err := s.spend("alice", "", map[[32]byte]uint64{
+		genesisTx.ID().Key(): 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	utx := &BaseTx{
+		NetID: vm.ctx.NetworkID,
+		BCID:  vm.ctx.ChainID,
+		Outs:  outs,
+		Ins:   ins,
+	}
+	unsignedBytes, err := vm.codec.Marshal(&Tx{UnsignedTx: utx, Creds: []*Credential{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := s.sign(utx, signers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBytes, err := vm.codec.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fee, err := vm.txFee(tx, txBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fee of the unsigned transaction is that of the signed one
+	for _, b := range [][]byte{unsignedBytes, txBytes} {
+		reply := EstimateFeeReply{}
+		if err := s.EstimateFee(nil, &EstimateFeeArgs{Tx: formatting.CB58{Bytes: b}}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if uint64(reply.Fee) != fee {
+			t.Fatalf("Estimated a fee of %d but the fee is %d", reply.Fee, fee)
+		}
+	}
+}
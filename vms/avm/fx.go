@@ -47,3 +47,34 @@ type FxTransferable interface {
 type FxAddressable interface {
 	Addresses() [][]byte
 }
+
+// FxCredentialRecoverer is the interface a feature extension may provide to
+// recover the signers of its credentials ahead of verification. Credentials of
+// different transactions are recovered concurrently.
+type FxCredentialRecoverer interface {
+	// RecoverCredential recovers the signers of [cred] over [tx] and caches
+	// them for when the credential is verified
+	RecoverCredential(tx, cred interface{}) error
+}
+
+// FxController is the interface a feature extension may provide to let the
+// owners of an asset's control outputs, such as its mint outputs, authorize
+// changes to the asset other than to its supply
+type FxController interface {
+	// VerifyControl verifies that [utxo] is a control output and that the
+	// credential shows that its owners signed [tx]
+	VerifyControl(tx, utxo, in, cred interface{}) error
+}
+
+// FxManager is the interface a feature extension may provide to restrict
+// transfers of an asset by the asset's managed state. The managed state of an
+// asset is held by its most recently accepted output that the feature
+// extension reports holds it.
+type FxManager interface {
+	// IsManagedState returns true if [out] holds the managed state of its asset
+	IsManagedState(out interface{}) bool
+
+	// VerifyManagedTransfer verifies that the managed state of the asset, held
+	// by [state], allows [utxo] to be spent
+	VerifyManagedTransfer(state, utxo interface{}) error
+}
@@ -0,0 +1,157 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/shared"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNoImportInputs        = errors.New("no import inputs")
+	errImportFromSameChain   = errors.New("can't import from the chain the tx is issued on")
+	errUnsupportedImportedIn = errors.New("only secp256k1fx transfer inputs can be imported")
+)
+
+// ImportTx is a transaction that imports funds that another chain exported to
+// this chain, through the memory that the chains share
+type ImportTx struct {
+	BaseTx `serialize:"true"`
+
+	SourceChain ids.ID               `serialize:"true"` // The chain the funds are imported from
+	ImportedIns []*TransferableInput `serialize:"true"` // The inputs consuming the exported UTXOs
+}
+
+// InputUTXOs track which UTXOs this transaction is consuming. The imported
+// UTXOs are symbolic, as they aren't part of this chain's state.
+func (t *ImportTx) InputUTXOs() []*UTXOID {
+	utxos := t.BaseTx.InputUTXOs()
+	for _, in := range t.ImportedIns {
+		utxoID := in.UTXOID
+		utxoID.Symbol = true
+		utxos = append(utxos, &utxoID)
+	}
+	return utxos
+}
+
+// AssetIDs returns the IDs of the assets this transaction depends on
+func (t *ImportTx) AssetIDs() ids.Set {
+	assets := t.BaseTx.AssetIDs()
+	for _, in := range t.ImportedIns {
+		assets.Add(in.AssetID())
+	}
+	return assets
+}
+
+// SyntacticVerify that this transaction is well-formed.
+// Burned returns the amount of [assetID] that this transaction consumes,
+// including the imported funds, but doesn't produce
+func (t *ImportTx) Burned(assetID ids.ID) (uint64, error) {
+	ins := make([]*TransferableInput, 0, len(t.Ins)+len(t.ImportedIns))
+	ins = append(ins, t.Ins...)
+	ins = append(ins, t.ImportedIns...)
+	return burned(assetID, ins, t.Outs)
+}
+
+// SyntacticVerify that this import transaction is well-formed.
+func (t *ImportTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error {
+	switch {
+	case t == nil:
+		return errNilTx
+	case len(t.ImportedIns) == 0:
+		return errNoImportInputs
+	case t.SourceChain.Equals(ctx.ChainID):
+		return errImportFromSameChain
+	}
+
+	if err := t.verifyFormat(ctx, c); err != nil {
+		return err
+	}
+
+	for _, in := range t.ImportedIns {
+		if err := in.Verify(); err != nil {
+			return err
+		}
+		if _, ok := in.In.(*secp256k1fx.TransferInput); !ok {
+			return errUnsupportedImportedIn
+		}
+	}
+	if !isSortedAndUniqueTransferableInputs(t.ImportedIns) {
+		return errInputsNotSortedUnique
+	}
+
+	ins := make([]*TransferableInput, 0, len(t.Ins)+len(t.ImportedIns))
+	ins = append(ins, t.Ins...)
+	ins = append(ins, t.ImportedIns...)
+	if err := verifyFunds(ins, t.Outs); err != nil {
+		return err
+	}
+	return t.metadata.Verify()
+}
+
+// SemanticVerify that this transaction is valid to be spent.
+func (t *ImportTx) SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) error {
+	if err := t.BaseTx.SemanticVerify(vm, uTx, creds); err != nil {
+		return err
+	}
+
+	fxIndex, err := vm.secpFxIndex()
+	if err != nil {
+		return err
+	}
+	fx := vm.fxs[fxIndex].Fx
+
+	db := vm.ctx.SharedMemory.GetDatabase(t.SourceChain)
+	defer vm.ctx.SharedMemory.ReleaseDatabase(t.SourceChain)
+
+	state := shared.NewState(db, vm.ctx.ChainID)
+	offset := len(t.Ins)
+	for i, in := range t.ImportedIns {
+		cred := creds[i+offset]
+
+		if credFxIndex, err := vm.getFx(cred.Cred); err != nil {
+			return err
+		} else if credFxIndex != fxIndex {
+			return errIncompatibleFx
+		}
+
+		utxo, err := state.UTXO(in.InputID())
+		if err != nil {
+			return errMissingUTXO
+		}
+
+		inAssetID := in.AssetID()
+		if !utxo.AssetID.Equals(inAssetID) {
+			return errAssetIDMismatch
+		}
+		if !vm.verifyFxUsage(fxIndex, inAssetID) {
+			return errIncompatibleFx
+		}
+
+		if err := fx.VerifyTransfer(uTx, &utxo.Out, in.In, cred.Cred); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExecuteSideEffects removes the imported UTXOs from the memory shared with
+// the source chain
+func (t *ImportTx) ExecuteSideEffects(vm *VM) error {
+	db := vm.ctx.SharedMemory.GetDatabase(t.SourceChain)
+	defer vm.ctx.SharedMemory.ReleaseDatabase(t.SourceChain)
+
+	state := shared.NewState(db, vm.ctx.ChainID)
+	for _, in := range t.ImportedIns {
+		if err := state.SpendUTXO(in.InputID()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ava-labs/gecko/api"
+	"github.com/ava-labs/gecko/utils/crypto/ledger"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+// LedgerService signs transactions on the Ledger device connected to this
+// node. Its handler doesn't hold the chain's lock, since signing waits for the
+// signature to be approved on the device. The lock is only held while the
+// chain's state is read.
+type LedgerService struct {
+	vm *VM
+
+	// open returns a connection to the device
+	open func() (*ledger.Ledger, error)
+}
+
+// LedgerSignTxArgs are arguments for passing into SignTx requests
+type LedgerSignTxArgs struct {
+	// The unsigned or partially signed transaction, such as one returned by
+	// CreateSpendTx
+	Tx formatting.CB58 `json:"tx"`
+
+	// The index of the key on the device that signs the transaction
+	Index json.Uint32 `json:"index"`
+}
+
+// LedgerSignTxReply defines the SignTx replies returned from the API
+type LedgerSignTxReply struct {
+	Tx     formatting.CB58 `json:"tx"`
+	Signer string          `json:"signer"`
+}
+
+// SignTx adds the signature of the key at [args.Index] on the Ledger device to
+// each input of [args.Tx] that requires it, as SignTx of the avm service does
+// with a user's key. The signature must be approved on the device.
+func (service *LedgerService) SignTx(r *http.Request, args *LedgerSignTxArgs, reply *LedgerSignTxReply) error {
+	service.vm.ctx.Log.Verbo("SignTx called on the Ledger endpoint in request %s", api.RequestID(r))
+
+	tx := Tx{}
+	if err := service.vm.codec.Unmarshal(args.Tx.Bytes, &tx); err != nil {
+		return fmt.Errorf("problem parsing transaction: %w", err)
+	}
+	unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	device, err := service.open()
+	if err != nil {
+		return fmt.Errorf("problem opening Ledger device: %w", err)
+	}
+	defer device.Close()
+
+	key, err := device.Signer(uint32(args.Index))
+	if err != nil {
+		return fmt.Errorf("problem getting key %d from Ledger device: %w", args.Index, err)
+	}
+	sig, err := key.SignHash(hashing.ComputeHash256(unsignedBytes))
+	if err != nil {
+		return fmt.Errorf("problem signing transaction: %w", err)
+	}
+
+	// The inputs the key signs depend on the UTXOs they spend
+	signer := key.PublicKey().Address()
+	service.vm.ctx.Lock.Lock()
+	err = (&Service{vm: service.vm}).addSignature(&tx, signer, sig)
+	service.vm.ctx.Lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	txBytes, err := service.vm.codec.Marshal(&tx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+	reply.Tx.Bytes = txBytes
+	reply.Signer = service.vm.Format(signer.Bytes())
+	return nil
+}
@@ -0,0 +1,106 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/crypto/ledger"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+func TestLedgerSignTx(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	ctx.Lock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		ctx.Lock.Lock()
+		vm.Shutdown()
+		ctx.Lock.Unlock()
+	}()
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	addr0 := vm.Format(keys[0].PublicKey().Address().Bytes())
+	addr1 := vm.Format(keys[1].PublicKey().Address().Bytes())
+
+	s := Service{vm: vm}
+	createReply := CreateSpendTxReply{}
+	ctx.Lock.Lock()
+	err = s.CreateSpendTx(nil, &CreateSpendTxArgs{
+		Amount:  600,
+		AssetID: genesisTx.ID().String(),
+		To:      addr1,
+		Signers: []string{addr0},
+	}, &createReply)
+	ctx.Lock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The chain's lock isn't held while the signature is approved
+	device := ledger.NewEmulator(keys[0])
+	device.Approve = func() bool {
+		locked := make(chan struct{})
+		go func() {
+			ctx.Lock.Lock()
+			ctx.Lock.Unlock()
+			close(locked)
+		}()
+		select {
+		case <-locked:
+			return true
+		case <-time.After(5 * time.Second):
+			t.Error("the chain's lock was held while the signature was approved")
+			return false
+		}
+	}
+	ls := LedgerService{
+		vm:   vm,
+		open: func() (*ledger.Ledger, error) { return ledger.New(device), nil },
+	}
+
+	signReply := LedgerSignTxReply{}
+	if err := ls.SignTx(nil, &LedgerSignTxArgs{Tx: createReply.Tx}, &signReply); err != nil {
+		t.Fatal(err)
+	}
+	if signReply.Signer != addr0 {
+		t.Fatalf("signer should be %s but is %s", addr0, signReply.Signer)
+	}
+
+	ctx.Lock.Lock()
+	_, err = vm.IssueTx(signReply.Tx.Bytes)
+	ctx.Lock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A key that no input needs can't sign the transaction
+	device.SetKey(keys[1])
+	if err := ls.SignTx(nil, &LedgerSignTxArgs{Tx: createReply.Tx}, &LedgerSignTxReply{}); err != errUnneededAddress {
+		t.Fatalf("should have failed with %s but failed with %v", errUnneededAddress, err)
+	}
+
+	if handler := vm.CreateHandlers()["/ledger"]; handler == nil || handler.LockOptions != common.NoLock {
+		t.Fatalf("the Ledger endpoint should be registered with NoLock")
+	}
+}
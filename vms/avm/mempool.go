@@ -0,0 +1,240 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"container/heap"
+	"errors"
+	"math/bits"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	errDuplicateTx = errors.New("transaction is already in the mempool")
+	errTxTooLarge  = errors.New("transaction is larger than the mempool")
+	errMempoolFull = errors.New("mempool is full and the transaction doesn't pay a high enough fee to replace another")
+)
+
+// mempool holds the transactions that were issued to this VM but haven't been
+// handed to consensus yet. It holds at most [maxTxs] transactions and [maxSize]
+// bytes of them.
+//
+// When the mempool is full, the transactions that burn the least of the fee
+// asset per byte are evicted to make room for a transaction that burns more.
+// Of the transactions that burn the same amount per byte, the newest is
+// evicted first, so a transaction can't be pushed out by others that pay no
+// more than it does.
+type mempool struct {
+	maxTxs, maxSize int
+
+	size int    // Sum of the sizes of the transactions
+	seq  uint64 // Number of transactions that have been added
+
+	txs  map[[32]byte]*mempoolTx
+	heap mempoolHeap // Lowest priority first
+
+	metrics mempoolMetrics
+}
+
+type mempoolTx struct {
+	tx     snowstorm.Tx
+	size   int
+	burned uint64 // Amount of the fee asset burned
+	seq    uint64 // Order the transaction was added in
+}
+
+// Initialize the mempool and its metrics
+func (m *mempool) Initialize(maxTxs, maxSize int, namespace string) {
+	m.maxTxs = maxTxs
+	m.maxSize = maxSize
+	m.txs = make(map[[32]byte]*mempoolTx)
+	m.metrics.Initialize(namespace)
+}
+
+// RegisterMetrics registers the mempool's metrics with [registerer]
+func (m *mempool) RegisterMetrics(registerer prometheus.Registerer) error {
+	return m.metrics.Register(registerer)
+}
+
+// Len returns the number of transactions in the mempool
+func (m *mempool) Len() int { return len(m.heap) }
+
+// Has returns true if the transaction [txID] is in the mempool
+func (m *mempool) Has(txID ids.ID) bool {
+	_, exists := m.txs[txID.Key()]
+	return exists
+}
+
+// Add [tx], which burns [burned] of the fee asset, to the mempool. Returns the
+// transactions that were evicted to make room for it.
+func (m *mempool) Add(tx snowstorm.Tx, burned uint64) ([]snowstorm.Tx, error) {
+	if m.Has(tx.ID()) {
+		m.metrics.rejected.Inc()
+		return nil, errDuplicateTx
+	}
+
+	newTx := &mempoolTx{
+		tx:     tx,
+		size:   len(tx.Bytes()),
+		burned: burned,
+		seq:    m.seq,
+	}
+	if newTx.size > m.maxSize {
+		m.metrics.rejected.Inc()
+		return nil, errTxTooLarge
+	}
+
+	// Evict the lowest priority transactions until there is room, unless the
+	// new transaction has a lower priority than one of them
+	evicted := []*mempoolTx(nil)
+	for len(m.heap) >= m.maxTxs || m.size+newTx.size > m.maxSize {
+		if len(m.heap) == 0 || !m.heap[0].lessThan(newTx) {
+			for _, evictedTx := range evicted {
+				m.push(evictedTx)
+			}
+			m.metrics.rejected.Inc()
+			return nil, errMempoolFull
+		}
+		evicted = append(evicted, m.pop())
+	}
+
+	m.seq++
+	m.push(newTx)
+	m.metrics.added.Inc()
+	m.metrics.evicted.Add(float64(len(evicted)))
+
+	evictedTxs := make([]snowstorm.Tx, len(evicted))
+	for i, evictedTx := range evicted {
+		evictedTxs[i] = evictedTx.tx
+	}
+	return evictedTxs, nil
+}
+
+// Clear the mempool and return its transactions in the order they were added
+func (m *mempool) Clear() []snowstorm.Tx {
+	entries := m.heap
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+
+	txs := make([]snowstorm.Tx, len(entries))
+	for i, entry := range entries {
+		txs[i] = entry.tx
+	}
+
+	m.size = 0
+	m.txs = make(map[[32]byte]*mempoolTx)
+	m.heap = nil
+	m.metrics.update(m)
+	return txs
+}
+
+func (m *mempool) push(tx *mempoolTx) {
+	heap.Push(&m.heap, tx)
+	m.txs[tx.tx.ID().Key()] = tx
+	m.size += tx.size
+	m.metrics.update(m)
+}
+
+func (m *mempool) pop() *mempoolTx {
+	tx := heap.Pop(&m.heap).(*mempoolTx)
+	delete(m.txs, tx.tx.ID().Key())
+	m.size -= tx.size
+	m.metrics.update(m)
+	return tx
+}
+
+// lessThan returns true if [tx] should be evicted before [other]
+func (tx *mempoolTx) lessThan(other *mempoolTx) bool {
+	// Compare tx.burned / tx.size with other.burned / other.size without
+	// losing precision
+	hi, lo := bits.Mul64(tx.burned, uint64(other.size))
+	otherHi, otherLo := bits.Mul64(other.burned, uint64(tx.size))
+	switch {
+	case hi != otherHi:
+		return hi < otherHi
+	case lo != otherLo:
+		return lo < otherLo
+	default:
+		return tx.seq > other.seq
+	}
+}
+
+// mempoolHeap implements heap.Interface, with the lowest priority transaction
+// first
+type mempoolHeap []*mempoolTx
+
+func (h mempoolHeap) Len() int            { return len(h) }
+func (h mempoolHeap) Less(i, j int) bool  { return h[i].lessThan(h[j]) }
+func (h mempoolHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *mempoolHeap) Push(x interface{}) { *h = append(*h, x.(*mempoolTx)) }
+func (h *mempoolHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	tx := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return tx
+}
+
+type mempoolMetrics struct {
+	txs, size                prometheus.Gauge
+	added, evicted, rejected prometheus.Counter
+}
+
+// Initialize the mempool's metrics
+func (m *mempoolMetrics) Initialize(namespace string) {
+	m.txs = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "mempool_txs",
+			Help:      "Number of transactions in the mempool",
+		})
+	m.size = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "mempool_size",
+			Help:      "Number of bytes of transactions in the mempool",
+		})
+	m.added = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mempool_added",
+			Help:      "Number of transactions added to the mempool",
+		})
+	m.evicted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mempool_evicted",
+			Help:      "Number of transactions evicted from the mempool to make room for others",
+		})
+	m.rejected = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mempool_rejected",
+			Help:      "Number of transactions that weren't added to the mempool",
+		})
+}
+
+// Register the mempool's metrics with [registerer]
+func (m *mempoolMetrics) Register(registerer prometheus.Registerer) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.txs),
+		registerer.Register(m.size),
+		registerer.Register(m.added),
+		registerer.Register(m.evicted),
+		registerer.Register(m.rejected),
+	)
+	return errs.Err
+}
+
+func (m *mempoolMetrics) update(pool *mempool) {
+	m.txs.Set(float64(len(pool.heap)))
+	m.size.Set(float64(pool.size))
+}
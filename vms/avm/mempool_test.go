@@ -0,0 +1,135 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
+)
+
+func newTestMempool(t *testing.T, maxTxs, maxSize int) *mempool {
+	m := &mempool{}
+	m.Initialize(maxTxs, maxSize, "")
+	if err := m.RegisterMetrics(prometheus.NewRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func newMempoolTestTx(i uint64, size int) *snowstorm.TestTx {
+	return &snowstorm.TestTx{
+		Identifier: ids.Empty.Prefix(i),
+		Bits:       make([]byte, size),
+	}
+}
+
+func TestMempoolDuplicate(t *testing.T) {
+	m := newTestMempool(t, 10, 1000)
+
+	tx := newMempoolTestTx(0, 10)
+	if _, err := m.Add(tx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(tx, 0); err != errDuplicateTx {
+		t.Fatalf("should have rejected the duplicate tx but got: %v", err)
+	}
+	if m.Len() != 1 {
+		t.Fatalf("mempool should have 1 tx but has %d", m.Len())
+	}
+}
+
+func TestMempoolEvictsLowestFee(t *testing.T) {
+	m := newTestMempool(t, 2, 1000)
+
+	tx0 := newMempoolTestTx(0, 10)
+	tx1 := newMempoolTestTx(1, 10)
+	tx2 := newMempoolTestTx(2, 10)
+	tx3 := newMempoolTestTx(3, 10)
+
+	if _, err := m.Add(tx0, 20); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(tx1, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tx that pays no more than the others can't replace them
+	if _, err := m.Add(tx2, 10); err != errMempoolFull {
+		t.Fatalf("should have rejected the tx but got: %v", err)
+	}
+
+	evicted, err := m.Add(tx3, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 1 || !evicted[0].ID().Equals(tx1.ID()) {
+		t.Fatalf("should have evicted %s but evicted %v", tx1.ID(), evicted)
+	}
+	if m.Has(tx1.ID()) || !m.Has(tx0.ID()) || !m.Has(tx3.ID()) {
+		t.Fatalf("wrong txs in the mempool")
+	}
+}
+
+func TestMempoolSizeLimit(t *testing.T) {
+	m := newTestMempool(t, 10, 100)
+
+	if _, err := m.Add(newMempoolTestTx(0, 101), 1000); err != errTxTooLarge {
+		t.Fatalf("should have rejected the tx but got: %v", err)
+	}
+
+	small0 := newMempoolTestTx(1, 40)
+	small1 := newMempoolTestTx(2, 40)
+	if _, err := m.Add(small0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(small1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both small txs must be evicted to make room for the large one
+	large := newMempoolTestTx(3, 90)
+	evicted, err := m.Add(large, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 2 {
+		t.Fatalf("should have evicted 2 txs but evicted %d", len(evicted))
+	}
+	if m.Len() != 1 || m.size != 90 {
+		t.Fatalf("mempool should hold 1 tx of 90 bytes but holds %d txs of %d bytes", m.Len(), m.size)
+	}
+}
+
+func TestMempoolClear(t *testing.T) {
+	m := newTestMempool(t, 10, 1000)
+
+	txs := []*snowstorm.TestTx{
+		newMempoolTestTx(0, 10),
+		newMempoolTestTx(1, 10),
+		newMempoolTestTx(2, 10),
+	}
+	// Fees don't change the order txs are handed to consensus in
+	for i, tx := range txs {
+		if _, err := m.Add(tx, uint64(len(txs)-i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cleared := m.Clear()
+	if len(cleared) != len(txs) {
+		t.Fatalf("should have returned %d txs but returned %d", len(txs), len(cleared))
+	}
+	for i, tx := range cleared {
+		if !tx.ID().Equals(txs[i].ID()) {
+			t.Fatalf("tx %d should be %s but is %s", i, txs[i].ID(), tx.ID())
+		}
+	}
+	if m.Len() != 0 || m.size != 0 || m.Has(txs[0].ID()) {
+		t.Fatalf("mempool should be empty")
+	}
+}
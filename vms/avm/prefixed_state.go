@@ -5,6 +5,7 @@ package avm
 
 import (
 	"github.com/ava-labs/gecko/cache"
+	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/utils/hashing"
@@ -16,10 +17,16 @@ const (
 	txStatusID
 	fundsID
 	dbInitializedID
+	assetMetadataID
+	managedStateID
 )
 
 var (
 	dbInitialized = ids.Empty.Prefix(dbInitializedID)
+
+	// fundsPrefix prefixes the index of the UTXOs each address owns, which
+	// stores an empty value under [address ID] + [UTXO ID]
+	fundsPrefix = []byte("funds")
 )
 
 // prefixedState wraps a state object. By prefixing the state, there will be no
@@ -29,6 +36,9 @@ type prefixedState struct {
 
 	tx, utxo, txStatus, funds cache.Cacher
 	uniqueTx                  cache.Deduplicator
+
+	// fundsIndex maps addresses to the UTXOs they own
+	fundsIndex database.Database
 }
 
 // UniqueTx de-duplicates the transaction.
@@ -73,15 +83,88 @@ func (s *prefixedState) SetDBInitialized(status choices.Status) error {
 	return s.state.SetStatus(dbInitialized, status)
 }
 
-// Funds returns the mapping from the 32 byte representation of an address to a
-// list of utxo IDs that reference the address.
-func (s *prefixedState) Funds(id ids.ID) ([]ids.ID, error) {
-	return s.state.IDs(s.uniqueID(id, fundsID, s.funds))
+// AssetMetadata returns the ID of the accepted transaction that last set the
+// metadata of the asset [assetID]
+func (s *prefixedState) AssetMetadata(assetID ids.ID) (ids.ID, error) {
+	txIDs, err := s.state.IDs(assetID.Prefix(assetMetadataID))
+	if err != nil {
+		return ids.ID{}, err
+	}
+	return txIDs[0], nil
+}
+
+// SetAssetMetadata saves that the transaction [txID] last set the metadata of
+// the asset [assetID]
+func (s *prefixedState) SetAssetMetadata(assetID, txID ids.ID) error {
+	return s.state.SetIDs(assetID.Prefix(assetMetadataID), []ids.ID{txID})
+}
+
+// ManagedState returns the output of the asset [assetID] that holds the
+// asset's managed state
+func (s *prefixedState) ManagedState(assetID ids.ID) (*UTXO, error) {
+	return s.state.UTXO(assetID.Prefix(managedStateID))
+}
+
+// SetManagedState saves that [utxo] holds the managed state of its asset
+func (s *prefixedState) SetManagedState(utxo *UTXO) error {
+	return s.state.SetUTXO(utxo.AssetID().Prefix(managedStateID), utxo)
 }
 
-// SetFunds saves the mapping from address to utxo IDs to storage.
-func (s *prefixedState) SetFunds(id ids.ID, idSlice []ids.ID) error {
-	return s.state.SetIDs(s.uniqueID(id, fundsID, s.funds), idSlice)
+// Funds returns the IDs of the UTXOs that reference the address whose 32 byte
+// representation is [addrID]
+func (s *prefixedState) Funds(addrID ids.ID) ([]ids.ID, error) {
+	return s.PaginatedFunds(addrID, ids.ID{}, -1)
+}
+
+// PaginatedFunds returns, in order, at most [limit] of the IDs of the UTXOs
+// that reference the address whose 32 byte representation is [addrID]. Only
+// the IDs after [start] are returned, unless [start] is the zero ID. If
+// [limit] is negative, all of them are returned.
+func (s *prefixedState) PaginatedFunds(addrID, start ids.ID, limit int) ([]ids.ID, error) {
+	if err := s.migrateFunds(addrID); err != nil {
+		return nil, err
+	}
+
+	prefix := addrID.Bytes()
+	startKey := prefix
+	if !start.IsZero() {
+		startKey = fundsKey(addrID, start)
+	}
+	iter := s.fundsIndex.NewIteratorWithStartAndPrefix(startKey, prefix)
+	defer iter.Release()
+
+	utxoIDs := []ids.ID(nil)
+	for (limit < 0 || len(utxoIDs) < limit) && iter.Next() {
+		utxoID, err := ids.ToID(iter.Key()[len(prefix):])
+		if err != nil {
+			return nil, err
+		}
+		if utxoID.Equals(start) {
+			continue
+		}
+		utxoIDs = append(utxoIDs, utxoID)
+	}
+	return utxoIDs, iter.Error()
+}
+
+// migrateFunds moves the UTXO IDs of [addrID] out of the list they were stored
+// in before the UTXOs were indexed one key per UTXO, if they haven't been
+// moved yet
+func (s *prefixedState) migrateFunds(addrID ids.ID) error {
+	listID := s.uniqueID(addrID, fundsID, s.funds)
+	utxoIDs, err := s.state.IDs(listID)
+	if err == database.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, utxoID := range utxoIDs {
+		if err := s.fundsIndex.Put(fundsKey(addrID, utxoID), nil); err != nil {
+			return err
+		}
+	}
+	return s.state.SetIDs(listID, nil)
 }
 
 func (s *prefixedState) uniqueID(id ids.ID, prefix uint64, cacher cache.Cacher) ids.ID {
@@ -114,23 +197,28 @@ func (s *prefixedState) SpendUTXO(utxoID ids.ID) error {
 func (s *prefixedState) removeUTXO(addrs [][]byte, utxoID ids.ID) error {
 	for _, addr := range addrs {
 		addrID := ids.NewID(hashing.ComputeHash256Array(addr))
-		utxos := ids.Set{}
-		funds, _ := s.Funds(addrID)
-		utxos.Add(funds...)
-		utxos.Remove(utxoID)
-		if err := s.SetFunds(addrID, utxos.List()); err != nil {
+		if err := s.migrateFunds(addrID); err != nil {
+			return err
+		}
+		if err := s.fundsIndex.Delete(fundsKey(addrID, utxoID)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// FundUTXO adds the provided utxo to the database
+// FundUTXO adds the provided utxo to the database. If it holds the managed
+// state of its asset, it replaces the asset's previous managed state.
 func (s *prefixedState) FundUTXO(utxo *UTXO) error {
 	utxoID := utxo.InputID()
 	if err := s.SetUTXO(utxoID, utxo); err != nil {
 		return err
 	}
+	if s.state.vm.isManagedState(utxo.Out) {
+		if err := s.SetManagedState(utxo); err != nil {
+			return err
+		}
+	}
 
 	addressable, ok := utxo.Out.(FxAddressable)
 	if !ok {
@@ -143,13 +231,16 @@ func (s *prefixedState) FundUTXO(utxo *UTXO) error {
 func (s *prefixedState) addUTXO(addrs [][]byte, utxoID ids.ID) error {
 	for _, addr := range addrs {
 		addrID := ids.NewID(hashing.ComputeHash256Array(addr))
-		utxos := ids.Set{}
-		funds, _ := s.Funds(addrID)
-		utxos.Add(funds...)
-		utxos.Add(utxoID)
-		if err := s.SetFunds(addrID, utxos.List()); err != nil {
+		if err := s.migrateFunds(addrID); err != nil {
+			return err
+		}
+		if err := s.fundsIndex.Put(fundsKey(addrID, utxoID), nil); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+func fundsKey(addrID, utxoID ids.ID) []byte {
+	return append(addrID.Bytes(), utxoID.Bytes()...)
+}
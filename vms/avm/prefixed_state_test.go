@@ -172,8 +172,55 @@ func TestPrefixedFundingAddresses(t *testing.T) {
 	if err := state.SpendUTXO(utxo.InputID()); err != nil {
 		t.Fatal(err)
 	}
-	_, err = state.Funds(ids.NewID(hashing.ComputeHash256Array([]byte{0})))
-	if err == nil {
+	funds, err = state.Funds(ids.NewID(hashing.ComputeHash256Array([]byte{0})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(funds) != 0 {
 		t.Fatalf("Should have returned no utxoIDs")
 	}
 }
+
+func TestPrefixedFundsMigration(t *testing.T) {
+	vm := GenesisVM(t)
+	state := vm.state
+
+	vm.codec.RegisterType(&testAddressable{})
+
+	// Before UTXOs were indexed one key per UTXO, the UTXOs of an address
+	// were stored as a list
+	addrID := ids.NewID(hashing.ComputeHash256Array([]byte{0}))
+	oldUTXOID := ids.Empty.Prefix(100)
+	if err := state.state.SetIDs(addrID.Prefix(fundsID), []ids.ID{oldUTXOID}); err != nil {
+		t.Fatal(err)
+	}
+
+	utxo := &UTXO{
+		UTXOID: UTXOID{
+			TxID:        ids.Empty,
+			OutputIndex: 1,
+		},
+		Asset: Asset{ID: ids.Empty},
+		Out: &testAddressable{
+			Addrs: [][]byte{
+				[]byte{0},
+			},
+		},
+	}
+	if err := state.FundUTXO(utxo); err != nil {
+		t.Fatal(err)
+	}
+
+	funds, err := state.Funds(addrID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxoIDs := ids.Set{}
+	utxoIDs.Add(funds...)
+	if utxoIDs.Len() != 2 || !utxoIDs.Contains(oldUTXOID) || !utxoIDs.Contains(utxo.InputID()) {
+		t.Fatalf("Should have returned both the listed and the indexed utxoIDs")
+	}
+	if _, err := state.state.IDs(addrID.Prefix(fundsID)); err == nil {
+		t.Fatalf("The list should have been removed")
+	}
+}
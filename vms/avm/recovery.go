@@ -0,0 +1,79 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"runtime"
+	"sync"
+)
+
+// recoveryTx is a transaction whose credentials are being recovered
+type recoveryTx struct {
+	unsignedBytes []byte
+	creds         []*Credential
+}
+
+func (tx *recoveryTx) UnsignedBytes() []byte { return tx.unsignedBytes }
+
+// recoverCredentials recovers the signers of the credentials of [txs] on
+// [numWorkers] goroutines, so that verifying the transactions afterwards only
+// has to look the signers up. Signature recovery is most of the cost of
+// verifying a transaction and, unlike the rest of verification, doesn't depend
+// on the state, so the transactions don't have to be independent.
+//
+// Credentials that can't be recovered are left for verification to reject.
+func (vm *VM) recoverCredentials(txs []*UniqueTx, numWorkers int) {
+	// Marshalling the unsigned transactions uses the codec, so it isn't done by
+	// the workers
+	recoveryTxs := make(chan *recoveryTx, len(txs))
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		recoveryTxs <- &recoveryTx{
+			unsignedBytes: tx.UnsignedBytes(),
+			creds:         tx.t.tx.Creds,
+		}
+	}
+	close(recoveryTxs)
+
+	wg := sync.WaitGroup{}
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			for tx := range recoveryTxs {
+				vm.recoverTxCredentials(tx)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// recoverTxCredentials recovers the signers of the credentials of [tx] that
+// belong to a feature extension that supports it
+func (vm *VM) recoverTxCredentials(tx *recoveryTx) {
+	for _, cred := range tx.creds {
+		fxIndex, err := vm.getFx(cred.Cred)
+		if err != nil {
+			continue
+		}
+		recoverer, ok := vm.fxs[fxIndex].Fx.(FxCredentialRecoverer)
+		if !ok {
+			continue
+		}
+		// The error is returned again when the credential is verified
+		_ = recoverer.RecoverCredential(tx, cred.Cred)
+	}
+}
+
+// recoveryWorkers returns the number of goroutines to recover the credentials
+// of [numTxs] transactions on
+func recoveryWorkers(numTxs int) int {
+	numWorkers := runtime.GOMAXPROCS(0)
+	if numTxs < numWorkers {
+		numWorkers = numTxs
+	}
+	return numWorkers
+}
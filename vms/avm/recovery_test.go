@@ -0,0 +1,283 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+// recoveryTestFx counts the credentials it's asked to recover
+type recoveryTestFx struct {
+	secp256k1fx.Fx
+
+	lock      sync.Mutex
+	recovered int
+}
+
+func (fx *recoveryTestFx) RecoverCredential(tx, cred interface{}) error {
+	fx.lock.Lock()
+	fx.recovered++
+	fx.lock.Unlock()
+
+	return fx.Fx.RecoverCredential(tx, cred)
+}
+
+func recoveryVM(tb testing.TB, fx Fx) *VM {
+	genesisBytes := BuildGenesisTest(tb)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: fx,
+		}},
+	)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return vm
+}
+
+// signedTxs returns [numTxs] transactions, starting from [seed], with one input
+// signed by [numSigs] keys each. The transactions aren't valid; only their
+// signatures are.
+func signedTxs(tb testing.TB, vm *VM, seed, numTxs, numSigs int) []*UniqueTx {
+	sigIndices := make([]uint32, numSigs)
+	for i := range sigIndices {
+		sigIndices[i] = uint32(i)
+	}
+
+	txs := make([]*UniqueTx, numTxs)
+	for i := range txs {
+		tx := &Tx{UnsignedTx: &BaseTx{
+			NetID: networkID,
+			BCID:  chainID,
+			Outs:  []*TransferableOutput{},
+			Ins: []*TransferableInput{&TransferableInput{
+				UTXOID: UTXOID{TxID: asset, OutputIndex: uint32(seed + i)},
+				Asset:  Asset{ID: asset},
+				In: &secp256k1fx.TransferInput{
+					Amt:   1,
+					Input: secp256k1fx.Input{SigIndices: sigIndices},
+				},
+			}},
+		}}
+		unsignedBytes, err := vm.codec.Marshal(&tx.UnsignedTx)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		cred := &secp256k1fx.Credential{}
+		for j := 0; j < numSigs; j++ {
+			sig, err := keys[j%len(keys)].Sign(unsignedBytes)
+			if err != nil {
+				tb.Fatal(err)
+			}
+			fixedSig := [crypto.SECP256K1RSigLen]byte{}
+			copy(fixedSig[:], sig)
+			cred.Sigs = append(cred.Sigs, fixedSig)
+		}
+		tx.Creds = []*Credential{&Credential{Cred: cred}}
+
+		b, err := vm.codec.Marshal(tx)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		tx.Initialize(b)
+		txs[i] = &UniqueTx{
+			vm:   vm,
+			txID: tx.ID(),
+			t:    &txState{tx: tx},
+		}
+	}
+	return txs
+}
+
+func TestRecoverCredentials(t *testing.T) {
+	fx := &recoveryTestFx{}
+	vm := recoveryVM(t, fx)
+	defer func() { ctx.Lock.Lock(); vm.Shutdown(); ctx.Lock.Unlock() }()
+
+	txs := signedTxs(t, vm, 0, 10, 2)
+	// Transactions that couldn't be parsed are skipped
+	txs = append(txs, nil)
+
+	vm.recoverCredentials(txs, 4)
+	if fx.recovered != 10 {
+		t.Fatalf("Should have recovered %d credentials but recovered %d", 10, fx.recovered)
+	}
+
+	if workers := recoveryWorkers(1); workers != 1 {
+		t.Fatalf("Should have recovered %d transaction on %d worker but used %d", 1, 1, workers)
+	}
+	if workers := recoveryWorkers(1 << 20); workers != runtime.GOMAXPROCS(0) {
+		t.Fatalf("Should have used %d workers but used %d", runtime.GOMAXPROCS(0), workers)
+	}
+}
+
+func TestIssueTxsRecoversCredentials(t *testing.T) {
+	fx := &recoveryTestFx{}
+	vm := recoveryVM(t, fx)
+	defer func() { ctx.Lock.Lock(); vm.Shutdown(); ctx.Lock.Unlock() }()
+
+	txs := signedTxs(t, vm, 0, 3, 1)
+	txBytes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		txBytes[i] = tx.Bytes()
+	}
+
+	ctx.Lock.Lock()
+	_, errs := vm.IssueTxs(txBytes)
+	ctx.Lock.Unlock()
+
+	// The transactions spend UTXOs that don't exist
+	for i, err := range errs {
+		if err == nil {
+			t.Fatalf("Should have failed to issue tx %d", i)
+		}
+	}
+	if fx.recovered != len(txs) {
+		t.Fatalf("Should have recovered %d credentials but recovered %d", len(txs), fx.recovered)
+	}
+}
+
+func TestParseTxsRecoversCredentials(t *testing.T) {
+	fx := &recoveryTestFx{}
+	vm := recoveryVM(t, fx)
+	defer func() { ctx.Lock.Lock(); vm.Shutdown(); ctx.Lock.Unlock() }()
+
+	txs := signedTxs(t, vm, 0, 3, 1)
+	txBytes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		txBytes[i] = tx.Bytes()
+	}
+
+	ctx.Lock.Lock()
+	parsed, err := vm.ParseTxs(txBytes)
+	ctx.Lock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parsed) != len(txs) {
+		t.Fatalf("Should have parsed %d txs but parsed %d", len(txs), len(parsed))
+	}
+	for i, tx := range parsed {
+		if !tx.ID().Equals(txs[i].ID()) {
+			t.Fatalf("Parsed tx %d has the wrong ID", i)
+		}
+	}
+	if fx.recovered != len(txs) {
+		t.Fatalf("Should have recovered %d credentials but recovered %d", len(txs), fx.recovered)
+	}
+
+	ctx.Lock.Lock()
+	_, err = vm.ParseTxs([][]byte{txBytes[0], []byte{1}})
+	ctx.Lock.Unlock()
+	if err == nil {
+		t.Fatalf("Should have failed to parse an invalid tx")
+	}
+}
+
+// BenchmarkRecoverCredentials recovers the credentials of a batch of
+// transactions on one worker and on one worker per core
+func BenchmarkRecoverCredentials(b *testing.B) {
+	const (
+		numTxs  = 64
+		numSigs = 2
+	)
+	workers := []int{1}
+	if numCores := runtime.GOMAXPROCS(0); numCores > 1 {
+		workers = append(workers, numCores)
+	}
+	for _, numWorkers := range workers {
+		b.Run(fmt.Sprintf("workers=%d", numWorkers), func(b *testing.B) {
+			vm := recoveryVM(b, &secp256k1fx.Fx{})
+			defer func() { ctx.Lock.Lock(); vm.Shutdown(); ctx.Lock.Unlock() }()
+
+			for n := 0; n < b.N; n++ {
+				// New transactions are signed every time, so that nothing was
+				// recovered before
+				b.StopTimer()
+				txs := signedTxs(b, vm, n*numTxs, numTxs, numSigs)
+				b.StartTimer()
+
+				vm.recoverCredentials(txs, numWorkers)
+			}
+		})
+	}
+}
+
+// BenchmarkParseTxs parses the transactions of a vertex one at a time, with
+// their credentials recovered when they're verified, and as a batch, with
+// their credentials recovered in parallel
+func BenchmarkParseTxs(b *testing.B) {
+	const (
+		numTxs  = 64
+		numSigs = 2
+	)
+	parsers := []struct {
+		name  string
+		parse func(vm *VM, txs [][]byte) error
+	}{
+		{
+			name: "ParseTx",
+			parse: func(vm *VM, txs [][]byte) error {
+				for _, txBytes := range txs {
+					tx, err := vm.parseTx(txBytes)
+					if err != nil {
+						return err
+					}
+					vm.recoverCredentials([]*UniqueTx{tx}, 1)
+				}
+				return nil
+			},
+		},
+		{
+			name: "ParseTxs",
+			parse: func(vm *VM, txs [][]byte) error {
+				_, err := vm.ParseTxs(txs)
+				return err
+			},
+		},
+	}
+	for _, parser := range parsers {
+		b.Run(parser.name, func(b *testing.B) {
+			vm := recoveryVM(b, &secp256k1fx.Fx{})
+			defer func() { ctx.Lock.Lock(); vm.Shutdown(); ctx.Lock.Unlock() }()
+
+			for n := 0; n < b.N; n++ {
+				// New transactions are signed every time, so that nothing was
+				// parsed or recovered before
+				b.StopTimer()
+				txs := signedTxs(b, vm, n*numTxs, numTxs, numSigs)
+				txBytes := make([][]byte, len(txs))
+				for i, tx := range txs {
+					txBytes[i] = tx.Bytes()
+				}
+				b.StartTimer()
+
+				if err := parser.parse(vm, txBytes); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
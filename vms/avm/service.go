@@ -9,7 +9,11 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strings"
 
+	stdmath "math"
+
+	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/utils"
@@ -18,7 +22,12 @@ import (
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/json"
 	"github.com/ava-labs/gecko/utils/math"
+	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/shared"
 	"github.com/ava-labs/gecko/vms/components/verify"
+	"github.com/ava-labs/gecko/vms/managedfx"
+	"github.com/ava-labs/gecko/vms/nftfx"
+	"github.com/ava-labs/gecko/vms/schnorrfx"
 	"github.com/ava-labs/gecko/vms/secp256k1fx"
 )
 
@@ -35,8 +44,51 @@ var (
 	errUnknownUTXO               = errors.New("unknown utxo")
 	errInvalidUTXO               = errors.New("invalid utxo")
 	errUnknownOutputType         = errors.New("unknown output type")
+	errUnknownInputType          = errors.New("unknown input type")
 	errUnneededAddress           = errors.New("address not required to sign")
 	errUnknownCredentialType     = errors.New("unknown credential type")
+	errPayloadTooLarge           = errors.New("payload too large")
+	errNoUniqueOutput            = errors.New("provided addresses don't hold a unique output of the provided asset and group")
+	errNoImportableFunds         = errors.New("no funds were exported to the provided addresses")
+	errUnknownStartAddress       = errors.New("startIndex.address must be one of the provided addresses")
+	errNoManagers                = errors.New("no managers provided")
+	errAddressesCantManageAsset  = errors.New("provided addresses don't have the authority to manage the provided asset")
+	errNoManagedOutputs          = errors.New("address holds no outputs of the asset")
+	errAddressFrozen             = errors.New("address is already frozen")
+	errAddressNotFrozen          = errors.New("address isn't frozen")
+	errNoTxs                     = errors.New("no transactions provided")
+	errNoTxOrSize                = errors.New("either a transaction or its size must be provided")
+	errNoEntryName               = errors.New("address book entries must have a name")
+	errEntryNameIsAddress        = errors.New("address book entries can't be named after an address")
+	errTooManyTxs                = fmt.Errorf("at most %d transactions can be issued at once", maxTxsToIssue)
+	errInvalidBatchSize          = fmt.Errorf("batch size must be in the range [2, %d]", maxConsolidationInputs)
+	errNothingToConsolidate      = errors.New("UTXOs hold no more than the fee to consolidate them")
+	errNoAssetMetadata           = errors.New("asset has no metadata")
+	errAddressesCantControlAsset = errors.New("provided addresses don't have the authority to control the provided asset")
+)
+
+const (
+	// maxFeeAttempts is the number of times a transaction is rebuilt to pay
+	// its fee before giving up
+	maxFeeAttempts = 5
+
+	// maxUTXOsToFetch is the most UTXOs that a call to GetUTXOs returns
+	maxUTXOsToFetch = 1024
+
+	// maxTxsToFetch is the most transactions that a call to GetAddressTxs
+	// returns
+	maxTxsToFetch = 1024
+
+	// maxTxsToIssue is the most transactions that a call to IssueTxs accepts
+	maxTxsToIssue = 256
+
+	// maxConsolidationInputs is the most UTXOs that a transaction issued by
+	// Consolidate consumes
+	maxConsolidationInputs = 256
+
+	// maxConsolidationTxs is the most transactions that a call to Consolidate
+	// issues
+	maxConsolidationTxs = 16
 )
 
 // Service defines the base service for the asset vm
@@ -44,7 +96,10 @@ type Service struct{ vm *VM }
 
 // IssueTxArgs are arguments for passing into IssueTx requests
 type IssueTxArgs struct {
-	Tx formatting.CB58 `json:"tx"`
+	Tx string `json:"tx"`
+
+	// Encoding of Tx, either "cb58" or "hex". Defaults to "cb58".
+	Encoding formatting.Encoding `json:"encoding"`
 }
 
 // IssueTxReply defines the IssueTx replies returned from the API
@@ -54,9 +109,13 @@ type IssueTxReply struct {
 
 // IssueTx attempts to issue a transaction into consensus
 func (service *Service) IssueTx(r *http.Request, args *IssueTxArgs, reply *IssueTxReply) error {
-	service.vm.ctx.Log.Verbo("IssueTx called with %s", args.Tx)
+	service.vm.ctx.Log.Verbo("IssueTx called with %s in request %s", args.Tx, api.RequestID(r))
 
-	txID, err := service.vm.IssueTx(args.Tx.Bytes)
+	txBytes, err := args.Encoding.Decode(args.Tx)
+	if err != nil {
+		return fmt.Errorf("problem decoding transaction: %w", err)
+	}
+	txID, err := service.vm.IssueTx(txBytes)
 	if err != nil {
 		return err
 	}
@@ -65,6 +124,249 @@ func (service *Service) IssueTx(r *http.Request, args *IssueTxArgs, reply *Issue
 	return nil
 }
 
+// IssueTxsArgs are arguments for passing into IssueTxs requests
+type IssueTxsArgs struct {
+	Txs []formatting.CB58 `json:"txs"`
+}
+
+// IssueTxResult is the result of issuing one transaction of an IssueTxs
+// request. If the transaction wasn't issued, Error is why.
+type IssueTxResult struct {
+	TxID  ids.ID `json:"txID"`
+	Error string `json:"error,omitempty"`
+}
+
+// IssueTxsReply defines the IssueTxs replies returned from the API
+type IssueTxsReply struct {
+	Results []IssueTxResult `json:"results"`
+}
+
+// IssueTxs attempts to issue an ordered batch of transactions into consensus.
+// A transaction may spend the outputs of the transactions before it in the
+// batch. Returns the result of each transaction, in the order provided.
+func (service *Service) IssueTxs(r *http.Request, args *IssueTxsArgs, reply *IssueTxsReply) error {
+	service.vm.ctx.Log.Verbo("IssueTxs called with %d txs in request %s", len(args.Txs), api.RequestID(r))
+
+	switch {
+	case len(args.Txs) == 0:
+		return errNoTxs
+	case len(args.Txs) > maxTxsToIssue:
+		return errTooManyTxs
+	}
+
+	txs := make([][]byte, len(args.Txs))
+	for i, tx := range args.Txs {
+		txs[i] = tx.Bytes
+	}
+
+	txIDs, errs := service.vm.IssueTxs(txs)
+	reply.Results = make([]IssueTxResult, len(txs))
+	for i, txID := range txIDs {
+		reply.Results[i].TxID = txID
+		if errs[i] != nil {
+			reply.Results[i].Error = errs[i].Error()
+		}
+	}
+	return nil
+}
+
+// DecodeTxArgs are arguments for passing into DecodeTx requests
+type DecodeTxArgs struct {
+	Tx string `json:"tx"`
+
+	// Encoding of Tx, either "cb58" or "hex". Defaults to "cb58".
+	Encoding formatting.Encoding `json:"encoding"`
+}
+
+// DecodedSignature is the signature an input requires of an address
+type DecodedSignature struct {
+	Address string `json:"address"`
+	Valid   bool   `json:"valid"`
+}
+
+// DecodedInput is an input of a decoded transaction
+type DecodedInput struct {
+	TxID        ids.ID      `json:"txID"`
+	OutputIndex json.Uint32 `json:"outputIndex"`
+	Type        string      `json:"type"`
+	Amount      json.Uint64 `json:"amount"`
+
+	// The signatures the input requires, in the order of its credential. If
+	// they couldn't be checked, SignaturesError is why.
+	Signatures      []DecodedSignature `json:"signatures"`
+	SignaturesError string             `json:"signaturesError,omitempty"`
+}
+
+// DecodedOutput is an output of a decoded transaction
+type DecodedOutput struct {
+	OutputIndex json.Uint32 `json:"outputIndex"`
+	AssetID     ids.ID      `json:"assetID"`
+	Type        string      `json:"type"`
+	Amount      json.Uint64 `json:"amount"`
+	Threshold   json.Uint32 `json:"threshold"`
+	Addresses   []string    `json:"addresses"`
+	Exported    bool        `json:"exported"`
+}
+
+// DecodeTxReply defines the DecodeTx replies returned from the API
+type DecodeTxReply struct {
+	TxID         ids.ID          `json:"txID"`
+	Type         string          `json:"type"`
+	NetworkID    json.Uint32     `json:"networkID"`
+	BlockchainID ids.ID          `json:"blockchainID"`
+	AssetIDs     []ids.ID        `json:"assetIDs"`
+	Inputs       []DecodedInput  `json:"inputs"`
+	Outputs      []DecodedOutput `json:"outputs"`
+
+	// Why the transaction isn't well-formed, if it isn't
+	SyntacticError string `json:"syntacticError,omitempty"`
+}
+
+// DecodeTx returns a breakdown of the transaction [args.Tx], including whether
+// the signatures of its inputs are valid. The transaction isn't issued, so it
+// can be used to check transactions before they're issued.
+func (service *Service) DecodeTx(r *http.Request, args *DecodeTxArgs, reply *DecodeTxReply) error {
+	service.vm.ctx.Log.Verbo("DecodeTx called in request %s", api.RequestID(r))
+
+	txBytes, err := args.Encoding.Decode(args.Tx)
+	if err != nil {
+		return fmt.Errorf("problem decoding transaction: %w", err)
+	}
+
+	tx := Tx{}
+	if err := service.vm.codec.Unmarshal(txBytes, &tx); err != nil {
+		return fmt.Errorf("problem parsing transaction: %w", err)
+	}
+	tx.Initialize(txBytes)
+
+	reply.TxID = tx.ID()
+	reply.Type = typeName(tx.UnsignedTx)
+	reply.NetworkID = json.Uint32(tx.NetworkID())
+	reply.BlockchainID = tx.ChainID()
+	reply.AssetIDs = tx.AssetIDs().List()
+	if err := tx.SyntacticVerify(service.vm.ctx, service.vm.codec, len(service.vm.fxs)); err != nil {
+		reply.SyntacticError = err.Error()
+	}
+
+	unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	inputs := txInputs(tx.UnsignedTx)
+	reply.Inputs = []DecodedInput{}
+	for i, inputUTXO := range tx.InputUTXOs() {
+		in := DecodedInput{
+			TxID:        inputUTXO.TxID,
+			OutputIndex: json.Uint32(inputUTXO.OutputIndex),
+			Signatures:  []DecodedSignature{},
+		}
+		if i < len(inputs) {
+			in.Type = typeName(inputs[i])
+			if transferable, ok := inputs[i].(FxTransferable); ok {
+				in.Amount = json.Uint64(transferable.Amount())
+			}
+
+			var cred verify.Verifiable
+			if i < len(tx.Creds) && tx.Creds[i] != nil {
+				cred = tx.Creds[i].Cred
+			}
+			in.Signatures, err = service.decodeSignatures(inputUTXO, inputs[i], cred, unsignedBytes)
+			if err != nil {
+				in.SignaturesError = err.Error()
+			}
+		}
+		reply.Inputs = append(reply.Inputs, in)
+	}
+
+	reply.Outputs = []DecodedOutput{}
+	for _, utxo := range tx.UTXOs() {
+		reply.Outputs = append(reply.Outputs, service.decodeOutput(utxo.OutputIndex, utxo.AssetID(), utxo.Out, false))
+	}
+	if exportTx, ok := tx.UnsignedTx.(*ExportTx); ok {
+		for _, out := range exportTx.ExportedOuts {
+			outputIndex := uint32(len(reply.Outputs))
+			reply.Outputs = append(reply.Outputs, service.decodeOutput(outputIndex, out.AssetID(), out.Out, true))
+		}
+	}
+	return nil
+}
+
+// decodeSignatures returns the signatures that [in], which spends
+// [inputUTXO], requires, and whether [cred] holds a valid signature of
+// [unsignedBytes] by each of them
+func (service *Service) decodeSignatures(inputUTXO *UTXOID, in verify.Verifiable, cred verify.Verifiable, unsignedBytes []byte) ([]DecodedSignature, error) {
+	sigIndices, _, ok := inputSigIndices(in)
+	if !ok {
+		return nil, errUnknownInputType
+	}
+	if inputUTXO.Symbolic() {
+		return nil, errors.New("imported utxos aren't part of this chain's state")
+	}
+	utxo, err := service.getUTXO(inputUTXO)
+	if err != nil {
+		return nil, err
+	}
+	owners, ok := outputOwners(utxo.Out)
+	if !ok {
+		return nil, errUnknownOutputType
+	}
+
+	sigs := [][crypto.SECP256K1RSigLen]byte(nil)
+	if cred != nil {
+		credSigs, ok := credentialSigs(cred)
+		if !ok {
+			return nil, errUnknownCredentialType
+		}
+		sigs = *credSigs
+	}
+
+	factory := crypto.FactorySECP256K1R{}
+	signatures := make([]DecodedSignature, len(sigIndices))
+	for i, index := range sigIndices {
+		if int(index) >= len(owners.Addrs) {
+			return nil, errInvalidUTXO
+		}
+		addr := owners.Addrs[index]
+		signatures[i].Address = service.vm.Format(addr.Bytes())
+		if i >= len(sigs) {
+			continue
+		}
+		key, err := factory.RecoverPublicKey(unsignedBytes, sigs[i][:])
+		signatures[i].Valid = err == nil && key.Address().Equals(addr)
+	}
+	return signatures, nil
+}
+
+// decodeOutput returns the API representation of the output [out] of the
+// asset [assetID]
+func (service *Service) decodeOutput(outputIndex uint32, assetID ids.ID, out verify.Verifiable, exported bool) DecodedOutput {
+	decoded := DecodedOutput{
+		OutputIndex: json.Uint32(outputIndex),
+		AssetID:     assetID,
+		Type:        typeName(out),
+		Addresses:   []string{},
+		Exported:    exported,
+	}
+	if transferable, ok := out.(FxTransferable); ok {
+		decoded.Amount = json.Uint64(transferable.Amount())
+	}
+	if owners, ok := outputOwners(out); ok {
+		decoded.Threshold = json.Uint32(owners.Threshold)
+		for _, addr := range owners.Addrs {
+			decoded.Addresses = append(decoded.Addresses, service.vm.Format(addr.Bytes()))
+		}
+	}
+	return decoded
+}
+
+// typeName returns the name of the type of [value], without its package path
+// or pointer, such as "TransferOutput"
+func typeName(value interface{}) string {
+	name := fmt.Sprintf("%T", value)
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
 // GetTxStatusArgs are arguments for passing into GetTxStatus requests
 type GetTxStatusArgs struct {
 	TxID ids.ID `json:"txID"`
@@ -77,7 +379,7 @@ type GetTxStatusReply struct {
 
 // GetTxStatus returns the status of the specified transaction
 func (service *Service) GetTxStatus(r *http.Request, args *GetTxStatusArgs, reply *GetTxStatusReply) error {
-	service.vm.ctx.Log.Verbo("GetTxStatus called with %s", args.TxID)
+	service.vm.ctx.Log.Verbo("GetTxStatus called with %s in request %s", args.TxID, api.RequestID(r))
 
 	if args.TxID.IsZero() {
 		return errNilTxID
@@ -92,41 +394,189 @@ func (service *Service) GetTxStatus(r *http.Request, args *GetTxStatusArgs, repl
 	return nil
 }
 
+// Index is a position in the UTXOs that a set of addresses reference
+type Index struct {
+	Address string `json:"address"`
+	UTXO    ids.ID `json:"utxo"`
+}
+
 // GetUTXOsArgs are arguments for passing into GetUTXOs requests
 type GetUTXOsArgs struct {
 	Addresses []string `json:"addresses"`
+
+	// Limit is the most UTXOs to return. If it's 0 or more than 1024, at
+	// most 1024 UTXOs are returned.
+	Limit json.Uint32 `json:"limit"`
+
+	// StartIndex is the endIndex of the previous page. If it's empty, the
+	// first page is returned.
+	StartIndex Index `json:"startIndex"`
+
+	// Encoding of the returned UTXOs, either "cb58" or "hex". Defaults to
+	// "cb58".
+	Encoding formatting.Encoding `json:"encoding"`
 }
 
 // GetUTXOsReply defines the GetUTXOs replies returned from the API
 type GetUTXOsReply struct {
-	UTXOs []formatting.CB58 `json:"utxos"`
+	// Number of UTXOs returned
+	NumFetched json.Uint64 `json:"numFetched"`
+
+	UTXOs    []string            `json:"utxos"`
+	Encoding formatting.Encoding `json:"encoding"`
+
+	// EndIndex is where the next page starts
+	EndIndex Index `json:"endIndex"`
 }
 
-// GetUTXOs creates an empty account with the name passed in
+// GetUTXOs returns the UTXOs that at least one of [args.Addresses] is
+// referenced in. They're returned in pages of at most [args.Limit] UTXOs; a
+// page with fewer UTXOs than that is the last one. To get the next page, call
+// GetUTXOs again with the same addresses and [args.StartIndex] set to the
+// [reply.EndIndex] of this page.
 func (service *Service) GetUTXOs(r *http.Request, args *GetUTXOsArgs, reply *GetUTXOsReply) error {
-	service.vm.ctx.Log.Verbo("GetUTXOs called with %s", args.Addresses)
+	service.vm.ctx.Log.Verbo("GetUTXOs called with %s in request %s", args.Addresses, api.RequestID(r))
+
+	encoding, err := formatting.ParseEncoding(string(args.Encoding))
+	if err != nil {
+		return err
+	}
 
+	addrs := []ids.ID(nil)
+	addrStrs := []string(nil)
 	addrSet := ids.Set{}
+	startAddr := -1
 	for _, addr := range args.Addresses {
 		addrBytes, err := service.vm.Parse(addr)
 		if err != nil {
 			return err
 		}
-		addrSet.Add(ids.NewID(hashing.ComputeHash256Array(addrBytes)))
+		addrID := ids.NewID(hashing.ComputeHash256Array(addrBytes))
+		if addrSet.Contains(addrID) {
+			continue
+		}
+		if addr == args.StartIndex.Address {
+			startAddr = len(addrs)
+		}
+		addrSet.Add(addrID)
+		addrs = append(addrs, addrID)
+		addrStrs = append(addrStrs, addr)
 	}
 
-	utxos, err := service.vm.GetUTXOs(addrSet)
+	startUTXO := args.StartIndex.UTXO
+	switch {
+	case args.StartIndex.Address == "":
+		startAddr = 0
+		startUTXO = ids.ID{}
+	case startAddr == -1:
+		return errUnknownStartAddress
+	}
+
+	limit := int(args.Limit)
+	if limit <= 0 || limit > maxUTXOsToFetch {
+		limit = maxUTXOsToFetch
+	}
+
+	utxos, endAddr, endUTXO, err := service.vm.GetPaginatedUTXOs(addrs, startAddr, startUTXO, limit)
 	if err != nil {
 		return err
 	}
 
-	reply.UTXOs = []formatting.CB58{}
+	reply.UTXOs = []string{}
 	for _, utxo := range utxos {
 		b, err := service.vm.codec.Marshal(utxo)
 		if err != nil {
 			return err
 		}
-		reply.UTXOs = append(reply.UTXOs, formatting.CB58{Bytes: b})
+		str, err := encoding.Encode(b)
+		if err != nil {
+			return err
+		}
+		reply.UTXOs = append(reply.UTXOs, str)
+	}
+	reply.Encoding = encoding
+	reply.NumFetched = json.Uint64(len(utxos))
+	if endAddr < len(addrStrs) {
+		reply.EndIndex = Index{Address: addrStrs[endAddr], UTXO: endUTXO}
+	}
+	return nil
+}
+
+// GetAddressTxsArgs are arguments for passing into GetAddressTxs requests
+type GetAddressTxsArgs struct {
+	Address string `json:"address"`
+
+	// Only transactions accepted at a height in [StartHeight, EndHeight] are
+	// returned. If EndHeight is 0, there is no upper bound.
+	StartHeight json.Uint64 `json:"startHeight"`
+	EndHeight   json.Uint64 `json:"endHeight"`
+
+	// Only transactions accepted at a Unix time, in seconds, in
+	// [StartTime, EndTime] are returned. If EndTime is 0, there is no upper
+	// bound.
+	StartTime json.Uint64 `json:"startTime"`
+	EndTime   json.Uint64 `json:"endTime"`
+
+	// Limit is the most transactions to return. If it's 0 or more than 1024,
+	// at most 1024 transactions are returned.
+	Limit json.Uint32 `json:"limit"`
+}
+
+// AddressTx is a transaction in the history of an address
+type AddressTx struct {
+	TxID ids.ID `json:"txID"`
+
+	// Height is the number of transactions this node accepted before this one
+	Height json.Uint64 `json:"height"`
+
+	// Timestamp is the Unix time, in seconds, this node accepted the tx at
+	Timestamp json.Uint64 `json:"timestamp"`
+}
+
+// GetAddressTxsReply defines the GetAddressTxs replies returned from the API
+type GetAddressTxsReply struct {
+	Txs []AddressTx `json:"txs"`
+}
+
+// GetAddressTxs returns the accepted transactions that consume or produce a
+// UTXO on this chain that [args.Address] owns, in the order they were
+// accepted in. A reply with fewer transactions than [args.Limit] is the last
+// page; to get the next page, call GetAddressTxs again with [args.StartHeight]
+// set to one more than the height of the last transaction returned.
+func (service *Service) GetAddressTxs(r *http.Request, args *GetAddressTxsArgs, reply *GetAddressTxsReply) error {
+	service.vm.ctx.Log.Verbo("GetAddressTxs called with %s in request %s", args.Address, api.RequestID(r))
+
+	addrBytes, err := service.vm.Parse(args.Address)
+	if err != nil {
+		return err
+	}
+	addrID := ids.NewID(hashing.ComputeHash256Array(addrBytes))
+
+	endHeight := uint64(args.EndHeight)
+	if endHeight == 0 {
+		endHeight = stdmath.MaxUint64
+	}
+	endTime := uint64(args.EndTime)
+	if endTime == 0 {
+		endTime = stdmath.MaxUint64
+	}
+	limit := int(args.Limit)
+	if limit <= 0 || limit > maxTxsToFetch {
+		limit = maxTxsToFetch
+	}
+
+	txs, err := service.vm.addressTxs(addrID, uint64(args.StartHeight), endHeight, uint64(args.StartTime), endTime, limit)
+	if err != nil {
+		return fmt.Errorf("problem retrieving the transactions of %s: %w", args.Address, err)
+	}
+
+	reply.Txs = make([]AddressTx, len(txs))
+	for i, tx := range txs {
+		reply.Txs[i] = AddressTx{
+			TxID:      tx.txID,
+			Height:    json.Uint64(tx.height),
+			Timestamp: json.Uint64(tx.timestamp),
+		}
 	}
 	return nil
 }
@@ -145,8 +595,8 @@ type GetAssetDescriptionReply struct {
 }
 
 // GetAssetDescription creates an empty account with the name passed in
-func (service *Service) GetAssetDescription(_ *http.Request, args *GetAssetDescriptionArgs, reply *GetAssetDescriptionReply) error {
-	service.vm.ctx.Log.Verbo("GetAssetDescription called with %s", args.AssetID)
+func (service *Service) GetAssetDescription(r *http.Request, args *GetAssetDescriptionArgs, reply *GetAssetDescriptionReply) error {
+	service.vm.ctx.Log.Verbo("GetAssetDescription called with %s in request %s", args.AssetID, api.RequestID(r))
 
 	assetID, err := service.vm.Lookup(args.AssetID)
 	if err != nil {
@@ -176,97 +626,396 @@ func (service *Service) GetAssetDescription(_ *http.Request, args *GetAssetDescr
 	return nil
 }
 
-// GetBalanceArgs are arguments for passing into GetBalance requests
-type GetBalanceArgs struct {
-	Address string `json:"address"`
+// GetAssetMetadataArgs are arguments for passing into GetAssetMetadata requests
+type GetAssetMetadataArgs struct {
 	AssetID string `json:"assetID"`
 }
 
-// GetBalanceReply defines the GetBalance replies returned from the API
-type GetBalanceReply struct {
-	Balance json.Uint64 `json:"balance"`
+// GetAssetMetadataReply defines the GetAssetMetadata replies returned from the
+// API
+type GetAssetMetadataReply struct {
+	AssetID  ids.ID          `json:"assetID"`
+	Decimals json.Uint8      `json:"decimals"`
+	LogoHash formatting.CB58 `json:"logoHash"`
+	Website  string          `json:"website"`
+	// ID of the transaction that set the metadata
+	TxID ids.ID `json:"txID"`
 }
 
-// GetBalance returns the amount of an asset that an address at least partially owns
-func (service *Service) GetBalance(r *http.Request, args *GetBalanceArgs, reply *GetBalanceReply) error {
-	service.vm.ctx.Log.Verbo("GetBalance called with address: %s assetID: %s", args.Address, args.AssetID)
+// GetAssetMetadata returns the metadata that the controllers of the asset
+// [args.AssetID] last set
+func (service *Service) GetAssetMetadata(r *http.Request, args *GetAssetMetadataArgs, reply *GetAssetMetadataReply) error {
+	service.vm.ctx.Log.Verbo("GetAssetMetadata called with %s in request %s", args.AssetID, api.RequestID(r))
 
-	address, err := service.vm.Parse(args.Address)
+	assetID, err := service.lookupAssetID(args.AssetID)
 	if err != nil {
 		return err
 	}
-
-	assetID, err := service.vm.Lookup(args.AssetID)
+	txID, err := service.vm.state.AssetMetadata(assetID)
 	if err != nil {
-		assetID, err = ids.FromString(args.AssetID)
-		if err != nil {
-			return err
-		}
+		return errNoAssetMetadata
+	}
+	tx, err := service.vm.state.Tx(txID)
+	if err != nil {
+		return fmt.Errorf("couldn't get the transaction that set the metadata: %w", err)
+	}
+	metadataTx, ok := tx.UnsignedTx.(*AssetMetadataTx)
+	if !ok {
+		return errNoAssetMetadata
 	}
 
-	addrSet := ids.Set{}
-	addrSet.Add(ids.NewID(hashing.ComputeHash256Array(address)))
+	reply.AssetID = assetID
+	reply.Decimals = json.Uint8(metadataTx.Metadata.Decimals)
+	reply.LogoHash.Bytes = metadataTx.Metadata.LogoHash
+	reply.Website = metadataTx.Metadata.Website
+	reply.TxID = txID
+	return nil
+}
 
-	utxos, err := service.vm.GetUTXOs(addrSet)
+// SetAssetMetadataArgs are arguments for passing into SetAssetMetadata requests
+type SetAssetMetadataArgs struct {
+	Username string          `json:"username"`
+	Password string          `json:"password"`
+	AssetID  string          `json:"assetID"`
+	Decimals json.Uint8      `json:"decimals"`
+	LogoHash formatting.CB58 `json:"logoHash"`
+	Website  string          `json:"website"`
+}
+
+// SetAssetMetadataReply defines the SetAssetMetadata replies returned from the
+// API
+type SetAssetMetadataReply struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// SetAssetMetadata sets the metadata of the asset [args.AssetID]. The user
+// must hold the keys of the owners of one of the asset's control outputs: a
+// mint output, or the manager output of a managed asset.
+func (service *Service) SetAssetMetadata(r *http.Request, args *SetAssetMetadataArgs, reply *SetAssetMetadataReply) error {
+	service.vm.ctx.Log.Verbo("SetAssetMetadata called with asset: %s in request %s", args.AssetID, api.RequestID(r))
+
+	assetID, err := service.lookupAssetID(args.AssetID)
 	if err != nil {
 		return err
 	}
+	metadata := AssetMetadata{
+		Decimals: byte(args.Decimals),
+		LogoHash: args.LogoHash.Bytes,
+		Website:  args.Website,
+	}
+	if metadata.LogoHash == nil {
+		metadata.LogoHash = []byte{}
+	}
+	if err := metadata.Verify(); err != nil {
+		return err
+	}
 
+	utxos, kc, err := service.userUTXOs(args.Username, args.Password)
+	if err != nil {
+		return err
+	}
 	for _, utxo := range utxos {
-		if utxo.AssetID().Equals(assetID) {
-			transferable, ok := utxo.Out.(FxTransferable)
+		if !utxo.AssetID().Equals(assetID) {
+			continue
+		}
+		owners, newInput, ok := controlInput(utxo.Out)
+		if !ok {
+			continue
+		}
+		sigIndices, signers, ok := kc.Match(owners)
+		if !ok {
+			continue
+		}
+
+		utx := &AssetMetadataTx{
+			BaseTx: BaseTx{
+				NetID: service.vm.ctx.NetworkID,
+				BCID:  service.vm.ctx.ChainID,
+			},
+			AssetID: assetID,
+			ControlIn: &OperableInput{
+				UTXOID: utxo.UTXOID,
+				In:     newInput(sigIndices),
+			},
+			ControlOut: &OperableOutput{utxo.Out},
+			Metadata:   metadata,
+		}
+		txID, err := service.issueWithFee(func(fee uint64) (*Tx, error) {
+			keys, err := service.payFee(args.Username, args.Password, &utx.BaseTx, fee)
+			if err != nil {
+				return nil, err
+			}
+			tx, err := service.sign(utx, keys)
+			if err != nil {
+				return nil, err
+			}
+
+			// The control input's credential follows the credentials of the
+			// inputs, and is of the type of the control output's Fx
+			_, cred, ok := inputSigIndices(utx.ControlIn.In)
 			if !ok {
-				continue
+				return nil, errUnknownInputType
 			}
-			amt, err := math.Add64(transferable.Amount(), uint64(reply.Balance))
+			sigs, ok := credentialSigs(cred)
+			if !ok {
+				return nil, errUnknownCredentialType
+			}
+			unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
 			if err != nil {
-				return err
+				return nil, fmt.Errorf("problem creating transaction: %w", err)
 			}
-			reply.Balance = json.Uint64(amt)
+			hash := hashing.ComputeHash256(unsignedBytes)
+
+			*sigs = [][crypto.SECP256K1RSigLen]byte{}
+			for _, key := range signers {
+				sig, err := key.SignHash(hash)
+				if err != nil {
+					return nil, fmt.Errorf("problem creating transaction: %w", err)
+				}
+				fixedSig := [crypto.SECP256K1RSigLen]byte{}
+				copy(fixedSig[:], sig)
+
+				*sigs = append(*sigs, fixedSig)
+			}
+			tx.Creds = append(tx.Creds, &Credential{Cred: cred})
+			return tx, nil
+		})
+		if err != nil {
+			return err
 		}
+
+		reply.TxID = txID
+		return nil
 	}
-	return nil
+	return errAddressesCantControlAsset
 }
 
-// CreateFixedCapAssetArgs are arguments for passing into CreateFixedCapAsset requests
-type CreateFixedCapAssetArgs struct {
-	Username       string    `json:"username"`
-	Password       string    `json:"password"`
-	Name           string    `json:"name"`
-	Symbol         string    `json:"symbol"`
-	Denomination   byte      `json:"denomination"`
-	InitialHolders []*Holder `json:"initialHolders"`
+// controlInput returns the owners of [out], if it's an output that controls
+// its asset, along with a function that returns an input that consumes [out]
+// with the signatures of the owners at [sigIndices]
+func controlInput(out verify.Verifiable) (*secp256k1fx.OutputOwners, func(sigIndices []uint32) verify.Verifiable, bool) {
+	switch out := out.(type) {
+	case *secp256k1fx.MintOutput:
+		return &out.OutputOwners, func(sigIndices []uint32) verify.Verifiable {
+			return &secp256k1fx.MintInput{Input: secp256k1fx.Input{SigIndices: sigIndices}}
+		}, true
+	case *nftfx.MintOutput:
+		return &out.OutputOwners, func(sigIndices []uint32) verify.Verifiable {
+			return &nftfx.MintInput{Input: secp256k1fx.Input{SigIndices: sigIndices}}
+		}, true
+	case *managedfx.ManagerOutput:
+		return &out.OutputOwners, func(sigIndices []uint32) verify.Verifiable {
+			return &managedfx.ManagerInput{Input: secp256k1fx.Input{SigIndices: sigIndices}}
+		}, true
+	default:
+		return nil, nil, false
+	}
 }
 
-// Holder describes how much an address owns of an asset
-type Holder struct {
-	Amount  json.Uint64 `json:"amount"`
-	Address string      `json:"address"`
+// GetTxFeeArgs are arguments for passing into GetTxFee requests
+type GetTxFeeArgs struct{}
+
+// GetTxFeeReply defines the GetTxFee replies returned from the API
+type GetTxFeeReply struct {
+	// Enabled is true if transactions issued now pay fees
+	Enabled      bool        `json:"enabled"`
+	AssetID      ids.ID      `json:"assetID"`
+	StartTime    json.Uint64 `json:"startTime"`
+	TxFee        json.Uint64 `json:"txFee"`
+	ByteFee      json.Uint64 `json:"byteFee"`
+	OperationFee json.Uint64 `json:"operationFee"`
 }
 
-// CreateFixedCapAssetReply defines the CreateFixedCapAsset replies returned from the API
-type CreateFixedCapAssetReply struct {
-	AssetID ids.ID `json:"assetID"`
+// GetTxFee returns the fee schedule of this chain. A transaction pays TxFee,
+// plus ByteFee for each byte of the signed transaction, plus OperationFee for
+// each UTXO it consumes or produces.
+func (service *Service) GetTxFee(r *http.Request, _ *GetTxFeeArgs, reply *GetTxFeeReply) error {
+	service.vm.ctx.Log.Verbo("GetTxFee called in request %s", api.RequestID(r))
+
+	fees := &service.vm.fees
+	reply.Enabled = fees.Enabled(service.vm.clock.Time())
+	reply.AssetID = fees.AssetID
+	if !fees.StartTime.IsZero() {
+		reply.StartTime = json.Uint64(fees.StartTime.Unix())
+	}
+	reply.TxFee = json.Uint64(fees.TxFee)
+	reply.ByteFee = json.Uint64(fees.ByteFee)
+	reply.OperationFee = json.Uint64(fees.OperationFee)
+	return nil
 }
 
-// CreateFixedCapAsset returns ID of the newly created asset
-func (service *Service) CreateFixedCapAsset(r *http.Request, args *CreateFixedCapAssetArgs, reply *CreateFixedCapAssetReply) error {
-	service.vm.ctx.Log.Verbo("CreateFixedCapAsset called with name: %s symbol: %s number of holders: %d",
-		args.Name,
-		args.Symbol,
-		len(args.InitialHolders),
-	)
+// EstimateFeeArgs are arguments for passing into EstimateFee requests. Either
+// Tx, a transaction that may not be signed yet, or the Size of the signed
+// transaction and the NumOperations it consumes and produces must be provided.
+type EstimateFeeArgs struct {
+	Tx            formatting.CB58 `json:"tx"`
+	Size          json.Uint32     `json:"size"`
+	NumOperations json.Uint32     `json:"numOperations"`
+}
 
-	if len(args.InitialHolders) == 0 {
-		return errNoHolders
-	}
+// EstimateFeeReply defines the EstimateFee replies returned from the API
+type EstimateFeeReply struct {
+	Fee     json.Uint64 `json:"fee"`
+	AssetID ids.ID      `json:"assetID"`
+}
 
-	initialState := &InitialState{
-		FxID: 0, // TODO: Should lookup secp256k1fx FxID
+// EstimateFee returns the fee that a transaction must pay if it's issued now.
+// If the provided transaction is missing credentials, the fee is of the
+// transaction once it's signed.
+func (service *Service) EstimateFee(r *http.Request, args *EstimateFeeArgs, reply *EstimateFeeReply) error {
+	service.vm.ctx.Log.Verbo("EstimateFee called in request %s", api.RequestID(r))
+
+	size := int(args.Size)
+	numOperations := int(args.NumOperations)
+	if len(args.Tx.Bytes) != 0 {
+		tx := Tx{}
+		if err := service.vm.codec.Unmarshal(args.Tx.Bytes, &tx); err != nil {
+			return fmt.Errorf("problem parsing transaction: %w", err)
+		}
+		missing, err := missingCredentialsSize(&tx)
+		if err != nil {
+			return err
+		}
+		size = len(args.Tx.Bytes) + missing
+		numOperations = len(tx.InputUTXOs()) + len(tx.UTXOs())
+	} else if size == 0 {
+		return errNoTxOrSize
+	}
+
+	fees := &service.vm.fees
+	reply.AssetID = fees.AssetID
+	if !fees.Enabled(service.vm.clock.Time()) {
+		return nil
+	}
+	fee, err := fees.Fee(size, numOperations)
+	if err != nil {
+		return err
+	}
+	reply.Fee = json.Uint64(fee)
+	return nil
+}
+
+// missingCredentialsSize returns the number of bytes that signing [tx] adds to
+// it, assuming each input without a credential is given one
+func missingCredentialsSize(tx *Tx) (int, error) {
+	size := 0
+	for i, in := range txInputs(tx.UnsignedTx) {
+		if i < len(tx.Creds) {
+			continue
+		}
+		sigIndices, _, ok := inputSigIndices(in)
+		if !ok {
+			return 0, errUnknownInputType
+		}
+		// The type ID of the credential, the number of its signatures and the
+		// signatures
+		size += wrappers.IntLen + wrappers.IntLen + len(sigIndices)*crypto.SECP256K1RSigLen
+	}
+	return size, nil
+}
+
+// GetBalanceArgs are arguments for passing into GetBalance requests
+type GetBalanceArgs struct {
+	Address string `json:"address"`
+	AssetID string `json:"assetID"`
+}
+
+// GetBalanceReply defines the GetBalance replies returned from the API
+type GetBalanceReply struct {
+	Balance json.Uint64 `json:"balance"`
+}
+
+// GetBalance returns the amount of an asset that an address at least partially owns
+func (service *Service) GetBalance(r *http.Request, args *GetBalanceArgs, reply *GetBalanceReply) error {
+	service.vm.ctx.Log.Verbo("GetBalance called with address: %s assetID: %s in request %s", args.Address, args.AssetID, api.RequestID(r))
+
+	address, err := service.vm.Parse(args.Address)
+	if err != nil {
+		return err
+	}
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return err
+		}
+	}
+
+	addrSet := ids.Set{}
+	addrSet.Add(ids.NewID(hashing.ComputeHash256Array(address)))
+
+	utxos, err := service.vm.GetUTXOs(addrSet)
+	if err != nil {
+		return err
+	}
+
+	for _, utxo := range utxos {
+		if utxo.AssetID().Equals(assetID) {
+			transferable, ok := utxo.Out.(FxTransferable)
+			if !ok {
+				continue
+			}
+			amt, err := math.Add64(transferable.Amount(), uint64(reply.Balance))
+			if err != nil {
+				return err
+			}
+			reply.Balance = json.Uint64(amt)
+		}
+	}
+	return nil
+}
+
+// CreateFixedCapAssetArgs are arguments for passing into CreateFixedCapAsset requests
+type CreateFixedCapAssetArgs struct {
+	Username       string    `json:"username"`
+	Password       string    `json:"password"`
+	Name           string    `json:"name"`
+	Symbol         string    `json:"symbol"`
+	Denomination   byte      `json:"denomination"`
+	InitialHolders []*Holder `json:"initialHolders"`
+	Aggregate      bool      `json:"aggregate"`
+}
+
+// Holder describes how much an address owns of an asset
+type Holder struct {
+	Amount  json.Uint64 `json:"amount"`
+	Address string      `json:"address"`
+}
+
+// CreateFixedCapAssetReply defines the CreateFixedCapAsset replies returned from the API
+type CreateFixedCapAssetReply struct {
+	AssetID ids.ID `json:"assetID"`
+}
+
+// CreateFixedCapAsset returns ID of the newly created asset. If [args.Aggregate]
+// is set, the asset's outputs are spent with aggregated signatures, so a
+// transaction holds one signature however many of them it spends.
+func (service *Service) CreateFixedCapAsset(r *http.Request, args *CreateFixedCapAssetArgs, reply *CreateFixedCapAssetReply) error {
+	service.vm.ctx.Log.Verbo("CreateFixedCapAsset called with name: %s symbol: %s number of holders: %d in request %s",
+		args.Name,
+		args.Symbol,
+		len(args.InitialHolders),
+		api.RequestID(r),
+	)
+
+	if len(args.InitialHolders) == 0 {
+		return errNoHolders
+	}
+
+	initialState := &InitialState{
+		FxID: 0, // TODO: Should lookup secp256k1fx FxID
 		Outs: []verify.Verifiable{},
 	}
+	if args.Aggregate {
+		fxIndex, err := service.vm.schnorrFxIndex()
+		if err != nil {
+			return err
+		}
+		initialState.FxID = fxIndex
+	}
 
-	tx := &Tx{UnsignedTx: &CreateAssetTx{
+	utx := &CreateAssetTx{
 		BaseTx: BaseTx{
 			NetID: service.vm.ctx.NetworkID,
 			BCID:  service.vm.ctx.ChainID,
@@ -277,7 +1026,7 @@ func (service *Service) CreateFixedCapAsset(r *http.Request, args *CreateFixedCa
 		States: []*InitialState{
 			initialState,
 		},
-	}}
+	}
 
 	for _, holder := range args.InitialHolders {
 		address, err := service.vm.Parse(holder.Address)
@@ -288,24 +1037,30 @@ func (service *Service) CreateFixedCapAsset(r *http.Request, args *CreateFixedCa
 		if err != nil {
 			return err
 		}
-		initialState.Outs = append(initialState.Outs, &secp256k1fx.TransferOutput{
+		out := &secp256k1fx.TransferOutput{
 			Amt: uint64(holder.Amount),
 			OutputOwners: secp256k1fx.OutputOwners{
 				Threshold: 1,
 				Addrs:     []ids.ShortID{addr},
 			},
-		})
+		}
+		if args.Aggregate {
+			initialState.Outs = append(initialState.Outs, &schnorrfx.TransferOutput{TransferOutput: *out})
+		} else {
+			initialState.Outs = append(initialState.Outs, out)
+		}
 	}
 	initialState.Sort(service.vm.codec)
 
-	b, err := service.vm.codec.Marshal(tx)
-	if err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
-	}
-
-	assetID, err := service.vm.IssueTx(b)
+	assetID, err := service.issueWithFee(func(fee uint64) (*Tx, error) {
+		keys, err := service.payFee(args.Username, args.Password, &utx.BaseTx, fee)
+		if err != nil {
+			return nil, err
+		}
+		return service.sign(utx, keys)
+	})
 	if err != nil {
-		return fmt.Errorf("problem issuing transaction: %w", err)
+		return err
 	}
 
 	reply.AssetID = assetID
@@ -336,10 +1091,11 @@ type CreateVariableCapAssetReply struct {
 
 // CreateVariableCapAsset returns ID of the newly created asset
 func (service *Service) CreateVariableCapAsset(r *http.Request, args *CreateVariableCapAssetArgs, reply *CreateVariableCapAssetReply) error {
-	service.vm.ctx.Log.Verbo("CreateFixedCapAsset called with name: %s symbol: %s number of minters: %d",
+	service.vm.ctx.Log.Verbo("CreateFixedCapAsset called with name: %s symbol: %s number of minters: %d in request %s",
 		args.Name,
 		args.Symbol,
 		len(args.MinterSets),
+		api.RequestID(r),
 	)
 
 	if len(args.MinterSets) == 0 {
@@ -351,7 +1107,7 @@ func (service *Service) CreateVariableCapAsset(r *http.Request, args *CreateVari
 		Outs: []verify.Verifiable{},
 	}
 
-	tx := &Tx{UnsignedTx: &CreateAssetTx{
+	utx := &CreateAssetTx{
 		BaseTx: BaseTx{
 			NetID: service.vm.ctx.NetworkID,
 			BCID:  service.vm.ctx.ChainID,
@@ -362,7 +1118,7 @@ func (service *Service) CreateVariableCapAsset(r *http.Request, args *CreateVari
 		States: []*InitialState{
 			initialState,
 		},
-	}}
+	}
 
 	for _, owner := range args.MinterSets {
 		minter := &secp256k1fx.MintOutput{
@@ -386,14 +1142,15 @@ func (service *Service) CreateVariableCapAsset(r *http.Request, args *CreateVari
 	}
 	initialState.Sort(service.vm.codec)
 
-	b, err := service.vm.codec.Marshal(tx)
-	if err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
-	}
-
-	assetID, err := service.vm.IssueTx(b)
+	assetID, err := service.issueWithFee(func(fee uint64) (*Tx, error) {
+		keys, err := service.payFee(args.Username, args.Password, &utx.BaseTx, fee)
+		if err != nil {
+			return nil, err
+		}
+		return service.sign(utx, keys)
+	})
 	if err != nil {
-		return fmt.Errorf("problem issuing transaction: %w", err)
+		return err
 	}
 
 	reply.AssetID = assetID
@@ -414,7 +1171,7 @@ type CreateAddressReply struct {
 
 // CreateAddress creates an address for the user [args.Username]
 func (service *Service) CreateAddress(r *http.Request, args *CreateAddressArgs, reply *CreateAddressReply) error {
-	service.vm.ctx.Log.Verbo("CreateAddress called for user '%s'", args.Username)
+	service.vm.ctx.Log.Verbo("CreateAddress called for user '%s' in request %s", args.Username, api.RequestID(r))
 
 	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
 	if err != nil {
@@ -460,14 +1217,14 @@ type ExportKeyReply struct {
 
 // ExportKey returns a private key from the provided user
 func (service *Service) ExportKey(r *http.Request, args *ExportKeyArgs, reply *ExportKeyReply) error {
-	service.vm.ctx.Log.Verbo("ExportKey called for user '%s'", args.Username)
+	service.vm.ctx.Log.Verbo("ExportKey called for user '%s' in request %s", args.Username, api.RequestID(r))
 
 	address, err := service.vm.Parse(args.Address)
 	if err != nil {
 		return fmt.Errorf("problem parsing address: %w", err)
 	}
 
-	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
+	db, err := service.vm.ctx.Keystore.GetReadOnlyDatabase(args.Username, args.Password)
 	if err != nil {
 		return fmt.Errorf("problem retrieving user: %w", err)
 	}
@@ -498,7 +1255,7 @@ type ImportKeyReply struct {
 
 // ImportKey adds a private key to the provided user
 func (service *Service) ImportKey(r *http.Request, args *ImportKeyArgs, reply *ImportKeyReply) error {
-	service.vm.ctx.Log.Verbo("ImportKey called for user '%s'", args.Username)
+	service.vm.ctx.Log.Verbo("ImportKey called for user '%s' in request %s", args.Username, api.RequestID(r))
 
 	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
 	if err != nil {
@@ -529,43 +1286,40 @@ func (service *Service) ImportKey(r *http.Request, args *ImportKeyArgs, reply *I
 	return nil
 }
 
-// SendArgs are arguments for passing into Send requests
-type SendArgs struct {
-	Username string      `json:"username"`
-	Password string      `json:"password"`
-	Amount   json.Uint64 `json:"amount"`
-	AssetID  string      `json:"assetID"`
-	To       string      `json:"to"`
+// AddressBookEntry is an entry of a user's address book
+type AddressBookEntry struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
 }
 
-// SendReply defines the Send replies returned from the API
-type SendReply struct {
-	TxID ids.ID `json:"txID"`
+// AddAddressBookEntryArgs are arguments for passing into AddAddressBookEntry
+// requests
+type AddAddressBookEntryArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	AddressBookEntry
 }
 
-// Send returns the ID of the newly created transaction
-func (service *Service) Send(r *http.Request, args *SendArgs, reply *SendReply) error {
-	service.vm.ctx.Log.Verbo("Send called with username: %s", args.Username)
+// AddAddressBookEntryReply is the response from calling AddAddressBookEntry
+type AddAddressBookEntryReply struct {
+	Success bool `json:"success"`
+}
 
-	if args.Amount == 0 {
-		return errInvalidAmount
-	}
+// AddAddressBookEntry adds an entry to the user's address book, replacing any
+// entry with the same name. The name can be used in place of the address in
+// the wallet calls that send funds.
+func (service *Service) AddAddressBookEntry(r *http.Request, args *AddAddressBookEntryArgs, reply *AddAddressBookEntryReply) error {
+	service.vm.ctx.Log.Verbo("AddAddressBookEntry called for user '%s' with name '%s' in request %s", args.Username, args.Name, api.RequestID(r))
 
-	assetID, err := service.vm.Lookup(args.AssetID)
-	if err != nil {
-		assetID, err = ids.FromString(args.AssetID)
-		if err != nil {
-			return fmt.Errorf("asset '%s' not found", args.AssetID)
-		}
+	if args.Name == "" {
+		return errNoEntryName
 	}
-
-	toBytes, err := service.vm.Parse(args.To)
-	if err != nil {
-		return fmt.Errorf("problem parsing to address: %w", err)
+	if _, err := service.parseAddress(args.Name); err == nil {
+		return errEntryNameIsAddress
 	}
-	to, err := ids.ToShortID(toBytes)
+	addr, err := service.parseAddress(args.Address)
 	if err != nil {
-		return fmt.Errorf("problem parsing to address: %w", err)
+		return fmt.Errorf("problem parsing address: %w", err)
 	}
 
 	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
@@ -574,126 +1328,429 @@ func (service *Service) Send(r *http.Request, args *SendArgs, reply *SendReply)
 	}
 
 	user := userState{vm: service.vm}
+	if err := user.SetAddressBookEntry(db, args.Name, addr); err != nil {
+		return fmt.Errorf("problem saving address book entry: %w", err)
+	}
 
-	addresses, _ := user.Addresses(db)
+	reply.Success = true
+	return nil
+}
 
-	addrs := ids.Set{}
-	addrs.Add(addresses...)
-	utxos, err := service.vm.GetUTXOs(addrs)
+// RemoveAddressBookEntryArgs are arguments for passing into
+// RemoveAddressBookEntry requests
+type RemoveAddressBookEntryArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+}
+
+// RemoveAddressBookEntryReply is the response from calling
+// RemoveAddressBookEntry
+type RemoveAddressBookEntryReply struct {
+	Success bool `json:"success"`
+}
+
+// RemoveAddressBookEntry removes an entry from the user's address book
+func (service *Service) RemoveAddressBookEntry(r *http.Request, args *RemoveAddressBookEntryArgs, reply *RemoveAddressBookEntryReply) error {
+	service.vm.ctx.Log.Verbo("RemoveAddressBookEntry called for user '%s' with name '%s' in request %s", args.Username, args.Name, api.RequestID(r))
+
+	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
 	if err != nil {
-		return fmt.Errorf("problem retrieving user's UTXOs: %w", err)
+		return fmt.Errorf("problem retrieving user: %w", err)
 	}
 
-	kc := secp256k1fx.NewKeychain()
-	for _, addr := range addresses {
-		sk, err := user.Key(db, addr)
-		if err != nil {
-			return fmt.Errorf("problem retrieving private key: %w", err)
-		}
-		kc.Add(sk)
+	user := userState{vm: service.vm}
+	if _, err := user.AddressBookEntry(db, args.Name); err != nil {
+		return fmt.Errorf("problem retrieving address book entry '%s': %w", args.Name, err)
+	}
+	if err := user.RemoveAddressBookEntry(db, args.Name); err != nil {
+		return fmt.Errorf("problem removing address book entry: %w", err)
 	}
 
-	amountSpent := uint64(0)
-	time := service.vm.clock.Unix()
+	reply.Success = true
+	return nil
+}
 
-	ins := []*TransferableInput{}
-	keys := [][]*crypto.PrivateKeySECP256K1R{}
-	for _, utxo := range utxos {
-		if !utxo.AssetID().Equals(assetID) {
-			continue
-		}
-		inputIntf, signers, err := kc.Spend(utxo.Out, time)
-		if err != nil {
-			continue
-		}
-		input, ok := inputIntf.(FxTransferable)
-		if !ok {
-			continue
-		}
-		spent, err := math.Add64(amountSpent, input.Amount())
-		if err != nil {
-			return errSpendOverflow
-		}
-		amountSpent = spent
+// ListAddressBookArgs are arguments for passing into ListAddressBook requests
+type ListAddressBookArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
 
-		in := &TransferableInput{
-			UTXOID: utxo.UTXOID,
-			Asset:  Asset{ID: assetID},
-			In:     input,
-		}
+// ListAddressBookReply is the response from calling ListAddressBook
+type ListAddressBookReply struct {
+	Entries []AddressBookEntry `json:"entries"`
+}
 
-		ins = append(ins, in)
-		keys = append(keys, signers)
+// ListAddressBook returns the entries of the user's address book, sorted by
+// name
+func (service *Service) ListAddressBook(r *http.Request, args *ListAddressBookArgs, reply *ListAddressBookReply) error {
+	service.vm.ctx.Log.Verbo("ListAddressBook called for user '%s' in request %s", args.Username, api.RequestID(r))
 
-		if amountSpent >= uint64(args.Amount) {
-			break
-		}
+	db, err := service.vm.ctx.Keystore.GetReadOnlyDatabase(args.Username, args.Password)
+	if err != nil {
+		return fmt.Errorf("problem retrieving user: %w", err)
 	}
 
-	if amountSpent < uint64(args.Amount) {
-		return errInsufficientFunds
+	user := userState{vm: service.vm}
+	names, addrs, err := user.AddressBook(db)
+	if err != nil {
+		return fmt.Errorf("problem retrieving address book: %w", err)
 	}
 
-	sortTransferableInputsWithSigners(ins, keys)
-
-	outs := []*TransferableOutput{
-		&TransferableOutput{
-			Asset: Asset{
-				ID: assetID,
-			},
-			Out: &secp256k1fx.TransferOutput{
-				Amt:      uint64(args.Amount),
-				Locktime: 0,
-				OutputOwners: secp256k1fx.OutputOwners{
-					Threshold: 1,
-					Addrs:     []ids.ShortID{to},
-				},
-			},
-		},
+	reply.Entries = make([]AddressBookEntry, len(names))
+	for i, name := range names {
+		reply.Entries[i] = AddressBookEntry{
+			Name:    name,
+			Address: service.vm.Format(addrs[i].Bytes()),
+		}
 	}
+	return nil
+}
 
-	if amountSpent > uint64(args.Amount) {
-		changeAddr := kc.Keys[0].PublicKey().Address()
-		outs = append(outs,
-			&TransferableOutput{
-				Asset: Asset{
-					ID: assetID,
-				},
-				Out: &secp256k1fx.TransferOutput{
-					Amt:      amountSpent - uint64(args.Amount),
-					Locktime: 0,
-					OutputOwners: secp256k1fx.OutputOwners{
-						Threshold: 1,
-						Addrs:     []ids.ShortID{changeAddr},
-					},
-				},
-			},
-		)
+// lookupAddress returns the address that [addrStr] formats or, if it doesn't
+// format one, the address of the entry of the user's address book named
+// [addrStr]
+func (service *Service) lookupAddress(username, password, addrStr string) (ids.ShortID, error) {
+	addr, err := service.parseAddress(addrStr)
+	if err == nil {
+		return addr, nil
 	}
 
-	sortTransferableOutputs(outs, service.vm.codec)
+	db, dbErr := service.vm.ctx.Keystore.GetReadOnlyDatabase(username, password)
+	if dbErr != nil {
+		return ids.ShortID{}, err
+	}
+	user := userState{vm: service.vm}
+	if addr, entryErr := user.AddressBookEntry(db, addrStr); entryErr == nil {
+		return addr, nil
+	}
+	return ids.ShortID{}, fmt.Errorf("%w and no address book entry is named '%s'", err, addrStr)
+}
 
-	tx := Tx{
-		UnsignedTx: &BaseTx{
+// SendArgs are arguments for passing into Send requests
+type SendArgs struct {
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	Amount   json.Uint64 `json:"amount"`
+	AssetID  string      `json:"assetID"`
+	To       string      `json:"to"`
+}
+
+// SendReply defines the Send replies returned from the API
+type SendReply struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// Send returns the ID of the newly created transaction
+func (service *Service) Send(r *http.Request, args *SendArgs, reply *SendReply) error {
+	service.vm.ctx.Log.Verbo("Send called with username: %s in request %s", args.Username, api.RequestID(r))
+
+	to, err := service.lookupAddress(args.Username, args.Password, args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+
+	txID, err := service.send(args.Username, args.Password, args.AssetID, uint64(args.Amount), secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{to},
+	})
+	if err != nil {
+		return err
+	}
+
+	reply.TxID = txID
+	return nil
+}
+
+// SendMultisigArgs are arguments for passing into SendMultisig requests
+type SendMultisigArgs struct {
+	Username  string      `json:"username"`
+	Password  string      `json:"password"`
+	Amount    json.Uint64 `json:"amount"`
+	AssetID   string      `json:"assetID"`
+	Threshold json.Uint32 `json:"threshold"`
+	To        []string    `json:"to"`
+}
+
+// SendMultisig sends [args.Amount] of the user's funds to an output that can
+// only be spent with the signatures of [args.Threshold] of the [args.To]
+// addresses
+func (service *Service) SendMultisig(r *http.Request, args *SendMultisigArgs, reply *SendReply) error {
+	service.vm.ctx.Log.Verbo("SendMultisig called with username: %s in request %s", args.Username, api.RequestID(r))
+
+	owners := secp256k1fx.OutputOwners{
+		Threshold: uint32(args.Threshold),
+	}
+	for _, address := range args.To {
+		addr, err := service.lookupAddress(args.Username, args.Password, address)
+		if err != nil {
+			return fmt.Errorf("problem parsing to address '%s': %w", address, err)
+		}
+		owners.Addrs = append(owners.Addrs, addr)
+	}
+	ids.SortShortIDs(owners.Addrs)
+	if err := owners.Verify(); err != nil {
+		return fmt.Errorf("invalid owners: %w", err)
+	}
+
+	txID, err := service.send(args.Username, args.Password, args.AssetID, uint64(args.Amount), owners)
+	if err != nil {
+		return err
+	}
+
+	reply.TxID = txID
+	return nil
+}
+
+// send issues a transaction that pays [amount] of the user's funds of
+// [assetIDStr] to an output owned by [owners]
+func (service *Service) send(username, password, assetIDStr string, amount uint64, owners secp256k1fx.OutputOwners) (ids.ID, error) {
+	assetID, err := service.lookupAssetID(assetIDStr)
+	if err != nil {
+		return ids.ID{}, err
+	}
+
+	if amount == 0 {
+		return ids.ID{}, errInvalidAmount
+	}
+
+	return service.issueWithFee(func(fee uint64) (*Tx, error) {
+		amounts := map[[32]byte]uint64{assetID.Key(): amount}
+		if err := service.addFee(amounts, fee); err != nil {
+			return nil, err
+		}
+		ins, outs, keys, err := service.spend(username, password, amounts)
+		if err != nil {
+			return nil, err
+		}
+
+		outs = append(outs, &TransferableOutput{
+			Asset: Asset{
+				ID: assetID,
+			},
+			Out: service.transferOutput(assetID, &secp256k1fx.TransferOutput{
+				Amt:          amount,
+				Locktime:     0,
+				OutputOwners: owners,
+			}),
+		})
+		sortTransferableOutputs(outs, service.vm.codec)
+
+		return service.sign(&BaseTx{
 			NetID: service.vm.ctx.NetworkID,
 			BCID:  service.vm.ctx.ChainID,
 			Outs:  outs,
 			Ins:   ins,
-		},
+		}, keys)
+	})
+}
+
+// lookupAssetID returns the ID of the asset with the alias or ID [assetIDStr]
+func (service *Service) lookupAssetID(assetIDStr string) (ids.ID, error) {
+	assetID, err := service.vm.Lookup(assetIDStr)
+	if err != nil {
+		assetID, err = ids.FromString(assetIDStr)
+		if err != nil {
+			return ids.ID{}, fmt.Errorf("asset '%s' not found", assetIDStr)
+		}
+	}
+	return assetID, nil
+}
+
+// spend returns the inputs that consume at least [amounts] of the user's
+// funds, keyed by asset ID, along with the keys that sign each input and the
+// outputs that return any change to the user
+func (service *Service) spend(username, password string, amounts map[[32]byte]uint64) ([]*TransferableInput, []*TransferableOutput, [][]*crypto.PrivateKeySECP256K1R, error) {
+	for _, amount := range amounts {
+		if amount == 0 {
+			return nil, nil, nil, errInvalidAmount
+		}
+	}
+
+	utxos, kc, err := service.userUTXOs(username, password)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return service.spendUTXOs(utxos, kc, amounts)
+}
+
+// spendUTXOs returns the inputs that consume at least [amounts] of [utxos],
+// keyed by asset ID, along with the keys in [kc] that sign each input and the
+// outputs that return any change to the first key in [kc]
+func (service *Service) spendUTXOs(utxos []*UTXO, kc *secp256k1fx.Keychain, amounts map[[32]byte]uint64) ([]*TransferableInput, []*TransferableOutput, [][]*crypto.PrivateKeySECP256K1R, error) {
+	amountsSpent := make(map[[32]byte]uint64, len(amounts))
+	time := service.vm.clock.Unix()
+
+	ins := []*TransferableInput{}
+	keys := [][]*crypto.PrivateKeySECP256K1R{}
+	for _, utxo := range utxos {
+		assetKey := utxo.AssetID().Key()
+		amount, exists := amounts[assetKey]
+		if !exists || amountsSpent[assetKey] >= amount {
+			continue
+		}
+		inputIntf, signers, err := spendOutput(kc, utxo.Out, time)
+		if err != nil {
+			continue
+		}
+		input, ok := inputIntf.(FxTransferable)
+		if !ok {
+			continue
+		}
+		spent, err := math.Add64(amountsSpent[assetKey], input.Amount())
+		if err != nil {
+			return nil, nil, nil, errSpendOverflow
+		}
+		amountsSpent[assetKey] = spent
+
+		in := &TransferableInput{
+			UTXOID: utxo.UTXOID,
+			Asset:  Asset{ID: utxo.AssetID()},
+			In:     input,
+		}
+
+		ins = append(ins, in)
+		keys = append(keys, signers)
+	}
+
+	for assetKey, amount := range amounts {
+		if amountsSpent[assetKey] < amount {
+			return nil, nil, nil, errInsufficientFunds
+		}
+	}
+
+	sortTransferableInputsWithSigners(ins, keys)
+
+	outs := []*TransferableOutput{}
+	changeAddr := kc.Keys[0].PublicKey().Address()
+	for assetKey, amount := range amounts {
+		if amountsSpent[assetKey] == amount {
+			continue
+		}
+		assetID := ids.NewID(assetKey)
+		outs = append(outs,
+			&TransferableOutput{
+				Asset: Asset{
+					ID: assetID,
+				},
+				Out: service.transferOutput(assetID, &secp256k1fx.TransferOutput{
+					Amt:      amountsSpent[assetKey] - amount,
+					Locktime: 0,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{changeAddr},
+					},
+				}),
+			},
+		)
+	}
+	return ins, outs, keys, nil
+}
+
+// spendOutput returns an input that spends [out] with the keys in [kc], along
+// with the keys that must sign it
+func spendOutput(kc *secp256k1fx.Keychain, out verify.Verifiable, time uint64) (verify.Verifiable, []*crypto.PrivateKeySECP256K1R, error) {
+	schnorrOut, ok := out.(*schnorrfx.TransferOutput)
+	if !ok {
+		return kc.Spend(out, time)
+	}
+	inputIntf, signers, err := kc.Spend(&schnorrOut.TransferOutput, time)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &schnorrfx.TransferInput{
+		TransferInput: *inputIntf.(*secp256k1fx.TransferInput),
+	}, signers, nil
+}
+
+// transferOutput returns [out] as an output of [assetID]. If the asset's
+// outputs can be spent with aggregated signatures, the output is too.
+func (service *Service) transferOutput(assetID ids.ID, out *secp256k1fx.TransferOutput) FxTransferable {
+	if fxIndex, err := service.vm.schnorrFxIndex(); err == nil && service.vm.verifyFxUsage(int(fxIndex), assetID) {
+		return &schnorrfx.TransferOutput{TransferOutput: *out}
+	}
+	return out
+}
+
+// addFee adds [fee] to the amount of the fee asset in [amounts]
+func (service *Service) addFee(amounts map[[32]byte]uint64, fee uint64) error {
+	if fee == 0 {
+		return nil
+	}
+	assetKey := service.vm.fees.AssetID.Key()
+	amount, err := math.Add64(amounts[assetKey], fee)
+	if err != nil {
+		return errSpendOverflow
+	}
+	amounts[assetKey] = amount
+	return nil
+}
+
+// payFee sets the inputs and outputs of [base] to pay [fee] out of the user's
+// funds, and returns the keys that sign the inputs
+func (service *Service) payFee(username, password string, base *BaseTx, fee uint64) ([][]*crypto.PrivateKeySECP256K1R, error) {
+	if fee == 0 {
+		base.Ins = nil
+		base.Outs = nil
+		return nil, nil
+	}
+
+	ins, outs, keys, err := service.spend(username, password, map[[32]byte]uint64{
+		service.vm.fees.AssetID.Key(): fee,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortTransferableOutputs(outs, service.vm.codec)
+
+	base.Ins = ins
+	base.Outs = outs
+	return keys, nil
+}
+
+// sign returns [utx] with each of its inputs signed by the corresponding
+// [keys]
+func (service *Service) sign(utx UnsignedTx, keys [][]*crypto.PrivateKeySECP256K1R) (*Tx, error) {
+	tx := &Tx{
+		UnsignedTx: utx,
 	}
 
 	unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
 	if err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
+		return nil, fmt.Errorf("problem creating transaction: %w", err)
 	}
 	hash := hashing.ComputeHash256(unsignedBytes)
 
-	for _, credKeys := range keys {
+	// All of the schnorrfx inputs are signed by one aggregated signature, which
+	// is held by the credential of the first of them
+	ins := txInputs(utx)
+	aggregate := (*schnorrfx.Credential)(nil)
+	aggregateKeys := map[[crypto.SECP256K1RPKLen]byte]*crypto.PrivateKeySECP256K1R{}
+
+	for i, credKeys := range keys {
+		if _, ok := ins[i].(*schnorrfx.TransferInput); ok {
+			cred := &schnorrfx.Credential{
+				PubKeys: [][crypto.SECP256K1RPKLen]byte{},
+				Sigs:    [][crypto.SchnorrSigLen]byte{},
+			}
+			if aggregate == nil {
+				aggregate = cred
+			}
+			for _, key := range credKeys {
+				pk := [crypto.SECP256K1RPKLen]byte{}
+				copy(pk[:], key.PublicKey().Bytes())
+				aggregateKeys[pk] = key
+			}
+			tx.Creds = append(tx.Creds, &Credential{Cred: cred})
+			continue
+		}
+
 		cred := &secp256k1fx.Credential{}
 		for _, key := range credKeys {
 			sig, err := key.SignHash(hash)
 			if err != nil {
-				return fmt.Errorf("problem creating transaction: %w", err)
+				return nil, fmt.Errorf("problem creating transaction: %w", err)
 			}
 			fixedSig := [crypto.SECP256K1RSigLen]byte{}
 			copy(fixedSig[:], sig)
@@ -703,289 +1760,2044 @@ func (service *Service) Send(r *http.Request, args *SendArgs, reply *SendReply)
 		tx.Creds = append(tx.Creds, &Credential{Cred: cred})
 	}
 
-	b, err := service.vm.codec.Marshal(tx)
+	if aggregate != nil {
+		for pk := range aggregateKeys {
+			aggregate.PubKeys = append(aggregate.PubKeys, pk)
+		}
+		schnorrfx.SortPubKeys(aggregate.PubKeys)
+
+		signers := make([]*crypto.PrivateKeySECP256K1R, len(aggregate.PubKeys))
+		for i, pk := range aggregate.PubKeys {
+			signers[i] = aggregateKeys[pk]
+		}
+		sig, err := crypto.SignAggregateHash(signers, hash)
+		if err != nil {
+			return nil, fmt.Errorf("problem creating transaction: %w", err)
+		}
+		fixedSig := [crypto.SchnorrSigLen]byte{}
+		copy(fixedSig[:], sig)
+
+		aggregate.Sigs = append(aggregate.Sigs, fixedSig)
+	}
+	return tx, nil
+}
+
+// issueWithFee issues the transaction that [build] returns when it's passed
+// the fee that the transaction must pay. The fee depends on the transaction's
+// size and UTXOs, which depend on the fee, so the transaction is rebuilt until
+// it pays enough.
+func (service *Service) issueWithFee(build func(fee uint64) (*Tx, error)) (ids.ID, error) {
+	_, b, _, err := service.buildWithFee(build)
 	if err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
+		return ids.ID{}, err
 	}
 
 	txID, err := service.vm.IssueTx(b)
 	if err != nil {
-		return fmt.Errorf("problem issuing transaction: %w", err)
+		return ids.ID{}, fmt.Errorf("problem issuing transaction: %w", err)
 	}
-
-	reply.TxID = txID
-	return nil
+	return txID, nil
 }
 
-type innerSortTransferableInputsWithSigners struct {
-	ins     []*TransferableInput
-	signers [][]*crypto.PrivateKeySECP256K1R
-}
+// buildWithFee returns the transaction that [build] returns when it's passed
+// the fee that the transaction must pay, along with the transaction's bytes and
+// the fee
+func (service *Service) buildWithFee(build func(fee uint64) (*Tx, error)) (*Tx, []byte, uint64, error) {
+	fee := uint64(0)
+	for i := 0; i < maxFeeAttempts; i++ {
+		tx, err := build(fee)
+		if err != nil {
+			return nil, nil, 0, err
+		}
 
-func (ins *innerSortTransferableInputsWithSigners) Less(i, j int) bool {
-	iID, iIndex := ins.ins[i].InputSource()
-	jID, jIndex := ins.ins[j].InputSource()
+		b, err := service.vm.codec.Marshal(tx)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("problem creating transaction: %w", err)
+		}
 
-	switch bytes.Compare(iID.Bytes(), jID.Bytes()) {
-	case -1:
-		return true
-	case 0:
-		return iIndex < jIndex
-	default:
-		return false
+		requiredFee, err := service.vm.txFee(tx, b)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("problem creating transaction: %w", err)
+		}
+		if requiredFee > fee {
+			fee = requiredFee
+			continue
+		}
+		return tx, b, fee, nil
 	}
+	return nil, nil, 0, errFeeNotConverged
 }
-func (ins *innerSortTransferableInputsWithSigners) Len() int { return len(ins.ins) }
-func (ins *innerSortTransferableInputsWithSigners) Swap(i, j int) {
-	ins.ins[j], ins.ins[i] = ins.ins[i], ins.ins[j]
-	ins.signers[j], ins.signers[i] = ins.signers[i], ins.signers[j]
+
+// ConsolidateArgs are arguments for passing into Consolidate requests
+type ConsolidateArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// Alias or ID of the asset whose UTXOs are consolidated
+	AssetID string `json:"assetID"`
+
+	// Address, or address book entry, that receives the consolidated outputs.
+	// Defaults to one of the user's addresses.
+	To string `json:"to"`
+
+	// Only UTXOs holding less than MaxAmount of the asset are consolidated. If
+	// 0, every UTXO is.
+	MaxAmount json.Uint64 `json:"maxAmount"`
+
+	// Most UTXOs that each transaction consumes. Defaults to, and may not be
+	// more than, maxConsolidationInputs.
+	BatchSize json.Uint32 `json:"batchSize"`
+
+	// If true, the transactions are built but not issued
+	DryRun bool `json:"dryRun"`
 }
 
-func sortTransferableInputsWithSigners(ins []*TransferableInput, signers [][]*crypto.PrivateKeySECP256K1R) {
-	sort.Sort(&innerSortTransferableInputsWithSigners{ins: ins, signers: signers})
+// Consolidation describes a transaction that consolidates UTXOs
+type Consolidation struct {
+	IssueTxResult
+
+	// Number of the asset's UTXOs the transaction consumes
+	NumUTXOs json.Uint32 `json:"numUTXOs"`
+
+	// Amount of the asset in the consolidated output
+	Amount json.Uint64 `json:"amount"`
+
+	// Fee the transaction pays
+	Fee json.Uint64 `json:"fee"`
 }
-func isSortedAndUniqueTransferableInputsWithSigners(ins []*TransferableInput, signers [][]*crypto.PrivateKeySECP256K1R) bool {
-	return utils.IsSortedAndUnique(&innerSortTransferableInputsWithSigners{ins: ins, signers: signers})
+
+// ConsolidateReply defines the Consolidate replies returned from the API
+type ConsolidateReply struct {
+	// The transactions that were issued, or would have been issued if this
+	// were a dry run, in order
+	Consolidations []Consolidation `json:"consolidations"`
+
+	// Number of the user's UTXOs that could still be consolidated after these
+	// transactions are accepted
+	RemainingUTXOs json.Uint32 `json:"remainingUTXOs"`
 }
 
-// CreateMintTxArgs are arguments for passing into CreateMintTx requests
-type CreateMintTxArgs struct {
-	Amount  json.Uint64 `json:"amount"`
-	AssetID string      `json:"assetID"`
-	To      string      `json:"to"`
-	Minters []string    `json:"minters"`
+// Consolidate sweeps many small UTXOs of [args.AssetID] that the user holds
+// into fewer outputs. The UTXOs are consolidated in batches of
+// [args.BatchSize], one transaction per batch, and each transaction pays its
+// own fee. If the asset is the fee asset, the fee is paid out of the
+// consolidated UTXOs. Batches that wouldn't hold anything after paying their
+// fee are skipped. At most maxConsolidationTxs transactions are issued per
+// call, so a wallet with more UTXOs than that calls Consolidate repeatedly.
+func (service *Service) Consolidate(r *http.Request, args *ConsolidateArgs, reply *ConsolidateReply) error {
+	service.vm.ctx.Log.Verbo("Consolidate called with username: %s in request %s", args.Username, api.RequestID(r))
+
+	assetID, err := service.lookupAssetID(args.AssetID)
+	if err != nil {
+		return err
+	}
+
+	batchSize := int(args.BatchSize)
+	switch {
+	case batchSize == 0:
+		batchSize = maxConsolidationInputs
+	case batchSize < 2 || batchSize > maxConsolidationInputs:
+		return errInvalidBatchSize
+	}
+
+	utxos, kc, err := service.userUTXOs(args.Username, args.Password)
+	if err != nil {
+		return err
+	}
+	if len(kc.Keys) == 0 {
+		return nil // The user has no addresses, so no UTXOs
+	}
+
+	to := kc.Keys[0].PublicKey().Address()
+	if args.To != "" {
+		if to, err = service.lookupAddress(args.Username, args.Password, args.To); err != nil {
+			return fmt.Errorf("problem parsing to address: %w", err)
+		}
+	}
+
+	// The UTXOs to consolidate, and the UTXOs that may pay the fees
+	now := service.vm.clock.Unix()
+	dust := []*UTXO{}
+	feeUTXOs := []*UTXO{}
+	feeAsset := service.vm.fees.AssetID
+	for _, utxo := range utxos {
+		switch {
+		case utxo.AssetID().Equals(assetID):
+			inputIntf, _, err := spendOutput(kc, utxo.Out, now)
+			if err != nil {
+				continue
+			}
+			if input, ok := inputIntf.(FxTransferable); ok && (args.MaxAmount == 0 || input.Amount() < uint64(args.MaxAmount)) {
+				dust = append(dust, utxo)
+			}
+		case utxo.AssetID().Equals(feeAsset):
+			feeUTXOs = append(feeUTXOs, utxo)
+		}
+	}
+
+	// Spend the UTXOs in a deterministic order, so that a dry run reports the
+	// transactions that would be issued
+	sortUTXOs(dust)
+	sortUTXOs(feeUTXOs)
+
+	txs := [][]byte{}
+	for len(dust) >= 2 && len(txs) < maxConsolidationTxs {
+		batch := dust
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		dust = dust[len(batch):]
+
+		consolidation, b, spentFeeUTXOs, err := service.consolidate(batch, feeUTXOs, kc, assetID, to)
+		if err == errNothingToConsolidate {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		feeUTXOs = removeUTXOs(feeUTXOs, spentFeeUTXOs)
+
+		reply.Consolidations = append(reply.Consolidations, consolidation)
+		txs = append(txs, b)
+	}
+	if len(dust) >= 2 {
+		reply.RemainingUTXOs = json.Uint32(len(dust))
+	}
+
+	if args.DryRun || len(txs) == 0 {
+		return nil
+	}
+
+	txIDs, errs := service.vm.IssueTxs(txs)
+	for i, txID := range txIDs {
+		reply.Consolidations[i].TxID = txID
+		if errs[i] != nil {
+			reply.Consolidations[i].Error = errs[i].Error()
+		}
+	}
+	return nil
+}
+
+// consolidate returns a transaction that consumes [batch], UTXOs of [assetID],
+// and produces one output of [assetID] owned by [to]. If [assetID] isn't the
+// fee asset, the fee is paid with [feeUTXOs]. Also returns the bytes of the
+// transaction and the IDs of the [feeUTXOs] that it consumes.
+func (service *Service) consolidate(batch, feeUTXOs []*UTXO, kc *secp256k1fx.Keychain, assetID ids.ID, to ids.ShortID) (Consolidation, []byte, []ids.ID, error) {
+	now := service.vm.clock.Unix()
+	payFeeFromBatch := assetID.Equals(service.vm.fees.AssetID)
+
+	tx, b, fee, err := service.buildWithFee(func(fee uint64) (*Tx, error) {
+		total := uint64(0)
+		ins := []*TransferableInput{}
+		keys := [][]*crypto.PrivateKeySECP256K1R{}
+		for _, utxo := range batch {
+			inputIntf, signers, err := spendOutput(kc, utxo.Out, now)
+			if err != nil {
+				return nil, err
+			}
+			input := inputIntf.(FxTransferable)
+			if total, err = math.Add64(total, input.Amount()); err != nil {
+				return nil, errSpendOverflow
+			}
+			ins = append(ins, &TransferableInput{
+				UTXOID: utxo.UTXOID,
+				Asset:  Asset{ID: assetID},
+				In:     input,
+			})
+			keys = append(keys, signers)
+		}
+
+		outs := []*TransferableOutput{}
+		if payFeeFromBatch {
+			if total <= fee {
+				return nil, errNothingToConsolidate
+			}
+			total -= fee
+		} else if fee > 0 {
+			feeIns, feeOuts, feeKeys, err := service.spendUTXOs(feeUTXOs, kc, map[[32]byte]uint64{
+				service.vm.fees.AssetID.Key(): fee,
+			})
+			if err != nil {
+				return nil, err
+			}
+			ins = append(ins, feeIns...)
+			keys = append(keys, feeKeys...)
+			outs = append(outs, feeOuts...)
+		}
+		sortTransferableInputsWithSigners(ins, keys)
+
+		outs = append(outs, &TransferableOutput{
+			Asset: Asset{ID: assetID},
+			Out: service.transferOutput(assetID, &secp256k1fx.TransferOutput{
+				Amt: total,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{to},
+				},
+			}),
+		})
+		sortTransferableOutputs(outs, service.vm.codec)
+
+		return service.sign(&BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+			Outs:  outs,
+			Ins:   ins,
+		}, keys)
+	})
+	if err != nil {
+		return Consolidation{}, nil, nil, err
+	}
+	tx.Initialize(b)
+
+	spentFeeUTXOs := []ids.ID{}
+	amount := uint64(0)
+	if !payFeeFromBatch {
+		for _, in := range tx.UnsignedTx.(*BaseTx).Ins {
+			if in.AssetID().Equals(service.vm.fees.AssetID) {
+				spentFeeUTXOs = append(spentFeeUTXOs, in.InputID())
+			}
+		}
+	}
+	for _, out := range tx.UnsignedTx.(*BaseTx).Outs {
+		if out.AssetID().Equals(assetID) {
+			amount = out.Out.(FxTransferable).Amount()
+		}
+	}
+
+	return Consolidation{
+		IssueTxResult: IssueTxResult{TxID: tx.ID()},
+		NumUTXOs:      json.Uint32(len(batch)),
+		Amount:        json.Uint64(amount),
+		Fee:           json.Uint64(fee),
+	}, b, spentFeeUTXOs, nil
+}
+
+// sortUTXOs sorts [utxos] by ID
+func sortUTXOs(utxos []*UTXO) {
+	sort.Slice(utxos, func(i, j int) bool {
+		return bytes.Compare(utxos[i].InputID().Bytes(), utxos[j].InputID().Bytes()) < 0
+	})
+}
+
+// removeUTXOs returns [utxos] without the UTXOs [utxoIDs]
+func removeUTXOs(utxos []*UTXO, utxoIDs []ids.ID) []*UTXO {
+	removed := ids.Set{}
+	removed.Add(utxoIDs...)
+	remaining := []*UTXO{}
+	for _, utxo := range utxos {
+		if !removed.Contains(utxo.InputID()) {
+			remaining = append(remaining, utxo)
+		}
+	}
+	return remaining
+}
+
+// ExportArgs are arguments for passing into Export requests
+type ExportArgs struct {
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	Amount   json.Uint64 `json:"amount"`
+	AssetID  string      `json:"assetID"`
+
+	// DestinationChain is the alias or ID of the chain the funds are exported
+	// to
+	DestinationChain string `json:"destinationChain"`
+
+	// To is the address, on the destination chain, that can import the funds
+	To ids.ShortID `json:"to"`
+}
+
+// Export sends [args.Amount] of the user's funds to [args.To] on another
+// chain. The funds must be imported on the destination chain before they can
+// be spent there.
+func (service *Service) Export(r *http.Request, args *ExportArgs, reply *SendReply) error {
+	service.vm.ctx.Log.Verbo("Export called with username: %s in request %s", args.Username, api.RequestID(r))
+
+	chainID, err := service.lookupChainID(args.DestinationChain)
+	if err != nil {
+		return err
+	}
+	if chainID.Equals(service.vm.ctx.ChainID) {
+		return errExportToSameChain
+	}
+
+	assetID, err := service.lookupAssetID(args.AssetID)
+	if err != nil {
+		return err
+	}
+
+	if args.Amount == 0 {
+		return errInvalidAmount
+	}
+
+	txID, err := service.issueWithFee(func(fee uint64) (*Tx, error) {
+		amounts := map[[32]byte]uint64{assetID.Key(): uint64(args.Amount)}
+		if err := service.addFee(amounts, fee); err != nil {
+			return nil, err
+		}
+		ins, outs, keys, err := service.spend(args.Username, args.Password, amounts)
+		if err != nil {
+			return nil, err
+		}
+		sortTransferableOutputs(outs, service.vm.codec)
+
+		return service.sign(&ExportTx{
+			BaseTx: BaseTx{
+				NetID: service.vm.ctx.NetworkID,
+				BCID:  service.vm.ctx.ChainID,
+				Outs:  outs,
+				Ins:   ins,
+			},
+			DestinationChain: chainID,
+			ExportedOuts: []*TransferableOutput{&TransferableOutput{
+				Asset: Asset{
+					ID: assetID,
+				},
+				Out: &secp256k1fx.TransferOutput{
+					Amt:      uint64(args.Amount),
+					Locktime: 0,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{args.To},
+					},
+				},
+			}},
+		}, keys)
+	})
+	if err != nil {
+		return err
+	}
+
+	reply.TxID = txID
+	return nil
+}
+
+// ImportArgs are arguments for passing into Import requests
+type ImportArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// SourceChain is the alias or ID of the chain the funds were exported from
+	SourceChain string `json:"sourceChain"`
+
+	// To is the address on this chain that the imported funds are sent to
+	To string `json:"to"`
+}
+
+// ImportReply defines the Import replies returned from the API
+type ImportReply struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// Import issues a transaction that imports all the funds that [args.SourceChain]
+// exported to the user's addresses, and sends them to [args.To]
+func (service *Service) Import(r *http.Request, args *ImportArgs, reply *ImportReply) error {
+	service.vm.ctx.Log.Verbo("Import called with username: %s in request %s", args.Username, api.RequestID(r))
+
+	chainID, err := service.lookupChainID(args.SourceChain)
+	if err != nil {
+		return err
+	}
+	if chainID.Equals(service.vm.ctx.ChainID) {
+		return errImportFromSameChain
+	}
+
+	toBytes, err := service.vm.Parse(args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+	to, err := ids.ToShortID(toBytes)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+
+	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
+	if err != nil {
+		return fmt.Errorf("problem retrieving user: %w", err)
+	}
+
+	user := userState{vm: service.vm}
+
+	addresses, _ := user.Addresses(db)
+
+	kc := secp256k1fx.NewKeychain()
+	for _, addr := range addresses {
+		sk, err := user.Key(db, addr)
+		if err != nil {
+			return fmt.Errorf("problem retrieving private key: %w", err)
+		}
+		kc.Add(sk)
+	}
+
+	utxos, err := service.importableUTXOs(chainID, kc.Addrs.List())
+	if err != nil {
+		return fmt.Errorf("problem retrieving exported UTXOs: %w", err)
+	}
+
+	time := service.vm.clock.Unix()
+
+	ins := []*TransferableInput{}
+	keys := [][]*crypto.PrivateKeySECP256K1R{}
+	importedFunds := map[[32]byte]uint64{}
+	for _, utxo := range utxos {
+		inputIntf, signers, err := kc.Spend(&utxo.Out, time)
+		if err != nil {
+			continue
+		}
+		input, ok := inputIntf.(FxTransferable)
+		if !ok {
+			continue
+		}
+		assetKey := utxo.AssetID.Key()
+		imported, err := math.Add64(importedFunds[assetKey], input.Amount())
+		if err != nil {
+			return errSpendOverflow
+		}
+		importedFunds[assetKey] = imported
+
+		ins = append(ins, &TransferableInput{
+			UTXOID: UTXOID{
+				TxID:        utxo.TxID,
+				OutputIndex: utxo.OutputIndex,
+			},
+			Asset: Asset{ID: utxo.AssetID},
+			In:    input,
+		})
+		keys = append(keys, signers)
+	}
+	if len(ins) == 0 {
+		return errNoImportableFunds
+	}
+
+	sortTransferableInputsWithSigners(ins, keys)
+
+	// The fee is paid out of the imported funds
+	txID, err := service.issueWithFee(func(fee uint64) (*Tx, error) {
+		feeKey := [32]byte{}
+		if fee != 0 {
+			feeKey = service.vm.fees.AssetID.Key()
+			if fee > importedFunds[feeKey] {
+				return nil, errInsufficientFunds
+			}
+		}
+
+		outs := []*TransferableOutput{}
+		for assetKey, amount := range importedFunds {
+			if fee != 0 && assetKey == feeKey {
+				amount -= fee
+			}
+			if amount == 0 {
+				continue
+			}
+			outs = append(outs, &TransferableOutput{
+				Asset: Asset{
+					ID: ids.NewID(assetKey),
+				},
+				Out: &secp256k1fx.TransferOutput{
+					Amt:      amount,
+					Locktime: 0,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{to},
+					},
+				},
+			})
+		}
+		sortTransferableOutputs(outs, service.vm.codec)
+
+		return service.sign(&ImportTx{
+			BaseTx: BaseTx{
+				NetID: service.vm.ctx.NetworkID,
+				BCID:  service.vm.ctx.ChainID,
+				Outs:  outs,
+			},
+			SourceChain: chainID,
+			ImportedIns: ins,
+		}, keys)
+	})
+	if err != nil {
+		return err
+	}
+
+	reply.TxID = txID
+	return nil
+}
+
+// lookupChainID returns the ID of the chain with the alias or ID [chainStr]
+func (service *Service) lookupChainID(chainStr string) (ids.ID, error) {
+	chainID, err := service.vm.ctx.BCLookup.Lookup(chainStr)
+	if err != nil {
+		chainID, err = ids.FromString(chainStr)
+		if err != nil {
+			return ids.ID{}, fmt.Errorf("chain '%s' not found", chainStr)
+		}
+	}
+	return chainID, nil
+}
+
+// importableUTXOs returns the UTXOs that [chainID] exported to this chain that
+// are owned by at least one of [addresses]
+func (service *Service) importableUTXOs(chainID ids.ID, addresses []ids.ShortID) ([]*shared.UTXO, error) {
+	db := service.vm.ctx.SharedMemory.GetDatabase(chainID)
+	defer service.vm.ctx.SharedMemory.ReleaseDatabase(chainID)
+
+	state := shared.NewState(db, service.vm.ctx.ChainID)
+
+	utxoIDs := ids.Set{}
+	for _, addr := range addresses {
+		addrUTXOIDs, err := state.Funds(addr)
+		if err != nil {
+			return nil, err
+		}
+		utxoIDs.Add(addrUTXOIDs...)
+	}
+
+	utxos := []*shared.UTXO{}
+	for _, utxoID := range utxoIDs.List() {
+		utxo, err := state.UTXO(utxoID)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos, nil
+}
+
+type innerSortTransferableInputsWithSigners struct {
+	ins     []*TransferableInput
+	signers [][]*crypto.PrivateKeySECP256K1R
+}
+
+func (ins *innerSortTransferableInputsWithSigners) Less(i, j int) bool {
+	iID, iIndex := ins.ins[i].InputSource()
+	jID, jIndex := ins.ins[j].InputSource()
+
+	switch bytes.Compare(iID.Bytes(), jID.Bytes()) {
+	case -1:
+		return true
+	case 0:
+		return iIndex < jIndex
+	default:
+		return false
+	}
+}
+func (ins *innerSortTransferableInputsWithSigners) Len() int { return len(ins.ins) }
+func (ins *innerSortTransferableInputsWithSigners) Swap(i, j int) {
+	ins.ins[j], ins.ins[i] = ins.ins[i], ins.ins[j]
+	ins.signers[j], ins.signers[i] = ins.signers[i], ins.signers[j]
+}
+
+func sortTransferableInputsWithSigners(ins []*TransferableInput, signers [][]*crypto.PrivateKeySECP256K1R) {
+	sort.Sort(&innerSortTransferableInputsWithSigners{ins: ins, signers: signers})
+}
+func isSortedAndUniqueTransferableInputsWithSigners(ins []*TransferableInput, signers [][]*crypto.PrivateKeySECP256K1R) bool {
+	return utils.IsSortedAndUnique(&innerSortTransferableInputsWithSigners{ins: ins, signers: signers})
+}
+
+// CreateMintTxArgs are arguments for passing into CreateMintTx requests
+type CreateMintTxArgs struct {
+	Amount  json.Uint64 `json:"amount"`
+	AssetID string      `json:"assetID"`
+	To      string      `json:"to"`
+	Minters []string    `json:"minters"`
+}
+
+// CreateMintTxReply defines the CreateMintTx replies returned from the API
+type CreateMintTxReply struct {
+	Tx formatting.CB58 `json:"tx"`
+}
+
+// CreateMintTx returns the newly created unsigned transaction
+func (service *Service) CreateMintTx(r *http.Request, args *CreateMintTxArgs, reply *CreateMintTxReply) error {
+	service.vm.ctx.Log.Verbo("CreateMintTx called in request %s", api.RequestID(r))
+
+	if args.Amount == 0 {
+		return errInvalidMintAmount
+	}
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return fmt.Errorf("asset '%s' not found", args.AssetID)
+		}
+	}
+
+	toBytes, err := service.vm.Parse(args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address '%s': %w", args.To, err)
+	}
+	to, err := ids.ToShortID(toBytes)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address '%s': %w", args.To, err)
+	}
+
+	addrs := ids.Set{}
+	minters := ids.ShortSet{}
+	for _, minter := range args.Minters {
+		addrBytes, err := service.vm.Parse(minter)
+		if err != nil {
+			return fmt.Errorf("problem parsing minter address '%s': %w", minter, err)
+		}
+		addr, err := ids.ToShortID(addrBytes)
+		if err != nil {
+			return fmt.Errorf("problem parsing minter address '%s': %w", minter, err)
+		}
+		addrs.Add(ids.NewID(hashing.ComputeHash256Array(addrBytes)))
+		minters.Add(addr)
+	}
+
+	utxos, err := service.vm.GetUTXOs(addrs)
+	if err != nil {
+		return fmt.Errorf("problem getting user's UTXOs: %w", err)
+	}
+
+	for _, utxo := range utxos {
+		switch out := utxo.Out.(type) {
+		case *secp256k1fx.MintOutput:
+			if !utxo.AssetID().Equals(assetID) {
+				continue
+			}
+			sigs := []uint32{}
+			for i := uint32(0); i < uint32(len(out.Addrs)) && uint32(len(sigs)) < out.Threshold; i++ {
+				if minters.Contains(out.Addrs[i]) {
+					sigs = append(sigs, i)
+				}
+			}
+
+			if uint32(len(sigs)) != out.Threshold {
+				continue
+			}
+
+			tx := Tx{
+				UnsignedTx: &OperationTx{
+					BaseTx: BaseTx{
+						NetID: service.vm.ctx.NetworkID,
+						BCID:  service.vm.ctx.ChainID,
+					},
+					Ops: []*Operation{
+						&Operation{
+							Asset: Asset{
+								ID: assetID,
+							},
+							Ins: []*OperableInput{
+								&OperableInput{
+									UTXOID: utxo.UTXOID,
+									In: &secp256k1fx.MintInput{
+										Input: secp256k1fx.Input{
+											SigIndices: sigs,
+										},
+									},
+								},
+							},
+							Outs: []*OperableOutput{
+								&OperableOutput{
+									&secp256k1fx.MintOutput{
+										OutputOwners: out.OutputOwners,
+									},
+								},
+								&OperableOutput{
+									&secp256k1fx.TransferOutput{
+										Amt: uint64(args.Amount),
+										OutputOwners: secp256k1fx.OutputOwners{
+											Threshold: 1,
+											Addrs:     []ids.ShortID{to},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			txBytes, err := service.vm.codec.Marshal(&tx)
+			if err != nil {
+				return fmt.Errorf("problem creating transaction: %w", err)
+			}
+			reply.Tx.Bytes = txBytes
+			return nil
+		}
+	}
+
+	return errAddressesCantMintAsset
+}
+
+// SignMintTxArgs are arguments for passing into SignMintTx requests
+type SignMintTxArgs struct {
+	Username string          `json:"username"`
+	Password string          `json:"password"`
+	Minter   string          `json:"minter"`
+	Tx       formatting.CB58 `json:"tx"`
+}
+
+// SignMintTxReply defines the SignMintTx replies returned from the API
+type SignMintTxReply struct {
+	Tx formatting.CB58 `json:"tx"`
+}
+
+// SignMintTx returns the newly signed transaction
+func (service *Service) SignMintTx(r *http.Request, args *SignMintTxArgs, reply *SignMintTxReply) error {
+	service.vm.ctx.Log.Verbo("SignMintTx called in request %s", api.RequestID(r))
+
+	minter, err := service.vm.Parse(args.Minter)
+	if err != nil {
+		return fmt.Errorf("problem parsing address '%s': %w", args.Minter, err)
+	}
+
+	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
+	if err != nil {
+		return fmt.Errorf("problem retrieving user: %w", err)
+	}
+
+	user := userState{vm: service.vm}
+
+	addr := ids.NewID(hashing.ComputeHash256Array(minter))
+	sk, err := user.Key(db, addr)
+	if err != nil {
+		return fmt.Errorf("problem retriving private key: %w", err)
+	}
+
+	tx := Tx{}
+	if err := service.vm.codec.Unmarshal(args.Tx.Bytes, &tx); err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	inputUTXOs := tx.InputUTXOs()
+	if len(inputUTXOs) != 1 {
+		return errCanOnlySignSingleInputTxs
+	}
+	inputUTXO := inputUTXOs[0]
+
+	inputTxID, utxoIndex := inputUTXO.InputSource()
+	utx := UniqueTx{
+		vm:   service.vm,
+		txID: inputTxID,
+	}
+	if !utx.Status().Fetched() {
+		return errUnknownUTXO
+	}
+	utxos := utx.UTXOs()
+	if uint32(len(utxos)) <= utxoIndex {
+		return errInvalidUTXO
+	}
+
+	utxo := utxos[int(utxoIndex)]
+
+	i := -1
+	size := 0
+	switch out := utxo.Out.(type) {
+	case *secp256k1fx.MintOutput:
+		size = int(out.Threshold)
+		for j, addr := range out.Addrs {
+			if bytes.Equal(addr.Bytes(), minter) {
+				i = j
+				break
+			}
+		}
+	default:
+		return errUnknownOutputType
+	}
+	if i == -1 {
+		return errUnneededAddress
+
+	}
+
+	if len(tx.Creds) == 0 {
+		tx.Creds = append(tx.Creds, &Credential{Cred: &secp256k1fx.Credential{}})
+	}
+
+	cred := tx.Creds[0]
+	switch cred := cred.Cred.(type) {
+	case *secp256k1fx.Credential:
+		if len(cred.Sigs) != size {
+			cred.Sigs = make([][crypto.SECP256K1RSigLen]byte, size)
+		}
+
+		unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
+		if err != nil {
+			return fmt.Errorf("problem creating transaction: %w", err)
+		}
+
+		sig, err := sk.Sign(unsignedBytes)
+		if err != nil {
+			return fmt.Errorf("problem signing transaction: %w", err)
+		}
+		copy(cred.Sigs[i][:], sig)
+	default:
+		return errUnknownCredentialType
+	}
+
+	txBytes, err := service.vm.codec.Marshal(&tx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+	reply.Tx.Bytes = txBytes
+	return nil
+}
+
+// CreateSpendTxArgs are arguments for passing into CreateSpendTx requests
+type CreateSpendTxArgs struct {
+	Amount  json.Uint64 `json:"amount"`
+	AssetID string      `json:"assetID"`
+	To      string      `json:"to"`
+	Signers []string    `json:"signers"`
+}
+
+// CreateSpendTxReply defines the CreateSpendTx replies returned from the API
+type CreateSpendTxReply struct {
+	Tx formatting.CB58 `json:"tx"`
+}
+
+// CreateSpendTx returns a transaction, without any signatures, that sends
+// [args.Amount] to [args.To] from outputs that [args.Signers] can spend
+// together. This allows multisig outputs to be spent by passing the
+// transaction to each signer in turn, with SignTx, before issuing it. Change
+// is returned to the owners of the first output spent.
+func (service *Service) CreateSpendTx(r *http.Request, args *CreateSpendTxArgs, reply *CreateSpendTxReply) error {
+	service.vm.ctx.Log.Verbo("CreateSpendTx called in request %s", api.RequestID(r))
+
+	if args.Amount == 0 {
+		return errInvalidAmount
+	}
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return fmt.Errorf("asset '%s' not found", args.AssetID)
+		}
+	}
+
+	toBytes, err := service.vm.Parse(args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address '%s': %w", args.To, err)
+	}
+	to, err := ids.ToShortID(toBytes)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address '%s': %w", args.To, err)
+	}
+
+	addrs := ids.Set{}
+	signers := ids.ShortSet{}
+	for _, signer := range args.Signers {
+		addrBytes, err := service.vm.Parse(signer)
+		if err != nil {
+			return fmt.Errorf("problem parsing signer address '%s': %w", signer, err)
+		}
+		addr, err := ids.ToShortID(addrBytes)
+		if err != nil {
+			return fmt.Errorf("problem parsing signer address '%s': %w", signer, err)
+		}
+		addrs.Add(ids.NewID(hashing.ComputeHash256Array(addrBytes)))
+		signers.Add(addr)
+	}
+
+	utxos, err := service.vm.GetUTXOs(addrs)
+	if err != nil {
+		return fmt.Errorf("problem getting signers' UTXOs: %w", err)
+	}
+
+	amount := uint64(args.Amount)
+	amountSpent := uint64(0)
+	time := service.vm.clock.Unix()
+
+	var changeOwners *secp256k1fx.OutputOwners
+	ins := []*TransferableInput{}
+	for _, utxo := range utxos {
+		out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !ok || !utxo.AssetID().Equals(assetID) || out.Locktime > time {
+			continue
+		}
+		sigs := []uint32{}
+		for i := uint32(0); i < uint32(len(out.Addrs)) && uint32(len(sigs)) < out.Threshold; i++ {
+			if signers.Contains(out.Addrs[i]) {
+				sigs = append(sigs, i)
+			}
+		}
+		if uint32(len(sigs)) != out.Threshold {
+			continue
+		}
+
+		spent, err := math.Add64(amountSpent, out.Amt)
+		if err != nil {
+			return errSpendOverflow
+		}
+		amountSpent = spent
+
+		ins = append(ins, &TransferableInput{
+			UTXOID: utxo.UTXOID,
+			Asset:  Asset{ID: assetID},
+			In: &secp256k1fx.TransferInput{
+				Amt: out.Amt,
+				Input: secp256k1fx.Input{
+					SigIndices: sigs,
+				},
+			},
+		})
+		if changeOwners == nil {
+			changeOwners = &out.OutputOwners
+		}
+
+		if amountSpent >= amount {
+			break
+		}
+	}
+
+	if amountSpent < amount {
+		return errInsufficientFunds
+	}
+
+	sortTransferableInputs(ins)
+
+	outs := []*TransferableOutput{
+		&TransferableOutput{
+			Asset: Asset{
+				ID: assetID,
+			},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{to},
+				},
+			},
+		},
+	}
+	if amountSpent > amount {
+		outs = append(outs, &TransferableOutput{
+			Asset: Asset{
+				ID: assetID,
+			},
+			Out: &secp256k1fx.TransferOutput{
+				Amt:          amountSpent - amount,
+				OutputOwners: *changeOwners,
+			},
+		})
+	}
+	sortTransferableOutputs(outs, service.vm.codec)
+
+	tx := Tx{
+		UnsignedTx: &BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+			Outs:  outs,
+			Ins:   ins,
+		},
+	}
+	// Each signature is left empty until a signer fills it in with SignTx
+	for _, in := range ins {
+		numSigs := len(in.In.(*secp256k1fx.TransferInput).SigIndices)
+		tx.Creds = append(tx.Creds, &Credential{Cred: &secp256k1fx.Credential{
+			Sigs: make([][crypto.SECP256K1RSigLen]byte, numSigs),
+		}})
+	}
+
+	txBytes, err := service.vm.codec.Marshal(&tx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+	reply.Tx.Bytes = txBytes
+	return nil
+}
+
+// SignTxArgs are arguments for passing into SignTx requests
+type SignTxArgs struct {
+	Username string          `json:"username"`
+	Password string          `json:"password"`
+	Signer   string          `json:"signer"`
+	Tx       formatting.CB58 `json:"tx"`
+}
+
+// SignTxReply defines the SignTx replies returned from the API
+type SignTxReply struct {
+	Tx formatting.CB58 `json:"tx"`
+}
+
+// SignTx adds the signature of [args.Signer] to each input of [args.Tx] that
+// requires it. The other signatures are left as they are, so a transaction
+// spending multisig outputs is complete, and can be issued with IssueTx, once
+// each of its signers has signed it.
+func (service *Service) SignTx(r *http.Request, args *SignTxArgs, reply *SignTxReply) error {
+	service.vm.ctx.Log.Verbo("SignTx called in request %s", api.RequestID(r))
+
+	signerBytes, err := service.vm.Parse(args.Signer)
+	if err != nil {
+		return fmt.Errorf("problem parsing address '%s': %w", args.Signer, err)
+	}
+	signer, err := ids.ToShortID(signerBytes)
+	if err != nil {
+		return fmt.Errorf("problem parsing address '%s': %w", args.Signer, err)
+	}
+
+	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
+	if err != nil {
+		return fmt.Errorf("problem retrieving user: %w", err)
+	}
+
+	user := userState{vm: service.vm}
+
+	sk, err := user.Key(db, ids.NewID(hashing.ComputeHash256Array(signerBytes)))
+	if err != nil {
+		return fmt.Errorf("problem retriving private key: %w", err)
+	}
+
+	tx := Tx{}
+	if err := service.vm.codec.Unmarshal(args.Tx.Bytes, &tx); err != nil {
+		return fmt.Errorf("problem parsing transaction: %w", err)
+	}
+
+	unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+	sig, err := sk.Sign(unsignedBytes)
+	if err != nil {
+		return fmt.Errorf("problem signing transaction: %w", err)
+	}
+	if err := service.addSignature(&tx, signer, sig); err != nil {
+		return err
+	}
+
+	txBytes, err := service.vm.codec.Marshal(&tx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+	reply.Tx.Bytes = txBytes
+	return nil
+}
+
+// addSignature adds [sig], by [signer], to each input of [tx] that requires
+// it. Returns errUnneededAddress if no input does.
+func (service *Service) addSignature(tx *Tx, signer ids.ShortID, sig []byte) error {
+	inputUTXOs := tx.InputUTXOs()
+	inputs := txInputs(tx.UnsignedTx)
+	signed := false
+	for i, inputUTXO := range inputUTXOs {
+		sigIndices, cred, ok := inputSigIndices(inputs[i])
+		if !ok {
+			return errUnknownInputType
+		}
+		utxo, err := service.getUTXO(inputUTXO)
+		if err != nil {
+			return err
+		}
+		owners, ok := outputOwners(utxo.Out)
+		if !ok {
+			return errUnknownOutputType
+		}
+
+		position := -1
+		for j, index := range sigIndices {
+			if int(index) < len(owners.Addrs) && owners.Addrs[index].Equals(signer) {
+				position = j
+				break
+			}
+		}
+		if position == -1 {
+			continue
+		}
+
+		for len(tx.Creds) <= i {
+			tx.Creds = append(tx.Creds, &Credential{})
+		}
+		if tx.Creds[i].Cred == nil {
+			tx.Creds[i].Cred = cred
+		}
+		sigs, ok := credentialSigs(tx.Creds[i].Cred)
+		if !ok {
+			return errUnknownCredentialType
+		}
+		if len(*sigs) != len(sigIndices) {
+			*sigs = make([][crypto.SECP256K1RSigLen]byte, len(sigIndices))
+		}
+		copy((*sigs)[position][:], sig)
+		signed = true
+	}
+	if !signed {
+		return errUnneededAddress
+	}
+	return nil
+}
+
+// getUTXO returns the UTXO [utxoID] refers to, which may have been produced by
+// a transaction that hasn't been accepted yet
+func (service *Service) getUTXO(utxoID *UTXOID) (*UTXO, error) {
+	inputTxID, utxoIndex := utxoID.InputSource()
+	utx := UniqueTx{
+		vm:   service.vm,
+		txID: inputTxID,
+	}
+	if !utx.Status().Fetched() {
+		return nil, errUnknownUTXO
+	}
+	utxos := utx.UTXOs()
+	if uint32(len(utxos)) <= utxoIndex {
+		return nil, errInvalidUTXO
+	}
+	return utxos[int(utxoIndex)], nil
+}
+
+// txInputs returns the inputs of [utx], in the order of its credentials
+func txInputs(utx UnsignedTx) []verify.Verifiable {
+	ins := []verify.Verifiable(nil)
+	for _, in := range utx.Inputs() {
+		ins = append(ins, in.In)
+	}
+	switch utx := utx.(type) {
+	case *OperationTx:
+		for _, op := range utx.Ops {
+			for _, in := range op.Ins {
+				ins = append(ins, in.In)
+			}
+		}
+	case *ImportTx:
+		for _, in := range utx.ImportedIns {
+			ins = append(ins, in.In)
+		}
+	case *AssetMetadataTx:
+		ins = append(ins, utx.ControlIn.In)
+	}
+	return ins
+}
+
+// inputSigIndices returns the indices of the addresses that must sign [in],
+// along with an empty credential of the type that [in] is spent with
+func inputSigIndices(in verify.Verifiable) ([]uint32, verify.Verifiable, bool) {
+	switch in := in.(type) {
+	case *secp256k1fx.TransferInput:
+		return in.SigIndices, &secp256k1fx.Credential{}, true
+	case *secp256k1fx.MintInput:
+		return in.SigIndices, &secp256k1fx.Credential{}, true
+	case *nftfx.MintInput:
+		return in.SigIndices, &nftfx.Credential{}, true
+	case *nftfx.TransferInput:
+		return in.SigIndices, &nftfx.Credential{}, true
+	case *managedfx.ManagerInput:
+		return in.SigIndices, &managedfx.Credential{}, true
+	case *managedfx.ManagedInput:
+		return nil, &managedfx.Credential{}, true
+	case *managedfx.TransferInput:
+		return in.SigIndices, &managedfx.Credential{}, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// outputOwners returns the owners whose signatures are needed to spend [out]
+func outputOwners(out verify.Verifiable) (*secp256k1fx.OutputOwners, bool) {
+	switch out := out.(type) {
+	case *secp256k1fx.TransferOutput:
+		return &out.OutputOwners, true
+	case *secp256k1fx.MintOutput:
+		return &out.OutputOwners, true
+	case *nftfx.MintOutput:
+		return &out.OutputOwners, true
+	case *nftfx.TransferOutput:
+		return &out.OutputOwners, true
+	case *managedfx.ManagerOutput:
+		return &out.OutputOwners, true
+	case *managedfx.TransferOutput:
+		return &out.OutputOwners, true
+	default:
+		return nil, false
+	}
+}
+
+// credentialSigs returns a reference to the signatures of [cred]
+func credentialSigs(cred verify.Verifiable) (*[][crypto.SECP256K1RSigLen]byte, bool) {
+	switch cred := cred.(type) {
+	case *secp256k1fx.Credential:
+		return &cred.Sigs, true
+	case *nftfx.Credential:
+		return &cred.Sigs, true
+	case *managedfx.Credential:
+		return &cred.Sigs, true
+	default:
+		return nil, false
+	}
+}
+
+// CreateNFTAssetArgs are arguments for passing into CreateNFTAsset requests
+type CreateNFTAssetArgs struct {
+	Username   string   `json:"username"`
+	Password   string   `json:"password"`
+	Name       string   `json:"name"`
+	Symbol     string   `json:"symbol"`
+	MinterSets []Owners `json:"minterSets"`
+}
+
+// CreateNFTAssetReply defines the CreateNFTAsset replies returned from the API
+type CreateNFTAssetReply struct {
+	AssetID ids.ID `json:"assetID"`
+}
+
+// CreateNFTAsset creates a non-fungible asset. Each minter set is given the
+// right to mint unique outputs in its own group, numbered in the order the
+// minter sets were provided.
+func (service *Service) CreateNFTAsset(r *http.Request, args *CreateNFTAssetArgs, reply *CreateNFTAssetReply) error {
+	service.vm.ctx.Log.Verbo("CreateNFTAsset called with name: %s symbol: %s number of minters: %d in request %s",
+		args.Name,
+		args.Symbol,
+		len(args.MinterSets),
+		api.RequestID(r),
+	)
+
+	if len(args.MinterSets) == 0 {
+		return errNoMinters
+	}
+
+	fxIndex, err := service.vm.nftFxIndex()
+	if err != nil {
+		return err
+	}
+
+	initialState := &InitialState{
+		FxID: fxIndex,
+		Outs: []verify.Verifiable{},
+	}
+
+	utx := &CreateAssetTx{
+		BaseTx: BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+		},
+		Name:   args.Name,
+		Symbol: args.Symbol,
+		States: []*InitialState{
+			initialState,
+		},
+	}
+
+	for i, owner := range args.MinterSets {
+		minter := &nftfx.MintOutput{
+			GroupID: uint32(i),
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: uint32(owner.Threshold),
+			},
+		}
+		for _, address := range owner.Minters {
+			addrBytes, err := service.vm.Parse(address)
+			if err != nil {
+				return err
+			}
+			addr, err := ids.ToShortID(addrBytes)
+			if err != nil {
+				return err
+			}
+			minter.Addrs = append(minter.Addrs, addr)
+		}
+		ids.SortShortIDs(minter.Addrs)
+		initialState.Outs = append(initialState.Outs, minter)
+	}
+	initialState.Sort(service.vm.codec)
+
+	assetID, err := service.issueWithFee(func(fee uint64) (*Tx, error) {
+		keys, err := service.payFee(args.Username, args.Password, &utx.BaseTx, fee)
+		if err != nil {
+			return nil, err
+		}
+		return service.sign(utx, keys)
+	})
+	if err != nil {
+		return err
+	}
+
+	reply.AssetID = assetID
+	return nil
+}
+
+// MintNFTArgs are arguments for passing into MintNFT requests
+type MintNFTArgs struct {
+	Username string          `json:"username"`
+	Password string          `json:"password"`
+	AssetID  string          `json:"assetID"`
+	Payload  formatting.CB58 `json:"payload"`
+	To       string          `json:"to"`
+}
+
+// MintNFTReply defines the MintNFT replies returned from the API
+type MintNFTReply struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// MintNFT mints a unique output of the asset [args.AssetID], carrying
+// [args.Payload], to [args.To]. The user must hold the keys of one of the
+// asset's minter sets.
+func (service *Service) MintNFT(r *http.Request, args *MintNFTArgs, reply *MintNFTReply) error {
+	service.vm.ctx.Log.Verbo("MintNFT called with username: %s in request %s", args.Username, api.RequestID(r))
+
+	if len(args.Payload.Bytes) > nftfx.MaxPayloadSize {
+		return errPayloadTooLarge
+	}
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return fmt.Errorf("asset '%s' not found", args.AssetID)
+		}
+	}
+
+	to, err := service.lookupAddress(args.Username, args.Password, args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+
+	utxos, kc, err := service.userUTXOs(args.Username, args.Password)
+	if err != nil {
+		return err
+	}
+
+	for _, utxo := range utxos {
+		out, ok := utxo.Out.(*nftfx.MintOutput)
+		if !ok || !utxo.AssetID().Equals(assetID) {
+			continue
+		}
+		sigs, signers, ok := kc.Match(&out.OutputOwners)
+		if !ok {
+			continue
+		}
+
+		txID, err := service.issueOperation(args.Username, args.Password, &Operation{
+			Asset: Asset{
+				ID: assetID,
+			},
+			Ins: []*OperableInput{
+				&OperableInput{
+					UTXOID: utxo.UTXOID,
+					In: &nftfx.MintInput{
+						Input: secp256k1fx.Input{
+							SigIndices: sigs,
+						},
+					},
+				},
+			},
+			Outs: []*OperableOutput{
+				&OperableOutput{
+					&nftfx.MintOutput{
+						GroupID:      out.GroupID,
+						OutputOwners: out.OutputOwners,
+					},
+				},
+				&OperableOutput{
+					&nftfx.TransferOutput{
+						GroupID: out.GroupID,
+						Payload: args.Payload.Bytes,
+						OutputOwners: secp256k1fx.OutputOwners{
+							Threshold: 1,
+							Addrs:     []ids.ShortID{to},
+						},
+					},
+				},
+			},
+		}, signers)
+		if err != nil {
+			return err
+		}
+
+		reply.TxID = txID
+		return nil
+	}
+
+	return errAddressesCantMintAsset
+}
+
+// SendNFTArgs are arguments for passing into SendNFT requests
+type SendNFTArgs struct {
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	AssetID  string      `json:"assetID"`
+	GroupID  json.Uint32 `json:"groupID"`
+	To       string      `json:"to"`
+}
+
+// SendNFTReply defines the SendNFT replies returned from the API
+type SendNFTReply struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// SendNFT sends one of the user's unique outputs of the group [args.GroupID]
+// of the asset [args.AssetID] to [args.To]
+func (service *Service) SendNFT(r *http.Request, args *SendNFTArgs, reply *SendNFTReply) error {
+	service.vm.ctx.Log.Verbo("SendNFT called with username: %s in request %s", args.Username, api.RequestID(r))
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return fmt.Errorf("asset '%s' not found", args.AssetID)
+		}
+	}
+
+	to, err := service.lookupAddress(args.Username, args.Password, args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+
+	utxos, kc, err := service.userUTXOs(args.Username, args.Password)
+	if err != nil {
+		return err
+	}
+
+	for _, utxo := range utxos {
+		out, ok := utxo.Out.(*nftfx.TransferOutput)
+		if !ok || !utxo.AssetID().Equals(assetID) || out.GroupID != uint32(args.GroupID) {
+			continue
+		}
+		sigs, signers, ok := kc.Match(&out.OutputOwners)
+		if !ok {
+			continue
+		}
+
+		txID, err := service.issueOperation(args.Username, args.Password, &Operation{
+			Asset: Asset{
+				ID: assetID,
+			},
+			Ins: []*OperableInput{
+				&OperableInput{
+					UTXOID: utxo.UTXOID,
+					In: &nftfx.TransferInput{
+						Input: secp256k1fx.Input{
+							SigIndices: sigs,
+						},
+					},
+				},
+			},
+			Outs: []*OperableOutput{
+				&OperableOutput{
+					&nftfx.TransferOutput{
+						GroupID: out.GroupID,
+						Payload: out.Payload,
+						OutputOwners: secp256k1fx.OutputOwners{
+							Threshold: 1,
+							Addrs:     []ids.ShortID{to},
+						},
+					},
+				},
+			},
+		}, signers)
+		if err != nil {
+			return err
+		}
+
+		reply.TxID = txID
+		return nil
+	}
+
+	return errNoUniqueOutput
+}
+
+// CreateManagedAssetArgs are arguments for passing into CreateManagedAsset
+// requests
+type CreateManagedAssetArgs struct {
+	Username       string    `json:"username"`
+	Password       string    `json:"password"`
+	Name           string    `json:"name"`
+	Symbol         string    `json:"symbol"`
+	Denomination   byte      `json:"denomination"`
+	Managers       Owners    `json:"managers"`
+	InitialHolders []*Holder `json:"initialHolders"`
+}
+
+// CreateManagedAssetReply defines the CreateManagedAsset replies returned from
+// the API
+type CreateManagedAssetReply struct {
+	AssetID ids.ID `json:"assetID"`
+}
+
+// CreateManagedAsset creates a managed asset. The addresses of [args.Managers]
+// can mint the asset, freeze and unfreeze the outputs of its holders, claw
+// them back, and hand the role to new managers.
+func (service *Service) CreateManagedAsset(r *http.Request, args *CreateManagedAssetArgs, reply *CreateManagedAssetReply) error {
+	service.vm.ctx.Log.Verbo("CreateManagedAsset called with name: %s symbol: %s number of holders: %d in request %s",
+		args.Name,
+		args.Symbol,
+		len(args.InitialHolders),
+		api.RequestID(r),
+	)
+
+	if len(args.Managers.Minters) == 0 {
+		return errNoManagers
+	}
+
+	fxIndex, err := service.vm.managedFxIndex()
+	if err != nil {
+		return err
+	}
+
+	managers, err := service.parseOwners(args.Managers)
+	if err != nil {
+		return err
+	}
+
+	initialState := &InitialState{
+		FxID: fxIndex,
+		Outs: []verify.Verifiable{
+			&managedfx.ManagerOutput{OutputOwners: managers},
+		},
+	}
+	for _, holder := range args.InitialHolders {
+		addr, err := service.parseAddress(holder.Address)
+		if err != nil {
+			return err
+		}
+		initialState.Outs = append(initialState.Outs, managedOutput(uint64(holder.Amount), addr))
+	}
+	initialState.Sort(service.vm.codec)
+
+	utx := &CreateAssetTx{
+		BaseTx: BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+		},
+		Name:         args.Name,
+		Symbol:       args.Symbol,
+		Denomination: args.Denomination,
+		States: []*InitialState{
+			initialState,
+		},
+	}
+
+	assetID, err := service.issueWithFee(func(fee uint64) (*Tx, error) {
+		keys, err := service.payFee(args.Username, args.Password, &utx.BaseTx, fee)
+		if err != nil {
+			return nil, err
+		}
+		return service.sign(utx, keys)
+	})
+	if err != nil {
+		return err
+	}
+
+	reply.AssetID = assetID
+	return nil
+}
+
+// ManageAssetReply defines the replies to the operations on managed assets
+// returned from the API
+type ManageAssetReply struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// MintManagedAssetArgs are arguments for passing into MintManagedAsset
+// requests
+type MintManagedAssetArgs struct {
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	AssetID  string      `json:"assetID"`
+	Amount   json.Uint64 `json:"amount"`
+	To       string      `json:"to"`
+}
+
+// MintManagedAsset mints [args.Amount] of the managed asset [args.AssetID] to
+// [args.To]. The user must hold the keys of the asset's managers.
+func (service *Service) MintManagedAsset(r *http.Request, args *MintManagedAssetArgs, reply *ManageAssetReply) error {
+	service.vm.ctx.Log.Verbo("MintManagedAsset called with username: %s in request %s", args.Username, api.RequestID(r))
+
+	if args.Amount == 0 {
+		return errInvalidMintAmount
+	}
+
+	assetID, err := service.lookupAssetID(args.AssetID)
+	if err != nil {
+		return err
+	}
+	to, err := service.lookupAddress(args.Username, args.Password, args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+
+	txID, err := service.manageAsset(args.Username, args.Password, assetID, nil, nil, []*managedfx.TransferOutput{
+		managedOutput(uint64(args.Amount), to),
+	})
+	if err != nil {
+		return err
+	}
+
+	reply.TxID = txID
+	return nil
+}
+
+// FreezeAddressArgs are arguments for passing into FreezeAddress and
+// UnfreezeAddress requests
+type FreezeAddressArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	AssetID  string `json:"assetID"`
+	Address  string `json:"address"`
+}
+
+// FreezeAddress freezes [args.Address] in the managed state of the managed
+// asset [args.AssetID], so that the outputs of the asset it holds, now or
+// later, can't be spent until it's unfrozen. The user must hold the keys of the
+// asset's managers.
+func (service *Service) FreezeAddress(r *http.Request, args *FreezeAddressArgs, reply *ManageAssetReply) error {
+	service.vm.ctx.Log.Verbo("FreezeAddress called with address: %s in request %s", args.Address, api.RequestID(r))
+
+	txID, err := service.setFrozen(args, true)
+	if err != nil {
+		return err
+	}
+
+	reply.TxID = txID
+	return nil
+}
+
+// UnfreezeAddress unfreezes [args.Address] in the managed state of the
+// managed asset [args.AssetID]. The user must hold the keys of the asset's
+// managers.
+func (service *Service) UnfreezeAddress(r *http.Request, args *FreezeAddressArgs, reply *ManageAssetReply) error {
+	service.vm.ctx.Log.Verbo("UnfreezeAddress called with address: %s in request %s", args.Address, api.RequestID(r))
+
+	txID, err := service.setFrozen(args, false)
+	if err != nil {
+		return err
+	}
+
+	reply.TxID = txID
+	return nil
+}
+
+// setFrozen issues a manager operation that freezes the address of [args] in
+// the asset's managed state, or unfreezes it if [frozen] is false
+func (service *Service) setFrozen(args *FreezeAddressArgs, frozen bool) (ids.ID, error) {
+	assetID, err := service.lookupAssetID(args.AssetID)
+	if err != nil {
+		return ids.ID{}, err
+	}
+	addr, err := service.parseAddress(args.Address)
+	if err != nil {
+		return ids.ID{}, err
+	}
+
+	return service.manageAsset(args.Username, args.Password, assetID, nil, func(manager *managedfx.ManagerOutput) error {
+		if manager.IsFrozen(addr) == frozen {
+			if frozen {
+				return errAddressFrozen
+			}
+			return errAddressNotFrozen
+		}
+
+		frozenAddrs := ids.ShortSet{}
+		frozenAddrs.Add(manager.Frozen...)
+		if frozen {
+			frozenAddrs.Add(addr)
+		} else {
+			frozenAddrs.Remove(addr)
+		}
+		manager.Frozen = frozenAddrs.List()
+		ids.SortShortIDs(manager.Frozen)
+		return nil
+	}, nil)
+}
+
+// ClawbackArgs are arguments for passing into Clawback requests
+type ClawbackArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	AssetID  string `json:"assetID"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// Clawback moves all the outputs of the managed asset [args.AssetID] that
+// [args.From] holds, frozen or not, into one output held by [args.To]. The
+// user must hold the keys of the asset's managers.
+func (service *Service) Clawback(r *http.Request, args *ClawbackArgs, reply *ManageAssetReply) error {
+	service.vm.ctx.Log.Verbo("Clawback called with from: %s to: %s in request %s", args.From, args.To, api.RequestID(r))
+
+	assetID, err := service.lookupAssetID(args.AssetID)
+	if err != nil {
+		return err
+	}
+	from, err := service.parseAddress(args.From)
+	if err != nil {
+		return fmt.Errorf("problem parsing from address: %w", err)
+	}
+	to, err := service.lookupAddress(args.Username, args.Password, args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+
+	utxos, err := service.managedUTXOs(assetID, from)
+	if err != nil {
+		return err
+	}
+	if len(utxos) == 0 {
+		return errNoManagedOutputs
+	}
+
+	amount := uint64(0)
+	for _, utxo := range utxos {
+		amount, err = math.Add64(amount, utxo.Out.(*managedfx.TransferOutput).Amt)
+		if err != nil {
+			return err
+		}
+	}
+
+	txID, err := service.manageAsset(args.Username, args.Password, assetID, utxos, nil, []*managedfx.TransferOutput{
+		managedOutput(amount, to),
+	})
+	if err != nil {
+		return err
+	}
+
+	reply.TxID = txID
+	return nil
 }
 
-// CreateMintTxReply defines the CreateMintTx replies returned from the API
-type CreateMintTxReply struct {
-	Tx formatting.CB58 `json:"tx"`
+// ChangeManagersArgs are arguments for passing into ChangeManagers requests
+type ChangeManagersArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	AssetID  string `json:"assetID"`
+	Managers Owners `json:"managers"`
 }
 
-// CreateMintTx returns the newly created unsigned transaction
-func (service *Service) CreateMintTx(r *http.Request, args *CreateMintTxArgs, reply *CreateMintTxReply) error {
-	service.vm.ctx.Log.Verbo("CreateMintTx called")
+// ChangeManagers hands the role of managing the managed asset [args.AssetID]
+// to [args.Managers]. The user must hold the keys of the asset's current
+// managers.
+func (service *Service) ChangeManagers(r *http.Request, args *ChangeManagersArgs, reply *ManageAssetReply) error {
+	service.vm.ctx.Log.Verbo("ChangeManagers called with username: %s in request %s", args.Username, api.RequestID(r))
 
-	if args.Amount == 0 {
-		return errInvalidMintAmount
+	if len(args.Managers.Minters) == 0 {
+		return errNoManagers
 	}
 
-	assetID, err := service.vm.Lookup(args.AssetID)
+	assetID, err := service.lookupAssetID(args.AssetID)
 	if err != nil {
-		assetID, err = ids.FromString(args.AssetID)
-		if err != nil {
-			return fmt.Errorf("asset '%s' not found", args.AssetID)
-		}
+		return err
 	}
-
-	toBytes, err := service.vm.Parse(args.To)
+	managers, err := service.parseOwners(args.Managers)
 	if err != nil {
-		return fmt.Errorf("problem parsing to address '%s': %w", args.To, err)
+		return err
 	}
-	to, err := ids.ToShortID(toBytes)
+
+	txID, err := service.manageAsset(args.Username, args.Password, assetID, nil, func(manager *managedfx.ManagerOutput) error {
+		manager.OutputOwners = managers
+		return nil
+	}, nil)
 	if err != nil {
-		return fmt.Errorf("problem parsing to address '%s': %w", args.To, err)
+		return err
 	}
 
-	addrs := ids.Set{}
-	minters := ids.ShortSet{}
-	for _, minter := range args.Minters {
-		addrBytes, err := service.vm.Parse(minter)
-		if err != nil {
-			return fmt.Errorf("problem parsing minter address '%s': %w", minter, err)
-		}
-		addr, err := ids.ToShortID(addrBytes)
-		if err != nil {
-			return fmt.Errorf("problem parsing minter address '%s': %w", minter, err)
-		}
-		addrs.Add(ids.NewID(hashing.ComputeHash256Array(addrBytes)))
-		minters.Add(addr)
-	}
+	reply.TxID = txID
+	return nil
+}
 
-	utxos, err := service.vm.GetUTXOs(addrs)
+// manageAsset issues a manager operation on the managed asset [assetID]. The
+// operation consumes the asset's manager output along with [utxos], and
+// produces a copy of the manager output, changed by [update] if it isn't nil,
+// followed by [outs]. The user must hold the keys of the asset's current
+// managers.
+func (service *Service) manageAsset(username, password string, assetID ids.ID, utxos []*UTXO, update func(*managedfx.ManagerOutput) error, outs []*managedfx.TransferOutput) (ids.ID, error) {
+	userUTXOs, kc, err := service.userUTXOs(username, password)
 	if err != nil {
-		return fmt.Errorf("problem getting user's UTXOs: %w", err)
+		return ids.ID{}, err
 	}
 
-	for _, utxo := range utxos {
-		switch out := utxo.Out.(type) {
-		case *secp256k1fx.MintOutput:
-			if !utxo.AssetID().Equals(assetID) {
-				continue
-			}
-			sigs := []uint32{}
-			for i := uint32(0); i < uint32(len(out.Addrs)) && uint32(len(sigs)) < out.Threshold; i++ {
-				if minters.Contains(out.Addrs[i]) {
-					sigs = append(sigs, i)
-				}
-			}
-
-			if uint32(len(sigs)) != out.Threshold {
-				continue
+	for _, utxo := range userUTXOs {
+		out, ok := utxo.Out.(*managedfx.ManagerOutput)
+		if !ok || !utxo.AssetID().Equals(assetID) {
+			continue
+		}
+		sigs, signers, ok := kc.Match(&out.OutputOwners)
+		if !ok {
+			continue
+		}
+		manager := &managedfx.ManagerOutput{
+			OutputOwners: out.OutputOwners,
+			Frozen:       out.Frozen,
+		}
+		if update != nil {
+			if err := update(manager); err != nil {
+				return ids.ID{}, err
 			}
+		}
 
-			tx := Tx{
-				UnsignedTx: &OperationTx{
-					BaseTx: BaseTx{
-						NetID: service.vm.ctx.NetworkID,
-						BCID:  service.vm.ctx.ChainID,
-					},
-					Ops: []*Operation{
-						&Operation{
-							Asset: Asset{
-								ID: assetID,
-							},
-							Ins: []*OperableInput{
-								&OperableInput{
-									UTXOID: utxo.UTXOID,
-									In: &secp256k1fx.MintInput{
-										Input: secp256k1fx.Input{
-											SigIndices: sigs,
-										},
-									},
-								},
-							},
-							Outs: []*OperableOutput{
-								&OperableOutput{
-									&secp256k1fx.MintOutput{
-										OutputOwners: out.OutputOwners,
-									},
-								},
-								&OperableOutput{
-									&secp256k1fx.TransferOutput{
-										Amt: uint64(args.Amount),
-										OutputOwners: secp256k1fx.OutputOwners{
-											Threshold: 1,
-											Addrs:     []ids.ShortID{to},
-										},
-									},
-								},
-							},
+		op := &Operation{
+			Asset: Asset{
+				ID: assetID,
+			},
+			Ins: []*OperableInput{
+				&OperableInput{
+					UTXOID: utxo.UTXOID,
+					In: &managedfx.ManagerInput{
+						Input: secp256k1fx.Input{
+							SigIndices: sigs,
 						},
 					},
 				},
-			}
-
-			txBytes, err := service.vm.codec.Marshal(&tx)
-			if err != nil {
-				return fmt.Errorf("problem creating transaction: %w", err)
-			}
-			reply.Tx.Bytes = txBytes
-			return nil
+			},
+			Outs: []*OperableOutput{
+				&OperableOutput{
+					manager,
+				},
+			},
 		}
-	}
-
-	return errAddressesCantMintAsset
-}
+		for _, held := range utxos {
+			op.Ins = append(op.Ins, &OperableInput{
+				UTXOID: held.UTXOID,
+				In: &managedfx.ManagedInput{
+					Amt: held.Out.(*managedfx.TransferOutput).Amt,
+				},
+			})
+		}
+		for _, out := range outs {
+			op.Outs = append(op.Outs, &OperableOutput{out})
+		}
+		sortOperableInputs(op.Ins)
+		sortOperableOutputs(op.Outs, service.vm.codec)
 
-// SignMintTxArgs are arguments for passing into SignMintTx requests
-type SignMintTxArgs struct {
-	Username string          `json:"username"`
-	Password string          `json:"password"`
-	Minter   string          `json:"minter"`
-	Tx       formatting.CB58 `json:"tx"`
-}
+		return service.issueOperation(username, password, op, signers)
+	}
 
-// SignMintTxReply defines the SignMintTx replies returned from the API
-type SignMintTxReply struct {
-	Tx formatting.CB58 `json:"tx"`
+	return ids.ID{}, errAddressesCantManageAsset
 }
 
-// SignMintTx returns the newly signed transaction
-func (service *Service) SignMintTx(r *http.Request, args *SignMintTxArgs, reply *SignMintTxReply) error {
-	service.vm.ctx.Log.Verbo("SignMintTx called")
-
-	minter, err := service.vm.Parse(args.Minter)
+// managedUTXOs returns the UTXOs of the managed asset [assetID] that [addr]
+// holds
+func (service *Service) managedUTXOs(assetID ids.ID, addr ids.ShortID) ([]*UTXO, error) {
+	addrs := ids.Set{}
+	addrs.Add(ids.NewID(hashing.ComputeHash256Array(addr.Bytes())))
+	utxos, err := service.vm.GetUTXOs(addrs)
 	if err != nil {
-		return fmt.Errorf("problem parsing address '%s': %w", args.Minter, err)
+		return nil, fmt.Errorf("problem retrieving UTXOs: %w", err)
 	}
 
-	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
-	if err != nil {
-		return fmt.Errorf("problem retrieving user: %w", err)
+	held := []*UTXO(nil)
+	for _, utxo := range utxos {
+		if _, ok := utxo.Out.(*managedfx.TransferOutput); ok && utxo.AssetID().Equals(assetID) {
+			held = append(held, utxo)
+		}
 	}
+	return held, nil
+}
 
-	user := userState{vm: service.vm}
+// managedOutput returns an output of [amount] of a managed asset held by
+// [addr]
+func managedOutput(amount uint64, addr ids.ShortID) *managedfx.TransferOutput {
+	return &managedfx.TransferOutput{
+		TransferOutput: secp256k1fx.TransferOutput{
+			Amt: amount,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	}
+}
 
-	addr := ids.NewID(hashing.ComputeHash256Array(minter))
-	sk, err := user.Key(db, addr)
+// parseAddress returns the address that [addrStr] formats
+func (service *Service) parseAddress(addrStr string) (ids.ShortID, error) {
+	addrBytes, err := service.vm.Parse(addrStr)
 	if err != nil {
-		return fmt.Errorf("problem retriving private key: %w", err)
+		return ids.ShortID{}, err
 	}
+	return ids.ToShortID(addrBytes)
+}
 
-	tx := Tx{}
-	if err := service.vm.codec.Unmarshal(args.Tx.Bytes, &tx); err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
+// parseOwners returns the output owners that [owners] describes
+func (service *Service) parseOwners(owners Owners) (secp256k1fx.OutputOwners, error) {
+	outputOwners := secp256k1fx.OutputOwners{
+		Threshold: uint32(owners.Threshold),
 	}
-
-	inputUTXOs := tx.InputUTXOs()
-	if len(inputUTXOs) != 1 {
-		return errCanOnlySignSingleInputTxs
+	for _, address := range owners.Minters {
+		addr, err := service.parseAddress(address)
+		if err != nil {
+			return secp256k1fx.OutputOwners{}, err
+		}
+		outputOwners.Addrs = append(outputOwners.Addrs, addr)
 	}
-	inputUTXO := inputUTXOs[0]
+	ids.SortShortIDs(outputOwners.Addrs)
+	return outputOwners, outputOwners.Verify()
+}
 
-	inputTxID, utxoIndex := inputUTXO.InputSource()
-	utx := UniqueTx{
-		vm:   service.vm,
-		txID: inputTxID,
-	}
-	if !utx.Status().Fetched() {
-		return errUnknownUTXO
-	}
-	utxos := utx.UTXOs()
-	if uint32(len(utxos)) <= utxoIndex {
-		return errInvalidUTXO
+// userUTXOs returns the UTXOs of the addresses held by the user, along with a
+// keychain of their keys
+func (service *Service) userUTXOs(username, password string) ([]*UTXO, *secp256k1fx.Keychain, error) {
+	db, err := service.vm.ctx.Keystore.GetReadOnlyDatabase(username, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem retrieving user: %w", err)
 	}
 
-	utxo := utxos[int(utxoIndex)]
+	user := userState{vm: service.vm}
 
-	i := -1
-	size := 0
-	switch out := utxo.Out.(type) {
-	case *secp256k1fx.MintOutput:
-		size = int(out.Threshold)
-		for j, addr := range out.Addrs {
-			if bytes.Equal(addr.Bytes(), minter) {
-				i = j
-				break
-			}
-		}
-	default:
-		return errUnknownOutputType
-	}
-	if i == -1 {
-		return errUnneededAddress
+	addresses, _ := user.Addresses(db)
 
+	addrs := ids.Set{}
+	addrs.Add(addresses...)
+	utxos, err := service.vm.GetUTXOs(addrs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem retrieving user's UTXOs: %w", err)
 	}
 
-	if len(tx.Creds) == 0 {
-		tx.Creds = append(tx.Creds, &Credential{Cred: &secp256k1fx.Credential{}})
+	kc := secp256k1fx.NewKeychain()
+	for _, addr := range addresses {
+		sk, err := user.Key(db, addr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("problem retrieving private key: %w", err)
+		}
+		kc.Add(sk)
 	}
+	return utxos, kc, nil
+}
 
-	cred := tx.Creds[0]
-	switch cred := cred.Cred.(type) {
-	case *secp256k1fx.Credential:
-		if len(cred.Sigs) != size {
-			cred.Sigs = make([][crypto.SECP256K1RSigLen]byte, size)
+// issueOperation signs an OperationTx performing [op] with [signers] and
+// issues it. Only the inputs of [op] that name signers are signed. The transaction's fee is paid out of the user's funds.
+func (service *Service) issueOperation(username, password string, op *Operation, signers []*crypto.PrivateKeySECP256K1R) (ids.ID, error) {
+	return service.issueWithFee(func(fee uint64) (*Tx, error) {
+		utx := &OperationTx{
+			BaseTx: BaseTx{
+				NetID: service.vm.ctx.NetworkID,
+				BCID:  service.vm.ctx.ChainID,
+			},
+			Ops: []*Operation{op},
 		}
-
-		unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
+		keys, err := service.payFee(username, password, &utx.BaseTx, fee)
 		if err != nil {
-			return fmt.Errorf("problem creating transaction: %w", err)
+			return nil, err
+		}
+		tx, err := service.sign(utx, keys)
+		if err != nil {
+			return nil, err
 		}
 
-		sig, err := sk.Sign(unsignedBytes)
+		unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
 		if err != nil {
-			return fmt.Errorf("problem signing transaction: %w", err)
+			return nil, fmt.Errorf("problem creating transaction: %w", err)
 		}
-		copy(cred.Sigs[i][:], sig)
-	default:
-		return errUnknownCredentialType
-	}
+		hash := hashing.ComputeHash256(unsignedBytes)
 
-	txBytes, err := service.vm.codec.Marshal(&tx)
-	if err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
-	}
-	reply.Tx.Bytes = txBytes
-	return nil
+		// The credentials of the operation's inputs follow the credentials of
+		// the inputs. The operation's inputs that need signatures are signed by
+		// [signers].
+		for _, in := range op.Ins {
+			sigIndices, cred, ok := inputSigIndices(in.In)
+			if !ok {
+				return nil, errUnknownInputType
+			}
+			sigs, ok := credentialSigs(cred)
+			if !ok {
+				return nil, errUnknownCredentialType
+			}
+			*sigs = [][crypto.SECP256K1RSigLen]byte{}
+			if len(sigIndices) > 0 {
+				for _, key := range signers {
+					sig, err := key.SignHash(hash)
+					if err != nil {
+						return nil, fmt.Errorf("problem creating transaction: %w", err)
+					}
+					fixedSig := [crypto.SECP256K1RSigLen]byte{}
+					copy(fixedSig[:], sig)
+
+					*sigs = append(*sigs, fixedSig)
+				}
+			}
+			tx.Creds = append(tx.Creds, &Credential{Cred: cred})
+		}
+		return tx, nil
+	})
 }
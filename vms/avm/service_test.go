@@ -4,11 +4,27 @@
 package avm
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/readonlydb"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/json"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms/components/shared"
+	"github.com/ava-labs/gecko/vms/managedfx"
+	"github.com/ava-labs/gecko/vms/schnorrfx"
 	"github.com/ava-labs/gecko/vms/secp256k1fx"
 )
 
@@ -186,3 +202,1305 @@ func TestCreateVariableCapAsset(t *testing.T) {
 		t.Fatalf("Wrong assetID returned from CreateFixedCapAsset %s", reply.AssetID)
 	}
 }
+
+type testKeystore map[string]database.Database
+
+func (ks testKeystore) GetDatabase(username, _ string) (database.Database, error) {
+	db, exists := ks[username]
+	if !exists {
+		return nil, errors.New("unknown user")
+	}
+	return db, nil
+}
+
+func (ks testKeystore) GetReadOnlyDatabase(username, password string) (database.Database, error) {
+	db, err := ks.GetDatabase(username, password)
+	if err != nil {
+		return nil, err
+	}
+	return readonlydb.New(db), nil
+}
+
+func TestMultisigSpend(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	keystore := testKeystore{}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	for i, username := range []string{"alice", "bob", "carol"} {
+		db := memdb.New()
+		user := userState{vm: vm}
+		if err := user.SetKey(db, keys[i]); err != nil {
+			t.Fatal(err)
+		}
+		addr := ids.NewID(hashing.ComputeHash256Array(keys[i].PublicKey().Address().Bytes()))
+		if err := user.SetAddresses(db, []ids.ID{addr}); err != nil {
+			t.Fatal(err)
+		}
+		keystore[username] = db
+	}
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	assetID := genesisTx.ID().String()
+
+	addr0 := vm.Format(keys[0].PublicKey().Address().Bytes())
+	addr1 := vm.Format(keys[1].PublicKey().Address().Bytes())
+	addr2 := vm.Format(keys[2].PublicKey().Address().Bytes())
+
+	s := Service{vm: vm}
+
+	sendReply := SendReply{}
+	if err := s.SendMultisig(nil, &SendMultisigArgs{
+		Username:  "alice",
+		Amount:    1000,
+		AssetID:   assetID,
+		Threshold: 2,
+		To:        []string{addr1, addr2},
+	}, &sendReply); err != nil {
+		t.Fatal(err)
+	}
+	sendTx := UniqueTx{vm: vm, txID: sendReply.TxID}
+	sendTx.Accept()
+
+	createReply := CreateSpendTxReply{}
+	if err := s.CreateSpendTx(nil, &CreateSpendTxArgs{
+		Amount:  600,
+		AssetID: assetID,
+		To:      addr0,
+		Signers: []string{addr1, addr2},
+	}, &createReply); err != nil {
+		t.Fatal(err)
+	}
+
+	bobReply := SignTxReply{}
+	if err := s.SignTx(nil, &SignTxArgs{
+		Username: "bob",
+		Signer:   addr1,
+		Tx:       createReply.Tx,
+	}, &bobReply); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.IssueTx(bobReply.Tx.Bytes); err == nil {
+		t.Fatalf("Should have failed to issue a transaction missing a signature")
+	}
+
+	if err := s.SignTx(nil, &SignTxArgs{
+		Username: "alice",
+		Signer:   addr0,
+		Tx:       bobReply.Tx,
+	}, &SignTxReply{}); err == nil {
+		t.Fatalf("Should have failed to sign with an address that isn't needed")
+	}
+
+	carolReply := SignTxReply{}
+	if err := s.SignTx(nil, &SignTxArgs{
+		Username: "carol",
+		Signer:   addr2,
+		Tx:       bobReply.Tx,
+	}, &carolReply); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.IssueTx(carolReply.Tx.Bytes); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	peerChainID := ids.Empty.Prefix(1)
+	memory := atomic.Memory{}
+	memory.Initialize(logging.NoLog{}, memdb.New())
+	ctx.SharedMemory = memory.NewBlockchainMemory(chainID)
+	defer func() { ctx.SharedMemory = nil }()
+
+	keystore := testKeystore{}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	for i, username := range []string{"alice", "bob"} {
+		db := memdb.New()
+		user := userState{vm: vm}
+		if err := user.SetKey(db, keys[i]); err != nil {
+			t.Fatal(err)
+		}
+		addr := ids.NewID(hashing.ComputeHash256Array(keys[i].PublicKey().Address().Bytes()))
+		if err := user.SetAddresses(db, []ids.ID{addr}); err != nil {
+			t.Fatal(err)
+		}
+		keystore[username] = db
+	}
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	assetID := genesisTx.ID()
+	addr1 := keys[1].PublicKey().Address()
+
+	s := Service{vm: vm}
+
+	exportReply := SendReply{}
+	if err := s.Export(nil, &ExportArgs{
+		Username:         "alice",
+		Amount:           1000,
+		AssetID:          assetID.String(),
+		DestinationChain: peerChainID.String(),
+		To:               addr1,
+	}, &exportReply); err != nil {
+		t.Fatal(err)
+	}
+	exportTx := UniqueTx{vm: vm, txID: exportReply.TxID}
+	exportTx.Accept()
+
+	peerMemory := memory.NewBlockchainMemory(peerChainID)
+	sharedDB := peerMemory.GetDatabase(chainID)
+	peerState := shared.NewState(sharedDB, peerChainID)
+	exportedIDs, err := peerState.Funds(addr1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exportedIDs) != 1 {
+		t.Fatalf("Expected one exported utxo but found %d", len(exportedIDs))
+	}
+	if utxo, err := peerState.UTXO(exportedIDs[0]); err != nil {
+		t.Fatal(err)
+	} else if utxo.Out.Amt != 1000 || !utxo.AssetID.Equals(assetID) {
+		t.Fatalf("Exported the wrong utxo")
+	}
+
+	// The peer chain exports funds back to this chain
+	state := shared.NewState(sharedDB, chainID)
+	if err := state.FundUTXO(&shared.UTXO{
+		TxID:    ids.Empty.Prefix(2),
+		AssetID: assetID,
+		Out: secp256k1fx.TransferOutput{
+			Amt: 400,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr1},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	peerMemory.ReleaseDatabase(chainID)
+
+	importReply := ImportReply{}
+	if err := s.Import(nil, &ImportArgs{
+		Username:    "bob",
+		SourceChain: peerChainID.String(),
+		To:          vm.Format(addr1.Bytes()),
+	}, &importReply); err != nil {
+		t.Fatal(err)
+	}
+	importTx := UniqueTx{vm: vm, txID: importReply.TxID}
+	importTx.Accept()
+
+	balanceReply := GetBalanceReply{}
+	if err := s.GetBalance(nil, &GetBalanceArgs{
+		Address: vm.Format(addr1.Bytes()),
+		AssetID: assetID.String(),
+	}, &balanceReply); err != nil {
+		t.Fatal(err)
+	}
+	if balanceReply.Balance != 400 {
+		t.Fatalf("Expected the imported balance to be 400 but was %d", balanceReply.Balance)
+	}
+
+	if err := s.Import(nil, &ImportArgs{
+		Username:    "bob",
+		SourceChain: peerChainID.String(),
+		To:          vm.Format(addr1.Bytes()),
+	}, &ImportReply{}); err == nil {
+		t.Fatalf("Should have failed to import funds that were already imported")
+	}
+}
+
+func TestGetUTXOsPagination(t *testing.T) {
+	vm := GenesisVM(t)
+	defer func() {
+		ctx.Lock.Lock()
+		vm.Shutdown()
+		ctx.Lock.Unlock()
+	}()
+
+	// Addresses that the genesis doesn't fund, each owning 3 UTXOs
+	addrs := []string(nil)
+	for i := uint32(0); i < 2; i++ {
+		addr := ids.NewShortID([20]byte{byte(i + 1)})
+		addrs = append(addrs, vm.Format(addr.Bytes()))
+		for j := uint32(0); j < 3; j++ {
+			utxo := &UTXO{
+				UTXOID: UTXOID{
+					TxID:        ids.Empty.Prefix(uint64(i)),
+					OutputIndex: j,
+				},
+				Asset: Asset{ID: ids.Empty.Prefix(2)},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: 1,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{addr},
+					},
+				},
+			}
+			if err := vm.state.FundUTXO(utxo); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	s := Service{vm: vm}
+
+	all := GetUTXOsReply{}
+	if err := s.GetUTXOs(nil, &GetUTXOsArgs{Addresses: addrs}, &all); err != nil {
+		t.Fatal(err)
+	}
+	if len(all.UTXOs) != 6 {
+		t.Fatalf("Should have returned 6 UTXOs but returned %d", len(all.UTXOs))
+	}
+
+	paged := []string(nil)
+	startIndex := Index{}
+	for {
+		reply := GetUTXOsReply{}
+		if err := s.GetUTXOs(nil, &GetUTXOsArgs{
+			Addresses:  addrs,
+			Limit:      2,
+			StartIndex: startIndex,
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if int(reply.NumFetched) != len(reply.UTXOs) {
+			t.Fatalf("NumFetched is %d but %d UTXOs were returned", reply.NumFetched, len(reply.UTXOs))
+		}
+		paged = append(paged, reply.UTXOs...)
+		if len(reply.UTXOs) < 2 {
+			break
+		}
+		startIndex = reply.EndIndex
+	}
+	if len(paged) != len(all.UTXOs) {
+		t.Fatalf("Pages should hold %d UTXOs but hold %d", len(all.UTXOs), len(paged))
+	}
+	for i, utxo := range paged {
+		if utxo != all.UTXOs[i] {
+			t.Fatalf("UTXO %d of the pages is wrong", i)
+		}
+	}
+
+	hexReply := GetUTXOsReply{}
+	if err := s.GetUTXOs(nil, &GetUTXOsArgs{Addresses: addrs, Encoding: formatting.HexEncoding}, &hexReply); err != nil {
+		t.Fatal(err)
+	}
+	if hexReply.Encoding != formatting.HexEncoding || len(hexReply.UTXOs) != len(all.UTXOs) {
+		t.Fatalf("Should have returned %d UTXOs in hex", len(all.UTXOs))
+	}
+	for i, utxo := range hexReply.UTXOs {
+		hexBytes, err := formatting.HexEncoding.Decode(utxo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cb58Bytes, err := formatting.CB58Encoding.Decode(all.UTXOs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(hexBytes, cb58Bytes) {
+			t.Fatalf("UTXO %d in hex is wrong", i)
+		}
+	}
+
+	if err := s.GetUTXOs(nil, &GetUTXOsArgs{
+		Addresses:  addrs[:1],
+		StartIndex: Index{Address: addrs[1]},
+	}, &GetUTXOsReply{}); err == nil {
+		t.Fatalf("Should have failed to start from an address that wasn't provided")
+	}
+}
+
+func TestGetAddressTxs(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	keystore := testKeystore{}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	db := memdb.New()
+	user := userState{vm: vm}
+	if err := user.SetKey(db, keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	addr0 := ids.NewID(hashing.ComputeHash256Array(keys[0].PublicKey().Address().Bytes()))
+	if err := user.SetAddresses(db, []ids.ID{addr0}); err != nil {
+		t.Fatal(err)
+	}
+	keystore["alice"] = db
+
+	s := Service{vm: vm}
+	from := vm.Format(keys[0].PublicKey().Address().Bytes())
+	to := vm.Format(keys[1].PublicKey().Address().Bytes())
+
+	// The genesis funds the address
+	genesisReply := GetAddressTxsReply{}
+	if err := s.GetAddressTxs(nil, &GetAddressTxsArgs{Address: from}, &genesisReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(genesisReply.Txs) == 0 {
+		t.Fatalf("The genesis should be in the history of the address")
+	}
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	sendReply := SendReply{}
+	if err := s.Send(nil, &SendArgs{
+		Username: "alice",
+		Amount:   1000,
+		AssetID:  genesisTx.ID().String(),
+		To:       to,
+	}, &sendReply); err != nil {
+		t.Fatal(err)
+	}
+
+	vm.clock.Set(time.Unix(1000, 0))
+	vm.state.UniqueTx(&UniqueTx{vm: vm, txID: sendReply.TxID}).Accept()
+
+	for _, addr := range []string{from, to} {
+		reply := GetAddressTxsReply{}
+		if err := s.GetAddressTxs(nil, &GetAddressTxsArgs{
+			Address:   addr,
+			StartTime: 1000,
+			EndTime:   1000,
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if len(reply.Txs) != 1 || !reply.Txs[0].TxID.Equals(sendReply.TxID) {
+			t.Fatalf("The history of %s should only have the send in it at time 1000", addr)
+		}
+		if reply.Txs[0].Timestamp != 1000 {
+			t.Fatalf("The send should have been accepted at time 1000 but was at %d", reply.Txs[0].Timestamp)
+		}
+	}
+
+	// Pages continue from the height after the last tx of the previous page
+	paged := []AddressTx(nil)
+	startHeight := json.Uint64(0)
+	for {
+		reply := GetAddressTxsReply{}
+		if err := s.GetAddressTxs(nil, &GetAddressTxsArgs{
+			Address:     from,
+			StartHeight: startHeight,
+			Limit:       1,
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		paged = append(paged, reply.Txs...)
+		if len(reply.Txs) < 1 {
+			break
+		}
+		startHeight = reply.Txs[0].Height + 1
+	}
+	if len(paged) != len(genesisReply.Txs)+1 {
+		t.Fatalf("The history should have %d txs but has %d", len(genesisReply.Txs)+1, len(paged))
+	}
+	if last := paged[len(paged)-1]; !last.TxID.Equals(sendReply.TxID) {
+		t.Fatalf("The send should be the last tx in the history")
+	}
+
+	// The addresses of the send are reported to indexers outside of the VM
+	addrs, err := vm.TxAddresses(sendReply.TxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]byte{keys[0].PublicKey().Address().Bytes(), keys[1].PublicKey().Address().Bytes()}
+	for _, addr := range expected {
+		found := false
+		for _, txAddr := range addrs {
+			found = found || bytes.Equal(addr, txAddr)
+		}
+		if !found {
+			t.Fatalf("The addresses of the send should include %s", vm.Format(addr))
+		}
+	}
+}
+
+// newSignedTestTx returns a signed transaction that spends the [inAmt] of
+// [assetID] held by keys[0] in [utxoID] and sends [outAmt] of it to keys[1]
+func newSignedTestTx(t *testing.T, vm *VM, utxoID UTXOID, assetID ids.ID, inAmt, outAmt uint64) formatting.CB58 {
+	tx := &Tx{UnsignedTx: &BaseTx{
+		NetID: networkID,
+		BCID:  chainID,
+		Outs: []*TransferableOutput{&TransferableOutput{
+			Asset: Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: outAmt,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{keys[1].PublicKey().Address()},
+				},
+			},
+		}},
+		Ins: []*TransferableInput{&TransferableInput{
+			UTXOID: utxoID,
+			Asset:  Asset{ID: assetID},
+			In: &secp256k1fx.TransferInput{
+				Amt:   inAmt,
+				Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+			},
+		}},
+	}}
+
+	unsignedBytes, err := vm.codec.Marshal(&tx.UnsignedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := keys[0].Sign(unsignedBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixedSig := [crypto.SECP256K1RSigLen]byte{}
+	copy(fixedSig[:], sig)
+	tx.Creds = append(tx.Creds, &Credential{
+		Cred: &secp256k1fx.Credential{
+			Sigs: [][crypto.SECP256K1RSigLen]byte{fixedSig},
+		},
+	})
+
+	b, err := vm.codec.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return formatting.CB58{Bytes: b}
+}
+
+func TestIssueTxs(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+	vm := GenesisVM(t)
+	defer func() {
+		ctx.Lock.Lock()
+		vm.Shutdown()
+		ctx.Lock.Unlock()
+	}()
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	assetID := genesisTx.ID()
+	genesisUTXO := UTXOID{TxID: genesisTx.ID(), OutputIndex: 1}
+
+	spend := newSignedTestTx(t, vm, genesisUTXO, assetID, 50000, 50000)
+	spendID := ids.NewID(hashing.ComputeHash256Array(spend.Bytes))
+	// keys[0] doesn't own the outputs of [spend], so this can't be verified
+	invalid := newSignedTestTx(t, vm, UTXOID{TxID: spendID}, assetID, 50000, 50000)
+	invalidID := ids.NewID(hashing.ComputeHash256Array(invalid.Bytes))
+
+	args := &IssueTxsArgs{Txs: []formatting.CB58{
+		newSignedTestTx(t, vm, UTXOID{TxID: spendID}, assetID, 50000, 40000),
+		spend,
+		spend,
+		newSignedTestTx(t, vm, genesisUTXO, assetID, 50000, 40000),
+		invalid,
+		newSignedTestTx(t, vm, UTXOID{TxID: invalidID}, assetID, 50000, 50000),
+		{Bytes: []byte{1, 2, 3}},
+	}}
+	expected := []error{
+		errBatchOrder,
+		nil,
+		errDuplicateBatchTx,
+		errBatchConflict,
+		errors.New("invalid"),
+		errBatchDependencyFailed,
+		errors.New("invalid"),
+	}
+
+	ctx.Lock.Lock()
+	s := Service{vm: vm}
+	reply := IssueTxsReply{}
+	err := s.IssueTxs(nil, args, &reply)
+	ctx.Lock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reply.Results) != len(expected) {
+		t.Fatalf("Should have returned %d results but returned %d", len(expected), len(reply.Results))
+	}
+	for i, result := range reply.Results {
+		switch {
+		case expected[i] == nil && result.Error != "":
+			t.Fatalf("Tx %d should have been issued but failed with: %s", i, result.Error)
+		case expected[i] == nil:
+		case result.Error == "":
+			t.Fatalf("Tx %d should have failed", i)
+		case expected[i].Error() != "invalid" && result.Error != expected[i].Error():
+			t.Fatalf("Tx %d should have failed with %q but failed with %q", i, expected[i], result.Error)
+		}
+	}
+	if !reply.Results[1].TxID.Equals(spendID) {
+		t.Fatalf("Wrong ID returned for the issued tx")
+	}
+	if !reply.Results[6].TxID.IsZero() {
+		t.Fatalf("An unparsable tx shouldn't have an ID")
+	}
+
+	if err := s.IssueTxs(nil, &IssueTxsArgs{}, &IssueTxsReply{}); err != errNoTxs {
+		t.Fatalf("Should have failed to issue an empty batch")
+	}
+}
+
+func TestDecodeTx(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+	vm := GenesisVM(t)
+	defer func() {
+		ctx.Lock.Lock()
+		vm.Shutdown()
+		ctx.Lock.Unlock()
+	}()
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	assetID := genesisTx.ID()
+	genesisUTXO := UTXOID{TxID: genesisTx.ID(), OutputIndex: 1}
+
+	spend := newSignedTestTx(t, vm, genesisUTXO, assetID, 50000, 40000)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	s := Service{vm: vm}
+	reply := DecodeTxReply{}
+	if err := s.DecodeTx(nil, &DecodeTxArgs{Tx: spend.String()}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if expected := ids.NewID(hashing.ComputeHash256Array(spend.Bytes)); !reply.TxID.Equals(expected) {
+		t.Fatalf("Wrong tx ID: expected %s but got %s", expected, reply.TxID)
+	}
+	if reply.Type != "BaseTx" || reply.SyntacticError != "" {
+		t.Fatalf("Wrong decoded tx: %+v", reply)
+	}
+	if len(reply.Inputs) != 1 || len(reply.Outputs) != 1 {
+		t.Fatalf("Tx should have 1 input and 1 output but has %d and %d", len(reply.Inputs), len(reply.Outputs))
+	}
+	in := reply.Inputs[0]
+	if in.Amount != 50000 || in.SignaturesError != "" || len(in.Signatures) != 1 || !in.Signatures[0].Valid {
+		t.Fatalf("Input should be validly signed: %+v", in)
+	}
+	if in.Signatures[0].Address != vm.Format(keys[0].PublicKey().Address().Bytes()) {
+		t.Fatalf("Input should require the signature of %s", keys[0].PublicKey().Address())
+	}
+	out := reply.Outputs[0]
+	if out.Amount != 40000 || out.Type != "TransferOutput" || len(out.Addresses) != 1 || out.Addresses[0] != vm.Format(keys[1].PublicKey().Address().Bytes()) {
+		t.Fatalf("Wrong decoded output: %+v", out)
+	}
+
+	// Corrupt the signature, and pass the tx as hex
+	corrupted := make([]byte, len(spend.Bytes))
+	copy(corrupted, spend.Bytes)
+	corrupted[len(corrupted)-2]++
+	reply = DecodeTxReply{}
+	if err := s.DecodeTx(nil, &DecodeTxArgs{Tx: "0x" + hex.EncodeToString(corrupted), Encoding: "hex"}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Inputs) != 1 || len(reply.Inputs[0].Signatures) != 1 || reply.Inputs[0].Signatures[0].Valid {
+		t.Fatalf("Input shouldn't be validly signed: %+v", reply.Inputs)
+	}
+
+	// The UTXO spent by this tx doesn't exist, so its signatures can't be
+	// checked
+	unknown := newSignedTestTx(t, vm, UTXOID{TxID: assetID, OutputIndex: 100}, assetID, 50000, 40000)
+	reply = DecodeTxReply{}
+	if err := s.DecodeTx(nil, &DecodeTxArgs{Tx: unknown.String()}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Inputs) != 1 || reply.Inputs[0].SignaturesError == "" {
+		t.Fatalf("Signatures of an unknown UTXO shouldn't have been checked: %+v", reply.Inputs)
+	}
+
+	if err := s.DecodeTx(nil, &DecodeTxArgs{Tx: "zz", Encoding: "hex"}, &reply); err == nil {
+		t.Fatalf("Should have failed to decode invalid hex")
+	}
+}
+
+func TestManagedAsset(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	keystore := testKeystore{}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{
+			&common.Fx{
+				ID: ids.Empty,
+				Fx: &secp256k1fx.Fx{},
+			},
+			&common.Fx{
+				ID: ids.Empty.Prefix(0),
+				Fx: &managedfx.Fx{},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	db := memdb.New()
+	user := userState{vm: vm}
+	if err := user.SetKey(db, keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	addr0 := ids.NewID(hashing.ComputeHash256Array(keys[0].PublicKey().Address().Bytes()))
+	if err := user.SetAddresses(db, []ids.ID{addr0}); err != nil {
+		t.Fatal(err)
+	}
+	keystore["alice"] = db
+
+	s := Service{vm: vm}
+	manager := vm.Format(keys[0].PublicKey().Address().Bytes())
+	holder := vm.Format(keys[1].PublicKey().Address().Bytes())
+	other := vm.Format(keys[2].PublicKey().Address().Bytes())
+	accept := func(txID ids.ID) {
+		vm.state.UniqueTx(&UniqueTx{vm: vm, txID: txID}).Accept()
+	}
+
+	createReply := CreateManagedAssetReply{}
+	if err := s.CreateManagedAsset(nil, &CreateManagedAssetArgs{
+		Username: "alice",
+		Name:     "managed asset",
+		Symbol:   "MA",
+		Managers: Owners{Threshold: 1, Minters: []string{manager}},
+		InitialHolders: []*Holder{&Holder{
+			Amount:  100,
+			Address: holder,
+		}},
+	}, &createReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(createReply.AssetID)
+	balance := func(addr string) uint64 {
+		reply := GetBalanceReply{}
+		if err := s.GetBalance(nil, &GetBalanceArgs{
+			Address: addr,
+			AssetID: createReply.AssetID.String(),
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		return uint64(reply.Balance)
+	}
+
+	mintReply := ManageAssetReply{}
+	if err := s.MintManagedAsset(nil, &MintManagedAssetArgs{
+		Username: "alice",
+		AssetID:  createReply.AssetID.String(),
+		Amount:   50,
+		To:       holder,
+	}, &mintReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(mintReply.TxID)
+	if b := balance(holder); b != 150 {
+		t.Fatalf("The holder should have 150 after the mint but has %d", b)
+	}
+
+	freezeReply := ManageAssetReply{}
+	if err := s.FreezeAddress(nil, &FreezeAddressArgs{
+		Username: "alice",
+		AssetID:  createReply.AssetID.String(),
+		Address:  holder,
+	}, &freezeReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(freezeReply.TxID)
+	utxos, err := s.managedUTXOs(createReply.AssetID, keys[1].PublicKey().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 2 {
+		t.Fatalf("The holder should have 2 outputs but has %d", len(utxos))
+	}
+	managedFx := vm.fxs[1].Fx
+	for _, utxo := range utxos {
+		if err := vm.verifyManagedTransfer(managedFx, utxo); err == nil {
+			t.Fatalf("The outputs of the holder should be frozen")
+		}
+	}
+	if err := s.FreezeAddress(nil, &FreezeAddressArgs{
+		Username: "alice",
+		AssetID:  createReply.AssetID.String(),
+		Address:  holder,
+	}, &ManageAssetReply{}); err != errAddressFrozen {
+		t.Fatalf("Should have failed to freeze an address that is already frozen")
+	}
+
+	unfreezeReply := ManageAssetReply{}
+	if err := s.UnfreezeAddress(nil, &FreezeAddressArgs{
+		Username: "alice",
+		AssetID:  createReply.AssetID.String(),
+		Address:  holder,
+	}, &unfreezeReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(unfreezeReply.TxID)
+	for _, utxo := range utxos {
+		if err := vm.verifyManagedTransfer(managedFx, utxo); err != nil {
+			t.Fatalf("The outputs of the holder should be unfrozen: %s", err)
+		}
+	}
+	if err := s.UnfreezeAddress(nil, &FreezeAddressArgs{
+		Username: "alice",
+		AssetID:  createReply.AssetID.String(),
+		Address:  holder,
+	}, &ManageAssetReply{}); err != errAddressNotFrozen {
+		t.Fatalf("Should have failed to unfreeze an address that isn't frozen")
+	}
+
+	clawbackReply := ManageAssetReply{}
+	if err := s.Clawback(nil, &ClawbackArgs{
+		Username: "alice",
+		AssetID:  createReply.AssetID.String(),
+		From:     holder,
+		To:       other,
+	}, &clawbackReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(clawbackReply.TxID)
+	if b := balance(holder); b != 0 {
+		t.Fatalf("The holder should have nothing after the clawback but has %d", b)
+	}
+	if b := balance(other); b != 150 {
+		t.Fatalf("The clawed back funds should be 150 but are %d", b)
+	}
+
+	changeReply := ManageAssetReply{}
+	if err := s.ChangeManagers(nil, &ChangeManagersArgs{
+		Username: "alice",
+		AssetID:  createReply.AssetID.String(),
+		Managers: Owners{Threshold: 1, Minters: []string{holder}},
+	}, &changeReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(changeReply.TxID)
+	if err := s.MintManagedAsset(nil, &MintManagedAssetArgs{
+		Username: "alice",
+		AssetID:  createReply.AssetID.String(),
+		Amount:   50,
+		To:       holder,
+	}, &ManageAssetReply{}); err != errAddressesCantManageAsset {
+		t.Fatalf("Should have failed to mint after handing over the role")
+	}
+}
+
+func TestSendAggregate(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	keystore := testKeystore{}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{
+			&common.Fx{
+				ID: ids.Empty,
+				Fx: &secp256k1fx.Fx{},
+			},
+			&common.Fx{
+				ID: ids.Empty.Prefix(0),
+				Fx: &schnorrfx.Fx{},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	db := memdb.New()
+	user := userState{vm: vm}
+	if err := user.SetKey(db, keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	addr0 := ids.NewID(hashing.ComputeHash256Array(keys[0].PublicKey().Address().Bytes()))
+	if err := user.SetAddresses(db, []ids.ID{addr0}); err != nil {
+		t.Fatal(err)
+	}
+	keystore["alice"] = db
+
+	s := Service{vm: vm}
+	holder := vm.Format(keys[0].PublicKey().Address().Bytes())
+	recipient := vm.Format(keys[1].PublicKey().Address().Bytes())
+	accept := func(txID ids.ID) {
+		vm.state.UniqueTx(&UniqueTx{vm: vm, txID: txID}).Accept()
+	}
+
+	createReply := CreateFixedCapAssetReply{}
+	if err := s.CreateFixedCapAsset(nil, &CreateFixedCapAssetArgs{
+		Username: "alice",
+		Name:     "aggregate asset",
+		Symbol:   "AGG",
+		InitialHolders: []*Holder{
+			&Holder{
+				Amount:  30,
+				Address: holder,
+			},
+			&Holder{
+				Amount:  40,
+				Address: holder,
+			},
+		},
+		Aggregate: true,
+	}, &createReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(createReply.AssetID)
+
+	// Spends both of the holder's outputs
+	sendReply := SendReply{}
+	if err := s.Send(nil, &SendArgs{
+		Username: "alice",
+		AssetID:  createReply.AssetID.String(),
+		Amount:   50,
+		To:       recipient,
+	}, &sendReply); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := vm.state.UniqueTx(&UniqueTx{vm: vm, txID: sendReply.TxID})
+	if status := tx.Status(); status != choices.Processing {
+		t.Fatalf("The transaction should be processing but is %s", status)
+	}
+	aggregates := 0
+	for _, credIntf := range tx.Credentials() {
+		cred, ok := credIntf.(*schnorrfx.Credential)
+		if !ok {
+			t.Fatalf("The asset should be spent with aggregated signatures")
+		}
+		aggregates += len(cred.Sigs)
+	}
+	if numCreds := len(tx.Credentials()); numCreds != 2 {
+		t.Fatalf("The transaction should have spent 2 outputs but spent %d", numCreds)
+	}
+	if aggregates != 1 {
+		t.Fatalf("The transaction should hold 1 signature but holds %d", aggregates)
+	}
+	accept(sendReply.TxID)
+
+	for addr, expected := range map[string]uint64{holder: 20, recipient: 50} {
+		reply := GetBalanceReply{}
+		if err := s.GetBalance(nil, &GetBalanceArgs{
+			Address: addr,
+			AssetID: createReply.AssetID.String(),
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if uint64(reply.Balance) != expected {
+			t.Fatalf("%s should have %d but has %d", addr, expected, reply.Balance)
+		}
+	}
+}
+
+func TestAddressBook(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	keystore := testKeystore{"alice": memdb.New()}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	s := &Service{vm: vm}
+	addr0 := vm.Format(keys[0].PublicKey().Address().Bytes())
+	addr1 := vm.Format(keys[1].PublicKey().Address().Bytes())
+
+	for _, entry := range []AddressBookEntry{{Name: "exchange-hot", Address: addr1}, {Name: "cold", Address: addr0}} {
+		if err := s.AddAddressBookEntry(nil, &AddAddressBookEntryArgs{
+			Username:         "alice",
+			AddressBookEntry: entry,
+		}, &AddAddressBookEntryReply{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AddAddressBookEntry(nil, &AddAddressBookEntryArgs{
+		Username:         "alice",
+		AddressBookEntry: AddressBookEntry{Name: addr0, Address: addr1},
+	}, &AddAddressBookEntryReply{}); err == nil {
+		t.Fatalf("Should have failed to name an entry after an address")
+	}
+
+	listReply := ListAddressBookReply{}
+	if err := s.ListAddressBook(nil, &ListAddressBookArgs{Username: "alice"}, &listReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(listReply.Entries) != 2 ||
+		listReply.Entries[0] != (AddressBookEntry{Name: "cold", Address: addr0}) ||
+		listReply.Entries[1] != (AddressBookEntry{Name: "exchange-hot", Address: addr1}) {
+		t.Fatalf("Wrong address book: %v", listReply.Entries)
+	}
+
+	if addr, err := s.lookupAddress("alice", "", "exchange-hot"); err != nil {
+		t.Fatal(err)
+	} else if !addr.Equals(keys[1].PublicKey().Address()) {
+		t.Fatalf("Wrong address %s", addr)
+	}
+	if addr, err := s.lookupAddress("alice", "", addr0); err != nil {
+		t.Fatal(err)
+	} else if !addr.Equals(keys[0].PublicKey().Address()) {
+		t.Fatalf("Wrong address %s", addr)
+	}
+
+	if err := s.RemoveAddressBookEntry(nil, &RemoveAddressBookEntryArgs{
+		Username: "alice",
+		Name:     "exchange-hot",
+	}, &RemoveAddressBookEntryReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.lookupAddress("alice", "", "exchange-hot"); err == nil {
+		t.Fatalf("Removed entry shouldn't be found")
+	}
+	if err := s.RemoveAddressBookEntry(nil, &RemoveAddressBookEntryArgs{
+		Username: "alice",
+		Name:     "exchange-hot",
+	}, &RemoveAddressBookEntryReply{}); err == nil {
+		t.Fatalf("Should have failed to remove an entry that doesn't exist")
+	}
+}
+
+func TestConsolidate(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	keystore := testKeystore{}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	db := memdb.New()
+	user := userState{vm: vm}
+	if err := user.SetKey(db, keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	addr0 := ids.NewID(hashing.ComputeHash256Array(keys[0].PublicKey().Address().Bytes()))
+	if err := user.SetAddresses(db, []ids.ID{addr0}); err != nil {
+		t.Fatal(err)
+	}
+	keystore["alice"] = db
+
+	s := Service{vm: vm}
+	holder := vm.Format(keys[0].PublicKey().Address().Bytes())
+	accept := func(txID ids.ID) {
+		vm.state.UniqueTx(&UniqueTx{vm: vm, txID: txID}).Accept()
+	}
+
+	// 5 dust outputs and 1 output that isn't dust
+	holders := []*Holder{&Holder{Amount: 1000, Address: holder}}
+	for i := 1; i <= 5; i++ {
+		holders = append(holders, &Holder{Amount: json.Uint64(i), Address: holder})
+	}
+	createReply := CreateFixedCapAssetReply{}
+	if err := s.CreateFixedCapAsset(nil, &CreateFixedCapAssetArgs{
+		Username:       "alice",
+		Name:           "dusty asset",
+		Symbol:         "DST",
+		InitialHolders: holders,
+	}, &createReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(createReply.AssetID)
+
+	args := &ConsolidateArgs{
+		Username:  "alice",
+		AssetID:   createReply.AssetID.String(),
+		MaxAmount: 10,
+		BatchSize: 2,
+		DryRun:    true,
+	}
+	dryRunReply := ConsolidateReply{}
+	if err := s.Consolidate(nil, args, &dryRunReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(dryRunReply.Consolidations) != 2 {
+		t.Fatalf("Should have consolidated 2 batches but consolidated %d", len(dryRunReply.Consolidations))
+	}
+	for _, consolidation := range dryRunReply.Consolidations {
+		if consolidation.NumUTXOs != 2 {
+			t.Fatalf("Each batch should consume 2 UTXOs but consumed %d", consolidation.NumUTXOs)
+		}
+		if status := vm.state.UniqueTx(&UniqueTx{vm: vm, txID: consolidation.TxID}).Status(); status != choices.Unknown {
+			t.Fatalf("A dry run shouldn't issue transactions, but one is %s", status)
+		}
+	}
+
+	args.DryRun = false
+	reply := ConsolidateReply{}
+	if err := s.Consolidate(nil, args, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Consolidations) != 2 {
+		t.Fatalf("Should have consolidated 2 batches but consolidated %d", len(reply.Consolidations))
+	}
+	for i, consolidation := range reply.Consolidations {
+		if consolidation.Error != "" {
+			t.Fatalf("Consolidation failed: %s", consolidation.Error)
+		}
+		if !consolidation.TxID.Equals(dryRunReply.Consolidations[i].TxID) {
+			t.Fatalf("Issued transaction should be the one the dry run reported")
+		}
+		accept(consolidation.TxID)
+	}
+
+	balanceReply := GetBalanceReply{}
+	if err := s.GetBalance(nil, &GetBalanceArgs{
+		Address: holder,
+		AssetID: createReply.AssetID.String(),
+	}, &balanceReply); err != nil {
+		t.Fatal(err)
+	}
+	if balanceReply.Balance != 1015 {
+		t.Fatalf("Consolidating shouldn't change the balance, but it's %d", balanceReply.Balance)
+	}
+
+	addrs := ids.Set{}
+	addrs.Add(addr0)
+	utxos, err := vm.GetUTXOs(addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numUTXOs := 0
+	for _, utxo := range utxos {
+		if utxo.AssetID().Equals(createReply.AssetID) {
+			numUTXOs++
+		}
+	}
+	if numUTXOs != 4 {
+		t.Fatalf("Holder should have 4 UTXOs of the asset but has %d", numUTXOs)
+	}
+}
+
+func TestAssetMetadata(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	keystore := testKeystore{}
+	ctx.Keystore = keystore
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{
+			&common.Fx{
+				ID: ids.Empty,
+				Fx: &secp256k1fx.Fx{},
+			},
+			&common.Fx{
+				ID: ids.Empty.Prefix(0),
+				Fx: &managedfx.Fx{},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	for i, username := range []string{"alice", "bob"} {
+		db := memdb.New()
+		user := userState{vm: vm}
+		if err := user.SetKey(db, keys[i]); err != nil {
+			t.Fatal(err)
+		}
+		addr := ids.NewID(hashing.ComputeHash256Array(keys[i].PublicKey().Address().Bytes()))
+		if err := user.SetAddresses(db, []ids.ID{addr}); err != nil {
+			t.Fatal(err)
+		}
+		keystore[username] = db
+	}
+
+	s := Service{vm: vm}
+	manager := vm.Format(keys[0].PublicKey().Address().Bytes())
+	accept := func(txID ids.ID) {
+		vm.state.UniqueTx(&UniqueTx{vm: vm, txID: txID}).Accept()
+	}
+
+	createReply := CreateManagedAssetReply{}
+	if err := s.CreateManagedAsset(nil, &CreateManagedAssetArgs{
+		Username: "alice",
+		Name:     "managed asset",
+		Symbol:   "MA",
+		Managers: Owners{Threshold: 1, Minters: []string{manager}},
+	}, &createReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(createReply.AssetID)
+	assetID := createReply.AssetID.String()
+
+	if err := s.GetAssetMetadata(nil, &GetAssetMetadataArgs{AssetID: assetID}, &GetAssetMetadataReply{}); err != errNoAssetMetadata {
+		t.Fatalf("Should have failed to get the metadata of an asset that has none")
+	}
+
+	logoHash := hashing.ComputeHash256([]byte("logo"))
+	setReply := SetAssetMetadataReply{}
+	if err := s.SetAssetMetadata(nil, &SetAssetMetadataArgs{
+		Username: "alice",
+		AssetID:  assetID,
+		Decimals: 6,
+		LogoHash: formatting.CB58{Bytes: logoHash},
+		Website:  "https://example.com",
+	}, &setReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(setReply.TxID)
+
+	getReply := GetAssetMetadataReply{}
+	if err := s.GetAssetMetadata(nil, &GetAssetMetadataArgs{AssetID: assetID}, &getReply); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case getReply.Decimals != 6:
+		t.Fatalf("Wrong decimals %d", getReply.Decimals)
+	case !bytes.Equal(getReply.LogoHash.Bytes, logoHash):
+		t.Fatalf("Wrong logo hash %s", getReply.LogoHash)
+	case getReply.Website != "https://example.com":
+		t.Fatalf("Wrong website %s", getReply.Website)
+	case !getReply.TxID.Equals(setReply.TxID):
+		t.Fatalf("Wrong tx ID %s", getReply.TxID)
+	}
+
+	// The manager output was produced again, so the managers still control
+	// the asset
+	updateReply := SetAssetMetadataReply{}
+	if err := s.SetAssetMetadata(nil, &SetAssetMetadataArgs{
+		Username: "alice",
+		AssetID:  assetID,
+		Decimals: 2,
+	}, &updateReply); err != nil {
+		t.Fatal(err)
+	}
+	accept(updateReply.TxID)
+	if err := s.GetAssetMetadata(nil, &GetAssetMetadataArgs{AssetID: assetID}, &getReply); err != nil {
+		t.Fatal(err)
+	}
+	if getReply.Decimals != 2 || len(getReply.LogoHash.Bytes) != 0 || getReply.Website != "" {
+		t.Fatalf("The metadata should have been replaced but is %+v", getReply)
+	}
+
+	if err := s.SetAssetMetadata(nil, &SetAssetMetadataArgs{
+		Username: "bob",
+		AssetID:  assetID,
+	}, &SetAssetMetadataReply{}); err != errAddressesCantControlAsset {
+		t.Fatalf("Should have failed to set the metadata without the managers' keys")
+	}
+	if err := s.SetAssetMetadata(nil, &SetAssetMetadataArgs{
+		Username: "alice",
+		AssetID:  assetID,
+		LogoHash: formatting.CB58{Bytes: []byte{1}},
+	}, &SetAssetMetadataReply{}); err != errInvalidLogoHash {
+		t.Fatalf("Should have failed to set a malformed logo hash")
+	}
+}
@@ -17,7 +17,8 @@ import (
 )
 
 var (
-	errUnknownAssetType = errors.New("unknown asset type")
+	errUnknownAssetType     = errors.New("unknown asset type")
+	errUndescribableGenesis = errors.New("genesis output can't be described by an asset definition")
 )
 
 // StaticService defines the base service for the asset vm
@@ -44,15 +45,7 @@ type BuildGenesisReply struct {
 // BuildGenesis returns the UTXOs such that at least one address in [args.Addresses] is
 // referenced in the UTXO.
 func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, reply *BuildGenesisReply) error {
-	c := codec.NewDefault()
-	c.RegisterType(&BaseTx{})
-	c.RegisterType(&CreateAssetTx{})
-	c.RegisterType(&OperationTx{})
-	c.RegisterType(&secp256k1fx.MintOutput{})
-	c.RegisterType(&secp256k1fx.TransferOutput{})
-	c.RegisterType(&secp256k1fx.MintInput{})
-	c.RegisterType(&secp256k1fx.TransferInput{})
-	c.RegisterType(&secp256k1fx.Credential{})
+	c := genesisCodec()
 
 	g := Genesis{}
 	for assetAlias, assetDefinition := range args.GenesisData {
@@ -153,3 +146,82 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 	reply.Bytes.Bytes = b
 	return nil
 }
+
+// ParseGenesisArgs are arguments for ParseGenesis
+type ParseGenesisArgs struct {
+	Bytes formatting.CB58 `json:"bytes"`
+}
+
+// ParseGenesisReply is the reply from ParseGenesis
+type ParseGenesisReply struct {
+	GenesisData map[string]AssetDefinition `json:"genesisData"`
+}
+
+// ParseGenesis returns the asset definitions that the genesis bytes
+// [args.Bytes] were built from. It's the inverse of BuildGenesis.
+func (*StaticService) ParseGenesis(_ *http.Request, args *ParseGenesisArgs, reply *ParseGenesisReply) error {
+	c := genesisCodec()
+
+	g := Genesis{}
+	if err := c.Unmarshal(args.Bytes.Bytes, &g); err != nil {
+		return err
+	}
+
+	reply.GenesisData = make(map[string]AssetDefinition, len(g.Txs))
+	for _, asset := range g.Txs {
+		assetDefinition := AssetDefinition{
+			Name:         asset.Name,
+			Symbol:       asset.Symbol,
+			Denomination: cjson.Uint8(asset.Denomination),
+			InitialState: make(map[string][]interface{}),
+		}
+		for _, initialState := range asset.States {
+			for _, out := range initialState.Outs {
+				switch out := out.(type) {
+				case *secp256k1fx.TransferOutput:
+					if out.Threshold != 1 || len(out.Addrs) != 1 {
+						return errUndescribableGenesis
+					}
+					assetDefinition.InitialState["fixedCap"] = append(assetDefinition.InitialState["fixedCap"], Holder{
+						Amount:  cjson.Uint64(out.Amount()),
+						Address: formatAddresses(out.Addrs)[0],
+					})
+				case *secp256k1fx.MintOutput:
+					assetDefinition.InitialState["variableCap"] = append(assetDefinition.InitialState["variableCap"], Owners{
+						Threshold: cjson.Uint32(out.Threshold),
+						Minters:   formatAddresses(out.Addrs),
+					})
+				default:
+					return errUndescribableGenesis
+				}
+			}
+		}
+		reply.GenesisData[asset.Alias] = assetDefinition
+	}
+	return nil
+}
+
+// formatAddresses returns the CB58 representation of [addrs], which is how
+// addresses are given to BuildGenesis
+func formatAddresses(addrs []ids.ShortID) []string {
+	formatted := make([]string, len(addrs))
+	for i, addr := range addrs {
+		formatted[i] = formatting.CB58{Bytes: addr.Bytes()}.String()
+	}
+	return formatted
+}
+
+// genesisCodec returns the codec that genesis data is serialized with, before
+// a VM has registered its feature extensions
+func genesisCodec() codec.Codec {
+	c := codec.NewDefault()
+	c.RegisterType(&BaseTx{})
+	c.RegisterType(&CreateAssetTx{})
+	c.RegisterType(&OperationTx{})
+	c.RegisterType(&secp256k1fx.MintOutput{})
+	c.RegisterType(&secp256k1fx.TransferOutput{})
+	c.RegisterType(&secp256k1fx.MintInput{})
+	c.RegisterType(&secp256k1fx.TransferInput{})
+	c.RegisterType(&secp256k1fx.Credential{})
+	return c
+}
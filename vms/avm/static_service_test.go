@@ -96,3 +96,69 @@ func TestBuildGenesis(t *testing.T) {
 		)
 	}
 }
+
+func TestParseGenesis(t *testing.T) {
+	ss := StaticService{}
+
+	buildArgs := BuildGenesisArgs{GenesisData: map[string]AssetDefinition{
+		"asset1": AssetDefinition{
+			Name:         "myFixedCapAsset",
+			Symbol:       "MFCA",
+			Denomination: 8,
+			InitialState: map[string][]interface{}{
+				"fixedCap": []interface{}{
+					Holder{
+						Amount:  100000,
+						Address: "A9bTQjfYGBFK3JPRJqF2eh3JYL7cHocvy",
+					},
+				},
+			},
+		},
+		"asset2": AssetDefinition{
+			Name:   "myVarCapAsset",
+			Symbol: "MVCA",
+			InitialState: map[string][]interface{}{
+				"variableCap": []interface{}{
+					Owners{
+						Threshold: 1,
+						Minters: []string{
+							"6mxBGnjGDCKgkVe7yfrmvMA7xE7qCv3vv",
+						},
+					},
+				},
+			},
+		},
+	}}
+	buildReply := BuildGenesisReply{}
+	if err := ss.BuildGenesis(nil, &buildArgs, &buildReply); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := ParseGenesisReply{}
+	if err := ss.ParseGenesis(nil, &ParseGenesisArgs{Bytes: buildReply.Bytes}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.GenesisData) != 2 {
+		t.Fatalf("Should have parsed 2 assets but parsed %d", len(reply.GenesisData))
+	}
+
+	asset1 := reply.GenesisData["asset1"]
+	if asset1.Name != "myFixedCapAsset" || asset1.Symbol != "MFCA" || asset1.Denomination != 8 {
+		t.Fatalf("Wrong asset: %+v", asset1)
+	}
+	if holders := asset1.InitialState["fixedCap"]; len(holders) != 1 ||
+		holders[0] != (Holder{Amount: 100000, Address: "A9bTQjfYGBFK3JPRJqF2eh3JYL7cHocvy"}) {
+		t.Fatalf("Wrong holders: %v", holders)
+	}
+
+	asset2 := reply.GenesisData["asset2"]
+	if owners := asset2.InitialState["variableCap"]; len(owners) != 1 {
+		t.Fatalf("Wrong owners: %v", owners)
+	} else if minters := owners[0].(Owners).Minters; len(minters) != 1 || minters[0] != "6mxBGnjGDCKgkVe7yfrmvMA7xE7qCv3vv" {
+		t.Fatalf("Wrong minters: %v", minters)
+	}
+
+	if err := ss.ParseGenesis(nil, &ParseGenesisArgs{Bytes: formatting.CB58{Bytes: []byte{1}}}, &reply); err == nil {
+		t.Fatalf("Should have failed to parse invalid genesis bytes")
+	}
+}
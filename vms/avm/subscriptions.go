@@ -0,0 +1,51 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+// Clients of the pubsub endpoint are notified of transactions on the "issued",
+// "verified", "accepted" and "rejected" channels. A client may subscribe to a
+// channel with a filter of addresses and asset IDs, in which case it's only
+// notified of the transactions that consume or produce a UTXO that one of the
+// addresses owns or that holds one of the assets.
+
+// publishTx notifies the subscribers of [channel] of [tx], whose subscription
+// keys are [keys]
+func (vm *VM) publishTx(channel string, tx *UniqueTx, keys []string) {
+	vm.pubsub.PublishFiltered(channel, tx.ID(), keys)
+}
+
+// subscriptionKeys returns the addresses, formatted as in the API, and the
+// asset IDs of the UTXOs that [tx] consumes and produces. A UTXO that [tx]
+// consumes is skipped if it isn't in the state, such as when it's produced by
+// a transaction that hasn't been accepted yet. So, this should be called
+// before [tx]'s inputs are spent.
+func (vm *VM) subscriptionKeys(tx *UniqueTx) []string {
+	utxos := append([]*UTXO(nil), tx.UTXOs()...)
+	for _, utxoID := range tx.InputUTXOs() {
+		if utxoID.Symbolic() {
+			continue
+		}
+		utxo, err := vm.state.UTXO(utxoID.InputID())
+		if err != nil {
+			continue
+		}
+		utxos = append(utxos, utxo)
+	}
+
+	keySet := map[string]struct{}{}
+	for _, utxo := range utxos {
+		keySet[utxo.AssetID().String()] = struct{}{}
+		if addressable, ok := utxo.Out.(FxAddressable); ok {
+			for _, addr := range addressable.Addresses() {
+				keySet[vm.Format(addr)] = struct{}{}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,99 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+func TestSubscriptionKeys(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+
+	newTx := &Tx{UnsignedTx: &BaseTx{
+		NetID: networkID,
+		BCID:  chainID,
+		Ins: []*TransferableInput{&TransferableInput{
+			UTXOID: UTXOID{
+				TxID:        genesisTx.ID(),
+				OutputIndex: 1,
+			},
+			Asset: Asset{ID: genesisTx.ID()},
+			In: &secp256k1fx.TransferInput{
+				Amt:   50000,
+				Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+			},
+		}},
+	}}
+
+	unsignedBytes, err := vm.codec.Marshal(&newTx.UnsignedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := keys[0].Sign(unsignedBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixedSig := [crypto.SECP256K1RSigLen]byte{}
+	copy(fixedSig[:], sig)
+	newTx.Creds = append(newTx.Creds, &Credential{
+		Cred: &secp256k1fx.Credential{
+			Sigs: [][crypto.SECP256K1RSigLen]byte{fixedSig},
+		},
+	})
+
+	b, err := vm.codec.Marshal(newTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := vm.parseTx(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxo, err := vm.state.UTXO(tx.InputUTXOs()[0].InputID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]bool{genesisTx.ID().String(): true}
+	for _, addr := range utxo.Out.(FxAddressable).Addresses() {
+		expected[vm.Format(addr)] = true
+	}
+
+	keys := vm.subscriptionKeys(tx)
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %d keys but got %v", len(expected), keys)
+	}
+	for _, key := range keys {
+		if !expected[key] {
+			t.Fatalf("unexpected key %s", key)
+		}
+	}
+}
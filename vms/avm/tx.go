@@ -30,8 +30,16 @@ type UnsignedTx interface {
 	AssetIDs() ids.Set
 	InputUTXOs() []*UTXOID
 	UTXOs() []*UTXO
+
+	// Burned returns the amount of [assetID] that this transaction consumes
+	// but doesn't produce
+	Burned(assetID ids.ID) (uint64, error)
+
 	SyntacticVerify(ctx *snow.Context, c codec.Codec, numFxs int) error
 	SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) error
+
+	// ExecuteSideEffects is called when this transaction is accepted
+	ExecuteSideEffects(vm *VM) error
 }
 
 // Tx is the core operation that can be performed. The tx uses the UTXO model.
@@ -79,5 +87,9 @@ func (t *Tx) SemanticVerify(vm *VM, uTx *UniqueTx) error {
 		return errNilTx
 	}
 
+	if err := vm.verifyFee(t, uTx.Bytes()); err != nil {
+		return err
+	}
+
 	return t.UnsignedTx.SemanticVerify(vm, uTx, t.Creds)
 }
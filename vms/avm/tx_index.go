@@ -0,0 +1,217 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Accepted transactions are indexed by each address that owns a UTXO they
+// consume or produce on this chain. Each accepted transaction is given a
+// height, which is the number of transactions this node accepted before it,
+// so the history of an address is kept in the order it was accepted in.
+var (
+	// txIndexPrefix prefixes the index, which stores
+	// [tx ID] + [acceptance time] under [address ID] + [height]
+	txIndexPrefix = []byte("txIndex")
+
+	// acceptedHeightKey is where the height of the next accepted transaction
+	// is stored, in the index's prefix
+	acceptedHeightKey = []byte("height")
+
+	// txAddressesPrefix prefixes the addresses of each accepted transaction,
+	// which are stored under its ID
+	txAddressesPrefix = []byte("txAddresses")
+)
+
+const (
+	addressTxKeyLen = hashing.HashLen + wrappers.LongLen
+	addressTxLen    = hashing.HashLen + wrappers.LongLen
+)
+
+// addressTx is a transaction in the history of an address
+type addressTx struct {
+	txID      ids.ID
+	height    uint64
+	timestamp uint64 // Unix time, in seconds, the tx was accepted at
+}
+
+// txAddresses returns the addresses that own one of the UTXOs [inputs]
+// consume or one of [utxos], without duplicates. Must be called before
+// [inputs] are spent.
+func (vm *VM) txAddresses(inputs []*UTXOID, utxos []*UTXO) ([][]byte, error) {
+	addrIDs := ids.Set{}
+	addrs := [][]byte(nil)
+	for _, utxoID := range inputs {
+		if utxoID.Symbolic() {
+			continue
+		}
+		utxo, err := vm.state.UTXO(utxoID.InputID())
+		if err != nil {
+			return nil, err
+		}
+		addrs = addAddresses(addrIDs, addrs, utxo)
+	}
+	for _, utxo := range utxos {
+		addrs = addAddresses(addrIDs, addrs, utxo)
+	}
+	return addrs, nil
+}
+
+// addAddresses appends to [addrs] the addresses that own [utxo] whose IDs
+// aren't in [addrIDs] yet
+func addAddresses(addrIDs ids.Set, addrs [][]byte, utxo *UTXO) [][]byte {
+	addressable, ok := utxo.Out.(FxAddressable)
+	if !ok {
+		return addrs
+	}
+	for _, addr := range addressable.Addresses() {
+		addrID := ids.NewID(hashing.ComputeHash256Array(addr))
+		if !addrIDs.Contains(addrID) {
+			addrIDs.Add(addrID)
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// indexTx adds the transaction [txID], which was just accepted, to the history
+// of each of [addrs], and records that [addrs] are its addresses
+func (vm *VM) indexTx(txID ids.ID, addrs [][]byte) error {
+	index := prefixdb.New(txIndexPrefix, vm.db)
+
+	height, err := vm.acceptedHeight()
+	if err != nil {
+		return err
+	}
+
+	p := wrappers.Packer{MaxSize: addressTxLen}
+	p.PackFixedBytes(txID.Bytes())
+	p.PackLong(vm.clock.Unix())
+	if p.Errored() {
+		return p.Err
+	}
+
+	size := wrappers.IntLen
+	for _, addr := range addrs {
+		addrID := ids.NewID(hashing.ComputeHash256Array(addr))
+		if err := index.Put(addressTxKey(addrID, height), p.Bytes); err != nil {
+			return err
+		}
+		size += wrappers.IntLen + len(addr)
+	}
+
+	addrsPacker := wrappers.Packer{MaxSize: size}
+	addrsPacker.PackInt(uint32(len(addrs)))
+	for _, addr := range addrs {
+		addrsPacker.PackBytes(addr)
+	}
+	if addrsPacker.Errored() {
+		return addrsPacker.Err
+	}
+	if err := prefixdb.New(txAddressesPrefix, vm.db).Put(txID.Bytes(), addrsPacker.Bytes); err != nil {
+		return err
+	}
+
+	next := wrappers.Packer{MaxSize: wrappers.LongLen}
+	next.PackLong(height + 1)
+	return index.Put(acceptedHeightKey, next.Bytes)
+}
+
+// TxAddresses implements the common.AddressableVM interface. It returns the
+// addresses that own a UTXO the accepted transaction [txID] consumed or
+// produced. Transactions accepted before addresses were recorded have none.
+func (vm *VM) TxAddresses(txID ids.ID) ([][]byte, error) {
+	addrsBytes, err := prefixdb.New(txAddressesPrefix, vm.db).Get(txID.Bytes())
+	if err == database.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := wrappers.Packer{Bytes: addrsBytes}
+	numAddrs := p.UnpackInt()
+	addrs := [][]byte(nil)
+	for i := uint32(0); i < numAddrs && !p.Errored(); i++ {
+		addrs = append(addrs, p.UnpackBytes())
+	}
+	if p.Errored() {
+		return nil, p.Err
+	}
+	return addrs, nil
+}
+
+// acceptedHeight returns the height of the next transaction to be accepted
+func (vm *VM) acceptedHeight() (uint64, error) {
+	heightBytes, err := prefixdb.New(txIndexPrefix, vm.db).Get(acceptedHeightKey)
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	p := wrappers.Packer{Bytes: heightBytes}
+	height := p.UnpackLong()
+	return height, p.Err
+}
+
+// addressTxs returns, in the order they were accepted in, at most [limit] of
+// the transactions in the history of [addrID] that were accepted at a height
+// in [startHeight, endHeight] and at a time in [startTime, endTime]
+func (vm *VM) addressTxs(addrID ids.ID, startHeight, endHeight, startTime, endTime uint64, limit int) ([]addressTx, error) {
+	iter := prefixdb.New(txIndexPrefix, vm.db).NewIteratorWithStartAndPrefix(
+		addressTxKey(addrID, startHeight),
+		addrID.Bytes(),
+	)
+	defer iter.Release()
+
+	txs := []addressTx(nil)
+	for len(txs) < limit && iter.Next() {
+		key := iter.Key()
+		if len(key) != addressTxKeyLen {
+			continue
+		}
+		keyParser := wrappers.Packer{Bytes: key[hashing.HashLen:]}
+		height := keyParser.UnpackLong()
+		if keyParser.Errored() {
+			return nil, keyParser.Err
+		}
+		if height > endHeight {
+			break
+		}
+
+		p := wrappers.Packer{Bytes: iter.Value()}
+		txIDBytes := p.UnpackFixedBytes(hashing.HashLen)
+		timestamp := p.UnpackLong()
+		if p.Errored() {
+			return nil, p.Err
+		}
+		if timestamp < startTime || timestamp > endTime {
+			continue
+		}
+
+		txID, err := ids.ToID(txIDBytes)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, addressTx{
+			txID:      txID,
+			height:    height,
+			timestamp: timestamp,
+		})
+	}
+	return txs, iter.Error()
+}
+
+func addressTxKey(addrID ids.ID, height uint64) []byte {
+	p := wrappers.Packer{MaxSize: addressTxKeyLen}
+	p.PackFixedBytes(addrID.Bytes())
+	p.PackLong(height)
+	return p.Bytes
+}
@@ -5,10 +5,12 @@ package avm
 
 import (
 	"errors"
+	"time"
 
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
+	"github.com/ava-labs/gecko/vms/components/verify"
 )
 
 var (
@@ -98,30 +100,56 @@ func (tx *UniqueTx) Accept() {
 		return
 	}
 
+	addrs, err := tx.vm.txAddresses(tx.InputUTXOs(), tx.UTXOs())
+	if err != nil {
+		tx.vm.ctx.Log.Error("Failed to find the addresses of tx %s due to %s", tx.txID, err)
+		return
+	}
+	keys := tx.vm.subscriptionKeys(tx)
+
 	// Remove spent utxos
-	for _, utxoID := range tx.InputIDs().List() {
+	spent := 0
+	for _, utxo := range tx.InputUTXOs() {
+		if utxo.Symbolic() {
+			// If the UTXO is symbolic, it can't be spent
+			continue
+		}
+		utxoID := utxo.InputID()
 		if err := tx.vm.state.SpendUTXO(utxoID); err != nil {
 			tx.vm.ctx.Log.Error("Failed to spend utxo %s due to %s", utxoID, err)
 			return
 		}
+		spent++
 	}
 
 	// Add new utxos
-	for _, utxo := range tx.UTXOs() {
+	utxos := tx.UTXOs()
+	for _, utxo := range utxos {
 		if err := tx.vm.state.FundUTXO(utxo); err != nil {
 			tx.vm.ctx.Log.Error("Failed to fund utxo %s due to %s", utxoID, err)
 			return
 		}
 	}
 
+	if err := tx.t.tx.ExecuteSideEffects(tx.vm); err != nil {
+		tx.vm.ctx.Log.Error("Failed to execute the side effects of %s due to %s", tx.txID, err)
+		return
+	}
+
 	txID := tx.ID()
+	if err := tx.vm.indexTx(txID, addrs); err != nil {
+		tx.vm.ctx.Log.Error("Failed to index tx %s due to %s", txID, err)
+		return
+	}
+
 	tx.vm.ctx.Log.Verbo("Accepting Tx: %s", txID)
 
 	if err := tx.vm.db.Commit(); err != nil {
 		tx.vm.ctx.Log.Error("Failed to commit accept %s due to %s", tx.txID, err)
 	}
 
-	tx.vm.pubsub.Publish("accepted", txID)
+	tx.vm.publishTx("accepted", tx, keys)
+	tx.vm.metrics.Accepted(1, len(utxos), spent)
 
 	tx.t.deps = nil // Needed to prevent a memory leak
 }
@@ -140,7 +168,8 @@ func (tx *UniqueTx) Reject() {
 		tx.vm.ctx.Log.Error("Failed to commit reject %s due to %s", tx.txID, err)
 	}
 
-	tx.vm.pubsub.Publish("rejected", txID)
+	tx.vm.publishTx("rejected", tx, tx.vm.subscriptionKeys(tx))
+	tx.vm.metrics.Rejected(1)
 
 	tx.t.deps = nil // Needed to prevent a memory leak
 }
@@ -160,6 +189,9 @@ func (tx *UniqueTx) Dependencies() []snowstorm.Tx {
 
 	txIDs := ids.Set{}
 	for _, in := range tx.InputUTXOs() {
+		if in.Symbolic() {
+			continue
+		}
 		txID, _ := in.InputSource()
 		if !txIDs.Contains(txID) {
 			txIDs.Add(txID)
@@ -259,11 +291,13 @@ func (tx *UniqueTx) SemanticVerify() error {
 		return tx.t.validity
 	}
 
+	start := time.Now()
 	tx.t.verifiedState = true
 	tx.t.validity = tx.t.tx.SemanticVerify(tx.vm, tx)
+	tx.vm.metrics.Verified(start)
 
 	if tx.t.validity == nil {
-		tx.vm.pubsub.Publish("verified", tx.ID())
+		tx.vm.publishTx("verified", tx, tx.vm.subscriptionKeys(tx))
 	}
 	return tx.t.validity
 }
@@ -274,3 +308,13 @@ func (tx *UniqueTx) UnsignedBytes() []byte {
 	tx.vm.ctx.Log.AssertNoError(err)
 	return b
 }
+
+// Credentials returns the credentials of the transaction, in the order of the
+// inputs they spend
+func (tx *UniqueTx) Credentials() []verify.Verifiable {
+	creds := make([]verify.Verifiable, len(tx.t.tx.Creds))
+	for i, cred := range tx.t.tx.Creds {
+		creds[i] = cred.Cred
+	}
+	return creds
+}
@@ -5,12 +5,17 @@ package avm
 
 import (
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/hashing"
 )
 
-var addresses = ids.Empty
+var (
+	addresses = ids.Empty
+
+	addressBookPrefix = []byte("address book")
+)
 
 type userState struct{ vm *VM }
 
@@ -51,3 +56,45 @@ func (s *userState) Key(db database.Database, address ids.ID) (*crypto.PrivateKe
 	}
 	return sk.(*crypto.PrivateKeySECP256K1R), nil
 }
+
+// addressBook returns the database that the user's address book is stored in
+// Key: Name of an entry
+// Value: The address the entry names
+func (s *userState) addressBook(db database.Database) database.Database {
+	return prefixdb.New(addressBookPrefix, db)
+}
+
+func (s *userState) SetAddressBookEntry(db database.Database, name string, address ids.ShortID) error {
+	return s.addressBook(db).Put([]byte(name), address.Bytes())
+}
+
+func (s *userState) AddressBookEntry(db database.Database, name string) (ids.ShortID, error) {
+	bytes, err := s.addressBook(db).Get([]byte(name))
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	return ids.ToShortID(bytes)
+}
+
+func (s *userState) RemoveAddressBookEntry(db database.Database, name string) error {
+	return s.addressBook(db).Delete([]byte(name))
+}
+
+// AddressBook returns the names of the entries in the user's address book, in
+// sorted order, and the addresses they name
+func (s *userState) AddressBook(db database.Database) ([]string, []ids.ShortID, error) {
+	it := s.addressBook(db).NewIterator()
+	defer it.Release()
+
+	names := []string{}
+	addrs := []ids.ShortID{}
+	for it.Next() {
+		addr, err := ids.ToShortID(it.Value())
+		if err != nil {
+			return nil, nil, err
+		}
+		names = append(names, string(it.Key()))
+		addrs = append(addrs, addr)
+	}
+	return names, addrs, it.Error()
+}
@@ -20,6 +20,10 @@ type UTXOID struct {
 	TxID        ids.ID `serialize:"true"`
 	OutputIndex uint32 `serialize:"true"`
 
+	// Symbol is true if the UTXO isn't part of this chain's state, because it
+	// was exported to this chain by another chain
+	Symbol bool
+
 	// Cached:
 	id ids.ID
 }
@@ -35,6 +39,10 @@ func (utxo *UTXOID) InputID() ids.ID {
 	return utxo.id
 }
 
+// Symbolic returns if this is the ID of a UTXO that isn't part of this chain's
+// state
+func (utxo *UTXOID) Symbolic() bool { return utxo.Symbol }
+
 // Verify implements the verify.Verifiable interface
 func (utxo *UTXOID) Verify() error {
 	switch {
@@ -5,25 +5,30 @@ package avm
 
 import (
 	"errors"
-	"fmt"
 	"reflect"
-	"strings"
 	"time"
 
-	"github.com/gorilla/rpc/v2"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/cache"
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
 	"github.com/ava-labs/gecko/snow/engine/common"
-	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/crypto/ledger"
 	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/address"
 	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/metrics"
+	"github.com/ava-labs/gecko/vms/managedfx"
+	"github.com/ava-labs/gecko/vms/nftfx"
+	"github.com/ava-labs/gecko/vms/schnorrfx"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
 
 	cjson "github.com/ava-labs/gecko/utils/json"
 )
@@ -31,18 +36,27 @@ import (
 const (
 	batchTimeout   = time.Second
 	batchSize      = 30
+	mempoolMaxTxs  = 4096
+	mempoolMaxSize = 32 << 20 // 32 MiB
 	stateCacheSize = 10000
 	idCacheSize    = 10000
 	txCacheSize    = 10000
-	addressSep     = "-"
 )
 
 var (
 	errIncompatibleFx            = errors.New("incompatible feature extension")
 	errUnknownFx                 = errors.New("unknown feature extension")
+	errNFTFxNotSupported         = errors.New("chain doesn't support non-fungible assets")
+	errSECPFxNotSupported        = errors.New("chain doesn't support secp256k1 outputs")
+	errManagedFxNotSupported     = errors.New("chain doesn't support managed assets")
+	errSchnorrFxNotSupported     = errors.New("chain doesn't support aggregated signatures")
 	errGenesisAssetMustHaveState = errors.New("genesis asset must have non-empty state")
-	errInvalidAddress            = errors.New("invalid address")
+	errGenesisNotSorted          = errors.New("genesis assets must be sorted and unique")
 	errWrongBlockchainID         = errors.New("wrong blockchain ID")
+	errDuplicateBatchTx          = errors.New("transaction appears earlier in the batch")
+	errBatchOrder                = errors.New("transaction depends on a later transaction in the batch")
+	errBatchDependencyFailed     = errors.New("transaction depends on a transaction in the batch that failed")
+	errBatchConflict             = errors.New("transaction spends a UTXO spent earlier in the batch")
 )
 
 // VM implements the avalanche.DAGVM interface
@@ -57,15 +71,20 @@ type VM struct {
 
 	codec codec.Codec
 
+	// Fees that transactions pay
+	fees FeeConfig
+
 	pubsub *cjson.PubSubServer
 
+	metrics metrics.Metrics
+
 	// State management
 	state *prefixedState
 
 	// Transaction issuing
 	timer        *timer.Timer
 	batchTimeout time.Duration
-	txs          []snowstorm.Tx
+	mempool      mempool
 	toEngine     chan<- common.Message
 
 	baseDB database.Database
@@ -111,9 +130,12 @@ func (vm *VM) Initialize(
 	vm.Aliaser.Initialize()
 
 	vm.pubsub = cjson.NewPubSubServer(ctx)
+	vm.metrics.Initialize(ctx.Namespace)
+	vm.mempool.Initialize(mempoolMaxTxs, mempoolMaxSize, ctx.Namespace)
 
 	errs := wrappers.Errs{}
 	errs.Add(
+		vm.pubsub.Register("issued"),
 		vm.pubsub.Register("accepted"),
 		vm.pubsub.Register("rejected"),
 		vm.pubsub.Register("verified"),
@@ -134,6 +156,8 @@ func (vm *VM) Initialize(
 		funds:    &cache.LRU{Size: idCacheSize},
 
 		uniqueTx: &cache.EvictableLRU{Size: txCacheSize},
+
+		fundsIndex: prefixdb.New(fundsPrefix, vm.db),
 	}
 
 	c := codec.NewDefault()
@@ -164,6 +188,17 @@ func (vm *VM) Initialize(
 		}
 	}
 
+	// Registered after the Fx types, so that adding them didn't change the
+	// serialization of existing chains
+	errs.Add(
+		c.RegisterType(&ImportTx{}),
+		c.RegisterType(&ExportTx{}),
+		c.RegisterType(&AssetMetadataTx{}),
+	)
+	if errs.Errored() {
+		return errs.Err
+	}
+
 	vm.codec = c
 
 	if err := vm.initAliases(genesisBytes); err != nil {
@@ -196,23 +231,41 @@ func (vm *VM) Shutdown() {
 	}
 }
 
+// ClearCaches implements the common.CachingVM interface. Unique transactions
+// aren't removed, as consensus may hold references to them.
+func (vm *VM) ClearCaches() {
+	vm.state.state.c.Flush()
+	vm.state.tx.Flush()
+	vm.state.utxo.Flush()
+	vm.state.txStatus.Flush()
+	vm.state.funds.Flush()
+}
+
 // CreateHandlers implements the avalanche.DAGVM interface
 func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
-	rpcServer := rpc.NewServer()
+	rpcServer := cjson.NewServer()
 	codec := cjson.NewCodec()
 	rpcServer.RegisterCodec(codec, "application/json")
 	rpcServer.RegisterCodec(codec, "application/json;charset=UTF-8")
 	rpcServer.RegisterService(&Service{vm: vm}, "avm") // name this service "avm"
 
+	// Signing on a Ledger device waits for the user, so it doesn't hold the
+	// lock while it does
+	ledgerServer := cjson.NewServer()
+	ledgerServer.RegisterCodec(codec, "application/json")
+	ledgerServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	ledgerServer.RegisterService(&LedgerService{vm: vm, open: ledger.Open}, "avm")
+
 	return map[string]*common.HTTPHandler{
 		"":        &common.HTTPHandler{Handler: rpcServer},
+		"/ledger": &common.HTTPHandler{LockOptions: common.NoLock, Handler: ledgerServer},
 		"/pubsub": &common.HTTPHandler{LockOptions: common.NoLock, Handler: vm.pubsub},
 	}
 }
 
 // CreateStaticHandlers implements the avalanche.DAGVM interface
 func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler {
-	newServer := rpc.NewServer()
+	newServer := cjson.NewServer()
 	codec := cjson.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
 	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
@@ -222,18 +275,68 @@ func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler {
 	}
 }
 
+// Metrics implements the avalanche.DAGVM interface
+func (vm *VM) Metrics(registerer prometheus.Registerer) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		vm.metrics.Register(registerer),
+		vm.mempool.RegisterMetrics(registerer),
+	)
+	return errs.Err
+}
+
+// VerifyGenesis implements the common.GenesisVerifier interface. Only genesis
+// data whose initial state uses the secp256k1 feature extension, as built by
+// the static API, can be verified.
+func (vm *VM) VerifyGenesis(genesisData []byte) error {
+	genesis := Genesis{}
+	if err := genesisCodec().Unmarshal(genesisData, &genesis); err != nil {
+		return err
+	}
+	if !genesis.IsSortedAndUnique() {
+		return errGenesisNotSorted
+	}
+	for _, genesisTx := range genesis.Txs {
+		if len(genesisTx.Outs) != 0 {
+			return errGenesisAssetMustHaveState
+		}
+	}
+	return nil
+}
+
 // PendingTxs implements the avalanche.DAGVM interface
 func (vm *VM) PendingTxs() []snowstorm.Tx {
 	vm.timer.Cancel()
 
-	txs := vm.txs
-	vm.txs = nil
+	txs := vm.mempool.Clear()
+	vm.metrics.SetMempoolTxs(0)
 	return txs
 }
 
 // ParseTx implements the avalanche.DAGVM interface
 func (vm *VM) ParseTx(b []byte) (snowstorm.Tx, error) { return vm.parseTx(b) }
 
+// ParseTxs implements the avalanche.BatchedTxParser interface. The credentials
+// of the transactions that haven't been decided are recovered in parallel, so
+// that verifying them when they're issued to consensus is cheap.
+func (vm *VM) ParseTxs(txs [][]byte) ([]snowstorm.Tx, error) {
+	parsed := make([]snowstorm.Tx, len(txs))
+	pending := make([]*UniqueTx, 0, len(txs))
+	for i, b := range txs {
+		tx, err := vm.parseTx(b)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = tx
+		if !tx.Status().Decided() {
+			pending = append(pending, tx)
+		}
+	}
+
+	vm.recoverCredentials(pending, recoveryWorkers(len(pending)))
+	return parsed, nil
+}
+
 // GetTx implements the avalanche.DAGVM interface
 func (vm *VM) GetTx(txID ids.ID) (snowstorm.Tx, error) {
 	tx := &UniqueTx{
@@ -260,10 +363,87 @@ func (vm *VM) IssueTx(b []byte) (ids.ID, error) {
 	if err := tx.Verify(); err != nil {
 		return ids.ID{}, err
 	}
-	vm.issueTx(tx)
+	if err := vm.issueTx(tx); err != nil {
+		return ids.ID{}, err
+	}
 	return tx.ID(), nil
 }
 
+// IssueTxs attempts to issue the ordered batch [txs]. A transaction may spend
+// the outputs of transactions earlier in the batch, but not of later ones, and
+// no two transactions in the batch may spend the same UTXO. If a transaction
+// fails, the transactions in the batch that depend on it fail as well.
+//
+// Returns, for each transaction, its ID and the reason it wasn't issued, if
+// any. The ID is the zero ID if the transaction couldn't be parsed.
+func (vm *VM) IssueTxs(txs [][]byte) ([]ids.ID, []error) {
+	txIDs := make([]ids.ID, len(txs))
+	errs := make([]error, len(txs))
+
+	parsed := make([]*UniqueTx, len(txs))
+	indices := make(map[[32]byte]int, len(txs))
+	for i, b := range txs {
+		tx, err := vm.parseTx(b)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		txID := tx.ID()
+		txIDs[i] = txID
+		if _, exists := indices[txID.Key()]; exists {
+			errs[i] = errDuplicateBatchTx
+			continue
+		}
+		parsed[i] = tx
+		indices[txID.Key()] = i
+	}
+
+	// Verification is sequential, because a transaction may depend on earlier
+	// ones, but the signatures it checks can be recovered in parallel first
+	vm.recoverCredentials(parsed, recoveryWorkers(len(parsed)))
+
+	spent := ids.Set{}
+	for i, tx := range parsed {
+		if tx == nil {
+			continue
+		}
+		if err := vm.verifyBatchDependencies(i, tx, indices, errs, spent); err != nil {
+			errs[i] = err
+			continue
+		}
+		if err := tx.Verify(); err != nil {
+			errs[i] = err
+			continue
+		}
+		if err := vm.issueTx(tx); err != nil {
+			errs[i] = err
+			continue
+		}
+		spent.Union(tx.InputIDs())
+	}
+	return txIDs, errs
+}
+
+// verifyBatchDependencies verifies that [tx], the [i]th transaction of a
+// batch, only depends on transactions earlier in the batch that didn't fail
+// and doesn't spend a UTXO in [spent].
+func (vm *VM) verifyBatchDependencies(i int, tx *UniqueTx, indices map[[32]byte]int, errs []error, spent ids.Set) error {
+	for _, dep := range tx.Dependencies() {
+		j, exists := indices[dep.ID().Key()]
+		switch {
+		case !exists:
+		case j >= i:
+			return errBatchOrder
+		case errs[j] != nil:
+			return errBatchDependencyFailed
+		}
+	}
+	if spent.Overlaps(tx.InputIDs()) {
+		return errBatchConflict
+	}
+	return nil
+}
+
 // GetUTXOs returns the utxos that at least one of the provided addresses is
 // referenced in.
 func (vm *VM) GetUTXOs(addrs ids.Set) ([]*UTXO, error) {
@@ -284,6 +464,51 @@ func (vm *VM) GetUTXOs(addrs ids.Set) ([]*UTXO, error) {
 	return utxos, nil
 }
 
+// GetPaginatedUTXOs returns, in order, at most [limit] of the UTXOs that
+// [addrs] are referenced in. The UTXOs of [addrs[startAddr]] after [startUTXO]
+// are returned first, then those of the addresses after it. If [startUTXO] is
+// the zero ID, all the UTXOs of [addrs[startAddr]] are returned.
+//
+// Also returns the index of the address and the ID of the UTXO that the page
+// ended at, which are where the next page starts. A UTXO that is referenced by
+// several of [addrs] is only returned once per page.
+func (vm *VM) GetPaginatedUTXOs(addrs []ids.ID, startAddr int, startUTXO ids.ID, limit int) ([]*UTXO, int, ids.ID, error) {
+	seen := ids.Set{}
+	utxos := []*UTXO(nil)
+	endAddr, endUTXO := startAddr, startUTXO
+	for i := startAddr; i < len(addrs) && len(utxos) < limit; i++ {
+		start := ids.ID{}
+		if i == startAddr {
+			start = startUTXO
+		}
+		for len(utxos) < limit {
+			numToFetch := limit - len(utxos)
+			utxoIDs, err := vm.state.PaginatedFunds(addrs[i], start, numToFetch)
+			if err != nil {
+				return nil, 0, ids.ID{}, err
+			}
+			for _, utxoID := range utxoIDs {
+				start = utxoID
+				endAddr, endUTXO = i, utxoID
+				if seen.Contains(utxoID) {
+					continue
+				}
+				seen.Add(utxoID)
+
+				utxo, err := vm.state.UTXO(utxoID)
+				if err != nil {
+					return nil, 0, ids.ID{}, err
+				}
+				utxos = append(utxos, utxo)
+			}
+			if len(utxoIDs) < numToFetch {
+				break // There are no more UTXOs of this address
+			}
+		}
+	}
+	return utxos, endAddr, endUTXO, nil
+}
+
 /*
  ******************************************************************************
  *********************************** Fx API ***********************************
@@ -305,7 +530,7 @@ func (vm *VM) Codec() codec.Codec { return vm.codec }
 // FlushTxs into consensus
 func (vm *VM) FlushTxs() {
 	vm.timer.Cancel()
-	if len(vm.txs) != 0 {
+	if vm.mempool.Len() != 0 {
 		select {
 		case vm.toEngine <- common.PendingTxs:
 		default:
@@ -384,6 +609,14 @@ func (vm *VM) initState(genesisBytes []byte) error {
 				return err
 			}
 		}
+
+		addrs, err := vm.txAddresses(nil, tx.UTXOs())
+		if err != nil {
+			return err
+		}
+		if err := vm.indexTx(txID, addrs); err != nil {
+			return err
+		}
 	}
 
 	return vm.state.SetDBInitialized(choices.Processing)
@@ -418,14 +651,39 @@ func (vm *VM) parseTx(b []byte) (*UniqueTx, error) {
 	return tx, nil
 }
 
-func (vm *VM) issueTx(tx snowstorm.Tx) {
-	vm.txs = append(vm.txs, tx)
+// issueTx adds [tx] to the mempool, to be issued to consensus in the next
+// batch
+func (vm *VM) issueTx(tx *UniqueTx) error {
+	burned := uint64(0)
+	if !vm.fees.AssetID.IsZero() {
+		amount, err := tx.t.tx.Burned(vm.fees.AssetID)
+		if err != nil {
+			return err
+		}
+		burned = amount
+	}
+
+	evicted, err := vm.mempool.Add(tx, burned)
+	if err != nil {
+		return err
+	}
+	vm.metrics.SetMempoolTxs(vm.mempool.Len())
+	vm.publishTx("issued", tx, vm.subscriptionKeys(tx))
+	// Evicted transactions are forgotten, so they can be issued again
+	for _, evictedTx := range evicted {
+		vm.ctx.Log.Debug("Evicted tx %s from the mempool", evictedTx.ID())
+		if err := evictedTx.(*UniqueTx).setStatus(choices.Unknown); err != nil {
+			vm.ctx.Log.Error("Failed to forget evicted tx %s due to %s", evictedTx.ID(), err)
+		}
+	}
+
 	switch {
-	case len(vm.txs) == batchSize:
+	case vm.mempool.Len() >= batchSize:
 		vm.FlushTxs()
-	case len(vm.txs) == 1:
+	case vm.mempool.Len() == 1:
 		vm.timer.SetTimeoutIn(vm.batchTimeout)
 	}
+	return nil
 }
 
 func (vm *VM) getFx(val interface{}) (int, error) {
@@ -437,6 +695,77 @@ func (vm *VM) getFx(val interface{}) (int, error) {
 	return fx, nil
 }
 
+// nftFxIndex returns the index of the nftfx among the Fxs this chain supports
+func (vm *VM) nftFxIndex() (uint32, error) {
+	for i, fx := range vm.fxs {
+		if _, ok := fx.Fx.(*nftfx.Fx); ok {
+			return uint32(i), nil
+		}
+	}
+	return 0, errNFTFxNotSupported
+}
+
+// managedFxIndex returns the index of the managedfx among the Fxs this chain
+// supports
+func (vm *VM) managedFxIndex() (uint32, error) {
+	for i, fx := range vm.fxs {
+		if _, ok := fx.Fx.(*managedfx.Fx); ok {
+			return uint32(i), nil
+		}
+	}
+	return 0, errManagedFxNotSupported
+}
+
+// schnorrFxIndex returns the index of the schnorrfx among the Fxs this chain
+// supports
+func (vm *VM) schnorrFxIndex() (uint32, error) {
+	for i, fx := range vm.fxs {
+		if _, ok := fx.Fx.(*schnorrfx.Fx); ok {
+			return uint32(i), nil
+		}
+	}
+	return 0, errSchnorrFxNotSupported
+}
+
+// secpFxIndex returns the index of the secp256k1fx among the Fxs this chain
+// supports
+func (vm *VM) secpFxIndex() (int, error) {
+	for i, fx := range vm.fxs {
+		if _, ok := fx.Fx.(*secp256k1fx.Fx); ok {
+			return i, nil
+		}
+	}
+	return 0, errSECPFxNotSupported
+}
+
+// isManagedState returns true if the Fx of [out] reports that it holds the
+// managed state of its asset
+func (vm *VM) isManagedState(out interface{}) bool {
+	fxIndex, err := vm.getFx(out)
+	if err != nil {
+		return false
+	}
+	manager, ok := vm.fxs[fxIndex].Fx.(FxManager)
+	return ok && manager.IsManagedState(out)
+}
+
+// verifyManagedTransfer verifies that the managed state of [utxo]'s asset
+// allows [utxo] to be spent, if [fx] manages the asset
+func (vm *VM) verifyManagedTransfer(fx Fx, utxo *UTXO) error {
+	manager, ok := fx.(FxManager)
+	if !ok {
+		return nil
+	}
+	state, err := vm.state.ManagedState(utxo.AssetID())
+	if err == database.ErrNotFound {
+		return nil // The asset has no managed state
+	}
+	if err != nil {
+		return err
+	}
+	return manager.VerifyManagedTransfer(state.Out, utxo.Out)
+}
+
 func (vm *VM) verifyFxUsage(fxID int, assetID ids.ID) bool {
 	tx := &UniqueTx{
 		vm:   vm,
@@ -461,12 +790,10 @@ func (vm *VM) verifyFxUsage(fxID int, assetID ids.ID) bool {
 
 // Parse ...
 func (vm *VM) Parse(addrStr string) ([]byte, error) {
-	if count := strings.Count(addrStr, addressSep); count != 1 {
-		return nil, errInvalidAddress
+	bcAlias, rawAddr, err := address.Parse(addrStr, vm.ctx.NetworkID)
+	if err != nil {
+		return nil, err
 	}
-	addressParts := strings.SplitN(addrStr, addressSep, 2)
-	bcAlias := addressParts[0]
-	rawAddr := addressParts[1]
 	bcID, err := vm.ctx.BCLookup.Lookup(bcAlias)
 	if err != nil {
 		bcID, err = ids.FromString(bcAlias)
@@ -477,9 +804,7 @@ func (vm *VM) Parse(addrStr string) ([]byte, error) {
 	if !bcID.Equals(vm.ctx.ChainID) {
 		return nil, errWrongBlockchainID
 	}
-	cb58 := formatting.CB58{}
-	err = cb58.FromString(rawAddr)
-	return cb58.Bytes, err
+	return rawAddr, nil
 }
 
 // Format ...
@@ -490,5 +815,5 @@ func (vm *VM) Format(b []byte) string {
 	} else {
 		bcAlias = vm.ctx.ChainID.String()
 	}
-	return fmt.Sprintf("%s%s%s", bcAlias, addressSep, formatting.CB58{Bytes: b})
+	return address.Format(bcAlias, b)
 }
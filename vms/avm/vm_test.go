@@ -6,6 +6,7 @@ package avm
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/ids"
@@ -16,6 +17,8 @@ import (
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/units"
 	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/verify"
+	"github.com/ava-labs/gecko/vms/nftfx"
 	"github.com/ava-labs/gecko/vms/secp256k1fx"
 )
 
@@ -82,7 +85,7 @@ func GetFirstTxFromGenesisTest(genesisBytes []byte, t *testing.T) *Tx {
 	return nil
 }
 
-func BuildGenesisTest(t *testing.T) []byte {
+func BuildGenesisTest(t testing.TB) []byte {
 	ss := StaticService{}
 
 	addr0 := keys[0].PublicKey().Address()
@@ -547,3 +550,175 @@ func TestGenesisGetUTXOs(t *testing.T) {
 		t.Fatalf("Wrong number of utxos (%d) returned", len(utxos))
 	}
 }
+
+func TestIssueNFT(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	issuer := make(chan common.Message, 1)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		issuer,
+		[]*common.Fx{
+			&common.Fx{
+				ID: ids.Empty.Prefix(0),
+				Fx: &secp256k1fx.Fx{},
+			},
+			&common.Fx{
+				ID: ids.Empty.Prefix(1),
+				Fx: &nftfx.Fx{},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.batchTimeout = time.Hour
+
+	owners := secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{keys[0].PublicKey().Address()},
+	}
+
+	createAssetTx := &Tx{UnsignedTx: &CreateAssetTx{
+		BaseTx: BaseTx{
+			NetID: networkID,
+			BCID:  chainID,
+		},
+		Name:   "Team Rocket",
+		Symbol: "TR",
+		States: []*InitialState{&InitialState{
+			FxID: 1,
+			Outs: []verify.Verifiable{
+				&nftfx.MintOutput{
+					GroupID:      1,
+					OutputOwners: owners,
+				},
+			},
+		}},
+	}}
+	b, err := vm.codec.Marshal(createAssetTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	createAssetTx.Initialize(b)
+
+	if _, err := vm.IssueTx(createAssetTx.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	parsedCreateAssetTx, err := vm.ParseTx(createAssetTx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedCreateAssetTx.Accept()
+
+	signNFTTx := func(tx *Tx) {
+		unsignedBytes, err := vm.codec.Marshal(&tx.UnsignedTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := keys[0].Sign(unsignedBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixedSig := [crypto.SECP256K1RSigLen]byte{}
+		copy(fixedSig[:], sig)
+
+		tx.Creds = append(tx.Creds, &Credential{
+			Cred: &nftfx.Credential{Credential: secp256k1fx.Credential{
+				Sigs: [][crypto.SECP256K1RSigLen]byte{
+					fixedSig,
+				},
+			}},
+		})
+		b, err := vm.codec.Marshal(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.Initialize(b)
+	}
+
+	mintTx := &Tx{UnsignedTx: &OperationTx{
+		BaseTx: BaseTx{
+			NetID: networkID,
+			BCID:  chainID,
+		},
+		Ops: []*Operation{&Operation{
+			Asset: Asset{ID: createAssetTx.ID()},
+			Ins: []*OperableInput{&OperableInput{
+				UTXOID: UTXOID{
+					TxID:        createAssetTx.ID(),
+					OutputIndex: 0,
+				},
+				In: &nftfx.MintInput{
+					Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+				},
+			}},
+			Outs: []*OperableOutput{
+				&OperableOutput{
+					Out: &nftfx.MintOutput{
+						GroupID:      1,
+						OutputOwners: owners,
+					},
+				},
+				&OperableOutput{
+					Out: &nftfx.TransferOutput{
+						GroupID:      1,
+						Payload:      []byte{'h', 'e', 'l', 'l', 'o'},
+						OutputOwners: owners,
+					},
+				},
+			},
+		}},
+	}}
+	signNFTTx(mintTx)
+
+	if _, err := vm.IssueTx(mintTx.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	parsedMintTx, err := vm.ParseTx(mintTx.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedMintTx.Accept()
+
+	transferTx := &Tx{UnsignedTx: &OperationTx{
+		BaseTx: BaseTx{
+			NetID: networkID,
+			BCID:  chainID,
+		},
+		Ops: []*Operation{&Operation{
+			Asset: Asset{ID: createAssetTx.ID()},
+			Ins: []*OperableInput{&OperableInput{
+				UTXOID: UTXOID{
+					TxID:        mintTx.ID(),
+					OutputIndex: 1,
+				},
+				In: &nftfx.TransferInput{
+					Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+				},
+			}},
+			Outs: []*OperableOutput{&OperableOutput{
+				Out: &nftfx.TransferOutput{
+					GroupID: 1,
+					Payload: []byte{'h', 'e', 'l', 'l', 'o'},
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{keys[1].PublicKey().Address()},
+					},
+				},
+			}},
+		}},
+	}}
+	signNFTTx(transferTx)
+
+	if _, err := vm.IssueTx(transferTx.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
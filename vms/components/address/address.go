@@ -0,0 +1,157 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package address converts between the representations of the address that a
+// key controls.
+//
+// A key's address is the same on every chain. The P-Chain represents it on its
+// own, such as 6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV. Chains that run the AVM
+// prefix it with the alias of the chain, such as
+// X-6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV.
+//
+// Addresses are formatted in CB58, but may also be given in bech32, with the
+// human-readable part (HRP) of the network they're on, such as
+// X-local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u. Bech32 catches mistyped
+// addresses, and addresses meant for another network.
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+// Separator is between the chain alias and the address of a chain address
+const Separator = "-"
+
+// HRPs of the bech32 addresses on each network
+const (
+	MainnetHRP  = "ava"
+	TestnetHRP  = "borealis"
+	LocalHRP    = "local"
+	FallbackHRP = "custom"
+)
+
+var (
+	// Key: The ID of a network, as given by the genesis package
+	// Value: The HRP of the network's bech32 addresses
+	networkHRPs = map[uint32]string{
+		1:     MainnetHRP,
+		2:     TestnetHRP,
+		12345: LocalHRP,
+	}
+
+	errInvalidAddress = errors.New("invalid address")
+	errNoChain        = errors.New("address has no chain alias")
+	errWrongNetwork   = errors.New("address is on the wrong network")
+
+	factory = crypto.FactorySECP256K1R{}
+)
+
+// HRP returns the HRP of bech32 addresses on the network [networkID]
+func HRP(networkID uint32) string {
+	if hrp, exists := networkHRPs[networkID]; exists {
+		return hrp
+	}
+	return FallbackHRP
+}
+
+// Format returns [addr] on the chain whose alias is [chainAlias]
+func Format(chainAlias string, addr []byte) string {
+	return fmt.Sprintf("%s%s%s", chainAlias, Separator, formatting.CB58{Bytes: addr})
+}
+
+// FormatBech32 returns [addr] in bech32 on the chain whose alias is
+// [chainAlias], on the network [networkID]
+func FormatBech32(chainAlias string, networkID uint32, addr []byte) (string, error) {
+	bech32, err := formatting.Bech32Encode(HRP(networkID), addr)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s%s", chainAlias, Separator, bech32), nil
+}
+
+// Parse returns the chain alias and the address of the chain address
+// [addrStr], on the network [networkID]
+func Parse(addrStr string, networkID uint32) (string, []byte, error) {
+	if count := strings.Count(addrStr, Separator); count != 1 {
+		return "", nil, errInvalidAddress
+	}
+	addressParts := strings.SplitN(addrStr, Separator, 2)
+	if addressParts[0] == "" {
+		return "", nil, errNoChain
+	}
+	addrBytes, err := parseBytes(addressParts[1], networkID)
+	return addressParts[0], addrBytes, err
+}
+
+// ParseAny returns the address [addrStr], on the network [networkID], which
+// may or may not be prefixed with a chain alias. The chain alias is empty if
+// there's no prefix.
+func ParseAny(addrStr string, networkID uint32) (string, ids.ShortID, error) {
+	chainAlias := ""
+	addrBytes := []byte(nil)
+	if strings.Contains(addrStr, Separator) {
+		var err error
+		chainAlias, addrBytes, err = Parse(addrStr, networkID)
+		if err != nil {
+			return "", ids.ShortID{}, err
+		}
+	} else {
+		var err error
+		addrBytes, err = parseBytes(addrStr, networkID)
+		if err != nil {
+			return "", ids.ShortID{}, err
+		}
+	}
+	addr, err := ids.ToShortID(addrBytes)
+	return chainAlias, addr, err
+}
+
+// parseBytes returns the bytes of the address [addrStr], which is in CB58 or,
+// if it has the HRP of the network [networkID], in bech32
+func parseBytes(addrStr string, networkID uint32) ([]byte, error) {
+	cb58 := formatting.CB58{}
+	cb58Err := cb58.FromString(addrStr)
+	if cb58Err == nil {
+		return cb58.Bytes, nil
+	}
+
+	hrp, addrBytes, err := formatting.Bech32Decode(addrStr)
+	switch {
+	case err != nil:
+		// The address is in neither format. Assume it's meant to be CB58
+		// unless it looks like bech32.
+		if strings.ContainsRune(addrStr, '1') && (strings.ToLower(addrStr) == addrStr || strings.ToUpper(addrStr) == addrStr) {
+			return nil, err
+		}
+		return nil, cb58Err
+	case hrp != HRP(networkID):
+		return nil, fmt.Errorf("%w: the address is on network %q, not %q", errWrongNetwork, hrp, HRP(networkID))
+	}
+	return addrBytes, nil
+}
+
+// FromPublicKey returns the address controlled by the SECP256K1 public key
+// [pkBytes]
+func FromPublicKey(pkBytes []byte) (ids.ShortID, error) {
+	pk, err := factory.ToPublicKey(pkBytes)
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	return pk.Address(), nil
+}
+
+// FromPrivateKey returns the address controlled by the SECP256K1 private key
+// [skBytes]
+func FromPrivateKey(skBytes []byte) (ids.ShortID, error) {
+	sk, err := factory.ToPrivateKey(skBytes)
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	return sk.PublicKey().Address(), nil
+}
@@ -0,0 +1,129 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package address
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+)
+
+func TestFormatParse(t *testing.T) {
+	addr := ids.NewShortID([20]byte{1, 2, 3})
+
+	addrStr := Format("X", addr.Bytes())
+	if addrStr != "X-"+addr.String() {
+		t.Fatalf("formatted address as %s", addrStr)
+	}
+	chainAlias, addrBytes, err := Parse(addrStr, 12345)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case chainAlias != "X":
+		t.Fatalf("parsed chain alias %s", chainAlias)
+	case !bytes.Equal(addrBytes, addr.Bytes()):
+		t.Fatalf("parsed the wrong address")
+	}
+
+	for _, invalid := range []string{addr.String(), "-" + addr.String(), "X-Y-" + addr.String(), "X-0"} {
+		if _, _, err := Parse(invalid, 12345); err == nil {
+			t.Fatalf("should have failed to parse %s", invalid)
+		}
+	}
+}
+
+func TestParseAny(t *testing.T) {
+	addr := ids.NewShortID([20]byte{1, 2, 3})
+
+	chainAlias, parsed, err := ParseAny(Format("X", addr.Bytes()), 12345)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case chainAlias != "X":
+		t.Fatalf("parsed chain alias %s", chainAlias)
+	case !parsed.Equals(addr):
+		t.Fatalf("parsed the wrong address")
+	}
+
+	chainAlias, parsed, err = ParseAny(addr.String(), 12345)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case chainAlias != "":
+		t.Fatalf("parsed chain alias %s from an address without one", chainAlias)
+	case !parsed.Equals(addr):
+		t.Fatalf("parsed the wrong address")
+	}
+
+	if _, _, err := ParseAny(Format("X", []byte{1, 2, 3}), 12345); err == nil {
+		t.Fatalf("should have failed to parse an address of the wrong length")
+	}
+}
+
+func TestBech32(t *testing.T) {
+	addr, err := ids.ShortFromString("6Y3kysjF9jnHnYkdS9yGAuoHyae2eNmeV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrStr, err := FormatBech32("X", 12345, addr.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "X-local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u"; addrStr != expected {
+		t.Fatalf("formatted address as %s but should have formatted it as %s", addrStr, expected)
+	}
+
+	for _, valid := range []string{addrStr, strings.ToUpper(addrStr), "local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u"} {
+		if _, parsed, err := ParseAny(valid, 12345); err != nil {
+			t.Fatal(err)
+		} else if !parsed.Equals(addr) {
+			t.Fatalf("parsed the wrong address from %s", valid)
+		}
+	}
+
+	if _, _, err := Parse(addrStr, 1); !errors.Is(err, errWrongNetwork) {
+		t.Fatalf("should have failed with %s but failed with %v", errWrongNetwork, err)
+	}
+	mistyped := strings.Replace(addrStr, "8jma", "8jna", 1)
+	if _, _, err := Parse(mistyped, 12345); err == nil {
+		t.Fatalf("should have failed to parse the mistyped address %s", mistyped)
+	}
+
+	if hrp := HRP(4321); hrp != FallbackHRP {
+		t.Fatalf("unknown networks should have HRP %s but have %s", FallbackHRP, hrp)
+	}
+}
+
+func TestFromKeys(t *testing.T) {
+	factory := crypto.FactorySECP256K1R{}
+	skIntf, err := factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk := skIntf.(*crypto.PrivateKeySECP256K1R)
+
+	addr, err := FromPrivateKey(sk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !addr.Equals(sk.PublicKey().Address()) {
+		t.Fatalf("derived the wrong address from the private key")
+	}
+
+	addr, err = FromPublicKey(sk.PublicKey().Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !addr.Equals(sk.PublicKey().Address()) {
+		t.Fatalf("derived the wrong address from the public key")
+	}
+
+	if _, err := FromPublicKey([]byte{1, 2, 3}); err == nil {
+		t.Fatalf("should have failed to parse an invalid public key")
+	}
+}
@@ -6,7 +6,10 @@ package codec
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/ava-labs/gecko/utils/wrappers"
@@ -43,6 +46,8 @@ var (
 	errUnmarshalUnexportedField  = errors.New("can't deserialize into an unexported field")
 	errOutOfMemory               = errors.New("out of memory")
 	errSliceTooLarge             = errors.New("slice too large")
+	errInsufficientBytes         = errors.New("slice has more elements than the remaining bytes could hold")
+	errInvalidVersionTag         = errors.New("version tag should be a version, such as \"1\", or a range of versions, such as \"1-3\"")
 )
 
 // Verify that the codec is a known codec value. Returns nil if the codec is
@@ -71,6 +76,7 @@ func (c Type) String() string {
 
 // Codec handles marshaling and unmarshaling of structs
 type codec struct {
+	version     uint16 // Fields are only serialized if they exist in this version
 	maxSize     int
 	maxSliceLen int
 
@@ -86,8 +92,13 @@ type Codec interface {
 }
 
 // New returns a new codec
-func New(maxSize, maxSliceLen int) Codec {
+func New(maxSize, maxSliceLen int) Codec { return NewVersioned(0, maxSize, maxSliceLen) }
+
+// NewVersioned returns a new codec that serializes the fields that exist in
+// version [version] of the structs it serializes
+func NewVersioned(version uint16, maxSize, maxSliceLen int) Codec {
 	return codec{
+		version:      version,
 		maxSize:      maxSize,
 		maxSliceLen:  maxSliceLen,
 		typeIDToType: map[uint32]reflect.Type{},
@@ -98,6 +109,12 @@ func New(maxSize, maxSliceLen int) Codec {
 // NewDefault returns a new codec with reasonable default values
 func NewDefault() Codec { return New(defaultMaxSize, defaultMaxSliceLength) }
 
+// NewDefaultVersioned returns a new codec of version [version] with reasonable
+// default values
+func NewDefaultVersioned(version uint16) Codec {
+	return NewVersioned(version, defaultMaxSize, defaultMaxSliceLength)
+}
+
 // RegisterType is used to register types that may be unmarshaled into an interface typed value
 // [val] is a value of the type being registered
 func (c codec) RegisterType(val interface{}) error {
@@ -123,6 +140,9 @@ func (c codec) RegisterType(val interface{}) error {
 //    you must call codec.RegisterType([instance of the type that fulfills the interface]).
 // 7) nil slices will be unmarshaled as an empty slice of the appropriate type
 // 8) Serialized fields must be exported
+// 9) To add a field in a version of a struct, also add the tag `version:"[first version]"`
+//    to it. To remove a field in a version, tag it with `version:"[first version]-[last version]"`.
+//    Untagged fields exist in every version. A codec only serializes the fields in its version.
 
 // Marshal returns the byte representation of [value]
 // If you want to marshal an interface, [value] must be a pointer
@@ -213,7 +233,11 @@ func (c codec) marshal(value reflect.Value) ([]byte, error) {
 	case reflect.Struct:
 		for i := 0; i < t.NumField(); i++ { // Go through all fields of this struct
 			field := t.Field(i)
-			if !shouldSerialize(field) { // Skip fields we don't need to serialize
+			serialize, err := c.shouldSerialize(field)
+			if err != nil {
+				return nil, err
+			}
+			if !serialize { // Skip fields we don't need to serialize
 				continue
 			}
 			if unicode.IsLower(rune(field.Name[0])) { // Can only marshal exported fields
@@ -299,6 +323,15 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 			return errSliceTooLarge
 		}
 
+		// Don't allocate a slice that the remaining bytes couldn't fill, so
+		// that a length prefix can't make us allocate more than was sent
+		if elemSize := c.minSize(field.Type().Elem(), map[reflect.Type]bool{}); elemSize > 0 {
+			if remaining := len(p.Bytes) - p.Offset; sliceLen > remaining/elemSize {
+				return fmt.Errorf("%w: %d elements of at least %d bytes each but %d bytes remain",
+					errInsufficientBytes, sliceLen, elemSize, remaining)
+			}
+		}
+
 		// First set [field] to be a slice of the appropriate type/capacity (right now [field] is nil)
 		slice := reflect.MakeSlice(field.Type(), sliceLen, sliceLen)
 		field.Set(slice)
@@ -337,7 +370,11 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 		// Go through all the fields and umarshal into each
 		for i := 0; i < structType.NumField(); i++ {
 			structField := structType.Field(i)
-			if !shouldSerialize(structField) { // Skip fields we don't need to unmarshal
+			serialize, err := c.shouldSerialize(structField)
+			if err != nil {
+				return err
+			}
+			if !serialize { // Skip fields we don't need to unmarshal
 				continue
 			}
 			if unicode.IsLower(rune(structField.Name[0])) { // Only unmarshal into exported field
@@ -370,10 +407,72 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 	return p.Err
 }
 
-// Returns true iff [field] should be serialized
-func shouldSerialize(field reflect.StructField) bool {
-	if field.Tag.Get("serialize") == "true" {
-		return true
+// minSize returns the fewest bytes that a value of type [typ] can be
+// serialized in. [visiting] holds the types whose size is being computed, so
+// that recursive types are counted as having no minimum size.
+func (c codec) minSize(typ reflect.Type, visiting map[reflect.Type]bool) int {
+	switch typ.Kind() {
+	case reflect.Uint8, reflect.Int8, reflect.Bool:
+		return wrappers.ByteLen
+	case reflect.Uint16, reflect.Int16, reflect.String:
+		return wrappers.ShortLen
+	case reflect.Uint32, reflect.Int32, reflect.Slice, reflect.Interface:
+		return wrappers.IntLen
+	case reflect.Uint64, reflect.Int64:
+		return wrappers.LongLen
+	case reflect.Array:
+		return typ.Len() * c.minSize(typ.Elem(), visiting)
+	case reflect.Ptr:
+		return c.minSize(typ.Elem(), visiting)
+	case reflect.Struct:
+		if visiting[typ] {
+			return 0
+		}
+		visiting[typ] = true
+		defer delete(visiting, typ)
+
+		size := 0
+		for i := 0; i < typ.NumField(); i++ {
+			if serialize, err := c.shouldSerialize(typ.Field(i)); err == nil && serialize {
+				size += c.minSize(typ.Field(i).Type, visiting)
+			}
+		}
+		return size
+	default:
+		return 0
+	}
+}
+
+// Returns true iff [field] should be serialized in this codec's version
+func (c codec) shouldSerialize(field reflect.StructField) (bool, error) {
+	if field.Tag.Get("serialize") != "true" {
+		return false, nil
+	}
+	versionTag, tagged := field.Tag.Lookup("version")
+	if !tagged {
+		return true, nil
+	}
+	first, last, err := parseVersionTag(versionTag)
+	if err != nil {
+		return false, fmt.Errorf("field %s: %w", field.Name, err)
+	}
+	return first <= c.version && c.version <= last, nil
+}
+
+// parseVersionTag returns the first and last versions that a field with the
+// version tag [tag] exists in
+func parseVersionTag(tag string) (uint16, uint16, error) {
+	fields := strings.SplitN(tag, "-", 2)
+	first, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return 0, 0, errInvalidVersionTag
+	}
+	if len(fields) == 1 {
+		return uint16(first), math.MaxUint16, nil
+	}
+	last, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil || last < first {
+		return 0, 0, errInvalidVersionTag
 	}
-	return false
+	return uint16(first), uint16(last), nil
 }
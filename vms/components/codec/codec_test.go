@@ -5,6 +5,7 @@ package codec
 
 import (
 	"bytes"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -492,6 +493,40 @@ func TestOutOfMemory(t *testing.T) {
 	}
 }
 
+// Ensure a slice length that the remaining bytes can't fill errors before the
+// slice is allocated
+func TestSliceLongerThanBytes(t *testing.T) {
+	type element struct {
+		Long  uint64    `serialize:"true"`
+		Short [2]uint16 `serialize:"true"`
+	}
+	codec := NewDefault()
+
+	// Claims 1000 elements of 12 bytes each but only has 2 elements' worth
+	b := []byte{0x00, 0x00, 0x03, 0xE8}
+	b = append(b, make([]byte, 24)...)
+	val := []element{}
+	if err := codec.Unmarshal(b, &val); !errors.Is(err, errInsufficientBytes) {
+		t.Fatalf("should have failed with %s but failed with %v", errInsufficientBytes, err)
+	}
+
+	// Exactly enough bytes unmarshal
+	b[2], b[3] = 0x00, 0x02
+	if err := codec.Unmarshal(b, &val); err != nil {
+		t.Fatal(err)
+	} else if len(val) != 2 {
+		t.Fatalf("should have unmarshalled 2 elements but unmarshalled %d", len(val))
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		strs := []string{}
+		_ = codec.Unmarshal([]byte{0x00, 0x03, 0xFF, 0xFF}, &strs)
+	})
+	if allocs > 5 {
+		t.Fatalf("rejecting a slice that's too long made %v allocations", allocs)
+	}
+}
+
 // Ensure serializing structs with negative number members works
 func TestNegativeNumbers(t *testing.T) {
 	type s struct {
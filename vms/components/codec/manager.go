@@ -0,0 +1,177 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// versionSize is the size, in bytes, of the version that bytes marshalled by
+// a Manager start with
+const versionSize = wrappers.ShortLen
+
+// Unversioned is the version Unmarshal returns for bytes that were
+// unmarshalled by the legacy codec. It can't be registered as a version.
+const Unversioned = math.MaxUint16
+
+var (
+	errNoCodecs         = errors.New("no codec has been registered")
+	errUnknownVersion   = errors.New("unknown codec version")
+	errDuplicateVersion = errors.New("codec version has already been registered")
+	errMissingVersion   = errors.New("bytes are too short to have a codec version")
+	errReservedVersion  = errors.New("codec version is reserved for unversioned bytes")
+)
+
+// Manager marshals with one of several versions of a codec. The bytes it
+// marshals start with the version of the codec that marshalled them, so they
+// can still be unmarshalled after newer versions are registered.
+//
+// Each version's codec should be created with NewVersioned, so it serializes
+// the fields that exist in that version.
+type Manager struct {
+	lock sync.RWMutex
+
+	// Key: A version
+	// Value: The codec of that version
+	codecs map[uint16]Codec
+
+	// The newest version. Marshal uses it.
+	latest uint16
+
+	// If non-nil, unmarshals bytes that were marshalled, without a version,
+	// before the codec was versioned
+	legacy Codec
+}
+
+// NewManager returns a manager without any codecs
+func NewManager() *Manager {
+	return &Manager{codecs: make(map[uint16]Codec)}
+}
+
+// RegisterCodec registers [codec] as version [version]
+func (m *Manager) RegisterCodec(version uint16, codec Codec) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if version == Unversioned {
+		return fmt.Errorf("%w: %d", errReservedVersion, version)
+	}
+	if _, exists := m.codecs[version]; exists {
+		return fmt.Errorf("%w: %d", errDuplicateVersion, version)
+	}
+	if len(m.codecs) == 0 || version > m.latest {
+		m.latest = version
+	}
+	m.codecs[version] = codec
+	return nil
+}
+
+// RegisterLegacyCodec registers [codec] as the codec that marshalled bytes
+// before they were versioned. Unmarshal falls back to it for bytes that no
+// registered version can unmarshal, so data stored before the codec was
+// versioned can still be read. Marshal never uses it.
+func (m *Manager) RegisterLegacyCodec(codec Codec) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.legacy = codec
+}
+
+// Marshal returns the byte representation of [value] in the newest version
+func (m *Manager) Marshal(value interface{}) ([]byte, error) {
+	m.lock.RLock()
+	latest, registered := m.latest, len(m.codecs) != 0
+	m.lock.RUnlock()
+
+	if !registered {
+		return nil, errNoCodecs
+	}
+	return m.MarshalVersion(latest, value)
+}
+
+// MarshalVersion returns the byte representation of [value] in version
+// [version]
+func (m *Manager) MarshalVersion(version uint16, value interface{}) ([]byte, error) {
+	codec, err := m.codec(version)
+	if err != nil {
+		return nil, err
+	}
+	valueBytes, err := codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	size := versionSize + len(valueBytes)
+	p := wrappers.Packer{MaxSize: size, Bytes: make([]byte, 0, size)}
+	p.PackShort(version)
+	p.PackFixedBytes(valueBytes)
+	return p.Bytes, p.Err
+}
+
+// Unmarshal unmarshals [bytes] into [dest], with the version of the codec that
+// marshalled them, and returns that version. If that fails and a legacy codec
+// is registered, [dest] is reset and [bytes] are unmarshalled with the legacy
+// codec instead, in which case Unversioned is returned.
+func (m *Manager) Unmarshal(bytes []byte, dest interface{}) (uint16, error) {
+	version, err := m.unmarshalVersioned(bytes, dest)
+	if err == nil {
+		return version, nil
+	}
+
+	m.lock.RLock()
+	legacy := m.legacy
+	m.lock.RUnlock()
+
+	if legacy == nil {
+		return 0, err
+	}
+	if destPtr := reflect.ValueOf(dest); destPtr.Kind() == reflect.Ptr && !destPtr.IsNil() {
+		destPtr.Elem().Set(reflect.Zero(destPtr.Elem().Type()))
+	}
+	if legacyErr := legacy.Unmarshal(bytes, dest); legacyErr != nil {
+		return 0, fmt.Errorf("couldn't unmarshal versioned (%s) or legacy (%s) bytes", err, legacyErr)
+	}
+	return Unversioned, nil
+}
+
+// unmarshalVersioned unmarshals [bytes] into [dest] with the registered
+// version that marshalled them
+func (m *Manager) unmarshalVersioned(bytes []byte, dest interface{}) (uint16, error) {
+	version, err := Version(bytes)
+	if err != nil {
+		return 0, err
+	}
+	codec, err := m.codec(version)
+	if err != nil {
+		return 0, err
+	}
+	return version, codec.Unmarshal(bytes[versionSize:], dest)
+}
+
+// Version returns the version of the codec that marshalled [bytes]
+func Version(bytes []byte) (uint16, error) {
+	if len(bytes) < versionSize {
+		return 0, errMissingVersion
+	}
+	p := wrappers.Packer{Bytes: bytes}
+	return p.UnpackShort(), nil
+}
+
+// codec returns the codec of version [version]
+func (m *Manager) codec(version uint16) (Codec, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	codec, exists := m.codecs[version]
+	if !exists {
+		return nil, fmt.Errorf("%w: %d", errUnknownVersion, version)
+	}
+	return codec, nil
+}
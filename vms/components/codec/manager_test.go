@@ -0,0 +1,156 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// versionedStruct gained [Added] in version 1 and lost [Removed] in version 2
+type versionedStruct struct {
+	Kept    uint32 `serialize:"true"`
+	Added   uint32 `serialize:"true" version:"1"`
+	Removed uint32 `serialize:"true" version:"0-1"`
+}
+
+func TestVersionedFields(t *testing.T) {
+	value := versionedStruct{Kept: 1, Added: 2, Removed: 3}
+	expected := map[uint16][]byte{
+		0: []byte{0, 0, 0, 1, 0, 0, 0, 3},
+		1: []byte{0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 3},
+		2: []byte{0, 0, 0, 1, 0, 0, 0, 2},
+	}
+	for version, expectedBytes := range expected {
+		c := NewVersioned(version, defaultMaxSize, defaultMaxSliceLength)
+		valueBytes, err := c.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(valueBytes, expectedBytes) {
+			t.Fatalf("version %d should have marshalled 0x%x but marshalled 0x%x", version, expectedBytes, valueBytes)
+		}
+	}
+
+	// Untagged fields are serialized by the default codec, which is version 0
+	valueBytes, err := NewDefault().Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(valueBytes, expected[0]) {
+		t.Fatalf("the default codec should have marshalled 0x%x but marshalled 0x%x", expected[0], valueBytes)
+	}
+
+	invalid := struct {
+		Field uint32 `serialize:"true" version:"2-1"`
+	}{}
+	if _, err := NewDefault().Marshal(invalid); !errors.Is(err, errInvalidVersionTag) {
+		t.Fatalf("should have failed with %s but failed with %v", errInvalidVersionTag, err)
+	}
+}
+
+func TestManager(t *testing.T) {
+	m := NewManager()
+	if _, err := m.Marshal(versionedStruct{}); err != errNoCodecs {
+		t.Fatalf("should have failed with %s but failed with %v", errNoCodecs, err)
+	}
+
+	for _, version := range []uint16{1, 0} {
+		if err := m.RegisterCodec(version, NewVersioned(version, defaultMaxSize, defaultMaxSliceLength)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.RegisterCodec(1, NewDefault()); !errors.Is(err, errDuplicateVersion) {
+		t.Fatalf("should have failed with %s but failed with %v", errDuplicateVersion, err)
+	}
+
+	value := versionedStruct{Kept: 1, Added: 2, Removed: 3}
+	oldBytes, err := m.MarshalVersion(0, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newBytes, err := m.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := Version(newBytes); err != nil {
+		t.Fatal(err)
+	} else if version != 1 {
+		t.Fatalf("should have marshalled with the newest version, 1, but marshalled with %d", version)
+	}
+
+	// Bytes marshalled by an older version are unmarshalled with that version
+	parsed := versionedStruct{}
+	if version, err := m.Unmarshal(oldBytes, &parsed); err != nil {
+		t.Fatal(err)
+	} else if version != 0 {
+		t.Fatalf("should have unmarshalled with version 0 but unmarshalled with %d", version)
+	}
+	if expected := (versionedStruct{Kept: 1, Removed: 3}); parsed != expected {
+		t.Fatalf("unmarshalled %+v but should have unmarshalled %+v", parsed, expected)
+	}
+
+	parsed = versionedStruct{}
+	if _, err := m.Unmarshal(newBytes, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed != value {
+		t.Fatalf("unmarshalled %+v but should have unmarshalled %+v", parsed, value)
+	}
+
+	if _, err := m.Unmarshal([]byte{0, 2, 0, 0, 0, 1}, &parsed); !errors.Is(err, errUnknownVersion) {
+		t.Fatalf("should have failed with %s but failed with %v", errUnknownVersion, err)
+	}
+	if _, err := m.Unmarshal([]byte{0}, &parsed); err != errMissingVersion {
+		t.Fatalf("should have failed with %s but failed with %v", errMissingVersion, err)
+	}
+	if err := m.RegisterCodec(Unversioned, NewDefault()); !errors.Is(err, errReservedVersion) {
+		t.Fatalf("should have failed with %s but failed with %v", errReservedVersion, err)
+	}
+}
+
+func TestManagerLegacyCodec(t *testing.T) {
+	m := NewManager()
+	if err := m.RegisterCodec(1, NewDefaultVersioned(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Marshalled before the codec was versioned, so there's no version prefix
+	legacy := NewDefault()
+	value := versionedStruct{Kept: 1, Removed: 3}
+	legacyBytes, err := legacy.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(legacyBytes, &versionedStruct{}); err == nil {
+		t.Fatal("should have failed without a legacy codec")
+	}
+
+	m.RegisterLegacyCodec(legacy)
+	parsed := versionedStruct{Added: 5}
+	if version, err := m.Unmarshal(legacyBytes, &parsed); err != nil {
+		t.Fatal(err)
+	} else if version != Unversioned {
+		t.Fatalf("should have unmarshalled with the legacy codec but unmarshalled with version %d", version)
+	}
+	if parsed != value {
+		t.Fatalf("unmarshalled %+v but should have unmarshalled %+v", parsed, value)
+	}
+
+	// Versioned bytes are still unmarshalled with their version
+	newBytes, err := m.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := m.Unmarshal(newBytes, &parsed); err != nil {
+		t.Fatal(err)
+	} else if version != 1 {
+		t.Fatalf("should have unmarshalled with version 1 but unmarshalled with %d", version)
+	}
+
+	if _, err := m.Unmarshal([]byte{0xff}, &parsed); err == nil {
+		t.Fatal("should have failed to unmarshal with either codec")
+	}
+}
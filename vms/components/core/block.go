@@ -53,11 +53,22 @@ func (b *Block) Parent() snowman.Block {
 // Accept sets this block's status to Accepted and sets lastAccepted to this
 // block's ID and saves this info to b.vm.DB
 // Recall that b.vm.DB.Commit() must be called to persist to the DB
+// The state after that commit is checkpointed as this block's ID
+// If the VM summarizes its state in epochs, the block's changes must already be
+// written to the state
 func (b *Block) Accept() {
 	b.SetStatus(choices.Accepted)                           // Change state of this block
 	b.VM.State.PutStatus(b.VM.DB, b.ID(), choices.Accepted) // Persist data
 	b.VM.State.PutLastAccepted(b.VM.DB, b.ID())
 	b.VM.lastAccepted = b.ID() // Change state of VM
+	if b.VM.checkpoints != nil {
+		b.VM.checkpoints.Checkpoint(b.ID())
+	}
+	if b.VM.Epochs != nil {
+		if err := b.VM.Epochs.Accept(b.ID()); err != nil {
+			b.VM.Ctx.Log.Error("Failed to record block %s in its epoch due to %s", b.ID(), err)
+		}
+	}
 }
 
 // Reject sets this block's status to Rejected and saves the status in state
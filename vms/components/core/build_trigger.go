@@ -0,0 +1,134 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+const (
+	// minRetryDelay is how long the trigger waits before notifying the engine
+	// again if transactions are still pending
+	minRetryDelay = 50 * time.Millisecond
+
+	// maxRetryDelay is the longest the trigger waits before notifying the
+	// engine again. The delay doubles each time the engine is notified but
+	// doesn't build a block, such as while it's bootstrapping.
+	maxRetryDelay = 5 * time.Second
+)
+
+// BuildTrigger tells the consensus engine when a Snowman VM's mempool has
+// transactions to build a block with.
+//
+// The VM calls NotifyBuildBlock when transactions arrive, and BuiltBlock when
+// the engine calls BuildBlock. Notifications for a burst of transactions are
+// coalesced, so the engine isn't flooded with messages it would drop. Until the
+// VM reports that no transactions remain, the engine is notified again after a
+// delay, so transactions don't stall if a notification was dropped or a block
+// couldn't be built.
+type BuildTrigger struct {
+	lock     sync.Mutex
+	log      logging.Logger
+	toEngine chan<- common.Message
+	timer    *timer.Timer
+
+	// How long to wait for more transactions before the engine is notified
+	batchDelay time.Duration
+
+	// How long to wait before the engine is notified again
+	retryDelay time.Duration
+
+	// True if the VM has transactions to build a block with
+	pending bool
+
+	// True if the timer is set to notify the engine
+	scheduled bool
+}
+
+// Initialize the trigger to notify the engine over [toEngine]. Once
+// transactions arrive, the engine is notified after [batchDelay], so that
+// transactions arriving in a burst are put into the same block. If
+// [batchDelay] is 0, the engine is notified as soon as transactions arrive.
+func (t *BuildTrigger) Initialize(log logging.Logger, toEngine chan<- common.Message, batchDelay time.Duration) {
+	t.log = log
+	t.toEngine = toEngine
+	t.batchDelay = batchDelay
+	t.retryDelay = minRetryDelay
+	t.pending = false
+	t.scheduled = false
+	t.timer = timer.NewTimer(t.fire)
+	go log.RecoverAndPanic(t.timer.Dispatch)
+}
+
+// NotifyBuildBlock tells the trigger that transactions arrived
+func (t *BuildTrigger) NotifyBuildBlock() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.pending = true
+	t.retryDelay = minRetryDelay
+	switch {
+	case t.scheduled:
+		// The engine will be notified of these transactions too
+	case t.batchDelay == 0:
+		t.notify()
+	default:
+		t.scheduled = true
+		t.timer.SetTimeoutIn(t.batchDelay)
+	}
+}
+
+// BuiltBlock tells the trigger that the engine asked the VM to build a block.
+// [remaining] is true if transactions are left for another block.
+func (t *BuildTrigger) BuiltBlock(remaining bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.pending = remaining
+	t.retryDelay = minRetryDelay
+	if remaining {
+		t.scheduled = true
+		t.timer.SetTimeoutIn(t.retryDelay)
+	} else {
+		t.scheduled = false
+		t.timer.Cancel()
+	}
+}
+
+// Shutdown stops the trigger from notifying the engine
+func (t *BuildTrigger) Shutdown() { t.timer.Stop() }
+
+func (t *BuildTrigger) fire() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.pending {
+		t.scheduled = false
+		return
+	}
+	t.notify()
+}
+
+// notify the engine that a block can be built, and make sure it's notified
+// again if the transactions are still pending later. Assumes the lock is held.
+func (t *BuildTrigger) notify() {
+	select {
+	case t.toEngine <- common.PendingTxs:
+	default:
+		// The engine already has a notification to handle
+		t.log.Debug("engine already has a pending transactions notification")
+	}
+
+	t.scheduled = true
+	t.timer.SetTimeoutIn(t.retryDelay)
+	t.retryDelay *= 2
+	if t.retryDelay > maxRetryDelay {
+		t.retryDelay = maxRetryDelay
+	}
+}
@@ -0,0 +1,91 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func newTestTrigger(batchDelay time.Duration) (*BuildTrigger, chan common.Message) {
+	toEngine := make(chan common.Message, 1)
+	trigger := &BuildTrigger{}
+	trigger.Initialize(logging.NoLog{}, toEngine, batchDelay)
+	return trigger, toEngine
+}
+
+func TestBuildTriggerNotifiesImmediately(t *testing.T) {
+	trigger, toEngine := newTestTrigger(0)
+	defer trigger.Shutdown()
+
+	trigger.NotifyBuildBlock()
+	select {
+	case msg := <-toEngine:
+		if msg != common.PendingTxs {
+			t.Fatalf("engine should have been notified of pending txs")
+		}
+	default:
+		t.Fatalf("engine should have been notified")
+	}
+
+	// A burst of transactions is coalesced
+	trigger.NotifyBuildBlock()
+	trigger.NotifyBuildBlock()
+	select {
+	case <-toEngine:
+		t.Fatalf("engine shouldn't have been notified again yet")
+	default:
+	}
+}
+
+func TestBuildTriggerBatches(t *testing.T) {
+	trigger, toEngine := newTestTrigger(10 * time.Millisecond)
+	defer trigger.Shutdown()
+
+	trigger.NotifyBuildBlock()
+	select {
+	case <-toEngine:
+		t.Fatalf("engine shouldn't have been notified before the batch delay")
+	default:
+	}
+	select {
+	case <-toEngine:
+	case <-time.After(time.Second):
+		t.Fatalf("engine should have been notified after the batch delay")
+	}
+}
+
+func TestBuildTriggerRetries(t *testing.T) {
+	trigger, toEngine := newTestTrigger(0)
+	defer trigger.Shutdown()
+
+	trigger.NotifyBuildBlock()
+	<-toEngine
+
+	// The engine didn't build a block, so it's notified again
+	select {
+	case <-toEngine:
+	case <-time.After(time.Second):
+		t.Fatalf("engine should have been notified again")
+	}
+
+	// Transactions remain after a block was built
+	trigger.BuiltBlock(true)
+	select {
+	case <-toEngine:
+	case <-time.After(time.Second):
+		t.Fatalf("engine should have been notified of the remaining transactions")
+	}
+
+	// No transactions remain after a block was built
+	trigger.BuiltBlock(false)
+	select {
+	case <-toEngine:
+		t.Fatalf("engine shouldn't have been notified without pending transactions")
+	case <-time.After(2 * minRetryDelay):
+	}
+}
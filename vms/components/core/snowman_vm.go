@@ -6,9 +6,10 @@ package core
 import (
 	"errors"
 
-	"github.com/gorilla/rpc/v2"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
@@ -16,14 +17,23 @@ import (
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/json"
+	"github.com/ava-labs/gecko/vms/components/epochs"
+	"github.com/ava-labs/gecko/vms/components/metrics"
 	"github.com/ava-labs/gecko/vms/components/state"
 )
 
 var (
 	errUnmarshalBlockUndefined = errors.New("vm's UnmarshalBlock member is undefined")
 	errBadData                 = errors.New("got unexpected value from database")
+	errRetainedBlock           = errors.New("the last accepted block and checkpointed blocks can't be pruned")
 )
 
+var epochsPrefix = []byte("epochs")
+
+// maxCheckpoints is the number of recently accepted blocks that the state can
+// be rolled back to
+const maxCheckpoints = 128
+
 // If the status of this ID is not choices.Accepted,
 // the db has not yet been initialized
 var dbInitializedID = ids.NewID([32]byte{'d', 'b', ' ', 'i', 'n', 'i', 't'})
@@ -38,6 +48,23 @@ type SnowmanVM struct {
 	// We use a versionDB here so user can do atomic commits as they see fit
 	DB *versiondb.Database
 
+	// Checkpoints of the state after each recently accepted block
+	checkpoints *versiondb.Checkpoints
+
+	// Summaries of the state at the end of each epoch. Nil unless the VM
+	// calls EnableEpochs.
+	Epochs *epochs.Epochs
+
+	// The state that's summarized in epochs
+	epochState database.Database
+
+	// The summarized state being fetched from other nodes, if any
+	sync *stateSync
+
+	// The metrics that every VM reports. They're registered by Metrics, and
+	// should be updated by the VM as its transactions are verified and decided.
+	VMMetrics metrics.Metrics
+
 	// The context of this vm
 	Ctx *snow.Context
 
@@ -65,6 +92,9 @@ func (svm *SnowmanVM) LastAccepted() ids.ID { return svm.lastAccepted }
 
 // ParseBlock parses [bytes] to a block
 func (svm *SnowmanVM) ParseBlock(bytes []byte) (snowman.Block, error) {
+	if svm.unmarshalBlockFunc == nil {
+		return nil, errUnmarshalBlockUndefined
+	}
 	return svm.unmarshalBlockFunc(bytes)
 }
 
@@ -88,6 +118,65 @@ func (svm *SnowmanVM) Shutdown() {
 	svm.DB.Close()               // close versionDB
 }
 
+// Metrics registers the VM's metrics with [registerer]
+func (svm *SnowmanVM) Metrics(registerer prometheus.Registerer) error {
+	return svm.VMMetrics.Register(registerer)
+}
+
+// Checkpoints returns the IDs of the accepted blocks that the state can be
+// rolled back to, oldest first
+func (svm *SnowmanVM) Checkpoints() []ids.ID { return svm.checkpoints.IDs() }
+
+// RollbackTo sets the state back to its state after the block with ID
+// [checkpointID] was accepted
+func (svm *SnowmanVM) RollbackTo(checkpointID ids.ID) error {
+	if err := svm.DB.Rollback(checkpointID); err != nil {
+		return err
+	}
+	svm.lastAccepted = checkpointID
+	svm.preferred = checkpointID
+	if svm.Epochs != nil {
+		return svm.Epochs.Reload()
+	}
+	return nil
+}
+
+// PruneContainer implements the common.PrunableVM interface. The block's
+// status is kept, but it can no longer be fetched. The last accepted block and
+// the blocks the state can be rolled back to are kept, since they're loaded
+// when the chain starts, as is the block at the end of the most recent epoch,
+// which is sent to nodes that sync its state.
+func (svm *SnowmanVM) PruneContainer(blkID ids.ID) error {
+	retained := append(svm.Checkpoints(), svm.lastAccepted)
+	if svm.Epochs != nil && svm.Epochs.Latest() != nil {
+		retained = append(retained, svm.Epochs.Latest().BlockID)
+	}
+	for _, retainedID := range retained {
+		if blkID.Equals(retainedID) {
+			return errRetainedBlock
+		}
+	}
+	if err := svm.State.Put(svm.DB, state.BlockTypeID, blkID, nil); err != nil {
+		return err
+	}
+	return svm.DB.Commit()
+}
+
+// EnableEpochs summarizes [state] at the end of every epoch of [interval]
+// accepted blocks. [state] should hold the VM's state, but not the blocks and
+// statuses stored in DB, since nodes may have processed different rejected
+// blocks. It must be called from the VM's Initialize, before the genesis block
+// is accepted. Blocks must write their changes to [state] before calling
+// Block.Accept. [state] is overwritten when the state is synced to another
+// node's summary, so a VM that caches its state must reload it once
+// bootstrapping finishes.
+func (svm *SnowmanVM) EnableEpochs(interval uint64, state database.Database) error {
+	var err error
+	svm.Epochs, err = epochs.New(prefixdb.New(epochsPrefix, svm.DB), state, interval)
+	svm.epochState = state
+	return err
+}
+
 // DBInitialized returns true iff [svm]'s database has values in it already
 func (svm *SnowmanVM) DBInitialized() bool {
 	status := svm.State.GetStatus(svm.DB, dbInitializedID)
@@ -118,14 +207,14 @@ func (svm *SnowmanVM) NotifyBlockReady() {
 }
 
 // NewHandler returns a new Handler for a service where:
-//   * The handler's functionality is defined by [service]
+//   - The handler's functionality is defined by [service]
 //     [service] should be a gorilla RPC service (see https://www.gorillatoolkit.org/pkg/rpc/v2)
-//   * The name of the service is [name]
-//   * The LockOption is the first element of [lockOption]
+//   - The name of the service is [name]
+//   - The LockOption is the first element of [lockOption]
 //     By default the LockOption is WriteLock
 //     [lockOption] should have either 0 or 1 elements. Elements beside the first are ignored.
 func (svm *SnowmanVM) NewHandler(name string, service interface{}, lockOption ...common.LockOption) *common.HTTPHandler {
-	server := rpc.NewServer()
+	server := json.NewServer()
 	server.RegisterCodec(json.NewCodec(), "application/json")
 	server.RegisterCodec(json.NewCodec(), "application/json;charset=UTF-8")
 	server.RegisterService(service, name)
@@ -149,8 +238,16 @@ func (svm *SnowmanVM) Initialize(
 	svm.Ctx = ctx
 	svm.ToEngine = toEngine
 	svm.DB = versiondb.New(db)
+	svm.VMMetrics.Initialize(ctx.Namespace)
 
 	var err error
+	svm.checkpoints, err = versiondb.NewCheckpoints(prefixdb.New([]byte("checkpoints"), db), maxCheckpoints)
+	if err != nil {
+		return err
+	}
+	svm.DB.SetCheckpoints(svm.checkpoints)
+
+	svm.unmarshalBlockFunc = unmarshalBlockFunc
 	svm.State, err = NewSnowmanState(unmarshalBlockFunc)
 	if err != nil {
 		return err
@@ -0,0 +1,337 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/epochs"
+)
+
+// maxStateChunkSize is the number of bytes of the block or of key/value pairs
+// returned for a request for the summarized state. It leaves room in the
+// smallest allowed message for the rest of the response.
+const maxStateChunkSize = 1 << 15
+
+var (
+	errNoEpochs           = errors.New("vm doesn't summarize its state in epochs")
+	errStateDiverged      = errors.New("state differs from the summarized state")
+	errNotSyncing         = errors.New("state isn't being synced")
+	errStateUnavailable   = errors.New("the state of the requested epoch isn't available")
+	errWrongEpoch         = errors.New("response is for a different epoch")
+	errUnexpectedResponse = errors.New("response doesn't answer the request")
+	errBlockLenDiffers    = errors.New("block length differs from the previous responses'")
+	errBadBlockChunk      = errors.New("response has the wrong part of the block")
+	errWrongBlock         = errors.New("fetched block isn't the summarized block")
+	errUnorderedPairs     = errors.New("response's pairs aren't in key order after the request's start")
+	errEmptyChunk         = errors.New("response has no pairs but claims there are more")
+	errWrongStateRoot     = errors.New("fetched state doesn't match the summary's state root")
+)
+
+// stateCodec serializes requests for the summarized state and the responses
+var stateCodec = codec.NewDefault()
+
+// stateRequest asks for part of the state at the end of an epoch. The block
+// that the state is the result of is fetched first, since the state can only
+// be used once the block is accepted.
+type stateRequest struct {
+	Epoch uint64 `serialize:"true"`
+
+	// Offset into the bytes of the block
+	BlockOffset uint32 `serialize:"true"`
+
+	// Key that pairs are returned from, once the whole block was fetched
+	Start []byte `serialize:"true"`
+}
+
+// stateResponse returns part of the state at the end of an epoch
+type stateResponse struct {
+	// The request that this responds to
+	Request stateRequest `serialize:"true"`
+
+	// Length of the block's bytes, and the requested part of them
+	BlockLen uint32 `serialize:"true"`
+	Block    []byte `serialize:"true"`
+
+	// Pairs of the state from the requested start, and whether there are more
+	Pairs []epochs.StatePair `serialize:"true"`
+	More  bool               `serialize:"true"`
+}
+
+// stateSync is the state of an epoch that's being fetched from other nodes
+type stateSync struct {
+	summary *epochs.Summary
+
+	// The next request to send
+	request stateRequest
+
+	// Fetched bytes of the block, and their length, which isn't known until
+	// the first response
+	block    []byte
+	blockLen uint32
+
+	// The summarized block, once it's fetched
+	blk snowman.Block
+
+	// Fetched pairs of the state
+	pairs *memdb.Database
+}
+
+// StateSummary returns the summary of the state at the end of the most recent
+// epoch, or nil if there's none
+func (svm *SnowmanVM) StateSummary() ([]byte, error) {
+	if svm.Epochs == nil || svm.Epochs.Latest() == nil {
+		return nil, nil
+	}
+	return svm.Epochs.Bytes(svm.Epochs.Latest())
+}
+
+// SyncState starts setting the state to the one that [summaryBytes], a summary
+// of an epoch that another node returned from StateSummary, commits to. If the
+// epoch already ended here, the state is checked against the summary and isn't
+// fetched. Otherwise, the block at the end of the epoch and the state are
+// fetched with the returned request.
+func (svm *SnowmanVM) SyncState(summaryBytes []byte) ([]byte, error) {
+	if svm.Epochs == nil {
+		return nil, errNoEpochs
+	}
+	summary, err := svm.Epochs.Parse(summaryBytes)
+	if err != nil {
+		return nil, err
+	}
+	svm.sync = nil
+
+	if summary.Height > svm.Epochs.Height() {
+		svm.sync = &stateSync{
+			summary: summary,
+			request: stateRequest{Epoch: summary.Epoch},
+			pairs:   memdb.New(),
+		}
+		return stateCodec.Marshal(&svm.sync.request)
+	}
+
+	localSummary, err := svm.Epochs.Summary(summary.Epoch)
+	if err != nil {
+		return nil, err
+	}
+	if localSummary.Height != summary.Height ||
+		!localSummary.BlockID.Equals(summary.BlockID) ||
+		!localSummary.StateRoot.Equals(summary.StateRoot) {
+		return nil, errStateDiverged
+	}
+	return nil, nil
+}
+
+// GetState returns the part of the state at the end of the most recent epoch
+// that [requestBytes] asks for. Only the most recent epoch's state is kept, so
+// requests for an earlier epoch fail.
+func (svm *SnowmanVM) GetState(requestBytes []byte) ([]byte, error) {
+	if svm.Epochs == nil {
+		return nil, errNoEpochs
+	}
+	request := stateRequest{}
+	if err := stateCodec.Unmarshal(requestBytes, &request); err != nil {
+		return nil, err
+	}
+	latest := svm.Epochs.Latest()
+	if latest == nil || latest.Epoch != request.Epoch {
+		return nil, errStateUnavailable
+	}
+	blk, err := svm.GetBlock(latest.BlockID)
+	if err != nil {
+		return nil, err
+	}
+	blkBytes := blk.Bytes()
+
+	response := stateResponse{
+		Request:  request,
+		BlockLen: uint32(len(blkBytes)),
+	}
+	switch offset := int(request.BlockOffset); {
+	case offset < len(blkBytes):
+		end := offset + maxStateChunkSize
+		if end > len(blkBytes) {
+			end = len(blkBytes)
+		}
+		response.Block = blkBytes[offset:end]
+	case offset == len(blkBytes):
+		response.Pairs, response.More, err = svm.Epochs.Pairs(request.Start, maxStateChunkSize)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errBadBlockChunk
+	}
+	return stateCodec.Marshal(&response)
+}
+
+// PutState adds [responseBytes], which another node's GetState returned, to the
+// state being fetched and returns the next request. Once the whole state is
+// fetched and matches the summary, it replaces the state, the summarized block
+// is accepted, and nil is returned. If the response is invalid, the request to
+// send to another node instead is returned along with the error.
+func (svm *SnowmanVM) PutState(responseBytes []byte) ([]byte, error) {
+	s := svm.sync
+	if s == nil {
+		return nil, errNotSyncing
+	}
+	fetched, err := s.put(svm, responseBytes)
+	if err != nil {
+		request, marshalErr := stateCodec.Marshal(&s.request)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		return request, err
+	}
+	if !fetched {
+		return stateCodec.Marshal(&s.request)
+	}
+
+	stateRoot, err := epochs.StateRoot(s.pairs)
+	if err != nil {
+		return nil, err
+	}
+	if !stateRoot.Equals(s.summary.StateRoot) {
+		// The block was checked against its ID, so only the pairs are
+		// fetched again
+		s.pairs = memdb.New()
+		s.request.Start = nil
+		request, marshalErr := stateCodec.Marshal(&s.request)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		return request, errWrongStateRoot
+	}
+	svm.sync = nil
+	return nil, svm.setState(s)
+}
+
+// put adds [responseBytes] to the fetched block or state, and advances the
+// request. It returns whether the whole state was fetched. The fetched block
+// and state are left unchanged if the response is invalid, unless the whole
+// block was fetched and isn't the summarized block.
+func (s *stateSync) put(svm *SnowmanVM, responseBytes []byte) (bool, error) {
+	response := stateResponse{}
+	if err := stateCodec.Unmarshal(responseBytes, &response); err != nil {
+		return false, err
+	}
+	request := response.Request
+	switch {
+	case request.Epoch != s.summary.Epoch:
+		return false, errWrongEpoch
+	case request.BlockOffset != s.request.BlockOffset || !bytes.Equal(request.Start, s.request.Start):
+		return false, errUnexpectedResponse
+	case s.blockLen != 0 && response.BlockLen != s.blockLen:
+		return false, errBlockLenDiffers
+	}
+
+	if s.blk == nil {
+		end := len(s.block) + len(response.Block)
+		if len(response.Block) == 0 || len(response.Pairs) != 0 || end > int(response.BlockLen) {
+			return false, errBadBlockChunk
+		}
+		s.block = append(s.block, response.Block...)
+		s.blockLen = response.BlockLen
+		s.request.BlockOffset = uint32(end)
+		if end < int(response.BlockLen) {
+			return false, nil
+		}
+
+		blk, err := svm.ParseBlock(s.block)
+		if err == nil && !blk.ID().Equals(s.summary.BlockID) {
+			err = errWrongBlock
+		}
+		if err != nil {
+			// A node sent a different block, so the block is fetched again
+			s.block = nil
+			s.blockLen = 0
+			s.request.BlockOffset = 0
+			return false, err
+		}
+		s.block = nil
+		s.blk = blk
+		return false, nil
+	}
+
+	if len(response.Block) != 0 {
+		return false, errBadBlockChunk
+	}
+	if response.More && len(response.Pairs) == 0 {
+		return false, errEmptyChunk
+	}
+	for i, pair := range response.Pairs {
+		if i == 0 && bytes.Compare(pair.Key, s.request.Start) < 0 ||
+			i > 0 && bytes.Compare(pair.Key, response.Pairs[i-1].Key) <= 0 {
+			return false, errUnorderedPairs
+		}
+	}
+
+	for _, pair := range response.Pairs {
+		if err := s.pairs.Put(pair.Key, pair.Value); err != nil {
+			return false, err
+		}
+	}
+	if !response.More {
+		return true, nil
+	}
+	// The next pairs start right after the last key
+	last := response.Pairs[len(response.Pairs)-1].Key
+	s.request.Start = append(append([]byte(nil), last...), 0)
+	return false, nil
+}
+
+// setState replaces the state with the state fetched by [s], and accepts the
+// summarized block
+func (svm *SnowmanVM) setState(s *stateSync) error {
+	it := svm.epochState.NewIterator()
+	keys := [][]byte(nil)
+	for it.Next() {
+		keys = append(keys, append([]byte(nil), it.Key()...))
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := svm.epochState.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	it = s.pairs.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		if err := svm.epochState.Put(it.Key(), it.Value()); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := svm.Epochs.Sync(s.summary); err != nil {
+		return err
+	}
+
+	blkID := s.blk.ID()
+	if err := svm.SaveBlock(svm.DB, s.blk); err != nil {
+		return err
+	}
+	if err := svm.State.PutStatus(svm.DB, blkID, choices.Accepted); err != nil {
+		return err
+	}
+	if err := svm.State.PutLastAccepted(svm.DB, blkID); err != nil {
+		return err
+	}
+	svm.lastAccepted = blkID
+	svm.preferred = blkID
+	if svm.checkpoints != nil {
+		svm.checkpoints.Checkpoint(blkID)
+	}
+	return svm.DB.Commit()
+}
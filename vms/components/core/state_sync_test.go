@@ -0,0 +1,191 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/vms/components/epochs"
+)
+
+type testBlock struct{ *Block }
+
+func (b *testBlock) Verify() error {
+	_, err := b.Block.Verify()
+	return err
+}
+
+// newStateSyncVM returns a VM whose state is summarized every 2 blocks, and
+// which has accepted a genesis block
+func newStateSyncVM(t *testing.T) (*SnowmanVM, database.Database) {
+	svm := &SnowmanVM{}
+	parse := func(b []byte) (snowman.Block, error) {
+		blk := &testBlock{Block: NewBlock(ids.Empty)}
+		blk.Initialize(b, svm)
+		return blk, nil
+	}
+	if err := svm.Initialize(snow.DefaultContextTest(), memdb.New(), parse, nil); err != nil {
+		t.Fatal(err)
+	}
+	state := prefixdb.New([]byte("state"), svm.DB)
+	if err := svm.EnableEpochs(2, state); err != nil {
+		t.Fatal(err)
+	}
+	acceptBlock(t, svm, []byte("genesis"))
+	return svm, state
+}
+
+func acceptBlock(t *testing.T, svm *SnowmanVM, blkBytes []byte) snowman.Block {
+	blk, err := svm.ParseBlock(blkBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svm.SaveBlock(svm.DB, blk); err != nil {
+		t.Fatal(err)
+	}
+	blk.Accept()
+	if err := svm.DB.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return blk
+}
+
+func TestStateSync(t *testing.T) {
+	server, serverState := newStateSyncVM(t)
+
+	// The state and the block at the end of the epoch don't fit in one
+	// response
+	for i := 0; i < 3; i++ {
+		value := bytes.Repeat([]byte{byte(i)}, maxStateChunkSize/2)
+		if err := serverState.Put([]byte{byte(i)}, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	acceptBlock(t, server, []byte("block 1"))
+	blk := acceptBlock(t, server, bytes.Repeat([]byte{2}, maxStateChunkSize+1))
+
+	summary, err := server.StateSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, clientState := newStateSyncVM(t)
+	if err := clientState.Put([]byte{9}, []byte{9}); err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := client.SyncState(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numRequests := 0
+	for request != nil {
+		numRequests++
+		response, err := server.GetState(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if request, err = client.PutState(response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 for the block, and 2 for the state
+	if numRequests != 4 {
+		t.Fatalf("should have sent 4 requests but sent %d", numRequests)
+	}
+
+	if !client.LastAccepted().Equals(blk.ID()) {
+		t.Fatalf("should have accepted the summarized block")
+	}
+	synced, err := client.GetBlock(blk.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synced.Status() != choices.Accepted || !bytes.Equal(synced.Bytes(), blk.Bytes()) {
+		t.Fatalf("should have stored the summarized block as accepted")
+	}
+	if has, err := clientState.Has([]byte{9}); err != nil || has {
+		t.Fatalf("should have removed the state that isn't summarized")
+	}
+	stateRoot, err := epochs.StateRoot(clientState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stateRoot.Equals(server.Epochs.Latest().StateRoot) {
+		t.Fatalf("should have synced the summarized state")
+	}
+	if clientSummary, err := client.StateSummary(); err != nil || !bytes.Equal(clientSummary, summary) {
+		t.Fatalf("should summarize the synced state")
+	}
+
+	// The synced state is already the summarized state
+	if request, err := client.SyncState(summary); err != nil || request != nil {
+		t.Fatalf("shouldn't fetch the state again")
+	}
+}
+
+func TestStateSyncInvalidResponse(t *testing.T) {
+	server, serverState := newStateSyncVM(t)
+	if err := serverState.Put([]byte{1}, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	acceptBlock(t, server, []byte("block 1"))
+	acceptBlock(t, server, []byte("block 2"))
+	summary, err := server.StateSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, _ := newStateSyncVM(t)
+	if _, err := client.PutState(nil); err != errNotSyncing {
+		t.Fatalf("shouldn't put state before syncing")
+	}
+	request, err := client.SyncState(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := server.GetState(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err = client.PutState(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A response with a different state is dropped, and the state is
+	// requested again
+	if err := serverState.Put([]byte{1}, []byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Epochs.Sync(server.Epochs.Latest()); err != nil {
+		t.Fatal(err)
+	}
+	response, err = server.GetState(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retry, err := client.PutState(response)
+	if err != errWrongStateRoot {
+		t.Fatalf("should have dropped a state that doesn't match the summary")
+	}
+	if !bytes.Equal(retry, request) {
+		t.Fatalf("should have requested the state again")
+	}
+
+	// A node that moved on to a later epoch can't provide the state
+	acceptBlock(t, server, []byte("block 3"))
+	acceptBlock(t, server, []byte("block 4"))
+	if _, err := server.GetState(request); err != errStateUnavailable {
+		t.Fatalf("shouldn't provide the state of an earlier epoch")
+	}
+}
@@ -0,0 +1,359 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package epochs
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+var (
+	summariesPrefix = []byte("summaries")
+	snapshotPrefix  = []byte("snapshot")
+	metaPrefix      = []byte("meta")
+
+	heightKey = []byte("height")
+)
+
+var (
+	errZeroInterval    = errors.New("epoch interval must be positive")
+	errNoEpochs        = errors.New("no epoch has ended yet")
+	errUnknownEpoch    = errors.New("unknown epoch")
+	errWrongHeight     = errors.New("state isn't at the height of the summary")
+	errStateRootDiffer = errors.New("state root differs from the summary's")
+)
+
+// Summary commits to a VM's state at the end of an epoch. Nodes whose
+// accepted state is the same have the same summaries, so a summary that enough
+// validators agree on anchors the chain's state: a node can check that a state
+// it synced or a value it was given belongs to the chain without executing the
+// chain's history.
+type Summary struct {
+	// Number of the epoch. Epoch n ends with the block at height
+	// n * interval.
+	Epoch uint64 `serialize:"true"`
+
+	// Height and ID of the block that the state is the result of
+	Height  uint64 `serialize:"true"`
+	BlockID ids.ID `serialize:"true"`
+
+	// Merkle root of the state, as returned by StateRoot
+	StateRoot ids.ID `serialize:"true"`
+}
+
+// StatePair is a key/value pair of the state
+type StatePair struct {
+	Key   []byte `serialize:"true"`
+	Value []byte `serialize:"true"`
+}
+
+// Epochs checkpoints a VM's state every [interval] accepted blocks by
+// persisting a summary of it. Like the rest of a VM's state, it must only be
+// used while the chain's lock is held.
+type Epochs struct {
+	codec    codec.Codec
+	interval uint64
+
+	// The state that's summarized
+	state database.Iteratee
+
+	// Key: Number of an epoch
+	// Value: Summary of the epoch
+	summaries database.Database
+
+	// Copy of the state at the end of the most recent epoch, which proofs
+	// are made against
+	snapshot database.Database
+
+	meta database.Database
+
+	// Height of the last accepted block. The genesis block is at height 0.
+	height uint64
+
+	// Whether a block has been accepted yet
+	started bool
+
+	// Summary of the most recent epoch, if any
+	latest *Summary
+}
+
+// New returns epochs of [interval] blocks that summarize [state] and are
+// stored in [db]. Writes aren't committed; they should be committed along with
+// the acceptance of the blocks, so that the summaries are rolled back with the
+// state. [db] must not overlap [state].
+//
+// Heights are counted from the first block accepted after the epochs are
+// created, so a VM must use them from when its chain is created.
+func New(db database.Database, state database.Iteratee, interval uint64) (*Epochs, error) {
+	if interval == 0 {
+		return nil, errZeroInterval
+	}
+	e := &Epochs{
+		codec:     codec.NewDefault(),
+		interval:  interval,
+		state:     state,
+		summaries: prefixdb.New(summariesPrefix, db),
+		snapshot:  prefixdb.New(snapshotPrefix, db),
+		meta:      prefixdb.New(metaPrefix, db),
+	}
+	return e, e.Reload()
+}
+
+// Reload the height and the latest summary from the database, after it was
+// rolled back
+func (e *Epochs) Reload() error {
+	e.height = 0
+	e.started = false
+	e.latest = nil
+
+	heightValue, err := e.meta.Get(heightKey)
+	switch err {
+	case nil:
+		p := wrappers.Packer{Bytes: heightValue}
+		e.height = p.UnpackLong()
+		if p.Errored() {
+			return p.Err
+		}
+		e.started = true
+	case database.ErrNotFound:
+		return nil
+	default:
+		return err
+	}
+
+	if epoch := e.height / e.interval; epoch > 0 {
+		latest, err := e.Summary(epoch)
+		if err != nil {
+			return err
+		}
+		e.latest = latest
+	}
+	return nil
+}
+
+// Interval returns the number of blocks in an epoch
+func (e *Epochs) Interval() uint64 { return e.interval }
+
+// Height returns the height of the last accepted block
+func (e *Epochs) Height() uint64 { return e.height }
+
+// Accept records that the block [blockID] was accepted. If it ends an epoch,
+// the state is summarized and copied, so the block's changes must already be
+// written to the state.
+func (e *Epochs) Accept(blockID ids.ID) error {
+	height := uint64(0)
+	if e.started {
+		height = e.height + 1
+	}
+	if err := e.meta.Put(heightKey, heightBytes(height)); err != nil {
+		return err
+	}
+	e.height = height
+	e.started = true
+
+	if height == 0 || height%e.interval != 0 {
+		return nil
+	}
+
+	if err := e.takeSnapshot(); err != nil {
+		return err
+	}
+	stateRoot, err := StateRoot(e.snapshot)
+	if err != nil {
+		return err
+	}
+	summary := &Summary{
+		Epoch:     height / e.interval,
+		Height:    height,
+		BlockID:   blockID,
+		StateRoot: stateRoot,
+	}
+	summaryBytes, err := e.codec.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	if err := e.summaries.Put(heightBytes(summary.Epoch), summaryBytes); err != nil {
+		return err
+	}
+	e.latest = summary
+	return nil
+}
+
+// Latest returns the summary of the most recent epoch, or nil if no epoch has
+// ended
+func (e *Epochs) Latest() *Summary { return e.latest }
+
+// Summary returns the summary of the epoch [epoch]
+func (e *Epochs) Summary(epoch uint64) (*Summary, error) {
+	summaryBytes, err := e.summaries.Get(heightBytes(epoch))
+	switch err {
+	case nil:
+	case database.ErrNotFound:
+		return nil, errUnknownEpoch
+	default:
+		return nil, err
+	}
+
+	summary := &Summary{}
+	err = e.codec.Unmarshal(summaryBytes, summary)
+	return summary, err
+}
+
+// Bytes returns the serialized form of [summary]
+func (e *Epochs) Bytes(summary *Summary) ([]byte, error) { return e.codec.Marshal(summary) }
+
+// Parse a summary from the serialized form returned by Bytes
+func (e *Epochs) Parse(summaryBytes []byte) (*Summary, error) {
+	summary := &Summary{}
+	err := e.codec.Unmarshal(summaryBytes, summary)
+	return summary, err
+}
+
+// Verify that the state, which must be the result of the block at the
+// summary's height, is the state that [summary] commits to
+func (e *Epochs) Verify(summary *Summary) error {
+	if !e.started || e.height != summary.Height {
+		return errWrongHeight
+	}
+	stateRoot, err := StateRoot(e.state)
+	if err != nil {
+		return err
+	}
+	if !stateRoot.Equals(summary.StateRoot) {
+		return errStateRootDiffer
+	}
+	return nil
+}
+
+// Pairs returns the key/value pairs, in key order, of the state at the end of
+// the most recent epoch whose keys are at least [start]. Pairs are returned
+// until their keys and values total at least [maxSize] bytes, and whether
+// there are more pairs after them is returned too.
+func (e *Epochs) Pairs(start []byte, maxSize int) ([]StatePair, bool, error) {
+	if e.latest == nil {
+		return nil, false, errNoEpochs
+	}
+
+	it := e.snapshot.NewIteratorWithStart(start)
+	defer it.Release()
+
+	pairs := []StatePair(nil)
+	size := 0
+	for size < maxSize && it.Next() {
+		// The iterator may reuse its buffers
+		key := make([]byte, len(it.Key()))
+		copy(key, it.Key())
+		value := make([]byte, len(it.Value()))
+		copy(value, it.Value())
+
+		pairs = append(pairs, StatePair{Key: key, Value: value})
+		size += len(key) + len(value)
+	}
+	more := size >= maxSize && it.Next()
+	return pairs, more, it.Error()
+}
+
+// Sync records that the state was set to the one that [summary] commits to,
+// which was fetched from other nodes, so the block at the summary's height is
+// the last accepted block
+func (e *Epochs) Sync(summary *Summary) error {
+	if err := e.meta.Put(heightKey, heightBytes(summary.Height)); err != nil {
+		return err
+	}
+	e.height = summary.Height
+	e.started = true
+
+	if err := e.takeSnapshot(); err != nil {
+		return err
+	}
+	summaryBytes, err := e.codec.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	if err := e.summaries.Put(heightBytes(summary.Epoch), summaryBytes); err != nil {
+		return err
+	}
+	e.latest = summary
+	return nil
+}
+
+// takeSnapshot replaces the snapshot with a copy of the state
+func (e *Epochs) takeSnapshot() error {
+	// Keys that are in the snapshot but may no longer be in the state
+	stale := map[string]struct{}{}
+	it := e.snapshot.NewIterator()
+	for it.Next() {
+		stale[string(it.Key())] = struct{}{}
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	it = e.state.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		// The iterator may reuse its buffers, and the database may keep the
+		// value without copying it
+		key := it.Key()
+		value := make([]byte, len(it.Value()))
+		copy(value, it.Value())
+		delete(stale, string(key))
+		if err := e.snapshot.Put(key, value); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	for key := range stale {
+		if err := e.snapshot.Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StateRoot returns the root of the Merkle tree whose leaves are the hashes of
+// the key/value pairs in [state], in key order. The root of an empty state is
+// the hash of no bytes.
+func StateRoot(state database.Iteratee) (ids.ID, error) {
+	it := state.NewIterator()
+	defer it.Release()
+
+	leaves := []hashing.Hash256(nil)
+	for it.Next() {
+		leaves = append(leaves, leaf(it.Key(), it.Value()))
+	}
+	if err := it.Error(); err != nil {
+		return ids.ID{}, err
+	}
+	if len(leaves) == 0 {
+		return ids.NewID(hashing.ComputeHash256Array(nil)), nil
+	}
+	return ids.NewID(hashing.MerkleRoot(leaves)), nil
+}
+
+// leaf returns the hash of the key/value pair [key], [value]. The key is
+// length prefixed so that the boundary between the key and the value is
+// unambiguous.
+func leaf(key, value []byte) hashing.Hash256 {
+	p := wrappers.Packer{Bytes: make([]byte, wrappers.IntLen+len(key)+len(value))}
+	p.PackBytes(key)
+	p.PackFixedBytes(value)
+	return hashing.ComputeHash256Array(p.Bytes)
+}
+
+func heightBytes(height uint64) []byte {
+	p := wrappers.Packer{Bytes: make([]byte, wrappers.LongLen)}
+	p.PackLong(height)
+	return p.Bytes
+}
@@ -0,0 +1,277 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package epochs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+func TestEpochs(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	state := prefixdb.New([]byte("state"), db)
+
+	e, err := New(prefixdb.New([]byte("epochs"), db), state, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesisID := ids.NewID([32]byte{1})
+	if err := e.Accept(genesisID); err != nil {
+		t.Fatal(err)
+	}
+	if e.Height() != 0 || e.Latest() != nil {
+		t.Fatalf("the genesis block shouldn't end an epoch")
+	}
+
+	if err := state.Put([]byte{1}, []byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Accept(ids.NewID([32]byte{2})); err != nil {
+		t.Fatal(err)
+	}
+	if e.Latest() != nil {
+		t.Fatalf("epoch shouldn't have ended at height 1")
+	}
+
+	if err := state.Put([]byte{3}, []byte{4}); err != nil {
+		t.Fatal(err)
+	}
+	blockID := ids.NewID([32]byte{3})
+	if err := e.Accept(blockID); err != nil {
+		t.Fatal(err)
+	}
+	stateRoot, err := StateRoot(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := e.Latest()
+	switch {
+	case summary == nil:
+		t.Fatalf("epoch should have ended at height 2")
+	case summary.Epoch != 1 || summary.Height != 2 || !summary.BlockID.Equals(blockID):
+		t.Fatalf("wrong summary: %+v", summary)
+	case !summary.StateRoot.Equals(stateRoot):
+		t.Fatalf("summary should commit to the state")
+	}
+	if err := e.Verify(summary); err != nil {
+		t.Fatal(err)
+	}
+
+	// Summaries survive being reloaded
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := New(prefixdb.New([]byte("epochs"), db), state, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Height() != 2 || reloaded.Latest() == nil || !reloaded.Latest().StateRoot.Equals(stateRoot) {
+		t.Fatalf("should have reloaded the latest summary")
+	}
+
+	// A summary of a different state doesn't verify
+	if err := state.Put([]byte{5}, []byte{6}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Verify(summary); err != errStateRootDiffer {
+		t.Fatalf("should have failed to verify a summary of a different state")
+	}
+	if err := e.Accept(ids.NewID([32]byte{4})); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Verify(summary); err != errWrongHeight {
+		t.Fatalf("should have failed to verify a summary at a different height")
+	}
+}
+
+func TestSummaryBytes(t *testing.T) {
+	db := memdb.New()
+	e, err := New(db, prefixdb.New([]byte("state"), db), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary := &Summary{
+		Epoch:     3,
+		Height:    3,
+		BlockID:   ids.NewID([32]byte{1}),
+		StateRoot: ids.NewID([32]byte{2}),
+	}
+	summaryBytes, err := e.Bytes(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := e.Parse(summaryBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Epoch != summary.Epoch || parsed.Height != summary.Height ||
+		!parsed.BlockID.Equals(summary.BlockID) || !parsed.StateRoot.Equals(summary.StateRoot) {
+		t.Fatalf("parsed %+v but expected %+v", parsed, summary)
+	}
+}
+
+func TestStateRoot(t *testing.T) {
+	empty, err := StateRoot(memdb.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.IsZero() {
+		t.Fatalf("the root of an empty state shouldn't be empty")
+	}
+
+	// The boundary between keys and values is part of the root
+	db0 := memdb.New()
+	if err := db0.Put([]byte{1}, []byte{2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	db1 := memdb.New()
+	if err := db1.Put([]byte{1, 2}, []byte{3}); err != nil {
+		t.Fatal(err)
+	}
+	root0, err := StateRoot(db0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root1, err := StateRoot(db1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root0.Equals(root1) || root0.Equals(empty) {
+		t.Fatalf("different states should have different roots")
+	}
+}
+
+func TestServiceGetSummary(t *testing.T) {
+	db := memdb.New()
+	e, err := New(db, prefixdb.New([]byte("state"), db), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{epochs: e}
+
+	if err := s.GetSummary(nil, &GetSummaryArgs{}, &GetSummaryReply{}); err != errNoEpochs {
+		t.Fatalf("shouldn't have returned a summary before an epoch ended")
+	}
+
+	for i := byte(1); i <= 3; i++ {
+		if err := e.Accept(ids.NewID([32]byte{i})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reply := GetSummaryReply{}
+	if err := s.GetSummary(nil, &GetSummaryArgs{}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Epoch != 2 || !reply.BlockID.Equals(ids.NewID([32]byte{3})) {
+		t.Fatalf("should have returned the latest summary but returned %+v", reply)
+	}
+
+	epoch := json.Uint64(1)
+	if err := s.GetSummary(nil, &GetSummaryArgs{Epoch: &epoch}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := e.Parse(reply.Bytes.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Epoch != 1 || summary.Epoch != 1 || !summary.BlockID.Equals(ids.NewID([32]byte{2})) {
+		t.Fatalf("should have returned the summary of epoch 1 but returned %+v", reply)
+	}
+
+	epoch = 5
+	if err := s.GetSummary(nil, &GetSummaryArgs{Epoch: &epoch}, &reply); err != errUnknownEpoch {
+		t.Fatalf("shouldn't have returned the summary of an epoch that hasn't ended")
+	}
+
+	heightReply := GetHeightReply{}
+	if err := s.GetHeight(nil, &GetHeightArgs{}, &heightReply); err != nil {
+		t.Fatal(err)
+	}
+	if heightReply.Height != 2 || heightReply.Interval != 1 {
+		t.Fatalf("wrong height: %+v", heightReply)
+	}
+}
+
+func TestPairsAndSync(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	state := prefixdb.New([]byte("state"), db)
+	e, err := New(prefixdb.New([]byte("epochs"), db), state, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := e.Pairs(nil, 1); err != errNoEpochs {
+		t.Fatalf("shouldn't return pairs before an epoch ended")
+	}
+
+	for i := byte(0); i < 4; i++ {
+		if err := state.Put([]byte{i}, []byte{i, i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.Accept(ids.NewID([32]byte{1})); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Accept(ids.NewID([32]byte{2})); err != nil {
+		t.Fatal(err)
+	}
+	summary := e.Latest()
+
+	// Each pair is 3 bytes, so the first two pairs reach 5 bytes
+	pairs, more, err := e.Pairs(nil, 5)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(pairs) != 2 || !more:
+		t.Fatalf("should have returned 2 of the 4 pairs")
+	case !bytes.Equal(pairs[1].Key, []byte{1}) || !bytes.Equal(pairs[1].Value, []byte{1, 1}):
+		t.Fatalf("wrong pair: %+v", pairs[1])
+	}
+	pairs, more, err = e.Pairs([]byte{2}, 6)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(pairs) != 2 || more:
+		t.Fatalf("should have returned the last 2 pairs")
+	}
+
+	// A new node syncs to the summary
+	syncDB := versiondb.New(memdb.New())
+	syncState := prefixdb.New([]byte("state"), syncDB)
+	synced, err := New(prefixdb.New([]byte("epochs"), syncDB), syncState, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := byte(0); i < 4; i++ {
+		if err := syncState.Put([]byte{i}, []byte{i, i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := synced.Sync(summary); err != nil {
+		t.Fatal(err)
+	}
+	if synced.Height() != summary.Height || synced.Latest() != summary {
+		t.Fatalf("should have synced to the summary")
+	}
+	if err := synced.Verify(summary); err != nil {
+		t.Fatal(err)
+	}
+	if pairs, _, err := synced.Pairs(nil, 100); err != nil || len(pairs) != 4 {
+		t.Fatalf("should return the synced state's pairs")
+	}
+	if err := synced.Accept(ids.NewID([32]byte{3})); err != nil {
+		t.Fatal(err)
+	}
+	if synced.Height() != summary.Height+1 {
+		t.Fatalf("should accept blocks after the summarized block")
+	}
+}
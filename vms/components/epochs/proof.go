@@ -0,0 +1,145 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package epochs
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	errInvalidProof = errors.New("proof doesn't match the state root")
+	errProofLeaves  = errors.New("proof has the wrong leaves for the key")
+)
+
+// ProofLeaf is a key/value pair of the state, and the path that proves it's in
+// the state
+type ProofLeaf struct {
+	// Position of the pair in the state, in key order
+	Index int
+
+	Key, Value []byte
+
+	// Siblings of the nodes on the path from the pair's leaf to the state root
+	Path []hashing.Hash256
+}
+
+// Proof that a key is or isn't in the state that a summary commits to. Since
+// the leaves of the state's Merkle tree are ordered by key, a key that isn't
+// in the state is proven absent by the adjacent leaves around it.
+type Proof struct {
+	// Number of key/value pairs in the state
+	NumLeaves int
+
+	// If the key is in the state, its leaf. Otherwise, the leaf with the
+	// greatest key before the key and the leaf with the least key after it,
+	// whichever exist.
+	Leaves []ProofLeaf
+}
+
+// Prove that [key] is or isn't in the state at the end of the most recent
+// epoch, whose summary is returned along with the proof
+func (e *Epochs) Prove(key []byte) (*Summary, *Proof, error) {
+	if e.latest == nil {
+		return nil, nil, errNoEpochs
+	}
+
+	it := e.snapshot.NewIterator()
+	defer it.Release()
+
+	leaves := []hashing.Hash256(nil)
+	proven := []ProofLeaf(nil)
+	found := false
+	for it.Next() {
+		leafKey, value := it.Key(), it.Value()
+		index := len(leaves)
+		leaves = append(leaves, leaf(leafKey, value))
+		if found {
+			continue
+		}
+
+		switch cmp := bytes.Compare(leafKey, key); {
+		case cmp < 0:
+			// The greatest key before [key] is the last one seen
+			proven = []ProofLeaf{newProofLeaf(index, leafKey, value)}
+		case cmp == 0:
+			proven = []ProofLeaf{newProofLeaf(index, leafKey, value)}
+			found = true
+		default:
+			proven = append(proven, newProofLeaf(index, leafKey, value))
+			found = true
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range proven {
+		path, err := hashing.MerkleProof(leaves, proven[i].Index)
+		if err != nil {
+			return nil, nil, err
+		}
+		proven[i].Path = path
+	}
+	return e.latest, &Proof{NumLeaves: len(leaves), Leaves: proven}, nil
+}
+
+// VerifyProof checks [proof] against the state root [stateRoot], which should
+// come from a summary that enough validators agree on. It returns whether
+// [key] is in the state, and if so its value.
+func VerifyProof(stateRoot ids.ID, key []byte, proof *Proof) ([]byte, bool, error) {
+	if proof.NumLeaves == 0 {
+		if len(proof.Leaves) != 0 {
+			return nil, false, errProofLeaves
+		}
+		if !stateRoot.Equals(ids.NewID(hashing.ComputeHash256Array(nil))) {
+			return nil, false, errInvalidProof
+		}
+		return nil, false, nil
+	}
+
+	root := hashing.Hash256(stateRoot.Key())
+	for _, l := range proof.Leaves {
+		if !hashing.VerifyMerkleProof(root, leaf(l.Key, l.Value), l.Index, proof.NumLeaves, l.Path) {
+			return nil, false, errInvalidProof
+		}
+	}
+
+	switch len(proof.Leaves) {
+	case 1:
+		l := proof.Leaves[0]
+		switch cmp := bytes.Compare(l.Key, key); {
+		case cmp == 0:
+			return l.Value, true, nil
+		case cmp > 0 && l.Index == 0:
+			// [key] is before the first key
+			return nil, false, nil
+		case cmp < 0 && l.Index == proof.NumLeaves-1:
+			// [key] is after the last key
+			return nil, false, nil
+		}
+	case 2:
+		before, after := proof.Leaves[0], proof.Leaves[1]
+		if after.Index == before.Index+1 &&
+			bytes.Compare(before.Key, key) < 0 &&
+			bytes.Compare(key, after.Key) < 0 {
+			return nil, false, nil
+		}
+	}
+	return nil, false, errProofLeaves
+}
+
+func newProofLeaf(index int, key, value []byte) ProofLeaf {
+	l := ProofLeaf{
+		Index: index,
+		Key:   make([]byte, len(key)),
+		Value: make([]byte, len(value)),
+	}
+	copy(l.Key, key)
+	copy(l.Value, value)
+	return l
+}
@@ -0,0 +1,165 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package epochs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+func TestProve(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	state := prefixdb.New([]byte("state"), db)
+
+	e, err := New(prefixdb.New([]byte("epochs"), db), state, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := e.Prove([]byte{1}); err != errNoEpochs {
+		t.Fatalf("shouldn't have proven a key before an epoch ended")
+	}
+
+	for _, key := range []byte{2, 4, 6, 8, 10} {
+		if err := state.Put([]byte{key}, []byte{key, key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.Accept(ids.NewID([32]byte{1})); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Accept(ids.NewID([32]byte{2})); err != nil {
+		t.Fatal(err)
+	}
+	stateRoot := e.Latest().StateRoot
+
+	// Changes after the epoch ended don't affect proofs
+	if err := state.Put([]byte{5}, []byte{5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Delete([]byte{4}); err != nil {
+		t.Fatal(err)
+	}
+
+	for key := byte(0); key <= 11; key++ {
+		summary, proof, err := e.Prove([]byte{key})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !summary.StateRoot.Equals(stateRoot) {
+			t.Fatalf("proof should be against the latest summary")
+		}
+		value, included, err := VerifyProof(stateRoot, []byte{key}, proof)
+		if err != nil {
+			t.Fatalf("proof of key %d didn't verify: %s", key, err)
+		}
+		shouldInclude := key%2 == 0 && key >= 2 && key <= 10
+		if included != shouldInclude {
+			t.Fatalf("key %d should be included: %v", key, shouldInclude)
+		}
+		if included && !bytes.Equal(value, []byte{key, key}) {
+			t.Fatalf("key %d has the wrong value", key)
+		}
+	}
+
+	// A proof of one key doesn't prove another
+	_, proof, err := e.Prove([]byte{4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyProof(stateRoot, []byte{6}, proof); err == nil {
+		t.Fatalf("proof of key 4 shouldn't prove key 6")
+	}
+	if _, _, err := VerifyProof(stateRoot, []byte{3}, proof); err == nil {
+		t.Fatalf("proof of key 4 shouldn't prove key 3 is absent")
+	}
+
+	// A tampered value doesn't verify
+	proof.Leaves[0].Value = []byte{1}
+	if _, _, err := VerifyProof(stateRoot, []byte{4}, proof); err != errInvalidProof {
+		t.Fatalf("tampered proof shouldn't have verified")
+	}
+
+	// Leaves that aren't adjacent don't prove a key is absent
+	_, before, err := e.Prove([]byte{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, after, err := e.Prove([]byte{6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gap := &Proof{NumLeaves: before.NumLeaves, Leaves: []ProofLeaf{before.Leaves[0], after.Leaves[0]}}
+	if _, _, err := VerifyProof(stateRoot, []byte{4}, gap); err != errProofLeaves {
+		t.Fatalf("should have failed to prove key 4 absent with leaves that aren't adjacent")
+	}
+}
+
+func TestProveEmptyState(t *testing.T) {
+	db := memdb.New()
+	e, err := New(db, prefixdb.New([]byte("state"), db), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := byte(1); i <= 2; i++ {
+		if err := e.Accept(ids.NewID([32]byte{i})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summary, proof, err := e.Prove([]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, included, err := VerifyProof(summary.StateRoot, []byte{1}, proof); err != nil || included {
+		t.Fatalf("should have proven the key absent from an empty state")
+	}
+	if _, _, err := VerifyProof(ids.NewID([32]byte{1}), []byte{1}, proof); err != errInvalidProof {
+		t.Fatalf("proof of an empty state shouldn't verify against another root")
+	}
+}
+
+func TestServiceGetProof(t *testing.T) {
+	db := memdb.New()
+	state := prefixdb.New([]byte("state"), db)
+	e, err := New(db, state, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Put([]byte{1}, []byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	for i := byte(1); i <= 2; i++ {
+		if err := e.Accept(ids.NewID([32]byte{i})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Service{epochs: e}
+
+	reply := GetProofReply{}
+	if err := s.GetProof(nil, &GetProofArgs{Key: formatting.CB58{Bytes: []byte{1}}}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case !reply.Included || !bytes.Equal(reply.Value.Bytes, []byte{2}):
+		t.Fatalf("key should have been included")
+	case reply.NumLeaves != 1 || len(reply.Leaves) != 1:
+		t.Fatalf("wrong proof: %+v", reply)
+	case !reply.StateRoot.Equals(e.Latest().StateRoot):
+		t.Fatalf("proof should be against the latest summary")
+	}
+
+	reply = GetProofReply{}
+	if err := s.GetProof(nil, &GetProofArgs{Key: formatting.CB58{Bytes: []byte{3}}}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Included {
+		t.Fatalf("key shouldn't have been included")
+	}
+}
@@ -0,0 +1,147 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package epochs
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+// CreateHandler returns the epochs API. A VM adds it to the handlers it
+// returns from CreateHandlers, usually at "/epochs".
+func (e *Epochs) CreateHandler() *common.HTTPHandler {
+	server := json.NewServer()
+	server.RegisterCodec(json.NewCodec(), "application/json")
+	server.RegisterCodec(json.NewCodec(), "application/json;charset=UTF-8")
+	server.RegisterService(&Service{epochs: e}, "epochs")
+	return &common.HTTPHandler{LockOptions: common.ReadLock, Handler: server}
+}
+
+// Service is the API of the summaries of a VM's state at the end of each epoch
+type Service struct{ epochs *Epochs }
+
+// GetSummaryArgs are the arguments for calling GetSummary
+type GetSummaryArgs struct {
+	// Number of the epoch. If omitted, the most recent epoch is returned.
+	Epoch *json.Uint64 `json:"epoch"`
+}
+
+// GetSummaryReply is the reply from calling GetSummary
+type GetSummaryReply struct {
+	Epoch     json.Uint64 `json:"epoch"`
+	Height    json.Uint64 `json:"height"`
+	BlockID   ids.ID      `json:"blockID"`
+	StateRoot ids.ID      `json:"stateRoot"`
+
+	// Serialized summary, as it's exchanged between nodes
+	Bytes formatting.CB58 `json:"bytes"`
+}
+
+// GetSummary returns the summary of the state at the end of an epoch
+func (service *Service) GetSummary(_ *http.Request, args *GetSummaryArgs, reply *GetSummaryReply) error {
+	summary := service.epochs.Latest()
+	if args.Epoch != nil {
+		epochSummary, err := service.epochs.Summary(uint64(*args.Epoch))
+		if err != nil {
+			return err
+		}
+		summary = epochSummary
+	}
+	if summary == nil {
+		return errNoEpochs
+	}
+
+	summaryBytes, err := service.epochs.Bytes(summary)
+	if err != nil {
+		return err
+	}
+	reply.Epoch = json.Uint64(summary.Epoch)
+	reply.Height = json.Uint64(summary.Height)
+	reply.BlockID = summary.BlockID
+	reply.StateRoot = summary.StateRoot
+	reply.Bytes.Bytes = summaryBytes
+	return nil
+}
+
+// GetHeightArgs are the arguments for calling GetHeight
+type GetHeightArgs struct{}
+
+// GetHeightReply is the reply from calling GetHeight
+type GetHeightReply struct {
+	Height   json.Uint64 `json:"height"`
+	Interval json.Uint64 `json:"interval"`
+}
+
+// GetHeight returns the height of the last accepted block, and the number of
+// blocks in an epoch
+func (service *Service) GetHeight(_ *http.Request, _ *GetHeightArgs, reply *GetHeightReply) error {
+	reply.Height = json.Uint64(service.epochs.Height())
+	reply.Interval = json.Uint64(service.epochs.Interval())
+	return nil
+}
+
+// GetProofArgs are the arguments for calling GetProof
+type GetProofArgs struct {
+	// Key of the state to prove
+	Key formatting.CB58 `json:"key"`
+}
+
+// APIProofLeaf is the API representation of a ProofLeaf
+type APIProofLeaf struct {
+	Index json.Uint32       `json:"index"`
+	Key   formatting.CB58   `json:"key"`
+	Value formatting.CB58   `json:"value"`
+	Path  []formatting.CB58 `json:"path"`
+}
+
+// GetProofReply is the reply from calling GetProof
+type GetProofReply struct {
+	// Summary of the state the proof is against
+	Epoch     json.Uint64 `json:"epoch"`
+	StateRoot ids.ID      `json:"stateRoot"`
+
+	// Whether the key is in the state, and if so its value
+	Included bool            `json:"included"`
+	Value    formatting.CB58 `json:"value"`
+
+	NumLeaves json.Uint32    `json:"numLeaves"`
+	Leaves    []APIProofLeaf `json:"leaves"`
+}
+
+// GetProof returns a proof that a key is or isn't in the state at the end of
+// the most recent epoch. The proof can be checked against the state root of
+// the epoch's summary, so the caller doesn't have to trust this node.
+func (service *Service) GetProof(_ *http.Request, args *GetProofArgs, reply *GetProofReply) error {
+	summary, proof, err := service.epochs.Prove(args.Key.Bytes)
+	if err != nil {
+		return err
+	}
+
+	reply.Epoch = json.Uint64(summary.Epoch)
+	reply.StateRoot = summary.StateRoot
+	reply.NumLeaves = json.Uint32(proof.NumLeaves)
+	reply.Leaves = make([]APIProofLeaf, len(proof.Leaves))
+	for i, l := range proof.Leaves {
+		path := make([]formatting.CB58, len(l.Path))
+		for j := range l.Path {
+			path[j] = formatting.CB58{Bytes: l.Path[j][:]}
+		}
+		reply.Leaves[i] = APIProofLeaf{
+			Index: json.Uint32(l.Index),
+			Key:   formatting.CB58{Bytes: l.Key},
+			Value: formatting.CB58{Bytes: l.Value},
+			Path:  path,
+		}
+		if len(proof.Leaves) == 1 && bytes.Equal(l.Key, args.Key.Bytes) {
+			reply.Included = true
+			reply.Value = reply.Leaves[i].Value
+		}
+	}
+	return nil
+}
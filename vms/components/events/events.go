@@ -0,0 +1,92 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package events lets a VM record structured events while it executes
+// transactions and blocks. The events a container emitted are persisted as its
+// receipt when it's accepted, and can be queried and streamed through the
+// events API.
+package events
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+const (
+	// MaxEvents is the most events a container may emit
+	MaxEvents = 256
+
+	// MaxAttributes is the most attributes an event may have
+	MaxAttributes = 32
+
+	// MaxTypeLen is the most bytes an event's type, or an attribute's key, may
+	// be
+	MaxTypeLen = 64
+
+	// MaxValueLen is the most bytes an attribute's value may be
+	MaxValueLen = 1024
+)
+
+var (
+	errNilEvent       = errors.New("nil event")
+	errNoType         = errors.New("event has no type")
+	errTypeTooLong    = errors.New("event type is too long")
+	errTooManyAttrs   = errors.New("event has too many attributes")
+	errNoKey          = errors.New("attribute has no key")
+	errKeyTooLong     = errors.New("attribute key is too long")
+	errValueTooLong   = errors.New("attribute value is too long")
+	errTooManyEvents  = errors.New("container emitted too many events")
+	errUnknownReceipt = errors.New("no receipt for the container")
+)
+
+// Attribute is a key/value pair that describes an event
+type Attribute struct {
+	Key   string `serialize:"true"`
+	Value string `serialize:"true"`
+}
+
+// Event is something that happened while a container was executed, such as a
+// transfer or a change of state. [Type] names what happened and is what
+// subscribers filter by.
+type Event struct {
+	Type       string      `serialize:"true"`
+	Attributes []Attribute `serialize:"true"`
+}
+
+// Verify returns nil if the event is well formed
+func (e *Event) Verify() error {
+	switch {
+	case e == nil:
+		return errNilEvent
+	case e.Type == "":
+		return errNoType
+	case len(e.Type) > MaxTypeLen:
+		return errTypeTooLong
+	case len(e.Attributes) > MaxAttributes:
+		return errTooManyAttrs
+	}
+	for _, attr := range e.Attributes {
+		switch {
+		case attr.Key == "":
+			return errNoKey
+		case len(attr.Key) > MaxTypeLen:
+			return errKeyTooLong
+		case len(attr.Value) > MaxValueLen:
+			return errValueTooLong
+		}
+	}
+	return nil
+}
+
+// Receipt is the events that an accepted container emitted
+type Receipt struct {
+	// ID of the transaction or block that emitted the events
+	ContainerID ids.ID
+
+	// Position of the receipt in the order that receipts were accepted,
+	// starting at 0
+	Index uint64 `serialize:"true"`
+
+	Events []*Event `serialize:"true"`
+}
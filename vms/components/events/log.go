@@ -0,0 +1,194 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/codec"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// Channel is the pubsub channel that accepted receipts are published to
+const Channel = "receipts"
+
+var (
+	receiptsPrefix = []byte("receipts")
+	orderPrefix    = []byte("order")
+	metaPrefix     = []byte("meta")
+
+	numReceiptsKey = []byte("numReceipts")
+)
+
+// Log records the events that processing containers emit, and persists them
+// as receipts when the containers are accepted. Like the rest of a VM's state,
+// it must only be used while the chain's lock is held.
+type Log struct {
+	ctx   *snow.Context
+	codec codec.Codec
+
+	// Key: Container ID
+	// Value: Receipt of the container
+	receipts database.Database
+
+	// Key: Index of a receipt
+	// Value: ID of the container the receipt belongs to
+	order database.Database
+
+	meta database.Database
+
+	numReceipts uint64
+
+	// Key: ID of a processing container
+	// Value: Events the container emitted
+	pending map[[32]byte][]*Event
+
+	pubsub *cjson.PubSubServer
+}
+
+// New returns a log that stores receipts in [db]. Writes aren't committed; they
+// should be committed along with the acceptance of the containers.
+func New(ctx *snow.Context, db database.Database) (*Log, error) {
+	l := &Log{
+		ctx:      ctx,
+		codec:    codec.NewDefault(),
+		receipts: prefixdb.New(receiptsPrefix, db),
+		order:    prefixdb.New(orderPrefix, db),
+		meta:     prefixdb.New(metaPrefix, db),
+		pending:  make(map[[32]byte][]*Event),
+		pubsub:   cjson.NewPubSubServer(ctx),
+	}
+
+	numReceipts, err := l.meta.Get(numReceiptsKey)
+	switch err {
+	case nil:
+		p := wrappers.Packer{Bytes: numReceipts}
+		l.numReceipts = p.UnpackLong()
+		if p.Errored() {
+			return nil, p.Err
+		}
+	case database.ErrNotFound:
+	default:
+		return nil, err
+	}
+	return l, l.pubsub.Register(Channel)
+}
+
+// Record that the processing container [containerID] emitted [events]. The
+// events replace any that were recorded for the container before, so a
+// container that's verified again doesn't emit its events twice.
+func (l *Log) Record(containerID ids.ID, events ...*Event) error {
+	if len(events) > MaxEvents {
+		return errTooManyEvents
+	}
+	for _, event := range events {
+		if err := event.Verify(); err != nil {
+			return err
+		}
+	}
+	l.pending[containerID.Key()] = events
+	return nil
+}
+
+// Pending returns the events that the processing container [containerID]
+// emitted
+func (l *Log) Pending(containerID ids.ID) []*Event { return l.pending[containerID.Key()] }
+
+// Accept persists the events that [containerID] emitted as its receipt, and
+// publishes the receipt to subscribers. A container that emitted no events
+// has no receipt.
+func (l *Log) Accept(containerID ids.ID) error {
+	key := containerID.Key()
+	events, ok := l.pending[key]
+	delete(l.pending, key)
+	if !ok || len(events) == 0 {
+		return nil
+	}
+
+	receipt := &Receipt{
+		ContainerID: containerID,
+		Index:       l.numReceipts,
+		Events:      events,
+	}
+	receiptBytes, err := l.codec.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		l.receipts.Put(containerID.Bytes(), receiptBytes),
+		l.order.Put(indexKey(receipt.Index), containerID.Bytes()),
+		l.meta.Put(numReceiptsKey, indexKey(receipt.Index+1)),
+	)
+	if errs.Errored() {
+		return errs.Err
+	}
+	l.numReceipts++
+
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	l.pubsub.PublishFiltered(Channel, newAPIReceipt(receipt), types)
+	return nil
+}
+
+// Reject drops the events that [containerID] emitted
+func (l *Log) Reject(containerID ids.ID) { delete(l.pending, containerID.Key()) }
+
+// NumReceipts returns the number of receipts that have been accepted
+func (l *Log) NumReceipts() uint64 { return l.numReceipts }
+
+// Receipt returns the receipt of the accepted container [containerID]
+func (l *Log) Receipt(containerID ids.ID) (*Receipt, error) {
+	receiptBytes, err := l.receipts.Get(containerID.Bytes())
+	switch err {
+	case nil:
+	case database.ErrNotFound:
+		return nil, errUnknownReceipt
+	default:
+		return nil, err
+	}
+
+	receipt := &Receipt{}
+	if err := l.codec.Unmarshal(receiptBytes, receipt); err != nil {
+		return nil, err
+	}
+	receipt.ContainerID = containerID
+	return receipt, nil
+}
+
+// Receipts returns at most [limit] receipts, in the order they were accepted,
+// starting with the receipt at [startIndex]
+func (l *Log) Receipts(startIndex uint64, limit int) ([]*Receipt, error) {
+	it := l.order.NewIteratorWithStart(indexKey(startIndex))
+	defer it.Release()
+
+	receipts := []*Receipt(nil)
+	for len(receipts) < limit && it.Next() {
+		containerID, err := ids.ToID(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		receipt, err := l.Receipt(containerID)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, it.Error()
+}
+
+// indexKey returns the key of the receipt at [index]. Keys are big endian so
+// that iterating over them visits receipts in the order they were accepted.
+func indexKey(index uint64) []byte {
+	p := wrappers.Packer{Bytes: make([]byte, wrappers.LongLen)}
+	p.PackLong(index)
+	return p.Bytes
+}
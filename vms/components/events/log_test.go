@@ -0,0 +1,176 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+func transferEvent(amount string) *Event {
+	return &Event{
+		Type:       "transfer",
+		Attributes: []Attribute{{Key: "amount", Value: amount}},
+	}
+}
+
+func TestEventVerify(t *testing.T) {
+	if err := transferEvent("5").Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []*Event{
+		nil,
+		{},
+		{Type: strings.Repeat("a", MaxTypeLen+1)},
+		{Type: "transfer", Attributes: make([]Attribute, MaxAttributes+1)},
+		{Type: "transfer", Attributes: []Attribute{{Value: "5"}}},
+		{Type: "transfer", Attributes: []Attribute{{Key: strings.Repeat("a", MaxTypeLen+1)}}},
+		{Type: "transfer", Attributes: []Attribute{{Key: "amount", Value: strings.Repeat("5", MaxValueLen+1)}}},
+	}
+	for _, event := range invalid {
+		if err := event.Verify(); err == nil {
+			t.Fatalf("event %+v should have failed verification", event)
+		}
+	}
+}
+
+func TestLog(t *testing.T) {
+	db := memdb.New()
+	l, err := New(snow.DefaultContextTest(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blk0 := ids.Empty.Prefix(0)
+	blk1 := ids.Empty.Prefix(1)
+	blk2 := ids.Empty.Prefix(2)
+	blk3 := ids.Empty.Prefix(3)
+
+	if err := l.Record(blk0, transferEvent("1"), &Event{}); err == nil {
+		t.Fatalf("should have failed to record an invalid event")
+	}
+	if err := l.Record(blk0, make([]*Event, MaxEvents+1)...); err == nil {
+		t.Fatalf("should have failed to record too many events")
+	}
+
+	if err := l.Record(blk0, transferEvent("1")); err != nil {
+		t.Fatal(err)
+	}
+	// Recording again replaces the events
+	if err := l.Record(blk0, transferEvent("2"), transferEvent("3")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(blk1, transferEvent("4")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(blk2); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(blk3, transferEvent("5")); err != nil {
+		t.Fatal(err)
+	}
+	if pending := l.Pending(blk0); len(pending) != 2 {
+		t.Fatalf("should have 2 pending events but has %d", len(pending))
+	}
+
+	l.Reject(blk1)
+	for _, blkID := range []ids.ID{blk0, blk1, blk2, blk3} {
+		if err := l.Accept(blkID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.NumReceipts() != 2 {
+		t.Fatalf("should have 2 receipts but has %d", l.NumReceipts())
+	}
+	for _, blkID := range []ids.ID{blk1, blk2} {
+		if _, err := l.Receipt(blkID); err == nil {
+			t.Fatalf("%s shouldn't have a receipt", blkID)
+		}
+	}
+
+	receipt, err := l.Receipt(blk0)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case !receipt.ContainerID.Equals(blk0):
+		t.Fatalf("receipt has the wrong container ID")
+	case receipt.Index != 0:
+		t.Fatalf("receipt should have index 0 but has %d", receipt.Index)
+	case len(receipt.Events) != 2:
+		t.Fatalf("receipt should have 2 events but has %d", len(receipt.Events))
+	case receipt.Events[1].Attributes[0].Value != "3":
+		t.Fatalf("receipt has the wrong events")
+	}
+
+	// The receipts are persisted
+	l, err = New(snow.DefaultContextTest(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.NumReceipts() != 2 {
+		t.Fatalf("should have 2 receipts but has %d", l.NumReceipts())
+	}
+	receipts, err := l.Receipts(1, 10)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(receipts) != 1:
+		t.Fatalf("should have returned 1 receipt but returned %d", len(receipts))
+	case !receipts[0].ContainerID.Equals(blk3):
+		t.Fatalf("returned the wrong receipt")
+	case receipts[0].Index != 1:
+		t.Fatalf("receipt should have index 1 but has %d", receipts[0].Index)
+	}
+}
+
+func TestService(t *testing.T) {
+	l, err := New(snow.DefaultContextTest(), memdb.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 3; i++ {
+		blkID := ids.Empty.Prefix(i)
+		if err := l.Record(blkID, transferEvent("1")); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Accept(blkID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if handlers := l.CreateHandlers(); len(handlers) != 2 {
+		t.Fatalf("should have 2 handlers but has %d", len(handlers))
+	}
+	service := &Service{log: l}
+
+	receiptReply := APIReceipt{}
+	if err := service.GetReceipt(nil, &GetReceiptArgs{ContainerID: ids.Empty.Prefix(1)}, &receiptReply); err != nil {
+		t.Fatal(err)
+	}
+	if receiptReply.Index != 1 || len(receiptReply.Events) != 1 || receiptReply.Events[0].Type != "transfer" {
+		t.Fatalf("returned the wrong receipt: %+v", receiptReply)
+	}
+	if err := service.GetReceipt(nil, &GetReceiptArgs{}, &receiptReply); err == nil {
+		t.Fatalf("should have failed without a container ID")
+	}
+
+	receiptsReply := GetReceiptsReply{}
+	if err := service.GetReceipts(nil, &GetReceiptsArgs{StartIndex: 1, Limit: 1}, &receiptsReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(receiptsReply.Receipts) != 1 || receiptsReply.NextIndex != 2 {
+		t.Fatalf("returned the wrong receipts: %+v", receiptsReply)
+	}
+	if !receiptsReply.Receipts[0].ContainerID.Equals(ids.Empty.Prefix(1)) {
+		t.Fatalf("returned the wrong receipt")
+	}
+	if err := service.GetReceipts(nil, &GetReceiptsArgs{StartIndex: json.Uint64(0)}, &receiptsReply); err == nil {
+		t.Fatalf("should have failed without a limit")
+	}
+}
@@ -0,0 +1,136 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+// MaxPageSize is the most receipts GetReceipts returns
+const MaxPageSize = 1024
+
+var (
+	errNilID   = errors.New("container ID must be non-empty")
+	errNoLimit = errors.New("limit must be positive")
+)
+
+// CreateHandlers returns the events API, served at "/events", and the stream
+// of accepted receipts, served at "/events/pubsub". A VM adds them to the
+// handlers it returns from CreateHandlers.
+func (l *Log) CreateHandlers() map[string]*common.HTTPHandler {
+	server := json.NewServer()
+	server.RegisterCodec(json.NewCodec(), "application/json")
+	server.RegisterCodec(json.NewCodec(), "application/json;charset=UTF-8")
+	server.RegisterService(&Service{log: l}, "events")
+	return map[string]*common.HTTPHandler{
+		"/events":        &common.HTTPHandler{LockOptions: common.ReadLock, Handler: server},
+		"/events/pubsub": &common.HTTPHandler{LockOptions: common.NoLock, Handler: l.pubsub},
+	}
+}
+
+// Service is the API of the events that containers emitted
+type Service struct{ log *Log }
+
+// APIAttribute is the API representation of an attribute
+type APIAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// APIEvent is the API representation of an event
+type APIEvent struct {
+	Type       string         `json:"type"`
+	Attributes []APIAttribute `json:"attributes"`
+}
+
+// APIReceipt is the API representation of a receipt
+type APIReceipt struct {
+	ContainerID ids.ID      `json:"containerID"`
+	Index       json.Uint64 `json:"index"`
+	Events      []APIEvent  `json:"events"`
+}
+
+func newAPIReceipt(receipt *Receipt) APIReceipt {
+	events := make([]APIEvent, len(receipt.Events))
+	for i, event := range receipt.Events {
+		attrs := make([]APIAttribute, len(event.Attributes))
+		for j, attr := range event.Attributes {
+			attrs[j] = APIAttribute{Key: attr.Key, Value: attr.Value}
+		}
+		events[i] = APIEvent{Type: event.Type, Attributes: attrs}
+	}
+	return APIReceipt{
+		ContainerID: receipt.ContainerID,
+		Index:       json.Uint64(receipt.Index),
+		Events:      events,
+	}
+}
+
+// GetReceiptArgs are the arguments to GetReceipt
+type GetReceiptArgs struct {
+	ContainerID ids.ID `json:"containerID"`
+}
+
+// GetReceipt returns the events that the accepted transaction or block
+// [args.ContainerID] emitted
+func (service *Service) GetReceipt(_ *http.Request, args *GetReceiptArgs, reply *APIReceipt) error {
+	service.log.ctx.Log.Verbo("GetReceipt called with %s", args.ContainerID)
+
+	if args.ContainerID.IsZero() {
+		return errNilID
+	}
+	receipt, err := service.log.Receipt(args.ContainerID)
+	if err != nil {
+		return err
+	}
+	*reply = newAPIReceipt(receipt)
+	return nil
+}
+
+// GetReceiptsArgs are the arguments to GetReceipts
+type GetReceiptsArgs struct {
+	// Index of the first receipt to return
+	StartIndex json.Uint64 `json:"startIndex"`
+
+	// Most receipts to return. At most MaxPageSize are returned.
+	Limit json.Uint64 `json:"limit"`
+}
+
+// GetReceiptsReply is the reply from GetReceipts
+type GetReceiptsReply struct {
+	Receipts []APIReceipt `json:"receipts"`
+
+	// Index to start at to get the next receipts
+	NextIndex json.Uint64 `json:"nextIndex"`
+}
+
+// GetReceipts returns receipts in the order they were accepted, starting with
+// the receipt at [args.StartIndex]
+func (service *Service) GetReceipts(_ *http.Request, args *GetReceiptsArgs, reply *GetReceiptsReply) error {
+	service.log.ctx.Log.Verbo("GetReceipts called with start index %d and limit %d", args.StartIndex, args.Limit)
+
+	if args.Limit == 0 {
+		return errNoLimit
+	}
+	limit := uint64(args.Limit)
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	receipts, err := service.log.Receipts(uint64(args.StartIndex), int(limit))
+	if err != nil {
+		return err
+	}
+	reply.Receipts = make([]APIReceipt, len(receipts))
+	for i, receipt := range receipts {
+		reply.Receipts[i] = newAPIReceipt(receipt)
+	}
+	reply.NextIndex = args.StartIndex + json.Uint64(len(receipts))
+	return nil
+}
@@ -0,0 +1,99 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Metrics are the metrics that each of the bundled VMs reports, so that every
+// chain can be monitored the same way, regardless of the VM it runs.
+//
+// The metrics are created by Initialize, so that the VM can update them from
+// then on, and are only reported once they're registered by Register.
+type Metrics struct {
+	txsAccepted, txsRejected    prometheus.Counter
+	utxosCreated, utxosConsumed prometheus.Counter
+	mempoolTxs                  prometheus.Gauge
+	verifyDuration              prometheus.Histogram
+}
+
+// Initialize the metrics of the chain whose metrics namespace is [namespace]
+func (m *Metrics) Initialize(namespace string) {
+	m.txsAccepted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "vm_txs_accepted",
+			Help:      "Number of transactions accepted",
+		})
+	m.txsRejected = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "vm_txs_rejected",
+			Help:      "Number of transactions rejected",
+		})
+	m.utxosCreated = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "vm_utxos_created",
+			Help:      "Number of UTXOs created by accepted transactions. Less vm_utxos_consumed, this is the growth of the UTXO set",
+		})
+	m.utxosConsumed = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "vm_utxos_consumed",
+			Help:      "Number of UTXOs consumed by accepted transactions",
+		})
+	m.mempoolTxs = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "vm_mempool_txs",
+			Help:      "Number of transactions waiting to be issued",
+		})
+	m.verifyDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "vm_verify_duration",
+			Help:      "Time spent verifying a container, in milliseconds",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 16),
+		})
+}
+
+// Register the metrics with [registerer]
+func (m *Metrics) Register(registerer prometheus.Registerer) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.txsAccepted),
+		registerer.Register(m.txsRejected),
+		registerer.Register(m.utxosCreated),
+		registerer.Register(m.utxosConsumed),
+		registerer.Register(m.mempoolTxs),
+		registerer.Register(m.verifyDuration),
+	)
+	return errs.Err
+}
+
+// Accepted records that [numTxs] transactions were accepted, which created
+// [numCreated] UTXOs and consumed [numConsumed] UTXOs
+func (m *Metrics) Accepted(numTxs, numCreated, numConsumed int) {
+	m.txsAccepted.Add(float64(numTxs))
+	m.utxosCreated.Add(float64(numCreated))
+	m.utxosConsumed.Add(float64(numConsumed))
+}
+
+// Rejected records that [numTxs] transactions were rejected
+func (m *Metrics) Rejected(numTxs int) { m.txsRejected.Add(float64(numTxs)) }
+
+// Verified records that a container, whose verification started at [start],
+// was verified
+func (m *Metrics) Verified(start time.Time) {
+	m.verifyDuration.Observe(float64(time.Since(start)) / float64(time.Millisecond))
+}
+
+// SetMempoolTxs records that [numTxs] transactions are waiting to be issued
+func (m *Metrics) SetMempoolTxs(numTxs int) { m.mempoolTxs.Set(float64(numTxs)) }
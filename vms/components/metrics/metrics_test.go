@@ -0,0 +1,63 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetrics(t *testing.T) {
+	m := Metrics{}
+	m.Initialize("test")
+
+	// The metrics can be updated before they're registered
+	m.Accepted(2, 3, 1)
+	m.Rejected(1)
+	m.SetMempoolTxs(4)
+	m.Verified(time.Now())
+
+	registry := prometheus.NewRegistry()
+	if err := m.Register(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		metric := family.GetMetric()[0]
+		switch {
+		case metric.Counter != nil:
+			values[family.GetName()] = metric.Counter.GetValue()
+		case metric.Gauge != nil:
+			values[family.GetName()] = metric.Gauge.GetValue()
+		case metric.Histogram != nil:
+			values[family.GetName()] = float64(metric.Histogram.GetSampleCount())
+		}
+	}
+
+	expected := map[string]float64{
+		"test_vm_txs_accepted":    2,
+		"test_vm_txs_rejected":    1,
+		"test_vm_utxos_created":   3,
+		"test_vm_utxos_consumed":  1,
+		"test_vm_mempool_txs":     4,
+		"test_vm_verify_duration": 1,
+	}
+	for name, value := range expected {
+		if values[name] != value {
+			t.Fatalf("%s should be %f but is %f", name, value, values[name])
+		}
+	}
+
+	// Registering the metrics of a chain twice fails
+	if err := m.Register(registry); err == nil {
+		t.Fatalf("Should have failed to register the metrics twice")
+	}
+}
@@ -0,0 +1,114 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package shared
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+)
+
+var (
+	utxoPrefix  = []byte("utxo")
+	fundsPrefix = []byte("funds")
+)
+
+// State is the set of UTXOs, in a region of the shared memory, that were
+// exported to one of the chains sharing the region
+type State struct {
+	utxos database.Database
+	funds database.Database
+}
+
+// NewState returns the UTXOs in the shared region [db] that were exported to
+// the chain [chainID]
+func NewState(db database.Database, chainID ids.ID) *State {
+	chainDB := prefixdb.New(chainID.Bytes(), db)
+	return &State{
+		utxos: prefixdb.New(utxoPrefix, chainDB),
+		funds: prefixdb.New(fundsPrefix, chainDB),
+	}
+}
+
+// UTXO returns the UTXO with ID [id]
+func (s *State) UTXO(id ids.ID) (*UTXO, error) {
+	b, err := s.utxos.Get(id.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	utxo := &UTXO{}
+	if err := Codec.Unmarshal(b, utxo); err != nil {
+		return nil, err
+	}
+	return utxo, nil
+}
+
+// FundUTXO adds [utxo] to the set, indexed by each of the addresses that own
+// it
+func (s *State) FundUTXO(utxo *UTXO) error {
+	b, err := Codec.Marshal(utxo)
+	if err != nil {
+		return err
+	}
+	utxoID := utxo.ID()
+	if err := s.utxos.Put(utxoID.Bytes(), b); err != nil {
+		return err
+	}
+	for _, addr := range utxo.Out.Addrs {
+		if err := s.funds.Put(fundsKey(addr, utxoID), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SpendUTXO removes the UTXO with ID [id] from the set
+func (s *State) SpendUTXO(id ids.ID) error {
+	utxo, err := s.UTXO(id)
+	if err != nil {
+		return err
+	}
+	for _, addr := range utxo.Out.Addrs {
+		if err := s.funds.Delete(fundsKey(addr, id)); err != nil {
+			return err
+		}
+	}
+	return s.utxos.Delete(id.Bytes())
+}
+
+// Funds returns the IDs of the UTXOs that [addr] is one of the owners of
+func (s *State) Funds(addr ids.ShortID) ([]ids.ID, error) {
+	return s.PaginatedFunds(addr, ids.ID{}, -1)
+}
+
+// PaginatedFunds returns, in order, at most [limit] of the IDs of the UTXOs
+// that [addr] is one of the owners of. Only the IDs after [start] are
+// returned, unless [start] is the zero ID. If [limit] is negative, all of them
+// are returned.
+func (s *State) PaginatedFunds(addr ids.ShortID, start ids.ID, limit int) ([]ids.ID, error) {
+	prefix := addr.Bytes()
+	startKey := prefix
+	if !start.IsZero() {
+		startKey = fundsKey(addr, start)
+	}
+	iter := s.funds.NewIteratorWithStartAndPrefix(startKey, prefix)
+	defer iter.Release()
+
+	utxoIDs := []ids.ID(nil)
+	for (limit < 0 || len(utxoIDs) < limit) && iter.Next() {
+		utxoID, err := ids.ToID(iter.Key()[len(prefix):])
+		if err != nil {
+			return nil, err
+		}
+		if utxoID.Equals(start) {
+			continue
+		}
+		utxoIDs = append(utxoIDs, utxoID)
+	}
+	return utxoIDs, iter.Error()
+}
+
+func fundsKey(addr ids.ShortID, utxoID ids.ID) []byte {
+	return append(addr.Bytes(), utxoID.Bytes()...)
+}
@@ -0,0 +1,126 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package shared
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+func TestStateFundAndSpend(t *testing.T) {
+	db := memdb.New()
+	chainID := ids.Empty.Prefix(0)
+	otherChainID := ids.Empty.Prefix(1)
+
+	addr0 := ids.NewShortID([20]byte{1})
+	addr1 := ids.NewShortID([20]byte{2})
+	utxo := &UTXO{
+		TxID:        ids.Empty.Prefix(2),
+		OutputIndex: 1,
+		AssetID:     ids.Empty.Prefix(3),
+		Out: secp256k1fx.TransferOutput{
+			Amt: 12345,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr0, addr1},
+			},
+		},
+	}
+	if err := utxo.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	state := NewState(db, chainID)
+	if err := state.FundUTXO(utxo); err != nil {
+		t.Fatal(err)
+	}
+
+	utxoID := utxo.ID()
+	if fetched, err := state.UTXO(utxoID); err != nil {
+		t.Fatal(err)
+	} else if fetched.Out.Amt != utxo.Out.Amt || !fetched.AssetID.Equals(utxo.AssetID) {
+		t.Fatalf("Fetched the wrong utxo")
+	}
+	for _, addr := range []ids.ShortID{addr0, addr1} {
+		if utxoIDs, err := state.Funds(addr); err != nil {
+			t.Fatal(err)
+		} else if len(utxoIDs) != 1 || !utxoIDs[0].Equals(utxoID) {
+			t.Fatalf("Funds of %s should be exactly the funded utxo", addr)
+		}
+	}
+
+	if utxoIDs, err := NewState(db, otherChainID).Funds(addr0); err != nil {
+		t.Fatal(err)
+	} else if len(utxoIDs) != 0 {
+		t.Fatalf("UTXOs exported to one chain shouldn't be visible to another")
+	}
+
+	if err := state.SpendUTXO(utxoID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := state.UTXO(utxoID); err == nil {
+		t.Fatalf("Spent utxo shouldn't exist")
+	}
+	if utxoIDs, err := state.Funds(addr0); err != nil {
+		t.Fatal(err)
+	} else if len(utxoIDs) != 0 {
+		t.Fatalf("Spent utxo shouldn't be indexed")
+	}
+}
+
+func TestStatePaginatedFunds(t *testing.T) {
+	state := NewState(memdb.New(), ids.Empty.Prefix(0))
+
+	addr := ids.NewShortID([20]byte{1})
+	for i := uint32(0); i < 5; i++ {
+		utxo := &UTXO{
+			TxID:        ids.Empty.Prefix(1),
+			OutputIndex: i,
+			AssetID:     ids.Empty.Prefix(2),
+			Out: secp256k1fx.TransferOutput{
+				Amt: 1,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		}
+		if err := state.FundUTXO(utxo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := state.Funds(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 5 {
+		t.Fatalf("Should have returned 5 utxoIDs but returned %d", len(all))
+	}
+
+	paged := []ids.ID(nil)
+	start := ids.ID{}
+	for {
+		page, err := state.PaginatedFunds(addr, start, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paged = append(paged, page...)
+		if len(page) < 2 {
+			break
+		}
+		start = page[len(page)-1]
+	}
+	if len(paged) != len(all) {
+		t.Fatalf("Pages should hold %d utxoIDs but hold %d", len(all), len(paged))
+	}
+	for i, utxoID := range paged {
+		if !utxoID.Equals(all[i]) {
+			t.Fatalf("utxoID %d should be %s but is %s", i, all[i], utxoID)
+		}
+	}
+}
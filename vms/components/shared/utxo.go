@@ -0,0 +1,56 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package shared defines how UTXOs that are moved between chains are stored
+// in the memory the chains share. Chains run different VMs, so the UTXOs are
+// stored in a format that doesn't depend on the codec of either VM.
+package shared
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilUTXO   = errors.New("nil utxo is not valid")
+	errNilTxID   = errors.New("nil tx ID is not valid")
+	errNilAsset  = errors.New("nil asset ID is not valid")
+	errEmptyUTXO = errors.New("utxo has no value")
+)
+
+// Codec serializes the UTXOs in the shared memory
+var Codec = codec.NewDefault()
+
+// UTXO is an output that was exported by one chain to be imported by another
+type UTXO struct {
+	// TxID is the ID of the transaction that exported this UTXO
+	TxID ids.ID `serialize:"true"`
+	// OutputIndex is the index of this UTXO among the outputs of that
+	// transaction
+	OutputIndex uint32 `serialize:"true"`
+
+	AssetID ids.ID                     `serialize:"true"`
+	Out     secp256k1fx.TransferOutput `serialize:"true"`
+}
+
+// ID returns the unique ID of this UTXO
+func (utxo *UTXO) ID() ids.ID { return utxo.TxID.Prefix(uint64(utxo.OutputIndex)) }
+
+// Verify implements the verify.Verifiable interface
+func (utxo *UTXO) Verify() error {
+	switch {
+	case utxo == nil:
+		return errNilUTXO
+	case utxo.TxID.IsZero():
+		return errNilTxID
+	case utxo.AssetID.IsZero():
+		return errNilAsset
+	case utxo.Out.Amt == 0:
+		return errEmptyUTXO
+	default:
+		return utxo.Out.OutputOwners.Verify()
+	}
+}
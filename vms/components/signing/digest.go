@@ -0,0 +1,27 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package signing
+
+import (
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Digest returns the hash that a key signs to authorize the transaction with
+// unsigned bytes [unsignedBytes] on the chain [chainID] of the network
+// [networkID].
+//
+// Because the digest commits to the network and the chain, a signature is only
+// valid on the chain it was made for, so a transaction can't be replayed on
+// another network or chain that uses the same keys. VMs whose unsigned bytes
+// don't already include both IDs should sign this digest rather than the
+// unsigned bytes.
+func Digest(networkID uint32, chainID ids.ID, unsignedBytes []byte) []byte {
+	p := wrappers.Packer{MaxSize: wrappers.IntLen + hashing.HashLen + len(unsignedBytes)}
+	p.PackInt(networkID)
+	p.PackFixedBytes(chainID.Bytes())
+	p.PackFixedBytes(unsignedBytes)
+	return hashing.ComputeHash256(p.Bytes)
+}
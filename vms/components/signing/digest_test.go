@@ -0,0 +1,61 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package signing
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+)
+
+func TestDigest(t *testing.T) {
+	chainID := ids.NewID([32]byte{1})
+	unsignedBytes := []byte{2, 3, 4}
+
+	digest := Digest(12345, chainID, unsignedBytes)
+	if !bytes.Equal(digest, Digest(12345, chainID, unsignedBytes)) {
+		t.Fatalf("digest should be deterministic")
+	}
+	if bytes.Equal(digest, Digest(1, chainID, unsignedBytes)) {
+		t.Fatalf("digest should commit to the network ID")
+	}
+	if bytes.Equal(digest, Digest(12345, ids.NewID([32]byte{5}), unsignedBytes)) {
+		t.Fatalf("digest should commit to the chain ID")
+	}
+	if bytes.Equal(digest, Digest(12345, chainID, []byte{2, 3})) {
+		t.Fatalf("digest should commit to the unsigned bytes")
+	}
+}
+
+func TestDigestReplay(t *testing.T) {
+	factory := crypto.FactorySECP256K1R{}
+	skIntf, err := factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk := skIntf.(*crypto.PrivateKeySECP256K1R)
+
+	chainID := ids.NewID([32]byte{1})
+	unsignedBytes := []byte{2, 3, 4}
+
+	sig, err := sk.SignHash(Digest(1, chainID, unsignedBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk, err := factory.RecoverHashPublicKey(Digest(1, chainID, unsignedBytes), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pk.Address().Equals(sk.PublicKey().Address()) {
+		t.Fatalf("signature should recover the signer on the chain it was made for")
+	}
+
+	pk, err = factory.RecoverHashPublicKey(Digest(2, chainID, unsignedBytes), sig)
+	if err == nil && pk.Address().Equals(sk.PublicKey().Address()) {
+		t.Fatalf("signature shouldn't recover the signer on another network")
+	}
+}
@@ -5,6 +5,7 @@ package evm
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/rlp"
@@ -28,12 +29,14 @@ func (b *Block) ID() ids.ID { return b.id }
 func (b *Block) Accept() {
 	b.vm.ctx.Log.Verbo("Block %s is accepted", b.ID())
 	b.vm.updateStatus(b.ID(), choices.Accepted)
+	b.vm.metrics.accepted(b.ethBlock)
 }
 
 // Reject implements the snowman.Block interface
 func (b *Block) Reject() {
 	b.vm.ctx.Log.Verbo("Block %s is rejected", b.ID())
 	b.vm.updateStatus(b.ID(), choices.Rejected)
+	b.vm.metrics.rejected(b.ethBlock)
 }
 
 // Status implements the snowman.Block interface
@@ -59,6 +62,8 @@ func (b *Block) Parent() snowman.Block {
 
 // Verify implements the snowman.Block interface
 func (b *Block) Verify() error {
+	defer b.vm.metrics.verified(time.Now())
+
 	_, err := b.vm.chain.InsertChain([]*types.Block{b.ethBlock})
 	return err
 }
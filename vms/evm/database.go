@@ -51,12 +51,12 @@ func (db Database) NewIterator() ethdb.Iterator { return db.Database.NewIterator
 
 // NewIteratorWithPrefix implements ethdb.Database
 func (db Database) NewIteratorWithPrefix(prefix []byte) ethdb.Iterator {
-	return db.NewIteratorWithPrefix(prefix)
+	return db.Database.NewIteratorWithPrefix(prefix)
 }
 
 // NewIteratorWithStart implements ethdb.Database
 func (db Database) NewIteratorWithStart(start []byte) ethdb.Iterator {
-	return db.NewIteratorWithStart(start)
+	return db.Database.NewIteratorWithStart(start)
 }
 
 // Batch implements ethdb.Batch
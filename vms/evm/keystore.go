@@ -0,0 +1,76 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/common/hexutil"
+	"github.com/ava-labs/go-ethereum/core/types"
+	"github.com/ava-labs/go-ethereum/crypto"
+	"github.com/ava-labs/go-ethereum/rlp"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+var (
+	errNoKeystore = errors.New("this chain doesn't have access to the keystore")
+)
+
+// KeystoreAPI signs transactions with keys held in the node's keystore. Keys
+// are stored in the user's database for this chain, and transactions are
+// signed for this chain's ID, so a signature can't be replayed on another
+// chain.
+type KeystoreAPI struct{ vm *VM }
+
+// ImportKey adds [privateKey] to the keys [username] holds for this chain and
+// returns its address
+func (api *KeystoreAPI) ImportKey(ctx context.Context, username, password string, privateKey hexutil.Bytes) (common.Address, error) {
+	sk, err := crypto.ToECDSA(privateKey)
+	if err != nil {
+		return common.Address{}, err
+	}
+	db, err := api.vm.userDatabase(username, password)
+	if err != nil {
+		return common.Address{}, err
+	}
+	addr := crypto.PubkeyToAddress(sk.PublicKey)
+	return addr, db.Put(addr.Bytes(), crypto.FromECDSA(sk))
+}
+
+// SignTransaction signs [tx], an RLP encoded transaction, with the key of
+// [from] that [username] holds and returns the signed transaction
+func (api *KeystoreAPI) SignTransaction(ctx context.Context, username, password string, from common.Address, tx hexutil.Bytes) (hexutil.Bytes, error) {
+	unsignedTx := new(types.Transaction)
+	if err := rlp.DecodeBytes(tx, unsignedTx); err != nil {
+		return nil, err
+	}
+	db, err := api.vm.userDatabase(username, password)
+	if err != nil {
+		return nil, err
+	}
+	skBytes, err := db.Get(from.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	sk, err := crypto.ToECDSA(skBytes)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := types.SignTx(unsignedTx, types.NewEIP155Signer(api.vm.chainID), sk)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(signedTx)
+}
+
+// userDatabase returns the database [username] has for this chain
+func (vm *VM) userDatabase(username, password string) (database.Database, error) {
+	if vm.ctx.Keystore == nil {
+		return nil, errNoKeystore
+	}
+	return vm.ctx.Keystore.GetDatabase(username, password)
+}
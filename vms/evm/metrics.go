@@ -0,0 +1,90 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/go-ethereum/core/types"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+
+	vmmetrics "github.com/ava-labs/gecko/vms/components/metrics"
+)
+
+type metrics struct {
+	numBlocksAccepted, numTxsAccepted, gasUsed prometheus.Counter
+	blockGasUsed, blockGasLimit                prometheus.Gauge
+
+	// the metrics that every VM reports
+	common vmmetrics.Metrics
+}
+
+// Initialize the metrics
+func (m *metrics) Initialize(namespace string) {
+	m.common.Initialize(namespace)
+	m.numBlocksAccepted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "blocks_accepted",
+			Help:      "Number of blocks accepted",
+		})
+	m.numTxsAccepted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "txs_accepted",
+			Help:      "Number of transactions in accepted blocks",
+		})
+	m.gasUsed = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "gas_used",
+			Help:      "Amount of gas used by accepted blocks",
+		})
+	m.blockGasUsed = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "block_gas_used",
+			Help:      "Amount of gas used by the last accepted block",
+		})
+	m.blockGasLimit = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "block_gas_limit",
+			Help:      "Gas limit of the last accepted block",
+		})
+}
+
+// Register the metrics with [registerer]
+func (m *metrics) Register(registerer prometheus.Registerer) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		m.common.Register(registerer),
+		registerer.Register(m.numBlocksAccepted),
+		registerer.Register(m.numTxsAccepted),
+		registerer.Register(m.gasUsed),
+		registerer.Register(m.blockGasUsed),
+		registerer.Register(m.blockGasLimit),
+	)
+	return errs.Err
+}
+
+// accepted records that [blk] was accepted
+func (m *metrics) accepted(blk *types.Block) {
+	m.numBlocksAccepted.Inc()
+	m.numTxsAccepted.Add(float64(len(blk.Transactions())))
+	m.gasUsed.Add(float64(blk.GasUsed()))
+	m.blockGasUsed.Set(float64(blk.GasUsed()))
+	m.blockGasLimit.Set(float64(blk.GasLimit()))
+	m.common.Accepted(len(blk.Transactions()), 0, 0)
+}
+
+// rejected records that [blk] was rejected
+func (m *metrics) rejected(blk *types.Block) { m.common.Rejected(len(blk.Transactions())) }
+
+// verified records that a block, whose verification started at [start], was
+// verified
+func (m *metrics) verified(start time.Time) { m.common.Verified(start) }
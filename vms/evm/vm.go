@@ -18,6 +18,8 @@ import (
 	"github.com/ava-labs/coreth/eth"
 	"github.com/ava-labs/coreth/node"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/ava-labs/go-ethereum/common"
 	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/rlp"
@@ -51,11 +53,12 @@ const (
 )
 
 var (
-	errEmptyBlock     = errors.New("empty block")
-	errCreateBlock    = errors.New("couldn't create block")
-	errUnknownBlock   = errors.New("unknown block")
-	errBlockFrequency = errors.New("too frequent block issuance")
-	errUnsupportedFXs = errors.New("unsupported feature extensions")
+	errEmptyBlock      = errors.New("empty block")
+	errCreateBlock     = errors.New("couldn't create block")
+	errUnknownBlock    = errors.New("unknown block")
+	errBlockFrequency  = errors.New("too frequent block issuance")
+	errUnsupportedFXs  = errors.New("unsupported feature extensions")
+	errHandlersCreated = errors.New("can't register an API after the handlers were created")
 )
 
 func maxDuration(x, y time.Duration) time.Duration {
@@ -94,6 +97,26 @@ type VM struct {
 
 	genlock      sync.Mutex
 	txSubmitChan <-chan struct{}
+
+	metrics metrics
+
+	// APIs, by namespace, that are served alongside the Ethereum APIs
+	rpcServices     map[string]interface{}
+	handlersCreated bool
+}
+
+// RegisterRPCService registers [service] to be served by this chain's
+// JSON-RPC handlers under [namespace]. Must be called before the handlers are
+// created.
+func (vm *VM) RegisterRPCService(namespace string, service interface{}) error {
+	if vm.handlersCreated {
+		return errHandlersCreated
+	}
+	if vm.rpcServices == nil {
+		vm.rpcServices = make(map[string]interface{})
+	}
+	vm.rpcServices[namespace] = service
+	return nil
 }
 
 /*
@@ -124,6 +147,8 @@ func (vm *VM) Initialize(
 
 	vm.chainID = g.Config.ChainID
 
+	vm.metrics.Initialize(ctx.Namespace)
+
 	config := eth.DefaultConfig
 	config.ManualCanonical = true
 	config.Genesis = g
@@ -257,6 +282,11 @@ func (vm *VM) Shutdown() {
 	vm.chain.Stop()
 }
 
+// Metrics implements the snowman.ChainVM interface
+func (vm *VM) Metrics(registerer prometheus.Registerer) error {
+	return vm.metrics.Register(registerer)
+}
+
 // BuildBlock implements the snowman.ChainVM interface
 func (vm *VM) BuildBlock() (snowman.Block, error) {
 	vm.chain.GenBlock()
@@ -330,6 +360,13 @@ func (vm *VM) CreateHandlers() map[string]*commonEng.HTTPHandler {
 	handler.RegisterName("snowman", &SnowmanAPI{vm})
 	handler.RegisterName("web3", &Web3API{})
 	handler.RegisterName("debug", &DebugAPI{vm})
+	handler.RegisterName("keystore", &KeystoreAPI{vm})
+	for namespace, service := range vm.rpcServices {
+		if err := handler.RegisterName(namespace, service); err != nil {
+			vm.ctx.Log.Error("Failed to register the %s API due to %s", namespace, err)
+		}
+	}
+	vm.handlersCreated = true
 
 	return map[string]*commonEng.HTTPHandler{
 		"/rpc": &commonEng.HTTPHandler{LockOptions: commonEng.NoLock, Handler: handler},
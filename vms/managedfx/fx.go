@@ -0,0 +1,222 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package managedfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/components/verify"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errWrongTxType         = errors.New("wrong tx type")
+	errWrongUTXOType       = errors.New("wrong utxo type")
+	errWrongOutputType     = errors.New("wrong output type")
+	errWrongInputType      = errors.New("wrong input type")
+	errWrongCredentialType = errors.New("wrong credential type")
+
+	errWrongNumberOfInputs      = errors.New("wrong number of inputs for an operation")
+	errWrongNumberOfCredentials = errors.New("wrong number of credentials for an operation")
+
+	errNoManagerInput        = errors.New("operation doesn't consume a manager output")
+	errMultipleManagerInputs = errors.New("operation consumes more than one manager output")
+	errWrongNumberOfManagers = errors.New("operation must produce exactly one manager output")
+	errWrongAmounts          = errors.New("input is consuming a different amount than expected")
+	errUnexpectedSigs        = errors.New("managed input's credential should hold no signatures")
+	errFrozen                = errors.New("output is held by a frozen address")
+)
+
+// Fx describes managed assets. The owners of an asset's manager output, its
+// managers, control the asset's supply and who may spend it.
+//
+// A manager operation consumes the manager output, along with any of the
+// asset's transfer outputs, and produces a new manager output followed by any
+// transfer outputs. This lets the managers:
+//   - mint, by producing more than was consumed
+//   - freeze or unfreeze addresses, by producing a manager output that lists
+//     the frozen addresses
+//   - claw back outputs, by reproducing them with new owners
+//   - hand the role to new managers, by producing a manager output with new
+//     owners
+//
+// Every one of these is recorded on chain as an operation. The latest manager
+// output holds the asset's managed state, which the VM checks transfers
+// against. Owners spend transfer outputs that no frozen address holds as they
+// would secp256k1fx outputs.
+type Fx struct{ secp256k1fx.Fx }
+
+// Initialize ...
+func (fx *Fx) Initialize(vmIntf interface{}) error {
+	if err := fx.InitializeVM(vmIntf); err != nil {
+		return err
+	}
+
+	c := vmIntf.(secp256k1fx.VM).Codec()
+	c.RegisterType(&ManagerOutput{})
+	c.RegisterType(&TransferOutput{})
+	c.RegisterType(&ManagerInput{})
+	c.RegisterType(&ManagedInput{})
+	c.RegisterType(&TransferInput{})
+	c.RegisterType(&Credential{})
+	return nil
+}
+
+// VerifyOperation verifies a manager operation. Exactly one input must be a
+// ManagerInput, signed by the managers, and the rest must be ManagedInputs.
+// Exactly one output must be a ManagerOutput, and the rest must be
+// TransferOutputs.
+func (fx *Fx) VerifyOperation(txIntf interface{}, utxosIntf, insIntf, credsIntf, outsIntf []interface{}) error {
+	tx, ok := txIntf.(secp256k1fx.Tx)
+	if !ok {
+		return errWrongTxType
+	}
+
+	if len(utxosIntf) != len(insIntf) {
+		return errWrongNumberOfInputs
+	}
+	if len(credsIntf) != len(insIntf) {
+		return errWrongNumberOfCredentials
+	}
+
+	managed := false
+	for i, inIntf := range insIntf {
+		cred, ok := credsIntf[i].(*Credential)
+		if !ok {
+			return errWrongCredentialType
+		}
+
+		switch in := inIntf.(type) {
+		case *ManagerInput:
+			if managed {
+				return errMultipleManagerInputs
+			}
+			managed = true
+
+			utxo, ok := utxosIntf[i].(*ManagerOutput)
+			if !ok {
+				return errWrongUTXOType
+			}
+			if err := verify.All(utxo, in, cred); err != nil {
+				return err
+			}
+			if err := fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, &cred.Credential); err != nil {
+				return err
+			}
+		case *ManagedInput:
+			utxo, ok := utxosIntf[i].(*TransferOutput)
+			if !ok {
+				return errWrongUTXOType
+			}
+			if err := verify.All(utxo, in, cred); err != nil {
+				return err
+			}
+			switch {
+			case utxo.Amt != in.Amt:
+				return errWrongAmounts
+			case len(cred.Sigs) != 0:
+				return errUnexpectedSigs
+			}
+		default:
+			return errWrongInputType
+		}
+	}
+	if !managed {
+		return errNoManagerInput
+	}
+
+	numManagers := 0
+	for _, outIntf := range outsIntf {
+		switch out := outIntf.(type) {
+		case *ManagerOutput:
+			numManagers++
+			if err := out.Verify(); err != nil {
+				return err
+			}
+		case *TransferOutput:
+			if err := out.Verify(); err != nil {
+				return err
+			}
+		default:
+			return errWrongOutputType
+		}
+	}
+	if numManagers != 1 {
+		return errWrongNumberOfManagers
+	}
+	return nil
+}
+
+// VerifyTransfer verifies that the owners of a TransferOutput spent it. Whether
+// the output is frozen is checked against the asset's managed state by
+// VerifyManagedTransfer.
+func (fx *Fx) VerifyTransfer(txIntf, utxoIntf, inIntf, credIntf interface{}) error {
+	utxo, ok := utxoIntf.(*TransferOutput)
+	if !ok {
+		return errWrongUTXOType
+	}
+	in, ok := inIntf.(*TransferInput)
+	if !ok {
+		return errWrongInputType
+	}
+	cred, ok := credIntf.(*Credential)
+	if !ok {
+		return errWrongCredentialType
+	}
+	if err := verify.All(utxo, in, cred); err != nil {
+		return err
+	}
+	return fx.Fx.VerifyTransfer(txIntf, &utxo.TransferOutput, &in.TransferInput, &cred.Credential)
+}
+
+// VerifyControl verifies that the managers of a ManagerOutput signed [txIntf].
+// The managers of a managed asset control the asset.
+func (fx *Fx) VerifyControl(txIntf, utxoIntf, inIntf, credIntf interface{}) error {
+	tx, ok := txIntf.(secp256k1fx.Tx)
+	if !ok {
+		return errWrongTxType
+	}
+	utxo, ok := utxoIntf.(*ManagerOutput)
+	if !ok {
+		return errWrongUTXOType
+	}
+	in, ok := inIntf.(*ManagerInput)
+	if !ok {
+		return errWrongInputType
+	}
+	cred, ok := credIntf.(*Credential)
+	if !ok {
+		return errWrongCredentialType
+	}
+	if err := verify.All(utxo, in, cred); err != nil {
+		return err
+	}
+	return fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, &cred.Credential)
+}
+
+// IsManagedState returns true if [outIntf] is a ManagerOutput, which holds the
+// managed state of its asset
+func (fx *Fx) IsManagedState(outIntf interface{}) bool {
+	_, ok := outIntf.(*ManagerOutput)
+	return ok
+}
+
+// VerifyManagedTransfer verifies that none of the owners of [utxoIntf] are
+// frozen by the asset's managed state, [stateIntf]
+func (fx *Fx) VerifyManagedTransfer(stateIntf, utxoIntf interface{}) error {
+	state, ok := stateIntf.(*ManagerOutput)
+	if !ok {
+		return errWrongOutputType
+	}
+	utxo, ok := utxoIntf.(*TransferOutput)
+	if !ok {
+		return errWrongUTXOType
+	}
+	for _, addr := range utxo.Addrs {
+		if state.IsFrozen(addr) {
+			return errFrozen
+		}
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package managedfx
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	txBytes  = []byte{0, 1, 2, 3, 4, 5}
+	sigBytes = [crypto.SECP256K1RSigLen]byte{
+		0x0e, 0x33, 0x4e, 0xbc, 0x67, 0xa7, 0x3f, 0xe8,
+		0x24, 0x33, 0xac, 0xa3, 0x47, 0x88, 0xa6, 0x3d,
+		0x58, 0xe5, 0x8e, 0xf0, 0x3a, 0xd5, 0x84, 0xf1,
+		0xbc, 0xa3, 0xb2, 0xd2, 0x5d, 0x51, 0xd6, 0x9b,
+		0x0f, 0x28, 0x5d, 0xcd, 0x3f, 0x71, 0x17, 0x0a,
+		0xf9, 0xbf, 0x2d, 0xb1, 0x10, 0x26, 0x5c, 0xe9,
+		0xdc, 0xc3, 0x9d, 0x7a, 0x01, 0x50, 0x9d, 0xe8,
+		0x35, 0xbd, 0xcb, 0x29, 0x3a, 0xd1, 0x49, 0x32,
+		0x00,
+	}
+	addrBytes = [hashing.AddrLen]byte{
+		0x01, 0x5c, 0xce, 0x6c, 0x55, 0xd6, 0xb5, 0x09,
+		0x84, 0x5c, 0x8c, 0x4e, 0x30, 0xbe, 0xd9, 0x8d,
+		0x39, 0x1a, 0xe7, 0xf0,
+	}
+)
+
+type testVM struct{ clock timer.Clock }
+
+func (vm *testVM) Codec() codec.Codec { return codec.NewDefault() }
+
+func (vm *testVM) Clock() *timer.Clock { return &vm.clock }
+
+type testTx struct{ bytes []byte }
+
+func (tx *testTx) UnsignedBytes() []byte { return tx.bytes }
+
+func owners() secp256k1fx.OutputOwners {
+	return secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs: []ids.ShortID{
+			ids.NewShortID(addrBytes),
+		},
+	}
+}
+
+func input() secp256k1fx.Input { return secp256k1fx.Input{SigIndices: []uint32{0}} }
+
+func credential() *Credential {
+	return &Credential{Credential: secp256k1fx.Credential{
+		Sigs: [][crypto.SECP256K1RSigLen]byte{
+			sigBytes,
+		},
+	}}
+}
+
+func transferOutput(amt uint64) *TransferOutput {
+	return &TransferOutput{
+		TransferOutput: secp256k1fx.TransferOutput{
+			Amt:          amt,
+			OutputOwners: owners(),
+		},
+	}
+}
+
+func noSigs() *Credential {
+	return &Credential{Credential: secp256k1fx.Credential{
+		Sigs: [][crypto.SECP256K1RSigLen]byte{},
+	}}
+}
+
+func TestFxInitialize(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFxInitializeInvalid(t *testing.T) {
+	fx := Fx{}
+	if err := fx.Initialize(nil); err == nil {
+		t.Fatalf("Should have returned an error")
+	}
+}
+
+func TestFxVerifyMintOperation(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := &ManagerOutput{OutputOwners: owners()}
+	in := &ManagerInput{Input: input()}
+	outs := []interface{}{
+		&ManagerOutput{OutputOwners: owners()},
+		transferOutput(1),
+	}
+
+	if err := fx.VerifyOperation(tx, []interface{}{utxo}, []interface{}{in}, []interface{}{credential()}, outs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFxVerifyFreezeOperation(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := &ManagerOutput{OutputOwners: owners()}
+	in := &ManagerInput{Input: input()}
+	outs := []interface{}{
+		&ManagerOutput{
+			OutputOwners: owners(),
+			Frozen:       []ids.ShortID{ids.NewShortID(addrBytes)},
+		},
+	}
+
+	if err := fx.VerifyOperation(tx, []interface{}{utxo}, []interface{}{in}, []interface{}{credential()}, outs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFxVerifyOperationUnsortedFrozen(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := &ManagerOutput{OutputOwners: owners()}
+	in := &ManagerInput{Input: input()}
+	outs := []interface{}{
+		&ManagerOutput{
+			OutputOwners: owners(),
+			Frozen:       []ids.ShortID{ids.NewShortID(addrBytes), ids.NewShortID(addrBytes)},
+		},
+	}
+
+	if err := fx.VerifyOperation(tx, []interface{}{utxo}, []interface{}{in}, []interface{}{credential()}, outs); err == nil {
+		t.Fatalf("Should have errored due to frozen addresses that aren't unique")
+	}
+}
+
+func TestFxVerifyOperationChangeManagers(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := &ManagerOutput{OutputOwners: owners()}
+	in := &ManagerInput{Input: input()}
+	outs := []interface{}{
+		&ManagerOutput{OutputOwners: secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{ids.NewShortID([20]byte{1})},
+		}},
+	}
+
+	if err := fx.VerifyOperation(tx, []interface{}{utxo}, []interface{}{in}, []interface{}{credential()}, outs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFxVerifyOperationNoManagerInput(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := transferOutput(5)
+	in := &ManagedInput{Amt: 5}
+	outs := []interface{}{
+		&ManagerOutput{OutputOwners: owners()},
+		transferOutput(5),
+	}
+
+	if err := fx.VerifyOperation(tx, []interface{}{utxo}, []interface{}{in}, []interface{}{noSigs()}, outs); err == nil {
+		t.Fatalf("Should have errored due to not consuming the manager output")
+	}
+}
+
+func TestFxVerifyOperationNoManagerOutput(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := &ManagerOutput{OutputOwners: owners()}
+	in := &ManagerInput{Input: input()}
+	outs := []interface{}{
+		transferOutput(1),
+	}
+
+	if err := fx.VerifyOperation(tx, []interface{}{utxo}, []interface{}{in}, []interface{}{credential()}, outs); err == nil {
+		t.Fatalf("Should have errored due to not producing a manager output")
+	}
+}
+
+func TestFxVerifyOperationWrongManagedAmount(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxos := []interface{}{
+		&ManagerOutput{OutputOwners: owners()},
+		transferOutput(5),
+	}
+	ins := []interface{}{
+		&ManagerInput{Input: input()},
+		&ManagedInput{Amt: 4},
+	}
+	creds := []interface{}{credential(), noSigs()}
+	outs := []interface{}{
+		&ManagerOutput{OutputOwners: owners()},
+	}
+
+	if err := fx.VerifyOperation(tx, utxos, ins, creds, outs); err == nil {
+		t.Fatalf("Should have errored due to consuming the wrong amount")
+	}
+}
+
+func TestFxVerifyOperationWrongSigner(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := &ManagerOutput{OutputOwners: secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{ids.NewShortID([20]byte{1})},
+	}}
+	in := &ManagerInput{Input: input()}
+	outs := []interface{}{
+		&ManagerOutput{OutputOwners: owners()},
+	}
+
+	if err := fx.VerifyOperation(tx, []interface{}{utxo}, []interface{}{in}, []interface{}{credential()}, outs); err == nil {
+		t.Fatalf("Should have errored due to the wrong signer")
+	}
+}
+
+func TestFxVerifyTransfer(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	in := &TransferInput{TransferInput: secp256k1fx.TransferInput{
+		Amt:   5,
+		Input: input(),
+	}}
+
+	if err := fx.VerifyTransfer(tx, transferOutput(5), in, credential()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFxVerifyManagedTransfer(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	state := &ManagerOutput{OutputOwners: owners()}
+	if !fx.IsManagedState(state) {
+		t.Fatalf("The manager output should hold the managed state")
+	}
+	if fx.IsManagedState(transferOutput(5)) {
+		t.Fatalf("A transfer output shouldn't hold the managed state")
+	}
+
+	if err := fx.VerifyManagedTransfer(state, transferOutput(5)); err != nil {
+		t.Fatal(err)
+	}
+
+	state.Frozen = []ids.ShortID{ids.NewShortID(addrBytes)}
+	if err := fx.VerifyManagedTransfer(state, transferOutput(5)); err == nil {
+		t.Fatalf("Should have errored due to spending an output held by a frozen address")
+	}
+}
+
+func TestFxVerifyTransferManagerOutput(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := &ManagerOutput{OutputOwners: owners()}
+	in := &TransferInput{TransferInput: secp256k1fx.TransferInput{
+		Amt:   5,
+		Input: input(),
+	}}
+
+	if err := fx.VerifyTransfer(tx, utxo, in, credential()); err == nil {
+		t.Fatalf("Should have errored due to transferring the manager output")
+	}
+}
+
+func TestFxVerifyControl(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := &ManagerOutput{OutputOwners: owners()}
+	in := &ManagerInput{Input: input()}
+
+	if err := fx.VerifyControl(tx, utxo, in, credential()); err != nil {
+		t.Fatal(err)
+	}
+	if err := fx.VerifyControl(tx, transferOutput(1), in, credential()); err == nil {
+		t.Fatalf("Should have errored due to a utxo that doesn't control the asset")
+	}
+	if err := fx.VerifyControl(&testTx{bytes: []byte{1}}, utxo, in, credential()); err == nil {
+		t.Fatalf("Should have errored due to a wrong signer")
+	}
+}
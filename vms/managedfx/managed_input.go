@@ -0,0 +1,34 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package managedfx
+
+import (
+	"errors"
+)
+
+var (
+	errNoValueInput = errors.New("input has no value")
+)
+
+// ManagedInput consumes the [Amt] held by a TransferOutput on the authority of
+// the asset's managers, rather than of the output's owners. Its credential
+// holds no signatures.
+type ManagedInput struct {
+	Amt uint64 `serialize:"true"`
+}
+
+// Amount returns the quantity of the asset this input consumes
+func (in *ManagedInput) Amount() uint64 { return in.Amt }
+
+// Verify this input is syntactically valid
+func (in *ManagedInput) Verify() error {
+	switch {
+	case in == nil:
+		return errNilInput
+	case in.Amt == 0:
+		return errNoValueInput
+	default:
+		return nil
+	}
+}
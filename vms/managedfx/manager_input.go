@@ -0,0 +1,29 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package managedfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilInput = errors.New("nil input")
+)
+
+// ManagerInput consumes a ManagerOutput to perform a manager operation
+type ManagerInput struct {
+	secp256k1fx.Input `serialize:"true"`
+}
+
+// Verify this input is syntactically valid
+func (in *ManagerInput) Verify() error {
+	switch {
+	case in == nil:
+		return errNilInput
+	default:
+		return in.Input.Verify()
+	}
+}
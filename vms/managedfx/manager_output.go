@@ -0,0 +1,46 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package managedfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilOutput             = errors.New("nil output")
+	errFrozenNotSortedUnique = errors.New("frozen addresses must be sorted and unique")
+)
+
+// ManagerOutput grants its owners the right to manage the asset it belongs to.
+// It holds the asset's managed state: the outputs of the asset held by any of
+// the [Frozen] addresses can't be spent by their owners.
+type ManagerOutput struct {
+	secp256k1fx.OutputOwners `serialize:"true"`
+	Frozen                   []ids.ShortID `serialize:"true"`
+}
+
+// IsFrozen returns true if [addr] is frozen
+func (out *ManagerOutput) IsFrozen(addr ids.ShortID) bool {
+	for _, frozen := range out.Frozen {
+		if frozen.Equals(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify ...
+func (out *ManagerOutput) Verify() error {
+	switch {
+	case out == nil:
+		return errNilOutput
+	case !ids.IsSortedAndUniqueShortIDs(out.Frozen):
+		return errFrozenNotSortedUnique
+	default:
+		return out.OutputOwners.Verify()
+	}
+}
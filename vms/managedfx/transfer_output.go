@@ -0,0 +1,25 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package managedfx
+
+import (
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+// TransferOutput holds an amount of a managed asset. If any of its owners is
+// frozen by the asset's managers, its owners can't spend it until the owner is
+// unfrozen.
+type TransferOutput struct {
+	secp256k1fx.TransferOutput `serialize:"true"`
+}
+
+// Verify ...
+func (out *TransferOutput) Verify() error {
+	switch {
+	case out == nil:
+		return errNilOutput
+	default:
+		return out.TransferOutput.Verify()
+	}
+}
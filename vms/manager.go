@@ -45,6 +45,9 @@ type Manager interface {
 
 	// Give an alias to a VM
 	Alias(ids.ID, string) error
+
+	// Remove an alias from the VM it's associated with
+	RemoveAlias(string) error
 }
 
 // Implements Manager
@@ -0,0 +1,29 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package nftfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilCredential = errors.New("nil credential")
+)
+
+// Credential ...
+type Credential struct {
+	secp256k1fx.Credential `serialize:"true"`
+}
+
+// Verify ...
+func (cr *Credential) Verify() error {
+	switch {
+	case cr == nil:
+		return errNilCredential
+	default:
+		return cr.Credential.Verify()
+	}
+}
@@ -0,0 +1,172 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package nftfx
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/components/verify"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errWrongTxType         = errors.New("wrong tx type")
+	errWrongUTXOType       = errors.New("wrong utxo type")
+	errWrongOutputType     = errors.New("wrong output type")
+	errWrongInputType      = errors.New("wrong input type")
+	errWrongCredentialType = errors.New("wrong credential type")
+
+	errWrongNumberOfOutputs     = errors.New("wrong number of outputs for an operation")
+	errWrongNumberOfInputs      = errors.New("wrong number of inputs for an operation")
+	errWrongNumberOfCredentials = errors.New("wrong number of credentials for an operation")
+
+	errWrongMintCreated     = errors.New("wrong mint output created from the operation")
+	errWrongUniqueOutput    = errors.New("unique output doesn't match the output it was created from")
+	errCantTransferFungibly = errors.New("unique outputs can't be transferred as a fungible amount")
+)
+
+// Fx describes non-fungible assets. Each mint output allows its owners to
+// mint unique outputs in one group. A unique output carries a payload and can
+// only be transferred whole, by an operation, to new owners.
+//
+// Ownership and signatures follow the rules of the secp256k1fx. An AVM chain
+// supports non-fungible assets if the transaction that created it lists ID
+// among its Fxs.
+type Fx struct{ secp256k1fx.Fx }
+
+// Initialize ...
+func (fx *Fx) Initialize(vmIntf interface{}) error {
+	if err := fx.InitializeVM(vmIntf); err != nil {
+		return err
+	}
+
+	c := vmIntf.(secp256k1fx.VM).Codec()
+	c.RegisterType(&MintOutput{})
+	c.RegisterType(&TransferOutput{})
+	c.RegisterType(&MintInput{})
+	c.RegisterType(&TransferInput{})
+	c.RegisterType(&Credential{})
+	return nil
+}
+
+// VerifyOperation verifies either a mint or a transfer of a unique output.
+//
+// A mint consumes a MintOutput and produces the same MintOutput, followed by
+// at least one TransferOutput of the same group.
+//
+// A transfer consumes a TransferOutput and produces exactly one TransferOutput
+// with the same group and payload.
+func (fx *Fx) VerifyOperation(txIntf interface{}, utxosIntf, insIntf, credsIntf, outsIntf []interface{}) error {
+	tx, ok := txIntf.(secp256k1fx.Tx)
+	if !ok {
+		return errWrongTxType
+	}
+
+	if len(utxosIntf) != 1 || len(insIntf) != 1 {
+		return errWrongNumberOfInputs
+	}
+	if len(credsIntf) != 1 {
+		return errWrongNumberOfCredentials
+	}
+	cred, ok := credsIntf[0].(*Credential)
+	if !ok {
+		return errWrongCredentialType
+	}
+
+	switch in := insIntf[0].(type) {
+	case *MintInput:
+		utxo, ok := utxosIntf[0].(*MintOutput)
+		if !ok {
+			return errWrongUTXOType
+		}
+		return fx.verifyMintOperation(tx, utxo, in, cred, outsIntf)
+	case *TransferInput:
+		utxo, ok := utxosIntf[0].(*TransferOutput)
+		if !ok {
+			return errWrongUTXOType
+		}
+		return fx.verifyTransferOperation(tx, utxo, in, cred, outsIntf)
+	default:
+		return errWrongInputType
+	}
+}
+
+func (fx *Fx) verifyMintOperation(tx secp256k1fx.Tx, utxo *MintOutput, in *MintInput, cred *Credential, outsIntf []interface{}) error {
+	if len(outsIntf) < 2 {
+		return errWrongNumberOfOutputs
+	}
+	newMint, ok := outsIntf[0].(*MintOutput)
+	if !ok {
+		return errWrongOutputType
+	}
+	if err := verify.All(utxo, in, cred, newMint); err != nil {
+		return err
+	}
+	if utxo.GroupID != newMint.GroupID || !utxo.OutputOwners.Equals(&newMint.OutputOwners) {
+		return errWrongMintCreated
+	}
+
+	for _, outIntf := range outsIntf[1:] {
+		out, ok := outIntf.(*TransferOutput)
+		if !ok {
+			return errWrongOutputType
+		}
+		if err := out.Verify(); err != nil {
+			return err
+		}
+		if out.GroupID != utxo.GroupID {
+			return errWrongUniqueOutput
+		}
+	}
+
+	return fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, &cred.Credential)
+}
+
+func (fx *Fx) verifyTransferOperation(tx secp256k1fx.Tx, utxo *TransferOutput, in *TransferInput, cred *Credential, outsIntf []interface{}) error {
+	if len(outsIntf) != 1 {
+		return errWrongNumberOfOutputs
+	}
+	out, ok := outsIntf[0].(*TransferOutput)
+	if !ok {
+		return errWrongOutputType
+	}
+	if err := verify.All(utxo, in, cred, out); err != nil {
+		return err
+	}
+	if utxo.GroupID != out.GroupID || !bytes.Equal(utxo.Payload, out.Payload) {
+		return errWrongUniqueOutput
+	}
+
+	return fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, &cred.Credential)
+}
+
+// VerifyTransfer always fails, as unique outputs don't have an amount that
+// could be moved by a BaseTx. They're moved by operations instead.
+func (fx *Fx) VerifyTransfer(_, _, _, _ interface{}) error { return errCantTransferFungibly }
+
+// VerifyControl verifies that the owners of a MintOutput signed [txIntf]. The
+// owners of an asset's mint outputs control the asset.
+func (fx *Fx) VerifyControl(txIntf, utxoIntf, inIntf, credIntf interface{}) error {
+	tx, ok := txIntf.(secp256k1fx.Tx)
+	if !ok {
+		return errWrongTxType
+	}
+	utxo, ok := utxoIntf.(*MintOutput)
+	if !ok {
+		return errWrongUTXOType
+	}
+	in, ok := inIntf.(*MintInput)
+	if !ok {
+		return errWrongInputType
+	}
+	cred, ok := credIntf.(*Credential)
+	if !ok {
+		return errWrongCredentialType
+	}
+	if err := verify.All(utxo, in, cred); err != nil {
+		return err
+	}
+	return fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, &cred.Credential)
+}
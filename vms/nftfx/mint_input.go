@@ -0,0 +1,29 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package nftfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilInput = errors.New("nil input")
+)
+
+// MintInput consumes a MintOutput to mint unique outputs
+type MintInput struct {
+	secp256k1fx.Input `serialize:"true"`
+}
+
+// Verify this input is syntactically valid
+func (in *MintInput) Verify() error {
+	switch {
+	case in == nil:
+		return errNilInput
+	default:
+		return in.Input.Verify()
+	}
+}
@@ -0,0 +1,31 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package nftfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilOutput = errors.New("nil output")
+)
+
+// MintOutput grants its owners the right to mint unique outputs in the group
+// [GroupID]
+type MintOutput struct {
+	GroupID                  uint32 `serialize:"true"`
+	secp256k1fx.OutputOwners `serialize:"true"`
+}
+
+// Verify ...
+func (out *MintOutput) Verify() error {
+	switch {
+	case out == nil:
+		return errNilOutput
+	default:
+		return out.OutputOwners.Verify()
+	}
+}
@@ -0,0 +1,23 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package nftfx
+
+import (
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+// TransferInput consumes a TransferOutput to give it a new owner
+type TransferInput struct {
+	secp256k1fx.Input `serialize:"true"`
+}
+
+// Verify this input is syntactically valid
+func (in *TransferInput) Verify() error {
+	switch {
+	case in == nil:
+		return errNilInput
+	default:
+		return in.Input.Verify()
+	}
+}
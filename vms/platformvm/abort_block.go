@@ -32,6 +32,13 @@ func (a *Abort) Verify() error {
 	return nil
 }
 
+// Accept implements the snowman.Block interface. Accepting an Abort block
+// rejects the tx proposed by its parent.
+func (a *Abort) Accept() {
+	a.CommonDecisionBlock.Accept()
+	a.vm.VMMetrics.Rejected(1)
+}
+
 // newAbortBlock returns a new *Abort block where the block's parent, a proposal
 // block, has ID [parentID].
 func (vm *VM) newAbortBlock(parentID ids.ID) *Abort {
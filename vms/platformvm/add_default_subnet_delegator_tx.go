@@ -88,7 +88,7 @@ func (tx *addDefaultSubnetDelegatorTx) SyntacticVerify() error {
 	}
 
 	// get account to pay tx fee from
-	key, err := tx.vm.factory.RecoverPublicKey(unsignedBytes, tx.Sig[:])
+	key, err := tx.vm.factory.RecoverHashPublicKey(tx.vm.signingDigest(unsignedBytes), tx.Sig[:])
 	if err != nil {
 		return err
 	}
@@ -194,7 +194,7 @@ func (vm *VM) newAddDefaultSubnetDelegatorTx(
 	nodeID ids.ShortID,
 	destination ids.ShortID,
 	networkID uint32,
-	key *crypto.PrivateKeySECP256K1R,
+	key crypto.Signer,
 ) (*addDefaultSubnetDelegatorTx, error) {
 	tx := &addDefaultSubnetDelegatorTx{
 		UnsignedAddDefaultSubnetDelegatorTx: UnsignedAddDefaultSubnetDelegatorTx{
@@ -218,7 +218,7 @@ func (vm *VM) newAddDefaultSubnetDelegatorTx(
 		return nil, err
 	}
 
-	sig, err := key.Sign(unsignedBytes)
+	sig, err := key.SignHash(vm.signingDigest(unsignedBytes))
 	if err != nil {
 		return nil, err
 	}
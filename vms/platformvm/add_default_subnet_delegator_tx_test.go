@@ -377,3 +377,43 @@ func TestAddDefaultSubnetDelegatorTxSemanticVerify(t *testing.T) {
 	}
 	txFee = txFeeSaved // Reset tx fee
 }
+
+// Ensure a signature made for one chain doesn't authorize the transaction on
+// another chain
+func TestAddDefaultSubnetDelegatorTxReplay(t *testing.T) {
+	vm := defaultVM()
+
+	tx, err := vm.newAddDefaultSubnetDelegatorTx(
+		defaultNonce+1,
+		defaultStakeAmount,
+		uint64(defaultValidateStartTime.Unix()),
+		uint64(defaultValidateEndTime.Unix()),
+		defaultKey.PublicKey().Address(),
+		defaultKey.PublicKey().Address(),
+		testNetworkID,
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SyntacticVerify(); err != nil {
+		t.Fatal(err)
+	}
+	if !tx.senderID.Equals(defaultKey.PublicKey().Address()) {
+		t.Fatalf("signature should authorize the tx on the chain it was made for")
+	}
+
+	otherVM := defaultVM()
+	otherVM.Ctx.ChainID = ids.NewID([32]byte{1})
+
+	replayedTx := &addDefaultSubnetDelegatorTx{
+		UnsignedAddDefaultSubnetDelegatorTx: tx.UnsignedAddDefaultSubnetDelegatorTx,
+		Sig:                                 tx.Sig,
+	}
+	if err := replayedTx.initialize(otherVM); err != nil {
+		t.Fatal(err)
+	}
+	if err := replayedTx.SyntacticVerify(); err == nil && replayedTx.senderID.Equals(defaultKey.PublicKey().Address()) {
+		t.Fatalf("signature shouldn't authorize the tx on another chain")
+	}
+}
@@ -98,7 +98,7 @@ func (tx *addDefaultSubnetValidatorTx) SyntacticVerify() error {
 		return err
 	}
 
-	key, err := tx.vm.factory.RecoverPublicKey(unsignedBytes, tx.Sig[:]) // the public key that signed [tx]
+	key, err := tx.vm.factory.RecoverHashPublicKey(tx.vm.signingDigest(unsignedBytes), tx.Sig[:]) // the public key that signed [tx]
 	if err != nil {
 		return err
 	}
@@ -197,7 +197,7 @@ func (tx *addDefaultSubnetValidatorTx) InitiallyPrefersCommit() bool {
 }
 
 // NewAddDefaultSubnetValidatorTx returns a new NewAddDefaultSubnetValidatorTx
-func (vm *VM) newAddDefaultSubnetValidatorTx(nonce, stakeAmt, startTime, endTime uint64, nodeID, destination ids.ShortID, shares, networkID uint32, key *crypto.PrivateKeySECP256K1R,
+func (vm *VM) newAddDefaultSubnetValidatorTx(nonce, stakeAmt, startTime, endTime uint64, nodeID, destination ids.ShortID, shares, networkID uint32, key crypto.Signer,
 ) (*addDefaultSubnetValidatorTx, error) {
 	tx := &addDefaultSubnetValidatorTx{
 		UnsignedAddDefaultSubnetValidatorTx: UnsignedAddDefaultSubnetValidatorTx{
@@ -222,7 +222,7 @@ func (vm *VM) newAddDefaultSubnetValidatorTx(nonce, stakeAmt, startTime, endTime
 		return nil, err
 	}
 
-	sig, err := key.Sign(unsignedBytes) // Sign the transaction
+	sig, err := key.SignHash(vm.signingDigest(unsignedBytes)) // Sign the transaction
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +111,7 @@ func (tx *addNonDefaultSubnetValidatorTx) SyntacticVerify() error {
 	if err != nil {
 		return err
 	}
-	unsignedBytesHash := hashing.ComputeHash256(unsignedBytes)
+	unsignedBytesHash := tx.vm.signingDigest(unsignedBytes)
 
 	tx.controlIDs = make([]ids.ShortID, len(tx.ControlSigs))
 	// recover control signatures
@@ -156,20 +156,10 @@ func (tx *addNonDefaultSubnetValidatorTx) SemanticVerify(db database.Database) (
 	}
 
 	// Get info about the subnet we're adding a validator to
-	subnets, err := tx.vm.getSubnets(db)
+	subnet, err := tx.vm.getSubnet(db, tx.SubnetID())
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
-	var subnet *CreateSubnetTx
-	for _, sn := range subnets {
-		if sn.ID.Equals(tx.SubnetID()) {
-			subnet = sn
-			break
-		}
-	}
-	if subnet == nil {
-		return nil, nil, nil, nil, fmt.Errorf("there is no subnet with ID %s", tx.SubnetID())
-	}
 
 	// Ensure the sigs on [tx] are valid
 	if len(tx.ControlSigs) != int(subnet.Threshold) {
@@ -335,7 +325,7 @@ func (vm *VM) newAddNonDefaultSubnetValidatorTx(
 	if err != nil {
 		return nil, err
 	}
-	unsignedHash := hashing.ComputeHash256(unsignedBytes)
+	unsignedHash := vm.signingDigest(unsignedBytes)
 
 	// Sign this tx with each control key
 	tx.ControlSigs = make([][crypto.SECP256K1RSigLen]byte, len(controlKeys))
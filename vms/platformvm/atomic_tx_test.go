@@ -0,0 +1,121 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms/components/shared"
+)
+
+// Export $AVA to the AVM and import it back
+func TestExportImport(t *testing.T) {
+	vm := defaultVM()
+	vm.avm = ids.Empty.Prefix(0)
+	vm.ava = ids.Empty.Prefix(1)
+
+	memory := atomic.Memory{}
+	memory.Initialize(logging.NoLog{}, memdb.New())
+	vm.Ctx.SharedMemory = memory.NewBlockchainMemory(vm.Ctx.ChainID)
+
+	exportTx, err := vm.newExportTx(
+		defaultNonce+1,
+		defaultKey.PublicKey().Address(),
+		defaultBalance/2,
+		testNetworkID,
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := versiondb.New(vm.DB)
+	onAccept, err := exportTx.SemanticVerify(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	onAccept()
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := vm.getAccount(vm.DB, defaultKey.PublicKey().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Balance != defaultBalance-defaultBalance/2 {
+		t.Fatalf("Expected the exported $AVA to be removed from the account")
+	}
+
+	// The AVM would import the exported UTXO, and later export it back to the
+	// platform chain
+	sharedDB := vm.Ctx.SharedMemory.GetDatabase(vm.avm)
+	avmState := shared.NewState(sharedDB, vm.avm)
+	utxo := exportTx.ExportedUTXO()
+	if _, err := avmState.UTXO(utxo.ID()); err != nil {
+		t.Fatalf("Exported UTXO should be in shared memory: %s", err)
+	}
+	if err := avmState.SpendUTXO(utxo.ID()); err != nil {
+		t.Fatal(err)
+	}
+	utxo.TxID = ids.Empty.Prefix(2)
+	if err := shared.NewState(sharedDB, vm.Ctx.ChainID).FundUTXO(utxo); err != nil {
+		t.Fatal(err)
+	}
+	vm.Ctx.SharedMemory.ReleaseDatabase(vm.avm)
+
+	importTx, err := vm.newImportTx(
+		defaultNonce+2,
+		[]ids.ID{utxo.ID()},
+		testNetworkID,
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db = versiondb.New(vm.DB)
+	onAccept, err = importTx.SemanticVerify(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same UTXO can't be imported twice
+	doubleImportTx, err := vm.newImportTx(
+		defaultNonce+3,
+		[]ids.ID{utxo.ID()},
+		testNetworkID,
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doubleImportTx.SemanticVerify(versiondb.New(db)); err == nil {
+		t.Fatalf("Should have errored because the UTXO was already imported")
+	}
+
+	onAccept()
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	account, err = vm.getAccount(vm.DB, defaultKey.PublicKey().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Balance != defaultBalance {
+		t.Fatalf("Expected the imported $AVA to be added to the account")
+	}
+
+	sharedDB = vm.Ctx.SharedMemory.GetDatabase(vm.avm)
+	defer vm.Ctx.SharedMemory.ReleaseDatabase(vm.avm)
+	if _, err := shared.NewState(sharedDB, vm.Ctx.ChainID).UTXO(utxo.ID()); err == nil {
+		t.Fatalf("Imported UTXO should have been removed from shared memory")
+	}
+}
@@ -32,6 +32,13 @@ func (c *Commit) Verify() error {
 	return nil
 }
 
+// Accept implements the snowman.Block interface. Accepting a Commit block
+// accepts the tx proposed by its parent.
+func (c *Commit) Accept() {
+	c.CommonDecisionBlock.Accept()
+	c.vm.VMMetrics.Accepted(1, 0, 0)
+}
+
 // newCommitBlock returns a new *Commit block where the block's parent, a
 // proposal block, has ID [parentID].
 func (vm *VM) newCommitBlock(parentID ids.ID) *Commit {
@@ -10,6 +10,7 @@ import (
 	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/hashing"
 )
@@ -17,6 +18,7 @@ import (
 var (
 	errInvalidVMID             = errors.New("invalid VM ID")
 	errFxIDsNotSortedAndUnique = errors.New("feature extensions IDs must be sorted and unique")
+	errControlSigsOnDefault    = errors.New("chains validated by the default subnet don't have control signatures")
 )
 
 // UnsignedCreateChainTx is an unsigned CreateChainTx
@@ -24,6 +26,9 @@ type UnsignedCreateChainTx struct {
 	// ID of the network this blockchain exists on
 	NetworkID uint32 `serialize:"true"`
 
+	// ID of the subnet that validates the new chain
+	SubnetID ids.ID `serialize:"true"`
+
 	// Next unused nonce of account paying the transaction fee for this transaction.
 	// Currently unused, as there are no tx fees.
 	Nonce uint64 `serialize:"true"`
@@ -45,12 +50,19 @@ type UnsignedCreateChainTx struct {
 type CreateChainTx struct {
 	UnsignedCreateChainTx `serialize:"true"`
 
+	// Signatures of a threshold of the control keys of the subnet that
+	// validates the new chain. Chains validated by the default subnet don't
+	// have any.
+	ControlSigs [][crypto.SECP256K1RSigLen]byte `serialize:"true"`
+
+	// Signature of the key whose account pays the tx fee
 	Sig [crypto.SECP256K1RSigLen]byte `serialize:"true"`
 
-	vm    *VM
-	id    ids.ID
-	key   crypto.PublicKey // public key of transaction signer
-	bytes []byte
+	vm         *VM
+	id         ids.ID
+	controlIDs []ids.ShortID
+	key        crypto.PublicKey // public key of transaction signer
+	bytes      []byte
 }
 
 func (tx *CreateChainTx) initialize(vm *VM) error {
@@ -83,10 +95,16 @@ func (tx *CreateChainTx) SyntacticVerify() error {
 		return errWrongNetworkID
 	case tx.id.IsZero():
 		return errInvalidID
+	case tx.SubnetID.IsZero():
+		return errInvalidID
 	case tx.VMID.IsZero():
 		return errInvalidVMID
 	case !ids.IsSortedAndUniqueIDs(tx.FxIDs):
 		return errFxIDsNotSortedAndUnique
+	case !crypto.IsSortedAndUniqueSECP2561RSigs(tx.ControlSigs):
+		return errSigsNotSorted
+	case tx.SubnetID.Equals(DefaultSubnetID) && len(tx.ControlSigs) != 0:
+		return errControlSigsOnDefault
 	}
 
 	unsignedIntf := interface{}(&tx.UnsignedCreateChainTx)
@@ -95,10 +113,20 @@ func (tx *CreateChainTx) SyntacticVerify() error {
 		return err
 	}
 
-	key, err := tx.vm.factory.RecoverPublicKey(unsignedBytes, tx.Sig[:])
+	controlIDs := make([]ids.ShortID, len(tx.ControlSigs))
+	for i, sig := range tx.ControlSigs {
+		key, err := tx.vm.factory.RecoverHashPublicKey(tx.vm.signingDigest(unsignedBytes), sig[:])
+		if err != nil {
+			return err
+		}
+		controlIDs[i] = key.Address()
+	}
+
+	key, err := tx.vm.factory.RecoverHashPublicKey(tx.vm.signingDigest(unsignedBytes), tx.Sig[:])
 	if err != nil {
 		return err
 	}
+	tx.controlIDs = controlIDs
 	tx.key = key
 
 	return nil
@@ -110,6 +138,18 @@ func (tx *CreateChainTx) SemanticVerify(db database.Database) (func(), error) {
 		return nil, err
 	}
 
+	// Chains validated by a subnet other than the default subnet must be
+	// authorized by the subnet's control keys
+	if !tx.SubnetID.Equals(DefaultSubnetID) {
+		subnet, err := tx.vm.getSubnet(db, tx.SubnetID)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyControlSigners(subnet.ControlKeys, subnet.Threshold, tx.controlIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	currentChains, err := tx.vm.getChains(db) // chains that currently exist
 	if err != nil {
 		return nil, errDBChains
@@ -141,6 +181,7 @@ func (tx *CreateChainTx) SemanticVerify(db database.Database) (func(), error) {
 	onAccept := func() {
 		chainParams := chains.ChainParameters{
 			ID:          tx.ID(),
+			SubnetID:    tx.SubnetID,
 			GenesisData: tx.GenesisData,
 			VMAlias:     tx.VMID.String(),
 		}
@@ -166,10 +207,39 @@ func (chains createChainList) Bytes() []byte {
 	return bytes
 }
 
-func (vm *VM) newCreateChainTx(nonce uint64, genesisData []byte, vmID ids.ID, fxIDs []ids.ID, chainName string, networkID uint32, key *crypto.PrivateKeySECP256K1R) (*CreateChainTx, error) {
+// verifyGenesis returns nil if [genesisData] is valid genesis data for a chain
+// running the VM [vmID]. If the VM can't verify genesis data statically, the
+// data isn't checked.
+func (vm *VM) verifyGenesis(vmID ids.ID, genesisData []byte) error {
+	if vm.VMManager == nil {
+		return nil
+	}
+	factory, err := vm.VMManager.GetVMFactory(vmID)
+	if err != nil {
+		return err
+	}
+	verifier, ok := factory.New().(common.GenesisVerifier)
+	if !ok {
+		return nil
+	}
+	return verifier.VerifyGenesis(genesisData)
+}
+
+func (vm *VM) newCreateChainTx(
+	nonce uint64,
+	subnetID ids.ID,
+	genesisData []byte,
+	vmID ids.ID,
+	fxIDs []ids.ID,
+	chainName string,
+	networkID uint32,
+	controlKeys []*crypto.PrivateKeySECP256K1R,
+	payerKey *crypto.PrivateKeySECP256K1R,
+) (*CreateChainTx, error) {
 	tx := &CreateChainTx{
 		UnsignedCreateChainTx: UnsignedCreateChainTx{
 			NetworkID:   networkID,
+			SubnetID:    subnetID,
 			Nonce:       nonce,
 			GenesisData: genesisData,
 			VMID:        vmID,
@@ -184,7 +254,19 @@ func (vm *VM) newCreateChainTx(nonce uint64, genesisData []byte, vmID ids.ID, fx
 		return nil, err
 	}
 
-	sig, err := key.Sign(unsignedBytes)
+	// Sign this tx with each control key
+	tx.ControlSigs = make([][crypto.SECP256K1RSigLen]byte, len(controlKeys))
+	for i, key := range controlKeys {
+		sig, err := key.SignHash(vm.signingDigest(unsignedBytes))
+		if err != nil {
+			return nil, err
+		}
+		copy(tx.ControlSigs[i][:], sig)
+	}
+	crypto.SortSECP2561RSigs(tx.ControlSigs)
+
+	// Sign this tx with the key of the tx fee payer
+	sig, err := payerKey.SignHash(vm.signingDigest(unsignedBytes))
 	if err != nil {
 		return nil, err
 	}
@@ -6,9 +6,14 @@ package platformvm
 import (
 	"testing"
 
+	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms"
 	"github.com/ava-labs/gecko/vms/avm"
+	"github.com/ava-labs/gecko/vms/timestampvm"
 )
 
 // test method SyntacticVerify
@@ -24,11 +29,13 @@ func TestCreateChainTxSyntacticVerify(t *testing.T) {
 	// Case 2: network ID is wrong
 	tx, err := vm.newCreateChainTx(
 		defaultNonce+1,
+		DefaultSubnetID,
 		nil,
 		avm.ID,
 		nil,
 		"chain name",
 		testNetworkID+1,
+		nil,
 		defaultKey,
 	)
 	if err != nil {
@@ -43,11 +50,13 @@ func TestCreateChainTxSyntacticVerify(t *testing.T) {
 	// case 3: tx ID is empty
 	tx, err = vm.newCreateChainTx(
 		defaultNonce+1,
+		DefaultSubnetID,
 		nil,
 		avm.ID,
 		nil,
 		"chain name",
 		testNetworkID,
+		nil,
 		defaultKey,
 	)
 	if err != nil {
@@ -61,11 +70,13 @@ func TestCreateChainTxSyntacticVerify(t *testing.T) {
 	// Case 4: vm ID is empty
 	tx, err = vm.newCreateChainTx(
 		defaultNonce+1,
+		DefaultSubnetID,
 		nil,
 		avm.ID,
 		nil,
 		"chain name",
 		testNetworkID,
+		nil,
 		defaultKey,
 	)
 	if err != nil {
@@ -83,11 +94,13 @@ func TestSemanticVerify(t *testing.T) {
 	// create a tx
 	tx, err := vm.newCreateChainTx(
 		defaultNonce+1,
+		DefaultSubnetID,
 		nil,
 		avm.ID,
 		nil,
 		"chain name",
 		testNetworkID,
+		nil,
 		defaultKey,
 	)
 	if err != nil {
@@ -119,11 +132,13 @@ func TestSemanticVerifyAlreadyExisting(t *testing.T) {
 	// create a tx
 	tx, err := vm.newCreateChainTx(
 		defaultNonce+1,
+		DefaultSubnetID,
 		nil,
 		avm.ID,
 		nil,
 		"chain name",
 		testNetworkID,
+		nil,
 		defaultKey,
 	)
 	if err != nil {
@@ -140,3 +155,105 @@ func TestSemanticVerifyAlreadyExisting(t *testing.T) {
 		t.Fatalf("should have failed because there is already a chain with ID %s", tx.id)
 	}
 }
+
+func TestSemanticVerifyNonDefaultSubnet(t *testing.T) {
+	vm := defaultVM()
+
+	// Signed by a threshold of the subnet's control keys
+	tx, err := vm.newCreateChainTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		nil,
+		avm.ID,
+		nil,
+		"chain name",
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0], testSubnet1ControlKeys[1]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not signed by enough control keys
+	tx, err = vm.newCreateChainTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		nil,
+		avm.ID,
+		nil,
+		"chain name",
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err == nil {
+		t.Fatalf("should have failed because the tx doesn't have enough control sigs")
+	}
+
+	// Signed by a key that isn't a control key
+	tx, err = vm.newCreateChainTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		nil,
+		avm.ID,
+		nil,
+		"chain name",
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0], keys[3]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err == nil {
+		t.Fatalf("should have failed because a control sig is from a key that isn't a control key")
+	}
+
+	// The subnet doesn't exist
+	tx, err = vm.newCreateChainTx(
+		defaultNonce+1,
+		ids.Empty.Prefix(1),
+		nil,
+		avm.ID,
+		nil,
+		"chain name",
+		testNetworkID,
+		nil,
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err == nil {
+		t.Fatalf("should have failed because the subnet doesn't exist")
+	}
+}
+
+func TestVerifyGenesis(t *testing.T) {
+	vm := defaultVM()
+
+	apiServer := &api.Server{}
+	apiServer.Initialize(logging.NoLog{}, nil, 0, api.CORSConfig{})
+	vmManager := vms.NewManager(apiServer, logging.NoLog{})
+	if err := vmManager.RegisterVMFactory(timestampvm.ID, &timestampvm.Factory{}); err != nil {
+		t.Fatal(err)
+	}
+	vm.VMManager = vmManager
+
+	if err := vm.verifyGenesis(timestampvm.ID, []byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.verifyGenesis(timestampvm.ID, make([]byte, 33)); err == nil {
+		t.Fatalf("should have failed because the genesis data is too long")
+	}
+	if err := vm.verifyGenesis(avm.ID, nil); err == nil {
+		t.Fatalf("should have failed because the VM isn't registered")
+	}
+}
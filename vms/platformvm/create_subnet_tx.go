@@ -84,7 +84,7 @@ func (tx *CreateSubnetTx) SyntacticVerify() error {
 	}
 
 	// Recover signature from byte repr. of unsigned tx
-	key, err := tx.vm.factory.RecoverPublicKey(unsignedBytes, tx.Sig[:]) // the public key that signed [tx]
+	key, err := tx.vm.factory.RecoverHashPublicKey(tx.vm.signingDigest(unsignedBytes), tx.Sig[:]) // the public key that signed [tx]
 	if err != nil {
 		return err
 	}
@@ -176,7 +176,7 @@ func (vm *VM) newCreateSubnetTx(networkID uint32, nonce uint64, controlKeys []id
 		return nil, err
 	}
 
-	sig, err := payerKey.Sign(unsignedBytes)
+	sig, err := payerKey.SignHash(vm.signingDigest(unsignedBytes))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,176 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/components/shared"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNoExportedFunds = errors.New("no funds are exported")
+)
+
+// UnsignedExportTx is an unsigned ExportTx
+type UnsignedExportTx struct {
+	// ID of the network this transaction exists on
+	NetworkID uint32 `serialize:"true"`
+
+	// Next unused nonce of the account the exported $AVA is removed from
+	Nonce uint64 `serialize:"true"`
+
+	// Address, on the AVM, that can import the exported $AVA
+	To ids.ShortID `serialize:"true"`
+
+	// Amount of $AVA exported
+	Amount uint64 `serialize:"true"`
+}
+
+// ExportTx exports $AVA from the account of the signer of the transaction to
+// the AVM
+type ExportTx struct {
+	UnsignedExportTx `serialize:"true"`
+
+	Sig [crypto.SECP256K1RSigLen]byte `serialize:"true"`
+
+	vm    *VM
+	id    ids.ID
+	key   crypto.PublicKey // public key of transaction signer
+	bytes []byte
+}
+
+func (tx *ExportTx) initialize(vm *VM) error {
+	tx.vm = vm
+	txBytes, err := Codec.Marshal(tx) // byte repr. of the signed tx
+	tx.bytes = txBytes
+	tx.id = ids.NewID(hashing.ComputeHash256Array(txBytes))
+	return err
+}
+
+// ID of this transaction
+func (tx *ExportTx) ID() ids.ID { return tx.id }
+
+// Key returns the public key of the signer of this transaction
+// Precondition: tx.Verify() has been called and returned nil
+func (tx *ExportTx) Key() crypto.PublicKey { return tx.key }
+
+// Bytes returns the byte representation of an ExportTx
+func (tx *ExportTx) Bytes() []byte { return tx.bytes }
+
+// SyntacticVerify this transaction is well-formed
+// Also populates [tx.Key] with the public key that signed this transaction
+func (tx *ExportTx) SyntacticVerify() error {
+	switch {
+	case tx == nil:
+		return errNilTx
+	case tx.key != nil:
+		return nil // Only verify the transaction once
+	case tx.NetworkID != tx.vm.Ctx.NetworkID: // verify the transaction is on this network
+		return errWrongNetworkID
+	case tx.id.IsZero():
+		return errInvalidID
+	case tx.Amount == 0:
+		return errNoExportedFunds
+	}
+
+	unsignedIntf := interface{}(&tx.UnsignedExportTx)
+	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // byte repr of unsigned tx
+	if err != nil {
+		return err
+	}
+
+	key, err := tx.vm.factory.RecoverHashPublicKey(tx.vm.signingDigest(unsignedBytes), tx.Sig[:])
+	if err != nil {
+		return err
+	}
+	tx.key = key
+
+	return nil
+}
+
+// SemanticVerify this transaction is valid.
+func (tx *ExportTx) SemanticVerify(db database.Database) (func(), error) {
+	if err := tx.SyntacticVerify(); err != nil {
+		return nil, err
+	}
+
+	// Remove the exported $AVA from the signer's account
+	account, err := tx.vm.getAccount(db, tx.Key().Address())
+	if err != nil {
+		return nil, err
+	}
+	account, err = account.Remove(tx.Amount, tx.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.vm.putAccount(db, account); err != nil {
+		return nil, err
+	}
+
+	if err := tx.vm.State.PutStatus(db, tx.ID(), choices.Accepted); err != nil {
+		return nil, err
+	}
+
+	// If this tx is accepted, write the exported UTXO to the shared memory
+	onAccept := func() {
+		sharedDB := tx.vm.Ctx.SharedMemory.GetDatabase(tx.vm.avm)
+		defer tx.vm.Ctx.SharedMemory.ReleaseDatabase(tx.vm.avm)
+
+		state := shared.NewState(sharedDB, tx.vm.avm)
+		if err := state.FundUTXO(tx.ExportedUTXO()); err != nil {
+			tx.vm.Ctx.Log.Error("failed to write exported utxo of %s to shared memory: %s", tx.ID(), err)
+		}
+	}
+
+	return onAccept, nil
+}
+
+// ExportedUTXO returns the UTXO that this transaction exports to the AVM
+func (tx *ExportTx) ExportedUTXO() *shared.UTXO {
+	return &shared.UTXO{
+		TxID:        tx.ID(),
+		OutputIndex: 0,
+		AssetID:     tx.vm.ava,
+		Out: secp256k1fx.TransferOutput{
+			Amt:      tx.Amount,
+			Locktime: 0,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{tx.To},
+			},
+		},
+	}
+}
+
+func (vm *VM) newExportTx(nonce uint64, to ids.ShortID, amount uint64, networkID uint32, key crypto.Signer) (*ExportTx, error) {
+	tx := &ExportTx{
+		UnsignedExportTx: UnsignedExportTx{
+			NetworkID: networkID,
+			Nonce:     nonce,
+			To:        to,
+			Amount:    amount,
+		},
+	}
+
+	unsignedIntf := interface{}(&tx.UnsignedExportTx)
+	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // Byte repr. of unsigned transaction
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := key.SignHash(vm.signingDigest(unsignedBytes))
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.Sig[:], sig)
+
+	return tx, tx.initialize(vm)
+}
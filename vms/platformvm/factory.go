@@ -7,6 +7,7 @@ import (
 	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/vms"
 )
 
 // ID of the platform VM
@@ -14,16 +15,34 @@ var (
 	ID = ids.NewID([32]byte{'p', 'l', 'a', 't', 'f', 'o', 'r', 'm', 'v', 'm'})
 )
 
+// Network notifies Connectors of peers connecting to and disconnecting from
+// this node
+type Network interface {
+	RegisterConnector(validators.Connector)
+}
+
 // Factory can create new instances of the Platform Chain
 type Factory struct {
 	ChainManager chains.Manager
+	VMManager    vms.Manager
 	Validators   validators.Manager
+	Network      Network // Used to track the uptime of validators. May be nil.
+	AVM          ids.ID  // ID of the AVM chain that $AVA is moved to and from
+	AVA          ids.ID  // ID of the $AVA asset on the AVM chain
 }
 
 // New returns a new instance of the Platform Chain
 func (f *Factory) New() interface{} {
-	return &VM{
+	vm := &VM{
 		ChainManager: f.ChainManager,
+		VMManager:    f.VMManager,
 		Validators:   f.Validators,
+		avm:          f.AVM,
+		ava:          f.AVA,
+	}
+	vm.uptimes.initialize(&vm.clock)
+	if f.Network != nil {
+		f.Network.RegisterConnector(vm)
 	}
+	return vm
 }
@@ -0,0 +1,222 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/math"
+	"github.com/ava-labs/gecko/vms/components/shared"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNoImportInputs          = errors.New("no import inputs")
+	errInputsNotSortedUnique   = errors.New("imported utxo IDs must be sorted and unique")
+	errUTXOAlreadyImported     = errors.New("utxo has already been imported")
+	errMissingUTXO             = errors.New("utxo wasn't exported to the platform chain")
+	errWrongAsset              = errors.New("only $AVA can be imported to the platform chain")
+	errUTXONotSpendable        = errors.New("utxo can't be spent by the signer of the tx")
+	errImportedAmountOverflows = errors.New("imported amount overflows uint64")
+)
+
+// UnsignedImportTx is an unsigned ImportTx
+type UnsignedImportTx struct {
+	// ID of the network this transaction exists on
+	NetworkID uint32 `serialize:"true"`
+
+	// Next unused nonce of the account the imported $AVA is added to
+	Nonce uint64 `serialize:"true"`
+
+	// IDs of the UTXOs, exported to this chain by the AVM, that are imported
+	UTXOIDs []ids.ID `serialize:"true"`
+}
+
+// ImportTx imports $AVA that the AVM exported to the platform chain into the
+// account of the signer of the transaction
+type ImportTx struct {
+	UnsignedImportTx `serialize:"true"`
+
+	Sig [crypto.SECP256K1RSigLen]byte `serialize:"true"`
+
+	vm    *VM
+	id    ids.ID
+	key   crypto.PublicKey // public key of transaction signer
+	bytes []byte
+}
+
+func (tx *ImportTx) initialize(vm *VM) error {
+	tx.vm = vm
+	txBytes, err := Codec.Marshal(tx) // byte repr. of the signed tx
+	tx.bytes = txBytes
+	tx.id = ids.NewID(hashing.ComputeHash256Array(txBytes))
+	return err
+}
+
+// ID of this transaction
+func (tx *ImportTx) ID() ids.ID { return tx.id }
+
+// Key returns the public key of the signer of this transaction
+// Precondition: tx.Verify() has been called and returned nil
+func (tx *ImportTx) Key() crypto.PublicKey { return tx.key }
+
+// Bytes returns the byte representation of an ImportTx
+func (tx *ImportTx) Bytes() []byte { return tx.bytes }
+
+// SyntacticVerify this transaction is well-formed
+// Also populates [tx.Key] with the public key that signed this transaction
+func (tx *ImportTx) SyntacticVerify() error {
+	switch {
+	case tx == nil:
+		return errNilTx
+	case tx.key != nil:
+		return nil // Only verify the transaction once
+	case tx.NetworkID != tx.vm.Ctx.NetworkID: // verify the transaction is on this network
+		return errWrongNetworkID
+	case tx.id.IsZero():
+		return errInvalidID
+	case len(tx.UTXOIDs) == 0:
+		return errNoImportInputs
+	case !ids.IsSortedAndUniqueIDs(tx.UTXOIDs):
+		return errInputsNotSortedUnique
+	}
+
+	unsignedIntf := interface{}(&tx.UnsignedImportTx)
+	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // byte repr of unsigned tx
+	if err != nil {
+		return err
+	}
+
+	key, err := tx.vm.factory.RecoverHashPublicKey(tx.vm.signingDigest(unsignedBytes), tx.Sig[:])
+	if err != nil {
+		return err
+	}
+	tx.key = key
+
+	return nil
+}
+
+// SemanticVerify this transaction is valid.
+func (tx *ImportTx) SemanticVerify(db database.Database) (func(), error) {
+	if err := tx.SyntacticVerify(); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := tx.vm.getTimestamp(db)
+	if err != nil {
+		return nil, err
+	}
+	addr := tx.Key().Address()
+
+	sharedDB := tx.vm.Ctx.SharedMemory.GetDatabase(tx.vm.avm)
+	defer tx.vm.Ctx.SharedMemory.ReleaseDatabase(tx.vm.avm)
+
+	state := shared.NewState(sharedDB, tx.vm.Ctx.ChainID)
+	amount := uint64(0)
+	for _, utxoID := range tx.UTXOIDs {
+		// The UTXO is only removed from the shared memory once this tx is
+		// accepted, so imports in processing blocks are tracked in [db]
+		if tx.vm.State.GetStatus(db, utxoID) == choices.Accepted {
+			return nil, errUTXOAlreadyImported
+		}
+		utxo, err := state.UTXO(utxoID)
+		if err != nil {
+			return nil, errMissingUTXO
+		}
+		if !utxo.AssetID.Equals(tx.vm.ava) {
+			return nil, errWrongAsset
+		}
+		if !canSpend(&utxo.Out, addr, uint64(timestamp.Unix())) {
+			return nil, errUTXONotSpendable
+		}
+		amount, err = math.Add64(amount, utxo.Out.Amt)
+		if err != nil {
+			return nil, errImportedAmountOverflows
+		}
+		if err := tx.vm.State.PutStatus(db, utxoID, choices.Accepted); err != nil {
+			return nil, err
+		}
+	}
+
+	// Add the imported $AVA to the signer's account
+	account, err := tx.vm.getAccount(db, addr)
+	if err != nil {
+		return nil, err
+	}
+	account, err = account.Remove(0, tx.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	account, err = account.Add(amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.vm.putAccount(db, account); err != nil {
+		return nil, err
+	}
+
+	if err := tx.vm.State.PutStatus(db, tx.ID(), choices.Accepted); err != nil {
+		return nil, err
+	}
+
+	// If this tx is accepted, remove the imported UTXOs from the shared memory
+	onAccept := func() {
+		sharedDB := tx.vm.Ctx.SharedMemory.GetDatabase(tx.vm.avm)
+		defer tx.vm.Ctx.SharedMemory.ReleaseDatabase(tx.vm.avm)
+
+		state := shared.NewState(sharedDB, tx.vm.Ctx.ChainID)
+		for _, utxoID := range tx.UTXOIDs {
+			if err := state.SpendUTXO(utxoID); err != nil {
+				tx.vm.Ctx.Log.Error("failed to remove imported utxo %s from shared memory: %s", utxoID, err)
+			}
+		}
+	}
+
+	return onAccept, nil
+}
+
+// canSpend returns true if [addr] alone can spend [out] at [timestamp]
+func canSpend(out *secp256k1fx.TransferOutput, addr ids.ShortID, timestamp uint64) bool {
+	switch {
+	case out.Locktime > timestamp || out.Threshold > 1:
+		return false
+	case out.Threshold == 0:
+		return true
+	}
+	for _, owner := range out.Addrs {
+		if owner.Equals(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (vm *VM) newImportTx(nonce uint64, utxoIDs []ids.ID, networkID uint32, key crypto.Signer) (*ImportTx, error) {
+	tx := &ImportTx{
+		UnsignedImportTx: UnsignedImportTx{
+			NetworkID: networkID,
+			Nonce:     nonce,
+			UTXOIDs:   utxoIDs,
+		},
+	}
+
+	unsignedIntf := interface{}(&tx.UnsignedImportTx)
+	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // Byte repr. of unsigned transaction
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := key.SignHash(vm.signingDigest(unsignedBytes))
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.Sig[:], sig)
+
+	return tx, tx.initialize(vm)
+}
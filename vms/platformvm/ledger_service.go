@@ -0,0 +1,88 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/crypto/ledger"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+// LedgerService signs transactions on the Ledger device connected to this
+// node. Its handler doesn't hold the chain's lock, since signing waits for the
+// signature to be approved on the device. The lock is only held while the
+// chain's state is read.
+type LedgerService struct {
+	vm *VM
+
+	// open returns a connection to the device
+	open func() (*ledger.Ledger, error)
+}
+
+// LedgerSignArgs are the arguments to Sign
+type LedgerSignArgs struct {
+	// The unsigned or partially signed transaction
+	Tx formatting.CB58 `json:"tx"`
+
+	// The index of the key on the device that signs the transaction
+	Index json.Uint32 `json:"index"`
+}
+
+// LedgerSignResponse is the response from Sign
+type LedgerSignResponse struct {
+	// The signed transaction
+	Tx formatting.CB58 `json:"tx"`
+
+	// The address of the key that signed the transaction
+	Signer ids.ShortID `json:"signer"`
+}
+
+// Sign [args.Tx] with the key at [args.Index] on the Ledger device. The
+// signature must be approved on the device.
+func (service *LedgerService) Sign(_ *http.Request, args *LedgerSignArgs, reply *LedgerSignResponse) error {
+	service.vm.Ctx.Log.Debug("platform.sign called on the Ledger endpoint")
+
+	genTx := genericTx{}
+	if err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
+		return err
+	}
+	unsignedTxBytes, err := unsignedBytes(&genTx)
+	if err != nil {
+		return err
+	}
+
+	device, err := service.open()
+	if err != nil {
+		return fmt.Errorf("couldn't open Ledger device: %w", err)
+	}
+	defer device.Close()
+
+	key, err := device.Signer(uint32(args.Index))
+	if err != nil {
+		return fmt.Errorf("couldn't get key %d from Ledger device: %w", args.Index, err)
+	}
+	sig, err := key.SignHash(service.vm.signingDigest(unsignedTxBytes))
+	if err != nil {
+		return fmt.Errorf("error while signing: %w", err)
+	}
+	fixedSig := [crypto.SECP256K1RSigLen]byte{}
+	copy(fixedSig[:], sig)
+
+	// Where the signature goes may depend on the subnet the tx is for
+	service.vm.Ctx.Lock.Lock()
+	err = (&Service{vm: service.vm}).addSignature(&genTx, key.PublicKey().Address(), fixedSig)
+	service.vm.Ctx.Lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	reply.Tx.Bytes, err = Codec.Marshal(genTx)
+	reply.Signer = key.PublicKey().Address()
+	return err
+}
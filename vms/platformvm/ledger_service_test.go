@@ -0,0 +1,86 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/crypto/ledger"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+func TestLedgerSign(t *testing.T) {
+	vm := defaultVM()
+	s := Service{vm: vm}
+
+	key := keys[0]
+	startTime := defaultValidateStartTime.Add(time.Second)
+	stakeAmount := cjson.Uint64(MinimumStakeAmount)
+
+	unsignedReply := AddDefaultSubnetDelegatorResponse{}
+	if err := s.AddDefaultSubnetDelegator(nil, &AddDefaultSubnetDelegatorArgs{
+		APIValidator: APIValidator{
+			StartTime:   cjson.Uint64(startTime.Unix()),
+			EndTime:     cjson.Uint64(defaultValidateEndTime.Unix()),
+			StakeAmount: &stakeAmount,
+			ID:          key.PublicKey().Address(),
+		},
+		Destination: key.PublicKey().Address(),
+		PayerNonce:  cjson.Uint64(defaultNonce + 1),
+	}, &unsignedReply); err != nil {
+		t.Fatal(err)
+	}
+
+	// The chain's lock isn't held while the signature is approved
+	device := ledger.NewEmulator(key)
+	device.Approve = func() bool {
+		locked := make(chan struct{})
+		go func() {
+			vm.Ctx.Lock.Lock()
+			vm.Ctx.Lock.Unlock()
+			close(locked)
+		}()
+		select {
+		case <-locked:
+			return true
+		case <-time.After(5 * time.Second):
+			t.Error("the chain's lock was held while the signature was approved")
+			return false
+		}
+	}
+	ls := LedgerService{
+		vm:   vm,
+		open: func() (*ledger.Ledger, error) { return ledger.New(device), nil },
+	}
+
+	signedReply := LedgerSignResponse{}
+	if err := ls.Sign(nil, &LedgerSignArgs{Tx: unsignedReply.UnsignedTx, Index: 3}, &signedReply); err != nil {
+		t.Fatal(err)
+	}
+	if !signedReply.Signer.Equals(key.PublicKey().Address()) {
+		t.Fatalf("signer should be %s but is %s", key.PublicKey().Address(), signedReply.Signer)
+	}
+	if err := s.IssueTx(nil, &IssueTxArgs{Tx: signedReply.Tx}, &IssueTxResponse{}); err != nil {
+		t.Fatal(err)
+	}
+
+	device.Approve = func() bool { return false }
+	if err := ls.Sign(nil, &LedgerSignArgs{Tx: unsignedReply.UnsignedTx}, &LedgerSignResponse{}); err == nil {
+		t.Fatalf("should have failed when the signature was rejected on the device")
+	}
+}
+
+func TestLedgerHandlerDoesntLock(t *testing.T) {
+	vm := defaultVM()
+	handler, ok := vm.CreateHandlers()["/ledger"]
+	if !ok {
+		t.Fatalf("the Ledger endpoint wasn't created")
+	}
+	if handler.LockOptions != common.NoLock {
+		t.Fatalf("the Ledger endpoint should be registered with NoLock but has %d", handler.LockOptions)
+	}
+}
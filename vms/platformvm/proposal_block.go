@@ -4,6 +4,8 @@
 package platformvm
 
 import (
+	"time"
+
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/ids"
@@ -85,6 +87,8 @@ func (pb *ProposalBlock) onAbort() (*versiondb.Database, func()) {
 //
 // If this block is valid, this function also sets pas.onCommit and pas.onAbort.
 func (pb *ProposalBlock) Verify() error {
+	defer pb.vm.VMMetrics.Verified(time.Now())
+
 	// pdb is the database if this block's parent is accepted
 	var pdb database.Database
 	parent := pb.parentBlock()
@@ -106,6 +110,12 @@ func (pb *ProposalBlock) Verify() error {
 	return nil
 }
 
+// Reject implements the snowman.Block interface
+func (pb *ProposalBlock) Reject() {
+	pb.CommonBlock.Reject()
+	pb.vm.VMMetrics.Rejected(1)
+}
+
 // Options returns the possible children of this block in preferential order.
 func (pb *ProposalBlock) Options() [2]snowman.Block {
 	blockID := pb.ID()
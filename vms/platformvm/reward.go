@@ -6,6 +6,8 @@ package platformvm
 import (
 	"math"
 	"time"
+
+	safemath "github.com/ava-labs/gecko/utils/math"
 )
 
 // reward returns the amount of $AVA to reward the staker with
@@ -21,5 +23,30 @@ func reward(duration time.Duration, amount uint64, inflationRate float64) uint64
 	// Amount of the reward in $AVA
 	reward := value - float64(amount)
 
-	return uint64(reward)
+	// Converting a float that's out of the range of a uint64 is undefined
+	switch {
+	case reward <= 0:
+		return 0
+	case reward >= math.MaxUint64:
+		return math.MaxUint64
+	default:
+		return uint64(reward)
+	}
+}
+
+// splitReward returns the portions of [reward], earned by a delegation to a
+// validator that keeps [shares] out of NumberOfShares, that go to the
+// delegator and to the validator
+func splitReward(reward uint64, shares uint32) (uint64, uint64) {
+	// Because shares <= NumberOfShares this will never underflow
+	delegatorShares := NumberOfShares - uint64(shares)
+	// Because delegatorShares <= NumberOfShares this will never overflow
+	delegatorReward := delegatorShares * (reward / NumberOfShares)
+	// Delay rounding as long as possible for small numbers
+	if optimisticReward, err := safemath.Mul64(delegatorShares, reward); err == nil {
+		delegatorReward = optimisticReward / NumberOfShares
+	}
+
+	// Because delegatorReward <= reward this will never underflow
+	return delegatorReward, reward - delegatorReward
 }
@@ -0,0 +1,89 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+)
+
+// RewardKind describes why a reward was paid
+type RewardKind uint8
+
+// The reasons a reward is paid
+const (
+	// ValidationReward is paid to a validator for validating the default subnet
+	ValidationReward RewardKind = iota
+	// DelegationReward is paid to a delegator for its delegation
+	DelegationReward
+	// DelegationFeeReward is the portion of a delegation's reward that the
+	// validator delegated to keeps
+	DelegationFeeReward
+)
+
+func (k RewardKind) String() string {
+	switch k {
+	case ValidationReward:
+		return "validation"
+	case DelegationReward:
+		return "delegation"
+	case DelegationFeeReward:
+		return "delegationFee"
+	default:
+		return "unknown"
+	}
+}
+
+// RewardPayout is a reward paid to an account when a staker left the default
+// subnet
+type RewardPayout struct {
+	// ID of the tx that added the staker
+	TxID ids.ID `serialize:"true"`
+	// Kind of reward that was paid
+	Kind RewardKind `serialize:"true"`
+	// NodeID of the validator that was validating or delegated to
+	NodeID ids.ShortID `serialize:"true"`
+	// StakeAmount is the amount of $AVA the staker bonded
+	StakeAmount uint64 `serialize:"true"`
+	// Reward is the amount of $AVA paid on top of the returned stake
+	Reward uint64 `serialize:"true"`
+	// Time, in Unix time, the staker stopped staking
+	Time uint64 `serialize:"true"`
+}
+
+// rewardPayoutList is the reward payouts made to an account, oldest first
+type rewardPayoutList []*RewardPayout
+
+// Bytes returns the binary representation of [lst]
+func (lst rewardPayoutList) Bytes() []byte {
+	bytes, _ := Codec.Marshal(lst)
+	return bytes
+}
+
+// get the reward payouts made to [address]
+func (vm *VM) getRewardPayouts(db database.Database, address ids.ShortID) ([]*RewardPayout, error) {
+	payoutsIntf, err := vm.State.Get(db, rewardPayoutsTypeID, address.LongID())
+	if err == database.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	payouts, ok := payoutsIntf.([]*RewardPayout)
+	if !ok {
+		vm.Ctx.Log.Warn("expected to retrieve []*RewardPayout from database but got different type")
+		return nil, errDB
+	}
+	return payouts, nil
+}
+
+// record that [payout] was made to [address] in [db]
+func (vm *VM) putRewardPayout(db database.Database, address ids.ShortID, payout *RewardPayout) error {
+	payouts, err := vm.getRewardPayouts(db, address)
+	if err != nil {
+		return err
+	}
+	payouts = append(payouts, payout)
+	return vm.State.Put(db, rewardPayoutsTypeID, address.LongID(), rewardPayoutList(payouts))
+}
@@ -149,6 +149,19 @@ func (tx *rewardValidatorTx) SemanticVerify(db database.Database) (*versiondb.Da
 		if err := tx.vm.putAccount(onAbortDB, accountNoReward); err != nil {
 			return nil, nil, nil, nil, errDBPutAccount
 		}
+
+		// The reward is only paid, and so only recorded, if this tx's proposal
+		// is committed
+		if err := tx.vm.putRewardPayout(onCommitDB, accountID, &RewardPayout{
+			TxID:        vdrTx.ID(),
+			Kind:        ValidationReward,
+			NodeID:      vdrTx.NodeID,
+			StakeAmount: amount,
+			Reward:      reward,
+			Time:        uint64(currentTime.Unix()),
+		}); err != nil {
+			return nil, nil, nil, nil, errDBPutRewardPayout
+		}
 	case *addDefaultSubnetDelegatorTx:
 		parentTx, err := currentEvents.getDefaultSubnetStaker(vdrTx.NodeID)
 		if err != nil {
@@ -158,18 +171,7 @@ func (tx *rewardValidatorTx) SemanticVerify(db database.Database) (*versiondb.Da
 		duration := vdrTx.Duration()
 		amount := vdrTx.Wght
 		reward := reward(duration, amount, InflationRate)
-
-		// Because parentTx.Shares <= NumberOfShares this will never underflow
-		delegatorShares := NumberOfShares - uint64(parentTx.Shares)
-		// Because delegatorShares <= NumberOfShares this will never overflow
-		delegatorReward := delegatorShares * (reward / NumberOfShares)
-		// Delay rounding as long as possible for small numbers
-		if optimisticReward, err := math.Mul64(delegatorShares, reward); err == nil {
-			delegatorReward = optimisticReward / NumberOfShares
-		}
-
-		// Because delegatorReward <= reward this will never underflow
-		validatorReward := reward - delegatorReward
+		delegatorReward, validatorReward := splitReward(reward, parentTx.Shares)
 
 		delegatorAmountWithReward, err := math.Add64(amount, delegatorReward)
 		if err != nil {
@@ -227,6 +229,27 @@ func (tx *rewardValidatorTx) SemanticVerify(db database.Database) (*versiondb.Da
 		if err := tx.vm.putAccount(onCommitDB, validatorAccountWithReward); err != nil {
 			return nil, nil, nil, nil, errDBPutAccount
 		}
+
+		if err := tx.vm.putRewardPayout(onCommitDB, delegatorAccountID, &RewardPayout{
+			TxID:        vdrTx.ID(),
+			Kind:        DelegationReward,
+			NodeID:      vdrTx.NodeID,
+			StakeAmount: amount,
+			Reward:      delegatorReward,
+			Time:        uint64(currentTime.Unix()),
+		}); err != nil {
+			return nil, nil, nil, nil, errDBPutRewardPayout
+		}
+		if err := tx.vm.putRewardPayout(onCommitDB, validatorAccountID, &RewardPayout{
+			TxID:        vdrTx.ID(),
+			Kind:        DelegationFeeReward,
+			NodeID:      vdrTx.NodeID,
+			StakeAmount: amount,
+			Reward:      validatorReward,
+			Time:        uint64(currentTime.Unix()),
+		}); err != nil {
+			return nil, nil, nil, nil, errDBPutRewardPayout
+		}
 	default:
 		return nil, nil, nil, nil, errShouldBeDSValidator
 	}
@@ -230,4 +230,30 @@ func TestRewardDelegatorTxSemanticVerify(t *testing.T) {
 	if expectedBalance := (defaultStakeAmount * 21) / 20; account.Balance != expectedBalance {
 		t.Fatalf("expected account balance to be %d was %d", expectedBalance, account.Balance)
 	}
+
+	// the rewards should have been recorded
+	payouts, err := vm.getRewardPayouts(onCommitDB, delTx.Destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payouts) != 1 {
+		t.Fatalf("expected 1 payout to the delegator but got %d", len(payouts))
+	}
+	if payout := payouts[0]; !payout.TxID.Equals(delTx.ID()) || payout.Kind != DelegationReward || payout.Reward != (defaultStakeAmount*3)/100 {
+		t.Fatalf("wrong payout to the delegator: %+v", payout)
+	}
+
+	payouts, err = vm.getRewardPayouts(onCommitDB, vdrTx.Destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payouts) != 2 {
+		t.Fatalf("expected 2 payouts to the validator but got %d", len(payouts))
+	}
+	if payout := payouts[0]; payout.Kind != DelegationFeeReward || payout.Reward != defaultStakeAmount/100 {
+		t.Fatalf("wrong delegation fee payout to the validator: %+v", payout)
+	}
+	if payout := payouts[1]; payout.Kind != ValidationReward || payout.Reward != defaultStakeAmount/25 {
+		t.Fatalf("wrong validation payout to the validator: %+v", payout)
+	}
 }
@@ -9,14 +9,19 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/gorilla/rpc/v2/json2"
 
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/json"
+	"github.com/ava-labs/gecko/vms/components/shared"
+
+	safemath "github.com/ava-labs/gecko/utils/math"
 )
 
 var (
@@ -26,27 +31,18 @@ var (
 	errGetAccounts          = errors.New("error getting accounts controlled by specified user")
 	errGetUser              = errors.New("error while getting user. Does user exist?")
 	errNoMethodWithGenesis  = errors.New("no method was provided but genesis data was provided")
+	errGenesisAndMethod     = errors.New("genesis bytes and a method to build them can't both be provided")
 	errCreatingTransaction  = errors.New("problem while creating transaction")
 	errNoDestination        = errors.New("call is missing field 'stakeDestination'")
 	errNoSource             = errors.New("call is missing field 'stakeSource'")
 	errGetStakeSource       = errors.New("couldn't get account specified in 'stakeSource'")
+	errUnknownStartAddress  = errors.New("startIndex.address must be one of the provided addresses")
+	errNotValidator         = errors.New("not a current validator of the subnet")
+	errUnsignableTx         = errors.New("Could not parse given tx. Must be one of: addDefaultSubnetValidatorTx, addDefaultSubnetDelegatorTx, addNonDefaultSubnetValidatorTx, createSubnetTx, createChainTx, importTx, exportTx")
 )
 
-var key *crypto.PrivateKeySECP256K1R
-
-func init() {
-	cb58 := formatting.CB58{}
-	err := cb58.FromString("24jUJ9vZexUM6expyMcT48LBx27k1m7xpraoV62oSQAHdziao5")
-	if err != nil {
-		panic(err)
-	}
-	factory := crypto.FactorySECP256K1R{}
-	pk, err := factory.ToPrivateKey(cb58.Bytes)
-	if err != nil {
-		panic(err)
-	}
-	key = pk.(*crypto.PrivateKeySECP256K1R)
-}
+// maxUTXOsToFetch is the most UTXOs that a call to GetUTXOs returns
+const maxUTXOsToFetch = 1024
 
 // Service defines the API calls that can be made to the platform chain
 type Service struct{ vm *VM }
@@ -90,6 +86,11 @@ func (service *Service) GetSubnets(_ *http.Request, args *GetSubnetsArgs, respon
 	if err != nil {
 		return fmt.Errorf("error getting subnets from database: %v", err)
 	}
+	for _, subnet := range subnets {
+		if err := service.vm.setSubnetControl(service.vm.DB, subnet); err != nil {
+			return fmt.Errorf("error getting control keys of subnet %s: %v", subnet.ID, err)
+		}
+	}
 
 	getAll := len(args.IDs) == 0
 
@@ -225,6 +226,70 @@ func (service *Service) GetPendingValidators(_ *http.Request, args *GetPendingVa
 	return nil
 }
 
+// GetValidatorUptimeArgs are the arguments for calling GetValidatorUptime
+type GetValidatorUptimeArgs struct {
+	// Subnet the validator validates
+	// If omitted, defaults to default subnet
+	SubnetID ids.ID `json:"subnetID"`
+
+	// ID of the validator
+	ID ids.ShortID `json:"id"`
+}
+
+// GetValidatorUptimeReply are the results from calling GetValidatorUptime
+type GetValidatorUptimeReply struct {
+	// True if the validator is connected to this node now
+	Connected bool `json:"connected"`
+
+	// Number of seconds of the validator's staking period that this node has
+	// observed. This node only observes the time since it last started.
+	ObservedDuration json.Uint64 `json:"observedDuration"`
+
+	// Number of seconds of the observed duration that the validator was
+	// connected to this node
+	ConnectedDuration json.Uint64 `json:"connectedDuration"`
+
+	// Fraction of the observed duration that the validator was connected to
+	// this node, between 0 and 1
+	Uptime float64 `json:"uptime"`
+}
+
+// GetValidatorUptime returns how long a current validator has been connected
+// to this node over its staking period
+func (service *Service) GetValidatorUptime(_ *http.Request, args *GetValidatorUptimeArgs, reply *GetValidatorUptimeReply) error {
+	service.vm.Ctx.Log.Debug("GetValidatorUptime called with %s", args.ID)
+
+	if args.SubnetID.IsZero() {
+		args.SubnetID = DefaultSubnetID
+	}
+
+	validators, err := service.vm.getCurrentValidators(service.vm.DB, args.SubnetID)
+	if err != nil {
+		return fmt.Errorf("couldn't get validators of subnet with ID %s. Does it exist?", args.SubnetID)
+	}
+
+	for _, tx := range validators.Txs {
+		if !tx.Vdr().ID().Equals(args.ID) {
+			continue
+		}
+
+		connected, observed := service.vm.uptimes.uptime(args.ID, tx.StartTime(), tx.EndTime())
+		reply.Connected = service.vm.uptimes.isConnected(args.ID)
+		// This node is always connected to itself
+		if args.ID.Equals(service.vm.Ctx.NodeID) {
+			connected = observed
+			reply.Connected = true
+		}
+		reply.ObservedDuration = json.Uint64(observed / time.Second)
+		reply.ConnectedDuration = json.Uint64(connected / time.Second)
+		if observed > 0 {
+			reply.Uptime = float64(connected) / float64(observed)
+		}
+		return nil
+	}
+	return errNotValidator
+}
+
 // SampleValidatorsArgs are the arguments for calling SampleValidators
 type SampleValidatorsArgs struct {
 	// Number of validators in the sample
@@ -267,6 +332,288 @@ func (service *Service) SampleValidators(_ *http.Request, args *SampleValidators
 	return nil
 }
 
+/*
+ ******************************************************
+ ************ Get Delegations and Rewards *************
+ ******************************************************
+ */
+
+// APIDelegation is a delegation to a validator of the default subnet. [ID] is
+// the ID of the node delegated to.
+type APIDelegation struct {
+	APIValidator
+
+	TxID        ids.ID      `json:"txID"`
+	Destination ids.ShortID `json:"destination"`
+}
+
+// GetDelegationsArgs are the arguments for calling GetDelegations
+type GetDelegationsArgs struct {
+	// Address that receives the delegated $AVA, and rewards, when the
+	// delegations end
+	Address ids.ShortID `json:"address"`
+}
+
+// GetDelegationsReply are the results from calling GetDelegations
+type GetDelegationsReply struct {
+	Current []APIDelegation `json:"current"`
+	Pending []APIDelegation `json:"pending"`
+}
+
+// GetDelegations returns the current and pending delegations to validators of
+// the default subnet whose destination is [args.Address]
+func (service *Service) GetDelegations(_ *http.Request, args *GetDelegationsArgs, reply *GetDelegationsReply) error {
+	service.vm.Ctx.Log.Debug("GetDelegations called with {Address = %s}", args.Address)
+
+	current, err := service.vm.getCurrentValidators(service.vm.DB, DefaultSubnetID)
+	if err != nil {
+		return errDBCurrentValidators
+	}
+	pending, err := service.vm.getPendingValidators(service.vm.DB, DefaultSubnetID)
+	if err != nil {
+		return errDBPendingValidators
+	}
+
+	reply.Current = delegations(current, args.Address)
+	reply.Pending = delegations(pending, args.Address)
+	return nil
+}
+
+// delegations returns the delegations in [stakers] whose destination is
+// [address]
+func delegations(stakers *EventHeap, address ids.ShortID) []APIDelegation {
+	delegations := []APIDelegation{}
+	for _, txIntf := range stakers.Txs {
+		tx, ok := txIntf.(*addDefaultSubnetDelegatorTx)
+		if !ok || !tx.Destination.Equals(address) {
+			continue
+		}
+		stakeAmount := json.Uint64(tx.Wght)
+		delegations = append(delegations, APIDelegation{
+			APIValidator: APIValidator{
+				ID:          tx.NodeID,
+				StartTime:   json.Uint64(tx.StartTime().Unix()),
+				EndTime:     json.Uint64(tx.EndTime().Unix()),
+				StakeAmount: &stakeAmount,
+			},
+			TxID:        tx.ID(),
+			Destination: tx.Destination,
+		})
+	}
+	return delegations
+}
+
+// APIAccruedReward is the reward a current staker has earned so far
+type APIAccruedReward struct {
+	// ID of the tx that added the staker
+	TxID ids.ID `json:"txID"`
+	// Kind of reward; one of "validation", "delegation" or "delegationFee"
+	Kind string `json:"kind"`
+	// NodeID of the validator that is validating or delegated to
+	NodeID      ids.ShortID `json:"nodeID"`
+	StakeAmount json.Uint64 `json:"stakeAmount"`
+	// Accrued is the reward earned up to the chain's current time
+	Accrued json.Uint64 `json:"accrued"`
+	// Potential is the reward that will be paid if the staker is rewarded
+	// when it stops staking
+	Potential json.Uint64 `json:"potential"`
+}
+
+// GetAccruedRewardsArgs are the arguments for calling GetAccruedRewards
+type GetAccruedRewardsArgs struct {
+	// Address that receives the rewards
+	Address ids.ShortID `json:"address"`
+}
+
+// GetAccruedRewardsReply are the results from calling GetAccruedRewards
+type GetAccruedRewardsReply struct {
+	Rewards []APIAccruedReward `json:"rewards"`
+	// Total of the accrued rewards
+	Total json.Uint64 `json:"total"`
+}
+
+// GetAccruedRewards returns the rewards that the current stakers of the
+// default subnet have earned so far for [args.Address]. This includes the
+// validators' share of the rewards of the delegations made to them.
+func (service *Service) GetAccruedRewards(_ *http.Request, args *GetAccruedRewardsArgs, reply *GetAccruedRewardsReply) error {
+	service.vm.Ctx.Log.Debug("GetAccruedRewards called with {Address = %s}", args.Address)
+
+	current, err := service.vm.getCurrentValidators(service.vm.DB, DefaultSubnetID)
+	if err != nil {
+		return errDBCurrentValidators
+	}
+	now, err := service.vm.getTimestamp(service.vm.DB)
+	if err != nil {
+		return fmt.Errorf("couldn't get the chain's timestamp: %w", err)
+	}
+
+	reply.Rewards = []APIAccruedReward{}
+	add := func(tx TimedTx, kind RewardKind, nodeID ids.ShortID, stakeAmount, accrued, potential uint64) {
+		reply.Rewards = append(reply.Rewards, APIAccruedReward{
+			TxID:        tx.ID(),
+			Kind:        kind.String(),
+			NodeID:      nodeID,
+			StakeAmount: json.Uint64(stakeAmount),
+			Accrued:     json.Uint64(accrued),
+			Potential:   json.Uint64(potential),
+		})
+		reply.Total = json.Uint64(safemath.SaturatingAdd64(uint64(reply.Total), accrued))
+	}
+
+	for _, txIntf := range current.Txs {
+		elapsed := now.Sub(txIntf.StartTime())
+		switch {
+		case elapsed < 0:
+			elapsed = 0
+		case elapsed > txIntf.EndTime().Sub(txIntf.StartTime()):
+			elapsed = txIntf.EndTime().Sub(txIntf.StartTime())
+		}
+
+		switch tx := txIntf.(type) {
+		case *addDefaultSubnetValidatorTx:
+			if !tx.Destination.Equals(args.Address) {
+				continue
+			}
+			add(tx, ValidationReward, tx.NodeID, tx.Wght,
+				reward(elapsed, tx.Wght, InflationRate),
+				reward(tx.Duration(), tx.Wght, InflationRate),
+			)
+		case *addDefaultSubnetDelegatorTx:
+			parentTx, err := current.getDefaultSubnetStaker(tx.NodeID)
+			if err != nil {
+				service.vm.Ctx.Log.Warn("delegation %s has no validator: %s", tx.ID(), err)
+				continue
+			}
+			accruedDelegator, accruedValidator := splitReward(reward(elapsed, tx.Wght, InflationRate), parentTx.Shares)
+			potentialDelegator, potentialValidator := splitReward(reward(tx.Duration(), tx.Wght, InflationRate), parentTx.Shares)
+			if tx.Destination.Equals(args.Address) {
+				add(tx, DelegationReward, tx.NodeID, tx.Wght, accruedDelegator, potentialDelegator)
+			}
+			if parentTx.Destination.Equals(args.Address) {
+				add(tx, DelegationFeeReward, tx.NodeID, tx.Wght, accruedValidator, potentialValidator)
+			}
+		}
+	}
+	return nil
+}
+
+// APIRewardPayout is a reward that was paid when a staker stopped staking
+type APIRewardPayout struct {
+	// ID of the tx that added the staker
+	TxID ids.ID `json:"txID"`
+	// Kind of reward; one of "validation", "delegation" or "delegationFee"
+	Kind string `json:"kind"`
+	// NodeID of the validator that was validating or delegated to
+	NodeID      ids.ShortID `json:"nodeID"`
+	StakeAmount json.Uint64 `json:"stakeAmount"`
+	Reward      json.Uint64 `json:"reward"`
+	// Time, in Unix time, the staker stopped staking
+	Time json.Uint64 `json:"time"`
+}
+
+// GetRewardPayoutsArgs are the arguments for calling GetRewardPayouts
+type GetRewardPayoutsArgs struct {
+	// Address the rewards were paid to
+	Address ids.ShortID `json:"address"`
+}
+
+// GetRewardPayoutsReply are the results from calling GetRewardPayouts
+type GetRewardPayoutsReply struct {
+	Payouts []APIRewardPayout `json:"payouts"`
+}
+
+// GetRewardPayouts returns the rewards that have been paid to [args.Address],
+// oldest first
+func (service *Service) GetRewardPayouts(_ *http.Request, args *GetRewardPayoutsArgs, reply *GetRewardPayoutsReply) error {
+	service.vm.Ctx.Log.Debug("GetRewardPayouts called with {Address = %s}", args.Address)
+
+	payouts, err := service.vm.getRewardPayouts(service.vm.DB, args.Address)
+	if err != nil {
+		return fmt.Errorf("couldn't get reward payouts: %w", err)
+	}
+
+	reply.Payouts = make([]APIRewardPayout, len(payouts))
+	for i, payout := range payouts {
+		reply.Payouts[i] = APIRewardPayout{
+			TxID:        payout.TxID,
+			Kind:        payout.Kind.String(),
+			NodeID:      payout.NodeID,
+			StakeAmount: json.Uint64(payout.StakeAmount),
+			Reward:      json.Uint64(payout.Reward),
+			Time:        json.Uint64(payout.Time),
+		}
+	}
+	return nil
+}
+
+// EstimateRewardArgs are the arguments for calling EstimateReward
+type EstimateRewardArgs struct {
+	// Amount of $AVA to stake
+	Amount json.Uint64 `json:"amount"`
+	// Length of the staking period, in seconds
+	Duration json.Uint64 `json:"duration"`
+	// Time, in Unix time, the staking period starts. Defaults to the chain's
+	// current timestamp.
+	StartTime json.Uint64 `json:"startTime"`
+	// If given, the stake is a delegation to a validator that keeps [Shares]
+	// out of NumberOfShares of the delegation's reward
+	Shares *json.Uint32 `json:"shares"`
+}
+
+// EstimateRewardReply are the results from calling EstimateReward
+type EstimateRewardReply struct {
+	// Reward for the whole staking period
+	Reward json.Uint64 `json:"reward"`
+	// If the stake is a delegation, the portions of [Reward] that go to the
+	// delegator and to the validator
+	DelegatorReward *json.Uint64 `json:"delegatorReward,omitempty"`
+	ValidatorReward *json.Uint64 `json:"validatorReward,omitempty"`
+	// Times, in Unix time, the staking period starts and ends
+	StartTime json.Uint64 `json:"startTime"`
+	EndTime   json.Uint64 `json:"endTime"`
+}
+
+// EstimateReward returns the reward a staker would be paid for staking
+// [args.Amount] for [args.Duration] seconds starting at [args.StartTime]. The
+// stake is held to the same limits as a stake added to the default subnet.
+func (service *Service) EstimateReward(_ *http.Request, args *EstimateRewardArgs, reply *EstimateRewardReply) error {
+	service.vm.Ctx.Log.Debug("EstimateReward called with {Amount = %d, Duration = %d, StartTime = %d}", args.Amount, args.Duration, args.StartTime)
+
+	now, err := service.vm.getTimestamp(service.vm.DB)
+	if err != nil {
+		return fmt.Errorf("couldn't get the chain's timestamp: %w", err)
+	}
+	startTime := now
+	if args.StartTime != 0 {
+		startTime = time.Unix(int64(args.StartTime), 0)
+	}
+	duration := time.Duration(args.Duration) * time.Second
+
+	switch {
+	case uint64(args.Amount) < MinimumStakeAmount:
+		return errWeightTooSmall
+	case args.Duration > json.Uint64(MaximumStakingDuration/time.Second):
+		return errStakeTooLong
+	case duration < MinimumStakingDuration:
+		return errStakeTooShort
+	case startTime.Before(now):
+		return fmt.Errorf("start time %d is before the chain's timestamp %d", startTime.Unix(), now.Unix())
+	case args.Shares != nil && uint64(*args.Shares) > NumberOfShares:
+		return errTooManyShares
+	}
+
+	stakeReward := reward(duration, uint64(args.Amount), InflationRate)
+	reply.Reward = json.Uint64(stakeReward)
+	if args.Shares != nil {
+		delegatorReward, validatorReward := splitReward(stakeReward, uint32(*args.Shares))
+		reply.DelegatorReward = (*json.Uint64)(&delegatorReward)
+		reply.ValidatorReward = (*json.Uint64)(&validatorReward)
+	}
+	reply.StartTime = json.Uint64(startTime.Unix())
+	reply.EndTime = json.Uint64(startTime.Add(duration).Unix())
+	return nil
+}
+
 /*
  ******************************************************
  *************** Get/Create Accounts ******************
@@ -318,7 +665,7 @@ func (service *Service) ListAccounts(_ *http.Request, args *ListAccountsArgs, re
 	service.vm.Ctx.Log.Debug("platform.listAccounts called for user '%s'", args.Username)
 
 	// db holds the user's info that pertains to the Platform Chain
-	userDB, err := service.vm.Ctx.Keystore.GetDatabase(args.Username, args.Password)
+	userDB, err := service.vm.Ctx.Keystore.GetReadOnlyDatabase(args.Username, args.Password)
 	if err != nil {
 		return errGetUser
 	}
@@ -633,19 +980,21 @@ func (service *Service) Sign(_ *http.Request, args *SignArgs, reply *SignRespons
 		return err
 	}
 
-	switch tx := genTx.Tx.(type) {
-	case *addDefaultSubnetValidatorTx:
-		genTx.Tx, err = service.signAddDefaultSubnetValidatorTx(tx, key)
-	case *addDefaultSubnetDelegatorTx:
-		genTx.Tx, err = service.signAddDefaultSubnetDelegatorTx(tx, key)
-	case *addNonDefaultSubnetValidatorTx:
-		genTx.Tx, err = service.signAddNonDefaultSubnetValidatorTx(tx, key)
-	case *CreateSubnetTx:
-		genTx.Tx, err = service.signCreateSubnetTx(tx, key)
-	default:
-		err = errors.New("Could not parse given tx. Must be one of: addDefaultSubnetValidatorTx, addNonDefaultSubnetValidatorTx, createSubnetTx")
+	unsignedTxBytes, err := unsignedBytes(&genTx)
+	if err != nil {
+		return err
 	}
+	sig, err := key.SignHash(service.vm.signingDigest(unsignedTxBytes))
 	if err != nil {
+		return fmt.Errorf("error while signing: %w", err)
+	}
+	if len(sig) != crypto.SECP256K1RSigLen {
+		return fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+	}
+	fixedSig := [crypto.SECP256K1RSigLen]byte{}
+	copy(fixedSig[:], sig)
+
+	if err := service.addSignature(&genTx, key.PublicKey().Address(), fixedSig); err != nil {
 		return err
 	}
 
@@ -653,124 +1002,269 @@ func (service *Service) Sign(_ *http.Request, args *SignArgs, reply *SignRespons
 	return err
 }
 
-// Sign [unsigned] with [key]
-func (service *Service) signAddDefaultSubnetValidatorTx(tx *addDefaultSubnetValidatorTx, key *crypto.PrivateKeySECP256K1R) (*addDefaultSubnetValidatorTx, error) {
-	service.vm.Ctx.Log.Debug("platform.signAddDefaultSubnetValidatorTx called")
+// GetSigningBytesArgs are the arguments to GetSigningBytes
+type GetSigningBytesArgs struct {
+	// The unsigned or partially signed transaction
+	Tx formatting.CB58 `json:"tx"`
+}
 
-	// TODO: Should we check if tx is already signed?
-	unsignedIntf := interface{}(&tx.UnsignedAddDefaultSubnetValidatorTx)
-	unsignedTxBytes, err := Codec.Marshal(&unsignedIntf)
-	if err != nil {
-		return nil, fmt.Errorf("error serializing unsigned tx: %v", err)
+// GetSigningBytesResponse is the response from GetSigningBytes
+type GetSigningBytesResponse struct {
+	// The unsigned bytes of the transaction
+	Bytes formatting.CB58 `json:"bytes"`
+
+	// The SHA256 hash of the network ID, this chain's ID and [Bytes]. A
+	// signature is a 65 byte recoverable secp256k1 signature of this hash,
+	// [r || s || v].
+	Hash formatting.CB58 `json:"hash"`
+}
+
+// GetSigningBytes returns the bytes that the signatures of [args.Tx] sign.
+// This allows a transaction to be signed on a machine that doesn't run a node,
+// such as one that keeps a validator's keys offline. The signatures are added
+// to the transaction with AddSignature.
+func (service *Service) GetSigningBytes(_ *http.Request, args *GetSigningBytesArgs, reply *GetSigningBytesResponse) error {
+	service.vm.Ctx.Log.Debug("platform.getSigningBytes called")
+
+	genTx := genericTx{}
+	if err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
+		return err
 	}
 
-	sig, err := key.Sign(unsignedTxBytes)
+	unsignedTxBytes, err := unsignedBytes(&genTx)
 	if err != nil {
-		return nil, errors.New("error while signing")
-	}
-	if len(sig) != crypto.SECP256K1RSigLen {
-		return nil, fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+		return err
 	}
-	copy(tx.Sig[:], sig)
+	reply.Bytes.Bytes = unsignedTxBytes
+	reply.Hash.Bytes = service.vm.signingDigest(unsignedTxBytes)
+	return nil
+}
 
-	return tx, nil
+// EstimateFeeArgs are the arguments to EstimateFee
+type EstimateFeeArgs struct {
+	// The transaction to estimate the fee of. Optional, as every transaction
+	// pays the same fee.
+	Tx formatting.CB58 `json:"tx"`
 }
 
-// Sign [unsigned] with [key]
-func (service *Service) signAddDefaultSubnetDelegatorTx(tx *addDefaultSubnetDelegatorTx, key *crypto.PrivateKeySECP256K1R) (*addDefaultSubnetDelegatorTx, error) {
-	service.vm.Ctx.Log.Debug("platform.signAddDefaultSubnetValidatorTx called")
+// EstimateFeeResponse is the response from EstimateFee
+type EstimateFeeResponse struct {
+	// The fee, in nAVA, that is removed from the paying account's balance
+	Fee json.Uint64 `json:"fee"`
+}
 
-	// TODO: Should we check if tx is already signed?
-	unsignedIntf := interface{}(&tx.UnsignedAddDefaultSubnetDelegatorTx)
-	unsignedTxBytes, err := Codec.Marshal(&unsignedIntf)
-	if err != nil {
-		return nil, fmt.Errorf("error serializing unsigned tx: %v", err)
-	}
+// EstimateFee returns the fee that a transaction must pay if it's issued now
+func (service *Service) EstimateFee(_ *http.Request, args *EstimateFeeArgs, reply *EstimateFeeResponse) error {
+	service.vm.Ctx.Log.Debug("platform.estimateFee called")
 
-	sig, err := key.Sign(unsignedTxBytes)
-	if err != nil {
-		return nil, errors.New("error while signing")
-	}
-	if len(sig) != crypto.SECP256K1RSigLen {
-		return nil, fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+	if len(args.Tx.Bytes) != 0 {
+		genTx := genericTx{}
+		if err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
+			return err
+		}
 	}
-	copy(tx.Sig[:], sig)
+	reply.Fee = json.Uint64(txFee)
+	return nil
+}
+
+// AddSignatureArgs are the arguments to AddSignature
+type AddSignatureArgs struct {
+	// The unsigned or partially signed transaction
+	Tx formatting.CB58 `json:"tx"`
 
-	return tx, nil
+	// A signature of the bytes returned by GetSigningBytes
+	Signature formatting.CB58 `json:"signature"`
 }
 
-// Sign [xt] with [key]
-func (service *Service) signCreateSubnetTx(tx *CreateSubnetTx, key *crypto.PrivateKeySECP256K1R) (*CreateSubnetTx, error) {
-	service.vm.Ctx.Log.Debug("platform.signAddDefaultSubnetValidatorTx called")
+// AddSignatureResponse is the response from AddSignature
+type AddSignatureResponse struct {
+	// The transaction, with the signature added
+	Tx formatting.CB58 `json:"tx"`
 
-	// TODO: Should we check if tx is already signed?
-	unsignedIntf := interface{}(&tx.UnsignedCreateSubnetTx)
-	unsignedTxBytes, err := Codec.Marshal(&unsignedIntf)
-	if err != nil {
-		return nil, fmt.Errorf("error serializing unsigned tx: %v", err)
+	// The address whose key produced the signature
+	Signer ids.ShortID `json:"signer"`
+}
+
+// AddSignature adds a signature that was produced outside of the node to
+// [args.Tx]. The signature is put in the same place as if its key had signed
+// the transaction with Sign. Once the transaction has all of its signatures, it
+// can be issued with IssueTx.
+func (service *Service) AddSignature(_ *http.Request, args *AddSignatureArgs, reply *AddSignatureResponse) error {
+	service.vm.Ctx.Log.Debug("platform.addSignature called")
+
+	if len(args.Signature.Bytes) != crypto.SECP256K1RSigLen {
+		return fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(args.Signature.Bytes))
+	}
+	sig := [crypto.SECP256K1RSigLen]byte{}
+	copy(sig[:], args.Signature.Bytes)
+
+	genTx := genericTx{}
+	if err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
+		return err
 	}
 
-	sig, err := key.Sign(unsignedTxBytes)
+	unsignedTxBytes, err := unsignedBytes(&genTx)
 	if err != nil {
-		return nil, errors.New("error while signing")
+		return err
 	}
-	if len(sig) != crypto.SECP256K1RSigLen {
-		return nil, fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+	signerKey, err := service.vm.factory.RecoverHashPublicKey(service.vm.signingDigest(unsignedTxBytes), sig[:])
+	if err != nil {
+		return fmt.Errorf("couldn't recover the signer of the signature: %w", err)
 	}
-	copy(tx.Sig[:], sig)
+	signer := signerKey.Address()
 
-	return tx, nil
+	if err := service.addSignature(&genTx, signer, sig); err != nil {
+		return err
+	}
+
+	reply.Tx.Bytes, err = Codec.Marshal(genTx)
+	if err != nil {
+		return err
+	}
+	reply.Signer = signer
+	return nil
 }
 
-// Signs an unsigned or partially signed addNonDefaultSubnetValidatorTx with [key]
-// If [key] is a control key for the subnet and there is an empty spot in tx.ControlSigs, signs there
-// If [key] is a control key for the subnet and there is no empty spot in tx.ControlSigs, signs as payer
-// If [key] is not a control key, sign as payer (account controlled by [key] pays the tx fee)
-// Sorts tx.ControlSigs before returning
-// Assumes each element of tx.ControlSigs is actually a signature, not just empty bytes
-func (service *Service) signAddNonDefaultSubnetValidatorTx(tx *addNonDefaultSubnetValidatorTx, key *crypto.PrivateKeySECP256K1R) (*addNonDefaultSubnetValidatorTx, error) {
-	service.vm.Ctx.Log.Debug("platform.signAddNonDefaultSubnetValidatorTx called")
+// unsignedBytes returns the bytes that the signatures of [genTx] sign
+func unsignedBytes(genTx *genericTx) ([]byte, error) {
+	var unsignedIntf interface{}
+	switch tx := genTx.Tx.(type) {
+	case *addDefaultSubnetValidatorTx:
+		unsignedIntf = &tx.UnsignedAddDefaultSubnetValidatorTx
+	case *addDefaultSubnetDelegatorTx:
+		unsignedIntf = &tx.UnsignedAddDefaultSubnetDelegatorTx
+	case *addNonDefaultSubnetValidatorTx:
+		unsignedIntf = &tx.UnsignedAddNonDefaultSubnetValidatorTx
+	case *CreateSubnetTx:
+		unsignedIntf = &tx.UnsignedCreateSubnetTx
+	case *CreateChainTx:
+		unsignedIntf = &tx.UnsignedCreateChainTx
+	case *UpdateSubnetTx:
+		unsignedIntf = &tx.UnsignedUpdateSubnetTx
+	case *ImportTx:
+		unsignedIntf = &tx.UnsignedImportTx
+	case *ExportTx:
+		unsignedIntf = &tx.UnsignedExportTx
+	default:
+		return nil, errUnsignableTx
+	}
 
-	// Compute the byte repr. of the unsigned tx and the signature of [key] over it
-	unsignedIntf := interface{}(&tx.UnsignedAddNonDefaultSubnetValidatorTx)
 	unsignedTxBytes, err := Codec.Marshal(&unsignedIntf)
 	if err != nil {
 		return nil, fmt.Errorf("error serializing unsigned tx: %v", err)
 	}
-	sig, err := key.Sign(unsignedTxBytes)
-	if err != nil {
-		return nil, errors.New("error while signing")
-	}
-	if len(sig) != crypto.SECP256K1RSigLen {
-		return nil, fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+	return unsignedTxBytes, nil
+}
+
+// addSignature adds [sig], which was produced by the key of [signer], to
+// [genTx]
+func (service *Service) addSignature(genTx *genericTx, signer ids.ShortID, sig [crypto.SECP256K1RSigLen]byte) error {
+	// TODO: Should we check if tx is already signed?
+	switch tx := genTx.Tx.(type) {
+	case *addDefaultSubnetValidatorTx:
+		tx.Sig = sig
+	case *addDefaultSubnetDelegatorTx:
+		tx.Sig = sig
+	case *addNonDefaultSubnetValidatorTx:
+		return service.addNonDefaultSubnetValidatorSig(tx, signer, sig)
+	case *CreateSubnetTx:
+		tx.Sig = sig
+	case *CreateChainTx:
+		return service.createChainSig(tx, signer, sig)
+	case *UpdateSubnetTx:
+		return service.updateSubnetSig(tx, signer, sig)
+	case *ImportTx:
+		tx.Sig = sig
+	case *ExportTx:
+		tx.Sig = sig
+	default:
+		return errUnsignableTx
 	}
+	return nil
+}
 
+// Adds [sig], by [signer], to an unsigned or partially signed addNonDefaultSubnetValidatorTx
+// If [signer] is a control key for the subnet and there is an empty spot in tx.ControlSigs, signs there
+// If [signer] is a control key for the subnet and there is no empty spot in tx.ControlSigs, signs as payer
+// If [signer] is not a control key, sign as payer (account controlled by [signer] pays the tx fee)
+// Assumes each element of tx.ControlSigs is actually a signature, not just empty bytes
+func (service *Service) addNonDefaultSubnetValidatorSig(tx *addNonDefaultSubnetValidatorTx, signer ids.ShortID, sig [crypto.SECP256K1RSigLen]byte) error {
 	// Get information about the subnet
 	subnet, err := service.vm.getSubnet(service.vm.DB, tx.SubnetID())
 	if err != nil {
-		return nil, fmt.Errorf("problem getting subnet information: %v", err)
+		return fmt.Errorf("problem getting subnet information: %v", err)
 	}
 
-	// Find the location at which [key] should put its signature.
-	// If [key] is a control key for this subnet and there is an empty spot in tx.ControlSigs, sign there
-	// If [key] is a control key for this subnet and there is no empty spot in tx.ControlSigs, sign as payer
-	// If [key] is not a control key, sign as payer (account controlled by [key] pays the tx fee)
+	// Find the location at which [signer] should put its signature.
 	controlKeySet := ids.ShortSet{}
 	controlKeySet.Add(subnet.ControlKeys...)
-	isControlKey := controlKeySet.Contains(key.PublicKey().Address())
+	isControlKey := controlKeySet.Contains(signer)
 
 	payerSigEmpty := tx.PayerSig == [crypto.SECP256K1RSigLen]byte{} // true if no key has signed to pay the tx fee
 
 	if isControlKey && len(tx.ControlSigs) != int(subnet.Threshold) { // Sign as controlSig
-		tx.ControlSigs = append(tx.ControlSigs, [crypto.SECP256K1RSigLen]byte{})
-		copy(tx.ControlSigs[len(tx.ControlSigs)-1][:], sig)
+		tx.ControlSigs = append(tx.ControlSigs, sig)
 	} else if payerSigEmpty { // sign as payer
-		copy(tx.PayerSig[:], sig)
+		tx.PayerSig = sig
 	} else {
-		return nil, errors.New("no place for key to sign")
+		return errors.New("no place for key to sign")
 	}
+	return nil
+}
+
+// Adds [sig], by [signer], to an unsigned or partially signed CreateChainTx.
+// Signatures are placed the same way as by addNonDefaultSubnetValidatorSig. If
+// the new chain is validated by the default subnet, [sig] is always the payer's.
+func (service *Service) createChainSig(tx *CreateChainTx, signer ids.ShortID, sig [crypto.SECP256K1RSigLen]byte) error {
+	isControlKey := false
+	threshold := 0
+	if !tx.SubnetID.Equals(DefaultSubnetID) {
+		subnet, err := service.vm.getSubnet(service.vm.DB, tx.SubnetID)
+		if err != nil {
+			return fmt.Errorf("problem getting subnet information: %v", err)
+		}
+		controlKeySet := ids.ShortSet{}
+		controlKeySet.Add(subnet.ControlKeys...)
+		isControlKey = controlKeySet.Contains(signer)
+		threshold = int(subnet.Threshold)
+	}
+
+	payerSigEmpty := tx.Sig == [crypto.SECP256K1RSigLen]byte{} // true if no key has signed to pay the tx fee
 
-	return tx, nil
+	if isControlKey && len(tx.ControlSigs) != threshold { // Sign as controlSig
+		tx.ControlSigs = append(tx.ControlSigs, sig)
+		crypto.SortSECP2561RSigs(tx.ControlSigs)
+	} else if payerSigEmpty { // sign as payer
+		tx.Sig = sig
+	} else {
+		return errors.New("no place for key to sign")
+	}
+	return nil
+}
+
+// Adds [sig], by [signer], to an unsigned or partially signed UpdateSubnetTx.
+// Signatures are placed the same way as by addNonDefaultSubnetValidatorSig,
+// using the control keys that the subnet has before the tx is accepted.
+func (service *Service) updateSubnetSig(tx *UpdateSubnetTx, signer ids.ShortID, sig [crypto.SECP256K1RSigLen]byte) error {
+	subnet, err := service.vm.getSubnet(service.vm.DB, tx.SubnetID)
+	if err != nil {
+		return fmt.Errorf("problem getting subnet information: %v", err)
+	}
+	controlKeySet := ids.ShortSet{}
+	controlKeySet.Add(subnet.ControlKeys...)
+	isControlKey := controlKeySet.Contains(signer)
+
+	payerSigEmpty := tx.Sig == [crypto.SECP256K1RSigLen]byte{} // true if no key has signed to pay the tx fee
+
+	if isControlKey && len(tx.ControlSigs) != int(subnet.Threshold) { // Sign as controlSig
+		tx.ControlSigs = append(tx.ControlSigs, sig)
+		crypto.SortSECP2561RSigs(tx.ControlSigs)
+	} else if payerSigEmpty { // sign as payer
+		tx.Sig = sig
+	} else {
+		return errors.New("no place for key to sign")
+	}
+	return nil
 }
 
 // IssueTxArgs are the arguments to IssueTx
@@ -809,8 +1303,40 @@ func (service *Service) IssueTx(_ *http.Request, args *IssueTxArgs, response *Is
 		defer service.vm.resetTimer()
 		response.TxID = tx.ID
 		return nil
+	case *CreateChainTx:
+		if err := tx.initialize(service.vm); err != nil {
+			return fmt.Errorf("error initializing tx: %s", err)
+		}
+		service.vm.unissuedDecisionTxs = append(service.vm.unissuedDecisionTxs, tx)
+		defer service.vm.resetTimer()
+		response.TxID = tx.ID()
+		return nil
+	case *ImportTx:
+		if err := tx.initialize(service.vm); err != nil {
+			return fmt.Errorf("error initializing tx: %s", err)
+		}
+		service.vm.unissuedDecisionTxs = append(service.vm.unissuedDecisionTxs, tx)
+		defer service.vm.resetTimer()
+		response.TxID = tx.ID()
+		return nil
+	case *ExportTx:
+		if err := tx.initialize(service.vm); err != nil {
+			return fmt.Errorf("error initializing tx: %s", err)
+		}
+		service.vm.unissuedDecisionTxs = append(service.vm.unissuedDecisionTxs, tx)
+		defer service.vm.resetTimer()
+		response.TxID = tx.ID()
+		return nil
+	case *UpdateSubnetTx:
+		if err := tx.initialize(service.vm); err != nil {
+			return fmt.Errorf("error initializing tx: %s", err)
+		}
+		service.vm.unissuedDecisionTxs = append(service.vm.unissuedDecisionTxs, tx)
+		defer service.vm.resetTimer()
+		response.TxID = tx.ID()
+		return nil
 	default:
-		return errors.New("Could not parse given tx. Must be one of: addDefaultSubnetValidatorTx, addDefaultSubnetDelegatorTx, addNonDefaultSubnetValidatorTx, createSubnetTx")
+		return errors.New("Could not parse given tx. Must be one of: addDefaultSubnetValidatorTx, addDefaultSubnetDelegatorTx, addNonDefaultSubnetValidatorTx, createSubnetTx, createChainTx, importTx, exportTx, updateSubnetTx")
 	}
 }
 
@@ -863,6 +1389,369 @@ func (service *Service) CreateSubnet(_ *http.Request, args *CreateSubnetArgs, re
 
 }
 
+// UpdateSubnetArgs are the arguments to UpdateSubnet
+type UpdateSubnetArgs struct {
+	// ID of the subnet to update
+	SubnetID ids.ID `json:"subnetID"`
+
+	// Control keys to give to the subnet
+	AddControlKeys []ids.ShortID `json:"addControlKeys"`
+
+	// Control keys to take from the subnet
+	RemoveControlKeys []ids.ShortID `json:"removeControlKeys"`
+
+	// Number of control keys whose signatures are needed once the subnet is
+	// updated
+	Threshold json.Uint16 `json:"threshold"`
+
+	// Nonce of the account that pays the transaction fee
+	PayerNonce json.Uint64 `json:"payerNonce"`
+}
+
+// UpdateSubnetResponse is the response from a call to UpdateSubnet
+type UpdateSubnetResponse struct {
+	// Byte representation of the unsigned transaction to update the subnet
+	UnsignedTx formatting.CB58 `json:"unsignedTx"`
+}
+
+// UpdateSubnet returns an unsigned transaction that changes the control keys
+// and threshold of a subnet. The unsigned transaction must be signed with a
+// threshold of the subnet's current control keys and with the key of the
+// payer.
+func (service *Service) UpdateSubnet(_ *http.Request, args *UpdateSubnetArgs, response *UpdateSubnetResponse) error {
+	service.vm.Ctx.Log.Debug("platform.updateSubnet called")
+
+	if args.SubnetID.Equals(DefaultSubnetID) {
+		return errUpdateDefaultSubnet
+	}
+	subnet, err := service.vm.getSubnet(service.vm.DB, args.SubnetID)
+	if err != nil {
+		return fmt.Errorf("problem getting subnet information: %w", err)
+	}
+
+	controlKeys := ids.ShortSet{}
+	controlKeys.Add(subnet.ControlKeys...)
+	controlKeys.Add(args.AddControlKeys...)
+	controlKeys.Remove(args.RemoveControlKeys...)
+	newControlKeys := controlKeys.List()
+	ids.SortShortIDs(newControlKeys)
+
+	if int(args.Threshold) > len(newControlKeys) {
+		return errThresholdExceedsKeysLen
+	}
+
+	tx := UpdateSubnetTx{
+		UnsignedUpdateSubnetTx: UnsignedUpdateSubnetTx{
+			NetworkID:   service.vm.Ctx.NetworkID,
+			SubnetID:    args.SubnetID,
+			Nonce:       uint64(args.PayerNonce),
+			ControlKeys: newControlKeys,
+			Threshold:   uint16(args.Threshold),
+		},
+		ControlSigs: [][crypto.SECP256K1RSigLen]byte{},
+	}
+
+	txBytes, err := Codec.Marshal(genericTx{Tx: &tx})
+	if err != nil {
+		return errCreatingTransaction
+	}
+
+	response.UnsignedTx.Bytes = txBytes
+	return nil
+}
+
+/*
+ ******************************************************
+ ************ Move $AVA to/from the AVM ***************
+ ******************************************************
+ */
+
+// ExportAVAArgs are the arguments to ExportAVA
+type ExportAVAArgs struct {
+	// Amount of $AVA to export
+	Amount json.Uint64 `json:"amount"`
+
+	// Address, on the AVM, that can import the exported $AVA
+	To ids.ShortID `json:"to"`
+
+	// Next unused nonce of the account the $AVA is exported from
+	PayerNonce json.Uint64 `json:"payerNonce"`
+}
+
+// ExportAVAResponse is the response from a call to ExportAVA
+type ExportAVAResponse struct {
+	// The unsigned transaction
+	UnsignedTx formatting.CB58 `json:"unsignedTx"`
+}
+
+// ExportAVA returns an unsigned transaction to export $AVA to the AVM.
+// The $AVA is exported from the account of the key that signs the transaction
+// using Sign(). Once the transaction is accepted, the $AVA must be imported on
+// the AVM.
+func (service *Service) ExportAVA(_ *http.Request, args *ExportAVAArgs, response *ExportAVAResponse) error {
+	service.vm.Ctx.Log.Debug("platform.exportAVA called")
+
+	if args.Amount == 0 {
+		return errNoExportedFunds
+	}
+
+	// Create the transaction
+	tx := ExportTx{UnsignedExportTx: UnsignedExportTx{
+		NetworkID: service.vm.Ctx.NetworkID,
+		Nonce:     uint64(args.PayerNonce),
+		To:        args.To,
+		Amount:    uint64(args.Amount),
+	}}
+
+	txBytes, err := Codec.Marshal(genericTx{Tx: &tx})
+	if err != nil {
+		return errCreatingTransaction
+	}
+
+	response.UnsignedTx.Bytes = txBytes
+	return nil
+}
+
+// ImportAVAArgs are the arguments to ImportAVA
+type ImportAVAArgs struct {
+	// Account the $AVA is imported to. The $AVA exported to this address by
+	// the AVM is imported.
+	To ids.ShortID `json:"to"`
+
+	// Next unused nonce of the account the $AVA is imported to
+	PayerNonce json.Uint64 `json:"payerNonce"`
+}
+
+// ImportAVAResponse is the response from a call to ImportAVA
+type ImportAVAResponse struct {
+	// The unsigned transaction
+	UnsignedTx formatting.CB58 `json:"unsignedTx"`
+}
+
+// ImportAVA returns an unsigned transaction to import the $AVA that the AVM
+// exported to [args.To]. The transaction must be signed with the key of
+// [args.To] using Sign().
+func (service *Service) ImportAVA(_ *http.Request, args *ImportAVAArgs, response *ImportAVAResponse) error {
+	service.vm.Ctx.Log.Debug("platform.importAVA called")
+
+	sharedDB := service.vm.Ctx.SharedMemory.GetDatabase(service.vm.avm)
+	defer service.vm.Ctx.SharedMemory.ReleaseDatabase(service.vm.avm)
+
+	state := shared.NewState(sharedDB, service.vm.Ctx.ChainID)
+	utxoIDs, err := state.Funds(args.To)
+	if err != nil {
+		return fmt.Errorf("problem retrieving exported UTXOs: %w", err)
+	}
+	if len(utxoIDs) == 0 {
+		return errNoImportInputs
+	}
+	ids.SortIDs(utxoIDs)
+
+	// Create the transaction
+	tx := ImportTx{UnsignedImportTx: UnsignedImportTx{
+		NetworkID: service.vm.Ctx.NetworkID,
+		Nonce:     uint64(args.PayerNonce),
+		UTXOIDs:   utxoIDs,
+	}}
+
+	txBytes, err := Codec.Marshal(genericTx{Tx: &tx})
+	if err != nil {
+		return errCreatingTransaction
+	}
+
+	response.UnsignedTx.Bytes = txBytes
+	return nil
+}
+
+// Index is a position in the UTXOs that a set of addresses own
+type Index struct {
+	Address ids.ShortID `json:"address"`
+	UTXO    ids.ID      `json:"utxo"`
+}
+
+// GetUTXOsArgs are the arguments to GetUTXOs
+type GetUTXOsArgs struct {
+	// Addresses whose exported UTXOs are returned
+	Addresses []ids.ShortID `json:"addresses"`
+
+	// Limit is the most UTXOs to return. If it's 0 or more than 1024, at
+	// most 1024 UTXOs are returned.
+	Limit json.Uint32 `json:"limit"`
+
+	// StartIndex is the endIndex of the previous page. If it's empty, the
+	// first page is returned.
+	StartIndex Index `json:"startIndex"`
+}
+
+// GetUTXOsResponse is the response from a call to GetUTXOs
+type GetUTXOsResponse struct {
+	// Number of UTXOs returned
+	NumFetched json.Uint64 `json:"numFetched"`
+
+	// The UTXOs, serialized in the format of the shared memory
+	UTXOs []formatting.CB58 `json:"utxos"`
+
+	// EndIndex is where the next page starts
+	EndIndex Index `json:"endIndex"`
+}
+
+// GetUTXOs returns the UTXOs that the AVM exported to this chain that at least
+// one of [args.Addresses] owns; these are the UTXOs that ImportAVA imports.
+// They're returned in pages of at most [args.Limit] UTXOs; a page with fewer
+// UTXOs than that is the last one. To get the next page, call GetUTXOs again
+// with the same addresses and [args.StartIndex] set to the
+// [response.EndIndex] of this page.
+func (service *Service) GetUTXOs(_ *http.Request, args *GetUTXOsArgs, response *GetUTXOsResponse) error {
+	service.vm.Ctx.Log.Debug("platform.getUTXOs called")
+
+	addrs := []ids.ShortID(nil)
+	addrSet := ids.ShortSet{}
+	startAddr := -1
+	for _, addr := range args.Addresses {
+		if addrSet.Contains(addr) {
+			continue
+		}
+		if addr.Equals(args.StartIndex.Address) {
+			startAddr = len(addrs)
+		}
+		addrSet.Add(addr)
+		addrs = append(addrs, addr)
+	}
+
+	startUTXO := args.StartIndex.UTXO
+	switch {
+	case args.StartIndex.Address.IsZero():
+		startAddr = 0
+		startUTXO = ids.ID{}
+	case startAddr == -1:
+		return errUnknownStartAddress
+	}
+
+	limit := int(args.Limit)
+	if limit <= 0 || limit > maxUTXOsToFetch {
+		limit = maxUTXOsToFetch
+	}
+
+	sharedDB := service.vm.Ctx.SharedMemory.GetDatabase(service.vm.avm)
+	defer service.vm.Ctx.SharedMemory.ReleaseDatabase(service.vm.avm)
+
+	state := shared.NewState(sharedDB, service.vm.Ctx.ChainID)
+
+	response.UTXOs = []formatting.CB58{}
+	seen := ids.Set{}
+	endAddr, endUTXO := startAddr, startUTXO
+	for i := startAddr; i < len(addrs) && len(response.UTXOs) < limit; i++ {
+		start := ids.ID{}
+		if i == startAddr {
+			start = startUTXO
+		}
+		for len(response.UTXOs) < limit {
+			numToFetch := limit - len(response.UTXOs)
+			utxoIDs, err := state.PaginatedFunds(addrs[i], start, numToFetch)
+			if err != nil {
+				return fmt.Errorf("problem retrieving exported UTXOs: %w", err)
+			}
+			for _, utxoID := range utxoIDs {
+				start = utxoID
+				endAddr, endUTXO = i, utxoID
+				if seen.Contains(utxoID) {
+					continue
+				}
+				seen.Add(utxoID)
+
+				utxo, err := state.UTXO(utxoID)
+				if err != nil {
+					return fmt.Errorf("problem retrieving exported UTXO %s: %w", utxoID, err)
+				}
+				b, err := shared.Codec.Marshal(utxo)
+				if err != nil {
+					return err
+				}
+				response.UTXOs = append(response.UTXOs, formatting.CB58{Bytes: b})
+			}
+			if len(utxoIDs) < numToFetch {
+				break // There are no more UTXOs of this address
+			}
+		}
+	}
+
+	response.NumFetched = json.Uint64(len(response.UTXOs))
+	if endAddr < len(addrs) {
+		response.EndIndex = Index{Address: addrs[endAddr], UTXO: endUTXO}
+	}
+	return nil
+}
+
+// GetAtomicTxStatusArgs are the arguments to GetAtomicTxStatus
+type GetAtomicTxStatusArgs struct {
+	// ID of the ImportTx or ExportTx
+	TxID ids.ID `json:"txID"`
+}
+
+// GetAtomicTxStatusReply is the response from a call to GetAtomicTxStatus
+type GetAtomicTxStatusReply struct {
+	Status choices.Status `json:"status"`
+}
+
+// GetAtomicTxStatus returns the status of the import or export transaction
+// [args.TxID]. An export is only importable on the AVM, and an import only
+// spendable on the platform chain, once its status is Accepted.
+func (service *Service) GetAtomicTxStatus(_ *http.Request, args *GetAtomicTxStatusArgs, reply *GetAtomicTxStatusReply) error {
+	service.vm.Ctx.Log.Debug("platform.getAtomicTxStatus called")
+
+	if accepted, err := service.atomicTxDecided(service.vm.LastAccepted(), args.TxID); err != nil {
+		return fmt.Errorf("problem looking up transaction: %w", err)
+	} else if accepted {
+		reply.Status = choices.Accepted
+		return nil
+	}
+
+	if processing, err := service.atomicTxDecided(service.vm.Preferred(), args.TxID); err != nil {
+		return fmt.Errorf("problem looking up transaction: %w", err)
+	} else if processing {
+		reply.Status = choices.Processing
+		return nil
+	}
+
+	for _, tx := range service.vm.unissuedDecisionTxs {
+		switch tx := tx.(type) {
+		case *ImportTx:
+			if tx.ID().Equals(args.TxID) {
+				reply.Status = choices.Processing
+				return nil
+			}
+		case *ExportTx:
+			if tx.ID().Equals(args.TxID) {
+				reply.Status = choices.Processing
+				return nil
+			}
+		}
+	}
+
+	reply.Status = choices.Unknown
+	return nil
+}
+
+// atomicTxDecided returns true if the import or export transaction [txID] is
+// in the state of the block [blockID]
+func (service *Service) atomicTxDecided(blockID ids.ID, txID ids.ID) (bool, error) {
+	blockIntf, err := service.vm.getBlock(blockID)
+	if err != nil {
+		return false, err
+	}
+
+	block, ok := blockIntf.(decision)
+	if !ok {
+		block, ok = blockIntf.Parent().(decision)
+		if !ok {
+			return false, errMissingDecisionBlock
+		}
+	}
+	db := block.onAccept()
+
+	return service.vm.State.GetStatus(db, txID) == choices.Accepted, nil
+}
+
 /*
  ******************************************************
  ******** Create/get status of a blockchain ***********
@@ -871,6 +1760,11 @@ func (service *Service) CreateSubnet(_ *http.Request, args *CreateSubnetArgs, re
 
 // CreateBlockchainArgs is the arguments for calling CreateBlockchain
 type CreateBlockchainArgs struct {
+	// ID of the subnet that validates the new blockchain. If it isn't the
+	// default subnet, the returned transaction must be signed by a threshold
+	// of the subnet's control keys.
+	SubnetID ids.ID `json:"subnetID"`
+
 	// ID of the VM the new blockchain is running
 	VMID string `json:"vmID"`
 
@@ -880,6 +1774,13 @@ type CreateBlockchainArgs struct {
 	// Human-readable name for the new blockchain, not necessarily unique
 	Name string `json:"name"`
 
+	// Next unused nonce of the account paying the transaction fee
+	PayerNonce json.Uint64 `json:"payerNonce"`
+
+	// Byte representation of the genesis data of the new blockchain. Should
+	// not be provided if [Method] is.
+	Genesis formatting.CB58 `json:"genesis"`
+
 	// To generate the byte representation of the genesis data for this blockchain,
 	// a POST request with body [GenesisData] is made to the API method whose name is [Method], whose
 	// endpoint is [Endpoint]. See Platform Chain documentation for more info and examples.
@@ -895,11 +1796,17 @@ type CreateGenesisReply struct {
 
 // CreateBlockchainReply is the reply from calling CreateBlockchain
 type CreateBlockchainReply struct {
-	BlockchainID ids.ID `json:"blockchainID"`
+	// The unsigned transaction
+	UnsignedTx formatting.CB58 `json:"unsignedTx"`
 }
 
-// CreateBlockchain issues a transaction to the network to create a new blockchain
+// CreateBlockchain returns an unsigned transaction to create a new blockchain.
+// The genesis data is verified by the blockchain's VM, if the VM supports it.
+// The returned unsigned transaction should be signed using Sign. The ID of the
+// new blockchain is the ID of the signed transaction, returned by IssueTx.
 func (service *Service) CreateBlockchain(_ *http.Request, args *CreateBlockchainArgs, reply *CreateBlockchainReply) error {
+	service.vm.Ctx.Log.Debug("platform.createBlockchain called")
+
 	vmID, err := service.vm.ChainManager.LookupVM(args.VMID)
 	if err != nil {
 		return fmt.Errorf("no VM with ID '%s' found", args.VMID)
@@ -913,9 +1820,14 @@ func (service *Service) CreateBlockchain(_ *http.Request, args *CreateBlockchain
 		}
 		fxIDs = append(fxIDs, fxID)
 	}
+	ids.SortIDs(fxIDs)
 
-	genesisBytes := []byte(nil)
+	genesisBytes := args.Genesis.Bytes
 	if args.Method != "" {
+		if len(genesisBytes) != 0 {
+			return errGenesisAndMethod
+		}
+
 		buf, err := json2.EncodeClientRequest(args.Method, args.GenesisData)
 		if err != nil {
 			return fmt.Errorf("problem building blockchain genesis state: %w", err)
@@ -942,19 +1854,37 @@ func (service *Service) CreateBlockchain(_ *http.Request, args *CreateBlockchain
 		return errNoMethodWithGenesis
 	}
 
-	// TODO: Should use the key store to sign this transaction.
-	// TODO: Nonce shouldn't always be 0
-	tx, err := service.vm.newCreateChainTx(0, genesisBytes, vmID, fxIDs, args.Name, service.vm.Ctx.NetworkID, key)
-	if err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
+	if err := service.vm.verifyGenesis(vmID, genesisBytes); err != nil {
+		return fmt.Errorf("invalid genesis data: %w", err)
 	}
 
-	// Add this tx to the set of unissued txs
-	service.vm.unissuedDecisionTxs = append(service.vm.unissuedDecisionTxs, tx)
-	service.vm.resetTimer()
+	if args.SubnetID.IsZero() {
+		args.SubnetID = DefaultSubnetID
+	} else if !args.SubnetID.Equals(DefaultSubnetID) {
+		if _, err := service.vm.getSubnet(service.vm.DB, args.SubnetID); err != nil {
+			return fmt.Errorf("problem getting subnet information: %w", err)
+		}
+	}
 
-	reply.BlockchainID = tx.ID()
+	tx := CreateChainTx{
+		UnsignedCreateChainTx: UnsignedCreateChainTx{
+			NetworkID:   service.vm.Ctx.NetworkID,
+			SubnetID:    args.SubnetID,
+			Nonce:       uint64(args.PayerNonce),
+			ChainName:   args.Name,
+			VMID:        vmID,
+			FxIDs:       fxIDs,
+			GenesisData: genesisBytes,
+		},
+		ControlSigs: [][crypto.SECP256K1RSigLen]byte{},
+	}
 
+	txBytes, err := Codec.Marshal(genericTx{Tx: &tx})
+	if err != nil {
+		return errCreatingTransaction
+	}
+
+	reply.UnsignedTx.Bytes = txBytes
 	return nil
 }
 
@@ -1026,3 +1956,55 @@ func (service *Service) chainExists(blockID ids.ID, chainID ids.ID) (bool, error
 
 	return false, nil
 }
+
+// APIBlockchain is the representation of a blockchain used in API calls
+type APIBlockchain struct {
+	// Blockchain's ID
+	ID ids.ID `json:"id"`
+
+	// Blockchain's (non-unique) human-readable name
+	Name string `json:"name"`
+
+	// Subnet that validates the blockchain
+	SubnetID ids.ID `json:"subnetID"`
+
+	// Virtual Machine the blockchain runs
+	VMID ids.ID `json:"vmID"`
+}
+
+// GetBlockchainsArgs are the arguments for calling GetBlockchains
+type GetBlockchainsArgs struct {
+	// If provided, only the blockchains validated by this subnet are returned
+	SubnetID ids.ID `json:"subnetID"`
+}
+
+// GetBlockchainsResponse is the response from a call to GetBlockchains
+type GetBlockchainsResponse struct {
+	// blockchains that exist
+	Blockchains []APIBlockchain `json:"blockchains"`
+}
+
+// GetBlockchains returns the blockchains that exist, or those validated by
+// [args.SubnetID] if it's provided
+func (service *Service) GetBlockchains(_ *http.Request, args *GetBlockchainsArgs, response *GetBlockchainsResponse) error {
+	service.vm.Ctx.Log.Debug("platform.getBlockchains called")
+
+	chains, err := service.vm.getChains(service.vm.DB)
+	if err != nil {
+		return fmt.Errorf("couldn't retrieve blockchains: %v", err)
+	}
+
+	response.Blockchains = []APIBlockchain{}
+	for _, chain := range chains {
+		if !args.SubnetID.IsZero() && !chain.SubnetID.Equals(args.SubnetID) {
+			continue
+		}
+		response.Blockchains = append(response.Blockchains, APIBlockchain{
+			ID:       chain.ID(),
+			Name:     chain.ChainName,
+			SubnetID: chain.SubnetID,
+			VMID:     chain.VMID,
+		})
+	}
+	return nil
+}
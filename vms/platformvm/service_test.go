@@ -4,8 +4,16 @@
 package platformvm
 
 import (
+	"bytes"
 	"encoding/json"
 	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/formatting"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
 )
 
 func TestAddDefaultSubnetValidator(t *testing.T) {
@@ -32,3 +40,273 @@ func TestCreateBlockchainArgsParsing(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestGetDelegationsAndAccruedRewards(t *testing.T) {
+	vm := defaultVM()
+	s := Service{vm: vm}
+
+	keyIntf1, err := vm.factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key1 := keyIntf1.(*crypto.PrivateKeySECP256K1R)
+
+	keyIntf2, err := vm.factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2 := keyIntf2.(*crypto.PrivateKeySECP256K1R)
+
+	startTime := defaultValidateEndTime.Add(-365 * 24 * time.Hour)
+	vdrTx, err := vm.newAddDefaultSubnetValidatorTx(
+		defaultNonce+1,     // nonce
+		defaultStakeAmount, // stakeAmt
+		uint64(startTime.Unix()),
+		uint64(defaultValidateEndTime.Unix()),
+		key1.PublicKey().Address(), // node ID
+		key1.PublicKey().Address(), // destination
+		NumberOfShares/4,
+		testNetworkID,
+		key1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delTx, err := vm.newAddDefaultSubnetDelegatorTx(
+		defaultNonce+1,     // nonce
+		defaultStakeAmount, // stakeAmt
+		uint64(startTime.Unix()),
+		uint64(defaultValidateEndTime.Unix()),
+		key1.PublicKey().Address(), // node ID
+		key2.PublicKey().Address(), // destination
+		testNetworkID,
+		key2,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	currentValidators, err := vm.getCurrentValidators(vm.DB, DefaultSubnetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	currentValidators.Add(vdrTx)
+	currentValidators.Add(delTx)
+	if err := vm.putCurrentValidators(vm.DB, currentValidators, DefaultSubnetID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Half way through the staking period
+	if err := vm.putTimestamp(vm.DB, startTime.Add(365*12*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	delegationsReply := GetDelegationsReply{}
+	if err := s.GetDelegations(nil, &GetDelegationsArgs{Address: key2.PublicKey().Address()}, &delegationsReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(delegationsReply.Current) != 1 || len(delegationsReply.Pending) != 0 {
+		t.Fatalf("expected 1 current and 0 pending delegations but got %d and %d", len(delegationsReply.Current), len(delegationsReply.Pending))
+	}
+	if delegation := delegationsReply.Current[0]; !delegation.TxID.Equals(delTx.ID()) || !delegation.ID.Equals(key1.PublicKey().Address()) {
+		t.Fatalf("wrong delegation returned: %+v", delegation)
+	}
+
+	delegatorReply := GetAccruedRewardsReply{}
+	if err := s.GetAccruedRewards(nil, &GetAccruedRewardsArgs{Address: key2.PublicKey().Address()}, &delegatorReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(delegatorReply.Rewards) != 1 {
+		t.Fatalf("expected 1 accrued reward but got %d", len(delegatorReply.Rewards))
+	}
+	delegatorReward := delegatorReply.Rewards[0]
+	switch {
+	case delegatorReward.Kind != DelegationReward.String():
+		t.Fatalf("wrong kind of reward %s", delegatorReward.Kind)
+	case uint64(delegatorReward.Potential) != (defaultStakeAmount*3)/100:
+		t.Fatalf("wrong potential reward %d", delegatorReward.Potential)
+	case delegatorReward.Accrued == 0 || delegatorReward.Accrued >= delegatorReward.Potential:
+		t.Fatalf("accrued reward %d should be part of the potential reward", delegatorReward.Accrued)
+	case delegatorReply.Total != delegatorReward.Accrued:
+		t.Fatalf("wrong total %d", delegatorReply.Total)
+	}
+
+	validatorReply := GetAccruedRewardsReply{}
+	if err := s.GetAccruedRewards(nil, &GetAccruedRewardsArgs{Address: key1.PublicKey().Address()}, &validatorReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(validatorReply.Rewards) != 2 {
+		t.Fatalf("expected 2 accrued rewards but got %d", len(validatorReply.Rewards))
+	}
+
+	payoutsReply := GetRewardPayoutsReply{}
+	if err := s.GetRewardPayouts(nil, &GetRewardPayoutsArgs{Address: key2.PublicKey().Address()}, &payoutsReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(payoutsReply.Payouts) != 0 {
+		t.Fatalf("no rewards should have been paid yet")
+	}
+}
+
+func TestOfflineSigning(t *testing.T) {
+	vm := defaultVM()
+	s := Service{vm: vm}
+
+	key := keys[0]
+	startTime := defaultValidateStartTime.Add(time.Second)
+	stakeAmount := cjson.Uint64(MinimumStakeAmount)
+
+	unsignedReply := AddDefaultSubnetDelegatorResponse{}
+	if err := s.AddDefaultSubnetDelegator(nil, &AddDefaultSubnetDelegatorArgs{
+		APIValidator: APIValidator{
+			StartTime:   cjson.Uint64(startTime.Unix()),
+			EndTime:     cjson.Uint64(defaultValidateEndTime.Unix()),
+			StakeAmount: &stakeAmount,
+			ID:          key.PublicKey().Address(),
+		},
+		Destination: key.PublicKey().Address(),
+		PayerNonce:  cjson.Uint64(defaultNonce + 1),
+	}, &unsignedReply); err != nil {
+		t.Fatal(err)
+	}
+
+	// The key never touches the node; only the hash to sign leaves it
+	bytesReply := GetSigningBytesResponse{}
+	if err := s.GetSigningBytes(nil, &GetSigningBytesArgs{Tx: unsignedReply.UnsignedTx}, &bytesReply); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := key.SignHash(bytesReply.Hash.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedReply := AddSignatureResponse{}
+	if err := s.AddSignature(nil, &AddSignatureArgs{
+		Tx:        unsignedReply.UnsignedTx,
+		Signature: formatting.CB58{Bytes: sig},
+	}, &signedReply); err != nil {
+		t.Fatal(err)
+	}
+	if !signedReply.Signer.Equals(key.PublicKey().Address()) {
+		t.Fatalf("signer should be %s but is %s", key.PublicKey().Address(), signedReply.Signer)
+	}
+
+	expectedTx, err := vm.newAddDefaultSubnetDelegatorTx(
+		defaultNonce+1,
+		MinimumStakeAmount,
+		uint64(startTime.Unix()),
+		uint64(defaultValidateEndTime.Unix()),
+		key.PublicKey().Address(),
+		key.PublicKey().Address(),
+		testNetworkID,
+		key,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBytes, err := Codec.Marshal(genericTx{Tx: expectedTx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signedReply.Tx.Bytes, expectedBytes) {
+		t.Fatalf("offline signed tx should match the tx signed by the node")
+	}
+
+	if err := s.IssueTx(nil, &IssueTxArgs{Tx: signedReply.Tx}, &IssueTxResponse{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.AddSignature(nil, &AddSignatureArgs{
+		Tx:        unsignedReply.UnsignedTx,
+		Signature: formatting.CB58{Bytes: sig[1:]},
+	}, &AddSignatureResponse{}); err == nil {
+		t.Fatalf("should have rejected a malformed signature")
+	}
+}
+
+func TestGetValidatorUptime(t *testing.T) {
+	vm := defaultVM()
+	s := Service{vm: vm}
+
+	validatorID := keys[1].PublicKey().Address()
+	vm.Connected(validatorID)
+	vm.clock.Set(defaultValidateStartTime.Add(100 * time.Second))
+	vm.Disconnected(validatorID)
+	vm.clock.Set(defaultValidateStartTime.Add(200 * time.Second))
+
+	reply := GetValidatorUptimeReply{}
+	if err := s.GetValidatorUptime(nil, &GetValidatorUptimeArgs{ID: validatorID}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case reply.Connected:
+		t.Fatalf("The validator shouldn't be connected")
+	case reply.ObservedDuration != 200:
+		t.Fatalf("Should have observed 200s but observed %ds", reply.ObservedDuration)
+	case reply.ConnectedDuration != 100:
+		t.Fatalf("Should have been connected for 100s but was for %ds", reply.ConnectedDuration)
+	case reply.Uptime != 0.5:
+		t.Fatalf("Uptime should be 0.5 but is %f", reply.Uptime)
+	}
+
+	if err := s.GetValidatorUptime(nil, &GetValidatorUptimeArgs{ID: ids.NewShortID([20]byte{1})}, &reply); err != errNotValidator {
+		t.Fatalf("Should have failed to get the uptime of a node that isn't a validator")
+	}
+}
+
+func TestEstimateReward(t *testing.T) {
+	vm := defaultVM()
+	s := Service{vm: vm}
+
+	now, err := vm.getTimestamp(vm.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := EstimateRewardArgs{
+		Amount:   cjson.Uint64(defaultStakeAmount),
+		Duration: cjson.Uint64(MaximumStakingDuration / time.Second),
+	}
+	reply := EstimateRewardReply{}
+	if err := s.EstimateReward(nil, &args, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if expected := reward(MaximumStakingDuration, defaultStakeAmount, InflationRate); uint64(reply.Reward) != expected {
+		t.Fatalf("expected reward %d but got %d", expected, reply.Reward)
+	}
+	if int64(reply.StartTime) != now.Unix() || int64(reply.EndTime) != now.Add(MaximumStakingDuration).Unix() {
+		t.Fatalf("wrong staking period [%d, %d]", reply.StartTime, reply.EndTime)
+	}
+	if reply.DelegatorReward != nil || reply.ValidatorReward != nil {
+		t.Fatalf("a validation shouldn't be split")
+	}
+
+	shares := cjson.Uint32(NumberOfShares / 4)
+	args.Shares = &shares
+	args.StartTime = cjson.Uint64(now.Add(time.Hour).Unix())
+	reply = EstimateRewardReply{}
+	if err := s.EstimateReward(nil, &args, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if delegatorReward, validatorReward := splitReward(uint64(reply.Reward), uint32(shares)); uint64(*reply.DelegatorReward) != delegatorReward || uint64(*reply.ValidatorReward) != validatorReward {
+		t.Fatalf("expected the reward to be split into %d and %d but got %d and %d", delegatorReward, validatorReward, *reply.DelegatorReward, *reply.ValidatorReward)
+	}
+	if int64(reply.StartTime) != now.Add(time.Hour).Unix() {
+		t.Fatalf("expected start time %d but got %d", now.Add(time.Hour).Unix(), reply.StartTime)
+	}
+
+	invalid := []EstimateRewardArgs{
+		{Amount: cjson.Uint64(MinimumStakeAmount - 1), Duration: args.Duration},
+		{Amount: args.Amount, Duration: cjson.Uint64(MinimumStakingDuration/time.Second - 1)},
+		{Amount: args.Amount, Duration: args.Duration + 1},
+		{Amount: args.Amount, Duration: args.Duration, StartTime: cjson.Uint64(now.Add(-time.Second).Unix())},
+	}
+	tooManyShares := cjson.Uint32(NumberOfShares + 1)
+	invalid = append(invalid, EstimateRewardArgs{Amount: args.Amount, Duration: args.Duration, Shares: &tooManyShares})
+	for i, args := range invalid {
+		if err := s.EstimateReward(nil, &args, &EstimateRewardReply{}); err == nil {
+			t.Fatalf("should have failed to estimate the reward of invalid stake %d", i)
+		}
+	}
+}
@@ -4,6 +4,8 @@
 package platformvm
 
 import (
+	"time"
+
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/ids"
@@ -47,6 +49,8 @@ func (sb *StandardBlock) initialize(vm *VM, bytes []byte) error {
 //
 // This function also sets onAcceptDB database if the verification passes.
 func (sb *StandardBlock) Verify() error {
+	defer sb.vm.VMMetrics.Verified(time.Now())
+
 	// StandardBlock is not a modifier on a proposal block, so its parent must
 	// be a decision.
 	parent, ok := sb.parentBlock().(decision)
@@ -83,6 +87,18 @@ func (sb *StandardBlock) Verify() error {
 	return nil
 }
 
+// Accept implements the snowman.Block interface
+func (sb *StandardBlock) Accept() {
+	sb.CommonDecisionBlock.Accept()
+	sb.vm.VMMetrics.Accepted(len(sb.Txs), 0, 0)
+}
+
+// Reject implements the snowman.Block interface
+func (sb *StandardBlock) Reject() {
+	sb.CommonDecisionBlock.Reject()
+	sb.vm.VMMetrics.Rejected(len(sb.Txs))
+}
+
 // newStandardBlock returns a new *StandardBlock where the block's parent, a
 // decision block, has ID [parentID].
 func (vm *VM) newStandardBlock(parentID ids.ID, txs []DecisionTx) (*StandardBlock, error) {
@@ -210,7 +210,7 @@ func (vm *VM) getSubnets(db database.Database) ([]*CreateSubnetTx, error) {
 	return subnets, nil
 }
 
-// get the subnet with the specified ID
+// get the subnet with the specified ID, with its current control keys
 func (vm *VM) getSubnet(db database.Database, ID ids.ID) (*CreateSubnetTx, error) {
 	subnets, err := vm.getSubnets(db)
 	if err != nil {
@@ -219,12 +219,37 @@ func (vm *VM) getSubnet(db database.Database, ID ids.ID) (*CreateSubnetTx, error
 
 	for _, subnet := range subnets {
 		if subnet.ID.Equals(ID) {
-			return subnet, nil
+			return subnet, vm.setSubnetControl(db, subnet)
 		}
 	}
 	return nil, fmt.Errorf("couldn't find subnet with ID %s", ID)
 }
 
+// put the control keys that replace those [subnetID] was created with
+func (vm *VM) putSubnetControl(db database.Database, subnetID ids.ID, control *SubnetControl) error {
+	return vm.State.Put(db, subnetControlTypeID, subnetID, control)
+}
+
+// set the control keys of [subnet] to its current ones. The control keys of a
+// subnet are stored apart from the tx that created it, whose bytes give the
+// subnet its ID. As such, [subnet] shouldn't be put back to [db] afterwards.
+func (vm *VM) setSubnetControl(db database.Database, subnet *CreateSubnetTx) error {
+	controlIntf, err := vm.State.Get(db, subnetControlTypeID, subnet.ID)
+	if err == database.ErrNotFound {
+		return nil // The subnet still has the control keys it was created with
+	} else if err != nil {
+		return err
+	}
+	control, ok := controlIntf.(*SubnetControl)
+	if !ok {
+		vm.Ctx.Log.Warn("expected to retrieve *SubnetControl from database but got different type")
+		return errDB
+	}
+	subnet.ControlKeys = control.ControlKeys
+	subnet.Threshold = control.Threshold
+	return nil
+}
+
 // register each type that we'll be storing in the database
 // so that [vm.State] knows how to unmarshal these types from bytes
 func (vm *VM) registerDBTypes() {
@@ -286,6 +311,28 @@ func (vm *VM) registerDBTypes() {
 	if err := vm.State.RegisterType(subnetsTypeID, unmarshalSubnetsFunc); err != nil {
 		vm.Ctx.Log.Warn(errRegisteringType.Error())
 	}
+
+	unmarshalRewardPayoutsFunc := func(bytes []byte) (interface{}, error) {
+		var payouts []*RewardPayout
+		if err := Codec.Unmarshal(bytes, &payouts); err != nil {
+			return nil, err
+		}
+		return payouts, nil
+	}
+	if err := vm.State.RegisterType(rewardPayoutsTypeID, unmarshalRewardPayoutsFunc); err != nil {
+		vm.Ctx.Log.Warn(errRegisteringType.Error())
+	}
+
+	unmarshalSubnetControlFunc := func(bytes []byte) (interface{}, error) {
+		control := &SubnetControl{}
+		if err := Codec.Unmarshal(bytes, control); err != nil {
+			return nil, err
+		}
+		return control, nil
+	}
+	if err := vm.State.RegisterType(subnetControlTypeID, unmarshalSubnetControlFunc); err != nil {
+		vm.Ctx.Log.Warn(errRegisteringType.Error())
+	}
 }
 
 // Unmarshal a Block from bytes and initialize it
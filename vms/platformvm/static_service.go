@@ -9,6 +9,7 @@ import (
 	"net/http"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/json"
 )
@@ -22,6 +23,7 @@ import (
 var (
 	errAccountHasNoValue    = errors.New("account has no value")
 	errValidatorAddsNoValue = errors.New("validator would have already unstaked")
+	errNotGenesisValidator  = errors.New("genesis validator isn't a default subnet validator")
 )
 
 // StaticService defines the static API methods exposed by the platform VM
@@ -182,12 +184,14 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 		tx := &CreateChainTx{
 			UnsignedCreateChainTx: UnsignedCreateChainTx{
 				NetworkID:   uint32(args.NetworkID),
+				SubnetID:    DefaultSubnetID,
 				Nonce:       0,
 				ChainName:   chain.Name,
 				VMID:        chain.VMID,
 				FxIDs:       chain.FxIDs,
 				GenesisData: chain.GenesisData.Bytes,
 			},
+			ControlSigs: [][crypto.SECP256K1RSigLen]byte{},
 		}
 		if err := tx.initialize(nil); err != nil {
 			return err
@@ -208,3 +212,72 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 	reply.Bytes.Bytes = bytes
 	return err
 }
+
+// ParseGenesisArgs are the arguments for calling ParseGenesis
+type ParseGenesisArgs struct {
+	Bytes formatting.CB58 `json:"bytes"`
+}
+
+// ParseGenesisReply is the genesis state of the Platform Chain, described
+// the way it's given to BuildGenesis
+type ParseGenesisReply struct {
+	NetworkID  json.Uint32                 `json:"networkID"`
+	Accounts   []APIAccount                `json:"accounts"`
+	Validators []APIDefaultSubnetValidator `json:"defaultSubnetValidators"`
+	Chains     []APIChain                  `json:"chains"`
+	Time       json.Uint64                 `json:"time"`
+}
+
+// ParseGenesis returns the genesis state of the Platform Chain that the
+// genesis bytes [args.Bytes] describe. It's the inverse of BuildGenesis.
+func (*StaticService) ParseGenesis(_ *http.Request, args *ParseGenesisArgs, reply *ParseGenesisReply) error {
+	genesis := Genesis{}
+	if err := Codec.Unmarshal(args.Bytes.Bytes, &genesis); err != nil {
+		return err
+	}
+
+	reply.Time = json.Uint64(genesis.Timestamp)
+
+	reply.Accounts = []APIAccount{}
+	for _, account := range genesis.Accounts {
+		reply.Accounts = append(reply.Accounts, APIAccount{
+			Address: account.Address,
+			Nonce:   json.Uint64(account.Nonce),
+			Balance: json.Uint64(account.Balance),
+		})
+	}
+
+	reply.Validators = []APIDefaultSubnetValidator{}
+	if genesis.Validators != nil {
+		for _, timedTx := range genesis.Validators.Txs {
+			tx, ok := timedTx.(*addDefaultSubnetValidatorTx)
+			if !ok {
+				return errNotGenesisValidator
+			}
+			weight := json.Uint64(tx.Wght)
+			reply.Validators = append(reply.Validators, APIDefaultSubnetValidator{
+				APIValidator: APIValidator{
+					StartTime: json.Uint64(tx.Start),
+					EndTime:   json.Uint64(tx.End),
+					Weight:    &weight,
+					ID:        tx.NodeID,
+				},
+				Destination:       tx.Destination,
+				DelegationFeeRate: json.Uint32(tx.Shares),
+			})
+			reply.NetworkID = json.Uint32(tx.NetworkID)
+		}
+	}
+
+	reply.Chains = []APIChain{}
+	for _, chain := range genesis.Chains {
+		reply.Chains = append(reply.Chains, APIChain{
+			GenesisData: formatting.CB58{Bytes: chain.GenesisData},
+			VMID:        chain.VMID,
+			FxIDs:       chain.FxIDs,
+			Name:        chain.ChainName,
+		})
+		reply.NetworkID = json.Uint32(chain.NetworkID)
+	}
+	return nil
+}
@@ -40,6 +40,10 @@ func TestBuildGenesis(t *testing.T) {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x13, 0x4d, 0x79, 0x20, 0x46,
 		0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x20,
 		0x45, 0x70, 0x69, 0x73, 0x6f, 0x64, 0x65, 0x53,
@@ -59,7 +63,8 @@ func TestBuildGenesis(t *testing.T) {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x05,
 	}
 
 	addr, _ := ids.ShortFromString("8CrVPQZ4VSqgL8zTdvL14G8HqAfrBr4z")
@@ -213,3 +218,62 @@ func TestBuildGenesisInvalidEndtime(t *testing.T) {
 		t.Fatalf("Should have errored due to an invalid end time")
 	}
 }
+
+func TestParseGenesis(t *testing.T) {
+	addr, _ := ids.ShortFromString("8CrVPQZ4VSqgL8zTdvL14G8HqAfrBr4z")
+	genesisData := formatting.CB58{}
+	genesisData.FromString("CGgRrQ3nws7RRMGyDV59cetJBAwmsmDyCSgku")
+	vmID, _ := ids.FromString("dkFD29iYU9e9jah2nrnksTWJUy2VVpg5Lnqd7nQqvCJgR26H4")
+
+	weight := json.Uint64(987654321)
+	args := BuildGenesisArgs{
+		NetworkID: 12345,
+		Accounts: []APIAccount{{
+			Address: addr,
+			Balance: 123456789,
+		}},
+		Validators: []APIDefaultSubnetValidator{{
+			APIValidator: APIValidator{
+				EndTime: 15,
+				Weight:  &weight,
+				ID:      addr,
+			},
+			Destination: addr,
+		}},
+		Chains: []APIChain{{
+			GenesisData: genesisData,
+			VMID:        vmID,
+			Name:        "My Favorite Episode",
+		}},
+		Time: 5,
+	}
+
+	ss := StaticService{}
+	buildReply := BuildGenesisReply{}
+	if err := ss.BuildGenesis(nil, &args, &buildReply); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := ParseGenesisReply{}
+	if err := ss.ParseGenesis(nil, &ParseGenesisArgs{Bytes: buildReply.Bytes}, &reply); err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case reply.NetworkID != 12345 || reply.Time != 5:
+		t.Fatalf("Wrong network ID %d or time %d", reply.NetworkID, reply.Time)
+	case len(reply.Accounts) != 1 || !reply.Accounts[0].Address.Equals(addr) || reply.Accounts[0].Balance != 123456789:
+		t.Fatalf("Wrong accounts: %+v", reply.Accounts)
+	case len(reply.Validators) != 1:
+		t.Fatalf("Wrong validators: %+v", reply.Validators)
+	case len(reply.Chains) != 1 || !reply.Chains[0].VMID.Equals(vmID) || reply.Chains[0].Name != "My Favorite Episode" ||
+		!bytes.Equal(reply.Chains[0].GenesisData.Bytes, genesisData.Bytes):
+		t.Fatalf("Wrong chains: %+v", reply.Chains)
+	}
+
+	validator := reply.Validators[0]
+	if validator.StartTime != 5 || validator.EndTime != 15 || validator.weight() != 987654321 ||
+		!validator.ID.Equals(addr) || !validator.Destination.Equals(addr) {
+		t.Fatalf("Wrong validator: %+v", validator)
+	}
+}
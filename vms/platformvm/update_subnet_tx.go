@@ -0,0 +1,243 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	errUpdateDefaultSubnet        = errors.New("the default subnet doesn't have control keys")
+	errControlKeysNotSortedUnique = errors.New("control keys must be sorted and unique")
+	errDuplicateControlSigs       = errors.New("tx has more than one control signature from the same key")
+	errUnknownControlKey          = errors.New("tx has control signature from key not in subnet's ControlKeys")
+)
+
+// SubnetControl is the set of keys that control a subnet. A transaction that
+// adds a validator to the subnet, creates a chain validated by the subnet or
+// changes the subnet's control keys must be signed by [Threshold] of
+// [ControlKeys].
+type SubnetControl struct {
+	ControlKeys []ids.ShortID `serialize:"true"`
+	Threshold   uint16        `serialize:"true"`
+}
+
+// Bytes returns the binary representation of [sc]
+func (sc *SubnetControl) Bytes() []byte {
+	bytes, _ := Codec.Marshal(sc)
+	return bytes
+}
+
+// verifyControlSigners returns nil if [signers], the keys that made a tx's
+// control signatures, are [threshold] distinct keys of [controlKeys]
+func verifyControlSigners(controlKeys []ids.ShortID, threshold uint16, signers []ids.ShortID) error {
+	signerSet := ids.ShortSet{}
+	signerSet.Add(signers...)
+	if signerSet.Len() != len(signers) {
+		return errDuplicateControlSigs
+	}
+	if signerSet.Len() != int(threshold) {
+		return fmt.Errorf("expected tx to have %d control sigs but has %d", threshold, signerSet.Len())
+	}
+	controlKeySet := ids.ShortSet{}
+	controlKeySet.Add(controlKeys...)
+	for _, signer := range signers {
+		if !controlKeySet.Contains(signer) {
+			return errUnknownControlKey
+		}
+	}
+	return nil
+}
+
+// UnsignedUpdateSubnetTx is an unsigned UpdateSubnetTx
+type UnsignedUpdateSubnetTx struct {
+	// ID of the network this tx was issued on
+	NetworkID uint32 `serialize:"true"`
+
+	// ID of the subnet whose control keys are replaced
+	SubnetID ids.ID `serialize:"true"`
+
+	// Next unused nonce of the account paying the tx fee
+	Nonce uint64 `serialize:"true"`
+
+	// The control keys and threshold of the subnet once this tx is accepted
+	ControlKeys []ids.ShortID `serialize:"true"`
+	Threshold   uint16        `serialize:"true"`
+}
+
+// UpdateSubnetTx replaces the control keys and threshold of a subnet. It must
+// be signed by a threshold of the subnet's current control keys, so keys can
+// be added to or removed from a subnet and its threshold can be changed only
+// by the keys that control it.
+type UpdateSubnetTx struct {
+	UnsignedUpdateSubnetTx `serialize:"true"`
+
+	// Signatures of a threshold of the subnet's current control keys
+	ControlSigs [][crypto.SECP256K1RSigLen]byte `serialize:"true"`
+
+	// Signature of the key whose account pays the tx fee
+	Sig [crypto.SECP256K1RSigLen]byte `serialize:"true"`
+
+	vm         *VM
+	id         ids.ID
+	controlIDs []ids.ShortID
+	key        crypto.PublicKey // public key of transaction signer
+	bytes      []byte
+}
+
+func (tx *UpdateSubnetTx) initialize(vm *VM) error {
+	tx.vm = vm
+	txBytes, err := Codec.Marshal(tx) // byte repr. of the signed tx
+	tx.bytes = txBytes
+	tx.id = ids.NewID(hashing.ComputeHash256Array(txBytes))
+	return err
+}
+
+// ID of this transaction
+func (tx *UpdateSubnetTx) ID() ids.ID { return tx.id }
+
+// Bytes returns the byte representation of an UpdateSubnetTx
+func (tx *UpdateSubnetTx) Bytes() []byte { return tx.bytes }
+
+// SyntacticVerify this transaction is well-formed
+// Also populates [tx.key] with the public key that signed this transaction
+func (tx *UpdateSubnetTx) SyntacticVerify() error {
+	switch {
+	case tx == nil:
+		return errNilTx
+	case tx.key != nil:
+		return nil // Only verify the transaction once
+	case tx.NetworkID != tx.vm.Ctx.NetworkID: // verify the transaction is on this network
+		return errWrongNetworkID
+	case tx.id.IsZero():
+		return errInvalidID
+	case tx.SubnetID.IsZero():
+		return errInvalidID
+	case tx.SubnetID.Equals(DefaultSubnetID):
+		return errUpdateDefaultSubnet
+	case !ids.IsSortedAndUniqueShortIDs(tx.ControlKeys):
+		return errControlKeysNotSortedUnique
+	case tx.Threshold > uint16(len(tx.ControlKeys)):
+		return errThresholdExceedsKeysLen
+	case tx.Threshold > maxThreshold:
+		return errThresholdTooHigh
+	case !crypto.IsSortedAndUniqueSECP2561RSigs(tx.ControlSigs):
+		return errSigsNotSorted
+	}
+
+	unsignedIntf := interface{}(&tx.UnsignedUpdateSubnetTx)
+	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // byte repr of unsigned tx
+	if err != nil {
+		return err
+	}
+
+	controlIDs := make([]ids.ShortID, len(tx.ControlSigs))
+	for i, sig := range tx.ControlSigs {
+		key, err := tx.vm.factory.RecoverHashPublicKey(tx.vm.signingDigest(unsignedBytes), sig[:])
+		if err != nil {
+			return err
+		}
+		controlIDs[i] = key.Address()
+	}
+
+	key, err := tx.vm.factory.RecoverHashPublicKey(tx.vm.signingDigest(unsignedBytes), tx.Sig[:])
+	if err != nil {
+		return err
+	}
+	tx.controlIDs = controlIDs
+	tx.key = key
+
+	return nil
+}
+
+// SemanticVerify this transaction is valid.
+func (tx *UpdateSubnetTx) SemanticVerify(db database.Database) (func(), error) {
+	if err := tx.SyntacticVerify(); err != nil {
+		return nil, err
+	}
+
+	subnet, err := tx.vm.getSubnet(db, tx.SubnetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyControlSigners(subnet.ControlKeys, subnet.Threshold, tx.controlIDs); err != nil {
+		return nil, err
+	}
+
+	control := &SubnetControl{
+		ControlKeys: tx.ControlKeys,
+		Threshold:   tx.Threshold,
+	}
+	if err := tx.vm.putSubnetControl(db, tx.SubnetID, control); err != nil {
+		return nil, err
+	}
+
+	// Deduct tx fee from payer's account
+	account, err := tx.vm.getAccount(db, tx.key.Address())
+	if err != nil {
+		return nil, err
+	}
+	account, err = account.Remove(0, tx.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.vm.putAccount(db, account); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (vm *VM) newUpdateSubnetTx(
+	nonce uint64,
+	subnetID ids.ID,
+	newControlKeys []ids.ShortID,
+	newThreshold uint16,
+	networkID uint32,
+	controlKeys []*crypto.PrivateKeySECP256K1R,
+	payerKey *crypto.PrivateKeySECP256K1R,
+) (*UpdateSubnetTx, error) {
+	ids.SortShortIDs(newControlKeys)
+	tx := &UpdateSubnetTx{
+		UnsignedUpdateSubnetTx: UnsignedUpdateSubnetTx{
+			NetworkID:   networkID,
+			SubnetID:    subnetID,
+			Nonce:       nonce,
+			ControlKeys: newControlKeys,
+			Threshold:   newThreshold,
+		},
+	}
+
+	unsignedIntf := interface{}(&tx.UnsignedUpdateSubnetTx)
+	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // Byte repr. of unsigned transaction
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign this tx with each control key
+	tx.ControlSigs = make([][crypto.SECP256K1RSigLen]byte, len(controlKeys))
+	for i, key := range controlKeys {
+		sig, err := key.SignHash(vm.signingDigest(unsignedBytes))
+		if err != nil {
+			return nil, err
+		}
+		copy(tx.ControlSigs[i][:], sig)
+	}
+	crypto.SortSECP2561RSigs(tx.ControlSigs)
+
+	// Sign this tx with the key of the tx fee payer
+	sig, err := payerKey.SignHash(vm.signingDigest(unsignedBytes))
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.Sig[:], sig)
+
+	return tx, tx.initialize(vm)
+}
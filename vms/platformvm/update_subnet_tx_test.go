@@ -0,0 +1,233 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+)
+
+func TestUpdateSubnetTxSyntacticVerify(t *testing.T) {
+	vm := defaultVM()
+	newControlKeys := []ids.ShortID{keys[3].PublicKey().Address(), keys[4].PublicKey().Address()}
+
+	// Case 1: tx is nil
+	var tx *UpdateSubnetTx
+	if err := tx.SyntacticVerify(); err != errNilTx {
+		t.Fatalf("should have errored with %s", errNilTx)
+	}
+
+	// Case 2: network ID is wrong
+	tx, err := vm.newUpdateSubnetTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		newControlKeys,
+		1,
+		testNetworkID+1,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0], testSubnet1ControlKeys[1]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SyntacticVerify(); err != errWrongNetworkID {
+		t.Fatalf("should have errored with %s", errWrongNetworkID)
+	}
+
+	// Case 3: the default subnet can't be updated
+	tx, err = vm.newUpdateSubnetTx(
+		defaultNonce+1,
+		DefaultSubnetID,
+		newControlKeys,
+		1,
+		testNetworkID,
+		nil,
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SyntacticVerify(); err != errUpdateDefaultSubnet {
+		t.Fatalf("should have errored with %s", errUpdateDefaultSubnet)
+	}
+
+	// Case 4: threshold is more than the number of control keys
+	tx, err = vm.newUpdateSubnetTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		newControlKeys,
+		3,
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0], testSubnet1ControlKeys[1]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SyntacticVerify(); err != errThresholdExceedsKeysLen {
+		t.Fatalf("should have errored with %s", errThresholdExceedsKeysLen)
+	}
+
+	// Case 5: control keys aren't unique
+	tx, err = vm.newUpdateSubnetTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		[]ids.ShortID{newControlKeys[0], newControlKeys[0]},
+		1,
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0], testSubnet1ControlKeys[1]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SyntacticVerify(); err != errControlKeysNotSortedUnique {
+		t.Fatalf("should have errored with %s", errControlKeysNotSortedUnique)
+	}
+
+	// Case 6: valid tx
+	tx, err = vm.newUpdateSubnetTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		newControlKeys,
+		1,
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0], testSubnet1ControlKeys[1]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SyntacticVerify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateSubnetTxSemanticVerify(t *testing.T) {
+	vm := defaultVM()
+	newControlKeys := []ids.ShortID{keys[3].PublicKey().Address(), keys[4].PublicKey().Address()}
+
+	// Case 1: too few control signatures
+	tx, err := vm.newUpdateSubnetTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		newControlKeys,
+		1,
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err == nil {
+		t.Fatal("should have failed because the tx has too few control signatures")
+	}
+
+	// Case 2: signed by a key that doesn't control the subnet
+	tx, err = vm.newUpdateSubnetTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		newControlKeys,
+		1,
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0], keys[3]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err == nil {
+		t.Fatal("should have failed because the tx is signed by a key that doesn't control the subnet")
+	}
+
+	// Case 3: valid tx
+	tx, err = vm.newUpdateSubnetTx(
+		defaultNonce+1,
+		testSubnet1.ID,
+		newControlKeys,
+		1,
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0], testSubnet1ControlKeys[1]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := versiondb.New(vm.DB)
+	if _, err := tx.SemanticVerify(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// The subnet keeps its ID but is now controlled by the new keys
+	subnet, err := vm.getSubnet(db, testSubnet1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subnet.Threshold != 1 {
+		t.Fatalf("threshold should be 1 but is %d", subnet.Threshold)
+	}
+	if len(subnet.ControlKeys) != len(newControlKeys) {
+		t.Fatalf("subnet should have %d control keys but has %d", len(newControlKeys), len(subnet.ControlKeys))
+	}
+	for i, key := range newControlKeys {
+		if !subnet.ControlKeys[i].Equals(key) {
+			t.Fatalf("subnet should be controlled by %s", key)
+		}
+	}
+
+	// The old control keys can no longer update the subnet
+	tx, err = vm.newUpdateSubnetTx(
+		defaultNonce+2,
+		testSubnet1.ID,
+		newControlKeys,
+		2,
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{testSubnet1ControlKeys[0]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(db)); err == nil {
+		t.Fatal("should have failed because the tx is signed by a removed control key")
+	}
+
+	// The new control keys can
+	tx, err = vm.newUpdateSubnetTx(
+		defaultNonce+2,
+		testSubnet1.ID,
+		newControlKeys,
+		2,
+		testNetworkID,
+		[]*crypto.PrivateKeySECP256K1R{keys[4]},
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(db)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyControlSigners(t *testing.T) {
+	controlKeys := []ids.ShortID{keys[0].PublicKey().Address(), keys[1].PublicKey().Address()}
+
+	if err := verifyControlSigners(controlKeys, 2, controlKeys); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyControlSigners(controlKeys, 2, []ids.ShortID{controlKeys[0], controlKeys[0]}); err == nil {
+		t.Fatal("should have failed because the same key signed twice")
+	}
+	if err := verifyControlSigners(controlKeys, 2, controlKeys[:1]); err == nil {
+		t.Fatal("should have failed because the tx has too few control signatures")
+	}
+	if err := verifyControlSigners(controlKeys, 1, []ids.ShortID{keys[2].PublicKey().Address()}); err == nil {
+		t.Fatal("should have failed because the tx is signed by a key that doesn't control the subnet")
+	}
+}
@@ -0,0 +1,125 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// session is a period of time that a peer was connected to this node
+type session struct{ start, end time.Time }
+
+// uptimeTracker tracks how long each peer has been connected to this node.
+// Only the time since the tracker was initialized is observed, so the uptime
+// of a peer is lost when this node restarts.
+type uptimeTracker struct {
+	lock  sync.Mutex
+	clock *timer.Clock
+
+	// startTime is when this node started observing its peers
+	startTime time.Time
+
+	// connected maps each connected peer to when it connected
+	connected map[[20]byte]time.Time
+
+	// sessions maps each peer to its connections to this node that are over,
+	// in the order they ended. Sessions that ended longer than the maximum
+	// staking duration ago are forgotten.
+	sessions map[[20]byte][]session
+}
+
+func (t *uptimeTracker) initialize(clock *timer.Clock) {
+	t.clock = clock
+	t.startTime = clock.Time()
+	t.connected = make(map[[20]byte]time.Time)
+	t.sessions = make(map[[20]byte][]session)
+}
+
+// connect marks [peerID] as connected from now on
+func (t *uptimeTracker) connect(peerID ids.ShortID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := peerID.Key()
+	if _, ok := t.connected[key]; !ok {
+		t.connected[key] = t.clock.Time()
+	}
+}
+
+// disconnect marks [peerID] as disconnected from now on
+func (t *uptimeTracker) disconnect(peerID ids.ShortID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := peerID.Key()
+	start, ok := t.connected[key]
+	if !ok {
+		return
+	}
+	delete(t.connected, key)
+
+	now := t.clock.Time()
+	sessions := append(t.sessions[key], session{start: start, end: now})
+
+	oldest := now.Add(-MaximumStakingDuration)
+	for len(sessions) > 0 && sessions[0].end.Before(oldest) {
+		sessions = sessions[1:]
+	}
+	t.sessions[key] = sessions
+}
+
+// uptime returns how long [peerID] has been connected to this node between
+// [start] and [end], and how much of that period this node has observed
+func (t *uptimeTracker) uptime(peerID ids.ShortID, start, end time.Time) (connected time.Duration, observed time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Time()
+	if start.Before(t.startTime) {
+		start = t.startTime
+	}
+	if end.After(now) {
+		end = now
+	}
+	if !end.After(start) {
+		return 0, 0
+	}
+
+	key := peerID.Key()
+	for _, s := range t.sessions[key] {
+		connected += overlap(s.start, s.end, start, end)
+	}
+	if connectedTime, ok := t.connected[key]; ok {
+		connected += overlap(connectedTime, now, start, end)
+	}
+	return connected, end.Sub(start)
+}
+
+// isConnected returns true if [peerID] is connected to this node
+func (t *uptimeTracker) isConnected(peerID ids.ShortID) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	_, ok := t.connected[peerID.Key()]
+	return ok
+}
+
+// overlap returns how long the periods [aStart, aEnd] and [bStart, bEnd]
+// overlap for
+func overlap(aStart, aEnd, bStart, bEnd time.Time) time.Duration {
+	if aStart.Before(bStart) {
+		aStart = bStart
+	}
+	if aEnd.After(bEnd) {
+		aEnd = bEnd
+	}
+	if !aEnd.After(aStart) {
+		return 0
+	}
+	return aEnd.Sub(aStart)
+}
@@ -0,0 +1,88 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+func TestUptimeTracker(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := timer.Clock{}
+	clock.Set(start)
+
+	tracker := uptimeTracker{}
+	tracker.initialize(&clock)
+
+	peerID := ids.NewShortID([20]byte{1})
+	tracker.connect(peerID)
+	clock.Set(start.Add(10 * time.Second))
+	tracker.disconnect(peerID)
+	clock.Set(start.Add(20 * time.Second))
+	tracker.connect(peerID)
+	// Connecting twice doesn't restart the session
+	clock.Set(start.Add(25 * time.Second))
+	tracker.connect(peerID)
+	clock.Set(start.Add(30 * time.Second))
+
+	connected, observed := tracker.uptime(peerID, start, start.Add(time.Hour))
+	if connected != 20*time.Second {
+		t.Fatalf("Should have been connected for 20s but was for %s", connected)
+	}
+	if observed != 30*time.Second {
+		t.Fatalf("Should have observed 30s but observed %s", observed)
+	}
+	if !tracker.isConnected(peerID) {
+		t.Fatalf("Should be connected")
+	}
+
+	// Only the time within the period counts
+	connected, observed = tracker.uptime(peerID, start.Add(5*time.Second), start.Add(25*time.Second))
+	if connected != 10*time.Second {
+		t.Fatalf("Should have been connected for 10s but was for %s", connected)
+	}
+	if observed != 20*time.Second {
+		t.Fatalf("Should have observed 20s but observed %s", observed)
+	}
+
+	// Time before the tracker started isn't observed
+	connected, observed = tracker.uptime(peerID, start.Add(-time.Hour), start.Add(10*time.Second))
+	if connected != 10*time.Second || observed != 10*time.Second {
+		t.Fatalf("Should have observed 10s of connection but observed %s of %s", connected, observed)
+	}
+
+	tracker.disconnect(peerID)
+	if tracker.isConnected(peerID) {
+		t.Fatalf("Shouldn't be connected")
+	}
+	// Disconnecting a peer that isn't connected does nothing
+	tracker.disconnect(ids.NewShortID([20]byte{2}))
+}
+
+func TestUptimeTrackerForgetsOldSessions(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := timer.Clock{}
+	clock.Set(start)
+
+	tracker := uptimeTracker{}
+	tracker.initialize(&clock)
+
+	peerID := ids.NewShortID([20]byte{1})
+	tracker.connect(peerID)
+	clock.Set(start.Add(time.Second))
+	tracker.disconnect(peerID)
+
+	clock.Set(start.Add(MaximumStakingDuration + time.Hour))
+	tracker.connect(peerID)
+	clock.Set(start.Add(MaximumStakingDuration + 2*time.Hour))
+	tracker.disconnect(peerID)
+
+	if sessions := tracker.sessions[peerID.Key()]; len(sessions) != 1 {
+		t.Fatalf("Should have forgotten the old session but remembers %d sessions", len(sessions))
+	}
+}
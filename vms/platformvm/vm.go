@@ -9,8 +9,6 @@ import (
 	"fmt"
 	"time"
 
-	stdmath "math"
-
 	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/versiondb"
@@ -20,12 +18,15 @@ import (
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/snow/validators"
 	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/crypto/ledger"
 	"github.com/ava-labs/gecko/utils/math"
 	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/utils/units"
 	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms"
 	"github.com/ava-labs/gecko/vms/components/codec"
 	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/components/signing"
 )
 
 const (
@@ -35,6 +36,8 @@ const (
 	chainsTypeID
 	blockTypeID
 	subnetsTypeID
+	rewardPayoutsTypeID
+	subnetControlTypeID
 
 	// Delta is the synchrony bound used for safe decision making
 	Delta = 10 * time.Second // TODO change to longer period (2 minutes?) before release
@@ -92,6 +95,7 @@ var (
 	errDBChains               = errors.New("couldn't retrieve chain list from database")
 	errDBPutChains            = errors.New("couldn't put chain list in database")
 	errDBPutBlock             = errors.New("couldn't put block in database")
+	errDBPutRewardPayout      = errors.New("couldn't put reward payout in database")
 	errRegisteringType        = errors.New("error registering type with database")
 	errMissingBlock           = errors.New("missing block")
 )
@@ -126,6 +130,15 @@ func init() {
 
 		Codec.RegisterType(&advanceTimeTx{}),
 		Codec.RegisterType(&rewardValidatorTx{}),
+
+		Codec.RegisterType(&UnsignedImportTx{}),
+		Codec.RegisterType(&ImportTx{}),
+
+		Codec.RegisterType(&UnsignedExportTx{}),
+		Codec.RegisterType(&ExportTx{}),
+
+		Codec.RegisterType(&UnsignedUpdateSubnetTx{}),
+		Codec.RegisterType(&UpdateSubnetTx{}),
 	)
 	if errs.Errored() {
 		panic(errs.Err)
@@ -141,12 +154,24 @@ type VM struct {
 	// The node's chain manager
 	ChainManager chains.Manager
 
+	// The node's VM manager. Used to verify the genesis data of new chains.
+	VMManager vms.Manager
+
+	// ID of the AVM chain that $AVA is imported from and exported to
+	avm ids.ID
+
+	// ID of the $AVA asset on the AVM chain
+	ava ids.ID
+
 	// Used to create and use keys.
 	factory crypto.FactorySECP256K1R
 
 	// Used to get time. Useful for faking time during tests.
 	clock timer.Clock
 
+	// Tracks how long validators have been connected to this node
+	uptimes uptimeTracker
+
 	// Key: block ID
 	// Value: the block
 	currentBlocks map[[32]byte]Block
@@ -175,6 +200,12 @@ func (vm *VM) Initialize(
 		return errUnsupportedFXs
 	}
 
+	// The factory starts tracking uptimes when the VM is created, so that no
+	// connection is missed before the VM is initialized
+	if vm.uptimes.clock == nil {
+		vm.uptimes.initialize(&vm.clock)
+	}
+
 	// Initialize the inner VM, which has a lot of boiler-plate logic
 	vm.SnowmanVM = &core.SnowmanVM{}
 	if err := vm.SnowmanVM.Initialize(ctx, db, vm.unmarshalBlockFunc, msgs); err != nil {
@@ -292,6 +323,7 @@ func (vm *VM) initBlockchains() error {
 	for _, chain := range existingChains { // Create each blockchain
 		chainParams := chains.ChainParameters{
 			ID:          chain.ID(),
+			SubnetID:    chain.SubnetID,
 			GenesisData: chain.GenesisData,
 			VMAlias:     chain.VMID.String(),
 		}
@@ -311,9 +343,16 @@ func (vm *VM) Shutdown() {
 	}
 }
 
+// Connected implements the validators.Connector interface
+func (vm *VM) Connected(validatorID ids.ShortID) { vm.uptimes.connect(validatorID) }
+
+// Disconnected implements the validators.Connector interface
+func (vm *VM) Disconnected(validatorID ids.ShortID) { vm.uptimes.disconnect(validatorID) }
+
 // BuildBlock builds a block to be added to consensus
 func (vm *VM) BuildBlock() (snowman.Block, error) {
 	vm.Ctx.Log.Debug("in BuildBlock")
+	defer vm.updateMempoolMetrics()
 	preferredID := vm.Preferred()
 
 	// If there are pending decision txs, build a block with a batch of them
@@ -487,7 +526,13 @@ func (vm *VM) SetPreference(blkID ids.ID) {
 func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
 	// Create a service with name "platform"
 	handler := vm.SnowmanVM.NewHandler("platform", &Service{vm: vm})
-	return map[string]*common.HTTPHandler{"": handler}
+	// Signing on a Ledger device waits for the user, so it doesn't hold the
+	// lock while it does
+	ledgerHandler := vm.SnowmanVM.NewHandler("platform", &LedgerService{vm: vm, open: ledger.Open}, common.NoLock)
+	return map[string]*common.HTTPHandler{
+		"":        handler,
+		"/ledger": ledgerHandler,
+	}
 }
 
 // CreateStaticHandlers implements the snowman.ChainVM interface
@@ -499,9 +544,16 @@ func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler {
 	}
 }
 
+// updateMempoolMetrics records the number of txs waiting to be put into a block
+func (vm *VM) updateMempoolMetrics() {
+	vm.VMMetrics.SetMempoolTxs(len(vm.unissuedDecisionTxs) + vm.unissuedEvents.Len())
+}
+
 // Check if there is a block ready to be added to consensus
 // If so, notify the consensus engine
 func (vm *VM) resetTimer() {
+	vm.updateMempoolMetrics()
+
 	// If there is a pending CreateChainTx, trigger building of a block
 	// with that transaction
 	if len(vm.unissuedDecisionTxs) > 0 {
@@ -657,11 +709,7 @@ func (vm *VM) getValidators(validatorEvents *EventHeap) []validators.Validator {
 			validator = &Validator{NodeID: vdrID}
 			vdrMap[vdrKey] = validator
 		}
-		weight, err := math.Add64(validator.Wght, vdr.Weight())
-		if err != nil {
-			weight = stdmath.MaxUint64
-		}
-		validator.Wght = weight
+		validator.Wght = math.SaturatingAdd64(validator.Wght, vdr.Weight())
 	}
 
 	vdrList := make([]validators.Validator, len(vdrMap))[:0]
@@ -686,3 +734,10 @@ func (vm *VM) updateValidators(subnetID ids.ID) error {
 	validatorSet.Set(validators)
 	return nil
 }
+
+// signingDigest returns the hash that keys sign to authorize the transaction
+// with unsigned bytes [unsignedBytes]. Unlike the unsigned bytes, the digest
+// commits to this chain's ID, so signatures can't be replayed on another chain.
+func (vm *VM) signingDigest(unsignedBytes []byte) []byte {
+	return signing.Digest(vm.Ctx.NetworkID, vm.Ctx.ChainID, unsignedBytes)
+}
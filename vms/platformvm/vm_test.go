@@ -192,7 +192,7 @@ func GenesisAccounts() []Account {
 
 // Returns the validators validating at genesis in tests
 func GenesisCurrentValidators() *EventHeap {
-	vm := &VM{}
+	vm := &VM{SnowmanVM: &core.SnowmanVM{Ctx: defaultContext()}}
 	validators := &EventHeap{SortByStartTime: false}
 	for _, key := range keys {
 		validator, _ := vm.newAddDefaultSubnetValidatorTx(
@@ -761,11 +761,13 @@ func TestCreateChain(t *testing.T) {
 
 	tx, err := vm.newCreateChainTx(
 		defaultNonce+1,
+		DefaultSubnetID,
 		nil,
 		timestampvm.ID,
 		nil,
 		"name ",
 		testNetworkID,
+		nil,
 		keys[0],
 	)
 	if err != nil {
@@ -0,0 +1,14 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+// Factory creates VMs that run in the plugin at [Path]. The plugin isn't
+// started until the VM is initialized, so creating a VM, as is done when the
+// factory is registered, is cheap.
+type Factory struct {
+	Path string
+}
+
+// New returns a new VM that runs in a new instance of the plugin
+func (f *Factory) New() interface{} { return &VMClient{path: f.Path} }
@@ -0,0 +1,65 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ghttp
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ava-labs/gecko/vms/rpcchainvm/ghttp/ghttpproto"
+)
+
+// Client is a http.Handler that talks over RPC. Requests and responses are
+// buffered, so streaming responses and connection upgrades, such as websockets,
+// aren't supported.
+type Client struct{ client ghttpproto.HTTPClient }
+
+// NewClient returns a HTTP handler connected to a remote HTTP handler
+func NewClient(client ghttpproto.HTTPClient) *Client {
+	return &Client{client: client}
+}
+
+// ServeHTTP forwards the request to the remote handler and writes its response
+func (c *Client) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url := r.RequestURI
+	if url == "" { // not set on client requests
+		url = r.URL.String()
+	}
+	resp, err := c.client.Handle(r.Context(), &ghttpproto.HTTPRequest{
+		Method:     r.Method,
+		Url:        url,
+		Host:       r.Host,
+		RemoteAddr: r.RemoteAddr,
+		Header:     toProtoHeader(r.Header),
+		Body:       body,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	header := w.Header()
+	for _, h := range resp.Header {
+		header[h.Key] = h.Values
+	}
+	w.WriteHeader(int(resp.Code))
+	w.Write(resp.Body)
+}
+
+func toProtoHeader(header http.Header) []*ghttpproto.Header {
+	protoHeader := make([]*ghttpproto.Header, 0, len(header))
+	for key, values := range header {
+		protoHeader = append(protoHeader, &ghttpproto.Header{
+			Key:    key,
+			Values: values,
+		})
+	}
+	return protoHeader
+}
@@ -0,0 +1,75 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ghttp
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/ava-labs/gecko/vms/rpcchainvm/ghttp/ghttpproto"
+)
+
+// Server is a http.Handler that is managed over RPC.
+type Server struct {
+	ghttpproto.UnimplementedHTTPServer
+
+	handler http.Handler
+}
+
+// NewServer returns a http.Handler instance managed remotely
+func NewServer(handler http.Handler) *Server {
+	return &Server{handler: handler}
+}
+
+// Handle serves the request with the handler and returns the buffered
+// response
+func (s *Server) Handle(ctx context.Context, req *ghttpproto.HTTPRequest) (*ghttpproto.HTTPResponse, error) {
+	request, err := http.NewRequest(req.Method, req.Url, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+	request.RequestURI = req.Url
+	request.Host = req.Host
+	request.RemoteAddr = req.RemoteAddr
+	for _, header := range req.Header {
+		request.Header[header.Key] = header.Values
+	}
+
+	writer := &responseWriter{
+		header: make(http.Header),
+		code:   http.StatusOK,
+	}
+	s.handler.ServeHTTP(writer, request)
+
+	return &ghttpproto.HTTPResponse{
+		Code:   int32(writer.code),
+		Header: toProtoHeader(writer.header),
+		Body:   writer.body.Bytes(),
+	}, nil
+}
+
+// responseWriter buffers the response written by a handler so that it can be
+// returned in one message
+type responseWriter struct {
+	header      http.Header
+	code        int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header { return w.header }
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.code = code
+	}
+}
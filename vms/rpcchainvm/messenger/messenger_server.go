@@ -0,0 +1,40 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package messenger
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/messenger/messengerproto"
+)
+
+var (
+	errFullQueue = errors.New("full message queue")
+)
+
+// Server is a messenger that is managed over RPC.
+type Server struct {
+	messengerproto.UnimplementedMessengerServer
+
+	messenger chan<- common.Message
+}
+
+// NewServer returns a messenger connected to a remote channel
+func NewServer(messenger chan<- common.Message) *Server {
+	return &Server{messenger: messenger}
+}
+
+// Notify passes the message to the consensus engine. Like a VM notifying the
+// engine directly, the message is dropped if the engine's queue is full.
+func (s *Server) Notify(_ context.Context, req *messengerproto.NotifyRequest) (*messengerproto.NotifyResponse, error) {
+	msg := common.Message(req.Message)
+	select {
+	case s.messenger <- msg:
+		return &messengerproto.NotifyResponse{}, nil
+	default:
+		return nil, errFullQueue
+	}
+}
@@ -0,0 +1,82 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package rpcchainvm runs a snowman.ChainVM in a separate process, a plugin,
+// that the node talks to over gRPC. Since the protocol is defined in the
+// vmproto, rpcdbproto, messengerproto and ghttpproto packages, a plugin may be
+// written in any language that has a gRPC implementation. A plugin that
+// crashes is restarted, and its VM initialized again, without stopping the
+// node.
+//
+// A plugin written in Go serves its VM by calling Serve from its main
+// function. The node runs a plugin by registering a Factory whose Path is the
+// plugin's executable.
+package rpcchainvm
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/hashicorp/go-plugin"
+
+	"github.com/ava-labs/gecko/snow/engine/snowman"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/vmproto"
+)
+
+// Handshake is a common handshake that is shared by plugin and host.
+var Handshake = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "VM_PLUGIN",
+	MagicCookieValue: "dynamic",
+}
+
+// PluginMap is the map of plugins we can dispense.
+var PluginMap = map[string]plugin.Plugin{
+	"vm": &Plugin{},
+}
+
+// Plugin is the implementation of plugin.Plugin so we can serve/consume this.
+// We also implement GRPCPlugin so that this plugin can be served over gRPC.
+type Plugin struct {
+	plugin.NetRPCUnsupportedPlugin
+	// Concrete implementation, written in Go. This is only used for plugins
+	// that are written in Go.
+	vm snowman.ChainVM
+}
+
+// New creates a new plugin from the provided VM
+func New(vm snowman.ChainVM) *Plugin { return &Plugin{vm: vm} }
+
+// GRPCServer registers a new GRPC server.
+func (p *Plugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	vmproto.RegisterVMServer(s, NewServer(p.vm, broker))
+	return nil
+}
+
+// GRPCClient returns a new GRPC client
+func (p *Plugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &connection{
+		client: vmproto.NewVMClient(c),
+		broker: broker,
+	}, nil
+}
+
+// connection is what the node dispenses from a running plugin
+type connection struct {
+	client vmproto.VMClient
+	broker *plugin.GRPCBroker
+}
+
+// Serve serves [vm] to the node. It's called from the main function of a plugin
+// and doesn't return.
+func Serve(vm snowman.ChainVM) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: map[string]plugin.Plugin{
+			"vm": New(vm),
+		},
+		// A non-nil value here enables gRPC serving for this plugin
+		GRPCServer: plugin.DefaultGRPCServer,
+	})
+}
@@ -0,0 +1,579 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/rpcdb"
+	"github.com/ava-labs/gecko/database/rpcdb/rpcdbproto"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/components/missing"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/ghttp"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/ghttp/ghttpproto"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/messenger"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/messenger/messengerproto"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/vmproto"
+)
+
+const (
+	// pluginCheckFrequency is how often the plugin is checked for having
+	// exited
+	pluginCheckFrequency = time.Second
+
+	// How long to wait before trying to restart the plugin again after
+	// restarting it failed. The wait doubles after each failure, up to
+	// maxRestartBackoff.
+	minRestartBackoff = time.Second
+	maxRestartBackoff = time.Minute
+)
+
+var (
+	errUnsupportedFXs = errors.New("unsupported feature extensions")
+	errWrongVM        = errors.New("wrong vm type")
+)
+
+// VMClient is an implementation of a VM that talks over RPC to a VM running in
+// a plugin.
+type VMClient struct {
+	path string
+
+	ctx     *snow.Context
+	process *plugin.Client
+	client  vmproto.VMClient
+	broker  *plugin.GRPCBroker
+
+	// What the VM was initialized with, so that it can be initialized again
+	// when the plugin is restarted
+	db           database.Database
+	genesisBytes []byte
+	toEngine     chan<- common.Message
+
+	// generation is incremented each time the plugin is restarted. The blocks
+	// the engine is still processing are verified again, and the preference
+	// set again, in the new instance of the plugin.
+	generation int
+	verified   []*BlockClient // verified blocks that aren't decided
+	preference ids.ID
+
+	// closed is closed when the VM is shutdown, after which the plugin is no
+	// longer restarted
+	closed chan struct{}
+
+	// servers and conns are closed when the VM is shutdown
+	lock     sync.Mutex
+	servers  []*grpc.Server
+	conns    []*grpc.ClientConn
+	handlers map[string]*handlerClient // Key: Prefix of the handler
+}
+
+// NewClient returns a VM connected to a remote VM
+func NewClient(client vmproto.VMClient, broker *plugin.GRPCBroker) *VMClient {
+	return &VMClient{
+		client: client,
+		broker: broker,
+	}
+}
+
+// Initialize starts the plugin, unless the VM is already connected to one, and
+// initializes the VM it serves. [db] and [toEngine] are served to the plugin
+// over RPC. A plugin started by the VM is restarted if it crashes.
+func (vm *VMClient) Initialize(
+	ctx *snow.Context,
+	db database.Database,
+	genesisBytes []byte,
+	toEngine chan<- common.Message,
+	fxs []*common.Fx,
+) error {
+	if len(fxs) != 0 {
+		return errUnsupportedFXs
+	}
+
+	vm.ctx = ctx
+	vm.db = db
+	vm.genesisBytes = genesisBytes
+	vm.toEngine = toEngine
+	vm.closed = make(chan struct{})
+
+	if vm.client == nil {
+		if err := vm.startPlugin(); err != nil {
+			return err
+		}
+	}
+	if err := vm.initializePlugin(); err != nil {
+		vm.stop()
+		return err
+	}
+
+	if vm.process != nil {
+		go ctx.Log.RecoverAndPanic(vm.monitor)
+	}
+	return nil
+}
+
+// initializePlugin initializes the VM the plugin serves
+func (vm *VMClient) initializePlugin() error {
+	dbServerID := vm.startServer(func(server *grpc.Server) {
+		rpcdbproto.RegisterDatabaseServer(server, rpcdb.NewServer(vm.db))
+	})
+	engineServerID := vm.startServer(func(server *grpc.Server) {
+		messengerproto.RegisterMessengerServer(server, messenger.NewServer(vm.toEngine))
+	})
+
+	_, err := vm.client.Initialize(context.Background(), &vmproto.InitializeRequest{
+		NetworkID:    vm.ctx.NetworkID,
+		ChainID:      vm.ctx.ChainID.Bytes(),
+		NodeID:       vm.ctx.NodeID.Bytes(),
+		GenesisBytes: vm.genesisBytes,
+		DbServer:     dbServerID,
+		EngineServer: engineServerID,
+	})
+	return err
+}
+
+// startPlugin runs the plugin at [vm.path] and connects to the VM it serves
+func (vm *VMClient) startPlugin() error {
+	vm.process = plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  Handshake,
+		Plugins:          PluginMap,
+		Cmd:              exec.Command(vm.path),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+		// Managed plugins are killed by KillPlugins
+		Managed: true,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:  "plugin",
+			Level: hclog.Warn,
+		}),
+	})
+
+	rpcClient, err := vm.process.Client()
+	if err != nil {
+		vm.process.Kill()
+		return err
+	}
+	raw, err := rpcClient.Dispense("vm")
+	if err != nil {
+		vm.process.Kill()
+		return err
+	}
+	conn, ok := raw.(*connection)
+	if !ok {
+		vm.process.Kill()
+		return errWrongVM
+	}
+	vm.client = conn.client
+	vm.broker = conn.broker
+	return nil
+}
+
+// monitor restarts the plugin whenever it exits, until the VM is shutdown
+func (vm *VMClient) monitor() {
+	ticker := time.NewTicker(pluginCheckFrequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-vm.closed:
+			return
+		case <-ticker.C:
+		}
+
+		if !vm.process.Exited() {
+			continue
+		}
+		select {
+		case <-vm.closed:
+			// The plugin was stopped by shutting down the VM
+			return
+		default:
+		}
+		vm.ctx.Log.Error("the plugin %s exited. Restarting it", vm.path)
+		vm.restart()
+	}
+}
+
+// restart the plugin, retrying with exponential backoff, until it's restarted
+// or the VM is shutdown. The chain's lock is held while the plugin is
+// restarted, but not between attempts.
+func (vm *VMClient) restart() {
+	backoff := minRestartBackoff
+	for {
+		vm.ctx.Lock.Lock()
+		select {
+		case <-vm.closed:
+			vm.ctx.Lock.Unlock()
+			return
+		default:
+		}
+		err := vm.reload()
+		vm.ctx.Lock.Unlock()
+
+		if err == nil {
+			vm.ctx.Log.Info("restarted the plugin %s", vm.path)
+			return
+		}
+		vm.ctx.Log.Error("restarting the plugin %s failed due to: %s. Retrying in %s", vm.path, err, backoff)
+
+		select {
+		case <-vm.closed:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxRestartBackoff {
+			backoff = maxRestartBackoff
+		}
+	}
+}
+
+// reload starts a new instance of the plugin and brings its VM to the state the
+// engine expects. Assumes the chain's lock is held.
+func (vm *VMClient) reload() error {
+	vm.stop()
+	if err := vm.startPlugin(); err != nil {
+		return err
+	}
+	if err := vm.initializePlugin(); err != nil {
+		vm.stop()
+		return err
+	}
+	vm.generation++
+
+	// The blocks were verified in order, so each block's parent is verified
+	// before it is
+	verified := vm.verified
+	vm.verified = nil
+	for _, blk := range verified {
+		blk.verified = false
+		if err := blk.Verify(); err != nil {
+			vm.ctx.Log.Warn("block %s failed verification after the plugin restarted: %s", blk.id, err)
+		}
+	}
+	if !vm.preference.IsZero() {
+		vm.SetPreference(vm.preference)
+	}
+
+	vm.lock.Lock()
+	handlers := vm.handlers
+	vm.lock.Unlock()
+	if len(handlers) == 0 {
+		return nil
+	}
+	resp, err := vm.client.CreateHandlers(context.Background(), &vmproto.CreateHandlersRequest{})
+	if err != nil {
+		return err
+	}
+	for _, handler := range resp.Handlers {
+		client, exists := handlers[handler.Prefix]
+		if !exists {
+			continue
+		}
+		conn, err := vm.broker.Dial(handler.Server)
+		if err != nil {
+			return err
+		}
+		vm.lock.Lock()
+		vm.conns = append(vm.conns, conn)
+		vm.lock.Unlock()
+		client.set(ghttpproto.NewHTTPClient(conn))
+	}
+	return nil
+}
+
+// KillPlugins kills the plugins that are still running, such as those of VMs
+// that failed to shut down
+func KillPlugins() { plugin.CleanupClients() }
+
+// startServer starts a server, on the plugin's broker, that [register] adds
+// services to. Returns the ID the plugin dials the server with.
+func (vm *VMClient) startServer(register func(*grpc.Server)) uint32 {
+	id := vm.broker.NextId()
+	go vm.broker.AcceptAndServe(id, func(opts []grpc.ServerOption) *grpc.Server {
+		server := grpc.NewServer(opts...)
+		vm.lock.Lock()
+		vm.servers = append(vm.servers, server)
+		vm.lock.Unlock()
+		register(server)
+		return server
+	})
+	return id
+}
+
+// Shutdown shuts down the remote VM and stops the plugin
+func (vm *VMClient) Shutdown() {
+	if vm.client == nil {
+		return
+	}
+	if vm.closed != nil {
+		close(vm.closed)
+	}
+	if _, err := vm.client.Shutdown(context.Background(), &vmproto.ShutdownRequest{}); err != nil {
+		vm.ctx.Log.Error("shutting down the plugin failed due to: %s", err)
+	}
+	vm.stop()
+}
+
+// stop closes the connections to the plugin and kills it
+func (vm *VMClient) stop() {
+	vm.lock.Lock()
+	defer vm.lock.Unlock()
+
+	for _, server := range vm.servers {
+		server.Stop()
+	}
+	for _, conn := range vm.conns {
+		conn.Close()
+	}
+	vm.servers = nil
+	vm.conns = nil
+
+	if vm.process != nil {
+		vm.process.Kill()
+	}
+}
+
+// Metrics implements the snowman.ChainVM interface. The remote VM's metrics
+// live in the plugin's process and aren't served over RPC, so nothing is
+// registered.
+func (vm *VMClient) Metrics(prometheus.Registerer) error { return nil }
+
+// CreateHandlers returns handlers that forward requests to the remote VM's
+// handlers. The remote VM takes the locks its handlers require, so no lock is
+// taken by the node.
+func (vm *VMClient) CreateHandlers() map[string]*common.HTTPHandler {
+	resp, err := vm.client.CreateHandlers(context.Background(), &vmproto.CreateHandlersRequest{})
+	if err != nil {
+		vm.ctx.Log.Error("failed to get handlers due to: %s", err)
+		return nil
+	}
+
+	handlers := make(map[string]*common.HTTPHandler, len(resp.Handlers))
+	for _, handler := range resp.Handlers {
+		conn, err := vm.broker.Dial(handler.Server)
+		if err != nil {
+			vm.ctx.Log.Error("failed to dial the handler for %q due to: %s", handler.Prefix, err)
+			continue
+		}
+		client := &handlerClient{client: ghttpproto.NewHTTPClient(conn)}
+		vm.lock.Lock()
+		vm.conns = append(vm.conns, conn)
+		if vm.handlers == nil {
+			vm.handlers = make(map[string]*handlerClient)
+		}
+		vm.handlers[handler.Prefix] = client
+		vm.lock.Unlock()
+
+		handlers[handler.Prefix] = &common.HTTPHandler{
+			LockOptions: common.NoLock,
+			Handler:     ghttp.NewClient(client),
+		}
+	}
+	return handlers
+}
+
+// handlerClient forwards requests to a handler of the plugin. It's connected to
+// the handler in the new instance of the plugin when the plugin is restarted.
+type handlerClient struct {
+	lock   sync.RWMutex
+	client ghttpproto.HTTPClient
+}
+
+func (c *handlerClient) set(client ghttpproto.HTTPClient) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.client = client
+}
+
+// Handle implements the ghttpproto.HTTPClient interface
+func (c *handlerClient) Handle(ctx context.Context, req *ghttpproto.HTTPRequest, opts ...grpc.CallOption) (*ghttpproto.HTTPResponse, error) {
+	c.lock.RLock()
+	client := c.client
+	c.lock.RUnlock()
+
+	return client.Handle(ctx, req, opts...)
+}
+
+// BuildBlock ...
+func (vm *VMClient) BuildBlock() (snowman.Block, error) {
+	resp, err := vm.client.BuildBlock(context.Background(), &vmproto.BuildBlockRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return vm.newBlock(resp.Id, resp.ParentID, choices.Processing, resp.Bytes)
+}
+
+// ParseBlock ...
+func (vm *VMClient) ParseBlock(bytes []byte) (snowman.Block, error) {
+	resp, err := vm.client.ParseBlock(context.Background(), &vmproto.ParseBlockRequest{
+		Bytes: bytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vm.newBlock(resp.Id, resp.ParentID, choices.Status(resp.Status), bytes)
+}
+
+// GetBlock ...
+func (vm *VMClient) GetBlock(id ids.ID) (snowman.Block, error) {
+	resp, err := vm.client.GetBlock(context.Background(), &vmproto.GetBlockRequest{
+		Id: id.Bytes(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vm.newBlock(id.Bytes(), resp.ParentID, choices.Status(resp.Status), resp.Bytes)
+}
+
+// SetPreference ...
+func (vm *VMClient) SetPreference(id ids.ID) {
+	vm.preference = id
+	_, err := vm.client.SetPreference(context.Background(), &vmproto.SetPreferenceRequest{
+		Id: id.Bytes(),
+	})
+	if err != nil {
+		vm.ctx.Log.Error("failed to set the preference to %s due to: %s", id, err)
+	}
+}
+
+// LastAccepted ...
+func (vm *VMClient) LastAccepted() ids.ID {
+	resp, err := vm.client.LastAccepted(context.Background(), &vmproto.LastAcceptedRequest{})
+	if err != nil {
+		vm.ctx.Log.Error("failed to get the last accepted block due to: %s", err)
+		return ids.ID{}
+	}
+	id, err := ids.ToID(resp.Id)
+	if err != nil {
+		vm.ctx.Log.Error("the last accepted block's ID is malformed: %s", err)
+		return ids.ID{}
+	}
+	return id
+}
+
+// newBlock returns a block that is managed by the remote VM
+func (vm *VMClient) newBlock(idBytes, parentIDBytes []byte, status choices.Status, bytes []byte) (*BlockClient, error) {
+	id, err := ids.ToID(idBytes)
+	if err != nil {
+		return nil, err
+	}
+	parentID, err := ids.ToID(parentIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := status.Valid(); err != nil {
+		return nil, err
+	}
+	return &BlockClient{
+		vm:         vm,
+		generation: vm.generation,
+		id:         id,
+		parentID:   parentID,
+		status:     status,
+		bytes:      bytes,
+	}, nil
+}
+
+// remove [blk] from the verified blocks
+func (vm *VMClient) remove(blk *BlockClient) {
+	for i, verified := range vm.verified {
+		if verified == blk {
+			vm.verified = append(vm.verified[:i], vm.verified[i+1:]...)
+			return
+		}
+	}
+}
+
+// BlockClient is an implementation of a block that talks over RPC to the VM
+// that manages it.
+type BlockClient struct {
+	vm *VMClient
+	// generation of the plugin the block was parsed by
+	generation int
+	verified   bool
+
+	id       ids.ID
+	parentID ids.ID
+	status   choices.Status
+	bytes    []byte
+}
+
+// ID ...
+func (b *BlockClient) ID() ids.ID { return b.id }
+
+// Accept ...
+func (b *BlockClient) Accept() {
+	b.status = choices.Accepted
+	b.vm.remove(b)
+	_, err := b.vm.client.BlockAccept(context.Background(), &vmproto.BlockAcceptRequest{
+		Id: b.id.Bytes(),
+	})
+	if err != nil {
+		b.vm.ctx.Log.Error("failed to accept block %s due to: %s", b.id, err)
+	}
+}
+
+// Reject ...
+func (b *BlockClient) Reject() {
+	b.status = choices.Rejected
+	b.vm.remove(b)
+	_, err := b.vm.client.BlockReject(context.Background(), &vmproto.BlockRejectRequest{
+		Id: b.id.Bytes(),
+	})
+	if err != nil {
+		b.vm.ctx.Log.Error("failed to reject block %s due to: %s", b.id, err)
+	}
+}
+
+// Status ...
+func (b *BlockClient) Status() choices.Status { return b.status }
+
+// Parent ...
+func (b *BlockClient) Parent() snowman.Block {
+	if parent, err := b.vm.GetBlock(b.parentID); err == nil {
+		return parent
+	}
+	return &missing.Block{BlkID: b.parentID}
+}
+
+// Verify ...
+func (b *BlockClient) Verify() error {
+	// The block is parsed again if the plugin was restarted since it was
+	// parsed
+	if b.generation != b.vm.generation {
+		if _, err := b.vm.client.ParseBlock(context.Background(), &vmproto.ParseBlockRequest{
+			Bytes: b.bytes,
+		}); err != nil {
+			return err
+		}
+		b.generation = b.vm.generation
+	}
+
+	_, err := b.vm.client.BlockVerify(context.Background(), &vmproto.BlockVerifyRequest{
+		Id: b.id.Bytes(),
+	})
+	if err == nil && !b.verified {
+		b.verified = true
+		b.vm.verified = append(b.vm.verified, b)
+	}
+	return err
+}
+
+// Bytes ...
+func (b *BlockClient) Bytes() []byte { return b.bytes }
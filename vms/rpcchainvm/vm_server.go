@@ -0,0 +1,355 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/hashicorp/go-plugin"
+
+	"github.com/ava-labs/gecko/database/rpcdb"
+	"github.com/ava-labs/gecko/database/rpcdb/rpcdbproto"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	consensus "github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/engine/snowman"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/ghttp"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/ghttp/ghttpproto"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/messenger"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/messenger/messengerproto"
+	"github.com/ava-labs/gecko/vms/rpcchainvm/vmproto"
+)
+
+const (
+	// toEngineSize is the size of the buffer of messages the VM sends to the
+	// consensus engine
+	toEngineSize = 1024
+)
+
+var (
+	errUnknownLockOption = errors.New("invalid lock options")
+)
+
+// VMServer is a VM that is managed over RPC. Calls to the VM hold its context's
+// lock, just as the consensus engine's calls do.
+type VMServer struct {
+	vmproto.UnimplementedVMServer
+
+	vm     snowman.ChainVM
+	broker *plugin.GRPCBroker
+
+	ctx      *snow.Context
+	toEngine chan common.Message
+
+	// Key: block ID
+	// Value: a block that was built or parsed and hasn't been decided. The VM
+	// may not be able to get these blocks until they're verified.
+	blks map[[32]byte]consensus.Block
+
+	// servers and conns are closed when the VM is shutdown
+	lock    sync.Mutex
+	servers []*grpc.Server
+	conns   []*grpc.ClientConn
+}
+
+// NewServer returns a VM instance that is managed remotely
+func NewServer(vm snowman.ChainVM, broker *plugin.GRPCBroker) *VMServer {
+	return &VMServer{
+		vm:     vm,
+		broker: broker,
+		blks:   make(map[[32]byte]consensus.Block),
+	}
+}
+
+// Initialize connects to the database and messenger served by the node and
+// initializes the VM
+func (vm *VMServer) Initialize(_ context.Context, req *vmproto.InitializeRequest) (*vmproto.InitializeResponse, error) {
+	chainID, err := ids.ToID(req.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	nodeID, err := ids.ToShortID(req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	dbConn, err := vm.dial(req.DbServer)
+	if err != nil {
+		return nil, err
+	}
+	engineConn, err := vm.dial(req.EngineServer)
+	if err != nil {
+		return nil, err
+	}
+
+	db := rpcdb.NewClient(rpcdbproto.NewDatabaseClient(dbConn))
+	engine := messenger.NewClient(messengerproto.NewMessengerClient(engineConn))
+
+	vm.toEngine = make(chan common.Message, toEngineSize)
+	go func(toEngine <-chan common.Message) {
+		for msg := range toEngine {
+			// Dropping a message is no worse than the engine's queue being
+			// full, so failures aren't retried
+			engine.Notify(msg)
+		}
+	}(vm.toEngine)
+
+	vm.ctx = &snow.Context{
+		NetworkID: req.NetworkID,
+		ChainID:   chainID,
+		NodeID:    nodeID,
+		Log:       logging.NoLog{},
+		BCLookup:  &ids.Aliaser{},
+	}
+
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	if err := vm.vm.Initialize(vm.ctx, db, req.GenesisBytes, vm.toEngine, nil); err != nil {
+		return nil, err
+	}
+	return &vmproto.InitializeResponse{}, nil
+}
+
+// dial connects to the server [id] on the node's broker. The connection is
+// closed when the VM is shutdown.
+func (vm *VMServer) dial(id uint32) (*grpc.ClientConn, error) {
+	conn, err := vm.broker.Dial(id)
+	if err != nil {
+		return nil, err
+	}
+	vm.lock.Lock()
+	vm.conns = append(vm.conns, conn)
+	vm.lock.Unlock()
+	return conn, nil
+}
+
+// Shutdown shuts down the VM and closes the connections to the node
+func (vm *VMServer) Shutdown(context.Context, *vmproto.ShutdownRequest) (*vmproto.ShutdownResponse, error) {
+	if vm.ctx != nil {
+		vm.ctx.Lock.Lock()
+		vm.vm.Shutdown()
+		close(vm.toEngine)
+		vm.ctx.Lock.Unlock()
+	}
+
+	vm.lock.Lock()
+	defer vm.lock.Unlock()
+
+	for _, server := range vm.servers {
+		server.Stop()
+	}
+	for _, conn := range vm.conns {
+		conn.Close()
+	}
+	vm.servers = nil
+	vm.conns = nil
+	return &vmproto.ShutdownResponse{}, nil
+}
+
+// CreateHandlers serves each of the VM's handlers on the broker and returns the
+// IDs of their servers
+func (vm *VMServer) CreateHandlers(context.Context, *vmproto.CreateHandlersRequest) (*vmproto.CreateHandlersResponse, error) {
+	vm.ctx.Lock.Lock()
+	handlers := vm.vm.CreateHandlers()
+	vm.ctx.Lock.Unlock()
+
+	resp := &vmproto.CreateHandlersResponse{}
+	for prefix, h := range handlers {
+		handler, err := vm.lockHandler(h)
+		if err != nil {
+			return nil, err
+		}
+
+		serverID := vm.broker.NextId()
+		go vm.broker.AcceptAndServe(serverID, func(opts []grpc.ServerOption) *grpc.Server {
+			server := grpc.NewServer(opts...)
+			vm.lock.Lock()
+			vm.servers = append(vm.servers, server)
+			vm.lock.Unlock()
+			ghttpproto.RegisterHTTPServer(server, ghttp.NewServer(handler))
+			return server
+		})
+
+		resp.Handlers = append(resp.Handlers, &vmproto.Handler{
+			Prefix:      prefix,
+			LockOptions: uint32(h.LockOptions),
+			Server:      serverID,
+		})
+	}
+	return resp, nil
+}
+
+// lockHandler returns a handler that holds the lock [handler] requires while
+// it serves a request
+func (vm *VMServer) lockHandler(handler *common.HTTPHandler) (http.Handler, error) {
+	switch handler.LockOptions {
+	case common.WriteLock:
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			vm.ctx.Lock.Lock()
+			defer vm.ctx.Lock.Unlock()
+			handler.Handler.ServeHTTP(w, r)
+		}), nil
+	case common.ReadLock:
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			vm.ctx.Lock.RLock()
+			defer vm.ctx.Lock.RUnlock()
+			handler.Handler.ServeHTTP(w, r)
+		}), nil
+	case common.NoLock:
+		return handler.Handler, nil
+	default:
+		return nil, errUnknownLockOption
+	}
+}
+
+// BuildBlock ...
+func (vm *VMServer) BuildBlock(context.Context, *vmproto.BuildBlockRequest) (*vmproto.BuildBlockResponse, error) {
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	blk, err := vm.vm.BuildBlock()
+	if err != nil {
+		return nil, err
+	}
+	vm.blks[blk.ID().Key()] = blk
+	return &vmproto.BuildBlockResponse{
+		Id:       blk.ID().Bytes(),
+		ParentID: blk.Parent().ID().Bytes(),
+		Bytes:    blk.Bytes(),
+	}, nil
+}
+
+// ParseBlock ...
+func (vm *VMServer) ParseBlock(_ context.Context, req *vmproto.ParseBlockRequest) (*vmproto.ParseBlockResponse, error) {
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	blk, err := vm.vm.ParseBlock(req.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the block that was handed out first, as it may have been verified
+	if _, exists := vm.blks[blk.ID().Key()]; !exists && !blk.Status().Decided() {
+		vm.blks[blk.ID().Key()] = blk
+	}
+	return &vmproto.ParseBlockResponse{
+		Id:       blk.ID().Bytes(),
+		ParentID: blk.Parent().ID().Bytes(),
+		Status:   uint32(blk.Status()),
+	}, nil
+}
+
+// GetBlock ...
+func (vm *VMServer) GetBlock(_ context.Context, req *vmproto.GetBlockRequest) (*vmproto.GetBlockResponse, error) {
+	id, err := ids.ToID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	blk, err := vm.getBlock(id)
+	if err != nil {
+		return nil, err
+	}
+	return &vmproto.GetBlockResponse{
+		ParentID: blk.Parent().ID().Bytes(),
+		Bytes:    blk.Bytes(),
+		Status:   uint32(blk.Status()),
+	}, nil
+}
+
+// SetPreference ...
+func (vm *VMServer) SetPreference(_ context.Context, req *vmproto.SetPreferenceRequest) (*vmproto.SetPreferenceResponse, error) {
+	id, err := ids.ToID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	vm.vm.SetPreference(id)
+	return &vmproto.SetPreferenceResponse{}, nil
+}
+
+// LastAccepted ...
+func (vm *VMServer) LastAccepted(context.Context, *vmproto.LastAcceptedRequest) (*vmproto.LastAcceptedResponse, error) {
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	return &vmproto.LastAcceptedResponse{Id: vm.vm.LastAccepted().Bytes()}, nil
+}
+
+// BlockVerify ...
+func (vm *VMServer) BlockVerify(_ context.Context, req *vmproto.BlockVerifyRequest) (*vmproto.BlockVerifyResponse, error) {
+	id, err := ids.ToID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	blk, err := vm.getBlock(id)
+	if err != nil {
+		return nil, err
+	}
+	return &vmproto.BlockVerifyResponse{}, blk.Verify()
+}
+
+// BlockAccept ...
+func (vm *VMServer) BlockAccept(_ context.Context, req *vmproto.BlockAcceptRequest) (*vmproto.BlockAcceptResponse, error) {
+	id, err := ids.ToID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	blk, err := vm.getBlock(id)
+	if err != nil {
+		return nil, err
+	}
+	delete(vm.blks, id.Key())
+	blk.Accept()
+	return &vmproto.BlockAcceptResponse{}, nil
+}
+
+// BlockReject ...
+func (vm *VMServer) BlockReject(_ context.Context, req *vmproto.BlockRejectRequest) (*vmproto.BlockRejectResponse, error) {
+	id, err := ids.ToID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	blk, err := vm.getBlock(id)
+	if err != nil {
+		return nil, err
+	}
+	delete(vm.blks, id.Key())
+	blk.Reject()
+	return &vmproto.BlockRejectResponse{}, nil
+}
+
+// getBlock returns the block [id]. Assumes the context's lock is held.
+func (vm *VMServer) getBlock(id ids.ID) (consensus.Block, error) {
+	if blk, exists := vm.blks[id.Key()]; exists {
+		return blk, nil
+	}
+	return vm.vm.GetBlock(id)
+}
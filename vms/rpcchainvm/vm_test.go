@@ -0,0 +1,211 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-plugin"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/timestampvm"
+)
+
+// TestMain serves a timestampvm when the test binary is run as a plugin, so
+// that the node's side of a plugin can be tested against a real process
+func TestMain(m *testing.M) {
+	if os.Getenv(Handshake.MagicCookieKey) == Handshake.MagicCookieValue {
+		Serve(&timestampvm.VM{})
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// proposeBlock proposes a block through [handler], which is served by the
+// plugin
+func proposeBlock(t *testing.T, handler *common.HTTPHandler) {
+	body := `{"jsonrpc":"2.0","method":"timestamp.proposeBlock","params":{"data":"SkB92YpWm4Q2ijQHH34cqbKkCZWszsiQgHVjtNeFF2HdvDQU"},"id":1}`
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	handler.Handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("proposing a block failed with %d: %s", resp.Code, resp.Body)
+	}
+}
+
+// setupVM returns a VM that talks over RPC to a timestampvm served in this
+// process
+func setupVM(t *testing.T) (*VMClient, *plugin.GRPCClient) {
+	client, _ := plugin.TestPluginGRPCConn(t, false, map[string]plugin.Plugin{
+		"vm": New(&timestampvm.VM{}),
+	})
+	raw, err := client.Dispense("vm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, ok := raw.(*connection)
+	if !ok {
+		t.Fatalf("wrong type dispensed: %T", raw)
+	}
+	return NewClient(conn.client, conn.broker), client
+}
+
+func TestInitialize(t *testing.T) {
+	vm, client := setupVM(t)
+	defer client.Close()
+
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = ids.NewID([32]byte{1, 2, 3})
+	if err := vm.Initialize(ctx, memdb.New(), make([]byte, 32), make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	genesisID := vm.LastAccepted()
+	if genesisID.IsZero() {
+		t.Fatal("lastAccepted should not be empty")
+	}
+	genesis, err := vm.GetBlock(genesisID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := genesis.Status(); status != choices.Accepted {
+		t.Fatalf("genesis should be %s but is %s", choices.Accepted, status)
+	}
+	if !genesis.Parent().ID().Equals(ids.Empty) {
+		t.Fatalf("genesis's parent should be %s but is %s", ids.Empty, genesis.Parent().ID())
+	}
+}
+
+func TestBuildAcceptBlock(t *testing.T) {
+	vm, client := setupVM(t)
+	defer client.Close()
+
+	msgChan := make(chan common.Message, 1)
+	if err := vm.Initialize(snow.DefaultContextTest(), memdb.New(), make([]byte, 32), msgChan, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	genesisID := vm.LastAccepted()
+	vm.SetPreference(genesisID)
+
+	// Propose a block through the API, which is served by the plugin
+	handler, exists := vm.CreateHandlers()[""]
+	if !exists {
+		t.Fatal("expected the VM's API handler")
+	}
+	proposeBlock(t, handler)
+
+	// The plugin notifies the engine through the node
+	if msg := <-msgChan; msg != common.PendingTxs {
+		t.Fatalf("expected %s but got %s", common.PendingTxs, msg)
+	}
+
+	blk, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !blk.Parent().ID().Equals(genesisID) {
+		t.Fatalf("block's parent should be %s but is %s", genesisID, blk.Parent().ID())
+	}
+	if err := blk.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := vm.ParseBlock(blk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.ID().Equals(blk.ID()) {
+		t.Fatalf("parsed block should be %s but is %s", blk.ID(), parsed.ID())
+	}
+
+	blk.Accept()
+	if lastAccepted := vm.LastAccepted(); !lastAccepted.Equals(blk.ID()) {
+		t.Fatalf("last accepted should be %s but is %s", blk.ID(), lastAccepted)
+	}
+	if blk, err := vm.GetBlock(blk.ID()); err != nil {
+		t.Fatal(err)
+	} else if status := blk.Status(); status != choices.Accepted {
+		t.Fatalf("block should be %s but is %s", choices.Accepted, status)
+	}
+}
+
+func TestPluginRestarts(t *testing.T) {
+	factory := Factory{Path: os.Args[0]}
+	vm := factory.New().(*VMClient)
+
+	ctx := snow.DefaultContextTest()
+	msgChan := make(chan common.Message, 1)
+	if err := vm.Initialize(ctx, memdb.New(), make([]byte, 32), msgChan, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	handler, exists := vm.CreateHandlers()[""]
+	if !exists {
+		t.Fatal("expected the VM's API handler")
+	}
+	proposeBlock(t, handler)
+	<-msgChan
+
+	ctx.Lock.Lock()
+	genesisID := vm.LastAccepted()
+	vm.SetPreference(genesisID)
+	blk, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := blk.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	vm.SetPreference(blk.ID())
+	process, err := os.FindProcess(vm.process.ReattachConfig().Pid)
+	ctx.Lock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Crash the plugin while the block is processing
+	if err := process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		ctx.Lock.Lock()
+		generation := vm.generation
+		ctx.Lock.Unlock()
+		if generation == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the plugin should have been restarted")
+		}
+	}
+
+	// The restarted plugin knows about the block that was processing
+	ctx.Lock.Lock()
+	blk.Accept()
+	lastAccepted := vm.LastAccepted()
+	ctx.Lock.Unlock()
+	if !lastAccepted.Equals(blk.ID()) {
+		t.Fatalf("last accepted should be %s but is %s", blk.ID(), lastAccepted)
+	}
+
+	// The API handler is served by the restarted plugin
+	proposeBlock(t, handler)
+	if msg := <-msgChan; msg != common.PendingTxs {
+		t.Fatalf("expected %s but got %s", common.PendingTxs, msg)
+	}
+}
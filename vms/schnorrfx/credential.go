@@ -0,0 +1,62 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package schnorrfx
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/crypto"
+)
+
+var (
+	errNilCredential          = errors.New("nil credential")
+	errTooManySigs            = errors.New("credential holds more than one signature")
+	errNoPubKeys              = errors.New("aggregated signature has no signers")
+	errUnexpectedPubKeys      = errors.New("credential without a signature shouldn't name signers")
+	errPubKeysNotSortedUnique = errors.New("signers not sorted and unique")
+)
+
+// Credential authorizes spending a TransferOutput. All of a transaction's
+// inputs of this Fx share one signature: one of their credentials holds the
+// public keys of all of their signers and the signature those keys aggregate
+// to, and the others are empty.
+type Credential struct {
+	PubKeys [][crypto.SECP256K1RPKLen]byte `serialize:"true"`
+	Sigs    [][crypto.SchnorrSigLen]byte   `serialize:"true"`
+}
+
+// Verify ...
+func (cr *Credential) Verify() error {
+	switch {
+	case cr == nil:
+		return errNilCredential
+	case len(cr.Sigs) > 1:
+		return errTooManySigs
+	case len(cr.Sigs) == 1 && len(cr.PubKeys) == 0:
+		return errNoPubKeys
+	case len(cr.Sigs) == 0 && len(cr.PubKeys) != 0:
+		return errUnexpectedPubKeys
+	case !IsSortedAndUniquePubKeys(cr.PubKeys):
+		return errPubKeysNotSortedUnique
+	default:
+		return nil
+	}
+}
+
+type innerSortPubKeys [][crypto.SECP256K1RPKLen]byte
+
+func (lst innerSortPubKeys) Less(i, j int) bool { return bytes.Compare(lst[i][:], lst[j][:]) < 0 }
+func (lst innerSortPubKeys) Len() int           { return len(lst) }
+func (lst innerSortPubKeys) Swap(i, j int)      { lst[j], lst[i] = lst[i], lst[j] }
+
+// SortPubKeys sorts a slice of public keys
+func SortPubKeys(pubKeys [][crypto.SECP256K1RPKLen]byte) { sort.Sort(innerSortPubKeys(pubKeys)) }
+
+// IsSortedAndUniquePubKeys returns true if [pubKeys] is sorted and unique
+func IsSortedAndUniquePubKeys(pubKeys [][crypto.SECP256K1RPKLen]byte) bool {
+	return utils.IsSortedAndUnique(innerSortPubKeys(pubKeys))
+}
@@ -0,0 +1,19 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package schnorrfx
+
+import (
+	"github.com/ava-labs/gecko/ids"
+)
+
+// ID that this Fx uses when labeled
+var (
+	ID = ids.NewID([32]byte{'s', 'c', 'h', 'n', 'o', 'r', 'r', 'f', 'x'})
+)
+
+// Factory ...
+type Factory struct{}
+
+// New ...
+func (f *Factory) New() interface{} { return &Fx{} }
@@ -0,0 +1,174 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package schnorrfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/cache"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/components/verify"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+const (
+	// number of verified aggregated signatures to remember, so that the
+	// signature isn't verified again for every input it covers
+	verifiedCacheSize = 2048
+)
+
+var (
+	errWrongVMType         = errors.New("wrong vm type")
+	errWrongTxType         = errors.New("wrong tx type")
+	errWrongUTXOType       = errors.New("wrong utxo type")
+	errWrongInputType      = errors.New("wrong input type")
+	errWrongCredentialType = errors.New("wrong credential type")
+
+	errOperationsNotSupported = errors.New("schnorrfx doesn't support operations")
+
+	errWrongAmounts        = errors.New("input is consuming a different amount than expected")
+	errTimelocked          = errors.New("output is time locked")
+	errTooManySigners      = errors.New("input has more signers than expected")
+	errTooFewSigners       = errors.New("input has less signers than expected")
+	errSigIndexOutOfBounds = errors.New("input signature index is out of bounds")
+	errNoAggregate         = errors.New("transaction doesn't hold an aggregated signature")
+	errMultipleAggregates  = errors.New("transaction holds more than one aggregated signature")
+	errInvalidAggregate    = errors.New("aggregated signature is invalid")
+	errWrongSigner         = errors.New("aggregated signature isn't by the expected signer")
+)
+
+// Fx describes outputs that are spent with aggregated schnorr signatures.
+// However many inputs of this Fx a transaction has, and however many addresses
+// sign them, the transaction holds a single signature for all of them. This
+// keeps transactions that consume many outputs small.
+type Fx struct {
+	vm      secp256k1fx.VM
+	factory crypto.FactorySECP256K1R
+
+	// maps the hash of an aggregated signature, the transaction it signs and
+	// its signers to the addresses of its signers
+	verified cache.LRU
+}
+
+// Initialize ...
+func (fx *Fx) Initialize(vmIntf interface{}) error {
+	vm, ok := vmIntf.(secp256k1fx.VM)
+	if !ok {
+		return errWrongVMType
+	}
+	fx.vm = vm
+	fx.verified = cache.LRU{Size: verifiedCacheSize}
+
+	c := vm.Codec()
+	c.RegisterType(&TransferOutput{})
+	c.RegisterType(&TransferInput{})
+	c.RegisterType(&Credential{})
+	return nil
+}
+
+// VerifyOperation ...
+func (fx *Fx) VerifyOperation(txIntf interface{}, utxosIntf, insIntf, credsIntf, outsIntf []interface{}) error {
+	return errOperationsNotSupported
+}
+
+// VerifyTransfer verifies that the addresses that [inIntf] names as signers
+// are among the signers of its transaction's aggregated signature
+func (fx *Fx) VerifyTransfer(txIntf, utxoIntf, inIntf, credIntf interface{}) error {
+	tx, ok := txIntf.(Tx)
+	if !ok {
+		return errWrongTxType
+	}
+	utxo, ok := utxoIntf.(*TransferOutput)
+	if !ok {
+		return errWrongUTXOType
+	}
+	in, ok := inIntf.(*TransferInput)
+	if !ok {
+		return errWrongInputType
+	}
+	cred, ok := credIntf.(*Credential)
+	if !ok {
+		return errWrongCredentialType
+	}
+	if err := verify.All(utxo, in, cred); err != nil {
+		return err
+	}
+
+	numSigs := uint32(len(in.SigIndices))
+	switch {
+	case utxo.Amt != in.Amt:
+		return errWrongAmounts
+	case utxo.Locktime > fx.vm.Clock().Unix():
+		return errTimelocked
+	case utxo.Threshold < numSigs:
+		return errTooManySigners
+	case utxo.Threshold > numSigs:
+		return errTooFewSigners
+	}
+
+	signers, err := fx.verifyAggregate(tx)
+	if err != nil {
+		return err
+	}
+	for _, index := range in.SigIndices {
+		if index >= uint32(len(utxo.Addrs)) {
+			return errSigIndexOutOfBounds
+		}
+		if !signers.Contains(utxo.Addrs[index]) {
+			return errWrongSigner
+		}
+	}
+	return nil
+}
+
+// verifyAggregate verifies the aggregated signature of [tx] and returns the
+// addresses of its signers
+func (fx *Fx) verifyAggregate(tx Tx) (ids.ShortSet, error) {
+	var aggregate *Credential
+	for _, credIntf := range tx.Credentials() {
+		if cred, ok := credIntf.(*Credential); ok && cred != nil && len(cred.Sigs) == 1 {
+			if aggregate != nil {
+				return nil, errMultipleAggregates
+			}
+			aggregate = cred
+		}
+	}
+	if aggregate == nil {
+		return nil, errNoAggregate
+	}
+
+	txHash := hashing.ComputeHash256(tx.UnsignedBytes())
+	sig := aggregate.Sigs[0]
+
+	cacheBytes := make([]byte, 0, len(txHash)+len(sig)+len(aggregate.PubKeys)*crypto.SECP256K1RPKLen)
+	cacheBytes = append(cacheBytes, txHash...)
+	cacheBytes = append(cacheBytes, sig[:]...)
+	for _, pkBytes := range aggregate.PubKeys {
+		cacheBytes = append(cacheBytes, pkBytes[:]...)
+	}
+	cacheID := ids.NewID(hashing.ComputeHash256Array(cacheBytes))
+	if signers, ok := fx.verified.Get(cacheID); ok {
+		return signers.(ids.ShortSet), nil
+	}
+
+	pks := make([]*crypto.PublicKeySECP256K1R, len(aggregate.PubKeys))
+	signers := ids.ShortSet{}
+	for i, pkBytes := range aggregate.PubKeys {
+		pkIntf, err := fx.factory.ToPublicKey(pkBytes[:])
+		if err != nil {
+			return nil, err
+		}
+		pk := pkIntf.(*crypto.PublicKeySECP256K1R)
+		pks[i] = pk
+		signers.Add(pk.Address())
+	}
+	if !crypto.VerifyAggregateHash(pks, txHash, sig[:]) {
+		return nil, errInvalidAggregate
+	}
+
+	fx.verified.Put(cacheID, signers)
+	return signers, nil
+}
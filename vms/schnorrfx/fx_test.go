@@ -0,0 +1,304 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package schnorrfx
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/verify"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	txBytes = []byte{0, 1, 2, 3, 4, 5}
+)
+
+type testVM struct{ clock timer.Clock }
+
+func (vm *testVM) Codec() codec.Codec { return codec.NewDefault() }
+
+func (vm *testVM) Clock() *timer.Clock { return &vm.clock }
+
+type testTx struct {
+	bytes []byte
+	creds []verify.Verifiable
+}
+
+func (tx *testTx) UnsignedBytes() []byte { return tx.bytes }
+
+func (tx *testTx) Credentials() []verify.Verifiable { return tx.creds }
+
+func newKeys(t *testing.T, num int) []*crypto.PrivateKeySECP256K1R {
+	f := crypto.FactorySECP256K1R{}
+	keys := []*crypto.PrivateKeySECP256K1R{}
+	for i := 0; i < num; i++ {
+		key, err := f.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key.(*crypto.PrivateKeySECP256K1R))
+	}
+	return keys
+}
+
+// aggregate returns the credential holding the aggregated signature of
+// [bytes] by [keys]
+func aggregate(t *testing.T, bytes []byte, keys []*crypto.PrivateKeySECP256K1R) *Credential {
+	cred := &Credential{}
+	for _, key := range keys {
+		pk := [crypto.SECP256K1RPKLen]byte{}
+		copy(pk[:], key.PublicKey().Bytes())
+		cred.PubKeys = append(cred.PubKeys, pk)
+	}
+	SortPubKeys(cred.PubKeys)
+
+	// Sign with the keys in the order of their public keys
+	sorted := make([]*crypto.PrivateKeySECP256K1R, len(keys))
+	for _, key := range keys {
+		for i, pk := range cred.PubKeys {
+			if string(pk[:]) == string(key.PublicKey().Bytes()) {
+				sorted[i] = key
+			}
+		}
+	}
+	sig, err := crypto.SignAggregateHash(sorted, hashing.ComputeHash256(bytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixedSig := [crypto.SchnorrSigLen]byte{}
+	copy(fixedSig[:], sig)
+	cred.Sigs = append(cred.Sigs, fixedSig)
+	return cred
+}
+
+func covered() *Credential {
+	return &Credential{
+		PubKeys: [][crypto.SECP256K1RPKLen]byte{},
+		Sigs:    [][crypto.SchnorrSigLen]byte{},
+	}
+}
+
+func output(amt uint64, addrs ...ids.ShortID) *TransferOutput {
+	ids.SortShortIDs(addrs)
+	return &TransferOutput{TransferOutput: secp256k1fx.TransferOutput{
+		Amt: amt,
+		OutputOwners: secp256k1fx.OutputOwners{
+			Threshold: uint32(len(addrs)),
+			Addrs:     addrs,
+		},
+	}}
+}
+
+func input(amt uint64, sigIndices ...uint32) *TransferInput {
+	return &TransferInput{TransferInput: secp256k1fx.TransferInput{
+		Amt:   amt,
+		Input: secp256k1fx.Input{SigIndices: sigIndices},
+	}}
+}
+
+func newFx(t *testing.T) (*Fx, *testVM) {
+	vm := &testVM{}
+	fx := &Fx{}
+	if err := fx.Initialize(vm); err != nil {
+		t.Fatal(err)
+	}
+	return fx, vm
+}
+
+func TestFxInitializeInvalid(t *testing.T) {
+	fx := Fx{}
+	if err := fx.Initialize(nil); err == nil {
+		t.Fatalf("Should have returned an error")
+	}
+}
+
+func TestFxVerifyTransfer(t *testing.T) {
+	fx, _ := newFx(t)
+	keys := newKeys(t, 3)
+	addr0 := keys[0].PublicKey().Address()
+	addr1 := keys[1].PublicKey().Address()
+	addr2 := keys[2].PublicKey().Address()
+
+	// The first input is signed by keys[0] and keys[1], the second by keys[2]
+	aggregateCred := aggregate(t, txBytes, keys)
+	coveredCred := covered()
+	tx := &testTx{
+		bytes: txBytes,
+		creds: []verify.Verifiable{aggregateCred, coveredCred},
+	}
+
+	out0 := output(1, addr0, addr1)
+	if err := fx.VerifyTransfer(tx, out0, input(1, 0, 1), aggregateCred); err != nil {
+		t.Fatal(err)
+	}
+	out1 := output(2, addr2)
+	if err := fx.VerifyTransfer(tx, out1, input(2, 0), coveredCred); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFxVerifyTransferWrongSigner(t *testing.T) {
+	fx, _ := newFx(t)
+	keys := newKeys(t, 2)
+
+	cred := aggregate(t, txBytes, keys[:1])
+	tx := &testTx{
+		bytes: txBytes,
+		creds: []verify.Verifiable{cred},
+	}
+	out := output(1, keys[1].PublicKey().Address())
+	if err := fx.VerifyTransfer(tx, out, input(1, 0), cred); err != errWrongSigner {
+		t.Fatalf("Should have errored with %s", errWrongSigner)
+	}
+}
+
+func TestFxVerifyTransferInvalidSignature(t *testing.T) {
+	fx, _ := newFx(t)
+	keys := newKeys(t, 1)
+
+	// Signs different bytes than the transaction's
+	cred := aggregate(t, []byte{1}, keys)
+	tx := &testTx{
+		bytes: txBytes,
+		creds: []verify.Verifiable{cred},
+	}
+	out := output(1, keys[0].PublicKey().Address())
+	if err := fx.VerifyTransfer(tx, out, input(1, 0), cred); err != errInvalidAggregate {
+		t.Fatalf("Should have errored with %s", errInvalidAggregate)
+	}
+}
+
+func TestFxVerifyTransferNoAggregate(t *testing.T) {
+	fx, _ := newFx(t)
+	keys := newKeys(t, 1)
+
+	cred := covered()
+	tx := &testTx{
+		bytes: txBytes,
+		creds: []verify.Verifiable{cred},
+	}
+	out := output(1, keys[0].PublicKey().Address())
+	if err := fx.VerifyTransfer(tx, out, input(1, 0), cred); err != errNoAggregate {
+		t.Fatalf("Should have errored with %s", errNoAggregate)
+	}
+}
+
+func TestFxVerifyTransferMultipleAggregates(t *testing.T) {
+	fx, _ := newFx(t)
+	keys := newKeys(t, 1)
+
+	cred := aggregate(t, txBytes, keys)
+	tx := &testTx{
+		bytes: txBytes,
+		creds: []verify.Verifiable{cred, aggregate(t, txBytes, keys)},
+	}
+	out := output(1, keys[0].PublicKey().Address())
+	if err := fx.VerifyTransfer(tx, out, input(1, 0), cred); err != errMultipleAggregates {
+		t.Fatalf("Should have errored with %s", errMultipleAggregates)
+	}
+}
+
+func TestFxVerifyTransferInvalidInput(t *testing.T) {
+	fx, vm := newFx(t)
+	keys := newKeys(t, 2)
+	addr0 := keys[0].PublicKey().Address()
+	addr1 := keys[1].PublicKey().Address()
+
+	cred := aggregate(t, txBytes, keys)
+	tx := &testTx{
+		bytes: txBytes,
+		creds: []verify.Verifiable{cred},
+	}
+
+	if err := fx.VerifyTransfer(tx, output(1, addr0), input(2, 0), cred); err != errWrongAmounts {
+		t.Fatalf("Should have errored with %s", errWrongAmounts)
+	}
+	if err := fx.VerifyTransfer(tx, output(1, addr0, addr1), input(1, 0), cred); err != errTooFewSigners {
+		t.Fatalf("Should have errored with %s", errTooFewSigners)
+	}
+	if err := fx.VerifyTransfer(tx, output(1, addr0), input(1, 0, 1), cred); err != errTooManySigners {
+		t.Fatalf("Should have errored with %s", errTooManySigners)
+	}
+
+	out := output(1, addr0)
+	out.Locktime = vm.clock.Unix() + 1
+	if err := fx.VerifyTransfer(tx, out, input(1, 0), cred); err != errTimelocked {
+		t.Fatalf("Should have errored with %s", errTimelocked)
+	}
+}
+
+func TestFxVerifyTransferWrongTypes(t *testing.T) {
+	fx, _ := newFx(t)
+	keys := newKeys(t, 1)
+	addr := keys[0].PublicKey().Address()
+
+	cred := aggregate(t, txBytes, keys)
+	tx := &testTx{
+		bytes: txBytes,
+		creds: []verify.Verifiable{cred},
+	}
+
+	if err := fx.VerifyTransfer(nil, output(1, addr), input(1, 0), cred); err != errWrongTxType {
+		t.Fatalf("Should have errored with %s", errWrongTxType)
+	}
+	if err := fx.VerifyTransfer(tx, nil, input(1, 0), cred); err != errWrongUTXOType {
+		t.Fatalf("Should have errored with %s", errWrongUTXOType)
+	}
+	if err := fx.VerifyTransfer(tx, output(1, addr), nil, cred); err != errWrongInputType {
+		t.Fatalf("Should have errored with %s", errWrongInputType)
+	}
+	if err := fx.VerifyTransfer(tx, output(1, addr), input(1, 0), nil); err != errWrongCredentialType {
+		t.Fatalf("Should have errored with %s", errWrongCredentialType)
+	}
+}
+
+func TestFxVerifyOperation(t *testing.T) {
+	fx, _ := newFx(t)
+	if err := fx.VerifyOperation(nil, nil, nil, nil, nil); err != errOperationsNotSupported {
+		t.Fatalf("Should have errored with %s", errOperationsNotSupported)
+	}
+}
+
+func TestCredentialVerify(t *testing.T) {
+	keys := newKeys(t, 2)
+
+	if err := (*Credential)(nil).Verify(); err != errNilCredential {
+		t.Fatalf("Should have errored with %s", errNilCredential)
+	}
+	if err := covered().Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := aggregate(t, txBytes, keys).Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	cred := aggregate(t, txBytes, keys)
+	cred.Sigs = append(cred.Sigs, cred.Sigs[0])
+	if err := cred.Verify(); err != errTooManySigs {
+		t.Fatalf("Should have errored with %s", errTooManySigs)
+	}
+
+	cred = aggregate(t, txBytes, keys)
+	cred.PubKeys = [][crypto.SECP256K1RPKLen]byte{}
+	if err := cred.Verify(); err != errNoPubKeys {
+		t.Fatalf("Should have errored with %s", errNoPubKeys)
+	}
+
+	cred = aggregate(t, txBytes, keys)
+	cred.Sigs = [][crypto.SchnorrSigLen]byte{}
+	if err := cred.Verify(); err != errUnexpectedPubKeys {
+		t.Fatalf("Should have errored with %s", errUnexpectedPubKeys)
+	}
+
+	cred = aggregate(t, txBytes, keys)
+	cred.PubKeys[0], cred.PubKeys[1] = cred.PubKeys[1], cred.PubKeys[0]
+	if err := cred.Verify(); err != errPubKeysNotSortedUnique {
+		t.Fatalf("Should have errored with %s", errPubKeysNotSortedUnique)
+	}
+}
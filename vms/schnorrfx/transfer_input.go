@@ -0,0 +1,30 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package schnorrfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilInput = errors.New("nil input")
+)
+
+// TransferInput consumes a TransferOutput. Its signers are the addresses of
+// the output at its signature indices.
+type TransferInput struct {
+	secp256k1fx.TransferInput `serialize:"true"`
+}
+
+// Verify this input is syntactically valid
+func (in *TransferInput) Verify() error {
+	switch {
+	case in == nil:
+		return errNilInput
+	default:
+		return in.TransferInput.Verify()
+	}
+}
@@ -0,0 +1,30 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package schnorrfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilOutput = errors.New("nil output")
+)
+
+// TransferOutput is owned like a secp256k1fx output, but is spent with the
+// aggregated signature of its transaction
+type TransferOutput struct {
+	secp256k1fx.TransferOutput `serialize:"true"`
+}
+
+// Verify ...
+func (out *TransferOutput) Verify() error {
+	switch {
+	case out == nil:
+		return errNilOutput
+	default:
+		return out.TransferOutput.Verify()
+	}
+}
@@ -0,0 +1,17 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package schnorrfx
+
+import (
+	"github.com/ava-labs/gecko/vms/components/verify"
+)
+
+// Tx that this Fx is supporting
+type Tx interface {
+	UnsignedBytes() []byte
+
+	// Credentials returns the credentials of the transaction, which include
+	// the one holding its aggregated signature
+	Credentials() []verify.Verifiable
+}
@@ -0,0 +1,46 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// newvm generates the skeleton of a VM built with the sdk package.
+//
+// Usage:
+//
+//	newvm -dir ./myvm -package myvm -name myvm
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/gecko/vms/sdk/scaffold"
+)
+
+func main() {
+	dir := flag.String("dir", "", "Directory to generate the VM in")
+	pkg := flag.String("package", "", "Go package name of the VM. Defaults to the name of the directory")
+	name := flag.String("name", "", "Name of the VM's API service. Defaults to the package name")
+	flag.Parse()
+
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "the directory to generate the VM in must be given with -dir")
+		os.Exit(2)
+	}
+	if *pkg == "" {
+		*pkg = filepath.Base(*dir)
+	}
+	if *name == "" {
+		*name = *pkg
+	}
+
+	config := scaffold.Config{
+		Package: *pkg,
+		Name:    *name,
+	}
+	if err := scaffold.Generate(*dir, config); err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't generate the VM: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("generated VM %s in %s\n", *name, *dir)
+}
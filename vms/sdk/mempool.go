@@ -0,0 +1,103 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sdk
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/components/metrics"
+)
+
+var (
+	errMempoolFull = errors.New("mempool is full")
+	errDuplicateTx = errors.New("transaction is already in the mempool")
+)
+
+// Tx is a transaction that can be put into the mempool
+type Tx interface {
+	ID() ids.ID
+	Bytes() []byte
+}
+
+// Mempool holds the transactions that haven't been put into a block, oldest
+// first
+type Mempool struct {
+	maxSize int
+	txs     []Tx
+	txIDs   ids.Set
+	metrics *metrics.Metrics
+}
+
+// Initialize the mempool to hold at most [maxSize] transactions. The number of
+// transactions in the mempool is reported to [metrics].
+func (m *Mempool) Initialize(maxSize int, metrics *metrics.Metrics) {
+	m.maxSize = maxSize
+	m.txs = nil
+	m.txIDs = ids.Set{}
+	m.metrics = metrics
+}
+
+// Add [tx] to the mempool
+func (m *Mempool) Add(tx Tx) error {
+	switch txID := tx.ID(); {
+	case m.txIDs.Contains(txID):
+		return errDuplicateTx
+	case len(m.txs) >= m.maxSize:
+		return errMempoolFull
+	default:
+		m.txs = append(m.txs, tx)
+		m.txIDs.Add(txID)
+		m.metrics.SetMempoolTxs(len(m.txs))
+		return nil
+	}
+}
+
+// Has returns true if the transaction [txID] is in the mempool
+func (m *Mempool) Has(txID ids.ID) bool { return m.txIDs.Contains(txID) }
+
+// Len returns the number of transactions in the mempool
+func (m *Mempool) Len() int { return len(m.txs) }
+
+// Pop removes and returns the oldest [n] transactions in the mempool, or every
+// transaction if there are fewer than [n]
+func (m *Mempool) Pop(n int) []Tx {
+	if n > len(m.txs) {
+		n = len(m.txs)
+	}
+	txs := m.txs[:n:n]
+	m.txs = m.txs[n:]
+	for _, tx := range txs {
+		m.txIDs.Remove(tx.ID())
+	}
+	m.metrics.SetMempoolTxs(len(m.txs))
+	return txs
+}
+
+// Remove the transactions [txIDs] from the mempool, such as when they were put
+// into a block built by another node
+func (m *Mempool) Remove(txIDs ...ids.ID) {
+	removed := ids.Set{}
+	for _, txID := range txIDs {
+		if m.txIDs.Contains(txID) {
+			removed.Add(txID)
+			m.txIDs.Remove(txID)
+		}
+	}
+	if removed.Len() == 0 {
+		return
+	}
+
+	remaining := m.txs[:0]
+	for _, tx := range m.txs {
+		if !removed.Contains(tx.ID()) {
+			remaining = append(remaining, tx)
+		}
+	}
+	for i := len(remaining); i < len(m.txs); i++ {
+		m.txs[i] = nil
+	}
+	m.txs = remaining
+	m.metrics.SetMempoolTxs(len(m.txs))
+}
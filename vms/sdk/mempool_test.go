@@ -0,0 +1,88 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sdk
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/components/metrics"
+)
+
+type testTx struct{ id ids.ID }
+
+func (tx *testTx) ID() ids.ID    { return tx.id }
+func (tx *testTx) Bytes() []byte { return tx.id.Bytes() }
+
+func newTestTx(b byte) *testTx { return &testTx{id: ids.NewID([32]byte{b})} }
+
+func newTestMempool(maxSize int) *Mempool {
+	m := &metrics.Metrics{}
+	m.Initialize("")
+	mempool := &Mempool{}
+	mempool.Initialize(maxSize, m)
+	return mempool
+}
+
+func TestMempoolAdd(t *testing.T) {
+	mempool := newTestMempool(2)
+
+	tx0, tx1, tx2 := newTestTx(0), newTestTx(1), newTestTx(2)
+	if err := mempool.Add(tx0); err != nil {
+		t.Fatal(err)
+	}
+	if err := mempool.Add(tx0); err != errDuplicateTx {
+		t.Fatalf("adding a tx twice should have failed but returned %v", err)
+	}
+	if err := mempool.Add(tx1); err != nil {
+		t.Fatal(err)
+	}
+	if err := mempool.Add(tx2); err != errMempoolFull {
+		t.Fatalf("adding a tx to a full mempool should have failed but returned %v", err)
+	}
+	if !mempool.Has(tx0.ID()) || !mempool.Has(tx1.ID()) || mempool.Has(tx2.ID()) {
+		t.Fatalf("mempool has the wrong txs")
+	}
+}
+
+func TestMempoolPop(t *testing.T) {
+	mempool := newTestMempool(10)
+	for i := byte(0); i < 5; i++ {
+		if err := mempool.Add(newTestTx(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	txs := mempool.Pop(2)
+	if len(txs) != 2 || !txs[0].ID().Equals(newTestTx(0).ID()) || !txs[1].ID().Equals(newTestTx(1).ID()) {
+		t.Fatalf("should have popped the 2 oldest txs")
+	}
+	if mempool.Has(txs[0].ID()) || mempool.Len() != 3 {
+		t.Fatalf("popped txs should have been removed from the mempool")
+	}
+
+	if txs := mempool.Pop(10); len(txs) != 3 {
+		t.Fatalf("should have popped the 3 remaining txs but popped %d", len(txs))
+	}
+	if mempool.Len() != 0 {
+		t.Fatalf("mempool should be empty")
+	}
+}
+
+func TestMempoolRemove(t *testing.T) {
+	mempool := newTestMempool(10)
+	for i := byte(0); i < 3; i++ {
+		if err := mempool.Add(newTestTx(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mempool.Remove(newTestTx(1).ID(), newTestTx(5).ID())
+	if mempool.Len() != 2 || mempool.Has(newTestTx(1).ID()) {
+		t.Fatalf("removed tx should no longer be in the mempool")
+	}
+	if txs := mempool.Pop(2); !txs[0].ID().Equals(newTestTx(0).ID()) || !txs[1].ID().Equals(newTestTx(2).ID()) {
+		t.Fatalf("remaining txs should have kept their order")
+	}
+}
@@ -0,0 +1,86 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package scaffold generates the skeleton of a VM built with the sdk package.
+// The skeleton builds blocks out of transactions that carry arbitrary data, and
+// is meant to be changed into the VM being built.
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+var (
+	errInvalidPackage = errors.New("package name must be a valid Go identifier")
+	errNoName         = errors.New("VM name must be non-empty")
+)
+
+// Config describes the VM to generate
+type Config struct {
+	// Name of the Go package of the VM
+	Package string
+
+	// Name of the VM's API service. Its methods are called as Name.Method.
+	Name string
+}
+
+// Files returns the source files of the skeleton VM described by [config].
+// Key: File name
+// Value: File contents
+func Files(config Config) (map[string][]byte, error) {
+	switch {
+	case !token.IsIdentifier(config.Package) || token.IsKeyword(config.Package):
+		return nil, errInvalidPackage
+	case config.Name == "":
+		return nil, errNoName
+	}
+
+	files := make(map[string][]byte, len(templates))
+	for name, text := range templates {
+		tmpl, err := template.New(name).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		buf := bytes.Buffer{}
+		if err := tmpl.Execute(&buf, config); err != nil {
+			return nil, err
+		}
+		source, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("couldn't format %s: %w", name, err)
+		}
+		files[name] = source
+	}
+	return files, nil
+}
+
+// Generate writes the source files of the skeleton VM described by [config]
+// to the directory [dir]. Existing files aren't overwritten.
+func Generate(dir string, config Config) error {
+	files, err := Files(config)
+	if err != nil {
+		return err
+	}
+	for name := range files {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return fmt.Errorf("%s already exists", filepath.Join(dir, name))
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, source := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), source, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFiles(t *testing.T) {
+	files, err := Files(Config{Package: "myvm", Name: "myvm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(templates) {
+		t.Fatalf("should have generated %d files but generated %d", len(templates), len(files))
+	}
+	for name, source := range files {
+		file, err := parser.ParseFile(token.NewFileSet(), name, source, 0)
+		if err != nil {
+			t.Fatalf("generated %s doesn't parse: %s", name, err)
+		}
+		if file.Name.Name != "myvm" {
+			t.Fatalf("generated %s is in package %s", name, file.Name.Name)
+		}
+	}
+
+	for _, invalid := range []Config{{Package: "", Name: "myvm"}, {Package: "my-vm", Name: "myvm"}, {Package: "func", Name: "myvm"}, {Package: "myvm"}} {
+		if _, err := Files(invalid); err == nil {
+			t.Fatalf("should have failed to generate %+v", invalid)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scaffold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	vmDir := filepath.Join(dir, "myvm")
+	config := Config{Package: "myvm", Name: "myvm"}
+	if err := Generate(vmDir, config); err != nil {
+		t.Fatal(err)
+	}
+	for name := range templates {
+		if _, err := os.Stat(filepath.Join(vmDir, name)); err != nil {
+			t.Fatalf("%s should have been generated: %s", name, err)
+		}
+	}
+	if err := Generate(vmDir, config); err == nil {
+		t.Fatalf("generating over existing files should have failed")
+	}
+}
@@ -0,0 +1,374 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package scaffold
+
+// Key: Name of a file of the skeleton VM
+// Value: Template of the file, executed with a Config
+var templates = map[string]string{
+	"factory.go": factoryTemplate,
+	"vm.go":      vmTemplate,
+	"block.go":   blockTemplate,
+	"tx.go":      txTemplate,
+	"service.go": serviceTemplate,
+}
+
+const factoryTemplate = `package {{.Package}}
+
+import (
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// ID is the ID of this VM. Chains are created with this VM by this ID.
+var ID = ids.NewID(hashing.ComputeHash256Array([]byte("{{.Name}}")))
+
+// Factory creates new instances of this VM
+type Factory struct{}
+
+// New returns a new instance of this VM
+func (f *Factory) New() interface{} { return &VM{} }
+`
+
+const vmTemplate = `package {{.Package}}
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/sdk"
+)
+
+// maxBlockTxs is the most transactions a block may contain
+const maxBlockTxs = 128
+
+var (
+	errNoPendingTxs = errors.New("there are no transactions to put into a block")
+	errWrongType    = errors.New("block has an unexpected type")
+)
+
+// VM is a Snowman VM whose blocks contain transactions
+type VM struct {
+	sdk.VM
+}
+
+// Initialize implements the snowman.ChainVM interface
+func (vm *VM) Initialize(
+	ctx *snow.Context,
+	db database.Database,
+	genesisData []byte,
+	toEngine chan<- common.Message,
+	_ []*common.Fx,
+) error {
+	err := vm.VM.Initialize(ctx, db, toEngine, sdk.Config{
+		ParseBlock: vm.ParseBlock,
+		Genesis: func() (snowman.Block, error) {
+			return vm.newBlock(ids.Empty, 0, []*Tx{})
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	vm.RegisterService("", "{{.Name}}", &Service{vm: vm})
+	return nil
+}
+
+// BuildBlock implements the snowman.ChainVM interface
+func (vm *VM) BuildBlock() (snowman.Block, error) {
+	parent, err := vm.getBlock(vm.Preferred())
+	if err != nil {
+		return nil, err
+	}
+
+	pending := vm.PopTxs(maxBlockTxs)
+	if len(pending) == 0 {
+		return nil, errNoPendingTxs
+	}
+
+	txs := make([]*Tx, len(pending))
+	for i, tx := range pending {
+		txs[i] = tx.(*Tx)
+	}
+	return vm.newBlock(parent.ID(), parent.Height+1, txs)
+}
+
+// ParseBlock implements the snowman.ChainVM interface
+func (vm *VM) ParseBlock(bytes []byte) (snowman.Block, error) {
+	block := &Block{}
+	if err := vm.Codec.Unmarshal(bytes, block); err != nil {
+		return nil, err
+	}
+	return block, block.initialize(bytes, vm)
+}
+
+// getBlock returns the block with ID [blkID]
+func (vm *VM) getBlock(blkID ids.ID) (*Block, error) {
+	blk, err := vm.GetBlock(blkID)
+	if err != nil {
+		return nil, err
+	}
+	block, ok := blk.(*Block)
+	if !ok {
+		return nil, errWrongType
+	}
+	return block, nil
+}
+
+// newBlock returns a block at [height], with parent [parentID], that contains
+// [txs]
+func (vm *VM) newBlock(parentID ids.ID, height uint64, txs []*Tx) (*Block, error) {
+	block := &Block{
+		Block:  core.NewBlock(parentID),
+		Height: height,
+		Txs:    txs,
+	}
+	bytes, err := vm.Codec.Marshal(block)
+	if err != nil {
+		return nil, err
+	}
+	return block, block.initialize(bytes, vm)
+}
+`
+
+const blockTemplate = `package {{.Package}}
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/components/events"
+)
+
+var (
+	errWrongHeight  = errors.New("block's height isn't one more than its parent's height")
+	errTooManyTxs   = errors.New("block contains too many transactions")
+	errDuplicateTxs = errors.New("block contains the same transaction more than once")
+)
+
+// Block is a block on the chain
+type Block struct {
+	*core.Block ` + "`serialize:\"true\"`" + `
+	Height      uint64 ` + "`serialize:\"true\"`" + `
+	Txs         []*Tx  ` + "`serialize:\"true\"`" + `
+
+	vm *VM
+}
+
+func (b *Block) initialize(bytes []byte, vm *VM) error {
+	b.vm = vm
+	b.Block.Initialize(bytes, &vm.SnowmanVM)
+	for _, tx := range b.Txs {
+		if err := tx.initialize(vm.Codec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify implements the snowman.Block interface
+func (b *Block) Verify() error {
+	if accepted, err := b.Block.Verify(); err != nil || accepted {
+		return err
+	}
+	defer b.vm.VMMetrics.Verified(time.Now())
+
+	parent, err := b.vm.getBlock(b.ParentID())
+	if err != nil {
+		return err
+	}
+	if b.Height != parent.Height+1 {
+		return errWrongHeight
+	}
+	if len(b.Txs) > maxBlockTxs {
+		return errTooManyTxs
+	}
+	txIDs := ids.Set{}
+	emitted := make([]*events.Event, len(b.Txs))
+	for i, tx := range b.Txs {
+		if err := tx.Verify(); err != nil {
+			return err
+		}
+		if txIDs.Contains(tx.ID()) {
+			return errDuplicateTxs
+		}
+		txIDs.Add(tx.ID())
+		emitted[i] = tx.Event()
+	}
+
+	// Receipts are indexed by block, as a transaction may be in blocks that
+	// conflict with each other
+	if err := b.vm.Events.Record(b.ID(), emitted...); err != nil {
+		return err
+	}
+	if err := b.vm.SaveBlock(b.vm.DB, b); err != nil {
+		return err
+	}
+	return b.vm.DB.Commit()
+}
+
+// Accept implements the snowman.Block interface
+func (b *Block) Accept() {
+	b.Block.Accept()
+	b.vm.VMMetrics.Accepted(len(b.Txs), 0, 0)
+
+	txIDs := make([]ids.ID, len(b.Txs))
+	for i, tx := range b.Txs {
+		txIDs[i] = tx.ID()
+	}
+	b.vm.Mempool.Remove(txIDs...)
+
+	if err := b.vm.Events.Accept(b.ID()); err != nil {
+		b.vm.Ctx.Log.Error("couldn't save the receipt of block %s: %s", b.ID(), err)
+	}
+	if err := b.vm.DB.Commit(); err != nil {
+		b.vm.Ctx.Log.Error("couldn't commit block %s: %s", b.ID(), err)
+	}
+}
+
+// Reject implements the snowman.Block interface
+func (b *Block) Reject() {
+	b.Block.Reject()
+	b.vm.VMMetrics.Rejected(len(b.Txs))
+	b.vm.Events.Reject(b.ID())
+
+	if err := b.vm.DB.Commit(); err != nil {
+		b.vm.Ctx.Log.Error("couldn't commit block %s: %s", b.ID(), err)
+	}
+}
+`
+
+const txTemplate = `package {{.Package}}
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/events"
+)
+
+// maxDataLen is the most bytes of data a transaction may carry
+const maxDataLen = 1024
+
+var (
+	errNoData      = errors.New("transaction carries no data")
+	errDataTooLong = errors.New("transaction carries too much data")
+)
+
+// Tx is a transaction that carries data
+type Tx struct {
+	Data []byte ` + "`serialize:\"true\"`" + `
+
+	id    ids.ID
+	bytes []byte
+}
+
+func (tx *Tx) initialize(c codec.Codec) error {
+	bytes, err := c.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	tx.bytes = bytes
+	tx.id = ids.NewID(hashing.ComputeHash256Array(bytes))
+	return nil
+}
+
+// ID returns the ID of this transaction
+func (tx *Tx) ID() ids.ID { return tx.id }
+
+// Bytes returns the byte representation of this transaction
+func (tx *Tx) Bytes() []byte { return tx.bytes }
+
+// Event returns the event this transaction emits when it's executed
+func (tx *Tx) Event() *events.Event {
+	return &events.Event{
+		Type: "data",
+		Attributes: []events.Attribute{
+			{Key: "txID", Value: tx.id.String()},
+			{Key: "size", Value: strconv.Itoa(len(tx.Data))},
+		},
+	}
+}
+
+// Verify returns nil if this transaction is well formed
+func (tx *Tx) Verify() error {
+	switch {
+	case len(tx.Data) == 0:
+		return errNoData
+	case len(tx.Data) > maxDataLen:
+		return errDataTooLong
+	default:
+		return nil
+	}
+}
+`
+
+const serviceTemplate = `package {{.Package}}
+
+import (
+	"net/http"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+// Service is the API of this VM
+type Service struct{ vm *VM }
+
+// IssueTxArgs are the arguments to IssueTx
+type IssueTxArgs struct {
+	Data formatting.CB58 ` + "`json:\"data\"`" + `
+}
+
+// IssueTxReply is the reply from IssueTx
+type IssueTxReply struct {
+	TxID ids.ID ` + "`json:\"txID\"`" + `
+}
+
+// IssueTx issues a transaction that carries [args.Data]
+func (s *Service) IssueTx(_ *http.Request, args *IssueTxArgs, reply *IssueTxReply) error {
+	tx := &Tx{Data: args.Data.Bytes}
+	if err := tx.Verify(); err != nil {
+		return err
+	}
+	if err := tx.initialize(s.vm.Codec); err != nil {
+		return err
+	}
+	if err := s.vm.IssueTx(tx); err != nil {
+		return err
+	}
+	reply.TxID = tx.ID()
+	return nil
+}
+
+// GetLastAcceptedArgs are the arguments to GetLastAccepted
+type GetLastAcceptedArgs struct{}
+
+// GetLastAcceptedReply is the reply from GetLastAccepted
+type GetLastAcceptedReply struct {
+	BlockID ids.ID      ` + "`json:\"blockID\"`" + `
+	Height  json.Uint64 ` + "`json:\"height\"`" + `
+}
+
+// GetLastAccepted returns the ID and height of the last accepted block
+func (s *Service) GetLastAccepted(_ *http.Request, _ *GetLastAcceptedArgs, reply *GetLastAcceptedReply) error {
+	block, err := s.vm.getBlock(s.vm.LastAccepted())
+	if err != nil {
+		return err
+	}
+	reply.BlockID = block.ID()
+	reply.Height = json.Uint64(block.Height)
+	return nil
+}
+`
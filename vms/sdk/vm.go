@@ -0,0 +1,170 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package sdk provides the plumbing that most Snowman VMs share, so that a new
+// VM only has to define its blocks, transactions and API. A skeleton VM that
+// uses this package can be generated with the scaffold package.
+package sdk
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/components/events"
+)
+
+// DefaultMaxMempoolSize is the number of transactions the mempool holds if the
+// VM doesn't specify a size
+const DefaultMaxMempoolSize = 1024
+
+var eventsPrefix = []byte("events")
+
+// Config describes the VM being initialized
+type Config struct {
+	// Parses a block from its bytes. This is also how blocks are loaded from
+	// the database, so the block must be initialized with the VM.
+	ParseBlock func([]byte) (snowman.Block, error)
+
+	// Returns the genesis block. Only called if the database is empty.
+	Genesis func() (snowman.Block, error)
+
+	// Types to register with the codec, in order. The order must never change
+	// once the chain is running.
+	CodecTypes []interface{}
+
+	// Number of transactions the mempool holds. Defaults to
+	// DefaultMaxMempoolSize.
+	MaxMempoolSize int
+
+	// How long to wait for more transactions before the consensus engine is
+	// told that a block can be built. If 0, the engine is told as soon as a
+	// transaction is issued.
+	BuildDelay time.Duration
+}
+
+// VM implements the block storage, mempool, codec and API registration of a
+// Snowman VM. A VM embeds it, calls Initialize from its own Initialize and
+// implements BuildBlock and ParseBlock.
+type VM struct {
+	core.SnowmanVM
+
+	// Codec that the VM's blocks and transactions are serialized with
+	Codec codec.Codec
+
+	// Transactions that haven't been put into a block
+	Mempool Mempool
+
+	// Tells the consensus engine when the mempool has transactions
+	BuildTrigger core.BuildTrigger
+
+	// Events that the VM's blocks emitted. Blocks record their events when
+	// they're verified, and accept or reject them along with themselves.
+	Events *events.Log
+
+	// Key: Path extension of the API
+	// Value: The handler of the API
+	handlers map[string]*common.HTTPHandler
+}
+
+// Initialize the VM with context [ctx] and database [db]. [toEngine] is used
+// to notify the consensus engine that a block is ready to be built. If the
+// database is empty, the genesis block is saved and accepted.
+func (vm *VM) Initialize(
+	ctx *snow.Context,
+	db database.Database,
+	toEngine chan<- common.Message,
+	config Config,
+) error {
+	if err := vm.SnowmanVM.Initialize(ctx, db, config.ParseBlock, toEngine); err != nil {
+		return err
+	}
+
+	vm.Codec = codec.NewDefault()
+	for _, typ := range config.CodecTypes {
+		if err := vm.Codec.RegisterType(typ); err != nil {
+			return err
+		}
+	}
+
+	maxMempoolSize := config.MaxMempoolSize
+	if maxMempoolSize <= 0 {
+		maxMempoolSize = DefaultMaxMempoolSize
+	}
+	vm.Mempool.Initialize(maxMempoolSize, &vm.VMMetrics)
+	vm.BuildTrigger.Initialize(ctx.Log, toEngine, config.BuildDelay)
+
+	var err error
+	vm.Events, err = events.New(ctx, prefixdb.New(eventsPrefix, vm.DB))
+	if err != nil {
+		return err
+	}
+	vm.handlers = vm.Events.CreateHandlers()
+
+	if vm.DBInitialized() {
+		return nil
+	}
+
+	genesisBlock, err := config.Genesis()
+	if err != nil {
+		return err
+	}
+	if err := vm.SaveBlock(vm.DB, genesisBlock); err != nil {
+		return err
+	}
+
+	// Sets the last accepted block
+	genesisBlock.Accept()
+	vm.SetPreference(genesisBlock.ID())
+	vm.SetDBInitialized()
+	return vm.DB.Commit()
+}
+
+// Shutdown the VM
+func (vm *VM) Shutdown() {
+	vm.BuildTrigger.Shutdown()
+	vm.SnowmanVM.Shutdown()
+}
+
+// IssueTx adds [tx] to the mempool and notifies the consensus engine that a
+// block can be built
+func (vm *VM) IssueTx(tx Tx) error {
+	if err := vm.Mempool.Add(tx); err != nil {
+		return err
+	}
+	vm.BuildTrigger.NotifyBuildBlock()
+	return nil
+}
+
+// PopTxs removes and returns the oldest [n] transactions in the mempool to put
+// into the block being built. It's called from BuildBlock, so that the
+// consensus engine is notified again if transactions remain.
+func (vm *VM) PopTxs(n int) []Tx {
+	txs := vm.Mempool.Pop(n)
+	vm.BuildTrigger.BuiltBlock(vm.Mempool.Len() > 0)
+	return txs
+}
+
+// RegisterService serves [service], a gorilla RPC service named [name], at the
+// path extension [extension] of the chain's API
+func (vm *VM) RegisterService(extension, name string, service interface{}, lockOption ...common.LockOption) {
+	vm.handlers[extension] = vm.NewHandler(name, service, lockOption...)
+}
+
+// RegisterHandler serves [handler] at the path extension [extension] of the
+// chain's API
+func (vm *VM) RegisterHandler(extension string, handler *common.HTTPHandler) {
+	vm.handlers[extension] = handler
+}
+
+// CreateHandlers returns the APIs that were registered, and the events API
+func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler { return vm.handlers }
+
+// CreateStaticHandlers returns no static APIs. A VM with static APIs should
+// override it.
+func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler { return nil }
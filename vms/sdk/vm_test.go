@@ -0,0 +1,115 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sdk
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/components/core"
+)
+
+type testBlock struct {
+	*core.Block `serialize:"true"`
+	Data        uint64 `serialize:"true"`
+}
+
+func (b *testBlock) Verify() error {
+	_, err := b.Block.Verify()
+	return err
+}
+
+type testService struct{}
+
+func newTestVM(t *testing.T) (*VM, chan common.Message) {
+	vm := &VM{}
+	parse := func(bytes []byte) (snowman.Block, error) {
+		block := &testBlock{}
+		if err := vm.Codec.Unmarshal(bytes, block); err != nil {
+			return nil, err
+		}
+		block.Initialize(bytes, &vm.SnowmanVM)
+		return block, nil
+	}
+	genesis := func() (snowman.Block, error) {
+		block := &testBlock{Block: core.NewBlock(ids.Empty), Data: 5}
+		bytes, err := vm.Codec.Marshal(block)
+		if err != nil {
+			return nil, err
+		}
+		block.Initialize(bytes, &vm.SnowmanVM)
+		return block, nil
+	}
+
+	toEngine := make(chan common.Message, 1)
+	err := vm.Initialize(snow.DefaultContextTest(), memdb.New(), toEngine, Config{
+		ParseBlock:     parse,
+		Genesis:        genesis,
+		MaxMempoolSize: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vm, toEngine
+}
+
+func TestInitializeGenesis(t *testing.T) {
+	vm, _ := newTestVM(t)
+
+	if !vm.DBInitialized() {
+		t.Fatalf("database should be initialized")
+	}
+	if !vm.Preferred().Equals(vm.LastAccepted()) {
+		t.Fatalf("genesis block should be preferred")
+	}
+	blk, err := vm.GetBlock(vm.LastAccepted())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block, ok := blk.(*testBlock); !ok || block.Data != 5 {
+		t.Fatalf("last accepted block should be the genesis block")
+	}
+}
+
+func TestIssueTx(t *testing.T) {
+	vm, toEngine := newTestVM(t)
+
+	if err := vm.IssueTx(newTestTx(0)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-toEngine:
+		if msg != common.PendingTxs {
+			t.Fatalf("engine should have been notified of pending txs")
+		}
+	default:
+		t.Fatalf("engine should have been notified")
+	}
+
+	if err := vm.IssueTx(newTestTx(1)); err != errMempoolFull {
+		t.Fatalf("issuing to a full mempool should have failed but returned %v", err)
+	}
+}
+
+func TestRegisterService(t *testing.T) {
+	vm, _ := newTestVM(t)
+
+	vm.RegisterService("", "test", &testService{})
+	vm.RegisterService("/other", "test", &testService{}, common.NoLock)
+
+	handlers := vm.CreateHandlers()
+	if len(handlers) != 4 {
+		t.Fatalf("should have 4 handlers but has %d", len(handlers))
+	}
+	if handlers["/other"].LockOptions != common.NoLock {
+		t.Fatalf("handler should have the given lock option")
+	}
+	if _, ok := handlers["/events"]; !ok {
+		t.Fatalf("should serve the events API")
+	}
+}
@@ -18,6 +18,12 @@ type Credential struct {
 	Sigs [][crypto.SECP256K1RSigLen]byte `serialize:"true"`
 }
 
+// signed is implemented by Credential and by the credentials of Fxs that embed
+// it
+type signed interface{ credential() *Credential }
+
+func (cr *Credential) credential() *Credential { return cr }
+
 // Verify ...
 func (cr *Credential) Verify() error {
 	switch {
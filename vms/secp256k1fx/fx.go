@@ -11,6 +11,11 @@ import (
 	"github.com/ava-labs/gecko/vms/components/verify"
 )
 
+// recoveryCacheSize is the number of recovered public keys that are cached, so
+// that signatures recovered ahead of verification are still cached when the
+// transactions are verified
+const recoveryCacheSize = 8192
+
 var (
 	errWrongVMType         = errors.New("wrong vm type")
 	errWrongTxType         = errors.New("wrong tx type")
@@ -41,19 +46,29 @@ type Fx struct {
 
 // Initialize ...
 func (fx *Fx) Initialize(vmIntf interface{}) error {
-	vm, ok := vmIntf.(VM)
-	if !ok {
-		return errWrongVMType
+	if err := fx.InitializeVM(vmIntf); err != nil {
+		return err
 	}
 
-	c := vm.Codec()
+	c := fx.vm.Codec()
 	c.RegisterType(&MintOutput{})
 	c.RegisterType(&TransferOutput{})
 	c.RegisterType(&MintInput{})
 	c.RegisterType(&TransferInput{})
 	c.RegisterType(&Credential{})
+	return nil
+}
 
+// InitializeVM sets the VM this Fx is run by without registering any types.
+// Fxs that build on this Fx's ownership and signature rules call this from
+// their own Initialize.
+func (fx *Fx) InitializeVM(vmIntf interface{}) error {
+	vm, ok := vmIntf.(VM)
+	if !ok {
+		return errWrongVMType
+	}
 	fx.vm = vm
+	fx.secpFactory.Cache.Size = recoveryCacheSize
 	return nil
 }
 
@@ -107,7 +122,7 @@ func (fx *Fx) verifyOperation(tx Tx, utxo *MintOutput, in *MintInput, cred *Cred
 		return errWrongMintCreated
 	}
 
-	return fx.verifyCredentials(tx, &utxo.OutputOwners, &in.Input, cred)
+	return fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, cred)
 }
 
 // VerifyTransfer ...
@@ -144,10 +159,12 @@ func (fx *Fx) verifyTransfer(tx Tx, utxo *TransferOutput, in *TransferInput, cre
 		return errTimelocked
 	}
 
-	return fx.verifyCredentials(tx, &utxo.OutputOwners, &in.Input, cred)
+	return fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, cred)
 }
 
-func (fx *Fx) verifyCredentials(tx Tx, out *OutputOwners, in *Input, cred *Credential) error {
+// VerifyCredentials returns nil if [cred] holds the signatures of the
+// addresses of [out] that [in] claims signed [tx]
+func (fx *Fx) VerifyCredentials(tx Tx, out *OutputOwners, in *Input, cred *Credential) error {
 	numSigs := len(in.SigIndices)
 	switch {
 	case out.Threshold < uint32(numSigs):
@@ -177,3 +194,50 @@ func (fx *Fx) verifyCredentials(tx Tx, out *OutputOwners, in *Input, cred *Crede
 
 	return nil
 }
+
+// VerifyControl verifies that the owners of a MintOutput signed [txIntf]. The
+// owners of an asset's mint outputs control the asset.
+func (fx *Fx) VerifyControl(txIntf, utxoIntf, inIntf, credIntf interface{}) error {
+	tx, ok := txIntf.(Tx)
+	if !ok {
+		return errWrongTxType
+	}
+	utxo, ok := utxoIntf.(*MintOutput)
+	if !ok {
+		return errWrongUTXOType
+	}
+	in, ok := inIntf.(*MintInput)
+	if !ok {
+		return errWrongInputType
+	}
+	cred, ok := credIntf.(*Credential)
+	if !ok {
+		return errWrongCredentialType
+	}
+	if err := verify.All(utxo, in, cred); err != nil {
+		return err
+	}
+	return fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, cred)
+}
+
+// RecoverCredential recovers the public keys that signed [txIntf] in
+// [credIntf] and caches them, so that verifying the credential doesn't have to
+// recover them again. It's safe to call concurrently.
+func (fx *Fx) RecoverCredential(txIntf, credIntf interface{}) error {
+	tx, ok := txIntf.(Tx)
+	if !ok {
+		return errWrongTxType
+	}
+	cred, ok := credIntf.(signed)
+	if !ok {
+		return errWrongCredentialType
+	}
+
+	txHash := hashing.ComputeHash256(tx.UnsignedBytes())
+	for _, sig := range cred.credential().Sigs {
+		if _, err := fx.secpFactory.RecoverHashPublicKey(txHash, sig[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1213,3 +1213,66 @@ func TestFxVerifyOperationMismatchedMintOutput(t *testing.T) {
 		t.Fatalf("Should have errored due to a mismatched mint output")
 	}
 }
+
+func TestFxRecoverCredential(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	cred := &Credential{Sigs: [][crypto.SECP256K1RSigLen]byte{sigBytes}}
+
+	if err := fx.RecoverCredential(tx, cred); err != nil {
+		t.Fatal(err)
+	}
+	if err := fx.RecoverCredential(nil, cred); err == nil {
+		t.Fatalf("Should have errored due to a wrong tx type")
+	}
+	if err := fx.RecoverCredential(tx, nil); err == nil {
+		t.Fatalf("Should have errored due to a wrong credential type")
+	}
+
+	invalidSig := sigBytes
+	invalidSig[crypto.SECP256K1RSigLen-1] = 4
+	if err := fx.RecoverCredential(tx, &Credential{Sigs: [][crypto.SECP256K1RSigLen]byte{invalidSig}}); err == nil {
+		t.Fatalf("Should have errored due to an invalid signature")
+	}
+}
+
+func TestFxVerifyControl(t *testing.T) {
+	vm := testVM{}
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	utxo := &MintOutput{
+		OutputOwners: OutputOwners{
+			Threshold: 1,
+			Addrs: []ids.ShortID{
+				ids.NewShortID(addrBytes),
+			},
+		},
+	}
+	in := &MintInput{
+		Input: Input{
+			SigIndices: []uint32{0},
+		},
+	}
+	cred := &Credential{
+		Sigs: [][crypto.SECP256K1RSigLen]byte{
+			sigBytes,
+		},
+	}
+
+	if err := fx.VerifyControl(tx, utxo, in, cred); err != nil {
+		t.Fatal(err)
+	}
+	if err := fx.VerifyControl(tx, &TransferOutput{OutputOwners: utxo.OutputOwners}, in, cred); err == nil {
+		t.Fatalf("Should have errored due to a utxo that doesn't control the asset")
+	}
+	if err := fx.VerifyControl(&testTx{bytes: []byte{1}}, utxo, in, cred); err == nil {
+		t.Fatalf("Should have errored due to a wrong signer")
+	}
+}
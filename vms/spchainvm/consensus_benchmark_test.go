@@ -15,6 +15,7 @@ import (
 	"github.com/ava-labs/gecko/snow/consensus/snowball"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/snow/engine/common/queue"
+	"github.com/ava-labs/gecko/snow/networking/benchlist"
 	"github.com/ava-labs/gecko/snow/networking/handler"
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/snow/networking/sender"
@@ -58,7 +59,7 @@ func ConsensusLeader(numBlocks, numTxsPerBlock int, b *testing.B) {
 		beacons := validators.NewSet()
 
 		timeoutManager := timeout.Manager{}
-		timeoutManager.Initialize(2 * time.Second)
+		timeoutManager.Initialize(2*time.Second, benchlist.NewNoBenchlist())
 		go timeoutManager.Dispatch()
 
 		router := &router.ChainRouter{}
@@ -104,7 +105,7 @@ func ConsensusLeader(numBlocks, numTxsPerBlock int, b *testing.B) {
 
 		// Asynchronously passes messages from the network to the consensus engine
 		handler := &handler.Handler{}
-		handler.Initialize(&engine, msgChan, 1000)
+		handler.Initialize(&engine, msgChan, 1000, "", prometheus.NewRegistry())
 
 		// Allow incoming messages to be routed to the new chain
 		router.AddChain(handler)
@@ -184,7 +185,7 @@ func ConsensusFollower(numBlocks, numTxsPerBlock int, b *testing.B) {
 		beacons := validators.NewSet()
 
 		timeoutManager := timeout.Manager{}
-		timeoutManager.Initialize(2 * time.Second)
+		timeoutManager.Initialize(2*time.Second, benchlist.NewNoBenchlist())
 		go timeoutManager.Dispatch()
 
 		router := &router.ChainRouter{}
@@ -235,7 +236,7 @@ func ConsensusFollower(numBlocks, numTxsPerBlock int, b *testing.B) {
 
 		// Asynchronously passes messages from the network to the consensus engine
 		handler := &handler.Handler{}
-		handler.Initialize(&engine, msgChan, 1000)
+		handler.Initialize(&engine, msgChan, 1000, "", prometheus.NewRegistry())
 
 		// Allow incoming messages to be routed to the new chain
 		router.AddChain(handler)
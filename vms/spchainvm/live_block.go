@@ -5,6 +5,7 @@ package spchainvm
 
 import (
 	"errors"
+	"time"
 
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/versiondb"
@@ -74,6 +75,7 @@ func (lb *LiveBlock) Accept() {
 	if lb.vm.onAccept != nil {
 		lb.vm.onAccept(bID)
 	}
+	lb.vm.metrics.Accepted(len(lb.block.txs), 0, 0)
 }
 
 // Reject is called when this block is finalized as rejected by consensus
@@ -96,6 +98,7 @@ func (lb *LiveBlock) Reject() {
 			tx.onDecide(choices.Rejected)
 		}
 	}
+	lb.vm.metrics.Rejected(len(lb.block.txs))
 }
 
 // Status returns the current status of this block
@@ -184,6 +187,7 @@ func (lb *LiveBlock) VerifyState() error {
 		return lb.validity
 	}
 	lb.verifiedState = true
+	defer lb.vm.metrics.Verified(time.Now())
 
 	// The database if this block were to be accepted
 	lb.db = versiondb.New(parent.database())
@@ -7,7 +7,7 @@ import (
 	"errors"
 	"time"
 
-	"github.com/gorilla/rpc/v2"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/cache"
 	"github.com/ava-labs/gecko/database"
@@ -21,7 +21,9 @@ import (
 	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/metrics"
 
+	smeng "github.com/ava-labs/gecko/snow/engine/snowman"
 	jsoncodec "github.com/ava-labs/gecko/utils/json"
 )
 
@@ -38,6 +40,8 @@ var (
 var (
 	errNoTxs          = errors.New("no transactions")
 	errUnknownBlock   = errors.New("unknown block")
+	errNotAccepted    = errors.New("block isn't accepted")
+	errTxNotInBlock   = errors.New("transaction isn't in the block")
 	errUnsupportedFXs = errors.New("unsupported feature extensions")
 )
 
@@ -62,6 +66,8 @@ type VM struct {
 
 	currentBlocks map[[32]byte]*LiveBlock
 
+	metrics metrics.Metrics
+
 	onAccept func(ids.ID)
 }
 
@@ -83,6 +89,7 @@ func (vm *VM) Initialize(
 		return errUnsupportedFXs
 	}
 	vm.ctx = ctx
+	vm.metrics.Initialize(ctx.Namespace)
 	vm.state = &prefixedState{
 		block:   &cache.LRU{Size: idCacheSize},
 		account: &cache.LRU{Size: idCacheSize},
@@ -124,6 +131,13 @@ func (vm *VM) Shutdown() {
 	}
 }
 
+// ClearCaches implements the common.CachingVM interface
+func (vm *VM) ClearCaches() {
+	vm.state.block.Flush()
+	vm.state.account.Flush()
+	vm.state.status.Flush()
+}
+
 // BuildBlock implements the snowman.ChainVM interface
 func (vm *VM) BuildBlock() (snowman.Block, error) {
 	vm.timer.Cancel()
@@ -139,6 +153,7 @@ func (vm *VM) BuildBlock() (snowman.Block, error) {
 		txs = txs[:maxBatchSize]
 	}
 	vm.txs = vm.txs[len(txs):]
+	vm.metrics.SetMempoolTxs(len(vm.txs))
 
 	builder := Builder{
 		NetworkID: 0,
@@ -191,9 +206,56 @@ func (vm *VM) SetPreference(preferred ids.ID) { vm.preferred = preferred }
 // LastAccepted returns the last accepted block ID
 func (vm *VM) LastAccepted() ids.ID { return vm.lastAccepted }
 
+// Header implements the snowman.ProvableChainVM interface
+func (vm *VM) Header(blkID ids.ID) (*smeng.BlockHeader, error) {
+	blk, txIDs, err := vm.acceptedBlock(blkID)
+	if err != nil {
+		return nil, err
+	}
+	return smeng.NewBlockHeader(blkID, blk.ParentID(), txIDs), nil
+}
+
+// Prove implements the snowman.ProvableChainVM interface
+func (vm *VM) Prove(blkID, txID ids.ID) (*smeng.InclusionProof, error) {
+	blk, txIDs, err := vm.acceptedBlock(blkID)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range txIDs {
+		if id.Equals(txID) {
+			return smeng.NewInclusionProof(smeng.NewBlockHeader(blkID, blk.ParentID(), txIDs), txIDs, i)
+		}
+	}
+	return nil, errTxNotInBlock
+}
+
+// acceptedBlock returns the accepted block [blkID] and the IDs of its
+// transactions
+func (vm *VM) acceptedBlock(blkID ids.ID) (*Block, []ids.ID, error) {
+	if status, err := vm.state.Status(vm.baseDB, blkID); err != nil {
+		return nil, nil, err
+	} else if status != choices.Accepted {
+		return nil, nil, errNotAccepted
+	}
+	blk, err := vm.state.Block(vm.baseDB, blkID)
+	if err != nil {
+		return nil, nil, err
+	}
+	txIDs := make([]ids.ID, len(blk.txs))
+	for i, tx := range blk.txs {
+		txIDs[i] = tx.ID()
+	}
+	return blk, txIDs, nil
+}
+
+// Metrics implements the snowman.ChainVM interface
+func (vm *VM) Metrics(registerer prometheus.Registerer) error {
+	return vm.metrics.Register(registerer)
+}
+
 // CreateHandlers makes new service objects with references to the vm
 func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
-	newServer := rpc.NewServer()
+	newServer := jsoncodec.NewServer()
 	codec := jsoncodec.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
 	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
@@ -205,7 +267,7 @@ func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
 
 // CreateStaticHandlers makes new service objects without references to the vm
 func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler {
-	newServer := rpc.NewServer()
+	newServer := jsoncodec.NewServer()
 	codec := jsoncodec.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
 	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
@@ -299,6 +361,7 @@ func (vm *VM) issueTx(tx *Tx) {
 	vm.ctx.Log.Verbo("Issuing tx:\n%s", formatting.DumpBytes{Bytes: tx.Bytes()})
 
 	vm.txs = append(vm.txs, tx)
+	vm.metrics.SetMempoolTxs(len(vm.txs))
 	switch {
 	case len(vm.txs) == maxBatchSize:
 		vm.FlushTxs()
@@ -144,3 +144,68 @@ func TestPayments(t *testing.T) {
 		t.Fatalf("Wrong Balance")
 	}
 }
+
+func TestProveTx(t *testing.T) {
+	genesisAccounts := GenesisAccounts()
+
+	codec := Codec{}
+	genesisData, _ := codec.MarshalGenesis(genesisAccounts)
+
+	msgChan := make(chan common.Message, 1)
+	vm := &VM{}
+	if err := vm.Initialize(ctx, memdb.New(), genesisData, msgChan, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	account := vm.GetAccount(vm.baseDB, keys[0].PublicKey().Address())
+	tx0, account, err := account.CreateTx(200, keys[1].PublicKey().Address(), ctx, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx1, _, err := account.CreateTx(300, keys[1].PublicKey().Address(), ctx, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.SetPreference(vm.LastAccepted())
+	vm.issueTx(tx0)
+	vm.issueTx(tx1)
+
+	blk, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.Prove(blk.ID(), tx0.ID()); err == nil {
+		t.Fatalf("Should have failed to prove a transaction in a block that isn't accepted")
+	}
+	if err := blk.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	blk.Accept()
+
+	header, err := vm.Header(blk.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !header.BlockID.Equals(blk.ID()) || !header.ParentID.Equals(blk.Parent().ID()) || header.NumTxs != 2 {
+		t.Fatalf("Wrong header: %+v", header)
+	}
+
+	proof, err := vm.Prove(blk.ID(), tx1.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proof.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if !proof.Header.TxRoot.Equals(header.TxRoot) || proof.Index != 1 {
+		t.Fatalf("Wrong proof: %+v", proof)
+	}
+
+	proof.TxID = tx0.ID()
+	if err := proof.Verify(); err == nil {
+		t.Fatalf("Proof of another transaction should be invalid")
+	}
+	if _, err := vm.Prove(blk.ID(), ids.Empty); err == nil {
+		t.Fatalf("Should have failed to prove a transaction that isn't in the block")
+	}
+}
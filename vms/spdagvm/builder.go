@@ -105,8 +105,9 @@ func (b Builder) NewTxFromUTXOs(keyChain *KeyChain, utxos []*UTXO, amount, txFee
 		utxo := utxos[i]
 		if in, signer, err := keyChain.Spend(utxo, currentTime); err == nil {
 			ins = append(ins, in)
-			amount := in.(*InputPayment).Amount()
-			spent += amount
+			if spent, err = math.Add64(spent, in.(*InputPayment).Amount()); err != nil {
+				return nil, errInputOverflow
+			}
 			signers = append(signers, signer)
 		}
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/json"
 )
 
 var (
@@ -43,6 +44,31 @@ func (service *Service) IssueTx(r *http.Request, args *IssueTxArgs, reply *Issue
 	return nil
 }
 
+// EstimateFeeArgs are arguments for passing into EstimateFee requests
+type EstimateFeeArgs struct {
+	// The transaction to estimate the fee of. Optional, as every transaction
+	// pays the same fee.
+	Tx formatting.CB58 `json:"tx"`
+}
+
+// EstimateFeeReply defines the EstimateFee replies returned from the API
+type EstimateFeeReply struct {
+	Fee json.Uint64 `json:"fee"`
+}
+
+// EstimateFee returns the fee that a transaction must pay if it's issued now
+func (service *Service) EstimateFee(r *http.Request, args *EstimateFeeArgs, reply *EstimateFeeReply) error {
+	service.vm.ctx.Log.Verbo("EstimateFee called")
+
+	if len(args.Tx.Bytes) != 0 {
+		if _, err := (&Codec{}).UnmarshalTx(args.Tx.Bytes); err != nil {
+			return err
+		}
+	}
+	reply.Fee = json.Uint64(service.vm.TxFee)
+	return nil
+}
+
 // GetTxStatusArgs are arguments for GetTxStatus
 type GetTxStatusArgs struct {
 	TxID ids.ID `json:"txID"`
@@ -5,6 +5,7 @@ package spdagvm
 
 import (
 	"errors"
+	"time"
 
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/choices"
@@ -87,7 +88,8 @@ func (tx *UniqueTx) Accept() {
 	}
 
 	// Remove spent UTXOs
-	for _, utxoID := range tx.InputIDs().List() {
+	inputIDs := tx.InputIDs().List()
+	for _, utxoID := range inputIDs {
 		if err := tx.vm.state.SpendUTXO(utxoID); err != nil {
 			tx.vm.ctx.Log.Error("Failed to spend utxo %s due to %s", utxoID, err)
 			return
@@ -95,7 +97,8 @@ func (tx *UniqueTx) Accept() {
 	}
 
 	// Add new UTXOs
-	for _, utxo := range tx.utxos() {
+	utxos := tx.utxos()
+	for _, utxo := range utxos {
 		if err := tx.vm.state.FundUTXO(utxo); err != nil {
 			tx.vm.ctx.Log.Error("Failed to fund utxo %s due to %s", utxoID, err)
 			return
@@ -111,6 +114,7 @@ func (tx *UniqueTx) Accept() {
 	if err := tx.vm.db.Commit(); err != nil {
 		tx.vm.ctx.Log.Error("Failed to commit accept %s due to %s", tx.txID, err)
 	}
+	tx.vm.metrics.Accepted(1, len(utxos), len(inputIDs))
 
 	tx.t.deps = nil // Needed to prevent a memory leak
 }
@@ -133,6 +137,7 @@ func (tx *UniqueTx) Reject() {
 	if err := tx.vm.db.Commit(); err != nil {
 		tx.vm.ctx.Log.Error("Failed to commit reject %s due to %s", tx.txID, err)
 	}
+	tx.vm.metrics.Rejected(1)
 
 	tx.t.deps = nil // Needed to prevent a memory leak
 }
@@ -223,13 +228,14 @@ func (tx *UniqueTx) VerifyState() error {
 	}
 
 	tx.t.verifiedState = true
+	defer tx.vm.metrics.Verified(time.Now())
 
-	time := tx.vm.clock.Unix()
+	now := tx.vm.clock.Unix()
 	for _, in := range tx.t.tx.ins {
 		// Tx is spending spent/non-existent utxo
 		// Tx input doesn't unlock output
 		if utxo, err := tx.vm.state.UTXO(in.InputID()); err == nil {
-			if err := utxo.Out().Unlock(in, time); err != nil {
+			if err := utxo.Out().Unlock(in, now); err != nil {
 				tx.t.validity = err
 				break
 			}
@@ -248,7 +254,7 @@ func (tx *UniqueTx) VerifyState() error {
 			tx.t.validity = errMissingUTXO
 		} else if uint32(len(parent.t.tx.outs)) <= inputIndex {
 			tx.t.validity = errInvalidUTXO
-		} else if err := parent.t.tx.outs[int(inputIndex)].Unlock(in, time); err != nil {
+		} else if err := parent.t.tx.outs[int(inputIndex)].Unlock(in, now); err != nil {
 			tx.t.validity = err
 		} else {
 			continue
@@ -9,7 +9,7 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/gorilla/rpc/v2"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/cache"
 	"github.com/ava-labs/gecko/database"
@@ -23,6 +23,8 @@ import (
 	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/math"
 	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/vms/components/address"
+	"github.com/ava-labs/gecko/vms/components/metrics"
 
 	jsoncodec "github.com/ava-labs/gecko/utils/json"
 )
@@ -41,6 +43,7 @@ var (
 	errAsset           = errors.New("assetID must be blank")
 	errAmountOverflow  = errors.New("the amount of this transaction plus the transaction fee overflows")
 	errUnsupportedFXs  = errors.New("unsupported feature extensions")
+	errChainAddress    = errors.New("addresses aren't prefixed with a chain alias")
 )
 
 // VM implements the avalanche.DAGVM interface
@@ -51,6 +54,8 @@ type VM struct {
 	// Used to check local time
 	clock timer.Clock
 
+	metrics metrics.Metrics
+
 	// State management
 	state *prefixedState
 
@@ -92,6 +97,7 @@ func (vm *VM) Initialize(
 		return errUnsupportedFXs
 	}
 	vm.ctx = ctx
+	vm.metrics.Initialize(ctx.Namespace)
 	vm.baseDB = db
 	vm.db = versiondb.New(db)
 	vm.state = &prefixedState{
@@ -136,9 +142,24 @@ func (vm *VM) Shutdown() {
 	}
 }
 
+// ClearCaches implements the common.CachingVM interface. Unique transactions
+// aren't removed, as consensus may hold references to them.
+func (vm *VM) ClearCaches() {
+	vm.state.state.c.Flush()
+	vm.state.tx.Flush()
+	vm.state.utxo.Flush()
+	vm.state.txStatus.Flush()
+	vm.state.funds.Flush()
+}
+
+// Metrics implements the avalanche.DAGVM interface
+func (vm *VM) Metrics(registerer prometheus.Registerer) error {
+	return vm.metrics.Register(registerer)
+}
+
 // CreateHandlers makes new service objects with references to the vm
 func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
-	newServer := rpc.NewServer()
+	newServer := jsoncodec.NewServer()
 	codec := jsoncodec.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
 	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
@@ -150,7 +171,7 @@ func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
 
 // CreateStaticHandlers makes new service objects without references to the vm
 func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler {
-	newServer := rpc.NewServer()
+	newServer := jsoncodec.NewServer()
 	codec := jsoncodec.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
 	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
@@ -169,6 +190,7 @@ func (vm *VM) PendingTxs() []snowstorm.Tx {
 
 	vm.txs = nil
 	vm.timer.Cancel()
+	vm.metrics.SetMempoolTxs(0)
 
 	return txs
 }
@@ -232,6 +254,18 @@ func (vm *VM) GetAddress(privKeyStr string) (string, error) {
 	return pk.PublicKey().Address().String(), nil
 }
 
+// parseAddress returns the address [addrStr], which is in CB58 or bech32
+func (vm *VM) parseAddress(addrStr string) (ids.ShortID, error) {
+	chainAlias, addr, err := address.ParseAny(addrStr, vm.ctx.NetworkID)
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	if chainAlias != "" {
+		return ids.ShortID{}, errChainAddress
+	}
+	return addr, nil
+}
+
 // GetBalance returns [address]'s balance of the asset whose
 // ID is [assetID]
 func (vm *VM) GetBalance(address, assetID string) (uint64, error) {
@@ -240,7 +274,7 @@ func (vm *VM) GetBalance(address, assetID string) (uint64, error) {
 	}
 
 	// Parse the string repr. of the address to an ids.ShortID
-	addr, err := ids.ShortFromString(address)
+	addr, err := vm.parseAddress(address)
 	if err != nil {
 		return 0, err
 	}
@@ -333,7 +367,7 @@ func (vm *VM) Send(amount uint64, assetID, toAddrStr string, fromPKs []string) (
 	}
 
 	// Parse [toAddrStr] to an ids.ShortID
-	toAddr, err := ids.ShortFromString(toAddrStr)
+	toAddr, err := vm.parseAddress(toAddrStr)
 	if err != nil {
 		return "", err
 	}
@@ -378,7 +412,7 @@ func (vm *VM) Send(amount uint64, assetID, toAddrStr string, fromPKs []string) (
 // GetTxHistory takes an address and returns an ordered list of known records containing
 // key-value pairs of data.
 func (vm *VM) GetTxHistory(address string) (string, []string, map[string]string, []map[string]string, error) {
-	addr, err := ids.ShortFromString(address)
+	addr, err := vm.parseAddress(address)
 	if err != nil {
 		return "", nil, nil, nil, err
 	}
@@ -540,6 +574,7 @@ func (vm *VM) wrapTx(rawTx *Tx, finalized func(choices.Status)) (*UniqueTx, erro
 
 func (vm *VM) issueTx(tx snowstorm.Tx) {
 	vm.txs = append(vm.txs, tx)
+	vm.metrics.SetMempoolTxs(len(vm.txs))
 	switch {
 	// Flush the transactions if enough transactions are waiting
 	case len(vm.txs) == batchSize:
@@ -24,6 +24,8 @@ type Block struct {
 	*core.Block `serialize:"true"`
 	Data        [dataLen]byte `serialize:"true"`
 	Timestamp   int64         `serialize:"true"`
+
+	vm *VM
 }
 
 // Verify returns nil iff this block is valid.
@@ -33,6 +35,7 @@ func (b *Block) Verify() error {
 	if accepted, err := b.Block.Verify(); err != nil || accepted {
 		return err
 	}
+	defer b.VM.VMMetrics.Verified(time.Now())
 
 	// Get [b]'s parent
 	parent, ok := b.Parent().(*Block)
@@ -52,3 +55,31 @@ func (b *Block) Verify() error {
 	b.VM.SaveBlock(b.VM.DB, b)
 	return b.VM.DB.Commit()
 }
+
+// Accept sets this block's status to Accepted, indexes it by height and
+// publishes it to the subscribers of accepted blocks
+func (b *Block) Accept() {
+	b.Block.Accept()
+	b.VM.VMMetrics.Accepted(1, 0, 0)
+
+	height, err := b.vm.childHeight(b.ParentID())
+	if err != nil {
+		b.vm.Ctx.Log.Error("couldn't get the height of block %s: %s", b.ID(), err)
+		return
+	}
+	if err := b.vm.putHeight(b.ID(), height); err != nil {
+		b.vm.Ctx.Log.Error("couldn't index block %s: %s", b.ID(), err)
+		return
+	}
+	if err := b.vm.DB.Commit(); err != nil {
+		b.vm.Ctx.Log.Error("couldn't commit block %s: %s", b.ID(), err)
+		return
+	}
+	b.vm.pubsub.Publish("accepted", newAPIBlock(b, height))
+}
+
+// Reject sets this block's status to Rejected
+func (b *Block) Reject() {
+	b.Block.Reject()
+	b.VM.VMMetrics.Rejected(1)
+}
@@ -5,17 +5,29 @@ package timestampvm
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/ava-labs/gecko/ids"
 
 	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+const (
+	// defaultRecentBlocks is the number of blocks GetRecentBlocks returns if
+	// no limit is given
+	defaultRecentBlocks = 10
+
+	// maxRecentBlocks is the most blocks GetRecentBlocks returns
+	maxRecentBlocks = 1024
 )
 
 var (
 	errDBError     = errors.New("error getting data from database")
 	errBadData     = errors.New("data must be base 58 repr. of 32 bytes")
 	errNoSuchBlock = errors.New("couldn't get block from database. Does it exist?")
+	errTooManyBlks = fmt.Errorf("at most %d blocks may be fetched at once", maxRecentBlocks)
 )
 
 // Service is the API service for this VM
@@ -50,10 +62,22 @@ func (s *Service) ProposeBlock(_ *http.Request, args *ProposeBlockArgs, reply *P
 
 // APIBlock is the API representation of a block
 type APIBlock struct {
-	Timestamp int64  `json:"timestamp"` // Timestamp of most recent block
-	Data      string `json:"data"`      // Data in the most recent block. Base 58 repr. of 5 bytes.
-	ID        string `json:"id"`        // String repr. of ID of the most recent block
-	ParentID  string `json:"parentID"`  // String repr. of ID of the most recent block's parent
+	Timestamp int64       `json:"timestamp"` // Timestamp of most recent block
+	Data      string      `json:"data"`      // Data in the most recent block. Base 58 repr. of 5 bytes.
+	ID        string      `json:"id"`        // String repr. of ID of the most recent block
+	ParentID  string      `json:"parentID"`  // String repr. of ID of the most recent block's parent
+	Height    json.Uint64 `json:"height"`    // Number of blocks between this block and the genesis block
+}
+
+func newAPIBlock(block *Block, height uint64) APIBlock {
+	byteFormatter := formatting.CB58{Bytes: block.Data[:]}
+	return APIBlock{
+		Timestamp: block.Timestamp,
+		Data:      byteFormatter.String(),
+		ID:        block.ID().String(),
+		ParentID:  block.ParentID().String(),
+		Height:    json.Uint64(height),
+	}
 }
 
 // GetBlockArgs are the arguments to GetBlock
@@ -92,11 +116,77 @@ func (s *Service) GetBlock(_ *http.Request, args *GetBlockArgs, reply *GetBlockR
 		return errBadData
 	}
 
-	reply.APIBlock.ID = block.ID().String()
-	reply.APIBlock.Timestamp = block.Timestamp
-	reply.APIBlock.ParentID = block.ParentID().String()
-	byteFormatter := formatting.CB58{Bytes: block.Data[:]}
-	reply.Data = byteFormatter.String()
+	height, err := s.vm.height(block)
+	if err != nil {
+		return err
+	}
+	reply.APIBlock = newAPIBlock(block, height)
+	return nil
+}
+
+// GetBlockByHeightArgs are the arguments to GetBlockByHeight
+type GetBlockByHeightArgs struct {
+	// Height of the accepted block we're getting. The genesis block's height is 0.
+	Height json.Uint64 `json:"height"`
+}
+
+// GetBlockByHeight gets the accepted block at height [args.Height]
+func (s *Service) GetBlockByHeight(_ *http.Request, args *GetBlockByHeightArgs, reply *GetBlockReply) error {
+	ID, err := s.vm.getAcceptedID(uint64(args.Height))
+	if err != nil {
+		return errNoSuchBlock
+	}
 
+	block, err := s.vm.getBlock(ID)
+	if err != nil {
+		return errDatabase
+	}
+
+	reply.APIBlock = newAPIBlock(block, uint64(args.Height))
+	return nil
+}
+
+// GetRecentBlocksArgs are the arguments to GetRecentBlocks
+type GetRecentBlocksArgs struct {
+	// Number of blocks to get. If 0, a default is used.
+	Limit json.Uint32 `json:"limit"`
+}
+
+// GetRecentBlocksReply is the reply from GetRecentBlocks
+type GetRecentBlocksReply struct {
+	// The most recently accepted blocks, newest first
+	Blocks []APIBlock `json:"blocks"`
+}
+
+// GetRecentBlocks gets the [args.Limit] most recently accepted blocks
+func (s *Service) GetRecentBlocks(_ *http.Request, args *GetRecentBlocksArgs, reply *GetRecentBlocksReply) error {
+	limit := int(args.Limit)
+	switch {
+	case limit == 0:
+		limit = defaultRecentBlocks
+	case limit > maxRecentBlocks:
+		return errTooManyBlks
+	}
+
+	ID := s.vm.LastAccepted()
+	height, err := s.vm.getHeight(ID)
+	if err != nil {
+		return errDatabase
+	}
+
+	reply.Blocks = make([]APIBlock, 0, limit)
+	for len(reply.Blocks) < limit {
+		block, err := s.vm.getBlock(ID)
+		if err != nil {
+			return errDatabase
+		}
+		reply.Blocks = append(reply.Blocks, newAPIBlock(block, height))
+
+		if height == 0 { // the genesis block has no parent
+			break
+		}
+		ID = block.ParentID()
+		height--
+	}
 	return nil
 }
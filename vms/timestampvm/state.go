@@ -0,0 +1,122 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Accepted blocks are indexed by height under these prefixes so that the index
+// doesn't collide with the blocks and statuses kept by core.SnowmanVM
+var (
+	heightPrefix  = []byte("height")  // height -> block ID
+	blockIDPrefix = []byte("blockID") // block ID -> height
+)
+
+// getAcceptedID returns the ID of the accepted block at [height]
+func (vm *VM) getAcceptedID(height uint64) (ids.ID, error) {
+	idBytes, err := prefixdb.New(heightPrefix, vm.DB).Get(heightKey(height))
+	if err != nil {
+		return ids.ID{}, err
+	}
+	return ids.ToID(idBytes)
+}
+
+// getHeight returns the height of the accepted block [blkID]
+func (vm *VM) getHeight(blkID ids.ID) (uint64, error) {
+	heightBytes, err := prefixdb.New(blockIDPrefix, vm.DB).Get(blkID.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	p := wrappers.Packer{Bytes: heightBytes}
+	height := p.UnpackLong()
+	return height, p.Err
+}
+
+// putHeight indexes the accepted block [blkID] at [height]
+func (vm *VM) putHeight(blkID ids.ID, height uint64) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		prefixdb.New(heightPrefix, vm.DB).Put(heightKey(height), blkID.Bytes()),
+		prefixdb.New(blockIDPrefix, vm.DB).Put(blkID.Bytes(), heightKey(height)),
+	)
+	return errs.Err
+}
+
+// indexHeights indexes the accepted blocks that aren't indexed yet, which is
+// all of them if the chain was created before blocks were indexed
+func (vm *VM) indexHeights() error {
+	unindexed := []*Block(nil)
+	blkID := vm.LastAccepted()
+	for !blkID.Equals(ids.Empty) {
+		if _, err := vm.getHeight(blkID); err == nil {
+			break
+		} else if err != database.ErrNotFound {
+			return err
+		}
+		blk, err := vm.getBlock(blkID)
+		if err != nil {
+			return err
+		}
+		unindexed = append(unindexed, blk)
+		blkID = blk.ParentID()
+	}
+
+	// Index from the oldest block to the newest
+	for i := len(unindexed) - 1; i >= 0; i-- {
+		blk := unindexed[i]
+		height, err := vm.childHeight(blk.ParentID())
+		if err != nil {
+			return err
+		}
+		if err := vm.putHeight(blk.ID(), height); err != nil {
+			return err
+		}
+	}
+	return vm.DB.Commit()
+}
+
+// childHeight returns the height of a child of the accepted block [parentID]
+func (vm *VM) childHeight(parentID ids.ID) (uint64, error) {
+	if parentID.Equals(ids.Empty) {
+		return 0, nil // the genesis block has no parent
+	}
+	parentHeight, err := vm.getHeight(parentID)
+	if err != nil {
+		return 0, err
+	}
+	return parentHeight + 1, nil
+}
+
+// height returns the height of [blk], which may not be accepted yet
+func (vm *VM) height(blk *Block) (uint64, error) {
+	// Find the nearest ancestor that is indexed
+	unindexed := uint64(0)
+	for {
+		height, err := vm.getHeight(blk.ID())
+		if err == nil {
+			return height + unindexed, nil
+		}
+		if err != database.ErrNotFound {
+			return 0, err
+		}
+		unindexed++
+
+		if blk.ParentID().Equals(ids.Empty) {
+			return unindexed - 1, nil // the genesis block
+		}
+		if blk, err = vm.getBlock(blk.ParentID()); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func heightKey(height uint64) []byte {
+	p := wrappers.Packer{MaxSize: wrappers.LongLen}
+	p.PackLong(height)
+	return p.Bytes
+}
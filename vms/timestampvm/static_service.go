@@ -0,0 +1,58 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"net/http"
+
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+// StaticService is the static API service for this VM
+type StaticService struct{}
+
+// BuildGenesisArgs are the arguments to BuildGenesis
+type BuildGenesisArgs struct {
+	// Data in the genesis block. At most 32 bytes.
+	Data formatting.CB58 `json:"data"`
+}
+
+// BuildGenesisReply is the reply from BuildGenesis
+type BuildGenesisReply struct {
+	Bytes formatting.CB58 `json:"bytes"`
+}
+
+// BuildGenesis returns the genesis bytes of a chain whose genesis block has
+// the data [args].Data
+func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, reply *BuildGenesisReply) error {
+	if len(args.Data.Bytes) > dataLen {
+		return errBadGenesisBytes
+	}
+	reply.Bytes.Bytes = args.Data.Bytes
+	return nil
+}
+
+// ParseGenesisArgs are the arguments to ParseGenesis
+type ParseGenesisArgs struct {
+	Bytes formatting.CB58 `json:"bytes"`
+}
+
+// ParseGenesisReply is the reply from ParseGenesis
+type ParseGenesisReply struct {
+	// Data in the genesis block
+	Data formatting.CB58 `json:"data"`
+}
+
+// ParseGenesis returns the data of the genesis block of a chain with the
+// genesis bytes [args].Bytes
+func (*StaticService) ParseGenesis(_ *http.Request, args *ParseGenesisArgs, reply *ParseGenesisReply) error {
+	if len(args.Bytes.Bytes) > dataLen {
+		return errBadGenesisBytes
+	}
+	// The genesis block's data is the genesis bytes, padded to [dataLen]
+	data := [dataLen]byte{}
+	copy(data[:], args.Bytes.Bytes)
+	reply.Data.Bytes = data[:]
+	return nil
+}
@@ -0,0 +1,38 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timestampvm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+func TestStaticServiceGenesis(t *testing.T) {
+	ss := StaticService{}
+
+	buildReply := BuildGenesisReply{}
+	if err := ss.BuildGenesis(nil, &BuildGenesisArgs{Data: formatting.CB58{Bytes: []byte{1, 2, 3}}}, &buildReply); err != nil {
+		t.Fatal(err)
+	}
+
+	parseReply := ParseGenesisReply{}
+	if err := ss.ParseGenesis(nil, &ParseGenesisArgs{Bytes: buildReply.Bytes}, &parseReply); err != nil {
+		t.Fatal(err)
+	}
+	expected := make([]byte, dataLen)
+	copy(expected, []byte{1, 2, 3})
+	if !bytes.Equal(parseReply.Data.Bytes, expected) {
+		t.Fatalf("Wrong genesis data: %v", parseReply.Data.Bytes)
+	}
+
+	tooLong := formatting.CB58{Bytes: make([]byte, dataLen+1)}
+	if err := ss.BuildGenesis(nil, &BuildGenesisArgs{Data: tooLong}, &buildReply); err == nil {
+		t.Fatalf("Should have failed to build genesis with too much data")
+	}
+	if err := ss.ParseGenesis(nil, &ParseGenesisArgs{Bytes: tooLong}, &parseReply); err == nil {
+		t.Fatalf("Should have failed to parse genesis with too much data")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/snow/engine/common"
+	cjson "github.com/ava-labs/gecko/utils/json"
 	"github.com/ava-labs/gecko/vms/components/codec"
 	"github.com/ava-labs/gecko/vms/components/core"
 )
@@ -21,6 +22,7 @@ const dataLen = 32
 var (
 	errNoPendingBlocks = errors.New("there is no block to propose")
 	errBadGenesisBytes = errors.New("genesis data should be bytes (max length 32)")
+	errBlockNotFound   = errors.New("block not found")
 )
 
 // VM implements the snowman.VM interface
@@ -31,6 +33,10 @@ type VM struct {
 	codec codec.Codec
 	// Proposed pieces of data that haven't been put into a block and proposed yet
 	mempool [][dataLen]byte
+	// Tells the consensus engine when the mempool has data to put into blocks
+	trigger core.BuildTrigger
+	// Publishes accepted blocks to subscribers
+	pubsub *cjson.PubSubServer
 }
 
 // Initialize this vm
@@ -51,6 +57,12 @@ func (vm *VM) Initialize(
 		return err
 	}
 	vm.codec = codec.NewDefault()
+	vm.trigger.Initialize(ctx.Log, toEngine, 0)
+
+	vm.pubsub = cjson.NewPubSubServer(ctx)
+	if err := vm.pubsub.Register("accepted"); err != nil {
+		return err
+	}
 
 	// If database is empty, create it using the provided genesis data
 	if !vm.DBInitialized() {
@@ -88,40 +100,65 @@ func (vm *VM) Initialize(
 			return err
 		}
 	}
+
+	// Index the blocks accepted before blocks were indexed by height
+	if err := vm.indexHeights(); err != nil {
+		vm.Ctx.Log.Error("error while indexing blocks: %v", err)
+		return err
+	}
 	return nil
 }
 
+// Shutdown this vm
+func (vm *VM) Shutdown() {
+	vm.trigger.Shutdown()
+	vm.SnowmanVM.Shutdown()
+}
+
 // CreateHandlers returns a map where:
 // Keys: The path extension for this VM's API (empty in this case)
 // Values: The handler for the API
 func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
 	handler := vm.NewHandler("timestamp", &Service{vm})
 	return map[string]*common.HTTPHandler{
-		"": handler,
+		"":        handler,
+		"/pubsub": &common.HTTPHandler{LockOptions: common.NoLock, Handler: vm.pubsub},
 	}
 }
 
 // CreateStaticHandlers returns a map where:
-// Keys: The path extension for this VM's static API
+// Keys: The path extension for this VM's static API (empty in this case)
 // Values: The handler for that static API
-// We return nil because this VM has no static API
-func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler { return nil }
+func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler {
+	handler := vm.NewHandler("timestamp", &StaticService{})
+	return map[string]*common.HTTPHandler{
+		"": handler,
+	}
+}
+
+// VerifyGenesis implements the common.GenesisVerifier interface
+func (vm *VM) VerifyGenesis(genesisData []byte) error {
+	if len(genesisData) > dataLen {
+		return errBadGenesisBytes
+	}
+	return nil
+}
 
 // BuildBlock returns a block that this vm wants to add to consensus
 func (vm *VM) BuildBlock() (snowman.Block, error) {
 	if len(vm.mempool) == 0 { // There is no block to be built
+		vm.trigger.BuiltBlock(false)
 		return nil, errNoPendingBlocks
 	}
 
 	// Get the value to put in the new block
 	value := vm.mempool[0]
 	vm.mempool = vm.mempool[1:]
+	vm.VMMetrics.SetMempoolTxs(len(vm.mempool))
 
 	// Notify consensus engine that there are more pending data for blocks
-	// (if that is the case) when done building this block
-	if len(vm.mempool) > 0 {
-		defer vm.NotifyBlockReady()
-	}
+	// (if that is the case)
+	vm.trigger.BuiltBlock(len(vm.mempool) > 0)
 
 	// Build the block
 	block, err := vm.NewBlock(vm.Preferred(), value, time.Now())
@@ -137,7 +174,8 @@ func (vm *VM) BuildBlock() (snowman.Block, error) {
 // (namely, a block with data [data])
 func (vm *VM) proposeBlock(data [dataLen]byte) {
 	vm.mempool = append(vm.mempool, data)
-	vm.NotifyBlockReady()
+	vm.VMMetrics.SetMempoolTxs(len(vm.mempool))
+	vm.trigger.NotifyBuildBlock()
 }
 
 // ParseBlock parses [bytes] to a snowman.Block
@@ -146,9 +184,22 @@ func (vm *VM) ParseBlock(bytes []byte) (snowman.Block, error) {
 	block := &Block{}
 	err := vm.codec.Unmarshal(bytes, block)
 	block.Initialize(bytes, &vm.SnowmanVM)
+	block.vm = vm
 	return block, err
 }
 
+// getBlock returns the block with ID [blkID]
+func (vm *VM) getBlock(blkID ids.ID) (*Block, error) {
+	blkInterface, err := vm.GetBlock(blkID)
+	if err != nil {
+		return nil, err
+	}
+	if blk, ok := blkInterface.(*Block); ok {
+		return blk, nil
+	}
+	return nil, errBlockNotFound
+}
+
 // NewBlock returns a new Block where:
 // - the block's parent is [parentID]
 // - the block's data is [data]
@@ -159,6 +210,7 @@ func (vm *VM) NewBlock(parentID ids.ID, data [dataLen]byte, timestamp time.Time)
 		Block:     core.NewBlock(parentID),
 		Data:      data,
 		Timestamp: timestamp.Unix(),
+		vm:        vm,
 	}
 
 	blockBytes, err := vm.codec.Marshal(block)
@@ -8,10 +8,12 @@ import (
 	"testing"
 
 	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/json"
 )
 
 var blockchainID = ids.NewID([32]byte{1, 2, 3})
@@ -207,3 +209,140 @@ func TestService(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestServiceBlocksByHeight(t *testing.T) {
+	// Initialize the vm
+	db := memdb.New()
+	msgChan := make(chan common.Message, 1)
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, db, []byte{0, 0, 0, 0, 0}, msgChan, nil); err != nil {
+		t.Fatal(err)
+	}
+	genesisID := vm.LastAccepted()
+	vm.SetPreference(genesisID)
+
+	// Accept two blocks on top of the genesis block
+	blkIDs := []ids.ID{genesisID}
+	for i := byte(1); i <= 2; i++ {
+		vm.proposeBlock([dataLen]byte{i})
+		<-msgChan
+		block, err := vm.BuildBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := block.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		block.Accept()
+		vm.SetPreference(block.ID())
+		blkIDs = append(blkIDs, block.ID())
+	}
+
+	service := Service{vm}
+	for height, blkID := range blkIDs {
+		reply := GetBlockReply{}
+		if err := service.GetBlockByHeight(nil, &GetBlockByHeightArgs{Height: json.Uint64(height)}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.ID != blkID.String() {
+			t.Fatalf("block at height %d should be %s but is %s", height, blkID, reply.ID)
+		}
+		if reply.Height != json.Uint64(height) {
+			t.Fatalf("expected height %d but got %d", height, reply.Height)
+		}
+
+		reply = GetBlockReply{}
+		if err := service.GetBlock(nil, &GetBlockArgs{ID: blkID.String()}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Height != json.Uint64(height) {
+			t.Fatalf("expected height %d but got %d", height, reply.Height)
+		}
+	}
+	if err := service.GetBlockByHeight(nil, &GetBlockByHeightArgs{Height: 3}, &GetBlockReply{}); err == nil {
+		t.Fatal("should have failed to get a block above the last accepted block")
+	}
+
+	// A processing block's height follows its parent's
+	vm.proposeBlock([dataLen]byte{3})
+	<-msgChan
+	block, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := block.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	reply := GetBlockReply{}
+	if err := service.GetBlock(nil, &GetBlockArgs{ID: block.ID().String()}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Height != 3 {
+		t.Fatalf("expected height 3 but got %d", reply.Height)
+	}
+
+	// The most recent blocks are returned newest first, ending at genesis
+	recentReply := GetRecentBlocksReply{}
+	if err := service.GetRecentBlocks(nil, &GetRecentBlocksArgs{Limit: 2}, &recentReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(recentReply.Blocks) != 2 || recentReply.Blocks[0].ID != blkIDs[2].String() || recentReply.Blocks[1].ID != blkIDs[1].String() {
+		t.Fatalf("unexpected recent blocks: %v", recentReply.Blocks)
+	}
+	recentReply = GetRecentBlocksReply{}
+	if err := service.GetRecentBlocks(nil, &GetRecentBlocksArgs{}, &recentReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(recentReply.Blocks) != len(blkIDs) {
+		t.Fatalf("expected %d blocks but got %d", len(blkIDs), len(recentReply.Blocks))
+	}
+	if err := service.GetRecentBlocks(nil, &GetRecentBlocksArgs{Limit: maxRecentBlocks + 1}, &recentReply); err == nil {
+		t.Fatal("should have failed to get too many blocks")
+	}
+}
+
+func TestIndexExistingChain(t *testing.T) {
+	db := memdb.New()
+	msgChan := make(chan common.Message, 1)
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = blockchainID
+	if err := vm.Initialize(ctx, db, []byte{0, 0, 0, 0, 0}, msgChan, nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.SetPreference(vm.LastAccepted())
+	vm.proposeBlock([dataLen]byte{1})
+	<-msgChan
+	block, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := block.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	block.Accept()
+
+	// Remove the index, as if the chain was created before blocks were indexed
+	for _, prefix := range [][]byte{heightPrefix, blockIDPrefix} {
+		prefixedDB := prefixdb.New(prefix, db)
+		iter := prefixedDB.NewIterator()
+		for iter.Next() {
+			if err := prefixedDB.Delete(iter.Key()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		iter.Release()
+	}
+
+	vm = &VM{}
+	if err := vm.Initialize(ctx, db, []byte{0, 0, 0, 0, 0}, msgChan, nil); err != nil {
+		t.Fatal(err)
+	}
+	if height, err := vm.getHeight(block.ID()); err != nil {
+		t.Fatal(err)
+	} else if height != 1 {
+		t.Fatalf("expected height 1 but got %d", height)
+	}
+}
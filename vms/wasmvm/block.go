@@ -0,0 +1,200 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasmvm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/components/missing"
+)
+
+var (
+	errNoTxs         = errors.New("block has no transactions")
+	errUnknownParent = errors.New("block's parent is unknown")
+)
+
+// Block is a block on this chain. Each block contains a batch of transactions,
+// which are executed in order when the block is verified.
+type Block struct {
+	*core.Block `serialize:"true"`
+
+	Txs []Tx `serialize:"true"`
+
+	vm *VM
+
+	// This block's parent.
+	// nil before parentBlock() is called on this block
+	parent *Block
+
+	// This block's children
+	children []*Block
+
+	// state of the chain if this block is accepted
+	onAcceptDB *versiondb.Database
+}
+
+// initialize this block's non-serialized fields
+func (b *Block) initialize(vm *VM, bytes []byte) error {
+	b.vm = vm
+	b.Block.Initialize(bytes, &vm.SnowmanVM)
+	for _, tx := range b.Txs {
+		if err := tx.initialize(vm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify executes the transactions in this block on top of the state of the
+// chain if its parent is accepted. Execution failing isn't an error; the
+// failure is recorded as the transaction's result.
+func (b *Block) Verify() error {
+	if accepted, err := b.Block.Verify(); err != nil || accepted {
+		return err
+	}
+	if len(b.Txs) == 0 {
+		return errNoTxs
+	}
+	defer b.vm.VMMetrics.Verified(time.Now())
+
+	parent := b.parentBlock()
+	if parent == nil {
+		return errUnknownParent
+	}
+
+	b.onAcceptDB = versiondb.New(parent.onAccept())
+	for _, tx := range b.Txs {
+		if err := tx.SyntacticVerify(); err != nil {
+			return err
+		}
+		// A tx may only be executed once
+		if _, err := b.vm.getResult(b.onAcceptDB, tx.ID()); err == nil {
+			return errDuplicateTx
+		}
+		result, err := tx.execute(b.onAcceptDB)
+		if err != nil {
+			return err
+		}
+		if err := b.vm.putResult(b.onAcceptDB, tx.ID(), result); err != nil {
+			return err
+		}
+	}
+
+	b.vm.currentBlocks[b.ID().Key()] = b
+	parent.addChild(b)
+	return nil
+}
+
+// onAccept returns the state of the chain if this block is accepted.
+// This function should only be called after Verify is called.
+func (b *Block) onAccept() database.Database {
+	if b.Status().Decided() {
+		return b.vm.DB
+	}
+	return b.onAcceptDB
+}
+
+// Accept implements the snowman.Block interface
+func (b *Block) Accept() {
+	b.vm.Ctx.Log.Verbo("Accepting block with ID %s", b.ID())
+
+	b.Block.Accept()
+	b.vm.VMMetrics.Accepted(len(b.Txs), 0, 0)
+
+	// Update the state of the chain in the database
+	if err := b.onAcceptDB.Commit(); err != nil {
+		b.vm.Ctx.Log.Warn("unable to commit onAcceptDB")
+	}
+	if err := b.vm.DB.Commit(); err != nil {
+		b.vm.Ctx.Log.Warn("unable to commit vm's DB")
+	}
+
+	for _, child := range b.children {
+		child.setBaseDatabase(b.vm.DB)
+	}
+
+	// remove this block and its parent from memory
+	if parent := b.parentBlock(); parent != nil {
+		parent.free()
+	}
+	b.free()
+}
+
+// Reject implements the snowman.Block interface
+func (b *Block) Reject() {
+	defer b.free() // remove this block from memory
+
+	b.Block.Reject()
+	b.vm.VMMetrics.Rejected(len(b.Txs))
+}
+
+// Parent returns this block's parent
+func (b *Block) Parent() snowman.Block {
+	if parent := b.parentBlock(); parent != nil {
+		return parent
+	}
+	return &missing.Block{BlkID: b.ParentID()}
+}
+
+// parentBlock returns this block's parent, or nil if it isn't known
+func (b *Block) parentBlock() *Block {
+	// Check if the block already has a reference to its parent
+	if b.parent != nil {
+		return b.parent
+	}
+
+	// Get the parent from database
+	parentID := b.ParentID()
+	if parentID.Equals(ids.Empty) {
+		return nil // the genesis block has no parent
+	}
+	parent, err := b.vm.getBlock(parentID)
+	if err != nil {
+		b.vm.Ctx.Log.Warn("could not get parent (ID %s) of block %s", parentID, b.ID())
+		return nil
+	}
+	b.parent = parent
+	return parent
+}
+
+// addChild adds [child] as a child of this block. When this block is accepted,
+// the child's state is rebased onto the vm's database so that the database
+// versions don't recurse the length of the chain.
+func (b *Block) addChild(child *Block) { b.children = append(b.children, child) }
+
+// free removes this block from memory
+func (b *Block) free() {
+	delete(b.vm.currentBlocks, b.ID().Key())
+	b.parent = nil
+	b.children = nil
+}
+
+// setBaseDatabase sets the database underlying this block's state to [db]
+func (b *Block) setBaseDatabase(db database.Database) {
+	if err := b.onAcceptDB.SetDatabase(db); err != nil {
+		b.vm.Ctx.Log.Error("problem while setting base database: %s", err)
+	}
+}
+
+// newBlock returns a new block, whose parent has ID [parentID], containing
+// [txs]
+func (vm *VM) newBlock(parentID ids.ID, txs []Tx) (*Block, error) {
+	blk := &Block{
+		Block: core.NewBlock(parentID),
+		Txs:   txs,
+		vm:    vm,
+	}
+	bytes, err := Codec.Marshal(blk)
+	if err != nil {
+		return nil, err
+	}
+	blk.Block.Initialize(bytes, &vm.SnowmanVM)
+	return blk, nil
+}
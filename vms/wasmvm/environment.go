@@ -0,0 +1,228 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasmvm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/wasmvm/wasm"
+)
+
+// Gas consumed by the host functions, in addition to the gas consumed by the
+// instructions that call them
+const (
+	hostCallGas     = 10
+	storageReadGas  = 100
+	storageWriteGas = 1000
+	byteGas         = 1  // per byte copied into or out of a contract's memory
+	storedByteGas   = 10 // per byte written to storage
+)
+
+// Bounds on the data that a contract may store or return
+const (
+	maxKeySize    = 1 << 8
+	maxValueSize  = 1 << 14
+	maxReturnSize = 1 << 14
+)
+
+var (
+	errKeyTooLarge    = fmt.Errorf("storage keys are limited to %d bytes", maxKeySize)
+	errValueTooLarge  = fmt.Errorf("storage values are limited to %d bytes", maxValueSize)
+	errReturnTooLarge = fmt.Errorf("return values are limited to %d bytes", maxReturnSize)
+	errDatabase       = errors.New("couldn't access the contract's storage")
+)
+
+// environment provides the host functions that a contract may import. All of
+// them are imported from the module "env":
+//   - args_size() -> i32 returns the size of the invocation's arguments.
+//   - args_read(ptr) copies the invocation's arguments to memory at [ptr].
+//   - storage_read(keyPtr, keyLen, valuePtr, valueCap) -> i32 copies at most
+//     [valueCap] bytes of the value stored under the key to memory at
+//     [valuePtr]. It returns the size of the value, or -1 if there isn't one.
+//   - storage_write(keyPtr, keyLen, valuePtr, valueLen) stores the value under
+//     the key.
+//   - storage_delete(keyPtr, keyLen) removes the value stored under the key.
+//   - set_return(ptr, len) sets the value that the invocation returns.
+//
+// All of the parameters and results are i32s.
+type environment struct {
+	storage     database.Database
+	args        []byte
+	returnValue []byte
+
+	// dbErr is set if the contract's storage couldn't be accessed. Unlike the
+	// other errors raised by host functions, this isn't the contract's fault.
+	dbErr error
+}
+
+func (e *environment) imports() wasm.Imports {
+	i32 := wasm.I32
+	return wasm.Imports{"env": {
+		"args_size": {
+			Type: wasm.FuncType{Results: []wasm.ValueType{i32}},
+			Call: e.argsSize,
+		},
+		"args_read": {
+			Type: wasm.FuncType{Params: []wasm.ValueType{i32}},
+			Call: e.argsRead,
+		},
+		"storage_read": {
+			Type: wasm.FuncType{Params: []wasm.ValueType{i32, i32, i32, i32}, Results: []wasm.ValueType{i32}},
+			Call: e.storageRead,
+		},
+		"storage_write": {
+			Type: wasm.FuncType{Params: []wasm.ValueType{i32, i32, i32, i32}},
+			Call: e.storageWrite,
+		},
+		"storage_delete": {
+			Type: wasm.FuncType{Params: []wasm.ValueType{i32, i32}},
+			Call: e.storageDelete,
+		},
+		"set_return": {
+			Type: wasm.FuncType{Params: []wasm.ValueType{i32, i32}},
+			Call: e.setReturn,
+		},
+	}}
+}
+
+func (e *environment) argsSize(instance *wasm.Instance, _ []uint64) ([]uint64, error) {
+	return []uint64{uint64(len(e.args))}, instance.UseGas(hostCallGas)
+}
+
+func (e *environment) argsRead(instance *wasm.Instance, args []uint64) ([]uint64, error) {
+	if err := instance.UseGas(hostCallGas + byteGas*uint64(len(e.args))); err != nil {
+		return nil, err
+	}
+	return nil, instance.Write(uint32(args[0]), e.args)
+}
+
+func (e *environment) storageRead(instance *wasm.Instance, args []uint64) ([]uint64, error) {
+	key, err := e.readKey(instance, args[0], args[1], storageReadGas)
+	if err != nil {
+		return nil, err
+	}
+	value, err := e.storage.Get(key)
+	if err == database.ErrNotFound {
+		return []uint64{math.MaxUint32}, nil // -1
+	}
+	if err != nil {
+		e.dbErr = err
+		return nil, errDatabase
+	}
+	if err := instance.UseGas(byteGas * uint64(len(value))); err != nil {
+		return nil, err
+	}
+	if valueCap := int(uint32(args[3])); len(value) > valueCap {
+		value = value[:valueCap]
+	}
+	return []uint64{uint64(len(value))}, instance.Write(uint32(args[2]), value)
+}
+
+func (e *environment) storageWrite(instance *wasm.Instance, args []uint64) ([]uint64, error) {
+	valueLen := uint32(args[3])
+	if valueLen > maxValueSize {
+		return nil, errValueTooLarge
+	}
+	key, err := e.readKey(instance, args[0], args[1], storageWriteGas+storedByteGas*uint64(valueLen))
+	if err != nil {
+		return nil, err
+	}
+	value, err := instance.Read(uint32(args[2]), valueLen)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.storage.Put(key, value); err != nil {
+		e.dbErr = err
+		return nil, errDatabase
+	}
+	return nil, nil
+}
+
+func (e *environment) storageDelete(instance *wasm.Instance, args []uint64) ([]uint64, error) {
+	key, err := e.readKey(instance, args[0], args[1], storageWriteGas)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.storage.Delete(key); err != nil {
+		e.dbErr = err
+		return nil, errDatabase
+	}
+	return nil, nil
+}
+
+func (e *environment) setReturn(instance *wasm.Instance, args []uint64) ([]uint64, error) {
+	length := uint32(args[1])
+	if length > maxReturnSize {
+		return nil, errReturnTooLarge
+	}
+	if err := instance.UseGas(hostCallGas + byteGas*uint64(length)); err != nil {
+		return nil, err
+	}
+	value, err := instance.Read(uint32(args[0]), length)
+	e.returnValue = value
+	return nil, err
+}
+
+// readKey consumes [gas], plus the gas for reading the key, and returns the
+// storage key of [length] bytes at [ptr]
+func (e *environment) readKey(instance *wasm.Instance, ptr, length uint64, gas uint64) ([]byte, error) {
+	if uint32(length) > maxKeySize {
+		return nil, errKeyTooLarge
+	}
+	if err := instance.UseGas(gas + byteGas*uint64(uint32(length))); err != nil {
+		return nil, err
+	}
+	return instance.Read(uint32(ptr), uint32(length))
+}
+
+// invoke calls [function] of the contract [contractID] with [args], using at
+// most [gasLimit] gas. The contract's storage in [db] is only changed if the
+// call succeeds. An error is only returned if [db] couldn't be read or written;
+// the failure of the call itself is reported in the result.
+func (vm *VM) invoke(db database.Database, contractID ids.ID, function string, args []byte, gasLimit uint64) (*TxResult, error) {
+	module, err := vm.getModule(db, contractID)
+	switch {
+	case err == database.ErrNotFound:
+		return &TxResult{Error: errUnknownContract.Error()}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	storage := versiondb.New(vm.contractStorage(db, contractID))
+	env := &environment{
+		storage: storage,
+		args:    args,
+	}
+	instance, err := wasm.Instantiate(module, env.imports(), wasm.Config{
+		GasLimit:     gasLimit,
+		MaxPages:     maxPages,
+		MaxCallDepth: maxCallDepth,
+	})
+	if err != nil {
+		return &TxResult{Error: err.Error()}, nil
+	}
+
+	results, err := instance.Call(function)
+	if env.dbErr != nil {
+		return nil, env.dbErr
+	}
+	if err == nil && len(results) == 1 && results[0] != 0 {
+		err = fmt.Errorf("%w: %d", errContractReturned, int32(results[0]))
+	}
+	if err != nil {
+		return &TxResult{
+			Error:   err.Error(),
+			GasUsed: instance.GasUsed(),
+		}, nil
+	}
+	return &TxResult{
+		GasUsed:     instance.GasUsed(),
+		ReturnValue: env.returnValue,
+	}, storage.Commit()
+}
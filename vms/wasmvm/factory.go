@@ -0,0 +1,17 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasmvm
+
+import "github.com/ava-labs/gecko/ids"
+
+// ID is a unique identifier for this VM
+var (
+	ID = ids.NewID([32]byte{'w', 'a', 's', 'm'})
+)
+
+// Factory ...
+type Factory struct{}
+
+// New ...
+func (f *Factory) New() interface{} { return &VM{} }
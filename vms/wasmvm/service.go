@@ -0,0 +1,196 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasmvm
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+var (
+	errNilTxID = errors.New("nil transaction ID")
+)
+
+// Service is the API service for this VM
+type Service struct{ vm *VM }
+
+// DeployContractArgs are the arguments to DeployContract
+type DeployContractArgs struct {
+	// Contract is the binary encoding of a WebAssembly module
+	Contract formatting.CB58 `json:"contract"`
+}
+
+// DeployContractReply is the reply from DeployContract
+type DeployContractReply struct {
+	TxID       ids.ID `json:"txID"`
+	ContractID ids.ID `json:"contractID"`
+}
+
+// DeployContract issues a tx that deploys [args.Contract]. The contract's ID is
+// the ID of the tx.
+func (s *Service) DeployContract(_ *http.Request, args *DeployContractArgs, reply *DeployContractReply) error {
+	s.vm.Ctx.Log.Verbo("DeployContract called")
+
+	tx := &DeployTx{
+		Code:  args.Contract.Bytes,
+		Nonce: uint64(s.vm.clock.Time().UnixNano()),
+	}
+	if err := tx.initialize(s.vm); err != nil {
+		return err
+	}
+	if err := s.vm.issueTx(tx); err != nil {
+		return err
+	}
+	reply.TxID = tx.ID()
+	reply.ContractID = tx.ID()
+	return nil
+}
+
+// InvokeArgs are the arguments to Invoke and Query
+type InvokeArgs struct {
+	// ContractID is the ID of the contract to call
+	ContractID ids.ID `json:"contractID"`
+
+	// Function is the name of the exported function to call
+	Function string `json:"function"`
+
+	// Args are passed to the contract
+	Args formatting.CB58 `json:"args"`
+
+	// GasLimit bounds the gas the call may consume. If 0, a default is used.
+	GasLimit json.Uint64 `json:"gasLimit"`
+}
+
+func (args *InvokeArgs) gasLimit() uint64 {
+	if args.GasLimit == 0 {
+		return defaultGasLimit
+	}
+	return uint64(args.GasLimit)
+}
+
+// InvokeReply is the reply from Invoke
+type InvokeReply struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// Invoke issues a tx that calls a function of a contract. The outcome of the
+// call can be fetched with GetTx once the tx has been put into a block.
+func (s *Service) Invoke(_ *http.Request, args *InvokeArgs, reply *InvokeReply) error {
+	s.vm.Ctx.Log.Verbo("Invoke called with %s.%s", args.ContractID, args.Function)
+
+	tx := &InvokeTx{
+		ContractID: args.ContractID,
+		Function:   args.Function,
+		Args:       args.Args.Bytes,
+		GasLimit:   args.gasLimit(),
+		Nonce:      uint64(s.vm.clock.Time().UnixNano()),
+	}
+	if err := tx.initialize(s.vm); err != nil {
+		return err
+	}
+	if err := s.vm.issueTx(tx); err != nil {
+		return err
+	}
+	reply.TxID = tx.ID()
+	return nil
+}
+
+// QueryReply is the reply from Query
+type QueryReply struct {
+	ReturnValue formatting.CB58 `json:"returnValue"`
+	GasUsed     json.Uint64     `json:"gasUsed"`
+}
+
+// Query calls a function of a contract against the last accepted state without
+// issuing a tx. Changes the call makes to the contract's storage are discarded.
+func (s *Service) Query(_ *http.Request, args *InvokeArgs, reply *QueryReply) error {
+	s.vm.Ctx.Log.Verbo("Query called with %s.%s", args.ContractID, args.Function)
+
+	tx := &InvokeTx{
+		ContractID: args.ContractID,
+		Function:   args.Function,
+		Args:       args.Args.Bytes,
+		GasLimit:   args.gasLimit(),
+	}
+	if err := tx.SyntacticVerify(); err != nil {
+		return err
+	}
+	result, err := s.vm.invoke(versiondb.New(s.vm.DB), tx.ContractID, tx.Function, tx.Args, tx.GasLimit)
+	if err != nil {
+		return err
+	}
+	if result.Error != "" {
+		return errors.New(result.Error)
+	}
+	reply.ReturnValue.Bytes = result.ReturnValue
+	reply.GasUsed = json.Uint64(result.GasUsed)
+	return nil
+}
+
+// GetTxArgs are the arguments to GetTx
+type GetTxArgs struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// GetTxReply is the reply from GetTx
+type GetTxReply struct {
+	// Status is Accepted if the tx is in an accepted block, Processing if it's
+	// waiting to be accepted and Unknown otherwise
+	Status choices.Status `json:"status"`
+
+	// The fields below are only set once the tx has been executed
+
+	// Error describes why the execution failed. Empty if it succeeded.
+	Error       string          `json:"error"`
+	GasUsed     json.Uint64     `json:"gasUsed"`
+	ReturnValue formatting.CB58 `json:"returnValue"`
+}
+
+// GetTx returns the status of the tx [args.TxID] and, if it has been executed,
+// its outcome
+func (s *Service) GetTx(_ *http.Request, args *GetTxArgs, reply *GetTxReply) error {
+	s.vm.Ctx.Log.Verbo("GetTx called with %s", args.TxID)
+
+	if args.TxID.IsZero() {
+		return errNilTxID
+	}
+
+	result, err := s.vm.getResult(s.vm.DB, args.TxID)
+	switch {
+	case err == nil:
+		reply.Status = choices.Accepted
+	case err != database.ErrNotFound:
+		return err
+	case s.vm.inMempool(args.TxID):
+		reply.Status = choices.Processing
+		return nil
+	default:
+		preferredDB, err := s.vm.preferredDB()
+		if err != nil {
+			return err
+		}
+		result, err = s.vm.getResult(preferredDB, args.TxID)
+		switch err {
+		case nil:
+			reply.Status = choices.Processing
+		case database.ErrNotFound:
+			reply.Status = choices.Unknown
+			return nil
+		default:
+			return err
+		}
+	}
+
+	reply.Error = result.Error
+	reply.GasUsed = json.Uint64(result.GasUsed)
+	reply.ReturnValue.Bytes = result.ReturnValue
+	return nil
+}
@@ -0,0 +1,87 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasmvm
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/wasmvm/wasm"
+)
+
+// The chain's state is kept under these prefixes so that it doesn't collide
+// with the blocks and statuses kept by core.SnowmanVM
+var (
+	contractPrefix = []byte("contract")
+	storagePrefix  = []byte("storage")
+	resultPrefix   = []byte("result")
+)
+
+// TxResult is the outcome of executing a transaction. It is kept in the
+// chain's state once the transaction has been executed.
+type TxResult struct {
+	// Error describes why execution failed. If empty, execution succeeded.
+	Error string `serialize:"true"`
+
+	// GasUsed is the gas consumed by the execution
+	GasUsed uint64 `serialize:"true"`
+
+	// ReturnValue is the value that the contract returned
+	ReturnValue []byte `serialize:"true"`
+}
+
+// getContract returns the code of the contract [contractID] in [db]
+func (vm *VM) getContract(db database.Database, contractID ids.ID) ([]byte, error) {
+	return prefixdb.New(contractPrefix, db).Get(contractID.Bytes())
+}
+
+// putContract stores [code] as the code of the contract [contractID] in [db]
+func (vm *VM) putContract(db database.Database, contractID ids.ID, code []byte) error {
+	return prefixdb.New(contractPrefix, db).Put(contractID.Bytes(), code)
+}
+
+// getModule returns the decoded code of the contract [contractID] in [db]
+func (vm *VM) getModule(db database.Database, contractID ids.ID) (*wasm.Module, error) {
+	if module, ok := vm.modules.Get(contractID); ok {
+		return module.(*wasm.Module), nil
+	}
+	code, err := vm.getContract(db, contractID)
+	if err != nil {
+		return nil, err
+	}
+	module, err := wasm.Decode(code)
+	if err != nil {
+		return nil, err
+	}
+	// A contract's ID is the ID of the transaction that deployed it, so the
+	// code of a contract ID never changes
+	vm.modules.Put(contractID, module)
+	return module, nil
+}
+
+// contractStorage returns the database that holds the storage of the contract
+// [contractID] in [db]
+func (vm *VM) contractStorage(db database.Database, contractID ids.ID) database.Database {
+	return prefixdb.New(contractID.Bytes(), prefixdb.New(storagePrefix, db))
+}
+
+// getResult returns the outcome of executing the transaction [txID] in [db]
+func (vm *VM) getResult(db database.Database, txID ids.ID) (*TxResult, error) {
+	resultBytes, err := prefixdb.New(resultPrefix, db).Get(txID.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	result := &TxResult{}
+	return result, Codec.Unmarshal(resultBytes, result)
+}
+
+// putResult stores [result] as the outcome of executing the transaction [txID]
+// in [db]
+func (vm *VM) putResult(db database.Database, txID ids.ID, result *TxResult) error {
+	resultBytes, err := Codec.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return prefixdb.New(resultPrefix, db).Put(txID.Bytes(), resultBytes)
+}
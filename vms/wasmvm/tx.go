@@ -0,0 +1,149 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasmvm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/wasmvm/wasm"
+)
+
+var (
+	errNilTx             = errors.New("tx is nil")
+	errContractTooLarge  = fmt.Errorf("contract is larger than %d bytes", maxContractSize)
+	errArgsTooLarge      = fmt.Errorf("arguments are larger than %d bytes", maxArgsSize)
+	errNoContractID      = errors.New("contract ID is empty")
+	errNoFunction        = errors.New("function name is empty")
+	errInvalidGasLimit   = fmt.Errorf("gas limit must be in (0, %d]", maxGasLimit)
+	errInvalidContract   = errors.New("contract isn't a valid WebAssembly module")
+	errUnknownContract   = errors.New("contract doesn't exist")
+	errContractReturned  = errors.New("contract returned a non-zero status")
+	errDuplicateTx       = errors.New("tx has already been issued")
+	errUnexpectedTxState = errors.New("tx doesn't have a result")
+)
+
+// Tx is a transaction on this chain. A transaction either deploys a contract or
+// invokes a function of a contract.
+type Tx interface {
+	// initialize this tx's non-serialized fields
+	initialize(vm *VM) error
+
+	// ID of this tx
+	ID() ids.ID
+
+	// Bytes returns the byte representation of this tx
+	Bytes() []byte
+
+	// SyntacticVerify returns nil if this tx is well-formed
+	SyntacticVerify() error
+
+	// execute this tx on [db] and return its outcome. The execution of a
+	// contract failing isn't an error; it's reported in the result. An error
+	// is only returned if [db] couldn't be read or written.
+	execute(db database.Database) (*TxResult, error)
+}
+
+// txBase contains the fields and methods common to all txs
+type txBase struct {
+	vm    *VM
+	id    ids.ID
+	bytes []byte
+}
+
+// initialize sets the ID and bytes of [tx], which is this tx as a Tx
+func (t *txBase) initialize(vm *VM, tx Tx) error {
+	t.vm = vm
+	txBytes, err := Codec.Marshal(&tx)
+	t.bytes = txBytes
+	t.id = ids.NewID(hashing.ComputeHash256Array(txBytes))
+	return err
+}
+
+// ID of this tx
+func (t *txBase) ID() ids.ID { return t.id }
+
+// Bytes returns the byte representation of this tx
+func (t *txBase) Bytes() []byte { return t.bytes }
+
+// DeployTx deploys a contract. The ID of the contract is the ID of this tx.
+type DeployTx struct {
+	txBase
+
+	// Code is the binary encoding of the contract, a WebAssembly module
+	Code []byte `serialize:"true"`
+
+	// Nonce distinguishes txs that would otherwise be identical
+	Nonce uint64 `serialize:"true"`
+}
+
+func (tx *DeployTx) initialize(vm *VM) error { return tx.txBase.initialize(vm, tx) }
+
+// SyntacticVerify returns nil if [tx] is well-formed
+func (tx *DeployTx) SyntacticVerify() error {
+	switch {
+	case tx == nil:
+		return errNilTx
+	case len(tx.Code) > maxContractSize:
+		return errContractTooLarge
+	}
+	if _, err := wasm.Decode(tx.Code); err != nil {
+		return fmt.Errorf("%w: %s", errInvalidContract, err)
+	}
+	return nil
+}
+
+func (tx *DeployTx) execute(db database.Database) (*TxResult, error) {
+	return &TxResult{}, tx.vm.putContract(db, tx.ID(), tx.Code)
+}
+
+// InvokeTx calls a function exported by a contract. The function must not take
+// any parameters. If it returns an i32, a non-zero value means the call
+// failed. If the call fails, the contract's storage isn't changed.
+type InvokeTx struct {
+	txBase
+
+	// ContractID is the ID of the contract being invoked
+	ContractID ids.ID `serialize:"true"`
+
+	// Function is the name of the exported function to call
+	Function string `serialize:"true"`
+
+	// Args are passed to the contract, which may read them through the args
+	// host functions
+	Args []byte `serialize:"true"`
+
+	// GasLimit bounds the gas that the call may consume
+	GasLimit uint64 `serialize:"true"`
+
+	// Nonce distinguishes txs that would otherwise be identical
+	Nonce uint64 `serialize:"true"`
+}
+
+func (tx *InvokeTx) initialize(vm *VM) error { return tx.txBase.initialize(vm, tx) }
+
+// SyntacticVerify returns nil if [tx] is well-formed
+func (tx *InvokeTx) SyntacticVerify() error {
+	switch {
+	case tx == nil:
+		return errNilTx
+	case tx.ContractID.IsZero(), tx.ContractID.Equals(ids.Empty):
+		return errNoContractID
+	case tx.Function == "":
+		return errNoFunction
+	case len(tx.Args) > maxArgsSize:
+		return errArgsTooLarge
+	case tx.GasLimit == 0 || tx.GasLimit > maxGasLimit:
+		return errInvalidGasLimit
+	default:
+		return nil
+	}
+}
+
+func (tx *InvokeTx) execute(db database.Database) (*TxResult, error) {
+	return tx.vm.invoke(db, tx.ContractID, tx.Function, tx.Args, tx.GasLimit)
+}
@@ -0,0 +1,292 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasmvm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/cache"
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/core"
+)
+
+const (
+	// maxBlockTxs is the maximum number of txs in a block
+	maxBlockTxs = 32
+
+	// maxContractSize is the maximum size of a contract's code, in bytes
+	maxContractSize = 1 << 16
+
+	// maxArgsSize is the maximum size of an invocation's arguments, in bytes
+	maxArgsSize = 1 << 12
+
+	// maxGasLimit is the maximum gas that an invocation may consume
+	maxGasLimit = 1 << 24
+
+	// defaultGasLimit is the gas limit of invocations that don't specify one
+	defaultGasLimit = 1 << 20
+
+	// maxPages is the maximum number of pages of memory a contract may use
+	maxPages = 16
+
+	// maxCallDepth is the maximum depth of a contract's call stack
+	maxCallDepth = 256
+
+	// moduleCacheSize is the number of decoded contracts kept in memory
+	moduleCacheSize = 64
+)
+
+var (
+	errNoPendingTxs    = errors.New("there are no pending transactions")
+	errBadGenesisBytes = errors.New("genesis data should be empty")
+	errUnsupportedFXs  = errors.New("unsupported feature extensions")
+)
+
+// Codec does serialization and deserialization. Blocks hold entire contracts,
+// so its size limit is larger than the default.
+var Codec codec.Codec
+
+func init() {
+	Codec = codec.New(maxBlockTxs*(maxContractSize+1<<10), maxContractSize)
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		Codec.RegisterType(&DeployTx{}),
+		Codec.RegisterType(&InvokeTx{}),
+	)
+	if errs.Errored() {
+		panic(errs.Err)
+	}
+}
+
+// VM implements the snowman.ChainVM interface. Each block on this chain
+// contains txs that deploy WebAssembly contracts or invoke their functions.
+type VM struct {
+	core.SnowmanVM
+
+	clock timer.Clock
+
+	// Txs that haven't been put into a block yet
+	mempool []Tx
+
+	// Key: block ID
+	// Value: the block. Blocks are kept in memory from when they're verified
+	// until they're decided.
+	currentBlocks map[[32]byte]*Block
+
+	// Key: contract ID
+	// Value: the contract's decoded module
+	modules cache.LRU
+}
+
+// Initialize this vm
+// [ctx] is this vm's context
+// [db] is this vm's database
+// [toEngine] is used to notify the consensus engine that new blocks are
+// ready to be added to consensus
+// The genesis data must be empty; no contracts exist at genesis
+func (vm *VM) Initialize(
+	ctx *snow.Context,
+	db database.Database,
+	genesisData []byte,
+	toEngine chan<- common.Message,
+	fxs []*common.Fx,
+) error {
+	if len(fxs) != 0 {
+		return errUnsupportedFXs
+	}
+	if err := vm.SnowmanVM.Initialize(ctx, db, vm.unmarshalBlockFunc, toEngine); err != nil {
+		ctx.Log.Error("error initializing SnowmanVM: %v", err)
+		return err
+	}
+	vm.currentBlocks = make(map[[32]byte]*Block)
+	vm.modules = cache.LRU{Size: moduleCacheSize}
+
+	// If database is empty, create it using the provided genesis data
+	if !vm.DBInitialized() {
+		if len(genesisData) != 0 {
+			return errBadGenesisBytes
+		}
+
+		// The genesis block has no parent and no txs
+		genesisBlock, err := vm.newBlock(ids.Empty, nil)
+		if err != nil {
+			vm.Ctx.Log.Error("error while creating genesis block: %v", err)
+			return err
+		}
+		if err := vm.State.PutBlock(vm.DB, genesisBlock); err != nil {
+			vm.Ctx.Log.Error("error while saving genesis block: %v", err)
+			return err
+		}
+
+		// Accept the genesis block
+		// Sets [vm.lastAccepted] and [vm.preferred]
+		genesisBlock.onAcceptDB = versiondb.New(vm.DB)
+		genesisBlock.Accept()
+
+		vm.SetDBInitialized()
+
+		// Flush VM's database to underlying db
+		if err := vm.DB.Commit(); err != nil {
+			vm.Ctx.Log.Error("error while commiting db: %v", err)
+			return err
+		}
+	}
+
+	// Build off the most recently accepted block
+	vm.SetPreference(vm.LastAccepted())
+	return nil
+}
+
+// CreateHandlers returns a map where:
+// Keys: The path extension for this VM's API (empty in this case)
+// Values: The handler for the API
+func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
+	handler := vm.NewHandler("wasm", &Service{vm: vm})
+	return map[string]*common.HTTPHandler{
+		"": handler,
+	}
+}
+
+// CreateStaticHandlers returns a map where:
+// Keys: The path extension for this VM's static API
+// Values: The handler for that static API
+// We return nil because this VM has no static API
+func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler { return nil }
+
+// BuildBlock returns a block containing a batch of the txs in the mempool
+func (vm *VM) BuildBlock() (snowman.Block, error) {
+	preferredDB, err := vm.preferredDB()
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop txs that were executed since they were issued
+	txs := []Tx(nil)
+	for len(vm.mempool) > 0 && len(txs) < maxBlockTxs {
+		tx := vm.mempool[0]
+		vm.mempool = vm.mempool[1:]
+		if _, err := vm.getResult(preferredDB, tx.ID()); err == nil {
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	vm.VMMetrics.SetMempoolTxs(len(vm.mempool))
+	if len(txs) == 0 { // There is no block to be built
+		return nil, errNoPendingTxs
+	}
+
+	// Notify consensus engine that there are more pending txs for blocks
+	// (if that is the case) when done building this block
+	if len(vm.mempool) > 0 {
+		defer vm.NotifyBlockReady()
+	}
+
+	blk, err := vm.newBlock(vm.Preferred(), txs)
+	if err != nil {
+		return nil, err
+	}
+	if err := blk.Verify(); err != nil {
+		return nil, err
+	}
+	if err := vm.State.PutBlock(vm.DB, blk); err != nil {
+		return nil, err
+	}
+	return blk, vm.DB.Commit()
+}
+
+// ParseBlock implements the snowman.ChainVM interface
+func (vm *VM) ParseBlock(bytes []byte) (snowman.Block, error) {
+	blk, err := vm.unmarshalBlockFunc(bytes)
+	if err != nil {
+		return nil, err
+	}
+	// If we have seen this block before, return it with the most up-to-date info
+	if blk, err := vm.getBlock(blk.ID()); err == nil {
+		return blk, nil
+	}
+	if err := vm.State.PutBlock(vm.DB, blk); err != nil {
+		return nil, err
+	}
+	return blk, vm.DB.Commit()
+}
+
+// GetBlock implements the snowman.ChainVM interface
+func (vm *VM) GetBlock(blkID ids.ID) (snowman.Block, error) { return vm.getBlock(blkID) }
+
+func (vm *VM) getBlock(blkID ids.ID) (*Block, error) {
+	// If block is in memory, return it.
+	if blk, exists := vm.currentBlocks[blkID.Key()]; exists {
+		return blk, nil
+	}
+	// Block isn't in memory. If block is in database, return it.
+	blkInterface, err := vm.State.GetBlock(vm.DB, blkID)
+	if err != nil {
+		return nil, err
+	}
+	if blk, ok := blkInterface.(*Block); ok {
+		return blk, nil
+	}
+	return nil, errors.New("block not found")
+}
+
+// unmarshalBlockFunc parses [bytes] to a block. It's used by the vm's state to
+// unmarshal blocks saved in state.
+func (vm *VM) unmarshalBlockFunc(bytes []byte) (snowman.Block, error) {
+	blk := &Block{}
+	if err := Codec.Unmarshal(bytes, blk); err != nil {
+		return nil, err
+	}
+	return blk, blk.initialize(vm, bytes)
+}
+
+// preferredDB returns the state of the chain if the preferred block is
+// accepted
+func (vm *VM) preferredDB() (database.Database, error) {
+	preferred, err := vm.getBlock(vm.Preferred())
+	if err != nil {
+		return nil, err
+	}
+	return preferred.onAccept(), nil
+}
+
+// issueTx adds [tx] to the mempool and notifies the consensus engine that a
+// block is ready to be built
+func (vm *VM) issueTx(tx Tx) error {
+	if err := tx.SyntacticVerify(); err != nil {
+		return err
+	}
+	if vm.inMempool(tx.ID()) {
+		return errDuplicateTx
+	}
+	preferredDB, err := vm.preferredDB()
+	if err != nil {
+		return err
+	}
+	if _, err := vm.getResult(preferredDB, tx.ID()); err == nil {
+		return errDuplicateTx
+	}
+	vm.mempool = append(vm.mempool, tx)
+	vm.VMMetrics.SetMempoolTxs(len(vm.mempool))
+	vm.NotifyBlockReady()
+	return nil
+}
+
+// inMempool returns true if the tx [txID] is waiting to be put into a block
+func (vm *VM) inMempool(txID ids.ID) bool {
+	for _, tx := range vm.mempool {
+		if tx.ID().Equals(txID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasmvm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+// The helpers below assemble the binary encoding of the test contract
+
+func leb(v uint32) []byte {
+	b := []byte(nil)
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func concat(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+func vec(items ...[]byte) []byte { return concat(leb(uint32(len(items))), concat(items...)) }
+
+func name(s string) []byte { return concat(leb(uint32(len(s))), []byte(s)) }
+
+func section(id byte, items ...[]byte) []byte {
+	contents := vec(items...)
+	return concat([]byte{id}, leb(uint32(len(contents))), contents)
+}
+
+func body(code ...byte) []byte {
+	contents := concat([]byte{0x00}, code) // no locals
+	return concat(leb(uint32(len(contents))), contents)
+}
+
+// counterContract keeps a little-endian uint64 under the key "n":
+// increment() adds 1 to it and returns its new value
+// fail() increments it and then returns a non-zero status
+// spin() loops forever
+// get() returns it
+var counterContract = concat(
+	[]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00},
+	section(1, // types
+		[]byte{0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f}, // 0: (i32, i32, i32, i32) -> i32
+		[]byte{0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x00},       // 1: (i32, i32, i32, i32) -> ()
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x00},                   // 2: (i32, i32) -> ()
+		[]byte{0x60, 0x00, 0x01, 0x7f},                         // 3: () -> i32
+		[]byte{0x60, 0x00, 0x00},                               // 4: () -> ()
+	),
+	section(2, // imports
+		concat(name("env"), name("storage_read"), []byte{0x00, 0x00}),
+		concat(name("env"), name("storage_write"), []byte{0x00, 0x01}),
+		concat(name("env"), name("set_return"), []byte{0x00, 0x02}),
+	),
+	section(3, []byte{0x03}, []byte{0x03}, []byte{0x04}, []byte{0x03}), // functions
+	section(5, []byte{0x00, 0x01}),                                     // memory of 1 page
+	section(7, // exports
+		concat(name("increment"), []byte{0x00, 0x03}),
+		concat(name("fail"), []byte{0x00, 0x04}),
+		concat(name("spin"), []byte{0x00, 0x05}),
+		concat(name("get"), []byte{0x00, 0x06}),
+	),
+	section(10, // code
+		body( // increment
+			0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x41, 0x08, // key at 0, length 1, value at 8, capacity 8
+			0x10, 0x00, // call storage_read
+			0x1a,       // drop
+			0x41, 0x08, // i32.const 8
+			0x41, 0x08, // i32.const 8
+			0x29, 0x03, 0x00, // i64.load
+			0x42, 0x01, // i64.const 1
+			0x7c,             // i64.add
+			0x37, 0x03, 0x00, // i64.store
+			0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x41, 0x08,
+			0x10, 0x01, // call storage_write
+			0x41, 0x08, 0x41, 0x08,
+			0x10, 0x02, // call set_return
+			0x41, 0x00, // i32.const 0
+			0x0b, // end
+		),
+		body( // fail
+			0x10, 0x03, // call increment
+			0x1a,       // drop
+			0x41, 0x01, // i32.const 1
+			0x0b, // end
+		),
+		body( // spin
+			0x03, 0x40, // loop
+			0x0c, 0x00, // br 0
+			0x0b, // end
+			0x0b, // end
+		),
+		body( // get
+			0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x41, 0x08,
+			0x10, 0x00, // call storage_read
+			0x1a, // drop
+			0x41, 0x08, 0x41, 0x08,
+			0x10, 0x02, // call set_return
+			0x41, 0x00, // i32.const 0
+			0x0b, // end
+		),
+	),
+	section(11, concat([]byte{0x00, 0x41, 0x00, 0x0b}, name("n"))), // data: "n" at 0
+)
+
+func defaultVM(t *testing.T) (*VM, *Service) {
+	vm := &VM{}
+	ctx := snow.DefaultContextTest()
+	if err := vm.Initialize(ctx, memdb.New(), nil, make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.clock.Set(time.Unix(1000, 0))
+	return vm, &Service{vm: vm}
+}
+
+// acceptBlock builds a block from the mempool and accepts it
+func acceptBlock(t *testing.T, vm *VM) {
+	blk, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := blk.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	blk.Accept()
+	vm.SetPreference(blk.ID())
+}
+
+func deploy(t *testing.T, vm *VM, s *Service) ids.ID {
+	reply := DeployContractReply{}
+	if err := s.DeployContract(nil, &DeployContractArgs{Contract: formatting.CB58{Bytes: counterContract}}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	acceptBlock(t, vm)
+	return reply.ContractID
+}
+
+func invoke(t *testing.T, vm *VM, s *Service, contractID ids.ID, function string) ids.ID {
+	vm.clock.Set(vm.clock.Time().Add(time.Second)) // so that each tx has a different nonce
+	reply := InvokeReply{}
+	if err := s.Invoke(nil, &InvokeArgs{ContractID: contractID, Function: function}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	return reply.TxID
+}
+
+func getTx(t *testing.T, s *Service, txID ids.ID) *GetTxReply {
+	reply := &GetTxReply{}
+	if err := s.GetTx(nil, &GetTxArgs{TxID: txID}, reply); err != nil {
+		t.Fatal(err)
+	}
+	return reply
+}
+
+func query(t *testing.T, s *Service, contractID ids.ID) uint64 {
+	reply := QueryReply{}
+	if err := s.Query(nil, &InvokeArgs{ContractID: contractID, Function: "get"}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.ReturnValue.Bytes) != 8 {
+		t.Fatalf("Wrong return value %x", reply.ReturnValue.Bytes)
+	}
+	return binary.LittleEndian.Uint64(reply.ReturnValue.Bytes)
+}
+
+func TestGenesis(t *testing.T) {
+	vm, _ := defaultVM(t)
+
+	genesis, err := vm.GetBlock(vm.LastAccepted())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := genesis.Status(); status != choices.Accepted {
+		t.Fatalf("Genesis block should be accepted but is %s", status)
+	}
+	if _, err := vm.BuildBlock(); err != errNoPendingTxs {
+		t.Fatalf("Expected %s but got %v", errNoPendingTxs, err)
+	}
+
+	vm = &VM{}
+	if err := vm.Initialize(snow.DefaultContextTest(), memdb.New(), []byte{1}, make(chan common.Message, 1), nil); err != errBadGenesisBytes {
+		t.Fatalf("Expected %s but got %v", errBadGenesisBytes, err)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	vm, s := defaultVM(t)
+	contractID := deploy(t, vm, s)
+
+	first := invoke(t, vm, s, contractID, "increment")
+	second := invoke(t, vm, s, contractID, "increment")
+	if status := getTx(t, s, second).Status; status != choices.Processing {
+		t.Fatalf("Tx should be processing but is %s", status)
+	}
+	acceptBlock(t, vm)
+
+	for i, txID := range []ids.ID{first, second} {
+		reply := getTx(t, s, txID)
+		if reply.Status != choices.Accepted {
+			t.Fatalf("Tx should be accepted but is %s", reply.Status)
+		}
+		if reply.Error != "" {
+			t.Fatalf("Tx shouldn't have failed but failed with: %s", reply.Error)
+		}
+		if value := binary.LittleEndian.Uint64(reply.ReturnValue.Bytes); value != uint64(i+1) {
+			t.Fatalf("Tx returned %d", value)
+		}
+		if reply.GasUsed == 0 {
+			t.Fatalf("Tx should have used gas")
+		}
+	}
+
+	// Querying a function that changes the contract's storage shouldn't change it
+	if value := query(t, s, contractID); value != 2 {
+		t.Fatalf("Counter should be 2 but is %d", value)
+	}
+	if err := s.Query(nil, &InvokeArgs{ContractID: contractID, Function: "increment"}, &QueryReply{}); err != nil {
+		t.Fatal(err)
+	}
+	if value := query(t, s, contractID); value != 2 {
+		t.Fatalf("Counter should be 2 but is %d", value)
+	}
+
+	if status := getTx(t, s, ids.NewID([32]byte{1})).Status; status != choices.Unknown {
+		t.Fatalf("Tx should be unknown but is %s", status)
+	}
+}
+
+func TestInvokeFailure(t *testing.T) {
+	vm, s := defaultVM(t)
+	contractID := deploy(t, vm, s)
+
+	incremented := invoke(t, vm, s, contractID, "increment")
+	failed := invoke(t, vm, s, contractID, "fail")
+	unknown := invoke(t, vm, s, ids.NewID([32]byte{1}), "increment")
+	acceptBlock(t, vm)
+
+	if err := getTx(t, s, incremented).Error; err != "" {
+		t.Fatalf("Tx shouldn't have failed but failed with: %s", err)
+	}
+	if err := getTx(t, s, failed).Error; !strings.Contains(err, errContractReturned.Error()) {
+		t.Fatalf("Tx should have failed with %q but failed with %q", errContractReturned, err)
+	}
+	if err := getTx(t, s, unknown).Error; err != errUnknownContract.Error() {
+		t.Fatalf("Tx should have failed with %q but failed with %q", errUnknownContract, err)
+	}
+
+	// The failed call's changes should have been rolled back
+	if value := query(t, s, contractID); value != 1 {
+		t.Fatalf("Counter should be 1 but is %d", value)
+	}
+}
+
+func TestInvokeOutOfGas(t *testing.T) {
+	vm, s := defaultVM(t)
+	contractID := deploy(t, vm, s)
+
+	vm.clock.Set(vm.clock.Time().Add(time.Second))
+	reply := InvokeReply{}
+	if err := s.Invoke(nil, &InvokeArgs{ContractID: contractID, Function: "spin", GasLimit: 5000}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	acceptBlock(t, vm)
+
+	result := getTx(t, s, reply.TxID)
+	if !strings.Contains(result.Error, "gas") {
+		t.Fatalf("Tx should have run out of gas but failed with %q", result.Error)
+	}
+	if result.GasUsed != 5000 {
+		t.Fatalf("Tx should have used all of its gas but used %d", result.GasUsed)
+	}
+}
+
+func TestIssueInvalidTxs(t *testing.T) {
+	vm, s := defaultVM(t)
+
+	if err := s.DeployContract(nil, &DeployContractArgs{Contract: formatting.CB58{Bytes: []byte{1, 2, 3}}}, &DeployContractReply{}); err == nil {
+		t.Fatalf("Should have errored due to an invalid contract")
+	}
+
+	contractID := deploy(t, vm, s)
+	args := &InvokeArgs{ContractID: contractID, Function: "increment"}
+	if err := s.Invoke(nil, args, &InvokeReply{}); err != nil {
+		t.Fatal(err)
+	}
+	// The clock hasn't moved, so this tx is identical to the last
+	if err := s.Invoke(nil, args, &InvokeReply{}); err != errDuplicateTx {
+		t.Fatalf("Expected %s but got %v", errDuplicateTx, err)
+	}
+	acceptBlock(t, vm)
+	if err := s.Invoke(nil, args, &InvokeReply{}); err != errDuplicateTx {
+		t.Fatalf("Expected %s but got %v", errDuplicateTx, err)
+	}
+
+	if err := s.Invoke(nil, &InvokeArgs{ContractID: contractID}, &InvokeReply{}); err != errNoFunction {
+		t.Fatalf("Expected %s but got %v", errNoFunction, err)
+	}
+	if err := s.Invoke(nil, &InvokeArgs{ContractID: contractID, Function: "increment", GasLimit: maxGasLimit + 1}, &InvokeReply{}); err != errInvalidGasLimit {
+		t.Fatalf("Expected %s but got %v", errInvalidGasLimit, err)
+	}
+}
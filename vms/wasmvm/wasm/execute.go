@@ -0,0 +1,485 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasm
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+// operands is the number of values that each instruction pops off the operand
+// stack, not including calls, whose operands depend on the callee
+var operands [256]uint8
+
+func init() {
+	for _, op := range []byte{opIf, opBrIf, opBrTable, opDrop, opLocalSet, opLocalTee, opGlobalSet, opMemoryGrow} {
+		operands[op] = 1
+	}
+	operands[opSelect] = 3
+
+	// Loads pop an address, stores an address and a value
+	for op := opI32Load; op <= 0x35; op++ {
+		operands[op] = 1
+	}
+	for op := byte(0x36); op <= opI64Store32; op++ {
+		operands[op] = 2
+	}
+
+	// Comparisons
+	operands[opI32Eqz] = 1
+	for op := byte(0x46); op <= 0x4f; op++ {
+		operands[op] = 2
+	}
+	operands[0x50] = 1 // i64.eqz
+	for op := byte(0x51); op <= opI64GeU; op++ {
+		operands[op] = 2
+	}
+
+	// Arithmetic
+	for op := opI32Clz; op <= 0x69; op++ {
+		operands[op] = 1
+	}
+	for op := byte(0x6a); op <= 0x78; op++ {
+		operands[op] = 2
+	}
+	for op := byte(0x79); op <= 0x7b; op++ {
+		operands[op] = 1
+	}
+	for op := byte(0x7c); op <= opI64Rotr; op++ {
+		operands[op] = 2
+	}
+
+	// Conversions
+	for _, op := range []byte{opI32WrapI64, opI64ExtendI32, opI64ExtendU32} {
+		operands[op] = 1
+	}
+	for op := opI32Extend8S; op <= opI64Extend32S; op++ {
+		operands[op] = 1
+	}
+}
+
+// execute the body of [f], whose locals, including its parameters, are
+// [locals]. On return, the function's results are on top of the operand
+// stack.
+func (i *Instance) execute(f *function, locals []uint64, numResults int) error {
+	code := f.code
+	base := len(i.stack)
+
+	// The function body is itself a block, so branching to its label returns
+	labels := []label{{pc: len(code), height: base, arity: numResults}}
+	for pc := 0; pc < len(code); {
+		in := &code[pc]
+		pc++
+
+		if err := i.UseGas(instructionGas); err != nil {
+			return err
+		}
+		if len(i.stack)-base < int(operands[in.op]) {
+			return errStackUnderflow
+		}
+
+		switch op := in.op; op {
+		case opUnreachable:
+			return errUnreachable
+		case opNop:
+		case opBlock:
+			labels = append(labels, label{pc: int(in.b) + 1, height: len(i.stack), arity: int(in.a)})
+		case opLoop:
+			labels = append(labels, label{pc: pc, height: len(i.stack), loop: true})
+		case opIf:
+			l := label{pc: int(in.b) + 1, height: len(i.stack) - 1, arity: int(in.a)}
+			switch {
+			case i.pop() != 0:
+				labels = append(labels, l)
+			case in.c != 0:
+				labels = append(labels, l)
+				pc = int(in.c) + 1
+			default:
+				pc = l.pc
+			}
+		case opElse:
+			// The then branch is done, so skip over the else branch
+			labels = labels[:len(labels)-1]
+			pc = int(in.b) + 1
+		case opEnd:
+			labels = labels[:len(labels)-1]
+		case opBr:
+			var err error
+			if labels, pc, err = i.branch(labels, int(in.a)); err != nil {
+				return err
+			}
+		case opBrIf:
+			if i.pop() != 0 {
+				var err error
+				if labels, pc, err = i.branch(labels, int(in.a)); err != nil {
+					return err
+				}
+			}
+		case opBrTable:
+			index := i.pop()
+			depth := in.table[len(in.table)-1]
+			if index < uint64(len(in.table)-1) {
+				depth = in.table[index]
+			}
+			var err error
+			if labels, pc, err = i.branch(labels, int(depth)); err != nil {
+				return err
+			}
+		case opReturn:
+			return i.unwind(base, numResults)
+		case opCall:
+			if err := i.call(uint32(in.a)); err != nil {
+				return err
+			}
+		case opDrop:
+			i.pop()
+		case opSelect:
+			cond := i.pop()
+			second := i.pop()
+			if cond == 0 {
+				i.stack[len(i.stack)-1] = second
+			}
+		case opLocalGet:
+			i.stack = append(i.stack, locals[in.a])
+		case opLocalSet:
+			locals[in.a] = i.pop()
+		case opLocalTee:
+			locals[in.a] = i.stack[len(i.stack)-1]
+		case opGlobalGet:
+			i.stack = append(i.stack, i.globals[in.a])
+		case opGlobalSet:
+			i.globals[in.a] = i.pop()
+		case opMemorySize:
+			i.stack = append(i.stack, uint64(len(i.memory)/PageSize))
+		case opMemoryGrow:
+			result, err := i.grow(uint32(i.pop()))
+			if err != nil {
+				return err
+			}
+			i.stack = append(i.stack, uint64(result))
+		case opI32Const, opI64Const:
+			i.stack = append(i.stack, in.a)
+		default:
+			var err error
+			switch {
+			case op >= opI32Load && op <= opI64Store32:
+				err = i.memoryAccess(op, in.a)
+			case op == opI32Eqz:
+				i.unary(func(a uint64) uint64 { return boolToValue(uint32(a) == 0) })
+			case op == 0x50: // i64.eqz
+				i.unary(func(a uint64) uint64 { return boolToValue(a == 0) })
+			case op >= 0x46 && op <= 0x4f:
+				b := i.pop()
+				i.stack[len(i.stack)-1] = compare(op-0x46, i.stack[len(i.stack)-1], b, true)
+			case op >= 0x51 && op <= opI64GeU:
+				b := i.pop()
+				i.stack[len(i.stack)-1] = compare(op-0x51, i.stack[len(i.stack)-1], b, false)
+			case op == opI32Clz:
+				i.unary(func(a uint64) uint64 { return uint64(bits.LeadingZeros32(uint32(a))) })
+			case op == 0x68: // i32.ctz
+				i.unary(func(a uint64) uint64 { return uint64(bits.TrailingZeros32(uint32(a))) })
+			case op == 0x69: // i32.popcnt
+				i.unary(func(a uint64) uint64 { return uint64(bits.OnesCount32(uint32(a))) })
+			case op >= 0x6a && op <= 0x78:
+				b := i.pop()
+				var result uint32
+				result, err = i32Binary(op, uint32(i.stack[len(i.stack)-1]), uint32(b))
+				i.stack[len(i.stack)-1] = uint64(result)
+			case op == 0x79: // i64.clz
+				i.unary(func(a uint64) uint64 { return uint64(bits.LeadingZeros64(a)) })
+			case op == 0x7a: // i64.ctz
+				i.unary(func(a uint64) uint64 { return uint64(bits.TrailingZeros64(a)) })
+			case op == 0x7b: // i64.popcnt
+				i.unary(func(a uint64) uint64 { return uint64(bits.OnesCount64(a)) })
+			case op >= 0x7c && op <= opI64Rotr:
+				b := i.pop()
+				i.stack[len(i.stack)-1], err = i64Binary(op, i.stack[len(i.stack)-1], b)
+			case op == opI32WrapI64:
+				i.unary(func(a uint64) uint64 { return uint64(uint32(a)) })
+			case op == opI64ExtendI32:
+				i.unary(func(a uint64) uint64 { return uint64(int64(int32(a))) })
+			case op == opI64ExtendU32:
+				i.unary(func(a uint64) uint64 { return uint64(uint32(a)) })
+			case op == opI32Extend8S:
+				i.unary(func(a uint64) uint64 { return uint64(uint32(int32(int8(a)))) })
+			case op == 0xc1: // i32.extend16_s
+				i.unary(func(a uint64) uint64 { return uint64(uint32(int32(int16(a)))) })
+			case op == 0xc2: // i64.extend8_s
+				i.unary(func(a uint64) uint64 { return uint64(int64(int8(a))) })
+			case op == 0xc3: // i64.extend16_s
+				i.unary(func(a uint64) uint64 { return uint64(int64(int16(a))) })
+			case op == opI64Extend32S:
+				i.unary(func(a uint64) uint64 { return uint64(int64(int32(a))) })
+			default:
+				// Decoding only allows the instructions handled above
+				err = errUnexpectedInstruction
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(i.stack) > maxStackHeight {
+			return errStackOverflow
+		}
+	}
+	return i.unwind(base, numResults)
+}
+
+// branch to the label [depth] labels up from the innermost label. Returns the
+// remaining labels and the instruction to continue at.
+func (i *Instance) branch(labels []label, depth int) ([]label, int, error) {
+	if depth >= len(labels) {
+		return nil, 0, errInvalidLabel
+	}
+	index := len(labels) - 1 - depth
+	l := labels[index]
+	if err := i.unwind(l.height, l.arity); err != nil {
+		return nil, 0, err
+	}
+	if l.loop {
+		// Branching to a loop restarts it, so the loop is still entered
+		return labels[:index+1], l.pc, nil
+	}
+	return labels[:index], l.pc, nil
+}
+
+// pop the top of the operand stack. The caller must have checked that the
+// stack isn't empty.
+func (i *Instance) pop() uint64 {
+	value := i.stack[len(i.stack)-1]
+	i.stack = i.stack[:len(i.stack)-1]
+	return value
+}
+
+// unary replaces the top of the operand stack with [f] applied to it
+func (i *Instance) unary(f func(uint64) uint64) {
+	i.stack[len(i.stack)-1] = f(i.stack[len(i.stack)-1])
+}
+
+// grow the memory by [delta] pages. Returns the previous number of pages, or
+// -1 as an i32 if the memory can't grow that much.
+func (i *Instance) grow(delta uint32) (uint32, error) {
+	current := uint32(len(i.memory) / PageSize)
+	limit := i.config.MaxPages
+	if mem := i.module.memory; mem.hasMax && mem.max < limit {
+		limit = mem.max
+	}
+	if uint64(current)+uint64(delta) > uint64(limit) {
+		return math.MaxUint32, nil
+	}
+	if err := i.UseGas(uint64(delta) * pageGas); err != nil {
+		return 0, err
+	}
+	i.memory = append(i.memory, make([]byte, int(delta)*PageSize)...)
+	return current, nil
+}
+
+// memoryAccess executes the load or store [op] with the static offset
+// [offset]
+func (i *Instance) memoryAccess(op byte, offset uint64) error {
+	value := uint64(0)
+	if op >= 0x36 {
+		// Stores pop the value to store before the address
+		value = i.pop()
+	}
+	size := uint64(8)
+	switch op {
+	case 0x28, 0x34, 0x35, 0x36, 0x3e:
+		size = 4
+	case 0x2c, 0x2d, 0x30, 0x31, 0x3a, 0x3c:
+		size = 1
+	case 0x2e, 0x2f, 0x32, 0x33, 0x3b, 0x3d:
+		size = 2
+	}
+	address := uint64(uint32(i.pop())) + offset
+	if address+size > uint64(len(i.memory)) {
+		return errOutOfBounds
+	}
+	mem := i.memory[address:]
+
+	switch op {
+	case 0x28: // i32.load
+		i.stack = append(i.stack, uint64(binary.LittleEndian.Uint32(mem)))
+	case 0x29: // i64.load
+		i.stack = append(i.stack, binary.LittleEndian.Uint64(mem))
+	case 0x2c: // i32.load8_s
+		i.stack = append(i.stack, uint64(uint32(int32(int8(mem[0])))))
+	case 0x2d, 0x31: // i32.load8_u, i64.load8_u
+		i.stack = append(i.stack, uint64(mem[0]))
+	case 0x2e: // i32.load16_s
+		i.stack = append(i.stack, uint64(uint32(int32(int16(binary.LittleEndian.Uint16(mem))))))
+	case 0x2f, 0x33: // i32.load16_u, i64.load16_u
+		i.stack = append(i.stack, uint64(binary.LittleEndian.Uint16(mem)))
+	case 0x30: // i64.load8_s
+		i.stack = append(i.stack, uint64(int64(int8(mem[0]))))
+	case 0x32: // i64.load16_s
+		i.stack = append(i.stack, uint64(int64(int16(binary.LittleEndian.Uint16(mem)))))
+	case 0x34: // i64.load32_s
+		i.stack = append(i.stack, uint64(int64(int32(binary.LittleEndian.Uint32(mem)))))
+	case 0x35: // i64.load32_u
+		i.stack = append(i.stack, uint64(binary.LittleEndian.Uint32(mem)))
+	case 0x36, 0x3e: // i32.store, i64.store32
+		binary.LittleEndian.PutUint32(mem, uint32(value))
+	case 0x37: // i64.store
+		binary.LittleEndian.PutUint64(mem, value)
+	case 0x3a, 0x3c: // i32.store8, i64.store8
+		mem[0] = byte(value)
+	case 0x3b, 0x3d: // i32.store16, i64.store16
+		binary.LittleEndian.PutUint16(mem, uint16(value))
+	default:
+		return errUnexpectedInstruction
+	}
+	return nil
+}
+
+// compare [a] and [b] with the comparison that is [index] places after eq. If
+// [is32], the values are i32s.
+func compare(index byte, a, b uint64, is32 bool) uint64 {
+	sa, sb := int64(a), int64(b)
+	if is32 {
+		a, b = uint64(uint32(a)), uint64(uint32(b))
+		sa, sb = int64(int32(a)), int64(int32(b))
+	}
+	switch index {
+	case 0: // eq
+		return boolToValue(a == b)
+	case 1: // ne
+		return boolToValue(a != b)
+	case 2: // lt_s
+		return boolToValue(sa < sb)
+	case 3: // lt_u
+		return boolToValue(a < b)
+	case 4: // gt_s
+		return boolToValue(sa > sb)
+	case 5: // gt_u
+		return boolToValue(a > b)
+	case 6: // le_s
+		return boolToValue(sa <= sb)
+	case 7: // le_u
+		return boolToValue(a <= b)
+	case 8: // ge_s
+		return boolToValue(sa >= sb)
+	default: // ge_u
+		return boolToValue(a >= b)
+	}
+}
+
+func i32Binary(op byte, a, b uint32) (uint32, error) {
+	switch op {
+	case 0x6a: // i32.add
+		return a + b, nil
+	case 0x6b: // i32.sub
+		return a - b, nil
+	case 0x6c: // i32.mul
+		return a * b, nil
+	case 0x6d: // i32.div_s
+		if b == 0 {
+			return 0, errDivideByZero
+		}
+		if int32(a) == math.MinInt32 && int32(b) == -1 {
+			return 0, errIntegerOverflow
+		}
+		return uint32(int32(a) / int32(b)), nil
+	case 0x6e: // i32.div_u
+		if b == 0 {
+			return 0, errDivideByZero
+		}
+		return a / b, nil
+	case 0x6f: // i32.rem_s
+		if b == 0 {
+			return 0, errDivideByZero
+		}
+		if int32(b) == -1 {
+			return 0, nil
+		}
+		return uint32(int32(a) % int32(b)), nil
+	case 0x70: // i32.rem_u
+		if b == 0 {
+			return 0, errDivideByZero
+		}
+		return a % b, nil
+	case 0x71: // i32.and
+		return a & b, nil
+	case 0x72: // i32.or
+		return a | b, nil
+	case 0x73: // i32.xor
+		return a ^ b, nil
+	case 0x74: // i32.shl
+		return a << (b % 32), nil
+	case 0x75: // i32.shr_s
+		return uint32(int32(a) >> (b % 32)), nil
+	case 0x76: // i32.shr_u
+		return a >> (b % 32), nil
+	case 0x77: // i32.rotl
+		return bits.RotateLeft32(a, int(b%32)), nil
+	case 0x78: // i32.rotr
+		return bits.RotateLeft32(a, -int(b%32)), nil
+	default:
+		return 0, errUnexpectedInstruction
+	}
+}
+
+func i64Binary(op byte, a, b uint64) (uint64, error) {
+	switch op {
+	case 0x7c: // i64.add
+		return a + b, nil
+	case 0x7d: // i64.sub
+		return a - b, nil
+	case 0x7e: // i64.mul
+		return a * b, nil
+	case 0x7f: // i64.div_s
+		if b == 0 {
+			return 0, errDivideByZero
+		}
+		if int64(a) == math.MinInt64 && int64(b) == -1 {
+			return 0, errIntegerOverflow
+		}
+		return uint64(int64(a) / int64(b)), nil
+	case 0x80: // i64.div_u
+		if b == 0 {
+			return 0, errDivideByZero
+		}
+		return a / b, nil
+	case 0x81: // i64.rem_s
+		if b == 0 {
+			return 0, errDivideByZero
+		}
+		if int64(b) == -1 {
+			return 0, nil
+		}
+		return uint64(int64(a) % int64(b)), nil
+	case 0x82: // i64.rem_u
+		if b == 0 {
+			return 0, errDivideByZero
+		}
+		return a % b, nil
+	case 0x83: // i64.and
+		return a & b, nil
+	case 0x84: // i64.or
+		return a | b, nil
+	case 0x85: // i64.xor
+		return a ^ b, nil
+	case 0x86: // i64.shl
+		return a << (b % 64), nil
+	case 0x87: // i64.shr_s
+		return uint64(int64(a) >> (b % 64)), nil
+	case 0x88: // i64.shr_u
+		return a >> (b % 64), nil
+	case 0x89: // i64.rotl
+		return bits.RotateLeft64(a, int(b%64)), nil
+	case 0x8a: // i64.rotr
+		return bits.RotateLeft64(a, -int(b%64)), nil
+	default:
+		return 0, errUnexpectedInstruction
+	}
+}
+
+func boolToValue(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,246 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasm
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// instructionGas is the gas consumed by executing an instruction
+	instructionGas = 1
+
+	// pageGas is the gas consumed by each page of memory that is allocated
+	pageGas = 1 << 10
+
+	// maxStackHeight bounds the number of values on the operand stack
+	maxStackHeight = 1 << 16
+)
+
+var (
+	// ErrOutOfGas is returned when execution reaches its gas limit
+	ErrOutOfGas = errors.New("out of gas")
+
+	// ErrUnknownFunction is returned when calling a function that isn't
+	// exported
+	ErrUnknownFunction = errors.New("function isn't exported")
+
+	errUnknownImport         = errors.New("unknown import")
+	errImportSignature       = errors.New("import has the wrong signature")
+	errMemoryTooLarge        = errors.New("initial memory exceeds the limit")
+	errDataOutOfBounds       = errors.New("data segment doesn't fit in memory")
+	errWrongNumArgs          = errors.New("wrong number of arguments")
+	errWrongNumResults       = errors.New("host function returned the wrong number of results")
+	errUnreachable           = errors.New("unreachable executed")
+	errOutOfBounds           = errors.New("out of bounds memory access")
+	errDivideByZero          = errors.New("integer divide by zero")
+	errIntegerOverflow       = errors.New("integer overflow")
+	errStackUnderflow        = errors.New("operand stack underflow")
+	errStackOverflow         = errors.New("operand stack overflow")
+	errCallStackExhausted    = errors.New("call stack exhausted")
+	errUnexpectedInstruction = errors.New("unexpected instruction")
+)
+
+// HostFunction is a function that is implemented by the embedder and imported
+// by a module
+type HostFunction struct {
+	Type FuncType
+	// Call is passed the instance that called the function and its arguments.
+	// Returning an error aborts the execution of the instance.
+	Call func(instance *Instance, args []uint64) ([]uint64, error)
+}
+
+// Imports maps module name, then field name, to the functions that may be
+// imported
+type Imports map[string]map[string]HostFunction
+
+// Config bounds the resources that an instance may use
+type Config struct {
+	// GasLimit is the gas available to the instance. Instantiation, as well as
+	// each call, consumes gas.
+	GasLimit uint64
+	// MaxPages bounds the number of pages of the instance's memory
+	MaxPages uint32
+	// MaxCallDepth bounds the number of nested calls
+	MaxCallDepth int
+}
+
+// Instance is an instantiated module. An instance isn't safe for concurrent
+// use.
+type Instance struct {
+	module  *Module
+	config  Config
+	host    []HostFunction // the imported functions
+	memory  []byte
+	globals []uint64
+
+	gasUsed uint64
+	depth   int
+	stack   []uint64
+}
+
+// label is the target of a branch
+type label struct {
+	pc     int  // the instruction to continue at
+	height int  // the height of the operand stack when the label was entered
+	arity  int  // the number of values that a branch to the label carries
+	loop   bool // if true, branching to the label doesn't exit it
+}
+
+// Instantiate [module], resolving its imports with [imports]
+func Instantiate(module *Module, imports Imports, config Config) (*Instance, error) {
+	i := &Instance{
+		module:  module,
+		config:  config,
+		host:    make([]HostFunction, len(module.imports)),
+		globals: make([]uint64, len(module.globals)),
+	}
+	for j, imp := range module.imports {
+		fn, ok := imports[imp.module][imp.name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s.%s", errUnknownImport, imp.module, imp.name)
+		}
+		if typ := module.types[imp.typ]; !typ.Equals(fn.Type) {
+			return nil, fmt.Errorf("%w: %s.%s expects %s but is %s", errImportSignature, imp.module, imp.name, typ, fn.Type)
+		}
+		i.host[j] = fn
+	}
+	for j, g := range module.globals {
+		i.globals[j] = g.init
+	}
+	if mem := module.memory; mem != nil {
+		if mem.min > config.MaxPages {
+			return nil, errMemoryTooLarge
+		}
+		if err := i.UseGas(uint64(mem.min) * pageGas); err != nil {
+			return nil, err
+		}
+		i.memory = make([]byte, int(mem.min)*PageSize)
+	}
+	for _, data := range module.data {
+		if uint64(data.offset)+uint64(len(data.bytes)) > uint64(len(i.memory)) {
+			return nil, errDataOutOfBounds
+		}
+		copy(i.memory[data.offset:], data.bytes)
+	}
+	return i, nil
+}
+
+// Call the exported function [name] with [args]. Arguments and results of
+// type i32 are zero extended to 64 bits.
+func (i *Instance) Call(name string, args ...uint64) ([]uint64, error) {
+	index, ok := i.module.exports[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFunction, name)
+	}
+	typ := i.module.funcType(index)
+	if len(args) != len(typ.Params) {
+		return nil, errWrongNumArgs
+	}
+
+	i.stack = i.stack[:0]
+	for j, arg := range args {
+		i.push(typ.Params[j], arg)
+	}
+	if err := i.call(uint32(index)); err != nil {
+		return nil, err
+	}
+	results := make([]uint64, len(i.stack))
+	copy(results, i.stack)
+	return results, nil
+}
+
+// GasUsed returns the gas consumed so far
+func (i *Instance) GasUsed() uint64 { return i.gasUsed }
+
+// UseGas consumes [amount] gas. If that exceeds the gas limit, all the
+// remaining gas is consumed and ErrOutOfGas is returned.
+func (i *Instance) UseGas(amount uint64) error {
+	if amount > i.config.GasLimit-i.gasUsed {
+		i.gasUsed = i.config.GasLimit
+		return ErrOutOfGas
+	}
+	i.gasUsed += amount
+	return nil
+}
+
+// Read returns a copy of [length] bytes of memory starting at [ptr]
+func (i *Instance) Read(ptr, length uint32) ([]byte, error) {
+	if uint64(ptr)+uint64(length) > uint64(len(i.memory)) {
+		return nil, errOutOfBounds
+	}
+	b := make([]byte, length)
+	copy(b, i.memory[ptr:])
+	return b, nil
+}
+
+// Write [b] to memory starting at [ptr]
+func (i *Instance) Write(ptr uint32, b []byte) error {
+	if uint64(ptr)+uint64(len(b)) > uint64(len(i.memory)) {
+		return errOutOfBounds
+	}
+	copy(i.memory[ptr:], b)
+	return nil
+}
+
+// push [value] as a value of type [t]
+func (i *Instance) push(t ValueType, value uint64) {
+	if t == I32 {
+		value = uint64(uint32(value))
+	}
+	i.stack = append(i.stack, value)
+}
+
+// call the function with index [index], taking its arguments from the operand
+// stack and pushing its results
+func (i *Instance) call(index uint32) error {
+	typ := i.module.funcType(index)
+	numParams := len(typ.Params)
+	if len(i.stack) < numParams {
+		return errStackUnderflow
+	}
+	args := i.stack[len(i.stack)-numParams:]
+
+	if int(index) < len(i.host) {
+		params := make([]uint64, numParams)
+		copy(params, args)
+		i.stack = i.stack[:len(i.stack)-numParams]
+
+		results, err := i.host[index].Call(i, params)
+		if err != nil {
+			return err
+		}
+		if len(results) != len(typ.Results) {
+			return errWrongNumResults
+		}
+		for j, result := range results {
+			i.push(typ.Results[j], result)
+		}
+		return nil
+	}
+
+	if i.depth >= i.config.MaxCallDepth {
+		return errCallStackExhausted
+	}
+	f := &i.module.functions[int(index)-len(i.host)]
+	locals := make([]uint64, numParams+len(f.locals))
+	copy(locals, args)
+	i.stack = i.stack[:len(i.stack)-numParams]
+
+	i.depth++
+	err := i.execute(f, locals, len(typ.Results))
+	i.depth--
+	return err
+}
+
+// unwind the operand stack to [height], keeping the top [arity] values
+func (i *Instance) unwind(height, arity int) error {
+	if len(i.stack) < height+arity {
+		return errStackUnderflow
+	}
+	copy(i.stack[height:], i.stack[len(i.stack)-arity:])
+	i.stack = i.stack[:height+arity]
+	return nil
+}
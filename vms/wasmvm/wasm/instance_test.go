@@ -0,0 +1,287 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var testConfig = Config{
+	GasLimit:     1 << 20,
+	MaxPages:     4,
+	MaxCallDepth: 64,
+}
+
+func instantiate(t *testing.T, b []byte, imports Imports, config Config) *Instance {
+	m, err := Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := Instantiate(m, imports, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+func call(t *testing.T, i *Instance, args ...uint64) uint64 {
+	results, err := i.Call("f", args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result but got %d", len(results))
+	}
+	return results[0]
+}
+
+func TestFactorial(t *testing.T) {
+	i := instantiate(t, singleFunc(sig([]ValueType{I64}, []ValueType{I64}), 0, []ValueType{I64},
+		0x42, 0x01, // i64.const 1
+		0x21, 0x01, // local.set 1
+		0x02, 0x40, // block
+		0x03, 0x40, // loop
+		0x20, 0x00, // local.get 0
+		0x50,       // i64.eqz
+		0x0d, 0x01, // br_if 1
+		0x20, 0x01, // local.get 1
+		0x20, 0x00, // local.get 0
+		0x7e,       // i64.mul
+		0x21, 0x01, // local.set 1
+		0x20, 0x00, // local.get 0
+		0x42, 0x01, // i64.const 1
+		0x7d,       // i64.sub
+		0x21, 0x00, // local.set 0
+		0x0c, 0x00, // br 0
+		0x0b,       // end
+		0x0b,       // end
+		0x20, 0x01, // local.get 1
+		0x0b, // end
+	), nil, testConfig)
+
+	if result := call(t, i, 20); result != 2432902008176640000 {
+		t.Fatalf("Wrong result %d", result)
+	}
+	if i.GasUsed() == 0 {
+		t.Fatalf("Execution should have used gas")
+	}
+}
+
+func TestRecursiveFibonacci(t *testing.T) {
+	i := instantiate(t, singleFunc(sig([]ValueType{I32}, []ValueType{I32}), 0, nil,
+		0x20, 0x00, // local.get 0
+		0x41, 0x02, // i32.const 2
+		0x49,       // i32.lt_u
+		0x04, 0x7f, // if (result i32)
+		0x20, 0x00, // local.get 0
+		0x05,       // else
+		0x20, 0x00, // local.get 0
+		0x41, 0x01, // i32.const 1
+		0x6b,       // i32.sub
+		0x10, 0x00, // call 0
+		0x20, 0x00, // local.get 0
+		0x41, 0x02, // i32.const 2
+		0x6b,       // i32.sub
+		0x10, 0x00, // call 0
+		0x6a, // i32.add
+		0x0b, // end
+		0x0b, // end
+	), nil, testConfig)
+
+	if result := call(t, i, 10); result != 55 {
+		t.Fatalf("Wrong result %d", result)
+	}
+}
+
+func TestBrTable(t *testing.T) {
+	i := instantiate(t, singleFunc(sig([]ValueType{I32}, []ValueType{I32}), 0, nil,
+		0x02, 0x40, // block
+		0x02, 0x40, // block
+		0x20, 0x00, // local.get 0
+		0x0e, 0x01, 0x00, 0x01, // br_table 0 1
+		0x0b,       // end
+		0x41, 0x0a, // i32.const 10
+		0x0f,       // return
+		0x0b,       // end
+		0x41, 0x14, // i32.const 20
+		0x0b, // end
+	), nil, testConfig)
+
+	if result := call(t, i, 0); result != 10 {
+		t.Fatalf("Wrong result %d", result)
+	}
+	if result := call(t, i, 7); result != 20 {
+		t.Fatalf("Wrong result %d", result)
+	}
+}
+
+func TestSignedArithmetic(t *testing.T) {
+	i := instantiate(t, singleFunc(sig([]ValueType{I32, I32}, []ValueType{I32}), 0, nil,
+		0x20, 0x00, // local.get 0
+		0x20, 0x01, // local.get 1
+		0x6d, // i32.div_s
+		0x0b, // end
+	), nil, testConfig)
+
+	if result := call(t, i, uint64(0xfffffff6), 3); result != 0xfffffffd { // -10 / 3 = -3
+		t.Fatalf("Wrong result %d", result)
+	}
+	if _, err := i.Call("f", 1, 0); !errors.Is(err, errDivideByZero) {
+		t.Fatalf("Expected %s but got %v", errDivideByZero, err)
+	}
+	if _, err := i.Call("f", 0x80000000, 0xffffffff); !errors.Is(err, errIntegerOverflow) {
+		t.Fatalf("Expected %s but got %v", errIntegerOverflow, err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	i := instantiate(t, singleFunc(sig(nil, []ValueType{I32}), 1, nil,
+		0x41, 0x08, // i32.const 8
+		0x42, 0x7e, // i64.const -2
+		0x37, 0x03, 0x00, // i64.store
+		0x41, 0x08, // i32.const 8
+		0x2c, 0x00, 0x00, // i32.load8_s
+		0x0b, // end
+	), nil, testConfig)
+
+	if result := call(t, i); result != 0xfffffffe {
+		t.Fatalf("Wrong result 0x%x", result)
+	}
+	stored, err := i.Read(8, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}) {
+		t.Fatalf("Wrong memory contents %x", stored)
+	}
+	if _, err := i.Read(PageSize-1, 2); err == nil {
+		t.Fatalf("Should have errored due to reading out of bounds")
+	}
+}
+
+func TestMemoryOutOfBounds(t *testing.T) {
+	i := instantiate(t, singleFunc(sig(nil, []ValueType{I32}), 1, nil,
+		0x41, 0x7f, // i32.const -1
+		0x28, 0x02, 0x00, // i32.load
+		0x0b, // end
+	), nil, testConfig)
+
+	if _, err := i.Call("f"); !errors.Is(err, errOutOfBounds) {
+		t.Fatalf("Expected %s but got %v", errOutOfBounds, err)
+	}
+}
+
+func TestMemoryGrow(t *testing.T) {
+	i := instantiate(t, singleFunc(sig([]ValueType{I32}, []ValueType{I32}), 1, nil,
+		0x20, 0x00, // local.get 0
+		0x40, 0x00, // memory.grow
+		0x0b, // end
+	), nil, testConfig)
+
+	if result := call(t, i, 2); result != 1 {
+		t.Fatalf("Growing should have returned the old size, but returned %d", result)
+	}
+	if result := call(t, i, 2); result != 0xffffffff {
+		t.Fatalf("Growing past the limit should have failed, but returned %d", result)
+	}
+	if _, err := i.Read(3*PageSize-1, 1); err != nil {
+		t.Fatalf("Memory should have grown: %s", err)
+	}
+}
+
+func TestOutOfGas(t *testing.T) {
+	config := testConfig
+	config.GasLimit = 1000
+	i := instantiate(t, singleFunc(sig(nil, nil), 0, nil,
+		0x03, 0x40, // loop
+		0x0c, 0x00, // br 0
+		0x0b, // end
+		0x0b, // end
+	), nil, config)
+
+	if _, err := i.Call("f"); err != ErrOutOfGas {
+		t.Fatalf("Expected %s but got %v", ErrOutOfGas, err)
+	}
+	if gas := i.GasUsed(); gas != config.GasLimit {
+		t.Fatalf("Should have used all the gas, but used %d", gas)
+	}
+}
+
+func TestCallStackExhausted(t *testing.T) {
+	i := instantiate(t, singleFunc(sig(nil, nil), 0, nil,
+		0x10, 0x00, // call 0
+		0x0b, // end
+	), nil, testConfig)
+
+	if _, err := i.Call("f"); !errors.Is(err, errCallStackExhausted) {
+		t.Fatalf("Expected %s but got %v", errCallStackExhausted, err)
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	i := instantiate(t, singleFunc(sig(nil, nil), 0, nil, 0x00, 0x0b), nil, testConfig)
+
+	if _, err := i.Call("f"); !errors.Is(err, errUnreachable) {
+		t.Fatalf("Expected %s but got %v", errUnreachable, err)
+	}
+	if _, err := i.Call("g"); !errors.Is(err, ErrUnknownFunction) {
+		t.Fatalf("Expected %s but got %v", ErrUnknownFunction, err)
+	}
+}
+
+func TestHostFunctions(t *testing.T) {
+	echoSig := sig([]ValueType{I32, I32}, []ValueType{I32})
+	b := module(
+		section(typeSection, echoSig, sig(nil, []ValueType{I32})),
+		section(importSection, concat(name("env"), name("echo"), []byte{funcKind}, leb(0))),
+		section(functionSection, leb(1)),
+		section(memorySection, []byte{0x00, 0x01}),
+		section(exportSection, export("f", 1)),
+		section(codeSection, body(nil,
+			0x41, 0x10, // i32.const 16
+			0x41, 0x02, // i32.const 2
+			0x10, 0x00, // call 0
+			0x0b, // end
+		)),
+		section(dataSection, concat(leb(0), []byte{0x41, 0x10, 0x0b}, name("hi"))),
+	)
+
+	echoed := []byte(nil)
+	imports := Imports{"env": {"echo": HostFunction{
+		Type: FuncType{Params: []ValueType{I32, I32}, Results: []ValueType{I32}},
+		Call: func(i *Instance, args []uint64) ([]uint64, error) {
+			b, err := i.Read(uint32(args[0]), uint32(args[1]))
+			if err != nil {
+				return nil, err
+			}
+			echoed = b
+			return []uint64{uint64(len(b))}, i.UseGas(100)
+		},
+	}}}
+
+	i := instantiate(t, b, imports, testConfig)
+	if result := call(t, i); result != 2 {
+		t.Fatalf("Wrong result %d", result)
+	}
+	if !bytes.Equal(echoed, []byte("hi")) {
+		t.Fatalf("Host function read %q", echoed)
+	}
+	if i.GasUsed() < 100 {
+		t.Fatalf("Gas used by the host function should have been counted")
+	}
+
+	m, err := Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Instantiate(m, nil, testConfig); !errors.Is(err, errUnknownImport) {
+		t.Fatalf("Expected %s but got %v", errUnknownImport, err)
+	}
+	wrongSig := Imports{"env": {"echo": HostFunction{Type: FuncType{}}}}
+	if _, err := Instantiate(m, wrongSig, testConfig); !errors.Is(err, errImportSignature) {
+		t.Fatalf("Expected %s but got %v", errImportSignature, err)
+	}
+}
@@ -0,0 +1,184 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasm
+
+import (
+	"fmt"
+)
+
+// Opcodes that are referred to by name. The numeric instructions are only
+// referred to in the interpreter, where they are commented with their names.
+const (
+	opUnreachable  byte = 0x00
+	opNop          byte = 0x01
+	opBlock        byte = 0x02
+	opLoop         byte = 0x03
+	opIf           byte = 0x04
+	opElse         byte = 0x05
+	opEnd          byte = 0x0b
+	opBr           byte = 0x0c
+	opBrIf         byte = 0x0d
+	opBrTable      byte = 0x0e
+	opReturn       byte = 0x0f
+	opCall         byte = 0x10
+	opDrop         byte = 0x1a
+	opSelect       byte = 0x1b
+	opLocalGet     byte = 0x20
+	opLocalSet     byte = 0x21
+	opLocalTee     byte = 0x22
+	opGlobalGet    byte = 0x23
+	opGlobalSet    byte = 0x24
+	opI32Load      byte = 0x28
+	opI64Store32   byte = 0x3e
+	opMemorySize   byte = 0x3f
+	opMemoryGrow   byte = 0x40
+	opI32Const     byte = 0x41
+	opI64Const     byte = 0x42
+	opI32Eqz       byte = 0x45
+	opI64GeU       byte = 0x5a
+	opI32Clz       byte = 0x67
+	opI64Rotr      byte = 0x8a
+	opI32WrapI64   byte = 0xa7
+	opI64ExtendI32 byte = 0xac
+	opI64ExtendU32 byte = 0xad
+	opI32Extend8S  byte = 0xc0
+	opI64Extend32S byte = 0xc4
+
+	// Floating point loads and stores, which lie in the memory instruction
+	// range but aren't supported
+	opF32Load  byte = 0x2a
+	opF64Load  byte = 0x2b
+	opF32Store byte = 0x38
+	opF64Store byte = 0x39
+
+	// emptyBlock is the block type of a block that doesn't have a result
+	emptyBlock byte = 0x40
+)
+
+// instruction is a decoded instruction. The meaning of the immediates depends
+// on the opcode. For a block, loop or if, [a] is the number of results, [b] is
+// the index of the matching end and [c] is the index of the matching else, or
+// 0. For an else, [b] is the index of the matching end. For a br_table, [table]
+// holds the label depths, followed by the default depth. For every other
+// instruction, [a] is its only immediate: a label depth, function, local or
+// global index, memory offset or constant.
+type instruction struct {
+	op      byte
+	a, b, c uint64
+	table   []uint32
+}
+
+// decodeInstructions decodes and validates the body of a function that has
+// [numLocals] locals, including its parameters
+func (m *Module) decodeInstructions(r *reader, numLocals int) ([]instruction, error) {
+	code := []instruction(nil)
+	control := []int(nil) // indices of the enclosing block, loop and if instructions
+	for {
+		if r.err != nil {
+			return nil, r.err
+		}
+		if r.len() == 0 {
+			return nil, errUnexpectedEnd
+		}
+
+		in := instruction{op: r.byte()}
+		switch op := in.op; {
+		case op == opBlock || op == opLoop || op == opIf:
+			switch blockType := r.byte(); blockType {
+			case emptyBlock:
+			case byte(I32), byte(I64):
+				in.a = 1
+			default:
+				return nil, errUnsupportedBlock
+			}
+			control = append(control, len(code))
+		case op == opElse:
+			if len(control) == 0 {
+				return nil, errUnexpectedElse
+			}
+			ifIndex := control[len(control)-1]
+			if code[ifIndex].op != opIf || code[ifIndex].c != 0 {
+				return nil, errUnexpectedElse
+			}
+			code[ifIndex].c = uint64(len(code))
+		case op == opEnd:
+			if len(control) == 0 {
+				// This is the end of the function body
+				if r.len() != 0 {
+					return nil, errTrailingInstruction
+				}
+				return append(code, in), nil
+			}
+			start := control[len(control)-1]
+			control = control[:len(control)-1]
+			code[start].b = uint64(len(code))
+			if elseIndex := code[start].c; elseIndex != 0 {
+				code[elseIndex].b = uint64(len(code))
+			}
+		case op == opBr || op == opBrIf:
+			in.a = uint64(r.u32())
+			if in.a > uint64(len(control)) {
+				return nil, errInvalidLabel
+			}
+		case op == opBrTable:
+			count := r.u32()
+			if int64(count) > int64(r.len()) {
+				return nil, errUnexpectedEOF
+			}
+			in.table = make([]uint32, count+1)
+			for i := range in.table {
+				in.table[i] = r.u32()
+				if in.table[i] > uint32(len(control)) {
+					return nil, errInvalidLabel
+				}
+			}
+		case op == opCall:
+			in.a = uint64(r.u32())
+			if in.a >= uint64(m.numFuncs()) {
+				return nil, errInvalidFuncIndex
+			}
+		case op >= opLocalGet && op <= opLocalTee:
+			in.a = uint64(r.u32())
+			if in.a >= uint64(numLocals) {
+				return nil, fmt.Errorf("invalid local index %d", in.a)
+			}
+		case op == opGlobalGet || op == opGlobalSet:
+			in.a = uint64(r.u32())
+			if in.a >= uint64(len(m.globals)) {
+				return nil, errInvalidGlobalIndex
+			}
+			if op == opGlobalSet && !m.globals[in.a].mutable {
+				return nil, errImmutableGlobal
+			}
+		case op >= opI32Load && op <= opI64Store32:
+			if op == opF32Load || op == opF64Load || op == opF32Store || op == opF64Store {
+				return nil, fmt.Errorf("unsupported instruction 0x%x", op)
+			}
+			if m.memory == nil {
+				return nil, errMissingMemory
+			}
+			r.u32() // the alignment hint doesn't affect execution
+			in.a = uint64(r.u32())
+		case op == opMemorySize || op == opMemoryGrow:
+			if m.memory == nil {
+				return nil, errMissingMemory
+			}
+			if r.byte() != 0 {
+				return nil, errBadReservedByte
+			}
+		case op == opI32Const:
+			in.a = uint64(uint32(r.s32()))
+		case op == opI64Const:
+			in.a = uint64(r.s64())
+		case op == opUnreachable, op == opNop, op == opReturn, op == opDrop, op == opSelect,
+			op >= opI32Eqz && op <= opI64GeU,
+			op >= opI32Clz && op <= opI64Rotr,
+			op == opI32WrapI64, op == opI64ExtendI32, op == opI64ExtendU32,
+			op >= opI32Extend8S && op <= opI64Extend32S:
+		default:
+			return nil, fmt.Errorf("unsupported instruction 0x%x", op)
+		}
+		code = append(code, in)
+	}
+}
@@ -0,0 +1,580 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package wasm decodes and executes WebAssembly modules.
+//
+// Execution is deterministic and metered: every instruction consumes gas, and a
+// call fails once its gas limit is reached. Since the results of floating
+// point instructions may differ between platforms, only the integer subset of
+// the WebAssembly MVP is supported. Modules that use floating point
+// instructions, element segments or indirect calls are rejected when they are
+// decoded.
+package wasm
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	magic   = 0x6d736100 // "\0asm"
+	version = 1
+
+	// PageSize is the number of bytes in a page of linear memory
+	PageSize = 1 << 16
+
+	// maxPages is the number of pages addressable by a 32 bit memory
+	maxPages = 1 << 16
+
+	// maxLocals bounds the number of locals, including parameters, of a
+	// function, which bounds the memory allocated by a call
+	maxLocals = 1 << 12
+)
+
+// ValueType is the type of a value
+type ValueType byte
+
+// The value types that may be used by a module
+const (
+	I32 ValueType = 0x7f
+	I64 ValueType = 0x7e
+)
+
+// Section IDs
+const (
+	customSection byte = iota
+	typeSection
+	importSection
+	functionSection
+	tableSection
+	memorySection
+	globalSection
+	exportSection
+	startSection
+	elementSection
+	codeSection
+	dataSection
+	dataCountSection
+)
+
+// sectionOrder is the position that each section must appear in. The data
+// count section was added after the others, so its ID doesn't match its
+// position.
+var sectionOrder = [...]int{
+	typeSection:      1,
+	importSection:    2,
+	functionSection:  3,
+	tableSection:     4,
+	memorySection:    5,
+	globalSection:    6,
+	exportSection:    7,
+	startSection:     8,
+	elementSection:   9,
+	dataCountSection: 10,
+	codeSection:      11,
+	dataSection:      12,
+}
+
+// External kinds
+const (
+	funcKind byte = iota
+	tableKind
+	memoryKind
+	globalKind
+)
+
+var (
+	errBadMagic            = errors.New("not a WebAssembly module")
+	errBadVersion          = errors.New("unsupported WebAssembly version")
+	errSectionOrder        = errors.New("sections are out of order")
+	errSectionSize         = errors.New("section size mismatch")
+	errUnsupportedImport   = errors.New("only functions may be imported")
+	errUnsupportedType     = errors.New("unsupported value type")
+	errBadFuncType         = errors.New("malformed function type")
+	errMultipleMemories    = errors.New("a module may have at most one memory")
+	errMultipleTables      = errors.New("a module may have at most one table")
+	errBadLimits           = errors.New("malformed limits")
+	errBadInitExpr         = errors.New("malformed constant expression")
+	errDuplicateExport     = errors.New("duplicate export name")
+	errFunctionCount       = errors.New("function and code section lengths differ")
+	errMissingMemory       = errors.New("module doesn't have a memory")
+	errInvalidTypeIndex    = errors.New("invalid type index")
+	errInvalidFuncIndex    = errors.New("invalid function index")
+	errInvalidGlobalIndex  = errors.New("invalid global index")
+	errInvalidMemoryIndex  = errors.New("invalid memory index")
+	errInvalidExportIndex  = errors.New("invalid export index")
+	errUnsupportedSection  = errors.New("unsupported section")
+	errTooManyLocals       = errors.New("too many locals")
+	errImmutableGlobal     = errors.New("global is immutable")
+	errUnexpectedEnd       = errors.New("function body doesn't end with end")
+	errTrailingInstruction = errors.New("instructions after the end of a function body")
+	errUnexpectedElse      = errors.New("else without a matching if")
+	errInvalidLabel        = errors.New("invalid branch depth")
+	errUnsupportedBlock    = errors.New("unsupported block type")
+	errBadReservedByte     = errors.New("reserved byte must be zero")
+)
+
+// FuncType is the signature of a function
+type FuncType struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+// Equals returns true if [t] and [other] are the same signature
+func (t FuncType) Equals(other FuncType) bool {
+	if len(t.Params) != len(other.Params) || len(t.Results) != len(other.Results) {
+		return false
+	}
+	for i, param := range t.Params {
+		if param != other.Params[i] {
+			return false
+		}
+	}
+	for i, result := range t.Results {
+		if result != other.Results[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t FuncType) String() string { return fmt.Sprintf("%v -> %v", t.Params, t.Results) }
+
+func (t ValueType) String() string {
+	switch t {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	default:
+		return fmt.Sprintf("0x%x", byte(t))
+	}
+}
+
+type funcImport struct {
+	module, name string
+	typ          uint32
+}
+
+type function struct {
+	typ    uint32
+	locals []ValueType // not including the parameters
+	code   []instruction
+}
+
+type limits struct {
+	min, max uint32
+	hasMax   bool
+}
+
+type global struct {
+	typ     ValueType
+	mutable bool
+	init    uint64
+}
+
+type dataSegment struct {
+	offset uint32
+	bytes  []byte
+}
+
+// Module is a decoded and validated WebAssembly module. A module is immutable,
+// so it may be instantiated any number of times, concurrently.
+type Module struct {
+	types     []FuncType
+	imports   []funcImport
+	functions []function
+	memory    *limits
+	globals   []global
+	exports   map[string]uint32 // exported function name -> function index
+	data      []dataSegment
+}
+
+// Decode parses and validates the binary encoding of a module
+func Decode(b []byte) (*Module, error) {
+	r := &reader{b: b}
+	if r.fixedU32() != magic {
+		return nil, errBadMagic
+	}
+	if r.fixedU32() != version {
+		return nil, errBadVersion
+	}
+
+	m := &Module{exports: make(map[string]uint32)}
+	funcTypes := []uint32(nil)
+	codeSeen := false
+	lastSection := 0
+	for r.len() > 0 {
+		id := r.byte()
+		body := r.bytes(r.u32())
+		if r.err != nil {
+			return nil, r.err
+		}
+		if id == customSection {
+			continue
+		}
+		if int(id) >= len(sectionOrder) {
+			return nil, fmt.Errorf("%w: %d", errUnsupportedSection, id)
+		}
+		if sectionOrder[id] <= lastSection {
+			return nil, errSectionOrder
+		}
+		lastSection = sectionOrder[id]
+
+		s := &reader{b: body}
+		var err error
+		switch id {
+		case typeSection:
+			err = m.decodeTypes(s)
+		case importSection:
+			err = m.decodeImports(s)
+		case functionSection:
+			funcTypes, err = m.decodeFunctionTypes(s)
+		case tableSection:
+			err = m.decodeTables(s)
+		case memorySection:
+			err = m.decodeMemories(s)
+		case globalSection:
+			err = m.decodeGlobals(s)
+		case exportSection:
+			err = m.decodeExports(s, len(funcTypes))
+		case codeSection:
+			codeSeen = true
+			err = m.decodeCode(s, funcTypes)
+		case dataSection:
+			err = m.decodeData(s)
+		case dataCountSection:
+			s.u32()
+		default: // start and element segments aren't supported
+			err = fmt.Errorf("%w: %d", errUnsupportedSection, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
+		if s.len() != 0 {
+			return nil, errSectionSize
+		}
+	}
+	if !codeSeen && len(funcTypes) != 0 {
+		return nil, errFunctionCount
+	}
+	return m, nil
+}
+
+// FuncType returns the signature of the exported function [name]
+func (m *Module) FuncType(name string) (FuncType, bool) {
+	index, ok := m.exports[name]
+	if !ok {
+		return FuncType{}, false
+	}
+	return m.funcType(index), true
+}
+
+// funcType returns the signature of the function with index [index] in the
+// function index space, which starts with the imported functions
+func (m *Module) funcType(index uint32) FuncType {
+	if int(index) < len(m.imports) {
+		return m.types[m.imports[index].typ]
+	}
+	return m.types[m.functions[int(index)-len(m.imports)].typ]
+}
+
+func (m *Module) numFuncs() int { return len(m.imports) + len(m.functions) }
+
+func (m *Module) decodeTypes(r *reader) error {
+	count := r.u32()
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		if r.byte() != 0x60 {
+			return errBadFuncType
+		}
+		params, err := decodeValueTypes(r)
+		if err != nil {
+			return err
+		}
+		results, err := decodeValueTypes(r)
+		if err != nil {
+			return err
+		}
+		if len(results) > 1 {
+			return errBadFuncType
+		}
+		m.types = append(m.types, FuncType{Params: params, Results: results})
+	}
+	return nil
+}
+
+func decodeValueTypes(r *reader) ([]ValueType, error) {
+	count := r.u32()
+	if int64(count) > int64(r.len()) {
+		return nil, errUnexpectedEOF
+	}
+	types := make([]ValueType, count)
+	for i := range types {
+		t, err := decodeValueType(r)
+		if err != nil {
+			return nil, err
+		}
+		types[i] = t
+	}
+	return types, nil
+}
+
+func decodeValueType(r *reader) (ValueType, error) {
+	switch t := ValueType(r.byte()); t {
+	case I32, I64:
+		return t, nil
+	default:
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, fmt.Errorf("%w: %s", errUnsupportedType, t)
+	}
+}
+
+func (m *Module) decodeImports(r *reader) error {
+	count := r.u32()
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		imp := funcImport{
+			module: r.name(),
+			name:   r.name(),
+		}
+		if kind := r.byte(); kind != funcKind {
+			if r.err != nil {
+				return r.err
+			}
+			return fmt.Errorf("%w: %s.%s", errUnsupportedImport, imp.module, imp.name)
+		}
+		imp.typ = r.u32()
+		if int(imp.typ) >= len(m.types) {
+			return errInvalidTypeIndex
+		}
+		m.imports = append(m.imports, imp)
+	}
+	return nil
+}
+
+func (m *Module) decodeFunctionTypes(r *reader) ([]uint32, error) {
+	count := r.u32()
+	if int64(count) > int64(r.len()) {
+		return nil, errUnexpectedEOF
+	}
+	types := make([]uint32, count)
+	for i := range types {
+		types[i] = r.u32()
+		if int(types[i]) >= len(m.types) {
+			return nil, errInvalidTypeIndex
+		}
+	}
+	return types, nil
+}
+
+// decodeTables accepts, and ignores, a single table. Compilers commonly emit a
+// table even when it is never used. Since element segments and indirect calls
+// aren't supported, the table is always empty.
+func (m *Module) decodeTables(r *reader) error {
+	count := r.u32()
+	if count > 1 {
+		return errMultipleTables
+	}
+	if count == 1 {
+		if elemType := r.byte(); elemType != 0x70 {
+			return errBadLimits
+		}
+		if _, err := decodeLimits(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Module) decodeMemories(r *reader) error {
+	count := r.u32()
+	if count > 1 {
+		return errMultipleMemories
+	}
+	if count == 1 {
+		l, err := decodeLimits(r)
+		if err != nil {
+			return err
+		}
+		if l.min > maxPages || (l.hasMax && l.max > maxPages) {
+			return errBadLimits
+		}
+		m.memory = &l
+	}
+	return nil
+}
+
+func decodeLimits(r *reader) (limits, error) {
+	l := limits{}
+	switch r.byte() {
+	case 0x00:
+		l.min = r.u32()
+	case 0x01:
+		l.min = r.u32()
+		l.max = r.u32()
+		l.hasMax = true
+		if l.max < l.min {
+			return l, errBadLimits
+		}
+	default:
+		if r.err != nil {
+			return l, r.err
+		}
+		return l, errBadLimits
+	}
+	return l, r.err
+}
+
+func (m *Module) decodeGlobals(r *reader) error {
+	count := r.u32()
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		t, err := decodeValueType(r)
+		if err != nil {
+			return err
+		}
+		g := global{typ: t}
+		switch r.byte() {
+		case 0x00:
+		case 0x01:
+			g.mutable = true
+		default:
+			return errBadInitExpr
+		}
+		if g.init, err = decodeConstExpr(r, t); err != nil {
+			return err
+		}
+		m.globals = append(m.globals, g)
+	}
+	return nil
+}
+
+// decodeConstExpr decodes an initializer, which must be a constant of type [t]
+func decodeConstExpr(r *reader, t ValueType) (uint64, error) {
+	value := uint64(0)
+	switch op := r.byte(); {
+	case op == opI32Const && t == I32:
+		value = uint64(uint32(r.s32()))
+	case op == opI64Const && t == I64:
+		value = uint64(r.s64())
+	default:
+		return 0, errBadInitExpr
+	}
+	if r.byte() != opEnd {
+		return 0, errBadInitExpr
+	}
+	return value, r.err
+}
+
+func (m *Module) decodeExports(r *reader, numDefinedFuncs int) error {
+	names := make(map[string]struct{})
+	count := r.u32()
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		name := r.name()
+		kind := r.byte()
+		index := r.u32()
+		if r.err != nil {
+			return r.err
+		}
+		if _, exists := names[name]; exists {
+			return fmt.Errorf("%w: %s", errDuplicateExport, name)
+		}
+		names[name] = struct{}{}
+
+		switch kind {
+		case funcKind:
+			if int(index) >= len(m.imports)+numDefinedFuncs {
+				return errInvalidExportIndex
+			}
+			m.exports[name] = index
+		case memoryKind:
+			if m.memory == nil || index != 0 {
+				return errInvalidExportIndex
+			}
+		case globalKind:
+			if int(index) >= len(m.globals) {
+				return errInvalidExportIndex
+			}
+		case tableKind:
+			if index != 0 {
+				return errInvalidExportIndex
+			}
+		default:
+			return errInvalidExportIndex
+		}
+	}
+	return nil
+}
+
+func (m *Module) decodeCode(r *reader, funcTypes []uint32) error {
+	count := r.u32()
+	if int(count) != len(funcTypes) {
+		return errFunctionCount
+	}
+	// All the function signatures must be known before any body is decoded,
+	// as a body may call a function that is defined after it
+	m.functions = make([]function, count)
+	for i, typ := range funcTypes {
+		m.functions[i].typ = typ
+	}
+	for i := range m.functions {
+		body := r.bytes(r.u32())
+		if r.err != nil {
+			return r.err
+		}
+		if err := m.decodeFunction(&m.functions[i], &reader{b: body}); err != nil {
+			return fmt.Errorf("function %d: %w", len(m.imports)+i, err)
+		}
+	}
+	return nil
+}
+
+func (m *Module) decodeFunction(f *function, r *reader) error {
+	numLocals := uint64(len(m.types[f.typ].Params))
+	groups := r.u32()
+	for i := uint32(0); i < groups && r.err == nil; i++ {
+		count := r.u32()
+		t, err := decodeValueType(r)
+		if err != nil {
+			return err
+		}
+		numLocals += uint64(count)
+		if numLocals > maxLocals {
+			return errTooManyLocals
+		}
+		for j := uint32(0); j < count; j++ {
+			f.locals = append(f.locals, t)
+		}
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	code, err := m.decodeInstructions(r, int(numLocals))
+	f.code = code
+	return err
+}
+
+func (m *Module) decodeData(r *reader) error {
+	count := r.u32()
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		if r.u32() != 0 {
+			return errInvalidMemoryIndex
+		}
+		if m.memory == nil {
+			return errMissingMemory
+		}
+		offset, err := decodeConstExpr(r, I32)
+		if err != nil {
+			return err
+		}
+		m.data = append(m.data, dataSegment{
+			offset: uint32(offset),
+			bytes:  r.bytes(r.u32()),
+		})
+	}
+	return nil
+}
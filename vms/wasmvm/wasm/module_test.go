@@ -0,0 +1,224 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// The helpers below assemble the binary encoding of test modules
+
+func leb(v uint32) []byte {
+	b := []byte(nil)
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func concat(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+func vec(items ...[]byte) []byte { return concat(leb(uint32(len(items))), concat(items...)) }
+
+func name(s string) []byte { return concat(leb(uint32(len(s))), []byte(s)) }
+
+func section(id byte, items ...[]byte) []byte {
+	contents := vec(items...)
+	return concat([]byte{id}, leb(uint32(len(contents))), contents)
+}
+
+func module(sections ...[]byte) []byte {
+	return concat([]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}, concat(sections...))
+}
+
+func sig(params, results []ValueType) []byte {
+	p := make([][]byte, len(params))
+	for i, t := range params {
+		p[i] = []byte{byte(t)}
+	}
+	r := make([][]byte, len(results))
+	for i, t := range results {
+		r[i] = []byte{byte(t)}
+	}
+	return concat([]byte{0x60}, vec(p...), vec(r...))
+}
+
+// body encodes a function body that declares one local of each of [locals]
+func body(locals []ValueType, code ...byte) []byte {
+	groups := make([][]byte, len(locals))
+	for i, t := range locals {
+		groups[i] = []byte{0x01, byte(t)}
+	}
+	contents := concat(vec(groups...), code)
+	return concat(leb(uint32(len(contents))), contents)
+}
+
+func export(exportName string, index uint32) []byte {
+	return concat(name(exportName), []byte{funcKind}, leb(index))
+}
+
+// singleFunc returns a module that exports the function "f" with signature
+// [typ]. If [pages] isn't 0, the module has a memory of that many pages.
+func singleFunc(typ []byte, pages uint32, locals []ValueType, code ...byte) []byte {
+	sections := [][]byte{
+		section(typeSection, typ),
+		section(functionSection, leb(0)),
+	}
+	if pages != 0 {
+		sections = append(sections, section(memorySection, concat([]byte{0x00}, leb(pages))))
+	}
+	sections = append(sections,
+		section(exportSection, export("f", 0)),
+		section(codeSection, body(locals, code...)),
+	)
+	return module(sections...)
+}
+
+func TestDecodeEmptyModule(t *testing.T) {
+	m, err := Decode(module())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.FuncType("f"); ok {
+		t.Fatalf("Empty module shouldn't export anything")
+	}
+}
+
+func TestDecodeFuncType(t *testing.T) {
+	m, err := Decode(singleFunc(sig([]ValueType{I32, I64}, []ValueType{I64}), 0, nil,
+		0x20, 0x01, // local.get 1
+		0x0b, // end
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ, ok := m.FuncType("f")
+	if !ok {
+		t.Fatalf("Should have exported f")
+	}
+	if !typ.Equals(FuncType{Params: []ValueType{I32, I64}, Results: []ValueType{I64}}) {
+		t.Fatalf("Wrong signature %s", typ)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	voidSig := sig(nil, nil)
+	tests := []struct {
+		name   string
+		module []byte
+	}{
+		{
+			name:   "bad magic",
+			module: []byte{0x00, 0x61, 0x73, 0x6e, 0x01, 0x00, 0x00, 0x00},
+		},
+		{
+			name:   "bad version",
+			module: []byte{0x00, 0x61, 0x73, 0x6d, 0x02, 0x00, 0x00, 0x00},
+		},
+		{
+			name:   "truncated",
+			module: module(section(typeSection, voidSig))[:12],
+		},
+		{
+			name: "sections out of order",
+			module: module(
+				section(functionSection),
+				section(typeSection),
+			),
+		},
+		{
+			name: "float value type",
+			module: module(
+				section(typeSection, sig([]ValueType{0x7d}, nil)),
+			),
+		},
+		{
+			name: "float instruction",
+			module: singleFunc(voidSig, 0, nil,
+				0x43, 0x00, 0x00, 0x00, 0x00, // f32.const 0
+				0x1a, // drop
+				0x0b, // end
+			),
+		},
+		{
+			name:   "memory instruction without a memory",
+			module: singleFunc(voidSig, 0, nil, 0x3f, 0x00, 0x1a, 0x0b),
+		},
+		{
+			name:   "missing end",
+			module: singleFunc(voidSig, 0, nil, 0x01),
+		},
+		{
+			name:   "unbalanced end",
+			module: singleFunc(voidSig, 0, nil, 0x0b, 0x0b),
+		},
+		{
+			name:   "invalid branch depth",
+			module: singleFunc(voidSig, 0, nil, 0x0c, 0x01, 0x0b),
+		},
+		{
+			name:   "invalid local",
+			module: singleFunc(voidSig, 0, []ValueType{I32}, 0x20, 0x01, 0x1a, 0x0b),
+		},
+		{
+			name:   "invalid call",
+			module: singleFunc(voidSig, 0, nil, 0x10, 0x01, 0x0b),
+		},
+		{
+			name:   "else without if",
+			module: singleFunc(voidSig, 0, nil, 0x02, 0x40, 0x05, 0x0b, 0x0b),
+		},
+		{
+			name: "start section",
+			module: module(
+				section(typeSection, voidSig),
+				section(functionSection, leb(0)),
+				concat([]byte{startSection, 0x01}, leb(0)),
+				section(codeSection, body(nil, 0x0b)),
+			),
+		},
+		{
+			name: "missing code section",
+			module: module(
+				section(typeSection, voidSig),
+				section(functionSection, leb(0)),
+			),
+		},
+		{
+			name: "duplicate export",
+			module: module(
+				section(typeSection, voidSig),
+				section(functionSection, leb(0)),
+				section(exportSection, export("f", 0), export("f", 0)),
+				section(codeSection, body(nil, 0x0b)),
+			),
+		},
+		{
+			name: "memory import",
+			module: module(
+				section(importSection, concat(name("env"), name("memory"), []byte{memoryKind, 0x00}, leb(1))),
+			),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Decode(test.module); err == nil {
+				t.Fatalf("Should have failed to decode the module")
+			}
+		})
+	}
+}
+
+func TestDecodeErrorWrapping(t *testing.T) {
+	_, err := Decode(singleFunc(sig(nil, nil), 0, nil, 0x0c, 0x01, 0x0b))
+	if !errors.Is(err, errInvalidLabel) {
+		t.Fatalf("Expected %s but got %v", errInvalidLabel, err)
+	}
+}
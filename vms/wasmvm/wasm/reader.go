@@ -0,0 +1,116 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package wasm
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"unicode/utf8"
+)
+
+var (
+	errUnexpectedEOF = errors.New("unexpected end of input")
+	errBadLEB128     = errors.New("malformed LEB128 integer")
+	errBadName       = errors.New("name isn't valid UTF-8")
+)
+
+// reader decodes the primitive values of the binary format. Like
+// wrappers.Packer, the first error is sticky: once it is set, all further
+// reads return zero values.
+type reader struct {
+	b   []byte
+	err error
+}
+
+// len returns the number of bytes that haven't been read yet
+func (r *reader) len() int { return len(r.b) }
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.b) == 0 {
+		r.err = errUnexpectedEOF
+		return 0
+	}
+	b := r.b[0]
+	r.b = r.b[1:]
+	return b
+}
+
+func (r *reader) bytes(n uint32) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(n) > uint64(len(r.b)) {
+		r.err = errUnexpectedEOF
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *reader) fixedU32() uint32 {
+	b := r.bytes(4)
+	if r.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (r *reader) u32() uint32 {
+	v := r.leb128(32, false)
+	if v > math.MaxUint32 && r.err == nil {
+		r.err = errBadLEB128
+	}
+	return uint32(v)
+}
+
+func (r *reader) s32() int32 {
+	v := int64(r.leb128(32, true))
+	if (v < math.MinInt32 || v > math.MaxInt32) && r.err == nil {
+		r.err = errBadLEB128
+	}
+	return int32(v)
+}
+
+func (r *reader) s64() int64 { return int64(r.leb128(64, true)) }
+
+// leb128 reads an integer of at most [bits] bits. Signed integers are sign
+// extended to 64 bits.
+func (r *reader) leb128(bits uint, signed bool) uint64 {
+	maxBytes := (bits + 6) / 7
+	result := uint64(0)
+	shift := uint(0)
+	for i := uint(0); i < maxBytes; i++ {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		result |= uint64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if signed && shift < 64 && b&0x40 != 0 {
+				result |= math.MaxUint64 << shift
+			}
+			return result
+		}
+	}
+	r.err = errBadLEB128
+	return 0
+}
+
+func (r *reader) name() string {
+	b := r.bytes(r.u32())
+	if r.err != nil {
+		return ""
+	}
+	if !utf8.Valid(b) {
+		r.err = errBadName
+		return ""
+	}
+	return string(b)
+}